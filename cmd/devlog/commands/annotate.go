@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+func AnnotateCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "annotate",
+		Usage:     "Attach a follow-up note to a captured event",
+		ArgsUsage: "<event-id> [text]",
+		Description: "Annotations show up under the event in search results and the API, and summaries\n" +
+			"   include them as notes added after the fact. The event ID can be shortened to the\n" +
+			"   eight characters search prints. Without text, lists the event's annotations.\n\n" +
+			"   Examples:\n" +
+			"      devlog annotate 3f2a9c1e \"this was the root cause\"\n" +
+			"      devlog annotate 3f2a9c1e\n" +
+			"      devlog annotate --delete 12",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:  "delete",
+				Usage: "Delete the annotation with this ID",
+			},
+		},
+		Action: annotateAction,
+	}
+}
+
+func annotateAction(c *cli.Context) error {
+	ctx := context.Background()
+
+	if c.IsSet("delete") {
+		return withEventStore(func(store *storage.Storage) error {
+			if err := store.DeleteAnnotationContext(ctx, c.Int64("delete")); err != nil {
+				return err
+			}
+			fmt.Println("✓ Annotation deleted")
+			return nil
+		})
+	}
+
+	if c.NArg() == 0 {
+		return fmt.Errorf("event id is required (e.g., devlog annotate 3f2a9c1e \"this was the root cause\")")
+	}
+	text := strings.TrimSpace(strings.Join(c.Args().Tail(), " "))
+
+	return withEventStore(func(store *storage.Storage) error {
+		id, err := store.ResolveEventIDContext(ctx, c.Args().First())
+		if err != nil {
+			return err
+		}
+
+		if text == "" {
+			annotations, err := store.AnnotationsContext(ctx, []string{id})
+			if err != nil {
+				return err
+			}
+			if len(annotations[id]) == 0 {
+				fmt.Printf("Event %s has no annotations\n", id[:8])
+				return nil
+			}
+			for _, a := range annotations[id] {
+				fmt.Printf("%d  %s  %s\n", a.ID, a.CreatedAt.Format("2006-01-02 15:04"), a.Text)
+			}
+			return nil
+		}
+
+		if _, err := store.AddAnnotationContext(ctx, id, text); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Annotated event %s\n", id[:8])
+		return nil
+	})
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	"devlog/plugins/archiver"
+
+	"github.com/urfave/cli/v2"
+)
+
+func ArchiverCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "archiver",
+		Usage: "Manage events archived to object storage",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "run",
+				Usage:  "Archive and prune aged events now",
+				Action: archiverRunAction,
+			},
+			{
+				Name:   "list",
+				Usage:  "List archives recorded in the bucket manifest",
+				Action: archiverListAction,
+			},
+			{
+				Name:  "restore",
+				Usage: "Restore archived events back into the local database",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "from",
+						Usage:    "First day to restore (2006-01-02, 'today' or 'yesterday')",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Last day to restore, inclusive (defaults to --from)",
+					},
+				},
+				Action: archiverRestoreAction,
+			},
+		},
+	}
+}
+
+func loadArchiver() (*archiver.Archiver, *storage.Storage, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if !cfg.IsPluginEnabled("archiver") {
+		return nil, nil, fmt.Errorf("archiver plugin is not enabled (run 'devlog plugin install archiver' first)")
+	}
+
+	pluginCfg, ok := cfg.GetPluginConfig("archiver")
+	if !ok {
+		return nil, nil, fmt.Errorf("archiver plugin config not found")
+	}
+
+	archiverCfg, err := archiver.ParseConfig(pluginCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse archiver config: %w", err)
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get data directory: %w", err)
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open storage: %w", err)
+	}
+
+	arch, err := archiver.NewFromConfig(archiverCfg, store)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+
+	return arch, store, nil
+}
+
+func archiverRunAction(c *cli.Context) error {
+	arch, store, err := loadArchiver()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	result, err := arch.Run(context.Background())
+	if err != nil {
+		return fmt.Errorf("archive events: %w", err)
+	}
+
+	if result.EventsArchived == 0 {
+		fmt.Println("No events old enough to archive")
+		return nil
+	}
+
+	fmt.Printf("✓ Archived %d events in %d archives (%d pruned locally)\n",
+		result.EventsArchived, result.Archives, result.EventsDeleted)
+	return nil
+}
+
+func archiverListAction(c *cli.Context) error {
+	arch, store, err := loadArchiver()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	manifest, err := arch.LoadManifest(context.Background())
+	if err != nil {
+		return err
+	}
+
+	if len(manifest.Archives) == 0 {
+		fmt.Println("No archives found")
+		return nil
+	}
+
+	total := 0
+	for _, entry := range manifest.Archives {
+		fmt.Printf("%s  %s → %s  %6d events  %s\n",
+			entry.CreatedAt, entry.From, entry.To, entry.EventCount, entry.Key)
+		total += entry.EventCount
+	}
+	fmt.Printf("\n%d archives, %d events\n", len(manifest.Archives), total)
+	return nil
+}
+
+func archiverRestoreAction(c *cli.Context) error {
+	from, err := parseDay(c.String("from"))
+	if err != nil {
+		return fmt.Errorf("parse --from: %w", err)
+	}
+
+	to := from
+	if c.IsSet("to") {
+		to, err = parseDay(c.String("to"))
+		if err != nil {
+			return fmt.Errorf("parse --to: %w", err)
+		}
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--to must not be before --from")
+	}
+
+	arch, store, err := loadArchiver()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	result, err := arch.Restore(context.Background(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("restore events: %w", err)
+	}
+
+	fmt.Printf("✓ Restored %d events from %d archives", result.EventsRestored, result.Archives)
+	if result.Duplicates > 0 {
+		fmt.Printf(" (%d already present)", result.Duplicates)
+	}
+	fmt.Println()
+	return nil
+}
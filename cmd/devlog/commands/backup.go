@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	"devlog/plugins/backup"
+
+	"github.com/urfave/cli/v2"
+)
+
+func BackupCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "backup",
+		Usage: "Upload encrypted backups to remote storage",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "status",
+				Usage:  "Show the last upload and the backups stored remotely",
+				Action: backupStatusAction,
+			},
+			{
+				Name:   "run",
+				Usage:  "Upload a backup now",
+				Action: backupRunAction,
+			},
+			{
+				Name:      "download",
+				Usage:     "Download and decrypt a remote backup for 'devlog db restore'",
+				ArgsUsage: "KEY",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Where to write the backup (default: file name of KEY in the current directory)",
+					},
+				},
+				Action: backupDownloadAction,
+			},
+			{
+				Name:  "keygen",
+				Usage: "Generate a new backup encryption key",
+				Action: func(c *cli.Context) error {
+					key, err := backup.GenerateKey()
+					if err != nil {
+						return err
+					}
+					fmt.Println(key)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func loadBackupUploader() (*backup.Uploader, *storage.Storage, string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("load config: %w", err)
+	}
+
+	if !cfg.IsPluginEnabled("backup") {
+		return nil, nil, "", fmt.Errorf("backup plugin is not enabled (run 'devlog plugin install backup' first)")
+	}
+
+	pluginCfg, ok := cfg.GetPluginConfig("backup")
+	if !ok {
+		return nil, nil, "", fmt.Errorf("backup plugin config not found")
+	}
+
+	backupCfg, err := backup.ParseConfig(pluginCfg)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("parse backup config: %w", err)
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("get data directory: %w", err)
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("open storage: %w", err)
+	}
+
+	uploader, err := backup.NewFromConfig(backupCfg, store, dataDir)
+	if err != nil {
+		store.Close()
+		return nil, nil, "", err
+	}
+
+	return uploader, store, dataDir, nil
+}
+
+func backupRunAction(c *cli.Context) error {
+	uploader, store, dataDir, err := loadBackupUploader()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	now := time.Now()
+	result, err := uploader.Run(context.Background())
+	if recordErr := backup.Record(dataDir, now, result, err); recordErr != nil {
+		fmt.Printf("Warning: could not save backup state: %v\n", recordErr)
+	}
+	if result != nil && result.Backup.Key != "" {
+		fmt.Printf("✓ Uploaded %s (%s)\n", result.Backup.Key, formatBytes(result.Backup.Size))
+		fmt.Printf("  %d summary files uploaded, %d old backups pruned\n", result.Summaries, result.Pruned)
+	}
+	if err != nil {
+		return fmt.Errorf("upload backup: %w", err)
+	}
+	return nil
+}
+
+func backupStatusAction(c *cli.Context) error {
+	uploader, store, dataDir, err := loadBackupUploader()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	state, err := backup.LoadState(dataDir)
+	if err != nil {
+		return err
+	}
+	if state.LastRun.IsZero() {
+		fmt.Println("Last run:      never")
+	} else {
+		fmt.Printf("Last run:      %s\n", state.LastRun.Local().Format("2006-01-02 15:04:05"))
+	}
+	if !state.LastSuccess.IsZero() {
+		fmt.Printf("Last success:  %s (%s)\n", state.LastSuccess.Local().Format("2006-01-02 15:04:05"), state.LastKey)
+	}
+	if state.LastError != "" {
+		fmt.Printf("Last error:    %s\n", state.LastError)
+	}
+
+	manifest, err := uploader.Manifest(context.Background())
+	if err != nil {
+		return fmt.Errorf("read remote manifest: %w", err)
+	}
+	fmt.Println()
+	fmt.Printf("Remote backups (%d):\n", len(manifest.Backups))
+	for _, b := range manifest.Backups {
+		fmt.Printf("  %s  %8s  %s\n", b.Created.Local().Format("2006-01-02 15:04"), formatBytes(b.Size), b.Key)
+	}
+	fmt.Printf("Summary files: %d\n", len(manifest.Summaries))
+	return nil
+}
+
+func backupDownloadAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("usage: devlog backup download KEY [--to PATH]")
+	}
+	key := c.Args().First()
+	dest := c.String("to")
+	if dest == "" {
+		dest = filepath.Base(key)
+		if ext := filepath.Ext(dest); ext == ".enc" {
+			dest = dest[:len(dest)-len(ext)]
+		}
+	}
+
+	uploader, store, _, err := loadBackupUploader()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := uploader.Download(context.Background(), key, dest); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Downloaded %s to %s\n", key, dest)
+	fmt.Printf("  Restore it with: devlog db restore %s\n", dest)
+	return nil
+}
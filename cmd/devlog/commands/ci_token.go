@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"devlog/internal/encryption"
+	"devlog/modules/ci"
+
+	"github.com/urfave/cli/v2"
+)
+
+func ciModuleCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ci",
+		Usage: "CI module settings",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "token",
+				Usage: "Store the GitHub token the ci module polls with in the OS keychain",
+				Description: "The token is read from stdin, so it never appears in your shell history:\n" +
+					"      gh auth token | devlog module ci token",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "delete",
+						Usage: "Remove the stored token",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.Bool("delete") {
+						return ciTokenDelete()
+					}
+					return ciTokenStore()
+				},
+			},
+		},
+	}
+}
+
+func ciTokenStore() error {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Print("GitHub token: ")
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	token := strings.TrimSpace(line)
+	if token == "" {
+		if err != nil {
+			return fmt.Errorf("read token: %w", err)
+		}
+		return fmt.Errorf("no token given")
+	}
+
+	if err := encryption.StoreSecret(ci.TokenAccount, ci.TokenLabel, token); err != nil {
+		return err
+	}
+	fmt.Printf("✓ GitHub token stored in %s\n", encryption.KeySource())
+	fmt.Println("Run 'devlog daemon restart' for a running daemon to use it")
+	return nil
+}
+
+func ciTokenDelete() error {
+	if err := encryption.DeleteSecret(ci.TokenAccount); err != nil {
+		return fmt.Errorf("delete token from %s: %w", encryption.KeySource(), err)
+	}
+	fmt.Printf("✓ GitHub token removed from %s\n", encryption.KeySource())
+	return nil
+}
@@ -239,6 +239,7 @@ func createInstallContext() *install.Context {
 		ConfigDir:   configDir,
 		DataDir:     dataDir,
 		HomeDir:     homeDir,
+		Version:     Version,
 		Log: func(format string, args ...interface{}) {
 			fmt.Printf(format+"\n", args...)
 		},
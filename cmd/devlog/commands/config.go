@@ -1,15 +1,21 @@
 package commands
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"devlog/internal/config"
+	"devlog/internal/daemon"
 	"devlog/internal/modules"
 	"devlog/internal/plugins"
 
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
 )
 
 func ConfigCommand() *cli.Command {
@@ -52,43 +58,66 @@ func ConfigCommand() *cli.Command {
 				},
 			},
 			{
-				Name:  "edit",
-				Usage: "Edit config in $EDITOR",
+				Name:      "get",
+				Usage:     "Print one setting, e.g. modules.shell.ignore_list",
+				ArgsUsage: "<path>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "json", Usage: "Print the value as JSON"},
+				},
 				Action: func(c *cli.Context) error {
-					path, err := config.ConfigPath()
-					if err != nil {
-						return fmt.Errorf("get config path: %w", err)
-					}
-
-					if _, err := os.Stat(path); os.IsNotExist(err) {
-						return fmt.Errorf("config file not found at %s (run 'devlog init' to create)", path)
-					}
-
-					editor := os.Getenv("EDITOR")
-					if editor == "" {
-						editor = "vi"
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: devlog config get <path>")
 					}
-
-					cmd := exec.Command(editor, path)
-					cmd.Stdin = os.Stdin
-					cmd.Stdout = os.Stdout
-					cmd.Stderr = os.Stderr
-
-					if err := cmd.Run(); err != nil {
-						return fmt.Errorf("failed to run editor: %w", err)
-					}
-
-					cfg, err := config.Load()
-					if err != nil {
-						return fmt.Errorf("validate config after edit: %w", err)
+					return configGet(c.Args().First(), c.Bool("json"))
+				},
+			},
+			{
+				Name:      "set",
+				Usage:     "Change one setting, e.g. plugins.summarizer.interval_seconds 900",
+				ArgsUsage: "<path> <value>",
+				Description: "The value is read as YAML, so 900 is a number, true a boolean and\n" +
+					"'[ls, cd]' a list. Use --string to store it as text as typed.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{Name: "string", Usage: "Store the value as a string"},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 2 {
+						return fmt.Errorf("usage: devlog config set <path> <value>")
 					}
-
-					if err := cfg.Validate(); err != nil {
-						return fmt.Errorf("config validation failed: %w", err)
+					return configSet(c.Args().Get(0), c.Args().Get(1), c.Bool("string"))
+				},
+			},
+			{
+				Name:      "unset",
+				Usage:     "Remove one setting so its default applies",
+				ArgsUsage: "<path>",
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: devlog config unset <path>")
 					}
-
-					fmt.Println("Configuration validated successfully")
-					return nil
+					return configUnset(c.Args().First())
+				},
+			},
+			{
+				Name:  "edit",
+				Usage: "Edit config in $EDITOR",
+				Action: func(c *cli.Context) error {
+					return configEdit()
+				},
+			},
+			{
+				Name:      "validate",
+				Usage:     "Check the config file, or another file, for errors",
+				ArgsUsage: "[file]",
+				Action: func(c *cli.Context) error {
+					return configValidate(c.Args().First())
+				},
+			},
+			{
+				Name:  "diff-default",
+				Usage: "Show the settings that differ from a fresh install",
+				Action: func(c *cli.Context) error {
+					return configDiffDefault()
 				},
 			},
 			{
@@ -98,6 +127,7 @@ func ConfigCommand() *cli.Command {
 					return configStatus()
 				},
 			},
+			configTestFilterCommand(),
 		},
 	}
 }
@@ -127,6 +157,10 @@ func configStatus() error {
 	fmt.Printf("Config file: %s\n", configPath)
 	fmt.Printf("Data directory: %s\n", dataDir)
 	fmt.Printf("HTTP port: %d\n", cfg.HTTP.Port)
+	fmt.Printf("HTTP address: %s\n", cfg.HTTP.LocalURL())
+	if socket, err := cfg.HTTP.SocketPath(); err == nil && socket != "" {
+		fmt.Printf("API socket: %s\n", socket)
+	}
 	fmt.Println()
 
 	fmt.Println("Modules:")
@@ -166,3 +200,259 @@ func configStatus() error {
 
 	return nil
 }
+
+func configGet(path string, asJSON bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	val, err := cfg.Get(path)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		data, err := json.MarshalIndent(val, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal value: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	switch val.(type) {
+	case map[string]interface{}, []interface{}:
+		data, err := yaml.Marshal(val)
+		if err != nil {
+			return fmt.Errorf("marshal value: %w", err)
+		}
+		fmt.Print(string(data))
+	default:
+		fmt.Println(val)
+	}
+	return nil
+}
+
+func configSet(path, raw string, asString bool) error {
+	var value interface{} = raw
+	if !asString {
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+			return fmt.Errorf("read value as YAML (use --string to store it as text): %w", err)
+		}
+		if value == nil {
+			return fmt.Errorf("value is empty; use 'devlog config unset %s' to remove the setting", path)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	next, err := cfg.Set(path, value)
+	if err != nil {
+		return err
+	}
+	if err := next.Save(); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	fmt.Printf("✓ Set %s to %s\n", path, formatConfigValue(value))
+	printConfigApplied(cfg, next)
+	return nil
+}
+
+func configUnset(path string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	next, err := cfg.Unset(path)
+	if err != nil {
+		return err
+	}
+	if err := next.Save(); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	fmt.Printf("✓ Removed %s\n", path)
+	printConfigApplied(cfg, next)
+	return nil
+}
+
+// printConfigApplied says when a change made from the command line takes
+// effect. The daemon's config watcher picks up the file on its own.
+func printConfigApplied(cfg, next *config.Config) {
+	if !daemon.IsRunning() {
+		return
+	}
+	if restart := cfg.RestartRequired(next); len(restart) > 0 {
+		fmt.Printf("Restart the daemon to apply %s\n", strings.Join(restart, ", "))
+	}
+}
+
+// configEdit opens a copy of config.yaml in $EDITOR and only replaces the
+// file once the copy is a valid config, so a typo never leaves the daemon
+// with a config it cannot load.
+func configEdit() error {
+	path, err := config.ConfigPath()
+	if err != nil {
+		return fmt.Errorf("get config path: %w", err)
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("config file not found at %s (run 'devlog init' to create)", path)
+		}
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "config-*.yaml")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	var edited []byte
+	for {
+		if err := runEditor(tmpPath); err != nil {
+			os.Remove(tmpPath)
+			return err
+		}
+		if edited, err = os.ReadFile(tmpPath); err != nil {
+			os.Remove(tmpPath)
+			return fmt.Errorf("read edited config: %w", err)
+		}
+		if bytes.Equal(edited, original) {
+			os.Remove(tmpPath)
+			fmt.Println("No changes")
+			return nil
+		}
+		if _, err = config.Parse(edited); err == nil {
+			break
+		}
+		fmt.Printf("✗ %v\n", err)
+		if !confirm("Edit again?") {
+			os.Remove(tmpPath)
+			return fmt.Errorf("config.yaml left unchanged")
+		}
+	}
+
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	if !bytes.Equal(current, original) {
+		return fmt.Errorf("config.yaml changed while you were editing; your version is in %s", tmpPath)
+	}
+	if err := os.WriteFile(path, edited, 0644); err != nil {
+		return fmt.Errorf("write config file: %w", err)
+	}
+	os.Remove(tmpPath)
+
+	fmt.Println("✓ Configuration validated and saved")
+	return nil
+}
+
+func runEditor(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run editor: %w", err)
+	}
+	return nil
+}
+
+func configValidate(path string) error {
+	if path == "" {
+		var err error
+		if path, err = config.ConfigPath(); err != nil {
+			return fmt.Errorf("get config path: %w", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	if _, err := config.Parse(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	fmt.Printf("✓ %s is valid\n", path)
+	return nil
+}
+
+// configDiffDefault compares the config with a fresh one in which the
+// configured modules and plugins have their install defaults, so only
+// the settings changed by hand show up.
+func configDiffDefault() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	moduleDefaults := make(map[string]interface{}, len(cfg.Modules))
+	for name := range cfg.Modules {
+		moduleDefaults[name] = nil
+		if m, err := modules.Get(name); err == nil {
+			moduleDefaults[name] = m.DefaultConfig()
+		}
+	}
+	pluginDefaults := make(map[string]interface{}, len(cfg.Plugins))
+	for name := range cfg.Plugins {
+		pluginDefaults[name] = nil
+		if p, err := plugins.Get(name); err == nil {
+			pluginDefaults[name] = p.DefaultConfig()
+		}
+	}
+
+	base, err := config.DefaultDocument(moduleDefaults, pluginDefaults)
+	if err != nil {
+		return err
+	}
+	doc, err := cfg.Document()
+	if err != nil {
+		return err
+	}
+
+	changes := config.DiffDocuments(base, doc)
+	if len(changes) == 0 {
+		fmt.Println("Config matches the defaults")
+		return nil
+	}
+	for _, change := range changes {
+		switch {
+		case change.Old == nil:
+			fmt.Printf("+ %s: %s\n", change.Path, formatConfigValue(change.New))
+		case change.New == nil:
+			fmt.Printf("- %s: %s\n", change.Path, formatConfigValue(change.Old))
+		default:
+			fmt.Printf("~ %s: %s → %s\n", change.Path, formatConfigValue(change.Old), formatConfigValue(change.New))
+		}
+	}
+	return nil
+}
+
+// formatConfigValue prints a setting on one line, with lists and sections
+// as JSON.
+func formatConfigValue(val interface{}) string {
+	switch val.(type) {
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(val)
+		if err == nil {
+			return string(data)
+		}
+	}
+	return fmt.Sprint(val)
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+
+	"github.com/urfave/cli/v2"
+)
+
+func configTestFilterCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "test-filter",
+		Usage: "Show whether an event would be captured or dropped by filters",
+		Description: "Builds an event from the flags and runs it through the shell ignore_list and the\n" +
+			"   filters section of the config, printing the rule that decides.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "module",
+				Aliases:  []string{"m"},
+				Usage:    "Event source (shell, git, kubectl, ...)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:    "type",
+				Aliases: []string{"t"},
+				Usage:   "Event type",
+			},
+			&cli.StringFlag{
+				Name:  "command",
+				Usage: "Command stored in the payload",
+			},
+			&cli.StringFlag{
+				Name:  "repo",
+				Usage: "Repository name or path",
+			},
+			&cli.StringFlag{
+				Name:  "branch",
+				Usage: "Branch name",
+			},
+			&cli.StringSliceFlag{
+				Name:  "payload",
+				Usage: "Payload field as key=value (repeatable)",
+			},
+		},
+		Action: configTestFilterAction,
+	}
+}
+
+func configTestFilterAction(c *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	event := events.NewEvent(c.String("module"), c.String("type"))
+	// A path is split the way ingest stores it: the repo is the directory
+	// name and the full path stays in the payload for repo rules.
+	if repo := c.String("repo"); repo != "" {
+		event.Repo = repo
+		path := config.ExpandHome(repo)
+		if path == "." || strings.ContainsRune(path, filepath.Separator) {
+			abs, err := filepath.Abs(path)
+			if err != nil {
+				return err
+			}
+			event.Repo = filepath.Base(abs)
+			event.Payload["repo_path"] = abs
+		}
+	}
+	event.Branch = c.String("branch")
+	for _, field := range c.StringSlice("payload") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --payload %q (use key=value)", field)
+		}
+		event.Payload[key] = value
+	}
+	if command := c.String("command"); command != "" {
+		event.Payload["command"] = command
+	}
+
+	if command, ok := event.Payload["command"].(string); ok && event.Source == string(events.SourceShell) {
+		for _, pattern := range cfg.ShellIgnoreList() {
+			if config.IsCommandIgnored([]string{pattern}, command) {
+				fmt.Printf("✗ dropped: shell ignore_list pattern %q\n", pattern)
+				return nil
+			}
+		}
+	}
+
+	decision := cfg.FilterEvent(event)
+	if !decision.Capture {
+		fmt.Printf("✗ dropped: %s\n", decision.Reason)
+		return nil
+	}
+
+	switch cfg.PrivacyAction(event) {
+	case config.PrivacyDrop:
+		fmt.Println("✗ dropped: repo matches privacy.excluded_repos")
+		return nil
+	case config.PrivacyMask:
+		fmt.Println("✓ captured with repo hashed and payload stripped (privacy.excluded_repos)")
+		return nil
+	}
+
+	if len(cfg.Filters) == 0 {
+		fmt.Println("✓ captured (no filters configured)")
+	} else {
+		fmt.Printf("✓ captured (checked %d filter rules)\n", len(cfg.Filters))
+	}
+	return nil
+}
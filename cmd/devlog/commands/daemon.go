@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 	"time"
 
 	"devlog/internal/config"
@@ -57,6 +56,42 @@ func DaemonCommand() *cli.Command {
 					return daemonStatus()
 				},
 			},
+			{
+				Name:  "service",
+				Usage: "Run the daemon as a Windows service",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "install",
+						Usage: "Register the daemon as a service that starts at boot, and start it",
+						Action: func(c *cli.Context) error {
+							return daemonServiceInstall()
+						},
+					},
+					{
+						Name:  "uninstall",
+						Usage: "Stop the service and remove it",
+						Action: func(c *cli.Context) error {
+							if err := daemon.UninstallService(); err != nil {
+								return err
+							}
+							fmt.Println("Service removed")
+							return nil
+						},
+					},
+					{
+						Name:  "status",
+						Usage: "Show whether the service is installed and running",
+						Action: func(c *cli.Context) error {
+							status, err := daemon.ServiceStatus()
+							if err != nil {
+								return err
+							}
+							fmt.Printf("Service %s: %s\n", daemon.ServiceName, status)
+							return nil
+						},
+					},
+				},
+			},
 		},
 	}
 }
@@ -66,6 +101,9 @@ func daemonStart(foreground bool) error {
 		return fmt.Errorf("daemon is already running (PID %d)", daemon.GetPID())
 	}
 
+	if daemon.IsService() {
+		return daemon.RunService(runDaemonForeground)
+	}
 	if os.Getenv("DEVLOG_DAEMON_SUBPROCESS") == "1" || foreground {
 		return runDaemonForeground()
 	}
@@ -96,21 +134,39 @@ func runDaemonForeground() error {
 		return err
 	}
 
-	dbPath := filepath.Join(dataDir, "events.db")
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return fmt.Errorf("database does not exist (run 'devlog init' first)")
+	if err := moduleRefresh(false, false); err != nil {
+		fmt.Printf("Warning: could not refresh module assets: %v\n", err)
 	}
 
-	store, err := storage.New(dbPath)
+	store, err := storage.Open(dataDir, cfg.Storage)
 	if err != nil {
 		return err
 	}
 	defer store.Close()
 
+	if sqlite, ok := store.(*storage.Storage); ok {
+		if qc := cfg.Daemon.QueryCache; !qc.Disabled {
+			sqlite.EnableQueryCache(qc.Size, time.Duration(qc.TTLSeconds)*time.Second)
+		}
+	}
+
 	d := daemon.New(cfg, store)
+	d.SetVersion(Version)
 	return d.Start()
 }
 
+func daemonServiceInstall() error {
+	if daemon.IsRunning() {
+		return fmt.Errorf("daemon is already running (PID %d); stop it with 'devlog daemon stop' first", daemon.GetPID())
+	}
+	if err := daemon.InstallService(); err != nil {
+		return err
+	}
+	fmt.Printf("Service %s installed and started\n", daemon.ServiceName)
+	fmt.Println("'devlog daemon stop' stops it from an elevated prompt; it starts again at boot")
+	return nil
+}
+
 func daemonStop() error {
 	if !daemon.IsRunning() {
 		fmt.Println("Daemon is not running")
@@ -137,14 +193,24 @@ func daemonRestart() error {
 	return daemonStart(false)
 }
 
+// daemonGet sends this machine's API token so status calls keep working when
+// http.auth_enabled is set.
+func daemonGet(httpCfg config.HTTPConfig, path string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, httpCfg.LocalURL()+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	config.AuthorizeRequest(req)
+	return httpCfg.Client(10 * time.Second).Do(req)
+}
+
 func daemonStatus() error {
 	if daemon.IsRunning() {
 		fmt.Printf("Daemon is running (PID %d)\n", daemon.GetPID())
 
 		cfg, err := config.Load()
 		if err == nil {
-			url := fmt.Sprintf("http://127.0.0.1:%d/api/v1/status", cfg.HTTP.Port)
-			resp, err := http.Get(url)
+			resp, err := daemonGet(cfg.HTTP, "/api/v1/status")
 			if err == nil {
 				defer resp.Body.Close()
 				body, _ := io.ReadAll(resp.Body)
@@ -153,6 +219,24 @@ func daemonStatus() error {
 				if json.Unmarshal(body, &status) == nil {
 					fmt.Printf("Event count: %v\n", status["event_count"])
 					fmt.Printf("Uptime: %v seconds\n", status["uptime_seconds"])
+					if paused, _ := status["paused"].(bool); paused {
+						if until, ok := status["paused_until"].(string); ok {
+							fmt.Printf("Capture: paused until %s\n", until)
+						} else {
+							fmt.Println("Capture: paused (run 'devlog resume')")
+						}
+					}
+					if issues, _ := status["preflight"].([]interface{}); len(issues) > 0 {
+						fmt.Println("Preflight:")
+						for _, raw := range issues {
+							issue, _ := raw.(map[string]interface{})
+							mark := "!"
+							if repaired, _ := issue["repaired"].(bool); repaired {
+								mark = "✓"
+							}
+							fmt.Printf("  %s %s: %v\n", mark, issue["module"], issue["message"])
+						}
+					}
 				}
 			}
 		}
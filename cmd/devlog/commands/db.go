@@ -0,0 +1,425 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/daemon"
+	"devlog/internal/events"
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+func DBCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "db",
+		Usage: "Maintain the local event database",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: "Show the schema version and which migrations have been applied",
+				Action: func(c *cli.Context) error {
+					return schemaStatus()
+				},
+			},
+			{
+				Name:  "migrate",
+				Usage: "Apply pending schema migrations",
+				Description: "The daemon and CLI apply pending migrations whenever they open the database, so this is\n" +
+					"   optional. Run it after upgrading devlog to see what changes before restarting the daemon.\n" +
+					"   A copy of the database is saved as events.db.v<N>.bak first, where N is the old version.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "List pending migrations without applying them",
+					},
+					&cli.BoolFlag{
+						Name:  "no-backup",
+						Usage: "Skip copying the database before migrating",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return migrateSchema(c.Bool("dry-run"), c.Bool("no-backup"))
+				},
+			},
+			{
+				Name:  "upgrade-events",
+				Usage: "Rewrite stored events whose payloads predate the current module format",
+				Description: "Events are upgraded in memory whenever they are read, so this is optional. Running it\n" +
+					"   stores the upgraded payloads so other tools reading the database see the new shape.\n" +
+					"   Safe to re-run if interrupted.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show how many events would be upgraded without changing them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return withEventStore(func(store *storage.Storage) error {
+						return upgradeEvents(store, c.Bool("dry-run"))
+					})
+				},
+			},
+			{
+				Name:  "maintain",
+				Usage: "Checkpoint the WAL, refresh planner statistics and optimize the search index",
+				Description: "The daemon runs this automatically once a day. Run it by hand after large imports or\n" +
+					"   prunes, or when searches feel slow. --vacuum also rewrites the file to reclaim free\n" +
+					"   space; it locks the database while it runs, so ingest waits until it finishes.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "vacuum",
+						Usage: "Also VACUUM the database to shrink the file",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return withEventStore(func(store *storage.Storage) error {
+						return maintainDatabase(store, c.Bool("vacuum"))
+					})
+				},
+			},
+			{
+				Name:  "backup",
+				Usage: "Copy the database to a backup file",
+				Description: "Uses SQLite's online backup API, so the copy is consistent even while the daemon is\n" +
+					"   writing. Without --to the backup goes to the backup directory (backup.dir, by default\n" +
+					"   backups/ in the data directory), where scheduled backups are kept too.\n\n" +
+					"   Examples:\n" +
+					"      devlog db backup\n" +
+					"      devlog db backup --to ~/Dropbox/devlog.db.gz --gzip\n" +
+					"      devlog db backup --list",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Write the backup to this path",
+					},
+					&cli.BoolFlag{
+						Name:  "gzip",
+						Usage: "Compress the backup (implied by a --to path ending in .gz)",
+					},
+					&cli.BoolFlag{
+						Name:  "list",
+						Usage: "List the backups in the backup directory instead",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.Bool("list") {
+						return listBackups()
+					}
+					return backupDatabase(c.String("to"), c.Bool("gzip"))
+				},
+			},
+			{
+				Name:      "restore",
+				Usage:     "Replace the database with a backup",
+				ArgsUsage: "<backup file>",
+				Description: "Takes a file written by 'devlog db backup' or a scheduled backup, compressed or not.\n" +
+					"   The backup is checked before anything is overwritten, and the current database is\n" +
+					"   saved as events.db.pre-restore.bak first. Stop the daemon before restoring.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "no-backup",
+						Usage: "Skip saving the current database before restoring",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					if c.NArg() != 1 {
+						return fmt.Errorf("usage: devlog db restore <backup file>")
+					}
+					return restoreDatabase(c.Args().First(), c.Bool("no-backup"))
+				},
+			},
+			{
+				Name:  "normalize-repos",
+				Usage: "Rename stored repos using the current repos.aliases config",
+				Description: "New events are named at ingest. This applies the same rules to events captured before an\n" +
+					"   alias was added, so older activity is grouped under the new name too. Only the stored\n" +
+					"   repo name is matched, not working directories or remotes.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show which repos would be renamed without changing them",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					cfg, err := config.Load()
+					if err != nil {
+						return err
+					}
+					return withEventStore(func(store *storage.Storage) error {
+						return normalizeRepos(store, cfg, c.Bool("dry-run"))
+					})
+				},
+			},
+			{
+				Name:  "migrations",
+				Usage: "List registered payload migrations",
+				Action: func(c *cli.Context) error {
+					migrations := events.PayloadMigrations()
+					if len(migrations) == 0 {
+						fmt.Println("No payload migrations registered")
+						return nil
+					}
+					for _, m := range migrations {
+						fmt.Printf("%-12s v%d -> v%d  %s\n", m.Source, m.From, m.From+1, m.Description)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func eventsDBPath() (string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "events.db"), nil
+}
+
+func schemaStatus() error {
+	dbPath, err := eventsDBPath()
+	if err != nil {
+		return err
+	}
+
+	status, err := storage.MigrationStatus(dbPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Schema version: %d (latest %d)\n\n", status.Current, status.Latest)
+	for _, m := range status.Migrations {
+		applied := "pending"
+		if !m.AppliedAt.IsZero() {
+			applied = m.AppliedAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Printf("%3d  %-16s  %s\n", m.Version, applied, m.Description)
+	}
+
+	if n := len(status.Pending()); n > 0 {
+		fmt.Printf("\n%d pending; run 'devlog db migrate' to apply\n", n)
+	}
+	return nil
+}
+
+func migrateSchema(dryRun, noBackup bool) error {
+	dbPath, err := eventsDBPath()
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		status, err := storage.MigrationStatus(dbPath)
+		if err != nil {
+			return err
+		}
+		pending := status.Pending()
+		if len(pending) == 0 {
+			fmt.Println("Database is up to date")
+			return nil
+		}
+		for _, m := range pending {
+			fmt.Printf("%3d  %s\n", m.Version, m.Description)
+		}
+		fmt.Printf("\nDry run: %d migrations would be applied\n", len(pending))
+		return nil
+	}
+
+	result, err := storage.Migrate(dbPath, storage.MigrateOptions{NoBackup: noBackup})
+	if result != nil {
+		if result.Backup != "" {
+			fmt.Printf("Backed up database to %s\n", result.Backup)
+		}
+		for _, m := range result.Applied {
+			fmt.Printf("✓ %3d  %s\n", m.Version, m.Description)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(result.Applied) == 0 {
+		fmt.Println("Database is up to date")
+		return nil
+	}
+	fmt.Printf("\nMigrated schema from version %d to %d\n", result.From, result.To)
+	return nil
+}
+
+func backupDatabase(dest string, gzip bool) error {
+	if dest == "" {
+		dir, err := backupDir()
+		if err != nil {
+			return err
+		}
+		dest = filepath.Join(dir, storage.BackupFileName(time.Now(), gzip))
+	} else {
+		dest = config.ExpandHome(dest)
+		gzip = gzip || strings.HasSuffix(dest, ".gz")
+	}
+
+	return withEventStore(func(store *storage.Storage) error {
+		info, err := store.BackupContext(context.Background(), dest, storage.BackupOptions{Gzip: gzip})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up database to %s (%s)\n", info.Path, formatBytes(info.Size))
+		return nil
+	})
+}
+
+func listBackups() error {
+	dir, err := backupDir()
+	if err != nil {
+		return err
+	}
+	backups, err := storage.ListBackups(dir)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		fmt.Printf("No backups in %s\n", dir)
+		return nil
+	}
+	for _, b := range backups {
+		fmt.Printf("%s  %9s  %s\n", b.Created.Local().Format("2006-01-02 15:04"), formatBytes(b.Size), b.Path)
+	}
+	return nil
+}
+
+func backupDir() (string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	var cfg config.BackupConfig
+	if loaded, err := config.Load(); err == nil {
+		cfg = loaded.Backup
+	}
+	return cfg.BackupDir(dataDir), nil
+}
+
+func restoreDatabase(src string, noBackup bool) error {
+	if daemon.IsRunning() {
+		return fmt.Errorf("the daemon is running; stop it with 'devlog daemon stop' before restoring")
+	}
+	dbPath, err := eventsDBPath()
+	if err != nil {
+		return err
+	}
+
+	result, err := storage.Restore(context.Background(), config.ExpandHome(src), dbPath, storage.RestoreOptions{NoBackup: noBackup})
+	if err != nil {
+		return err
+	}
+	if result.Backup != "" {
+		fmt.Printf("Saved the previous database to %s\n", result.Backup)
+	}
+	fmt.Printf("Restored %d events (schema version %d) from %s\n", result.Events, result.SchemaVersion, src)
+	if result.SchemaVersion < storage.LatestSchemaVersion() {
+		fmt.Println("The backup predates this devlog version; it is migrated the next time the database is opened.")
+	}
+	return nil
+}
+
+func maintainDatabase(store *storage.Storage, vacuum bool) error {
+	result, err := store.MaintainContext(context.Background(), storage.MaintenanceOptions{Vacuum: vacuum})
+	if result != nil {
+		for _, step := range result.Steps {
+			fmt.Printf("✓ %-22s %s\n", step.Name, step.Duration.Round(time.Millisecond))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nDatabase size: %s", formatBytes(result.SizeBefore))
+	if result.SizeAfter != result.SizeBefore {
+		fmt.Printf(" -> %s", formatBytes(result.SizeAfter))
+	}
+	fmt.Println()
+	return nil
+}
+
+func normalizeRepos(store *storage.Storage, cfg *config.Config, dryRun bool) error {
+	ctx := context.Background()
+
+	// A negative limit returns every repo.
+	repos, err := store.TopRepos(ctx, -1)
+	if err != nil {
+		return err
+	}
+
+	renamed := 0
+	var total int64
+	for _, rs := range repos {
+		name := cfg.ResolveRepoName(rs.Repo)
+		if name == rs.Repo {
+			continue
+		}
+		renamed++
+		if dryRun {
+			fmt.Printf("%s -> %s  %d events\n", rs.Repo, name, rs.Count)
+			total += int64(rs.Count)
+			continue
+		}
+		n, err := store.RenameRepoContext(ctx, rs.Repo, name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s -> %s  %d events\n", rs.Repo, name, n)
+		total += n
+	}
+
+	switch {
+	case renamed == 0:
+		fmt.Println("All repos already use their canonical names")
+	case dryRun:
+		fmt.Printf("\nDry run: %d events in %d repos would be renamed\n", total, renamed)
+	default:
+		fmt.Printf("\nRenamed %d events in %d repos\n", total, renamed)
+	}
+	return nil
+}
+
+func upgradeEvents(store *storage.Storage, dryRun bool) error {
+	ctx := context.Background()
+
+	outdated, err := store.OutdatedEventsContext(ctx)
+	if err != nil {
+		return err
+	}
+	if len(outdated) == 0 {
+		fmt.Println("All events are at their current payload version")
+		return nil
+	}
+
+	total := 0
+	for _, o := range outdated {
+		fmt.Printf("%-12s v%d -> v%d  %d events\n", o.Source, o.Version, o.CurrentVersion, o.Count)
+		total += o.Count
+	}
+
+	if dryRun {
+		fmt.Printf("\nDry run: %d events would be upgraded\n", total)
+		return nil
+	}
+
+	result, err := store.UpgradeEventsContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nUpgraded %d events\n", result.Upgraded)
+	if result.Failed > 0 {
+		fmt.Printf("%d events could not be upgraded and were left unchanged\n", result.Failed)
+	}
+	return nil
+}
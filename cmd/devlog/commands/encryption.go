@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devlog/internal/config"
+	"devlog/internal/encryption"
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+func EncryptionCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "encryption",
+		Usage: "Manage encryption of stored event payloads",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "enable",
+				Usage: "Encrypt event payloads, including those already stored",
+				Description: "Generates a key in the OS keychain (unless one exists), marks the database as encrypted,\n" +
+					"   and rewrites every existing payload. Safe to re-run if interrupted.\n\n" +
+					"   Set " + encryption.EnvKey + " to a 64-character hex key to bypass the keychain.",
+				Action: func(c *cli.Context) error {
+					return withEventStore(enablePayloadEncryption)
+				},
+			},
+			{
+				Name:  "disable",
+				Usage: "Decrypt all event payloads and turn encryption off",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "delete-key",
+						Usage: "Also remove the key from the OS keychain",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					return withEventStore(func(store *storage.Storage) error {
+						return disablePayloadEncryption(store, c.Bool("delete-key"))
+					})
+				},
+			},
+			{
+				Name:  "status",
+				Usage: "Show whether payloads are encrypted",
+				Action: func(c *cli.Context) error {
+					return withEventStore(func(store *storage.Storage) error {
+						if !store.PayloadEncryptionEnabled() {
+							fmt.Println("Payload encryption: disabled")
+							return nil
+						}
+						fmt.Println("Payload encryption: enabled (aes-256-gcm)")
+						fmt.Printf("Key ID:             %s\n", store.PayloadKeyID())
+						fmt.Printf("Key source:         %s\n", encryption.KeySource())
+						return nil
+					})
+				},
+			},
+		},
+	}
+}
+
+func withEventStore(fn func(store *storage.Storage) error) error {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	return fn(store)
+}
+
+func loadOrCreateEncryptionKey() ([]byte, error) {
+	key, err := encryption.LoadKey()
+	if err == nil {
+		fmt.Printf("Using existing encryption key from %s\n", encryption.KeySource())
+		return key, nil
+	}
+	if !errors.Is(err, encryption.ErrNoKey) {
+		return nil, err
+	}
+
+	key, err = encryption.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := encryption.StoreKey(key); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Stored new encryption key in %s\n", encryption.KeySource())
+	return key, nil
+}
+
+func enablePayloadEncryption(store *storage.Storage) error {
+	key, err := loadOrCreateEncryptionKey()
+	if err != nil {
+		return err
+	}
+
+	n, err := store.EnablePayloadEncryption(context.Background(), key)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Payload encryption enabled (key %s)\n", store.PayloadKeyID())
+	if n > 0 {
+		fmt.Printf("  Encrypted %d existing payloads\n", n)
+	}
+	fmt.Println()
+	fmt.Println("Notes:")
+	fmt.Println("  - Back up the key; without it stored payloads cannot be read.")
+	fmt.Println("  - Full-text search and command stats no longer see payload contents.")
+	fmt.Println("  - Restart a running daemon so new events are encrypted: devlog daemon restart")
+
+	return nil
+}
+
+func disablePayloadEncryption(store *storage.Storage, deleteKey bool) error {
+	n, err := store.DisablePayloadEncryption(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Payload encryption disabled; decrypted %d payloads\n", n)
+
+	if deleteKey {
+		if err := encryption.DeleteKey(); err != nil {
+			return err
+		}
+		fmt.Printf("  Removed key from %s\n", encryption.KeySource())
+	}
+
+	fmt.Println("Restart a running daemon so new events are stored in plaintext: devlog daemon restart")
+	return nil
+}
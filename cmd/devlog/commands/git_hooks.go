@@ -0,0 +1,243 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"devlog/internal/config"
+	"devlog/internal/state"
+	"devlog/modules/git"
+
+	"github.com/urfave/cli/v2"
+)
+
+func gitModuleCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "git",
+		Usage: "git module settings",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "hooks",
+				Usage: "Manage the post-commit, post-checkout and post-merge hooks installed into repos",
+				Description: "The git wrapper only sees commands typed in a shell. Repo hooks also record\n" +
+					"   commits, branch switches and merges made by editors and GUI clients. Existing\n" +
+					"   hooks are kept as <hook>.devlog-orig and run first. Directories passed to\n" +
+					"   'install' are saved as hook_roots; the daemon watches them for new clones and\n" +
+					"   lists them here, or installs hooks itself when auto_install_hooks is true.",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "Show repos with hooks and new repos without them",
+						Action: func(c *cli.Context) error { return gitHooksList() },
+					},
+					{
+						Name:      "install",
+						Usage:     "Install hooks into every repo under DIR, or under hook_roots when none is given",
+						ArgsUsage: "[DIR...]",
+						Action: func(c *cli.Context) error {
+							return gitHooksInstall(c.Args().Slice())
+						},
+					},
+					{
+						Name:      "uninstall",
+						Usage:     "Remove hooks from the given repos, restoring the hooks they chained",
+						ArgsUsage: "REPO [REPO...]",
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "Remove hooks from every repo and clear hook_roots",
+							},
+						},
+						Action: func(c *cli.Context) error {
+							return gitHooksUninstall(c.Args().Slice(), c.Bool("all"))
+						},
+					},
+				},
+				Action: func(c *cli.Context) error { return gitHooksList() },
+			},
+		},
+	}
+}
+
+func gitHooksList() error {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+	if _, err := git.PruneRepoHooks(dataDir); err != nil {
+		return err
+	}
+
+	hooked, err := git.HookedRepos(dataDir)
+	if err != nil {
+		return err
+	}
+	if len(hooked) == 0 {
+		fmt.Println("No repos have devlog hooks; add them with 'devlog module git hooks install DIR'")
+	}
+	for _, repo := range hooked {
+		fmt.Printf("✓ %s\n", repo)
+	}
+
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return err
+	}
+	if pending := git.PendingRepos(stateMgr); len(pending) > 0 {
+		fmt.Println()
+		fmt.Println("New repos without hooks (install with 'devlog module git hooks install'):")
+		for _, repo := range pending {
+			fmt.Printf("  %s\n", repo)
+		}
+	}
+	return nil
+}
+
+// gitHooksInstall installs hooks into the repos under dirs, which are
+// added to hook_roots, or under the existing hook_roots when dirs is empty.
+func gitHooksInstall(dirs []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !cfg.IsModuleEnabled("git") {
+		return fmt.Errorf("the git module is not enabled; run 'devlog module install git' first")
+	}
+
+	ctx := createInstallContext()
+	modCfg, _ := cfg.GetModuleConfig("git")
+	if modCfg == nil {
+		modCfg = make(map[string]interface{})
+	}
+
+	roots := git.HookRoots(modCfg, ctx.HomeDir)
+	if len(dirs) > 0 {
+		known := make(map[string]bool, len(roots))
+		for _, root := range roots {
+			known[root] = true
+		}
+		list, _ := modCfg["hook_roots"].([]interface{})
+
+		roots = nil
+		for _, dir := range dirs {
+			root := git.ExpandRoot(dir, ctx.HomeDir)
+			if info, err := os.Stat(root); err != nil || !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", dir)
+			}
+			roots = append(roots, root)
+			if !known[root] {
+				known[root] = true
+				list = append(list, root)
+			}
+		}
+
+		modCfg["hook_roots"] = list
+		cfg.SetModuleConfig("git", modCfg)
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("save config: %w", err)
+		}
+	}
+	if len(roots) == 0 {
+		return fmt.Errorf("no directory given and hook_roots is empty")
+	}
+
+	var repos, failed []string
+	for _, root := range roots {
+		found, err := git.FindRepos(root)
+		if err != nil {
+			return err
+		}
+		repos = append(repos, found...)
+	}
+	if len(repos) == 0 {
+		fmt.Printf("No git repos found under %s\n", strings.Join(roots, ", "))
+	}
+
+	for _, repo := range repos {
+		if err := git.InstallRepoHooks(ctx, repo); err != nil {
+			fmt.Printf("✗ %s: %v\n", repo, err)
+			failed = append(failed, repo)
+			continue
+		}
+		fmt.Printf("✓ %s\n", repo)
+	}
+
+	if stateMgr, err := state.NewManager(ctx.DataDir); err == nil {
+		git.SetPendingRepos(stateMgr, failed)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to install hooks in %d of %d repos", len(failed), len(repos))
+	}
+	return nil
+}
+
+func gitHooksUninstall(repos []string, all bool) error {
+	if len(repos) == 0 && !all {
+		return fmt.Errorf("give the repos to remove hooks from, or --all")
+	}
+
+	ctx := createInstallContext()
+	if all {
+		hooked, err := git.HookedRepos(ctx.DataDir)
+		if err != nil {
+			return err
+		}
+		repos = hooked
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if modCfg, ok := cfg.GetModuleConfig("git"); ok && modCfg != nil {
+			modCfg["hook_roots"] = []interface{}{}
+			cfg.SetModuleConfig("git", modCfg)
+			if err := cfg.Save(); err != nil {
+				return fmt.Errorf("save config: %w", err)
+			}
+		}
+		if stateMgr, err := state.NewManager(ctx.DataDir); err == nil {
+			git.SetPendingRepos(stateMgr, nil)
+		}
+	}
+
+	var failed int
+	for _, repo := range repos {
+		repo = git.ExpandRoot(repo, ctx.HomeDir)
+		if err := git.UninstallRepoHooks(ctx, repo); err != nil {
+			fmt.Printf("✗ %s: %v\n", repo, err)
+			failed++
+			continue
+		}
+		fmt.Printf("✓ Removed hooks from %s\n", repo)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to remove hooks from %d repos", failed)
+	}
+	return nil
+}
+
+// splitAllRepos takes --all-repos DIR out of install arguments. urfave/cli
+// stops parsing flags at the first module name, so 'devlog module install
+// git --all-repos ~/src' leaves the flag among the arguments.
+func splitAllRepos(args []string) ([]string, string, error) {
+	var names []string
+	var root string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--all-repos" || arg == "-all-repos":
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("--all-repos needs a directory")
+			}
+			root = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--all-repos="):
+			root = strings.TrimPrefix(arg, "--all-repos=")
+		default:
+			names = append(names, arg)
+		}
+	}
+	return names, root, nil
+}
@@ -6,6 +6,9 @@ import (
 	_ "devlog/modules/git"
 	_ "devlog/modules/kubectl"
 	_ "devlog/modules/shell"
+	_ "devlog/modules/ssh"
+	_ "devlog/modules/terraform"
+	_ "devlog/modules/tests"
 	_ "devlog/modules/tmux"
 
 	"github.com/urfave/cli/v2"
@@ -16,6 +19,6 @@ func IngestCommand() *cli.Command {
 		Name:        "ingest",
 		Usage:       "Manually ingest an event (developer/debug command)",
 		Hidden:      true,
-		Subcommands: ingest.GetCommands(),
+		Subcommands: append(ingest.GetCommands(), IngestStdinCommand()),
 	}
 }
@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/ingest"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+)
+
+const maxNDJSONLineSize = 1 << 20
+
+func IngestStdinCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "stdin",
+		Usage: "Ingest newline-delimited JSON events from stdin",
+		Description: `Reads one event per line. Missing "v", "id" and "timestamp" fields are filled in,
+so a minimal line looks like:
+
+  {"source":"manual","type":"note","payload":{"text":"deployed v2"}}`,
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "batch-size",
+				Usage: "Events sent per request to the daemon",
+				Value: 100,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Validate events without ingesting them",
+			},
+		},
+		Action: ingestStdinAction,
+	}
+}
+
+type ndjsonLineError struct {
+	Line int
+	Err  error
+}
+
+func (e ndjsonLineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func readNDJSONEvents(r io.Reader) ([]*events.Event, []ndjsonLineError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineSize)
+
+	var evts []*events.Event
+	var invalid []ndjsonLineError
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		event, err := events.FromJSON([]byte(line))
+		if err != nil {
+			invalid = append(invalid, ndjsonLineError{Line: lineNum, Err: err})
+			continue
+		}
+
+		if event.Version == 0 {
+			event.Version = 1
+		}
+		if event.ID == "" {
+			event.ID = uuid.New().String()
+		}
+		if event.Timestamp == "" {
+			event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+		}
+		if event.Payload == nil {
+			event.Payload = make(map[string]interface{})
+		}
+
+		if err := event.Validate(); err != nil {
+			invalid = append(invalid, ndjsonLineError{Line: lineNum, Err: err})
+			continue
+		}
+
+		evts = append(evts, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return evts, invalid, fmt.Errorf("read stdin: %w", err)
+	}
+
+	return evts, invalid, nil
+}
+
+func ingestStdinAction(c *cli.Context) error {
+	batchSize := c.Int("batch-size")
+	if batchSize < 1 {
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+
+	evts, invalid, err := readNDJSONEvents(c.App.Reader)
+	if err != nil {
+		return err
+	}
+
+	for _, lineErr := range invalid {
+		fmt.Fprintf(c.App.ErrWriter, "skipping %s\n", lineErr.Error())
+	}
+
+	if c.Bool("dry-run") {
+		fmt.Fprintf(c.App.Writer, "%d valid, %d invalid\n", len(evts), len(invalid))
+		if len(invalid) > 0 {
+			return fmt.Errorf("%d invalid events", len(invalid))
+		}
+		return nil
+	}
+
+	total := &ingest.BatchResult{}
+	for start := 0; start < len(evts); start += batchSize {
+		end := min(start+batchSize, len(evts))
+
+		result, err := ingest.SendEvents(evts[start:end])
+		if result != nil {
+			total.Ingested += result.Ingested
+			total.Filtered += result.Filtered
+			total.Duplicates += result.Duplicates
+			total.Queued += result.Queued
+			total.Errors = append(total.Errors, result.Errors...)
+		}
+		if err != nil {
+			return fmt.Errorf("send events: %w", err)
+		}
+	}
+
+	for _, msg := range total.Errors {
+		fmt.Fprintf(c.App.ErrWriter, "rejected %s\n", msg)
+	}
+
+	fmt.Fprintf(c.App.Writer, "Ingested %d, queued %d, filtered %d, duplicates %d, invalid %d\n",
+		total.Ingested, total.Queued, total.Filtered, total.Duplicates, len(invalid)+len(total.Errors))
+
+	if len(invalid) > 0 || len(total.Errors) > 0 {
+		return fmt.Errorf("%d events were not ingested", len(invalid)+len(total.Errors))
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"devlog/internal/ingest"
@@ -97,3 +98,40 @@ func TestFindGitRepoNestedRepos(t *testing.T) {
 		}
 	})
 }
+
+func TestReadNDJSONEvents(t *testing.T) {
+	input := strings.Join([]string{
+		`{"source":"manual","type":"note","payload":{"text":"minimal"}}`,
+		``,
+		`{"v":1,"id":"5f2b6a3e-3c1d-4b8e-9a57-1e0f3c2d4b6a","timestamp":"2025-01-15T10:00:00Z","source":"git","type":"commit","repo":"devlog","payload":{"hash":"abc"}}`,
+		`not json`,
+		`{"source":"nope","type":"note","payload":{}}`,
+		`{"source":"shell","type":"command"}`,
+	}, "\n")
+
+	evts, invalid, err := readNDJSONEvents(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readNDJSONEvents() error: %v", err)
+	}
+
+	if len(evts) != 3 {
+		t.Fatalf("got %d valid events, want 3", len(evts))
+	}
+	if len(invalid) != 2 {
+		t.Fatalf("got %d invalid lines, want 2: %v", len(invalid), invalid)
+	}
+	if invalid[0].Line != 4 || invalid[1].Line != 5 {
+		t.Errorf("got invalid lines %d and %d, want 4 and 5", invalid[0].Line, invalid[1].Line)
+	}
+
+	minimal := evts[0]
+	if minimal.Version != 1 || minimal.ID == "" || minimal.Timestamp == "" {
+		t.Errorf("defaults not filled: %+v", minimal)
+	}
+	if evts[1].ID != "5f2b6a3e-3c1d-4b8e-9a57-1e0f3c2d4b6a" || evts[1].Timestamp != "2025-01-15T10:00:00Z" {
+		t.Errorf("explicit fields overwritten: %+v", evts[1])
+	}
+	if evts[2].Payload == nil {
+		t.Error("missing payload should default to empty map")
+	}
+}
@@ -15,13 +15,19 @@ func InitCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "init",
 		Usage: "Initialize devlog configuration and database",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "encrypt",
+				Usage: "Encrypt event payloads with a key stored in the OS keychain",
+			},
+		},
 		Action: func(c *cli.Context) error {
-			return Init()
+			return Init(c.Bool("encrypt"))
 		},
 	}
 }
 
-func Init() error {
+func Init(encrypt bool) error {
 	fmt.Println("Initializing devlog...")
 
 	if err := config.InitConfig(); err != nil {
@@ -38,6 +44,12 @@ func Init() error {
 		return err
 	}
 
+	if encrypt {
+		if err := withEventStore(enablePayloadEncryption); err != nil {
+			return fmt.Errorf("enable encryption: %w", err)
+		}
+	}
+
 	fmt.Println("\nInitialization complete!")
 	fmt.Println("\nNext steps:")
 	fmt.Println("  1. Install modules to enable event capture:")
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/output"
+	"devlog/internal/storage"
+	"devlog/plugins/issues"
+
+	"github.com/urfave/cli/v2"
+)
+
+func IssuesCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "issues",
+		Usage: "Show work grouped by Jira and Linear issue",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the issues worked on recently",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "since",
+						Value: "7d",
+						Usage: "How far back to look (e.g. '24h', '7d')",
+					},
+				},
+				Action: issuesListAction,
+			},
+			{
+				Name:      "show",
+				Usage:     "Show the events linked to an issue",
+				ArgsUsage: "KEY",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:    "number",
+						Aliases: []string{"n"},
+						Value:   20,
+						Usage:   "Number of events to display",
+					},
+				},
+				Action: issuesShowAction,
+			},
+		},
+	}
+}
+
+func openIssuesStore() (storage.Store, *issues.Cache, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if !cfg.IsPluginEnabled("issues") {
+		return nil, nil, fmt.Errorf("issues plugin is not enabled (run 'devlog plugin install issues' first)")
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get data directory: %w", err)
+	}
+
+	cache, err := issues.LoadCache(issues.CachePath(dataDir))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	store, err := storage.Open(dataDir, cfg.Storage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open storage: %w", err)
+	}
+	return store, cache, nil
+}
+
+func issuesListAction(c *cli.Context) error {
+	since, err := parseDuration(c.String("since"))
+	if err != nil {
+		return fmt.Errorf("invalid since duration: %w", err)
+	}
+
+	store, cache, err := openIssuesStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	start := time.Now().Add(-since)
+	evts, err := store.QueryEventsContext(context.Background(), storage.QueryOptions{StartTime: &start})
+	if err != nil {
+		return fmt.Errorf("query events: %w", err)
+	}
+
+	activity := issues.Group(evts)
+	if len(activity) == 0 {
+		fmt.Printf("No issues found in events since %s\n", start.Local().Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	for _, a := range activity {
+		title, status := a.Title, ""
+		if issue, ok := cache.Get(a.Key); ok {
+			if issue.Title != "" {
+				title = issue.Title
+			}
+			if issue.Status != "" {
+				status = " [" + issue.Status + "]"
+			}
+		}
+		fmt.Printf("%-12s %s%s\n", a.Key, title, status)
+		fmt.Printf("             %d events, last %s", a.Events, a.Last.Local().Format("2006-01-02 15:04"))
+		if len(a.Repos) > 0 {
+			fmt.Printf(", %s", strings.Join(a.Repos, ", "))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func issuesShowAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf("usage: devlog issues show KEY")
+	}
+	key := strings.ToUpper(c.Args().First())
+
+	store, cache, err := openIssuesStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if issue, ok := cache.Get(key); ok && !issue.Missing {
+		fmt.Printf("%s %s\n", key, issue.Title)
+		if issue.Status != "" {
+			fmt.Printf("Status: %s\n", issue.Status)
+		}
+		if issue.URL != "" {
+			fmt.Printf("URL:    %s\n", issue.URL)
+		}
+	} else {
+		fmt.Println(key)
+	}
+	fmt.Println()
+
+	results, err := store.Search(context.Background(), storage.SearchOptions{
+		Query:         "*",
+		Limit:         c.Int("number"),
+		SortOrder:     storage.SortByTimeDesc,
+		PayloadFilter: &storage.PayloadFilter{JSONPath: "$.issue", Value: key},
+	})
+	if err != nil {
+		return fmt.Errorf("search events: %w", err)
+	}
+	return output.NewSearchPresenter(os.Stdout, output.FormatTable).Present(context.Background(), results, key)
+}
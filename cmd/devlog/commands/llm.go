@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+func LLMCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "llm",
+		Usage: "Inspect LLM provider usage and prompts",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "usage",
+				Usage: "Show daily token usage and estimated spend per plugin",
+				Description: "Costs are estimated from each model's list price per million tokens.\n" +
+					"   Local ollama models cost nothing; models without a known price show $0.",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "days",
+						Usage: "Number of days to show, including today",
+						Value: 7,
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: table or json",
+						Value: "table",
+					},
+				},
+				Action: llmUsageAction,
+			},
+			llmPromptsCommand(),
+		},
+	}
+}
+
+func llmUsageAction(c *cli.Context) error {
+	days := c.Int("days")
+	if days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	format := c.String("format")
+	if format != "table" && format != "json" {
+		return fmt.Errorf("unknown format %q (use table or json)", format)
+	}
+
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	start := end.AddDate(0, 0, -days)
+
+	return withEventStore(func(store *storage.Storage) error {
+		usage, err := store.LLMUsageByDayContext(context.Background(), start, end)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			return writeLLMUsageJSON(os.Stdout, usage)
+		}
+		writeLLMUsageTable(os.Stdout, usage)
+		return nil
+	})
+}
+
+func writeLLMUsageTable(w io.Writer, usage []storage.LLMDailyUsage) {
+	if len(usage) == 0 {
+		fmt.Fprintln(w, "No LLM usage recorded in this period.")
+		return
+	}
+
+	fmt.Fprintf(w, "%-10s  %-12s  %6s  %12s  %12s  %10s\n", "DATE", "PLUGIN", "CALLS", "INPUT", "OUTPUT", "COST")
+
+	var total storage.LLMDailyUsage
+	var dayCost float64
+	var dayRows int
+	for i, u := range usage {
+		date := ""
+		if i == 0 || !usage[i-1].Date.Equal(u.Date) {
+			date = u.Date.Format("2006-01-02")
+			dayCost, dayRows = 0, 0
+		}
+		fmt.Fprintf(w, "%-10s  %-12s  %6d  %12d  %12d  %10s\n",
+			date, u.Caller, u.Completions, u.InputTokens, u.OutputTokens, formatUSD(u.CostUSD))
+
+		dayCost += u.CostUSD
+		dayRows++
+		total.Completions += u.Completions
+		total.InputTokens += u.InputTokens
+		total.OutputTokens += u.OutputTokens
+		total.CostUSD += u.CostUSD
+
+		lastOfDay := i == len(usage)-1 || !usage[i+1].Date.Equal(u.Date)
+		if lastOfDay && dayRows > 1 {
+			fmt.Fprintf(w, "%-10s  %-12s  %6s  %12s  %12s  %10s\n", "", "(day total)", "", "", "", formatUSD(dayCost))
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-10s  %-12s  %6d  %12d  %12d  %10s\n",
+		"TOTAL", "", total.Completions, total.InputTokens, total.OutputTokens, formatUSD(total.CostUSD))
+}
+
+func formatUSD(amount float64) string {
+	if amount > 0 && amount < 1 {
+		return fmt.Sprintf("$%.4f", amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+type llmUsageJSON struct {
+	Date         string  `json:"date"`
+	Plugin       string  `json:"plugin"`
+	Completions  int     `json:"completions"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+func writeLLMUsageJSON(w io.Writer, usage []storage.LLMDailyUsage) error {
+	rows := make([]llmUsageJSON, len(usage))
+	for i, u := range usage {
+		rows[i] = llmUsageJSON{
+			Date:         u.Date.Format("2006-01-02"),
+			Plugin:       u.Caller,
+			Completions:  u.Completions,
+			InputTokens:  u.InputTokens,
+			OutputTokens: u.OutputTokens,
+			CostUSD:      u.CostUSD,
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
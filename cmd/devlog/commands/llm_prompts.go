@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"devlog/internal/prompts"
+
+	"github.com/urfave/cli/v2"
+)
+
+func llmPromptsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "prompts",
+		Usage: "List, export and show the summarizer and query prompt templates",
+		Description: "Pick a prompt with 'prompt' in the summarizer or query plugin config: concise,\n" +
+			"   detailed or custom:<path>. Exported prompts under ~/.config/devlog/prompts\n" +
+			"   replace the built-in ones of the same name and can be edited freely.",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List the built-in prompts and whether they are overridden",
+				Action: llmPromptsListAction,
+			},
+			{
+				Name:  "export",
+				Usage: "Write the built-in prompts to ~/.config/devlog/prompts for editing",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Overwrite prompts that were already exported",
+					},
+				},
+				Action: llmPromptsExportAction,
+			},
+			{
+				Name:      "show",
+				Usage:     "Print the prompt template in use for a plugin",
+				ArgsUsage: "<summarizer|query> <concise|detailed>",
+				Action:    llmPromptsShowAction,
+			},
+		},
+	}
+}
+
+func llmPromptsListAction(c *cli.Context) error {
+	defaults, err := prompts.Defaults()
+	if err != nil {
+		return err
+	}
+	return writePromptList(os.Stdout, defaults)
+}
+
+func writePromptList(w io.Writer, defaults []prompts.Prompt) error {
+	fmt.Fprintf(w, "%-12s  %-10s  %-8s  %s\n", "PLUGIN", "NAME", "VERSION", "SOURCE")
+	for _, p := range defaults {
+		override, err := prompts.OverridePath(p.Plugin, p.Name)
+		if err != nil {
+			return err
+		}
+
+		source := "built-in"
+		if data, err := os.ReadFile(override); err == nil {
+			source = override
+			if v := prompts.Version(string(data)); v > 0 && v < p.Version {
+				source += fmt.Sprintf(" (exported from v%d, run 'devlog llm prompts export --force' to update)", v)
+			}
+		}
+		fmt.Fprintf(w, "%-12s  %-10s  %-8s  %s\n", p.Plugin, p.Name, fmt.Sprintf("v%d", p.Version), source)
+	}
+	return nil
+}
+
+func llmPromptsExportAction(c *cli.Context) error {
+	dir, err := prompts.Dir()
+	if err != nil {
+		return err
+	}
+
+	written, err := prompts.Export(dir, c.Bool("force"))
+	if err != nil {
+		return err
+	}
+	if len(written) == 0 {
+		fmt.Printf("All prompts are already exported to %s (use --force to overwrite)\n", dir)
+		return nil
+	}
+	for _, file := range written {
+		fmt.Printf("✓ Wrote %s\n", file)
+	}
+	fmt.Println("\nEdited prompts are used the next time the summarizer or query runs.")
+	return nil
+}
+
+func llmPromptsShowAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf("usage: devlog llm prompts show <summarizer|query> <concise|detailed>")
+	}
+	plugin, name := c.Args().Get(0), c.Args().Get(1)
+
+	builtin, err := prompts.Default(plugin, name)
+	if err != nil {
+		return err
+	}
+
+	override, err := prompts.OverridePath(plugin, name)
+	if err != nil {
+		return err
+	}
+	if data, err := os.ReadFile(override); err == nil {
+		fmt.Fprintf(os.Stderr, "# %s\n", override)
+		fmt.Print(string(data))
+		return nil
+	}
+	fmt.Print(builtin.Text)
+	return nil
+}
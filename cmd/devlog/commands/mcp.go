@@ -0,0 +1,302 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/mcp"
+	"devlog/internal/output"
+	"devlog/internal/services"
+	"devlog/internal/storage"
+	queryPlugin "devlog/plugins/query"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	mcpDefaultLimit = 50
+	mcpMaxLimit     = 200
+)
+
+const mcpInstructions = "devlog records the user's development activity: shell commands, git commits, " +
+	"file edits, notes and periodic LLM summaries. Use recent_events or summaries to learn what the " +
+	"user has been working on, and search or query to find specific past work."
+
+func MCPCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "mcp",
+		Usage: "Expose your devlog history to MCP clients",
+		Subcommands: []*cli.Command{
+			{
+				Name:        "serve",
+				Usage:       "Run an MCP server on stdin/stdout",
+				Description: "Speaks the Model Context Protocol over stdio so editors and coding agents can search\n   your history. Register it with your client as the command 'devlog mcp serve'.",
+				Action: func(c *cli.Context) error {
+					cfg, err := config.Load()
+					if err != nil {
+						return err
+					}
+					dataDir, err := config.DataDir()
+					if err != nil {
+						return err
+					}
+					store, err := storage.OpenConfigured(dataDir)
+					if err != nil {
+						return err
+					}
+					defer store.Close()
+
+					ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+					defer stop()
+
+					return newMCPServer(store, cfg).Serve(ctx, os.Stdin, os.Stdout)
+				},
+			},
+		},
+	}
+}
+
+func newMCPServer(store storage.Store, cfg *config.Config) *mcp.Server {
+	eventService := services.NewEventService(store, func() *config.Config { return cfg }, nil)
+	srv := mcp.NewServer("devlog", Version, mcpInstructions)
+
+	srv.AddTool(mcp.Tool{
+		Name:        "search",
+		Description: "Full-text search over recorded events and summaries. Returns matching events newest first.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"query":   map[string]any{"type": "string", "description": "Keywords to match, e.g. \"migration sqlite\""},
+				"scope":   map[string]any{"type": "string", "enum": []string{"events", "summaries", "all"}, "description": "What to search (default events)"},
+				"since":   map[string]any{"type": "string", "description": "Only results newer than this duration, e.g. 2h or 7d"},
+				"modules": map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "Only these sources, e.g. git, shell"},
+				"repo":    map[string]any{"type": "string", "description": "Substring of the repository path"},
+				"limit":   map[string]any{"type": "integer", "description": "Maximum results (default 50, max 200)"},
+			},
+			"required": []string{"query"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Query   string   `json:"query"`
+				Scope   string   `json:"scope"`
+				Since   string   `json:"since"`
+				Modules []string `json:"modules"`
+				Repo    string   `json:"repo"`
+				Limit   int      `json:"limit"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+			if strings.TrimSpace(args.Query) == "" {
+				return "", fmt.Errorf("query is required")
+			}
+			opts := storage.SearchOptions{
+				Query:       args.Query,
+				Limit:       mcpLimit(args.Limit),
+				Modules:     args.Modules,
+				RepoPattern: args.Repo,
+				SortOrder:   storage.SortByTimeDesc,
+			}
+			scope, err := storage.ParseSearchScope(args.Scope)
+			if err != nil {
+				return "", err
+			}
+			opts.Scope = scope
+			if args.Since != "" {
+				after, err := mcpSince(args.Since)
+				if err != nil {
+					return "", err
+				}
+				opts.After = &after
+			}
+			return mcpSearch(ctx, eventService, opts)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name: "query",
+		Description: "Structured query using devlog's query syntax, e.g. " +
+			"'repo:devlog type:commit since:3d keyword:\"fts5\"'. Keys: keyword, repo, branch, source, type, since, until, from, to, limit, sort, scope.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"dsl": map[string]any{"type": "string", "description": "The query, e.g. repo:devlog type:commit since:3d"},
+			},
+			"required": []string{"dsl"},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				DSL string `json:"dsl"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+			opts, err := queryPlugin.ParseDSL(args.DSL, time.Now())
+			if err != nil {
+				return "", fmt.Errorf("invalid query: %w", err)
+			}
+			return mcpSearch(ctx, eventService, opts)
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "recent_events",
+		Description: "The most recent events, newest first. Use this to see what the user is working on right now.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"since":  map[string]any{"type": "string", "description": "How far back to look (default 2h), e.g. 30m or 1d"},
+				"source": map[string]any{"type": "string", "description": "Only this source, e.g. git or shell"},
+				"repo":   map[string]any{"type": "string", "description": "Substring of the repository path"},
+				"limit":  map[string]any{"type": "integer", "description": "Maximum events (default 50, max 200)"},
+			},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Since  string `json:"since"`
+				Source string `json:"source"`
+				Repo   string `json:"repo"`
+				Limit  int    `json:"limit"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+			if args.Since == "" {
+				args.Since = "2h"
+			}
+			start, err := mcpSince(args.Since)
+			if err != nil {
+				return "", err
+			}
+			return mcpRecentEvents(ctx, eventService, storage.QueryOptions{
+				StartTime:   &start,
+				Source:      args.Source,
+				RepoPattern: args.Repo,
+				Limit:       mcpLimit(args.Limit),
+			})
+		},
+	})
+
+	srv.AddTool(mcp.Tool{
+		Name:        "summaries",
+		Description: "The periodic LLM summaries of the user's work for one day, in time order.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"day": map[string]any{"type": "string", "description": "YYYY-MM-DD, today, or yesterday (default today)"},
+			},
+		},
+		Handler: func(ctx context.Context, raw json.RawMessage) (string, error) {
+			var args struct {
+				Day string `json:"day"`
+			}
+			if err := json.Unmarshal(raw, &args); err != nil {
+				return "", fmt.Errorf("invalid arguments: %w", err)
+			}
+			if args.Day == "" {
+				args.Day = "today"
+			}
+			day, err := parseDay(args.Day)
+			if err != nil {
+				return "", err
+			}
+			return mcpSummaries(ctx, eventService, day)
+		},
+	})
+
+	srv.AddResource(mcp.Resource{
+		URI:         "devlog://summaries/today",
+		Name:        "Today's summaries",
+		Description: "Summaries of today's work so far",
+		MIMEType:    "text/markdown",
+		Read: func(ctx context.Context) (string, error) {
+			day, _ := parseDay("today")
+			return mcpSummaries(ctx, eventService, day)
+		},
+	})
+
+	srv.AddResource(mcp.Resource{
+		URI:         "devlog://events/recent",
+		Name:        "Recent events",
+		Description: "Events from the last two hours",
+		MIMEType:    "text/plain",
+		Read: func(ctx context.Context) (string, error) {
+			start := time.Now().Add(-2 * time.Hour)
+			return mcpRecentEvents(ctx, eventService, storage.QueryOptions{StartTime: &start, Limit: mcpMaxLimit})
+		},
+	})
+
+	return srv
+}
+
+func mcpLimit(limit int) int {
+	if limit <= 0 {
+		return mcpDefaultLimit
+	}
+	if limit > mcpMaxLimit {
+		return mcpMaxLimit
+	}
+	return limit
+}
+
+func mcpSince(since string) (time.Time, error) {
+	d, err := parseDuration(since)
+	if err != nil || d <= 0 {
+		return time.Time{}, fmt.Errorf("invalid since %q: use a duration like 30m, 2h or 7d", since)
+	}
+	return time.Now().Add(-d), nil
+}
+
+func mcpSearch(ctx context.Context, eventService *services.EventService, opts storage.SearchOptions) (string, error) {
+	results, err := eventService.SearchEvents(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(results) == 0 {
+		return "No matching events.", nil
+	}
+	return output.NewSimpleFormatter().Format(ctx, results, opts.Query)
+}
+
+func mcpRecentEvents(ctx context.Context, eventService *services.EventService, opts storage.QueryOptions) (string, error) {
+	evts, err := eventService.GetEvents(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	if len(evts) == 0 {
+		return "No events recorded in this period.", nil
+	}
+	lines := make([]string, 0, len(evts))
+	for _, evt := range evts {
+		lines = append(lines, strings.TrimPrefix(output.FormatEventLine(evt, 200, 300, 300, 200), "\n"))
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func mcpSummaries(ctx context.Context, eventService *services.EventService, day time.Time) (string, error) {
+	summaries, err := eventService.GetSummaries(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return "", err
+	}
+	if len(summaries) == 0 {
+		return fmt.Sprintf("No summaries for %s.", day.Format("2006-01-02")), nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Summaries for %s\n", day.Format("Monday, January 2, 2006"))
+	for _, s := range summaries {
+		fmt.Fprintf(&sb, "\n## %s - %s", s.PeriodStart.Local().Format("15:04"), s.PeriodEnd.Local().Format("15:04"))
+		if len(s.Repos) > 0 {
+			fmt.Fprintf(&sb, " (%s)", strings.Join(s.Repos, ", "))
+		}
+		fmt.Fprintf(&sb, "\n\n%s\n", strings.TrimSpace(s.Text))
+	}
+	return sb.String(), nil
+}
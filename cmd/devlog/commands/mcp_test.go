@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/storage"
+)
+
+func TestMCPServerTools(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "events.db")
+	if err := storage.InitDB(dbPath); err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.New(dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	commit := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	commit.Repo = "devlog"
+	commit.Payload["message"] = "add fts5 search index"
+	if err := store.InsertEvent(commit); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	if err := store.InsertSummaryContext(ctx, &storage.Summary{
+		PeriodStart:  now.Add(-30 * time.Minute),
+		PeriodEnd:    now,
+		ContextStart: now.Add(-time.Hour),
+		Repos:        []string{"devlog"},
+		Text:         "Worked on the search index.",
+		EventCount:   1,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := newMCPServer(store, config.DefaultConfig())
+	requests := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search","arguments":{"query":"fts5"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"query","arguments":{"dsl":"repo:devlog type:commit since:1d"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"recent_events","arguments":{"since":"1h"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"summaries","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"recent_events","arguments":{"since":"soon"}}}`,
+	}
+	var out strings.Builder
+	if err := srv.Serve(ctx, strings.NewReader(strings.Join(requests, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	type toolResponse struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			IsError bool `json:"isError"`
+		} `json:"result"`
+	}
+	var results []toolResponse
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r toolResponse
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, r)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("got %d responses, want %d", len(results), len(requests))
+	}
+
+	for i, want := range []string{"add fts5 search index", "add fts5 search index", "add fts5 search index", "Worked on the search index."} {
+		res := results[i].Result
+		if res.IsError || len(res.Content) == 0 || !strings.Contains(res.Content[0].Text, want) {
+			t.Errorf("request %d: got %+v, want text containing %q", i+1, res, want)
+		}
+	}
+	if !results[4].Result.IsError {
+		t.Error("invalid since should be reported as a tool error")
+	}
+}
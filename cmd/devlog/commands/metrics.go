@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+func MetricsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "metrics",
+		Usage: "Export activity metrics for your own dashboards",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Export per-day event counts, active hours and failures",
+				Description: "Each row is one calendar day in local time: total events, hours with any activity,\n" +
+					"   minutes at the keyboard (needs the activity module), events whose exit_code was\n" +
+					"   non-zero, and one events column per source.",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "daily",
+						Usage: "Aggregate by day (the only supported granularity)",
+						Value: true,
+					},
+					&cli.StringFlag{
+						Name:  "range",
+						Usage: "How far back to export (e.g. 30d, 180d)",
+						Value: "30d",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Usage: "Output format: csv or json",
+						Value: "csv",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write to a file instead of stdout",
+					},
+				},
+				Action: metricsExportAction,
+			},
+		},
+	}
+}
+
+func metricsExportAction(c *cli.Context) error {
+	if !c.Bool("daily") {
+		return fmt.Errorf("only daily aggregation is supported")
+	}
+
+	format := c.String("format")
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unknown format %q (use csv or json)", format)
+	}
+
+	span, err := parseDuration(c.String("range"))
+	if err != nil {
+		return fmt.Errorf("invalid --range: %w", err)
+	}
+	if span <= 0 {
+		return fmt.Errorf("--range must be positive")
+	}
+
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	days := int((span + 24*time.Hour - 1) / (24 * time.Hour))
+	start := end.AddDate(0, 0, -days)
+
+	return withEventStore(func(store *storage.Storage) error {
+		metrics, err := store.DailyMetricsContext(context.Background(), start, end)
+		if err != nil {
+			return err
+		}
+
+		w := io.Writer(os.Stdout)
+		if path := c.String("output"); path != "" {
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("create output file: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if format == "json" {
+			return writeDailyMetricsJSON(w, metrics)
+		}
+		return writeDailyMetricsCSV(w, metrics)
+	})
+}
+
+func metricSources(metrics []storage.DailyMetric) []string {
+	seen := make(map[string]bool)
+	var sources []string
+	for _, m := range metrics {
+		for source := range m.BySource {
+			if !seen[source] {
+				seen[source] = true
+				sources = append(sources, source)
+			}
+		}
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func writeDailyMetricsCSV(w io.Writer, metrics []storage.DailyMetric) error {
+	sources := metricSources(metrics)
+
+	cw := csv.NewWriter(w)
+	header := []string{"date", "events", "active_hours", "active_minutes", "failures"}
+	for _, source := range sources {
+		header = append(header, "events_"+source)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, m := range metrics {
+		row := []string{
+			m.Date.Format("2006-01-02"),
+			strconv.Itoa(m.Events),
+			strconv.Itoa(m.ActiveHours),
+			strconv.Itoa(m.ActiveMinutes),
+			strconv.Itoa(m.Failures),
+		}
+		for _, source := range sources {
+			row = append(row, strconv.Itoa(m.BySource[source]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+type dailyMetricJSON struct {
+	Date          string         `json:"date"`
+	Events        int            `json:"events"`
+	ActiveHours   int            `json:"active_hours"`
+	ActiveMinutes int            `json:"active_minutes"`
+	Failures      int            `json:"failures"`
+	BySource      map[string]int `json:"by_source"`
+}
+
+func writeDailyMetricsJSON(w io.Writer, metrics []storage.DailyMetric) error {
+	rows := make([]dailyMetricJSON, len(metrics))
+	for i, m := range metrics {
+		rows[i] = dailyMetricJSON{
+			Date:          m.Date.Format("2006-01-02"),
+			Events:        m.Events,
+			ActiveHours:   m.ActiveHours,
+			ActiveMinutes: m.ActiveMinutes,
+			Failures:      m.Failures,
+			BySource:      m.BySource,
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
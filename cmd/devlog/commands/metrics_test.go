@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+func TestWriteDailyMetricsCSV(t *testing.T) {
+	day := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	metrics := []storage.DailyMetric{
+		{Date: day, Events: 4, ActiveHours: 2, ActiveMinutes: 95, Failures: 1, BySource: map[string]int{"shell": 3, "git": 1}},
+		{Date: day.AddDate(0, 0, 1), BySource: map[string]int{}},
+		{Date: day.AddDate(0, 0, 2), Events: 2, ActiveHours: 1, BySource: map[string]int{"tmux": 2}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeDailyMetricsCSV(&buf, metrics); err != nil {
+		t.Fatalf("writeDailyMetricsCSV() error: %v", err)
+	}
+
+	want := "date,events,active_hours,active_minutes,failures,events_git,events_shell,events_tmux\n" +
+		"2025-05-19,4,2,95,1,1,3,0\n" +
+		"2025-05-20,0,0,0,0,0,0,0\n" +
+		"2025-05-21,2,1,0,0,0,0,2\n"
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteLLMUsageTable(t *testing.T) {
+	day := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	usage := []storage.LLMDailyUsage{
+		{Date: day, Caller: "summarizer", Completions: 4, InputTokens: 8000, OutputTokens: 800, CostUSD: 0.012},
+		{Date: day, Caller: "query", Completions: 1, InputTokens: 1000, OutputTokens: 100, CostUSD: 0.0015},
+		{Date: day.AddDate(0, 0, 1), Caller: "summarizer", Completions: 2, InputTokens: 4000, OutputTokens: 400, CostUSD: 0.006},
+	}
+
+	var buf bytes.Buffer
+	writeLLMUsageTable(&buf, usage)
+	out := buf.String()
+
+	for _, want := range []string{"2025-05-19", "(day total)", "$0.0135", "2025-05-20", "TOTAL", "13000", "$0.0195"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "(day total)") != 1 {
+		t.Errorf("want a day total only for days with several plugins:\n%s", out)
+	}
+}
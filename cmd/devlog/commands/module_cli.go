@@ -1,22 +1,263 @@
 package commands
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"devlog/internal/assets"
 	"devlog/internal/config"
+	"devlog/internal/daemon"
 	"devlog/internal/modules"
+	"devlog/internal/storage"
 
 	"github.com/urfave/cli/v2"
 )
 
 func ModuleCommand() *cli.Command {
-	return createComponentCommandCli(
+	configOpsFunc := func() ComponentConfig {
+		cfg, _ := config.Load()
+		return moduleConfigOps{cfg: cfg}
+	}
+	cmd := createComponentCommandCli(
 		"module",
 		"modules",
 		moduleRegistry{},
-		func() ComponentConfig {
-			cfg, _ := config.Load()
-			return moduleConfigOps{cfg: cfg}
-		},
+		configOpsFunc,
 	)
+
+	for _, sub := range cmd.Subcommands {
+		if sub.Name == "install" {
+			addAllReposFlag(sub, configOpsFunc)
+		}
+	}
+
+	cmd.Subcommands = append(cmd.Subcommands, &cli.Command{
+		Name:  "refresh",
+		Usage: "Rewrite installed hook scripts and wrappers from this devlog binary",
+		Description: "Installed scripts are refreshed automatically when the daemon starts. Files you have\n" +
+			"   edited by hand are skipped unless --force is given.",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Overwrite files that were modified after install",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return moduleRefresh(c.Bool("force"), true)
+		},
+	}, &cli.Command{
+		Name:  "doctor",
+		Usage: "Check that enabled modules' hooks and wrappers are actually installed",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "repair",
+				Usage: "Reinstall modules with repairable problems",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return moduleDoctor(c.Bool("repair"))
+		},
+	}, &cli.Command{
+		Name:  "status",
+		Usage: "Show whether each enabled module's hooks are installed and still recording events",
+		Description: "Combines the doctor checks with live checks, such as whether a wrapper is first on\n" +
+			"   PATH, and the newest event from each module's sources. Modules that usually record\n" +
+			"   often are flagged when they have gone quiet.",
+		Action: func(c *cli.Context) error {
+			return moduleStatus()
+		},
+	}, shellModuleCommand(), ciModuleCommand(), sshModuleCommand(), gitModuleCommand())
+
+	return cmd
+}
+
+// addAllReposFlag lets 'devlog module install git --all-repos DIR' also
+// install repo hooks into every repository under DIR.
+func addAllReposFlag(install *cli.Command, configOpsFunc func() ComponentConfig) {
+	install.ArgsUsage = "<name> [name...] [--all-repos DIR]"
+	install.Flags = append(install.Flags, &cli.StringFlag{
+		Name:  "all-repos",
+		Usage: "With git, also install hooks into every repo under `DIR` and watch it for new clones",
+	})
+
+	action := install.Action
+	install.Action = func(c *cli.Context) error {
+		names, root, err := splitAllRepos(c.Args().Slice())
+		if err != nil {
+			return err
+		}
+		if root == "" {
+			root = c.String("all-repos")
+		}
+		if root == "" {
+			return action(c)
+		}
+
+		hasGit := false
+		for _, name := range names {
+			hasGit = hasGit || name == "git"
+		}
+		if !hasGit {
+			return fmt.Errorf("--all-repos only applies to the git module")
+		}
+
+		if err := componentInstall("module", names, moduleRegistry{}, configOpsFunc()); err != nil {
+			return err
+		}
+		fmt.Println()
+		return gitHooksInstall([]string{root})
+	}
+}
+
+func moduleDoctor(repair bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	ctx := createInstallContext()
+	ctx.Interactive = false
+	ctx.Log = func(format string, args ...interface{}) {}
+
+	issues := daemon.Preflight(cfg, ctx, repair)
+	if len(issues) == 0 {
+		fmt.Println("✓ Installed hooks match the module config")
+		return nil
+	}
+
+	for _, issue := range issues {
+		switch {
+		case issue.Repaired:
+			fmt.Printf("✓ %s: %s (repaired)\n", issue.Module, issue.Message)
+		case issue.Repairable:
+			fmt.Printf("✗ %s: %s (fix with 'devlog module doctor --repair')\n", issue.Module, issue.Message)
+		default:
+			fmt.Printf("✗ %s: %s\n", issue.Module, issue.Message)
+		}
+	}
+	return nil
+}
+
+func moduleStatus() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+	store, err := storage.Open(dataDir, cfg.Storage)
+	if err != nil {
+		return fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	ctx := createInstallContext()
+	ctx.Interactive = false
+	ctx.Log = func(format string, args ...interface{}) {}
+
+	now := time.Now()
+	statuses, err := daemon.ModuleStatuses(context.Background(), cfg, ctx, store, now)
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		fmt.Println("No modules enabled")
+		return nil
+	}
+
+	for _, s := range statuses {
+		mark := "✓"
+		if !s.Healthy() {
+			mark = "✗"
+		}
+
+		var seen string
+		switch {
+		case s.LastEvent.IsZero():
+			seen = "no events recorded"
+		default:
+			seen = fmt.Sprintf("last event %s ago", formatAge(now.Sub(s.LastEvent)))
+			if len(s.Sources) > 1 {
+				seen += fmt.Sprintf(" (%s)", s.LastSource)
+			}
+		}
+		if s.Quiet {
+			seen += fmt.Sprintf(", expected one within %s", formatAge(s.QuietAfter))
+		}
+		fmt.Printf("%s %-10s %s\n", mark, s.Module, seen)
+
+		for _, c := range s.Checks {
+			if c.OK {
+				fmt.Printf("    ✓ %s\n", c.Message)
+			} else {
+				fmt.Printf("    ✗ %s: %s\n", c.Name, c.Message)
+			}
+		}
+		for _, issue := range s.Issues {
+			if issue.Repairable {
+				fmt.Printf("    ✗ %s (fix with 'devlog module doctor --repair')\n", issue.Message)
+			} else {
+				fmt.Printf("    ✗ %s\n", issue.Message)
+			}
+		}
+	}
+	return nil
+}
+
+// formatAge renders d in the largest whole unit: 45s, 12m, 5h or 3d.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func moduleRefresh(force, verbose bool) error {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+
+	results, err := assets.Refresh(dataDir, Version, force)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		if verbose {
+			fmt.Println("No installed module assets are tracked. Reinstall modules with 'devlog module install <name>' to track them.")
+		}
+		return nil
+	}
+
+	for _, r := range results {
+		switch r.Status {
+		case assets.StatusUpdated:
+			fmt.Printf("✓ Updated %s (%s)\n", r.Path, r.Module)
+		case assets.StatusModified:
+			fmt.Printf("Warning: %s was modified locally, skipping (use 'devlog module refresh --force' to overwrite)\n", r.Path)
+		case assets.StatusMissing:
+			if verbose {
+				fmt.Printf("Warning: %s is missing; reinstall with 'devlog module install %s'\n", r.Path, r.Module)
+			}
+		case assets.StatusCurrent, assets.StatusOrphaned:
+			if verbose {
+				fmt.Printf("  %s is %s\n", r.Path, r.Status)
+			}
+		}
+	}
+
+	return nil
 }
 
 type moduleRegistry struct{}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/ingest"
+	"devlog/internal/vcs"
+
+	"github.com/urfave/cli/v2"
+)
+
+func NoteCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "note",
+		Usage:     "Record a journal entry alongside captured activity",
+		ArgsUsage: "<text>",
+		Description: "Stores a manual/note event that shows up in search, the dashboard, and summaries.\n\n" +
+			"   Examples:\n" +
+			"      devlog note \"finished the auth refactor\"\n" +
+			"      devlog note \"chose sqlite over postgres\" --repo . --tags decision\n" +
+			"      devlog note \"blocked on infra review\" -t blocker,infra",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "repo",
+				Usage: "Attach the note to the repository containing this path (e.g., '.')",
+			},
+			&cli.StringSliceFlag{
+				Name:    "tags",
+				Aliases: []string{"t"},
+				Usage:   "Tags for the note (repeatable or comma-separated)",
+			},
+		},
+		Action: noteAction,
+	}
+}
+
+func noteAction(c *cli.Context) error {
+	text := strings.TrimSpace(strings.Join(c.Args().Slice(), " "))
+	if text == "" {
+		return fmt.Errorf("note text is required (e.g., devlog note \"finished the auth refactor\")")
+	}
+
+	event, err := buildNoteEvent(text, c.String("repo"), c.StringSlice("tags"))
+	if err != nil {
+		return err
+	}
+
+	if err := ingest.SendEvent(event); err != nil {
+		return fmt.Errorf("record note: %w", err)
+	}
+
+	fmt.Println("✓ Note recorded")
+	return nil
+}
+
+func buildNoteEvent(text, repoPath string, tags []string) (*events.Event, error) {
+	event := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	event.Payload["text"] = text
+
+	if normalized := normalizeTags(tags); len(normalized) > 0 {
+		event.Payload["tags"] = normalized
+	}
+
+	if repoPath != "" {
+		absPath, err := filepath.Abs(repoPath)
+		if err != nil {
+			return nil, fmt.Errorf("resolve repo path: %w", err)
+		}
+		repo, err := vcs.Detect(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not inside a repository", absPath)
+		}
+		event.Repo = repo.Name
+		event.Branch = repo.Branch
+		event.Payload["workdir"] = repo.Root
+		if repo.Remote != "" {
+			event.Payload[config.RemotePayloadKey] = repo.Remote
+		}
+	}
+
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, tag := range tags {
+		for _, part := range strings.Split(tag, ",") {
+			part = strings.ToLower(strings.TrimSpace(part))
+			if part == "" || seen[part] {
+				continue
+			}
+			seen[part] = true
+			result = append(result, part)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"devlog/internal/config"
+)
+
+func TestBuildNoteEvent(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ".git", "HEAD"), []byte("ref: refs/heads/auth-refactor\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	subDir := filepath.Join(repoDir, "internal")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := buildNoteEvent("finished the auth refactor", subDir, []string{"Decision", "auth, decision"})
+	if err != nil {
+		t.Fatalf("buildNoteEvent() error: %v", err)
+	}
+
+	if event.Source != "manual" || event.Type != "note" {
+		t.Errorf("event = %s/%s, want manual/note", event.Source, event.Type)
+	}
+	if event.Payload["text"] != "finished the auth refactor" {
+		t.Errorf("text = %v", event.Payload["text"])
+	}
+	if got := event.Tags(); !reflect.DeepEqual(got, []string{"decision", "auth"}) {
+		t.Errorf("Tags() = %v, want [decision auth]", got)
+	}
+	if event.Repo != filepath.Base(repoDir) || event.Branch != "auth-refactor" {
+		t.Errorf("repo = %q branch = %q, want %q auth-refactor", event.Repo, event.Branch, filepath.Base(repoDir))
+	}
+	if event.Payload["workdir"] != repoDir {
+		t.Errorf("workdir = %v, want %s", event.Payload["workdir"], repoDir)
+	}
+}
+
+func TestBuildNoteEventRecordsRemote(t *testing.T) {
+	repoDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(repoDir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repoDir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitConfig := "[remote \"origin\"]\n\turl = git@github.com:acme/api.git\n"
+	if err := os.WriteFile(filepath.Join(repoDir, ".git", "config"), []byte(gitConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := buildNoteEvent("picked the queue library", repoDir, nil)
+	if err != nil {
+		t.Fatalf("buildNoteEvent() error: %v", err)
+	}
+	if got := event.Payload[config.RemotePayloadKey]; got != "github.com/acme/api" {
+		t.Errorf("%s = %v, want github.com/acme/api", config.RemotePayloadKey, got)
+	}
+}
+
+func TestBuildNoteEventWithoutRepo(t *testing.T) {
+	event, err := buildNoteEvent("standup notes", "", nil)
+	if err != nil {
+		t.Fatalf("buildNoteEvent() error: %v", err)
+	}
+	if event.Repo != "" {
+		t.Errorf("Repo = %q, want empty", event.Repo)
+	}
+	if _, ok := event.Payload["tags"]; ok {
+		t.Error("payload should not contain tags when none are given")
+	}
+
+	if _, err := buildNoteEvent("outside", t.TempDir(), nil); err == nil {
+		t.Error("buildNoteEvent() expected error for a path outside any repository")
+	}
+}
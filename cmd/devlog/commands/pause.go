@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/daemon"
+	"devlog/internal/pause"
+
+	"github.com/urfave/cli/v2"
+)
+
+func PauseCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "pause",
+		Usage: "Stop capturing events until resumed (do-not-track mode)",
+		Description: "While paused, hooks skip sending events and the daemon drops anything it receives or polls.\n\n" +
+			"   Examples:\n" +
+			"      devlog pause              # until 'devlog resume'\n" +
+			"      devlog pause --for 2h     # resumes automatically",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "for",
+				Usage: "Resume automatically after this long (e.g., '45m', '2h', '1d')",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var duration time.Duration
+			if value := c.String("for"); value != "" {
+				d, err := parseDuration(value)
+				if err != nil {
+					return fmt.Errorf("invalid --for duration: %w", err)
+				}
+				if d <= 0 {
+					return fmt.Errorf("invalid --for duration: must be positive")
+				}
+				duration = d
+			}
+
+			state, err := setPaused(true, duration)
+			if err != nil {
+				return err
+			}
+
+			if state.Until != nil {
+				fmt.Printf("Capture paused until %s\n", state.Until.Local().Format("Jan 2 15:04"))
+			} else {
+				fmt.Println("Capture paused. Run 'devlog resume' to start recording again.")
+			}
+			return nil
+		},
+	}
+}
+
+func ResumeCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "resume",
+		Usage: "Resume capturing events after 'devlog pause'",
+		Action: func(c *cli.Context) error {
+			if _, err := setPaused(false, 0); err != nil {
+				return err
+			}
+			fmt.Println("Capture resumed")
+			return nil
+		},
+	}
+}
+
+func setPaused(paused bool, duration time.Duration) (pause.State, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return pause.State{}, err
+	}
+
+	if daemon.IsRunning() {
+		if state, err := setPausedViaAPI(cfg.HTTP, paused, duration); err == nil {
+			return state, nil
+		}
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return pause.State{}, err
+	}
+	controller := pause.NewController(dataDir)
+	if !paused {
+		return pause.State{}, controller.Resume()
+	}
+	return controller.Pause(duration)
+}
+
+func setPausedViaAPI(httpCfg config.HTTPConfig, paused bool, duration time.Duration) (pause.State, error) {
+	url := httpCfg.LocalURL() + "/api/v1/resume"
+	body := []byte("{}")
+	if paused {
+		url = httpCfg.LocalURL() + "/api/v1/pause"
+		if duration > 0 {
+			body, _ = json.Marshal(map[string]string{"duration": duration.String()})
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return pause.State{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	config.AuthorizeRequest(req)
+
+	resp, err := httpCfg.Client(5 * time.Second).Do(req)
+	if err != nil {
+		return pause.State{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return pause.State{}, fmt.Errorf("daemon returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Paused bool   `json:"paused"`
+		Since  string `json:"since"`
+		Until  string `json:"until"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return pause.State{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	state := pause.State{Paused: result.Paused}
+	state.Since, _ = time.Parse(time.RFC3339, result.Since)
+	if until, err := time.Parse(time.RFC3339, result.Until); err == nil {
+		state.Until = &until
+	}
+	return state, nil
+}
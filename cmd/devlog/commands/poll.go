@@ -2,6 +2,7 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 	"devlog/internal/llm"
 	"devlog/internal/modules"
 	"devlog/internal/storage"
+	llmplugin "devlog/plugins/llm"
 	"devlog/plugins/summarizer"
 
 	"github.com/urfave/cli/v2"
@@ -74,6 +76,9 @@ func pollModule(name string, mod modules.ModuleWithPoller) error {
 	fmt.Printf("Polling %s module...\n\n", name)
 
 	poller, err := mod.CreatePoller(modCfg, dataDir)
+	if errors.Is(err, modules.ErrPollerDisabled) {
+		return fmt.Errorf("%s module polling is disabled in its config", name)
+	}
 	if err != nil {
 		return fmt.Errorf("create poller: %w", err)
 	}
@@ -83,6 +88,18 @@ func pollModule(name string, mod modules.ModuleWithPoller) error {
 		return fmt.Errorf("poll: %w", err)
 	}
 
+	active := events[:0]
+	for _, evt := range events {
+		ts, err := time.Parse(time.RFC3339, evt.Timestamp)
+		if err != nil || cfg.IsModuleActiveAt(name, ts) {
+			active = append(active, evt)
+		}
+	}
+	if skipped := len(events) - len(active); skipped > 0 {
+		fmt.Printf("Skipped %d event(s) outside %s active hours\n", skipped, name)
+	}
+	events = active
+
 	if len(events) == 0 {
 		fmt.Println("No new events found")
 		return nil
@@ -90,8 +107,7 @@ func pollModule(name string, mod modules.ModuleWithPoller) error {
 
 	fmt.Printf("Found %d new events:\n\n", len(events))
 
-	dbPath := filepath.Join(dataDir, "events.db")
-	store, err := storage.New(dbPath)
+	store, err := storage.Open(dataDir, cfg.Storage)
 	if err != nil {
 		return fmt.Errorf("open storage: %w", err)
 	}
@@ -173,8 +189,7 @@ func pollSummarizer() error {
 		return fmt.Errorf("get data directory: %w", err)
 	}
 
-	dbPath := filepath.Join(dataDir, "events.db")
-	store, err := storage.New(dbPath)
+	store, err := storage.Open(dataDir, cfg.Storage)
 	if err != nil {
 		return fmt.Errorf("open storage: %w", err)
 	}
@@ -209,37 +224,16 @@ func pollSummarizer() error {
 		return fmt.Errorf("llm plugin config not found")
 	}
 
-	provider := "ollama"
-	if p, ok := llmCfg["provider"].(string); ok {
-		provider = p
-	}
-
-	apiKey := ""
-	if k, ok := llmCfg["api_key"].(string); ok {
-		apiKey = k
-	}
-
-	baseURL := ""
-	if u, ok := llmCfg["base_url"].(string); ok {
-		baseURL = u
-	}
-
-	model := ""
-	if m, ok := llmCfg["model"].(string); ok {
-		model = m
-	}
-
-	llmConfig := llm.Config{
-		Provider: llm.ProviderType(provider),
-		APIKey:   apiKey,
-		BaseURL:  baseURL,
-		Model:    model,
+	llmConfig, err := llmplugin.ClientConfig(llmCfg)
+	if err != nil {
+		return fmt.Errorf("parse LLM config: %w", err)
 	}
 
 	llmClient, err := llm.NewClient(llmConfig)
 	if err != nil {
 		return fmt.Errorf("create LLM client: %w", err)
 	}
+	llmplugin.TrackUsage()
 
 	interval := time.Duration(intervalMins) * time.Minute
 	contextWindow := time.Duration(contextWindowMins) * time.Minute
@@ -256,7 +250,7 @@ func pollSummarizer() error {
 	}
 
 	fmt.Printf("Configuration:\n")
-	fmt.Printf("  Provider: %s\n", provider)
+	fmt.Printf("  Provider: %s\n", llmConfig.ProviderChain())
 	fmt.Printf("  Interval: %d minutes\n", intervalMins)
 	fmt.Printf("  Context window: %d minutes\n", contextWindowMins)
 	if len(excludeSources) > 0 {
@@ -265,6 +259,13 @@ func pollSummarizer() error {
 	fmt.Println()
 
 	plugin := summarizer.NewForPoll(llmClient, store, interval, contextWindow, excludeSources)
+	if val, ok := pluginCfg["journal"]; ok && val != nil {
+		journalCfg, err := summarizer.ParseJournalConfig(val)
+		if err != nil {
+			return fmt.Errorf("parse journal config: %w", err)
+		}
+		plugin.SetJournal(journalCfg)
+	}
 
 	fmt.Println("Generating summary...")
 	if err := plugin.GenerateSummaryNow(context.Background()); err != nil {
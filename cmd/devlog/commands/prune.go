@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+func PruneCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "prune",
+		Usage: "Delete old events from the local database",
+		Description: "Shows how many events and how much space would be reclaimed, then asks before deleting.\n\n" +
+			"   Examples:\n" +
+			"      devlog prune --source clipboard --older-than 30d --dry-run\n" +
+			"      devlog prune --older-than 180d\n" +
+			"      devlog prune -s shell -s tmux --older-than 90d --yes",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:    "source",
+				Aliases: []string{"s"},
+				Usage:   "Only prune events from these sources (repeatable; default all)",
+			},
+			&cli.StringFlag{
+				Name:     "older-than",
+				Usage:    "Prune events older than this (e.g., '30d', '720h')",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would be deleted without deleting",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Skip the confirmation prompt",
+			},
+			&cli.BoolFlag{
+				Name:  "no-vacuum",
+				Usage: "Skip VACUUM after deleting (space is reused but the file does not shrink)",
+			},
+		},
+		Action: pruneAction,
+	}
+}
+
+func pruneAction(c *cli.Context) error {
+	age, err := parseDuration(c.String("older-than"))
+	if err != nil {
+		return fmt.Errorf("invalid older-than duration: %w", err)
+	}
+	if age <= 0 {
+		return fmt.Errorf("older-than must be greater than zero")
+	}
+
+	sources := c.StringSlice("source")
+	for _, source := range sources {
+		if err := events.EventSource(source).Validate(); err != nil {
+			return err
+		}
+	}
+
+	filter := storage.PruneFilter{
+		Sources: sources,
+		Before:  time.Now().Add(-age),
+	}
+
+	return withEventStore(func(store *storage.Storage) error {
+		ctx := context.Background()
+
+		preview, err := store.PreviewPruneContext(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		if preview.Count == 0 {
+			fmt.Printf("No events older than %s match.\n", filter.Before.Format("2006-01-02 15:04"))
+			return nil
+		}
+
+		printPrunePreview(preview, filter)
+
+		if c.Bool("dry-run") {
+			fmt.Println("\nDry run: nothing was deleted.")
+			return nil
+		}
+
+		if !c.Bool("yes") && !confirm(fmt.Sprintf("\nDelete %d events?", preview.Count)) {
+			fmt.Println("Aborted.")
+			return nil
+		}
+
+		deleted, err := store.PruneContext(ctx, filter, storage.DefaultPruneBatchSize)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Deleted %d events\n", deleted)
+
+		if c.Bool("no-vacuum") {
+			return nil
+		}
+
+		before, err := store.DatabaseSizeContext(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Compacting database (VACUUM)...")
+		if err := store.VacuumContext(ctx); err != nil {
+			return err
+		}
+		after, err := store.DatabaseSizeContext(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Database is now %s (reclaimed %s)\n",
+			formatBytes(after.TotalBytes), formatBytes(before.TotalBytes-after.TotalBytes))
+
+		return nil
+	})
+}
+
+func printPrunePreview(preview *storage.PrunePreview, filter storage.PruneFilter) {
+	fmt.Printf("Events older than %s:\n\n", filter.Before.Format("2006-01-02 15:04"))
+	fmt.Printf("  %-12s %10s %10s  %s\n", "SOURCE", "EVENTS", "SIZE", "RANGE")
+	for _, s := range preview.Sources {
+		fmt.Printf("  %-12s %10d %10s  %s → %s\n",
+			s.Source, s.Count, formatBytes(s.Bytes),
+			s.Oldest.Format("2006-01-02"), s.Newest.Format("2006-01-02"))
+	}
+	fmt.Printf("  %-12s %10d %10s\n", "total", preview.Count, formatBytes(preview.Bytes))
+	fmt.Println("\nSize is the approximate event data; the search index shrinks along with it.")
+}
+
+func confirm(prompt string) bool {
+	fmt.Printf("%s [y/N] ", prompt)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
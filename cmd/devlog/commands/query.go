@@ -1,12 +1,20 @@
 package commands
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"time"
 
+	"devlog/internal/config"
+	"devlog/internal/ingest"
+	"devlog/internal/llm"
 	"devlog/internal/output"
+	"devlog/internal/services"
+	"devlog/internal/storage"
 	queryPlugin "devlog/plugins/query"
 
 	"github.com/urfave/cli/v2"
@@ -17,20 +25,55 @@ func QueryCommand() *cli.Command {
 		Name:        "query",
 		Usage:       "Ask questions about your event history in natural language",
 		UsageText:   "devlog query [options] [question]",
-		Description: "Uses an LLM to understand your question and query your event history intelligently.\n\n   Examples:\n      devlog query \"What was I working on?\"\n      devlog query \"What files did I change today?\"\n      devlog query \"Show me all git commits from last week\"\n      devlog query \"What errors did I encounter yesterday?\"\n      devlog query \"When did I last work on the auth module?\"",
+		Description: "Uses an LLM to understand your question and query your event history intelligently.\n\n   Examples:\n      devlog query \"What was I working on?\"\n      devlog query \"What files did I change today?\"\n      devlog query \"Show me all git commits from last week\"\n      devlog query \"What errors did I encounter yesterday?\"\n      devlog query \"When did I last work on the auth module?\"\n      devlog query -i \"What did I ship on Monday?\"   # then ask \"what about Tuesday?\"\n      devlog query --dsl 'repo:devlog type:commit since:3d keyword:\"fts5\"'",
 		ArgsUsage:   "[question]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "Keep a session open so follow-up questions build on earlier ones",
+			},
+			&cli.StringFlag{
+				Name:  "dsl",
+				Usage: "Run a structured query without the LLM, e.g. 'repo:devlog type:commit since:3d keyword:\"fts5\"'",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Value:   "table",
+				Usage:   "Output format for --dsl results: table, json, simple",
+				Aliases: []string{"f"},
+			},
+			&cli.BoolFlag{
+				Name:  "save-session",
+				Usage: "Store the interactive session transcript as a manual/query_session event",
+			},
+		},
 		Action: func(c *cli.Context) error {
+			if dsl := c.String("dsl"); dsl != "" {
+				return runDSLQuery(dsl, c.String("format"))
+			}
+
 			question := "What was I working on my event history?"
 			if c.NArg() > 0 {
 				question = strings.Join(c.Args().Slice(), " ")
 			}
 
-			plugin, _, err := queryPlugin.LoadPlugin()
+			plugin, pluginCfg, err := queryPlugin.LoadPlugin()
 			if err != nil {
 				return err
 			}
 
-			ctx := context.Background()
+			ctx := llm.WithCaller(context.Background(), "query")
+
+			if c.Bool("interactive") {
+				var first string
+				if c.NArg() > 0 {
+					first = question
+				}
+				save := c.Bool("save-session") || pluginCfg.SaveSessions
+				return runQuerySession(ctx, plugin, first, os.Stdin, save)
+			}
+
 			result, err := plugin.Query(ctx, question)
 			if err != nil {
 				return err
@@ -44,6 +87,7 @@ func QueryCommand() *cli.Command {
 			fmt.Printf("Generating summary of %d events...\n", len(result.Results))
 			llmClient := plugin.LLMClient()
 			formatter := output.NewLLMFormatter(llmClient, result.Plan.ResponseGoal)
+			formatter.SetPrompt(plugin.AnswerPrompt())
 			presenter := output.NewSearchPresenterWithFormatter(os.Stdout, formatter)
 			fmt.Println("==================================")
 			fmt.Println("")
@@ -51,3 +95,115 @@ func QueryCommand() *cli.Command {
 		},
 	}
 }
+
+func runQuerySession(ctx context.Context, plugin *queryPlugin.Plugin, first string, in io.Reader, save bool) error {
+	session := plugin.NewSession()
+	scanner := bufio.NewScanner(in)
+
+	fmt.Println("Ask about your history; follow-ups use earlier answers as context. Type 'exit' or press Ctrl-D to quit.")
+
+	question := first
+	for {
+		if question == "" {
+			fmt.Print("\n> ")
+			if !scanner.Scan() {
+				fmt.Println()
+				break
+			}
+			question = strings.TrimSpace(scanner.Text())
+			if question == "" {
+				continue
+			}
+			if question == "exit" || question == "quit" {
+				break
+			}
+		}
+
+		answer, result, err := answerQuestion(ctx, plugin, session, question, os.Stdout)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		} else {
+			session.Record(question, result, answer)
+		}
+		question = ""
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if save && len(session.Turns) > 0 {
+		if err := ingest.SendEvent(session.Event()); err != nil {
+			return fmt.Errorf("save query session: %w", err)
+		}
+		fmt.Printf("✓ Saved session with %d questions\n", len(session.Turns))
+	}
+	return nil
+}
+
+// answerQuestion streams the answer to out as it is generated and returns
+// it for the session history.
+func answerQuestion(ctx context.Context, plugin *queryPlugin.Plugin, session *queryPlugin.Session, question string, out io.Writer) (string, *queryPlugin.QueryResult, error) {
+	result, err := session.Ask(ctx, question)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(result.Results) == 0 {
+		answer := "No events found matching your query."
+		fmt.Fprintln(out, answer)
+		return answer, result, nil
+	}
+
+	fmt.Fprintf(out, "Generating summary of %d events...\n\n", len(result.Results))
+	formatter := output.NewLLMFormatter(plugin.LLMClient(), result.Plan.ResponseGoal)
+	formatter.SetPrompt(plugin.AnswerPrompt())
+	answer, err := formatter.FormatStream(ctx, result.Results, question, out)
+	if err != nil {
+		return "", nil, err
+	}
+	return answer, result, nil
+}
+
+func runDSLQuery(dsl, formatName string) error {
+	opts, err := queryPlugin.ParseDSL(dsl, time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	var format output.OutputFormat
+	switch formatName {
+	case "table":
+		format = output.FormatTable
+	case "json":
+		format = output.FormatJSON
+	case "simple":
+		format = output.FormatSimple
+	default:
+		return fmt.Errorf("invalid format: %s (must be table, json, or simple)", formatName)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	eventService := services.NewEventService(store, func() *config.Config { return cfg }, nil)
+	results, err := eventService.SearchEvents(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return output.NewSearchPresenter(os.Stdout, format).Present(ctx, results, opts.Query)
+}
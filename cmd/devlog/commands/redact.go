@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/output"
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+func RedactCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "redact",
+		Usage: "Delete or scrub events that captured something sensitive",
+		Description: "Matches events by ID, or by text anywhere in their payload, repo or branch (case-insensitive),\n" +
+			"   then deletes them, or with --scrub replaces the matched text with [REDACTED]. Changes are\n" +
+			"   made in one transaction, the search index is purged, and freed pages are zeroed.\n\n" +
+			"   Examples:\n" +
+			"      devlog redact --query \"aws_secret\" --since 30d --dry-run\n" +
+			"      devlog redact --query \"hunter2\" --scrub\n" +
+			"      devlog redact --id 3f2a9c1e",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "id",
+				Usage: "Event ID or unique prefix to redact (repeatable)",
+			},
+			&cli.StringFlag{
+				Name:    "query",
+				Aliases: []string{"q"},
+				Usage:   "Redact events containing this text",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Only match events newer than this (e.g., '30d', '24h'; default all time)",
+			},
+			&cli.StringSliceFlag{
+				Name:    "source",
+				Aliases: []string{"s"},
+				Usage:   "Only match events from these sources (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "scrub",
+				Usage: "Replace the matched text (or, with only --id, the whole payload) instead of deleting",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would be redacted without changing anything",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Skip the confirmation prompt",
+			},
+		},
+		Action: redactAction,
+	}
+}
+
+func redactAction(c *cli.Context) error {
+	if len(c.StringSlice("id")) == 0 && c.String("query") == "" {
+		return fmt.Errorf("--id or --query is required")
+	}
+
+	filter := storage.RedactFilter{
+		Query:   c.String("query"),
+		Sources: c.StringSlice("source"),
+	}
+	for _, source := range filter.Sources {
+		if err := events.EventSource(source).Validate(); err != nil {
+			return err
+		}
+	}
+	if since := c.String("since"); since != "" {
+		age, err := parseDuration(since)
+		if err != nil {
+			return fmt.Errorf("invalid since duration: %w", err)
+		}
+		after := time.Now().Add(-age)
+		filter.Since = &after
+	}
+
+	return withEventStore(func(store *storage.Storage) error {
+		ctx := context.Background()
+
+		for _, prefix := range c.StringSlice("id") {
+			id, err := store.ResolveEventIDContext(ctx, prefix)
+			if err != nil {
+				return err
+			}
+			filter.IDs = append(filter.IDs, id)
+		}
+
+		matched, err := store.FindRedactableContext(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(matched) == 0 {
+			fmt.Println("No events match.")
+			return nil
+		}
+
+		action := "Delete"
+		if c.Bool("scrub") {
+			action = "Scrub"
+		}
+		fmt.Printf("%d matching event(s):\n\n", len(matched))
+		for _, event := range matched {
+			fmt.Printf("  %s %s [%s:%s]\n", event.Timestamp, event.ID[:8], event.Source, event.Type)
+			if content := output.ExtractContent(event, 100); content != "" {
+				fmt.Printf("    %s\n", content)
+			}
+		}
+
+		if c.Bool("dry-run") {
+			fmt.Println("\nDry run: nothing was changed.")
+			return nil
+		}
+
+		if !c.Bool("yes") && !confirm(fmt.Sprintf("\n%s %d event(s)?", action, len(matched))) {
+			fmt.Println("Aborted.")
+			return nil
+		}
+
+		n, err := store.RedactEventsContext(ctx, matched, c.Bool("scrub"), filter.Query)
+		if err != nil && n == 0 {
+			return err
+		}
+		if c.Bool("scrub") {
+			fmt.Printf("✓ Scrubbed %d event(s)\n", n)
+		} else {
+			fmt.Printf("✓ Deleted %d event(s)\n", n)
+		}
+		if err != nil {
+			// The redaction is committed; only the WAL still holds old pages,
+			// for example because the daemon is reading. They are overwritten
+			// at its next checkpoint.
+			fmt.Printf("⚠ Could not checkpoint the write-ahead log: %v\n", err)
+		}
+		fmt.Println("Summaries and copies synced to other machines are not changed.")
+		return nil
+	})
+}
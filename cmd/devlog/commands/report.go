@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	"devlog/plugins/summarizer"
+
+	"github.com/urfave/cli/v2"
+)
+
+func ReportCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "report",
+		Usage: "Generate a weekly or monthly report from summaries and activity",
+		Description: "Aggregates daily summaries, top repos, commit counts, and time distribution into one report.\n\n" +
+			"   Examples:\n" +
+			"      devlog report --week 2025-W21\n" +
+			"      devlog report --month 2025-05 --format html -o may.html",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "week",
+				Usage: "ISO week to report on (e.g., '2025-W21')",
+			},
+			&cli.StringFlag{
+				Name:  "month",
+				Usage: "Month to report on (e.g., '2025-05')",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Value:   summarizer.ReportFormatMarkdown,
+				Usage:   "Output format: markdown, html",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Usage:   "Write the report to a file instead of stdout",
+			},
+		},
+		Action: reportAction,
+	}
+}
+
+func reportAction(c *cli.Context) error {
+	week, month := c.String("week"), c.String("month")
+
+	var (
+		start, end time.Time
+		title      string
+		err        error
+	)
+	switch {
+	case week != "" && month != "":
+		return fmt.Errorf("use either --week or --month, not both")
+	case week != "":
+		start, end, err = summarizer.ParseWeek(week, time.Local)
+		title = fmt.Sprintf("Weekly Report - %s", week)
+	case month != "":
+		start, end, err = summarizer.ParseMonth(month, time.Local)
+		title = fmt.Sprintf("Monthly Report - %s", start.Format("January 2006"))
+	default:
+		return fmt.Errorf("--week or --month is required (e.g., --week 2025-W21)")
+	}
+	if err != nil {
+		return err
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return fmt.Errorf("get data directory: %w", err)
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	report, err := summarizer.BuildReport(context.Background(), store, title, start, end)
+	if err != nil {
+		return err
+	}
+
+	output := c.String("output")
+	if output == "" {
+		return report.Render(os.Stdout, c.String("format"))
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create report file: %w", err)
+	}
+	if err := report.Render(f, c.String("format")); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write report file: %w", err)
+	}
+
+	fmt.Printf("✓ Wrote %s\n", output)
+	return nil
+}
@@ -4,14 +4,15 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"devlog/internal/config"
+	"devlog/internal/jump"
 	"devlog/internal/output"
 	"devlog/internal/services"
 	"devlog/internal/storage"
+	"devlog/plugins/embeddings"
 
 	"github.com/urfave/cli/v2"
 )
@@ -19,9 +20,9 @@ import (
 func SearchCommand() *cli.Command {
 	return &cli.Command{
 		Name:        "search",
-		Usage:       "Search events using full-text search with advanced filters",
+		Usage:       "Search events and summaries using full-text search with advanced filters",
 		UsageText:   "devlog search [options] [query]",
-		Description: "Search your development history. Note: options must come before the query.\n\n   Examples:\n      devlog search --since 2h \"error\"\n      devlog search --module git --type commit \"fix\"\n      devlog search --repo myproject \"auth\"",
+		Description: "Search your development history. Note: options must come before the query.\n\n   Examples:\n      devlog search --since 2h \"error\"\n      devlog search --module git --type commit \"fix\"\n      devlog search --repo myproject \"auth\"\n      devlog search --issue PROJ-123\n      devlog search --format jsonl --fields timestamp,repo,content \"deploy\" | jq .\n      devlog search --scope summaries \"migration\"\n      devlog search --sort time_desc --open \"auth.go\"\n      devlog search --semantic \"when did I debug the flaky websocket test\"",
 		ArgsUsage:   "[query]",
 		Flags: []cli.Flag{
 			&cli.IntFlag{
@@ -52,6 +53,15 @@ func SearchCommand() *cli.Command {
 				Name:  "branch",
 				Usage: "Filter by branch pattern",
 			},
+			&cli.StringFlag{
+				Name:  "issue",
+				Usage: "Filter by issue key added by the issues plugin (e.g. PROJ-123)",
+			},
+			&cli.StringFlag{
+				Name:  "scope",
+				Value: "events",
+				Usage: "What to search: events (including notes), summaries, all",
+			},
 			&cli.StringFlag{
 				Name:    "sort",
 				Value:   "time_asc",
@@ -61,9 +71,25 @@ func SearchCommand() *cli.Command {
 			&cli.StringFlag{
 				Name:    "format",
 				Value:   "table",
-				Usage:   "Output format: table, json, simple",
+				Usage:   "Output format: table, json, jsonl, md, simple",
 				Aliases: []string{"f"},
 			},
+			&cli.StringFlag{
+				Name:  "fields",
+				Usage: "Comma-separated fields to show: " + strings.Join(output.SearchFields, ", ") + ", or payload.KEY",
+			},
+			&cli.BoolFlag{
+				Name:  "no-truncate",
+				Usage: "Show content in full instead of shortening it",
+			},
+			&cli.BoolFlag{
+				Name:  "semantic",
+				Usage: "Rank by meaning using the embeddings plugin's index instead of full-text matching",
+			},
+			&cli.BoolFlag{
+				Name:  "open",
+				Usage: "Open the first result's file or repo in $EDITOR, or its commit/PR URL in the browser",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			query := "*"
@@ -96,6 +122,11 @@ func parseDuration(s string) (time.Duration, error) {
 }
 
 func executeSearch(c *cli.Context, query string) error {
+	format, presentOpts, err := searchOutputOptions(c)
+	if err != nil {
+		return err
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
@@ -106,9 +137,7 @@ func executeSearch(c *cli.Context, query string) error {
 		return err
 	}
 
-	dbPath := filepath.Join(dataDir, "events.db")
-
-	store, err := storage.New(dbPath)
+	store, err := storage.Open(dataDir, cfg.Storage)
 	if err != nil {
 		return err
 	}
@@ -126,6 +155,15 @@ func executeSearch(c *cli.Context, query string) error {
 		RepoPattern:   c.String("repo"),
 		BranchPattern: c.String("branch"),
 	}
+	if issue := c.String("issue"); issue != "" {
+		searchOpts.PayloadFilter = &storage.PayloadFilter{JSONPath: "$.issue", Value: strings.ToUpper(issue)}
+	}
+
+	scope, err := storage.ParseSearchScope(c.String("scope"))
+	if err != nil {
+		return err
+	}
+	searchOpts.Scope = scope
 
 	if since := c.String("since"); since != "" {
 		duration, err := parseDuration(since)
@@ -147,23 +185,94 @@ func executeSearch(c *cli.Context, query string) error {
 		return fmt.Errorf("invalid sort order: %s (must be time_asc, time_desc, or relevance)", c.String("sort"))
 	}
 
-	results, err := eventService.SearchEvents(ctx, searchOpts)
+	var results []*storage.SearchResult
+	if c.Bool("semantic") {
+		results, err = semanticSearch(ctx, cfg, dataDir, store, searchOpts)
+		if err == nil {
+			results, err = eventService.AttachAnnotations(ctx, results)
+		}
+	} else {
+		results, err = eventService.SearchEvents(ctx, searchOpts)
+	}
 	if err != nil {
 		return err
 	}
 
-	var format output.OutputFormat
-	switch c.String("format") {
-	case "table":
-		format = output.FormatTable
-	case "json":
-		format = output.FormatJSON
-	case "simple":
-		format = output.FormatSimple
+	presenter := output.NewSearchPresenterWithOptions(os.Stdout, format, presentOpts)
+	if err := presenter.Present(ctx, results, query); err != nil {
+		return err
+	}
+
+	if c.Bool("open") {
+		return openFirstResult(results)
+	}
+	return nil
+}
+
+// searchOutputOptions checks --format, --fields and --no-truncate before
+// anything is searched.
+func searchOutputOptions(c *cli.Context) (output.OutputFormat, output.PresentOptions, error) {
+	format := output.OutputFormat(c.String("format"))
+	switch format {
+	case output.FormatTable, output.FormatJSON, output.FormatJSONL, output.FormatMarkdown, output.FormatSimple:
 	default:
-		return fmt.Errorf("invalid format: %s (must be table, json, or simple)", c.String("format"))
+		return "", output.PresentOptions{}, fmt.Errorf("invalid format: %s (must be table, json, jsonl, md, or simple)", format)
+	}
+
+	fields, err := output.ParseFields(c.String("fields"))
+	if err != nil {
+		return "", output.PresentOptions{}, err
 	}
+	if len(fields) > 0 && format == output.FormatSimple {
+		return "", output.PresentOptions{}, fmt.Errorf("--fields does not apply to the simple format")
+	}
+
+	return format, output.PresentOptions{Fields: fields, NoTruncate: c.Bool("no-truncate")}, nil
+}
 
-	presenter := output.NewSearchPresenter(os.Stdout, format)
-	return presenter.Present(ctx, results, query)
+func semanticSearch(ctx context.Context, cfg *config.Config, dataDir string, store storage.Store, opts storage.SearchOptions) ([]*storage.SearchResult, error) {
+	if opts.Query == "" || opts.Query == "*" {
+		return nil, fmt.Errorf("--semantic requires a query")
+	}
+	sqlite, ok := store.(*storage.Storage)
+	if !ok {
+		return nil, fmt.Errorf("--semantic is only available with the sqlite storage backend")
+	}
+	if !cfg.IsPluginEnabled("embeddings") {
+		return nil, fmt.Errorf("--semantic requires the embeddings plugin (devlog plugin install embeddings)")
+	}
+
+	cfgMap, _ := cfg.GetPluginConfig("embeddings")
+	pluginCfg, err := embeddings.ParseConfig(cfgMap)
+	if err != nil {
+		return nil, err
+	}
+
+	embedder, err := embeddings.NewEmbedderFromConfig(cfg, pluginCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := embeddings.OpenIndex(embeddings.IndexPath(dataDir))
+	if err != nil {
+		return nil, err
+	}
+	defer index.Close()
+
+	return embeddings.Search(ctx, sqlite, index, embedder, opts)
+}
+
+func openFirstResult(results []*storage.SearchResult) error {
+	for _, result := range results {
+		if result.Event == nil {
+			continue
+		}
+		target, ok := jump.Resolve(result.Event)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Opening %s\n", target.Location)
+		return jump.Open(target)
+	}
+	return fmt.Errorf("no results reference a file, repository, or URL to open")
 }
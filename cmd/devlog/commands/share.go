@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/share"
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+const maxShareExpiry = 90 * 24 * time.Hour
+
+func ShareCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "share",
+		Usage: "Create read-only links to your summaries",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "summary",
+				Usage:     "Create an expiring link to a day's summaries",
+				ArgsUsage: "<day>",
+				Description: "Creates a signed link that shows only the summaries for one day, never raw events.\n\n" +
+					"   Examples:\n" +
+					"      devlog share summary today\n" +
+					"      devlog share summary 2024-06-12 --expires 30d\n" +
+					"      devlog share summary yesterday --base-url https://devlog.example.ts.net",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "expires",
+						Value: "7d",
+						Usage: "How long the link stays valid (e.g., '12h', '7d'; max 90d)",
+					},
+					&cli.StringFlag{
+						Name:  "base-url",
+						Usage: "Public URL that reaches the daemon (defaults to the local address)",
+					},
+				},
+				Action: shareSummaryAction,
+			},
+			{
+				Name:   "rotate",
+				Usage:  "Rotate the signing key, revoking every existing share link",
+				Action: shareRotateAction,
+			},
+		},
+	}
+}
+
+func shareSummaryAction(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("day is required (e.g., 'today', 'yesterday', or '2024-06-12')")
+	}
+
+	day, err := parseDay(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	expires, err := parseDuration(c.String("expires"))
+	if err != nil {
+		return fmt.Errorf("invalid expires duration: %w", err)
+	}
+	if expires <= 0 || expires > maxShareExpiry {
+		return fmt.Errorf("expires must be between 1m and 90d")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	summaries, err := store.QuerySummariesContext(context.Background(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("query summaries: %w", err)
+	}
+	if len(summaries) == 0 {
+		return fmt.Errorf("no summaries found for %s", day.Format("2006-01-02"))
+	}
+
+	key, err := share.LoadOrCreateKey(dataDir)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(expires)
+	token, err := share.Sign(key, share.Grant{
+		Kind:      share.KindSummary,
+		Date:      day.Format("2006-01-02"),
+		ExpiresAt: expiresAt.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	baseURL := strings.TrimSuffix(c.String("base-url"), "/")
+	if baseURL == "" {
+		baseURL = cfg.HTTP.LocalURL()
+	}
+
+	fmt.Printf("%s/share/%s\n\n", baseURL, token)
+	fmt.Printf("Shows %d summaries for %s. Expires %s.\n",
+		len(summaries), day.Format("January 2, 2006"), expiresAt.Format("2006-01-02 15:04"))
+	if c.String("base-url") == "" && cfg.HTTP.IsLoopback() {
+		fmt.Println("The daemon listens on 127.0.0.1; use --base-url with a tunnel to share outside this machine.")
+	}
+	fmt.Println("Revoke all links with 'devlog share rotate'.")
+
+	return nil
+}
+
+func shareRotateAction(c *cli.Context) error {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return err
+	}
+
+	if _, err := share.RotateKey(dataDir); err != nil {
+		return err
+	}
+
+	fmt.Println("✓ Share key rotated. All existing share links are now invalid.")
+	return nil
+}
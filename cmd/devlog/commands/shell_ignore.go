@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+
+	"devlog/internal/config"
+
+	"github.com/urfave/cli/v2"
+)
+
+func shellModuleCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "shell",
+		Usage: "Shell module settings",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "ignore",
+				Usage: "Manage commands the shell module does not record",
+				Description: "Patterns:\n" +
+					"      ls              the command name (matches 'ls -la')\n" +
+					"      git status      a command prefix (matches 'git status -s')\n" +
+					"      'aws sso *'     a glob over the whole command (* and ?)\n" +
+					"      're:^op .*'     a regular expression\n\n" +
+					"Deprecated for rules of your own: add a 'filters' entry with module: shell and\n" +
+					"the same pattern as command: instead. The list keeps the entries modules manage.",
+				Subcommands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "Show the ignore list",
+						Action: func(c *cli.Context) error { return shellIgnoreList() },
+					},
+					{
+						Name:      "add",
+						Usage:     "Add patterns to the ignore list",
+						ArgsUsage: "PATTERN [PATTERN...]",
+						Action: func(c *cli.Context) error {
+							return shellIgnoreAdd(c.Args().Slice())
+						},
+					},
+					{
+						Name:      "remove",
+						Aliases:   []string{"rm"},
+						Usage:     "Remove patterns from the ignore list",
+						ArgsUsage: "PATTERN [PATTERN...]",
+						Action: func(c *cli.Context) error {
+							return shellIgnoreRemove(c.Args().Slice())
+						},
+					},
+					{
+						Name:      "test",
+						Usage:     "Check whether a command would be ignored",
+						ArgsUsage: "COMMAND",
+						Action: func(c *cli.Context) error {
+							return shellIgnoreTest(c.Args().First())
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func shellIgnoreList() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	list := cfg.ShellIgnoreList()
+	if len(list) == 0 {
+		fmt.Println("Ignore list is empty")
+		return nil
+	}
+	for _, pattern := range list {
+		fmt.Println(pattern)
+	}
+	return nil
+}
+
+func shellIgnoreAdd(patterns []string) error {
+	if len(patterns) == 0 {
+		return fmt.Errorf("at least one pattern is required")
+	}
+	for _, pattern := range patterns {
+		if err := config.ValidateIgnorePattern(pattern); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, pattern := range cfg.ShellIgnoreList() {
+		existing[pattern] = true
+	}
+
+	cfg.AddToShellIgnoreList(patterns...)
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	for _, pattern := range patterns {
+		if existing[pattern] {
+			fmt.Printf("  %s is already ignored\n", pattern)
+		} else {
+			fmt.Printf("✓ Ignoring %s\n", pattern)
+		}
+	}
+	return nil
+}
+
+func shellIgnoreRemove(patterns []string) error {
+	if len(patterns) == 0 {
+		return fmt.Errorf("at least one pattern is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+	for _, pattern := range cfg.ShellIgnoreList() {
+		existing[pattern] = true
+	}
+
+	cfg.RemoveFromShellIgnoreList(patterns...)
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	for _, pattern := range patterns {
+		if existing[pattern] {
+			fmt.Printf("✓ Removed %s\n", pattern)
+		} else {
+			fmt.Printf("  %s was not in the ignore list\n", pattern)
+		}
+	}
+	return nil
+}
+
+func shellIgnoreTest(command string) error {
+	if command == "" {
+		return fmt.Errorf("a command is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	for _, pattern := range cfg.ShellIgnoreList() {
+		if config.IsCommandIgnored([]string{pattern}, command) {
+			fmt.Printf("ignored (matches %s)\n", pattern)
+			return nil
+		}
+	}
+	fmt.Println("recorded")
+	return nil
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+
+	"devlog/modules/ssh"
+
+	"github.com/urfave/cli/v2"
+)
+
+func sshModuleCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ssh",
+		Usage: "ssh module settings",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "remote-hook",
+				Usage: "Print the shell snippet that reports remote commands back to devlog",
+				Description: "Add the snippet to ~/.bashrc or ~/.zshrc on each remote machine, and set\n" +
+					"   capture_remote_commands: true for the ssh module. Commands typed in sessions\n" +
+					"   opened through the devlog ssh wrapper are then recorded as ssh/command events:\n" +
+					"      devlog module ssh remote-hook | ssh prod-db-1 'cat >> ~/.bashrc'",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "port",
+						Usage: "Remote port the wrapper forwards (defaults to the module's forward_port)",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					port := c.Int("port")
+					if port == 0 {
+						port = ssh.ForwardPort()
+					}
+					hook, err := ssh.RemoteHook(port)
+					if err != nil {
+						return err
+					}
+					fmt.Print(hook)
+					return nil
+				},
+			},
+		},
+	}
+}
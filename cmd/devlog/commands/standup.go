@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	"devlog/plugins/summarizer"
+
+	"github.com/urfave/cli/v2"
+)
+
+func StandupCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "standup",
+		Usage: "Print Yesterday / Today / Blockers notes for a standup",
+		Description: "Builds standup notes from summaries, PRs, commits, workflow runs and notes.\n" +
+			"   Notes tagged 'blocker' or 'blocked' are listed under Blockers, notes tagged\n" +
+			"   'todo', 'today' or 'next' under Today.\n\n" +
+			"   Examples:\n" +
+			"      devlog standup\n" +
+			"      devlog standup --since 2025-05-16    # e.g. on a Monday\n" +
+			"      devlog standup --format slack | pbcopy",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "since",
+				Value: "yesterday",
+				Usage: "Start of the window: 'yesterday', 'today' or a date (YYYY-MM-DD)",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Value:   summarizer.StandupFormatText,
+				Usage:   "Output format: text, markdown, slack",
+			},
+		},
+		Action: standupAction,
+	}
+}
+
+func standupAction(c *cli.Context) error {
+	since, err := parseDay(c.String("since"))
+	if err != nil {
+		return err
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return fmt.Errorf("get data directory: %w", err)
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	standup, err := summarizer.BuildStandup(context.Background(), store, since, time.Now())
+	if err != nil {
+		return err
+	}
+	return standup.Render(os.Stdout, c.String("format"))
+}
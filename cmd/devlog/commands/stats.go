@@ -0,0 +1,247 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	statsBarWidth = 30
+	// statsMaxSparkline is the widest the per-day sparkline gets; longer
+	// ranges put several days in each mark.
+	statsMaxSparkline = 60
+)
+
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+func StatsCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "stats",
+		Usage: "Chart events per day, sources, top repos and active hours in the terminal",
+		Description: "A quick look at the same numbers as the web dashboard, drawn with text.\n\n" +
+			"   Examples:\n" +
+			"      devlog stats\n" +
+			"      devlog stats --since 7d --top 10",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "How far back to look (e.g. 7d, 30d, 90d)",
+				Value: "30d",
+			},
+			&cli.IntFlag{
+				Name:  "top",
+				Usage: "Number of sources and repos to chart",
+				Value: 8,
+			},
+		},
+		Action: statsAction,
+	}
+}
+
+func statsAction(c *cli.Context) error {
+	span, err := parseDuration(c.String("since"))
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	if span <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	if c.Int("top") < 1 {
+		return fmt.Errorf("--top must be at least 1")
+	}
+
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	days := int((span + 24*time.Hour - 1) / (24 * time.Hour))
+	start := end.AddDate(0, 0, -days)
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return fmt.Errorf("get data directory: %w", err)
+	}
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	buckets, err := store.ActivityBucketsContext(context.Background(), start, end)
+	if err != nil {
+		return err
+	}
+
+	writeStats(os.Stdout, buildActivityStats(buckets, start, end), c.Int("top"))
+	return nil
+}
+
+type statsCount struct {
+	Name  string
+	Count int
+}
+
+// activityStats holds event counts over a range of local calendar days.
+type activityStats struct {
+	Start   time.Time
+	Days    []statsCount
+	Sources []statsCount
+	Repos   []statsCount
+	Hours   [24]int
+	Total   int
+}
+
+func buildActivityStats(buckets []storage.ActivityBucket, start, end time.Time) *activityStats {
+	loc := start.Location()
+	stats := &activityStats{Start: start}
+	index := make(map[string]int)
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		index[key] = len(stats.Days)
+		stats.Days = append(stats.Days, statsCount{Name: key})
+	}
+
+	sources := make(map[string]int)
+	repos := make(map[string]int)
+	for _, b := range buckets {
+		hour := b.Hour.In(loc)
+		i, ok := index[hour.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		stats.Days[i].Count += b.Count
+		stats.Hours[hour.Hour()] += b.Count
+		stats.Total += b.Count
+		sources[b.Source] += b.Count
+		if b.Repo != "" {
+			repos[filepath.Base(b.Repo)] += b.Count
+		}
+	}
+	stats.Sources = sortedCounts(sources)
+	stats.Repos = sortedCounts(repos)
+	return stats
+}
+
+// sortedCounts orders counts from largest to smallest, ties by name.
+func sortedCounts(m map[string]int) []statsCount {
+	counts := make([]statsCount, 0, len(m))
+	for name, n := range m {
+		counts = append(counts, statsCount{Name: name, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+func writeStats(w io.Writer, s *activityStats, top int) {
+	fmt.Fprintf(w, "# Activity since %s (%d days)\n\n", s.Start.Format("Mon, Jan 2"), len(s.Days))
+	if s.Total == 0 {
+		fmt.Fprintln(w, "No activity recorded.")
+		return
+	}
+
+	active := 0
+	busiest := s.Days[0]
+	for _, d := range s.Days {
+		if d.Count > 0 {
+			active++
+		}
+		if d.Count > busiest.Count {
+			busiest = d
+		}
+	}
+	busiestDay, _ := time.ParseInLocation("2006-01-02", busiest.Name, s.Start.Location())
+	fmt.Fprintf(w, "%d events · %d active days · busiest %s (%d)\n", s.Total, active, busiestDay.Format("Mon, Jan 2"), busiest.Count)
+
+	fmt.Fprintln(w, "\n## Events per day")
+	perMark := (len(s.Days) + statsMaxSparkline - 1) / statsMaxSparkline
+	marks := make([]int, 0, statsMaxSparkline)
+	for i := 0; i < len(s.Days); i += perMark {
+		sum := 0
+		for _, d := range s.Days[i:min(i+perMark, len(s.Days))] {
+			sum += d.Count
+		}
+		marks = append(marks, sum)
+	}
+	line := sparkline(marks)
+	fmt.Fprintf(w, "\n  %s\n", line)
+	first := s.Start.Format("Jan 2")
+	last, _ := time.ParseInLocation("2006-01-02", s.Days[len(s.Days)-1].Name, s.Start.Location())
+	fmt.Fprintf(w, "  %s\n", spreadLabels(first, last.Format("Jan 2"), len(marks)))
+	if perMark > 1 {
+		fmt.Fprintf(w, "  (each mark is %d days)\n", perMark)
+	}
+
+	fmt.Fprintln(w, "\n## Sources")
+	writeBars(w, s.Sources, top)
+
+	if len(s.Repos) > 0 {
+		fmt.Fprintln(w, "\n## Top repos")
+		writeBars(w, s.Repos, top)
+	}
+
+	fmt.Fprintln(w, "\n## Active hours")
+	fmt.Fprintf(w, "\n  %s\n", sparkline(s.Hours[:]))
+	fmt.Fprintf(w, "  %s\n", "0     6     12    18   23")
+}
+
+// sparkline draws each value as a block scaled to the largest; zero is a
+// blank so idle stretches stand out.
+func sparkline(values []int) string {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var sb strings.Builder
+	for _, v := range values {
+		if v == 0 || peak == 0 {
+			sb.WriteRune(' ')
+			continue
+		}
+		level := (v*len(sparkLevels) - 1) / peak
+		sb.WriteRune(sparkLevels[min(level, len(sparkLevels)-1)])
+	}
+	return sb.String()
+}
+
+// spreadLabels puts left and right at either end of a line width runes
+// wide, or one space apart when they do not fit.
+func spreadLabels(left, right string, width int) string {
+	gap := width - len(left) - len(right)
+	if gap < 1 {
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+func writeBars(w io.Writer, counts []statsCount, top int) {
+	if len(counts) > top {
+		counts = counts[:top]
+	}
+	nameWidth := 0
+	for _, c := range counts {
+		nameWidth = max(nameWidth, len(c.Name))
+	}
+	peak := counts[0].Count
+	fmt.Fprintln(w)
+	for _, c := range counts {
+		width := c.Count * statsBarWidth / peak
+		if width == 0 {
+			width = 1
+		}
+		fmt.Fprintf(w, "  %-*s %-*s %d\n", nameWidth, c.Name, statsBarWidth, strings.Repeat("█", width), c.Count)
+	}
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+func TestBuildActivityStats(t *testing.T) {
+	start := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 3)
+	buckets := []storage.ActivityBucket{
+		{Hour: start.Add(9 * time.Hour), Source: "shell", Repo: "/home/me/src/devlog", Count: 6},
+		{Hour: start.Add(9 * time.Hour), Source: "git", Repo: "/home/me/src/devlog", Count: 2},
+		{Hour: start.Add(2*24*time.Hour + 14*time.Hour), Source: "shell", Repo: "api", Count: 3},
+		{Hour: start.Add(2*24*time.Hour + 15*time.Hour), Source: "clipboard", Count: 1},
+		{Hour: end.Add(time.Hour), Source: "shell", Count: 50},
+	}
+
+	s := buildActivityStats(buckets, start, end)
+
+	if s.Total != 12 {
+		t.Errorf("Total = %d, want 12 (events after the range are left out)", s.Total)
+	}
+	if got := []int{s.Days[0].Count, s.Days[1].Count, s.Days[2].Count}; got[0] != 8 || got[1] != 0 || got[2] != 4 {
+		t.Errorf("events per day = %v, want [8 0 4]", got)
+	}
+	if s.Sources[0] != (statsCount{Name: "shell", Count: 9}) || len(s.Sources) != 3 {
+		t.Errorf("Sources = %+v", s.Sources)
+	}
+	if len(s.Repos) != 2 || s.Repos[0] != (statsCount{Name: "devlog", Count: 8}) {
+		t.Errorf("Repos = %+v", s.Repos)
+	}
+	if s.Hours[9] != 8 || s.Hours[14] != 3 || s.Hours[15] != 1 {
+		t.Errorf("Hours = %v", s.Hours)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 1, 4, 8}); got != " ▁▄█" {
+		t.Errorf("sparkline() = %q", got)
+	}
+	if got := sparkline([]int{0, 0}); got != "  " {
+		t.Errorf("sparkline() of no activity = %q", got)
+	}
+}
+
+func TestWriteStats(t *testing.T) {
+	start := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	s := buildActivityStats([]storage.ActivityBucket{
+		{Hour: start.Add(9 * time.Hour), Source: "shell", Repo: "devlog", Count: 20},
+		{Hour: start.Add(33 * time.Hour), Source: "git", Repo: "api", Count: 5},
+	}, start, start.AddDate(0, 0, 90))
+
+	var buf bytes.Buffer
+	writeStats(&buf, s, 1)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# Activity since Mon, May 19 (90 days)",
+		"25 events · 2 active days · busiest Mon, May 19 (20)",
+		"(each mark is 2 days)",
+		"  shell " + strings.Repeat("█", statsBarWidth) + " 20",
+		"## Top repos",
+		"0     6     12    18   23",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "  git ") {
+		t.Errorf("--top 1 should chart only the largest source:\n%s", out)
+	}
+
+	buf.Reset()
+	writeStats(&buf, buildActivityStats(nil, start, start.AddDate(0, 0, 7)), 5)
+	if !strings.Contains(buf.String(), "No activity recorded.") {
+		t.Errorf("empty range output:\n%s", buf.String())
+	}
+}
@@ -2,9 +2,10 @@ package commands
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"path/filepath"
 
 	"devlog/cmd/devlog/formatting"
 	"devlog/internal/config"
@@ -51,19 +52,25 @@ func StatusCommand() *cli.Command {
 }
 
 func Status(verbose bool, limit int, source string) error {
-	_, err := config.Load()
+	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
+	if warnings := resourceWarnings(cfg.HTTP); len(warnings) > 0 {
+		fmt.Println("Over resource budget:")
+		for _, warning := range warnings {
+			fmt.Printf("  ⚠ %s\n", warning)
+		}
+		fmt.Println()
+	}
+
 	dataDir, err := config.DataDir()
 	if err != nil {
 		return err
 	}
 
-	dbPath := filepath.Join(dataDir, "events.db")
-
-	store, err := storage.New(dbPath)
+	store, err := storage.Open(dataDir, cfg.Storage)
 	if err != nil {
 		return err
 	}
@@ -73,7 +80,7 @@ func Status(verbose bool, limit int, source string) error {
 		limit = 10
 	}
 
-	recentEvents, err := store.QueryEvents(storage.QueryOptions{
+	recentEvents, err := store.QueryEventsContext(context.Background(), storage.QueryOptions{
 		Limit:  limit,
 		Source: source,
 	})
@@ -111,6 +118,31 @@ func Status(verbose bool, limit int, source string) error {
 	return nil
 }
 
+// resourceWarnings asks a running daemon which plugins and pollers are over
+// their resource budgets. It returns nothing when the daemon is not running
+// or cannot be reached, since status also works without one.
+func resourceWarnings(httpCfg config.HTTPConfig) []string {
+	if !daemon.IsRunning() {
+		return nil
+	}
+	resp, err := daemonGet(httpCfg, "/api/v1/metrics?summary=true")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var summary struct {
+		ResourceWarnings []string `json:"resource_warnings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return nil
+	}
+	return summary.ResourceWarnings
+}
+
 func StatusMetrics() error {
 	if !daemon.IsRunning() {
 		return fmt.Errorf("daemon is not running")
@@ -121,9 +153,7 @@ func StatusMetrics() error {
 		return err
 	}
 
-	url := fmt.Sprintf("http://127.0.0.1:%d/api/v1/metrics?summary=true", cfg.HTTP.Port)
-
-	resp, err := http.Get(url)
+	resp, err := daemonGet(cfg.HTTP, "/api/v1/metrics?summary=true")
 	if err != nil {
 		return fmt.Errorf("failed to connect to daemon: %w", err)
 	}
@@ -3,16 +3,19 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"time"
 
 	"devlog/internal/config"
 	"devlog/internal/llm"
 	"devlog/internal/storage"
+	llmplugin "devlog/plugins/llm"
 	"devlog/plugins/summarizer"
 
 	"github.com/urfave/cli/v2"
@@ -25,20 +28,44 @@ func SummarizerCommand() *cli.Command {
 		Subcommands: []*cli.Command{
 			{
 				Name:      "backfill",
-				Usage:     "Backfill summaries for a specific day (defaults to today)",
+				Usage:     "Regenerate summaries for a day, or fill in missing ones for a date range",
 				ArgsUsage: "[day]",
-				Action:    backfillAction,
+				Description: "With a day (defaults to today), deletes that day's summary file and regenerates every period.\n" +
+					"   With --from/--to, only generates periods that have no summary yet, keeping existing\n" +
+					"   sections and inserting new ones in order:\n\n" +
+					"      devlog summarizer backfill --from 2025-05-01 --to 2025-05-07",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "First day of the range (YYYY-MM-DD, today or yesterday)",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "Last day of the range, inclusive (defaults to --from)",
+					},
+				},
+				Action: backfillAction,
 			},
 			{
 				Name:   "open",
 				Usage:  "Open the latest summary file",
 				Action: openAction,
 			},
+			{
+				Name:      "show",
+				Usage:     "Show stored summaries for a day (defaults to today)",
+				ArgsUsage: "[day]",
+				Action:    showSummariesAction,
+			},
 		},
 	}
 }
 
 func backfillAction(c *cli.Context) error {
+	if c.IsSet("from") || c.IsSet("to") {
+		return backfillRangeAction(c)
+	}
+
 	dayStr := "today"
 	if c.Args().Present() {
 		dayStr = c.Args().First()
@@ -73,6 +100,65 @@ func backfillAction(c *cli.Context) error {
 	return backfillSummarizer(day, endTime, dataDir)
 }
 
+func showSummariesAction(c *cli.Context) error {
+	dayStr := "today"
+	if c.Args().Present() {
+		dayStr = c.Args().First()
+	}
+
+	day, err := parseDay(dayStr)
+	if err != nil {
+		return fmt.Errorf("parse day: %w", err)
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return fmt.Errorf("get data directory: %w", err)
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	summaries, err := store.QuerySummariesContext(context.Background(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return fmt.Errorf("query summaries: %w", err)
+	}
+
+	if len(summaries) == 0 {
+		fmt.Printf("No summaries found for %s\n", day.Format("2006-01-02"))
+		return nil
+	}
+
+	fmt.Printf("# Development Summary - %s\n\n", day.Format("January 2, 2006"))
+	for _, summary := range summaries {
+		heading := fmt.Sprintf("%s - %s", summary.PeriodStart.Format("15:04"), summary.PeriodEnd.Format("15:04"))
+		if summary.Repo != "" {
+			heading += " · " + summary.Repo
+		}
+		fmt.Printf("## %s\n\n", heading)
+		fmt.Println(summary.Text)
+		fmt.Println()
+
+		meta := fmt.Sprintf("%d events", summary.EventCount)
+		if len(summary.Repos) > 0 {
+			repos := make([]string, len(summary.Repos))
+			for i, repo := range summary.Repos {
+				repos[i] = filepath.Base(repo)
+			}
+			meta += fmt.Sprintf(" · repos: %s", strings.Join(repos, ", "))
+		}
+		if summary.Provider != "" {
+			meta += fmt.Sprintf(" · %s (%d in / %d out tokens)", summary.Provider, summary.InputTokens, summary.OutputTokens)
+		}
+		fmt.Printf("_%s_\n\n", meta)
+	}
+
+	return nil
+}
+
 func parseDay(dayStr string) (time.Time, error) {
 	now := time.Now()
 
@@ -93,27 +179,149 @@ func parseDay(dayStr string) (time.Time, error) {
 }
 
 func backfillSummarizer(start, end time.Time, dataDir string) error {
-	cfg, err := config.Load()
+	fmt.Printf("Backfilling summaries for %s:\n", start.Format("2006-01-02"))
+
+	plugin, store, err := openBackfillSummarizer(dataDir, os.Stdout)
 	if err != nil {
-		return fmt.Errorf("load config: %w", err)
+		return err
 	}
+	defer store.Close()
 
-	if !cfg.IsPluginEnabled("summarizer") {
-		return fmt.Errorf("summarizer plugin is not enabled (run 'devlog plugin install summarizer' first)")
+	ctx := context.Background()
+	if err := store.DeleteSummaryWindowsContext(ctx, start, end); err != nil {
+		return fmt.Errorf("reset summarized windows: %w", err)
 	}
 
-	pluginCfg, ok := cfg.GetPluginConfig("summarizer")
-	if !ok {
-		return fmt.Errorf("summarizer plugin config not found")
+	windows, err := plugin.MissingWindows(ctx, start, end)
+	if err != nil {
+		return err
 	}
 
-	dbPath := filepath.Join(dataDir, "events.db")
-	store, err := storage.New(dbPath)
+	count, err := summarizeWindows(ctx, plugin, windows, os.Stdout)
 	if err != nil {
-		return fmt.Errorf("open storage: %w", err)
+		return err
+	}
+
+	fmt.Printf("\n✓ Generated %d summaries for %s\n", count, start.Format("2006-01-02"))
+	return nil
+}
+
+func backfillRangeAction(c *cli.Context) error {
+	fromStr := c.String("from")
+	if fromStr == "" {
+		return fmt.Errorf("--to requires --from")
+	}
+	from, err := parseDay(fromStr)
+	if err != nil {
+		return fmt.Errorf("parse --from: %w", err)
+	}
+
+	to := from
+	if c.IsSet("to") {
+		if to, err = parseDay(c.String("to")); err != nil {
+			return fmt.Errorf("parse --to: %w", err)
+		}
+	}
+	if to.Before(from) {
+		return fmt.Errorf("--to %s is before --from %s", to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return fmt.Errorf("get data directory: %w", err)
+	}
+
+	fmt.Printf("Backfilling missing summaries from %s to %s:\n", from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	plugin, store, err := openBackfillSummarizer(dataDir, os.Stdout)
+	if err != nil {
+		return err
 	}
 	defer store.Close()
 
+	ctx := context.Background()
+	windows, err := plugin.MissingWindows(ctx, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		fmt.Println("✓ Every period in this range already has a summary")
+		return nil
+	}
+
+	count, err := summarizeWindows(ctx, plugin, windows, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n✓ Generated %d missing summaries\n", count)
+	return nil
+}
+
+func summarizeWindows(ctx context.Context, plugin *summarizer.Plugin, windows []summarizer.Window, out io.Writer) (int, error) {
+	count := 0
+	for i, w := range windows {
+		if day := w.Start.Format("2006-01-02"); i == 0 || day != windows[i-1].Start.Format("2006-01-02") {
+			fmt.Fprintf(out, "%s\n", day)
+		}
+		fmt.Fprintf(out, "  [%s - %s] ", w.Start.Format("15:04"), w.End.Format("15:04"))
+
+		// The summary streams in below the window as the LLM writes it.
+		stream := &indentWriter{w: out, indent: "    "}
+		plugin.SetStream(stream)
+		err := plugin.SummarizeWindow(ctx, w)
+		if stream.started {
+			fmt.Fprintf(out, "\n   ")
+		}
+		if err != nil {
+			fmt.Fprintf(out, "❌ Error: %v\n", err)
+			return count, err
+		}
+
+		fmt.Fprintf(out, "✓\n")
+		count++
+	}
+	return count, nil
+}
+
+// indentWriter starts a new line on its first write and indents every line
+// written through it.
+type indentWriter struct {
+	w       io.Writer
+	indent  string
+	started bool
+}
+
+func (iw *indentWriter) Write(p []byte) (int, error) {
+	text := strings.ReplaceAll(string(p), "\n", "\n"+iw.indent)
+	if !iw.started {
+		iw.started = true
+		text = "\n" + iw.indent + text
+	}
+	if _, err := io.WriteString(iw.w, text); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// openBackfillSummarizer builds a summarizer from the plugin config for
+// running outside the daemon, describing its settings on out. The caller
+// closes the returned storage.
+func openBackfillSummarizer(dataDir string, out io.Writer) (*summarizer.Plugin, storage.Store, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if !cfg.IsPluginEnabled("summarizer") {
+		return nil, nil, fmt.Errorf("summarizer plugin is not enabled (run 'devlog plugin install summarizer' first)")
+	}
+
+	pluginCfg, ok := cfg.GetPluginConfig("summarizer")
+	if !ok {
+		return nil, nil, fmt.Errorf("summarizer plugin config not found")
+	}
+
 	intervalSecs := 1800
 	if val, ok := pluginCfg["interval_seconds"]; ok {
 		switch v := val.(type) {
@@ -135,48 +343,24 @@ func backfillSummarizer(start, end time.Time, dataDir string) error {
 	}
 
 	if !cfg.IsPluginEnabled("llm") {
-		return fmt.Errorf("llm plugin is not enabled (required by summarizer)")
+		return nil, nil, fmt.Errorf("llm plugin is not enabled (required by summarizer)")
 	}
 
 	llmCfg, ok := cfg.GetPluginConfig("llm")
 	if !ok {
-		return fmt.Errorf("llm plugin config not found")
-	}
-
-	provider := "ollama"
-	if p, ok := llmCfg["provider"].(string); ok {
-		provider = p
-	}
-
-	apiKey := ""
-	if k, ok := llmCfg["api_key"].(string); ok {
-		apiKey = k
+		return nil, nil, fmt.Errorf("llm plugin config not found")
 	}
 
-	baseURL := ""
-	if u, ok := llmCfg["base_url"].(string); ok {
-		baseURL = u
-	}
-
-	model := ""
-	if m, ok := llmCfg["model"].(string); ok {
-		model = m
-	}
-
-	llmConfig := llm.Config{
-		Provider: llm.ProviderType(provider),
-		APIKey:   apiKey,
-		BaseURL:  baseURL,
-		Model:    model,
+	llmConfig, err := llmplugin.ClientConfig(llmCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse LLM config: %w", err)
 	}
 
 	llmClient, err := llm.NewClient(llmConfig)
 	if err != nil {
-		return fmt.Errorf("create LLM client: %w", err)
+		return nil, nil, fmt.Errorf("create LLM client: %w", err)
 	}
-
-	interval := time.Duration(intervalSecs) * time.Second
-	contextWindow := time.Duration(contextWindowSecs) * time.Second
+	llmplugin.TrackUsage()
 
 	var excludeSources []string
 	if val, ok := pluginCfg["exclude_sources"]; ok {
@@ -189,46 +373,54 @@ func backfillSummarizer(start, end time.Time, dataDir string) error {
 		}
 	}
 
-	fmt.Printf("Backfilling summaries for %s:\n", start.Format("2006-01-02"))
-	fmt.Printf("  Interval: %d seconds (%.0f minutes)\n", intervalSecs, float64(intervalSecs)/60)
-	fmt.Printf("  Context window: %d seconds (%.0f minutes)\n", contextWindowSecs, float64(contextWindowSecs)/60)
-	fmt.Printf("  Provider: %s\n", provider)
+	fmt.Fprintf(out, "  Interval: %d seconds (%.0f minutes)\n", intervalSecs, float64(intervalSecs)/60)
+	fmt.Fprintf(out, "  Context window: %d seconds (%.0f minutes)\n", contextWindowSecs, float64(contextWindowSecs)/60)
+	fmt.Fprintf(out, "  Provider: %s\n", llmConfig.ProviderChain())
 	if len(excludeSources) > 0 {
-		fmt.Printf("  Excluding sources: %v\n", excludeSources)
+		fmt.Fprintf(out, "  Excluding sources: %v\n", excludeSources)
 	}
-	fmt.Println()
+	fmt.Fprintln(out)
 
-	current := start
-	count := 0
-	now := time.Now()
+	store, err := storage.Open(dataDir, cfg.Storage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open storage: %w", err)
+	}
 
-	for current.Before(end) && current.Before(now) {
-		focusEnd := current.Add(interval)
-		if focusEnd.After(end) {
-			focusEnd = end
+	interval := time.Duration(intervalSecs) * time.Second
+	contextWindow := time.Duration(contextWindowSecs) * time.Second
+	plugin := summarizer.NewForPoll(llmClient, store, interval, contextWindow, excludeSources)
+	if val, ok := pluginCfg["journal"]; ok && val != nil {
+		journalCfg, err := summarizer.ParseJournalConfig(val)
+		if err != nil {
+			store.Close()
+			return nil, nil, fmt.Errorf("parse journal config: %w", err)
 		}
-		if focusEnd.After(now) {
-			focusEnd = now
+		plugin.SetJournal(journalCfg)
+	}
+	if perRepo, ok := pluginCfg["per_repo"].(bool); ok {
+		plugin.SetPerRepo(perRepo)
+	}
+	switch v := pluginCfg["max_prompt_tokens"].(type) {
+	case float64:
+		plugin.SetMaxPromptTokens(int(v))
+	case int:
+		plugin.SetMaxPromptTokens(v)
+	}
+	if val, ok := pluginCfg["validation"]; ok && val != nil {
+		validation, err := summarizer.ParseValidationConfig(val)
+		if err != nil {
+			store.Close()
+			return nil, nil, fmt.Errorf("parse validation config: %w", err)
 		}
-
-		fmt.Printf("[%s - %s] ", current.Format("15:04"), focusEnd.Format("15:04"))
-
-		plugin := summarizer.NewForPoll(llmClient, store, interval, contextWindow, excludeSources)
-		ctx := context.Background()
-		contextStart := current.Add(-contextWindow)
-
-		if err := plugin.GenerateSummaryForPeriod(ctx, current, focusEnd, contextStart); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			return err
+		plugin.SetValidation(validation)
+	}
+	if selection, ok := pluginCfg["prompt"].(string); ok {
+		if err := plugin.SetPrompt(selection); err != nil {
+			store.Close()
+			return nil, nil, fmt.Errorf("load prompt: %w", err)
 		}
-
-		fmt.Printf("✓\n")
-		count++
-		current = focusEnd
 	}
-
-	fmt.Printf("\n✓ Generated %d summaries for %s\n", count, start.Format("2006-01-02"))
-	return nil
+	return plugin, store, nil
 }
 
 func openAction(c *cli.Context) error {
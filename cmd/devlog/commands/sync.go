@@ -0,0 +1,138 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	syncPlugin "devlog/plugins/sync"
+
+	"github.com/urfave/cli/v2"
+)
+
+func SyncCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "sync",
+		Usage: "Replicate events with your other machines",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "run",
+				Usage:  "Push local events and pull peers' events now",
+				Action: syncRunAction,
+			},
+			{
+				Name:   "status",
+				Usage:  "Show what has been pushed and pulled",
+				Action: syncStatusAction,
+			},
+			{
+				Name:  "keygen",
+				Usage: "Generate a new shared sync key",
+				Action: func(c *cli.Context) error {
+					key, err := syncPlugin.GenerateKey()
+					if err != nil {
+						return err
+					}
+					fmt.Println(key)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func loadSyncer() (*syncPlugin.Syncer, *storage.Storage, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if !cfg.IsPluginEnabled("sync") {
+		return nil, nil, fmt.Errorf("sync plugin is not enabled (run 'devlog plugin install sync' first)")
+	}
+
+	pluginCfg, ok := cfg.GetPluginConfig("sync")
+	if !ok {
+		return nil, nil, fmt.Errorf("sync plugin config not found")
+	}
+
+	syncCfg, err := syncPlugin.ParseConfig(pluginCfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse sync config: %w", err)
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, nil, fmt.Errorf("get data directory: %w", err)
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open storage: %w", err)
+	}
+
+	syncer, err := syncPlugin.NewFromConfig(syncCfg, store)
+	if err != nil {
+		store.Close()
+		return nil, nil, err
+	}
+
+	return syncer, store, nil
+}
+
+func syncRunAction(c *cli.Context) error {
+	syncer, store, err := loadSyncer()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+
+	pushed, err := syncer.Push(ctx)
+	if err != nil {
+		return fmt.Errorf("push events: %w", err)
+	}
+	fmt.Printf("✓ Pushed %d events in %d segments\n", pushed.Events, pushed.Segments)
+
+	pulled, err := syncer.Pull(ctx)
+	if err != nil {
+		return fmt.Errorf("pull events: %w", err)
+	}
+	fmt.Printf("✓ Pulled %d events from %d segments", pulled.Events, pulled.Segments)
+	if pulled.Duplicates > 0 {
+		fmt.Printf(" (%d already present)", pulled.Duplicates)
+	}
+	fmt.Println()
+	return nil
+}
+
+func syncStatusAction(c *cli.Context) error {
+	syncer, store, err := loadSyncer()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	status, err := syncer.Status(context.Background())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Machine:  %s\n", status.Machine)
+	fmt.Printf("Pushed:   %d segments (%d rows stored since last push)\n", status.PushedSegments, status.PendingRows)
+	fmt.Println()
+	for _, peer := range status.Peers {
+		if peer.Err != nil {
+			fmt.Printf("  %-20s error: %v\n", peer.Machine, peer.Err)
+			continue
+		}
+		state := "up to date"
+		if behind := peer.Available - peer.Pulled; behind > 0 {
+			state = fmt.Sprintf("%d segments to pull", behind)
+		}
+		fmt.Printf("  %-20s %d/%d segments  %s\n", peer.Machine, peer.Pulled, peer.Available, state)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"devlog/internal/analytics"
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	"devlog/plugins/timetrack"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TimeTrackCommand() *cli.Command {
+	rangeFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "How far back to go (e.g. 1d, 30d)",
+			Value: "7d",
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Usage: "Stop this long ago (e.g. 1d); defaults to now",
+		},
+	}
+
+	return &cli.Command{
+		Name:  "timetrack",
+		Usage: "Turn work sessions into time entries",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "sessions",
+				Usage:  "List the work sessions detected in a time range",
+				Flags:  rangeFlags,
+				Action: timetrackSessionsAction,
+			},
+			{
+				Name:  "export",
+				Usage: "Write sessions as Timewarrior, JSON or CSV time entries",
+				Description: "The timew format is a JSON array for 'timew import':\n" +
+					"      devlog timetrack export --since 1d --format timew | timew import",
+				Flags: append(rangeFlags,
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Value:   timetrack.FormatTimewarrior,
+						Usage:   "Output format: timew, json, csv",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write to a file instead of stdout",
+					},
+				),
+				Action: timetrackExportAction,
+			},
+			{
+				Name:  "push",
+				Usage: "Create Toggl time entries for the sessions in a time range",
+				Description: "Backfills sessions the daemon has not sent. Sessions whose start matches an\n" +
+					"   entry already in Toggl are skipped, so overlapping ranges are safe to push again.",
+				Flags: append(rangeFlags,
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "List the entries without creating them",
+					},
+				),
+				Action: timetrackPushAction,
+			},
+		},
+	}
+}
+
+func loadTimeTrackConfig() (*timetrack.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	pluginCfg, ok := cfg.GetPluginConfig("timetrack")
+	if !ok {
+		return &timetrack.Config{}, nil
+	}
+
+	ttCfg, err := timetrack.ParseConfig(pluginCfg)
+	if err != nil {
+		return nil, fmt.Errorf("parse timetrack config: %w", err)
+	}
+	return ttCfg, nil
+}
+
+func timetrackSessions(c *cli.Context, cfg *timetrack.Config) ([]analytics.Session, time.Time, time.Time, error) {
+	since, err := parseDuration(c.String("since"))
+	if err != nil || since <= 0 {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid --since %q", c.String("since"))
+	}
+	end := time.Now()
+	if until := c.String("until"); until != "" {
+		d, err := parseDuration(until)
+		if err != nil || d < 0 {
+			return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid --until %q", until)
+		}
+		end = end.Add(-d)
+	}
+	start := time.Now().Add(-since)
+	if !end.After(start) {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("--until must be more recent than --since")
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("get data directory: %w", err)
+	}
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return nil, time.Time{}, time.Time{}, fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	sessions, err := timetrack.SessionsBetween(context.Background(), store, start, end, cfg.SessionOptions())
+	return sessions, start, end, err
+}
+
+func timetrackSessionsAction(c *cli.Context) error {
+	cfg, err := loadTimeTrackConfig()
+	if err != nil {
+		return err
+	}
+	sessions, _, _, err := timetrackSessions(c, cfg)
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No sessions found")
+		return nil
+	}
+
+	var total time.Duration
+	day := ""
+	for _, s := range sessions {
+		start := s.Start.Local()
+		if d := start.Format("Mon 2006-01-02"); d != day {
+			day = d
+			fmt.Printf("\n%s\n", day)
+		}
+		fmt.Printf("  %s-%s  %6s  %s\n", start.Format("15:04"), s.End.Local().Format("15:04"),
+			s.Duration().Round(time.Minute), timetrack.Description(s))
+		total += s.Duration()
+	}
+	fmt.Printf("\n%d sessions, %s total\n", len(sessions), total.Round(time.Minute))
+	return nil
+}
+
+func timetrackExportAction(c *cli.Context) error {
+	cfg, err := loadTimeTrackConfig()
+	if err != nil {
+		return err
+	}
+	sessions, _, _, err := timetrackSessions(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if path := c.String("output"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := timetrack.Write(w, c.String("format"), sessions, cfg.Tags); err != nil {
+		return err
+	}
+	if c.String("output") != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d sessions to %s\n", len(sessions), c.String("output"))
+	}
+	return nil
+}
+
+func timetrackPushAction(c *cli.Context) error {
+	cfg, err := loadTimeTrackConfig()
+	if err != nil {
+		return err
+	}
+	sessions, start, end, err := timetrackSessions(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := timetrack.NewTogglClientFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return fmt.Errorf("toggl_api_token is not set in the timetrack plugin config or $TOGGL_API_TOKEN")
+	}
+
+	ctx := context.Background()
+	existing, err := client.Starts(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	created, skipped := 0, 0
+	for _, s := range sessions {
+		if existing[s.Start.UTC().Truncate(time.Second)] {
+			skipped++
+			continue
+		}
+		entry := timetrack.ToToggl(s, cfg)
+		if c.Bool("dry-run") {
+			fmt.Printf("Would create %s-%s %s\n", s.Start.Local().Format("2006-01-02 15:04"), s.End.Local().Format("15:04"), entry.Description)
+			continue
+		}
+		if err := client.Create(ctx, entry); err != nil {
+			return err
+		}
+		created++
+	}
+	if c.Bool("dry-run") {
+		fmt.Printf("%d sessions already in Toggl\n", skipped)
+		return nil
+	}
+	fmt.Printf("✓ Created %d time entries in Toggl (%d already there)\n", created, skipped)
+	return nil
+}
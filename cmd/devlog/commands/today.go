@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	"devlog/plugins/summarizer"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TodayCommand() *cli.Command {
+	return dayViewCommand("today", "Recap today: summaries so far, repos and event counts")
+}
+
+func YesterdayCommand() *cli.Command {
+	return dayViewCommand("yesterday", "Recap yesterday: summaries, repos and event counts")
+}
+
+func dayViewCommand(day, usage string) *cli.Command {
+	return &cli.Command{
+		Name:  day,
+		Usage: usage,
+		Description: "When the day has events but no summaries yet and the summarizer plugin is enabled,\n" +
+			"   the missing summaries are generated first, as with 'devlog summarizer backfill'.\n\n" +
+			"   Examples:\n" +
+			"      devlog " + day + "\n" +
+			"      devlog " + day + " --json | jq '.repos[].name'",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the recap as JSON",
+			},
+			&cli.BoolFlag{
+				Name:  "no-generate",
+				Usage: "Only show stored summaries, never call the LLM",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			return dayViewAction(day, c.Bool("json"), !c.Bool("no-generate"))
+		},
+	}
+}
+
+func dayViewAction(dayStr string, asJSON, generate bool) error {
+	day, err := parseDay(dayStr)
+	if err != nil {
+		return err
+	}
+	end := day.AddDate(0, 0, 1)
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return fmt.Errorf("get data directory: %w", err)
+	}
+
+	// Progress goes to stderr so --json output stays parseable.
+	if generate {
+		if err := generateMissingSummaries(dataDir, day, end, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not generate summaries: %v\n\n", err)
+		}
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	title := fmt.Sprintf("%s - %s", strings.ToUpper(dayStr[:1])+dayStr[1:], day.Format("Monday, January 2"))
+	report, err := summarizer.BuildReport(context.Background(), store, title, day, end)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return writeDayViewJSON(os.Stdout, report)
+	}
+	writeDayView(os.Stdout, report)
+	return nil
+}
+
+// generateMissingSummaries summarizes the day's unsummarized periods when
+// it has none at all. Days the daemon is keeping up with are left alone, so
+// the view stays fast and cheap.
+func generateMissingSummaries(dataDir string, start, end time.Time, out io.Writer) error {
+	cfg, err := config.Load()
+	if err != nil || !cfg.IsPluginEnabled("summarizer") {
+		return nil
+	}
+
+	ctx := context.Background()
+	needed, err := needsSummaries(ctx, dataDir, start, end)
+	if err != nil || !needed {
+		return err
+	}
+
+	fmt.Fprintf(out, "No summaries for %s yet, generating:\n", start.Format("2006-01-02"))
+	plugin, store, err := openBackfillSummarizer(dataDir, out)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	windows, err := plugin.MissingWindows(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	if _, err := summarizeWindows(ctx, plugin, windows, out); err != nil {
+		return err
+	}
+	fmt.Fprintln(out)
+	return nil
+}
+
+// needsSummaries reports whether [start, end) has events but no summaries.
+func needsSummaries(ctx context.Context, dataDir string, start, end time.Time) (bool, error) {
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return false, fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	summaries, err := store.QuerySummariesContext(ctx, start, end)
+	if err != nil || len(summaries) > 0 {
+		return false, err
+	}
+	evts, err := store.QueryEventsContext(ctx, storage.QueryOptions{StartTime: &start, EndTime: &end, Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	return len(evts) > 0, nil
+}
+
+func writeDayView(w io.Writer, r *summarizer.Report) {
+	fmt.Fprintf(w, "# %s\n\n", r.Title)
+
+	if r.TotalEvents == 0 && r.SummaryCount == 0 {
+		fmt.Fprintln(w, "No activity recorded.")
+		return
+	}
+
+	fmt.Fprintf(w, "%d events · %d commits · %d active hours\n", r.TotalEvents, r.Commits, r.ActiveHours)
+
+	if len(r.Repos) > 0 {
+		fmt.Fprintln(w, "\n## Repos")
+		for _, repo := range r.Repos {
+			line := fmt.Sprintf("  %-30s %5d events", filepath.Base(repo.Name), repo.Events)
+			if repo.Commits > 0 {
+				line += fmt.Sprintf(", %d commits", repo.Commits)
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	if len(r.Sources) > 0 {
+		parts := make([]string, len(r.Sources))
+		for i, s := range r.Sources {
+			parts[i] = fmt.Sprintf("%s %d", s.Name, s.Count)
+		}
+		fmt.Fprintf(w, "\nSources: %s\n", strings.Join(parts, ", "))
+	}
+
+	fmt.Fprintln(w, "\n## Summaries")
+	if r.SummaryCount == 0 {
+		fmt.Fprintln(w, "\nNo summaries yet.")
+		return
+	}
+	for _, day := range r.Days {
+		for _, s := range day.Summaries {
+			heading := fmt.Sprintf("%s - %s", s.PeriodStart.Format("15:04"), s.PeriodEnd.Format("15:04"))
+			if s.Repo != "" {
+				heading += " · " + filepath.Base(s.Repo)
+			}
+			fmt.Fprintf(w, "\n### %s\n\n%s\n", heading, strings.TrimSpace(s.Text))
+		}
+	}
+}
+
+type dayViewJSON struct {
+	Date        string               `json:"date"`
+	Events      int                  `json:"events"`
+	Commits     int                  `json:"commits"`
+	ActiveHours int                  `json:"active_hours"`
+	Repos       []dayViewRepoJSON    `json:"repos"`
+	BySource    map[string]int       `json:"by_source"`
+	Summaries   []dayViewSummaryJSON `json:"summaries"`
+}
+
+type dayViewRepoJSON struct {
+	Name    string `json:"name"`
+	Events  int    `json:"events"`
+	Commits int    `json:"commits"`
+}
+
+type dayViewSummaryJSON struct {
+	PeriodStart string   `json:"period_start"`
+	PeriodEnd   string   `json:"period_end"`
+	Repo        string   `json:"repo,omitempty"`
+	Text        string   `json:"text"`
+	EventCount  int      `json:"event_count"`
+	Repos       []string `json:"repos"`
+}
+
+func writeDayViewJSON(w io.Writer, r *summarizer.Report) error {
+	out := dayViewJSON{
+		Date:        r.Start.Format("2006-01-02"),
+		Events:      r.TotalEvents,
+		Commits:     r.Commits,
+		ActiveHours: r.ActiveHours,
+		Repos:       []dayViewRepoJSON{},
+		BySource:    map[string]int{},
+		Summaries:   []dayViewSummaryJSON{},
+	}
+	for _, repo := range r.Repos {
+		out.Repos = append(out.Repos, dayViewRepoJSON{Name: repo.Name, Events: repo.Events, Commits: repo.Commits})
+	}
+	for _, s := range r.Sources {
+		out.BySource[s.Name] = s.Count
+	}
+	for _, day := range r.Days {
+		for _, s := range day.Summaries {
+			repos := s.Repos
+			if repos == nil {
+				repos = []string{}
+			}
+			out.Summaries = append(out.Summaries, dayViewSummaryJSON{
+				PeriodStart: s.PeriodStart.Format(time.RFC3339),
+				PeriodEnd:   s.PeriodEnd.Format(time.RFC3339),
+				Repo:        s.Repo,
+				Text:        s.Text,
+				EventCount:  s.EventCount,
+				Repos:       repos,
+			})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/storage"
+	"devlog/plugins/summarizer"
+)
+
+func testDayReport() *summarizer.Report {
+	day := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	return &summarizer.Report{
+		Title:        "Yesterday - Monday, May 19",
+		Start:        day,
+		End:          day.AddDate(0, 0, 1),
+		TotalEvents:  12,
+		Commits:      3,
+		ActiveHours:  2,
+		SummaryCount: 1,
+		Repos:        []summarizer.ReportRepo{{Name: "/home/me/src/devlog", Events: 10, Commits: 3}},
+		Sources:      []summarizer.ReportCount{{Name: "shell", Count: 9}, {Name: "git", Count: 3}},
+		Days: []summarizer.ReportDay{{
+			Date: day,
+			Summaries: []*storage.Summary{{
+				PeriodStart: day.Add(9 * time.Hour),
+				PeriodEnd:   day.Add(9*time.Hour + 30*time.Minute),
+				Text:        "Fixed the flaky storage tests.",
+				EventCount:  12,
+			}},
+		}},
+	}
+}
+
+func TestWriteDayView(t *testing.T) {
+	var buf bytes.Buffer
+	writeDayView(&buf, testDayReport())
+	out := buf.String()
+
+	for _, want := range []string{"# Yesterday - Monday, May 19", "12 events · 3 commits", "devlog", "3 commits", "shell 9, git 3", "### 09:00 - 09:30", "flaky storage tests"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteDayViewJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeDayViewJSON(&buf, testDayReport()); err != nil {
+		t.Fatalf("writeDayViewJSON() error: %v", err)
+	}
+
+	var got dayViewJSON
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Date != "2025-05-19" || got.Events != 12 || got.Commits != 3 {
+		t.Errorf("got %+v", got)
+	}
+	if len(got.Repos) != 1 || got.Repos[0].Commits != 3 || got.BySource["git"] != 3 {
+		t.Errorf("repos = %+v, by_source = %v", got.Repos, got.BySource)
+	}
+	if len(got.Summaries) != 1 || got.Summaries[0].Repos == nil {
+		t.Errorf("summaries = %+v", got.Summaries)
+	}
+}
+
+func TestWriteDayViewEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	writeDayView(&buf, &summarizer.Report{Title: "Today - Tuesday, May 20"})
+	if !strings.Contains(buf.String(), "No activity recorded.") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"devlog/internal/config"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TokenCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "token",
+		Usage: "Manage API tokens for the HTTP API",
+		Description: "Tokens are required on API requests once http.auth_enabled is true in config.yaml.\n" +
+			"   Only a hash of each token is stored in the config.",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "create",
+				Usage: "Create a new API token and print it once",
+				Description: "Stores the token's hash in config.yaml and, unless --no-save is given, saves the\n" +
+					"   token for this machine's hooks and CLI.\n\n" +
+					"   Examples:\n" +
+					"      devlog token create\n" +
+					"      devlog token create --name laptop --no-save",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Value: "default",
+						Usage: "Name to identify the token by (replaces an existing token with the same name)",
+					},
+					&cli.BoolFlag{
+						Name:  "no-save",
+						Usage: "Only print the token, e.g. for another machine or a script",
+					},
+				},
+				Action: tokenCreateAction,
+			},
+			{
+				Name:   "list",
+				Usage:  "List API tokens",
+				Action: tokenListAction,
+			},
+			{
+				Name:      "revoke",
+				Usage:     "Revoke an API token",
+				ArgsUsage: "<name>",
+				Action:    tokenRevokeAction,
+			},
+		},
+	}
+}
+
+func tokenCreateAction(c *cli.Context) error {
+	name := c.String("name")
+	if name == "" {
+		return fmt.Errorf("--name must not be empty")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	token, err := config.GenerateToken()
+	if err != nil {
+		return err
+	}
+	cfg.AddToken(name, token, time.Now())
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Created token %q\n\n", name)
+	fmt.Printf("  %s\n\n", token)
+	fmt.Println("This is the only time the token is shown.")
+
+	if !c.Bool("no-save") {
+		path, err := config.SaveClientToken(token)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved to %s for this machine's hooks and CLI.\n", path)
+	}
+	if !cfg.HTTP.AuthEnabled {
+		fmt.Println("Set http.auth_enabled: true in config.yaml to require tokens.")
+	}
+	return nil
+}
+
+func tokenListAction(c *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.HTTP.Tokens) == 0 {
+		fmt.Println("No API tokens (create one with 'devlog token create')")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCREATED")
+	for _, t := range cfg.HTTP.Tokens {
+		fmt.Fprintf(w, "%s\t%s\n", t.Name, t.CreatedAt)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if cfg.HTTP.AuthEnabled {
+		fmt.Println("\nAuthentication: enabled")
+	} else {
+		fmt.Println("\nAuthentication: disabled (set http.auth_enabled: true)")
+	}
+	return nil
+}
+
+func tokenRevokeAction(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return fmt.Errorf("token name is required")
+	}
+	name := c.Args().First()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if !cfg.RemoveToken(name) {
+		return fmt.Errorf("no token named %q", name)
+	}
+	if err := cfg.Save(); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Revoked token %q\n", name)
+	if cfg.HTTP.AuthEnabled && len(cfg.HTTP.Tokens) == 0 {
+		fmt.Println("No tokens left: every API request will be rejected until you create one.")
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+	"devlog/plugins/wakatime"
+
+	"github.com/urfave/cli/v2"
+)
+
+func WakaTimeCommand() *cli.Command {
+	rangeFlags := []cli.Flag{
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: "How far back to go (e.g. 1d, 30d)",
+			Value: "7d",
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Usage: "Stop this long ago (e.g. 1d); defaults to now",
+		},
+	}
+
+	return &cli.Command{
+		Name:  "wakatime",
+		Usage: "Export activity as WakaTime heartbeats",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Write heartbeats for a time range as a JSON array",
+				Description: "The output can be fed to 'wakatime-cli --extra-heartbeats' or posted to any\n" +
+					"   WakaTime-compatible heartbeats.bulk endpoint.",
+				Flags: append(rangeFlags,
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write to a file instead of stdout",
+					},
+				),
+				Action: wakatimeExportAction,
+			},
+			{
+				Name:  "push",
+				Usage: "Send heartbeats for a time range to WakaTime or Wakapi",
+				Description: "Backfills history the daemon has not sent. WakaTime ignores heartbeats it\n" +
+					"   already has, so overlapping ranges are safe to push again.",
+				Flags: append(rangeFlags,
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Count the heartbeats without sending them",
+					},
+				),
+				Action: wakatimePushAction,
+			},
+		},
+	}
+}
+
+func loadWakaTimeConfig() (*wakatime.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	pluginCfg, ok := cfg.GetPluginConfig("wakatime")
+	if !ok {
+		return &wakatime.Config{}, nil
+	}
+
+	wakatimeCfg, err := wakatime.ParseConfig(pluginCfg)
+	if err != nil {
+		return nil, fmt.Errorf("parse wakatime config: %w", err)
+	}
+	return wakatimeCfg, nil
+}
+
+func wakatimeHeartbeats(c *cli.Context, cfg *wakatime.Config) ([]wakatime.Heartbeat, error) {
+	since, err := parseDuration(c.String("since"))
+	if err != nil || since <= 0 {
+		return nil, fmt.Errorf("invalid --since %q", c.String("since"))
+	}
+	end := time.Now()
+	if until := c.String("until"); until != "" {
+		d, err := parseDuration(until)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid --until %q", until)
+		}
+		end = end.Add(-d)
+	}
+	start := time.Now().Add(-since)
+	if !end.After(start) {
+		return nil, fmt.Errorf("--until must be more recent than --since")
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, fmt.Errorf("get data directory: %w", err)
+	}
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("open storage: %w", err)
+	}
+	defer store.Close()
+
+	return wakatime.HeartbeatsBetween(context.Background(), store, start, end, cfg.Sources)
+}
+
+func wakatimeExportAction(c *cli.Context) error {
+	cfg, err := loadWakaTimeConfig()
+	if err != nil {
+		return err
+	}
+	beats, err := wakatimeHeartbeats(c, cfg)
+	if err != nil {
+		return err
+	}
+	if beats == nil {
+		beats = []wakatime.Heartbeat{}
+	}
+
+	var w io.Writer = os.Stdout
+	if path := c.String("output"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("create output: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(beats); err != nil {
+		return fmt.Errorf("write heartbeats: %w", err)
+	}
+	if c.String("output") != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d heartbeats to %s\n", len(beats), c.String("output"))
+	}
+	return nil
+}
+
+func wakatimePushAction(c *cli.Context) error {
+	cfg, err := loadWakaTimeConfig()
+	if err != nil {
+		return err
+	}
+	beats, err := wakatimeHeartbeats(c, cfg)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("dry-run") {
+		fmt.Printf("Would send %d heartbeats\n", len(beats))
+		return nil
+	}
+
+	client, err := wakatime.NewClientFromConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if err := client.Send(context.Background(), beats); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Sent %d heartbeats to %s\n", len(beats), client.APIURL)
+	return nil
+}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"devlog/cmd/devlog/formatting"
+	"devlog/internal/config"
+	"devlog/internal/daemon"
+	"devlog/internal/events"
+	"devlog/internal/vcs"
+
+	"github.com/urfave/cli/v2"
+)
+
+const watchReconnectDelay = 2 * time.Second
+
+func WatchCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "watch",
+		Usage: "Print events live as the daemon records them",
+		Description: "Like tail -f for your dev activity. Reconnects if the daemon restarts.\n\n" +
+			"   Examples:\n" +
+			"      devlog watch\n" +
+			"      devlog watch --source git,shell --repo .",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "source",
+				Aliases: []string{"s"},
+				Usage:   "Only show these sources (comma-separated)",
+			},
+			&cli.StringFlag{
+				Name:  "repo",
+				Usage: "Only show events for this repo name, or the repository containing this path (e.g., '.')",
+			},
+			&cli.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disable colored output",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if !daemon.IsRunning() {
+				return fmt.Errorf("daemon is not running (start it with 'devlog daemon start')")
+			}
+
+			cfg, err := config.Load()
+			if err != nil {
+				return err
+			}
+
+			repo, err := watchRepoName(c.String("repo"))
+			if err != nil {
+				return err
+			}
+
+			query := url.Values{}
+			if source := c.String("source"); source != "" {
+				query.Set("source", source)
+			}
+			if repo != "" {
+				query.Set("repo", repo)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			color := !c.Bool("no-color") && formatting.ColorEnabled()
+			return watchEvents(ctx, cfg.HTTP, query, color)
+		},
+	}
+}
+
+// watchRepoName turns a path inside a checkout into its repo name and
+// passes anything else through as a name.
+func watchRepoName(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	if _, err := os.Stat(value); err != nil {
+		return value, nil
+	}
+	absPath, err := filepath.Abs(value)
+	if err != nil {
+		return "", fmt.Errorf("resolve repo path: %w", err)
+	}
+	repo, err := vcs.Detect(absPath)
+	if err != nil {
+		return "", fmt.Errorf("%s is not inside a repository", absPath)
+	}
+	return repo.Name, nil
+}
+
+func watchEvents(ctx context.Context, httpCfg config.HTTPConfig, query url.Values, color bool) error {
+	client := httpCfg.Client(0)
+	streamURL := httpCfg.LocalURL() + "/api/v1/events/stream?" + query.Encode()
+
+	fmt.Fprintln(os.Stderr, "Watching for events (Ctrl-C to stop)...")
+
+	var lastID string
+	connected := false
+	for {
+		err := readEventStream(ctx, client, streamURL, &lastID, func(evt *events.Event) {
+			fmt.Println(formatting.EventLine(evt, color))
+		}, func() {
+			if connected {
+				fmt.Fprintln(os.Stderr, "Reconnected")
+			}
+			connected = true
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !connected {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Connection lost (%v), retrying...\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchReconnectDelay):
+		}
+	}
+}
+
+// readEventStream reads server-sent events until the connection ends,
+// remembering the last event id so a reconnect resumes without gaps.
+func readEventStream(ctx context.Context, client *http.Client, streamURL string, lastID *string, onEvent func(*events.Event), onConnect func()) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, nil)
+	if err != nil {
+		return err
+	}
+	config.AuthorizeRequest(req)
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastID != "" {
+		req.Header.Set("Last-Event-ID", *lastID)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("connect to daemon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("daemon returned error: %d", resp.StatusCode)
+	}
+	onConnect()
+
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 {
+				var evt events.Event
+				if err := json.Unmarshal([]byte(data.String()), &evt); err == nil {
+					onEvent(&evt)
+				}
+				data.Reset()
+			}
+		case strings.HasPrefix(line, "id: "):
+			*lastID = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "data: "):
+			data.WriteString(strings.TrimPrefix(line, "data: "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("stream closed")
+}
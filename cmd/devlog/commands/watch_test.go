@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"devlog/internal/events"
+)
+
+func TestReadEventStream(t *testing.T) {
+	var gotLastID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLastID = r.Header.Get("Last-Event-ID")
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": connected\n\n")
+		fmt.Fprint(w, "id: 7\nevent: event\ndata: {\"id\":\"a\",\"source\":\"git\",\"type\":\"commit\",\"payload\":{}}\n\n")
+		fmt.Fprint(w, ": ping\n\n")
+		fmt.Fprint(w, "id: 9\nevent: event\ndata: {\"id\":\"b\",\"source\":\"shell\",\"type\":\"command\",\"payload\":{}}\n\n")
+	}))
+	defer srv.Close()
+
+	lastID := "3"
+	var got []*events.Event
+	connected := false
+	err := readEventStream(context.Background(), srv.Client(), srv.URL, &lastID,
+		func(evt *events.Event) { got = append(got, evt) },
+		func() { connected = true })
+	if err == nil {
+		t.Error("readEventStream() should report the closed stream")
+	}
+
+	if gotLastID != "3" {
+		t.Errorf("Last-Event-ID = %q, want 3", gotLastID)
+	}
+	if !connected {
+		t.Error("onConnect not called")
+	}
+	if len(got) != 2 || got[0].ID != "a" || got[1].Source != "shell" {
+		t.Fatalf("events = %+v", got)
+	}
+	if lastID != "9" {
+		t.Errorf("lastID = %q, want 9", lastID)
+	}
+}
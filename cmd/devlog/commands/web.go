@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"strings"
 
 	"devlog/internal/config"
+	"devlog/internal/tlscert"
 
 	"github.com/urfave/cli/v2"
 )
@@ -24,7 +26,7 @@ func WebCommand() *cli.Command {
 						return err
 					}
 
-					url := fmt.Sprintf("http://127.0.0.1:%d", cfg.HTTP.Port)
+					url := cfg.HTTP.LocalURL()
 
 					var cmd *exec.Cmd
 					switch runtime.GOOS {
@@ -55,11 +57,75 @@ func WebCommand() *cli.Command {
 						return err
 					}
 
-					url := fmt.Sprintf("http://127.0.0.1:%d", cfg.HTTP.Port)
+					url := cfg.HTTP.LocalURL()
 					fmt.Println(url)
 					return nil
 				},
 			},
+			{
+				Name:  "cert",
+				Usage: "Show or regenerate the self-signed TLS certificate",
+				Description: "Shows the certificate used when http.tls.self_signed is true, including the\n" +
+					"   SHA-256 fingerprint to compare against what your browser shows.\n\n" +
+					"   Examples:\n" +
+					"      devlog web cert\n" +
+					"      devlog web cert --regenerate --host devbox.lan",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "regenerate",
+						Usage: "Create a new certificate (restart the daemon afterwards)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "host",
+						Usage: "Extra DNS name or IP the certificate should cover (repeatable)",
+					},
+				},
+				Action: webCertAction,
+			},
 		},
 	}
 }
+
+func webCertAction(c *cli.Context) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.HTTP.TLS.Cert != "" {
+		return fmt.Errorf("http.tls uses your own certificate (%s); devlog only manages self-signed ones", cfg.HTTP.TLS.Cert)
+	}
+
+	dir, err := config.TLSDir()
+	if err != nil {
+		return err
+	}
+	certPath, _ := tlscert.Paths(dir)
+
+	if c.Bool("regenerate") || len(c.StringSlice("host")) > 0 {
+		hosts := append(tlscert.DefaultHosts(cfg.HTTP.BindAddress), c.StringSlice("host")...)
+		if err := tlscert.Generate(dir, hosts); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Generated %s\n", certPath)
+		fmt.Println("Restart the daemon to use it: devlog daemon restart")
+	}
+
+	cert, err := tlscert.Load(certPath)
+	if err != nil {
+		return fmt.Errorf("no self-signed certificate yet (set http.tls.self_signed: true and start the daemon, or use --regenerate): %w", err)
+	}
+
+	hosts := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		hosts = append(hosts, ip.String())
+	}
+
+	fmt.Printf("Certificate: %s\n", certPath)
+	fmt.Printf("Valid until: %s\n", cert.NotAfter.Local().Format("2006-01-02"))
+	fmt.Printf("Hosts:       %s\n", strings.Join(hosts, ", "))
+	fmt.Printf("SHA-256:     %s\n", tlscert.Fingerprint(cert))
+	if !cfg.HTTP.TLS.SelfSigned {
+		fmt.Println("\nNot in use: set http.tls.self_signed: true to serve it.")
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/storage"
+	"devlog/plugins/webhooks"
+
+	"github.com/urfave/cli/v2"
+)
+
+func WebhooksCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "webhooks",
+		Usage: "Inspect and test outbound webhook targets",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List configured webhook targets",
+				Action: webhooksListAction,
+			},
+			{
+				Name:  "test",
+				Usage: "Send a sample notification to webhook targets",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "target",
+						Usage: "Only send to the named target",
+					},
+					&cli.StringFlag{
+						Name:  "kind",
+						Usage: "Notification to send: summary, event or daily_report (today's report)",
+						Value: webhooks.TriggerEvent,
+					},
+				},
+				Action: webhooksTestAction,
+			},
+		},
+	}
+}
+
+func loadWebhooksConfig() (*webhooks.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+
+	if !cfg.IsPluginEnabled("webhooks") {
+		return nil, fmt.Errorf("webhooks plugin is not enabled (run 'devlog plugin install webhooks' first)")
+	}
+
+	pluginCfg, ok := cfg.GetPluginConfig("webhooks")
+	if !ok {
+		return nil, fmt.Errorf("webhooks plugin config not found")
+	}
+
+	webhooksCfg, err := webhooks.ParseConfig(pluginCfg)
+	if err != nil {
+		return nil, fmt.Errorf("parse webhooks config: %w", err)
+	}
+	return webhooksCfg, nil
+}
+
+func webhooksListAction(c *cli.Context) error {
+	cfg, err := loadWebhooksConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Targets) == 0 {
+		fmt.Println("No webhook targets configured")
+		return nil
+	}
+
+	for _, t := range cfg.Targets {
+		format := t.Format
+		if format == "" {
+			format = webhooks.FormatJSON
+		}
+		fmt.Printf("%-16s %-8s %-28s %s\n", t.Name, format, strings.Join(t.On, ","), t.URL)
+	}
+	if cfg.DailyReportAt != "" {
+		fmt.Printf("\nDaily report sent at %s\n", cfg.DailyReportAt)
+	}
+	return nil
+}
+
+func webhooksTestAction(c *cli.Context) error {
+	cfg, err := loadWebhooksConfig()
+	if err != nil {
+		return err
+	}
+
+	n, err := sampleNotification(c.String("kind"))
+	if err != nil {
+		return err
+	}
+
+	name := c.String("target")
+	sender := webhooks.NewSender()
+	sent := 0
+	var failed []string
+	for _, target := range cfg.Targets {
+		if name != "" && target.Name != name {
+			continue
+		}
+		sent++
+		if err := sender.Send(context.Background(), target, n); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", target.Name, err))
+			continue
+		}
+		fmt.Printf("✓ Sent %s notification to %s\n", n.Kind, target.Name)
+	}
+
+	if sent == 0 {
+		if name != "" {
+			return fmt.Errorf("no webhook target named %q", name)
+		}
+		return fmt.Errorf("no webhook targets configured")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("webhook delivery failed:\n  %s", strings.Join(failed, "\n  "))
+	}
+	return nil
+}
+
+func sampleNotification(kind string) (*webhooks.Notification, error) {
+	now := time.Now()
+	switch kind {
+	case webhooks.TriggerEvent:
+		evt := events.NewEvent("devlog", "webhook_test")
+		evt.Payload["text"] = "Test notification from devlog"
+		return webhooks.EventNotification(evt), nil
+	case webhooks.TriggerSummary:
+		return webhooks.SummaryNotification(&storage.Summary{
+			PeriodStart: now.Add(-15 * time.Minute),
+			PeriodEnd:   now,
+			Text:        "Test summary from devlog.",
+		}), nil
+	case webhooks.TriggerDailyReport:
+		dataDir, err := config.DataDir()
+		if err != nil {
+			return nil, fmt.Errorf("get data directory: %w", err)
+		}
+		store, err := storage.OpenConfigured(dataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open storage: %w", err)
+		}
+		defer store.Close()
+
+		dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return webhooks.BuildDailyReport(context.Background(), store, dayStart)
+	default:
+		return nil, fmt.Errorf("unknown --kind %q (use summary, event or daily_report)", kind)
+	}
+}
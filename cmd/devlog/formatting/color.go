@@ -0,0 +1,57 @@
+package formatting
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"devlog/internal/events"
+	internalFormatting "devlog/internal/formatting"
+)
+
+const colorReset = "\033[0m"
+
+var sourceColors = map[string]string{
+	string(events.SourceGit):       "\033[32m",
+	string(events.SourceShell):     "\033[36m",
+	string(events.SourceClaude):    "\033[35m",
+	string(events.SourceGitHub):    "\033[34m",
+	string(events.SourceGitLab):    "\033[34m",
+	string(events.SourceBitbucket): "\033[34m",
+	string(events.SourceKubectl):   "\033[94m",
+	string(events.SourceTerraform): "\033[95m",
+	string(events.SourceTests):     "\033[92m",
+	string(events.SourceSSH):       "\033[37m",
+	string(events.SourceClipboard): "\033[33m",
+	string(events.SourceWisprflow): "\033[93m",
+	string(events.SourceTmux):      "\033[96m",
+	string(events.SourceActivity):  "\033[90m",
+	string(events.SourceManual):    "\033[97m",
+	string(events.SourcePatterns):  "\033[91m",
+}
+
+// ColorEnabled reports whether stdout is a terminal that should get ANSI
+// colors, honouring NO_COLOR.
+func ColorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// EventLine renders an event on one line for live output, with the type tag
+// colored by source when color is set.
+func EventLine(event *events.Event, color bool) string {
+	ts, _ := time.Parse(time.RFC3339, event.Timestamp)
+	tag := fmt.Sprintf("%-12s", getTypeTag(event))
+	if code, ok := sourceColors[event.Source]; ok && color {
+		tag = code + tag + colorReset
+	}
+
+	line := fmt.Sprintf("%s %s ", ts.Local().Format("15:04:05"), tag)
+	if folder := getFolder(event); folder != "" {
+		line += folder + ": "
+	}
+	return line + internalFormatting.FormatEventContent(event)
+}
@@ -9,11 +9,15 @@ import (
 	"devlog/internal/events"
 	internalFormatting "devlog/internal/formatting"
 
+	_ "devlog/modules/activity"
 	_ "devlog/modules/claude"
 	_ "devlog/modules/clipboard"
 	_ "devlog/modules/git"
 	_ "devlog/modules/kubectl"
 	_ "devlog/modules/shell"
+	_ "devlog/modules/ssh"
+	_ "devlog/modules/terraform"
+	_ "devlog/modules/tests"
 	_ "devlog/modules/wisprflow"
 )
 
@@ -46,7 +50,10 @@ func getTypeTag(event *events.Event) string {
 		return "shell"
 	case "note":
 		return "note"
-	case "pr_merged":
+	case "pr_opened", "pr_merged", "pr_closed", "pr_review", "issue_opened", "issue_closed", "workflow_run":
+		if event.Source == string(events.SourceGitLab) || event.Source == string(events.SourceBitbucket) {
+			return event.Source
+		}
 		return "github"
 	case "transcription":
 		return "voice"
@@ -54,6 +61,12 @@ func getTypeTag(event *events.Event) string {
 		return "conversation"
 	case "file_edit":
 		return "edit"
+	case "tool_call":
+		return "tool"
+	case "test_run":
+		return "test"
+	case "terraform_plan", "terraform_apply", "terraform_destroy":
+		return "terraform"
 	default:
 		return event.Type
 	}
@@ -10,16 +10,33 @@ import (
 
 	"github.com/urfave/cli/v2"
 
+	_ "devlog/modules/activity"
+	_ "devlog/modules/ci"
 	_ "devlog/modules/claude"
+	_ "devlog/modules/forge"
 	_ "devlog/modules/git"
+	_ "devlog/modules/github"
 	_ "devlog/modules/kubectl"
 	_ "devlog/modules/shell"
+	_ "devlog/modules/ssh"
+	_ "devlog/modules/terraform"
+	_ "devlog/modules/tests"
 	_ "devlog/modules/tmux"
 	_ "devlog/modules/wisprflow"
 
+	_ "devlog/plugins/archiver"
+	_ "devlog/plugins/backup"
+	_ "devlog/plugins/embeddings"
+	_ "devlog/plugins/issues"
 	_ "devlog/plugins/llm"
+	_ "devlog/plugins/obsidian"
+	_ "devlog/plugins/patterns"
 	_ "devlog/plugins/query"
 	_ "devlog/plugins/summarizer"
+	_ "devlog/plugins/sync"
+	_ "devlog/plugins/timetrack"
+	_ "devlog/plugins/wakatime"
+	_ "devlog/plugins/webhooks"
 )
 
 func main() {
@@ -28,10 +45,25 @@ func main() {
 		commands.ConfigCommand(),
 		commands.DaemonCommand(),
 		commands.StatusCommand(),
+		commands.TodayCommand(),
+		commands.YesterdayCommand(),
+		commands.WatchCommand(),
 		commands.SearchCommand(),
+		commands.NoteCommand(),
+		commands.AnnotateCommand(),
+		commands.PauseCommand(),
+		commands.ResumeCommand(),
+		commands.EncryptionCommand(),
+		commands.PruneCommand(),
+		commands.RedactCommand(),
+		commands.DBCommand(),
+		commands.MetricsCommand(),
+		commands.StatsCommand(),
 		commands.ModuleCommand(),
 		commands.PluginCommand(),
+		commands.TokenCommand(),
 		commands.WebCommand(),
+		commands.MCPCommand(),
 		commands.VersionCommand(),
 	}
 
@@ -42,12 +74,47 @@ func main() {
 	cfg, err := config.Load()
 	var pluginCommands []*cli.Command
 
+	if err == nil && cfg.IsPluginEnabled("llm") {
+		pluginCommands = append(pluginCommands, commands.LLMCommand())
+	}
+
 	if err == nil && cfg.IsPluginEnabled("query") {
 		pluginCommands = append(pluginCommands, commands.QueryCommand())
 	}
 
+	if err == nil && cfg.IsPluginEnabled("archiver") {
+		pluginCommands = append(pluginCommands, commands.ArchiverCommand())
+	}
+
+	if err == nil && cfg.IsPluginEnabled("backup") {
+		pluginCommands = append(pluginCommands, commands.BackupCommand())
+	}
+
+	if err == nil && cfg.IsPluginEnabled("issues") {
+		pluginCommands = append(pluginCommands, commands.IssuesCommand())
+	}
+
 	if err == nil && cfg.IsPluginEnabled("summarizer") {
 		pluginCommands = append(pluginCommands, commands.SummarizerCommand())
+		pluginCommands = append(pluginCommands, commands.ReportCommand())
+		pluginCommands = append(pluginCommands, commands.StandupCommand())
+		pluginCommands = append(pluginCommands, commands.ShareCommand())
+	}
+
+	if err == nil && cfg.IsPluginEnabled("sync") {
+		pluginCommands = append(pluginCommands, commands.SyncCommand())
+	}
+
+	if err == nil && cfg.IsPluginEnabled("timetrack") {
+		pluginCommands = append(pluginCommands, commands.TimeTrackCommand())
+	}
+
+	if err == nil && cfg.IsPluginEnabled("webhooks") {
+		pluginCommands = append(pluginCommands, commands.WebhooksCommand())
+	}
+
+	if err == nil && cfg.IsPluginEnabled("wakatime") {
+		pluginCommands = append(pluginCommands, commands.WakaTimeCommand())
 	}
 
 	for _, cmd := range pluginCommands {
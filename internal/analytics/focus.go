@@ -0,0 +1,230 @@
+// Package analytics derives productivity metrics from stored events.
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"devlog/internal/events"
+)
+
+const (
+	DefaultIdleGap     = 15 * time.Minute
+	DefaultDeepWorkMin = 45 * time.Minute
+
+	SwitchRepo        = "repo"
+	SwitchBranch      = "branch"
+	SwitchTmuxSession = "tmux_session"
+)
+
+// remoteSources record what happened elsewhere (webhooks and polled
+// forges), not where you were working, so they never move the context.
+var remoteSources = map[string]bool{
+	string(events.SourceGitHub):    true,
+	string(events.SourceGitLab):    true,
+	string(events.SourceBitbucket): true,
+	string(events.SourceActivity):  true,
+}
+
+type FocusOptions struct {
+	// IdleGap is the longest pause between two events that still counts as
+	// working. A longer pause ends the focus streak.
+	IdleGap time.Duration
+	// DeepWorkMin is how long a streak must last to be a deep-work window.
+	DeepWorkMin time.Duration
+}
+
+func (o FocusOptions) withDefaults() FocusOptions {
+	if o.IdleGap <= 0 {
+		o.IdleGap = DefaultIdleGap
+	}
+	if o.DeepWorkMin <= 0 {
+		o.DeepWorkMin = DefaultDeepWorkMin
+	}
+	return o
+}
+
+// Switch is one change of repo, branch or tmux session while working.
+type Switch struct {
+	At   time.Time
+	Kind string
+	From string
+	To   string
+}
+
+// Streak is an uninterrupted stretch of work in a single repo.
+type Streak struct {
+	Repo     string
+	Branches []string
+	Start    time.Time
+	End      time.Time
+	Events   int
+	Switches int // branch and tmux session switches inside the streak
+}
+
+func (s Streak) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+type FocusReport struct {
+	Start  time.Time
+	End    time.Time
+	Events int
+	// Active is the time between consecutive events no further apart than
+	// the idle gap.
+	Active   time.Duration
+	Switches []Switch
+	ByKind   map[string]int
+	// ByHour counts switches per hour of the day, local time.
+	ByHour [24]int
+	// Streaks holds every focus streak, longest first.
+	Streaks  []Streak
+	DeepWork []Streak
+}
+
+// SwitchesPerHour is the switch rate over active time.
+func (r *FocusReport) SwitchesPerHour() float64 {
+	if r.Active < time.Minute {
+		return 0
+	}
+	return float64(len(r.Switches)) / r.Active.Hours()
+}
+
+// Longest returns the longest focus streak, or nil without any.
+func (r *FocusReport) Longest() *Streak {
+	if len(r.Streaks) == 0 {
+		return nil
+	}
+	return &r.Streaks[0]
+}
+
+func (r *FocusReport) DeepWorkTime() time.Duration {
+	var total time.Duration
+	for _, s := range r.DeepWork {
+		total += s.Duration()
+	}
+	return total
+}
+
+type point struct {
+	at          time.Time
+	repo        string
+	branch      string
+	tmuxSession string
+}
+
+// Focus computes context switches, focus streaks and deep-work windows from
+// events in [start, end). Events may come in any order.
+//
+// A switch is a change of repo, of branch within a repo, or of tmux session
+// between two events at most IdleGap apart; starting on something new after
+// a break is not a switch. A streak runs while events stay in one repo with
+// no idle gap; events without a repo neither extend nor break it.
+func Focus(evts []*events.Event, start, end time.Time, opts FocusOptions) *FocusReport {
+	opts = opts.withDefaults()
+	report := &FocusReport{Start: start, End: end, ByKind: make(map[string]int)}
+
+	points := make([]point, 0, len(evts))
+	for _, e := range evts {
+		if remoteSources[e.Source] {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil || at.Before(start) || !at.Before(end) {
+			continue
+		}
+		p := point{at: at, repo: e.Repo, branch: e.Branch}
+		if e.Source == string(events.SourceTmux) {
+			p.tmuxSession, _ = e.Payload["session"].(string)
+		}
+		points = append(points, p)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+	report.Events = len(points)
+
+	var (
+		repo, branch, tmuxSession string
+		lastAt                    time.Time
+		current                   *Streak
+	)
+	closeStreak := func() {
+		if current != nil {
+			report.Streaks = append(report.Streaks, *current)
+		}
+		current = nil
+	}
+
+	for i, p := range points {
+		working := i > 0 && p.at.Sub(lastAt) <= opts.IdleGap
+		if working {
+			report.Active += p.at.Sub(lastAt)
+		} else {
+			closeStreak()
+		}
+		lastAt = p.at
+
+		// One event counts as at most one switch: entering another tmux
+		// session usually means another repo too.
+		var sw *Switch
+		if p.repo != "" && repo != "" && p.repo != repo {
+			sw = &Switch{Kind: SwitchRepo, From: repo, To: p.repo}
+		} else if p.repo != "" && p.repo == repo && p.branch != "" && branch != "" && p.branch != branch {
+			sw = &Switch{Kind: SwitchBranch, From: branch, To: p.branch}
+		} else if p.tmuxSession != "" && tmuxSession != "" && p.tmuxSession != tmuxSession {
+			sw = &Switch{Kind: SwitchTmuxSession, From: tmuxSession, To: p.tmuxSession}
+		}
+		if sw != nil && working {
+			sw.At = p.at
+			report.Switches = append(report.Switches, *sw)
+			report.ByKind[sw.Kind]++
+			report.ByHour[p.at.Local().Hour()]++
+			if current != nil && sw.Kind != SwitchRepo {
+				current.Switches++
+			}
+		}
+
+		if p.tmuxSession != "" {
+			tmuxSession = p.tmuxSession
+		}
+		if p.repo == "" {
+			continue
+		}
+		if p.repo != repo {
+			closeStreak()
+			repo, branch = p.repo, ""
+		}
+		if p.branch != "" {
+			branch = p.branch
+		}
+
+		if current == nil {
+			current = &Streak{Repo: p.repo, Start: p.at}
+		}
+		current.End = p.at
+		current.Events++
+		if p.branch != "" && !containsString(current.Branches, p.branch) {
+			current.Branches = append(current.Branches, p.branch)
+		}
+	}
+	closeStreak()
+
+	sort.SliceStable(report.Streaks, func(i, j int) bool {
+		return report.Streaks[i].Duration() > report.Streaks[j].Duration()
+	})
+	for _, s := range report.Streaks {
+		if s.Duration() >= opts.DeepWorkMin {
+			report.DeepWork = append(report.DeepWork, s)
+		}
+	}
+	sort.Slice(report.DeepWork, func(i, j int) bool { return report.DeepWork[i].Start.Before(report.DeepWork[j].Start) })
+	return report
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
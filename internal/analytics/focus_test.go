@@ -0,0 +1,112 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+var base = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func at(minutes int, source, repo, branch string) *events.Event {
+	e := events.NewEvent(source, string(events.TypeCommand))
+	e.Timestamp = base.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339)
+	e.Repo = repo
+	e.Branch = branch
+	return e
+}
+
+func tmux(minutes int, session string) *events.Event {
+	e := at(minutes, string(events.SourceTmux), "", "")
+	e.Type = string(events.TypeTmuxSession)
+	e.Payload["session"] = session
+	return e
+}
+
+func TestFocusSwitchesAndStreaks(t *testing.T) {
+	shell := string(events.SourceShell)
+	evts := []*events.Event{
+		at(0, shell, "api", "main"),
+		at(10, shell, "api", "main"),
+		at(20, shell, "api", "feature"), // branch switch
+		at(30, shell, "api", "feature"),
+		at(40, shell, "api", "feature"),
+		at(50, shell, "api", "feature"),
+		at(55, shell, "web", "main"), // repo switch ends the api streak
+		tmux(58, "web"),
+		tmux(60, "dotfiles"), // tmux switch
+		at(62, shell, "web", "main"),
+		// Back after lunch in another repo: a new streak, not a switch.
+		at(180, shell, "api", "feature"),
+		at(190, string(events.SourceGitHub), "infra", "main"), // remote, ignored
+		at(195, shell, "api", "feature"),
+	}
+
+	r := Focus(evts, base, base.Add(8*time.Hour), FocusOptions{})
+
+	if r.Events != 12 {
+		t.Errorf("Events = %d, want 12 (github ignored)", r.Events)
+	}
+	if len(r.Switches) != 3 || r.ByKind[SwitchBranch] != 1 || r.ByKind[SwitchRepo] != 1 || r.ByKind[SwitchTmuxSession] != 1 {
+		t.Errorf("switches = %+v, want one of each kind", r.Switches)
+	}
+	if sw := r.Switches[1]; sw.Kind != SwitchRepo || sw.From != "api" || sw.To != "web" {
+		t.Errorf("second switch = %+v, want api -> web", sw)
+	}
+	if r.Active != 77*time.Minute {
+		t.Errorf("Active = %v, want 1h17m", r.Active)
+	}
+
+	longest := r.Longest()
+	if longest == nil || longest.Repo != "api" || longest.Duration() != 50*time.Minute || longest.Events != 6 {
+		t.Fatalf("longest = %+v, want 50m in api", longest)
+	}
+	if longest.Switches != 1 || len(longest.Branches) != 2 {
+		t.Errorf("longest streak switches %d branches %v, want 1 and main+feature", longest.Switches, longest.Branches)
+	}
+	if len(r.Streaks) != 3 {
+		t.Errorf("got %d streaks, want 3", len(r.Streaks))
+	}
+	if len(r.DeepWork) != 1 || r.DeepWorkTime() != 50*time.Minute {
+		t.Errorf("deep work = %+v, want the api streak", r.DeepWork)
+	}
+
+	want := 3 / (77.0 / 60)
+	if got := r.SwitchesPerHour(); got < want-0.01 || got > want+0.01 {
+		t.Errorf("SwitchesPerHour() = %.2f, want %.2f", got, want)
+	}
+}
+
+func TestFocusOptions(t *testing.T) {
+	shell := string(events.SourceShell)
+	evts := []*events.Event{
+		at(0, shell, "api", ""),
+		at(20, shell, "api", ""),
+		at(40, shell, "web", ""),
+	}
+
+	// With a 10 minute idle gap nothing counts as continuous work.
+	r := Focus(evts, base, base.Add(time.Hour), FocusOptions{IdleGap: 10 * time.Minute})
+	if len(r.Switches) != 0 || r.Active != 0 || len(r.Streaks) != 3 {
+		t.Errorf("switches %d active %v streaks %d, want 0, 0 and 3", len(r.Switches), r.Active, len(r.Streaks))
+	}
+
+	r = Focus(evts, base, base.Add(time.Hour), FocusOptions{IdleGap: 30 * time.Minute, DeepWorkMin: 20 * time.Minute})
+	if len(r.Switches) != 1 || len(r.DeepWork) != 1 || r.DeepWork[0].Repo != "api" {
+		t.Errorf("switches %d deep work %+v, want one switch and api as deep work", len(r.Switches), r.DeepWork)
+	}
+
+	// Events outside the range are left out.
+	r = Focus(evts, base.Add(30*time.Minute), base.Add(time.Hour), FocusOptions{})
+	if r.Events != 1 {
+		t.Errorf("Events = %d, want 1 inside the range", r.Events)
+	}
+}
+
+func TestFocusEmpty(t *testing.T) {
+	r := Focus(nil, base, base.Add(time.Hour), FocusOptions{})
+	if r.Longest() != nil || r.SwitchesPerHour() != 0 || len(r.DeepWork) != 0 {
+		t.Errorf("empty report = %+v", r)
+	}
+}
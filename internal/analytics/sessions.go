@@ -0,0 +1,118 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"devlog/internal/events"
+)
+
+const DefaultMinSession = 5 * time.Minute
+
+// Session is a stretch of continuous work on one repo and, when the issues
+// plugin tagged its events, one issue. Sessions are what time tracking
+// exports turn into time entries.
+type Session struct {
+	Repo       string
+	Branch     string
+	Issue      string
+	IssueTitle string
+	Start      time.Time
+	End        time.Time
+	Events     int
+}
+
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+type SessionOptions struct {
+	// IdleGap is the longest pause that still counts as working.
+	IdleGap time.Duration
+	// MinDuration drops sessions shorter than this, such as a lone commit.
+	MinDuration time.Duration
+}
+
+func (o SessionOptions) withDefaults() SessionOptions {
+	if o.IdleGap <= 0 {
+		o.IdleGap = DefaultIdleGap
+	}
+	if o.MinDuration <= 0 {
+		o.MinDuration = DefaultMinSession
+	}
+	return o
+}
+
+// Sessions splits events into sessions. Events may come in any order.
+//
+// A session ends at an idle gap, or when work moves to another repo or
+// issue; in the latter case it runs until the first event of the next one,
+// so back-to-back sessions leave no hole. Events without a repo extend the
+// current session, and events without an issue stay in it, taking the
+// issue of the first event that has one.
+func Sessions(evts []*events.Event, opts SessionOptions) []Session {
+	opts = opts.withDefaults()
+
+	type point struct {
+		at                time.Time
+		repo, branch      string
+		issue, issueTitle string
+	}
+	points := make([]point, 0, len(evts))
+	for _, e := range evts {
+		if remoteSources[e.Source] {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+		p := point{at: at, repo: e.Repo, branch: e.Branch}
+		p.issue, _ = e.Payload["issue"].(string)
+		p.issueTitle, _ = e.Payload["issue_title"].(string)
+		points = append(points, p)
+	}
+	sort.SliceStable(points, func(i, j int) bool { return points[i].at.Before(points[j].at) })
+
+	var (
+		sessions []Session
+		current  *Session
+	)
+	closeSession := func() {
+		if current != nil && current.Duration() >= opts.MinDuration {
+			sessions = append(sessions, *current)
+		}
+		current = nil
+	}
+
+	for _, p := range points {
+		if current != nil && p.at.Sub(current.End) > opts.IdleGap {
+			closeSession()
+		}
+		if current != nil && p.repo != "" &&
+			(p.repo != current.Repo || (p.issue != "" && current.Issue != "" && p.issue != current.Issue)) {
+			current.End = p.at
+			closeSession()
+		}
+
+		if current == nil {
+			if p.repo == "" {
+				continue
+			}
+			current = &Session{Repo: p.repo, Start: p.at}
+		}
+		current.End = p.at
+		current.Events++
+		if p.branch != "" && p.repo == current.Repo {
+			current.Branch = p.branch
+		}
+		if p.issue != "" && current.Issue == "" {
+			current.Issue = p.issue
+		}
+		if p.issueTitle != "" && p.issue == current.Issue {
+			current.IssueTitle = p.issueTitle
+		}
+	}
+	closeSession()
+	return sessions
+}
@@ -0,0 +1,66 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func withIssue(e *events.Event, issue string) *events.Event {
+	e.Payload["issue"] = issue
+	return e
+}
+
+func TestSessions(t *testing.T) {
+	shell := string(events.SourceShell)
+	evts := []*events.Event{
+		at(0, shell, "api", "main"),
+		at(5, shell, "", ""), // no repo, extends the session
+		withIssue(at(10, string(events.SourceGit), "api", "PROJ-1-login"), "PROJ-1"),
+		at(22, shell, "api", "PROJ-1-login"),
+		withIssue(at(35, shell, "api", "PROJ-2"), "PROJ-2"), // issue change
+		at(50, shell, "api", "PROJ-2"),
+		at(55, string(events.SourceGitHub), "web", "main"), // remote, ignored
+		at(58, shell, "web", "main"),                       // repo change
+		at(59, shell, "web", "main"),                       // too short once idle
+		at(120, shell, "api", "main"),                      // after a break
+		at(130, shell, "api", "main"),
+	}
+
+	got := Sessions(evts, SessionOptions{})
+	want := []struct {
+		repo, issue string
+		start, end  int
+	}{
+		{"api", "PROJ-1", 0, 35},
+		{"api", "PROJ-2", 35, 58},
+		{"api", "", 120, 130},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d sessions %+v, want %d", len(got), got, len(want))
+	}
+	for i, w := range want {
+		s := got[i]
+		if s.Repo != w.repo || s.Issue != w.issue ||
+			!s.Start.Equal(base.Add(time.Duration(w.start)*time.Minute)) ||
+			!s.End.Equal(base.Add(time.Duration(w.end)*time.Minute)) {
+			t.Errorf("session %d = %+v, want %s %s %d-%dm", i, s, w.repo, w.issue, w.start, w.end)
+		}
+	}
+	if got[0].Events != 4 || got[0].Branch != "PROJ-1-login" {
+		t.Errorf("first session = %+v, want 4 events on PROJ-1-login", got[0])
+	}
+}
+
+func TestSessionsMinDuration(t *testing.T) {
+	shell := string(events.SourceShell)
+	evts := []*events.Event{at(0, shell, "api", ""), at(3, shell, "api", "")}
+
+	if got := Sessions(evts, SessionOptions{}); len(got) != 0 {
+		t.Errorf("got %+v, want short session dropped", got)
+	}
+	if got := Sessions(evts, SessionOptions{MinDuration: time.Minute}); len(got) != 1 {
+		t.Errorf("got %d sessions, want 1 with a 1m minimum", len(got))
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path"
+	"strings"
+
+	"devlog/internal/api"
+)
+
+// goClient renders the types and Client methods of the spec as Go source.
+// The transport (Client, APIError, do) is hand-written in the target
+// package.
+func goClient(doc *api.OpenAPIDocument, pkg string) ([]byte, error) {
+	var b bytes.Buffer
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		writeGoStruct(&b, name, doc.Components.Schemas[name])
+	}
+
+	for _, op := range operations(doc) {
+		if op.response == nil && streams(doc, op) {
+			continue
+		}
+		if len(op.queryParams) > 0 {
+			writeGoParams(&b, op)
+		}
+		writeGoMethod(&b, op)
+	}
+
+	var file bytes.Buffer
+	fmt.Fprintf(&file, "// Code generated by apigen from the devlog OpenAPI spec. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&file, "package %s\n\nimport (\n", pkg)
+	for _, imp := range []string{"context", "encoding/json", "net/url", "strconv"} {
+		if imp == "context" || bytes.Contains(b.Bytes(), []byte(path.Base(imp)+".")) {
+			fmt.Fprintf(&file, "\t%q\n", imp)
+		}
+	}
+	file.WriteString(")\n")
+	file.Write(b.Bytes())
+
+	src, err := format.Source(file.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated Go client: %w", err)
+	}
+	return src, nil
+}
+
+func writeGoStruct(b *bytes.Buffer, name string, schema *api.Schema) {
+	fmt.Fprintf(b, "\ntype %s struct {\n", name)
+	for _, prop := range sortedKeys(schema.Properties) {
+		tag, typ := prop, goType(schema.Properties[prop])
+		if !isRequired(schema, prop) {
+			tag += ",omitempty"
+			// Optional objects are pointers so an absent one reads as nil.
+			if schema.Properties[prop].Ref != "" {
+				typ = "*" + typ
+			}
+		}
+		fmt.Fprintf(b, "\t%s %s `json:\"%s\"`\n", exportName(prop), typ, tag)
+	}
+	b.WriteString("}\n")
+}
+
+func goType(s *api.Schema) string {
+	if s.Ref != "" {
+		return s.RefName()
+	}
+	switch s.Type {
+	case "array":
+		if s.MinItems != nil && s.MaxItems != nil && *s.MinItems == *s.MaxItems {
+			return fmt.Sprintf("[%d]%s", *s.MinItems, goType(s.Items))
+		}
+		return "[]" + goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + goType(s.AdditionalProperties)
+		}
+		return "map[string]interface{}"
+	case "string":
+		return "string"
+	case "boolean":
+		return "bool"
+	case "integer":
+		if s.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	default:
+		return "interface{}"
+	}
+}
+
+func writeGoParams(b *bytes.Buffer, op operation) {
+	name := op.name + "Params"
+	fmt.Fprintf(b, "\n// %s holds the query parameters of %s. Zero values are left out.\n", name, op.name)
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, p := range op.queryParams {
+		if p.Description != "" {
+			fmt.Fprintf(b, "\t// %s\n", p.Description)
+		}
+		fmt.Fprintf(b, "\t%s %s\n", exportName(p.Name), goType(p.Schema))
+	}
+	b.WriteString("}\n")
+
+	fmt.Fprintf(b, "\nfunc (p *%s) values() url.Values {\n\tq := url.Values{}\n\tif p == nil {\n\t\treturn q\n\t}\n", name)
+	for _, p := range op.queryParams {
+		field := "p." + exportName(p.Name)
+		switch p.Schema.Type {
+		case "array":
+			fmt.Fprintf(b, "\tfor _, v := range %s {\n\t\tq.Add(%q, v)\n\t}\n", field, p.Name)
+		case "integer":
+			fmt.Fprintf(b, "\tif %s != 0 {\n\t\tq.Set(%q, strconv.Itoa(%s))\n\t}\n", field, p.Name, field)
+		default:
+			fmt.Fprintf(b, "\tif %s != \"\" {\n\t\tq.Set(%q, %s)\n\t}\n", field, p.Name, field)
+		}
+	}
+	b.WriteString("\treturn q\n}\n")
+}
+
+func writeGoMethod(b *bytes.Buffer, op operation) {
+	args := []string{"ctx context.Context"}
+	urlPath := fmt.Sprintf("%q", op.path)
+	for _, p := range op.pathParams {
+		args = append(args, p.Name+" string")
+		urlPath = strings.Replace(urlPath, "{"+p.Name+"}", `" + url.PathEscape(`+p.Name+`) + "`, 1)
+	}
+	urlPath = strings.TrimSuffix(urlPath, ` + ""`)
+
+	var encode, bodyArg, contentType string
+	bodyArg, contentType = "nil", `""`
+	if op.body != nil {
+		contentType = fmt.Sprintf("%q", op.bodyType)
+		switch {
+		case op.body.Ref == "" && op.body.Type == "":
+			args = append(args, "body []byte")
+			bodyArg = "body"
+		case op.bodyType == "application/x-ndjson":
+			args = append(args, "body "+goType(op.body))
+			encode = "data, err := encodeNDJSON(body)"
+			bodyArg = "data"
+		default:
+			args = append(args, "body *"+goType(op.body))
+			encode = "data, err := json.Marshal(body)"
+			bodyArg = "data"
+		}
+	}
+
+	query := "nil"
+	if len(op.queryParams) > 0 {
+		args = append(args, "params *"+op.name+"Params")
+		query = "params.values()"
+	}
+
+	result, ret := "map[string]interface{}", "out"
+	if op.response != nil && op.response.Ref != "" {
+		result, ret = "*"+op.response.RefName(), "&out"
+	} else if op.response != nil {
+		result = goType(op.response)
+	}
+
+	fmt.Fprintf(b, "\n// %s calls %s %s: %s.\n", op.name, op.method, op.path, lowerFirst(op.summary))
+	fmt.Fprintf(b, "func (c *Client) %s(%s) (%s, error) {\n", op.name, strings.Join(args, ", "), result)
+	if encode != "" {
+		fmt.Fprintf(b, "\t%s\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", encode)
+	}
+	fmt.Fprintf(b, "\tvar out %s\n", strings.TrimPrefix(result, "*"))
+	fmt.Fprintf(b, "\tif err := c.do(ctx, %q, %s, %s, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n",
+		op.method, urlPath, query, contentType, bodyArg)
+	fmt.Fprintf(b, "\treturn %s, nil\n}\n", ret)
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
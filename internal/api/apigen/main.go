@@ -0,0 +1,65 @@
+// Command apigen writes the OpenAPI document of the daemon's HTTP API and
+// generates Go and TypeScript clients from it. It is run through go generate
+// in pkg/devlog/apiclient:
+//
+//	go generate ./pkg/devlog/apiclient
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"devlog/internal/api"
+)
+
+func main() {
+	goOut := flag.String("go", "", "write the Go client to this file")
+	goPkg := flag.String("package", "apiclient", "package name of the Go client")
+	tsOut := flag.String("ts", "", "write the TypeScript client to this file")
+	specOut := flag.String("spec", "", "write the OpenAPI document to this file")
+	flag.Parse()
+
+	if err := run(*goOut, *goPkg, *tsOut, *specOut); err != nil {
+		fmt.Fprintf(os.Stderr, "apigen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(goOut, goPkg, tsOut, specOut string) error {
+	doc := api.OpenAPISpec()
+
+	if specOut != "" {
+		data, err := specJSON(doc)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(specOut, data, 0644); err != nil {
+			return err
+		}
+	}
+	if goOut != "" {
+		src, err := goClient(doc, goPkg)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(goOut, src, 0644); err != nil {
+			return err
+		}
+	}
+	if tsOut != "" {
+		if err := os.WriteFile(tsOut, tsClient(doc), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func specJSON(doc *api.OpenAPIDocument) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
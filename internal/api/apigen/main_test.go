@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"devlog/internal/api"
+)
+
+// TestGeneratedClientsUpToDate fails when the API changed without
+// re-running go generate ./pkg/devlog/apiclient.
+func TestGeneratedClientsUpToDate(t *testing.T) {
+	doc := api.OpenAPISpec()
+	dir := filepath.Join("..", "..", "..", "pkg", "devlog", "apiclient")
+
+	spec, err := specJSON(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goSrc, err := goClient(doc, "apiclient")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for file, want := range map[string][]byte{
+		"openapi.json":  spec,
+		"client_gen.go": goSrc,
+		"devlog.ts":     tsClient(doc),
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatalf("read %s: %v", file, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s is out of date; run go generate ./pkg/devlog/apiclient", file)
+		}
+	}
+}
+
+func TestExportName(t *testing.T) {
+	tests := map[string]string{
+		"event_id":    "EventID",
+		"ok":          "OK",
+		"listEvents":  "ListEvents",
+		"getOpenAPI":  "GetOpenAPI",
+		"duration_ms": "DurationMs",
+		"next_cursor": "NextCursor",
+	}
+	for in, want := range tests {
+		if got := exportName(in); got != want {
+			t.Errorf("exportName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"devlog/internal/api"
+)
+
+// operation is an OpenAPI operation with what both emitters need resolved.
+type operation struct {
+	method      string
+	path        string
+	name        string // exported Go name, e.g. ListEvents
+	id          string // operationId, e.g. listEvents
+	summary     string
+	pathParams  []api.Parameter
+	queryParams []api.Parameter
+	body        *api.Schema // nil without a request body
+	bodyType    string      // request content type
+	response    *api.Schema // nil for non-JSON responses
+}
+
+// operations returns the spec's operations sorted by path and method.
+func operations(doc *api.OpenAPIDocument) []operation {
+	var ops []operation
+	for path, methods := range doc.Paths {
+		for method, op := range methods {
+			o := operation{
+				method:  strings.ToUpper(method),
+				path:    path,
+				name:    exportName(op.OperationID),
+				id:      op.OperationID,
+				summary: op.Summary,
+			}
+			for _, p := range op.Parameters {
+				if p.In == "path" {
+					o.pathParams = append(o.pathParams, p)
+				} else {
+					o.queryParams = append(o.queryParams, p)
+				}
+			}
+			if op.RequestBody != nil {
+				for contentType, media := range op.RequestBody.Content {
+					o.body, o.bodyType = media.Schema, contentType
+				}
+			}
+			for status, resp := range op.Responses {
+				if status == "default" {
+					continue
+				}
+				if media, ok := resp.Content["application/json"]; ok {
+					o.response = media.Schema
+				}
+			}
+			ops = append(ops, o)
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if ops[i].path != ops[j].path {
+			return ops[i].path < ops[j].path
+		}
+		return ops[i].method < ops[j].method
+	})
+	return ops
+}
+
+// streams reports whether the operation answers with server-sent events,
+// which the generated clients leave to the caller.
+func streams(doc *api.OpenAPIDocument, o operation) bool {
+	op := doc.Paths[o.path][strings.ToLower(o.method)]
+	for _, resp := range op.Responses {
+		if _, ok := resp.Content["text/event-stream"]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// initialisms are spelled in capitals in Go names, as in event_id -> EventID.
+var initialisms = map[string]string{"id": "ID", "ok": "OK", "url": "URL", "api": "API"}
+
+// exportName turns snake_case and camelCase names into exported Go names.
+func exportName(name string) string {
+	var words []string
+	word := []rune{}
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-':
+			flush()
+		case unicode.IsUpper(r):
+			flush()
+			word = append(word, unicode.ToLower(r))
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if up, ok := initialisms[w]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+func isRequired(schema *api.Schema, name string) bool {
+	for _, r := range schema.Required {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"devlog/internal/api"
+)
+
+// tsClient renders the spec as a dependency-free TypeScript module: one
+// interface per schema and a fetch-based DevlogClient.
+func tsClient(doc *api.OpenAPIDocument) []byte {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by apigen from the devlog OpenAPI spec. DO NOT EDIT.\n")
+
+	for _, name := range sortedKeys(doc.Components.Schemas) {
+		schema := doc.Components.Schemas[name]
+		fmt.Fprintf(&b, "\nexport interface %s {\n", name)
+		for _, prop := range sortedKeys(schema.Properties) {
+			optional := "?"
+			if isRequired(schema, prop) {
+				optional = ""
+			}
+			fmt.Fprintf(&b, "  %s%s: %s;\n", prop, optional, tsType(schema.Properties[prop]))
+		}
+		b.WriteString("}\n")
+	}
+
+	ops := operations(doc)
+	for _, op := range ops {
+		if len(op.queryParams) == 0 || streams(doc, op) {
+			continue
+		}
+		fmt.Fprintf(&b, "\nexport interface %sParams {\n", op.name)
+		for _, p := range op.queryParams {
+			if p.Description != "" {
+				fmt.Fprintf(&b, "  /** %s */\n", p.Description)
+			}
+			fmt.Fprintf(&b, "  %s?: %s;\n", p.Name, tsType(p.Schema))
+		}
+		b.WriteString("}\n")
+	}
+
+	b.WriteString(tsRuntime)
+
+	for _, op := range ops {
+		if op.response == nil && streams(doc, op) {
+			continue
+		}
+		writeTSMethod(&b, op)
+	}
+	b.WriteString("}\n")
+	return b.Bytes()
+}
+
+func tsType(s *api.Schema) string {
+	if s.Ref != "" {
+		return s.RefName()
+	}
+	switch s.Type {
+	case "array":
+		if s.MinItems != nil && s.MaxItems != nil && *s.MinItems == *s.MaxItems {
+			items := make([]string, *s.MinItems)
+			for i := range items {
+				items[i] = tsType(s.Items)
+			}
+			return "[" + strings.Join(items, ", ") + "]"
+		}
+		return tsType(s.Items) + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Record<string, " + tsType(s.AdditionalProperties) + ">"
+		}
+		return "Record<string, unknown>"
+	case "string":
+		return "string"
+	case "boolean":
+		return "boolean"
+	case "integer", "number":
+		return "number"
+	default:
+		return "unknown"
+	}
+}
+
+func writeTSMethod(b *bytes.Buffer, op operation) {
+	var args []string
+	urlPath := "`" + op.path + "`"
+	for _, p := range op.pathParams {
+		args = append(args, p.Name+": string")
+		urlPath = strings.Replace(urlPath, "{"+p.Name+"}", "${encodeURIComponent("+p.Name+")}", 1)
+	}
+
+	body := "undefined"
+	if op.body != nil {
+		switch {
+		case op.body.Ref == "" && op.body.Type == "":
+			args = append(args, "body: string")
+			body = fmt.Sprintf("{ data: body, contentType: %q }", op.bodyType)
+		case op.bodyType == "application/x-ndjson":
+			args = append(args, "body: "+tsType(op.body))
+			body = fmt.Sprintf(`{ data: body.map((item) => JSON.stringify(item)).join("\n"), contentType: %q }`, op.bodyType)
+		default:
+			args = append(args, "body: "+tsType(op.body))
+			body = fmt.Sprintf("{ data: JSON.stringify(body), contentType: %q }", op.bodyType)
+		}
+	}
+
+	query := "undefined"
+	if len(op.queryParams) > 0 {
+		args = append(args, "params: "+op.name+"Params = {}")
+		query = "params"
+	}
+
+	result := "Record<string, unknown>"
+	if op.response != nil {
+		result = tsType(op.response)
+	}
+
+	fmt.Fprintf(b, "\n  /** %s %s: %s. */\n", op.method, op.path, lowerFirst(op.summary))
+	fmt.Fprintf(b, "  %s(%s): Promise<%s> {\n", op.id, strings.Join(args, ", "), result)
+	call := []string{fmt.Sprintf("%q", op.method), urlPath, query, body}
+	for call[len(call)-1] == "undefined" {
+		call = call[:len(call)-1]
+	}
+	fmt.Fprintf(b, "    return this.request(%s);\n  }\n", strings.Join(call, ", "))
+}
+
+const tsRuntime = `
+export class DevlogError extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+    /** The daemon asks the caller to queue the event and retry later. */
+    readonly queue: boolean = false,
+  ) {
+    super(message);
+    this.name = "DevlogError";
+  }
+}
+
+export interface ClientOptions {
+  /** Daemon address, e.g. http://localhost:8573. Defaults to the page's origin. */
+  baseURL?: string;
+  /** Bearer token from 'devlog token create', for daemons with http.auth_enabled. */
+  token?: string;
+  fetch?: typeof fetch;
+}
+
+export class DevlogClient {
+  constructor(private readonly options: ClientOptions = {}) {}
+
+  private async request<T>(
+    method: string,
+    path: string,
+    query?: object,
+    body?: { data: string; contentType: string },
+  ): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {}) as [string, unknown][]) {
+      if (value === undefined || value === "" || value === 0) continue;
+      for (const v of Array.isArray(value) ? value : [value]) params.append(key, String(v));
+    }
+    const qs = params.toString();
+    const headers: Record<string, string> = {};
+    if (body) headers["Content-Type"] = body.contentType;
+    if (this.options.token) headers["Authorization"] = ` + "`Bearer ${this.options.token}`" + `;
+
+    const doFetch = this.options.fetch ?? fetch;
+    const res = await doFetch((this.options.baseURL ?? "") + path + (qs ? "?" + qs : ""), {
+      method,
+      headers,
+      body: body?.data,
+    });
+    const text = await res.text();
+    let data: any;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      data = undefined;
+    }
+    if (!res.ok) {
+      throw new DevlogError(res.status, data?.error ?? res.statusText, data?.queue ?? false);
+    }
+    return data as T;
+  }
+`
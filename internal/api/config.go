@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"devlog/internal/config"
+	"devlog/internal/modules"
+	"devlog/internal/plugins"
+)
+
+// handleGetConfig returns config.yaml as JSON with secrets redacted, the
+// file's version for the next save, and the modules and plugins that can be
+// turned on.
+func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	version, err := config.FileVersion()
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to read config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	resp, err := s.configResponse(s.configGetter(), version)
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to read config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	respondJSON(w, resp, http.StatusOK)
+}
+
+// handlePutConfig validates a config document and writes it to config.yaml.
+// The daemon's config watcher then applies it like a hand edit: modules and
+// plugins are started, stopped or restarted as needed.
+func (s *Server) handlePutConfig(w http.ResponseWriter, r *http.Request) {
+	var req UpdateConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Config == nil {
+		respondError(w, "config is required", http.StatusBadRequest)
+		return
+	}
+
+	version, err := config.FileVersion()
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to read config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if req.Version != "" && req.Version != version {
+		respondError(w, "config.yaml changed since it was loaded; reload and apply your changes again", http.StatusConflict)
+		return
+	}
+
+	current := s.configGetter()
+	next, err := config.ParseDocument(req.Config, current)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err := next.Save(); err != nil {
+		respondError(w, fmt.Sprintf("Failed to save config: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	if version, err = config.FileVersion(); err != nil {
+		respondError(w, fmt.Sprintf("Failed to read config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	resp, err := s.configResponse(next, version)
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to read config: %v", err), http.StatusInternalServerError)
+		return
+	}
+	resp.RestartRequired = current.RestartRequired(next)
+
+	s.logger.Info("config updated over the API", slog.String("version", version))
+	respondJSON(w, resp, http.StatusOK)
+}
+
+func (s *Server) configResponse(cfg *config.Config, version string) (ConfigResponse, error) {
+	doc, err := cfg.Document()
+	if err != nil {
+		return ConfigResponse{}, err
+	}
+	path, err := config.ConfigPath()
+	if err != nil {
+		return ConfigResponse{}, err
+	}
+
+	resp := ConfigResponse{Path: path, Version: version, Config: doc}
+	for _, m := range modules.List() {
+		resp.Modules = append(resp.Modules, ComponentInfo{Name: m.Name(), Description: m.Description(), DefaultConfig: m.DefaultConfig()})
+	}
+	for _, p := range plugins.List() {
+		resp.Plugins = append(resp.Plugins, ComponentInfo{Name: p.Name(), Description: p.Description(), DefaultConfig: p.DefaultConfig()})
+	}
+	sort.Slice(resp.Modules, func(i, j int) bool { return resp.Modules[i].Name < resp.Modules[j].Name })
+	sort.Slice(resp.Plugins, func(i, j int) bool { return resp.Plugins[i].Name < resp.Plugins[j].Name })
+	return resp, nil
+}
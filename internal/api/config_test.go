@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+)
+
+func TestConfigHandlers(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.Modules["shell"] = config.ComponentConfig{Enabled: true, Config: map[string]interface{}{
+		"ignore_list":    []interface{}{"ls"},
+		"webhook_secret": "s3cret",
+	}}
+	if err := os.MkdirAll(mustConfigDir(t), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.configGetter = func() *config.Config { return cfg }
+	mux := server.SetupRoutes()
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://localhost:8573/api/v1/config", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET: status %d: %s", w.Code, w.Body.String())
+	}
+	var got ConfigResponse
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	shell := got.Config["modules"].(map[string]interface{})["shell"].(map[string]interface{})
+	if shell["webhook_secret"] != config.RedactedValue {
+		t.Errorf("GET returned secret %v", shell["webhook_secret"])
+	}
+
+	put := func(version string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(UpdateConfigRequest{Version: version, Config: got.Config})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "http://localhost:8573/api/v1/config", bytes.NewReader(body)))
+		return w
+	}
+
+	shell["ignore_list"] = []interface{}{"ls", "pwd"}
+	got.Config["http"].(map[string]interface{})["port"] = 9000
+	w = put(got.Version)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: status %d: %s", w.Code, w.Body.String())
+	}
+	var saved ConfigResponse
+	if err := json.NewDecoder(w.Body).Decode(&saved); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Version == got.Version || len(saved.RestartRequired) != 1 || saved.RestartRequired[0] != "http.port" {
+		t.Errorf("PUT response = version %s, restart %v", saved.Version, saved.RestartRequired)
+	}
+
+	onDisk, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list := onDisk.Modules["shell"].Config["ignore_list"]; len(list.([]interface{})) != 2 {
+		t.Errorf("saved ignore_list = %v", list)
+	}
+	if secret := onDisk.Modules["shell"].Config["webhook_secret"]; secret != "s3cret" {
+		t.Errorf("saved webhook_secret = %v, want the original", secret)
+	}
+
+	if w := put(got.Version); w.Code != http.StatusConflict {
+		t.Errorf("PUT with a stale version: status %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	got.Config["http"].(map[string]interface{})["port"] = 80
+	if w := put(""); w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "port") {
+		t.Errorf("PUT with an invalid port: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestConfigRequiresSameOrigin(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	cfg := config.DefaultConfig()
+	token, err := config.GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AddToken("laptop", token, time.Now())
+	if err := os.MkdirAll(mustConfigDir(t), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.configGetter = func() *config.Config { return cfg }
+	mux := server.SetupRoutes()
+
+	tests := []struct {
+		name   string
+		url    string
+		origin string
+		token  string
+		want   int
+	}{
+		{"localhost", "http://localhost:8573/api/v1/config", "", "", http.StatusOK},
+		{"loopback address from the dashboard", "http://127.0.0.1:8573/api/v1/config", "http://127.0.0.1:8573", "", http.StatusOK},
+		{"IPv6 loopback", "http://[::1]:8573/api/v1/config", "", "", http.StatusOK},
+		{"LAN address", "http://192.168.1.20:8573/api/v1/config", "", "", http.StatusOK},
+		{"rebound host name", "http://attacker.example:8573/api/v1/config", "http://attacker.example:8573", "", http.StatusForbidden},
+		{"cross-site page", "http://localhost:8573/api/v1/config", "http://attacker.example", "", http.StatusForbidden},
+		{"other port on localhost", "http://localhost:8573/api/v1/config", "http://localhost:3000", "", http.StatusForbidden},
+		{"host name with a token", "http://devbox.lan:8573/api/v1/config", "", token, http.StatusOK},
+		{"host name with a bad token", "http://devbox.lan:8573/api/v1/config", "", "nope", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{http.MethodGet, http.MethodPut} {
+				var body *bytes.Reader
+				if method == http.MethodPut {
+					// An invalid document: it is rejected after the origin
+					// check, so nothing is written.
+					body = bytes.NewReader([]byte(`{"config": {"http": {"port": 80}}}`))
+				} else {
+					body = bytes.NewReader(nil)
+				}
+				r := httptest.NewRequest(method, tt.url, body)
+				if tt.origin != "" {
+					r.Header.Set("Origin", tt.origin)
+				}
+				if tt.token != "" {
+					r.Header.Set("Authorization", "Bearer "+tt.token)
+				}
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, r)
+
+				forbidden := w.Code == http.StatusForbidden
+				if forbidden != (tt.want == http.StatusForbidden) {
+					t.Errorf("%s: status %d, want forbidden=%v: %s", method, w.Code, tt.want == http.StatusForbidden, w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func mustConfigDir(t *testing.T) string {
+	t.Helper()
+	dir, err := config.ConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
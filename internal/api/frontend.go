@@ -1,9 +1,79 @@
 package api
 
 import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+	"mime"
 	"net/http"
+	"path"
+	"strings"
 )
 
+//go:embed static
+var staticFS embed.FS
+
+type staticAsset struct {
+	data        []byte
+	contentType string
+	version     string
+}
+
+var (
+	staticAssets = loadStaticAssets()
+	frontendPage = renderFrontendHTML()
+)
+
+func loadStaticAssets() map[string]staticAsset {
+	assets := make(map[string]staticAsset)
+	err := fs.WalkDir(staticFS, "static", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := staticFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		contentType := mime.TypeByExtension(path.Ext(p))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		assets[strings.TrimPrefix(p, "static/")] = staticAsset{
+			data:        data,
+			contentType: contentType,
+			version:     hex.EncodeToString(sum[:])[:12],
+		}
+		return nil
+	})
+	if err != nil {
+		panic("load embedded dashboard assets: " + err.Error())
+	}
+	return assets
+}
+
+// assetURL returns the /assets/ path for name with a content hash so browsers
+// can cache it until the binary ships a different file.
+func assetURL(name string) string {
+	asset, ok := staticAssets[name]
+	if !ok {
+		return "/assets/" + name
+	}
+	return "/assets/" + name + "?v=" + asset.version
+}
+
+func renderFrontendHTML() string {
+	return strings.NewReplacer(
+		"{{charts.js}}", assetURL("charts.js"),
+		"{{dashboard.css}}", assetURL("dashboard.css"),
+		"{{dashboard.js}}", assetURL("dashboard.js"),
+		"{{journal.js}}", assetURL("journal.js"),
+		"{{search.js}}", assetURL("search.js"),
+		"{{settings.js}}", assetURL("settings.js"),
+	).Replace(frontendHTML)
+}
+
 func (s *Server) handleFrontend(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path != "/" {
 		http.NotFound(w, r)
@@ -12,7 +82,32 @@ func (s *Server) handleFrontend(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(frontendHTML))
+	w.Write([]byte(frontendPage))
+}
+
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	asset, ok := staticAssets[r.PathValue("file")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	etag := `"` + asset.version + `"`
+	w.Header().Set("Content-Type", asset.contentType)
+	w.Header().Set("ETag", etag)
+	if r.URL.Query().Get("v") == asset.version {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(asset.data)
 }
 
 const frontendHTML = `<!DOCTYPE html>
@@ -21,538 +116,251 @@ const frontendHTML = `<!DOCTYPE html>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>DevLog Dashboard</title>
-    <script src="https://cdn.jsdelivr.net/npm/chart.js@4.4.1/dist/chart.umd.min.js"></script>
-    <style>
-        * {
-            margin: 0;
-            padding: 0;
-            box-sizing: border-box;
-        }
-
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, Cantarell, sans-serif;
-            background: #0f0f0f;
-            color: #e0e0e0;
-            line-height: 1.6;
-        }
-
-        .container {
-            max-width: 1400px;
-            margin: 0 auto;
-            padding: 20px;
-        }
-
-        header {
-            background: #1a1a1a;
-            padding: 20px;
-            border-bottom: 2px solid #2a2a2a;
-            margin-bottom: 30px;
-        }
-
-        h1 {
-            font-size: 2em;
-            font-weight: 600;
-            color: #ffffff;
-        }
-
-        .subtitle {
-            color: #888;
-            font-size: 0.9em;
-            margin-top: 5px;
-        }
-
-        .stats-grid {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(250px, 1fr));
-            gap: 20px;
-            margin-bottom: 30px;
-        }
-
-        .stat-card {
-            background: #1a1a1a;
-            padding: 20px;
-            border-radius: 8px;
-            border: 1px solid #2a2a2a;
-        }
-
-        .stat-card h3 {
-            font-size: 0.9em;
-            color: #888;
-            text-transform: uppercase;
-            letter-spacing: 0.5px;
-            margin-bottom: 10px;
-        }
-
-        .stat-value {
-            font-size: 2em;
-            font-weight: 700;
-            color: #2563eb;
-        }
-
-        .chart-grid {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(500px, 1fr));
-            gap: 20px;
-            margin-bottom: 30px;
-        }
-
-        .chart-card {
-            background: #1a1a1a;
-            padding: 20px;
-            border-radius: 8px;
-            border: 1px solid #2a2a2a;
-        }
-
-        .chart-card h2 {
-            font-size: 1.2em;
-            margin-bottom: 15px;
-            color: #ffffff;
-        }
-
-        .chart-container {
-            position: relative;
-            height: 300px;
-        }
-
-        .events-section {
-            background: #1a1a1a;
-            padding: 20px;
-            border-radius: 8px;
-            border: 1px solid #2a2a2a;
-            margin-bottom: 30px;
-        }
-
-        .events-section h2 {
-            font-size: 1.2em;
-            margin-bottom: 15px;
-            color: #ffffff;
-        }
-
-        .events-list {
-            max-height: 400px;
-            overflow-y: auto;
-        }
-
-        .event-item {
-            padding: 10px;
-            border-bottom: 1px solid #2a2a2a;
-            font-size: 0.9em;
-        }
-
-        .event-item:last-child {
-            border-bottom: none;
-        }
-
-        .event-time {
-            color: #666;
-            font-size: 0.85em;
-        }
-
-        .event-source {
-            display: inline-block;
-            padding: 2px 8px;
-            border-radius: 4px;
-            font-size: 0.8em;
-            font-weight: 600;
-            margin-right: 8px;
-        }
-
-        .source-git { background: #10b981; color: white; }
-        .source-shell { background: #f59e0b; color: white; }
-        .source-clipboard { background: #8b5cf6; color: white; }
-        .source-tmux { background: #ec4899; color: white; }
-        .source-wisprflow { background: #06b6d4; color: white; }
-
-        .event-type {
-            color: #888;
-        }
-
-        .event-details {
-            color: #ccc;
-            margin-top: 4px;
-        }
-
-        .loading {
-            text-align: center;
-            padding: 40px;
-            color: #666;
-        }
-
-        .error {
-            background: #dc2626;
-            color: white;
-            padding: 15px;
-            border-radius: 8px;
-            margin-bottom: 20px;
-        }
-
-        ::-webkit-scrollbar {
-            width: 8px;
-        }
-
-        ::-webkit-scrollbar-track {
-            background: #1a1a1a;
-        }
-
-        ::-webkit-scrollbar-thumb {
-            background: #2a2a2a;
-            border-radius: 4px;
-        }
-
-        ::-webkit-scrollbar-thumb:hover {
-            background: #3a3a3a;
-        }
-    </style>
+    <link rel="stylesheet" href="{{dashboard.css}}">
+    <script src="{{charts.js}}"></script>
 </head>
 <body>
     <header>
         <div class="container">
             <h1>DevLog Dashboard</h1>
             <div class="subtitle">Local development activity tracking</div>
+            <nav class="nav">
+                <a href="#/" data-view="dashboard">Dashboard</a>
+                <a href="#/journal" data-view="journal">Journal</a>
+                <a href="#/search" data-view="search">Search</a>
+                <a href="#/settings" data-view="settings">Settings</a>
+            </nav>
         </div>
     </header>
 
     <div class="container">
         <div id="error-container"></div>
 
-        <div class="stats-grid">
-            <div class="stat-card">
-                <h3>Total Events</h3>
-                <div class="stat-value" id="total-events">-</div>
-            </div>
-            <div class="stat-card">
-                <h3>Uptime</h3>
-                <div class="stat-value" id="uptime">-</div>
+        <div id="journal-view" class="view" hidden>
+            <div class="journal-nav">
+                <button type="button" id="journal-prev" onclick="shiftJournalDay(-1)" aria-label="Previous day">&larr;</button>
+                <h2 id="journal-date"></h2>
+                <button type="button" id="journal-next" onclick="shiftJournalDay(1)" aria-label="Next day">&rarr;</button>
+                <button type="button" id="journal-today" onclick="journalToday()">Today</button>
+                <select id="journal-repo" onchange="filterJournalRepo(this.value)">
+                    <option value="">All repos</option>
+                </select>
             </div>
+            <div id="journal-status" class="search-status"></div>
+            <div id="journal-list" class="journal-list"></div>
+        </div>
+
+        <div id="settings-view" class="view" hidden>
+            <form id="settings-form" class="settings-form">
+                <div id="settings-status" class="search-status"></div>
+
+                <div class="chart-grid">
+                    <div class="chart-card">
+                        <h2>Modules</h2>
+                        <div class="chart-hint">Modules that capture through shell or git hooks also need <code>devlog module install &lt;name&gt;</code></div>
+                        <div id="settings-modules" class="settings-list"></div>
+                    </div>
+                    <div class="chart-card">
+                        <h2>Plugins</h2>
+                        <div id="settings-plugins" class="settings-list"></div>
+                    </div>
+                </div>
+
+                <div class="chart-grid">
+                    <div class="chart-card">
+                        <h2>Shell Ignore List</h2>
+                        <div class="chart-hint">One command or glob per line, e.g. <code>ls</code> or <code>git status*</code></div>
+                        <textarea id="settings-ignore" rows="8" spellcheck="false"></textarea>
+                    </div>
+                    <div class="chart-card">
+                        <h2>Summarizer</h2>
+                        <div class="chart-hint">Enable the summarizer plugin to edit its schedule</div>
+                        <label class="settings-field">Summary every <input type="number" name="interval" min="1" max="1440"> minutes</label>
+                        <label class="settings-field">Context window <input type="number" name="context_window" min="1" max="1440"> minutes</label>
+                    </div>
+                </div>
+
+                <details class="chart-card settings-advanced">
+                    <summary>All settings as JSON</summary>
+                    <textarea id="settings-json" rows="20" spellcheck="false"></textarea>
+                    <button type="button" onclick="useSettingsJSON()">Apply JSON to form</button>
+                </details>
+
+                <div class="settings-actions">
+                    <button type="submit">Save</button>
+                    <button type="button" onclick="loadSettings()">Discard changes</button>
+                </div>
+            </form>
+        </div>
+
+        <div id="search-view" class="view" hidden>
+            <form id="search-form" class="search-form">
+                <input type="search" name="q" placeholder="Search events and summaries" autocomplete="off">
+                <select name="module">
+                    <option value="">All modules</option>
+                </select>
+                <input type="text" name="type" placeholder="Type (e.g. commit)">
+                <input type="text" name="repo" placeholder="Repo contains">
+                <label>From <input type="date" name="from"></label>
+                <label>To <input type="date" name="to"></label>
+                <select name="scope">
+                    <option value="events">Events</option>
+                    <option value="summaries">Summaries</option>
+                    <option value="all">Events and summaries</option>
+                </select>
+                <select name="sort">
+                    <option value="relevance">Best match</option>
+                    <option value="time_desc">Newest first</option>
+                </select>
+                <button type="submit">Search</button>
+            </form>
+            <div id="search-status" class="search-status"></div>
+            <div id="search-results" class="search-results"></div>
+            <button id="search-more" class="load-more" onclick="loadMoreResults()">Load more results</button>
         </div>
 
-        <div class="chart-grid">
-            <div class="chart-card">
-                <h2>Events by Source</h2>
-                <div class="chart-container">
-                    <canvas id="source-chart"></canvas>
+        <div id="dashboard-view" class="view">
+
+            <div class="stats-grid">
+                <div class="stat-card">
+                    <h3>Total Events</h3>
+                    <div class="stat-value" id="total-events">-</div>
+                </div>
+                <div class="stat-card">
+                    <h3>Uptime</h3>
+                    <div class="stat-value" id="uptime">-</div>
                 </div>
             </div>
-            <div class="chart-card">
-                <h2>Activity Timeline (Last 7 Days)</h2>
-                <div class="chart-container">
-                    <canvas id="timeline-chart"></canvas>
+
+            <div class="chart-grid">
+                <div class="chart-card">
+                    <h2>Events by Source</h2>
+                    <div class="chart-container">
+                        <canvas id="source-chart"></canvas>
+                    </div>
+                </div>
+                <div class="chart-card">
+                    <div class="chart-header">
+                        <h2>Activity Timeline</h2>
+                        <button type="button" id="timeline-reset" class="chart-reset" onclick="resetTimelineZoom()" hidden>Reset zoom</button>
+                    </div>
+                    <div id="timeline-range" class="chart-hint">Last 7 days &middot; drag to zoom</div>
+                    <div class="chart-container">
+                        <canvas id="timeline-chart"></canvas>
+                    </div>
                 </div>
             </div>
-        </div>
 
-        <div class="chart-grid">
-            <div class="chart-card">
-                <h2>Top Repositories</h2>
-                <div class="chart-container">
-                    <canvas id="repo-chart"></canvas>
+            <div class="chart-grid">
+                <div class="chart-card">
+                    <h2>Top Repositories</h2>
+                    <div class="chart-container">
+                        <canvas id="repo-chart"></canvas>
+                    </div>
+                </div>
+                <div class="chart-card">
+                    <h2>Most Used Commands</h2>
+                    <div class="chart-container">
+                        <canvas id="command-chart"></canvas>
+                    </div>
                 </div>
             </div>
-            <div class="chart-card">
-                <h2>Most Used Commands</h2>
-                <div class="chart-container">
-                    <canvas id="command-chart"></canvas>
+
+            <div class="chart-grid">
+                <div class="chart-card">
+                    <h2>Top Files This Week</h2>
+                    <div class="chart-container">
+                        <canvas id="files-chart"></canvas>
+                    </div>
+                </div>
+                <div class="chart-card">
+                    <h2>Languages This Week</h2>
+                    <div class="chart-container">
+                        <canvas id="languages-chart"></canvas>
+                    </div>
+                </div>
+            </div>
+
+            <div class="chart-card heatmap-card">
+                <div class="chart-header">
+                    <h2>Activity Heatmap</h2>
+                    <select id="heatmap-year" class="chart-reset" onchange="loadHeatmap()">
+                        <option value="">Last 12 months</option>
+                    </select>
+                </div>
+                <div id="heatmap-summary" class="chart-hint"></div>
+                <div id="heatmap" class="heatmap"></div>
+            </div>
+
+            <div class="chart-grid">
+                <div class="chart-card">
+                    <h2>Hour of Day</h2>
+                    <div class="chart-hint">Last 90 days</div>
+                    <div class="chart-container">
+                        <canvas id="hourly-chart"></canvas>
+                    </div>
+                </div>
+                <div class="chart-card">
+                    <h2>Day of Week</h2>
+                    <div class="chart-hint">Last 90 days</div>
+                    <div class="chart-container">
+                        <canvas id="weekday-chart"></canvas>
+                    </div>
                 </div>
             </div>
-        </div>
 
-        <div class="events-section">
-            <h2>Recent Events (Last 50)</h2>
-            <div id="events-list" class="events-list"></div>
+            <div class="chart-grid">
+                <div class="chart-card">
+                    <h2>Focus This Week</h2>
+                    <div class="chart-hint">Repo, branch and tmux session changes while working</div>
+                    <div class="focus-stats">
+                        <div>
+                            <div class="focus-label">Switches per hour</div>
+                            <div class="focus-value" id="focus-rate">-</div>
+                        </div>
+                        <div>
+                            <div class="focus-label">Longest streak</div>
+                            <div class="focus-value" id="focus-longest">-</div>
+                            <div class="focus-detail" id="focus-longest-repo"></div>
+                        </div>
+                        <div>
+                            <div class="focus-label">Deep work</div>
+                            <div class="focus-value" id="focus-deep">-</div>
+                        </div>
+                    </div>
+                    <ul id="focus-windows" class="focus-windows"></ul>
+                </div>
+                <div class="chart-card">
+                    <h2>Context Switches by Hour</h2>
+                    <div class="chart-hint">Last 7 days</div>
+                    <div class="chart-container">
+                        <canvas id="switches-chart"></canvas>
+                    </div>
+                </div>
+            </div>
+
+            <div class="events-section">
+                <h2>Recent Events</h2>
+                <div id="events-list" class="events-list"></div>
+                <button id="events-more" class="load-more" onclick="loadMoreEvents()">Load older events</button>
+            </div>
         </div>
     </div>
 
-    <script>
-        let charts = {};
-
-        function showError(message) {
-            const container = document.getElementById('error-container');
-            container.innerHTML = '<div class="error">' + message + '</div>';
-        }
-
-        function clearError() {
-            document.getElementById('error-container').innerHTML = '';
-        }
-
-        async function fetchJSON(url) {
-            const response = await fetch(url);
-            if (!response.ok) {
-                throw new Error('Failed to fetch ' + url);
-            }
-            return response.json();
-        }
-
-        async function loadStatus() {
-            try {
-                const data = await fetchJSON('/api/v1/status');
-                document.getElementById('total-events').textContent = data.event_count.toLocaleString();
-
-                const hours = Math.floor(data.uptime_seconds / 3600);
-                const minutes = Math.floor((data.uptime_seconds % 3600) / 60);
-                document.getElementById('uptime').textContent = hours + 'h ' + minutes + 'm';
-            } catch (error) {
-                console.error('Failed to load status:', error);
-            }
-        }
-
-        async function loadEvents() {
-            try {
-                const data = await fetchJSON('/api/v1/events');
-                const listEl = document.getElementById('events-list');
-
-                if (data.events.length === 0) {
-                    listEl.innerHTML = '<div class="event-item">No events found</div>';
-                    return;
-                }
-
-                listEl.innerHTML = data.events.map(event => {
-                    const time = new Date(event.timestamp).toLocaleString();
-                    const sourceClass = 'source-' + event.source;
-
-                    let details = '';
-                    if (event.payload) {
-                        if (event.payload.message) {
-                            details = event.payload.message;
-                        } else if (event.payload.command) {
-                            details = event.payload.command;
-                        } else if (event.payload.content) {
-                            const content = event.payload.content;
-                            details = content.length > 50 ? content.substring(0, 50) + '...' : content;
-                        }
-                    }
-
-                    if (event.repo) {
-                        details = (details ? details + ' • ' : '') + event.repo.split('/').pop();
-                    }
-
-                    return '<div class="event-item">' +
-                        '<div>' +
-                        '<span class="event-source ' + sourceClass + '">' + event.source + '</span>' +
-                        '<span class="event-type">' + event.type + '</span>' +
-                        '</div>' +
-                        (details ? '<div class="event-details">' + details + '</div>' : '') +
-                        '<div class="event-time">' + time + '</div>' +
-                        '</div>';
-                }).join('');
-            } catch (error) {
-                console.error('Failed to load events:', error);
-                showError('Failed to load events: ' + error.message);
-            }
-        }
-
-        async function loadEventsBySource() {
-            try {
-                const data = await fetchJSON('/api/v1/analytics/events-by-source');
-
-                if (charts.sourceChart) {
-                    charts.sourceChart.destroy();
-                }
-
-                const ctx = document.getElementById('source-chart').getContext('2d');
-                charts.sourceChart = new Chart(ctx, {
-                    type: 'doughnut',
-                    data: {
-                        labels: data.data.map(d => d.source),
-                        datasets: [{
-                            data: data.data.map(d => d.count),
-                            backgroundColor: [
-                                '#10b981',
-                                '#f59e0b',
-                                '#8b5cf6',
-                                '#ec4899',
-                                '#06b6d4',
-                                '#6366f1'
-                            ]
-                        }]
-                    },
-                    options: {
-                        responsive: true,
-                        maintainAspectRatio: false,
-                        plugins: {
-                            legend: {
-                                position: 'bottom',
-                                labels: { color: '#e0e0e0' }
-                            }
-                        }
-                    }
-                });
-            } catch (error) {
-                console.error('Failed to load source data:', error);
-            }
-        }
-
-        async function loadTimeline() {
-            try {
-                const data = await fetchJSON('/api/v1/analytics/events-timeline');
-
-                if (charts.timelineChart) {
-                    charts.timelineChart.destroy();
-                }
-
-                const reversedData = data.data.slice().reverse();
-
-                const ctx = document.getElementById('timeline-chart').getContext('2d');
-                charts.timelineChart = new Chart(ctx, {
-                    type: 'line',
-                    data: {
-                        labels: reversedData.map(d => {
-                            const date = new Date(d.hour);
-                            return date.toLocaleDateString('en-US', { month: 'short', day: 'numeric', hour: 'numeric' });
-                        }),
-                        datasets: [{
-                            label: 'Events',
-                            data: reversedData.map(d => d.count),
-                            borderColor: '#2563eb',
-                            backgroundColor: 'rgba(37, 99, 235, 0.1)',
-                            fill: true,
-                            tension: 0.4
-                        }]
-                    },
-                    options: {
-                        responsive: true,
-                        maintainAspectRatio: false,
-                        plugins: {
-                            legend: {
-                                display: false
-                            }
-                        },
-                        scales: {
-                            x: {
-                                ticks: {
-                                    color: '#888',
-                                    maxRotation: 45,
-                                    minRotation: 45
-                                },
-                                grid: { color: '#2a2a2a' }
-                            },
-                            y: {
-                                ticks: { color: '#888' },
-                                grid: { color: '#2a2a2a' }
-                            }
-                        }
-                    }
-                });
-            } catch (error) {
-                console.error('Failed to load timeline:', error);
-            }
-        }
-
-        async function loadRepoStats() {
-            try {
-                const data = await fetchJSON('/api/v1/analytics/repo-stats');
-
-                if (charts.repoChart) {
-                    charts.repoChart.destroy();
-                }
-
-                if (data.data.length === 0) {
-                    return;
-                }
-
-                const ctx = document.getElementById('repo-chart').getContext('2d');
-                charts.repoChart = new Chart(ctx, {
-                    type: 'bar',
-                    data: {
-                        labels: data.data.map(d => d.repo.split('/').pop()),
-                        datasets: [{
-                            label: 'Events',
-                            data: data.data.map(d => d.count),
-                            backgroundColor: '#10b981'
-                        }]
-                    },
-                    options: {
-                        responsive: true,
-                        maintainAspectRatio: false,
-                        indexAxis: 'y',
-                        plugins: {
-                            legend: { display: false }
-                        },
-                        scales: {
-                            x: {
-                                ticks: { color: '#888' },
-                                grid: { color: '#2a2a2a' }
-                            },
-                            y: {
-                                ticks: { color: '#888' },
-                                grid: { display: false }
-                            }
-                        }
-                    }
-                });
-            } catch (error) {
-                console.error('Failed to load repo stats:', error);
-            }
-        }
-
-        async function loadCommandStats() {
-            try {
-                const data = await fetchJSON('/api/v1/analytics/command-stats');
-
-                if (charts.commandChart) {
-                    charts.commandChart.destroy();
-                }
-
-                if (data.data.length === 0) {
-                    return;
-                }
-
-                const ctx = document.getElementById('command-chart').getContext('2d');
-                charts.commandChart = new Chart(ctx, {
-                    type: 'bar',
-                    data: {
-                        labels: data.data.map(d => {
-                            const cmd = d.command;
-                            return cmd.length > 30 ? cmd.substring(0, 30) + '...' : cmd;
-                        }),
-                        datasets: [{
-                            label: 'Count',
-                            data: data.data.map(d => d.count),
-                            backgroundColor: '#f59e0b'
-                        }]
-                    },
-                    options: {
-                        responsive: true,
-                        maintainAspectRatio: false,
-                        indexAxis: 'y',
-                        plugins: {
-                            legend: { display: false }
-                        },
-                        scales: {
-                            x: {
-                                ticks: { color: '#888' },
-                                grid: { color: '#2a2a2a' }
-                            },
-                            y: {
-                                ticks: { color: '#888' },
-                                grid: { display: false }
-                            }
-                        }
-                    }
-                });
-            } catch (error) {
-                console.error('Failed to load command stats:', error);
-            }
-        }
-
-        async function loadAllData() {
-            clearError();
-            try {
-                await Promise.all([
-                    loadStatus(),
-                    loadEvents(),
-                    loadEventsBySource(),
-                    loadTimeline(),
-                    loadRepoStats(),
-                    loadCommandStats()
-                ]);
-            } catch (error) {
-                showError('Failed to load dashboard data: ' + error.message);
-            }
-        }
-
-        loadAllData();
-        setInterval(loadAllData, 30000);
-    </script>
+    <aside id="event-drawer" class="drawer" hidden>
+        <div class="drawer-header">
+            <h2 id="drawer-title"></h2>
+            <button type="button" class="drawer-close" onclick="closeDrawer()" aria-label="Close">&times;</button>
+        </div>
+        <dl id="drawer-meta" class="drawer-meta"></dl>
+        <ul id="drawer-annotations" class="drawer-annotations" hidden></ul>
+        <pre id="drawer-json" class="drawer-json"></pre>
+    </aside>
+
+    <script src="{{dashboard.js}}"></script>
+    <script src="{{journal.js}}"></script>
+    <script src="{{settings.js}}"></script>
+    <script src="{{search.js}}"></script>
 </body>
 </html>
 `
@@ -96,7 +96,15 @@ func TestFrontendHTMLContent(t *testing.T) {
 			"stats-grid",
 			"chart-grid",
 			"events-list",
-			"<script>",
+			"<script src=",
+			`id="search-view"`,
+			`id="search-form"`,
+			`id="event-drawer"`,
+			`<script src="/assets/search.js?v=`,
+			`id="journal-view"`,
+			`<script src="/assets/journal.js?v=`,
+			`id="settings-form"`,
+			`<script src="/assets/settings.js?v=`,
 		}
 
 		for _, elem := range requiredElements {
@@ -106,7 +114,7 @@ func TestFrontendHTMLContent(t *testing.T) {
 		}
 	})
 
-	t.Run("HTML has CSS styles", func(t *testing.T) {
+	t.Run("HTML links local stylesheet", func(t *testing.T) {
 		server := &Server{}
 
 		req := httptest.NewRequest("GET", "/", nil)
@@ -116,16 +124,12 @@ func TestFrontendHTMLContent(t *testing.T) {
 
 		body := w.Body.String()
 
-		if !strings.Contains(body, "<style>") {
-			t.Error("response doesn't contain style block")
-		}
-
-		if !strings.Contains(body, "background:") || !strings.Contains(body, "color:") {
-			t.Error("style block doesn't contain expected CSS")
+		if !strings.Contains(body, `<link rel="stylesheet" href="/assets/dashboard.css?v=`) {
+			t.Error("response doesn't link the embedded stylesheet")
 		}
 	})
 
-	t.Run("HTML includes Chart.js", func(t *testing.T) {
+	t.Run("HTML loads charts from local assets", func(t *testing.T) {
 		server := &Server{}
 
 		req := httptest.NewRequest("GET", "/", nil)
@@ -135,8 +139,11 @@ func TestFrontendHTMLContent(t *testing.T) {
 
 		body := w.Body.String()
 
-		if !strings.Contains(body, "Chart") {
-			t.Error("response doesn't include Chart.js references")
+		if !strings.Contains(body, `<script src="/assets/charts.js?v=`) {
+			t.Error("response doesn't load charts.js from /assets/")
+		}
+		if strings.Contains(body, "https://") {
+			t.Error("dashboard references a remote URL")
 		}
 	})
 
@@ -238,3 +245,70 @@ func TestFrontendResponseHeaders(t *testing.T) {
 		t.Logf("Cache-Control header: %s", cc)
 	})
 }
+
+func TestAssetHandler(t *testing.T) {
+	server := &Server{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /assets/{file}", server.handleAsset)
+
+	for _, tc := range []struct {
+		file        string
+		contentType string
+		contains    string
+	}{
+		{"charts.js", "javascript", "global.Chart = Chart"},
+		{"dashboard.js", "javascript", "loadAllData"},
+		{"search.js", "javascript", "/api/v1/search?"},
+		{"dashboard.css", "text/css", "background:"},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			req := httptest.NewRequest("GET", assetURL(tc.file), nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, tc.contentType) {
+				t.Errorf("Content-Type = %q, want %s", ct, tc.contentType)
+			}
+			if !strings.Contains(w.Header().Get("Cache-Control"), "immutable") {
+				t.Errorf("versioned asset Cache-Control = %q", w.Header().Get("Cache-Control"))
+			}
+			if !strings.Contains(w.Body.String(), tc.contains) {
+				t.Errorf("body doesn't contain %q", tc.contains)
+			}
+			if strings.Contains(w.Body.String(), "cdn.jsdelivr.net") {
+				t.Error("asset references the CDN")
+			}
+		})
+	}
+
+	t.Run("unversioned request is revalidated", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/assets/charts.js", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Header().Get("Cache-Control") != "no-cache" {
+			t.Errorf("Cache-Control = %q, want no-cache", w.Header().Get("Cache-Control"))
+		}
+
+		req = httptest.NewRequest("GET", "/assets/charts.js", nil)
+		req.Header.Set("If-None-Match", w.Header().Get("ETag"))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified {
+			t.Errorf("expected status %d, got %d", http.StatusNotModified, w.Code)
+		}
+	})
+
+	t.Run("unknown asset", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/assets/missing.js", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
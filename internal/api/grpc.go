@@ -0,0 +1,333 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/metrics"
+	"devlog/internal/protowire"
+	"devlog/internal/services"
+)
+
+// The gRPC methods of the devlog.v1.Ingest service described in
+// pkg/devlog/proto/ingest.proto.
+const (
+	grpcIngestMethod       = "/devlog.v1.Ingest/Ingest"
+	grpcIngestStreamMethod = "/devlog.v1.Ingest/IngestStream"
+)
+
+// gRPC status codes, as defined by google.golang.org/grpc/codes.
+const (
+	grpcOK                = 0
+	grpcInvalidArgument   = 3
+	grpcAlreadyExists     = 6
+	grpcResourceExhausted = 8
+	grpcUnimplemented     = 12
+	grpcInternal          = 13
+	grpcUnavailable       = 14
+	grpcUnauthenticated   = 16
+)
+
+// maxGRPCStreamErrors caps the failures listed in an IngestStream
+// response; the rest are only counted.
+const maxGRPCStreamErrors = 100
+
+type grpcError struct {
+	code    int
+	message string
+}
+
+func (e *grpcError) Error() string {
+	return e.message
+}
+
+// GRPCHandler serves the gRPC ingestion endpoint. gRPC is plain HTTP/2 with
+// length-prefixed protobuf messages and the status in trailers, so it is
+// served by net/http on its own port instead of pulling in grpc-go. The
+// server must accept HTTP/2, including unencrypted HTTP/2 when TLS is off.
+func (s *Server) GRPCHandler() http.Handler {
+	return loggingMiddleware(s.logger, func(w http.ResponseWriter, r *http.Request) {
+		var call func(r *http.Request) ([]byte, error)
+		group := config.RouteGroupIngest
+		switch r.URL.Path {
+		case grpcIngestMethod:
+			call = s.grpcIngest
+		case grpcIngestStreamMethod:
+			call, group = s.grpcIngestStream, config.RouteGroupBatch
+		}
+
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			http.Error(w, "gRPC requests only", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Grpc-Accept-Encoding", "gzip")
+		switch {
+		case call == nil:
+			writeGRPCStatus(w, &grpcError{grpcUnimplemented, "unknown method " + r.URL.Path})
+			return
+		case !s.authorized(r):
+			writeGRPCStatus(w, &grpcError{grpcUnauthenticated, "missing or invalid API token"})
+			return
+		case s.draining.Load():
+			writeGRPCStatus(w, &grpcError{grpcUnavailable, "daemon is shutting down; queue the events and retry"})
+			return
+		}
+		if encoding := r.Header.Get("Grpc-Encoding"); encoding != "" && encoding != "identity" && encoding != "gzip" {
+			writeGRPCStatus(w, &grpcError{grpcUnimplemented, "unsupported grpc-encoding " + encoding})
+			return
+		}
+		limit := s.routeLimit(group)
+		if ok, _ := s.limiter(group).allow(clientKey(r), limit.RequestsPerSecond, limit.Burst); !ok {
+			metrics.APIRateLimited.Add(group, 1)
+			writeGRPCStatus(w, &grpcError{grpcResourceExhausted, "rate limit exceeded; slow down and retry"})
+			return
+		}
+
+		reply, err := call(r)
+		if err == nil {
+			_, err = w.Write(grpcFrame(reply))
+		}
+		writeGRPCStatus(w, err)
+	})
+}
+
+// grpcIngest stores one event, like POST /api/v1/ingest.
+func (s *Server) grpcIngest(r *http.Request) ([]byte, error) {
+	timer := metrics.StartAPITimer(grpcIngestMethod)
+	defer timer.Stop()
+
+	msg, err := s.readGRPCMessage(r)
+	if err == io.EOF {
+		return nil, &grpcError{grpcInvalidArgument, "request has no event"}
+	}
+	if err != nil {
+		return nil, err
+	}
+	event, err := decodeGRPCEvent(msg)
+	if err != nil {
+		metrics.EventIngestionErrors.Add(1)
+		return nil, &grpcError{grpcInvalidArgument, err.Error()}
+	}
+
+	var reply []byte
+	err = s.eventService.IngestEvent(r.Context(), event)
+	switch {
+	case err == nil:
+		reply = protowire.AppendString(reply, 1, event.ID)
+	case err == services.ErrEventFiltered:
+		reply = protowire.AppendBool(reply, 2, true)
+	case err == services.ErrDuplicateEvent:
+		return nil, &grpcError{grpcAlreadyExists, err.Error()}
+	default:
+		var validationErr *services.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, &grpcError{grpcInvalidArgument, err.Error()}
+		}
+		return nil, &grpcError{grpcInternal, err.Error()}
+	}
+	return reply, nil
+}
+
+// grpcIngestStream stores events as they arrive on a client stream, like
+// POST /api/v1/ingest/batch without the batch size limit.
+func (s *Server) grpcIngestStream(r *http.Request) ([]byte, error) {
+	timer := metrics.StartAPITimer(grpcIngestStreamMethod)
+	defer timer.Stop()
+
+	var (
+		ingested, filtered, duplicates, failed int
+		failures                               []byte
+	)
+	fail := func(index int, err error) {
+		failed++
+		if failed > maxGRPCStreamErrors {
+			return
+		}
+		var item []byte
+		item = protowire.AppendVarint(item, 1, uint64(index))
+		item = protowire.AppendString(item, 2, err.Error())
+		failures = protowire.AppendBytes(failures, 5, item)
+	}
+
+	for index := 0; ; index++ {
+		msg, err := s.readGRPCMessage(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if s.draining.Load() {
+			return nil, &grpcError{grpcUnavailable, fmt.Sprintf("daemon is shutting down; stored %d events, resend from event %d", ingested, index)}
+		}
+
+		event, err := decodeGRPCEvent(msg)
+		if err != nil {
+			metrics.EventIngestionErrors.Add(1)
+			fail(index, err)
+			continue
+		}
+		err = s.eventService.IngestEvent(r.Context(), event)
+		switch {
+		case err == nil:
+			ingested++
+		case err == services.ErrEventFiltered:
+			filtered++
+		case err == services.ErrDuplicateEvent:
+			duplicates++
+		default:
+			fail(index, err)
+		}
+	}
+
+	if failed > 0 {
+		s.logger.Debug("gRPC stream had invalid events", slog.Int("failed", failed))
+	}
+	var reply []byte
+	reply = protowire.AppendVarint(reply, 1, uint64(ingested))
+	reply = protowire.AppendVarint(reply, 2, uint64(filtered))
+	reply = protowire.AppendVarint(reply, 3, uint64(duplicates))
+	reply = protowire.AppendVarint(reply, 4, uint64(failed))
+	return append(reply, failures...), nil
+}
+
+// readGRPCMessage reads the next length-prefixed message of the request,
+// or io.EOF once the client has sent them all. Messages are held to the
+// ingest route group's body limit.
+func (s *Server) readGRPCMessage(r *http.Request) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r.Body, header[:]); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, &grpcError{grpcInternal, "read request: " + err.Error()}
+	}
+
+	limit := s.routeLimit(config.RouteGroupIngest).MaxBodyBytes
+	size := int64(binary.BigEndian.Uint32(header[1:]))
+	if size > limit {
+		return nil, &grpcError{grpcResourceExhausted, fmt.Sprintf("message of %d bytes exceeds %d bytes", size, limit)}
+	}
+	msg := make([]byte, size)
+	if _, err := io.ReadFull(r.Body, msg); err != nil {
+		return nil, &grpcError{grpcInternal, "read request: " + err.Error()}
+	}
+	if header[0] == 0 {
+		return msg, nil
+	}
+
+	if r.Header.Get("Grpc-Encoding") != "gzip" {
+		return nil, &grpcError{grpcInternal, "compressed message without grpc-encoding"}
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(msg))
+	if err != nil {
+		return nil, &grpcError{grpcInternal, "decompress message: " + err.Error()}
+	}
+	msg, err = io.ReadAll(io.LimitReader(zr, limit+1))
+	if err != nil {
+		return nil, &grpcError{grpcInternal, "decompress message: " + err.Error()}
+	}
+	if int64(len(msg)) > limit {
+		return nil, &grpcError{grpcResourceExhausted, fmt.Sprintf("message exceeds %d bytes", limit)}
+	}
+	return msg, nil
+}
+
+// decodeGRPCEvent reads a devlog.v1.Event message.
+func decodeGRPCEvent(msg []byte) (*events.Event, error) {
+	event := &events.Event{}
+	var payload []byte
+	err := protowire.EachField(msg, func(field int, value uint64, raw []byte) error {
+		switch field {
+		case 1:
+			event.Version = int(int32(value))
+		case 2:
+			event.ID = string(raw)
+		case 3:
+			event.Timestamp = string(raw)
+		case 4:
+			event.Source = string(raw)
+		case 5:
+			event.Type = string(raw)
+		case 6:
+			event.Repo = string(raw)
+		case 7:
+			event.Branch = string(raw)
+		case 8:
+			event.DurationMs = int64(value)
+		case 9:
+			event.SessionID = string(raw)
+		case 10:
+			event.ParentID = string(raw)
+		case 11:
+			event.Severity = string(raw)
+		case 12:
+			payload = raw
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid event message: %w", err)
+	}
+
+	event.Payload = make(map[string]interface{})
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &event.Payload); err != nil {
+			return nil, fmt.Errorf("invalid payload_json: %w", err)
+		}
+	}
+	return event, nil
+}
+
+// grpcFrame prefixes an uncompressed message with its gRPC frame header.
+func grpcFrame(msg []byte) []byte {
+	frame := make([]byte, 5, 5+len(msg))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(msg)))
+	return append(frame, msg...)
+}
+
+// writeGRPCStatus sets the status trailers that end a gRPC response; a
+// nil err is OK. net/http sends them after the body, or right after the
+// headers when there is none.
+func writeGRPCStatus(w http.ResponseWriter, err error) {
+	code, message := grpcOK, ""
+	if err != nil {
+		code, message = grpcInternal, err.Error()
+		var grpcErr *grpcError
+		if errors.As(err, &grpcErr) {
+			code = grpcErr.code
+		}
+	}
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.Itoa(code))
+	if message != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", grpcPercentEncode(message))
+	}
+}
+
+// grpcPercentEncode escapes a grpc-message value as the gRPC spec requires:
+// bytes outside printable ASCII, and '%', become %XX.
+func grpcPercentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < ' ' || c > '~' || c == '%' {
+			fmt.Fprintf(&b, "%%%02X", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
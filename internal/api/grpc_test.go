@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/protowire"
+)
+
+// grpcTestClient calls the gRPC handler over unencrypted HTTP/2, the way
+// grpc-go clients reach a daemon without TLS.
+func grpcTestClient(t *testing.T, server *Server) (func(method string, msgs ...[]byte) (int, string, []byte), *http.Request) {
+	t.Helper()
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+
+	ts := httptest.NewUnstartedServer(server.GRPCHandler())
+	ts.Config.Protocols = &protocols
+	ts.Start()
+	t.Cleanup(ts.Close)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+
+	template, _ := http.NewRequest(http.MethodPost, ts.URL, nil)
+	call := func(method string, msgs ...[]byte) (int, string, []byte) {
+		t.Helper()
+		var body bytes.Buffer
+		for _, msg := range msgs {
+			body.Write(grpcFrame(msg))
+		}
+		req, err := http.NewRequest(http.MethodPost, ts.URL+method, &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header = template.Header.Clone()
+		req.Header.Set("Content-Type", "application/grpc")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("response over HTTP/%d, want HTTP/2", resp.ProtoMajor)
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var reply []byte
+		if len(data) >= 5 {
+			size := binary.BigEndian.Uint32(data[1:5])
+			reply = data[5 : 5+size]
+		}
+		code, err := strconv.Atoi(resp.Trailer.Get("Grpc-Status"))
+		if err != nil {
+			t.Fatalf("grpc-status trailer: %v", err)
+		}
+		return code, resp.Trailer.Get("Grpc-Message"), reply
+	}
+	return call, template
+}
+
+func grpcTestEvent(event *events.Event) []byte {
+	payload, _ := event.PayloadJSON()
+	var msg []byte
+	msg = protowire.AppendVarint(msg, 1, uint64(event.Version))
+	msg = protowire.AppendString(msg, 2, event.ID)
+	msg = protowire.AppendString(msg, 3, event.Timestamp)
+	msg = protowire.AppendString(msg, 4, event.Source)
+	msg = protowire.AppendString(msg, 5, event.Type)
+	msg = protowire.AppendString(msg, 6, event.Repo)
+	msg = protowire.AppendVarint(msg, 8, uint64(event.DurationMs))
+	msg = protowire.AppendString(msg, 12, payload)
+	return msg
+}
+
+func TestGRPCIngest(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+	call, _ := grpcTestClient(t, server)
+
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Repo = "api"
+	event.DurationMs = 1200
+	event.Payload["command"] = "make test"
+
+	code, message, reply := call(grpcIngestMethod, grpcTestEvent(event))
+	if code != grpcOK {
+		t.Fatalf("Ingest status = %d (%s), want OK", code, message)
+	}
+	var gotID string
+	protowire.EachField(reply, func(field int, _ uint64, raw []byte) error {
+		if field == 1 {
+			gotID = string(raw)
+		}
+		return nil
+	})
+	if gotID != event.ID {
+		t.Errorf("event_id = %q, want %q", gotID, event.ID)
+	}
+
+	stored, err := store.GetEvent(event.ID)
+	if err != nil {
+		t.Fatalf("GetEvent() error: %v", err)
+	}
+	if stored.Repo != "api" || stored.DurationMs != 1200 || stored.Payload["command"] != "make test" {
+		t.Errorf("stored event = %+v", stored)
+	}
+
+	if code, _, _ := call(grpcIngestMethod, grpcTestEvent(event)); code != grpcAlreadyExists {
+		t.Errorf("duplicate status = %d, want ALREADY_EXISTS", code)
+	}
+	invalid := events.NewEvent("nope", string(events.TypeCommand))
+	if code, _, _ := call(grpcIngestMethod, grpcTestEvent(invalid)); code != grpcInvalidArgument {
+		t.Errorf("invalid event status = %d, want INVALID_ARGUMENT", code)
+	}
+	if code, _, _ := call("/devlog.v1.Ingest/Nope"); code != grpcUnimplemented {
+		t.Errorf("unknown method status = %d, want UNIMPLEMENTED", code)
+	}
+}
+
+func TestGRPCIngestStream(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+	call, _ := grpcTestClient(t, server)
+
+	var msgs [][]byte
+	for i := 0; i < 600; i++ {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		msgs = append(msgs, grpcTestEvent(event))
+	}
+	msgs = append(msgs, grpcTestEvent(events.NewEvent(string(events.SourceGit), "nope")))
+	msgs = append(msgs, []byte{0xff})
+
+	code, message, reply := call(grpcIngestStreamMethod, msgs...)
+	if code != grpcOK {
+		t.Fatalf("IngestStream status = %d (%s), want OK", code, message)
+	}
+
+	counts := make(map[int]uint64)
+	var errorIndexes []uint64
+	protowire.EachField(reply, func(field int, value uint64, raw []byte) error {
+		if field == 5 {
+			protowire.EachField(raw, func(field int, value uint64, _ []byte) error {
+				if field == 1 {
+					errorIndexes = append(errorIndexes, value)
+				}
+				return nil
+			})
+			return nil
+		}
+		counts[field] = value
+		return nil
+	})
+	if counts[1] != 600 || counts[4] != 2 {
+		t.Errorf("ingested = %d, failed = %d; want 600 and 2", counts[1], counts[4])
+	}
+	if len(errorIndexes) != 2 || errorIndexes[0] != 600 || errorIndexes[1] != 601 {
+		t.Errorf("error indexes = %v, want [600 601]", errorIndexes)
+	}
+	if n, _ := store.Count(); n != 600 {
+		t.Errorf("stored %d events, want 600", n)
+	}
+}
+
+func TestGRPCRequiresToken(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+	cfg := server.configGetter()
+	token, err := config.GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.AddToken("ci", token, time.Now())
+	cfg.HTTP.AuthEnabled = true
+	call, template := grpcTestClient(t, server)
+
+	note := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	note.Payload["text"] = "deploy from CI"
+	event := grpcTestEvent(note)
+	if code, _, _ := call(grpcIngestMethod, event); code != grpcUnauthenticated {
+		t.Errorf("status without token = %d, want UNAUTHENTICATED", code)
+	}
+	template.Header.Set("Authorization", "Bearer "+token)
+	if code, message, _ := call(grpcIngestMethod, event); code != grpcOK {
+		t.Errorf("status with token = %d (%s), want OK", code, message)
+	}
+}
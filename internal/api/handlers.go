@@ -7,60 +7,132 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
+	"devlog/internal/analytics"
 	"devlog/internal/config"
 	"devlog/internal/events"
 	"devlog/internal/logger"
 	"devlog/internal/metrics"
+	"devlog/internal/modules"
+	"devlog/internal/pause"
 	"devlog/internal/services"
 	"devlog/internal/storage"
 )
 
 const (
 	DefaultEventsLimit      = 50
+	MaxEventsLimit          = 500
 	DefaultSearchLimit      = 20
 	MaxSearchLimit          = 100
 	DefaultTopReposLimit    = 10
 	DefaultTopCommandsLimit = 15
+	DefaultTopFilesLimit    = 15
+	MaxTopFilesLimit        = 100
+	MaxFocusEvents          = 50000
 	HealthCheckTimeout      = 2 * time.Second
 	MaxQueryLength          = 1000
+	MaxBatchEvents          = 500
 )
 
 type Server struct {
-	storage      *storage.Storage
+	storage      storage.Store
 	eventService *services.EventService
 	config       *config.Config
+	configGetter func() *config.Config
 	logger       *logger.Logger
 	startTime    time.Time
+	pause        *pause.Controller
+	preflight    []PreflightIssue
+	draining     atomic.Bool
+	drainOnce    sync.Once
+	streamsDone  chan struct{}
+	limitersMu   sync.Mutex
+	limiters     map[string]*rateLimiter
 }
 
-func NewServer(storage *storage.Storage, configGetter func() *config.Config, log *logger.Logger) *Server {
+// NewServer returns the API server. eventService should be the daemon's own,
+// so events ingested over HTTP, gRPC and webhooks share its enrichment,
+// quotas and write buffer with polled ones; nil builds a standalone one.
+func NewServer(storage storage.Store, eventService *services.EventService, configGetter func() *config.Config, log *logger.Logger) *Server {
 	if log == nil {
 		log = logger.Default()
 	}
-	eventService := services.NewEventService(storage, configGetter, log)
+	if eventService == nil {
+		eventService = services.NewEventService(storage, configGetter, log)
+	}
 	cfg := configGetter()
 	return &Server{
 		storage:      storage,
 		eventService: eventService,
 		config:       cfg,
+		configGetter: configGetter,
 		logger:       log,
 		startTime:    time.Now(),
+		streamsDone:  make(chan struct{}),
+		limiters:     make(map[string]*rateLimiter),
+	}
+}
+
+func (s *Server) SetPause(p *pause.Controller) {
+	s.pause = p
+	s.eventService.SetPause(p)
+}
+
+func (s *Server) SetWriteBuffer(b *services.WriteBuffer) {
+	s.eventService.SetWriteBuffer(b)
+}
+
+func (s *Server) SetPreflight(issues []PreflightIssue) {
+	s.preflight = issues
+}
+
+// Drain makes ingest and webhook endpoints answer 503 so clients queue their
+// events on disk while the daemon finishes in-flight work and shuts down.
+// Open event streams are closed so they do not hold up the HTTP shutdown.
+func (s *Server) Drain() {
+	s.draining.Store(true)
+	s.drainOnce.Do(func() { close(s.streamsDone) })
+}
+
+func (s *Server) rejectWhileDraining(w http.ResponseWriter) bool {
+	if !s.draining.Load() {
+		return false
 	}
+	w.Header().Set("Retry-After", "5")
+	respondJSON(w, ErrorResponse{
+		OK:    false,
+		Error: "daemon is shutting down; queue the event and retry",
+		Queue: true,
+	}, http.StatusServiceUnavailable)
+	return true
 }
 
 func (s *Server) IngestHandler(w http.ResponseWriter, r *http.Request) {
 	timer := metrics.StartAPITimer("/api/v1/ingest")
 	defer timer.Stop()
 
+	if s.rejectWhileDraining(w) {
+		return
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		metrics.EventIngestionErrors.Add(1)
+		if limit, ok := bodyLimit(err); ok {
+			respondBodyTooLarge(w, limit)
+			return
+		}
 		respondJSON(w, ErrorResponse{
 			OK:    false,
 			Error: "Failed to read request body",
@@ -109,6 +181,146 @@ func (s *Server) IngestHandler(w http.ResponseWriter, r *http.Request) {
 	}, http.StatusOK)
 }
 
+func (s *Server) BatchIngestHandler(w http.ResponseWriter, r *http.Request) {
+	timer := metrics.StartAPITimer("/api/v1/ingest/batch")
+	defer timer.Stop()
+
+	if s.rejectWhileDraining(w) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		metrics.EventIngestionErrors.Add(1)
+		if limit, ok := bodyLimit(err); ok {
+			respondBodyTooLarge(w, limit)
+			return
+		}
+		respondError(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	lines := strings.Split(string(body), "\n")
+	count := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	// Reject an oversized batch before storing any of it, so a client that
+	// splits it up and retries does not leave the first part stored twice.
+	if count > MaxBatchEvents {
+		respondError(w, fmt.Sprintf("batch of %d events exceeds maximum of %d", count, MaxBatchEvents), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	response := BatchIngestResponse{OK: true}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		event, err := events.FromJSON([]byte(line))
+		if err != nil {
+			metrics.EventIngestionErrors.Add(1)
+			response.Errors = append(response.Errors, BatchIngestError{Line: i + 1, Error: err.Error()})
+			continue
+		}
+
+		err = s.eventService.IngestEvent(r.Context(), event)
+		switch {
+		case err == nil:
+			response.Ingested++
+		case err == services.ErrEventFiltered:
+			response.Filtered++
+		case err == services.ErrDuplicateEvent:
+			response.Duplicates++
+		default:
+			response.Errors = append(response.Errors, BatchIngestError{Line: i + 1, Error: err.Error()})
+		}
+	}
+
+	if len(response.Errors) > 0 {
+		response.OK = false
+	}
+
+	respondJSON(w, response, http.StatusOK)
+}
+
+func (s *Server) WebhookHandler(w http.ResponseWriter, r *http.Request) {
+	moduleName := r.PathValue("module")
+	timer := metrics.StartAPITimer("/api/v1/webhooks/" + moduleName)
+	defer timer.Stop()
+
+	if s.rejectWhileDraining(w) {
+		return
+	}
+
+	cfg := s.configGetter()
+	if !cfg.IsModuleEnabled(moduleName) {
+		respondError(w, fmt.Sprintf("No webhook receiver for %s", moduleName), http.StatusNotFound)
+		return
+	}
+
+	mod, err := modules.Get(moduleName)
+	if err != nil {
+		respondError(w, fmt.Sprintf("No webhook receiver for %s", moduleName), http.StatusNotFound)
+		return
+	}
+
+	receiver, ok := mod.(modules.WebhookReceiver)
+	if !ok {
+		respondError(w, fmt.Sprintf("No webhook receiver for %s", moduleName), http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if limit, ok := bodyLimit(err); ok {
+			respondBodyTooLarge(w, limit)
+			return
+		}
+		respondError(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	moduleCfg, _ := cfg.GetModuleConfig(moduleName)
+	evts, err := receiver.HandleWebhook(r.Header, body, moduleCfg)
+	if errors.Is(err, modules.ErrWebhookUnauthorized) {
+		s.logger.Warn("webhook rejected",
+			slog.String("module", moduleName),
+			slog.String("remote", r.RemoteAddr))
+		respondError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := BatchIngestResponse{OK: true}
+	for i, event := range evts {
+		event.Module = moduleName
+		err := s.eventService.IngestEvent(r.Context(), event)
+		switch {
+		case err == nil:
+			response.Ingested++
+		case err == services.ErrEventFiltered:
+			response.Filtered++
+		case err == services.ErrDuplicateEvent:
+			response.Duplicates++
+		default:
+			response.OK = false
+			response.Errors = append(response.Errors, BatchIngestError{Line: i + 1, Error: err.Error()})
+		}
+	}
+
+	respondJSON(w, response, http.StatusOK)
+}
+
 func (s *Server) StatusHandler(w http.ResponseWriter, r *http.Request) {
 	count, err := s.storage.Count()
 	if err != nil {
@@ -121,11 +333,89 @@ func (s *Server) StatusHandler(w http.ResponseWriter, r *http.Request) {
 
 	uptime := time.Since(s.startTime).Seconds()
 
-	respondJSON(w, StatusResponse{
+	response := StatusResponse{
 		Running:       true,
 		EventCount:    count,
 		UptimeSeconds: int(uptime),
-	}, http.StatusOK)
+		Preflight:     s.preflight,
+	}
+	if s.pause != nil {
+		state := s.pause.State()
+		response.Paused = state.Paused
+		if state.Until != nil {
+			response.PausedUntil = state.Until.Format(time.RFC3339)
+		}
+	}
+
+	respondJSON(w, response, http.StatusOK)
+}
+
+func (s *Server) PauseStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if s.pause == nil {
+		respondError(w, "Pausing capture is not available", http.StatusServiceUnavailable)
+		return
+	}
+	respondJSON(w, toPauseResponse(s.pause.State()), http.StatusOK)
+}
+
+func (s *Server) PauseHandler(w http.ResponseWriter, r *http.Request) {
+	if s.pause == nil {
+		respondError(w, "Pausing capture is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req PauseRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			respondError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var duration time.Duration
+	if req.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			respondError(w, "Invalid duration: use a positive Go duration such as 30m or 2h", http.StatusBadRequest)
+			return
+		}
+	}
+
+	state, err := s.pause.Pause(duration)
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to pause: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("capture paused", slog.String("duration", req.Duration))
+	respondJSON(w, toPauseResponse(state), http.StatusOK)
+}
+
+func (s *Server) ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	if s.pause == nil {
+		respondError(w, "Pausing capture is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	if err := s.pause.Resume(); err != nil {
+		respondError(w, fmt.Sprintf("Failed to resume: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	s.logger.Info("capture resumed")
+	respondJSON(w, PauseResponse{Paused: false}, http.StatusOK)
+}
+
+func toPauseResponse(state pause.State) PauseResponse {
+	response := PauseResponse{Paused: state.Paused}
+	if state.Paused {
+		response.Since = state.Since.Format(time.RFC3339)
+	}
+	if state.Until != nil {
+		response.Until = state.Until.Format(time.RFC3339)
+	}
+	return response
 }
 
 func (s *Server) HealthHandler(w http.ResponseWriter, r *http.Request) {
@@ -170,34 +460,182 @@ func respondError(w http.ResponseWriter, message string, statusCode int) {
 }
 
 func (s *Server) handleGetEvents(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
 	limit := DefaultEventsLimit
-	events, err := s.eventService.GetEvents(r.Context(), storage.QueryOptions{
-		Limit: limit,
-	})
+	if limitStr := params.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid limit format: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if limit <= 0 {
+			limit = DefaultEventsLimit
+		} else if limit > MaxEventsLimit {
+			respondError(w, fmt.Sprintf("limit exceeds maximum of %d", MaxEventsLimit), http.StatusBadRequest)
+			return
+		}
+	}
+
+	opts := storage.QueryOptions{
+		Source:      params.Get("source"),
+		RepoPattern: params.Get("repo"),
+		SessionID:   params.Get("session_id"),
+		ParentID:    params.Get("parent_id"),
+		Severity:    params.Get("severity"),
+		Cursor:      params.Get("cursor"),
+		Limit:       limit + 1,
+	}
+
+	if sinceStr := params.Get("since"); sinceStr != "" {
+		since, err := parseSince(sinceStr)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid since: %v", err), http.StatusBadRequest)
+			return
+		}
+		opts.StartTime = &since
+	}
+
+	events, err := s.eventService.GetEvents(r.Context(), opts)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		respondError(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		respondError(w, fmt.Sprintf("Failed to query events: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	eventList := make([]EventResponse, len(events))
-	for i, evt := range events {
-		eventList[i] = EventResponse{
-			ID:        evt.ID,
-			Timestamp: evt.Timestamp,
-			Source:    evt.Source,
-			Type:      evt.Type,
-			Repo:      evt.Repo,
-			Branch:    evt.Branch,
-			Payload:   evt.Payload,
-		}
+	var nextCursor string
+	if len(events) > limit {
+		events = events[:limit]
+		nextCursor = storage.EventCursor(events[len(events)-1])
 	}
 
 	respondJSON(w, GetEventsResponse{
-		Events: eventList,
-		Count:  len(events),
+		Events:     eventResponses(events),
+		Count:      len(events),
+		NextCursor: nextCursor,
+	}, http.StatusOK)
+}
+
+func (s *Server) handleRelatedEvents(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	related, err := s.eventService.GetRelatedEvents(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, storage.ErrEventNotFound) {
+			respondError(w, fmt.Sprintf("event not found: %s", id), http.StatusNotFound)
+			return
+		}
+		respondError(w, fmt.Sprintf("Failed to query related events: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, RelatedEventsResponse{
+		EventID: id,
+		Events:  eventResponses(related),
+		Count:   len(related),
+	}, http.StatusOK)
+}
+
+func (s *Server) handleGetEvent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	event, annotations, err := s.eventService.GetEvent(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, storage.ErrEventNotFound) {
+			respondError(w, fmt.Sprintf("event not found: %s", id), http.StatusNotFound)
+			return
+		}
+		respondError(w, fmt.Sprintf("Failed to query event: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	list := annotationResponses(annotations)
+	if list == nil {
+		list = []AnnotationResponse{}
+	}
+	respondJSON(w, EventDetailResponse{
+		Event:       eventResponses([]*events.Event{event})[0],
+		Annotations: list,
 	}, http.StatusOK)
 }
 
+func (s *Server) handleAddAnnotation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req AddAnnotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondError(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		respondError(w, "text is required", http.StatusBadRequest)
+		return
+	}
+
+	annotation, err := s.eventService.AddAnnotation(r.Context(), id, req.Text)
+	if err != nil {
+		if errors.Is(err, storage.ErrEventNotFound) {
+			respondError(w, fmt.Sprintf("event not found: %s", id), http.StatusNotFound)
+			return
+		}
+		respondError(w, fmt.Sprintf("Failed to add annotation: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	respondJSON(w, annotationResponses([]storage.Annotation{*annotation})[0], http.StatusCreated)
+}
+
+func annotationResponses(annotations []storage.Annotation) []AnnotationResponse {
+	if len(annotations) == 0 {
+		return nil
+	}
+	list := make([]AnnotationResponse, len(annotations))
+	for i, a := range annotations {
+		list[i] = AnnotationResponse{
+			ID:        a.ID,
+			EventID:   a.EventID,
+			Text:      a.Text,
+			CreatedAt: a.CreatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+	return list
+}
+
+func eventResponses(evts []*events.Event) []EventResponse {
+	list := make([]EventResponse, len(evts))
+	for i, evt := range evts {
+		list[i] = EventResponse{
+			ID:         evt.ID,
+			Timestamp:  evt.Timestamp,
+			Source:     evt.Source,
+			Type:       evt.Type,
+			Repo:       evt.Repo,
+			Branch:     evt.Branch,
+			DurationMs: evt.DurationMs,
+			SessionID:  evt.SessionID,
+			ParentID:   evt.ParentID,
+			Severity:   evt.Severity,
+			Payload:    evt.Payload,
+		}
+	}
+	return list
+}
+
+func parseSince(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	duration, err := parseDuration(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("must be a duration like '2h' or '7d', or an RFC3339 timestamp")
+	}
+	return time.Now().Add(-duration), nil
+}
+
 func (s *Server) handleEventsBySource(w http.ResponseWriter, r *http.Request) {
 	results, err := s.eventService.GetEventsBySource(r.Context())
 	if err != nil {
@@ -216,8 +654,68 @@ func (s *Server) handleEventsBySource(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, EventsBySourceResponse{Data: data}, http.StatusOK)
 }
 
+// timelineBucketFor picks the finest bucket that keeps a range to a few
+// hundred points.
+func timelineBucketFor(span time.Duration) string {
+	switch {
+	case span <= 6*time.Hour:
+		return storage.BucketMinute
+	case span <= 14*24*time.Hour:
+		return storage.BucketHour
+	case span <= 365*24*time.Hour:
+		return storage.BucketDay
+	default:
+		return storage.BucketWeek
+	}
+}
+
 func (s *Server) handleEventsTimeline(w http.ResponseWriter, r *http.Request) {
-	results, err := s.eventService.GetTimeline(r.Context())
+	end := time.Now()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		to, err := parseSearchTime(toStr, true)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid to: %v", err), http.StatusBadRequest)
+			return
+		}
+		end = to
+	}
+
+	start := end.Add(-7 * 24 * time.Hour)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err := parseSearchTime(fromStr, false)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid from: %v", err), http.StatusBadRequest)
+			return
+		}
+		start = from
+	}
+
+	if !end.After(start) {
+		respondError(w, "to must be after from", http.StatusBadRequest)
+		return
+	}
+
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" || bucket == "auto" {
+		bucket = timelineBucketFor(end.Sub(start))
+	} else if !storage.ValidBucket(bucket) {
+		respondError(w, fmt.Sprintf("invalid bucket %q: use minute, hour, day, week or auto", bucket), http.StatusBadRequest)
+		return
+	}
+
+	widths := map[string]time.Duration{
+		storage.BucketMinute: time.Minute,
+		storage.BucketHour:   time.Hour,
+		storage.BucketDay:    24 * time.Hour,
+		storage.BucketWeek:   7 * 24 * time.Hour,
+	}
+	if end.Sub(start)/widths[bucket] >= storage.MaxTimelineBuckets {
+		respondError(w, fmt.Sprintf("range too large for %s buckets (max %d)", bucket, storage.MaxTimelineBuckets), http.StatusBadRequest)
+		return
+	}
+
+	start, end = start.In(time.Local), end.In(time.Local)
+	results, err := s.eventService.GetTimeline(r.Context(), start, end, bucket)
 	if err != nil {
 		respondError(w, fmt.Sprintf("Failed to query timeline: %v", err), http.StatusInternalServerError)
 		return
@@ -226,12 +724,94 @@ func (s *Server) handleEventsTimeline(w http.ResponseWriter, r *http.Request) {
 	data := make([]TimelinePoint, len(results))
 	for i, tp := range results {
 		data[i] = TimelinePoint{
-			Hour:  tp.Hour,
-			Count: tp.Count,
+			Start:    tp.Start.Format(time.RFC3339),
+			Count:    tp.Count,
+			BySource: tp.BySource,
 		}
 	}
 
-	respondJSON(w, EventsTimelineResponse{Data: data}, http.StatusOK)
+	respondJSON(w, EventsTimelineResponse{
+		Bucket: bucket,
+		From:   start.Format(time.RFC3339),
+		To:     end.Format(time.RFC3339),
+		Data:   data,
+	}, http.StatusOK)
+}
+
+// handleHeatmap returns daily event counts for a calendar year, or for the
+// last 53 weeks starting on a Monday when no year is given.
+func (s *Server) handleHeatmap(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	today := storage.BucketStart(now, storage.BucketDay)
+	start := storage.BucketStart(today.AddDate(0, 0, -364), storage.BucketWeek)
+	end := today.AddDate(0, 0, 1)
+
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil || year < 1970 || year > now.Year() {
+			respondError(w, fmt.Sprintf("invalid year %q", yearStr), http.StatusBadRequest)
+			return
+		}
+		start = time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+		end = start.AddDate(1, 0, 0)
+	}
+
+	results, err := s.eventService.GetTimeline(r.Context(), start, end, storage.BucketDay)
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to query heatmap: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := HeatmapResponse{
+		From: start.Format("2006-01-02"),
+		To:   end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Data: make([]HeatmapDay, len(results)),
+	}
+	for i, tp := range results {
+		resp.Data[i] = HeatmapDay{Date: tp.Start.Format("2006-01-02"), Count: tp.Count}
+		resp.Total += tp.Count
+		resp.Max = max(resp.Max, tp.Count)
+	}
+
+	respondJSON(w, resp, http.StatusOK)
+}
+
+// handleHourlyDistribution returns when events happen by hour of day and day
+// of week, Monday first. since defaults to the last 90 days.
+func (s *Server) handleHourlyDistribution(w http.ResponseWriter, r *http.Request) {
+	end := time.Now()
+	start := end.AddDate(0, 0, -90)
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		since, err := parseSince(sinceStr)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid since: %v", err), http.StatusBadRequest)
+			return
+		}
+		start = since
+	}
+
+	start, end = start.In(time.Local), end.In(time.Local)
+	grid, err := s.eventService.GetActivityDistribution(r.Context(), start, end)
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to query distribution: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	resp := HourlyDistributionResponse{
+		From:     start.Format(time.RFC3339),
+		To:       end.Format(time.RFC3339),
+		Weekdays: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+	}
+	for i := range resp.Grid {
+		weekday := time.Weekday((i + 1) % 7)
+		resp.Grid[i] = grid[weekday]
+		for hour, count := range grid[weekday] {
+			resp.ByHour[hour] += count
+			resp.ByWeekday[i] += count
+		}
+	}
+
+	respondJSON(w, resp, http.StatusOK)
 }
 
 func (s *Server) handleRepoStats(w http.ResponseWriter, r *http.Request) {
@@ -270,6 +850,175 @@ func (s *Server) handleCommandStats(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, CommandStatsResponse{Data: data}, http.StatusOK)
 }
 
+// fileStatsRange reads the since and limit parameters shared by the file
+// analytics endpoints. since defaults to the last 7 days.
+func fileStatsRange(r *http.Request) (time.Time, time.Time, int, error) {
+	// Timestamps are stored in whole seconds; round up so events from the
+	// current second are included.
+	end := time.Now().Truncate(time.Second).Add(time.Second)
+	start := end.Add(-7 * 24 * time.Hour)
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		since, err := parseSince(sinceStr)
+		if err != nil {
+			return start, end, 0, fmt.Errorf("invalid since: %w", err)
+		}
+		start = since
+	}
+
+	limit := DefaultTopFilesLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			return start, end, 0, fmt.Errorf("invalid limit: must be a positive integer")
+		}
+		limit = min(n, MaxTopFilesLimit)
+	}
+	return start, end, limit, nil
+}
+
+func (s *Server) handleTopFiles(w http.ResponseWriter, r *http.Request) {
+	start, end, limit, err := fileStatsRange(r)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := s.eventService.GetTopFiles(r.Context(), start, end, limit)
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to query files: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data := make([]FileStat, len(results))
+	for i, fs := range results {
+		data[i] = FileStat{
+			Repo:     fs.Repo,
+			Path:     fs.Path,
+			Language: fs.Language,
+			Count:    fs.Count,
+		}
+	}
+
+	respondJSON(w, FileStatsResponse{
+		From: start.Format(time.RFC3339),
+		To:   end.Format(time.RFC3339),
+		Data: data,
+	}, http.StatusOK)
+}
+
+func (s *Server) handleTopLanguages(w http.ResponseWriter, r *http.Request) {
+	start, end, limit, err := fileStatsRange(r)
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := s.eventService.GetTopLanguages(r.Context(), start, end, limit)
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to query languages: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	data := make([]LanguageStat, len(results))
+	for i, ls := range results {
+		data[i] = LanguageStat{
+			Language: ls.Language,
+			Count:    ls.Count,
+			Files:    ls.Files,
+		}
+	}
+
+	respondJSON(w, LanguageStatsResponse{
+		From: start.Format(time.RFC3339),
+		To:   end.Format(time.RFC3339),
+		Data: data,
+	}, http.StatusOK)
+}
+
+// handleFocus reports context switches, focus streaks and deep-work windows.
+// since defaults to the last 7 days.
+func (s *Server) handleFocus(w http.ResponseWriter, r *http.Request) {
+	end := time.Now().Truncate(time.Second).Add(time.Second)
+	start := end.Add(-7 * 24 * time.Hour)
+	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
+		since, err := parseSince(sinceStr)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid since: %v", err), http.StatusBadRequest)
+			return
+		}
+		start = since
+	}
+
+	var opts analytics.FocusOptions
+	if v := r.URL.Query().Get("deep_work"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			respondError(w, "invalid deep_work: must be a positive duration like 45m", http.StatusBadRequest)
+			return
+		}
+		opts.DeepWorkMin = d
+	}
+	if v := r.URL.Query().Get("idle_gap"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			respondError(w, "invalid idle_gap: must be a positive duration like 15m", http.StatusBadRequest)
+			return
+		}
+		opts.IdleGap = d
+	}
+
+	evts, err := s.eventService.GetEvents(r.Context(), storage.QueryOptions{
+		StartTime: &start,
+		EndTime:   &end,
+		Ascending: true,
+		Limit:     MaxFocusEvents + 1,
+	})
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to query events: %v", err), http.StatusInternalServerError)
+		return
+	}
+	truncated := len(evts) > MaxFocusEvents
+	if truncated {
+		evts = evts[:MaxFocusEvents]
+	}
+
+	report := analytics.Focus(evts, start, end, opts)
+	resp := FocusResponse{
+		From:            start.In(time.Local).Format(time.RFC3339),
+		To:              end.In(time.Local).Format(time.RFC3339),
+		Events:          report.Events,
+		Truncated:       truncated,
+		ActiveMinutes:   int(report.Active.Minutes()),
+		Switches:        len(report.Switches),
+		SwitchesPerHour: math.Round(report.SwitchesPerHour()*10) / 10,
+		SwitchesByKind:  report.ByKind,
+		SwitchesByHour:  report.ByHour,
+		DeepWork:        make([]FocusStreak, len(report.DeepWork)),
+		DeepWorkMinutes: int(report.DeepWorkTime().Minutes()),
+	}
+	if longest := report.Longest(); longest != nil {
+		streak := focusStreak(*longest)
+		resp.LongestStreak = &streak
+	}
+	for i, dw := range report.DeepWork {
+		resp.DeepWork[i] = focusStreak(dw)
+	}
+
+	respondJSON(w, resp, http.StatusOK)
+}
+
+func focusStreak(s analytics.Streak) FocusStreak {
+	return FocusStreak{
+		Repo:            s.Repo,
+		Branches:        s.Branches,
+		Start:           s.Start.In(time.Local).Format(time.RFC3339),
+		End:             s.End.In(time.Local).Format(time.RFC3339),
+		DurationMinutes: int(s.Duration().Minutes()),
+		Events:          s.Events,
+		Switches:        s.Switches,
+	}
+}
+
 func parseDuration(s string) (time.Duration, error) {
 	if strings.HasSuffix(s, "d") {
 		days := strings.TrimSuffix(s, "d")
@@ -289,6 +1038,22 @@ func parseDuration(s string) (time.Duration, error) {
 	return time.ParseDuration(s)
 }
 
+// parseSearchTime accepts RFC3339 or a local YYYY-MM-DD date. With endOfDay
+// a bare date means the start of the following day, so "to" is inclusive.
+func parseSearchTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("use YYYY-MM-DD or RFC3339")
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -342,6 +1107,16 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		RepoPattern:   r.URL.Query().Get("repo"),
 		BranchPattern: r.URL.Query().Get("branch"),
 	}
+	if issue := r.URL.Query().Get("issue"); issue != "" {
+		searchOpts.PayloadFilter = &storage.PayloadFilter{JSONPath: "$.issue", Value: strings.ToUpper(issue)}
+	}
+
+	scope, err := storage.ParseSearchScope(r.URL.Query().Get("scope"))
+	if err != nil {
+		respondError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	searchOpts.Scope = scope
 
 	if sinceStr := r.URL.Query().Get("since"); sinceStr != "" {
 		duration, err := parseDuration(sinceStr)
@@ -353,6 +1128,26 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		searchOpts.After = &afterTime
 	}
 
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		from, err := parseSearchTime(fromStr, false)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid from: %v", err), http.StatusBadRequest)
+			return
+		}
+		if searchOpts.After == nil || from.After(*searchOpts.After) {
+			searchOpts.After = &from
+		}
+	}
+
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		to, err := parseSearchTime(toStr, true)
+		if err != nil {
+			respondError(w, fmt.Sprintf("invalid to: %v", err), http.StatusBadRequest)
+			return
+		}
+		searchOpts.Before = &to
+	}
+
 	sortOrder := r.URL.Query().Get("sort")
 	if sortOrder == "" {
 		sortOrder = "relevance"
@@ -376,15 +1171,28 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	data := make([]SearchResultResponse, len(results))
 	var nextCursor string
 	for i, result := range results {
-		data[i] = SearchResultResponse{
-			ID:        result.Event.ID,
-			Timestamp: result.Event.Timestamp,
-			Source:    result.Event.Source,
-			Type:      result.Event.Type,
-			Repo:      result.Event.Repo,
-			Branch:    result.Event.Branch,
-			Payload:   result.Event.Payload,
-			Rank:      result.Rank,
+		if result.Summary != nil {
+			summary := toSummaryResponse(result.Summary)
+			data[i] = SearchResultResponse{
+				Kind:      "summary",
+				ID:        strconv.FormatInt(result.Summary.ID, 10),
+				Timestamp: summary.PeriodStart,
+				Summary:   &summary,
+				Rank:      result.Rank,
+			}
+		} else {
+			data[i] = SearchResultResponse{
+				Kind:        "event",
+				ID:          result.Event.ID,
+				Timestamp:   result.Event.Timestamp,
+				Source:      result.Event.Source,
+				Type:        result.Event.Type,
+				Repo:        result.Event.Repo,
+				Branch:      result.Event.Branch,
+				Payload:     result.Event.Payload,
+				Annotations: annotationResponses(result.Annotations),
+				Rank:        result.Rank,
+			}
 		}
 		if result.NextCursor != "" {
 			nextCursor = result.NextCursor
@@ -404,6 +1212,76 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	respondJSON(w, response, http.StatusOK)
 }
 
+func (s *Server) handleSummaries(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, now.Location())
+		if err != nil {
+			respondError(w, "Invalid date: use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		day = parsed
+	}
+
+	summaries, err := s.eventService.GetSummaries(r.Context(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		respondError(w, fmt.Sprintf("Failed to query summaries: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Repos lists every repo of the day, so a client filtering by one can
+	// still offer the others.
+	repo := r.URL.Query().Get("repo")
+	repos := []string{}
+	seen := make(map[string]bool)
+	data := make([]SummaryResponse, 0, len(summaries))
+	for _, summary := range summaries {
+		resp := toSummaryResponse(summary)
+		for _, name := range append([]string{resp.Repo}, resp.Repos...) {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				repos = append(repos, name)
+			}
+		}
+		if repo == "" || resp.Repo == repo || slices.Contains(resp.Repos, repo) {
+			data = append(data, resp)
+		}
+	}
+	sort.Strings(repos)
+
+	respondJSON(w, SummariesResponse{
+		Date:      day.Format("2006-01-02"),
+		Repo:      repo,
+		Repos:     repos,
+		Summaries: data,
+		Count:     len(data),
+	}, http.StatusOK)
+}
+
+func toSummaryResponse(summary *storage.Summary) SummaryResponse {
+	repos := summary.Repos
+	if repos == nil {
+		repos = []string{}
+	}
+	return SummaryResponse{
+		ID:                summary.ID,
+		PeriodStart:       summary.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:         summary.PeriodEnd.Format(time.RFC3339),
+		ContextStart:      summary.ContextStart.Format(time.RFC3339),
+		Repo:              summary.Repo,
+		Repos:             repos,
+		Summary:           summary.Text,
+		EventCount:        summary.EventCount,
+		ContextEventCount: summary.ContextEventCount,
+		Provider:          summary.Provider,
+		InputTokens:       summary.InputTokens,
+		OutputTokens:      summary.OutputTokens,
+		CreatedAt:         summary.CreatedAt.Format(time.RFC3339),
+	}
+}
+
 func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 	summary := r.URL.Query().Get("summary") == "true"
 
@@ -425,28 +1303,19 @@ func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
 func (s *Server) SetupRoutes() *http.ServeMux {
 	mux := http.NewServeMux()
 
-	ingestHandler := loggingMiddleware(s.logger, limitRequestSize(s.IngestHandler))
-	statusHandler := loggingMiddleware(s.logger, s.StatusHandler)
-	healthHandler := loggingMiddleware(s.logger, s.HealthHandler)
-
-	eventsHandler := loggingMiddleware(s.logger, s.handleGetEvents)
-	eventsBySourceHandler := loggingMiddleware(s.logger, s.handleEventsBySource)
-	eventsTimelineHandler := loggingMiddleware(s.logger, s.handleEventsTimeline)
-	repoStatsHandler := loggingMiddleware(s.logger, s.handleRepoStats)
-	commandStatsHandler := loggingMiddleware(s.logger, s.handleCommandStats)
-
-	mux.HandleFunc("POST /api/v1/ingest", ingestHandler)
-	mux.HandleFunc("GET /api/v1/status", statusHandler)
-	mux.HandleFunc("GET /api/v1/health", healthHandler)
-
-	mux.HandleFunc("GET /api/v1/events", eventsHandler)
-	mux.HandleFunc("GET /api/v1/search", loggingMiddleware(s.logger, s.handleSearch))
-	mux.HandleFunc("GET /api/v1/metrics", loggingMiddleware(s.logger, s.handleMetrics))
-	mux.HandleFunc("GET /api/v1/analytics/events-by-source", eventsBySourceHandler)
-	mux.HandleFunc("GET /api/v1/analytics/events-timeline", eventsTimelineHandler)
-	mux.HandleFunc("GET /api/v1/analytics/repo-stats", repoStatsHandler)
-	mux.HandleFunc("GET /api/v1/analytics/command-stats", commandStatsHandler)
+	for _, rt := range s.routes() {
+		h := rt.handler
+		if rt.sameOrigin {
+			h = s.requireSameOrigin(h)
+		}
+		if rt.auth {
+			h = s.requireToken(h)
+		}
+		mux.HandleFunc(rt.method+" "+rt.path, loggingMiddleware(s.logger, s.limitRoute(rt.group, h)))
+	}
 
+	mux.HandleFunc("GET /share/{token}", s.limitRoute(config.RouteGroupAPI, s.handleShare))
+	mux.HandleFunc("GET /assets/{file}", s.handleAsset)
 	mux.HandleFunc("GET /", s.handleFrontend)
 
 	return mux
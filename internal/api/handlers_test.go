@@ -2,15 +2,26 @@ package api
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"devlog/internal/config"
 	"devlog/internal/events"
+	"devlog/internal/pause"
 	"devlog/internal/storage"
+
+	_ "devlog/modules/forge"
+	_ "devlog/modules/github"
 )
 
 func setupTestServer(t *testing.T) (*Server, *storage.Storage) {
@@ -29,7 +40,7 @@ func setupTestServer(t *testing.T) (*Server, *storage.Storage) {
 	cfg := config.DefaultConfig()
 	cfg.Modules["git"] = config.ComponentConfig{Enabled: true}
 	cfg.Modules["shell"] = config.ComponentConfig{Enabled: true}
-	server := NewServer(store, func() *config.Config { return cfg }, nil)
+	server := NewServer(store, nil, func() *config.Config { return cfg }, nil)
 	return server, store
 }
 
@@ -143,6 +154,47 @@ func TestIngestHandlerInvalidEvent(t *testing.T) {
 	}
 }
 
+func TestIngestHandlerDraining(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	server.Drain()
+
+	event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	eventJSON, err := event.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ingest", bytes.NewReader(eventJSON))
+	w := httptest.NewRecorder()
+
+	server.IngestHandler(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("expected Retry-After header while draining")
+	}
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response["queue"] != true {
+		t.Errorf("got queue=%v, want true", response["queue"])
+	}
+
+	count, err := store.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("got count %d, want 0", count)
+	}
+}
+
 func TestStatusHandler(t *testing.T) {
 	server, store := setupTestServer(t)
 	defer store.Close()
@@ -228,3 +280,874 @@ func TestSetupRoutes(t *testing.T) {
 		t.Errorf("status route: got status %d, want %d", statusW.Code, http.StatusOK)
 	}
 }
+
+func TestSummariesHandler(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.Local)
+	for _, summary := range []*storage.Summary{
+		{PeriodStart: day.Add(10 * time.Hour), PeriodEnd: day.Add(10*time.Hour + 30*time.Minute), Text: "morning", Repos: []string{"/src/devlog"}, EventCount: 4},
+		{PeriodStart: day.Add(9 * time.Hour), PeriodEnd: day.Add(9*time.Hour + 30*time.Minute), Text: "early", EventCount: 2},
+		{PeriodStart: day.AddDate(0, 0, 1).Add(9 * time.Hour), PeriodEnd: day.AddDate(0, 0, 1).Add(10 * time.Hour), Text: "next day"},
+	} {
+		if err := store.InsertSummaryContext(context.Background(), summary); err != nil {
+			t.Fatalf("InsertSummaryContext() error: %v", err)
+		}
+	}
+
+	mux := server.SetupRoutes()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/summaries?date=2025-03-14", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var response SummariesResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Count != 2 {
+		t.Fatalf("got %d summaries, want 2", response.Count)
+	}
+	if response.Summaries[0].Summary != "early" || response.Summaries[1].Summary != "morning" {
+		t.Errorf("summaries out of order: %+v", response.Summaries)
+	}
+	if len(response.Summaries[1].Repos) != 1 || response.Summaries[1].Repos[0] != "/src/devlog" {
+		t.Errorf("got repos %v", response.Summaries[1].Repos)
+	}
+
+	if len(response.Repos) != 1 || response.Repos[0] != "/src/devlog" {
+		t.Errorf("got day repos %v, want [/src/devlog]", response.Repos)
+	}
+
+	repoReq := httptest.NewRequest(http.MethodGet, "/api/v1/summaries?date=2025-03-14&repo=/src/devlog", nil)
+	repoW := httptest.NewRecorder()
+	mux.ServeHTTP(repoW, repoReq)
+	var filtered SummariesResponse
+	if err := json.NewDecoder(repoW.Body).Decode(&filtered); err != nil {
+		t.Fatal(err)
+	}
+	if filtered.Count != 1 || filtered.Summaries[0].Summary != "morning" || len(filtered.Repos) != 1 {
+		t.Errorf("repo filter returned %+v", filtered)
+	}
+
+	badReq := httptest.NewRequest(http.MethodGet, "/api/v1/summaries?date=yesterday", nil)
+	badW := httptest.NewRecorder()
+	mux.ServeHTTP(badW, badReq)
+	if badW.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for invalid date, want %d", badW.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSearchHandlerScope(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	start := time.Date(2025, 3, 14, 10, 0, 0, 0, time.Local)
+	if err := store.InsertSummaryContext(context.Background(), &storage.Summary{
+		PeriodStart: start,
+		PeriodEnd:   start.Add(30 * time.Minute),
+		Text:        "Shipped the deploy pipeline",
+	}); err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Payload["command"] = "make deploy"
+	if err := store.InsertEvent(event); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+
+	mux := server.SetupRoutes()
+
+	tests := []struct {
+		scope     string
+		wantKinds []string
+	}{
+		{"", []string{"event"}},
+		{"summaries", []string{"summary"}},
+		{"all", []string{"event", "summary"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.scope, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=deploy&sort=time_desc&scope="+tt.scope, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+			}
+
+			var response SearchResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+
+			var kinds []string
+			for _, r := range response.Results {
+				kinds = append(kinds, r.Kind)
+				if r.Kind == "summary" && (r.Summary == nil || r.Summary.Summary != "Shipped the deploy pipeline") {
+					t.Errorf("got summary result %+v", r.Summary)
+				}
+			}
+			if strings.Join(kinds, ",") != strings.Join(tt.wantKinds, ",") {
+				t.Errorf("got kinds %v, want %v", kinds, tt.wantKinds)
+			}
+		})
+	}
+
+	badReq := httptest.NewRequest(http.MethodGet, "/api/v1/search?q=deploy&scope=notes", nil)
+	badW := httptest.NewRecorder()
+	mux.ServeHTTP(badW, badReq)
+	if badW.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for invalid scope, want %d", badW.Code, http.StatusBadRequest)
+	}
+}
+
+func TestSearchHandlerDateRange(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	for _, day := range []string{"2025-03-09", "2025-03-10", "2025-03-11"} {
+		ts, _ := time.ParseInLocation("2006-01-02", day, time.Local)
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Timestamp = ts.Add(12 * time.Hour).UTC().Format(time.RFC3339)
+		event.Payload["command"] = "make release " + day
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	mux := server.SetupRoutes()
+
+	tests := []struct {
+		query string
+		want  int
+		code  int
+	}{
+		{"q=release&from=2025-03-10", 2, http.StatusOK},
+		{"q=release&to=2025-03-10", 2, http.StatusOK},
+		{"q=release&from=2025-03-10&to=2025-03-10", 1, http.StatusOK},
+		{"from=2025-03-11", 1, http.StatusOK},
+		{"q=release&from=march", 0, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/search?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var response SearchResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Count != tt.want {
+				t.Errorf("got %d results, want %d", response.Count, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchHandlerIssue(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	for _, issue := range []string{"PROJ-1", "PROJ-1", "ENG-7", ""} {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Payload["message"] = "fix"
+		if issue != "" {
+			event.Payload["issue"] = issue
+		}
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/search?issue=proj-1", nil)
+	w := httptest.NewRecorder()
+	server.SetupRoutes().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+
+	var response SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Count != 2 {
+		t.Errorf("got %d results, want the 2 PROJ-1 events", response.Count)
+	}
+}
+
+func TestEventsTimelineHandler(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	day, _ := time.ParseInLocation("2006-01-02", "2025-03-10", time.Local)
+	for _, at := range []time.Duration{9 * time.Hour, 9*time.Hour + 20*time.Minute, 15 * time.Hour} {
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Timestamp = day.Add(at).UTC().Format(time.RFC3339)
+		event.Payload["command"] = "make"
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	mux := server.SetupRoutes()
+
+	tests := []struct {
+		query   string
+		code    int
+		bucket  string
+		buckets int
+	}{
+		{"", http.StatusOK, "hour", 169},
+		{"from=2025-03-10&to=2025-03-10", http.StatusOK, "hour", 24},
+		{"from=2025-03-10&to=2025-03-10&bucket=day", http.StatusOK, "day", 1},
+		{"from=2025-03-01&to=2025-03-31", http.StatusOK, "day", 31},
+		{"from=2025-03-10&to=2025-03-10&bucket=fortnight", http.StatusBadRequest, "", 0},
+		{"from=2020-01-01&to=2025-03-10&bucket=minute", http.StatusBadRequest, "", 0},
+		{"from=2025-03-11&to=2025-03-10", http.StatusBadRequest, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/analytics/events-timeline?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.code, w.Body.String())
+			}
+			if tt.code != http.StatusOK {
+				return
+			}
+
+			var response EventsTimelineResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if response.Bucket != tt.bucket || len(response.Data) != tt.buckets {
+				t.Errorf("got %d %s buckets, want %d %s", len(response.Data), response.Bucket, tt.buckets, tt.bucket)
+			}
+		})
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analytics/events-timeline?from=2025-03-10&to=2025-03-10", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	var response EventsTimelineResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if p := response.Data[9]; p.Count != 2 || p.BySource["shell"] != 2 {
+		t.Errorf("09:00 bucket = %+v, want 2 shell events", p)
+	}
+}
+
+func TestTopFilesHandlers(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	commit := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	commit.Repo = "devlog"
+	commit.Payload["files"] = []interface{}{"main.go", "README.md"}
+	shell := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	shell.Repo = "devlog"
+	shell.Payload["command"] = "vim main.go"
+	for _, event := range []*events.Event{commit, shell} {
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	mux := server.SetupRoutes()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analytics/top-files?since=1d", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("top-files status %d: %s", w.Code, w.Body.String())
+	}
+	var files FileStatsResponse
+	if err := json.NewDecoder(w.Body).Decode(&files); err != nil {
+		t.Fatal(err)
+	}
+	if len(files.Data) != 2 || files.Data[0].Path != "main.go" || files.Data[0].Count != 2 || files.Data[0].Language != "Go" {
+		t.Errorf("top-files = %+v, want main.go touched twice first", files.Data)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/analytics/top-languages?since=1d&limit=1", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var languages LanguageStatsResponse
+	if err := json.NewDecoder(w.Body).Decode(&languages); err != nil {
+		t.Fatal(err)
+	}
+	if len(languages.Data) != 1 || languages.Data[0].Language != "Go" || languages.Data[0].Files != 1 {
+		t.Errorf("top-languages = %+v, want only Go", languages.Data)
+	}
+
+	for _, query := range []string{"limit=0", "limit=abc", "since=soon"} {
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/analytics/top-files?"+query, nil)
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestHeatmapAndDistributionHandlers(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	for i := 0; i < 3; i++ {
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Payload["command"] = "make"
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	mux := server.SetupRoutes()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analytics/heatmap", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var heatmap HeatmapResponse
+	if err := json.NewDecoder(w.Body).Decode(&heatmap); err != nil {
+		t.Fatal(err)
+	}
+	if len(heatmap.Data) < 365 || len(heatmap.Data) > 371 {
+		t.Errorf("got %d days, want about a year", len(heatmap.Data))
+	}
+	last := heatmap.Data[len(heatmap.Data)-1]
+	if last.Date != time.Now().Format("2006-01-02") || last.Count != 3 || heatmap.Total != 3 || heatmap.Max != 3 {
+		t.Errorf("heatmap ends with %+v (total %d, max %d), want 3 events today", last, heatmap.Total, heatmap.Max)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/analytics/heatmap?year=2024", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	heatmap = HeatmapResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&heatmap); err != nil {
+		t.Fatal(err)
+	}
+	if len(heatmap.Data) != 366 || heatmap.From != "2024-01-01" || heatmap.To != "2024-12-31" {
+		t.Errorf("2024 heatmap = %d days from %s to %s", len(heatmap.Data), heatmap.From, heatmap.To)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/analytics/hourly-distribution?since=1d", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var dist HourlyDistributionResponse
+	if err := json.NewDecoder(w.Body).Decode(&dist); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	if dist.ByHour[now.Hour()] != 3 || dist.ByWeekday[(int(now.Weekday())+6)%7] != 3 {
+		t.Errorf("distribution = %+v, want 3 events in the current hour and weekday", dist)
+	}
+
+	for _, path := range []string{"/api/v1/analytics/heatmap?year=abc", "/api/v1/analytics/heatmap?year=1900", "/api/v1/analytics/hourly-distribution?since=soon"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400", path, w.Code)
+		}
+	}
+}
+
+func TestFocusHandler(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	start := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	for i, repo := range []string{"api", "api", "api", "web"} {
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Timestamp = start.Add(time.Duration(i) * 10 * time.Minute).Format(time.RFC3339)
+		event.Repo = repo
+		event.Payload["command"] = "make"
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	mux := server.SetupRoutes()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/analytics/focus?since=1d&deep_work=20m", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("focus status %d: %s", w.Code, w.Body.String())
+	}
+	var focus FocusResponse
+	if err := json.NewDecoder(w.Body).Decode(&focus); err != nil {
+		t.Fatal(err)
+	}
+	if focus.Events != 4 || focus.Switches != 1 || focus.SwitchesByKind["repo"] != 1 || focus.ActiveMinutes != 30 {
+		t.Errorf("focus = %+v, want 4 events, one repo switch in 30 active minutes", focus)
+	}
+	if focus.LongestStreak == nil || focus.LongestStreak.Repo != "api" || focus.LongestStreak.DurationMinutes != 20 {
+		t.Errorf("longest streak = %+v, want 20 minutes in api", focus.LongestStreak)
+	}
+	if len(focus.DeepWork) != 1 || focus.DeepWorkMinutes != 20 {
+		t.Errorf("deep work = %+v (%d min), want the api streak", focus.DeepWork, focus.DeepWorkMinutes)
+	}
+
+	for _, path := range []string{"/api/v1/analytics/focus?since=soon", "/api/v1/analytics/focus?deep_work=long", "/api/v1/analytics/focus?idle_gap=-5m"} {
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400", path, w.Code)
+		}
+	}
+}
+
+func TestBatchIngestHandler(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	first := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	second := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	second.Payload["command"] = "go test ./..."
+
+	firstJSON, _ := first.ToJSON()
+	secondJSON, _ := second.ToJSON()
+
+	body := strings.Join([]string{
+		string(firstJSON),
+		string(secondJSON),
+		"",
+		string(firstJSON),
+		`{"v":1,"id":"bad"}`,
+	}, "\n")
+
+	mux := server.SetupRoutes()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ingest/batch", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var response BatchIngestResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Ingested != 2 || response.Duplicates != 1 {
+		t.Errorf("got ingested=%d duplicates=%d, want 2 and 1", response.Ingested, response.Duplicates)
+	}
+	if response.OK || len(response.Errors) != 1 || response.Errors[0].Line != 5 {
+		t.Errorf("got ok=%v errors=%+v, want one error on line 5", response.OK, response.Errors)
+	}
+
+	count, err := store.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("got %d stored events, want 2", count)
+	}
+}
+
+func TestBatchIngestHandlerRejectsOversizedBatch(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	lines := make([]string, 0, MaxBatchEvents+1)
+	for range MaxBatchEvents + 1 {
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Payload["command"] = "make build"
+		data, _ := event.ToJSON()
+		lines = append(lines, string(data), "")
+	}
+
+	mux := server.SetupRoutes()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ingest/batch", strings.NewReader(strings.Join(lines, "\n")))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge || !strings.Contains(w.Body.String(), "exceeds maximum") {
+		t.Fatalf("got status %d, want %d for the event count: %s", w.Code, http.StatusRequestEntityTooLarge, w.Body.String())
+	}
+	count, err := store.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("got %d stored events, want none from a rejected batch", count)
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	secret := "0123456789abcdef0123456789abcdef"
+	server.config.Modules["github"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"webhook_secret": secret},
+	}
+
+	body := []byte(`{"action":"closed","repository":{"name":"devlog","full_name":"me/devlog"},"pull_request":{"number":12,"title":"Add webhooks","merged":true,"head":{"ref":"feature/webhooks"}}}`)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	mux := server.SetupRoutes()
+	send := func(module, sig string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+module, bytes.NewReader(body))
+		req.Header.Set("X-GitHub-Event", "pull_request")
+		req.Header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+		req.Header.Set("X-Hub-Signature-256", sig)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("github", "sha256=deadbeef"); w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d for bad signature, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if w := send("shell", signature); w.Code != http.StatusNotFound {
+		t.Errorf("got status %d for module without receiver, want %d", w.Code, http.StatusNotFound)
+	}
+
+	w := send("github", signature)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var response BatchIngestResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Ingested != 1 {
+		t.Errorf("got ingested=%d, want 1", response.Ingested)
+	}
+
+	if w := send("github", signature); w.Code != http.StatusOK {
+		t.Errorf("got status %d for redelivery, want %d", w.Code, http.StatusOK)
+	}
+
+	evts, err := store.QueryEvents(storage.QueryOptions{Source: "github"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evts) != 1 {
+		t.Fatalf("got %d github events, want 1", len(evts))
+	}
+	if evts[0].Type != string(events.TypePRMerged) || evts[0].Branch != "feature/webhooks" {
+		t.Errorf("got type=%s branch=%s", evts[0].Type, evts[0].Branch)
+	}
+}
+
+// TestWebhookHandlerAppliesModuleQuota checks that the forge quota covers
+// the gitlab events its webhook produces.
+func TestWebhookHandlerAppliesModuleQuota(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	secret := "0123456789abcdef0123456789abcdef"
+	server.config.Modules["forge"] = config.ComponentConfig{
+		Enabled: true,
+		Config: map[string]interface{}{
+			"gitlab":              map[string]interface{}{"webhook_secret": secret},
+			"max_events_per_hour": 1,
+		},
+	}
+
+	mux := server.SetupRoutes()
+	send := func(iid int) BatchIngestResponse {
+		body := fmt.Sprintf(`{"object_kind":"merge_request","user":{"username":"me"},"project":{"name":"devlog","path_with_namespace":"team/devlog"},"object_attributes":{"iid":%d,"title":"Add forge","source_branch":"feature/forge","action":"open"}}`, iid)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/forge", strings.NewReader(body))
+		req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+		req.Header.Set("X-Gitlab-Token", secret)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+		}
+		var response BatchIngestResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	if response := send(12); response.Ingested != 1 {
+		t.Errorf("first delivery: got %+v, want it ingested", response)
+	}
+	if response := send(13); response.Ingested != 0 || response.Filtered != 1 {
+		t.Errorf("second delivery: got %+v, want it filtered by the forge quota", response)
+	}
+}
+
+func TestGetEventsPagination(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Timestamp = base.Add(time.Duration(i/2) * time.Minute).Format(time.RFC3339)
+		event.Repo = "/home/dev/devlog"
+		event.Payload["command"] = fmt.Sprintf("cmd-%d", i)
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	other := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	other.Repo = "/home/dev/website"
+	other.Timestamp = base.Add(-48 * time.Hour).Format(time.RFC3339)
+	if err := store.InsertEvent(other); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+
+	mux := server.SetupRoutes()
+	get := func(query string) (int, GetEventsResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events"+query, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		var response GetEventsResponse
+		json.NewDecoder(w.Body).Decode(&response)
+		return w.Code, response
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+	pages := 0
+	for {
+		query := "?limit=2&source=shell"
+		if cursor != "" {
+			query += "&cursor=" + cursor
+		}
+		code, page := get(query)
+		if code != http.StatusOK {
+			t.Fatalf("got status %d", code)
+		}
+		pages++
+		for _, e := range page.Events {
+			if seen[e.ID] {
+				t.Fatalf("event %s returned twice", e.ID)
+			}
+			seen[e.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != 5 || pages != 3 {
+		t.Errorf("got %d events over %d pages, want 5 over 3", len(seen), pages)
+	}
+
+	if _, resp := get("?repo=website"); resp.Count != 1 || resp.Events[0].ID != other.ID {
+		t.Errorf("repo filter returned %+v", resp.Events)
+	}
+	if _, resp := get("?since=24h"); resp.Count != 5 {
+		t.Errorf("since filter returned %d events, want 5", resp.Count)
+	}
+	if _, resp := get("?since=" + base.Add(30*time.Second).Format(time.RFC3339)); resp.Count != 3 {
+		t.Errorf("RFC3339 since returned %d events, want 3", resp.Count)
+	}
+
+	for _, bad := range []string{"?cursor=not-a-cursor", "?limit=abc", "?limit=1000", "?since=yesterday"} {
+		if code, _ := get(bad); code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", bad, code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestRelatedEventsHandler(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	conv := events.NewEvent(string(events.SourceClaude), string(events.TypeConversation))
+	conv.SessionID = "session-1"
+	cmd := events.NewEvent(string(events.SourceClaude), string(events.TypeCommand))
+	cmd.SessionID = "session-1"
+	cmd.ParentID = conv.ID
+	cmd.DurationMs = 1200
+	cmd.Severity = string(events.SeverityError)
+	for _, e := range []*events.Event{conv, cmd} {
+		if err := store.InsertEvent(e); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	mux := server.SetupRoutes()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/"+conv.ID+"/related", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body.String())
+	}
+
+	var response RelatedEventsResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Count != 1 {
+		t.Fatalf("got %d related events, want 1", response.Count)
+	}
+	got := response.Events[0]
+	if got.ID != cmd.ID || got.ParentID != conv.ID || got.DurationMs != 1200 || got.Severity != "error" {
+		t.Errorf("related event = %+v", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/events/missing/related", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing event: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestEventAnnotationHandlers(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Payload["command"] = "make deploy"
+	if err := store.InsertEvent(event); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+	mux := server.SetupRoutes()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events/"+event.ID+"/annotations", strings.NewReader(`{"text":"this was the root cause"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("add annotation status %d: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/events/"+event.ID, nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var detail EventDetailResponse
+	if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+		t.Fatal(err)
+	}
+	if detail.Event.ID != event.ID || len(detail.Annotations) != 1 || detail.Annotations[0].Text != "this was the root cause" {
+		t.Errorf("event detail = %+v", detail)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/search?q=deploy", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var search SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&search); err != nil {
+		t.Fatal(err)
+	}
+	if len(search.Results) != 1 || len(search.Results[0].Annotations) != 1 {
+		t.Errorf("search results = %+v, want the annotation attached", search.Results)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodGet, "/api/v1/events/missing", "", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/events/missing/annotations", `{"text":"x"}`, http.StatusNotFound},
+		{http.MethodPost, "/api/v1/events/" + event.ID + "/annotations", `{"text":" "}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s %s: got status %d, want %d", tc.method, tc.path, w.Code, tc.want)
+		}
+	}
+}
+
+func TestPauseHandlers(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+	server.SetPause(pause.NewController(t.TempDir()))
+	mux := server.SetupRoutes()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pause", strings.NewReader(`{"duration":"2h"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("pause: status %d: %s", w.Code, w.Body.String())
+	}
+	var paused PauseResponse
+	if err := json.NewDecoder(w.Body).Decode(&paused); err != nil {
+		t.Fatal(err)
+	}
+	if !paused.Paused || paused.Until == "" {
+		t.Errorf("unexpected pause response: %+v", paused)
+	}
+
+	event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	event.Payload["hash"] = "abc123"
+	eventJSON, _ := event.ToJSON()
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/ingest", bytes.NewReader(eventJSON))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var ingest IngestEventResponse
+	if err := json.NewDecoder(w.Body).Decode(&ingest); err != nil {
+		t.Fatal(err)
+	}
+	if !ingest.Filtered {
+		t.Errorf("expected event to be filtered while paused: %+v", ingest)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/status", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var status StatusResponse
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatal(err)
+	}
+	if !status.Paused || status.PausedUntil == "" {
+		t.Errorf("status should report pause: %+v", status)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/resume", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("resume: status %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/pause", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var state PauseResponse
+	if err := json.NewDecoder(w.Body).Decode(&state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Paused {
+		t.Errorf("expected capture to be resumed: %+v", state)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/pause", strings.NewReader(`{"duration":"soon"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid duration, got %d", w.Code)
+	}
+}
@@ -1,24 +1,18 @@
 package api
 
 import (
+	"context"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"devlog/internal/config"
 	"devlog/internal/metrics"
 )
 
-const (
-	MaxRequestSize = 1 << 20
-)
-
-func limitRequestSize(next http.HandlerFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		r.Body = http.MaxBytesReader(w, r.Body, MaxRequestSize)
-		next(w, r)
-	}
-}
-
 type loggerInterface interface {
 	Debug(msg string, args ...any)
 }
@@ -39,3 +33,99 @@ func loggingMiddleware(logger loggerInterface, next http.HandlerFunc) http.Handl
 			slog.Duration("duration", duration))
 	}
 }
+
+// requireToken rejects requests without a valid bearer token while
+// http.auth_enabled is set. The config is read per request so tokens created
+// or revoked while the daemon runs take effect on the next reload.
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="devlog"`)
+			respondError(w, "missing or invalid API token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// socketConnKey marks the context of connections accepted on the daemon's
+// Unix socket.
+type socketConnKey struct{}
+
+// SocketConnContext is the http.Server ConnContext of the Unix socket
+// listener. The socket's file mode already limits it to the daemon's user,
+// so requests over it are not asked for an API token.
+func SocketConnContext(ctx context.Context, _ net.Conn) context.Context {
+	return context.WithValue(ctx, socketConnKey{}, true)
+}
+
+// authorized reports whether a request may use authenticated endpoints:
+// auth is off, it came over the Unix socket, or it carries a valid bearer
+// token.
+func (s *Server) authorized(r *http.Request) bool {
+	cfg := s.configGetter()
+	if cfg == nil || !cfg.HTTP.AuthEnabled {
+		return true
+	}
+	if overSocket, _ := r.Context().Value(socketConnKey{}).(bool); overSocket {
+		return true
+	}
+
+	return s.hasValidToken(r, cfg)
+}
+
+func (s *Server) hasValidToken(r *http.Request, cfg *config.Config) bool {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	_, ok := cfg.VerifyToken(strings.TrimSpace(token))
+	return ok
+}
+
+// requireSameOrigin guards endpoints that rewrite the daemon's config. A
+// request with a valid token, or over the Unix socket, passes. Otherwise,
+// even with auth off, it must address the daemon as localhost or by IP
+// address and, if a browser sent an Origin, come from that same host: a
+// page on another site that re-points its own name at 127.0.0.1 (DNS
+// rebinding) fails the first check, and a cross-site form the second.
+func (s *Server) requireSameOrigin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if overSocket, _ := r.Context().Value(socketConnKey{}).(bool); overSocket {
+			next(w, r)
+			return
+		}
+		if cfg := s.configGetter(); cfg != nil && s.hasValidToken(r, cfg) {
+			next(w, r)
+			return
+		}
+		if !localHost(r.Host) || !sameOrigin(r) {
+			respondError(w, "the config API needs an API token, or a same-origin request to localhost", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// localHost reports whether a Host header names the daemon by IP address
+// or as localhost, rather than by a DNS name someone else controls.
+func localHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.EqualFold(host, "localhost") || net.ParseIP(host) != nil
+}
+
+// sameOrigin reports whether a browser request came from a page served by
+// this host. Requests without an Origin header are not from a cross-site
+// page.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
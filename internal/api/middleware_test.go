@@ -2,91 +2,13 @@ package api
 
 import (
 	"bytes"
-	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
-)
-
-func TestLimitRequestSizeMiddleware(t *testing.T) {
-	t.Run("allows small requests", func(t *testing.T) {
-		handlerCalled := false
-		handler := func(w http.ResponseWriter, r *http.Request) {
-			handlerCalled = true
-			w.WriteHeader(http.StatusOK)
-		}
-
-		middlewareHandler := limitRequestSize(handler)
-
-		// Create a small request
-		smallBody := bytes.NewReader([]byte("small"))
-		req := httptest.NewRequest("POST", "/", smallBody)
-		w := httptest.NewRecorder()
-
-		middlewareHandler(w, req)
-
-		if !handlerCalled {
-			t.Error("handler not called for small request")
-		}
-
-		if w.Code != http.StatusOK {
-			t.Errorf("expected 200, got %d", w.Code)
-		}
-	})
-
-	t.Run("rejects large requests", func(t *testing.T) {
-		handler := func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-		}
-
-		middlewareHandler := limitRequestSize(handler)
-
-		// Create a request larger than MaxRequestSize
-		largeBody := bytes.NewReader(make([]byte, MaxRequestSize+1))
-		req := httptest.NewRequest("POST", "/", largeBody)
-		w := httptest.NewRecorder()
+	"time"
 
-		middlewareHandler(w, req)
-
-		// Should get an error due to size limit
-		// The MaxBytesReader will return an error when trying to read more than MaxRequestSize
-		t.Logf("response status: %d", w.Code)
-	})
-
-	t.Run("MaxRequestSize is defined", func(t *testing.T) {
-		if MaxRequestSize == 0 {
-			t.Error("MaxRequestSize is zero")
-		}
-
-		// Should be 1MB (1 << 20)
-		expectedSize := 1 << 20
-		if MaxRequestSize != int64(expectedSize) {
-			t.Errorf("expected MaxRequestSize %d, got %d", expectedSize, MaxRequestSize)
-		}
-	})
-
-	t.Run("sets correct size limit", func(t *testing.T) {
-		handler := func(w http.ResponseWriter, r *http.Request) {
-			// Try to read the body
-			body, _ := io.ReadAll(r.Body)
-			w.Write(body)
-		}
-
-		middlewareHandler := limitRequestSize(handler)
-
-		// Create a request with exactly MaxRequestSize - should work
-		body := make([]byte, MaxRequestSize)
-		req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
-		w := httptest.NewRecorder()
-
-		middlewareHandler(w, req)
-
-		// Should succeed
-		if w.Code >= 400 {
-			t.Logf("request at MaxRequestSize resulted in error status: %d", w.Code)
-		}
-	})
-}
+	"devlog/internal/config"
+)
 
 func TestLoggingMiddleware(t *testing.T) {
 	t.Run("logs request information", func(t *testing.T) {
@@ -187,7 +109,8 @@ func TestMiddlewareCombination(t *testing.T) {
 		// Apply both middlewares
 		logger := &TestLogger{}
 		middleware1 := loggingMiddleware(logger, handler)
-		middleware2 := limitRequestSize(middleware1)
+		server, _ := setupTestServer(t)
+		middleware2 := server.limitRoute(config.RouteGroupIngest, middleware1)
 
 		// Create a valid request
 		req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte("test")))
@@ -226,3 +149,66 @@ func TestMiddlewareErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestRequireToken(t *testing.T) {
+	server, _ := setupTestServer(t)
+	mux := server.SetupRoutes()
+
+	token, err := config.GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.config.AddToken("laptop", token, time.Now())
+
+	get := func(path, auth string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := get("/api/v1/events", ""); code != http.StatusOK {
+		t.Errorf("auth disabled: got %d, want 200", code)
+	}
+
+	server.config.HTTP.AuthEnabled = true
+
+	tests := []struct {
+		path string
+		auth string
+		want int
+	}{
+		{"/api/v1/events", "", http.StatusUnauthorized},
+		{"/api/v1/events", "Bearer wrong", http.StatusUnauthorized},
+		{"/api/v1/events", "Basic " + token, http.StatusUnauthorized},
+		{"/api/v1/events", "Bearer " + token, http.StatusOK},
+		{"/api/v1/search?q=x", "", http.StatusUnauthorized},
+		{"/api/v1/status", "", http.StatusUnauthorized},
+		{"/api/v1/health", "", http.StatusOK},
+		{"/", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if code := get(tt.path, tt.auth); code != tt.want {
+			t.Errorf("GET %s with %q: got %d, want %d", tt.path, tt.auth, code, tt.want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ingest", bytes.NewReader([]byte("{}")))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized || w.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("ingest without token: got %d, want 401 with WWW-Authenticate", w.Code)
+	}
+
+	// Requests over the Unix socket need no token.
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/events", nil)
+	req = req.WithContext(SocketConnContext(req.Context(), nil))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("GET over the socket without token: got %d, want 200", w.Code)
+	}
+}
@@ -0,0 +1,496 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+)
+
+// route describes one endpoint of the HTTP API. SetupRoutes registers the
+// routes and OpenAPISpec documents them from the same table, so the spec
+// cannot drift from what the daemon serves.
+type route struct {
+	method      string
+	path        string
+	operationID string
+	summary     string
+	tag         string
+	group       string // rate limit group
+	auth        bool   // requires an API token while http.auth_enabled is set
+	sameOrigin  bool   // without a token, only from localhost or an IP address and the same origin
+	params      []param
+	request     interface{} // request body; nil when there is none
+	requestType string      // request content type, application/json by default
+	response    interface{} // success body; nil for free-form JSON
+	status      int         // success status, 200 by default
+	stream      bool        // responds with server-sent events
+	handler     http.HandlerFunc
+}
+
+type param struct {
+	name        string
+	in          string // "path" or "query"
+	kind        string // "string", "integer" or "array"
+	description string
+}
+
+func pathParam(name, description string) param {
+	return param{name: name, in: "path", kind: "string", description: description}
+}
+
+func queryParam(name, kind, description string) param {
+	return param{name: name, in: "query", kind: kind, description: description}
+}
+
+// anyJSON marks a request body that takes any JSON document.
+type anyJSON struct{}
+
+const ndjson = "application/x-ndjson"
+
+func (s *Server) routes() []route {
+	since := queryParam("since", "string", "Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time")
+
+	return []route{
+		{method: "POST", path: "/api/v1/ingest", operationID: "ingestEvent", tag: "ingest",
+			summary: "Store one event",
+			group:   config.RouteGroupIngest, auth: true,
+			request: events.Event{}, response: IngestEventResponse{},
+			handler: s.IngestHandler},
+		{method: "POST", path: "/api/v1/ingest/batch", operationID: "ingestBatch", tag: "ingest",
+			summary: "Store up to 500 events sent as newline-delimited JSON",
+			group:   config.RouteGroupBatch, auth: true,
+			request: []events.Event{}, requestType: ndjson, response: BatchIngestResponse{},
+			handler: s.BatchIngestHandler},
+		{method: "POST", path: "/api/v1/webhooks/{module}", operationID: "receiveWebhook", tag: "ingest",
+			summary: "Turn a webhook delivery into events; the module checks the signature",
+			group:   config.RouteGroupWebhooks,
+			params:  []param{pathParam("module", "Module that receives the webhook, e.g. github")},
+			request: anyJSON{}, response: BatchIngestResponse{},
+			handler: s.WebhookHandler},
+		{method: "GET", path: "/api/v1/status", operationID: "getStatus", tag: "daemon",
+			summary: "Daemon status and event count",
+			group:   config.RouteGroupAPI, auth: true,
+			response: StatusResponse{},
+			handler:  s.StatusHandler},
+		{method: "GET", path: "/api/v1/health", operationID: "getHealth", tag: "daemon",
+			summary:  "Health checks; answers 503 when one fails",
+			group:    config.RouteGroupAPI,
+			response: HealthResponse{},
+			handler:  s.HealthHandler},
+		{method: "GET", path: "/api/v1/pause", operationID: "getPause", tag: "daemon",
+			summary: "Whether capture is paused",
+			group:   config.RouteGroupAPI, auth: true,
+			response: PauseResponse{},
+			handler:  s.PauseStatusHandler},
+		{method: "POST", path: "/api/v1/pause", operationID: "pause", tag: "daemon",
+			summary: "Pause capture, indefinitely or for a duration",
+			group:   config.RouteGroupAPI, auth: true,
+			request: PauseRequest{}, response: PauseResponse{},
+			handler: s.PauseHandler},
+		{method: "POST", path: "/api/v1/resume", operationID: "resume", tag: "daemon",
+			summary: "Resume capture",
+			group:   config.RouteGroupAPI, auth: true,
+			response: PauseResponse{},
+			handler:  s.ResumeHandler},
+		{method: "GET", path: "/api/v1/config", operationID: "getConfig", tag: "daemon",
+			summary: "config.yaml as JSON, with secrets redacted",
+			group:   config.RouteGroupAPI, auth: true, sameOrigin: true,
+			response: ConfigResponse{},
+			handler:  s.handleGetConfig},
+		{method: "PUT", path: "/api/v1/config", operationID: "updateConfig", tag: "daemon",
+			summary: "Validate and save config.yaml; the daemon reloads it",
+			group:   config.RouteGroupAPI, auth: true, sameOrigin: true,
+			request: UpdateConfigRequest{}, response: ConfigResponse{},
+			handler: s.handlePutConfig},
+		{method: "GET", path: "/api/v1/openapi.json", operationID: "getOpenAPI", tag: "daemon",
+			summary: "This API description",
+			group:   config.RouteGroupAPI,
+			handler: s.handleOpenAPI},
+
+		{method: "GET", path: "/api/v1/events", operationID: "listEvents", tag: "events",
+			summary: "Events newest first, with cursor paging",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				queryParam("limit", "integer", "Events per page (default 50, max 500)"),
+				queryParam("source", "string", "Only events from this source"),
+				queryParam("repo", "string", "Only events whose repo contains this"),
+				queryParam("session_id", "string", "Only events in this session"),
+				queryParam("parent_id", "string", "Only children of this event"),
+				queryParam("severity", "string", "Only events with this severity"),
+				queryParam("cursor", "string", "next_cursor of the previous page"),
+				since,
+			},
+			response: GetEventsResponse{},
+			handler:  s.handleGetEvents},
+		{method: "GET", path: "/api/v1/events/stream", operationID: "streamEvents", tag: "events",
+			summary: "Server-sent events for each event as it is stored",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				queryParam("source", "string", "Comma-separated sources to include"),
+				queryParam("repo", "string", "Only events from this repo"),
+			},
+			stream:  true,
+			handler: s.handleEventStream},
+		{method: "GET", path: "/api/v1/events/{id}", operationID: "getEvent", tag: "events",
+			summary: "One event with its annotations",
+			group:   config.RouteGroupAPI, auth: true,
+			params:   []param{pathParam("id", "Event ID")},
+			response: EventDetailResponse{},
+			handler:  s.handleGetEvent},
+		{method: "GET", path: "/api/v1/events/{id}/related", operationID: "getRelatedEvents", tag: "events",
+			summary: "An event's parent, children and session, oldest first",
+			group:   config.RouteGroupAPI, auth: true,
+			params:   []param{pathParam("id", "Event ID")},
+			response: RelatedEventsResponse{},
+			handler:  s.handleRelatedEvents},
+		{method: "POST", path: "/api/v1/events/{id}/annotations", operationID: "addAnnotation", tag: "events",
+			summary: "Attach a note to an event",
+			group:   config.RouteGroupAPI, auth: true,
+			params:  []param{pathParam("id", "Event ID")},
+			request: AddAnnotationRequest{}, response: AnnotationResponse{}, status: http.StatusCreated,
+			handler: s.handleAddAnnotation},
+		{method: "GET", path: "/api/v1/search", operationID: "search", tag: "events",
+			summary: "Full-text search over events and summaries",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				queryParam("q", "string", "Search query"),
+				queryParam("limit", "integer", "Results per page (default 20, max 100)"),
+				queryParam("cursor", "string", "next_cursor of the previous page"),
+				queryParam("module", "array", "Only events from these sources"),
+				queryParam("type", "array", "Only events of these types"),
+				queryParam("repo", "string", "Only results whose repo contains this"),
+				queryParam("branch", "string", "Only events whose branch contains this"),
+				queryParam("issue", "string", "Only events linked to this issue key by the issues plugin"),
+				queryParam("scope", "string", "events, summaries or all"),
+				since,
+				queryParam("from", "string", "Start date, YYYY-MM-DD or RFC 3339"),
+				queryParam("to", "string", "End date, YYYY-MM-DD or RFC 3339"),
+				queryParam("sort", "string", "relevance or time_desc"),
+			},
+			response: SearchResponse{},
+			handler:  s.handleSearch},
+		{method: "GET", path: "/api/v1/metrics", operationID: "getMetrics", tag: "daemon",
+			summary: "Daemon metrics",
+			group:   config.RouteGroupAPI, auth: true,
+			params:  []param{queryParam("summary", "string", "true for the condensed summary")},
+			handler: s.handleMetrics},
+		{method: "GET", path: "/api/v1/summaries", operationID: "listSummaries", tag: "summaries",
+			summary: "Summaries of one day",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				queryParam("date", "string", "Day as YYYY-MM-DD, today by default"),
+				queryParam("repo", "string", "Only summaries covering this repo"),
+			},
+			response: SummariesResponse{},
+			handler:  s.handleSummaries},
+
+		{method: "GET", path: "/api/v1/analytics/events-by-source", operationID: "eventsBySource", tag: "analytics",
+			summary: "Event counts per source",
+			group:   config.RouteGroupAPI, auth: true,
+			response: EventsBySourceResponse{},
+			handler:  s.handleEventsBySource},
+		{method: "GET", path: "/api/v1/analytics/events-timeline", operationID: "eventsTimeline", tag: "analytics",
+			summary: "Event counts over time",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				queryParam("from", "string", "Start, YYYY-MM-DD or RFC 3339"),
+				queryParam("to", "string", "End, YYYY-MM-DD or RFC 3339"),
+				queryParam("bucket", "string", "minute, hour, day, week or auto"),
+			},
+			response: EventsTimelineResponse{},
+			handler:  s.handleEventsTimeline},
+		{method: "GET", path: "/api/v1/analytics/heatmap", operationID: "heatmap", tag: "analytics",
+			summary: "Events per day of a year",
+			group:   config.RouteGroupAPI, auth: true,
+			params:   []param{queryParam("year", "integer", "Year, the last 12 months by default")},
+			response: HeatmapResponse{},
+			handler:  s.handleHeatmap},
+		{method: "GET", path: "/api/v1/analytics/hourly-distribution", operationID: "hourlyDistribution", tag: "analytics",
+			summary: "Events by weekday and hour",
+			group:   config.RouteGroupAPI, auth: true,
+			params:   []param{since},
+			response: HourlyDistributionResponse{},
+			handler:  s.handleHourlyDistribution},
+		{method: "GET", path: "/api/v1/analytics/repo-stats", operationID: "repoStats", tag: "analytics",
+			summary: "Most active repos",
+			group:   config.RouteGroupAPI, auth: true,
+			response: RepoStatsResponse{},
+			handler:  s.handleRepoStats},
+		{method: "GET", path: "/api/v1/analytics/command-stats", operationID: "commandStats", tag: "analytics",
+			summary: "Most run shell commands",
+			group:   config.RouteGroupAPI, auth: true,
+			response: CommandStatsResponse{},
+			handler:  s.handleCommandStats},
+		{method: "GET", path: "/api/v1/analytics/top-files", operationID: "topFiles", tag: "analytics",
+			summary: "Most touched files",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				since,
+				queryParam("limit", "integer", "Files to return (default 15, max 100)"),
+			},
+			response: FileStatsResponse{},
+			handler:  s.handleTopFiles},
+		{method: "GET", path: "/api/v1/analytics/top-languages", operationID: "topLanguages", tag: "analytics",
+			summary: "Most touched languages",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				since,
+				queryParam("limit", "integer", "Languages to return (default 15, max 100)"),
+			},
+			response: LanguageStatsResponse{},
+			handler:  s.handleTopLanguages},
+		{method: "GET", path: "/api/v1/analytics/focus", operationID: "focus", tag: "analytics",
+			summary: "Context switches, focus streaks and deep-work windows",
+			group:   config.RouteGroupAPI, auth: true,
+			params: []param{
+				since,
+				queryParam("deep_work", "string", "Shortest streak that counts as deep work, a duration (default 45m)"),
+				queryParam("idle_gap", "string", "Longest pause that still counts as working, a duration (default 15m)"),
+			},
+			response: FocusResponse{},
+			handler:  s.handleFocus},
+	}
+}
+
+// OpenAPI document types, covering the parts of OpenAPI 3.0 the spec uses.
+type (
+	OpenAPIDocument struct {
+		OpenAPI    string                           `json:"openapi"`
+		Info       OpenAPIInfo                      `json:"info"`
+		Paths      map[string]map[string]*Operation `json:"paths"`
+		Components Components                       `json:"components"`
+	}
+
+	OpenAPIInfo struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Components struct {
+		Schemas         map[string]*Schema         `json:"schemas"`
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+	}
+
+	SecurityScheme struct {
+		Type   string `json:"type"`
+		Scheme string `json:"scheme"`
+	}
+
+	Operation struct {
+		OperationID string                `json:"operationId"`
+		Summary     string                `json:"summary"`
+		Tags        []string              `json:"tags"`
+		Parameters  []Parameter           `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]*Response  `json:"responses"`
+		Security    []map[string][]string `json:"security,omitempty"`
+	}
+
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Explode     *bool   `json:"explode,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	RequestBody struct {
+		Required bool                  `json:"required"`
+		Content  map[string]*MediaType `json:"content"`
+	}
+
+	Response struct {
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		MinItems             *int               `json:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty"`
+	}
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// RefName returns the component a $ref schema points to.
+func (s *Schema) RefName() string {
+	return strings.TrimPrefix(s.Ref, schemaRefPrefix)
+}
+
+// OpenAPISpec describes the /api/v1 endpoints.
+func OpenAPISpec() *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       "devlog",
+			Version:     "v1",
+			Description: "HTTP API of the devlog daemon. Endpoints marked with bearerAuth need a token from 'devlog token create' while http.auth_enabled is set.",
+		},
+		Paths: make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas:         make(map[string]*Schema),
+			SecuritySchemes: map[string]*SecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer"}},
+		},
+	}
+
+	schemas := schemaBuilder{components: doc.Components.Schemas}
+	errorSchema := schemas.of(reflect.TypeOf(ErrorResponse{}))
+
+	for _, rt := range (&Server{}).routes() {
+		op := &Operation{
+			OperationID: rt.operationID,
+			Summary:     rt.summary,
+			Tags:        []string{rt.tag},
+			Responses: map[string]*Response{
+				"default": {Description: "Error", Content: jsonContent(errorSchema)},
+			},
+		}
+		if rt.auth {
+			op.Security = []map[string][]string{{"bearerAuth": {}}}
+		}
+
+		for _, p := range rt.params {
+			op.Parameters = append(op.Parameters, p.openAPI())
+		}
+
+		if rt.request != nil {
+			contentType := rt.requestType
+			if contentType == "" {
+				contentType = "application/json"
+			}
+			schema := &Schema{}
+			if _, ok := rt.request.(anyJSON); !ok {
+				schema = schemas.of(reflect.TypeOf(rt.request))
+			}
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]*MediaType{contentType: {Schema: schema}},
+			}
+		}
+
+		status := rt.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := &Response{Description: http.StatusText(status)}
+		switch {
+		case rt.stream:
+			success.Content = map[string]*MediaType{"text/event-stream": {Schema: &Schema{Type: "string"}}}
+		case rt.response != nil:
+			success.Content = jsonContent(schemas.of(reflect.TypeOf(rt.response)))
+		default:
+			success.Content = jsonContent(&Schema{Type: "object", AdditionalProperties: &Schema{}})
+		}
+		op.Responses[strconv.Itoa(status)] = success
+
+		if doc.Paths[rt.path] == nil {
+			doc.Paths[rt.path] = make(map[string]*Operation)
+		}
+		doc.Paths[rt.path][strings.ToLower(rt.method)] = op
+	}
+
+	return doc
+}
+
+func (p param) openAPI() Parameter {
+	out := Parameter{
+		Name:        p.name,
+		In:          p.in,
+		Description: p.description,
+		Required:    p.in == "path",
+		Schema:      &Schema{Type: p.kind},
+	}
+	if p.kind == "array" {
+		explode := true
+		out.Explode = &explode
+		out.Schema.Items = &Schema{Type: "string"}
+	}
+	return out
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+// schemaBuilder derives schemas from Go types through their JSON tags.
+// Named structs become components referenced by $ref; fields tagged
+// omitempty are optional.
+type schemaBuilder struct {
+	components map[string]*Schema
+}
+
+func (b schemaBuilder) of(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.of(t.Elem())
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := b.components[name]; !ok {
+			b.components[name] = &Schema{}
+			*b.components[name] = *b.object(t)
+		}
+		return &Schema{Ref: schemaRefPrefix + name}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: b.of(t.Elem())}
+	case reflect.Array:
+		n := t.Len()
+		return &Schema{Type: "array", Items: b.of(t.Elem()), MinItems: &n, MaxItems: &n}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.of(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int, reflect.Int32, reflect.Int16, reflect.Int8:
+		return &Schema{Type: "integer"}
+	case reflect.Float64, reflect.Float32:
+		return &Schema{Type: "number"}
+	default:
+		return &Schema{}
+	}
+}
+
+func (b schemaBuilder) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = b.of(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, OpenAPISpec(), http.StatusOK)
+}
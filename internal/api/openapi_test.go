@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	doc := OpenAPISpec()
+	for _, rt := range server.routes() {
+		op := doc.Paths[rt.path][strings.ToLower(rt.method)]
+		if op == nil {
+			t.Errorf("%s %s missing from spec", rt.method, rt.path)
+			continue
+		}
+		if rt.auth != (len(op.Security) > 0) {
+			t.Errorf("%s: security = %v, auth = %v", op.OperationID, op.Security, rt.auth)
+		}
+	}
+
+	search := doc.Paths["/api/v1/search"]["get"]
+	var module *Parameter
+	for i := range search.Parameters {
+		if search.Parameters[i].Name == "module" {
+			module = &search.Parameters[i]
+		}
+	}
+	if module == nil || module.Schema.Type != "array" {
+		t.Errorf("search module parameter = %+v, want array", module)
+	}
+
+	if status := doc.Paths["/api/v1/events/{id}/annotations"]["post"].Responses["201"]; status == nil {
+		t.Error("addAnnotation should document its 201 response")
+	}
+}
+
+func TestOpenAPISchemaRefsResolve(t *testing.T) {
+	doc := OpenAPISpec()
+
+	var check func(where string, s *Schema)
+	check = func(where string, s *Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			if _, ok := doc.Components.Schemas[s.RefName()]; !ok {
+				t.Errorf("%s: dangling $ref %s", where, s.Ref)
+			}
+		}
+		check(where, s.Items)
+		check(where, s.AdditionalProperties)
+		for name, prop := range s.Properties {
+			check(where+"."+name, prop)
+		}
+	}
+	for name, s := range doc.Components.Schemas {
+		check(name, s)
+	}
+
+	event := doc.Components.Schemas["EventResponse"]
+	if event == nil {
+		t.Fatal("EventResponse schema missing")
+	}
+	if got := event.Properties["duration_ms"]; got.Type != "integer" || got.Format != "int64" {
+		t.Errorf("duration_ms = %+v, want int64 integer", got)
+	}
+	if !contains(event.Required, "id") || contains(event.Required, "repo") {
+		t.Errorf("required = %v, want id but not omitempty repo", event.Required)
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+	cfg := server.configGetter()
+	cfg.HTTP.AuthEnabled = true
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	server.SetupRoutes().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 without a token", w.Code)
+	}
+	var doc OpenAPIDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode spec: %v", err)
+	}
+	if doc.OpenAPI != "3.0.3" || doc.Paths["/api/v1/events"]["get"].OperationID != "listEvents" {
+		t.Errorf("unexpected spec: %s %+v", doc.OpenAPI, doc.Paths["/api/v1/events"])
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
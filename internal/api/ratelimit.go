@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/metrics"
+)
+
+// idleBucketTTL is how long a client's bucket is kept after its last
+// request. A full bucket carries no state, so dropping it changes nothing.
+const idleBucketTTL = 10 * time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter keeps one token bucket per remote address for a route group.
+// Rate and burst are passed on every call so config reloads apply at once.
+type rateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from key's bucket. When the bucket is empty it returns
+// false and how long until the next token is available.
+func (l *rateLimiter) allow(key string, rate float64, burst int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: float64(burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
+
+func (l *rateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func clientKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// limitRoute applies the rate and body size limits of a route group. The
+// config is read per request, like requireToken, so reloads take effect
+// without restarting the daemon.
+func (s *Server) limitRoute(group string, next http.HandlerFunc) http.HandlerFunc {
+	limiter := s.limiter(group)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit := s.routeLimit(group)
+
+		if ok, wait := limiter.allow(clientKey(r), limit.RequestsPerSecond, limit.Burst); !ok {
+			metrics.APIRateLimited.Add(group, 1)
+			s.logger.Debug("request rate limited",
+				slog.String("group", group),
+				slog.String("path", r.URL.Path),
+				slog.String("remote_addr", r.RemoteAddr))
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			respondError(w, "rate limit exceeded; slow down and retry", http.StatusTooManyRequests)
+			return
+		}
+
+		if r.ContentLength > limit.MaxBodyBytes {
+			respondBodyTooLarge(w, limit.MaxBodyBytes)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit.MaxBodyBytes)
+		next(w, r)
+	}
+}
+
+// limiter returns the rate limiter of a route group, shared by every route
+// in it.
+func (s *Server) limiter(group string) *rateLimiter {
+	s.limitersMu.Lock()
+	defer s.limitersMu.Unlock()
+	limiter, ok := s.limiters[group]
+	if !ok {
+		limiter = newRateLimiter()
+		s.limiters[group] = limiter
+	}
+	return limiter
+}
+
+func (s *Server) routeLimit(group string) config.RouteLimit {
+	if cfg := s.configGetter(); cfg != nil {
+		return cfg.HTTP.RouteLimit(group)
+	}
+	return config.RouteGroups[group]
+}
+
+// bodyLimit reports whether err came from reading past the body limit, and
+// what that limit was.
+func bodyLimit(err error) (int64, bool) {
+	var maxErr *http.MaxBytesError
+	if !errors.As(err, &maxErr) {
+		return 0, false
+	}
+	return maxErr.Limit, true
+}
+
+func respondBodyTooLarge(w http.ResponseWriter, limit int64) {
+	respondError(w, "request body exceeds "+strconv.FormatInt(limit, 10)+" bytes", http.StatusRequestEntityTooLarge)
+}
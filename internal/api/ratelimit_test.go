@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter()
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.allow("10.0.0.1", 1, 3); !ok {
+			t.Fatalf("request %d rejected within burst", i+1)
+		}
+	}
+	ok, wait := l.allow("10.0.0.1", 1, 3)
+	if ok {
+		t.Fatal("request beyond burst allowed")
+	}
+	if wait <= 0 || wait > time.Second {
+		t.Errorf("wait = %v, want (0, 1s]", wait)
+	}
+
+	if ok, _ := l.allow("10.0.0.2", 1, 3); !ok {
+		t.Error("other client shares the bucket")
+	}
+
+	now = now.Add(time.Second)
+	if ok, _ := l.allow("10.0.0.1", 1, 3); !ok {
+		t.Error("bucket did not refill")
+	}
+
+	now = now.Add(idleBucketTTL + time.Minute)
+	l.allow("10.0.0.3", 1, 3)
+	if _, ok := l.buckets["10.0.0.1"]; ok {
+		t.Error("idle bucket not swept")
+	}
+}
+
+func TestLimitRouteRateLimit(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+	cfg := server.configGetter()
+	cfg.HTTP.Limits = map[string]config.RouteLimit{
+		config.RouteGroupAPI: {RequestsPerSecond: 0.01, Burst: 2},
+	}
+	mux := server.SetupRoutes()
+
+	get := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/health", nil)
+		req.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := get("192.0.2.1:5000"); w.Code == http.StatusTooManyRequests {
+			t.Fatalf("request %d rate limited", i+1)
+		}
+	}
+	w := get("192.0.2.1:5001")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("missing Retry-After header")
+	}
+	if w := get("192.0.2.2:5000"); w.Code == http.StatusTooManyRequests {
+		t.Error("a different client was rate limited")
+	}
+}
+
+func TestLimitRouteBodySize(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+	cfg := server.configGetter()
+	cfg.HTTP.Limits = map[string]config.RouteLimit{
+		config.RouteGroupIngest: {MaxBodyBytes: 64},
+	}
+	mux := server.SetupRoutes()
+
+	body := `{"source":"git","type":"commit","payload":{"message":"` + strings.Repeat("x", 100) + `"}}`
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/ingest", bytes.NewReader([]byte(body)))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("declared length: expected 413, got %d", w.Code)
+	}
+
+	// Chunked bodies carry no Content-Length and are cut off while reading.
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/ingest", bytes.NewReader([]byte(body)))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("streamed body: expected 413, got %d", w.Code)
+	}
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/metrics"
+	"devlog/internal/share"
+)
+
+type sharedSection struct {
+	Period string
+	Repos  []string
+	Text   string
+}
+
+type sharedPage struct {
+	Date     string
+	Expires  string
+	Sections []sharedSection
+}
+
+func (s *Server) handleShare(w http.ResponseWriter, r *http.Request) {
+	timer := metrics.StartAPITimer("/share")
+	defer timer.Stop()
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex, nofollow")
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		http.Error(w, "Share links are unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	key, err := share.LoadOrCreateKey(dataDir)
+	if err != nil {
+		s.logger.Error("failed to load share key", slog.String("error", err.Error()))
+		http.Error(w, "Share links are unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	grant, err := share.Verify(key, r.PathValue("token"), time.Now())
+	if errors.Is(err, share.ErrExpired) {
+		http.Error(w, "This share link has expired", http.StatusGone)
+		return
+	}
+	if err != nil || grant.Kind != share.KindSummary {
+		http.NotFound(w, r)
+		return
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", grant.Date, time.Local)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	summaries, err := s.eventService.GetSummaries(r.Context(), day, day.AddDate(0, 0, 1))
+	if err != nil {
+		s.logger.Error("failed to load shared summaries", slog.String("error", err.Error()))
+		http.Error(w, "Failed to load summary", http.StatusInternalServerError)
+		return
+	}
+
+	page := sharedPage{
+		Date:    day.Format("Monday, January 2, 2006"),
+		Expires: grant.Expiry().Format("January 2, 2006 15:04 MST"),
+	}
+	for _, summary := range summaries {
+		repos := make([]string, len(summary.Repos))
+		for i, repo := range summary.Repos {
+			repos[i] = filepath.Base(repo)
+		}
+		page.Sections = append(page.Sections, sharedSection{
+			Period: summary.PeriodStart.Format("15:04") + " - " + summary.PeriodEnd.Format("15:04"),
+			Repos:  repos,
+			Text:   summary.Text,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := sharedSummaryTemplate.Execute(&buf, page); err != nil {
+		s.logger.Error("failed to render shared summary", slog.String("error", err.Error()))
+		http.Error(w, "Failed to render summary", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+var sharedSummaryTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <meta name="robots" content="noindex, nofollow">
+    <title>Work Log - {{.Date}}</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, Cantarell, sans-serif;
+            max-width: 760px;
+            margin: 40px auto;
+            padding: 0 20px;
+            color: #222;
+            line-height: 1.6;
+        }
+
+        h1 {
+            font-size: 1.6em;
+            margin-bottom: 4px;
+        }
+
+        .meta {
+            color: #777;
+            font-size: 0.85em;
+        }
+
+        section {
+            border-top: 1px solid #eee;
+            padding: 16px 0;
+        }
+
+        h2 {
+            font-size: 1.05em;
+            margin: 0 0 6px;
+        }
+
+        .repos {
+            color: #555;
+            font-size: 0.85em;
+        }
+
+        .text {
+            white-space: pre-wrap;
+        }
+    </style>
+</head>
+<body>
+    <h1>Work Log</h1>
+    <div class="meta">{{.Date}} · link expires {{.Expires}}</div>
+    {{range .Sections}}
+    <section>
+        <h2>{{.Period}}</h2>
+        {{if .Repos}}<div class="repos">{{range $i, $r := .Repos}}{{if $i}}, {{end}}{{$r}}{{end}}</div>{{end}}
+        <div class="text">{{.Text}}</div>
+    </section>
+    {{else}}
+    <section>
+        <p>No summaries were recorded for this day.</p>
+    </section>
+    {{end}}
+</body>
+</html>
+`))
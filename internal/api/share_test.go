@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/share"
+	"devlog/internal/storage"
+)
+
+func TestShareHandler(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	day := time.Date(2025, 6, 12, 0, 0, 0, 0, time.Local)
+	if err := store.InsertSummaryContext(context.Background(), &storage.Summary{
+		PeriodStart: day.Add(10 * time.Hour),
+		PeriodEnd:   day.Add(10*time.Hour + 30*time.Minute),
+		Repos:       []string{"/home/me/src/devlog"},
+		Text:        "Shipped <b>share</b> links",
+	}); err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Payload["command"] = "export SECRET_TOKEN=hunter2"
+	if err := store.InsertEvent(event); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := share.LoadOrCreateKey(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sign := func(expiresAt time.Time) string {
+		token, err := share.Sign(key, share.Grant{Kind: share.KindSummary, Date: "2025-06-12", ExpiresAt: expiresAt.Unix()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	mux := server.SetupRoutes()
+	get := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/share/"+token, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(sign(time.Now().Add(time.Hour)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Shipped &lt;b&gt;share&lt;/b&gt; links") {
+		t.Errorf("summary text missing or unescaped:\n%s", body)
+	}
+	if !strings.Contains(body, "10:00 - 10:30") || !strings.Contains(body, "devlog") {
+		t.Errorf("period or repo missing:\n%s", body)
+	}
+	if strings.Contains(body, "hunter2") || strings.Contains(body, "/home/me") {
+		t.Errorf("shared page leaked raw event data or paths:\n%s", body)
+	}
+	if w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("got Cache-Control %q, want no-store", w.Header().Get("Cache-Control"))
+	}
+
+	if w := get(sign(time.Now().Add(-time.Minute))); w.Code != http.StatusGone {
+		t.Errorf("expired link: got status %d, want %d", w.Code, http.StatusGone)
+	}
+
+	if w := get("not-a-token"); w.Code != http.StatusNotFound {
+		t.Errorf("invalid link: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	valid := sign(time.Now().Add(time.Hour))
+	if _, err := share.RotateKey(dataDir); err != nil {
+		t.Fatal(err)
+	}
+	if w := get(valid); w.Code != http.StatusNotFound {
+		t.Errorf("link after key rotation: got status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
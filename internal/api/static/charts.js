@@ -0,0 +1,528 @@
+// Minimal canvas charts for the dashboard. Implements the part of the
+// Chart.js constructor API the dashboard uses (doughnut, line and bar charts,
+// horizontal bars via indexAxis: 'y') so no third-party script is needed.
+// Vertical charts also accept options.brush = { onSelect(from, to), onReset() }
+// to let users drag across the x axis to pick a range of indices.
+(function (global) {
+    'use strict';
+
+    const FONT = '12px -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif';
+    const PADDING = 8;
+
+    function get(obj, path, fallback) {
+        let cur = obj;
+        for (const key of path.split('.')) {
+            if (cur == null || !(key in cur)) {
+                return fallback;
+            }
+            cur = cur[key];
+        }
+        return cur === undefined ? fallback : cur;
+    }
+
+    function niceMax(value) {
+        if (value <= 0) {
+            return 1;
+        }
+        const exp = Math.pow(10, Math.floor(Math.log10(value)));
+        for (const step of [1, 2, 2.5, 5, 10]) {
+            if (step * exp >= value) {
+                return step * exp;
+            }
+        }
+        return 10 * exp;
+    }
+
+    function formatNumber(n) {
+        return Number.isInteger(n) ? n.toLocaleString() : n.toFixed(1);
+    }
+
+    function truncateText(ctx, text, width) {
+        if (ctx.measureText(text).width <= width) {
+            return text;
+        }
+        let t = text;
+        while (t.length > 1 && ctx.measureText(t + '…').width > width) {
+            t = t.slice(0, -1);
+        }
+        return t + '…';
+    }
+
+    class Chart {
+        constructor(target, config) {
+            this.canvas = target.canvas || target;
+            this.ctx = this.canvas.getContext('2d');
+            this.config = config;
+            this.type = config.type;
+            this.data = config.data || { labels: [], datasets: [] };
+            this.options = config.options || {};
+            this.hover = null;
+            this.elements = [];
+            this.positions = [];
+            this.selection = null;
+
+            this._onResize = () => this.render();
+            this._onMove = (e) => this._handleMove(e);
+            this._onLeave = () => {
+                this.hover = null;
+                this.render();
+            };
+            this._onDown = (e) => this._handleDown(e);
+            this._onUp = () => this._handleUp();
+            this._onDblClick = () => {
+                const brush = this.options.brush;
+                if (brush && brush.onReset) {
+                    brush.onReset();
+                }
+            };
+
+            if (this.options.responsive !== false) {
+                global.addEventListener('resize', this._onResize);
+            }
+            this.canvas.addEventListener('mousemove', this._onMove);
+            this.canvas.addEventListener('mouseleave', this._onLeave);
+            if (this.options.brush) {
+                this.canvas.addEventListener('mousedown', this._onDown);
+                this.canvas.addEventListener('dblclick', this._onDblClick);
+                global.addEventListener('mouseup', this._onUp);
+            }
+
+            this.render();
+        }
+
+        destroy() {
+            global.removeEventListener('resize', this._onResize);
+            this.canvas.removeEventListener('mousemove', this._onMove);
+            this.canvas.removeEventListener('mouseleave', this._onLeave);
+            this.canvas.removeEventListener('mousedown', this._onDown);
+            this.canvas.removeEventListener('dblclick', this._onDblClick);
+            global.removeEventListener('mouseup', this._onUp);
+            this.ctx.setTransform(1, 0, 0, 1, 0, 0);
+            this.ctx.clearRect(0, 0, this.canvas.width, this.canvas.height);
+        }
+
+        _resize() {
+            const parent = this.canvas.parentElement;
+            const width = parent ? parent.clientWidth : this.canvas.clientWidth;
+            const height = parent && this.options.maintainAspectRatio === false
+                ? parent.clientHeight
+                : Math.round(width / 2);
+            const ratio = global.devicePixelRatio || 1;
+
+            this.canvas.style.width = width + 'px';
+            this.canvas.style.height = height + 'px';
+            this.canvas.width = Math.round(width * ratio);
+            this.canvas.height = Math.round(height * ratio);
+            this.ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+            this.width = width;
+            this.height = height;
+        }
+
+        render() {
+            this._resize();
+            const ctx = this.ctx;
+            ctx.clearRect(0, 0, this.width, this.height);
+            ctx.font = FONT;
+            this.elements = [];
+            this.positions = [];
+
+            if (this.type === 'doughnut') {
+                this._drawDoughnut();
+            } else if (this.type === 'line' || this.type === 'bar') {
+                this._drawCartesian();
+            }
+
+            if (this.selection) {
+                this._drawSelection(this.selection);
+            } else if (this.hover) {
+                this._drawTooltip(this.hover);
+            }
+        }
+
+        _dataset() {
+            return this.data.datasets[0] || { data: [] };
+        }
+
+        _colorAt(color, i) {
+            return Array.isArray(color) ? color[i % color.length] : color;
+        }
+
+        _drawDoughnut() {
+            const ctx = this.ctx;
+            const ds = this._dataset();
+            const values = ds.data.map(Number);
+            const total = values.reduce((a, b) => a + b, 0);
+            const labels = this.data.labels || [];
+
+            let legendHeight = 0;
+            if (get(this.options, 'plugins.legend.display', true) !== false && labels.length > 0) {
+                legendHeight = this._drawLegend(labels, ds.backgroundColor);
+            }
+
+            const cx = this.width / 2;
+            const cy = (this.height - legendHeight) / 2;
+            const outer = Math.max(0, Math.min(this.width, this.height - legendHeight) / 2 - PADDING);
+            const inner = outer * 0.5;
+            if (total <= 0 || outer <= 0) {
+                return;
+            }
+
+            let angle = -Math.PI / 2;
+            values.forEach((v, i) => {
+                const sweep = (v / total) * Math.PI * 2;
+                ctx.beginPath();
+                ctx.arc(cx, cy, outer, angle, angle + sweep);
+                ctx.arc(cx, cy, inner, angle + sweep, angle, true);
+                ctx.closePath();
+                ctx.fillStyle = this._colorAt(ds.backgroundColor, i);
+                ctx.fill();
+                this.elements.push({ kind: 'arc', cx, cy, inner, outer, start: angle, end: angle + sweep, label: labels[i], value: v });
+                angle += sweep;
+            });
+        }
+
+        _drawLegend(labels, colors) {
+            const ctx = this.ctx;
+            const box = 10;
+            const gap = 16;
+            const rowHeight = 18;
+            ctx.font = FONT;
+            ctx.textBaseline = 'middle';
+
+            const rows = [[]];
+            let rowWidth = 0;
+            labels.forEach((label, i) => {
+                const w = box + 6 + ctx.measureText(label).width;
+                if (rowWidth > 0 && rowWidth + gap + w > this.width - PADDING * 2) {
+                    rows.push([]);
+                    rowWidth = 0;
+                }
+                rows[rows.length - 1].push({ label, i, w });
+                rowWidth += (rowWidth > 0 ? gap : 0) + w;
+            });
+
+            const height = rows.length * rowHeight + PADDING;
+            let y = this.height - height + rowHeight / 2;
+            ctx.fillStyle = get(this.options, 'plugins.legend.labels.color', '#666');
+            for (const row of rows) {
+                const total = row.reduce((a, item) => a + item.w, 0) + gap * (row.length - 1);
+                let x = (this.width - total) / 2;
+                for (const item of row) {
+                    ctx.fillStyle = this._colorAt(colors, item.i);
+                    ctx.fillRect(x, y - box / 2, box, box);
+                    ctx.fillStyle = get(this.options, 'plugins.legend.labels.color', '#666');
+                    ctx.textAlign = 'left';
+                    ctx.fillText(item.label, x + box + 6, y);
+                    x += item.w + gap;
+                }
+                y += rowHeight;
+            }
+            return height;
+        }
+
+        _drawCartesian() {
+            const ctx = this.ctx;
+            const ds = this._dataset();
+            const values = ds.data.map(Number);
+            const labels = (this.data.labels || []).map(String);
+            const horizontal = this.type === 'bar' && this.options.indexAxis === 'y';
+            const indexScale = horizontal ? 'y' : 'x';
+            const valueScale = horizontal ? 'x' : 'y';
+            const max = niceMax(Math.max(0, ...values));
+            const ticks = 5;
+
+            ctx.font = FONT;
+            const valueLabels = [];
+            for (let i = 0; i <= ticks; i++) {
+                valueLabels.push(formatNumber((max / ticks) * i));
+            }
+
+            const rotation = horizontal ? 0 : (get(this.options, 'scales.x.ticks.maxRotation', 0) * Math.PI) / 180;
+            const labelWidth = Math.max(0, ...labels.map((l) => ctx.measureText(l).width));
+
+            let left, bottom;
+            if (horizontal) {
+                left = Math.min(labelWidth, this.width * 0.4) + PADDING * 2;
+                bottom = 20;
+            } else {
+                left = Math.max(...valueLabels.map((l) => ctx.measureText(l).width)) + PADDING * 2;
+                bottom = rotation ? Math.min(labelWidth * Math.sin(rotation) + 16, this.height * 0.45) : 20;
+            }
+            const area = {
+                left,
+                top: PADDING,
+                right: this.width - PADDING,
+                bottom: this.height - bottom,
+            };
+            const areaWidth = area.right - area.left;
+            const areaHeight = area.bottom - area.top;
+            if (areaWidth <= 0 || areaHeight <= 0) {
+                return;
+            }
+
+            const valueGrid = get(this.options, 'scales.' + valueScale + '.grid', {});
+            const indexGrid = get(this.options, 'scales.' + indexScale + '.grid', {});
+            const valueTickColor = get(this.options, 'scales.' + valueScale + '.ticks.color', '#666');
+            const indexTickColor = get(this.options, 'scales.' + indexScale + '.ticks.color', '#666');
+
+            for (let i = 0; i <= ticks; i++) {
+                const frac = i / ticks;
+                ctx.strokeStyle = valueGrid.color || 'rgba(0,0,0,0.1)';
+                ctx.fillStyle = valueTickColor;
+                ctx.lineWidth = 1;
+                ctx.beginPath();
+                if (horizontal) {
+                    const x = area.left + frac * areaWidth;
+                    if (valueGrid.display !== false) {
+                        ctx.moveTo(x, area.top);
+                        ctx.lineTo(x, area.bottom);
+                    }
+                    ctx.textAlign = 'center';
+                    ctx.textBaseline = 'top';
+                    ctx.fillText(valueLabels[i], x, area.bottom + 4);
+                } else {
+                    const y = area.bottom - frac * areaHeight;
+                    if (valueGrid.display !== false) {
+                        ctx.moveTo(area.left, y);
+                        ctx.lineTo(area.right, y);
+                    }
+                    ctx.textAlign = 'right';
+                    ctx.textBaseline = 'middle';
+                    ctx.fillText(valueLabels[i], area.left - PADDING, y);
+                }
+                ctx.stroke();
+            }
+
+            const n = values.length;
+            if (n === 0) {
+                return;
+            }
+            const band = (horizontal ? areaHeight : areaWidth) / n;
+            const positionAt = (i) => (this.type === 'line' && n > 1
+                ? (horizontal ? area.top : area.left) + (i / (n - 1)) * (horizontal ? areaHeight : areaWidth)
+                : (horizontal ? area.top : area.left) + band * (i + 0.5));
+            if (!horizontal) {
+                this.area = area;
+                this.positions = values.map((_, i) => positionAt(i));
+            }
+
+            ctx.fillStyle = indexTickColor;
+            const step = Math.max(1, Math.ceil(n / Math.max(1, Math.floor((horizontal ? areaHeight : areaWidth) / 18))));
+            labels.forEach((label, i) => {
+                if (i % step !== 0) {
+                    return;
+                }
+                const pos = positionAt(i);
+                if (indexGrid.display !== false && indexGrid.color) {
+                    ctx.strokeStyle = indexGrid.color;
+                    ctx.beginPath();
+                    if (horizontal) {
+                        ctx.moveTo(area.left, pos);
+                        ctx.lineTo(area.right, pos);
+                    } else {
+                        ctx.moveTo(pos, area.top);
+                        ctx.lineTo(pos, area.bottom);
+                    }
+                    ctx.stroke();
+                }
+                ctx.fillStyle = indexTickColor;
+                if (horizontal) {
+                    ctx.textAlign = 'right';
+                    ctx.textBaseline = 'middle';
+                    ctx.fillText(truncateText(ctx, label, area.left - PADDING * 2), area.left - PADDING, pos);
+                } else if (rotation) {
+                    ctx.save();
+                    ctx.translate(pos, area.bottom + 6);
+                    ctx.rotate(-rotation);
+                    ctx.textAlign = 'right';
+                    ctx.textBaseline = 'middle';
+                    ctx.fillText(label, 0, 0);
+                    ctx.restore();
+                } else {
+                    ctx.textAlign = 'center';
+                    ctx.textBaseline = 'top';
+                    ctx.fillText(label, pos, area.bottom + 4);
+                }
+            });
+
+            if (this.type === 'bar') {
+                this._drawBars(values, labels, area, band, positionAt, max, horizontal, ds);
+            } else {
+                this._drawLine(values, labels, area, positionAt, max, ds);
+            }
+        }
+
+        _drawBars(values, labels, area, band, positionAt, max, horizontal, ds) {
+            const ctx = this.ctx;
+            const thickness = Math.max(1, band * 0.7);
+            values.forEach((v, i) => {
+                const pos = positionAt(i);
+                const length = (v / max) * (horizontal ? area.right - area.left : area.bottom - area.top);
+                const rect = horizontal
+                    ? { x: area.left, y: pos - thickness / 2, w: length, h: thickness }
+                    : { x: pos - thickness / 2, y: area.bottom - length, w: thickness, h: length };
+                ctx.fillStyle = this._colorAt(ds.backgroundColor || '#2563eb', i);
+                ctx.fillRect(rect.x, rect.y, rect.w, rect.h);
+                this.elements.push({ kind: 'rect', ...rect, label: labels[i], value: v });
+            });
+        }
+
+        _drawLine(values, labels, area, positionAt, max, ds) {
+            const ctx = this.ctx;
+            const points = values.map((v, i) => ({
+                x: positionAt(i),
+                y: area.bottom - (v / max) * (area.bottom - area.top),
+                label: labels[i],
+                value: v,
+            }));
+            const smooth = (ds.tension || 0) > 0;
+
+            const trace = () => {
+                ctx.moveTo(points[0].x, points[0].y);
+                for (let i = 1; i < points.length; i++) {
+                    const prev = points[i - 1];
+                    const cur = points[i];
+                    if (smooth) {
+                        const mid = (prev.x + cur.x) / 2;
+                        ctx.bezierCurveTo(mid, prev.y, mid, cur.y, cur.x, cur.y);
+                    } else {
+                        ctx.lineTo(cur.x, cur.y);
+                    }
+                }
+            };
+
+            if (ds.fill) {
+                ctx.beginPath();
+                trace();
+                ctx.lineTo(points[points.length - 1].x, area.bottom);
+                ctx.lineTo(points[0].x, area.bottom);
+                ctx.closePath();
+                ctx.fillStyle = ds.backgroundColor || 'rgba(37, 99, 235, 0.1)';
+                ctx.fill();
+            }
+
+            ctx.beginPath();
+            trace();
+            ctx.strokeStyle = ds.borderColor || '#2563eb';
+            ctx.lineWidth = 2;
+            ctx.stroke();
+
+            for (const p of points) {
+                this.elements.push({ kind: 'point', ...p });
+            }
+        }
+
+        _hit(x, y) {
+            let best = null;
+            let bestDist = Infinity;
+            for (const el of this.elements) {
+                if (el.kind === 'rect') {
+                    if (x >= el.x && x <= el.x + Math.max(el.w, 4) && y >= el.y && y <= el.y + Math.max(el.h, 4)) {
+                        return el;
+                    }
+                } else if (el.kind === 'arc') {
+                    const dx = x - el.cx;
+                    const dy = y - el.cy;
+                    const r = Math.sqrt(dx * dx + dy * dy);
+                    let a = Math.atan2(dy, dx);
+                    if (a < -Math.PI / 2) {
+                        a += Math.PI * 2;
+                    }
+                    if (r >= el.inner && r <= el.outer && a >= el.start && a < el.end) {
+                        return el;
+                    }
+                } else if (el.kind === 'point') {
+                    const d = Math.abs(x - el.x);
+                    if (d < bestDist) {
+                        bestDist = d;
+                        best = el;
+                    }
+                }
+            }
+            return bestDist < 20 ? best : null;
+        }
+
+        _indexAt(x) {
+            let best = 0;
+            this.positions.forEach((pos, i) => {
+                if (Math.abs(pos - x) < Math.abs(this.positions[best] - x)) {
+                    best = i;
+                }
+            });
+            return best;
+        }
+
+        _handleDown(e) {
+            if (this.positions.length < 2 || e.button !== 0) {
+                return;
+            }
+            const x = e.clientX - this.canvas.getBoundingClientRect().left;
+            if (x < this.area.left || x > this.area.right) {
+                return;
+            }
+            e.preventDefault();
+            this.selection = { x1: x, x2: x };
+            this.render();
+        }
+
+        _handleUp() {
+            const sel = this.selection;
+            if (!sel) {
+                return;
+            }
+            this.selection = null;
+            this.render();
+            if (Math.abs(sel.x2 - sel.x1) < 5) {
+                return;
+            }
+            const from = this._indexAt(Math.min(sel.x1, sel.x2));
+            const to = this._indexAt(Math.max(sel.x1, sel.x2));
+            if (to > from) {
+                this.options.brush.onSelect(from, to);
+            }
+        }
+
+        _drawSelection(sel) {
+            const ctx = this.ctx;
+            const left = Math.min(sel.x1, sel.x2);
+            const width = Math.abs(sel.x2 - sel.x1);
+            ctx.fillStyle = get(this.options, 'brush.color', 'rgba(37, 99, 235, 0.2)');
+            ctx.fillRect(left, this.area.top, width, this.area.bottom - this.area.top);
+        }
+
+        _handleMove(e) {
+            const rect = this.canvas.getBoundingClientRect();
+            const x = e.clientX - rect.left;
+            const y = e.clientY - rect.top;
+            if (this.selection) {
+                this.selection.x2 = Math.min(Math.max(x, this.area.left), this.area.right);
+                this.render();
+                return;
+            }
+            const el = this._hit(x, y);
+            const next = el ? { el, x, y } : null;
+            if ((next && next.el) !== (this.hover && this.hover.el)) {
+                this.hover = next;
+                this.render();
+            }
+        }
+
+        _drawTooltip(hover) {
+            const ctx = this.ctx;
+            const text = (hover.el.label !== undefined ? hover.el.label + ': ' : '') + formatNumber(hover.el.value);
+            ctx.font = FONT;
+            const w = ctx.measureText(text).width + 12;
+            const h = 22;
+            const x = Math.min(Math.max(hover.x + 10, 0), this.width - w);
+            const y = Math.min(Math.max(hover.y - h - 6, 0), this.height - h);
+            ctx.fillStyle = 'rgba(0, 0, 0, 0.8)';
+            ctx.fillRect(x, y, w, h);
+            ctx.fillStyle = '#fff';
+            ctx.textAlign = 'left';
+            ctx.textBaseline = 'middle';
+            ctx.fillText(text, x + 6, y + h / 2);
+        }
+    }
+
+    global.Chart = Chart;
+})(window);
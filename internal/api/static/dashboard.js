@@ -0,0 +1,661 @@
+let charts = {};
+
+// With http.auth_enabled the dashboard needs an API token. Open it once as
+// /#token=<token>; the token is kept in localStorage and removed from the URL.
+const apiToken = (function () {
+    const match = location.hash.match(/token=([^&]+)/);
+    if (match) {
+        localStorage.setItem('devlogToken', decodeURIComponent(match[1]));
+        history.replaceState(null, '', location.pathname + location.search);
+    }
+    return localStorage.getItem('devlogToken');
+})();
+
+function apiFetch(url) {
+    const headers = apiToken ? { 'Authorization': 'Bearer ' + apiToken } : {};
+    return fetch(url, { headers: headers });
+}
+
+function showError(message) {
+    const container = document.getElementById('error-container');
+    container.innerHTML = '<div class="error">' + message + '</div>';
+}
+
+function clearError() {
+    document.getElementById('error-container').innerHTML = '';
+}
+
+async function fetchJSON(url) {
+    const response = await apiFetch(url);
+    if (!response.ok) {
+        throw new Error('Failed to fetch ' + url);
+    }
+    return response.json();
+}
+
+async function loadStatus() {
+    try {
+        const data = await fetchJSON('/api/v1/status');
+        document.getElementById('total-events').textContent = data.event_count.toLocaleString();
+
+        const hours = Math.floor(data.uptime_seconds / 3600);
+        const minutes = Math.floor((data.uptime_seconds % 3600) / 60);
+        document.getElementById('uptime').textContent = hours + 'h ' + minutes + 'm';
+    } catch (error) {
+        console.error('Failed to load status:', error);
+    }
+}
+
+let eventsCursor = '';
+let eventsPaged = false;
+
+function escapeHTML(value) {
+    return String(value)
+        .replace(/&/g, '&amp;')
+        .replace(/</g, '&lt;')
+        .replace(/>/g, '&gt;')
+        .replace(/"/g, '&quot;');
+}
+
+function renderNote(event) {
+    const payload = event.payload || {};
+    const tags = (payload.tags || []).map(tag => '<span class="event-tag">' + escapeHTML(tag) + '</span>').join('');
+    const repo = event.repo ? ' • ' + escapeHTML(event.repo.split('/').pop()) : '';
+    return '<div class="event-details event-note">' + escapeHTML(payload.text || '') + repo + tags + '</div>';
+}
+
+function isDangerous(payload) {
+    return !!payload && (payload.danger_level === 'high' || payload.danger_level === 'critical');
+}
+
+function renderRisk(payload) {
+    if (!payload || !payload.environment) {
+        return '';
+    }
+    const label = payload.environment + (isDangerous(payload) ? ' • ' + payload.danger_level : '');
+    return '<span class="event-env env-' + escapeHTML(payload.environment) + '">' + escapeHTML(label) + '</span>';
+}
+
+function renderEvent(event) {
+    const time = new Date(event.timestamp).toLocaleString();
+    const sourceClass = 'source-' + event.source;
+
+    if (event.source === 'manual' && event.type === 'note') {
+        return '<div class="event-item">' +
+            '<div>' +
+            '<span class="event-source ' + sourceClass + '">' + event.source + '</span>' +
+            '<span class="event-type">' + event.type + '</span>' +
+            '</div>' +
+            renderNote(event) +
+            '<div class="event-time">' + time + '</div>' +
+            '</div>';
+    }
+
+    let details = '';
+    if (event.payload) {
+        if (event.payload.message) {
+            details = event.payload.message;
+        } else if (event.payload.command) {
+            details = event.payload.command;
+        } else if (event.payload.content) {
+            const content = event.payload.content;
+            details = content.length > 50 ? content.substring(0, 50) + '...' : content;
+        }
+    }
+
+    if (event.repo) {
+        details = (details ? details + ' • ' : '') + event.repo.split('/').pop();
+    }
+
+    return '<div class="event-item' + (isDangerous(event.payload) ? ' event-danger' : '') + '">' +
+        '<div>' +
+        '<span class="event-source ' + sourceClass + '">' + event.source + '</span>' +
+        '<span class="event-type">' + event.type + '</span>' +
+        renderRisk(event.payload) +
+        '</div>' +
+        (details ? '<div class="event-details">' + escapeHTML(details) + '</div>' : '') +
+        '<div class="event-time">' + time + '</div>' +
+        '</div>';
+}
+
+function updateEventsCursor(data) {
+    eventsCursor = data.next_cursor || '';
+    document.getElementById('events-more').style.display = eventsCursor ? 'inline-block' : 'none';
+}
+
+async function loadEvents() {
+    if (eventsPaged) {
+        return;
+    }
+    try {
+        const data = await fetchJSON('/api/v1/events');
+        const listEl = document.getElementById('events-list');
+        updateEventsCursor(data);
+
+        if (data.events.length === 0) {
+            listEl.innerHTML = '<div class="event-item">No events found</div>';
+            return;
+        }
+
+        listEl.innerHTML = data.events.map(renderEvent).join('');
+    } catch (error) {
+        console.error('Failed to load events:', error);
+        showError('Failed to load events: ' + error.message);
+    }
+}
+
+async function loadMoreEvents() {
+    if (!eventsCursor) {
+        return;
+    }
+    try {
+        const data = await fetchJSON('/api/v1/events?cursor=' + encodeURIComponent(eventsCursor));
+        eventsPaged = true;
+        document.getElementById('events-list').insertAdjacentHTML('beforeend', data.events.map(renderEvent).join(''));
+        updateEventsCursor(data);
+    } catch (error) {
+        console.error('Failed to load more events:', error);
+        showError('Failed to load more events: ' + error.message);
+    }
+}
+
+async function loadEventsBySource() {
+    try {
+        const data = await fetchJSON('/api/v1/analytics/events-by-source');
+
+        if (charts.sourceChart) {
+            charts.sourceChart.destroy();
+        }
+
+        const ctx = document.getElementById('source-chart').getContext('2d');
+        charts.sourceChart = new Chart(ctx, {
+            type: 'doughnut',
+            data: {
+                labels: data.data.map(d => d.source),
+                datasets: [{
+                    data: data.data.map(d => d.count),
+                    backgroundColor: [
+                        '#10b981',
+                        '#f59e0b',
+                        '#8b5cf6',
+                        '#ec4899',
+                        '#06b6d4',
+                        '#6366f1'
+                    ]
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                plugins: {
+                    legend: {
+                        position: 'bottom',
+                        labels: { color: '#e0e0e0' }
+                    }
+                }
+            }
+        });
+    } catch (error) {
+        console.error('Failed to load source data:', error);
+    }
+}
+
+let timelineRange = null;
+
+function timelineLabel(start, bucket) {
+    const date = new Date(start);
+    switch (bucket) {
+        case 'minute':
+            return date.toLocaleTimeString('en-US', { hour: 'numeric', minute: '2-digit' });
+        case 'hour':
+            return date.toLocaleDateString('en-US', { month: 'short', day: 'numeric', hour: 'numeric' });
+        case 'week':
+            return 'Week of ' + date.toLocaleDateString('en-US', { month: 'short', day: 'numeric' });
+        default:
+            return date.toLocaleDateString('en-US', { month: 'short', day: 'numeric' });
+    }
+}
+
+function zoomTimeline(range) {
+    timelineRange = range;
+    loadTimeline();
+}
+
+function resetTimelineZoom() {
+    zoomTimeline(null);
+}
+
+async function loadTimeline() {
+    try {
+        let url = '/api/v1/analytics/events-timeline';
+        if (timelineRange) {
+            url += '?from=' + encodeURIComponent(timelineRange.from) + '&to=' + encodeURIComponent(timelineRange.to);
+        }
+        const data = await fetchJSON(url);
+
+        if (charts.timelineChart) {
+            charts.timelineChart.destroy();
+        }
+
+        const points = data.data;
+        const from = new Date(data.from).toLocaleString();
+        const to = new Date(data.to).toLocaleString();
+        document.getElementById('timeline-range').textContent = timelineRange
+            ? from + ' – ' + to + ' · per ' + data.bucket + ' · drag to zoom, double-click to reset'
+            : 'Last 7 days · per ' + data.bucket + ' · drag to zoom';
+        document.getElementById('timeline-reset').hidden = !timelineRange;
+
+        const ctx = document.getElementById('timeline-chart').getContext('2d');
+        charts.timelineChart = new Chart(ctx, {
+            type: 'line',
+            data: {
+                labels: points.map(d => timelineLabel(d.start, data.bucket)),
+                datasets: [{
+                    label: 'Events',
+                    data: points.map(d => d.count),
+                    borderColor: '#2563eb',
+                    backgroundColor: 'rgba(37, 99, 235, 0.1)',
+                    fill: true,
+                    tension: 0.4
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                brush: {
+                    onSelect: (start, end) => {
+                        const last = end + 1 < points.length ? points[end + 1].start : data.to;
+                        zoomTimeline({ from: points[start].start, to: last });
+                    },
+                    onReset: () => {
+                        if (timelineRange) {
+                            resetTimelineZoom();
+                        }
+                    }
+                },
+                plugins: {
+                    legend: {
+                        display: false
+                    }
+                },
+                scales: {
+                    x: {
+                        ticks: {
+                            color: '#888',
+                            maxRotation: 45,
+                            minRotation: 45
+                        },
+                        grid: { color: '#2a2a2a' }
+                    },
+                    y: {
+                        ticks: { color: '#888' },
+                        grid: { color: '#2a2a2a' }
+                    }
+                }
+            }
+        });
+    } catch (error) {
+        console.error('Failed to load timeline:', error);
+    }
+}
+
+async function loadRepoStats() {
+    try {
+        const data = await fetchJSON('/api/v1/analytics/repo-stats');
+
+        if (charts.repoChart) {
+            charts.repoChart.destroy();
+        }
+
+        if (data.data.length === 0) {
+            return;
+        }
+
+        const ctx = document.getElementById('repo-chart').getContext('2d');
+        charts.repoChart = new Chart(ctx, {
+            type: 'bar',
+            data: {
+                labels: data.data.map(d => d.repo.split('/').pop()),
+                datasets: [{
+                    label: 'Events',
+                    data: data.data.map(d => d.count),
+                    backgroundColor: '#10b981'
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                indexAxis: 'y',
+                plugins: {
+                    legend: { display: false }
+                },
+                scales: {
+                    x: {
+                        ticks: { color: '#888' },
+                        grid: { color: '#2a2a2a' }
+                    },
+                    y: {
+                        ticks: { color: '#888' },
+                        grid: { display: false }
+                    }
+                }
+            }
+        });
+    } catch (error) {
+        console.error('Failed to load repo stats:', error);
+    }
+}
+
+async function loadCommandStats() {
+    try {
+        const data = await fetchJSON('/api/v1/analytics/command-stats');
+
+        if (charts.commandChart) {
+            charts.commandChart.destroy();
+        }
+
+        if (data.data.length === 0) {
+            return;
+        }
+
+        const ctx = document.getElementById('command-chart').getContext('2d');
+        charts.commandChart = new Chart(ctx, {
+            type: 'bar',
+            data: {
+                labels: data.data.map(d => {
+                    const cmd = d.command;
+                    return cmd.length > 30 ? cmd.substring(0, 30) + '...' : cmd;
+                }),
+                datasets: [{
+                    label: 'Count',
+                    data: data.data.map(d => d.count),
+                    backgroundColor: '#f59e0b'
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                indexAxis: 'y',
+                plugins: {
+                    legend: { display: false }
+                },
+                scales: {
+                    x: {
+                        ticks: { color: '#888' },
+                        grid: { color: '#2a2a2a' }
+                    },
+                    y: {
+                        ticks: { color: '#888' },
+                        grid: { display: false }
+                    }
+                }
+            }
+        });
+    } catch (error) {
+        console.error('Failed to load command stats:', error);
+    }
+}
+
+async function loadTopFiles() {
+    try {
+        const data = await fetchJSON('/api/v1/analytics/top-files?since=7d');
+
+        if (charts.filesChart) {
+            charts.filesChart.destroy();
+        }
+
+        if (data.data.length === 0) {
+            return;
+        }
+
+        const ctx = document.getElementById('files-chart').getContext('2d');
+        charts.filesChart = new Chart(ctx, {
+            type: 'bar',
+            data: {
+                labels: data.data.map(d => {
+                    const label = d.repo ? d.repo + ': ' + d.path : d.path;
+                    return label.length > 40 ? '...' + label.slice(-37) : label;
+                }),
+                datasets: [{
+                    label: 'Touches',
+                    data: data.data.map(d => d.count),
+                    backgroundColor: '#8b5cf6'
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                indexAxis: 'y',
+                plugins: {
+                    legend: { display: false }
+                },
+                scales: {
+                    x: {
+                        ticks: { color: '#888' },
+                        grid: { color: '#2a2a2a' }
+                    },
+                    y: {
+                        ticks: { color: '#888' },
+                        grid: { display: false }
+                    }
+                }
+            }
+        });
+    } catch (error) {
+        console.error('Failed to load top files:', error);
+    }
+}
+
+async function loadTopLanguages() {
+    try {
+        const data = await fetchJSON('/api/v1/analytics/top-languages?since=7d');
+
+        if (charts.languagesChart) {
+            charts.languagesChart.destroy();
+        }
+
+        if (data.data.length === 0) {
+            return;
+        }
+
+        const ctx = document.getElementById('languages-chart').getContext('2d');
+        charts.languagesChart = new Chart(ctx, {
+            type: 'doughnut',
+            data: {
+                labels: data.data.map(d => `${d.language} (${d.files} files)`),
+                datasets: [{
+                    data: data.data.map(d => d.count),
+                    backgroundColor: [
+                        '#10b981',
+                        '#f59e0b',
+                        '#8b5cf6',
+                        '#ec4899',
+                        '#06b6d4',
+                        '#6366f1'
+                    ]
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                plugins: {
+                    legend: {
+                        position: 'bottom',
+                        labels: { color: '#e0e0e0' }
+                    }
+                }
+            }
+        });
+    } catch (error) {
+        console.error('Failed to load languages:', error);
+    }
+}
+
+function heatmapLevel(count, max) {
+    if (count === 0 || max === 0) {
+        return 0;
+    }
+    return Math.min(4, Math.ceil((count / max) * 4));
+}
+
+function populateHeatmapYears() {
+    const select = document.getElementById('heatmap-year');
+    if (select.options.length > 1) {
+        return;
+    }
+    const year = new Date().getFullYear();
+    for (let y = year; y > year - 5; y--) {
+        const option = document.createElement('option');
+        option.value = y;
+        option.textContent = y;
+        select.appendChild(option);
+    }
+}
+
+async function loadHeatmap() {
+    try {
+        populateHeatmapYears();
+        const year = document.getElementById('heatmap-year').value;
+        const data = await fetchJSON('/api/v1/analytics/heatmap' + (year ? '?year=' + year : ''));
+
+        const container = document.getElementById('heatmap');
+        container.innerHTML = '';
+        if (data.data.length === 0) {
+            return;
+        }
+
+        // Rows run Monday to Sunday, so pad the first column up to the
+        // weekday of the first day.
+        const first = new Date(data.data[0].date + 'T00:00:00');
+        for (let i = 0; i < (first.getDay() + 6) % 7; i++) {
+            const pad = document.createElement('div');
+            pad.className = 'heatmap-cell heatmap-pad';
+            container.appendChild(pad);
+        }
+
+        for (const day of data.data) {
+            const cell = document.createElement('div');
+            cell.className = 'heatmap-cell';
+            cell.dataset.level = heatmapLevel(day.count, data.max);
+            cell.title = `${day.count} event${day.count === 1 ? '' : 's'} on ${day.date}`;
+            container.appendChild(cell);
+        }
+
+        document.getElementById('heatmap-summary').textContent =
+            `${data.total.toLocaleString()} events from ${data.from} to ${data.to}`;
+    } catch (error) {
+        console.error('Failed to load heatmap:', error);
+    }
+}
+
+function distributionChart(id, labels, values, color, label = 'Events') {
+    const ctx = document.getElementById(id).getContext('2d');
+    return new Chart(ctx, {
+        type: 'bar',
+        data: {
+            labels: labels,
+            datasets: [{
+                label: label,
+                data: values,
+                backgroundColor: color
+            }]
+        },
+        options: {
+            responsive: true,
+            maintainAspectRatio: false,
+            plugins: {
+                legend: { display: false }
+            },
+            scales: {
+                x: {
+                    ticks: { color: '#888' },
+                    grid: { display: false }
+                },
+                y: {
+                    ticks: { color: '#888' },
+                    grid: { color: '#2a2a2a' }
+                }
+            }
+        }
+    });
+}
+
+async function loadHourlyDistribution() {
+    try {
+        const data = await fetchJSON('/api/v1/analytics/hourly-distribution?since=90d');
+
+        if (charts.hourlyChart) {
+            charts.hourlyChart.destroy();
+        }
+        if (charts.weekdayChart) {
+            charts.weekdayChart.destroy();
+        }
+
+        const hours = data.by_hour.map((_, h) => String(h).padStart(2, '0'));
+        charts.hourlyChart = distributionChart('hourly-chart', hours, data.by_hour, '#06b6d4');
+        charts.weekdayChart = distributionChart('weekday-chart', data.weekdays, data.by_weekday, '#f59e0b');
+    } catch (error) {
+        console.error('Failed to load hourly distribution:', error);
+    }
+}
+
+function formatMinutes(minutes) {
+    if (minutes < 60) {
+        return minutes + 'm';
+    }
+    const m = minutes % 60;
+    return Math.floor(minutes / 60) + 'h' + (m ? ' ' + m + 'm' : '');
+}
+
+async function loadFocus() {
+    try {
+        const data = await fetchJSON('/api/v1/analytics/focus?since=7d');
+
+        document.getElementById('focus-rate').textContent = data.switches_per_hour.toFixed(1);
+        document.getElementById('focus-longest').textContent =
+            data.longest_streak ? formatMinutes(data.longest_streak.duration_minutes) : '-';
+        document.getElementById('focus-longest-repo').textContent =
+            data.longest_streak ? data.longest_streak.repo : '';
+        document.getElementById('focus-deep').textContent = formatMinutes(data.deep_work_minutes);
+
+        const windows = document.getElementById('focus-windows');
+        windows.innerHTML = data.deep_work.slice(-5).reverse().map(w => {
+            const start = new Date(w.start);
+            const day = start.toLocaleDateString([], { weekday: 'short', month: 'short', day: 'numeric' });
+            const time = start.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
+            return `<li>${escapeHTML(day)} ${escapeHTML(time)} &middot; ${formatMinutes(w.duration_minutes)} in ${escapeHTML(w.repo)}</li>`;
+        }).join('');
+
+        if (charts.switchesChart) {
+            charts.switchesChart.destroy();
+        }
+        const hours = data.switches_by_hour.map((_, h) => String(h).padStart(2, '0'));
+        charts.switchesChart = distributionChart('switches-chart', hours, data.switches_by_hour, '#ec4899', 'Switches');
+    } catch (error) {
+        console.error('Failed to load focus metrics:', error);
+    }
+}
+
+async function loadAllData() {
+    clearError();
+    try {
+        await Promise.all([
+            loadStatus(),
+            loadEvents(),
+            loadEventsBySource(),
+            loadTimeline(),
+            loadRepoStats(),
+            loadCommandStats(),
+            loadTopFiles(),
+            loadTopLanguages(),
+            loadHeatmap(),
+            loadHourlyDistribution(),
+            loadFocus()
+        ]);
+    } catch (error) {
+        showError('Failed to load dashboard data: ' + error.message);
+    }
+}
+
+loadAllData();
+setInterval(loadAllData, 30000);
@@ -0,0 +1,152 @@
+let journalDate = '';
+let journalRepo = '';
+
+function localDate(date) {
+    const pad = n => String(n).padStart(2, '0');
+    return date.getFullYear() + '-' + pad(date.getMonth() + 1) + '-' + pad(date.getDate());
+}
+
+function parseLocalDate(value) {
+    const [year, month, day] = value.split('-').map(Number);
+    return new Date(year, month - 1, day);
+}
+
+function journalHash(date, repo) {
+    const params = new URLSearchParams();
+    params.set('date', date);
+    if (repo) {
+        params.set('repo', repo);
+    }
+    return '#/journal?' + params.toString();
+}
+
+function shiftJournalDay(days) {
+    const date = parseLocalDate(journalDate);
+    date.setDate(date.getDate() + days);
+    const next = localDate(date);
+    if (next > localDate(new Date())) {
+        return;
+    }
+    window.location.hash = journalHash(next, journalRepo);
+}
+
+function journalToday() {
+    window.location.hash = journalHash(localDate(new Date()), journalRepo);
+}
+
+function filterJournalRepo(repo) {
+    window.location.hash = journalHash(journalDate, repo);
+}
+
+async function loadJournal(params) {
+    const today = localDate(new Date());
+    journalDate = /^\d{4}-\d{2}-\d{2}$/.test(params.get('date') || '') ? params.get('date') : today;
+    journalRepo = params.get('repo') || '';
+
+    document.getElementById('journal-date').textContent = parseLocalDate(journalDate)
+        .toLocaleDateString(undefined, { weekday: 'long', year: 'numeric', month: 'long', day: 'numeric' });
+    document.getElementById('journal-next').disabled = journalDate >= today;
+    document.getElementById('journal-today').disabled = journalDate === today;
+
+    const status = document.getElementById('journal-status');
+    const list = document.getElementById('journal-list');
+    status.textContent = 'Loading…';
+    list.innerHTML = '';
+
+    const api = new URLSearchParams({ date: journalDate });
+    if (journalRepo) {
+        api.set('repo', journalRepo);
+    }
+    try {
+        const data = await fetchJSON('/api/v1/summaries?' + api.toString());
+        renderJournalRepos(data.repos || []);
+        list.innerHTML = data.summaries.map(renderJournalEntry).join('');
+
+        const events = data.summaries.reduce((sum, s) => sum + s.event_count, 0);
+        status.textContent = data.count === 0
+            ? 'No summaries for this day' + (journalRepo ? ' in ' + journalRepo.split('/').pop() : '')
+            : data.count + ' summar' + (data.count === 1 ? 'y' : 'ies') + ' • ' + events.toLocaleString() + ' events';
+    } catch (error) {
+        status.textContent = 'Failed to load summaries: ' + error.message;
+    }
+}
+
+function renderJournalRepos(repos) {
+    const select = document.getElementById('journal-repo');
+    if (journalRepo && !repos.includes(journalRepo)) {
+        repos = repos.concat(journalRepo);
+    }
+    select.innerHTML = '<option value="">All repos</option>' + repos
+        .map(repo => '<option value="' + escapeHTML(repo) + '">' + escapeHTML(repo.split('/').pop()) + '</option>')
+        .join('');
+    select.value = journalRepo;
+}
+
+// renderSummaryText turns the "Working on:" line and bullets of a summary
+// into HTML. Anything else, such as a custom prompt's output, is kept as
+// paragraphs.
+function renderSummaryText(text) {
+    let html = '';
+    let inList = false;
+    text.split('\n').forEach(line => {
+        line = line.trim();
+        const bullet = line.match(/^[-*•]\s+(.*)$/);
+        if (bullet) {
+            html += (inList ? '' : '<ul>') + '<li>' + escapeHTML(bullet[1]) + '</li>';
+            inList = true;
+            return;
+        }
+        if (inList) {
+            html += '</ul>';
+            inList = false;
+        }
+        if (line) {
+            html += '<p>' + escapeHTML(line) + '</p>';
+        }
+    });
+    return html + (inList ? '</ul>' : '');
+}
+
+function renderJournalEntry(summary) {
+    const time = date => new Date(date).toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' });
+    const repos = (summary.repos || [])
+        .map(repo => '<span class="event-tag">' + escapeHTML(repo.split('/').pop()) + '</span>')
+        .join('');
+
+    const debug = [
+        ['ID', summary.id],
+        ['Period', new Date(summary.period_start).toLocaleString() + ' – ' + new Date(summary.period_end).toLocaleString()],
+        ['Context from', new Date(summary.context_start).toLocaleString()],
+        ['Events', summary.event_count],
+        ['Context events', summary.context_event_count],
+        ['Provider', summary.provider || '-'],
+        ['Tokens', summary.input_tokens.toLocaleString() + ' in / ' + summary.output_tokens.toLocaleString() + ' out'],
+        ['Created', new Date(summary.created_at).toLocaleString()],
+    ];
+
+    return '<article class="journal-entry">' +
+        '<div class="journal-entry-header">' +
+        '<span class="journal-time">' + time(summary.period_start) + ' – ' + time(summary.period_end) + '</span>' +
+        repos +
+        '</div>' +
+        '<div class="journal-text">' + renderSummaryText(summary.summary) + '</div>' +
+        '<details class="journal-debug">' +
+        '<summary>Debug</summary>' +
+        '<dl class="drawer-meta">' + debug
+            .map(([k, v]) => '<dt>' + escapeHTML(k) + '</dt><dd>' + escapeHTML(v) + '</dd>')
+            .join('') + '</dl>' +
+        '<pre class="drawer-json">' + escapeHTML(JSON.stringify(summary, null, 2)) + '</pre>' +
+        '</details>' +
+        '</article>';
+}
+
+document.addEventListener('keydown', event => {
+    if (document.getElementById('journal-view').hidden || event.target.closest('input, select, textarea')) {
+        return;
+    }
+    if (event.key === 'ArrowLeft') {
+        shiftJournalDay(-1);
+    } else if (event.key === 'ArrowRight') {
+        shiftJournalDay(1);
+    }
+});
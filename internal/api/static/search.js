@@ -0,0 +1,291 @@
+let searchCursor = '';
+let searchParams = null;
+let searchTerms = [];
+let searchResults = [];
+let modulesLoaded = false;
+
+const SEARCH_FIELDS = ['q', 'module', 'type', 'repo', 'from', 'to', 'scope', 'sort'];
+
+function showView(name) {
+    document.getElementById('dashboard-view').hidden = name !== 'dashboard';
+    document.getElementById('search-view').hidden = name !== 'search';
+    document.getElementById('journal-view').hidden = name !== 'journal';
+    document.getElementById('settings-view').hidden = name !== 'settings';
+    document.querySelectorAll('.nav a').forEach(link => {
+        link.classList.toggle('active', link.dataset.view === name);
+    });
+}
+
+function route() {
+    const hash = window.location.hash || '#/';
+    const query = hash.indexOf('?') >= 0 ? hash.slice(hash.indexOf('?') + 1) : '';
+    const params = new URLSearchParams(query);
+    if (hash.startsWith('#/journal')) {
+        closeDrawer();
+        showView('journal');
+        loadJournal(params);
+        return;
+    }
+    if (hash.startsWith('#/settings')) {
+        closeDrawer();
+        showView('settings');
+        loadSettings();
+        return;
+    }
+    if (!hash.startsWith('#/search')) {
+        closeDrawer();
+        showView('dashboard');
+        return;
+    }
+
+    showView('search');
+    loadModuleOptions();
+
+    const form = document.getElementById('search-form');
+    SEARCH_FIELDS.forEach(field => {
+        if (form.elements[field]) {
+            form.elements[field].value = params.get(field) || (field === 'scope' ? 'events' : field === 'sort' ? 'relevance' : '');
+        }
+    });
+
+    if (query) {
+        runSearch(params);
+    } else {
+        document.getElementById('search-results').innerHTML = '';
+        document.getElementById('search-status').textContent = '';
+        document.getElementById('search-more').style.display = 'none';
+    }
+}
+
+async function loadModuleOptions() {
+    if (modulesLoaded) {
+        return;
+    }
+    try {
+        const data = await fetchJSON('/api/v1/analytics/events-by-source');
+        const select = document.getElementById('search-form').elements.module;
+        const current = select.value;
+        data.data.forEach(d => {
+            const option = document.createElement('option');
+            option.value = d.source;
+            option.textContent = d.source + ' (' + d.count.toLocaleString() + ')';
+            select.appendChild(option);
+        });
+        select.value = current;
+        modulesLoaded = true;
+    } catch (error) {
+        console.error('Failed to load modules:', error);
+    }
+}
+
+function formParams() {
+    const form = document.getElementById('search-form');
+    const params = new URLSearchParams();
+    SEARCH_FIELDS.forEach(field => {
+        const value = (form.elements[field].value || '').trim();
+        if (value) {
+            params.set(field, value);
+        }
+    });
+    return params;
+}
+
+function apiParams(params) {
+    const api = new URLSearchParams();
+    params.forEach((value, key) => {
+        if (key === 'type') {
+            value.split(',').map(t => t.trim()).filter(Boolean).forEach(t => api.append('type', t));
+        } else {
+            api.set(key, value);
+        }
+    });
+    api.set('limit', '25');
+    return api;
+}
+
+function queryTerms(q) {
+    return (q || '')
+        .split(/\s+/)
+        .map(t => t.replace(/^["(]+|[")*]+$/g, ''))
+        .filter(t => t && !['AND', 'OR', 'NOT', 'NEAR'].includes(t))
+        .map(t => t.toLowerCase());
+}
+
+function highlight(text) {
+    const escaped = escapeHTML(text);
+    if (searchTerms.length === 0) {
+        return escaped;
+    }
+    const pattern = searchTerms
+        .map(t => escapeHTML(t).replace(/[.*+?^${}()|[\]\\]/g, '\\$&'))
+        .join('|');
+    return escaped.replace(new RegExp('(' + pattern + ')', 'gi'), '<mark>$1</mark>');
+}
+
+async function fetchSearch(params, cursor) {
+    const api = apiParams(params);
+    if (cursor) {
+        api.set('cursor', cursor);
+    }
+    const response = await apiFetch('/api/v1/search?' + api.toString());
+    const data = await response.json().catch(() => ({}));
+    if (!response.ok) {
+        throw new Error(data.error || 'search failed');
+    }
+    return data;
+}
+
+async function runSearch(params) {
+    searchParams = params;
+    searchTerms = queryTerms(params.get('q'));
+    searchResults = [];
+    const status = document.getElementById('search-status');
+    const list = document.getElementById('search-results');
+    status.textContent = 'Searching…';
+    list.innerHTML = '';
+
+    try {
+        const data = await fetchSearch(params, '');
+        appendResults(data);
+    } catch (error) {
+        status.textContent = 'Search failed: ' + error.message;
+        document.getElementById('search-more').style.display = 'none';
+    }
+}
+
+async function loadMoreResults() {
+    if (!searchCursor || !searchParams) {
+        return;
+    }
+    try {
+        const data = await fetchSearch(searchParams, searchCursor);
+        appendResults(data);
+    } catch (error) {
+        document.getElementById('search-status').textContent = 'Search failed: ' + error.message;
+    }
+}
+
+function appendResults(data) {
+    const start = searchResults.length;
+    searchResults = searchResults.concat(data.results);
+    searchCursor = data.next_cursor || '';
+
+    document.getElementById('search-results').insertAdjacentHTML('beforeend',
+        data.results.map((result, i) => renderResult(result, start + i)).join(''));
+    document.getElementById('search-more').style.display = searchCursor ? 'inline-block' : 'none';
+
+    const count = searchResults.length;
+    document.getElementById('search-status').textContent = count === 0
+        ? 'No results'
+        : count + (searchCursor ? '+' : '') + ' result' + (count === 1 ? '' : 's');
+}
+
+function resultText(result) {
+    if (result.kind === 'summary') {
+        return result.summary ? result.summary.summary : '';
+    }
+    const payload = result.payload || {};
+    for (const key of ['text', 'message', 'command', 'summary', 'title', 'content']) {
+        if (typeof payload[key] === 'string' && payload[key]) {
+            return payload[key];
+        }
+    }
+    return JSON.stringify(payload);
+}
+
+function renderResult(result, index) {
+    const time = new Date(result.timestamp).toLocaleString();
+    let text = resultText(result);
+    if (text.length > 300) {
+        text = text.substring(0, 300) + '…';
+    }
+
+    let badges;
+    if (result.kind === 'summary') {
+        badges = '<span class="event-source source-summary">summary</span>';
+    } else {
+        badges = '<span class="event-source source-' + escapeHTML(result.source) + '">' + escapeHTML(result.source) + '</span>' +
+            '<span class="event-type">' + escapeHTML(result.type) + '</span>' +
+            renderRisk(result.payload);
+    }
+
+    const where = [];
+    if (result.repo) {
+        where.push(result.repo.split('/').pop());
+    }
+    if (result.branch) {
+        where.push(result.branch);
+    }
+
+    const danger = result.kind !== 'summary' && isDangerous(result.payload) ? ' event-danger' : '';
+    return '<div class="event-item search-result' + danger + '" onclick="openDrawer(' + index + ')">' +
+        '<div>' + badges + '</div>' +
+        '<div class="event-details">' + highlight(text) + '</div>' +
+        '<div class="event-time">' + time + (where.length ? ' • ' + escapeHTML(where.join(' @ ')) : '') +
+        (result.annotations ? ' <span class="annotation-count">✎ ' + result.annotations.length + '</span>' : '') + '</div>' +
+        '</div>';
+}
+
+function openDrawer(index) {
+    const result = searchResults[index];
+    if (!result) {
+        return;
+    }
+
+    const meta = [['Time', new Date(result.timestamp).toLocaleString()]];
+    let body;
+    if (result.kind === 'summary') {
+        const s = result.summary || {};
+        document.getElementById('drawer-title').textContent = 'Summary #' + result.id;
+        meta.push(['Period', new Date(s.period_start).toLocaleString() + ' – ' + new Date(s.period_end).toLocaleString()]);
+        meta.push(['Repos', (s.repos || []).join(', ') || '-']);
+        meta.push(['Events', String(s.event_count)]);
+        body = s;
+    } else {
+        document.getElementById('drawer-title').textContent = result.source + ' / ' + result.type;
+        meta.push(['ID', result.id]);
+        if (result.repo) {
+            meta.push(['Repo', result.repo]);
+        }
+        if (result.branch) {
+            meta.push(['Branch', result.branch]);
+        }
+        body = result.payload || {};
+    }
+
+    document.getElementById('drawer-meta').innerHTML = meta
+        .map(([k, v]) => '<dt>' + escapeHTML(k) + '</dt><dd>' + escapeHTML(v) + '</dd>')
+        .join('');
+    const annotations = document.getElementById('drawer-annotations');
+    annotations.innerHTML = (result.annotations || [])
+        .map(a => '<li>' + escapeHTML(a.text) +
+            ' <span class="annotation-time">' + escapeHTML(new Date(a.created_at).toLocaleString()) + '</span></li>')
+        .join('');
+    annotations.hidden = !result.annotations;
+    document.getElementById('drawer-json').innerHTML = highlight(JSON.stringify(body, null, 2));
+    document.getElementById('event-drawer').hidden = false;
+}
+
+function closeDrawer() {
+    document.getElementById('event-drawer').hidden = true;
+}
+
+document.getElementById('search-form').addEventListener('submit', event => {
+    event.preventDefault();
+    const params = formParams();
+    const hash = '#/search' + (params.toString() ? '?' + params.toString() : '');
+    if (window.location.hash === hash) {
+        route();
+    } else {
+        window.location.hash = hash;
+    }
+});
+
+document.addEventListener('keydown', event => {
+    if (event.key === 'Escape') {
+        closeDrawer();
+    }
+});
+
+window.addEventListener('hashchange', route);
+route();
@@ -0,0 +1,145 @@
+// The settings page edits config.yaml through GET/PUT /api/v1/config. The
+// form works on settingsData.config, the same document the API returns, so
+// anything the form has no field for is saved back unchanged.
+let settingsData = null;
+
+function apiSend(url, method, body) {
+    const headers = { 'Content-Type': 'application/json' };
+    if (apiToken) {
+        headers['Authorization'] = 'Bearer ' + apiToken;
+    }
+    return fetch(url, { method: method, headers: headers, body: JSON.stringify(body) });
+}
+
+async function loadSettings() {
+    const status = document.getElementById('settings-status');
+    status.textContent = 'Loading…';
+    status.className = 'search-status';
+    try {
+        settingsData = await fetchJSON('/api/v1/config');
+        renderSettings();
+        status.textContent = 'Editing ' + settingsData.path;
+    } catch (error) {
+        status.textContent = 'Failed to load config: ' + error.message;
+    }
+}
+
+function settingsSection(name) {
+    const config = settingsData.config;
+    if (!config[name] || typeof config[name] !== 'object') {
+        config[name] = {};
+    }
+    return config[name];
+}
+
+function renderSettings() {
+    renderComponents('modules', settingsData.modules, 'settings-modules');
+    renderComponents('plugins', settingsData.plugins, 'settings-plugins');
+
+    const shell = settingsSection('modules').shell || {};
+    document.getElementById('settings-ignore').value = (shell.ignore_list || []).join('\n');
+
+    const summarizer = settingsSection('plugins').summarizer || {};
+    const form = document.getElementById('settings-form');
+    form.elements.interval.value = summarizer.interval_seconds ? summarizer.interval_seconds / 60 : '';
+    form.elements.context_window.value = summarizer.context_window_seconds ? summarizer.context_window_seconds / 60 : '';
+    form.elements.interval.disabled = form.elements.context_window.disabled = !summarizer.interval_seconds;
+
+    document.getElementById('settings-json').value = JSON.stringify(settingsData.config, null, 2);
+}
+
+function renderComponents(kind, available, elementId) {
+    const configured = settingsSection(kind);
+    const names = new Set(available.map(c => c.name));
+    const components = available.concat(Object.keys(configured)
+        .filter(name => !names.has(name))
+        .map(name => ({ name: name, description: 'Not installed in this build' })));
+
+    document.getElementById(elementId).innerHTML = components.map(c => {
+        const enabled = configured[c.name] && configured[c.name].enabled;
+        return '<label class="settings-toggle">' +
+            '<input type="checkbox" data-kind="' + kind + '" data-name="' + escapeHTML(c.name) + '"' + (enabled ? ' checked' : '') + '>' +
+            '<span class="settings-name">' + escapeHTML(c.name) + '</span>' +
+            '<span class="settings-description">' + escapeHTML(c.description) + '</span>' +
+            '</label>';
+    }).join('');
+}
+
+// applySettingsForm copies the form fields into the config document.
+function applySettingsForm() {
+    const config = settingsData.config;
+    document.querySelectorAll('#settings-view input[type="checkbox"][data-kind]').forEach(box => {
+        const section = settingsSection(box.dataset.kind);
+        const current = section[box.dataset.name];
+        if (!current && !box.checked) {
+            return;
+        }
+        if (!current) {
+            // Turning on a component that was never configured starts from
+            // the defaults its install command would write.
+            const list = box.dataset.kind === 'modules' ? settingsData.modules : settingsData.plugins;
+            const info = list.find(c => c.name === box.dataset.name) || {};
+            section[box.dataset.name] = Object.assign({}, info.default_config || {}, { enabled: true });
+            return;
+        }
+        current.enabled = box.checked;
+    });
+
+    const ignore = document.getElementById('settings-ignore').value
+        .split('\n').map(line => line.trim()).filter(Boolean);
+    const modules = settingsSection('modules');
+    if (ignore.length > 0 || (modules.shell && modules.shell.ignore_list)) {
+        modules.shell = modules.shell || { enabled: false };
+        modules.shell.ignore_list = ignore;
+    }
+
+    const summarizer = settingsSection('plugins').summarizer;
+    const form = document.getElementById('settings-form');
+    if (summarizer && form.elements.interval.value) {
+        summarizer.interval_seconds = Math.round(Number(form.elements.interval.value) * 60);
+        summarizer.context_window_seconds = Math.round(Number(form.elements.context_window.value) * 60);
+    }
+    return config;
+}
+
+function useSettingsJSON() {
+    const status = document.getElementById('settings-status');
+    try {
+        settingsData.config = JSON.parse(document.getElementById('settings-json').value);
+    } catch (error) {
+        status.textContent = 'Invalid JSON: ' + error.message;
+        status.className = 'search-status settings-error';
+        return;
+    }
+    renderSettings();
+    status.textContent = 'JSON applied to the form; save to write config.yaml';
+    status.className = 'search-status';
+}
+
+async function saveSettings(event) {
+    event.preventDefault();
+    if (!settingsData) {
+        return;
+    }
+    const status = document.getElementById('settings-status');
+    status.textContent = 'Saving…';
+    status.className = 'search-status';
+
+    const response = await apiSend('/api/v1/config', 'PUT', {
+        version: settingsData.version,
+        config: applySettingsForm(),
+    });
+    const data = await response.json().catch(() => ({}));
+    if (!response.ok) {
+        status.textContent = (response.status === 409 ? 'Not saved: ' : 'Invalid config: ') + (data.error || response.statusText);
+        status.className = 'search-status settings-error';
+        return;
+    }
+
+    settingsData = data;
+    renderSettings();
+    status.textContent = 'Saved. The daemon reloads the new config in a moment.' +
+        (data.restart_required ? ' Restart it to apply ' + data.restart_required.join(', ') + '.' : '');
+}
+
+document.getElementById('settings-form').addEventListener('submit', saveSettings);
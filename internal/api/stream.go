@@ -0,0 +1,143 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+)
+
+const (
+	StreamPollInterval = time.Second
+	StreamKeepalive    = 15 * time.Second
+	streamBatchSize    = 100
+)
+
+// handleEventStream sends events as they are stored, as server-sent events.
+// It follows the events table by rowid, so nothing is missed when ingest
+// bursts, and a client reconnecting with Last-Event-ID resumes where it
+// stopped.
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respondError(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	filter := s.streamFilter(r)
+
+	ctx := r.Context()
+	var cursor int64
+	if last := r.Header.Get("Last-Event-ID"); last != "" {
+		row, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || row < 0 {
+			respondError(w, "invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		cursor = row
+	} else {
+		row, err := s.storage.MaxEventRowContext(ctx)
+		if err != nil {
+			respondError(w, fmt.Sprintf("Failed to open stream: %v", err), http.StatusInternalServerError)
+			return
+		}
+		cursor = row
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	poll := time.NewTicker(StreamPollInterval)
+	defer poll.Stop()
+	lastWrite := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.streamsDone:
+			return
+		case <-poll.C:
+		}
+
+		for {
+			evts, lastRow, err := s.storage.EventsAfterRowContext(ctx, cursor, streamBatchSize)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Warn("event stream query failed", slog.String("error", err.Error()))
+				}
+				return
+			}
+			for _, evt := range evts {
+				if !filter.match(evt) {
+					continue
+				}
+				data, err := json.Marshal(eventResponses([]*events.Event{evt})[0])
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(w, "id: %d\nevent: event\ndata: %s\n\n", lastRow, data)
+			}
+			if len(evts) > 0 {
+				// The id on each message is the batch's last row, so a
+				// reconnect never replays events already sent.
+				cursor = lastRow
+				flusher.Flush()
+				lastWrite = time.Now()
+			}
+			if len(evts) < streamBatchSize {
+				break
+			}
+		}
+
+		if time.Since(lastWrite) >= StreamKeepalive {
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+			lastWrite = time.Now()
+		}
+	}
+}
+
+type streamFilter struct {
+	sources map[string]bool
+	repo    string
+}
+
+func (s *Server) streamFilter(r *http.Request) streamFilter {
+	params := r.URL.Query()
+
+	var f streamFilter
+	for _, source := range strings.Split(params.Get("source"), ",") {
+		if source = strings.TrimSpace(source); source != "" {
+			if f.sources == nil {
+				f.sources = make(map[string]bool)
+			}
+			f.sources[source] = true
+		}
+	}
+	if repo := params.Get("repo"); repo != "" {
+		f.repo = s.configGetter().ResolveRepoName(repo)
+	}
+	return f
+}
+
+func (f streamFilter) match(evt *events.Event) bool {
+	if f.sources != nil && !f.sources[evt.Source] {
+		return false
+	}
+	if f.repo != "" && evt.Repo != f.repo {
+		return false
+	}
+	return true
+}
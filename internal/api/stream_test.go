@@ -0,0 +1,83 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func TestEventStream(t *testing.T) {
+	server, store := setupTestServer(t)
+	defer store.Close()
+
+	old := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	old.Repo = "devlog"
+	if err := store.InsertEvent(old); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(server.SetupRoutes())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/api/v1/events/stream?source=git&repo=devlog", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	shell := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	shell.Repo = "devlog"
+	other := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	other.Repo = "elsewhere"
+	want := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	want.Repo = "devlog"
+	want.Payload["hash"] = "abc123"
+	for _, e := range []*events.Event{shell, other, want} {
+		if err := store.InsertEvent(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var got EventResponse
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			if err := json.Unmarshal([]byte(data), &got); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			break
+		}
+	}
+	if got.ID != want.ID {
+		t.Fatalf("streamed event %q, want %q (only new git events in devlog)", got.ID, want.ID)
+	}
+	if got.Payload["hash"] != "abc123" {
+		t.Errorf("payload = %v", got.Payload)
+	}
+
+	server.Drain()
+	done := make(chan struct{})
+	go func() {
+		for scanner.Scan() {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Error("stream stayed open after Drain")
+	}
+}
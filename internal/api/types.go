@@ -15,10 +15,43 @@ type IngestEventResponse struct {
 	Error    string `json:"error,omitempty"`
 }
 
+type BatchIngestError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type BatchIngestResponse struct {
+	OK         bool               `json:"ok"`
+	Ingested   int                `json:"ingested"`
+	Filtered   int                `json:"filtered,omitempty"`
+	Duplicates int                `json:"duplicates,omitempty"`
+	Errors     []BatchIngestError `json:"errors,omitempty"`
+}
+
 type StatusResponse struct {
-	Running       bool `json:"running"`
-	EventCount    int  `json:"event_count"`
-	UptimeSeconds int  `json:"uptime_seconds"`
+	Running       bool             `json:"running"`
+	EventCount    int              `json:"event_count"`
+	UptimeSeconds int              `json:"uptime_seconds"`
+	Paused        bool             `json:"paused"`
+	PausedUntil   string           `json:"paused_until,omitempty"`
+	Preflight     []PreflightIssue `json:"preflight,omitempty"`
+}
+
+type PreflightIssue struct {
+	Module   string `json:"module"`
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+	Repaired bool   `json:"repaired,omitempty"`
+}
+
+type PauseRequest struct {
+	Duration string `json:"duration,omitempty"`
+}
+
+type PauseResponse struct {
+	Paused bool   `json:"paused"`
+	Since  string `json:"since,omitempty"`
+	Until  string `json:"until,omitempty"`
 }
 
 type HealthResponse struct {
@@ -27,18 +60,45 @@ type HealthResponse struct {
 }
 
 type EventResponse struct {
-	ID        string                 `json:"id"`
-	Timestamp string                 `json:"timestamp"`
-	Source    string                 `json:"source"`
-	Type      string                 `json:"type"`
-	Repo      string                 `json:"repo,omitempty"`
-	Branch    string                 `json:"branch,omitempty"`
-	Payload   map[string]interface{} `json:"payload"`
+	ID         string                 `json:"id"`
+	Timestamp  string                 `json:"timestamp"`
+	Source     string                 `json:"source"`
+	Type       string                 `json:"type"`
+	Repo       string                 `json:"repo,omitempty"`
+	Branch     string                 `json:"branch,omitempty"`
+	DurationMs int64                  `json:"duration_ms,omitempty"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Severity   string                 `json:"severity,omitempty"`
+	Payload    map[string]interface{} `json:"payload"`
 }
 
 type GetEventsResponse struct {
-	Events []EventResponse `json:"events"`
-	Count  int             `json:"count"`
+	Events     []EventResponse `json:"events"`
+	Count      int             `json:"count"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+type AnnotationResponse struct {
+	ID        int64  `json:"id"`
+	EventID   string `json:"event_id"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"created_at"`
+}
+
+type EventDetailResponse struct {
+	Event       EventResponse        `json:"event"`
+	Annotations []AnnotationResponse `json:"annotations"`
+}
+
+type AddAnnotationRequest struct {
+	Text string `json:"text"`
+}
+
+type RelatedEventsResponse struct {
+	EventID string          `json:"event_id"`
+	Events  []EventResponse `json:"events"`
+	Count   int             `json:"count"`
 }
 
 type SourceCount struct {
@@ -51,12 +111,38 @@ type EventsBySourceResponse struct {
 }
 
 type TimelinePoint struct {
-	Hour  string `json:"hour"`
-	Count int    `json:"count"`
+	Start    string         `json:"start"`
+	Count    int            `json:"count"`
+	BySource map[string]int `json:"by_source"`
 }
 
 type EventsTimelineResponse struct {
-	Data []TimelinePoint `json:"data"`
+	Bucket string          `json:"bucket"`
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Data   []TimelinePoint `json:"data"`
+}
+
+type HeatmapDay struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+type HeatmapResponse struct {
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Total int          `json:"total"`
+	Max   int          `json:"max"`
+	Data  []HeatmapDay `json:"data"`
+}
+
+type HourlyDistributionResponse struct {
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Weekdays  []string   `json:"weekdays"`
+	ByHour    [24]int    `json:"by_hour"`
+	ByWeekday [7]int     `json:"by_weekday"`
+	Grid      [7][24]int `json:"grid"`
 }
 
 type RepoStat struct {
@@ -68,6 +154,56 @@ type RepoStatsResponse struct {
 	Data []RepoStat `json:"data"`
 }
 
+type FileStat struct {
+	Repo     string `json:"repo,omitempty"`
+	Path     string `json:"path"`
+	Language string `json:"language,omitempty"`
+	Count    int    `json:"count"`
+}
+
+type FileStatsResponse struct {
+	From string     `json:"from"`
+	To   string     `json:"to"`
+	Data []FileStat `json:"data"`
+}
+
+type LanguageStat struct {
+	Language string `json:"language"`
+	Count    int    `json:"count"`
+	Files    int    `json:"files"`
+}
+
+type LanguageStatsResponse struct {
+	From string         `json:"from"`
+	To   string         `json:"to"`
+	Data []LanguageStat `json:"data"`
+}
+
+type FocusStreak struct {
+	Repo            string   `json:"repo"`
+	Branches        []string `json:"branches,omitempty"`
+	Start           string   `json:"start"`
+	End             string   `json:"end"`
+	DurationMinutes int      `json:"duration_minutes"`
+	Events          int      `json:"events"`
+	Switches        int      `json:"switches"`
+}
+
+type FocusResponse struct {
+	From            string         `json:"from"`
+	To              string         `json:"to"`
+	Events          int            `json:"events"`
+	Truncated       bool           `json:"truncated,omitempty"`
+	ActiveMinutes   int            `json:"active_minutes"`
+	Switches        int            `json:"switches"`
+	SwitchesPerHour float64        `json:"switches_per_hour"`
+	SwitchesByKind  map[string]int `json:"switches_by_kind"`
+	SwitchesByHour  [24]int        `json:"switches_by_hour"`
+	LongestStreak   *FocusStreak   `json:"longest_streak,omitempty"`
+	DeepWork        []FocusStreak  `json:"deep_work"`
+	DeepWorkMinutes int            `json:"deep_work_minutes"`
+}
+
 type CommandStat struct {
 	Command string `json:"command"`
 	Count   int    `json:"count"`
@@ -78,14 +214,17 @@ type CommandStatsResponse struct {
 }
 
 type SearchResultResponse struct {
-	ID        string                 `json:"id"`
-	Timestamp string                 `json:"timestamp"`
-	Source    string                 `json:"source"`
-	Type      string                 `json:"type"`
-	Repo      string                 `json:"repo,omitempty"`
-	Branch    string                 `json:"branch,omitempty"`
-	Payload   map[string]interface{} `json:"payload"`
-	Rank      float64                `json:"rank"`
+	Kind        string                 `json:"kind"`
+	ID          string                 `json:"id"`
+	Timestamp   string                 `json:"timestamp"`
+	Source      string                 `json:"source,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Repo        string                 `json:"repo,omitempty"`
+	Branch      string                 `json:"branch,omitempty"`
+	Payload     map[string]interface{} `json:"payload,omitempty"`
+	Summary     *SummaryResponse       `json:"summary,omitempty"`
+	Annotations []AnnotationResponse   `json:"annotations,omitempty"`
+	Rank        float64                `json:"rank"`
 }
 
 type SearchResponse struct {
@@ -96,7 +235,58 @@ type SearchResponse struct {
 	HasMore    bool                   `json:"has_more,omitempty"`
 }
 
+type SummaryResponse struct {
+	ID                int64    `json:"id"`
+	PeriodStart       string   `json:"period_start"`
+	PeriodEnd         string   `json:"period_end"`
+	ContextStart      string   `json:"context_start"`
+	Repo              string   `json:"repo,omitempty"`
+	Repos             []string `json:"repos"`
+	Summary           string   `json:"summary"`
+	EventCount        int      `json:"event_count"`
+	ContextEventCount int      `json:"context_event_count"`
+	Provider          string   `json:"provider,omitempty"`
+	InputTokens       int      `json:"input_tokens"`
+	OutputTokens      int      `json:"output_tokens"`
+	CreatedAt         string   `json:"created_at"`
+}
+
+type SummariesResponse struct {
+	Date      string            `json:"date"`
+	Repo      string            `json:"repo,omitempty"`
+	Repos     []string          `json:"repos"`
+	Summaries []SummaryResponse `json:"summaries"`
+	Count     int               `json:"count"`
+}
+
+type ConfigResponse struct {
+	Path    string                 `json:"path"`
+	Version string                 `json:"version"`
+	Config  map[string]interface{} `json:"config"`
+	Modules []ComponentInfo        `json:"modules"`
+	Plugins []ComponentInfo        `json:"plugins"`
+	// RestartRequired lists saved settings that only apply after the
+	// daemon restarts.
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+type ComponentInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// DefaultConfig is the config `devlog module install` or `devlog plugin
+	// install` writes, for editors to fill in when turning a component on.
+	DefaultConfig interface{} `json:"default_config,omitempty"`
+}
+
+type UpdateConfigRequest struct {
+	// Version is the version the edit started from; the save fails with
+	// 409 if config.yaml changed since. Empty skips the check.
+	Version string                 `json:"version,omitempty"`
+	Config  map[string]interface{} `json:"config"`
+}
+
 type ErrorResponse struct {
 	OK    bool   `json:"ok"`
 	Error string `json:"error"`
+	Queue bool   `json:"queue,omitempty"`
 }
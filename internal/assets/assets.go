@@ -0,0 +1,188 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type Asset struct {
+	Module  string
+	Name    string
+	Content string
+	Mode    os.FileMode
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]Asset)
+)
+
+func key(module, name string) string {
+	return module + "/" + name
+}
+
+func Register(a Asset) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	k := key(a.Module, a.Name)
+	if _, exists := registry[k]; exists {
+		panic(fmt.Sprintf("asset %s already registered", k))
+	}
+	if a.Mode == 0 {
+		a.Mode = 0644
+	}
+	registry[k] = a
+}
+
+func RegisterFS(module string, fsys fs.FS, dir string, executables ...string) {
+	exec := make(map[string]bool, len(executables))
+	for _, name := range executables {
+		exec[name] = true
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		panic(fmt.Sprintf("read embedded assets for %s: %v", module, err))
+	}
+
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, dir+"/"+e.Name())
+		if err != nil {
+			panic(fmt.Sprintf("read embedded asset %s: %v", key(module, e.Name()), err))
+		}
+
+		mode := os.FileMode(0644)
+		if exec[e.Name()] {
+			mode = 0755
+		}
+		Register(Asset{Module: module, Name: e.Name(), Content: string(content), Mode: mode})
+	}
+}
+
+func Get(module, name string) (Asset, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	a, ok := registry[key(module, name)]
+	if !ok {
+		return Asset{}, fmt.Errorf("asset %s not found", key(module, name))
+	}
+	return a, nil
+}
+
+func List() []Asset {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]Asset, 0, len(registry))
+	for _, a := range registry {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return key(list[i].Module, list[i].Name) < key(list[j].Module, list[j].Name)
+	})
+	return list
+}
+
+func (a Asset) Render(vars map[string]string) string {
+	content := a.Content
+	for k, v := range vars {
+		content = strings.ReplaceAll(content, "{{"+k+"}}", v)
+	}
+	return content
+}
+
+func Materialize(dataDir, version, module, name, path string, vars map[string]string) error {
+	a, err := Get(module, name)
+	if err != nil {
+		return err
+	}
+
+	content := a.Render(vars)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := writeFile(path, content, a.Mode); err != nil {
+		return err
+	}
+
+	if dataDir == "" {
+		return nil
+	}
+
+	m, err := LoadManifest(dataDir)
+	if err != nil {
+		return err
+	}
+	m.record(path, module, name, vars, content, version)
+	return m.Save(dataDir)
+}
+
+func Owned(dataDir, module, name, path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	sum := hash(string(content))
+
+	a, err := Get(module, name)
+	if err != nil {
+		return false
+	}
+
+	vars := map[string]string(nil)
+	if m, err := LoadManifest(dataDir); err == nil {
+		if entry, ok := m.Files[path]; ok {
+			if entry.SHA256 == sum {
+				return true
+			}
+			vars = entry.Vars
+		}
+	}
+
+	return hash(a.Render(vars)) == sum
+}
+
+func Forget(dataDir, path string) error {
+	m, err := LoadManifest(dataDir)
+	if err != nil {
+		return err
+	}
+	if _, ok := m.Files[path]; !ok {
+		return nil
+	}
+	delete(m.Files, path)
+	return m.Save(dataDir)
+}
+
+func writeFile(path, content string, mode os.FileMode) error {
+	tmp := path + ".devlog-tmp"
+	if err := os.WriteFile(tmp, []byte(content), mode); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestRegisterFS(t *testing.T) {
+	RegisterFS("fsmod", fstest.MapFS{
+		"hooks/wrapper.sh": {Data: []byte("#!/bin/sh\n")},
+		"hooks/lib.sh":     {Data: []byte("# lib\n")},
+	}, "hooks", "wrapper.sh")
+
+	wrapper, err := Get("fsmod", "wrapper.sh")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if wrapper.Mode != 0755 {
+		t.Errorf("expected executable mode, got %o", wrapper.Mode)
+	}
+
+	lib, err := Get("fsmod", "lib.sh")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if lib.Mode != 0644 || lib.Content != "# lib\n" {
+		t.Errorf("unexpected lib asset: %+v", lib)
+	}
+
+	if _, err := Get("fsmod", "missing.sh"); err == nil {
+		t.Error("expected error for unknown asset")
+	}
+}
+
+func TestMaterializeAndRefresh(t *testing.T) {
+	dataDir := t.TempDir()
+	binDir := t.TempDir()
+
+	Register(Asset{Module: "refreshmod", Name: "hooks.conf", Content: "run {{WRAPPER_PATH}} v1\n"})
+	Register(Asset{Module: "refreshmod", Name: "edited.sh", Content: "v1\n", Mode: 0755})
+
+	confPath := filepath.Join(binDir, "hooks.conf")
+	editedPath := filepath.Join(binDir, "edited.sh")
+	vars := map[string]string{"WRAPPER_PATH": "/opt/wrapper"}
+
+	if err := Materialize(dataDir, "1.0.0", "refreshmod", "hooks.conf", confPath, vars); err != nil {
+		t.Fatalf("Materialize failed: %v", err)
+	}
+	if err := Materialize(dataDir, "1.0.0", "refreshmod", "edited.sh", editedPath, nil); err != nil {
+		t.Fatalf("Materialize failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(confPath)
+	if string(content) != "run /opt/wrapper v1\n" {
+		t.Errorf("expected rendered template, got %q", content)
+	}
+	if info, _ := os.Stat(editedPath); info.Mode().Perm() != 0755 {
+		t.Errorf("expected 0755, got %o", info.Mode().Perm())
+	}
+	if !Owned(dataDir, "refreshmod", "edited.sh", editedPath) {
+		t.Error("expected freshly written asset to be owned")
+	}
+
+	m, err := LoadManifest(dataDir)
+	if err != nil {
+		t.Fatalf("LoadManifest failed: %v", err)
+	}
+	if m.Version != "1.0.0" || len(m.Files) != 2 {
+		t.Fatalf("unexpected manifest: %+v", m)
+	}
+
+	os.WriteFile(editedPath, []byte("v1 with local tweak\n"), 0755)
+
+	mu.Lock()
+	registry[key("refreshmod", "hooks.conf")] = Asset{Module: "refreshmod", Name: "hooks.conf", Content: "run {{WRAPPER_PATH}} v2\n", Mode: 0644}
+	registry[key("refreshmod", "edited.sh")] = Asset{Module: "refreshmod", Name: "edited.sh", Content: "v2\n", Mode: 0755}
+	mu.Unlock()
+
+	results, err := Refresh(dataDir, "1.1.0", false)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	statuses := map[string]Status{}
+	for _, r := range results {
+		statuses[r.Path] = r.Status
+	}
+	if statuses[confPath] != StatusUpdated {
+		t.Errorf("expected %s to be updated, got %s", confPath, statuses[confPath])
+	}
+	if statuses[editedPath] != StatusModified {
+		t.Errorf("expected %s to be skipped as modified, got %s", editedPath, statuses[editedPath])
+	}
+
+	content, _ = os.ReadFile(confPath)
+	if string(content) != "run /opt/wrapper v2\n" {
+		t.Errorf("expected refreshed template with original vars, got %q", content)
+	}
+	if Owned(dataDir, "refreshmod", "edited.sh", editedPath) {
+		t.Error("locally modified asset should not be owned")
+	}
+
+	results, err = Refresh(dataDir, "1.1.0", true)
+	if err != nil {
+		t.Fatalf("Refresh --force failed: %v", err)
+	}
+	for _, r := range results {
+		if r.Path == editedPath && r.Status != StatusUpdated {
+			t.Errorf("expected forced update, got %s", r.Status)
+		}
+		if r.Path == confPath && r.Status != StatusCurrent {
+			t.Errorf("expected %s to be current, got %s", confPath, r.Status)
+		}
+	}
+
+	m, _ = LoadManifest(dataDir)
+	if m.Version != "1.1.0" || m.Files[editedPath].Version != "1.1.0" {
+		t.Errorf("expected manifest to record new version, got %+v", m)
+	}
+
+	os.Remove(confPath)
+	if err := Forget(dataDir, confPath); err != nil {
+		t.Fatalf("Forget failed: %v", err)
+	}
+	m, _ = LoadManifest(dataDir)
+	if _, ok := m.Files[confPath]; ok {
+		t.Error("expected forgotten path to leave the manifest")
+	}
+}
+
+func TestOwnedLegacyInstall(t *testing.T) {
+	dataDir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "legacy.sh")
+
+	Register(Asset{Module: "legacymod", Name: "legacy.sh", Content: "legacy\n"})
+	os.WriteFile(path, []byte("legacy\n"), 0755)
+
+	if !Owned(dataDir, "legacymod", "legacy.sh", path) {
+		t.Error("expected untracked file matching the embedded asset to be owned")
+	}
+
+	os.WriteFile(path, []byte("someone else's script\n"), 0755)
+	if Owned(dataDir, "legacymod", "legacy.sh", path) {
+		t.Error("expected foreign file not to be owned")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	dataDir := t.TempDir()
+	binDir := t.TempDir()
+
+	Register(Asset{Module: "verifymod", Name: "a.sh", Content: "a\n"})
+	Register(Asset{Module: "verifymod", Name: "b.sh", Content: "b\n"})
+	Register(Asset{Module: "verifymod", Name: "c.sh", Content: "c\n"})
+
+	for _, name := range []string{"a.sh", "b.sh", "c.sh"} {
+		if err := Materialize(dataDir, "1.0.0", "verifymod", name, filepath.Join(binDir, name), nil); err != nil {
+			t.Fatalf("Materialize failed: %v", err)
+		}
+	}
+	os.WriteFile(filepath.Join(binDir, "b.sh"), []byte("edited\n"), 0644)
+	os.Remove(filepath.Join(binDir, "c.sh"))
+
+	results, err := Verify(dataDir)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	want := []Status{StatusCurrent, StatusModified, StatusMissing}
+	if len(results) != len(want) {
+		t.Fatalf("expected %d results, got %+v", len(want), results)
+	}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("%s: expected %s, got %s", r.Path, want[i], r.Status)
+		}
+	}
+
+	content, _ := os.ReadFile(filepath.Join(binDir, "b.sh"))
+	if string(content) != "edited\n" {
+		t.Error("Verify should not rewrite files")
+	}
+}
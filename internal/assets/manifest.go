@@ -0,0 +1,187 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const manifestFile = "assets.json"
+
+type Entry struct {
+	Module      string            `json:"module"`
+	Asset       string            `json:"asset"`
+	Vars        map[string]string `json:"vars,omitempty"`
+	SHA256      string            `json:"sha256"`
+	Version     string            `json:"version"`
+	InstalledAt time.Time         `json:"installed_at"`
+}
+
+type Manifest struct {
+	Version string           `json:"version"`
+	Files   map[string]Entry `json:"files"`
+}
+
+type Status string
+
+const (
+	StatusCurrent  Status = "current"
+	StatusUpdated  Status = "updated"
+	StatusModified Status = "modified"
+	StatusMissing  Status = "missing"
+	StatusOrphaned Status = "orphaned"
+)
+
+type Result struct {
+	Path   string
+	Module string
+	Status Status
+}
+
+func LoadManifest(dataDir string) (*Manifest, error) {
+	m := &Manifest{Files: make(map[string]Entry)}
+
+	data, err := os.ReadFile(filepath.Join(dataDir, manifestFile))
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read asset manifest: %w", err)
+	}
+
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse asset manifest: %w", err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]Entry)
+	}
+	return m, nil
+}
+
+func (m *Manifest) Save(dataDir string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode asset manifest: %w", err)
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	if err := writeFile(filepath.Join(dataDir, manifestFile), string(data)+"\n", 0644); err != nil {
+		return fmt.Errorf("write asset manifest: %w", err)
+	}
+	return nil
+}
+
+func (m *Manifest) record(path, module, name string, vars map[string]string, content, version string) {
+	m.Files[path] = Entry{
+		Module:      module,
+		Asset:       name,
+		Vars:        vars,
+		SHA256:      hash(content),
+		Version:     version,
+		InstalledAt: time.Now().UTC(),
+	}
+	m.Version = version
+}
+
+// Refresh rewrites installed assets whose embedded content has changed
+// since they were written. Files edited by hand are left alone unless
+// force is set.
+func Refresh(dataDir, version string, force bool) ([]Result, error) {
+	m, err := LoadManifest(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	results := make([]Result, 0, len(paths))
+	for _, path := range paths {
+		entry := m.Files[path]
+		result := Result{Path: path, Module: entry.Module}
+
+		a, err := Get(entry.Module, entry.Asset)
+		if err != nil {
+			result.Status = StatusOrphaned
+			results = append(results, result)
+			continue
+		}
+
+		current, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			result.Status = StatusMissing
+			results = append(results, result)
+			continue
+		}
+		if err != nil {
+			return results, fmt.Errorf("read %s: %w", path, err)
+		}
+
+		want := a.Render(entry.Vars)
+		switch {
+		case hash(string(current)) == hash(want):
+			result.Status = StatusCurrent
+			entry.SHA256 = hash(want)
+			entry.Version = version
+			m.Files[path] = entry
+		case hash(string(current)) != entry.SHA256 && !force:
+			result.Status = StatusModified
+		default:
+			if err := writeFile(path, want, a.Mode); err != nil {
+				return results, fmt.Errorf("refresh %s: %w", path, err)
+			}
+			m.record(path, entry.Module, entry.Asset, entry.Vars, want, version)
+			result.Status = StatusUpdated
+		}
+
+		results = append(results, result)
+	}
+
+	m.Version = version
+	if len(m.Files) == 0 {
+		return results, nil
+	}
+	return results, m.Save(dataDir)
+}
+
+// Verify reports the on-disk state of every tracked asset without
+// rewriting anything. Files that still match what devlog last wrote are
+// current even if a newer embedded version exists; Refresh handles those.
+func Verify(dataDir string) ([]Result, error) {
+	m, err := LoadManifest(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(m.Files))
+	for path := range m.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	results := make([]Result, 0, len(paths))
+	for _, path := range paths {
+		entry := m.Files[path]
+		result := Result{Path: path, Module: entry.Module, Status: StatusCurrent}
+
+		if _, err := Get(entry.Module, entry.Asset); err != nil {
+			result.Status = StatusOrphaned
+		} else if current, err := os.ReadFile(path); os.IsNotExist(err) {
+			result.Status = StatusMissing
+		} else if err != nil {
+			return results, fmt.Errorf("read %s: %w", path, err)
+		} else if hash(string(current)) != entry.SHA256 {
+			result.Status = StatusModified
+		}
+
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const activeHoursKey = "active_hours"
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+type ActiveHours struct {
+	start int
+	end   int
+	days  map[time.Weekday]bool
+}
+
+// ParseActiveHours parses "HH:MM-HH:MM [days]", e.g. "09:00-18:00 Mon-Fri"
+// or "22:00-02:00 Fri,Sat". Days default to every day; a window that
+// crosses midnight belongs to the day it starts on.
+func ParseActiveHours(spec string) (*ActiveHours, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("active_hours must look like \"09:00-18:00 Mon-Fri\", got %q", spec)
+	}
+
+	startStr, endStr, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return nil, fmt.Errorf("active_hours time range must be HH:MM-HH:MM, got %q", fields[0])
+	}
+
+	start, err := parseClockMinutes(startStr)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseClockMinutes(endStr)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("active_hours start and end must differ")
+	}
+
+	a := &ActiveHours{start: start, end: end}
+	if len(fields) == 2 {
+		days, err := parseDays(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		a.days = days
+	}
+
+	return a, nil
+}
+
+func parseClockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("active_hours time must be HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(s)
+	if len(name) > 3 {
+		name = name[:3]
+	}
+	day, ok := weekdayNames[name]
+	if !ok {
+		return 0, fmt.Errorf("active_hours has unknown day %q", s)
+	}
+	return day, nil
+}
+
+func parseDays(spec string) (map[time.Weekday]bool, error) {
+	days := make(map[time.Weekday]bool)
+	for _, part := range strings.Split(spec, ",") {
+		fromStr, toStr, isRange := strings.Cut(part, "-")
+
+		from, err := parseWeekday(fromStr)
+		if err != nil {
+			return nil, err
+		}
+		if !isRange {
+			days[from] = true
+			continue
+		}
+
+		to, err := parseWeekday(toStr)
+		if err != nil {
+			return nil, err
+		}
+		for d := from; ; d = (d + 1) % 7 {
+			days[d] = true
+			if d == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+func (a *ActiveHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	var inside bool
+	if a.start < a.end {
+		inside = minute >= a.start && minute < a.end
+	} else {
+		inside = minute >= a.start || minute < a.end
+		if minute < a.end {
+			day = t.AddDate(0, 0, -1).Weekday()
+		}
+	}
+
+	if !inside {
+		return false
+	}
+	return a.days == nil || a.days[day]
+}
+
+func (c *Config) ModuleActiveHours(moduleName string) (*ActiveHours, error) {
+	modCfg, ok := c.GetModuleConfig(moduleName)
+	if !ok {
+		return nil, nil
+	}
+
+	spec, ok := modCfg[activeHoursKey].(string)
+	if !ok || strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	return ParseActiveHours(spec)
+}
+
+func (c *Config) IsModuleActiveAt(moduleName string, t time.Time) bool {
+	hours, err := c.ModuleActiveHours(moduleName)
+	if err != nil || hours == nil {
+		return true
+	}
+	return hours.Contains(t.Local())
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseActiveHours(t *testing.T) {
+	monday := time.Date(2025, 6, 2, 0, 0, 0, 0, time.Local)
+	at := func(day, hour, minute int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	tests := []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		{"09:00-18:00 Mon-Fri", at(0, 9, 0), true},
+		{"09:00-18:00 Mon-Fri", at(0, 17, 59), true},
+		{"09:00-18:00 Mon-Fri", at(0, 18, 0), false},
+		{"09:00-18:00 Mon-Fri", at(0, 8, 59), false},
+		{"09:00-18:00 Mon-Fri", at(5, 10, 0), false},
+		{"09:00-18:00", at(5, 10, 0), true},
+		{"09:00-12:00 mon,wed", at(2, 10, 0), true},
+		{"09:00-12:00 mon,wed", at(1, 10, 0), false},
+		{"10:00-14:00 Sat-Sun", at(6, 11, 0), true},
+		{"10:00-14:00 Fri-Mon", at(0, 11, 0), true},
+		{"10:00-14:00 Fri-Mon", at(1, 11, 0), false},
+		{"22:00-02:00 Fri", at(4, 23, 0), true},
+		{"22:00-02:00 Fri", at(5, 1, 0), true},
+		{"22:00-02:00 Fri", at(5, 23, 0), false},
+	}
+
+	for _, tt := range tests {
+		hours, err := ParseActiveHours(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseActiveHours(%q) error: %v", tt.spec, err)
+		}
+		if got := hours.Contains(tt.at); got != tt.want {
+			t.Errorf("%q at %s: got %v, want %v", tt.spec, tt.at.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+}
+
+func TestParseActiveHoursInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"9-5",
+		"09:00",
+		"09:00-09:00",
+		"09:00-25:00",
+		"09:00-18:00 Someday",
+		"09:00-18:00 Mon-Fri extra",
+	} {
+		if _, err := ParseActiveHours(spec); err == nil {
+			t.Errorf("ParseActiveHours(%q) expected error", spec)
+		}
+	}
+}
+
+func TestIsModuleActiveAt(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Modules["clipboard"] = ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"active_hours": "09:00-18:00 Mon-Fri"},
+	}
+	cfg.Modules["shell"] = ComponentConfig{Enabled: true}
+
+	saturday := time.Date(2025, 6, 7, 12, 0, 0, 0, time.Local)
+	if cfg.IsModuleActiveAt("clipboard", saturday) {
+		t.Error("clipboard should be inactive on Saturday")
+	}
+	if !cfg.IsModuleActiveAt("clipboard", saturday.AddDate(0, 0, 2)) {
+		t.Error("clipboard should be active Monday at noon")
+	}
+	if !cfg.IsModuleActiveAt("shell", saturday) {
+		t.Error("modules without active_hours are always active")
+	}
+
+	cfg.Modules["shell"] = ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"active_hours": "nine to five"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected Validate to reject malformed active_hours")
+	}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const (
+	DefaultBackupInterval = 24 * time.Hour
+	DefaultBackupKeep     = 7
+)
+
+// BackupConfig schedules automatic copies of the SQLite database, taken by
+// the daemon while it runs.
+type BackupConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Interval between backups as a Go duration, 24h by default.
+	Interval string `yaml:"interval,omitempty"`
+	// Keep is how many backups are retained; older ones are deleted.
+	Keep int  `yaml:"keep,omitempty"`
+	Gzip bool `yaml:"gzip,omitempty"`
+	// Dir defaults to backups/ in the data directory.
+	Dir string `yaml:"dir,omitempty"`
+}
+
+func (b BackupConfig) IntervalDuration() time.Duration {
+	if d, err := time.ParseDuration(b.Interval); err == nil && d > 0 {
+		return d
+	}
+	return DefaultBackupInterval
+}
+
+func (b BackupConfig) KeepCount() int {
+	if b.Keep > 0 {
+		return b.Keep
+	}
+	return DefaultBackupKeep
+}
+
+// BackupDir returns where scheduled backups are written.
+func (b BackupConfig) BackupDir(dataDir string) string {
+	if b.Dir != "" {
+		return ExpandHome(b.Dir)
+	}
+	return filepath.Join(dataDir, "backups")
+}
+
+func (b BackupConfig) validate() error {
+	if b.Interval != "" {
+		d, err := time.ParseDuration(b.Interval)
+		if err != nil {
+			return fmt.Errorf("backup.interval: %w", err)
+		}
+		if d < time.Minute {
+			return fmt.Errorf("backup.interval must be at least 1m")
+		}
+	}
+	if b.Keep < 0 {
+		return fmt.Errorf("backup.keep must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupConfigDefaults(t *testing.T) {
+	var b BackupConfig
+	if b.IntervalDuration() != DefaultBackupInterval || b.KeepCount() != DefaultBackupKeep {
+		t.Errorf("defaults = %v, %d", b.IntervalDuration(), b.KeepCount())
+	}
+	if got := b.BackupDir("/data"); got != filepath.Join("/data", "backups") {
+		t.Errorf("BackupDir() = %q", got)
+	}
+
+	b = BackupConfig{Interval: "6h", Keep: 3, Dir: "/mnt/backups"}
+	if b.IntervalDuration() != 6*time.Hour || b.KeepCount() != 3 || b.BackupDir("/data") != "/mnt/backups" {
+		t.Errorf("configured = %v, %d, %q", b.IntervalDuration(), b.KeepCount(), b.BackupDir("/data"))
+	}
+}
+
+func TestBackupConfigValidate(t *testing.T) {
+	tests := []struct {
+		cfg     BackupConfig
+		wantErr bool
+	}{
+		{BackupConfig{}, false},
+		{BackupConfig{Enabled: true, Interval: "12h", Keep: 14}, false},
+		{BackupConfig{Interval: "daily"}, true},
+		{BackupConfig{Interval: "30s"}, true},
+		{BackupConfig{Keep: -1}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("validate(%+v) error = %v, wantErr %v", tt.cfg, err, tt.wantErr)
+		}
+	}
+}
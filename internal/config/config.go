@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 
@@ -15,6 +16,12 @@ type Config struct {
 	HTTP    HTTPConfig                 `yaml:"http"`
 	Modules map[string]ComponentConfig `yaml:"modules,omitempty"`
 	Plugins map[string]ComponentConfig `yaml:"plugins,omitempty"`
+	Filters []FilterRule               `yaml:"filters,omitempty"`
+	Privacy PrivacyConfig              `yaml:"privacy,omitempty"`
+	Repos   ReposConfig                `yaml:"repos,omitempty"`
+	Daemon  DaemonConfig               `yaml:"daemon,omitempty"`
+	Storage StorageConfig              `yaml:"storage,omitempty"`
+	Backup  BackupConfig               `yaml:"backup,omitempty"`
 }
 
 type ComponentConfig struct {
@@ -22,8 +29,50 @@ type ComponentConfig struct {
 	Config  map[string]interface{} `yaml:",inline"`
 }
 
+type DaemonConfig struct {
+	AutoRepair bool `yaml:"auto_repair,omitempty"`
+	// QueryCache tunes the in-memory cache of search results and dashboard
+	// aggregations.
+	QueryCache QueryCacheConfig `yaml:"query_cache,omitempty"`
+	// WriteBuffer tunes how ingested events are batched before they are
+	// written to the database.
+	WriteBuffer WriteBufferConfig `yaml:"write_buffer,omitempty"`
+	// Resources tunes the watchdog that checks plugins and pollers against
+	// their resource budgets.
+	Resources ResourcesConfig `yaml:"resources,omitempty"`
+}
+
+type QueryCacheConfig struct {
+	Disabled   bool `yaml:"disabled,omitempty"`
+	Size       int  `yaml:"size,omitempty"`
+	TTLSeconds int  `yaml:"ttl_seconds,omitempty"`
+}
+
+type WriteBufferConfig struct {
+	Disabled    bool `yaml:"disabled,omitempty"`
+	Size        int  `yaml:"size,omitempty"`
+	BatchSize   int  `yaml:"batch_size,omitempty"`
+	FlushMillis int  `yaml:"flush_ms,omitempty"`
+}
+
 type HTTPConfig struct {
 	Port int `yaml:"port"`
+	// BindAddress is the IP the API listens on. It defaults to 127.0.0.1;
+	// use 0.0.0.0 or a LAN address to reach the dashboard from elsewhere.
+	BindAddress string        `yaml:"bind_address,omitempty"`
+	TLS         HTTPTLSConfig `yaml:"tls,omitempty"`
+	// AuthEnabled requires one of Tokens as a bearer token on every API
+	// endpoint except health checks and signed webhooks.
+	AuthEnabled bool       `yaml:"auth_enabled,omitempty"`
+	Tokens      []APIToken `yaml:"tokens,omitempty"`
+	// Limits overrides the per-route rate and body size limits, keyed by
+	// route group (see RouteGroups).
+	Limits map[string]RouteLimit `yaml:"limits,omitempty"`
+	// GRPCPort serves the gRPC ingestion endpoint on a second port, with the
+	// same bind address, TLS and tokens as the HTTP API. Zero turns it off.
+	GRPCPort int `yaml:"grpc_port,omitempty"`
+	// Socket serves the API on a Unix socket as well as the TCP port.
+	Socket SocketConfig `yaml:"socket,omitempty"`
 }
 
 func DefaultConfig() *Config {
@@ -36,14 +85,6 @@ func DefaultConfig() *Config {
 	}
 }
 
-func ConfigDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("get home directory: %w", err)
-	}
-	return filepath.Join(home, ".config", "devlog"), nil
-}
-
 func ConfigPath() (string, error) {
 	dir, err := ConfigDir()
 	if err != nil {
@@ -52,14 +93,6 @@ func ConfigPath() (string, error) {
 	return filepath.Join(dir, "config.yaml"), nil
 }
 
-func DataDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("get home directory: %w", err)
-	}
-	return filepath.Join(home, ".local", "share", "devlog"), nil
-}
-
 func QueueDir() (string, error) {
 	dataDir, err := DataDir()
 	if err != nil {
@@ -100,6 +133,11 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("read config file: %w", err)
 	}
 
+	return Parse(data)
+}
+
+// Parse decodes and validates the contents of a config file.
+func Parse(data []byte) (*Config, error) {
 	cfg := DefaultConfig()
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parse config file: %w", err)
@@ -117,6 +155,14 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("http port must be between 1024 and 65535 (privileged ports not allowed)")
 	}
 
+	if err := c.HTTP.validate(); err != nil {
+		return err
+	}
+
+	if err := c.validateTokens(); err != nil {
+		return fmt.Errorf("http token validation failed: %w", err)
+	}
+
 	if err := c.validateModules(); err != nil {
 		return fmt.Errorf("module validation failed: %w", err)
 	}
@@ -125,6 +171,39 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("plugin validation failed: %w", err)
 	}
 
+	if err := c.validateFilters(); err != nil {
+		return fmt.Errorf("filter validation failed: %w", err)
+	}
+
+	if err := c.validatePrivacy(); err != nil {
+		return fmt.Errorf("privacy validation failed: %w", err)
+	}
+
+	if err := c.validateRepos(); err != nil {
+		return fmt.Errorf("repos validation failed: %w", err)
+	}
+
+	if err := c.Storage.validate(); err != nil {
+		return fmt.Errorf("storage validation failed: %w", err)
+	}
+
+	if err := c.Backup.validate(); err != nil {
+		return fmt.Errorf("backup validation failed: %w", err)
+	}
+
+	if c.Daemon.QueryCache.Size < 0 || c.Daemon.QueryCache.TTLSeconds < 0 {
+		return fmt.Errorf("daemon.query_cache size and ttl_seconds must not be negative")
+	}
+
+	wb := c.Daemon.WriteBuffer
+	if wb.Size < 0 || wb.BatchSize < 0 || wb.FlushMillis < 0 {
+		return fmt.Errorf("daemon.write_buffer size, batch_size and flush_ms must not be negative")
+	}
+
+	if err := c.Daemon.Resources.validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -134,13 +213,31 @@ func (c *Config) validateModules() error {
 			continue
 		}
 
+		if val, ok := modCfg.Config[activeHoursKey]; ok && val != nil {
+			spec, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("module '%s': active_hours must be a string", name)
+			}
+			if _, err := ParseActiveHours(spec); err != nil {
+				return fmt.Errorf("module '%s': %w", name, err)
+			}
+		}
+
+		if _, err := ParseResourceBudget(modCfg.Config[resourcesKey]); err != nil {
+			return fmt.Errorf("module '%s': %w", name, err)
+		}
+
+		if _, err := ParseIngestQuota(modCfg.Config); err != nil {
+			return fmt.Errorf("module '%s': %w", name, err)
+		}
+
 		mod, err := modules.Get(name)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: unknown module '%s' in config (module may not be installed)\n", name)
 			continue
 		}
 
-		if err := mod.ValidateConfig(modCfg.Config); err != nil {
+		if err := mod.ValidateConfig(withoutKeys(modCfg.Config, activeHoursKey, resourcesKey, maxEventsPerHourKey, sampleRateKey)); err != nil {
 			return fmt.Errorf("module '%s' config validation failed: %w", name, err)
 		}
 	}
@@ -153,19 +250,36 @@ func (c *Config) validatePlugins() error {
 			continue
 		}
 
+		if _, err := ParseResourceBudget(pluginCfg.Config[resourcesKey]); err != nil {
+			return fmt.Errorf("plugin '%s': %w", name, err)
+		}
+
 		plugin, err := plugins.Get(name)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: unknown plugin '%s' in config (plugin may not be installed)\n", name)
 			continue
 		}
 
-		if err := plugin.ValidateConfig(pluginCfg.Config); err != nil {
+		if err := plugin.ValidateConfig(withoutKeys(pluginCfg.Config, resourcesKey)); err != nil {
 			return fmt.Errorf("plugin '%s' config validation failed: %w", name, err)
 		}
 	}
 	return nil
 }
 
+// withoutKeys copies a component config without the settings config
+// validates for every component, so a module or plugin only sees its own.
+func withoutKeys(cfg map[string]interface{}, keys ...string) map[string]interface{} {
+	if cfg == nil {
+		return nil
+	}
+	own := maps.Clone(cfg)
+	for _, key := range keys {
+		delete(own, key)
+	}
+	return own
+}
+
 func isComponentEnabled(components map[string]ComponentConfig, name string) bool {
 	if components == nil {
 		return false
@@ -255,42 +369,38 @@ func (c *Config) ShouldCaptureCommand(command string) bool {
 		return false
 	}
 
+	return !IsCommandIgnored(c.ShellIgnoreList(), command)
+}
+
+func (c *Config) ShellIgnoreList() []string {
 	shellCfg, ok := c.GetModuleConfig("shell")
 	if !ok {
-		return true
+		return nil
 	}
 
-	baseCmd := command
-	for i, ch := range command {
-		if ch == ' ' || ch == '\t' {
-			baseCmd = command[:i]
-			break
-		}
-	}
-
-	ignoreListInterface, _ := shellCfg["ignore_list"].([]interface{})
-	for _, item := range ignoreListInterface {
-		if ignored, ok := item.(string); ok && baseCmd == ignored {
-			return false
+	var ignoreList []string
+	switch list := shellCfg["ignore_list"].(type) {
+	case []string:
+		ignoreList = append(ignoreList, list...)
+	case []interface{}:
+		for _, item := range list {
+			if cmd, ok := item.(string); ok {
+				ignoreList = append(ignoreList, cmd)
+			}
 		}
 	}
-
-	return true
+	return ignoreList
 }
 
 func (c *Config) AddToShellIgnoreList(commands ...string) {
 	shellCfg, ok := c.GetModuleConfig("shell")
-	if !ok {
+	if !ok || shellCfg == nil {
 		shellCfg = make(map[string]interface{})
 	}
 
-	ignoreList := []string{}
-	if existingList, ok := shellCfg["ignore_list"].([]interface{}); ok {
-		for _, item := range existingList {
-			if cmd, ok := item.(string); ok {
-				ignoreList = append(ignoreList, cmd)
-			}
-		}
+	ignoreList := c.ShellIgnoreList()
+	if ignoreList == nil {
+		ignoreList = []string{}
 	}
 
 	for _, cmd := range commands {
@@ -312,26 +422,22 @@ func (c *Config) AddToShellIgnoreList(commands ...string) {
 
 func (c *Config) RemoveFromShellIgnoreList(commands ...string) {
 	shellCfg, ok := c.GetModuleConfig("shell")
-	if !ok {
+	if !ok || shellCfg == nil {
 		return
 	}
 
 	ignoreList := []string{}
-	if existingList, ok := shellCfg["ignore_list"].([]interface{}); ok {
-		for _, item := range existingList {
-			if cmd, ok := item.(string); ok {
-				shouldRemove := false
-				for _, toRemove := range commands {
-					if cmd == toRemove {
-						shouldRemove = true
-						break
-					}
-				}
-				if !shouldRemove {
-					ignoreList = append(ignoreList, cmd)
-				}
+	for _, cmd := range c.ShellIgnoreList() {
+		shouldRemove := false
+		for _, toRemove := range commands {
+			if cmd == toRemove {
+				shouldRemove = true
+				break
 			}
 		}
+		if !shouldRemove {
+			ignoreList = append(ignoreList, cmd)
+		}
 	}
 
 	shellCfg["ignore_list"] = ignoreList
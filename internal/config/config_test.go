@@ -113,3 +113,29 @@ func TestConfigDirPaths(t *testing.T) {
 		t.Errorf("got data dir %s, want %s", dataDir, expectedDataDir)
 	}
 }
+
+func TestShellIgnoreList(t *testing.T) {
+	cfg := DefaultConfig()
+	if list := cfg.ShellIgnoreList(); len(list) != 0 {
+		t.Errorf("expected empty ignore list without shell config, got %v", list)
+	}
+
+	cfg.Modules["shell"] = ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"ignore_list": []interface{}{"ls", "cd"}},
+	}
+	if list := cfg.ShellIgnoreList(); len(list) != 2 || list[0] != "ls" {
+		t.Errorf("got %v, want [ls cd]", list)
+	}
+
+	cfg.AddToShellIgnoreList("git")
+	if list := cfg.ShellIgnoreList(); len(list) != 3 || list[2] != "git" {
+		t.Errorf("got %v after AddToShellIgnoreList, want [ls cd git]", list)
+	}
+	if cfg.ShouldCaptureCommand("git status") {
+		t.Error("expected git status to be ignored")
+	}
+	if !cfg.ShouldCaptureCommand("go test ./...") {
+		t.Error("expected go test to be captured")
+	}
+}
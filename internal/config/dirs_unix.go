@@ -0,0 +1,25 @@
+//go:build !windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func ConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "devlog"), nil
+}
+
+func DataDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", "devlog"), nil
+}
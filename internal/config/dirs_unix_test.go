@@ -0,0 +1,31 @@
+//go:build !windows
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDirsUseHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	// %APPDATA% only matters on Windows.
+	t.Setenv("APPDATA", filepath.Join(home, "Roaming"))
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir() error: %v", err)
+	}
+	if want := filepath.Join(home, ".config", "devlog"); configDir != want {
+		t.Errorf("ConfigDir() = %q, want %q", configDir, want)
+	}
+
+	dataDir, err := DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() error: %v", err)
+	}
+	if want := filepath.Join(home, ".local", "share", "devlog"); dataDir != want {
+		t.Errorf("DataDir() = %q, want %q", dataDir, want)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ConfigDir is %APPDATA%\devlog, the roaming profile folder Windows
+// programs keep their settings in.
+func ConfigDir() (string, error) {
+	appData, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("get %%APPDATA%%: %w", err)
+	}
+	return filepath.Join(appData, "devlog"), nil
+}
+
+// DataDir keeps the database, logs and queue under %APPDATA%\devlog\data,
+// next to the config.
+func DataDir() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "data"), nil
+}
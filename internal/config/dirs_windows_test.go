@@ -0,0 +1,40 @@
+//go:build windows
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDirsUseAppData(t *testing.T) {
+	appData := filepath.Join(t.TempDir(), "Roaming")
+	t.Setenv("APPDATA", appData)
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		t.Fatalf("ConfigDir() error: %v", err)
+	}
+	if want := filepath.Join(appData, "devlog"); configDir != want {
+		t.Errorf("ConfigDir() = %q, want %q", configDir, want)
+	}
+
+	dataDir, err := DataDir()
+	if err != nil {
+		t.Fatalf("DataDir() error: %v", err)
+	}
+	if want := filepath.Join(appData, "devlog", "data"); dataDir != want {
+		t.Errorf("DataDir() = %q, want %q", dataDir, want)
+	}
+}
+
+func TestDirsNeedAppData(t *testing.T) {
+	t.Setenv("APPDATA", "")
+
+	if dir, err := ConfigDir(); err == nil {
+		t.Errorf("ConfigDir() without %%APPDATA%% = %q, want an error", dir)
+	}
+	if dir, err := DataDir(); err == nil {
+		t.Errorf("DataDir() without %%APPDATA%% = %q, want an error", dir)
+	}
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces secrets in a config document. Sending it back
+// unchanged keeps the secret that is already configured.
+const RedactedValue = "********"
+
+// secretSuffixes mark config keys whose values are credentials, such as
+// api_key, webhook_secret or toggl_api_token.
+var secretSuffixes = []string{"secret", "password", "api_key", "access_key", "_token", "dsn"}
+
+// secretKeys are credentials named without a telling suffix: the
+// encryption key of the sync and backup plugins, and bare tokens.
+var secretKeys = []string{"key", "token"}
+
+// secretMaps hold only credentials, such as the headers sent with a
+// webhook, which often carry an Authorization value.
+var secretMaps = []string{"headers"}
+
+func isSecretKey(key string) bool {
+	if slices.Contains(secretKeys, key) {
+		return true
+	}
+	for _, suffix := range secretSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Document returns the config as the generic map config.yaml decodes to,
+// keyed like the YAML file, with secrets replaced by RedactedValue. It is
+// what the API and dashboard show and edit.
+func (c *Config) Document() (map[string]interface{}, error) {
+	doc, err := c.rawDocument()
+	if err != nil {
+		return nil, err
+	}
+	redactSecrets(doc)
+	return doc, nil
+}
+
+func (c *Config) rawDocument() (map[string]interface{}, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode config: %w", err)
+	}
+	return doc, nil
+}
+
+func redactSecrets(val interface{}) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if s, ok := child.(string); ok && s != "" && isSecretKey(key) {
+				v[key] = RedactedValue
+				continue
+			}
+			if secrets, ok := child.(map[string]interface{}); ok && slices.Contains(secretMaps, key) {
+				for name, value := range secrets {
+					if s, ok := value.(string); ok && s != "" {
+						secrets[name] = RedactedValue
+					}
+				}
+				continue
+			}
+			redactSecrets(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			redactSecrets(child)
+		}
+	}
+}
+
+// ParseDocument builds and validates a config from a document in the shape
+// Document returns. Secrets left as RedactedValue keep their values from
+// current, matched by their path in the document.
+func ParseDocument(doc map[string]interface{}, current *Config) (*Config, error) {
+	if current != nil {
+		old, err := current.rawDocument()
+		if err != nil {
+			return nil, err
+		}
+		restoreSecrets(doc, old)
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+func restoreSecrets(val, old interface{}) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		oldMap, _ := old.(map[string]interface{})
+		for key, child := range v {
+			if child == RedactedValue && isSecretKey(key) {
+				restoreSecret(v, oldMap, key)
+				continue
+			}
+			if secrets, ok := child.(map[string]interface{}); ok && slices.Contains(secretMaps, key) {
+				oldSecrets, _ := oldMap[key].(map[string]interface{})
+				for name, value := range secrets {
+					if value == RedactedValue {
+						restoreSecret(secrets, oldSecrets, name)
+					}
+				}
+				continue
+			}
+			restoreSecrets(child, oldMap[key])
+		}
+	case []interface{}:
+		oldList, _ := old.([]interface{})
+		for i, child := range v {
+			var prev interface{}
+			if i < len(oldList) {
+				prev = oldList[i]
+			}
+			restoreSecrets(child, prev)
+		}
+	}
+}
+
+// restoreSecret puts back the configured value of a redacted key, or drops
+// the key if nothing was configured under it.
+func restoreSecret(m, old map[string]interface{}, key string) {
+	if prev, ok := old[key]; ok {
+		m[key] = prev
+	} else {
+		delete(m, key)
+	}
+}
+
+// RestartRequired lists the settings that differ between c and next and
+// only take effect when the daemon restarts.
+func (c *Config) RestartRequired(next *Config) []string {
+	var fields []string
+	if c.HTTP.Port != next.HTTP.Port {
+		fields = append(fields, "http.port")
+	}
+	if c.HTTP.BindAddress != next.HTTP.BindAddress {
+		fields = append(fields, "http.bind_address")
+	}
+	if c.HTTP.TLS != next.HTTP.TLS {
+		fields = append(fields, "http.tls")
+	}
+	if c.HTTP.GRPCPort != next.HTTP.GRPCPort {
+		fields = append(fields, "http.grpc_port")
+	}
+	if c.HTTP.Socket != next.HTTP.Socket {
+		fields = append(fields, "http.socket")
+	}
+	if c.Storage != next.Storage {
+		fields = append(fields, "storage")
+	}
+	if c.Daemon.AutoRepair != next.Daemon.AutoRepair {
+		fields = append(fields, "daemon.auto_repair")
+	}
+	if c.Daemon.QueryCache != next.Daemon.QueryCache {
+		fields = append(fields, "daemon.query_cache")
+	}
+	if c.Daemon.WriteBuffer != next.Daemon.WriteBuffer {
+		fields = append(fields, "daemon.write_buffer")
+	}
+	return fields
+}
+
+// FileVersion returns a short hash of config.yaml, which changes whenever
+// the file does. Editors send it back so a save does not overwrite changes
+// made in the meantime.
+func FileVersion() (string, error) {
+	path, err := ConfigPath()
+	if err != nil {
+		return "", fmt.Errorf("get config path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read config file: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12], nil
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocumentRedactsSecrets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Storage.Postgres.DSN = "postgres://devlog:hunter2@db/devlog"
+	cfg.Plugins["llm"] = ComponentConfig{Enabled: false, Config: map[string]interface{}{
+		"api_key":        "sk-secret",
+		"context_tokens": 8192,
+		"providers":      []interface{}{map[string]interface{}{"name": "openai", "api_key": "sk-other"}},
+	}}
+
+	doc, err := cfg.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := doc["plugins"].(map[string]interface{})["llm"].(map[string]interface{})
+	if llm["api_key"] != RedactedValue || llm["context_tokens"] != 8192 {
+		t.Errorf("llm = %v, want api_key redacted and context_tokens kept", llm)
+	}
+	if provider := llm["providers"].([]interface{})[0].(map[string]interface{}); provider["api_key"] != RedactedValue {
+		t.Errorf("provider api_key = %v, want it redacted", provider["api_key"])
+	}
+	if dsn := doc["storage"].(map[string]interface{})["postgres"].(map[string]interface{})["dsn"]; dsn != RedactedValue {
+		t.Errorf("dsn = %v, want it redacted", dsn)
+	}
+
+	// Sending the document back keeps the secrets and applies the edits.
+	llm["context_tokens"] = 16384
+	llm["providers"].([]interface{})[0].(map[string]interface{})["api_key"] = "sk-new"
+	next, err := ParseDocument(doc, cfg)
+	if err != nil {
+		t.Fatalf("ParseDocument() error: %v", err)
+	}
+	got := next.Plugins["llm"].Config
+	if got["api_key"] != "sk-secret" || got["context_tokens"] != 16384 {
+		t.Errorf("llm config = %v", got)
+	}
+	if provider := got["providers"].([]interface{})[0].(map[string]interface{}); provider["api_key"] != "sk-new" {
+		t.Errorf("provider api_key = %v, want the new key", provider["api_key"])
+	}
+	if next.Storage.Postgres.DSN != cfg.Storage.Postgres.DSN {
+		t.Errorf("dsn = %q, want the configured one", next.Storage.Postgres.DSN)
+	}
+}
+
+func TestDocumentRedactsKeysAndHeaders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Plugins["sync"] = ComponentConfig{Config: map[string]interface{}{
+		"key":   "c3luYy1rZXktc3luYy1rZXktc3luYy1rZXktc3luYy0=",
+		"peers": []interface{}{"laptop"},
+	}}
+	cfg.Plugins["backup"] = ComponentConfig{Config: map[string]interface{}{
+		"key":         "YmFja3VwLWtleS1iYWNrdXAta2V5LWJhY2t1cC1rZXk=",
+		"destination": "/backups",
+	}}
+	cfg.Plugins["webhooks"] = ComponentConfig{Config: map[string]interface{}{
+		"targets": []interface{}{map[string]interface{}{
+			"url":     "https://hooks.example.com/devlog",
+			"headers": map[string]interface{}{"Authorization": "Bearer abc123", "X-Team": "platform"},
+		}},
+	}}
+
+	doc, err := cfg.Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plugins := doc["plugins"].(map[string]interface{})
+	sync := plugins["sync"].(map[string]interface{})
+	backup := plugins["backup"].(map[string]interface{})
+	target := plugins["webhooks"].(map[string]interface{})["targets"].([]interface{})[0].(map[string]interface{})
+	headers := target["headers"].(map[string]interface{})
+	if sync["key"] != RedactedValue || backup["key"] != RedactedValue {
+		t.Errorf("sync key = %v, backup key = %v, want both redacted", sync["key"], backup["key"])
+	}
+	if backup["destination"] != "/backups" || target["url"] != "https://hooks.example.com/devlog" {
+		t.Errorf("backup = %v, target = %v, want the other settings kept", backup, target)
+	}
+	if headers["Authorization"] != RedactedValue || headers["X-Team"] != RedactedValue {
+		t.Errorf("headers = %v, want every value redacted", headers)
+	}
+
+	// Redacted values round-trip; a new header value replaces the old one.
+	headers["X-Team"] = "infra"
+	next, err := ParseDocument(doc, cfg)
+	if err != nil {
+		t.Fatalf("ParseDocument() error: %v", err)
+	}
+	if got := next.Plugins["sync"].Config["key"]; got != cfg.Plugins["sync"].Config["key"] {
+		t.Errorf("sync key = %v, want the configured one", got)
+	}
+	if got := next.Plugins["backup"].Config["key"]; got != cfg.Plugins["backup"].Config["key"] {
+		t.Errorf("backup key = %v, want the configured one", got)
+	}
+	nextTarget := next.Plugins["webhooks"].Config["targets"].([]interface{})[0].(map[string]interface{})
+	want := map[string]interface{}{"Authorization": "Bearer abc123", "X-Team": "infra"}
+	if got := nextTarget["headers"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("headers = %v, want %v", got, want)
+	}
+}
+
+func TestParseDocumentValidates(t *testing.T) {
+	doc, err := DefaultConfig().Document()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc["http"].(map[string]interface{})["port"] = 80
+	if _, err := ParseDocument(doc, nil); err == nil {
+		t.Error("ParseDocument() accepted a privileged port")
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	cfg := DefaultConfig()
+	next := DefaultConfig()
+	next.HTTP.Port = 9000
+	next.Modules["git"] = ComponentConfig{Enabled: true}
+	next.Daemon.QueryCache.Size = 10
+	next.Daemon.Resources.MaxHeapMB = 512
+
+	if got := cfg.RestartRequired(next); !reflect.DeepEqual(got, []string{"http.port", "daemon.query_cache"}) {
+		t.Errorf("RestartRequired() = %v", got)
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"devlog/internal/events"
+)
+
+const (
+	FilterInclude = "include"
+	FilterExclude = "exclude"
+)
+
+// FilterRule is one entry of the top-level filters section. Every condition
+// that is set must match for the rule to apply. Module, type, repo, branch
+// and payload values are globs (* and ?) or "re:" regular expressions;
+// command uses the ignore_list syntax. Repo is matched like an excluded
+// repo: against the repo name and the working directory and its parents.
+type FilterRule struct {
+	Module  string            `yaml:"module,omitempty"`
+	Action  string            `yaml:"action,omitempty"`
+	Type    string            `yaml:"type,omitempty"`
+	Command string            `yaml:"command,omitempty"`
+	Repo    string            `yaml:"repo,omitempty"`
+	Branch  string            `yaml:"branch,omitempty"`
+	Payload map[string]string `yaml:"payload,omitempty"`
+}
+
+type FilterDecision struct {
+	Capture bool
+	Index   int
+	Rule    *FilterRule
+	Reason  string
+}
+
+var filterPatternCache sync.Map
+
+func (r FilterRule) action() string {
+	if r.Action == "" {
+		return FilterExclude
+	}
+	return r.Action
+}
+
+func (r FilterRule) appliesToModule(source string) bool {
+	return r.Module == "" || matchFilterPattern(r.Module, source)
+}
+
+func (r FilterRule) Matches(evt *events.Event) bool {
+	if !r.appliesToModule(evt.Source) {
+		return false
+	}
+	if r.Type != "" && !matchFilterPattern(r.Type, evt.Type) {
+		return false
+	}
+	if r.Command != "" {
+		command, _ := evt.Payload["command"].(string)
+		if !IsCommandIgnored([]string{r.Command}, command) {
+			return false
+		}
+	}
+	if r.Repo != "" && !matchRepoPattern(ExpandHome(r.Repo), evt) {
+		return false
+	}
+	if r.Branch != "" && !matchFilterPattern(r.Branch, evt.Branch) {
+		return false
+	}
+	for key, pattern := range r.Payload {
+		value, ok := evt.Payload[key]
+		if !ok || !matchFilterPattern(pattern, fmt.Sprint(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r FilterRule) String() string {
+	parts := []string{r.action()}
+	if r.Module != "" {
+		parts = append(parts, "module="+r.Module)
+	}
+	if r.Type != "" {
+		parts = append(parts, "type="+r.Type)
+	}
+	if r.Command != "" {
+		parts = append(parts, fmt.Sprintf("command=%q", r.Command))
+	}
+	if r.Repo != "" {
+		parts = append(parts, "repo="+r.Repo)
+	}
+	if r.Branch != "" {
+		parts = append(parts, "branch="+r.Branch)
+	}
+	keys := make([]string, 0, len(r.Payload))
+	for key := range r.Payload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("payload.%s=%s", key, r.Payload[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (r FilterRule) Validate() error {
+	switch r.action() {
+	case FilterInclude, FilterExclude:
+	default:
+		return fmt.Errorf("action must be %q or %q", FilterInclude, FilterExclude)
+	}
+
+	if r.Command != "" {
+		if err := ValidateIgnorePattern(r.Command); err != nil {
+			return fmt.Errorf("command: %w", err)
+		}
+	}
+
+	patterns := map[string]string{"module": r.Module, "type": r.Type, "repo": ExpandHome(r.Repo), "branch": r.Branch}
+	for key, pattern := range r.Payload {
+		patterns["payload."+key] = pattern
+	}
+	for field, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+		if _, err := compileFilterPattern(pattern); err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// FilterEvent applies the filters section. When include rules exist for an
+// event's module the event must match one of them; any matching exclude rule
+// then drops it.
+func (c *Config) FilterEvent(evt *events.Event) FilterDecision {
+	hasInclude := false
+	included := false
+	for i := range c.Filters {
+		rule := &c.Filters[i]
+		if rule.action() != FilterInclude || !rule.appliesToModule(evt.Source) {
+			continue
+		}
+		hasInclude = true
+		if rule.Matches(evt) {
+			included = true
+			break
+		}
+	}
+	if hasInclude && !included {
+		return FilterDecision{Index: -1, Reason: fmt.Sprintf("no include rule matches %s events", evt.Source)}
+	}
+
+	for i := range c.Filters {
+		rule := &c.Filters[i]
+		if rule.action() == FilterExclude && rule.Matches(evt) {
+			return FilterDecision{Index: i, Rule: rule, Reason: fmt.Sprintf("filters[%d]: %s", i, rule)}
+		}
+	}
+
+	return FilterDecision{Capture: true, Index: -1}
+}
+
+func (c *Config) validateFilters() error {
+	for i, rule := range c.Filters {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("filters[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func matchFilterPattern(pattern, value string) bool {
+	re, err := compileFilterPattern(pattern)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+func compileFilterPattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := filterPatternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	expr := "^" + globToRegexp(pattern) + "$"
+	if strings.HasPrefix(pattern, regexIgnorePrefix) {
+		expr = strings.TrimPrefix(pattern, regexIgnorePrefix)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	filterPatternCache.Store(pattern, re)
+	return re, nil
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory.
+func ExpandHome(pattern string) string {
+	if pattern != "~" && !strings.HasPrefix(pattern, "~/") {
+		return pattern
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return pattern
+	}
+	return filepath.Join(home, strings.TrimPrefix(pattern, "~"))
+}
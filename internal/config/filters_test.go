@@ -0,0 +1,184 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"devlog/internal/events"
+)
+
+func filterTestEvent(source, eventType, repo, branch string, payload map[string]interface{}) *events.Event {
+	event := events.NewEvent(source, eventType)
+	event.Repo = repo
+	event.Branch = branch
+	for k, v := range payload {
+		event.Payload[k] = v
+	}
+	return event
+}
+
+func workdir(path string) map[string]interface{} {
+	return map[string]interface{}{"workdir": path}
+}
+
+func TestFilterRuleMatches(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	tests := []struct {
+		name  string
+		rule  FilterRule
+		event *events.Event
+		want  bool
+	}{
+		{
+			"module only",
+			FilterRule{Module: "tmux"},
+			filterTestEvent("tmux", "session_create", "", "", nil),
+			true,
+		},
+		{
+			"other module",
+			FilterRule{Module: "tmux"},
+			filterTestEvent("shell", "command", "", "", nil),
+			false,
+		},
+		{
+			"command uses ignore_list syntax",
+			FilterRule{Module: "shell", Command: "aws sso *"},
+			filterTestEvent("shell", "command", "", "", map[string]interface{}{"command": "aws sso login"}),
+			true,
+		},
+		{
+			"command regex",
+			FilterRule{Command: "re:--token"},
+			filterTestEvent("kubectl", "kubectl_get", "", "", map[string]interface{}{"command": "kubectl get pods --token abc"}),
+			true,
+		},
+		{
+			"repo glob with home matches workdir parent",
+			FilterRule{Repo: "~/scratch/*"},
+			filterTestEvent("git", "commit", "demo", "main", map[string]interface{}{"workdir": filepath.Join(home, "scratch", "demo", "cmd")}),
+			true,
+		},
+		{
+			"repo glob matches repo name",
+			FilterRule{Repo: "devlog-*"},
+			filterTestEvent("git", "commit", "devlog-web", "main", nil),
+			true,
+		},
+		{
+			"repo path is not matched against repo name",
+			FilterRule{Repo: "~/scratch/*"},
+			filterTestEvent("git", "commit", "demo", "main", nil),
+			false,
+		},
+		{
+			"repo glob no match",
+			FilterRule{Repo: "~/scratch/*"},
+			filterTestEvent("git", "commit", "app", "main", map[string]interface{}{"cwd": "/srv/app"}),
+			false,
+		},
+		{
+			"branch regex",
+			FilterRule{Branch: "re:^(wip|tmp)/"},
+			filterTestEvent("git", "commit", "/srv/app", "tmp/experiment", nil),
+			true,
+		},
+		{
+			"payload glob",
+			FilterRule{Module: "kubectl", Payload: map[string]string{"context": "prod-*"}},
+			filterTestEvent("kubectl", "kubectl_get", "", "", map[string]interface{}{"context": "prod-eu"}),
+			true,
+		},
+		{
+			"payload missing field",
+			FilterRule{Payload: map[string]string{"context": "*"}},
+			filterTestEvent("kubectl", "kubectl_get", "", "", nil),
+			false,
+		},
+		{
+			"payload number",
+			FilterRule{Payload: map[string]string{"exit_code": "130"}},
+			filterTestEvent("shell", "command", "", "", map[string]interface{}{"exit_code": float64(130)}),
+			true,
+		},
+		{
+			"all conditions must match",
+			FilterRule{Module: "git", Type: "commit", Branch: "main"},
+			filterTestEvent("git", "push", "", "main", nil),
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterEvent(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Filters = []FilterRule{
+		{Module: "git", Action: FilterInclude, Repo: "/work/*"},
+		{Module: "git", Action: FilterInclude, Repo: "/oss/devlog"},
+		{Module: "git", Branch: "wip/*"},
+		{Module: "clipboard"},
+	}
+
+	tests := []struct {
+		name    string
+		event   *events.Event
+		capture bool
+		index   int
+	}{
+		{"included repo", filterTestEvent("git", "commit", "api", "main", workdir("/work/api")), true, -1},
+		{"second include rule", filterTestEvent("git", "commit", "devlog", "main", workdir("/oss/devlog/internal")), true, -1},
+		{"not included", filterTestEvent("git", "commit", "dotfiles", "main", workdir("/home/me/dotfiles")), false, -1},
+		{"included but excluded", filterTestEvent("git", "commit", "api", "wip/x", workdir("/work/api")), false, 2},
+		{"excluded module", filterTestEvent("clipboard", "copy", "", "", nil), false, 3},
+		{"no rules for module", filterTestEvent("shell", "command", "", "", nil), true, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := cfg.FilterEvent(tt.event)
+			if decision.Capture != tt.capture || decision.Index != tt.index {
+				t.Errorf("FilterEvent() = %+v, want capture=%v index=%d", decision, tt.capture, tt.index)
+			}
+			if !decision.Capture && decision.Reason == "" {
+				t.Error("dropped event has no reason")
+			}
+		})
+	}
+}
+
+func TestValidateFilters(t *testing.T) {
+	valid := DefaultConfig()
+	valid.Filters = []FilterRule{
+		{Module: "shell", Command: "re:^op "},
+		{Module: "git", Action: FilterInclude, Repo: "~/work/*"},
+	}
+	if err := valid.validateFilters(); err != nil {
+		t.Errorf("validateFilters() error: %v", err)
+	}
+
+	for name, rule := range map[string]FilterRule{
+		"unknown action": {Action: "drop"},
+		"bad command":    {Command: "re:("},
+		"bad branch":     {Branch: "re:[a-"},
+		"bad payload":    {Payload: map[string]string{"cwd": "re:("}},
+	} {
+		cfg := DefaultConfig()
+		cfg.Filters = []FilterRule{rule}
+		if err := cfg.validateFilters(); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"devlog/internal/tlscert"
+)
+
+const DefaultBindAddress = "127.0.0.1"
+
+// HTTPTLSConfig serves the API over HTTPS, either with the given certificate
+// and key or with a self-signed certificate devlog generates and keeps in
+// the data directory.
+type HTTPTLSConfig struct {
+	Cert       string `yaml:"cert,omitempty"`
+	Key        string `yaml:"key,omitempty"`
+	SelfSigned bool   `yaml:"self_signed,omitempty"`
+}
+
+// SocketConfig is the Unix socket the daemon serves the API on next to its
+// TCP port. Local clients (the CLI, shell hooks and pkg/devlog) use it
+// whenever it exists. Only the user running the daemon can connect to it,
+// so requests over it need no API token.
+type SocketConfig struct {
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Path defaults to devlog.sock in the data directory.
+	Path string `yaml:"path,omitempty"`
+}
+
+// RouteLimit caps how fast one client may call a group of API routes and how
+// large a request body may be. Requests are counted per remote address with a
+// token bucket that refills at RequestsPerSecond up to Burst. Zero fields
+// fall back to the group's default.
+type RouteLimit struct {
+	RequestsPerSecond float64 `yaml:"requests_per_second,omitempty"`
+	Burst             int     `yaml:"burst,omitempty"`
+	MaxBodyBytes      int64   `yaml:"max_body_bytes,omitempty"`
+}
+
+const (
+	RouteGroupIngest   = "ingest"
+	RouteGroupBatch    = "batch"
+	RouteGroupWebhooks = "webhooks"
+	RouteGroupAPI      = "api"
+)
+
+// RouteGroups lists the route groups and their default limits. Hooks send
+// single events, so ingest allows the most requests; batch bodies carry up
+// to 500 events.
+var RouteGroups = map[string]RouteLimit{
+	RouteGroupIngest:   {RequestsPerSecond: 200, Burst: 400, MaxBodyBytes: 1 << 20},
+	RouteGroupBatch:    {RequestsPerSecond: 20, Burst: 40, MaxBodyBytes: 8 << 20},
+	RouteGroupWebhooks: {RequestsPerSecond: 20, Burst: 60, MaxBodyBytes: 1 << 20},
+	RouteGroupAPI:      {RequestsPerSecond: 50, Burst: 100, MaxBodyBytes: 64 << 10},
+}
+
+// RouteLimit returns the limits for a route group with defaults applied.
+func (h HTTPConfig) RouteLimit(group string) RouteLimit {
+	limit := RouteGroups[group]
+	override, ok := h.Limits[group]
+	if !ok {
+		return limit
+	}
+	if override.RequestsPerSecond > 0 {
+		limit.RequestsPerSecond = override.RequestsPerSecond
+	}
+	if override.Burst > 0 {
+		limit.Burst = override.Burst
+	}
+	if override.MaxBodyBytes > 0 {
+		limit.MaxBodyBytes = override.MaxBodyBytes
+	}
+	return limit
+}
+
+func (h HTTPConfig) TLSEnabled() bool {
+	return h.TLS.Cert != "" || h.TLS.SelfSigned
+}
+
+func (h HTTPConfig) bindAddress() string {
+	if h.BindAddress == "" {
+		return DefaultBindAddress
+	}
+	return h.BindAddress
+}
+
+// ListenAddr is the address the daemon's HTTP server listens on.
+func (h HTTPConfig) ListenAddr() string {
+	return net.JoinHostPort(h.bindAddress(), strconv.Itoa(h.Port))
+}
+
+// GRPCListenAddr is the address the gRPC ingestion endpoint listens on.
+func (h HTTPConfig) GRPCListenAddr() string {
+	return net.JoinHostPort(h.bindAddress(), strconv.Itoa(h.GRPCPort))
+}
+
+// IsLoopback reports whether the API is only reachable from this machine.
+func (h HTTPConfig) IsLoopback() bool {
+	bind := h.bindAddress()
+	if bind == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(bind)
+	return ip != nil && ip.IsLoopback()
+}
+
+// LocalURL is how clients on this machine reach the daemon. Wildcard binds
+// are reached through loopback; a specific address must be used as is.
+func (h HTTPConfig) LocalURL() string {
+	host := h.bindAddress()
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsUnspecified()) {
+		host = DefaultBindAddress
+	}
+
+	scheme := "http"
+	if h.TLSEnabled() {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, strconv.Itoa(h.Port))
+}
+
+// TLSFiles returns the certificate and key to serve, expanding ~ in
+// configured paths. For self_signed they are the generated files under the
+// data directory, which may not exist yet.
+func (h HTTPConfig) TLSFiles() (string, string, error) {
+	if h.TLS.Cert != "" {
+		return ExpandHome(h.TLS.Cert), ExpandHome(h.TLS.Key), nil
+	}
+	dir, err := TLSDir()
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), nil
+}
+
+func TLSDir() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "tls"), nil
+}
+
+// SocketPath returns where the daemon's Unix socket lives, or "" when the
+// socket is disabled.
+func (h HTTPConfig) SocketPath() (string, error) {
+	if h.Socket.Disabled {
+		return "", nil
+	}
+	if h.Socket.Path != "" {
+		return ExpandHome(h.Socket.Path), nil
+	}
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "devlog.sock"), nil
+}
+
+// Client returns an HTTP client for LocalURL. While the daemon's Unix
+// socket exists, requests to LocalURL go over it instead of TCP. With TLS
+// it trusts exactly the daemon's certificate, so self-signed certificates
+// work without adding them to the system trust store and without relying
+// on hostnames.
+func (h HTTPConfig) Client(timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout, Transport: h.tcpTransport()}
+
+	if socket, err := h.SocketPath(); err == nil && socket != "" {
+		if info, err := os.Stat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			local, _ := url.Parse(h.LocalURL())
+			client.Transport = &socketTransport{
+				local:  local.Host,
+				socket: &http.Transport{DialContext: dialSocket(socket)},
+				tcp:    client.Transport,
+			}
+		}
+	}
+	return client
+}
+
+func dialSocket(path string) func(ctx context.Context, _, _ string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+}
+
+// socketTransport sends requests for the daemon's LocalURL over its Unix
+// socket, which speaks plain HTTP even when the TCP port uses TLS. Other
+// hosts, such as a base URL a pkg/devlog caller set, go over TCP.
+type socketTransport struct {
+	local  string
+	socket *http.Transport
+	tcp    http.RoundTripper
+}
+
+func (t *socketTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.local {
+		return t.tcp.RoundTrip(req)
+	}
+	if req.URL.Scheme != "http" {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = "http"
+	}
+	return t.socket.RoundTrip(req)
+}
+
+func (h HTTPConfig) tcpTransport() http.RoundTripper {
+	if !h.TLSEnabled() {
+		return http.DefaultTransport
+	}
+
+	certPath, _, err := h.TLSFiles()
+	if err != nil {
+		return http.DefaultTransport
+	}
+	cert, err := tlscert.Load(certPath)
+	if err != nil {
+		return http.DefaultTransport
+	}
+	pinned := cert.Raw
+
+	return &http.Transport{
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			// Verification is replaced by the pin below.
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				if len(rawCerts) == 0 || !bytes.Equal(rawCerts[0], pinned) {
+					return fmt.Errorf("daemon certificate does not match %s", certPath)
+				}
+				return nil
+			},
+		},
+	}
+}
+
+func (h HTTPConfig) validate() error {
+	if h.BindAddress != "" && h.BindAddress != "localhost" && net.ParseIP(h.BindAddress) == nil {
+		return fmt.Errorf("http bind_address must be an IP address or 'localhost'")
+	}
+	if (h.TLS.Cert == "") != (h.TLS.Key == "") {
+		return fmt.Errorf("http tls cert and key must be set together")
+	}
+	if h.TLS.Cert != "" && h.TLS.SelfSigned {
+		return fmt.Errorf("http tls self_signed cannot be combined with cert and key")
+	}
+	if h.GRPCPort != 0 && (h.GRPCPort < 1024 || h.GRPCPort > 65535) {
+		return fmt.Errorf("http grpc_port must be between 1024 and 65535 (privileged ports not allowed)")
+	}
+	if h.GRPCPort == h.Port {
+		return fmt.Errorf("http grpc_port must differ from port")
+	}
+	for group, limit := range h.Limits {
+		if _, ok := RouteGroups[group]; !ok {
+			return fmt.Errorf("http limits: unknown route group %q (use ingest, batch, webhooks or api)", group)
+		}
+		if limit.RequestsPerSecond < 0 || limit.Burst < 0 || limit.MaxBodyBytes < 0 {
+			return fmt.Errorf("http limits.%s: values must not be negative", group)
+		}
+	}
+	return nil
+}
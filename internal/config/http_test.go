@@ -0,0 +1,184 @@
+package config
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"devlog/internal/tlscert"
+)
+
+func TestHTTPConfigAddresses(t *testing.T) {
+	tests := []struct {
+		http     HTTPConfig
+		listen   string
+		local    string
+		loopback bool
+	}{
+		{HTTPConfig{Port: 8573}, "127.0.0.1:8573", "http://127.0.0.1:8573", true},
+		{HTTPConfig{Port: 8573, BindAddress: "0.0.0.0"}, "0.0.0.0:8573", "http://127.0.0.1:8573", false},
+		{HTTPConfig{Port: 8573, BindAddress: "::"}, "[::]:8573", "http://127.0.0.1:8573", false},
+		{HTTPConfig{Port: 8573, BindAddress: "192.168.1.20", TLS: HTTPTLSConfig{SelfSigned: true}}, "192.168.1.20:8573", "https://192.168.1.20:8573", false},
+		{HTTPConfig{Port: 9000, BindAddress: "localhost"}, "localhost:9000", "http://127.0.0.1:9000", true},
+	}
+	for _, tt := range tests {
+		if got := tt.http.ListenAddr(); got != tt.listen {
+			t.Errorf("ListenAddr(%+v) = %s, want %s", tt.http, got, tt.listen)
+		}
+		if got := tt.http.LocalURL(); got != tt.local {
+			t.Errorf("LocalURL(%+v) = %s, want %s", tt.http, got, tt.local)
+		}
+		if got := tt.http.IsLoopback(); got != tt.loopback {
+			t.Errorf("IsLoopback(%+v) = %v, want %v", tt.http, got, tt.loopback)
+		}
+	}
+}
+
+func TestHTTPConfigValidate(t *testing.T) {
+	invalid := map[string]HTTPConfig{
+		"hostname bind":      {Port: 8573, BindAddress: "devbox"},
+		"cert without key":   {Port: 8573, TLS: HTTPTLSConfig{Cert: "/tmp/cert.pem"}},
+		"cert + self_signed": {Port: 8573, TLS: HTTPTLSConfig{Cert: "c", Key: "k", SelfSigned: true}},
+		"unknown limit":      {Port: 8573, Limits: map[string]RouteLimit{"search": {Burst: 5}}},
+		"negative limit":     {Port: 8573, Limits: map[string]RouteLimit{"ingest": {RequestsPerSecond: -1}}},
+		"privileged grpc":    {Port: 8573, GRPCPort: 443},
+		"grpc on http port":  {Port: 8573, GRPCPort: 8573},
+	}
+	for name, httpCfg := range invalid {
+		cfg := DefaultConfig()
+		cfg.HTTP = httpCfg
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.HTTP.BindAddress = "0.0.0.0"
+	cfg.HTTP.TLS.SelfSigned = true
+	cfg.HTTP.GRPCPort = 8574
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+}
+
+func TestHTTPConfigRouteLimit(t *testing.T) {
+	h := HTTPConfig{Limits: map[string]RouteLimit{
+		RouteGroupIngest: {RequestsPerSecond: 5, MaxBodyBytes: 2048},
+	}}
+
+	got := h.RouteLimit(RouteGroupIngest)
+	want := RouteLimit{RequestsPerSecond: 5, Burst: RouteGroups[RouteGroupIngest].Burst, MaxBodyBytes: 2048}
+	if got != want {
+		t.Errorf("RouteLimit(ingest) = %+v, want %+v", got, want)
+	}
+	if got := h.RouteLimit(RouteGroupAPI); got != RouteGroups[RouteGroupAPI] {
+		t.Errorf("RouteLimit(api) = %+v, want defaults", got)
+	}
+}
+
+func TestHTTPConfigClientPinsCertificate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir, err := TLSDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tlscert.Generate(dir, []string{"127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	certPath, keyPath := tlscert.Paths(dir)
+
+	serve := func(certPath, keyPath string) *httptest.Server {
+		pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{pair}}
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	client := HTTPConfig{TLS: HTTPTLSConfig{SelfSigned: true}}.Client(5 * time.Second)
+
+	resp, err := client.Get(serve(certPath, keyPath).URL)
+	if err != nil {
+		t.Fatalf("request to pinned certificate failed: %v", err)
+	}
+	resp.Body.Close()
+
+	otherDir := filepath.Join(t.TempDir(), "other")
+	if err := tlscert.Generate(otherDir, []string{"127.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(serve(tlscert.Paths(otherDir)).URL); err == nil {
+		t.Error("client accepted a certificate other than the pinned one")
+	}
+}
+
+func TestHTTPConfigClientUsesSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "devlog-sock")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "devlog.sock")
+
+	tcp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "tcp")
+	}))
+	defer tcp.Close()
+	tcpURL, _ := url.Parse(tcp.URL)
+	port, _ := strconv.Atoi(tcpURL.Port())
+	httpCfg := HTTPConfig{Port: port, Socket: SocketConfig{Path: socket}}
+
+	get := func(client *http.Client, target string) string {
+		t.Helper()
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if got := get(httpCfg.Client(time.Second), httpCfg.LocalURL()+"/api/v1/status"); got != "tcp" {
+		t.Errorf("without a socket: reached %q, want tcp", got)
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	socketServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "socket")
+	})}
+	go socketServer.Serve(listener)
+	defer socketServer.Close()
+
+	client := httpCfg.Client(time.Second)
+	if got := get(client, httpCfg.LocalURL()+"/api/v1/status"); got != "socket" {
+		t.Errorf("with a socket: reached %q, want socket", got)
+	}
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "other")
+	}))
+	defer other.Close()
+	if got := get(client, other.URL); got != "other" {
+		t.Errorf("other host: reached %q, want it over TCP", got)
+	}
+
+	httpCfg.Socket.Disabled = true
+	if got := get(httpCfg.Client(time.Second), httpCfg.LocalURL()+"/api/v1/status"); got != "tcp" {
+		t.Errorf("socket disabled: reached %q, want tcp", got)
+	}
+}
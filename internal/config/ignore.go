@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const regexIgnorePrefix = "re:"
+
+var ignorePatternCache sync.Map
+
+// IsCommandIgnored reports whether command matches any ignore_list entry.
+// A bare word matches the command name ("ls"), several words match as a
+// prefix ("git status"), * and ? are globs over the whole command
+// ("aws sso *"), and "re:" entries are regular expressions.
+func IsCommandIgnored(ignoreList []string, command string) bool {
+	command = strings.Join(strings.Fields(command), " ")
+	if command == "" {
+		return false
+	}
+
+	for _, pattern := range ignoreList {
+		re, err := compileIgnorePattern(pattern)
+		if err != nil {
+			continue
+		}
+		if re.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+func ValidateIgnorePattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("pattern must not be empty")
+	}
+	_, err := compileIgnorePattern(pattern)
+	return err
+}
+
+func compileIgnorePattern(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := ignorePatternCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	var expr string
+	switch {
+	case strings.HasPrefix(pattern, regexIgnorePrefix):
+		expr = strings.TrimPrefix(pattern, regexIgnorePrefix)
+	case strings.ContainsAny(pattern, "*?"):
+		expr = "^" + globToRegexp(strings.Join(strings.Fields(pattern), " ")) + "$"
+	default:
+		expr = "^" + regexp.QuoteMeta(strings.Join(strings.Fields(pattern), " ")) + "(\\s|$)"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	ignorePatternCache.Store(pattern, re)
+	return re, nil
+}
+
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package config
+
+import "testing"
+
+func TestIsCommandIgnored(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		command string
+		want    bool
+	}{
+		{"bare word matches command name", "ls", "ls -la", true},
+		{"bare word matches alone", "ls", "ls", true},
+		{"bare word is not a prefix match", "ls", "lsof -i", false},
+		{"multi-word prefix", "git status", "git status -s", true},
+		{"multi-word prefix needs word boundary", "git status", "git statusx", false},
+		{"multi-word prefix different subcommand", "git status", "git commit", false},
+		{"glob suffix", "aws sso *", "aws sso login --profile dev", true},
+		{"glob does not match other subcommands", "aws sso *", "aws s3 ls", false},
+		{"glob question mark", "k?", "k9", true},
+		{"glob matches slashes", "cat *.env", "cat ./config/.env", true},
+		{"glob tolerates extra whitespace", "aws  sso *", "aws   sso   login", true},
+		{"regex", "re:^op (read|item get)", "op read op://vault/item", true},
+		{"regex no match", "re:^op (read|item get)", "op signin", false},
+		{"regex unanchored", "re:--password", "mysql -u root --password=hunter2", true},
+		{"invalid regex never matches", "re:([", "anything", false},
+		{"empty command", "ls", "   ", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsCommandIgnored([]string{tt.pattern}, tt.command); got != tt.want {
+				t.Errorf("IsCommandIgnored(%q, %q) = %v, want %v", tt.pattern, tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateIgnorePattern(t *testing.T) {
+	for _, pattern := range []string{"ls", "aws sso *", "re:^kubectl .*secret"} {
+		if err := ValidateIgnorePattern(pattern); err != nil {
+			t.Errorf("ValidateIgnorePattern(%q) error: %v", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", "  ", "re:(unclosed"} {
+		if err := ValidateIgnorePattern(pattern); err == nil {
+			t.Errorf("ValidateIgnorePattern(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestShellIgnoreListAddRemove(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Modules["shell"] = ComponentConfig{Enabled: true}
+
+	cfg.AddToShellIgnoreList("ls", "aws sso *")
+	cfg.AddToShellIgnoreList("re:^op ", "ls")
+	list := cfg.ShellIgnoreList()
+	if len(list) != 3 || list[0] != "ls" || list[1] != "aws sso *" || list[2] != "re:^op " {
+		t.Fatalf("got %v, want [ls aws sso * re:^op ]", list)
+	}
+
+	if cfg.ShouldCaptureCommand("aws sso login") {
+		t.Error("expected glob pattern to be applied by ShouldCaptureCommand")
+	}
+	if cfg.ShouldCaptureCommand("op read secret") {
+		t.Error("expected regex pattern to be applied by ShouldCaptureCommand")
+	}
+
+	cfg.RemoveFromShellIgnoreList("aws sso *")
+	list = cfg.ShellIgnoreList()
+	if len(list) != 2 || list[0] != "ls" || list[1] != "re:^op " {
+		t.Fatalf("got %v after remove, want [ls re:^op ]", list)
+	}
+	if !cfg.ShouldCaptureCommand("aws sso login") {
+		t.Error("expected aws sso login to be captured after removing the pattern")
+	}
+}
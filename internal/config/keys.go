@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Get returns the value at a dotted path into the config document, such as
+// "modules.shell.ignore_list" or "http.tokens.0.name". Secrets are returned
+// as they are configured.
+func (c *Config) Get(path string) (interface{}, error) {
+	keys, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.rawDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	var val interface{} = doc
+	for i, key := range keys {
+		child, err := childAt(val, keys[:i], key)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			return nil, fmt.Errorf("%s is not set", strings.Join(keys[:i+1], "."))
+		}
+		val = child
+	}
+	return val, nil
+}
+
+// Set returns a copy of the config with the value at a dotted path
+// replaced, validated like a loaded config. Sections missing along the
+// path are created.
+func (c *Config) Set(path string, value interface{}) (*Config, error) {
+	return c.edit(path, func(parent interface{}, key string) error {
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[key] = value
+		case []interface{}:
+			i, _ := strconv.Atoi(key)
+			p[i] = value
+		}
+		return nil
+	})
+}
+
+// Unset returns a copy of the config without the value at a dotted path,
+// so it falls back to its default.
+func (c *Config) Unset(path string) (*Config, error) {
+	return c.edit(path, func(parent interface{}, key string) error {
+		p, ok := parent.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is a list item; set the whole list instead", path)
+		}
+		if _, ok := p[key]; !ok {
+			return fmt.Errorf("%s is not set", path)
+		}
+		delete(p, key)
+		return nil
+	})
+}
+
+// edit walks the document to the parent of path, creating missing
+// sections, and lets apply change the last key.
+func (c *Config) edit(path string, apply func(parent interface{}, key string) error) (*Config, error) {
+	keys, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := c.rawDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	var parent interface{} = doc
+	last := len(keys) - 1
+	for i, key := range keys[:last] {
+		child, err := childAt(parent, keys[:i], key)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			section, ok := parent.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s is empty", strings.Join(keys[:i+1], "."))
+			}
+			child = make(map[string]interface{})
+			section[key] = child
+		}
+		parent = child
+	}
+	if _, err := childAt(parent, keys[:last], keys[last]); err != nil {
+		return nil, err
+	}
+	if err := apply(parent, keys[last]); err != nil {
+		return nil, err
+	}
+	return ParseDocument(doc, nil)
+}
+
+func splitPath(path string) ([]string, error) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("invalid config path %q", path)
+		}
+	}
+	return keys, nil
+}
+
+// childAt returns the value under key in a map or list of the document,
+// or nil when a map has no such key. parent is the path of val, for errors.
+func childAt(val interface{}, parent []string, key string) (interface{}, error) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return v[key], nil
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil, fmt.Errorf("%s has %d items; %q is not one of their indexes", strings.Join(parent, "."), len(v), key)
+		}
+		return v[i], nil
+	default:
+		return nil, fmt.Errorf("%s is a value, not a section", strings.Join(parent, "."))
+	}
+}
+
+// DefaultDocument returns the document of a fresh config with the given
+// modules and plugins configured as their install commands would, but
+// disabled. The maps hold each component's DefaultConfig.
+func DefaultDocument(moduleDefaults, pluginDefaults map[string]interface{}) (map[string]interface{}, error) {
+	doc, err := DefaultConfig().rawDocument()
+	if err != nil {
+		return nil, err
+	}
+	for section, defaults := range map[string]map[string]interface{}{"modules": moduleDefaults, "plugins": pluginDefaults} {
+		components := make(map[string]interface{}, len(defaults))
+		for name, defaultCfg := range defaults {
+			component := make(map[string]interface{})
+			if defaultCfg != nil {
+				// Defaults are written to the config through their JSON
+				// keys; decoding the JSON as YAML keeps integers as ints,
+				// like a config read from disk.
+				data, err := json.Marshal(defaultCfg)
+				if err != nil {
+					return nil, fmt.Errorf("marshal %s defaults: %w", name, err)
+				}
+				if err := yaml.Unmarshal(data, &component); err != nil {
+					return nil, fmt.Errorf("decode %s defaults: %w", name, err)
+				}
+			}
+			component["enabled"] = false
+			components[name] = component
+		}
+		if len(components) > 0 {
+			doc[section] = components
+		}
+	}
+	return doc, nil
+}
+
+// DocumentChange is one setting that differs between two config
+// documents. Old is nil for a setting only the second one has, and New is
+// nil for one it lacks.
+type DocumentChange struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// DiffDocuments lists the settings that differ between base and doc, by
+// path. Lists are compared whole.
+func DiffDocuments(base, doc map[string]interface{}) []DocumentChange {
+	oldValues := make(map[string]interface{})
+	newValues := make(map[string]interface{})
+	flattenDocument("", base, oldValues)
+	flattenDocument("", doc, newValues)
+
+	paths := make(map[string]bool, len(oldValues)+len(newValues))
+	for path := range oldValues {
+		paths[path] = true
+	}
+	for path := range newValues {
+		paths[path] = true
+	}
+
+	var changes []DocumentChange
+	for path := range paths {
+		if !reflect.DeepEqual(oldValues[path], newValues[path]) {
+			changes = append(changes, DocumentChange{Path: path, Old: oldValues[path], New: newValues[path]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func flattenDocument(prefix string, val interface{}, out map[string]interface{}) {
+	m, ok := val.(map[string]interface{})
+	if !ok {
+		out[prefix] = val
+		return
+	}
+	for key, child := range m {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flattenDocument(path, child, out)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetSet(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Modules["shell"] = ComponentConfig{Enabled: true, Config: map[string]interface{}{
+		"ignore_list": []interface{}{"ls", "cd"},
+	}}
+
+	got, err := cfg.Get("modules.shell.ignore_list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []interface{}{"ls", "cd"}) {
+		t.Errorf("Get(ignore_list) = %v", got)
+	}
+	if got, _ := cfg.Get("modules.shell.ignore_list.1"); got != "cd" {
+		t.Errorf("Get(ignore_list.1) = %v, want cd", got)
+	}
+	if _, err := cfg.Get("modules.git"); err == nil {
+		t.Error("Get() of an unset section succeeded")
+	}
+	if _, err := cfg.Get("http.port.x"); err == nil {
+		t.Error("Get() below a value succeeded")
+	}
+
+	next, err := cfg.Set("daemon.query_cache.size", 64)
+	if err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	if next.Daemon.QueryCache.Size != 64 {
+		t.Errorf("query_cache.size = %d, want 64", next.Daemon.QueryCache.Size)
+	}
+	if !next.IsModuleEnabled("shell") || cfg.Daemon.QueryCache.Size != 0 {
+		t.Error("Set() lost other settings or changed the original config")
+	}
+
+	if _, err := cfg.Set("http.port", 80); err == nil {
+		t.Error("Set() accepted an invalid config")
+	}
+
+	next, err = next.Unset("modules.shell.ignore_list")
+	if err != nil {
+		t.Fatalf("Unset() error: %v", err)
+	}
+	if _, ok := next.Modules["shell"].Config["ignore_list"]; ok {
+		t.Error("Unset() kept ignore_list")
+	}
+	if _, err := next.Unset("modules.shell.ignore_list"); err == nil {
+		t.Error("Unset() of an unset value succeeded")
+	}
+}
+
+func TestDiffDocuments(t *testing.T) {
+	base, err := DefaultDocument(map[string]interface{}{
+		"shell": map[string]interface{}{"ignore_list": []string{"ls"}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.HTTP.Port = 9000
+	cfg.Modules["shell"] = ComponentConfig{Enabled: true, Config: map[string]interface{}{
+		"ignore_list": []interface{}{"ls"},
+	}}
+	cfg.Plugins["llm"] = ComponentConfig{Enabled: true}
+	doc, err := cfg.rawDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []DocumentChange{
+		{Path: "http.port", Old: 8573, New: 9000},
+		{Path: "modules.shell.enabled", Old: false, New: true},
+		{Path: "plugins.llm.enabled", New: true},
+	}
+	if got := DiffDocuments(base, doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffDocuments() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"devlog/internal/events"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PrivacyDrop = "drop"
+	PrivacyMask = "mask"
+)
+
+// RepoPathKeys are the payload fields holding a path inside the repository an
+// event came from.
+var RepoPathKeys = []string{"workdir", "cwd", "manifest_root", "repo_path"}
+
+type PrivacyConfig struct {
+	ExcludedRepos []ExcludedRepo `yaml:"excluded_repos,omitempty"`
+}
+
+// ExcludedRepo matches events from private repositories. Pattern uses the
+// filters glob syntax and is checked against the event's repo and against
+// its working directory and every parent of it.
+type ExcludedRepo struct {
+	Pattern string `yaml:"pattern"`
+	Action  string `yaml:"action,omitempty"`
+}
+
+// UnmarshalYAML accepts a bare pattern string as shorthand for a drop rule.
+func (r *ExcludedRepo) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Pattern = node.Value
+		return nil
+	}
+	type plain ExcludedRepo
+	return node.Decode((*plain)(r))
+}
+
+func (r ExcludedRepo) action() string {
+	if r.Action == "" {
+		return PrivacyDrop
+	}
+	return r.Action
+}
+
+func (r ExcludedRepo) Matches(evt *events.Event) bool {
+	return matchRepoPattern(ExpandHome(r.Pattern), evt)
+}
+
+// matchRepoPattern checks pattern against the event's repo and against its
+// working directory and every parent of it.
+func matchRepoPattern(pattern string, evt *events.Event) bool {
+	if evt.Repo != "" && matchFilterPattern(pattern, evt.Repo) {
+		return true
+	}
+	for _, key := range RepoPathKeys {
+		path, _ := evt.Payload[key].(string)
+		if path == "" || !filepath.IsAbs(path) {
+			continue
+		}
+		for dir := filepath.Clean(path); ; dir = filepath.Dir(dir) {
+			if matchFilterPattern(pattern, dir) {
+				return true
+			}
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	return false
+}
+
+func (r ExcludedRepo) Validate() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("pattern is required")
+	}
+	switch r.action() {
+	case PrivacyDrop, PrivacyMask:
+	default:
+		return fmt.Errorf("action must be %q or %q", PrivacyDrop, PrivacyMask)
+	}
+	if _, err := compileFilterPattern(ExpandHome(r.Pattern)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// PrivacyAction returns the action of the first excluded repo matching the
+// event, or "" when the event can be stored as is.
+func (c *Config) PrivacyAction(evt *events.Event) string {
+	for _, rule := range c.Privacy.ExcludedRepos {
+		if rule.Matches(evt) {
+			return rule.action()
+		}
+	}
+	return ""
+}
+
+func (c *Config) validatePrivacy() error {
+	for i, rule := range c.Privacy.ExcludedRepos {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("excluded_repos[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestPrivacyConfigYAML(t *testing.T) {
+	data := `
+privacy:
+  excluded_repos:
+    - "~/clients/*"
+    - pattern: "secret-*"
+      action: mask
+`
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal([]byte(data), cfg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	rules := cfg.Privacy.ExcludedRepos
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %+v", rules)
+	}
+	if rules[0].Pattern != "~/clients/*" || rules[0].action() != PrivacyDrop {
+		t.Errorf("shorthand rule = %+v, want drop", rules[0])
+	}
+	if rules[1].Pattern != "secret-*" || rules[1].action() != PrivacyMask {
+		t.Errorf("rule = %+v, want mask", rules[1])
+	}
+	if err := cfg.validatePrivacy(); err != nil {
+		t.Errorf("validatePrivacy() error: %v", err)
+	}
+}
+
+func TestPrivacyAction(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Privacy.ExcludedRepos = []ExcludedRepo{
+		{Pattern: "/work/clients/*"},
+		{Pattern: "secret-*", Action: PrivacyMask},
+	}
+
+	tests := []struct {
+		name string
+		repo string
+		path map[string]interface{}
+		want string
+	}{
+		{"repo name", "secret-api", nil, PrivacyMask},
+		{"workdir below excluded root", "acme", map[string]interface{}{"workdir": "/work/clients/acme/src/pkg"}, PrivacyDrop},
+		{"claude cwd", "", map[string]interface{}{"cwd": "/work/clients/acme"}, PrivacyDrop},
+		{"relative path ignored", "", map[string]interface{}{"workdir": "clients/acme"}, ""},
+		{"unrelated", "devlog", map[string]interface{}{"workdir": "/work/devlog"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt := filterTestEvent("git", "commit", tt.repo, "main", tt.path)
+			if got := cfg.PrivacyAction(evt); got != tt.want {
+				t.Errorf("PrivacyAction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePrivacy(t *testing.T) {
+	for _, rule := range []ExcludedRepo{
+		{},
+		{Pattern: "x", Action: "hide"},
+		{Pattern: "re:(unclosed"},
+	} {
+		cfg := DefaultConfig()
+		cfg.Privacy.ExcludedRepos = []ExcludedRepo{rule}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected validation error for %+v", rule)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import "fmt"
+
+const (
+	maxEventsPerHourKey = "max_events_per_hour"
+	sampleRateKey       = "sample_rate"
+)
+
+// IngestQuota limits how many events a module may store, so a chatty
+// source cannot bloat the database or crowd out other sources in
+// summaries. It is set with `max_events_per_hour` and `sample_rate` in the
+// module's config.
+type IngestQuota struct {
+	// MaxEventsPerHour caps the events stored in any rolling hour; zero
+	// means no cap.
+	MaxEventsPerHour int
+	// SampleRate is the fraction of events kept, chosen at random; 1 keeps
+	// them all. Error events are always kept.
+	SampleRate float64
+}
+
+// IsZero reports whether the quota lets every event through.
+func (q IngestQuota) IsZero() bool {
+	return q.MaxEventsPerHour == 0 && q.SampleRate >= 1
+}
+
+// ParseIngestQuota reads the quota options of a module's config.
+func ParseIngestQuota(modCfg map[string]interface{}) (IngestQuota, error) {
+	quota := IngestQuota{SampleRate: 1}
+
+	if val, ok := modCfg[maxEventsPerHourKey]; ok && val != nil {
+		limit, ok := val.(int)
+		if !ok {
+			return quota, fmt.Errorf("%s must be a whole number", maxEventsPerHourKey)
+		}
+		if limit < 0 {
+			return quota, fmt.Errorf("%s must not be negative", maxEventsPerHourKey)
+		}
+		quota.MaxEventsPerHour = limit
+	}
+
+	if val, ok := modCfg[sampleRateKey]; ok && val != nil {
+		var rate float64
+		switch v := val.(type) {
+		case float64:
+			rate = v
+		case int:
+			rate = float64(v)
+		default:
+			return quota, fmt.Errorf("%s must be a number between 0 and 1", sampleRateKey)
+		}
+		if rate <= 0 || rate > 1 {
+			return quota, fmt.Errorf("%s must be greater than 0 and at most 1, got %v", sampleRateKey, rate)
+		}
+		quota.SampleRate = rate
+	}
+
+	return quota, nil
+}
+
+// ModuleQuota returns the ingest quota of an enabled module.
+func (c *Config) ModuleQuota(moduleName string) IngestQuota {
+	modCfg, ok := c.GetModuleConfig(moduleName)
+	if !ok {
+		return IngestQuota{SampleRate: 1}
+	}
+	quota, err := ParseIngestQuota(modCfg)
+	if err != nil {
+		return IngestQuota{SampleRate: 1}
+	}
+	return quota
+}
@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+func TestParseIngestQuota(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		want    IngestQuota
+		wantErr bool
+	}{
+		{name: "unset", cfg: map[string]interface{}{}, want: IngestQuota{SampleRate: 1}},
+		{name: "limit", cfg: map[string]interface{}{"max_events_per_hour": 200}, want: IngestQuota{MaxEventsPerHour: 200, SampleRate: 1}},
+		{name: "sampling", cfg: map[string]interface{}{"sample_rate": 0.1}, want: IngestQuota{SampleRate: 0.1}},
+		{name: "sample everything", cfg: map[string]interface{}{"sample_rate": 1}, want: IngestQuota{SampleRate: 1}},
+		{name: "negative limit", cfg: map[string]interface{}{"max_events_per_hour": -1}, wantErr: true},
+		{name: "fractional limit", cfg: map[string]interface{}{"max_events_per_hour": 2.5}, wantErr: true},
+		{name: "zero rate", cfg: map[string]interface{}{"sample_rate": 0}, wantErr: true},
+		{name: "rate above one", cfg: map[string]interface{}{"sample_rate": 1.5}, wantErr: true},
+		{name: "rate as text", cfg: map[string]interface{}{"sample_rate": "10%"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIngestQuota(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseIngestQuota() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseIngestQuota() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsBadQuota(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Modules["clipboard"] = ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"max_events_per_hour": "lots"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected Validate to reject a non-numeric max_events_per_hour")
+	}
+}
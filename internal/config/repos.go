@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"devlog/internal/events"
+)
+
+// RemotePayloadKey is the payload field modules fill with the normalized
+// origin URL of the repository an event came from.
+const RemotePayloadKey = "repo_remote"
+
+// ReposConfig controls how the repo recorded on an event is named, so that
+// one project cloned in several places or on several machines is counted as
+// a single repo.
+type ReposConfig struct {
+	// UseRemote names repos by their normalized origin URL (e.g.
+	// github.com/owner/repo) whenever the capturing module knows it.
+	UseRemote bool        `yaml:"use_remote,omitempty"`
+	Aliases   []RepoAlias `yaml:"aliases,omitempty"`
+}
+
+// RepoAlias stores every event matching one of its patterns under Name.
+// Patterns use the filters glob syntax and are checked against the repo,
+// the normalized remote and the event's working directory and its parents.
+type RepoAlias struct {
+	Name  string   `yaml:"name"`
+	Match []string `yaml:"match"`
+}
+
+func (a RepoAlias) Matches(evt *events.Event) bool {
+	remote, _ := evt.Payload[RemotePayloadKey].(string)
+	for _, pattern := range a.Match {
+		pattern = ExpandHome(pattern)
+		if matchRepoPattern(pattern, evt) {
+			return true
+		}
+		if remote != "" && matchFilterPattern(pattern, remote) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a RepoAlias) Validate() error {
+	if a.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(a.Match) == 0 {
+		return fmt.Errorf("at least one match pattern is required")
+	}
+	for _, pattern := range a.Match {
+		if _, err := compileFilterPattern(ExpandHome(pattern)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResolveRepo returns the name an event's repo is stored under: the first
+// matching alias, else the remote when use_remote is set, else the repo with
+// any absolute path reduced to its directory name.
+func (c *Config) ResolveRepo(evt *events.Event) string {
+	for _, alias := range c.Repos.Aliases {
+		if alias.Matches(evt) {
+			return alias.Name
+		}
+	}
+	if c.Repos.UseRemote {
+		if remote, _ := evt.Payload[RemotePayloadKey].(string); remote != "" {
+			return remote
+		}
+	}
+	return baseRepoName(evt.Repo)
+}
+
+// ResolveRepoName applies the same rules to a bare repo name, e.g. one
+// already in the database or typed as a search filter.
+func (c *Config) ResolveRepoName(repo string) string {
+	if repo == "" {
+		return ""
+	}
+	return c.ResolveRepo(&events.Event{Repo: repo, Payload: map[string]interface{}{}})
+}
+
+func baseRepoName(repo string) string {
+	if !filepath.IsAbs(repo) {
+		return repo
+	}
+	return filepath.Base(filepath.Clean(repo))
+}
+
+func (c *Config) validateRepos() error {
+	for i, alias := range c.Repos.Aliases {
+		if err := alias.Validate(); err != nil {
+			return fmt.Errorf("aliases[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestReposConfigYAML(t *testing.T) {
+	data := `
+repos:
+  use_remote: true
+  aliases:
+    - name: devlog
+      match: ["github.com/*/devlog", "~/src/devlog-*"]
+`
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal([]byte(data), cfg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	if !cfg.Repos.UseRemote {
+		t.Error("use_remote not parsed")
+	}
+	if len(cfg.Repos.Aliases) != 1 || cfg.Repos.Aliases[0].Name != "devlog" || len(cfg.Repos.Aliases[0].Match) != 2 {
+		t.Fatalf("aliases = %+v", cfg.Repos.Aliases)
+	}
+	if err := cfg.validateRepos(); err != nil {
+		t.Errorf("validateRepos() error: %v", err)
+	}
+
+	cfg.Repos.Aliases = append(cfg.Repos.Aliases, RepoAlias{Name: "empty"})
+	if err := cfg.validateRepos(); err == nil {
+		t.Error("expected error for alias without patterns")
+	}
+}
+
+func TestResolveRepo(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Repos.Aliases = []RepoAlias{
+		{Name: "devlog", Match: []string{"github.com/ayellapragada/devlog", "/work/forks/devlog*", "devlog-old"}},
+	}
+
+	tests := []struct {
+		name      string
+		repo      string
+		payload   map[string]interface{}
+		useRemote bool
+		want      string
+	}{
+		{"alias by remote", "dl", map[string]interface{}{RemotePayloadKey: "github.com/ayellapragada/devlog"}, false, "devlog"},
+		{"alias by workdir", "checkout", map[string]interface{}{"workdir": "/work/forks/devlog-2/cmd"}, false, "devlog"},
+		{"alias by name", "devlog-old", nil, false, "devlog"},
+		{"remote when enabled", "api", map[string]interface{}{RemotePayloadKey: "github.com/acme/api"}, true, "github.com/acme/api"},
+		{"remote ignored by default", "api", map[string]interface{}{RemotePayloadKey: "github.com/acme/api"}, false, "api"},
+		{"absolute path", "/home/me/src/api/", nil, false, "api"},
+		{"plain name", "api", nil, true, "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Repos.UseRemote = tt.useRemote
+			evt := filterTestEvent("git", "commit", tt.repo, "main", tt.payload)
+			if got := cfg.ResolveRepo(evt); got != tt.want {
+				t.Errorf("ResolveRepo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := cfg.ResolveRepoName("devlog-old"); got != "devlog" {
+		t.Errorf("ResolveRepoName(devlog-old) = %q, want devlog", got)
+	}
+	if got := cfg.ResolveRepoName(""); got != "" {
+		t.Errorf("ResolveRepoName(\"\") = %q, want empty", got)
+	}
+}
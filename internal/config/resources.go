@@ -0,0 +1,131 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	resourcesKey = "resources"
+
+	BudgetActionWarn    = "warn"
+	BudgetActionRestart = "restart"
+
+	defaultResourceInterval  = time.Minute
+	defaultResourceCPUWindow = 5 * time.Second
+)
+
+// ResourceBudget caps what one plugin, or one module's poller, may use
+// inside the daemon. It is set under the component's `resources` key; zero
+// limits are unchecked.
+type ResourceBudget struct {
+	MaxGoroutines int     `json:"max_goroutines,omitempty"`
+	MaxCPUPercent float64 `json:"max_cpu_percent,omitempty"`
+	// Action is what happens when the component stays over budget: warn
+	// (the default) only logs and reports it, restart also restarts it.
+	Action string `json:"action,omitempty"`
+}
+
+// IsZero reports whether the budget checks nothing.
+func (b ResourceBudget) IsZero() bool {
+	return b.MaxGoroutines == 0 && b.MaxCPUPercent == 0
+}
+
+// ResourcesConfig tunes the daemon's resource watchdog.
+type ResourcesConfig struct {
+	Disabled bool `yaml:"disabled,omitempty"`
+	// IntervalSeconds is how often usage is sampled (default 60).
+	IntervalSeconds int `yaml:"interval_seconds,omitempty"`
+	// CPUWindowSeconds is how long the CPU profiler runs for each sample
+	// when a budget limits CPU (default 5).
+	CPUWindowSeconds int `yaml:"cpu_window_seconds,omitempty"`
+	// MaxHeapMB warns when the daemon's live heap grows past it. Go cannot
+	// attribute heap memory to the goroutine that allocated it, so this is
+	// a limit for the whole daemon rather than per component.
+	MaxHeapMB int `yaml:"max_heap_mb,omitempty"`
+}
+
+func (r ResourcesConfig) Interval() time.Duration {
+	if r.IntervalSeconds <= 0 {
+		return defaultResourceInterval
+	}
+	return time.Duration(r.IntervalSeconds) * time.Second
+}
+
+func (r ResourcesConfig) CPUWindow() time.Duration {
+	if r.CPUWindowSeconds <= 0 {
+		return defaultResourceCPUWindow
+	}
+	return time.Duration(r.CPUWindowSeconds) * time.Second
+}
+
+func (r ResourcesConfig) validate() error {
+	if r.IntervalSeconds < 0 || r.CPUWindowSeconds < 0 || r.MaxHeapMB < 0 {
+		return fmt.Errorf("daemon.resources values must not be negative")
+	}
+	if r.IntervalSeconds > 0 && r.IntervalSeconds < 10 {
+		return fmt.Errorf("daemon.resources.interval_seconds must be at least 10")
+	}
+	if r.Interval() <= r.CPUWindow() {
+		return fmt.Errorf("daemon.resources.cpu_window_seconds must be shorter than interval_seconds")
+	}
+	return nil
+}
+
+// ParseResourceBudget reads a component's resources option.
+func ParseResourceBudget(val interface{}) (ResourceBudget, error) {
+	var budget ResourceBudget
+	if val == nil {
+		return budget, nil
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return budget, err
+	}
+	if err := json.Unmarshal(data, &budget); err != nil {
+		return budget, fmt.Errorf("resources must be an object with max_goroutines, max_cpu_percent or action")
+	}
+	if budget.MaxGoroutines < 0 || budget.MaxCPUPercent < 0 {
+		return budget, fmt.Errorf("resources limits must not be negative")
+	}
+	switch budget.Action {
+	case "", BudgetActionWarn, BudgetActionRestart:
+	default:
+		return budget, fmt.Errorf("resources action must be %s or %s", BudgetActionWarn, BudgetActionRestart)
+	}
+	return budget, nil
+}
+
+// ModuleBudget returns the resource budget of an enabled module's poller.
+func (c *Config) ModuleBudget(moduleName string) ResourceBudget {
+	modCfg, ok := c.GetModuleConfig(moduleName)
+	if !ok {
+		return ResourceBudget{}
+	}
+	budget, _ := ParseResourceBudget(modCfg[resourcesKey])
+	return budget
+}
+
+// PluginBudget returns the resource budget of an enabled plugin.
+func (c *Config) PluginBudget(pluginName string) ResourceBudget {
+	pluginCfg, ok := c.GetPluginConfig(pluginName)
+	if !ok {
+		return ResourceBudget{}
+	}
+	budget, _ := ParseResourceBudget(pluginCfg[resourcesKey])
+	return budget
+}
+
+// Check returns how a sample breaks the budget. CPU is only checked when
+// cpuSampled is set, since a sample may have no CPU reading.
+func (b ResourceBudget) Check(goroutines int, cpuPercent float64, cpuSampled bool) []string {
+	var over []string
+	if b.MaxGoroutines > 0 && goroutines > b.MaxGoroutines {
+		over = append(over, fmt.Sprintf("%d goroutines, budget %d", goroutines, b.MaxGoroutines))
+	}
+	if cpuSampled && b.MaxCPUPercent > 0 && cpuPercent > b.MaxCPUPercent {
+		over = append(over, fmt.Sprintf("%.0f%% CPU, budget %.0f%%", cpuPercent, b.MaxCPUPercent))
+	}
+	return over
+}
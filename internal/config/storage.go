@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	StorageSQLite   = "sqlite"
+	StoragePostgres = "postgres"
+
+	// PostgresDSNEnv holds the Postgres connection string when it is not
+	// written to the config file.
+	PostgresDSNEnv = "DEVLOG_POSTGRES_DSN"
+)
+
+// StorageConfig selects where events and summaries are stored. The default
+// is the SQLite database in the data directory; Postgres lets several
+// machines share one central database.
+type StorageConfig struct {
+	Backend  string         `yaml:"backend,omitempty"`
+	Postgres PostgresConfig `yaml:"postgres,omitempty"`
+}
+
+type PostgresConfig struct {
+	// DSN is a connection string such as
+	// postgres://devlog@db.internal:5432/devlog?sslmode=require. When empty,
+	// $DEVLOG_POSTGRES_DSN is used.
+	DSN string `yaml:"dsn,omitempty"`
+}
+
+// BackendName returns the configured backend, defaulting to SQLite.
+func (s StorageConfig) BackendName() string {
+	if s.Backend == "" {
+		return StorageSQLite
+	}
+	return s.Backend
+}
+
+// PostgresDSN returns the Postgres connection string from the config or the
+// environment.
+func (s StorageConfig) PostgresDSN() string {
+	if s.Postgres.DSN != "" {
+		return s.Postgres.DSN
+	}
+	return os.Getenv(PostgresDSNEnv)
+}
+
+func (s StorageConfig) validate() error {
+	switch s.BackendName() {
+	case StorageSQLite:
+		return nil
+	case StoragePostgres:
+		if s.PostgresDSN() == "" {
+			return fmt.Errorf("storage.postgres.dsn or $%s is required for the postgres backend", PostgresDSNEnv)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown storage backend %q (use %s or %s)", s.Backend, StorageSQLite, StoragePostgres)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import "testing"
+
+func TestStorageConfigValidate(t *testing.T) {
+	t.Setenv(PostgresDSNEnv, "")
+
+	tests := []struct {
+		name    string
+		storage StorageConfig
+		wantErr bool
+	}{
+		{"default is sqlite", StorageConfig{}, false},
+		{"sqlite", StorageConfig{Backend: StorageSQLite}, false},
+		{"postgres with dsn", StorageConfig{Backend: StoragePostgres, Postgres: PostgresConfig{DSN: "postgres://localhost/devlog"}}, false},
+		{"postgres without dsn", StorageConfig{Backend: StoragePostgres}, true},
+		{"unknown backend", StorageConfig{Backend: "mysql"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Storage = tt.storage
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostgresDSNFromEnv(t *testing.T) {
+	t.Setenv(PostgresDSNEnv, "postgres://env/devlog")
+
+	cfg := StorageConfig{Backend: StoragePostgres}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate() error: %v", err)
+	}
+	if got := cfg.PostgresDSN(); got != "postgres://env/devlog" {
+		t.Errorf("PostgresDSN() = %q", got)
+	}
+
+	cfg.Postgres.DSN = "postgres://file/devlog"
+	if got := cfg.PostgresDSN(); got != "postgres://file/devlog" {
+		t.Errorf("PostgresDSN() = %q, want the configured dsn", got)
+	}
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	tokenPrefix = "dlg_"
+
+	// TokenEnv overrides the client token file for hooks, the CLI and the
+	// Go client, e.g. on a machine that only sends events to a remote daemon.
+	TokenEnv = "DEVLOG_API_TOKEN"
+
+	clientTokenFile = "api.token"
+)
+
+// APIToken is a bearer token accepted by the HTTP API. Only the SHA-256 of
+// the token is kept in the config, so config.yaml can be shared or backed up
+// without handing out access.
+type APIToken struct {
+	Name      string `yaml:"name"`
+	Hash      string `yaml:"hash"`
+	CreatedAt string `yaml:"created_at,omitempty"`
+}
+
+func GenerateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AddToken stores the hash of token under name, replacing any token that
+// already has that name.
+func (c *Config) AddToken(name, token string, now time.Time) {
+	c.RemoveToken(name)
+	c.HTTP.Tokens = append(c.HTTP.Tokens, APIToken{
+		Name:      name,
+		Hash:      HashToken(token),
+		CreatedAt: now.UTC().Format(time.RFC3339),
+	})
+}
+
+func (c *Config) RemoveToken(name string) bool {
+	for i, t := range c.HTTP.Tokens {
+		if t.Name == name {
+			c.HTTP.Tokens = append(c.HTTP.Tokens[:i], c.HTTP.Tokens[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyToken returns the name of the configured token matching token.
+func (c *Config) VerifyToken(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	hash := []byte(HashToken(token))
+	for _, t := range c.HTTP.Tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(strings.ToLower(t.Hash))) == 1 {
+			return t.Name, true
+		}
+	}
+	return "", false
+}
+
+func (c *Config) validateTokens() error {
+	seen := make(map[string]bool)
+	for i, t := range c.HTTP.Tokens {
+		if t.Name == "" {
+			return fmt.Errorf("tokens[%d]: name is required", i)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("tokens[%d]: duplicate name %q", i, t.Name)
+		}
+		seen[t.Name] = true
+		if b, err := hex.DecodeString(t.Hash); err != nil || len(b) != sha256.Size {
+			return fmt.Errorf("tokens[%d]: hash must be a hex SHA-256 (create tokens with 'devlog token create')", i)
+		}
+	}
+	return nil
+}
+
+func ClientTokenPath() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, clientTokenFile), nil
+}
+
+// ClientToken returns the token this machine sends to the daemon: $DEVLOG_API_TOKEN
+// if set, else the token saved by 'devlog token create'. Empty when neither
+// exists.
+func ClientToken() string {
+	if token := strings.TrimSpace(os.Getenv(TokenEnv)); token != "" {
+		return token
+	}
+	path, err := ClientTokenPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func SaveClientToken(token string) (string, error) {
+	path, err := ClientTokenPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("create data directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("write client token: %w", err)
+	}
+	return path, nil
+}
+
+// AuthorizeRequest adds this machine's client token, if any, to a request
+// for the daemon's API.
+func AuthorizeRequest(req *http.Request) {
+	if token := ClientToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokens(t *testing.T) {
+	cfg := DefaultConfig()
+
+	token, err := GenerateToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, tokenPrefix) {
+		t.Errorf("GenerateToken() = %q, want %s prefix", token, tokenPrefix)
+	}
+
+	cfg.AddToken("laptop", token, time.Now())
+	if cfg.HTTP.Tokens[0].Hash == token || strings.Contains(cfg.HTTP.Tokens[0].Hash, token) {
+		t.Error("token stored in plain text")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	if name, ok := cfg.VerifyToken(token); !ok || name != "laptop" {
+		t.Errorf("VerifyToken() = %q, %v; want laptop, true", name, ok)
+	}
+	if _, ok := cfg.VerifyToken(token + "x"); ok {
+		t.Error("VerifyToken() accepted a wrong token")
+	}
+	if _, ok := cfg.VerifyToken(""); ok {
+		t.Error("VerifyToken() accepted an empty token")
+	}
+
+	replacement, _ := GenerateToken()
+	cfg.AddToken("laptop", replacement, time.Now())
+	if len(cfg.HTTP.Tokens) != 1 {
+		t.Fatalf("AddToken() with an existing name left %d tokens, want 1", len(cfg.HTTP.Tokens))
+	}
+	if _, ok := cfg.VerifyToken(token); ok {
+		t.Error("replaced token still accepted")
+	}
+
+	if !cfg.RemoveToken("laptop") || cfg.RemoveToken("laptop") {
+		t.Error("RemoveToken() should remove the token exactly once")
+	}
+}
+
+func TestValidateTokens(t *testing.T) {
+	tests := map[string][]APIToken{
+		"missing name": {{Hash: HashToken("a")}},
+		"plain token":  {{Name: "a", Hash: "dlg_secret"}},
+		"duplicate":    {{Name: "a", Hash: HashToken("a")}, {Name: "a", Hash: HashToken("b")}},
+	}
+	for name, tokens := range tests {
+		cfg := DefaultConfig()
+		cfg.HTTP.Tokens = tokens
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestClientTokenEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if got := ClientToken(); got != "" {
+		t.Errorf("ClientToken() = %q, want empty", got)
+	}
+
+	if _, err := SaveClientToken("dlg_saved"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ClientToken(); got != "dlg_saved" {
+		t.Errorf("ClientToken() = %q, want saved token", got)
+	}
+
+	t.Setenv(TokenEnv, "dlg_env")
+	if got := ClientToken(); got != "dlg_env" {
+		t.Errorf("ClientToken() = %q, want $%s", got, TokenEnv)
+	}
+}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+)
+
+const (
+	BackupInitialDelay  = 5 * time.Minute
+	BackupCheckInterval = 10 * time.Minute
+)
+
+// startBackups takes a backup whenever backup.enabled is set and the newest
+// one in the backup directory is older than backup.interval, then prunes
+// down to backup.keep. The config is read on every check, so enabling
+// backups takes effect without a restart.
+func (d *Daemon) startBackups(ctx context.Context) {
+	backuper, ok := d.storage.(storage.Backuper)
+	if !ok {
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(BackupInitialDelay)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				d.logger.Debug("scheduled backups stopped")
+				return
+			case <-timer.C:
+				d.runScheduledBackup(ctx, backuper, time.Now())
+				timer.Reset(BackupCheckInterval)
+			}
+		}
+	}()
+}
+
+func (d *Daemon) runScheduledBackup(ctx context.Context, backuper storage.Backuper, now time.Time) {
+	cfg := d.getConfig().Backup
+	if !cfg.Enabled {
+		return
+	}
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return
+	}
+	dir := cfg.BackupDir(dataDir)
+
+	backups, err := storage.ListBackups(dir)
+	if err != nil {
+		d.logger.Warn("list backups failed", slog.String("error", err.Error()))
+		return
+	}
+	if len(backups) > 0 && now.Sub(backups[0].Created) < cfg.IntervalDuration() {
+		return
+	}
+
+	dest := filepath.Join(dir, storage.BackupFileName(now, cfg.Gzip))
+	start := time.Now()
+	info, err := backuper.BackupContext(ctx, dest, storage.BackupOptions{Gzip: cfg.Gzip})
+	if err != nil {
+		d.logger.Warn("scheduled backup failed", slog.String("error", err.Error()))
+		return
+	}
+	d.logger.Info("scheduled backup completed",
+		slog.String("path", info.Path),
+		slog.Int64("bytes", info.Size),
+		slog.Duration("duration", time.Since(start)))
+
+	removed, err := storage.PruneBackups(dir, cfg.KeepCount())
+	if err != nil {
+		d.logger.Warn("prune backups failed", slog.String("error", err.Error()))
+	}
+	for _, path := range removed {
+		d.logger.Debug("removed old backup", slog.String("path", path))
+	}
+}
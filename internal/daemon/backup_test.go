@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+func TestRunScheduledBackup(t *testing.T) {
+	d, _, cleanup := setupTestDaemon(t)
+	defer cleanup()
+
+	dir := t.TempDir()
+	d.config.Backup.Dir = dir
+	d.config.Backup.Keep = 2
+	backuper := d.storage.(storage.Backuper)
+	ctx := context.Background()
+	start := time.Now()
+
+	d.runScheduledBackup(ctx, backuper, start)
+	if backups, _ := storage.ListBackups(dir); len(backups) != 0 {
+		t.Fatalf("backed up with backup.enabled off: %v", backups)
+	}
+
+	d.config.Backup.Enabled = true
+	d.runScheduledBackup(ctx, backuper, start)
+	d.runScheduledBackup(ctx, backuper, start.Add(time.Hour))
+	if backups, _ := storage.ListBackups(dir); len(backups) != 1 {
+		t.Fatalf("got %d backups, want 1 within the interval", len(backups))
+	}
+
+	for day := 1; day <= 3; day++ {
+		d.runScheduledBackup(ctx, backuper, start.Add(time.Duration(day)*25*time.Hour))
+	}
+	backups, err := storage.ListBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("got %d backups, want backup.keep = 2", len(backups))
+	}
+}
@@ -48,11 +48,23 @@ func (d *Daemon) handleConfigChange(newConfig *config.Config) {
 			slog.Int("new_port", newConfig.HTTP.Port))
 	}
 
+	if oldConfig.HTTP.BindAddress != newConfig.HTTP.BindAddress || oldConfig.HTTP.TLS != newConfig.HTTP.TLS {
+		d.logger.Warn("http bind address or tls changed, restart required")
+	}
+
 	d.handleExtensionConfigChanges("module", oldConfig.Modules, newConfig.Modules)
 	d.handleExtensionConfigChanges("plugin", oldConfig.Plugins, newConfig.Plugins)
+
+	if oldList, newList := oldConfig.ShellIgnoreList(), newConfig.ShellIgnoreList(); !stringSlicesEqual(oldList, newList) {
+		d.logger.Info("shell ignore list changed, notifying modules",
+			slog.Int("commands", len(newList)))
+		d.pollerManager.NotifyIgnoreList(newList)
+	}
 }
 
 func (d *Daemon) handleExtensionConfigChanges(extensionType string, oldComponents, newComponents map[string]config.ComponentConfig) {
+	var changed []string
+
 	for name := range newComponents {
 		oldCfg, oldExists := oldComponents[name]
 		newCfg := newComponents[name]
@@ -83,6 +95,14 @@ func (d *Daemon) handleExtensionConfigChanges(extensionType string, oldComponent
 		if oldCfg.Enabled && newCfg.Enabled && !configMapsEqual(oldCfg.Config, newCfg.Config) {
 			d.logger.Info(extensionType+" config changed, restarting",
 				slog.String(extensionType, name))
+			changed = append(changed, name)
+		}
+	}
+
+	if extensionType == "plugin" {
+		d.restartPlugins(changed)
+	} else {
+		for _, name := range changed {
 			d.restartExtension(extensionType, name)
 		}
 	}
@@ -164,6 +184,18 @@ func (d *Daemon) restartExtension(extensionType, name string) {
 	}
 }
 
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func configMapsEqual(a, b map[string]interface{}) bool {
 	if len(a) != len(b) {
 		return false
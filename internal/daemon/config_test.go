@@ -0,0 +1,164 @@
+package daemon
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/plugins"
+	"devlog/internal/testutil"
+)
+
+type ignoreAwarePoller struct {
+	mu         sync.Mutex
+	ignoreList []string
+	calls      int
+}
+
+func (p *ignoreAwarePoller) Name() string                { return "ignore-aware" }
+func (p *ignoreAwarePoller) PollInterval() time.Duration { return time.Hour }
+func (p *ignoreAwarePoller) Poll(ctx context.Context) ([]*events.Event, error) {
+	return nil, nil
+}
+
+func (p *ignoreAwarePoller) SetIgnoreList(commands []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.ignoreList = commands
+	p.calls++
+}
+
+type reloadTestPlugin struct {
+	name   string
+	deps   []string
+	mu     sync.Mutex
+	starts int
+}
+
+func (p *reloadTestPlugin) Name() string                            { return p.name }
+func (p *reloadTestPlugin) Description() string                     { return "reload test plugin" }
+func (p *reloadTestPlugin) Install(ctx *install.Context) error      { return nil }
+func (p *reloadTestPlugin) Uninstall(ctx *install.Context) error    { return nil }
+func (p *reloadTestPlugin) DefaultConfig() interface{}              { return nil }
+func (p *reloadTestPlugin) ValidateConfig(config interface{}) error { return nil }
+
+func (p *reloadTestPlugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{Name: p.name, Dependencies: p.deps}
+}
+
+func (p *reloadTestPlugin) Start(ctx context.Context) error {
+	p.mu.Lock()
+	p.starts++
+	p.mu.Unlock()
+	<-ctx.Done()
+	return nil
+}
+
+func (p *reloadTestPlugin) startCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.starts
+}
+
+func waitForStarts(t *testing.T, p *reloadTestPlugin, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if p.startCount() == want {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("plugin %s started %d times, want %d", p.name, p.startCount(), want)
+}
+
+func TestHandleConfigChangeNotifiesIgnoreList(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	defer store.Close()
+
+	oldCfg := config.DefaultConfig()
+	oldCfg.Modules["shell"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"ignore_list": []interface{}{"ls"}},
+	}
+	d := New(oldCfg, store)
+
+	p := &ignoreAwarePoller{}
+	d.pollerManager.Register(p)
+
+	sameCfg := config.DefaultConfig()
+	sameCfg.Modules["shell"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"ignore_list": []interface{}{"ls"}},
+	}
+	d.handleConfigChange(sameCfg)
+	if p.calls != 0 {
+		t.Errorf("poller notified %d times for unchanged ignore list", p.calls)
+	}
+
+	newCfg := config.DefaultConfig()
+	newCfg.Modules["shell"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"ignore_list": []interface{}{"ls", "cd"}},
+	}
+	d.handleConfigChange(newCfg)
+
+	if p.calls != 1 {
+		t.Fatalf("poller notified %d times, want 1", p.calls)
+	}
+	if len(p.ignoreList) != 2 || p.ignoreList[1] != "cd" {
+		t.Errorf("got ignore list %v, want [ls cd]", p.ignoreList)
+	}
+	if d.getConfig() != newCfg {
+		t.Error("daemon config was not swapped")
+	}
+}
+
+func TestHandleConfigChangeRestartsDependentPlugins(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	defer store.Close()
+
+	provider := &reloadTestPlugin{name: "reload-test-provider"}
+	dependent := &reloadTestPlugin{name: "reload-test-dependent", deps: []string{"reload-test-provider"}}
+	unrelated := &reloadTestPlugin{name: "reload-test-unrelated"}
+	for _, p := range []*reloadTestPlugin{provider, dependent, unrelated} {
+		if err := plugins.Register(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pluginConfig := func(model string) map[string]config.ComponentConfig {
+		return map[string]config.ComponentConfig{
+			"reload-test-provider":  {Enabled: true, Config: map[string]interface{}{"model": model}},
+			"reload-test-dependent": {Enabled: true},
+			"reload-test-unrelated": {Enabled: true},
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Plugins = pluginConfig("a")
+	d := New(cfg, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.startPlugins(ctx)
+
+	waitForStarts(t, provider, 1)
+	waitForStarts(t, dependent, 1)
+	waitForStarts(t, unrelated, 1)
+
+	newCfg := config.DefaultConfig()
+	newCfg.Plugins = pluginConfig("b")
+	d.handleConfigChange(newCfg)
+
+	waitForStarts(t, provider, 2)
+	waitForStarts(t, dependent, 2)
+	waitForStarts(t, unrelated, 1)
+
+	cancel()
+	d.pluginWG.Wait()
+}
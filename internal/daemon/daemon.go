@@ -8,29 +8,46 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"os/signal"
 	"path/filepath"
 	"strconv"
 	"sync"
-	"syscall"
 	"time"
 
 	"devlog/internal/api"
 	"devlog/internal/config"
 	"devlog/internal/errors"
+	"devlog/internal/events"
 	"devlog/internal/logger"
 	"devlog/internal/metrics"
+	"devlog/internal/pause"
+	"devlog/internal/plugins"
 	"devlog/internal/poller"
 	"devlog/internal/queue"
 	"devlog/internal/services"
 	"devlog/internal/storage"
+	_ "devlog/modules/activity"
 	_ "devlog/modules/claude"
 	_ "devlog/modules/clipboard"
+	_ "devlog/modules/github"
 	_ "devlog/modules/wisprflow"
+	_ "devlog/plugins/archiver"
+	_ "devlog/plugins/backup"
+	_ "devlog/plugins/embeddings"
+	_ "devlog/plugins/issues"
 	_ "devlog/plugins/llm"
+	_ "devlog/plugins/obsidian"
+	_ "devlog/plugins/patterns"
 	_ "devlog/plugins/summarizer"
+	_ "devlog/plugins/sync"
+	_ "devlog/plugins/timetrack"
+	_ "devlog/plugins/wakatime"
+	_ "devlog/plugins/webhooks"
 )
 
+// ServiceName is the name the daemon is registered under as a Windows
+// service.
+const ServiceName = "devlog"
+
 const (
 	PluginShutdownTimeout      = 5 * time.Second
 	PluginShutdownTimeoutShort = 2 * time.Second
@@ -40,16 +57,27 @@ const (
 	StopDaemonMaxAttempts      = 50
 	QueueProcessorInterval     = 30 * time.Second
 	MetricsUpdaterInterval     = 60 * time.Second
+	MaintenanceInitialDelay    = 10 * time.Minute
+	MaintenanceInterval        = 24 * time.Hour
+	WriteBufferCloseTimeout    = 10 * time.Second
 )
 
 type Daemon struct {
 	config          *config.Config
 	configMu        sync.RWMutex
 	configWatcher   *config.Watcher
-	storage         *storage.Storage
-	eventService    poller.EventService
+	storage         storage.Store
+	eventService    *services.EventService
+	writeBuffer     *services.WriteBuffer
+	pause           *pause.Controller
 	pollerManager   *poller.Manager
 	server          *http.Server
+	grpcServer      *http.Server
+	socketServer    *http.Server
+	socketPath      string
+	tlsCert         string
+	tlsKey          string
+	apiServer       *api.Server
 	logger          *logger.Logger
 	stopChan        chan struct{}
 	pluginCtx       context.Context
@@ -62,9 +90,13 @@ type Daemon struct {
 	moduleCtx       context.Context
 	services        map[string]interface{}
 	servicesMu      sync.RWMutex
+	version         string
+	preflight       []PreflightIssue
+
+	pluginRestart restartPolicy
 }
 
-func New(cfg *config.Config, store *storage.Storage) *Daemon {
+func New(cfg *config.Config, store storage.Store) *Daemon {
 	logDir, err := config.DataDir()
 	var log *logger.Logger
 
@@ -96,15 +128,31 @@ func New(cfg *config.Config, store *storage.Storage) *Daemon {
 		plugins:  make(map[string]*pluginInstance),
 		modules:  make(map[string]string),
 		services: make(map[string]interface{}),
+
+		pluginRestart: defaultPluginRestartPolicy,
 	}
 
 	eventService := services.NewEventService(store, d.getConfig, log)
+	if err == nil {
+		d.pause = pause.NewController(logDir)
+		eventService.SetPause(d.pause)
+	}
+	eventService.SetEnricher(d.enrichEvent)
+	eventService.SetObserver(d.observeEvent)
 	d.eventService = eventService
+	d.services[plugins.ServiceEventIngest] = eventService
 	d.pollerManager = poller.NewManager(eventService, log)
+	d.pollerManager.SetFallback(d.queueUnstoredEvent)
 
 	return d
 }
 
+// SetVersion records the running binary's version, used when preflight
+// reinstalls module hooks.
+func (d *Daemon) SetVersion(version string) {
+	d.version = version
+}
+
 func (d *Daemon) Start() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	var startupComplete bool
@@ -120,6 +168,8 @@ func (d *Daemon) Start() error {
 		return errors.WrapDaemon("pre-startup validation", err)
 	}
 
+	d.preflight = d.runPreflight()
+
 	if err := d.setupResources(ctx); err != nil {
 		return errors.WrapDaemon("setup resources", err)
 	}
@@ -130,7 +180,8 @@ func (d *Daemon) Start() error {
 
 	startupComplete = true
 	d.logger.Info("daemon started successfully",
-		slog.Int("port", d.config.HTTP.Port),
+		slog.String("addr", d.config.HTTP.ListenAddr()),
+		slog.Bool("tls", d.tlsCert != ""),
 		slog.Int("pid", os.Getpid()))
 
 	return d.runEventLoop(ctx, cancel)
@@ -169,14 +220,24 @@ func (d *Daemon) setupResources(ctx context.Context) error {
 }
 
 func (d *Daemon) startServices(ctx context.Context) error {
-	apiServer := api.NewServer(d.storage, d.getConfig, d.logger)
-	mux := apiServer.SetupRoutes()
+	d.startWriteBuffer()
+
+	d.apiServer = d.newAPIServer()
+	mux := d.apiServer.SetupRoutes()
 
-	addr := fmt.Sprintf("127.0.0.1:%d", d.config.HTTP.Port)
 	d.server = &http.Server{
-		Addr:    addr,
+		Addr:    d.config.HTTP.ListenAddr(),
 		Handler: mux,
 	}
+	if err := d.prepareTLS(); err != nil {
+		return errors.WrapDaemon("prepare tls", err)
+	}
+	d.setupGRPC()
+	d.serveSocket(mux)
+	if !d.config.HTTP.IsLoopback() && !d.config.HTTP.AuthEnabled {
+		d.logger.Warn("API is reachable from other machines without authentication; set http.auth_enabled",
+			slog.String("addr", d.server.Addr))
+	}
 
 	d.startPlugins(ctx)
 	d.moduleCtx = ctx
@@ -190,26 +251,79 @@ func (d *Daemon) startServices(ctx context.Context) error {
 
 	d.startQueueProcessor(ctx)
 	d.startMetricsUpdater(ctx)
+	d.startMaintenance(ctx)
+	d.startBackups(ctx)
+	d.startResourceWatchdog(ctx)
 
 	return nil
 }
 
+// newAPIServer serves the API through the daemon's event service, so events
+// ingested over HTTP are enriched by plugins and counted against the same
+// quotas as polled ones.
+func (d *Daemon) newAPIServer() *api.Server {
+	apiServer := api.NewServer(d.storage, d.eventService, d.getConfig, d.logger)
+	if d.pause != nil {
+		apiServer.SetPause(d.pause)
+	}
+	apiServer.SetPreflight(preflightStatus(d.preflight))
+	return apiServer
+}
+
+// startWriteBuffer batches ingested events into one transaction per flush
+// unless daemon.write_buffer.disabled is set. Events the buffer cannot store
+// go to the on-disk queue.
+func (d *Daemon) startWriteBuffer() {
+	wb := d.config.Daemon.WriteBuffer
+	if wb.Disabled {
+		return
+	}
+	d.writeBuffer = services.NewWriteBuffer(d.storage, wb.Size, wb.BatchSize,
+		time.Duration(wb.FlushMillis)*time.Millisecond, d.queueUnstoredEvent, d.logger)
+	d.writeBuffer.Start()
+	d.eventService.SetWriteBuffer(d.writeBuffer)
+}
+
+// closeWriteBuffer flushes buffered events, queueing whatever is left when
+// the flush takes longer than timeout.
+func (d *Daemon) closeWriteBuffer(timeout time.Duration) {
+	if d.writeBuffer == nil {
+		return
+	}
+	d.logger.Debug("flushing write buffer", slog.Int("events", d.writeBuffer.Len()))
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := d.writeBuffer.Close(ctx); err != nil {
+		d.logger.Warn("write buffer flush timed out; remaining events were queued")
+	}
+}
+
 func (d *Daemon) runEventLoop(ctx context.Context, cancel context.CancelFunc) error {
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	if err := notifyStop(sigChan); err != nil {
+		d.logger.Warn("failed to listen for stop requests", slog.String("error", err.Error()))
+	}
 
 	errChan := make(chan error, 1)
 	go func() {
-		if err := d.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if d.tlsCert != "" {
+			err = d.server.ListenAndServeTLS(d.tlsCert, d.tlsKey)
+		} else {
+			err = d.server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			errChan <- err
 		}
 	}()
+	d.serveGRPC(errChan)
 
 	select {
 	case <-sigChan:
 		d.logger.Info("shutdown signal received")
+		err := d.Shutdown()
 		cancel()
-		return d.Shutdown()
+		return err
 	case err := <-errChan:
 		d.logger.Error("server error", slog.String("error", err.Error()))
 		cancel()
@@ -251,6 +365,14 @@ func (d *Daemon) cleanupOnError() {
 				slog.String("error", err.Error()))
 		}
 	}
+	if d.grpcServer != nil || d.socketServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), ServerShutdownTimeoutShort)
+		defer cancel()
+		d.stopGRPC(ctx)
+		d.stopSocket(ctx)
+	}
+
+	d.closeWriteBuffer(ServerShutdownTimeoutShort)
 
 	d.removePIDFile()
 	d.logger.Debug("cleanup completed")
@@ -282,7 +404,7 @@ func (d *Daemon) processQueue() error {
 	filteredCount := 0
 	for _, event := range queuedEvents {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		err := d.eventService.IngestEvent(ctx, event)
+		err := d.eventService.IngestEventSync(ctx, event)
 		cancel()
 
 		if err == services.ErrEventFiltered || err == services.ErrDuplicateEvent {
@@ -332,6 +454,32 @@ func (d *Daemon) processQueue() error {
 	return nil
 }
 
+// queueUnstoredEvent writes a polled event that could not be stored to the
+// on-disk queue so it is ingested on the next queue run or daemon start.
+func (d *Daemon) queueUnstoredEvent(event *events.Event, err error) {
+	var validationErr *services.ValidationError
+	if err == services.ErrEventFiltered || err == services.ErrDuplicateEvent || stderrors.As(err, &validationErr) {
+		return
+	}
+
+	queueDir, qerr := config.QueueDir()
+	if qerr == nil {
+		var q *queue.Queue
+		if q, qerr = queue.New(queueDir); qerr == nil {
+			qerr = q.Enqueue(event)
+		}
+	}
+	if qerr != nil {
+		d.logger.Error("failed to queue unstored event",
+			slog.String("event_id", event.ID),
+			slog.String("error", qerr.Error()))
+		return
+	}
+	d.logger.Info("queued event that could not be stored",
+		slog.String("event_id", event.ID),
+		slog.String("reason", err.Error()))
+}
+
 func (d *Daemon) startQueueProcessor(ctx context.Context) {
 	go func() {
 		ticker := time.NewTicker(QueueProcessorInterval)
@@ -352,6 +500,44 @@ func (d *Daemon) startQueueProcessor(ctx context.Context) {
 	}()
 }
 
+// startMaintenance runs database maintenance shortly after startup and then
+// once a day. VACUUM is left to `devlog db maintain --vacuum` because it
+// blocks ingest for the whole run.
+func (d *Daemon) startMaintenance(ctx context.Context) {
+	maintainer, ok := d.storage.(storage.Maintainer)
+	if !ok {
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(MaintenanceInitialDelay)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				d.logger.Debug("database maintenance stopped")
+				return
+			case <-timer.C:
+				result, err := maintainer.MaintainContext(ctx, storage.MaintenanceOptions{})
+				if err != nil {
+					d.logger.Warn("database maintenance failed",
+						slog.String("error", err.Error()))
+				} else {
+					var total time.Duration
+					for _, step := range result.Steps {
+						total += step.Duration
+					}
+					d.logger.Info("database maintenance completed",
+						slog.Duration("duration", total),
+						slog.Int("wal_pages", result.WALPages))
+				}
+				timer.Reset(MaintenanceInterval)
+			}
+		}
+	}()
+}
+
 func (d *Daemon) startMetricsUpdater(ctx context.Context) {
 	go func() {
 		ticker := time.NewTicker(MetricsUpdaterInterval)
@@ -430,6 +616,36 @@ func (d *Daemon) Shutdown() error {
 		}
 	}
 
+	// Stop accepting new events first: ingest requests get a 503 that tells
+	// clients to queue locally, while requests already being stored finish.
+	if d.apiServer != nil {
+		d.logger.Info("draining ingest")
+		d.apiServer.Drain()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ServerShutdownTimeout)
+	defer cancel()
+
+	if d.server != nil {
+		if err := d.server.Shutdown(ctx); err != nil {
+			d.logger.Error("failed to shutdown server", slog.String("error", err.Error()))
+			return fmt.Errorf("shutdown server: %w", err)
+		}
+		d.logger.Debug("http server stopped")
+	}
+	d.stopGRPC(ctx)
+	d.stopSocket(ctx)
+
+	// Pollers save their cursors before handing back events, so the events
+	// of an in-flight poll must be stored (or queued) before we exit.
+	if d.pollerManager != nil {
+		d.pollerManager.Stop()
+		if !d.pollerManager.Wait(PluginShutdownTimeout) {
+			d.logger.Warn("pollers did not finish within timeout")
+		}
+		d.logger.Debug("poller manager stopped")
+	}
+
 	if d.pluginCtxCancel != nil {
 		d.logger.Debug("stopping plugins")
 		d.pluginCtxCancel()
@@ -448,21 +664,9 @@ func (d *Daemon) Shutdown() error {
 		}
 	}
 
-	if d.pollerManager != nil {
-		d.pollerManager.Stop()
-		d.logger.Debug("poller manager stopped")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), ServerShutdownTimeout)
-	defer cancel()
-
-	if d.server != nil {
-		if err := d.server.Shutdown(ctx); err != nil {
-			d.logger.Error("failed to shutdown server", slog.String("error", err.Error()))
-			return fmt.Errorf("shutdown server: %w", err)
-		}
-		d.logger.Debug("http server stopped")
-	}
+	// Everything that ingests has stopped; write what is still buffered
+	// before the database closes.
+	d.closeWriteBuffer(WriteBufferCloseTimeout)
 
 	if d.storage != nil {
 		if err := d.storage.Close(); err != nil {
@@ -529,13 +733,7 @@ func IsRunning() bool {
 		return false
 	}
 
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+	return processAlive(pid)
 }
 
 func GetPID() int {
@@ -567,13 +765,8 @@ func StopDaemon() error {
 		return fmt.Errorf("could not read PID file")
 	}
 
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return fmt.Errorf("find process: %w", err)
-	}
-
-	if err := process.Signal(syscall.SIGTERM); err != nil {
-		return fmt.Errorf("send SIGTERM: %w", err)
+	if err := requestStop(pid); err != nil {
+		return err
 	}
 
 	for i := 0; i < StopDaemonMaxAttempts; i++ {
@@ -600,9 +793,7 @@ func SpawnBackground() *exec.Cmd {
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	cmd.Stdin = nil
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setsid: true,
-	}
+	detach(cmd)
 
 	return cmd
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"devlog/internal/config"
+	"devlog/internal/plugins"
 	"devlog/internal/storage"
 	"devlog/internal/testutil"
 )
@@ -239,6 +240,9 @@ func TestDaemonServices(t *testing.T) {
 		if daemon.services == nil {
 			t.Error("services map is nil")
 		}
+		if _, ok := daemon.services[plugins.ServiceEventIngest].(plugins.EventIngester); !ok {
+			t.Errorf("%s service not registered", plugins.ServiceEventIngest)
+		}
 	})
 
 	t.Run("can register service", func(t *testing.T) {
@@ -0,0 +1,65 @@
+package daemon
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net/http"
+)
+
+// setupGRPC prepares the gRPC ingestion server when http.grpc_port is set.
+// It serves HTTP/2 only: over TLS with the API's certificate, or as
+// unencrypted HTTP/2, which is how gRPC clients connect without TLS.
+func (d *Daemon) setupGRPC() {
+	if d.config.HTTP.GRPCPort == 0 {
+		return
+	}
+
+	var protocols http.Protocols
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(d.tlsCert == "")
+	d.grpcServer = &http.Server{
+		Addr:      d.config.HTTP.GRPCListenAddr(),
+		Handler:   d.apiServer.GRPCHandler(),
+		Protocols: &protocols,
+	}
+	if d.tlsCert != "" {
+		d.grpcServer.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+}
+
+// serveGRPC runs the gRPC server until it is shut down, reporting other
+// errors on errChan like the HTTP server.
+func (d *Daemon) serveGRPC(errChan chan<- error) {
+	if d.grpcServer == nil {
+		return
+	}
+	d.logger.Info("serving gRPC ingestion", slog.String("addr", d.grpcServer.Addr))
+	go func() {
+		var err error
+		if d.tlsCert != "" {
+			err = d.grpcServer.ListenAndServeTLS(d.tlsCert, d.tlsKey)
+		} else {
+			err = d.grpcServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			select {
+			case errChan <- err:
+			default:
+			}
+		}
+	}()
+}
+
+// stopGRPC waits for open streams to finish, up to the context's deadline.
+// Streams still open after a drain end with UNAVAILABLE on their next
+// event.
+func (d *Daemon) stopGRPC(ctx context.Context) {
+	if d.grpcServer == nil {
+		return
+	}
+	if err := d.grpcServer.Shutdown(ctx); err != nil {
+		d.logger.Debug("error during gRPC server shutdown", slog.String("error", err.Error()))
+		d.grpcServer.Close()
+	}
+}
@@ -2,11 +2,13 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"devlog/internal/config"
 	"devlog/internal/modules"
+	"devlog/internal/poller"
 )
 
 func (d *Daemon) setupPollers() {
@@ -14,7 +16,7 @@ func (d *Daemon) setupPollers() {
 	for _, module := range allModules {
 		moduleName := module.Name()
 
-		if !d.config.IsModuleEnabled(moduleName) {
+		if !d.getConfig().IsModuleEnabled(moduleName) {
 			continue
 		}
 
@@ -59,14 +61,20 @@ func (d *Daemon) setupModule(module modules.Module, moduleName string, startImme
 		return
 	}
 
-	modCfg, ok := d.config.GetModuleConfig(moduleName)
+	cfg := d.getConfig()
+	modCfg, ok := cfg.GetModuleConfig(moduleName)
 	if !ok {
 		d.logger.Warn("module config not found",
 			slog.String("module", moduleName))
 		return
 	}
 
-	poller, err := pollable.CreatePoller(modCfg, dataDir)
+	p, err := pollable.CreatePoller(modCfg, dataDir)
+	if errors.Is(err, modules.ErrPollerDisabled) {
+		d.logger.Debug("module poller disabled",
+			slog.String("module", moduleName))
+		return
+	}
 	if err != nil {
 		d.logger.Warn("failed to create poller",
 			slog.String("module", moduleName),
@@ -74,19 +82,23 @@ func (d *Daemon) setupModule(module modules.Module, moduleName string, startImme
 		return
 	}
 
-	d.pollerManager.Register(poller)
+	if aware, ok := p.(poller.IgnoreListAware); ok {
+		aware.SetIgnoreList(cfg.ShellIgnoreList())
+	}
+
+	d.pollerManager.Register(p)
 
 	if startImmediately {
-		d.pollerManager.StartPoller(ctx, poller)
+		d.pollerManager.StartPoller(ctx, p)
 	}
 
 	d.modulesMu.Lock()
-	d.modules[moduleName] = poller.Name()
+	d.modules[moduleName] = p.Name()
 	d.modulesMu.Unlock()
 
 	d.logger.Info("polling started",
 		slog.String("module", moduleName),
-		slog.Duration("interval", poller.PollInterval()))
+		slog.Duration("interval", p.PollInterval()))
 }
 
 func (d *Daemon) stopModule(moduleName string) error {
@@ -94,11 +106,10 @@ func (d *Daemon) stopModule(moduleName string) error {
 	pollerName, exists := d.modules[moduleName]
 	if !exists {
 		d.modulesMu.Unlock()
-		module, err := modules.Get(moduleName)
-		if err == nil {
-			if _, ok := module.(modules.Pollable); !ok {
-				return nil
-			}
+		// Registered modules without a running poller (not pollable, or
+		// polling disabled in config) have nothing to stop.
+		if _, err := modules.Get(moduleName); err == nil {
+			return nil
 		}
 		d.logger.Warn("module not found in registry",
 			slog.String("module", moduleName))
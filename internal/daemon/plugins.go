@@ -4,12 +4,15 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"devlog/internal/contextkeys"
+	"devlog/internal/events"
 	"devlog/internal/metrics"
 	"devlog/internal/plugins"
+	"devlog/internal/resources"
 )
 
 type pluginInstance struct {
@@ -29,7 +32,7 @@ func (d *Daemon) startPlugins(ctx context.Context) {
 
 	for _, plugin := range allPlugins {
 		pluginName := plugin.Name()
-		if !d.config.IsPluginEnabled(pluginName) {
+		if !d.getConfig().IsPluginEnabled(pluginName) {
 			d.logger.Debug("plugin disabled, skipping",
 				slog.String("plugin", pluginName))
 			continue
@@ -49,6 +52,36 @@ func (d *Daemon) startPlugins(ctx context.Context) {
 	}
 }
 
+// enrichEvent lets every running plugin that implements
+// plugins.EventEnricher add fields to an event before it is stored.
+func (d *Daemon) enrichEvent(event *events.Event) {
+	d.pluginsMu.RLock()
+	defer d.pluginsMu.RUnlock()
+
+	for _, instance := range d.plugins {
+		if instance.ctx.Err() != nil {
+			continue
+		}
+		if enricher, ok := instance.plugin.(plugins.EventEnricher); ok {
+			enricher.EnrichEvent(event)
+		}
+	}
+}
+
+func (d *Daemon) observeEvent(event *events.Event) {
+	d.pluginsMu.RLock()
+	defer d.pluginsMu.RUnlock()
+
+	for _, instance := range d.plugins {
+		if instance.ctx.Err() != nil {
+			continue
+		}
+		if observer, ok := instance.plugin.(plugins.EventObserver); ok {
+			observer.ObserveEvent(event)
+		}
+	}
+}
+
 func (d *Daemon) resolvePluginDependencies(enabledPlugins []plugins.Plugin) ([]plugins.Plugin, error) {
 	pluginMap := make(map[string]plugins.Plugin)
 	for _, p := range enabledPlugins {
@@ -102,7 +135,7 @@ func (d *Daemon) resolvePluginDependencies(enabledPlugins []plugins.Plugin) ([]p
 }
 
 func (d *Daemon) startPlugin(parentCtx context.Context, plugin plugins.Plugin, pluginName string) {
-	pluginCfgMap, ok := d.config.GetPluginConfig(pluginName)
+	pluginCfgMap, ok := d.getConfig().GetPluginConfig(pluginName)
 	if !ok || pluginCfgMap == nil {
 		d.logger.Debug("plugin has no config, using defaults",
 			slog.String("plugin", pluginName))
@@ -126,8 +159,13 @@ func (d *Daemon) startPlugin(parentCtx context.Context, plugin plugins.Plugin, p
 	d.plugins[pluginName] = instance
 	d.pluginsMu.Unlock()
 
+	component := resources.Component("plugin", pluginName)
 	if initializable, ok := plugin.(plugins.Initializable); ok {
-		if err := initializable.Initialize(pluginConfigCtx); err != nil {
+		var err error
+		resources.Do(pluginConfigCtx, component, func(ctx context.Context) {
+			err = initializable.Initialize(ctx)
+		})
+		if err != nil {
 			d.logger.Error("failed to initialize plugin",
 				slog.String("plugin", pluginName),
 				slog.String("error", err.Error()))
@@ -170,20 +208,98 @@ func (d *Daemon) startPlugin(parentCtx context.Context, plugin plugins.Plugin, p
 	go func() {
 		defer instance.wg.Done()
 		defer d.pluginWG.Done()
+		resources.Label(pluginConfigCtx, component)
+		d.supervisePlugin(pluginConfigCtx, plugin, pluginName)
+	}()
+}
 
+// restartPolicy controls how a plugin whose Start fails or panics is
+// brought back: the delay doubles from BaseDelay up to MaxDelay, and after
+// MaxRestarts consecutive failures the plugin is left stopped until the
+// next config reload or daemon restart. A run that lasted StableAfter
+// resets the count.
+type restartPolicy struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxRestarts int
+	StableAfter time.Duration
+}
+
+var defaultPluginRestartPolicy = restartPolicy{
+	BaseDelay:   time.Second,
+	MaxDelay:    5 * time.Minute,
+	MaxRestarts: 5,
+	StableAfter: 10 * time.Minute,
+}
+
+func (p restartPolicy) delay(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+func (d *Daemon) supervisePlugin(ctx context.Context, plugin plugins.Plugin, pluginName string) {
+	failures := 0
+	for {
 		metrics.GlobalSnapshot.RecordPluginStart(pluginName)
 		d.logger.Info("plugin started", slog.String("plugin", pluginName))
 
-		if err := plugin.Start(pluginConfigCtx); err != nil {
-			metrics.GlobalSnapshot.RecordPluginError(pluginName, err)
-			d.logger.Error("plugin error",
+		started := time.Now()
+		err := d.runPlugin(ctx, plugin, pluginName)
+		if ctx.Err() != nil || err == nil {
+			d.logger.Info("plugin stopped", slog.String("plugin", pluginName))
+			return
+		}
+
+		metrics.GlobalSnapshot.RecordPluginError(pluginName, err)
+		d.logger.Error("plugin error",
+			slog.String("plugin", pluginName),
+			slog.String("error", err.Error()))
+
+		if time.Since(started) >= d.pluginRestart.StableAfter {
+			failures = 0
+		}
+		if failures >= d.pluginRestart.MaxRestarts {
+			metrics.GlobalSnapshot.RecordPluginFailed(pluginName)
+			d.logger.Error("plugin keeps failing, giving up until the next reload",
 				slog.String("plugin", pluginName),
-				slog.String("error", err.Error()))
+				slog.Int("restarts", failures))
+			return
+		}
+
+		delay := d.pluginRestart.delay(failures)
+		failures++
+		d.logger.Warn("restarting plugin after failure",
+			slog.String("plugin", pluginName),
+			slog.Int("attempt", failures),
+			slog.Duration("delay", delay))
+
+		select {
+		case <-ctx.Done():
 			return
+		case <-time.After(delay):
 		}
+		metrics.GlobalSnapshot.RecordPluginRestart(pluginName)
+	}
+}
 
-		d.logger.Info("plugin stopped", slog.String("plugin", pluginName))
+// runPlugin turns a panic in Start into an error so one broken plugin
+// cannot take the daemon down with it.
+func (d *Daemon) runPlugin(ctx context.Context, plugin plugins.Plugin, pluginName string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			d.logger.Error("plugin panicked",
+				slog.String("plugin", pluginName),
+				slog.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("panic: %v", r)
+		}
 	}()
+	return plugin.Start(ctx)
 }
 
 func (d *Daemon) stopPlugin(pluginName string) error {
@@ -243,3 +359,48 @@ func (d *Daemon) restartPlugin(pluginName string) {
 	d.logger.Info("restarting plugin", slog.String("plugin", pluginName))
 	d.startPlugin(d.pluginCtx, plugin, pluginName)
 }
+
+func (d *Daemon) restartPlugins(names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	targets := make(map[string]bool)
+	for _, name := range names {
+		targets[name] = true
+	}
+
+	cfg := d.getConfig()
+	var enabled []plugins.Plugin
+	for _, plugin := range plugins.List() {
+		if cfg.IsPluginEnabled(plugin.Name()) {
+			enabled = append(enabled, plugin)
+		}
+	}
+
+	ordered, err := d.resolvePluginDependencies(enabled)
+	if err != nil {
+		d.logger.Error("failed to resolve plugin dependencies",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	for _, plugin := range ordered {
+		name := plugin.Name()
+		if !targets[name] {
+			for _, dep := range plugin.Metadata().Dependencies {
+				if targets[dep] {
+					d.logger.Info("dependency restarted, restarting dependent plugin",
+						slog.String("plugin", name),
+						slog.String("dependency", dep))
+					targets[name] = true
+					break
+				}
+			}
+		}
+
+		if targets[name] {
+			d.restartPlugin(name)
+		}
+	}
+}
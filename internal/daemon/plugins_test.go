@@ -0,0 +1,206 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/testutil"
+)
+
+type crashingPlugin struct {
+	name    string
+	crashes int
+	panics  bool
+	mu      sync.Mutex
+	starts  int
+}
+
+func (p *crashingPlugin) Name() string                            { return p.name }
+func (p *crashingPlugin) Description() string                     { return "crashing test plugin" }
+func (p *crashingPlugin) Install(ctx *install.Context) error      { return nil }
+func (p *crashingPlugin) Uninstall(ctx *install.Context) error    { return nil }
+func (p *crashingPlugin) DefaultConfig() interface{}              { return nil }
+func (p *crashingPlugin) ValidateConfig(config interface{}) error { return nil }
+func (p *crashingPlugin) Metadata() plugins.Metadata              { return plugins.Metadata{Name: p.name} }
+
+func (p *crashingPlugin) Start(ctx context.Context) error {
+	p.mu.Lock()
+	p.starts++
+	crash := p.crashes < 0 || p.starts <= p.crashes
+	p.mu.Unlock()
+
+	if crash {
+		if p.panics {
+			panic("boom")
+		}
+		return errors.New("connection refused")
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (p *crashingPlugin) startCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.starts
+}
+
+func startCrashingPlugin(t *testing.T, p *crashingPlugin) (*Daemon, context.CancelFunc) {
+	t.Helper()
+	store := testutil.NewTestStorage(t)
+	t.Cleanup(func() { store.Close() })
+
+	if err := plugins.Register(p); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Plugins = map[string]config.ComponentConfig{p.name: {Enabled: true}}
+	d := New(cfg, store)
+	d.pluginRestart = restartPolicy{
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    5 * time.Millisecond,
+		MaxRestarts: 3,
+		StableAfter: time.Hour,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.startPlugins(ctx)
+	return d, cancel
+}
+
+// taggingPlugin marks every event it enriches and counts the ones it
+// observes.
+type taggingPlugin struct {
+	crashingPlugin
+	observed map[string]int
+}
+
+func (p *taggingPlugin) EnrichEvent(event *events.Event) {
+	event.Payload["tagged_by"] = p.name
+}
+
+func (p *taggingPlugin) ObserveEvent(event *events.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.observed[event.ID]++
+}
+
+func TestIngestOverHTTPIsEnriched(t *testing.T) {
+	p := &taggingPlugin{crashingPlugin: crashingPlugin{name: "tagging-test"}, observed: make(map[string]int)}
+	store := testutil.NewTestStorage(t)
+	t.Cleanup(func() { store.Close() })
+	if err := plugins.Register(p); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Plugins = map[string]config.ComponentConfig{p.name: {Enabled: true}}
+	d := New(cfg, store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.startPlugins(ctx)
+
+	srv := httptest.NewServer(d.newAPIServer().SetupRoutes())
+	defer srv.Close()
+
+	event := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	event.Payload["text"] = "follow up on PROJ-12"
+	body, err := event.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The batch re-sends the same event, which must not be observed twice.
+	for _, path := range []string{"/api/v1/ingest", "/api/v1/ingest/batch"} {
+		resp, err := http.Post(srv.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("POST %s status = %d", path, resp.StatusCode)
+		}
+	}
+
+	stored, err := store.GetEventContext(ctx, event.ID)
+	if err != nil {
+		t.Fatalf("event not stored: %v", err)
+	}
+	if stored.Payload["tagged_by"] != p.name {
+		t.Errorf("stored payload = %v, want it enriched by %s", stored.Payload, p.name)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.observed[event.ID] != 1 {
+		t.Errorf("event observed %d times, want once after it was stored", p.observed[event.ID])
+	}
+}
+
+func TestSupervisePluginRestartsAfterPanic(t *testing.T) {
+	p := &crashingPlugin{name: "crash-test-recovers", crashes: 2, panics: true}
+	d, cancel := startCrashingPlugin(t, p)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for p.startCount() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := p.startCount(); got != 3 {
+		t.Fatalf("plugin started %d times, want 3", got)
+	}
+
+	snap := metrics.GlobalSnapshot.Copy()
+	if snap.PluginRestarts[p.name] != 2 {
+		t.Errorf("restarts = %d, want 2", snap.PluginRestarts[p.name])
+	}
+	if snap.PluginLastError[p.name] != "panic: boom" {
+		t.Errorf("last error = %q", snap.PluginLastError[p.name])
+	}
+
+	cancel()
+	d.pluginWG.Wait()
+}
+
+func TestSupervisePluginGivesUp(t *testing.T) {
+	p := &crashingPlugin{name: "crash-test-gives-up", crashes: -1}
+	d, cancel := startCrashingPlugin(t, p)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.pluginWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("supervisor kept restarting past MaxRestarts")
+	}
+
+	if got := p.startCount(); got != 4 {
+		t.Errorf("plugin started %d times, want 4", got)
+	}
+	if status := metrics.GlobalSnapshot.GetSummary().PluginStatus[p.name]; status != "failed" {
+		t.Errorf("status = %q, want failed", status)
+	}
+}
+
+func TestRestartPolicyDelay(t *testing.T) {
+	p := restartPolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for attempt, w := range want {
+		if got := p.delay(attempt); got != w {
+			t.Errorf("delay(%d) = %v, want %v", attempt, got, w)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+
+	"devlog/internal/api"
+	"devlog/internal/assets"
+	"devlog/internal/config"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+)
+
+// PreflightIssue is a mismatch between a module's config and what is
+// actually installed on disk.
+type PreflightIssue struct {
+	Module     string
+	Check      string
+	Message    string
+	Repairable bool
+	Repaired   bool
+}
+
+// Preflight compares the enabled modules in cfg against their installed
+// hooks and wrappers. When repair is set, modules with repairable issues
+// are reinstalled and those issues are marked as repaired.
+func Preflight(cfg *config.Config, ctx *install.Context, repair bool) []PreflightIssue {
+	var issues []PreflightIssue
+
+	results, err := assets.Verify(ctx.DataDir)
+	if err != nil {
+		issues = append(issues, PreflightIssue{Module: "assets", Check: "manifest", Message: err.Error()})
+	}
+
+	tracked := make(map[string][]assets.Result)
+	present := make(map[string]bool)
+	for _, r := range results {
+		tracked[r.Module] = append(tracked[r.Module], r)
+		if r.Status == assets.StatusCurrent || r.Status == assets.StatusModified {
+			present[r.Module] = true
+		}
+	}
+
+	owned := make(map[string]bool)
+	for _, a := range assets.List() {
+		owned[a.Module] = true
+	}
+
+	mods := modules.List()
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name() < mods[j].Name() })
+
+	for _, mod := range mods {
+		name := mod.Name()
+
+		if !cfg.IsModuleEnabled(name) {
+			if present[name] {
+				issues = append(issues, PreflightIssue{
+					Module:  name,
+					Check:   "installed",
+					Message: fmt.Sprintf("hooks are installed but the module is disabled; run 'devlog module uninstall %s' or enable it", name),
+				})
+			}
+			continue
+		}
+
+		var found []PreflightIssue
+		if owned[name] && len(tracked[name]) == 0 {
+			found = append(found, PreflightIssue{
+				Module:     name,
+				Check:      "installed",
+				Message:    "module is enabled but none of its hooks are tracked as installed",
+				Repairable: true,
+			})
+		}
+
+		for _, r := range tracked[name] {
+			switch r.Status {
+			case assets.StatusMissing:
+				found = append(found, PreflightIssue{
+					Module:     name,
+					Check:      "asset",
+					Message:    fmt.Sprintf("%s is missing", r.Path),
+					Repairable: true,
+				})
+			case assets.StatusModified:
+				found = append(found, PreflightIssue{
+					Module:  name,
+					Check:   "asset",
+					Message: fmt.Sprintf("%s was modified locally; run 'devlog module refresh --force' to restore it", r.Path),
+				})
+			}
+		}
+
+		if doctor, ok := mod.(modules.Doctor); ok {
+			for _, c := range doctor.Check(ctx) {
+				if c.OK {
+					continue
+				}
+				found = append(found, PreflightIssue{
+					Module:     name,
+					Check:      c.Name,
+					Message:    c.Message,
+					Repairable: true,
+				})
+			}
+		}
+
+		if repair && hasRepairable(found) {
+			err := mod.Install(ctx)
+			for i := range found {
+				if !found[i].Repairable {
+					continue
+				}
+				if err != nil {
+					found[i].Message += fmt.Sprintf(" (repair failed: %v)", err)
+				} else {
+					found[i].Repaired = true
+				}
+			}
+		}
+
+		issues = append(issues, found...)
+	}
+
+	return issues
+}
+
+func hasRepairable(issues []PreflightIssue) bool {
+	for _, issue := range issues {
+		if issue.Repairable {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Daemon) runPreflight() []PreflightIssue {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		d.logger.Warn("skipping preflight checks", slog.String("error", err.Error()))
+		return nil
+	}
+	homeDir, _ := os.UserHomeDir()
+	configDir, _ := config.ConfigDir()
+
+	cfg := d.getConfig()
+	ctx := &install.Context{
+		ConfigDir: configDir,
+		DataDir:   dataDir,
+		HomeDir:   homeDir,
+		Version:   d.version,
+		Log: func(format string, args ...interface{}) {
+			d.logger.Debug(fmt.Sprintf(format, args...))
+		},
+	}
+
+	issues := Preflight(cfg, ctx, cfg.Daemon.AutoRepair)
+	for _, issue := range issues {
+		attrs := []any{
+			slog.String("module", issue.Module),
+			slog.String("check", issue.Check),
+			slog.String("message", issue.Message),
+		}
+		if issue.Repaired {
+			d.logger.Info("preflight repaired module", attrs...)
+		} else {
+			d.logger.Warn("preflight found config drift", attrs...)
+		}
+	}
+	return issues
+}
+
+func preflightStatus(issues []PreflightIssue) []api.PreflightIssue {
+	status := make([]api.PreflightIssue, 0, len(issues))
+	for _, issue := range issues {
+		status = append(status, api.PreflightIssue{
+			Module:   issue.Module,
+			Check:    issue.Check,
+			Message:  issue.Message,
+			Repaired: issue.Repaired,
+		})
+	}
+	return status
+}
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"devlog/internal/assets"
+	"devlog/internal/config"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+)
+
+type preflightModule struct {
+	binDir string
+}
+
+func (m *preflightModule) Name() string                     { return "preflighttest" }
+func (m *preflightModule) Description() string              { return "preflight test module" }
+func (m *preflightModule) Uninstall(*install.Context) error { return nil }
+func (m *preflightModule) DefaultConfig() interface{}       { return map[string]interface{}{} }
+func (m *preflightModule) ValidateConfig(interface{}) error { return nil }
+
+func (m *preflightModule) Install(ctx *install.Context) error {
+	return ctx.WriteAsset("preflighttest", "wrapper.sh", filepath.Join(m.binDir, "wrapper.sh"), nil)
+}
+
+func (m *preflightModule) Check(ctx *install.Context) []modules.Check {
+	_, err := os.Stat(filepath.Join(m.binDir, "marker"))
+	return []modules.Check{{Name: "marker", OK: err == nil, Message: "marker file not found"}}
+}
+
+func preflightIssuesFor(issues []PreflightIssue, module string) []PreflightIssue {
+	var result []PreflightIssue
+	for _, issue := range issues {
+		if issue.Module == module {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+func TestPreflight(t *testing.T) {
+	binDir := t.TempDir()
+	assets.Register(assets.Asset{Module: "preflighttest", Name: "wrapper.sh", Content: "#!/bin/sh\n", Mode: 0755})
+	mod := &preflightModule{binDir: binDir}
+	if err := modules.Register(mod); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	ctx := &install.Context{DataDir: t.TempDir(), Version: "1.0.0", Log: func(string, ...interface{}) {}}
+	cfg := config.DefaultConfig()
+	cfg.SetModuleEnabled("preflighttest", true)
+
+	issues := preflightIssuesFor(Preflight(cfg, ctx, false), "preflighttest")
+	if len(issues) != 2 || issues[0].Check != "installed" || issues[1].Check != "marker" {
+		t.Fatalf("expected not-installed and marker issues, got %+v", issues)
+	}
+	if _, err := os.Stat(filepath.Join(binDir, "wrapper.sh")); !os.IsNotExist(err) {
+		t.Fatal("preflight without repair should not install anything")
+	}
+
+	issues = preflightIssuesFor(Preflight(cfg, ctx, true), "preflighttest")
+	for _, issue := range issues {
+		if !issue.Repaired {
+			t.Errorf("expected %s issue to be repaired", issue.Check)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(binDir, "wrapper.sh")); err != nil {
+		t.Fatalf("repair should reinstall the wrapper: %v", err)
+	}
+
+	os.WriteFile(filepath.Join(binDir, "marker"), nil, 0644)
+	if issues := preflightIssuesFor(Preflight(cfg, ctx, false), "preflighttest"); len(issues) != 0 {
+		t.Errorf("expected no issues after repair, got %+v", issues)
+	}
+
+	os.WriteFile(filepath.Join(binDir, "wrapper.sh"), []byte("#!/bin/sh\necho edited\n"), 0755)
+	issues = preflightIssuesFor(Preflight(cfg, ctx, true), "preflighttest")
+	if len(issues) != 1 || issues[0].Repairable || issues[0].Repaired {
+		t.Errorf("expected one unrepairable modified issue, got %+v", issues)
+	}
+
+	os.Remove(filepath.Join(binDir, "wrapper.sh"))
+	issues = preflightIssuesFor(Preflight(cfg, ctx, false), "preflighttest")
+	if len(issues) != 1 || issues[0].Check != "asset" || !issues[0].Repairable {
+		t.Errorf("expected one missing asset issue, got %+v", issues)
+	}
+
+	cfg.SetModuleEnabled("preflighttest", false)
+	if issues := preflightIssuesFor(Preflight(cfg, ctx, false), "preflighttest"); len(issues) != 0 {
+		t.Errorf("expected no issues for a disabled module with nothing on disk, got %+v", issues)
+	}
+
+	if err := mod.Install(ctx); err != nil {
+		t.Fatalf("Install failed: %v", err)
+	}
+	issues = preflightIssuesFor(Preflight(cfg, ctx, false), "preflighttest")
+	if len(issues) != 1 || issues[0].Check != "installed" || issues[0].Repairable {
+		t.Errorf("expected disabled-but-installed issue, got %+v", issues)
+	}
+}
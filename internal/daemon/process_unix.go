@@ -0,0 +1,48 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// detach starts the daemon in a session of its own, so it outlives the
+// terminal that started it.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid: true,
+	}
+}
+
+// processAlive probes pid with signal 0, which checks the process exists
+// without disturbing it.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// requestStop asks the daemon with pid to shut down gracefully.
+func requestStop(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("find process: %w", err)
+	}
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("send SIGTERM: %w", err)
+	}
+	return nil
+}
+
+// notifyStop relays the requests to shut down this daemon to ch: Ctrl-C
+// and SIGTERM.
+func notifyStop(ch chan<- os.Signal) error {
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+	return nil
+}
@@ -0,0 +1,109 @@
+//go:build windows
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a process
+// that has not exited.
+const stillActive = 259
+
+// detach starts the daemon without a console, in a process group of its
+// own, so closing the terminal that started it neither kills it nor pops
+// up a window.
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+		HideWindow:    true,
+	}
+}
+
+// processAlive opens pid to read its exit code. A process the caller may
+// not open, such as a daemon running as a service under another account,
+// counts as alive.
+func processAlive(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return errors.Is(err, windows.ERROR_ACCESS_DENIED)
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
+
+// stopEventName names the event a daemon waits on for requests to shut
+// down. Windows has no SIGTERM to send a detached process, so the event
+// stands in for it.
+func stopEventName(pid int) string {
+	return fmt.Sprintf(`Local\devlog-stop-%d`, pid)
+}
+
+// requestStop asks the daemon with pid to shut down gracefully by setting
+// its stop event. A daemon running as a service lives in another session,
+// where the event cannot be reached, so it is stopped through the service
+// manager instead.
+func requestStop(pid int) error {
+	name, err := windows.UTF16PtrFromString(stopEventName(pid))
+	if err != nil {
+		return err
+	}
+	event, err := windows.OpenEvent(windows.EVENT_MODIFY_STATE, false, name)
+	if err != nil {
+		if serviceErr := stopService(); serviceErr != nil {
+			return fmt.Errorf("open stop event: %w; stop service: %v", err, serviceErr)
+		}
+		return nil
+	}
+	defer windows.CloseHandle(event)
+
+	if err := windows.SetEvent(event); err != nil {
+		return fmt.Errorf("set stop event: %w", err)
+	}
+	return nil
+}
+
+// notifyStop relays the requests to shut down this daemon to ch: Ctrl-C
+// in its console, the stop event set by `devlog daemon stop`, and a stop
+// from the service manager.
+func notifyStop(ch chan<- os.Signal) error {
+	signal.Notify(ch, os.Interrupt)
+	go func() {
+		<-serviceStop
+		select {
+		case ch <- os.Interrupt:
+		default:
+		}
+	}()
+
+	name, err := windows.UTF16PtrFromString(stopEventName(os.Getpid()))
+	if err != nil {
+		return err
+	}
+	event, err := windows.CreateEvent(nil, 1, 0, name)
+	if err != nil {
+		return fmt.Errorf("create stop event: %w", err)
+	}
+	go func() {
+		defer windows.CloseHandle(event)
+		if _, err := windows.WaitForSingleObject(event, windows.INFINITE); err == nil {
+			select {
+			case ch <- os.Interrupt:
+			default:
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,183 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/metrics"
+	"devlog/internal/resources"
+)
+
+const (
+	// budgetRestartAfter is how many samples in a row a component must be
+	// over budget before a restart action restarts it.
+	budgetRestartAfter = 2
+	// budgetMaxRestarts caps budget restarts of one component per daemon
+	// run. Go cannot kill goroutines, so a component that ignores
+	// cancellation keeps its goroutines through a restart; after this many
+	// restarts it is only reported.
+	budgetMaxRestarts = 3
+)
+
+// budgetTarget is a running plugin or module poller with a resource budget.
+type budgetTarget struct {
+	kind      string // "plugin" or "module"
+	name      string
+	component string // resources label, e.g. "poller/git"
+	budget    config.ResourceBudget
+}
+
+// resourceWatch is the watchdog's memory between samples.
+type resourceWatch struct {
+	over     map[string]int
+	restarts map[string]int
+	lastCPU  float64
+	lastAt   time.Time
+}
+
+// startResourceWatchdog samples what each plugin and poller uses every
+// daemon.resources.interval_seconds and checks it against the component's
+// resources budget. The config is read on every sample, so budgets can be
+// changed without a restart.
+func (d *Daemon) startResourceWatchdog(ctx context.Context) {
+	watch := &resourceWatch{
+		over:     make(map[string]int),
+		restarts: make(map[string]int),
+		lastCPU:  resources.ReadRuntime().CPUSeconds,
+		lastAt:   time.Now(),
+	}
+	go func() {
+		for {
+			timer := time.NewTimer(d.getConfig().Daemon.Resources.Interval())
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				d.logger.Debug("resource watchdog stopped")
+				return
+			case <-timer.C:
+				d.checkResources(ctx, watch)
+			}
+		}
+	}()
+}
+
+func (d *Daemon) checkResources(ctx context.Context, watch *resourceWatch) {
+	cfg := d.getConfig()
+	if cfg.Daemon.Resources.Disabled {
+		return
+	}
+	targets := d.budgetTargets(cfg)
+
+	var cpu map[string]time.Duration
+	window := cfg.Daemon.Resources.CPUWindow()
+	for _, t := range targets {
+		if t.budget.MaxCPUPercent > 0 {
+			var err error
+			cpu, err = resources.SampleCPU(ctx, window)
+			if errors.Is(err, resources.ErrProfiling) {
+				d.logger.Debug("skipping CPU budgets while another CPU profile runs")
+			} else if err != nil {
+				if ctx.Err() == nil {
+					d.logger.Warn("failed to sample CPU", slog.String("error", err.Error()))
+				}
+				return
+			}
+			break
+		}
+	}
+
+	goroutines, err := resources.Goroutines()
+	if err != nil {
+		d.logger.Warn("failed to count goroutines", slog.String("error", err.Error()))
+		return
+	}
+
+	now := time.Now()
+	usage := make(map[string]metrics.ResourceUsage, len(goroutines))
+	for component, n := range goroutines {
+		usage[component] = metrics.ResourceUsage{Goroutines: n, SampledAt: now}
+	}
+	for component, t := range cpu {
+		u := usage[component]
+		u.CPUPercent = t.Seconds() / window.Seconds() * 100
+		u.SampledAt = now
+		usage[component] = u
+	}
+
+	for _, t := range targets {
+		u := usage[t.component]
+		u.OverBudget = t.budget.Check(u.Goroutines, u.CPUPercent, cpu != nil)
+		if len(u.OverBudget) == 0 {
+			delete(watch.over, t.component)
+			continue
+		}
+		usage[t.component] = u
+		watch.over[t.component]++
+
+		d.logger.Warn(t.kind+" is over its resource budget",
+			slog.String(t.kind, t.name),
+			slog.String("over", strings.Join(u.OverBudget, "; ")))
+
+		if t.budget.Action != config.BudgetActionRestart || watch.over[t.component] < budgetRestartAfter {
+			continue
+		}
+		if watch.restarts[t.component] >= budgetMaxRestarts {
+			if watch.over[t.component] == budgetRestartAfter {
+				d.logger.Error(t.kind+" stays over its resource budget after restarts, no longer restarting it",
+					slog.String(t.kind, t.name),
+					slog.Int("restarts", budgetMaxRestarts))
+			}
+			continue
+		}
+		delete(watch.over, t.component)
+		watch.restarts[t.component]++
+		metrics.GlobalSnapshot.RecordBudgetRestart(t.component)
+		d.logger.Warn("restarting "+t.kind+" over its resource budget", slog.String(t.kind, t.name))
+		d.restartExtension(t.kind, t.name)
+	}
+
+	rt := resources.ReadRuntime()
+	var heapOver string
+	if limit := cfg.Daemon.Resources.MaxHeapMB; limit > 0 && rt.HeapBytes > uint64(limit)<<20 {
+		heapOver = fmt.Sprintf("%d MB heap, budget %d MB", rt.HeapBytes>>20, limit)
+		d.logger.Warn("daemon heap is over its budget",
+			slog.Uint64("heap_mb", rt.HeapBytes>>20),
+			slog.Int("max_heap_mb", limit))
+	}
+	var daemonCPU float64
+	if elapsed := now.Sub(watch.lastAt).Seconds(); elapsed > 0 {
+		daemonCPU = (rt.CPUSeconds - watch.lastCPU) / elapsed * 100
+	}
+	watch.lastCPU, watch.lastAt = rt.CPUSeconds, now
+
+	metrics.GlobalSnapshot.RecordResources(usage, rt.HeapBytes, heapOver, daemonCPU)
+}
+
+// budgetTargets lists the running plugins and module pollers that have a
+// resource budget.
+func (d *Daemon) budgetTargets(cfg *config.Config) []budgetTarget {
+	var targets []budgetTarget
+
+	d.pluginsMu.RLock()
+	for name := range d.plugins {
+		if budget := cfg.PluginBudget(name); !budget.IsZero() {
+			targets = append(targets, budgetTarget{"plugin", name, resources.Component("plugin", name), budget})
+		}
+	}
+	d.pluginsMu.RUnlock()
+
+	d.modulesMu.RLock()
+	for name, pollerName := range d.modules {
+		if budget := cfg.ModuleBudget(name); !budget.IsZero() {
+			targets = append(targets, budgetTarget{"module", name, resources.Component("poller", pollerName), budget})
+		}
+	}
+	d.modulesMu.RUnlock()
+
+	return targets
+}
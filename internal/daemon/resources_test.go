@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/install"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/testutil"
+)
+
+// leakyPlugin starts more goroutines than its budget allows.
+type leakyPlugin struct {
+	mu     sync.Mutex
+	starts int
+}
+
+func (p *leakyPlugin) Name() string                            { return "leaky-test" }
+func (p *leakyPlugin) Description() string                     { return "leaky test plugin" }
+func (p *leakyPlugin) Install(ctx *install.Context) error      { return nil }
+func (p *leakyPlugin) Uninstall(ctx *install.Context) error    { return nil }
+func (p *leakyPlugin) DefaultConfig() interface{}              { return nil }
+func (p *leakyPlugin) ValidateConfig(config interface{}) error { return nil }
+func (p *leakyPlugin) Metadata() plugins.Metadata              { return plugins.Metadata{Name: p.Name()} }
+
+func (p *leakyPlugin) Start(ctx context.Context) error {
+	p.mu.Lock()
+	p.starts++
+	p.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ctx.Done()
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+func (p *leakyPlugin) startCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.starts
+}
+
+func TestResourceWatchdogRestartsPluginOverBudget(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	defer store.Close()
+
+	p := &leakyPlugin{}
+	if err := plugins.Register(p); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Plugins = map[string]config.ComponentConfig{p.Name(): {Enabled: true, Config: map[string]interface{}{
+		"resources": map[string]interface{}{"max_goroutines": 3, "action": "restart"},
+	}}}
+	d := New(cfg, store)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		d.pluginWG.Wait()
+	}()
+	d.startPlugins(ctx)
+
+	watch := &resourceWatch{over: make(map[string]int), restarts: make(map[string]int), lastAt: time.Now()}
+	deadline := time.Now().Add(2 * time.Second)
+	for metrics.GlobalSnapshot.Copy().Resources["plugin/leaky-test"].Goroutines < 6 {
+		if time.Now().After(deadline) {
+			t.Fatal("plugin goroutines were not attributed to it")
+		}
+		d.checkResources(ctx, watch)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	warnings := strings.Join(metrics.GlobalSnapshot.GetSummary().ResourceWarnings, "\n")
+	if !strings.Contains(warnings, "plugin/leaky-test: 6 goroutines, budget 3") {
+		t.Errorf("resource warnings = %q", warnings)
+	}
+	if p.startCount() != 1 {
+		t.Fatalf("plugin restarted after one sample over budget")
+	}
+
+	d.checkResources(ctx, watch)
+	for p.startCount() < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := p.startCount(); got != 2 {
+		t.Errorf("plugin started %d times, want a restart after two samples over budget", got)
+	}
+	if restarts := metrics.GlobalSnapshot.Copy().Resources["plugin/leaky-test"].Restarts; restarts != 1 {
+		t.Errorf("budget restarts = %d, want 1", restarts)
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+
+package daemon
+
+import "fmt"
+
+var errServiceUnsupported = fmt.Errorf("the daemon only runs as a service on Windows; use your init system or a login item elsewhere")
+
+// IsService reports whether the process was started by the Windows service
+// manager, which never happens outside Windows.
+func IsService() bool {
+	return false
+}
+
+func RunService(run func() error) error {
+	return errServiceUnsupported
+}
+
+func InstallService() error {
+	return errServiceUnsupported
+}
+
+func UninstallService() error {
+	return errServiceUnsupported
+}
+
+func ServiceStatus() (string, error) {
+	return "", errServiceUnsupported
+}
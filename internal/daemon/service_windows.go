@@ -0,0 +1,258 @@
+//go:build windows
+
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// serviceStop is closed when the service manager asks the daemon to stop;
+// notifyStop turns it into a shutdown request.
+var (
+	serviceStop     = make(chan struct{})
+	serviceStopOnce sync.Once
+)
+
+// IsService reports whether the process was started by the Windows service
+// manager.
+func IsService() bool {
+	isService, err := svc.IsWindowsService()
+	return err == nil && isService
+}
+
+// RunService runs the daemon as a Windows service: run is called once and
+// the service stops when it returns. Stop and shutdown requests from the
+// service manager shut the daemon down like `devlog daemon stop`.
+func RunService(run func() error) error {
+	return svc.Run(ServiceName, &serviceHandler{run: run})
+}
+
+type serviceHandler struct {
+	run func() error
+}
+
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- h.run()
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				// A service-specific exit code marks the stop as a failure,
+				// so recovery actions restart the daemon.
+				return true, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				serviceStopOnce.Do(func() { close(serviceStop) })
+			}
+		}
+	}
+}
+
+// InstallService registers the running executable as a service that
+// starts the daemon at boot, then starts it. The service runs as
+// LocalSystem with the installing user's profile directories in its
+// environment, so it reads that user's config and database. It needs an
+// elevated prompt.
+func InstallService() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("find executable: %w", err)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager (run from an elevated prompt): %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(ServiceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed", ServiceName)
+	}
+
+	s, err := m.CreateService(ServiceName, executable, mgr.Config{
+		DisplayName: "devlog",
+		Description: "Captures development activity for devlog.",
+		StartType:   mgr.StartAutomatic,
+	}, "daemon", "start", "--foreground")
+	if err != nil {
+		return fmt.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+
+	if err := setServiceEnvironment(); err != nil {
+		s.Delete()
+		return err
+	}
+	recovery := []mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: 5 * time.Second}}
+	if err := s.SetRecoveryActions(recovery, uint32((24 * time.Hour).Seconds())); err != nil {
+		s.Delete()
+		return fmt.Errorf("set recovery actions: %w", err)
+	}
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("start service: %w", err)
+	}
+	return nil
+}
+
+// setServiceEnvironment stores the variables the service starts with. The
+// service manager reads them from the Environment value of the service's
+// registry key.
+func setServiceEnvironment() error {
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+ServiceName, registry.SET_VALUE)
+	if err != nil {
+		return fmt.Errorf("open service registry key: %w", err)
+	}
+	defer key.Close()
+
+	if err := key.SetStringsValue("Environment", serviceEnvironment(os.Getenv)); err != nil {
+		return fmt.Errorf("set service environment: %w", err)
+	}
+	return nil
+}
+
+// serviceEnvironment lists the variables the service starts with: the
+// installing user's profile directories, so config.ConfigDir resolves to
+// their %APPDATA%, and the marker the daemon sets for its own subprocess.
+func serviceEnvironment(getenv func(string) string) []string {
+	env := []string{"DEVLOG_DAEMON_SUBPROCESS=1"}
+	for _, name := range []string{"APPDATA", "LOCALAPPDATA", "USERPROFILE"} {
+		if value := getenv(name); value != "" {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// UninstallService stops the service if it is running and removes it. It
+// needs an elevated prompt.
+func UninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager (run from an elevated prompt): %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(ServiceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", ServiceName)
+	}
+	defer s.Close()
+
+	if err := waitServiceStopped(s); err != nil {
+		return err
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("delete service: %w", err)
+	}
+	return nil
+}
+
+// ServiceStatus describes the state of the service, or reports that it is
+// not installed.
+func ServiceStatus() (string, error) {
+	s, closeService, err := openService(windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return "", err
+	}
+	defer closeService()
+
+	status, err := s.Query()
+	if err != nil {
+		return "", fmt.Errorf("query service: %w", err)
+	}
+	switch status.State {
+	case svc.Running:
+		return fmt.Sprintf("running (PID %d)", status.ProcessId), nil
+	case svc.StartPending:
+		return "starting", nil
+	case svc.StopPending:
+		return "stopping", nil
+	case svc.Stopped:
+		return "stopped", nil
+	default:
+		return fmt.Sprintf("state %d", status.State), nil
+	}
+}
+
+// stopService stops the service and waits for the daemon to exit.
+func stopService() error {
+	s, closeService, err := openService(windows.SERVICE_STOP | windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return err
+	}
+	defer closeService()
+	return waitServiceStopped(s)
+}
+
+func waitServiceStopped(s *mgr.Service) error {
+	status, err := s.Query()
+	if err != nil {
+		return fmt.Errorf("query service: %w", err)
+	}
+	if status.State == svc.Stopped {
+		return nil
+	}
+	if status.State != svc.StopPending {
+		if _, err := s.Control(svc.Stop); err != nil {
+			return fmt.Errorf("stop service: %w", err)
+		}
+	}
+	for i := 0; i < StopDaemonMaxAttempts; i++ {
+		time.Sleep(StopDaemonPollInterval)
+		if status, err = s.Query(); err == nil && status.State == svc.Stopped {
+			return nil
+		}
+	}
+	return fmt.Errorf("service did not stop after %d attempts", StopDaemonMaxAttempts)
+}
+
+// openService opens the service with only the access it needs, since the
+// full access mgr asks for is reserved to administrators.
+func openService(access uint32) (*mgr.Service, func(), error) {
+	manager, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect to service manager: %w", err)
+	}
+	name, err := windows.UTF16PtrFromString(ServiceName)
+	if err != nil {
+		windows.CloseServiceHandle(manager)
+		return nil, nil, err
+	}
+	handle, err := windows.OpenService(manager, name, access)
+	if err != nil {
+		windows.CloseServiceHandle(manager)
+		if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+			return nil, nil, fmt.Errorf("service %s is not installed", ServiceName)
+		}
+		return nil, nil, fmt.Errorf("open service: %w", err)
+	}
+	closeService := func() {
+		windows.CloseServiceHandle(handle)
+		windows.CloseServiceHandle(manager)
+	}
+	return &mgr.Service{Name: ServiceName, Handle: handle}, closeService, nil
+}
@@ -0,0 +1,132 @@
+//go:build windows
+
+package daemon
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+func nextStatus(t *testing.T, status <-chan svc.Status) svc.Status {
+	t.Helper()
+	select {
+	case s := <-status:
+		return s
+	case <-time.After(5 * time.Second):
+		t.Fatal("no status reported")
+		return svc.Status{}
+	}
+}
+
+func TestServiceHandlerRunError(t *testing.T) {
+	requests := make(chan svc.ChangeRequest)
+	status := make(chan svc.Status, 4)
+	h := &serviceHandler{run: func() error { return errors.New("bind failed") }}
+
+	specific, code := h.Execute(nil, requests, status)
+	if !specific || code != 1 {
+		t.Errorf("Execute() = %v, %d, want a service-specific exit code 1 so recovery restarts it", specific, code)
+	}
+	if s := nextStatus(t, status); s.State != svc.StartPending {
+		t.Errorf("first status = %d, want StartPending", s.State)
+	}
+	if s := nextStatus(t, status); s.State != svc.Running || s.Accepts != svc.AcceptStop|svc.AcceptShutdown {
+		t.Errorf("second status = %+v, want Running accepting stop and shutdown", s)
+	}
+}
+
+func TestServiceHandlerStop(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  svc.Cmd
+	}{
+		{"stop", svc.Stop},
+		{"shutdown", svc.Shutdown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceStop = make(chan struct{})
+			serviceStopOnce = sync.Once{}
+
+			requests := make(chan svc.ChangeRequest)
+			status := make(chan svc.Status, 4)
+			h := &serviceHandler{run: func() error {
+				<-serviceStop
+				return nil
+			}}
+
+			type result struct {
+				specific bool
+				code     uint32
+			}
+			done := make(chan result, 1)
+			go func() {
+				specific, code := h.Execute(nil, requests, status)
+				done <- result{specific, code}
+			}()
+			nextStatus(t, status)
+			nextStatus(t, status)
+
+			current := svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+			requests <- svc.ChangeRequest{Cmd: svc.Interrogate, CurrentStatus: current}
+			if s := nextStatus(t, status); s != current {
+				t.Errorf("Interrogate reported %+v, want %+v", s, current)
+			}
+
+			requests <- svc.ChangeRequest{Cmd: tt.cmd}
+			if s := nextStatus(t, status); s.State != svc.StopPending {
+				t.Errorf("status after %s = %d, want StopPending", tt.name, s.State)
+			}
+			select {
+			case r := <-done:
+				if r.specific || r.code != 0 {
+					t.Errorf("Execute() after %s = %v, %d, want a clean stop", tt.name, r.specific, r.code)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Execute() did not return after %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestServiceEnvironment(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "profile directories",
+			env: map[string]string{
+				"APPDATA":      `C:\Users\ada\AppData\Roaming`,
+				"LOCALAPPDATA": `C:\Users\ada\AppData\Local`,
+				"USERPROFILE":  `C:\Users\ada`,
+				"PATH":         `C:\Windows`,
+			},
+			want: []string{
+				"DEVLOG_DAEMON_SUBPROCESS=1",
+				`APPDATA=C:\Users\ada\AppData\Roaming`,
+				`LOCALAPPDATA=C:\Users\ada\AppData\Local`,
+				`USERPROFILE=C:\Users\ada`,
+			},
+		},
+		{
+			name: "unset variables are left out",
+			env:  map[string]string{"APPDATA": `C:\Users\ada\AppData\Roaming`},
+			want: []string{"DEVLOG_DAEMON_SUBPROCESS=1", `APPDATA=C:\Users\ada\AppData\Roaming`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serviceEnvironment(func(name string) string { return tt.env[name] })
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("serviceEnvironment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
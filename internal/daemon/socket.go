@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+
+	"devlog/internal/api"
+)
+
+// serveSocket serves the API on the Unix socket from http.socket as well as
+// the TCP port. Local clients prefer the socket, so they keep working when
+// the port is taken and need no API token: the socket is created with mode
+// 0600, so only the daemon's user can connect. Failing to listen only
+// costs the socket; the TCP port still works.
+func (d *Daemon) serveSocket(handler http.Handler) {
+	path, err := d.config.HTTP.SocketPath()
+	if err != nil {
+		d.logger.Warn("failed to resolve socket path", slog.String("error", err.Error()))
+		return
+	}
+	if path == "" {
+		return
+	}
+
+	// A daemon that crashed leaves its socket behind, and listening on an
+	// existing path fails. Only the PID check in preStartupValidation lets
+	// us get here, so no other daemon owns it.
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		d.logger.Warn("failed to listen on unix socket; serving on TCP only",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+		return
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		d.logger.Warn("failed to restrict unix socket; serving on TCP only",
+			slog.String("path", path),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	d.socketPath = path
+	d.socketServer = &http.Server{
+		Handler:     handler,
+		ConnContext: api.SocketConnContext,
+	}
+	go func() {
+		if err := d.socketServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			d.logger.Warn("unix socket server stopped", slog.String("error", err.Error()))
+		}
+	}()
+	d.logger.Info("serving API on unix socket", slog.String("path", path))
+}
+
+// stopSocket shuts the socket server down and removes the socket, so
+// clients go back to TCP.
+func (d *Daemon) stopSocket(ctx context.Context) {
+	if d.socketServer == nil {
+		return
+	}
+	if err := d.socketServer.Shutdown(ctx); err != nil {
+		d.logger.Debug("error during socket server shutdown", slog.String("error", err.Error()))
+	}
+	os.Remove(d.socketPath)
+	d.socketServer = nil
+}
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+	"devlog/internal/storage"
+)
+
+// ModuleStatus is the health of one enabled module: whether its hooks are
+// installed and whether they are still producing events.
+type ModuleStatus struct {
+	Module  string
+	Sources []string
+	// LastEvent is the time of the newest event from any of Sources, zero
+	// when there is none.
+	LastEvent  time.Time
+	LastSource string
+	QuietAfter time.Duration
+	// Quiet is set when the module has a quiet threshold and no event
+	// arrived within it.
+	Quiet  bool
+	Checks []modules.Check
+	Issues []PreflightIssue
+}
+
+func (s ModuleStatus) Healthy() bool {
+	if s.Quiet || len(s.Issues) > 0 {
+		return false
+	}
+	for _, c := range s.Checks {
+		if !c.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// ModuleStatuses reports on every enabled module, combining the preflight
+// checks of its installed hooks, its own health checks and the newest event
+// recorded from its sources.
+func ModuleStatuses(ctx context.Context, cfg *config.Config, ictx *install.Context, store storage.Store, now time.Time) ([]ModuleStatus, error) {
+	issues := make(map[string][]PreflightIssue)
+	for _, issue := range Preflight(cfg, ictx, false) {
+		issues[issue.Module] = append(issues[issue.Module], issue)
+	}
+
+	mods := modules.List()
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Name() < mods[j].Name() })
+
+	var statuses []ModuleStatus
+	for _, mod := range mods {
+		name := mod.Name()
+		if !cfg.IsModuleEnabled(name) {
+			continue
+		}
+
+		health := modules.Health{Sources: []string{name}}
+		if checker, ok := mod.(modules.HealthChecker); ok {
+			health = checker.HealthCheck(ictx)
+		}
+
+		status := ModuleStatus{
+			Module:     name,
+			Sources:    health.Sources,
+			QuietAfter: health.QuietAfter,
+			Checks:     health.Checks,
+			Issues:     issues[name],
+		}
+
+		for _, source := range health.Sources {
+			evts, err := store.QueryEventsContext(ctx, storage.QueryOptions{Source: source, Limit: 1})
+			if err != nil {
+				return nil, fmt.Errorf("query %s events: %w", source, err)
+			}
+			if len(evts) == 0 {
+				continue
+			}
+			at, err := time.Parse(time.RFC3339, evts[0].Timestamp)
+			if err != nil {
+				continue
+			}
+			if at.After(status.LastEvent) {
+				status.LastEvent = at
+				status.LastSource = source
+			}
+		}
+
+		if status.QuietAfter > 0 && now.Sub(status.LastEvent) > status.QuietAfter {
+			status.Quiet = true
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
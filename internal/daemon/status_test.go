@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+	"devlog/internal/testutil"
+)
+
+type statusModule struct {
+	pathOK bool
+}
+
+func (m *statusModule) Name() string                     { return "statustest" }
+func (m *statusModule) Description() string              { return "status test module" }
+func (m *statusModule) Install(*install.Context) error   { return nil }
+func (m *statusModule) Uninstall(*install.Context) error { return nil }
+func (m *statusModule) DefaultConfig() interface{}       { return map[string]interface{}{} }
+func (m *statusModule) ValidateConfig(interface{}) error { return nil }
+
+func (m *statusModule) HealthCheck(*install.Context) modules.Health {
+	return modules.Health{
+		Sources:    []string{string(events.SourceGit), string(events.SourceShell)},
+		QuietAfter: time.Hour,
+		Checks:     []modules.Check{{Name: "path", OK: m.pathOK, Message: "wrapper not on PATH"}},
+	}
+}
+
+func statusFor(t *testing.T, statuses []ModuleStatus, module string) ModuleStatus {
+	t.Helper()
+	for _, s := range statuses {
+		if s.Module == module {
+			return s
+		}
+	}
+	t.Fatalf("no status for %s in %+v", module, statuses)
+	return ModuleStatus{}
+}
+
+func TestModuleStatuses(t *testing.T) {
+	mod := &statusModule{pathOK: true}
+	if err := modules.Register(mod); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	store := testutil.NewTestStorage(t)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	gitEvent := testutil.NewTestEvent(string(events.SourceGit), string(events.TypeCommit))
+	gitEvent.Timestamp = now.Add(-2 * time.Hour).Format(time.RFC3339)
+	shellEvent := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+	shellEvent.Timestamp = now.Add(-30 * time.Minute).Format(time.RFC3339)
+	testutil.MustInsertEvents(t, store, gitEvent, shellEvent)
+
+	ctx := &install.Context{DataDir: t.TempDir(), Version: "1.0.0", Log: func(string, ...interface{}) {}}
+	cfg := config.DefaultConfig()
+	cfg.SetModuleEnabled("statustest", true)
+
+	statuses, err := ModuleStatuses(context.Background(), cfg, ctx, store, now)
+	if err != nil {
+		t.Fatalf("ModuleStatuses failed: %v", err)
+	}
+	status := statusFor(t, statuses, "statustest")
+	if status.LastSource != "shell" || !status.LastEvent.Equal(now.Add(-30*time.Minute)) {
+		t.Errorf("last event = %s from %q, want the shell event", status.LastEvent, status.LastSource)
+	}
+	if !status.Healthy() {
+		t.Errorf("expected healthy status, got %+v", status)
+	}
+
+	mod.pathOK = false
+	statuses, err = ModuleStatuses(context.Background(), cfg, ctx, store, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("ModuleStatuses failed: %v", err)
+	}
+	status = statusFor(t, statuses, "statustest")
+	if !status.Quiet || status.Healthy() {
+		t.Errorf("expected quiet, unhealthy status, got %+v", status)
+	}
+
+	cfg.SetModuleEnabled("statustest", false)
+	statuses, err = ModuleStatuses(context.Background(), cfg, ctx, store, now)
+	if err != nil {
+		t.Fatalf("ModuleStatuses failed: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Module == "statustest" {
+			t.Errorf("disabled module should not be reported, got %+v", s)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package daemon
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"devlog/internal/config"
+	"devlog/internal/tlscert"
+)
+
+// prepareTLS resolves the certificate the API is served with, generating the
+// self-signed one on first use. Certificates are read once at startup, so
+// replacing them needs a restart.
+func (d *Daemon) prepareTLS() error {
+	httpCfg := d.config.HTTP
+	if !httpCfg.TLSEnabled() {
+		return nil
+	}
+
+	certPath, keyPath, err := httpCfg.TLSFiles()
+	if err != nil {
+		return err
+	}
+
+	if httpCfg.TLS.SelfSigned {
+		dir, err := config.TLSDir()
+		if err != nil {
+			return err
+		}
+		if certPath, keyPath, err = tlscert.LoadOrCreate(dir, tlscert.DefaultHosts(httpCfg.BindAddress)); err != nil {
+			return err
+		}
+	} else {
+		for _, path := range []string{certPath, keyPath} {
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("tls file: %w", err)
+			}
+		}
+	}
+
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+
+	attrs := []any{slog.String("cert", certPath)}
+	if cert, err := tlscert.Load(certPath); err == nil {
+		attrs = append(attrs, slog.String("sha256", tlscert.Fingerprint(cert)))
+	}
+	d.logger.Info("serving API over TLS", attrs...)
+
+	d.server.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	d.tlsCert, d.tlsKey = certPath, keyPath
+	return nil
+}
@@ -0,0 +1,182 @@
+package encryption
+
+import (
+	"encoding/hex"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCipherRoundTrip(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+
+	plaintext := `{"command":"git push","exit_code":0}`
+	enc, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !IsEncrypted(enc) {
+		t.Fatalf("expected %q prefix, got %q", Prefix, enc)
+	}
+	if strings.Contains(enc, "git push") {
+		t.Fatal("ciphertext leaks plaintext")
+	}
+
+	again, _ := c.Encrypt(plaintext)
+	if again == enc {
+		t.Error("expected a fresh nonce per encryption")
+	}
+
+	dec, err := c.Decrypt(enc)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if dec != plaintext {
+		t.Errorf("expected %q, got %q", plaintext, dec)
+	}
+
+	passthrough, err := c.Decrypt(plaintext)
+	if err != nil || passthrough != plaintext {
+		t.Errorf("expected plaintext to pass through, got %q, %v", passthrough, err)
+	}
+}
+
+func TestCipherWrongKey(t *testing.T) {
+	k1, _ := GenerateKey()
+	k2, _ := GenerateKey()
+	c1, _ := NewCipher(k1)
+	c2, _ := NewCipher(k2)
+
+	if c1.KeyID() == c2.KeyID() {
+		t.Fatal("expected distinct key ids")
+	}
+
+	enc, err := c1.Encrypt(`{"a":1}`)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if _, err := c2.Decrypt(enc); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt, got %v", err)
+	}
+	if _, err := c1.Decrypt(Prefix + "not-base64!"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("expected ErrDecrypt for corrupt data, got %v", err)
+	}
+}
+
+func TestNewCipherRejectsShortKey(t *testing.T) {
+	if _, err := NewCipher(make([]byte, 16)); err == nil {
+		t.Error("expected error for 16-byte key")
+	}
+}
+
+func TestLoadKeyFromEnv(t *testing.T) {
+	key, _ := GenerateKey()
+	t.Setenv(EnvKey, hex.EncodeToString(key))
+
+	loaded, err := LoadKey()
+	if err != nil {
+		t.Fatalf("LoadKey failed: %v", err)
+	}
+	if hex.EncodeToString(loaded) != hex.EncodeToString(key) {
+		t.Error("loaded key does not match")
+	}
+	if err := StoreKey(key); err == nil {
+		t.Error("expected StoreKey to refuse while env override is set")
+	}
+
+	t.Setenv(EnvKey, "abcd")
+	if _, err := LoadKey(); err == nil {
+		t.Error("expected error for short env key")
+	}
+}
+
+func TestKeychainSecretTool(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("secret-tool backend is only used on linux")
+	}
+	t.Setenv(EnvKey, "")
+
+	stored := map[string]string{}
+	orig := runCommand
+	runCommand = func(stdin string, name string, args ...string) (string, error) {
+		if name != "secret-tool" {
+			t.Fatalf("unexpected command %s", name)
+		}
+		switch args[0] {
+		case "store":
+			stored["key"] = stdin
+		case "lookup":
+			return stored["key"], nil
+		case "clear":
+			delete(stored, "key")
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { runCommand = orig })
+
+	if _, err := LoadKey(); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("expected ErrNoKey, got %v", err)
+	}
+
+	key, _ := GenerateKey()
+	if err := StoreKey(key); err != nil {
+		t.Fatalf("StoreKey failed: %v", err)
+	}
+	if stored["key"] != hex.EncodeToString(key) {
+		t.Error("expected key to be passed to secret-tool as hex on stdin")
+	}
+
+	loaded, err := LoadKey()
+	if err != nil {
+		t.Fatalf("LoadKey failed: %v", err)
+	}
+	if KeyID(loaded) != KeyID(key) {
+		t.Error("loaded key does not match stored key")
+	}
+
+	if err := DeleteKey(); err != nil {
+		t.Fatalf("DeleteKey failed: %v", err)
+	}
+	if _, err := LoadKey(); !errors.Is(err, ErrNoKey) {
+		t.Errorf("expected ErrNoKey after delete, got %v", err)
+	}
+}
+
+func TestSealOpen(t *testing.T) {
+	key, _ := GenerateKey()
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("SQLite format 3\x00 and some binary \xff\xfe")
+	sealed, err := c.Seal(data)
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if strings.Contains(string(sealed), "SQLite") {
+		t.Fatal("sealed data leaks plaintext")
+	}
+
+	opened, err := c.Open(sealed)
+	if err != nil || string(opened) != string(data) {
+		t.Fatalf("Open() = %q, %v", opened, err)
+	}
+
+	otherKey, _ := GenerateKey()
+	other, _ := NewCipher(otherKey)
+	if _, err := other.Open(sealed); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Open with the wrong key: err = %v, want ErrDecrypt", err)
+	}
+	if _, err := c.Open(sealed[:4]); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Open of truncated data: err = %v, want ErrDecrypt", err)
+	}
+}
@@ -0,0 +1,159 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	EnvKey = "DEVLOG_ENCRYPTION_KEY"
+
+	keychainService = "devlog"
+	keychainAccount = "payload-key"
+	keychainLabel   = "devlog payload encryption key"
+)
+
+var (
+	ErrNoKey    = errors.New("encryption key not found")
+	ErrNoSecret = errors.New("secret not found")
+)
+
+var runCommand = func(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.String(), nil
+}
+
+func KeySource() string {
+	if os.Getenv(EnvKey) != "" {
+		return "$" + EnvKey
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "macOS Keychain"
+	case "linux", "freebsd", "openbsd":
+		return "Secret Service (secret-tool)"
+	default:
+		return "unsupported"
+	}
+}
+
+func LoadKey() ([]byte, error) {
+	if v := os.Getenv(EnvKey); v != "" {
+		return decodeKey(v)
+	}
+
+	out, err := LoadSecret(keychainAccount)
+	if err != nil {
+		if errors.Is(err, ErrNoSecret) {
+			return nil, fmt.Errorf("%w: %v", ErrNoKey, err)
+		}
+		return nil, err
+	}
+	return decodeKey(out)
+}
+
+func StoreKey(key []byte) error {
+	if os.Getenv(EnvKey) != "" {
+		return fmt.Errorf("%s is set; unset it to store the key in the OS keychain", EnvKey)
+	}
+	return StoreSecret(keychainAccount, keychainLabel, hex.EncodeToString(key))
+}
+
+func DeleteKey() error {
+	if os.Getenv(EnvKey) != "" {
+		return nil
+	}
+	if err := DeleteSecret(keychainAccount); err != nil {
+		return fmt.Errorf("delete key from %s: %w", KeySource(), err)
+	}
+	return nil
+}
+
+// LoadSecret reads a secret devlog stored in the OS keychain under account,
+// such as the payload key or an API token for a poller.
+func LoadSecret(account string) (string, error) {
+	var out string
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		out, err = runCommand("", "security", "find-generic-password",
+			"-s", keychainService, "-a", account, "-w")
+	case "linux", "freebsd", "openbsd":
+		out, err = runCommand("", "secret-tool", "lookup",
+			"service", keychainService, "account", account)
+	default:
+		return "", unsupportedError()
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %s in %s: %v", ErrNoSecret, account, KeySource(), err)
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return "", fmt.Errorf("%w: %s in %s", ErrNoSecret, account, KeySource())
+	}
+	return out, nil
+}
+
+func StoreSecret(account, label, secret string) error {
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		_, err = runCommand("", "security", "add-generic-password", "-U",
+			"-s", keychainService, "-a", account, "-l", label, "-w", secret)
+	case "linux", "freebsd", "openbsd":
+		_, err = runCommand(secret, "secret-tool", "store", "--label", label,
+			"service", keychainService, "account", account)
+	default:
+		return unsupportedError()
+	}
+	if err != nil {
+		return fmt.Errorf("store %s in %s: %w", account, KeySource(), err)
+	}
+	return nil
+}
+
+func DeleteSecret(account string) error {
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		_, err = runCommand("", "security", "delete-generic-password",
+			"-s", keychainService, "-a", account)
+	case "linux", "freebsd", "openbsd":
+		_, err = runCommand("", "secret-tool", "clear",
+			"service", keychainService, "account", account)
+	default:
+		return unsupportedError()
+	}
+	return err
+}
+
+func decodeKey(s string) ([]byte, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key must be %d hex-encoded bytes", KeySize)
+	}
+	return key, nil
+}
+
+func unsupportedError() error {
+	return fmt.Errorf("no OS keychain support on %s; set %s to a %d-byte hex key instead",
+		runtime.GOOS, EnvKey, KeySize)
+}
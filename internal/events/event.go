@@ -20,6 +20,13 @@ const (
 	SourceTmux      EventSource = "tmux"
 	SourceClaude    EventSource = "claude"
 	SourceKubectl   EventSource = "kubectl"
+	SourceTerraform EventSource = "terraform"
+	SourceActivity  EventSource = "activity"
+	SourceTests     EventSource = "tests"
+	SourceGitLab    EventSource = "gitlab"
+	SourceBitbucket EventSource = "bitbucket"
+	SourceSSH       EventSource = "ssh"
+	SourcePatterns  EventSource = "patterns"
 )
 
 func (s EventSource) String() string {
@@ -28,7 +35,7 @@ func (s EventSource) String() string {
 
 func (s EventSource) Validate() error {
 	switch s {
-	case SourceGit, SourceShell, SourceWisprflow, SourceManual, SourceGitHub, SourceClipboard, SourceTmux, SourceClaude, SourceKubectl:
+	case SourceGit, SourceShell, SourceWisprflow, SourceManual, SourceGitHub, SourceClipboard, SourceTmux, SourceClaude, SourceKubectl, SourceTerraform, SourceActivity, SourceTests, SourceGitLab, SourceBitbucket, SourceSSH, SourcePatterns:
 		return nil
 	default:
 		return fmt.Errorf("invalid source: %s", s)
@@ -38,38 +45,55 @@ func (s EventSource) Validate() error {
 type EventType string
 
 const (
-	TypeCommit          EventType = "commit"
-	TypeMerge           EventType = "merge"
-	TypePush            EventType = "push"
-	TypePull            EventType = "pull"
-	TypeFetch           EventType = "fetch"
-	TypeCheckout        EventType = "checkout"
-	TypeRebase          EventType = "rebase"
-	TypeStash           EventType = "stash"
-	TypeCommand         EventType = "command"
-	TypeNote            EventType = "note"
-	TypePRMerged        EventType = "pr_merged"
-	TypeContextSwitch   EventType = "context_switch"
-	TypeTranscription   EventType = "transcription"
-	TypeCopy            EventType = "copy"
-	TypeTmuxSession     EventType = "tmux_session"
-	TypeTmuxWindow      EventType = "tmux_window"
-	TypeTmuxPane        EventType = "tmux_pane"
-	TypeTmuxAttach      EventType = "tmux_attach"
-	TypeTmuxDetach      EventType = "tmux_detach"
-	TypeConversation    EventType = "conversation"
-	TypeFileEdit        EventType = "file_edit"
-	TypeKubectlApply    EventType = "kubectl_apply"
-	TypeKubectlCreate   EventType = "kubectl_create"
-	TypeKubectlDelete   EventType = "kubectl_delete"
-	TypeKubectlGet      EventType = "kubectl_get"
-	TypeKubectlDescribe EventType = "kubectl_describe"
-	TypeKubectlEdit     EventType = "kubectl_edit"
-	TypeKubectlPatch    EventType = "kubectl_patch"
-	TypeKubectlLogs     EventType = "kubectl_logs"
-	TypeKubectlExec     EventType = "kubectl_exec"
-	TypeKubectlDebug    EventType = "kubectl_debug"
-	TypeOther           EventType = "other"
+	TypeCommit           EventType = "commit"
+	TypeMerge            EventType = "merge"
+	TypePush             EventType = "push"
+	TypePull             EventType = "pull"
+	TypeFetch            EventType = "fetch"
+	TypeCheckout         EventType = "checkout"
+	TypeRebase           EventType = "rebase"
+	TypeStash            EventType = "stash"
+	TypeCommand          EventType = "command"
+	TypeNote             EventType = "note"
+	TypePROpened         EventType = "pr_opened"
+	TypePRMerged         EventType = "pr_merged"
+	TypePRClosed         EventType = "pr_closed"
+	TypePRReview         EventType = "pr_review"
+	TypeIssueOpened      EventType = "issue_opened"
+	TypeIssueClosed      EventType = "issue_closed"
+	TypeWorkflowRun      EventType = "workflow_run"
+	TypeResearch         EventType = "research"
+	TypeContextSwitch    EventType = "context_switch"
+	TypeTranscription    EventType = "transcription"
+	TypeCopy             EventType = "copy"
+	TypeTmuxSession      EventType = "tmux_session"
+	TypeTmuxWindow       EventType = "tmux_window"
+	TypeTmuxPane         EventType = "tmux_pane"
+	TypeTmuxAttach       EventType = "tmux_attach"
+	TypeTmuxDetach       EventType = "tmux_detach"
+	TypeTmuxSample       EventType = "tmux_sample"
+	TypeConversation     EventType = "conversation"
+	TypeFileEdit         EventType = "file_edit"
+	TypeToolCall         EventType = "tool_call"
+	TypeTestRun          EventType = "test_run"
+	TypeQuerySession     EventType = "query_session"
+	TypeKubectlApply     EventType = "kubectl_apply"
+	TypeKubectlCreate    EventType = "kubectl_create"
+	TypeKubectlDelete    EventType = "kubectl_delete"
+	TypeKubectlGet       EventType = "kubectl_get"
+	TypeKubectlDescribe  EventType = "kubectl_describe"
+	TypeKubectlEdit      EventType = "kubectl_edit"
+	TypeKubectlPatch     EventType = "kubectl_patch"
+	TypeKubectlLogs      EventType = "kubectl_logs"
+	TypeKubectlExec      EventType = "kubectl_exec"
+	TypeKubectlDebug     EventType = "kubectl_debug"
+	TypeTerraformPlan    EventType = "terraform_plan"
+	TypeTerraformApply   EventType = "terraform_apply"
+	TypeTerraformDestroy EventType = "terraform_destroy"
+	TypeSessionStart     EventType = "session_start"
+	TypeSessionEnd       EventType = "session_end"
+	TypeInsight          EventType = "insight"
+	TypeOther            EventType = "other"
 )
 
 func (t EventType) String() string {
@@ -79,11 +103,14 @@ func (t EventType) String() string {
 func (t EventType) Validate() error {
 	switch t {
 	case TypeCommit, TypeMerge, TypePush, TypePull, TypeFetch, TypeCheckout, TypeRebase, TypeStash,
-		TypeCommand, TypeNote, TypePRMerged, TypeContextSwitch, TypeTranscription, TypeCopy,
-		TypeTmuxSession, TypeTmuxWindow, TypeTmuxPane, TypeTmuxAttach, TypeTmuxDetach,
-		TypeConversation, TypeFileEdit,
+		TypeCommand, TypeNote, TypeContextSwitch, TypeTranscription, TypeCopy,
+		TypePROpened, TypePRMerged, TypePRClosed, TypePRReview, TypeIssueOpened, TypeIssueClosed, TypeWorkflowRun, TypeResearch,
+		TypeTmuxSession, TypeTmuxWindow, TypeTmuxPane, TypeTmuxAttach, TypeTmuxDetach, TypeTmuxSample,
+		TypeConversation, TypeFileEdit, TypeToolCall, TypeTestRun, TypeQuerySession,
 		TypeKubectlApply, TypeKubectlCreate, TypeKubectlDelete, TypeKubectlGet, TypeKubectlDescribe,
 		TypeKubectlEdit, TypeKubectlPatch, TypeKubectlLogs, TypeKubectlExec, TypeKubectlDebug,
+		TypeTerraformPlan, TypeTerraformApply, TypeTerraformDestroy,
+		TypeSessionStart, TypeSessionEnd, TypeInsight,
 		TypeOther:
 		return nil
 	default:
@@ -91,20 +118,60 @@ func (t EventType) Validate() error {
 	}
 }
 
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityError    Severity = "error"
+	SeverityCritical Severity = "critical"
+)
+
+func (s Severity) Validate() error {
+	switch s {
+	case "", SeverityInfo, SeverityWarning, SeverityError, SeverityCritical:
+		return nil
+	default:
+		return fmt.Errorf("invalid severity: %s", s)
+	}
+}
+
+// Event is one captured action. DurationMs, SessionID, ParentID and Severity
+// are optional: SessionID groups events from the same unit of work (a Claude
+// conversation, a pull request) and ParentID points at the event this one
+// belongs to, such as the conversation a command ran in.
 type Event struct {
-	Version   int                    `json:"v"`
-	ID        string                 `json:"id"`
-	Timestamp string                 `json:"timestamp"`
-	Source    string                 `json:"source"`
-	Type      string                 `json:"type"`
-	Repo      string                 `json:"repo,omitempty"`
-	Branch    string                 `json:"branch,omitempty"`
-	Payload   map[string]interface{} `json:"payload"`
+	Version    int                    `json:"v"`
+	ID         string                 `json:"id"`
+	Timestamp  string                 `json:"timestamp"`
+	Source     string                 `json:"source"`
+	Type       string                 `json:"type"`
+	Repo       string                 `json:"repo,omitempty"`
+	Branch     string                 `json:"branch,omitempty"`
+	DurationMs int64                  `json:"duration_ms,omitempty"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Severity   string                 `json:"severity,omitempty"`
+	Payload    map[string]interface{} `json:"payload"`
+
+	// Module names the module that produced the event when its source is
+	// not the module's own name, such as ci's github events, so module
+	// settings apply to it. It is set by the daemon and never stored.
+	Module string `json:"-"`
+}
+
+// Owner returns the module whose settings apply to the event: Module if
+// set, otherwise the module named after its source.
+func (e *Event) Owner() string {
+	if e.Module != "" {
+		return e.Module
+	}
+	return e.Source
 }
 
 func NewEvent(source, eventType string) *Event {
 	return &Event{
-		Version:   1,
+		Version:   CurrentVersion(source),
 		ID:        uuid.New().String(),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		Source:    source,
@@ -114,7 +181,7 @@ func NewEvent(source, eventType string) *Event {
 }
 
 func (e *Event) Validate() error {
-	if e.Version != 1 {
+	if e.Version < 1 || e.Version > CurrentVersion(e.Source) {
 		return fmt.Errorf("unsupported version: %d", e.Version)
 	}
 
@@ -142,6 +209,18 @@ func (e *Event) Validate() error {
 		return err
 	}
 
+	if e.DurationMs < 0 {
+		return fmt.Errorf("duration_ms cannot be negative")
+	}
+
+	if e.ParentID == e.ID {
+		return fmt.Errorf("parent_id cannot reference the event itself")
+	}
+
+	if err := Severity(e.Severity).Validate(); err != nil {
+		return err
+	}
+
 	if e.Payload == nil {
 		return fmt.Errorf("payload is required")
 	}
@@ -168,3 +247,25 @@ func (e *Event) PayloadJSON() (string, error) {
 	}
 	return string(data), nil
 }
+
+func (e *Event) Tags() []string {
+	return e.StringList("tags")
+}
+
+// StringList returns a payload list of strings, whether it was built in
+// memory ([]string) or decoded from JSON ([]interface{}).
+func (e *Event) StringList(key string) []string {
+	switch list := e.Payload[key].(type) {
+	case []string:
+		return list
+	case []interface{}:
+		result := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
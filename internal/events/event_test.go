@@ -2,6 +2,7 @@ package events
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -163,6 +164,48 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "with links and severity",
+			event: &Event{
+				Version:    1,
+				ID:         uuid.New().String(),
+				Timestamp:  time.Now().UTC().Format(time.RFC3339),
+				Source:     string(SourceShell),
+				Type:       string(TypeCommand),
+				DurationMs: 1500,
+				SessionID:  "term-42",
+				ParentID:   uuid.New().String(),
+				Severity:   string(SeverityError),
+				Payload:    map[string]interface{}{"command": "make"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid severity",
+			event: &Event{
+				Version:   1,
+				ID:        uuid.New().String(),
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Source:    string(SourceShell),
+				Type:      string(TypeCommand),
+				Severity:  "fatal",
+				Payload:   map[string]interface{}{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative duration",
+			event: &Event{
+				Version:    1,
+				ID:         uuid.New().String(),
+				Timestamp:  time.Now().UTC().Format(time.RFC3339),
+				Source:     string(SourceShell),
+				Type:       string(TypeCommand),
+				DurationMs: -1,
+				Payload:    map[string]interface{}{},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -258,3 +301,117 @@ func TestPayloadJSON(t *testing.T) {
 		t.Errorf("got message %v, want 'Test commit'", payload["message"])
 	}
 }
+
+func TestUpgradeManualNote(t *testing.T) {
+	event := NewEvent(string(SourceManual), string(TypeNote))
+	event.Version = 1
+	event.Payload["note"] = "shipped the fix"
+
+	upgraded, err := event.Upgrade()
+	if err != nil {
+		t.Fatalf("Upgrade() error: %v", err)
+	}
+	if !upgraded {
+		t.Fatal("expected event to be upgraded")
+	}
+	if event.Version != CurrentVersion(string(SourceManual)) {
+		t.Errorf("got version %d, want %d", event.Version, CurrentVersion(string(SourceManual)))
+	}
+	if event.Payload["text"] != "shipped the fix" {
+		t.Errorf("got payload %v", event.Payload)
+	}
+	if _, ok := event.Payload["note"]; ok {
+		t.Error("old note key should be removed")
+	}
+
+	upgraded, err = event.Upgrade()
+	if err != nil || upgraded {
+		t.Errorf("second Upgrade() = %v, %v; want false, nil", upgraded, err)
+	}
+}
+
+func TestUpgradeLeavesEventOnFailure(t *testing.T) {
+	source := "upgrade-test"
+	RegisterPayloadMigration(PayloadMigration{
+		Source: source,
+		From:   1,
+		Up: func(e *Event) error {
+			e.Payload["added"] = true
+			return nil
+		},
+	})
+	RegisterPayloadMigration(PayloadMigration{
+		Source: source,
+		From:   2,
+		Up: func(e *Event) error {
+			return fmt.Errorf("boom")
+		},
+	})
+
+	event := &Event{Version: 1, Source: source, Payload: map[string]interface{}{"k": "v"}}
+	if _, err := event.Upgrade(); err == nil {
+		t.Fatal("expected error")
+	}
+	if event.Version != 1 {
+		t.Errorf("version changed to %d", event.Version)
+	}
+	if _, ok := event.Payload["added"]; ok {
+		t.Error("payload modified by failed upgrade")
+	}
+}
+
+func TestValidateAcceptsCurrentVersion(t *testing.T) {
+	event := NewEvent(string(SourceManual), string(TypeNote))
+	if err := event.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+
+	event.Version = CurrentVersion(string(SourceManual)) + 1
+	if err := event.Validate(); err == nil {
+		t.Error("expected error for version newer than current")
+	}
+}
+
+func TestValidatePayload(t *testing.T) {
+	RegisterPayloadSchema(PayloadSchema{
+		Source: "schema-test",
+		Types:  []EventType{TypeCommand},
+		Fields: map[string]PayloadField{
+			"command":   {Kind: KindString, Required: true},
+			"exit_code": {Kind: KindNumber, Required: true},
+			"files":     {Kind: KindStringList},
+		},
+	})
+
+	tests := []struct {
+		name    string
+		typ     string
+		payload map[string]interface{}
+		field   string
+	}{
+		{"valid", "command", map[string]interface{}{"command": "ls", "exit_code": 0, "extra": true}, ""},
+		{"decoded json", "command", map[string]interface{}{"command": "ls", "exit_code": float64(1), "files": []interface{}{"a"}}, ""},
+		{"missing", "command", map[string]interface{}{"exit_code": 0}, "command"},
+		{"empty", "command", map[string]interface{}{"command": "", "exit_code": 0}, "command"},
+		{"wrong kind", "command", map[string]interface{}{"command": "ls", "exit_code": "0"}, "exit_code"},
+		{"bad list", "command", map[string]interface{}{"command": "ls", "exit_code": 0, "files": []interface{}{1}}, "files"},
+		{"no schema", "note", map[string]interface{}{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &Event{Source: "schema-test", Type: tt.typ, Payload: tt.payload}
+			err := event.ValidatePayload()
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("ValidatePayload() error: %v", err)
+				}
+				return
+			}
+			payloadErr, ok := err.(*PayloadError)
+			if !ok || payloadErr.Field != tt.field {
+				t.Errorf("ValidatePayload() = %v, want an error for %q", err, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package events
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var commandFilePatterns = []struct {
+	regex *regexp.Regexp
+	group int
+}{
+	{regexp.MustCompile(`(?:vim|vi|nvim|nano|emacs|code|subl)\s+([^\s]+)`), 1},
+	{regexp.MustCompile(`(?:cat|less|more|head|tail)\s+([^\s]+)`), 1},
+	{regexp.MustCompile(`sed\s+.*?\s+([^\s][^\s]*\.[^\s]+)(?:\s|$)`), 1},
+	{regexp.MustCompile(`awk\s+.*?\s+([^\s][^\s]*\.[^\s]+)(?:\s|$)`), 1},
+	{regexp.MustCompile(`echo\s+.*?>\s*([^\s]+)`), 1},
+	{regexp.MustCompile(`(?:cp|mv)\s+[^\s]+\s+([^\s]+)`), 1},
+	{regexp.MustCompile(`(?:touch|rm|chmod|chown)\s+([^\s]+)`), 1},
+	{regexp.MustCompile(`git\s+(?:add|rm|mv|checkout)\s+([^\s-][^\s]*)`), 1},
+}
+
+// ExtractFilesFromCommand guesses which files a shell command opened or
+// changed, from the arguments of common editors and file utilities.
+func ExtractFilesFromCommand(cmd string) []string {
+	var files []string
+
+	for _, pattern := range commandFilePatterns {
+		if matches := pattern.regex.FindAllStringSubmatch(cmd, -1); matches != nil {
+			for _, match := range matches {
+				if len(match) > pattern.group {
+					file := match[pattern.group]
+					if file != "" && !strings.HasPrefix(file, "-") && !strings.HasPrefix(file, "'") && !strings.HasPrefix(file, "\"") {
+						files = append(files, file)
+					}
+				}
+			}
+		}
+	}
+
+	return files
+}
+
+// Files returns the paths an event touched: the files of a git commit, the
+// file of an editor edit, or the files named in a shell command.
+func Files(evt *Event) []string {
+	switch {
+	case evt.Source == string(SourceGit) && evt.Type == string(TypeCommit):
+		return stringList(evt.Payload["files"])
+	case evt.Source == string(SourceShell) && evt.Type == string(TypeCommand):
+		if cmd, ok := evt.Payload["command"].(string); ok {
+			return ExtractFilesFromCommand(cmd)
+		}
+	default:
+		if path, ok := evt.Payload["file_path"].(string); ok && path != "" {
+			return []string{path}
+		}
+	}
+	return nil
+}
+
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+var languagesByExt = map[string]string{
+	".go":     "Go",
+	".py":     "Python",
+	".js":     "JavaScript",
+	".mjs":    "JavaScript",
+	".cjs":    "JavaScript",
+	".jsx":    "JavaScript",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".rb":     "Ruby",
+	".rs":     "Rust",
+	".java":   "Java",
+	".kt":     "Kotlin",
+	".swift":  "Swift",
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".php":    "PHP",
+	".ex":     "Elixir",
+	".exs":    "Elixir",
+	".erl":    "Erlang",
+	".hs":     "Haskell",
+	".scala":  "Scala",
+	".clj":    "Clojure",
+	".lua":    "Lua",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".zsh":    "Shell",
+	".fish":   "Shell",
+	".sql":    "SQL",
+	".html":   "HTML",
+	".css":    "CSS",
+	".scss":   "CSS",
+	".vue":    "Vue",
+	".svelte": "Svelte",
+	".md":     "Markdown",
+	".yaml":   "YAML",
+	".yml":    "YAML",
+	".json":   "JSON",
+	".toml":   "TOML",
+	".tf":     "Terraform",
+	".proto":  "Protocol Buffers",
+	".nix":    "Nix",
+	".dart":   "Dart",
+	".zig":    "Zig",
+}
+
+var languagesByName = map[string]string{
+	"Dockerfile": "Docker",
+	"Makefile":   "Makefile",
+	"go.mod":     "Go",
+	"go.sum":     "Go",
+}
+
+// Language names the language of a file from its name or extension, or
+// returns "" when it is not recognised.
+func Language(path string) string {
+	base := filepath.Base(path)
+	if lang, ok := languagesByName[base]; ok {
+		return lang
+	}
+	return languagesByExt[strings.ToLower(filepath.Ext(base))]
+}
@@ -0,0 +1,39 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractFilesFromCommand(t *testing.T) {
+	tests := []struct {
+		cmd  string
+		want []string
+	}{
+		{"vim main.go", []string{"main.go"}},
+		{"git add internal/api/handlers.go", []string{"internal/api/handlers.go"}},
+		{"git add -A", nil},
+		{"ls -la", nil},
+	}
+	for _, tt := range tests {
+		if got := ExtractFilesFromCommand(tt.cmd); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ExtractFilesFromCommand(%q) = %v, want %v", tt.cmd, got, tt.want)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := map[string]string{
+		"internal/api/handlers.go": "Go",
+		"web/App.TSX":              "TypeScript",
+		"deploy/Dockerfile":        "Docker",
+		"go.mod":                   "Go",
+		"LICENSE":                  "",
+		"notes.xyz":                "",
+	}
+	for path, want := range tests {
+		if got := Language(path); got != want {
+			t.Errorf("Language(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type PayloadMigration struct {
+	Source      string
+	From        int
+	Description string
+	Up          func(event *Event) error
+}
+
+var (
+	payloadMigrationsMu sync.RWMutex
+	payloadMigrations   = map[string][]PayloadMigration{}
+)
+
+func init() {
+	RegisterPayloadMigration(PayloadMigration{
+		Source:      string(SourceManual),
+		From:        1,
+		Description: "Rename note payload key to text",
+		Up: func(event *Event) error {
+			if event.Type != string(TypeNote) {
+				return nil
+			}
+			if note, ok := event.Payload["note"]; ok {
+				if _, hasText := event.Payload["text"]; !hasText {
+					event.Payload["text"] = note
+				}
+				delete(event.Payload, "note")
+			}
+			return nil
+		},
+	})
+}
+
+// RegisterPayloadMigration adds the step that upgrades a source's payloads
+// from version From to From+1. Steps must be registered in order.
+func RegisterPayloadMigration(m PayloadMigration) {
+	payloadMigrationsMu.Lock()
+	defer payloadMigrationsMu.Unlock()
+
+	existing := payloadMigrations[m.Source]
+	if m.From != len(existing)+1 {
+		panic(fmt.Sprintf("payload migration for %s must upgrade from version %d, got %d", m.Source, len(existing)+1, m.From))
+	}
+	payloadMigrations[m.Source] = append(existing, m)
+}
+
+func CurrentVersion(source string) int {
+	payloadMigrationsMu.RLock()
+	defer payloadMigrationsMu.RUnlock()
+	return len(payloadMigrations[source]) + 1
+}
+
+func PayloadMigrations() []PayloadMigration {
+	payloadMigrationsMu.RLock()
+	defer payloadMigrationsMu.RUnlock()
+
+	var all []PayloadMigration
+	for _, steps := range payloadMigrations {
+		all = append(all, steps...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Source != all[j].Source {
+			return all[i].Source < all[j].Source
+		}
+		return all[i].From < all[j].From
+	})
+	return all
+}
+
+// Upgrade brings the event's payload up to the current version for its
+// source. The event is left untouched if any step fails.
+func (e *Event) Upgrade() (bool, error) {
+	current := CurrentVersion(e.Source)
+	if e.Version < 1 || e.Version >= current {
+		return false, nil
+	}
+
+	payloadMigrationsMu.RLock()
+	steps := payloadMigrations[e.Source]
+	payloadMigrationsMu.RUnlock()
+
+	upgraded := *e
+	payload, err := copyPayload(e.Payload)
+	if err != nil {
+		return false, err
+	}
+	upgraded.Payload = payload
+
+	for _, step := range steps[e.Version-1:] {
+		if err := step.Up(&upgraded); err != nil {
+			return false, fmt.Errorf("upgrade %s payload from v%d: %w", e.Source, step.From, err)
+		}
+		upgraded.Version = step.From + 1
+	}
+
+	*e = upgraded
+	return true, nil
+}
+
+func copyPayload(payload map[string]interface{}) (map[string]interface{}, error) {
+	if payload == nil {
+		return make(map[string]interface{}), nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("copy payload: %w", err)
+	}
+	copied := make(map[string]interface{}, len(payload))
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("copy payload: %w", err)
+	}
+	return copied, nil
+}
@@ -0,0 +1,181 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// FieldKind is the JSON type a payload field must have.
+type FieldKind string
+
+const (
+	KindString     FieldKind = "string"
+	KindNumber     FieldKind = "number"
+	KindBool       FieldKind = "bool"
+	KindStringList FieldKind = "string_list"
+	KindObject     FieldKind = "object"
+)
+
+type PayloadField struct {
+	Kind     FieldKind
+	Required bool
+}
+
+// PayloadSchema describes the payload of a source's events. Types limits it
+// to some event types; empty means every type of the source. Fields that are
+// not listed are allowed and left unchecked.
+type PayloadSchema struct {
+	Source string
+	Types  []EventType
+	Fields map[string]PayloadField
+}
+
+// PayloadError reports a payload that does not match its schema.
+type PayloadError struct {
+	Source string
+	Type   string
+	Field  string
+	Reason string
+}
+
+func (e *PayloadError) Error() string {
+	return fmt.Sprintf("payload field %q of %s/%s events %s", e.Field, e.Source, e.Type, e.Reason)
+}
+
+var (
+	payloadSchemasMu sync.RWMutex
+	payloadSchemas   = map[string]PayloadSchema{}
+)
+
+func init() {
+	RegisterPayloadSchema(PayloadSchema{
+		Source: string(SourceManual),
+		Types:  []EventType{TypeNote},
+		Fields: map[string]PayloadField{
+			"text":    {Kind: KindString, Required: true},
+			"tags":    {Kind: KindStringList},
+			"workdir": {Kind: KindString},
+		},
+	})
+}
+
+func schemaKey(source, eventType string) string {
+	return source + "/" + eventType
+}
+
+// RegisterPayloadSchema sets the schema for a source's payloads, replacing
+// any earlier schema for the same source and types. Schemas describe the
+// current payload version; older events are upgraded before they are
+// checked.
+func RegisterPayloadSchema(s PayloadSchema) {
+	payloadSchemasMu.Lock()
+	defer payloadSchemasMu.Unlock()
+
+	if len(s.Types) == 0 {
+		payloadSchemas[schemaKey(s.Source, "")] = s
+		return
+	}
+	for _, t := range s.Types {
+		payloadSchemas[schemaKey(s.Source, string(t))] = s
+	}
+}
+
+// LookupPayloadSchema returns the schema for an event type, falling back to
+// the schema for every type of its source.
+func LookupPayloadSchema(source, eventType string) (PayloadSchema, bool) {
+	payloadSchemasMu.RLock()
+	defer payloadSchemasMu.RUnlock()
+
+	if s, ok := payloadSchemas[schemaKey(source, eventType)]; ok {
+		return s, true
+	}
+	s, ok := payloadSchemas[schemaKey(source, "")]
+	return s, ok
+}
+
+// ValidatePayload checks the payload against the schema registered for the
+// event's source and type. Events without a schema always pass. The event
+// must be at its source's current version; see Upgrade.
+func (e *Event) ValidatePayload() error {
+	schema, ok := LookupPayloadSchema(e.Source, e.Type)
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(schema.Fields))
+	for name := range schema.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field := schema.Fields[name]
+		value, present := e.Payload[name]
+		if !present || value == nil {
+			if field.Required {
+				return &PayloadError{Source: e.Source, Type: e.Type, Field: name, Reason: "is required"}
+			}
+			continue
+		}
+		if !field.Kind.matches(value) {
+			return &PayloadError{Source: e.Source, Type: e.Type, Field: name,
+				Reason: fmt.Sprintf("must be a %s, got %s", field.Kind, describeValue(value))}
+		}
+		if field.Required && field.Kind == KindString && value.(string) == "" {
+			return &PayloadError{Source: e.Source, Type: e.Type, Field: name, Reason: "must not be empty"}
+		}
+	}
+	return nil
+}
+
+func (k FieldKind) matches(value interface{}) bool {
+	switch k {
+	case KindString:
+		_, ok := value.(string)
+		return ok
+	case KindNumber:
+		switch value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, json.Number:
+			return true
+		}
+		return false
+	case KindBool:
+		_, ok := value.(bool)
+		return ok
+	case KindStringList:
+		switch list := value.(type) {
+		case []string:
+			return true
+		case []interface{}:
+			for _, item := range list {
+				if _, ok := item.(string); !ok {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+	case KindObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return true
+}
+
+func describeValue(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64, float32, int, int64, json.Number:
+		return "number"
+	case []interface{}, []string:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
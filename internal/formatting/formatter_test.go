@@ -274,3 +274,13 @@ func TestGitHubFormatter(t *testing.T) {
 		}
 	})
 }
+
+func TestGitHubFormatterResearch(t *testing.T) {
+	event := events.NewEvent("github", "research")
+	event.Payload["kind"] = "star"
+	event.Payload["text"] = "Starred charmbracelet/bubbletea: A TUI framework"
+
+	if got := FormatEventContent(event); got != "Starred charmbracelet/bubbletea: A TUI framework" {
+		t.Errorf("FormatEventContent() = %q", got)
+	}
+}
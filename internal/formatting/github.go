@@ -10,9 +10,17 @@ type GitHubFormatter struct{}
 
 func init() {
 	Register("github", &GitHubFormatter{})
+	Register("gitlab", &GitHubFormatter{})
+	Register("bitbucket", &GitHubFormatter{})
 }
 
 func (f *GitHubFormatter) Format(event *events.Event) string {
+	if event.Type == string(events.TypeResearch) {
+		if text, ok := event.Payload["text"].(string); ok && text != "" {
+			return TruncateToFirstLine(text, 100)
+		}
+	}
+
 	title := ""
 	if t, ok := event.Payload["title"].(string); ok {
 		title = t
@@ -24,6 +32,14 @@ func (f *GitHubFormatter) Format(event *events.Event) string {
 	prNum := ""
 	if num, ok := event.Payload["pr_number"].(float64); ok {
 		prNum = fmt.Sprintf("#%.0f", num)
+	} else if num, ok := event.Payload["issue_number"].(float64); ok {
+		prNum = fmt.Sprintf("#%.0f", num)
+	}
+
+	if conclusion, ok := event.Payload["conclusion"].(string); ok && conclusion != "" {
+		title = fmt.Sprintf("%s (%s)", title, conclusion)
+	} else if event.Type == string(events.TypeWorkflowRun) && event.Payload["action"] == "in_progress" {
+		title = fmt.Sprintf("%s (running)", title)
 	}
 
 	switch {
@@ -34,6 +50,6 @@ func (f *GitHubFormatter) Format(event *events.Event) string {
 	case title != "":
 		return title
 	default:
-		return fmt.Sprintf("github/%s", event.Type)
+		return fmt.Sprintf("%s/%s", event.Source, event.Type)
 	}
 }
@@ -1,6 +1,8 @@
 package formatting
 
 import (
+	"strings"
+
 	"devlog/internal/events"
 )
 
@@ -16,8 +18,12 @@ func (f *ManualFormatter) Format(event *events.Event) string {
 		text = TruncateToFirstLine(t, 80)
 	}
 
-	if text != "" {
-		return text
+	if text == "" {
+		text = "(empty)"
+	}
+
+	if tags := event.Tags(); len(tags) > 0 {
+		text += " #" + strings.Join(tags, " #")
 	}
-	return "(empty)"
+	return text
 }
@@ -2,7 +2,9 @@ package ingest
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -10,6 +12,7 @@ import (
 	"devlog/internal/config"
 	"devlog/internal/daemon"
 	"devlog/internal/events"
+	"devlog/internal/pause"
 	"devlog/internal/queue"
 )
 
@@ -19,14 +22,17 @@ func SendEvent(event *events.Event) error {
 		return err
 	}
 
+	if capturePaused() {
+		return nil
+	}
+
 	if daemon.IsRunning() {
 		eventJSON, err := event.ToJSON()
 		if err != nil {
 			return fmt.Errorf("serialize event: %w", err)
 		}
 
-		url := fmt.Sprintf("http://127.0.0.1:%d/api/v1/ingest", cfg.HTTP.Port)
-		resp, err := http.Post(url, "application/json", bytes.NewReader(eventJSON))
+		resp, err := postToDaemon(cfg.HTTP, "/api/v1/ingest", "application/json", bytes.NewReader(eventJSON))
 		if err == nil {
 			defer resp.Body.Close()
 			if resp.StatusCode == http.StatusOK {
@@ -52,6 +58,119 @@ func SendEvent(event *events.Event) error {
 	return nil
 }
 
+type BatchResult struct {
+	Ingested   int
+	Filtered   int
+	Duplicates int
+	Queued     int
+	Errors     []string
+}
+
+func SendEvents(evts []*events.Event) (*BatchResult, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	if capturePaused() {
+		return &BatchResult{Filtered: len(evts)}, nil
+	}
+
+	if daemon.IsRunning() {
+		if result, err := postBatch(cfg.HTTP, evts); err == nil {
+			return result, nil
+		}
+	}
+
+	queueDir, err := config.QueueDir()
+	if err != nil {
+		return nil, fmt.Errorf("get queue directory: %w", err)
+	}
+
+	q, err := queue.New(queueDir)
+	if err != nil {
+		return nil, fmt.Errorf("create queue: %w", err)
+	}
+
+	result := &BatchResult{}
+	for _, event := range evts {
+		if err := q.Enqueue(event); err != nil {
+			return result, fmt.Errorf("queue event: %w", err)
+		}
+		result.Queued++
+	}
+
+	return result, nil
+}
+
+func capturePaused() bool {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return false
+	}
+	return pause.NewController(dataDir).Active()
+}
+
+func postBatch(httpCfg config.HTTPConfig, evts []*events.Event) (*BatchResult, error) {
+	var body bytes.Buffer
+	for _, event := range evts {
+		eventJSON, err := event.ToJSON()
+		if err != nil {
+			return nil, fmt.Errorf("serialize event: %w", err)
+		}
+		body.Write(eventJSON)
+		body.WriteByte('\n')
+	}
+
+	resp, err := postToDaemon(httpCfg, "/api/v1/ingest/batch", "application/x-ndjson", &body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("batch ingest failed with status %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Ingested   int `json:"ingested"`
+		Filtered   int `json:"filtered"`
+		Duplicates int `json:"duplicates"`
+		Errors     []struct {
+			Line  int    `json:"line"`
+			Error string `json:"error"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	result := &BatchResult{
+		Ingested:   response.Ingested,
+		Filtered:   response.Filtered,
+		Duplicates: response.Duplicates,
+	}
+	for _, e := range response.Errors {
+		id := ""
+		if e.Line >= 1 && e.Line <= len(evts) {
+			id = evts[e.Line-1].ID
+		}
+		result.Errors = append(result.Errors, fmt.Sprintf("event %s: %s", id, e.Error))
+	}
+
+	return result, nil
+}
+
+func postToDaemon(httpCfg config.HTTPConfig, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, httpCfg.LocalURL()+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	config.AuthorizeRequest(req)
+	return httpCfg.Client(0).Do(req)
+}
+
 func FindGitRepo(path string) (string, error) {
 	current := path
 	for {
@@ -1,9 +1,34 @@
 package install
 
+import (
+	"os"
+
+	"devlog/internal/assets"
+)
+
 type Context struct {
 	Interactive bool
 	ConfigDir   string
 	DataDir     string
 	HomeDir     string
+	Version     string
 	Log         func(format string, args ...interface{})
 }
+
+func (c *Context) WriteAsset(module, name, path string, vars map[string]string) error {
+	return assets.Materialize(c.DataDir, c.Version, module, name, path, vars)
+}
+
+func (c *Context) OwnsAsset(module, name, path string) bool {
+	return assets.Owned(c.DataDir, module, name, path)
+}
+
+func (c *Context) RemoveAsset(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if c.DataDir == "" {
+		return nil
+	}
+	return assets.Forget(c.DataDir, path)
+}
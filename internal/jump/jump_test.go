@@ -0,0 +1,132 @@
+package jump
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"devlog/internal/events"
+)
+
+func TestRemoteWebURL(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string
+		ok     bool
+	}{
+		{"git@github.com:acme/api.git", "https://github.com/acme/api", true},
+		{"https://github.com/acme/api.git", "https://github.com/acme/api", true},
+		{"ssh://git@gitlab.com/group/sub/api.git", "https://gitlab.com/group/sub/api", true},
+		{"https://user@bitbucket.org/acme/api", "https://bitbucket.org/acme/api", true},
+		{"/srv/git/api.git", "", false},
+		{"git@github.com", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := RemoteWebURL(tt.remote)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("RemoteWebURL(%q) = %q, %v; want %q, %v", tt.remote, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCommitURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/acme/api":    "https://github.com/acme/api/commit/abc123",
+		"https://gitlab.com/acme/api":    "https://gitlab.com/acme/api/-/commit/abc123",
+		"https://bitbucket.org/acme/api": "https://bitbucket.org/acme/api/commits/abc123",
+	}
+	for web, want := range tests {
+		if got := CommitURL(web, "abc123"); got != want {
+			t.Errorf("CommitURL(%q) = %q, want %q", web, got, want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "auth.go")
+	if err := os.WriteFile(file, []byte("package auth\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pr := events.NewEvent(string(events.SourceGitHub), string(events.TypePROpened))
+	pr.Payload["url"] = "https://github.com/acme/api/pull/7"
+
+	commit := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	commit.Payload["remote_url"] = "git@github.com:acme/api.git"
+	commit.Payload["hash"] = "abc123"
+
+	edit := events.NewEvent(string(events.SourceClaude), string(events.TypeFileEdit))
+	edit.Payload["file_path"] = file
+
+	relative := events.NewEvent(string(events.SourceClaude), string(events.TypeFileEdit))
+	relative.Payload["file_path"] = "auth.go"
+	relative.Payload["workdir"] = dir
+
+	command := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	command.Payload["workdir"] = dir
+
+	missing := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	missing.Payload["workdir"] = filepath.Join(dir, "gone")
+
+	tests := []struct {
+		name  string
+		event *events.Event
+		want  *Target
+	}{
+		{"pull request url", pr, &Target{Kind: KindURL, Location: "https://github.com/acme/api/pull/7"}},
+		{"commit from remote", commit, &Target{Kind: KindURL, Location: "https://github.com/acme/api/commit/abc123"}},
+		{"absolute file", edit, &Target{Kind: KindFile, Location: file}},
+		{"file relative to workdir", relative, &Target{Kind: KindFile, Location: file}},
+		{"workdir", command, &Target{Kind: KindDir, Location: dir}},
+		{"missing workdir", missing, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Resolve(tt.event)
+			if tt.want == nil {
+				if ok {
+					t.Errorf("Resolve() = %v, want no target", got)
+				}
+				return
+			}
+			if !ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	var started, ran []string
+	origStart, origRun := startCommand, runCommand
+	startCommand = func(name string, args ...string) error {
+		started = append([]string{name}, args...)
+		return nil
+	}
+	runCommand = func(name string, args ...string) error {
+		ran = append([]string{name}, args...)
+		return nil
+	}
+	defer func() { startCommand, runCommand = origStart, origRun }()
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+
+	if err := Open(&Target{Kind: KindFile, Location: "/src/auth.go"}); err != nil {
+		t.Fatalf("Open(file) error: %v", err)
+	}
+	if want := []string{"code", "--wait", "/src/auth.go"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("editor command = %v, want %v", ran, want)
+	}
+
+	if err := Open(&Target{Kind: KindURL, Location: "https://github.com/acme/api"}); err != nil {
+		t.Fatalf("Open(url) error: %v", err)
+	}
+	if len(started) == 0 || started[len(started)-1] != "https://github.com/acme/api" {
+		t.Errorf("browser command = %v, want URL as last argument", started)
+	}
+}
@@ -0,0 +1,62 @@
+package jump
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+var startCommand = func(name string, args ...string) error {
+	return exec.Command(name, args...).Start()
+}
+
+var runCommand = func(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func Open(t *Target) error {
+	if t.Kind == KindURL {
+		return openWithSystem(t.Location)
+	}
+
+	editor := Editor()
+	if editor == "" {
+		return openWithSystem(t.Location)
+	}
+
+	fields := strings.Fields(editor)
+	args := append(fields[1:], t.Location)
+	if err := runCommand(fields[0], args...); err != nil {
+		return fmt.Errorf("run %s: %w", fields[0], err)
+	}
+	return nil
+}
+
+func Editor() string {
+	if editor := strings.TrimSpace(os.Getenv("VISUAL")); editor != "" {
+		return editor
+	}
+	return strings.TrimSpace(os.Getenv("EDITOR"))
+}
+
+func openWithSystem(location string) error {
+	var err error
+	switch runtime.GOOS {
+	case "darwin":
+		err = startCommand("open", location)
+	case "windows":
+		err = startCommand("rundll32", "url.dll,FileProtocolHandler", location)
+	default:
+		err = startCommand("xdg-open", location)
+	}
+	if err != nil {
+		return fmt.Errorf("open %s: %w", location, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package jump
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"devlog/internal/events"
+)
+
+type Kind string
+
+const (
+	KindURL  Kind = "url"
+	KindFile Kind = "file"
+	KindDir  Kind = "dir"
+)
+
+type Target struct {
+	Kind     Kind
+	Location string
+}
+
+func (t *Target) String() string {
+	return fmt.Sprintf("%s %s", t.Kind, t.Location)
+}
+
+func Resolve(evt *events.Event) (*Target, bool) {
+	if u := payloadString(evt, "url"); isWebURL(u) {
+		return &Target{Kind: KindURL, Location: u}, true
+	}
+
+	if remote := payloadString(evt, "remote_url"); remote != "" {
+		if web, ok := RemoteWebURL(remote); ok {
+			if hash := payloadString(evt, "hash"); hash != "" {
+				return &Target{Kind: KindURL, Location: CommitURL(web, hash)}, true
+			}
+			return &Target{Kind: KindURL, Location: web}, true
+		}
+	}
+
+	workdir := payloadString(evt, "workdir")
+
+	for _, key := range []string{"file_path", "file"} {
+		path := payloadString(evt, key)
+		if path == "" {
+			continue
+		}
+		if !filepath.IsAbs(path) && workdir != "" {
+			path = filepath.Join(workdir, path)
+		}
+		if isFile(path) {
+			return &Target{Kind: KindFile, Location: path}, true
+		}
+	}
+
+	if workdir != "" && isDir(workdir) {
+		return &Target{Kind: KindDir, Location: workdir}, true
+	}
+
+	for _, repo := range []string{payloadString(evt, "repo_path"), evt.Repo} {
+		if filepath.IsAbs(repo) && isDir(repo) {
+			return &Target{Kind: KindDir, Location: repo}, true
+		}
+	}
+
+	return nil, false
+}
+
+func RemoteWebURL(remote string) (string, bool) {
+	remote = strings.TrimSpace(remote)
+
+	if rest, ok := strings.CutPrefix(remote, "git@"); ok {
+		host, path, found := strings.Cut(rest, ":")
+		if !found {
+			return "", false
+		}
+		remote = "https://" + host + "/" + path
+	}
+
+	u, err := url.Parse(remote)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+
+	switch u.Scheme {
+	case "https", "http", "ssh", "git":
+	default:
+		return "", false
+	}
+
+	path := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+	if path == "" {
+		return "", false
+	}
+
+	return "https://" + u.Hostname() + "/" + path, true
+}
+
+func CommitURL(webURL, hash string) string {
+	host := strings.ToLower(webURL)
+	switch {
+	case strings.Contains(host, "bitbucket"):
+		return webURL + "/commits/" + hash
+	case strings.Contains(host, "gitlab"):
+		return webURL + "/-/commit/" + hash
+	default:
+		return webURL + "/commit/" + hash
+	}
+}
+
+func payloadString(evt *events.Event, key string) string {
+	s, _ := evt.Payload[key].(string)
+	return strings.TrimSpace(s)
+}
+
+func isWebURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
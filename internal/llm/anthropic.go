@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -20,6 +21,7 @@ type anthropicRequest struct {
 	Model     string             `json:"model"`
 	Messages  []anthropicMessage `json:"messages"`
 	MaxTokens int                `json:"max_tokens"`
+	Stream    bool               `json:"stream,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -31,13 +33,17 @@ type anthropicResponse struct {
 	Content []struct {
 		Text string `json:"text"`
 	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
 	Error *struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
-func newAnthropicClient(apiKey, model string) *anthropicClient {
+func newAnthropicClient(apiKey, model string, timeout time.Duration) *anthropicClient {
 	if model == "" {
 		model = "claude-haiku-4-5-20251001"
 	}
@@ -45,13 +51,19 @@ func newAnthropicClient(apiKey, model string) *anthropicClient {
 	return &anthropicClient{
 		apiKey: apiKey,
 		model:  model,
-		client: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		client: newHTTPClient(timeout),
 	}
 }
 
 func (c *anthropicClient) Complete(ctx context.Context, prompt string) (string, error) {
+	completion, err := c.CompleteWithUsage(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return completion.Text, nil
+}
+
+func (c *anthropicClient) newRequest(ctx context.Context, prompt string, stream bool) (*http.Request, error) {
 	reqBody := anthropicRequest{
 		Model: c.model,
 		Messages: []anthropicMessage{
@@ -61,49 +73,147 @@ func (c *anthropicClient) Complete(ctx context.Context, prompt string) (string,
 			},
 		},
 		MaxTokens: 1000,
+		Stream:    stream,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-api-key", c.apiKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
+	return req, nil
+}
+
+func (c *anthropicClient) CompleteWithUsage(ctx context.Context, prompt string) (*Completion, error) {
+	req, err := c.newRequest(ctx, prompt, false)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("send request: %w", err)
+		return nil, fmt.Errorf("send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var anthropicResp anthropicResponse
 	if err := json.Unmarshal(body, &anthropicResp); err != nil {
-		return "", fmt.Errorf("unmarshal response: %w", err)
+		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if anthropicResp.Error != nil {
-		return "", fmt.Errorf("API error: %s (%s)", anthropicResp.Error.Message, anthropicResp.Error.Type)
+		return nil, fmt.Errorf("API error: %s (%s)", anthropicResp.Error.Message, anthropicResp.Error.Type)
 	}
 
 	if len(anthropicResp.Content) == 0 {
-		return "", fmt.Errorf("no content in response")
+		return nil, fmt.Errorf("no content in response")
+	}
+
+	return &Completion{
+		Text:         anthropicResp.Content[0].Text,
+		Provider:     string(ProviderAnthropic),
+		Model:        c.model,
+		InputTokens:  anthropicResp.Usage.InputTokens,
+		OutputTokens: anthropicResp.Usage.OutputTokens,
+	}, nil
+}
+
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (c *anthropicClient) CompleteStream(ctx context.Context, prompt string) (<-chan Chunk, error) {
+	req, err := c.newRequest(ctx, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+	body, err := postStream(c.client, req)
+	if err != nil {
+		return nil, err
 	}
 
-	return anthropicResp.Content[0].Text, nil
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		defer body.Close()
+
+		completion := &Completion{Provider: string(ProviderAnthropic), Model: c.model}
+		var text strings.Builder
+		var streamErr error
+		err := readSSE(body, func(_, data string) bool {
+			var ev anthropicStreamEvent
+			if err := json.Unmarshal([]byte(data), &ev); err != nil {
+				streamErr = fmt.Errorf("unmarshal stream event: %w", err)
+				return false
+			}
+			switch ev.Type {
+			case "message_start":
+				completion.InputTokens = ev.Message.Usage.InputTokens
+			case "content_block_delta":
+				if ev.Delta.Type == "text_delta" && ev.Delta.Text != "" {
+					text.WriteString(ev.Delta.Text)
+					return send(ctx, ch, Chunk{Text: ev.Delta.Text})
+				}
+			case "message_delta":
+				completion.OutputTokens = ev.Usage.OutputTokens
+			case "error":
+				if ev.Error != nil {
+					streamErr = fmt.Errorf("API error: %s (%s)", ev.Error.Message, ev.Error.Type)
+				} else {
+					streamErr = fmt.Errorf("API error in stream")
+				}
+				return false
+			case "message_stop":
+				return false
+			}
+			return true
+		})
+		if streamErr == nil {
+			streamErr = err
+		}
+		if streamErr == nil {
+			streamErr = ctx.Err()
+		}
+		if streamErr != nil {
+			send(ctx, ch, Chunk{Err: streamErr})
+			return
+		}
+
+		completion.Text = text.String()
+		send(ctx, ch, Chunk{Completion: completion})
+	}()
+	return ch, nil
 }
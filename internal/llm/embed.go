@@ -0,0 +1,183 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOllamaEmbeddingModel = "nomic-embed-text"
+	DefaultOpenAIEmbeddingModel = "text-embedding-3-small"
+)
+
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Model() string
+}
+
+func NewEmbedder(cfg Config) (Embedder, error) {
+	switch cfg.Provider {
+	case ProviderOllama:
+		return newOllamaEmbedder(cfg.BaseURL, cfg.Model, cfg.Timeout), nil
+	case ProviderOpenAI:
+		return newOpenAIEmbedder(cfg.APIKey, cfg.BaseURL, cfg.Model, cfg.Timeout), nil
+	case ProviderAnthropic:
+		return nil, fmt.Errorf("anthropic does not provide an embeddings API (use ollama or openai)")
+	default:
+		return nil, fmt.Errorf("unsupported embeddings provider %q", cfg.Provider)
+	}
+}
+
+type ollamaEmbedder struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func newOllamaEmbedder(baseURL, model string, timeout time.Duration) *ollamaEmbedder {
+	if baseURL == "" {
+		baseURL = "http://localhost:11434"
+	}
+	if model == "" {
+		model = DefaultOllamaEmbeddingModel
+	}
+	return &ollamaEmbedder{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  newHTTPClient(timeout),
+	}
+}
+
+func (e *ollamaEmbedder) Model() string {
+	return string(ProviderOllama) + "/" + e.model
+}
+
+func (e *ollamaEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+		Error      string      `json:"error,omitempty"`
+	}
+
+	err := postEmbeddingJSON(ctx, e.client, e.baseURL+"/api/embed", "", map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("API error: %s", resp.Error)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d inputs", len(resp.Embeddings), len(texts))
+	}
+
+	return resp.Embeddings, nil
+}
+
+type openAIEmbedder struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func newOpenAIEmbedder(apiKey, baseURL, model string, timeout time.Duration) *openAIEmbedder {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	if model == "" {
+		model = DefaultOpenAIEmbeddingModel
+	}
+	return &openAIEmbedder{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  newHTTPClient(timeout),
+	}
+}
+
+func (e *openAIEmbedder) Model() string {
+	return string(ProviderOpenAI) + "/" + e.model
+}
+
+func (e *openAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Error *struct {
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		} `json:"error,omitempty"`
+	}
+
+	err := postEmbeddingJSON(ctx, e.client, e.baseURL+"/embeddings", e.apiKey, map[string]interface{}{
+		"model": e.model,
+		"input": texts,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, fmt.Errorf("API error: %s (%s)", resp.Error.Message, resp.Error.Type)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("missing embedding for input %d", i)
+		}
+	}
+
+	return vectors, nil
+}
+
+func postEmbeddingJSON(ctx context.Context, client *http.Client, url, apiKey string, body interface{}, out interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	return nil
+}
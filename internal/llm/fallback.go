@@ -0,0 +1,232 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"devlog/internal/metrics"
+)
+
+type chainEntry struct {
+	name   string
+	client Client
+	cfg    Config
+}
+
+type fallbackClient struct {
+	entries []chainEntry
+}
+
+func newFallbackClient(providers []Config) (*fallbackClient, error) {
+	entries := make([]chainEntry, 0, len(providers))
+	for i, cfg := range providers {
+		client, err := newProviderClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("provider %d (%s): %w", i, cfg.Provider, err)
+		}
+		if client == nil {
+			return nil, fmt.Errorf("provider %d: unsupported provider %q", i, cfg.Provider)
+		}
+		entries = append(entries, chainEntry{
+			name:   providerLabel(cfg),
+			client: client,
+			cfg:    cfg,
+		})
+	}
+
+	return &fallbackClient{entries: entries}, nil
+}
+
+// ContextTokens returns the smallest context of the chain, so a prompt that
+// fits can fall back to any provider.
+func (c *fallbackClient) ContextTokens() int {
+	smallest := 0
+	for _, entry := range c.entries {
+		if n := ContextTokensFor(entry.cfg); smallest == 0 || n < smallest {
+			smallest = n
+		}
+	}
+	return smallest
+}
+
+func (c *fallbackClient) Complete(ctx context.Context, prompt string) (string, error) {
+	completion, err := c.CompleteWithUsage(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return completion.Text, nil
+}
+
+func (c *fallbackClient) CompleteWithUsage(ctx context.Context, prompt string) (*Completion, error) {
+	var errs []error
+	var lastErr error
+
+	for _, entry := range c.entries {
+		if err := ctx.Err(); err != nil {
+			lastErr = err
+			errs = append(errs, err)
+			break
+		}
+
+		completion, err := c.completeWith(ctx, entry, prompt)
+		if err == nil {
+			completion.Provider = entry.name
+			return completion, nil
+		}
+
+		lastErr = err
+		errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+	}
+
+	if len(c.entries) == 1 {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
+
+func (c *fallbackClient) completeWith(ctx context.Context, entry chainEntry, prompt string) (*Completion, error) {
+	callCtx := ctx
+	if entry.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, entry.cfg.Timeout)
+		defer cancel()
+	}
+
+	timer := metrics.StartLLMTimer(entry.name)
+	completion, err := CompleteWithUsage(callCtx, entry.client, prompt)
+	if err != nil {
+		timer.Fail()
+		return nil, err
+	}
+	timer.Stop()
+
+	c.recordCompletion(ctx, entry, completion)
+	return completion, nil
+}
+
+// recordCompletion fills in the model and cost of a finished completion and
+// records its token usage.
+func (c *fallbackClient) recordCompletion(ctx context.Context, entry chainEntry, completion *Completion) {
+	if completion.Model == "" {
+		completion.Model = entry.cfg.Model
+	}
+	completion.CostUSD = EstimateCost(entry.cfg.Provider, completion.Model, completion.InputTokens, completion.OutputTokens)
+
+	caller := CallerFromContext(ctx)
+	metrics.RecordLLMUsage(caller, completion.InputTokens, completion.OutputTokens, completion.CostUSD)
+	recordUsage(Usage{
+		Time:         time.Now(),
+		Caller:       caller,
+		Provider:     string(entry.cfg.Provider),
+		Model:        completion.Model,
+		InputTokens:  completion.InputTokens,
+		OutputTokens: completion.OutputTokens,
+		CostUSD:      completion.CostUSD,
+	})
+}
+
+// CompleteStream streams from the first provider that starts answering. A
+// provider that fails before sending any text is skipped like in
+// CompleteWithUsage; once text has been shown, a failure ends the stream.
+func (c *fallbackClient) CompleteStream(ctx context.Context, prompt string) (<-chan Chunk, error) {
+	out := make(chan Chunk)
+	started := make(chan error, 1)
+
+	go func() {
+		defer close(out)
+
+		var errs []error
+		var lastErr error
+		reported := false
+		report := func(err error) {
+			if !reported {
+				reported = true
+				started <- err
+			}
+		}
+
+		for _, entry := range c.entries {
+			if err := ctx.Err(); err != nil {
+				lastErr = err
+				errs = append(errs, err)
+				break
+			}
+
+			sent, err := c.streamWith(ctx, entry, prompt, out, func() { report(nil) })
+			if err == nil {
+				report(nil)
+				return
+			}
+			if sent {
+				send(ctx, out, Chunk{Err: fmt.Errorf("%s: %w", entry.name, err)})
+				return
+			}
+
+			lastErr = err
+			errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+		}
+
+		if len(c.entries) == 1 {
+			report(lastErr)
+			return
+		}
+		report(fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...)))
+	}()
+
+	if err := <-started; err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// streamWith forwards one provider's stream to out. It reports whether any
+// text was forwarded, and calls onText before the first piece.
+func (c *fallbackClient) streamWith(ctx context.Context, entry chainEntry, prompt string, out chan<- Chunk, onText func()) (bool, error) {
+	callCtx := ctx
+	if entry.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, entry.cfg.Timeout)
+		defer cancel()
+	}
+
+	timer := metrics.StartLLMTimer(entry.name)
+	ch, err := CompleteStream(callCtx, entry.client, prompt)
+	if err != nil {
+		timer.Fail()
+		return false, err
+	}
+
+	sent := false
+	for chunk := range ch {
+		switch {
+		case chunk.Err != nil:
+			timer.Fail()
+			return sent, chunk.Err
+		case chunk.Completion != nil:
+			timer.Stop()
+			completion := chunk.Completion
+			completion.Provider = entry.name
+			c.recordCompletion(ctx, entry, completion)
+			onText()
+			send(ctx, out, Chunk{Completion: completion})
+			return true, nil
+		case chunk.Text != "":
+			if !sent {
+				sent = true
+				onText()
+			}
+			if !send(ctx, out, Chunk{Text: chunk.Text}) {
+				timer.Fail()
+				return sent, ctx.Err()
+			}
+		}
+	}
+
+	timer.Fail()
+	if err := callCtx.Err(); err != nil {
+		return sent, err
+	}
+	return sent, fmt.Errorf("stream ended without a completion")
+}
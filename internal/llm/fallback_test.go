@@ -0,0 +1,157 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/metrics"
+)
+
+func newOpenAIServer(t *testing.T, status int, content string, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`{"error":{"type":"server_error","message":"boom"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": content}},
+			},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewClientSingleProvider(t *testing.T) {
+	server := newOpenAIServer(t, http.StatusOK, "hello", 0)
+
+	client, err := NewClient(Config{Provider: ProviderOpenAI, APIKey: "key", BaseURL: server.URL, Model: "single"})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	got, err := client.Complete(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if got != "hello" {
+		t.Errorf("Complete() = %q, want %q", got, "hello")
+	}
+}
+
+func TestNewClientUnknownProvider(t *testing.T) {
+	client, err := NewClient(Config{Provider: "unknown"})
+	if err != nil || client != nil {
+		t.Errorf("NewClient() = %v, %v; want nil, nil", client, err)
+	}
+
+	_, err = NewClient(Config{Providers: []Config{{Provider: "unknown"}}})
+	if err == nil {
+		t.Error("NewClient() should fail for unknown provider in chain")
+	}
+}
+
+func TestFallbackOnError(t *testing.T) {
+	failing := newOpenAIServer(t, http.StatusInternalServerError, "", 0)
+	working := newOpenAIServer(t, http.StatusOK, "from backup", 0)
+
+	client, err := NewClient(Config{Providers: []Config{
+		{Provider: ProviderOpenAI, BaseURL: failing.URL, Model: "primary-error"},
+		{Provider: ProviderOpenAI, BaseURL: working.URL, Model: "backup-error"},
+	}})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	got, err := client.Complete(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if got != "from backup" {
+		t.Errorf("Complete() = %q, want %q", got, "from backup")
+	}
+
+	if v := metrics.LLMCompletionErrors.Get("openai/primary-error"); v == nil || v.String() != "1" {
+		t.Errorf("primary error count = %v, want 1", v)
+	}
+	if v := metrics.LLMCompletionCount.Get("openai/backup-error"); v == nil || v.String() != "1" {
+		t.Errorf("backup completion count = %v, want 1", v)
+	}
+	if v := metrics.LLMCompletionCount.Get("openai/primary-error"); v != nil {
+		t.Errorf("primary completion count = %v, want none", v)
+	}
+}
+
+func TestFallbackOnTimeout(t *testing.T) {
+	slow := newOpenAIServer(t, http.StatusOK, "too late", 500*time.Millisecond)
+	working := newOpenAIServer(t, http.StatusOK, "on time", 0)
+
+	client, err := NewClient(Config{Providers: []Config{
+		{Provider: ProviderOpenAI, BaseURL: slow.URL, Model: "slow", Timeout: 50 * time.Millisecond},
+		{Provider: ProviderOpenAI, BaseURL: working.URL, Model: "fast"},
+	}})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	got, err := client.Complete(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if got != "on time" {
+		t.Errorf("Complete() = %q, want %q", got, "on time")
+	}
+}
+
+func TestFallbackAllFail(t *testing.T) {
+	first := newOpenAIServer(t, http.StatusInternalServerError, "", 0)
+	second := newOpenAIServer(t, http.StatusBadGateway, "", 0)
+
+	client, err := NewClient(Config{Providers: []Config{
+		{Provider: ProviderOpenAI, BaseURL: first.URL, Model: "first"},
+		{Provider: ProviderOpenAI, BaseURL: second.URL, Model: "second"},
+	}})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	_, err = client.Complete(context.Background(), "hi")
+	if err == nil {
+		t.Fatal("Complete() should fail when every provider fails")
+	}
+	for _, want := range []string{"all LLM providers failed", "openai/first", "openai/second"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err.Error(), want)
+		}
+	}
+}
+
+func TestProviderChain(t *testing.T) {
+	cfg := Config{Providers: []Config{{Provider: ProviderAnthropic}, {Provider: ProviderOllama}}}
+	if got := cfg.ProviderChain(); got != "anthropic -> ollama" {
+		t.Errorf("ProviderChain() = %q", got)
+	}
+
+	single := Config{Provider: ProviderOpenAI}
+	if got := single.ProviderChain(); got != "openai" {
+		t.Errorf("ProviderChain() = %q", got)
+	}
+}
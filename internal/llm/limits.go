@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultContextTokens is the context size assumed for models not listed in
+// modelContextTokens. It is ollama's usual num_ctx, which is small but safe.
+const DefaultContextTokens = 8192
+
+// modelContextTokens holds context sizes by model name prefix; the longest
+// matching prefix wins, as in modelPrices.
+var modelContextTokens = map[string]int{
+	"claude-":      200000,
+	"gpt-4o":       128000,
+	"gpt-4.1":      1000000,
+	"gpt-5":        400000,
+	"qwen2.5":      32768,
+	"qwen3":        40960,
+	"llama3.1":     131072,
+	"llama3.2":     131072,
+	"llama3":       8192,
+	"mistral":      32768,
+	"gemma3":       131072,
+	"deepseek-r1":  131072,
+	"phi4":         16384,
+	"codellama":    16384,
+	"granite3.3":   131072,
+	"mistral-nemo": 131072,
+}
+
+// ContextTokensFor returns the context size of a model: cfg.ContextTokens
+// when set, otherwise the known size of cfg.Model, otherwise
+// DefaultContextTokens.
+func ContextTokensFor(cfg Config) int {
+	if cfg.ContextTokens > 0 {
+		return cfg.ContextTokens
+	}
+
+	var best string
+	for prefix := range modelContextTokens {
+		if strings.HasPrefix(cfg.Model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return DefaultContextTokens
+	}
+	return modelContextTokens[best]
+}
+
+// ContextLimiter is implemented by clients that know how large a prompt
+// their model accepts.
+type ContextLimiter interface {
+	ContextTokens() int
+}
+
+// ContextTokens returns how many tokens a prompt sent to client may use in
+// total, including the answer.
+func ContextTokens(client Client) int {
+	if limiter, ok := client.(ContextLimiter); ok {
+		if n := limiter.ContextTokens(); n > 0 {
+			return n
+		}
+	}
+	return DefaultContextTokens
+}
+
+// EstimateTokens guesses the token count of text at four characters per
+// token, the usual rule of thumb for English. Code and other languages use
+// more, so callers budgeting a prompt should leave headroom.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
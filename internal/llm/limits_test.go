@@ -0,0 +1,35 @@
+package llm
+
+import "testing"
+
+func TestContextTokensFor(t *testing.T) {
+	tests := []struct {
+		cfg  Config
+		want int
+	}{
+		{Config{Provider: ProviderAnthropic, Model: "claude-sonnet-4-5"}, 200000},
+		{Config{Provider: ProviderOpenAI, Model: "gpt-4o-mini"}, 128000},
+		{Config{Provider: ProviderOllama, Model: "llama3.1:8b"}, 131072},
+		{Config{Provider: ProviderOllama, Model: "llama3:8b"}, 8192},
+		{Config{Provider: ProviderOllama, Model: "mystery"}, DefaultContextTokens},
+		{Config{Provider: ProviderOllama, Model: "qwen2.5:14b", ContextTokens: 65536}, 65536},
+	}
+	for _, tt := range tests {
+		if got := ContextTokensFor(tt.cfg); got != tt.want {
+			t.Errorf("ContextTokensFor(%s) = %d, want %d", tt.cfg.Model, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackContextTokens(t *testing.T) {
+	client, err := NewClient(Config{Providers: []Config{
+		{Provider: ProviderAnthropic, APIKey: "key", Model: "claude-haiku-4-5"},
+		{Provider: ProviderOllama, BaseURL: "http://localhost:11434", Model: "qwen2.5:14b"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ContextTokens(client); got != 32768 {
+		t.Errorf("ContextTokens() = %d, want the smaller context of the chain", got)
+	}
+}
@@ -1,32 +1,120 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultTimeout = 120 * time.Second
 
 type Client interface {
 	Complete(ctx context.Context, prompt string) (string, error)
 }
 
+type Completion struct {
+	Text         string
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+}
+
+type UsageClient interface {
+	CompleteWithUsage(ctx context.Context, prompt string) (*Completion, error)
+}
+
+func CompleteWithUsage(ctx context.Context, client Client, prompt string) (*Completion, error) {
+	if usageClient, ok := client.(UsageClient); ok {
+		return usageClient.CompleteWithUsage(ctx, prompt)
+	}
+
+	text, err := client.Complete(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &Completion{Text: text}, nil
+}
+
 type ProviderType string
 
 const (
 	ProviderAnthropic ProviderType = "anthropic"
+	ProviderOpenAI    ProviderType = "openai"
 	ProviderOllama    ProviderType = "ollama"
 )
 
+func IsValidProvider(provider string) bool {
+	switch ProviderType(provider) {
+	case ProviderAnthropic, ProviderOpenAI, ProviderOllama:
+		return true
+	default:
+		return false
+	}
+}
+
 type Config struct {
-	Provider ProviderType
-	APIKey   string
-	BaseURL  string
-	Model    string
+	Provider      ProviderType
+	APIKey        string
+	BaseURL       string
+	Model         string
+	Timeout       time.Duration
+	ContextTokens int
+	Providers     []Config
 }
 
 func NewClient(cfg Config) (Client, error) {
+	if len(cfg.Providers) > 0 {
+		return newFallbackClient(cfg.Providers)
+	}
+
+	client, err := newProviderClient(cfg)
+	if err != nil || client == nil {
+		return nil, err
+	}
+	return newFallbackClient([]Config{cfg})
+}
+
+func newProviderClient(cfg Config) (Client, error) {
 	switch cfg.Provider {
 	case ProviderOllama:
-		return newOllamaClient(cfg.BaseURL, cfg.Model), nil
+		return newOllamaClient(cfg.BaseURL, cfg.Model, cfg.Timeout), nil
 	case ProviderAnthropic:
-		return newAnthropicClient(cfg.APIKey, cfg.Model), nil
+		return newAnthropicClient(cfg.APIKey, cfg.Model, cfg.Timeout), nil
+	case ProviderOpenAI:
+		return newOpenAIClient(cfg.APIKey, cfg.BaseURL, cfg.Model, cfg.Timeout), nil
 	default:
 		return nil, nil
 	}
 }
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{
+		Timeout: timeout,
+	}
+}
+
+func providerLabel(cfg Config) string {
+	if cfg.Model == "" {
+		return string(cfg.Provider)
+	}
+	return fmt.Sprintf("%s/%s", cfg.Provider, cfg.Model)
+}
+
+func (c Config) ProviderChain() string {
+	if len(c.Providers) == 0 {
+		return string(c.Provider)
+	}
+
+	names := make([]string, len(c.Providers))
+	for i, provider := range c.Providers {
+		names[i] = string(provider.Provider)
+	}
+	return strings.Join(names, " -> ")
+}
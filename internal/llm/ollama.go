@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -32,9 +33,12 @@ type ollamaChatResponse struct {
 	Message ollamaMessage `json:"message"`
 	Done    bool          `json:"done"`
 	Error   string        `json:"error,omitempty"`
+
+	PromptEvalCount int `json:"prompt_eval_count"`
+	EvalCount       int `json:"eval_count"`
 }
 
-func newOllamaClient(baseURL, model string) *ollamaClient {
+func newOllamaClient(baseURL, model string, timeout time.Duration) *ollamaClient {
 	if baseURL == "" {
 		baseURL = "http://localhost:11434"
 	}
@@ -45,13 +49,19 @@ func newOllamaClient(baseURL, model string) *ollamaClient {
 	return &ollamaClient{
 		baseURL: baseURL,
 		model:   model,
-		client: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		client:  newHTTPClient(timeout),
 	}
 }
 
 func (c *ollamaClient) Complete(ctx context.Context, prompt string) (string, error) {
+	completion, err := c.CompleteWithUsage(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return completion.Text, nil
+}
+
+func (c *ollamaClient) newRequest(ctx context.Context, prompt string, stream bool) (*http.Request, error) {
 	reqBody := ollamaChatRequest{
 		Model: c.model,
 		Messages: []ollamaMessage{
@@ -60,45 +70,114 @@ func (c *ollamaClient) Complete(ctx context.Context, prompt string) (string, err
 				Content: prompt + " /no_think",
 			},
 		},
-		Stream: false,
+		Stream: stream,
 	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
 	url := c.baseURL + "/api/chat"
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (c *ollamaClient) CompleteWithUsage(ctx context.Context, prompt string) (*Completion, error) {
+	req, err := c.newRequest(ctx, prompt, false)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("send request: %w", err)
+		return nil, fmt.Errorf("send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
 	}
 
 	var chatResp ollamaChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return "", fmt.Errorf("unmarshal response: %w", err)
+		return nil, fmt.Errorf("unmarshal response: %w", err)
 	}
 
 	if chatResp.Error != "" {
-		return "", fmt.Errorf("API error: %s", chatResp.Error)
+		return nil, fmt.Errorf("API error: %s", chatResp.Error)
+	}
+
+	return &Completion{
+		Text:         chatResp.Message.Content,
+		Provider:     string(ProviderOllama),
+		Model:        c.model,
+		InputTokens:  chatResp.PromptEvalCount,
+		OutputTokens: chatResp.EvalCount,
+	}, nil
+}
+
+// CompleteStream reads Ollama's newline-delimited JSON stream, where the
+// final object has done set and carries the token counts.
+func (c *ollamaClient) CompleteStream(ctx context.Context, prompt string) (<-chan Chunk, error) {
+	req, err := c.newRequest(ctx, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+	body, err := postStream(c.client, req)
+	if err != nil {
+		return nil, err
 	}
 
-	return chatResp.Message.Content, nil
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		defer body.Close()
+
+		completion := &Completion{Provider: string(ProviderOllama), Model: c.model}
+		var text strings.Builder
+		dec := json.NewDecoder(body)
+		for {
+			var msg ollamaChatResponse
+			if err := dec.Decode(&msg); err != nil {
+				if err == io.EOF {
+					err = fmt.Errorf("stream ended before done")
+				}
+				if ctx.Err() != nil {
+					err = ctx.Err()
+				}
+				send(ctx, ch, Chunk{Err: err})
+				return
+			}
+			if msg.Error != "" {
+				send(ctx, ch, Chunk{Err: fmt.Errorf("API error: %s", msg.Error)})
+				return
+			}
+			if msg.Message.Content != "" {
+				text.WriteString(msg.Message.Content)
+				if !send(ctx, ch, Chunk{Text: msg.Message.Content}) {
+					return
+				}
+			}
+			if msg.Done {
+				completion.InputTokens = msg.PromptEvalCount
+				completion.OutputTokens = msg.EvalCount
+				break
+			}
+		}
+
+		completion.Text = text.String()
+		send(ctx, ch, Chunk{Completion: completion})
+	}()
+	return ch, nil
 }
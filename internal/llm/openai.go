@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type openAIClient struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+type openAIChatRequest struct {
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func newOpenAIClient(apiKey, baseURL, model string, timeout time.Duration) *openAIClient {
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	if model == "" {
+		model = "gpt-4o-mini"
+	}
+
+	return &openAIClient{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client:  newHTTPClient(timeout),
+	}
+}
+
+func (c *openAIClient) Complete(ctx context.Context, prompt string) (string, error) {
+	completion, err := c.CompleteWithUsage(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+	return completion.Text, nil
+}
+
+func (c *openAIClient) newRequest(ctx context.Context, prompt string, stream bool) (*http.Request, error) {
+	reqBody := openAIChatRequest{
+		Model: c.model,
+		Messages: []openAIMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		MaxTokens: 1000,
+	}
+	if stream {
+		reqBody.Stream = true
+		reqBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	url := c.baseURL + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return req, nil
+}
+
+func (c *openAIClient) CompleteWithUsage(ctx context.Context, prompt string) (*Completion, error) {
+	req, err := c.newRequest(ctx, prompt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var chatResp openAIChatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	if chatResp.Error != nil {
+		return nil, fmt.Errorf("API error: %s (%s)", chatResp.Error.Message, chatResp.Error.Type)
+	}
+
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	return &Completion{
+		Text:         chatResp.Choices[0].Message.Content,
+		Provider:     string(ProviderOpenAI),
+		Model:        c.model,
+		InputTokens:  chatResp.Usage.PromptTokens,
+		OutputTokens: chatResp.Usage.CompletionTokens,
+	}, nil
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (c *openAIClient) CompleteStream(ctx context.Context, prompt string) (<-chan Chunk, error) {
+	req, err := c.newRequest(ctx, prompt, true)
+	if err != nil {
+		return nil, err
+	}
+	body, err := postStream(c.client, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Chunk)
+	go func() {
+		defer close(ch)
+		defer body.Close()
+
+		completion := &Completion{Provider: string(ProviderOpenAI), Model: c.model}
+		var text strings.Builder
+		var streamErr error
+		err := readSSE(body, func(_, data string) bool {
+			if data == "[DONE]" {
+				return false
+			}
+			var chunk openAIStreamChunk
+			if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+				streamErr = fmt.Errorf("unmarshal stream chunk: %w", err)
+				return false
+			}
+			if chunk.Error != nil {
+				streamErr = fmt.Errorf("API error: %s (%s)", chunk.Error.Message, chunk.Error.Type)
+				return false
+			}
+			if chunk.Usage != nil {
+				completion.InputTokens = chunk.Usage.PromptTokens
+				completion.OutputTokens = chunk.Usage.CompletionTokens
+			}
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+				delta := chunk.Choices[0].Delta.Content
+				text.WriteString(delta)
+				return send(ctx, ch, Chunk{Text: delta})
+			}
+			return true
+		})
+		if streamErr == nil {
+			streamErr = err
+		}
+		if streamErr == nil {
+			streamErr = ctx.Err()
+		}
+		if streamErr != nil {
+			send(ctx, ch, Chunk{Err: streamErr})
+			return
+		}
+
+		completion.Text = text.String()
+		send(ctx, ch, Chunk{Completion: completion})
+	}()
+	return ch, nil
+}
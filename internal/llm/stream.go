@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Chunk is one piece of a streamed completion. Text holds the newly
+// generated text. The last chunk on a stream carries either the finished
+// Completion, with the full text and token usage, or Err; the channel is
+// closed after it.
+type Chunk struct {
+	Text       string
+	Completion *Completion
+	Err        error
+}
+
+type StreamClient interface {
+	CompleteStream(ctx context.Context, prompt string) (<-chan Chunk, error)
+}
+
+// CompleteStream streams a completion from clients that support it and
+// delivers the whole answer as a single chunk from those that don't.
+func CompleteStream(ctx context.Context, client Client, prompt string) (<-chan Chunk, error) {
+	if streamClient, ok := client.(StreamClient); ok {
+		return streamClient.CompleteStream(ctx, prompt)
+	}
+
+	completion, err := CompleteWithUsage(ctx, client, prompt)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan Chunk, 2)
+	ch <- Chunk{Text: completion.Text}
+	ch <- Chunk{Completion: completion}
+	close(ch)
+	return ch, nil
+}
+
+// StreamTo copies a streamed completion to w as it arrives and returns the
+// finished completion.
+func StreamTo(ctx context.Context, client Client, prompt string, w io.Writer) (*Completion, error) {
+	ch, err := CompleteStream(ctx, client, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	var completion *Completion
+	for chunk := range ch {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+		if chunk.Text != "" {
+			io.WriteString(w, chunk.Text)
+		}
+		if chunk.Completion != nil {
+			completion = chunk.Completion
+		}
+	}
+	if completion == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("stream ended without a completion")
+	}
+	return completion, nil
+}
+
+// postStream sends a streaming request and returns the response body once
+// the provider has accepted it.
+func postStream(client *http.Client, req *http.Request) (io.ReadCloser, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
+// readSSE calls fn with the event name and data of each server-sent event
+// in r until fn returns false or r ends.
+func readSSE(r io.Reader, fn func(event, data string) bool) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data.Len() > 0 && !fn(event, data.String()) {
+				return nil
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read stream: %w", err)
+	}
+	if data.Len() > 0 {
+		fn(event, data.String())
+	}
+	return nil
+}
+
+// send delivers a chunk unless the caller has given up on the stream.
+func send(ctx context.Context, ch chan<- Chunk, chunk Chunk) bool {
+	select {
+	case ch <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newOpenAIStreamServer(t *testing.T, events ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range events {
+			w.Write([]byte("data: " + ev + "\n\n"))
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOpenAIStream(t *testing.T) {
+	server := newOpenAIStreamServer(t,
+		`{"choices":[{"delta":{"role":"assistant"}}]}`,
+		`{"choices":[{"delta":{"content":"Hel"}}]}`,
+		`{"choices":[{"delta":{"content":"lo"}}]}`,
+		`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":2}}`,
+		`[DONE]`,
+	)
+
+	client, err := NewClient(Config{Provider: ProviderOpenAI, BaseURL: server.URL, Model: "gpt-4o-mini"})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	ch, err := CompleteStream(context.Background(), client, "hi")
+	if err != nil {
+		t.Fatalf("CompleteStream() error: %v", err)
+	}
+
+	var pieces []string
+	var completion *Completion
+	for chunk := range ch {
+		if chunk.Err != nil {
+			t.Fatalf("stream error: %v", chunk.Err)
+		}
+		if chunk.Text != "" {
+			pieces = append(pieces, chunk.Text)
+		}
+		if chunk.Completion != nil {
+			completion = chunk.Completion
+		}
+	}
+
+	if strings.Join(pieces, "|") != "Hel|lo" {
+		t.Errorf("pieces = %q", pieces)
+	}
+	if completion == nil || completion.Text != "Hello" || completion.InputTokens != 12 || completion.OutputTokens != 2 {
+		t.Errorf("completion = %+v", completion)
+	}
+	if completion != nil && completion.Provider != "openai/gpt-4o-mini" {
+		t.Errorf("Provider = %q", completion.Provider)
+	}
+}
+
+func TestOllamaStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"message":{"role":"assistant","content":"Work"},"done":false}` + "\n"))
+		w.Write([]byte(`{"message":{"role":"assistant","content":"ed"},"done":false}` + "\n"))
+		w.Write([]byte(`{"message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":30,"eval_count":2}` + "\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	client := newOllamaClient(server.URL, "qwen3", 0)
+	var buf strings.Builder
+	completion, err := StreamTo(context.Background(), client, "hi", &buf)
+	if err != nil {
+		t.Fatalf("StreamTo() error: %v", err)
+	}
+	if buf.String() != "Worked" || completion.Text != "Worked" {
+		t.Errorf("streamed %q, completion %q", buf.String(), completion.Text)
+	}
+	if completion.InputTokens != 30 || completion.OutputTokens != 2 {
+		t.Errorf("usage = %d/%d", completion.InputTokens, completion.OutputTokens)
+	}
+}
+
+func TestStreamFallsBackBeforeFirstText(t *testing.T) {
+	failing := newOpenAIServer(t, http.StatusInternalServerError, "", 0)
+	working := newOpenAIStreamServer(t, `{"choices":[{"delta":{"content":"from backup"}}]}`, `[DONE]`)
+
+	client, err := NewClient(Config{Providers: []Config{
+		{Provider: ProviderOpenAI, BaseURL: failing.URL, Model: "primary-stream"},
+		{Provider: ProviderOpenAI, BaseURL: working.URL, Model: "backup-stream"},
+	}})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	var buf strings.Builder
+	completion, err := StreamTo(context.Background(), client, "hi", &buf)
+	if err != nil {
+		t.Fatalf("StreamTo() error: %v", err)
+	}
+	if buf.String() != "from backup" || completion.Provider != "openai/backup-stream" {
+		t.Errorf("streamed %q from %q", buf.String(), completion.Provider)
+	}
+}
+
+func TestStreamAllProvidersFail(t *testing.T) {
+	failing := newOpenAIServer(t, http.StatusInternalServerError, "", 0)
+
+	client, err := NewClient(Config{Provider: ProviderOpenAI, BaseURL: failing.URL, Model: "only"})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+	if _, err := CompleteStream(context.Background(), client, "hi"); err == nil {
+		t.Error("CompleteStream() should fail when the only provider fails")
+	}
+}
+
+type plainClient struct{}
+
+func (plainClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return "whole answer", nil
+}
+
+func TestCompleteStreamWithoutStreamSupport(t *testing.T) {
+	var buf strings.Builder
+	completion, err := StreamTo(context.Background(), plainClient{}, "hi", &buf)
+	if err != nil {
+		t.Fatalf("StreamTo() error: %v", err)
+	}
+	if buf.String() != "whole answer" || completion.Text != "whole answer" {
+		t.Errorf("streamed %q, completion %q", buf.String(), completion.Text)
+	}
+}
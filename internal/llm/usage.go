@@ -0,0 +1,108 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Price is what a model costs in USD per million tokens.
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// modelPrices holds list prices by model name prefix; the longest matching
+// prefix wins so dated snapshots share their family's price.
+var modelPrices = map[string]Price{
+	"claude-haiku-4-5":  {Input: 1, Output: 5},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-opus-4-5":   {Input: 5, Output: 25},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.6},
+	"gpt-4o":            {Input: 2.5, Output: 10},
+	"gpt-4.1-nano":      {Input: 0.1, Output: 0.4},
+	"gpt-4.1-mini":      {Input: 0.4, Output: 1.6},
+	"gpt-4.1":           {Input: 2, Output: 8},
+	"gpt-5-nano":        {Input: 0.05, Output: 0.4},
+	"gpt-5-mini":        {Input: 0.25, Output: 2},
+	"gpt-5":             {Input: 1.25, Output: 10},
+}
+
+// PriceFor returns the price of a model. Local ollama models are free and
+// unknown models report ok=false so callers can show them as unpriced.
+func PriceFor(provider ProviderType, model string) (Price, bool) {
+	if provider == ProviderOllama {
+		return Price{}, true
+	}
+
+	var best string
+	for prefix := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return modelPrices[best], true
+}
+
+// EstimateCost returns the USD cost of a call, or 0 for unpriced models.
+func EstimateCost(provider ProviderType, model string, inputTokens, outputTokens int) float64 {
+	price, _ := PriceFor(provider, model)
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1_000_000
+}
+
+// Usage is the token count and estimated cost of one completion.
+type Usage struct {
+	Time         time.Time
+	Caller       string
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+}
+
+type callerKey struct{}
+
+// WithCaller tags LLM calls made with ctx so usage is attributed to the
+// plugin or command that made them.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func CallerFromContext(ctx context.Context) string {
+	if caller, ok := ctx.Value(callerKey{}).(string); ok && caller != "" {
+		return caller
+	}
+	return "other"
+}
+
+var (
+	recorderMu sync.RWMutex
+	recorder   func(Usage)
+)
+
+// SetUsageRecorder installs a function called after every successful
+// completion, typically one that persists usage for `devlog llm usage`.
+func SetUsageRecorder(fn func(Usage)) {
+	recorderMu.Lock()
+	defer recorderMu.Unlock()
+	recorder = fn
+}
+
+func recordUsage(u Usage) {
+	recorderMu.RLock()
+	fn := recorder
+	recorderMu.RUnlock()
+	if fn != nil {
+		fn(u)
+	}
+}
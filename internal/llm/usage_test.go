@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPriceFor(t *testing.T) {
+	tests := []struct {
+		provider ProviderType
+		model    string
+		want     Price
+		ok       bool
+	}{
+		{ProviderAnthropic, "claude-haiku-4-5-20251001", Price{Input: 1, Output: 5}, true},
+		{ProviderAnthropic, "claude-opus-4-5-20251101", Price{Input: 5, Output: 25}, true},
+		{ProviderAnthropic, "claude-opus-4-1-20250805", Price{Input: 15, Output: 75}, true},
+		{ProviderOpenAI, "gpt-4o-mini-2024-07-18", Price{Input: 0.15, Output: 0.6}, true},
+		{ProviderOpenAI, "gpt-4o", Price{Input: 2.5, Output: 10}, true},
+		{ProviderOllama, "qwen2.5:14b", Price{}, true},
+		{ProviderOpenAI, "local-llama", Price{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := PriceFor(tt.provider, tt.model)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("PriceFor(%s, %s) = %+v, %v; want %+v, %v", tt.provider, tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCompletionRecordsUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"role": "assistant", "content": "ok"}},
+			},
+			"usage": map[string]int{"prompt_tokens": 2000, "completion_tokens": 500},
+		})
+	}))
+	defer server.Close()
+
+	var recorded []Usage
+	SetUsageRecorder(func(u Usage) { recorded = append(recorded, u) })
+	defer SetUsageRecorder(nil)
+
+	client, err := NewClient(Config{Provider: ProviderOpenAI, APIKey: "key", BaseURL: server.URL, Model: "gpt-4o-mini"})
+	if err != nil {
+		t.Fatalf("NewClient() error: %v", err)
+	}
+
+	completion, err := CompleteWithUsage(WithCaller(context.Background(), "summarizer"), client, "hi")
+	if err != nil {
+		t.Fatalf("CompleteWithUsage() error: %v", err)
+	}
+
+	wantCost := (2000*0.15 + 500*0.6) / 1_000_000
+	if math.Abs(completion.CostUSD-wantCost) > 1e-12 {
+		t.Errorf("CostUSD = %v, want %v", completion.CostUSD, wantCost)
+	}
+	if len(recorded) != 1 {
+		t.Fatalf("recorded %d usages, want 1", len(recorded))
+	}
+	u := recorded[0]
+	if u.Caller != "summarizer" || u.Provider != "openai" || u.Model != "gpt-4o-mini" || u.InputTokens != 2000 || u.OutputTokens != 500 {
+		t.Errorf("usage = %+v", u)
+	}
+
+	if _, err := client.Complete(context.Background(), "hi"); err != nil {
+		t.Fatalf("Complete() error: %v", err)
+	}
+	if recorded[1].Caller != "other" {
+		t.Errorf("untagged caller = %q, want other", recorded[1].Caller)
+	}
+}
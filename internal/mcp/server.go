@@ -0,0 +1,337 @@
+// Package mcp implements the server side of the Model Context Protocol over
+// stdio: newline-delimited JSON-RPC 2.0 messages on stdin and stdout. It
+// only knows about tools and resources; what they do is up to the caller.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ProtocolVersion is the newest protocol revision the server speaks. Clients
+// asking for one of supportedVersions get that version back instead.
+const ProtocolVersion = "2025-06-18"
+
+var supportedVersions = map[string]bool{
+	"2024-11-05":    true,
+	"2025-03-26":    true,
+	ProtocolVersion: true,
+}
+
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// maxMessageSize bounds a single JSON-RPC message read from the client.
+const maxMessageSize = 4 << 20
+
+// Tool is a function the client's model may call. InputSchema is a JSON
+// Schema object describing Arguments.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]any
+	Handler     func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// Resource is a document the client may read and attach to its context.
+type Resource struct {
+	URI         string
+	Name        string
+	Description string
+	MIMEType    string
+	Read        func(ctx context.Context) (string, error)
+}
+
+type Server struct {
+	name         string
+	version      string
+	instructions string
+	tools        map[string]Tool
+	resources    map[string]Resource
+}
+
+func NewServer(name, version, instructions string) *Server {
+	return &Server{
+		name:         name,
+		version:      version,
+		instructions: instructions,
+		tools:        make(map[string]Tool),
+		resources:    make(map[string]Resource),
+	}
+}
+
+func (s *Server) AddTool(tool Tool) {
+	s.tools[tool.Name] = tool
+}
+
+func (s *Server) AddResource(resource Resource) {
+	s.resources[resource.URI] = resource
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// Serve answers requests from r on w until r is exhausted or ctx is
+// cancelled. Requests are handled one at a time, in order.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+
+	lines := make(chan []byte)
+	scanErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		scanErr <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-scanErr:
+					if err != nil {
+						return fmt.Errorf("read request: %w", err)
+					}
+				default:
+				}
+				return nil
+			}
+			if len(line) == 0 {
+				continue
+			}
+			if resp := s.handleMessage(ctx, line); resp != nil {
+				if err := s.write(w, resp); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (s *Server) write(w io.Writer, resp *response) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("encode response: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("write response: %w", err)
+	}
+	return nil
+}
+
+// handleMessage returns nil for notifications, which get no response.
+func (s *Server) handleMessage(ctx context.Context, line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error"}}
+	}
+	if len(req.ID) == 0 {
+		return nil
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request"}}
+	}
+
+	result, err := s.dispatch(ctx, req.Method, req.Params)
+	if err != nil {
+		rerr, ok := err.(*rpcError)
+		if !ok {
+			rerr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rerr}
+	}
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: result}
+}
+
+func (s *Server) dispatch(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, params)
+	case "resources/list":
+		return s.listResources(), nil
+	case "resources/read":
+		return s.readResource(ctx, params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid initialize params"}
+		}
+	}
+	version := ProtocolVersion
+	if supportedVersions[p.ProtocolVersion] {
+		version = p.ProtocolVersion
+	}
+
+	result := map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools":     map[string]any{},
+			"resources": map[string]any{},
+		},
+		"serverInfo": map[string]string{
+			"name":    s.name,
+			"version": s.version,
+		},
+	}
+	if s.instructions != "" {
+		result["instructions"] = s.instructions
+	}
+	return result, nil
+}
+
+func (s *Server) listTools() any {
+	names := make([]string, 0, len(s.tools))
+	for name := range s.tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tools := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		tool := s.tools[name]
+		schema := tool.InputSchema
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		tools = append(tools, map[string]any{
+			"name":        tool.Name,
+			"description": tool.Description,
+			"inputSchema": schema,
+		})
+	}
+	return map[string]any{"tools": tools}
+}
+
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// callTool reports handler errors as a tool result with isError set, so the
+// model sees the message and can retry with different arguments.
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid tools/call params"}
+	}
+	tool, ok := s.tools[p.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	}
+	args := p.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	text, err := tool.Handler(ctx, args)
+	if err != nil {
+		return map[string]any{
+			"content": []textContent{{Type: "text", Text: err.Error()}},
+			"isError": true,
+		}, nil
+	}
+	return map[string]any{
+		"content": []textContent{{Type: "text", Text: text}},
+	}, nil
+}
+
+func (s *Server) listResources() any {
+	uris := make([]string, 0, len(s.resources))
+	for uri := range s.resources {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	resources := make([]map[string]any, 0, len(uris))
+	for _, uri := range uris {
+		res := s.resources[uri]
+		resources = append(resources, map[string]any{
+			"uri":         res.URI,
+			"name":        res.Name,
+			"description": res.Description,
+			"mimeType":    res.MIMEType,
+		})
+	}
+	return map[string]any{"resources": resources}
+}
+
+func (s *Server) readResource(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "invalid resources/read params"}
+	}
+	res, ok := s.resources[p.URI]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown resource: " + p.URI}
+	}
+	text, err := res.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"contents": []map[string]string{{
+			"uri":      res.URI,
+			"mimeType": res.MIMEType,
+			"text":     text,
+		}},
+	}, nil
+}
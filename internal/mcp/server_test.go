@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func serve(t *testing.T, srv *Server, messages ...string) []map[string]any {
+	t.Helper()
+	var out strings.Builder
+	if err := srv.Serve(context.Background(), strings.NewReader(strings.Join(messages, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve() error: %v", err)
+	}
+
+	var responses []map[string]any
+	scanner := bufio.NewScanner(strings.NewReader(out.String()))
+	for scanner.Scan() {
+		var resp map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", scanner.Text(), err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func testServer() *Server {
+	srv := NewServer("devlog", "test", "Use the echo tool.")
+	srv.AddTool(Tool{
+		Name:        "echo",
+		Description: "Echoes its text argument",
+		InputSchema: map[string]any{"type": "object"},
+		Handler: func(ctx context.Context, args json.RawMessage) (string, error) {
+			var a struct {
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(args, &a); err != nil {
+				return "", err
+			}
+			if a.Text == "" {
+				return "", fmt.Errorf("text is required")
+			}
+			return a.Text, nil
+		},
+	})
+	srv.AddResource(Resource{
+		URI:      "devlog://hello",
+		Name:     "Hello",
+		MIMEType: "text/plain",
+		Read:     func(ctx context.Context) (string, error) { return "hello", nil },
+	})
+	return srv
+}
+
+func TestServeInitialize(t *testing.T) {
+	responses := serve(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	)
+	if len(responses) != 2 {
+		t.Fatalf("expected 2 responses (notification gets none), got %d", len(responses))
+	}
+
+	result := responses[0]["result"].(map[string]any)
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("protocolVersion = %v, want the client's supported version", result["protocolVersion"])
+	}
+	if info := result["serverInfo"].(map[string]any); info["name"] != "devlog" {
+		t.Errorf("serverInfo = %v", info)
+	}
+	if result["instructions"] != "Use the echo tool." {
+		t.Errorf("instructions = %v", result["instructions"])
+	}
+	if responses[1]["id"].(float64) != 2 {
+		t.Errorf("ping id = %v", responses[1]["id"])
+	}
+
+	responses = serve(t, testServer(), `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+	if v := responses[0]["result"].(map[string]any)["protocolVersion"]; v != ProtocolVersion {
+		t.Errorf("unsupported client version answered with %v, want %s", v, ProtocolVersion)
+	}
+}
+
+func TestServeTools(t *testing.T) {
+	responses := serve(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"missing"}}`,
+	)
+
+	tools := responses[0]["result"].(map[string]any)["tools"].([]any)
+	if len(tools) != 1 || tools[0].(map[string]any)["name"] != "echo" {
+		t.Errorf("tools/list = %v", tools)
+	}
+
+	content := responses[1]["result"].(map[string]any)["content"].([]any)
+	if text := content[0].(map[string]any)["text"]; text != "hi" {
+		t.Errorf("echo result = %v", text)
+	}
+
+	failed := responses[2]["result"].(map[string]any)
+	if failed["isError"] != true {
+		t.Errorf("handler error should set isError, got %v", failed)
+	}
+
+	if code := responses[3]["error"].(map[string]any)["code"].(float64); code != codeInvalidParams {
+		t.Errorf("unknown tool code = %v", code)
+	}
+}
+
+func TestServeResourcesAndErrors(t *testing.T) {
+	responses := serve(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"resources/read","params":{"uri":"devlog://hello"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"prompts/list"}`,
+		`not json`,
+	)
+
+	resources := responses[0]["result"].(map[string]any)["resources"].([]any)
+	if len(resources) != 1 || resources[0].(map[string]any)["uri"] != "devlog://hello" {
+		t.Errorf("resources/list = %v", resources)
+	}
+
+	contents := responses[1]["result"].(map[string]any)["contents"].([]any)
+	if text := contents[0].(map[string]any)["text"]; text != "hello" {
+		t.Errorf("resources/read = %v", text)
+	}
+
+	if code := responses[2]["error"].(map[string]any)["code"].(float64); code != codeMethodNotFound {
+		t.Errorf("unknown method code = %v", code)
+	}
+	if code := responses[3]["error"].(map[string]any)["code"].(float64); code != codeParseError {
+		t.Errorf("parse error code = %v", code)
+	}
+}
@@ -9,11 +9,22 @@ import (
 var (
 	EventIngestionRate      = expvar.NewInt("events.ingested.total")
 	EventIngestionErrors    = expvar.NewInt("events.ingested.errors")
+	EventsOverQuota         = expvar.NewMap("events.dropped.over_quota")
+	EventsSampledOut        = expvar.NewMap("events.dropped.sampled_out")
 	StorageOperationLatency = expvar.NewMap("storage.operation.latency_ms")
 	PluginExecutionCount    = expvar.NewMap("plugins.execution.count")
 	PluginExecutionDuration = expvar.NewMap("plugins.execution.duration_ms")
 	APIRequestCount         = expvar.NewMap("api.requests.count")
 	APIRequestDuration      = expvar.NewMap("api.requests.duration_ms")
+	APIRateLimited          = expvar.NewMap("api.requests.rate_limited")
+	QueryCache              = expvar.NewMap("storage.query_cache")
+	WriteBuffer             = expvar.NewMap("storage.write_buffer")
+	LLMCompletionCount      = expvar.NewMap("llm.completions.count")
+	LLMCompletionErrors     = expvar.NewMap("llm.completions.errors")
+	LLMCompletionDuration   = expvar.NewMap("llm.completions.duration_ms")
+	LLMInputTokens          = expvar.NewMap("llm.tokens.input")
+	LLMOutputTokens         = expvar.NewMap("llm.tokens.output")
+	SummaryQualityCount     = expvar.NewMap("summary.quality")
 )
 
 type Timer struct {
@@ -69,6 +80,56 @@ func (t *APITimer) Stop() {
 	APIRequestDuration.Add(t.endpoint, duration.Milliseconds())
 }
 
+type LLMTimer struct {
+	start    time.Time
+	provider string
+}
+
+func StartLLMTimer(provider string) *LLMTimer {
+	return &LLMTimer{
+		start:    time.Now(),
+		provider: provider,
+	}
+}
+
+func (t *LLMTimer) Stop() {
+	duration := time.Since(t.start)
+	LLMCompletionCount.Add(t.provider, 1)
+	LLMCompletionDuration.Add(t.provider, duration.Milliseconds())
+	GlobalSnapshot.RecordLLMCompletion(t.provider)
+}
+
+func (t *LLMTimer) Fail() {
+	LLMCompletionErrors.Add(t.provider, 1)
+}
+
+// RecordLLMUsage attributes the tokens and estimated cost of a completion to
+// the plugin or command that requested it.
+func RecordLLMUsage(caller string, inputTokens, outputTokens int, costUSD float64) {
+	LLMInputTokens.Add(caller, int64(inputTokens))
+	LLMOutputTokens.Add(caller, int64(outputTokens))
+	GlobalSnapshot.RecordLLMUsage(caller, inputTokens, outputTokens, costUSD)
+}
+
+// Outcomes of checking a generated summary against its required format.
+const (
+	SummaryValid    = "valid"
+	SummaryRepaired = "repaired"
+	SummaryInvalid  = "invalid"
+)
+
+// RecordSummaryQuality records whether a summary met its format on the first
+// try, after retries, or not at all, and which rules the kept summary
+// broke.
+func RecordSummaryQuality(outcome string, retries int, brokenRules []string) {
+	SummaryQualityCount.Add(outcome, 1)
+	SummaryQualityCount.Add("retries", int64(retries))
+	for _, rule := range brokenRules {
+		SummaryQualityCount.Add("violation."+rule, 1)
+	}
+	GlobalSnapshot.RecordSummaryQuality(outcome, retries, brokenRules)
+}
+
 type Counter struct {
 	mu    sync.Mutex
 	value int64
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"sync"
 	"time"
 )
@@ -93,6 +94,34 @@ type TimeBucket struct {
 	TotalEvents    int64            `json:"total_events"`
 }
 
+type LLMUsage struct {
+	Completions  int64   `json:"completions"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// SummaryQuality counts how generated summaries fared against their
+// required format.
+type SummaryQuality struct {
+	Valid      int64            `json:"valid"`
+	Repaired   int64            `json:"repaired"`
+	Invalid    int64            `json:"invalid"`
+	Retries    int64            `json:"retries"`
+	Violations map[string]int64 `json:"violations,omitempty"`
+}
+
+// ResourceUsage is the latest resource sample of a plugin or poller, keyed
+// in the snapshot by its component name such as "plugin/llm".
+type ResourceUsage struct {
+	Goroutines int     `json:"goroutines"`
+	CPUPercent float64 `json:"cpu_percent"`
+	// OverBudget describes each budget limit the sample broke.
+	OverBudget []string  `json:"over_budget,omitempty"`
+	Restarts   int64     `json:"budget_restarts,omitempty"`
+	SampledAt  time.Time `json:"sampled_at"`
+}
+
 type Snapshot struct {
 	mu sync.RWMutex
 
@@ -100,6 +129,7 @@ type Snapshot struct {
 	PluginLastError  map[string]string    `json:"plugin_last_error"`
 	PluginErrorCount map[string]int64     `json:"plugin_error_count"`
 	PluginRestarts   map[string]int64     `json:"plugin_restarts"`
+	PluginFailed     map[string]bool      `json:"plugin_failed,omitempty"`
 
 	EventsIngested int64            `json:"events_ingested"`
 	EventsBySource map[string]int64 `json:"events_by_source"`
@@ -108,6 +138,18 @@ type Snapshot struct {
 	HourlyBuckets map[int64]*TimeBucket `json:"hourly_buckets,omitempty"`
 	DailyBuckets  map[int64]*TimeBucket `json:"daily_buckets,omitempty"`
 
+	LLMCompletionsByProvider map[string]int64    `json:"llm_completions_by_provider"`
+	LLMLastProvider          string              `json:"llm_last_provider,omitempty"`
+	LLMUsageByPlugin         map[string]LLMUsage `json:"llm_usage_by_plugin"`
+	LLMCostTodayUSD          float64             `json:"llm_cost_today_usd"`
+
+	SummaryQuality SummaryQuality `json:"summary_quality"`
+
+	Resources        map[string]ResourceUsage `json:"resources,omitempty"`
+	HeapBytes        uint64                   `json:"heap_bytes"`
+	HeapOverBudget   string                   `json:"heap_over_budget,omitempty"`
+	DaemonCPUPercent float64                  `json:"daemon_cpu_percent"`
+
 	QueueDepth   int64 `json:"queue_depth"`
 	DatabaseSize int64 `json:"database_size_bytes"`
 	EventCount   int64 `json:"event_count"`
@@ -117,23 +159,28 @@ type Snapshot struct {
 
 	ringBuffer  *RingBuffer
 	lastCleanup time.Time
+	llmCostDay  string
 }
 
 var GlobalSnapshot = NewSnapshot()
 
 func NewSnapshot() *Snapshot {
 	return &Snapshot{
-		PluginStartTime:  make(map[string]time.Time),
-		PluginLastError:  make(map[string]string),
-		PluginErrorCount: make(map[string]int64),
-		PluginRestarts:   make(map[string]int64),
-		EventsBySource:   make(map[string]int64),
-		EventsByType:     make(map[string]int64),
-		HourlyBuckets:    make(map[int64]*TimeBucket),
-		DailyBuckets:     make(map[int64]*TimeBucket),
-		LastStartTime:    time.Now(),
-		ringBuffer:       NewRingBuffer(RingBufferSize),
-		lastCleanup:      time.Now(),
+		PluginStartTime:          make(map[string]time.Time),
+		PluginLastError:          make(map[string]string),
+		PluginErrorCount:         make(map[string]int64),
+		PluginRestarts:           make(map[string]int64),
+		PluginFailed:             make(map[string]bool),
+		EventsBySource:           make(map[string]int64),
+		EventsByType:             make(map[string]int64),
+		HourlyBuckets:            make(map[int64]*TimeBucket),
+		DailyBuckets:             make(map[int64]*TimeBucket),
+		LLMCompletionsByProvider: make(map[string]int64),
+		LLMUsageByPlugin:         make(map[string]LLMUsage),
+		Resources:                make(map[string]ResourceUsage),
+		LastStartTime:            time.Now(),
+		ringBuffer:               NewRingBuffer(RingBufferSize),
+		lastCleanup:              time.Now(),
 	}
 }
 
@@ -141,6 +188,7 @@ func (s *Snapshot) RecordPluginStart(name string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.PluginStartTime[name] = time.Now()
+	delete(s.PluginFailed, name)
 }
 
 func (s *Snapshot) RecordPluginError(name string, err error) {
@@ -156,6 +204,92 @@ func (s *Snapshot) RecordPluginRestart(name string) {
 	s.PluginRestarts[name]++
 }
 
+// RecordPluginFailed marks a plugin the daemon stopped restarting after
+// repeated failures.
+func (s *Snapshot) RecordPluginFailed(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PluginFailed[name] = true
+}
+
+func (s *Snapshot) RecordLLMCompletion(provider string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.LLMCompletionsByProvider[provider]++
+	s.LLMLastProvider = provider
+}
+
+func (s *Snapshot) RecordLLMUsage(caller string, inputTokens, outputTokens int, costUSD float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := s.LLMUsageByPlugin[caller]
+	usage.Completions++
+	usage.InputTokens += int64(inputTokens)
+	usage.OutputTokens += int64(outputTokens)
+	usage.CostUSD += costUSD
+	s.LLMUsageByPlugin[caller] = usage
+
+	today := time.Now().Format("2006-01-02")
+	if s.llmCostDay != today {
+		s.llmCostDay = today
+		s.LLMCostTodayUSD = 0
+	}
+	s.LLMCostTodayUSD += costUSD
+}
+
+func (s *Snapshot) RecordSummaryQuality(outcome string, retries int, brokenRules []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch outcome {
+	case SummaryValid:
+		s.SummaryQuality.Valid++
+	case SummaryRepaired:
+		s.SummaryQuality.Repaired++
+	case SummaryInvalid:
+		s.SummaryQuality.Invalid++
+	}
+	s.SummaryQuality.Retries += int64(retries)
+	for _, rule := range brokenRules {
+		if s.SummaryQuality.Violations == nil {
+			s.SummaryQuality.Violations = make(map[string]int64)
+		}
+		s.SummaryQuality.Violations[rule]++
+	}
+}
+
+// RecordResources replaces the resource samples with usage, keeping each
+// component's count of budget restarts. heapOverBudget describes the heap
+// limit the daemon broke, if any.
+func (s *Snapshot) RecordResources(usage map[string]ResourceUsage, heapBytes uint64, heapOverBudget string, daemonCPUPercent float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, u := range usage {
+		u.Restarts = s.Resources[name].Restarts
+		usage[name] = u
+	}
+	for name, u := range s.Resources {
+		if _, ok := usage[name]; !ok && u.Restarts > 0 {
+			usage[name] = ResourceUsage{Restarts: u.Restarts}
+		}
+	}
+	s.Resources = usage
+	s.HeapBytes = heapBytes
+	s.HeapOverBudget = heapOverBudget
+	s.DaemonCPUPercent = daemonCPUPercent
+}
+
+// RecordBudgetRestart counts a restart of a component that stayed over its
+// resource budget.
+func (s *Snapshot) RecordBudgetRestart(component string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u := s.Resources[component]
+	u.Restarts++
+	s.Resources[component] = u
+}
+
 func (s *Snapshot) RecordEventIngested(source, eventType string) {
 	now := time.Now()
 
@@ -265,24 +399,43 @@ func (s *Snapshot) Copy() *Snapshot {
 	defer s.mu.RUnlock()
 
 	snapshot := &Snapshot{
-		PluginStartTime:  make(map[string]time.Time),
-		PluginLastError:  make(map[string]string),
-		PluginErrorCount: make(map[string]int64),
-		PluginRestarts:   make(map[string]int64),
-		EventsBySource:   make(map[string]int64),
-		EventsByType:     make(map[string]int64),
-		HourlyBuckets:    make(map[int64]*TimeBucket),
-		DailyBuckets:     make(map[int64]*TimeBucket),
-		EventsIngested:   s.EventsIngested,
-		QueueDepth:       s.QueueDepth,
-		DatabaseSize:     s.DatabaseSize,
-		EventCount:       s.EventCount,
-		UptimeSeconds:    s.UptimeSeconds,
-		LastStartTime:    s.LastStartTime,
-		ringBuffer:       s.ringBuffer,
-		lastCleanup:      s.lastCleanup,
+		PluginStartTime:          make(map[string]time.Time),
+		PluginLastError:          make(map[string]string),
+		PluginErrorCount:         make(map[string]int64),
+		PluginRestarts:           make(map[string]int64),
+		PluginFailed:             make(map[string]bool),
+		EventsBySource:           make(map[string]int64),
+		EventsByType:             make(map[string]int64),
+		HourlyBuckets:            make(map[int64]*TimeBucket),
+		DailyBuckets:             make(map[int64]*TimeBucket),
+		EventsIngested:           s.EventsIngested,
+		LLMCompletionsByProvider: copyMap(s.LLMCompletionsByProvider),
+		LLMLastProvider:          s.LLMLastProvider,
+		LLMUsageByPlugin:         copyMap(s.LLMUsageByPlugin),
+		LLMCostTodayUSD:          s.LLMCostTodayUSD,
+		SummaryQuality:           s.SummaryQuality,
+		Resources:                make(map[string]ResourceUsage, len(s.Resources)),
+		HeapBytes:                s.HeapBytes,
+		HeapOverBudget:           s.HeapOverBudget,
+		DaemonCPUPercent:         s.DaemonCPUPercent,
+		QueueDepth:               s.QueueDepth,
+		DatabaseSize:             s.DatabaseSize,
+		EventCount:               s.EventCount,
+		UptimeSeconds:            s.UptimeSeconds,
+		LastStartTime:            s.LastStartTime,
+		ringBuffer:               s.ringBuffer,
+		lastCleanup:              s.lastCleanup,
+		llmCostDay:               s.llmCostDay,
 	}
 
+	if s.SummaryQuality.Violations != nil {
+		snapshot.SummaryQuality.Violations = copyMap(s.SummaryQuality.Violations)
+	}
+
+	for k, v := range s.Resources {
+		v.OverBudget = append([]string(nil), v.OverBudget...)
+		snapshot.Resources[k] = v
+	}
 	for k, v := range s.PluginStartTime {
 		snapshot.PluginStartTime[k] = v
 	}
@@ -295,6 +448,9 @@ func (s *Snapshot) Copy() *Snapshot {
 	for k, v := range s.PluginRestarts {
 		snapshot.PluginRestarts[k] = v
 	}
+	for k, v := range s.PluginFailed {
+		snapshot.PluginFailed[k] = v
+	}
 	for k, v := range s.EventsBySource {
 		snapshot.EventsBySource[k] = v
 	}
@@ -341,7 +497,12 @@ type Summary struct {
 	QueueDepth     int64             `json:"queue_depth"`
 	EventsBySource map[string]int64  `json:"events_by_source"`
 	PluginStatus   map[string]string `json:"plugin_status"`
+	PluginRestarts map[string]int64  `json:"plugin_restarts,omitempty"`
 	ErrorCount     int64             `json:"total_errors"`
+	LLMCostToday   float64           `json:"llm_cost_today_usd"`
+	// ResourceWarnings lists the plugins and pollers over their resource
+	// budgets in the latest sample, and the daemon's heap if it is over.
+	ResourceWarnings []string `json:"resource_warnings,omitempty"`
 }
 
 func (s *Snapshot) GetSummary() *Summary {
@@ -368,6 +529,20 @@ func (s *Snapshot) GetSummary() *Summary {
 		if runtime < 5*time.Second {
 			pluginStatus[name] = "starting"
 		}
+		if s.PluginFailed[name] {
+			pluginStatus[name] = "failed"
+		}
+	}
+
+	var warnings []string
+	for name, usage := range s.Resources {
+		for _, over := range usage.OverBudget {
+			warnings = append(warnings, name+": "+over)
+		}
+	}
+	sort.Strings(warnings)
+	if s.HeapOverBudget != "" {
+		warnings = append(warnings, "daemon: "+s.HeapOverBudget)
 	}
 
 	return &Summary{
@@ -376,7 +551,11 @@ func (s *Snapshot) GetSummary() *Summary {
 		QueueDepth:     s.QueueDepth,
 		EventsBySource: copyMap(s.EventsBySource),
 		PluginStatus:   pluginStatus,
+		PluginRestarts: copyMap(s.PluginRestarts),
 		ErrorCount:     totalErrors,
+		LLMCostToday:   s.LLMCostTodayUSD,
+
+		ResourceWarnings: warnings,
 	}
 }
 
@@ -382,3 +382,21 @@ func BenchmarkSnapshot_Copy(b *testing.B) {
 		_ = s.Copy()
 	}
 }
+
+func TestSnapshot_RecordLLMUsage(t *testing.T) {
+	s := NewSnapshot()
+
+	s.RecordLLMUsage("summarizer", 1000, 200, 0.002)
+	s.RecordLLMUsage("summarizer", 500, 100, 0.001)
+	s.RecordLLMUsage("query", 300, 50, 0.0005)
+
+	got := s.Copy().LLMUsageByPlugin["summarizer"]
+	want := LLMUsage{Completions: 2, InputTokens: 1500, OutputTokens: 300, CostUSD: 0.003}
+	if got.Completions != want.Completions || got.InputTokens != want.InputTokens ||
+		got.OutputTokens != want.OutputTokens || got.CostUSD < 0.00299 || got.CostUSD > 0.00301 {
+		t.Errorf("summarizer usage = %+v, want %+v", got, want)
+	}
+	if s.LLMCostTodayUSD < 0.00349 || s.LLMCostTodayUSD > 0.00351 {
+		t.Errorf("cost today = %v, want 0.0035", s.LLMCostTodayUSD)
+	}
+}
@@ -0,0 +1,51 @@
+package modules
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"devlog/internal/install"
+)
+
+// Health describes how to tell whether a module is actually recording.
+type Health struct {
+	// Sources are the event sources the module's events are stored under.
+	Sources []string
+	// QuietAfter is how long the module can go without events before that
+	// looks like a broken hook. Zero means silence is normal, as it is for
+	// webhooks and pollers of other people's activity.
+	QuietAfter time.Duration
+	// Checks are live checks the asset manifest cannot make, such as
+	// whether a wrapper is the command found first on PATH.
+	Checks []Check
+}
+
+// HealthChecker is implemented by modules that know which sources they
+// record and how often to expect events. Modules without it are assumed to
+// record under their own name with no quiet threshold.
+type HealthChecker interface {
+	HealthCheck(ctx *install.Context) Health
+}
+
+// WrapperCheck reports whether name resolves on PATH to the devlog wrapper
+// in ~/.local/bin rather than to the real binary.
+func WrapperCheck(ctx *install.Context, name string) Check {
+	check := Check{Name: name + " on PATH"}
+	wrapperPath := filepath.Join(ctx.HomeDir, ".local", "bin", name)
+
+	found, err := exec.LookPath(name)
+	if err != nil {
+		check.Message = fmt.Sprintf("%s is not on PATH; add %s to PATH", name, filepath.Dir(wrapperPath))
+		return check
+	}
+	if filepath.Clean(found) != wrapperPath {
+		check.Message = fmt.Sprintf("%s resolves to %s; put %s before it on PATH", name, found, filepath.Dir(wrapperPath))
+		return check
+	}
+
+	check.OK = true
+	check.Message = fmt.Sprintf("%s resolves to the devlog wrapper", name)
+	return check
+}
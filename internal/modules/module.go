@@ -1,9 +1,12 @@
 package modules
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 
+	"devlog/internal/events"
 	"devlog/internal/install"
 	"devlog/internal/poller"
 )
@@ -21,6 +24,29 @@ type Pollable interface {
 	CreatePoller(config map[string]interface{}, dataDir string) (poller.Poller, error)
 }
 
+var ErrWebhookUnauthorized = errors.New("webhook signature verification failed")
+
+// ErrPollerDisabled is returned by CreatePoller when a module's polling is
+// optional and switched off in its config.
+var ErrPollerDisabled = errors.New("poller disabled in module config")
+
+type WebhookReceiver interface {
+	HandleWebhook(headers http.Header, body []byte, config map[string]interface{}) ([]*events.Event, error)
+}
+
+// Check is one doctor finding about a module's installed hooks or wrappers.
+type Check struct {
+	Name    string
+	OK      bool
+	Message string
+}
+
+// Doctor is implemented by modules that install integrations the asset
+// manifest cannot see on its own, such as lines in shell rc files.
+type Doctor interface {
+	Check(ctx *install.Context) []Check
+}
+
 type ModuleWithPoller interface {
 	Module
 	Pollable
@@ -2,9 +2,12 @@ package output
 
 import (
 	"fmt"
+	"strings"
+	"time"
 	"unicode/utf8"
 
 	"devlog/internal/events"
+	"devlog/internal/storage"
 )
 
 func Truncate(s string, maxLen int) string {
@@ -27,6 +30,23 @@ func Truncate(s string, maxLen int) string {
 	return s + "..."
 }
 
+// TruncateStart is Truncate for text whose end matters most, such as
+// command output: it drops the beginning instead.
+func TruncateStart(s string, maxLen int) string {
+	if s == "" || maxLen <= 0 {
+		return ""
+	}
+	if maxLen <= 3 {
+		return "..."
+	}
+
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	return "..." + string(runes[len(runes)-(maxLen-3):])
+}
+
 func ExtractContent(evt *events.Event, maxLen int) string {
 	payload := evt.Payload
 
@@ -67,8 +87,12 @@ func ExtractContent(evt *events.Event, maxLen int) string {
 			return source
 		}
 	case "note":
-		if note, ok := payload["note"].(string); ok {
-			return Truncate(note, maxLen)
+		if text, ok := payload["text"].(string); ok {
+			return Truncate(text, maxLen)
+		}
+	case "insight":
+		if msg, ok := payload["message"].(string); ok {
+			return Truncate(msg, maxLen)
 		}
 	case "file_edit":
 		if file, ok := payload["file"].(string); ok {
@@ -105,3 +129,28 @@ func FormatEventLine(evt *events.Event, maxSummaryLen int, maxMessageLen int, ma
 
 	return line
 }
+
+// FormatAnnotations renders an event's annotations one per line, each
+// starting with indent.
+func FormatAnnotations(annotations []storage.Annotation, indent string) string {
+	var sb strings.Builder
+	for _, a := range annotations {
+		sb.WriteString(fmt.Sprintf("%s✎ %s (%s)\n", indent, a.Text, a.CreatedAt.Format("2006-01-02")))
+	}
+	return sb.String()
+}
+
+func FormatSummaryLine(summary *storage.Summary, maxTextLen int) string {
+	line := fmt.Sprintf("\n[%s - %s] summary",
+		summary.PeriodStart.Format(time.RFC3339), summary.PeriodEnd.Format(time.RFC3339))
+
+	if len(summary.Repos) > 0 {
+		line += fmt.Sprintf(" repos=%s", strings.Join(summary.Repos, ","))
+	}
+
+	if text := strings.Join(strings.Fields(summary.Text), " "); text != "" {
+		line += fmt.Sprintf(": %s", Truncate(text, maxTextLen))
+	}
+
+	return line
+}
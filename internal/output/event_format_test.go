@@ -34,6 +34,31 @@ func TestTruncate(t *testing.T) {
 	}
 }
 
+func TestTruncateStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"empty string", "", 10, ""},
+		{"exact length", "hello", 5, "hello"},
+		{"needs truncation", "build failed: exit 2", 11, "...: exit 2"},
+		{"multibyte", "ошибка сборки", 9, "...сборки"},
+		{"max length 3", "hello", 3, "..."},
+		{"zero max length", "hello", 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateStart(tt.input, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("TruncateStart(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractContent(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,165 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+// SearchFields are the fields that can be selected for search output.
+// payload.KEY selects a single payload field.
+var SearchFields = []string{"id", "timestamp", "kind", "source", "type", "repo", "branch", "content", "payload", "annotations", "rank"}
+
+// DefaultSearchFields are used by the jsonl and md formats and by table
+// output when fields are selected without naming any.
+var DefaultSearchFields = []string{"timestamp", "kind", "source", "type", "repo", "branch", "content", "id"}
+
+// unlimited disables truncation in the Truncate-based helpers.
+const unlimited = 1 << 30
+
+// ParseFields splits a comma-separated field list and checks every name.
+func ParseFields(s string) ([]string, error) {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !validField(f) {
+			return nil, fmt.Errorf("unknown field %q (must be one of %s, or payload.KEY)", f, strings.Join(SearchFields, ", "))
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func validField(f string) bool {
+	if key, ok := strings.CutPrefix(f, "payload."); ok {
+		return key != ""
+	}
+	for _, known := range SearchFields {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldValue returns one field of a result. Content longer than maxLen is
+// truncated.
+func fieldValue(result *storage.SearchResult, field string, maxLen int) interface{} {
+	if s := result.Summary; s != nil {
+		switch field {
+		case "id":
+			return s.ID
+		case "timestamp":
+			return s.PeriodStart.Format(time.RFC3339)
+		case "kind":
+			return "summary"
+		case "repo":
+			return strings.Join(s.Repos, ",")
+		case "content":
+			return Truncate(strings.Join(strings.Fields(s.Text), " "), maxLen)
+		case "rank":
+			return result.Rank
+		}
+		return nil
+	}
+
+	e := result.Event
+	if e == nil {
+		return nil
+	}
+	switch field {
+	case "id":
+		return e.ID
+	case "timestamp":
+		return e.Timestamp
+	case "kind":
+		return "event"
+	case "source":
+		return e.Source
+	case "type":
+		return e.Type
+	case "repo":
+		return e.Repo
+	case "branch":
+		return e.Branch
+	case "content":
+		return ExtractContent(e, maxLen)
+	case "payload":
+		return e.Payload
+	case "annotations":
+		texts := make([]string, len(result.Annotations))
+		for i, a := range result.Annotations {
+			texts[i] = a.Text
+		}
+		return texts
+	case "rank":
+		return result.Rank
+	}
+	if key, ok := strings.CutPrefix(field, "payload."); ok {
+		return e.Payload[key]
+	}
+	return nil
+}
+
+// fieldString renders a field value on one line for text output.
+func fieldString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.Join(strings.Fields(v), " ")
+	case []string:
+		return strings.Join(v, "; ")
+	case float64:
+		if v == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.3f", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// record is a result's selected fields, marshaled to JSON in field order.
+type record struct {
+	fields []string
+	values []interface{}
+}
+
+func newRecord(result *storage.SearchResult, fields []string) record {
+	r := record{fields: fields, values: make([]interface{}, len(fields))}
+	for i, f := range fields {
+		r.values[i] = fieldValue(result, f, unlimited)
+	}
+	return r
+}
+
+func (r record) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+)
+
+func fieldResults() []*storage.SearchResult {
+	commit := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	commit.ID = "0123456789abcdef"
+	commit.Timestamp = "2026-03-02T09:00:00Z"
+	commit.Repo = "devlog"
+	commit.Branch = "main"
+	commit.Payload["message"] = "fix | pipe and a long message " + strings.Repeat("x", 200)
+	commit.Payload["issue"] = "PROJ-1"
+	return []*storage.SearchResult{{Event: commit}}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields("timestamp, repo,payload.issue")
+	if err != nil || strings.Join(fields, ",") != "timestamp,repo,payload.issue" {
+		t.Errorf("ParseFields() = %v, %v", fields, err)
+	}
+	if _, err := ParseFields("timestamp,bogus"); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+	if fields, _ := ParseFields(""); len(fields) != 0 {
+		t.Errorf("ParseFields(\"\") = %v, want none", fields)
+	}
+}
+
+func TestPresentJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	opts := PresentOptions{Fields: []string{"repo", "payload.issue", "content"}}
+	if err := NewSearchPresenterWithOptions(&buf, FormatJSONL, opts).Present(context.Background(), fieldResults(), "fix"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], `{"repo":"devlog","payload.issue":"PROJ-1","content":"fix`) {
+		t.Fatalf("jsonl output = %q", buf.String())
+	}
+	var rec map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatal(err)
+	}
+	if content := rec["content"].(string); strings.HasSuffix(content, "...") {
+		t.Error("jsonl content should not be truncated")
+	}
+}
+
+func TestPresentJSONFields(t *testing.T) {
+	var buf bytes.Buffer
+	opts := PresentOptions{Fields: []string{"id", "type"}}
+	if err := NewSearchPresenterWithOptions(&buf, FormatJSON, opts).Present(context.Background(), fieldResults(), "fix"); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Results []map[string]string `json:"results"`
+		Count   int                 `json:"count"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Count != 1 || len(out.Results[0]) != 2 || out.Results[0]["id"] != "0123456789abcdef" {
+		t.Errorf("json output = %+v", out)
+	}
+}
+
+func TestPresentMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewSearchPresenterWithOptions(&buf, FormatMarkdown, PresentOptions{}).Present(context.Background(), fieldResults(), "fix"); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "| timestamp | source | type | repo | content |" || lines[1] != "| --- | --- | --- | --- | --- |" {
+		t.Fatalf("markdown output = %q", buf.String())
+	}
+	if !strings.Contains(lines[2], `fix \| pipe`) || !strings.HasSuffix(lines[2], "... |") {
+		t.Errorf("row = %q, want escaped pipe and truncated content", lines[2])
+	}
+}
+
+func TestPresentTableOptions(t *testing.T) {
+	var buf bytes.Buffer
+	opts := PresentOptions{Fields: []string{"timestamp", "repo"}}
+	if err := NewSearchPresenterWithOptions(&buf, FormatTable, opts).Present(context.Background(), fieldResults(), "fix"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "TIMESTAMP             REPO\n2026-03-02T09:00:00Z  devlog\n" {
+		t.Errorf("table columns = %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewSearchPresenterWithOptions(&buf, FormatTable, PresentOptions{NoTruncate: true}).Present(context.Background(), fieldResults(), "fix"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "0123456789abcdef") || strings.Contains(buf.String(), "...") {
+		t.Errorf("--no-truncate table = %q", buf.String())
+	}
+}
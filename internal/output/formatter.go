@@ -2,6 +2,7 @@ package output
 
 import (
 	"context"
+	"io"
 
 	"devlog/internal/storage"
 )
@@ -9,3 +10,9 @@ import (
 type ResultFormatter interface {
 	Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error)
 }
+
+// StreamingFormatter is implemented by formatters that can write their
+// output while it is still being generated, such as LLM answers.
+type StreamingFormatter interface {
+	FormatStream(ctx context.Context, results []*storage.SearchResult, query string, w io.Writer) (string, error)
+}
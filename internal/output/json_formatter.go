@@ -8,22 +8,31 @@ import (
 	"devlog/internal/storage"
 )
 
-type jsonFormatter struct{}
+type jsonFormatter struct {
+	fields []string
+}
 
 func NewJSONFormatter() ResultFormatter {
 	return jsonFormatter{}
 }
 
-func (jsonFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
+func (f jsonFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
 	type output struct {
-		Results []*storage.SearchResult `json:"results"`
-		Count   int                     `json:"count"`
+		Results interface{} `json:"results"`
+		Count   int         `json:"count"`
 	}
 
 	data := output{
 		Results: results,
 		Count:   len(results),
 	}
+	if len(f.fields) > 0 {
+		records := make([]record, len(results))
+		for i, result := range results {
+			records[i] = newRecord(result, f.fields)
+		}
+		data.Results = records
+	}
 
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
@@ -34,3 +43,29 @@ func (jsonFormatter) Format(ctx context.Context, results []*storage.SearchResult
 
 	return buf.String(), nil
 }
+
+// jsonlFormatter writes one JSON object per result and nothing else, for
+// jq and line-oriented tools.
+type jsonlFormatter struct {
+	fields []string
+}
+
+func NewJSONLFormatter() ResultFormatter {
+	return jsonlFormatter{}
+}
+
+func (f jsonlFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
+	fields := f.fields
+	if len(fields) == 0 {
+		fields = DefaultSearchFields
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, result := range results {
+		if err := encoder.Encode(newRecord(result, fields)); err != nil {
+			return "", err
+		}
+	}
+	return buf.String(), nil
+}
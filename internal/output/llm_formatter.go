@@ -3,12 +3,24 @@ package output
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
+	"text/template"
 
 	"devlog/internal/events"
+	"devlog/internal/llm"
+	"devlog/internal/prompts"
 	"devlog/internal/storage"
 )
 
+var defaultAnswerPrompt = template.Must(prompts.Load("query", prompts.Concise))
+
+type answerPromptData struct {
+	ResponseGoal string
+	FenceNotice  string
+	Events       string
+}
+
 type LLMClient interface {
 	Complete(ctx context.Context, prompt string) (string, error)
 }
@@ -16,12 +28,22 @@ type LLMClient interface {
 type LLMFormatter struct {
 	llmClient    LLMClient
 	responseGoal string
+	template     *template.Template
 }
 
-func NewLLMFormatter(llmClient LLMClient, responseGoal string) ResultFormatter {
+func NewLLMFormatter(llmClient LLMClient, responseGoal string) *LLMFormatter {
 	return &LLMFormatter{
 		llmClient:    llmClient,
 		responseGoal: responseGoal,
+		template:     defaultAnswerPrompt,
+	}
+}
+
+// SetPrompt replaces the built-in concise answer prompt. A nil template
+// keeps the current one.
+func (f *LLMFormatter) SetPrompt(tmpl *template.Template) {
+	if tmpl != nil {
+		f.template = tmpl
 	}
 }
 
@@ -30,40 +52,63 @@ func (f *LLMFormatter) Format(ctx context.Context, results []*storage.SearchResu
 		return "No events found matching your query.", nil
 	}
 
-	events := make([]*events.Event, len(results))
-	for i, r := range results {
-		events[i] = r.Event
+	prompt, err := f.prompt(results)
+	if err != nil {
+		return "", err
 	}
 
-	eventsBySource := groupEventsBySource(events)
-
-	prompt := fmt.Sprintf(`You are summarizing development activity for a user based on actual logged events.
-
-User's question goal: %s
+	answer, err := f.llmClient.Complete(ctx, prompt)
+	if err != nil {
+		return "", fmt.Errorf("format response: %w", err)
+	}
 
-Events found:
- %s
+	return strings.TrimSpace(answer), nil
+}
 
-Instructions:
-- Provide a CONCISE, narrative summary in 1-3 paragraphs maximum
-- Address the user directly using "you" when appropriate.
-- Do NOT list individual events separately
-- Synthesize related events into coherent narratives (e.g., "worked on authentication" instead of listing individual file edits)
-- Include key details: repos, branches, significant commits, important commands
-- Use past tense for completed actions
-- Prioritize CRITICAL and HIGH priority events but mention other significant activity
-- If there are many similar events (e.g., clipboard commands), consolidate them into a single sentence.
-- Focus on what was accomplished, not individual timestamps
-- Remember: the user is asking about THEIR OWN activity, so use second person ("you") not third person
+// FormatStream writes the answer to w as the LLM generates it and returns
+// the complete answer.
+func (f *LLMFormatter) FormatStream(ctx context.Context, results []*storage.SearchResult, query string, w io.Writer) (string, error) {
+	if len(results) == 0 {
+		answer := "No events found matching your query."
+		fmt.Fprintln(w, answer)
+		return answer, nil
+	}
 
-Generate a concise narrative summary now.`, f.responseGoal, formattedBySource(eventsBySource))
+	prompt, err := f.prompt(results)
+	if err != nil {
+		return "", err
+	}
 
-	answer, err := f.llmClient.Complete(ctx, prompt)
+	completion, err := llm.StreamTo(ctx, f.llmClient, prompt, w)
 	if err != nil {
 		return "", fmt.Errorf("format response: %w", err)
 	}
+	fmt.Fprintln(w)
 
-	return strings.TrimSpace(answer), nil
+	return strings.TrimSpace(completion.Text), nil
+}
+
+func (f *LLMFormatter) prompt(results []*storage.SearchResult) (string, error) {
+	events := make([]*events.Event, 0, len(results))
+	for _, r := range results {
+		if r.Event != nil {
+			events = append(events, r.Event)
+		}
+	}
+
+	eventsBySource := groupEventsBySource(events)
+	fence := llm.NewFence("EVENTS")
+
+	var buf strings.Builder
+	err := f.template.Execute(&buf, answerPromptData{
+		ResponseGoal: f.responseGoal,
+		FenceNotice:  llm.FenceNotice(fence),
+		Events:       fence.Wrap(formattedBySource(eventsBySource)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("render prompt: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
 }
 
 func groupEventsBySource(evts []*events.Event) map[string][]*events.Event {
@@ -83,13 +128,20 @@ func formattedBySource(eventsBySource map[string][]*events.Event) string {
 	}{
 		{"claude", "CRITICAL"},
 		{"github", "HIGH"},
+		{"gitlab", "HIGH"},
+		{"bitbucket", "HIGH"},
+		{"patterns", "HIGH"},
 		{"git", "MEDIUM"},
 		{"kubectl", "MEDIUM"},
+		{"terraform", "MEDIUM"},
+		{"tests", "MEDIUM"},
+		{"ssh", "MEDIUM"},
 		{"shell", "LOW"},
 		{"clipboard", "LOW"},
 		{"tmux", "LOW"},
 		{"wisprflow", "LOW"},
 		{"manual", "MEDIUM"},
+		{"activity", "PRESENCE"},
 	}
 
 	for _, s := range sources {
@@ -107,6 +159,16 @@ func formattedBySource(eventsBySource map[string][]*events.Event) string {
 	return sb.String()
 }
 
+// maxOutputChars is how much of a failed command's captured output goes
+// into an answer prompt.
+const maxOutputChars = 800
+
 func formatEventForLLM(evt *events.Event) string {
-	return FormatEventLine(evt, 500, 500, 300, 200)
+	line := llm.SanitizeUntrusted(FormatEventLine(evt, 500, 500, 300, 200), llm.DefaultMaxEventChars)
+	// Captured output holds the error a question about a failed command is
+	// after, and errors come last, so the tail is kept.
+	if out, ok := evt.Payload["output"].(string); ok && out != "" {
+		line += "\n  output: " + llm.SanitizeUntrusted(TruncateStart(out, maxOutputChars), maxOutputChars)
+	}
+	return line
 }
@@ -0,0 +1,48 @@
+package output
+
+import (
+	"context"
+	"strings"
+
+	"devlog/internal/storage"
+)
+
+var markdownFields = []string{"timestamp", "source", "type", "repo", "content"}
+
+// markdownFormatter renders results as a Markdown table, for pasting into
+// notes, issues and pull requests.
+type markdownFormatter struct {
+	fields     []string
+	noTruncate bool
+}
+
+func NewMarkdownFormatter() ResultFormatter {
+	return markdownFormatter{}
+}
+
+func (f markdownFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
+	fields := f.fields
+	if len(fields) == 0 {
+		fields = markdownFields
+	}
+	maxLen := 120
+	if f.noTruncate {
+		maxLen = unlimited
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(fields, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(fields)) + "\n")
+	for _, result := range results {
+		cells := make([]string, len(fields))
+		for i, field := range fields {
+			cells[i] = markdownCell(fieldString(fieldValue(result, field, maxLen)))
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return sb.String(), nil
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
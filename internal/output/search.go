@@ -11,25 +11,45 @@ import (
 type OutputFormat string
 
 const (
-	FormatTable  OutputFormat = "table"
-	FormatJSON   OutputFormat = "json"
-	FormatSimple OutputFormat = "simple"
+	FormatTable    OutputFormat = "table"
+	FormatJSON     OutputFormat = "json"
+	FormatJSONL    OutputFormat = "jsonl"
+	FormatMarkdown OutputFormat = "md"
+	FormatSimple   OutputFormat = "simple"
 )
 
+// PresentOptions adjust the built-in formats.
+type PresentOptions struct {
+	// Fields selects and orders the fields shown for each result. Empty
+	// keeps each format's default layout.
+	Fields []string
+	// NoTruncate prints content in full in the text formats. The json and
+	// jsonl formats never truncate.
+	NoTruncate bool
+}
+
 type SearchPresenter struct {
 	writer    io.Writer
 	formatter ResultFormatter
 }
 
 func NewSearchPresenter(writer io.Writer, format OutputFormat) *SearchPresenter {
+	return NewSearchPresenterWithOptions(writer, format, PresentOptions{})
+}
+
+func NewSearchPresenterWithOptions(writer io.Writer, format OutputFormat, opts PresentOptions) *SearchPresenter {
 	var formatter ResultFormatter
 	switch format {
 	case FormatJSON:
-		formatter = NewJSONFormatter()
+		formatter = jsonFormatter{fields: opts.Fields}
+	case FormatJSONL:
+		formatter = jsonlFormatter{fields: opts.Fields}
+	case FormatMarkdown:
+		formatter = markdownFormatter{fields: opts.Fields, noTruncate: opts.NoTruncate}
 	case FormatSimple:
-		formatter = NewSimpleFormatter()
+		formatter = simpleFormatter{noTruncate: opts.NoTruncate}
 	default:
-		formatter = NewTableFormatter()
+		formatter = tableFormatter{fields: opts.Fields, noTruncate: opts.NoTruncate}
 	}
 
 	return &SearchPresenter{
@@ -46,6 +66,11 @@ func NewSearchPresenterWithFormatter(writer io.Writer, formatter ResultFormatter
 }
 
 func (p *SearchPresenter) Present(ctx context.Context, results []*storage.SearchResult, query string) error {
+	if streaming, ok := p.formatter.(StreamingFormatter); ok {
+		_, err := streaming.FormatStream(ctx, results, query, p.writer)
+		return err
+	}
+
 	output, err := p.formatter.Format(ctx, results, query)
 	if err != nil {
 		return err
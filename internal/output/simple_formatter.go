@@ -8,19 +8,32 @@ import (
 	"devlog/internal/storage"
 )
 
-type simpleFormatter struct{}
+type simpleFormatter struct {
+	noTruncate bool
+}
 
 func NewSimpleFormatter() ResultFormatter {
 	return simpleFormatter{}
 }
 
-func (simpleFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
+func (f simpleFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
+	summaryLen, eventSummaryLen, messageLen, textLen := 300, 200, 300, 100
+	if f.noTruncate {
+		summaryLen, eventSummaryLen, messageLen, textLen = unlimited, unlimited, unlimited, unlimited
+	}
+
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("Found %d events:\n\n", len(results)))
+	sb.WriteString(fmt.Sprintf("Found %d results:\n\n", len(results)))
 
 	for _, result := range results {
-		sb.WriteString(FormatEventLine(result.Event, 200, 300, 300, 100))
+		if result.Summary != nil {
+			sb.WriteString(FormatSummaryLine(result.Summary, summaryLen))
+			sb.WriteString("\n")
+			continue
+		}
+		sb.WriteString(FormatEventLine(result.Event, eventSummaryLen, messageLen, messageLen, textLen))
 		sb.WriteString("\n")
+		sb.WriteString(FormatAnnotations(result.Annotations, "  "))
 	}
 
 	return sb.String(), nil
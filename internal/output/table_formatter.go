@@ -4,40 +4,94 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"devlog/internal/storage"
 )
 
-type tableFormatter struct{}
+type tableFormatter struct {
+	fields     []string
+	noTruncate bool
+}
 
 func NewTableFormatter() ResultFormatter {
 	return tableFormatter{}
 }
 
-func (tableFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
+func (f tableFormatter) Format(ctx context.Context, results []*storage.SearchResult, query string) (string, error) {
 	var sb strings.Builder
 
 	if len(results) == 0 {
-		return fmt.Sprintf("No events found matching '%s'\n", query), nil
+		return fmt.Sprintf("No results found matching '%s'\n", query), nil
+	}
+
+	if len(f.fields) > 0 {
+		return f.columns(results), nil
+	}
+
+	summaryLen, contentLen := 200, 100
+	if f.noTruncate {
+		summaryLen, contentLen = unlimited, unlimited
 	}
 
-	sb.WriteString(fmt.Sprintf("Found %d event(s) matching '%s':\n\n", len(results), query))
+	sb.WriteString(fmt.Sprintf("Found %d result(s) matching '%s':\n\n", len(results), query))
 
 	for _, result := range results {
+		if result.Summary != nil {
+			sb.WriteString(fmt.Sprintf("%s summary [%s - %s]\n",
+				result.Summary.PeriodStart.Format(time.RFC3339),
+				result.Summary.PeriodStart.Format("15:04"),
+				result.Summary.PeriodEnd.Format("15:04"),
+			))
+			if len(result.Summary.Repos) > 0 {
+				sb.WriteString(fmt.Sprintf("  repos: %s\n", strings.Join(result.Summary.Repos, ", ")))
+			}
+			sb.WriteString(fmt.Sprintf("  %s\n\n", Truncate(strings.Join(strings.Fields(result.Summary.Text), " "), summaryLen)))
+			continue
+		}
+
+		id := result.Event.ID
+		if !f.noTruncate {
+			id = id[:8]
+		}
 		sb.WriteString(fmt.Sprintf("%s %s [%s:%s]\n",
 			result.Event.Timestamp,
-			result.Event.ID[:8],
+			id,
 			result.Event.Source,
 			result.Event.Type,
 		))
 
-		content := ExtractContent(result.Event, 100)
+		content := ExtractContent(result.Event, contentLen)
 		if content != "" {
 			sb.WriteString(fmt.Sprintf("  %s\n", content))
 		}
+		sb.WriteString(FormatAnnotations(result.Annotations, "  "))
 
 		sb.WriteString("\n")
 	}
 
 	return sb.String(), nil
 }
+
+// columns prints the selected fields as aligned columns under a header,
+// one result per line.
+func (f tableFormatter) columns(results []*storage.SearchResult) string {
+	maxLen := 80
+	if f.noTruncate {
+		maxLen = unlimited
+	}
+
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(f.fields, "\t")))
+	for _, result := range results {
+		cells := make([]string, len(f.fields))
+		for i, field := range f.fields {
+			cells[i] = fieldString(fieldValue(result, field, maxLen))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+	return sb.String()
+}
@@ -0,0 +1,135 @@
+package pause
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const fileName = "paused.json"
+
+type State struct {
+	Paused bool       `json:"paused"`
+	Since  time.Time  `json:"since,omitempty"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+func (s State) ActiveAt(t time.Time) bool {
+	if !s.Paused {
+		return false
+	}
+	return s.Until == nil || t.Before(*s.Until)
+}
+
+// Controller holds the do-not-track state. It is backed by a file in the
+// data dir so hooks can check it without the daemon, and reloads that file
+// whenever it changes on disk.
+type Controller struct {
+	path    string
+	mu      sync.Mutex
+	state   State
+	modTime time.Time
+	now     func() time.Time
+}
+
+func NewController(dataDir string) *Controller {
+	return &Controller{
+		path: filepath.Join(dataDir, fileName),
+		now:  time.Now,
+	}
+}
+
+func (c *Controller) State() State {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reload()
+	if !c.state.ActiveAt(c.now()) {
+		return State{}
+	}
+	return c.state
+}
+
+func (c *Controller) Active() bool {
+	return c.State().Paused
+}
+
+func (c *Controller) Pause(d time.Duration) (State, error) {
+	if d < 0 {
+		return State{}, fmt.Errorf("pause duration must not be negative")
+	}
+
+	now := c.now()
+	state := State{Paused: true, Since: now.UTC()}
+	if d > 0 {
+		until := now.Add(d).UTC()
+		state.Until = &until
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.write(state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+func (c *Controller) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Remove(c.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove pause state: %w", err)
+	}
+	c.state = State{}
+	c.modTime = time.Time{}
+	return nil
+}
+
+func (c *Controller) reload() {
+	info, err := os.Stat(c.path)
+	if err != nil {
+		c.state = State{}
+		c.modTime = time.Time{}
+		return
+	}
+	if info.ModTime().Equal(c.modTime) {
+		return
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return
+	}
+	c.state = state
+	c.modTime = info.ModTime()
+}
+
+func (c *Controller) write(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal pause state: %w", err)
+	}
+
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("write pause state: %w", err)
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("write pause state: %w", err)
+	}
+
+	c.state = state
+	if info, err := os.Stat(c.path); err == nil {
+		c.modTime = info.ModTime()
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package pause
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseAndResume(t *testing.T) {
+	dir := t.TempDir()
+	c := NewController(dir)
+
+	if c.Active() {
+		t.Fatal("new controller should not be paused")
+	}
+
+	state, err := c.Pause(0)
+	if err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+	if !state.Paused || state.Until != nil {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	if !c.Active() {
+		t.Error("expected controller to be paused")
+	}
+
+	other := NewController(dir)
+	if !other.Active() {
+		t.Error("pause state should be visible to other controllers on the same data dir")
+	}
+
+	if err := other.Resume(); err != nil {
+		t.Fatalf("Resume() error: %v", err)
+	}
+	if c.Active() {
+		t.Error("resume from another controller should be picked up")
+	}
+	if err := c.Resume(); err != nil {
+		t.Errorf("Resume() when not paused should succeed, got %v", err)
+	}
+}
+
+func TestPauseExpires(t *testing.T) {
+	c := NewController(t.TempDir())
+	now := time.Date(2025, 5, 20, 10, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	state, err := c.Pause(2 * time.Hour)
+	if err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+	if state.Until == nil || !state.Until.Equal(now.Add(2*time.Hour)) {
+		t.Fatalf("unexpected until: %+v", state.Until)
+	}
+	if !c.Active() {
+		t.Error("expected paused before deadline")
+	}
+
+	now = now.Add(2*time.Hour + time.Second)
+	if c.Active() {
+		t.Error("expected pause to expire after deadline")
+	}
+	if c.State().Until != nil {
+		t.Error("expired state should be reported as not paused")
+	}
+}
+
+func TestPauseRejectsNegativeDuration(t *testing.T) {
+	c := NewController(t.TempDir())
+	if _, err := c.Pause(-time.Minute); err == nil {
+		t.Error("expected error for negative duration")
+	}
+}
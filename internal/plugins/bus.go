@@ -0,0 +1,74 @@
+package plugins
+
+import (
+	"sync"
+	"time"
+)
+
+const TopicSummaryGenerated = "summary.generated"
+
+const subscriberBuffer = 16
+
+// SummaryGenerated is published on TopicSummaryGenerated after a summary is
+// stored, so other plugins can react without polling the summaries table.
+type SummaryGenerated struct {
+	ID          int64
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Repos       []string
+	EventCount  int
+}
+
+type Notification struct {
+	Topic   string
+	Time    time.Time
+	Payload interface{}
+}
+
+var (
+	busMu       sync.RWMutex
+	subscribers = make(map[string]map[chan Notification]struct{})
+)
+
+// Subscribe returns a channel receiving notifications published on topic and
+// a function that unsubscribes and closes it. Delivery is best effort: a
+// subscriber that falls more than a few notifications behind misses them.
+func Subscribe(topic string) (<-chan Notification, func()) {
+	ch := make(chan Notification, subscriberBuffer)
+
+	busMu.Lock()
+	if subscribers[topic] == nil {
+		subscribers[topic] = make(map[chan Notification]struct{})
+	}
+	subscribers[topic][ch] = struct{}{}
+	busMu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			busMu.Lock()
+			delete(subscribers[topic], ch)
+			busMu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers payload to every current subscriber of topic without
+// blocking and returns how many received it.
+func Publish(topic string, payload interface{}) int {
+	n := Notification{Topic: topic, Time: time.Now(), Payload: payload}
+
+	busMu.RLock()
+	defer busMu.RUnlock()
+
+	delivered := 0
+	for ch := range subscribers[topic] {
+		select {
+		case ch <- n:
+			delivered++
+		default:
+		}
+	}
+	return delivered
+}
@@ -0,0 +1,61 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPublishSubscribe(t *testing.T) {
+	ch, unsubscribe := Subscribe(TopicSummaryGenerated)
+	other, unsubscribeOther := Subscribe("other.topic")
+	defer unsubscribeOther()
+
+	payload := SummaryGenerated{ID: 42, PeriodStart: time.Now().Add(-time.Hour), PeriodEnd: time.Now()}
+	if n := Publish(TopicSummaryGenerated, payload); n != 1 {
+		t.Fatalf("Publish() delivered to %d subscribers, want 1", n)
+	}
+
+	select {
+	case n := <-ch:
+		got, ok := n.Payload.(SummaryGenerated)
+		if !ok || got.ID != 42 || n.Topic != TopicSummaryGenerated {
+			t.Errorf("got notification %+v", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no notification received")
+	}
+
+	select {
+	case n := <-other:
+		t.Errorf("other topic received %+v", n)
+	default:
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if _, open := <-ch; open {
+		t.Error("channel still open after unsubscribe")
+	}
+	if n := Publish(TopicSummaryGenerated, payload); n != 0 {
+		t.Errorf("Publish() after unsubscribe delivered to %d subscribers", n)
+	}
+}
+
+func TestPublishDoesNotBlockOnSlowSubscriber(t *testing.T) {
+	_, unsubscribe := Subscribe("slow.topic")
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < subscriberBuffer*2; i++ {
+			Publish("slow.topic", i)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full subscriber")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sync"
 
+	"devlog/internal/events"
 	"devlog/internal/install"
 )
 
@@ -22,6 +23,31 @@ type ServiceInjector interface {
 	InjectServices(services map[string]interface{}) error
 }
 
+// EventEnricher is implemented by plugins that add fields to events as they
+// are ingested. EnrichEvent runs on the ingest path after filtering, so it
+// must be quick and must not wait on the network.
+type EventEnricher interface {
+	EnrichEvent(event *events.Event)
+}
+
+// ServiceEventIngest names the daemon's EventIngester in the services
+// passed to InjectServices.
+const ServiceEventIngest = "events.ingest"
+
+// EventIngester stores events through the daemon's ingest pipeline, so
+// events a plugin derives from others are filtered, enriched and indexed
+// like any captured event. Plugins must not call it from EnrichEvent.
+type EventIngester interface {
+	IngestEvent(ctx context.Context, event *events.Event) error
+}
+
+// EventObserver is implemented by plugins that watch the event stream.
+// ObserveEvent runs once for each event after it is stored, so duplicates
+// and filtered events are never seen; like EnrichEvent it must be quick.
+type EventObserver interface {
+	ObserveEvent(event *events.Event)
+}
+
 type Plugin interface {
 	Name() string
 	Description() string
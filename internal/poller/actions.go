@@ -0,0 +1,264 @@
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultActionsAPIURL = "https://api.github.com"
+
+	actionsStateModule = "ci"
+	actionsPageSize    = 50
+	actionsLookback    = 24 * time.Hour
+)
+
+// ActionsConfig configures an ActionsPoller. Repos lists "owner/name" (or
+// "github.com/owner/name") repositories to watch; when it is empty the poller
+// watches every GitHub repo with events in the last ActiveDays days.
+type ActionsConfig struct {
+	Token      string
+	APIURL     string
+	Repos      []string
+	ActiveDays int
+	Interval   time.Duration
+}
+
+// RepoLister returns the repos with devlog events at or after since.
+type RepoLister func(ctx context.Context, since time.Time) ([]string, error)
+
+// CursorStore persists per-repo poll cursors between daemon restarts.
+type CursorStore interface {
+	GetString(module, key string) (string, bool)
+	Set(module, key string, value interface{}) error
+}
+
+// ActionsPoller checks GitHub Actions workflow runs and reports when they
+// start and how they finish, for users who can't or don't want to point a
+// webhook at their machine.
+type ActionsPoller struct {
+	cfg     ActionsConfig
+	repos   RepoLister
+	cursors CursorStore
+	client  *http.Client
+	now     func() time.Time
+}
+
+func NewActionsPoller(cfg ActionsConfig, repos RepoLister, cursors CursorStore) *ActionsPoller {
+	if cfg.APIURL == "" {
+		cfg.APIURL = DefaultActionsAPIURL
+	}
+	return &ActionsPoller{
+		cfg:     cfg,
+		repos:   repos,
+		cursors: cursors,
+		client:  &http.Client{Timeout: 20 * time.Second},
+		now:     time.Now,
+	}
+}
+
+func (p *ActionsPoller) Name() string {
+	return "ci"
+}
+
+func (p *ActionsPoller) PollInterval() time.Duration {
+	return p.cfg.Interval
+}
+
+func (p *ActionsPoller) Poll(ctx context.Context) ([]*events.Event, error) {
+	repos, err := p.watchedRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []*events.Event
+	for _, repo := range repos {
+		evts, err := p.pollRepo(ctx, repo)
+		if err != nil {
+			return all, fmt.Errorf("poll %s: %w", repo, err)
+		}
+		all = append(all, evts...)
+	}
+	return all, nil
+}
+
+func (p *ActionsPoller) watchedRepos(ctx context.Context) ([]string, error) {
+	candidates := p.cfg.Repos
+	explicit := len(candidates) > 0
+	if !explicit && p.repos != nil {
+		since := p.now().AddDate(0, 0, -p.cfg.ActiveDays)
+		active, err := p.repos(ctx, since)
+		if err != nil {
+			return nil, fmt.Errorf("list active repos: %w", err)
+		}
+		candidates = active
+	}
+
+	seen := make(map[string]bool)
+	var repos []string
+	for _, c := range candidates {
+		name, ok := GitHubRepo(c, explicit)
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		repos = append(repos, name)
+	}
+	return repos, nil
+}
+
+// GitHubRepo returns the "owner/name" of a GitHub repo given as
+// "github.com/owner/name" or, when bare is set, as "owner/name". Repos stored
+// under a plain directory name can't be mapped to GitHub and are rejected.
+func GitHubRepo(repo string, bare bool) (string, bool) {
+	repo = strings.TrimSuffix(strings.TrimSpace(repo), ".git")
+	if rest, ok := strings.CutPrefix(repo, "github.com/"); ok {
+		repo = rest
+	} else if !bare {
+		return "", false
+	}
+
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", false
+	}
+	return repo, true
+}
+
+type workflowRun struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	HeadBranch   string `json:"head_branch"`
+	HeadSHA      string `json:"head_sha"`
+	Status       string `json:"status"`
+	Conclusion   string `json:"conclusion"`
+	HTMLURL      string `json:"html_url"`
+	RunNumber    int    `json:"run_number"`
+	RunAttempt   int    `json:"run_attempt"`
+	Event        string `json:"event"`
+	RunStartedAt string `json:"run_started_at"`
+	UpdatedAt    string `json:"updated_at"`
+	Actor        struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// pollRepo reports runs updated since the repo's cursor. A run that is
+// seen both in progress and later completed yields a start and a finish
+// event; event IDs derive from the run, so overlapping polls store each
+// only once.
+func (p *ActionsPoller) pollRepo(ctx context.Context, repo string) ([]*events.Event, error) {
+	key := "runs_since:" + repo
+	since := p.now().Add(-actionsLookback)
+	if s, ok := p.cursors.GetString(actionsStateModule, key); ok {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			since = t
+		}
+	}
+
+	var resp struct {
+		WorkflowRuns []workflowRun `json:"workflow_runs"`
+	}
+	path := fmt.Sprintf("/repos/%s/actions/runs?per_page=%d", repo, actionsPageSize)
+	if err := p.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+
+	latest := since
+	var result []*events.Event
+	for _, run := range resp.WorkflowRuns {
+		updated, err := time.Parse(time.RFC3339, run.UpdatedAt)
+		if err != nil || !updated.After(since) {
+			continue
+		}
+		if updated.After(latest) {
+			latest = updated
+		}
+		if event := convertRun(run, updated); event != nil {
+			result = append(result, event)
+		}
+	}
+
+	if latest.After(since) {
+		if err := p.cursors.Set(actionsStateModule, key, latest.UTC().Format(time.RFC3339)); err != nil {
+			return nil, fmt.Errorf("save cursor: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func convertRun(run workflowRun, updated time.Time) *events.Event {
+	var phase string
+	switch run.Status {
+	case "in_progress":
+		phase = "started"
+	case "completed":
+		phase = "completed"
+	default:
+		return nil
+	}
+
+	event := events.NewEvent(string(events.SourceGitHub), string(events.TypeWorkflowRun))
+	event.ID = uuid.NewSHA1(uuid.NameSpaceURL,
+		[]byte(fmt.Sprintf("github-actions:%s:%d:%d:%s", run.Repository.FullName, run.ID, run.RunAttempt, phase))).String()
+	event.Timestamp = updated.UTC().Format(time.RFC3339)
+	event.Repo = run.Repository.Name
+	event.Branch = run.HeadBranch
+	event.SessionID = fmt.Sprintf("github-actions:%s:%d", run.Repository.FullName, run.ID)
+	event.Payload["action"] = run.Status
+	event.Payload["full_name"] = run.Repository.FullName
+	event.Payload["actor"] = run.Actor.Login
+	event.Payload["title"] = run.Name
+	event.Payload["workflow"] = run.Name
+	event.Payload["run_number"] = run.RunNumber
+	event.Payload["head_sha"] = run.HeadSHA
+	event.Payload["trigger"] = run.Event
+	event.Payload["url"] = run.HTMLURL
+
+	if phase == "started" {
+		return event
+	}
+
+	event.Payload["conclusion"] = run.Conclusion
+	if started, err := time.Parse(time.RFC3339, run.RunStartedAt); err == nil && updated.After(started) {
+		event.DurationMs = updated.Sub(started).Milliseconds()
+	}
+	switch run.Conclusion {
+	case "failure", "timed_out", "startup_failure":
+		event.Severity = string(events.SeverityError)
+	}
+	return event
+}
+
+func (p *ActionsPoller) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(p.cfg.APIURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.cfg.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
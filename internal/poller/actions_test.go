@@ -0,0 +1,130 @@
+package poller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type memCursors map[string]string
+
+func (m memCursors) GetString(module, key string) (string, bool) {
+	v, ok := m[module+"/"+key]
+	return v, ok
+}
+
+func (m memCursors) Set(module, key string, value interface{}) error {
+	m[module+"/"+key] = value.(string)
+	return nil
+}
+
+const actionsRunsJSON = `{"workflow_runs": [
+  {"id": 3, "name": "CI", "head_branch": "main", "status": "queued", "run_attempt": 1,
+   "updated_at": "2026-10-16T11:59:00Z", "repository": {"name": "devlog", "full_name": "me/devlog"}},
+  {"id": 2, "name": "CI", "head_branch": "fix", "status": "in_progress", "run_attempt": 1,
+   "updated_at": "2026-10-16T11:50:00Z", "repository": {"name": "devlog", "full_name": "me/devlog"}},
+  {"id": 1, "name": "CI", "head_branch": "main", "status": "completed", "conclusion": "failure",
+   "run_number": 41, "run_attempt": 1, "run_started_at": "2026-10-16T11:30:00Z",
+   "updated_at": "2026-10-16T11:40:00Z", "repository": {"name": "devlog", "full_name": "me/devlog"}},
+  {"id": 0, "name": "CI", "status": "completed", "conclusion": "success", "run_attempt": 1,
+   "updated_at": "2026-10-14T09:00:00Z", "repository": {"name": "devlog", "full_name": "me/devlog"}}
+]}`
+
+func TestActionsPollerPoll(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(actionsRunsJSON))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	lister := func(ctx context.Context, since time.Time) ([]string, error) {
+		if want := now.AddDate(0, 0, -7); !since.Equal(want) {
+			t.Errorf("since = %v, want %v", since, want)
+		}
+		return []string{"github.com/me/devlog", "scratch", "gitlab.com/me/other"}, nil
+	}
+	cursors := memCursors{}
+	p := NewActionsPoller(ActionsConfig{Token: "tok", APIURL: srv.URL, ActiveDays: 7}, lister, cursors)
+	p.now = func() time.Time { return now }
+
+	evts, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "/repos/me/devlog/actions/runs" {
+		t.Errorf("requested %v, want only me/devlog runs", paths)
+	}
+	if len(evts) != 2 {
+		t.Fatalf("got %d events, want started and failed runs", len(evts))
+	}
+
+	started, failed := evts[0], evts[1]
+	if started.Payload["action"] != "in_progress" || started.Branch != "fix" {
+		t.Errorf("started event = %+v", started)
+	}
+	if failed.Payload["conclusion"] != "failure" || failed.Severity != "error" {
+		t.Errorf("failed event = %+v", failed)
+	}
+	if failed.DurationMs != (10 * time.Minute).Milliseconds() {
+		t.Errorf("DurationMs = %d, want 10m", failed.DurationMs)
+	}
+	if failed.Repo != "devlog" || failed.Payload["full_name"] != "me/devlog" {
+		t.Errorf("repo = %q, full_name = %v", failed.Repo, failed.Payload["full_name"])
+	}
+	if got := cursors["ci/runs_since:me/devlog"]; got != "2026-10-16T11:59:00Z" {
+		t.Errorf("cursor = %q", got)
+	}
+
+	again, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("second Poll() error: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("second poll returned %d events, want none", len(again))
+	}
+}
+
+func TestActionsEventIDsAreStable(t *testing.T) {
+	run := workflowRun{ID: 7, Status: "completed", RunAttempt: 1, UpdatedAt: "2026-10-16T11:40:00Z"}
+	run.Repository.FullName = "me/devlog"
+	ts := time.Date(2026, 10, 16, 11, 40, 0, 0, time.UTC)
+
+	a, b := convertRun(run, ts), convertRun(run, ts)
+	if a.ID != b.ID {
+		t.Errorf("IDs differ for the same run: %s, %s", a.ID, b.ID)
+	}
+
+	run.RunAttempt = 2
+	if c := convertRun(run, ts); c.ID == a.ID {
+		t.Error("a re-run attempt reused the first attempt's ID")
+	}
+}
+
+func TestGitHubRepo(t *testing.T) {
+	tests := []struct {
+		in   string
+		bare bool
+		want string
+		ok   bool
+	}{
+		{"github.com/me/devlog", false, "me/devlog", true},
+		{"github.com/me/devlog.git", false, "me/devlog", true},
+		{"me/devlog", false, "", false},
+		{"me/devlog", true, "me/devlog", true},
+		{"devlog", true, "", false},
+		{"gitlab.com/group/sub/project", true, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := GitHubRepo(tt.in, tt.bare)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("GitHubRepo(%q, %v) = %q, %v; want %q, %v", tt.in, tt.bare, got, ok, tt.want, tt.ok)
+		}
+	}
+}
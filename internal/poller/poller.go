@@ -8,6 +8,7 @@ import (
 
 	"devlog/internal/events"
 	"devlog/internal/logger"
+	"devlog/internal/resources"
 )
 
 type Poller interface {
@@ -16,6 +17,17 @@ type Poller interface {
 	Poll(ctx context.Context) ([]*events.Event, error)
 }
 
+// Closer is implemented by pollers that hold resources between polls, such
+// as file watchers. Close is called when the poller stops; the poller must
+// be able to start again afterwards.
+type Closer interface {
+	Close() error
+}
+
+type IgnoreListAware interface {
+	SetIgnoreList(commands []string)
+}
+
 type Manager struct {
 	pollers      map[string]Poller
 	eventService EventService
@@ -24,6 +36,8 @@ type Manager struct {
 	stopOnce     map[string]*sync.Once
 	running      map[string]bool
 	mu           sync.RWMutex
+	wg           sync.WaitGroup
+	fallback     func(event *events.Event, err error)
 }
 
 type EventService interface {
@@ -41,6 +55,15 @@ func NewManager(eventService EventService, log *logger.Logger) *Manager {
 	}
 }
 
+// SetFallback sets a function called for each polled event that could not be
+// ingested. Pollers advance their cursors before returning events, so the
+// fallback is the last chance to keep an event, e.g. by queueing it on disk.
+func (m *Manager) SetFallback(fn func(event *events.Event, err error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback = fn
+}
+
 func (m *Manager) Register(poller Poller) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -96,6 +119,7 @@ func (m *Manager) StartPoller(ctx context.Context, poller Poller) {
 	m.stopChans[name] = stopChan
 	m.stopOnce[name] = &sync.Once{}
 	m.running[name] = true
+	m.wg.Add(1)
 	m.mu.Unlock()
 
 	go m.runPoller(ctx, poller, stopChan, name)
@@ -139,6 +163,21 @@ func (m *Manager) RestartPoller(ctx context.Context, name string) {
 	}
 }
 
+func (m *Manager) NotifyIgnoreList(commands []string) {
+	m.mu.RLock()
+	aware := make([]IgnoreListAware, 0)
+	for _, p := range m.pollers {
+		if a, ok := p.(IgnoreListAware); ok {
+			aware = append(aware, a)
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, a := range aware {
+		a.SetIgnoreList(commands)
+	}
+}
+
 func (m *Manager) Stop() {
 	m.mu.RLock()
 	names := make([]string, 0, len(m.stopChans))
@@ -152,12 +191,41 @@ func (m *Manager) Stop() {
 	}
 }
 
+// Wait blocks until every poller goroutine has returned, including any poll
+// whose events are still being stored, or until timeout. It reports whether
+// all pollers finished.
+func (m *Manager) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 func (m *Manager) runPoller(ctx context.Context, poller Poller, stopChan chan struct{}, name string) {
+	defer m.wg.Done()
+	resources.Label(ctx, resources.Component("poller", name))
 	defer func() {
 		m.mu.Lock()
 		m.running[name] = false
 		m.mu.Unlock()
 	}()
+	if closer, ok := poller.(Closer); ok {
+		defer func() {
+			if err := closer.Close(); err != nil {
+				m.logger.Warn("failed to close poller",
+					slog.String("poller", name),
+					slog.String("error", err.Error()))
+			}
+		}()
+	}
 
 	ticker := time.NewTicker(poller.PollInterval())
 	defer ticker.Stop()
@@ -202,9 +270,22 @@ func (m *Manager) doPoll(ctx context.Context, poller Poller) {
 
 	pollerLogger.Debug("poll completed", slog.Int("event_count", len(events)))
 
+	m.mu.RLock()
+	fallback := m.fallback
+	m.mu.RUnlock()
+
+	// The poller has already saved its cursor, so store the events even if
+	// shutdown cancels ctx in the meantime.
+	ingestCtx := context.WithoutCancel(ctx)
+
 	successCount := 0
 	for _, event := range events {
-		insertCtx, insertCancel := context.WithTimeout(ctx, 5*time.Second)
+		// Pollers are named after their module, whose quota and active
+		// hours apply to the events whatever their source.
+		if event.Module == "" {
+			event.Module = poller.Name()
+		}
+		insertCtx, insertCancel := context.WithTimeout(ingestCtx, 5*time.Second)
 		err := m.eventService.IngestEvent(insertCtx, event)
 		insertCancel()
 
@@ -212,6 +293,9 @@ func (m *Manager) doPoll(ctx context.Context, poller Poller) {
 			pollerLogger.Debug("skipping event",
 				slog.String("event_id", event.ID),
 				slog.String("reason", err.Error()))
+			if fallback != nil {
+				fallback(event, err)
+			}
 			continue
 		}
 		successCount++
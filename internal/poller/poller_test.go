@@ -136,6 +136,34 @@ func TestManagerStartStop(t *testing.T) {
 	}
 }
 
+type closingPoller struct {
+	mockPoller
+	closed chan struct{}
+}
+
+func (c *closingPoller) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func TestManagerClosesPollerOnStop(t *testing.T) {
+	manager := NewManager(&mockEventService{}, logger.Default())
+	poller := &closingPoller{
+		mockPoller: mockPoller{name: "closing", interval: time.Hour},
+		closed:     make(chan struct{}),
+	}
+
+	manager.Register(poller)
+	manager.Start()
+	manager.Stop()
+
+	select {
+	case <-poller.closed:
+	case <-time.After(time.Second):
+		t.Fatal("Close was not called after Stop")
+	}
+}
+
 func TestManagerDoPoll(t *testing.T) {
 	eventService := &mockEventService{}
 	log := logger.Default()
@@ -167,6 +195,12 @@ func TestManagerDoPoll(t *testing.T) {
 	if insertedEvents[1].Payload["id"] != "2" {
 		t.Errorf("Second event has wrong ID: %v", insertedEvents[1].Payload["id"])
 	}
+
+	for _, event := range insertedEvents {
+		if event.Module != "test" {
+			t.Errorf("event module = %q, want the poller's module", event.Module)
+		}
+	}
 }
 
 func TestManagerDoPollWithStorageError(t *testing.T) {
@@ -190,6 +224,78 @@ func TestManagerDoPollWithStorageError(t *testing.T) {
 	}
 }
 
+func TestManagerDoPollFallbackOnStorageError(t *testing.T) {
+	eventService := &mockEventService{shouldError: true}
+	manager := NewManager(eventService, logger.Default())
+
+	var fallbackEvents []*events.Event
+	manager.SetFallback(func(event *events.Event, err error) {
+		fallbackEvents = append(fallbackEvents, event)
+	})
+
+	event := events.NewEvent("test", "type")
+	poller := &mockPoller{
+		name:           "test",
+		interval:       time.Second,
+		eventsToReturn: []*events.Event{event},
+	}
+
+	manager.doPoll(context.Background(), poller)
+
+	if len(fallbackEvents) != 1 || fallbackEvents[0].ID != event.ID {
+		t.Errorf("Expected event to be handed to fallback, got %v", fallbackEvents)
+	}
+}
+
+type cancellingPoller struct {
+	mockPoller
+	cancel context.CancelFunc
+}
+
+func (c *cancellingPoller) Poll(ctx context.Context) ([]*events.Event, error) {
+	evts, err := c.mockPoller.Poll(ctx)
+	c.cancel()
+	return evts, err
+}
+
+func TestManagerDoPollStoresEventsAfterCancel(t *testing.T) {
+	eventService := &mockEventService{}
+	manager := NewManager(eventService, logger.Default())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	poller := &cancellingPoller{
+		mockPoller: mockPoller{
+			name:           "test",
+			interval:       time.Second,
+			eventsToReturn: []*events.Event{events.NewEvent("test", "type")},
+		},
+		cancel: cancel,
+	}
+
+	manager.doPoll(ctx, poller)
+
+	if got := len(eventService.getInsertedEvents()); got != 1 {
+		t.Errorf("Expected events polled before shutdown to be stored, got %d", got)
+	}
+}
+
+func TestManagerWait(t *testing.T) {
+	manager := NewManager(&mockEventService{}, logger.Default())
+
+	if !manager.Wait(10 * time.Millisecond) {
+		t.Error("Wait() with no pollers should return true")
+	}
+
+	poller := &mockPoller{name: "test", interval: time.Hour}
+	manager.Register(poller)
+	manager.Start()
+	manager.Stop()
+
+	if !manager.Wait(time.Second) {
+		t.Error("Wait() should return true once pollers have stopped")
+	}
+}
+
 func TestManagerDoPollWithEmptyEvents(t *testing.T) {
 	eventService := &mockEventService{}
 	log := logger.Default()
@@ -260,3 +366,27 @@ func TestManagerMultiplePollers(t *testing.T) {
 		t.Error("No events from poller2 found")
 	}
 }
+
+type ignoreListPoller struct {
+	mockPoller
+	ignoreList []string
+}
+
+func (p *ignoreListPoller) SetIgnoreList(commands []string) {
+	p.ignoreList = commands
+}
+
+func TestManagerNotifyIgnoreList(t *testing.T) {
+	manager := NewManager(&mockEventService{}, nil)
+
+	aware := &ignoreListPoller{mockPoller: mockPoller{name: "aware", interval: time.Hour}}
+	plain := &mockPoller{name: "plain", interval: time.Hour}
+	manager.Register(aware)
+	manager.Register(plain)
+
+	manager.NotifyIgnoreList([]string{"ls", "cd"})
+
+	if len(aware.ignoreList) != 2 || aware.ignoreList[0] != "ls" {
+		t.Errorf("got ignore list %v, want [ls cd]", aware.ignoreList)
+	}
+}
@@ -0,0 +1,193 @@
+// Package prompts loads the text/template prompts that plugins send to the
+// LLM. Each plugin ships built-in "concise" and "detailed" prompts. A file of
+// the same name under <config dir>/prompts/<plugin>/ overrides a built-in one,
+// and "custom:<path>" selects any template file.
+package prompts
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"devlog/internal/config"
+)
+
+const (
+	Concise  = "concise"
+	Detailed = "detailed"
+
+	customPrefix = "custom:"
+)
+
+//go:embed defaults
+var defaultsFS embed.FS
+
+// versionPattern matches the comment heading every built-in prompt. The
+// version goes up whenever a built-in prompt changes, so exported copies can
+// be recognised as out of date.
+var versionPattern = regexp.MustCompile(`^\{\{/\* devlog default prompt, version (\d+) \*/ -\}\}`)
+
+// Prompt is a built-in prompt template.
+type Prompt struct {
+	Plugin  string
+	Name    string
+	Version int
+	Text    string
+}
+
+// Defaults returns the built-in prompts ordered by plugin and name.
+func Defaults() ([]Prompt, error) {
+	paths, err := fs.Glob(defaultsFS, "defaults/*/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	result := make([]Prompt, 0, len(paths))
+	for _, p := range paths {
+		data, err := defaultsFS.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Prompt{
+			Plugin:  path.Base(path.Dir(p)),
+			Name:    strings.TrimSuffix(path.Base(p), ".tmpl"),
+			Version: Version(string(data)),
+			Text:    string(data),
+		})
+	}
+	return result, nil
+}
+
+// Default returns the built-in prompt with the given name.
+func Default(plugin, name string) (Prompt, error) {
+	defaults, err := Defaults()
+	if err != nil {
+		return Prompt{}, err
+	}
+	for _, p := range defaults {
+		if p.Plugin == plugin && p.Name == name {
+			return p, nil
+		}
+	}
+	return Prompt{}, fmt.Errorf("unknown prompt %q for %s (use %s, %s or custom:<path>)", name, plugin, Concise, Detailed)
+}
+
+// Version returns the built-in prompt version a template was exported from,
+// or 0 when it has no version comment.
+func Version(text string) int {
+	m := versionPattern.FindStringSubmatch(text)
+	if m == nil {
+		return 0
+	}
+	v, _ := strconv.Atoi(m[1])
+	return v
+}
+
+// Dir returns the directory holding exported and overridden prompts.
+func Dir() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prompts"), nil
+}
+
+// OverridePath returns where a named prompt for plugin is overridden.
+func OverridePath(plugin, name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, plugin, name+".tmpl"), nil
+}
+
+// Load parses the prompt template for plugin picked by selection: "concise",
+// "detailed" or "custom:<path>". Relative custom paths are resolved against
+// Dir.
+func Load(plugin, selection string) (*template.Template, error) {
+	if file, ok := strings.CutPrefix(selection, customPrefix); ok {
+		return loadCustom(file)
+	}
+
+	builtin, err := Default(plugin, selection)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := OverridePath(plugin, selection)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := os.ReadFile(override); err == nil {
+		return parse(override, string(data))
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read prompt: %w", err)
+	}
+
+	return parse(plugin+"/"+selection, builtin.Text)
+}
+
+func loadCustom(file string) (*template.Template, error) {
+	if file == "" {
+		return nil, fmt.Errorf("custom prompt needs a path (custom:<path>)")
+	}
+	file = config.ExpandHome(file)
+	if !filepath.IsAbs(file) {
+		dir, err := Dir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(dir, file)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read prompt: %w", err)
+	}
+	return parse(file, string(data))
+}
+
+func parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Export writes the built-in prompts to dir as <plugin>/<name>.tmpl, where
+// they override the built-in ones and can be edited. Existing files are kept
+// unless overwrite is set. It returns the paths written.
+func Export(dir string, overwrite bool) ([]string, error) {
+	defaults, err := Defaults()
+	if err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, p := range defaults {
+		file := filepath.Join(dir, p.Plugin, p.Name+".tmpl")
+		if !overwrite {
+			if _, err := os.Stat(file); err == nil {
+				continue
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return written, fmt.Errorf("create prompt directory: %w", err)
+		}
+		if err := os.WriteFile(file, []byte(p.Text), 0644); err != nil {
+			return written, fmt.Errorf("write prompt: %w", err)
+		}
+		written = append(written, file)
+	}
+	return written, nil
+}
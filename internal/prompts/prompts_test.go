@@ -0,0 +1,101 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, plugin, selection string) string {
+	t.Helper()
+	tmpl, err := Load(plugin, selection)
+	if err != nil {
+		t.Fatalf("Load(%q, %q) error: %v", plugin, selection, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, map[string]string{"ResponseGoal": "GOAL", "FenceNotice": "", "Events": ""}); err != nil {
+		t.Fatalf("Execute() error: %v", err)
+	}
+	return sb.String()
+}
+
+func TestDefaults(t *testing.T) {
+	defaults, err := Defaults()
+	if err != nil {
+		t.Fatalf("Defaults() error: %v", err)
+	}
+
+	found := map[string]bool{}
+	for _, p := range defaults {
+		found[p.Plugin+"/"+p.Name] = true
+		if p.Version < 1 {
+			t.Errorf("%s/%s has no version comment", p.Plugin, p.Name)
+		}
+	}
+	for _, want := range []string{"query/concise", "query/detailed", "summarizer/concise", "summarizer/detailed"} {
+		if !found[want] {
+			t.Errorf("missing built-in prompt %s", want)
+		}
+	}
+}
+
+func TestLoadBuiltinAndOverride(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	out := render(t, "query", Concise)
+	if !strings.Contains(out, "User's question goal: GOAL") {
+		t.Errorf("built-in prompt not rendered:\n%s", out)
+	}
+	if strings.HasPrefix(out, "\n") || strings.Contains(out, "devlog default prompt") {
+		t.Errorf("version comment leaked into the prompt:\n%.80s", out)
+	}
+
+	override, err := OverridePath("query", Concise)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(override), 0755)
+	os.WriteFile(override, []byte("Answer in one line: {{.ResponseGoal}}"), 0644)
+
+	if out := render(t, "query", Concise); out != "Answer in one line: GOAL" {
+		t.Errorf("override not used: %q", out)
+	}
+}
+
+func TestLoadCustom(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir, _ := Dir()
+	os.MkdirAll(dir, 0755)
+	os.WriteFile(filepath.Join(dir, "pirate.tmpl"), []byte("Arr: {{.ResponseGoal}}"), 0644)
+
+	if out := render(t, "query", "custom:pirate.tmpl"); out != "Arr: GOAL" {
+		t.Errorf("custom prompt = %q", out)
+	}
+	if _, err := Load("query", "custom:missing.tmpl"); err == nil {
+		t.Error("missing custom prompt should fail")
+	}
+	if _, err := Load("query", "chatty"); err == nil {
+		t.Error("unknown prompt name should fail")
+	}
+}
+
+func TestExportKeepsEditedFiles(t *testing.T) {
+	dir := t.TempDir()
+	edited := filepath.Join(dir, "summarizer", "concise.tmpl")
+	os.MkdirAll(filepath.Dir(edited), 0755)
+	os.WriteFile(edited, []byte("mine"), 0644)
+
+	written, err := Export(dir, false)
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	for _, w := range written {
+		if w == edited {
+			t.Error("Export overwrote an existing prompt")
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "query", "detailed.tmpl")); Version(string(data)) < 1 {
+		t.Error("exported prompt lost its version comment")
+	}
+}
@@ -0,0 +1,122 @@
+// Package protowire reads and writes the protobuf wire format for the few
+// places devlog speaks protobuf without generated code: reading CPU
+// profiles and serving gRPC ingestion. Only the scalar, string and
+// embedded message encodings are supported; groups are rejected.
+package protowire
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Wire types used by Append functions.
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// EachField calls fn for each field of a message. Varint and fixed-width
+// fields come as value with raw nil; length-delimited fields (strings,
+// bytes, embedded messages and packed repeated fields) come as raw.
+func EachField(data []byte, fn func(field int, value uint64, raw []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errors.New("protobuf: bad field key")
+		}
+		data = data[n:]
+
+		field := int(key >> 3)
+		var (
+			value uint64
+			raw   []byte
+		)
+		switch key & 7 {
+		case 0:
+			value, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errors.New("protobuf: bad varint")
+			}
+			data = data[n:]
+		case 1:
+			if len(data) < 8 {
+				return io.ErrUnexpectedEOF
+			}
+			value = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case 2:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return io.ErrUnexpectedEOF
+			}
+			raw = data[n : n+int(size)]
+			if raw == nil {
+				raw = []byte{}
+			}
+			data = data[n+int(size):]
+		case 5:
+			if len(data) < 4 {
+				return io.ErrUnexpectedEOF
+			}
+			value = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d", key&7)
+		}
+		if err := fn(field, value, raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Varints decodes a packed repeated varint field.
+func Varints(raw []byte) ([]uint64, error) {
+	var values []uint64
+	for len(raw) > 0 {
+		v, n := binary.Uvarint(raw)
+		if n <= 0 {
+			return nil, errors.New("protobuf: bad packed varint")
+		}
+		values = append(values, v)
+		raw = raw[n:]
+	}
+	return values, nil
+}
+
+// AppendVarint appends a varint field (int32, int64, bool, enum). Zero
+// values are skipped, as proto3 does.
+func AppendVarint(b []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+// AppendBool appends a bool field; false is skipped.
+func AppendBool(b []byte, field int, v bool) []byte {
+	if !v {
+		return b
+	}
+	return AppendVarint(b, field, 1)
+}
+
+// AppendBytes appends a length-delimited field: bytes or an encoded
+// message. It is written even when empty, so repeated messages keep
+// their count.
+func AppendBytes(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// AppendString appends a string field; "" is skipped.
+func AppendString(b []byte, field int, v string) []byte {
+	if v == "" {
+		return b
+	}
+	return AppendBytes(b, field, []byte(v))
+}
@@ -0,0 +1,52 @@
+package protowire
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendAndEachField(t *testing.T) {
+	inner := AppendString(nil, 1, "nested")
+	var msg []byte
+	msg = AppendVarint(msg, 1, 300)
+	msg = AppendString(msg, 2, "hello")
+	msg = AppendBool(msg, 3, true)
+	msg = AppendBool(msg, 4, false)
+	msg = AppendBytes(msg, 5, inner)
+	msg = AppendBytes(msg, 5, nil)
+	msg = AppendBytes(msg, 6, []byte{1, 0x96, 0x01})
+
+	type field struct {
+		num   int
+		value uint64
+		raw   string
+	}
+	var got []field
+	err := EachField(msg, func(num int, value uint64, raw []byte) error {
+		got = append(got, field{num, value, string(raw)})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []field{
+		{1, 300, ""},
+		{2, 0, "hello"},
+		{3, 1, ""},
+		{5, 0, string(inner)},
+		{5, 0, ""},
+		{6, 0, "\x01\x96\x01"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+
+	packed, err := Varints([]byte{1, 0x96, 0x01})
+	if err != nil || !reflect.DeepEqual(packed, []uint64{1, 150}) {
+		t.Errorf("Varints() = %v, %v", packed, err)
+	}
+
+	if err := EachField(msg[:len(msg)-1], func(int, uint64, []byte) error { return nil }); err == nil {
+		t.Error("EachField() accepted a truncated message")
+	}
+}
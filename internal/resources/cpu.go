@@ -0,0 +1,141 @@
+package resources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"time"
+
+	"devlog/internal/protowire"
+)
+
+// ErrProfiling is returned by SampleCPU when another CPU profile is already
+// running, such as one started by `go test -cpuprofile`.
+var ErrProfiling = errors.New("a CPU profile is already running")
+
+// SampleCPU runs the CPU profiler for window and returns the CPU time each
+// labelled component used in it. The profiler samples at 100 Hz, so
+// components that used less than about 10ms may be missing.
+func SampleCPU(ctx context.Context, window time.Duration) (map[string]time.Duration, error) {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, ErrProfiling
+	}
+	timer := time.NewTimer(window)
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	timer.Stop()
+	pprof.StopCPUProfile()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parseCPUProfile(&buf)
+}
+
+// parseCPUProfile sums the CPU nanoseconds of a gzipped profile.proto by the
+// component label of each sample. It reads only the fields it needs:
+// Profile.sample_type (1), Profile.sample (2) and Profile.string_table (6),
+// Sample.value (2) and Sample.label (3), and Label.key (1) and Label.str (2).
+func parseCPUProfile(r io.Reader) (map[string]time.Duration, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read CPU profile: %w", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("read CPU profile: %w", err)
+	}
+
+	var (
+		sampleTypes []int64 // unit string index of each value
+		samples     [][]byte
+		strs        []string
+	)
+	err = protowire.EachField(data, func(field int, value uint64, raw []byte) error {
+		switch field {
+		case 1:
+			var unit int64
+			err := protowire.EachField(raw, func(field int, value uint64, _ []byte) error {
+				if field == 2 {
+					unit = int64(value)
+				}
+				return nil
+			})
+			sampleTypes = append(sampleTypes, unit)
+			return err
+		case 2:
+			samples = append(samples, raw)
+		case 6:
+			strs = append(strs, string(raw))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read CPU profile: %w", err)
+	}
+
+	nanos := -1
+	for i, unit := range sampleTypes {
+		if unit >= 0 && int(unit) < len(strs) && strs[unit] == "nanoseconds" {
+			nanos = i
+		}
+	}
+	if nanos < 0 {
+		return nil, fmt.Errorf("read CPU profile: no nanoseconds sample type")
+	}
+
+	cpu := make(map[string]time.Duration)
+	for _, sample := range samples {
+		var (
+			values    []int64
+			component string
+		)
+		err := protowire.EachField(sample, func(field int, value uint64, raw []byte) error {
+			switch field {
+			case 2:
+				if raw == nil {
+					values = append(values, int64(value))
+					return nil
+				}
+				packed, err := protowire.Varints(raw)
+				if err != nil {
+					return fmt.Errorf("read CPU profile: %w", err)
+				}
+				for _, v := range packed {
+					values = append(values, int64(v))
+				}
+			case 3:
+				var key, str int64
+				err := protowire.EachField(raw, func(field int, value uint64, _ []byte) error {
+					switch field {
+					case 1:
+						key = int64(value)
+					case 2:
+						str = int64(value)
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				if key > 0 && int(key) < len(strs) && strs[key] == labelKey && int(str) < len(strs) {
+					component = strs[str]
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if component != "" && nanos < len(values) {
+			cpu[component] += time.Duration(values[nanos])
+		}
+	}
+	return cpu, nil
+}
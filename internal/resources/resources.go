@@ -0,0 +1,111 @@
+// Package resources attributes the daemon's goroutines and CPU time to the
+// plugins and pollers that run in it. Components run under a pprof label,
+// which goroutines they start inherit, so the goroutine and CPU profiles can
+// be split by component without any cooperation from the component itself.
+package resources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/metrics"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+)
+
+// labelKey is the pprof label holding the component a goroutine belongs to.
+const labelKey = "devlog_component"
+
+// Component names a plugin or poller for attribution, e.g. "plugin/llm" or
+// "poller/git".
+func Component(kind, name string) string {
+	return kind + "/" + name
+}
+
+// Label sets the component label on the calling goroutine for as long as it
+// runs, and on every goroutine it starts. It suits long-lived goroutines
+// that own a component; use Do for a bounded piece of work.
+func Label(ctx context.Context, component string) {
+	pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels(labelKey, component)))
+}
+
+// Do runs fn under the component label, so the work it does and the
+// goroutines it starts are attributed to the component.
+func Do(ctx context.Context, component string, fn func(context.Context)) {
+	pprof.Do(ctx, pprof.Labels(labelKey, component), fn)
+}
+
+// Goroutines counts the live goroutines of each labelled component.
+func Goroutines() (map[string]int, error) {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		return nil, fmt.Errorf("write goroutine profile: %w", err)
+	}
+	return parseGoroutineProfile(&buf)
+}
+
+// parseGoroutineProfile reads the debug=1 goroutine profile, where each
+// group of identical stacks starts with "<count> @ <pcs>" and, when the
+// goroutines are labelled, is followed by "# labels: {...}".
+func parseGoroutineProfile(buf *bytes.Buffer) (map[string]int, error) {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(buf)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	group := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		if count, _, ok := strings.Cut(line, " @ "); ok {
+			group, _ = strconv.Atoi(count)
+			continue
+		}
+		labels, ok := strings.CutPrefix(line, "# labels: ")
+		if !ok || group == 0 {
+			continue
+		}
+		var parsed map[string]string
+		if err := json.Unmarshal([]byte(labels), &parsed); err != nil {
+			continue
+		}
+		if component := parsed[labelKey]; component != "" {
+			counts[component] += group
+		}
+		group = 0
+	}
+	return counts, scanner.Err()
+}
+
+// Runtime is a process-wide reading of the runtime's own metrics. CPUSeconds
+// is the CPU time the Go code and runtime have used, as estimated by the
+// runtime; it only grows.
+type Runtime struct {
+	HeapBytes  uint64
+	Goroutines int
+	CPUSeconds float64
+}
+
+// ReadRuntime samples the live heap, goroutine count and total CPU time of
+// the process.
+func ReadRuntime() Runtime {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/heap/objects:bytes"},
+		{Name: "/sched/goroutines:goroutines"},
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+	}
+	metrics.Read(samples)
+
+	var r Runtime
+	if v := samples[0].Value; v.Kind() == metrics.KindUint64 {
+		r.HeapBytes = v.Uint64()
+	}
+	if v := samples[1].Value; v.Kind() == metrics.KindUint64 {
+		r.Goroutines = int(v.Uint64())
+	}
+	if total, idle := samples[2].Value, samples[3].Value; total.Kind() == metrics.KindFloat64 && idle.Kind() == metrics.KindFloat64 {
+		r.CPUSeconds = total.Float64() - idle.Float64()
+	}
+	return r
+}
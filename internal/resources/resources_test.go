@@ -0,0 +1,75 @@
+package resources
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGoroutines(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		Label(ctx, Component("plugin", "idle"))
+		// Goroutines started by a labelled goroutine count towards it.
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-ctx.Done()
+			}()
+		}
+		<-ctx.Done()
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		counts, err := Goroutines()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counts["plugin/idle"] == 4 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Goroutines() = %v, want 4 for plugin/idle", counts)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSampleCPU(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		Label(ctx, Component("poller", "busy"))
+		for ctx.Err() == nil {
+			for i := 0; i < 1e6; i++ {
+			}
+		}
+	}()
+
+	cpu, err := SampleCPU(context.Background(), 300*time.Millisecond)
+	if errors.Is(err, ErrProfiling) {
+		t.Skip("CPU profiler in use")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu["poller/busy"] < 100*time.Millisecond {
+		t.Errorf("SampleCPU() = %v, want most of the window for poller/busy", cpu)
+	}
+}
+
+func TestReadRuntime(t *testing.T) {
+	r := ReadRuntime()
+	if r.HeapBytes == 0 || r.Goroutines == 0 {
+		t.Errorf("ReadRuntime() = %+v", r)
+	}
+}
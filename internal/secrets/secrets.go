@@ -0,0 +1,39 @@
+// Package secrets masks credentials in captured terminal text, such as
+// REPL sessions and command output, before it is stored.
+package secrets
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces each credential Redact finds.
+const Placeholder = "[REDACTED]"
+
+var patterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?(-----END [A-Z ]*PRIVATE KEY-----|$)`),
+	regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\b(ghp|gho|ghu|ghs|ghr|github_pat)_[A-Za-z0-9_]{20,}\b`),
+	regexp.MustCompile(`\b(sk|pk|rk)-[A-Za-z0-9_-]{20,}\b`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`),
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\b`),
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`),
+	regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`),
+}
+
+// keyValue matches assignments like password=..., "api_key": "...".
+var keyValue = regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key)["'\]]*\s*[:=]\s*["']?)([^\s"',;]+)`)
+
+// Redact masks private keys, cloud and API tokens, bearer headers, URL
+// passwords and secret-looking assignments in text.
+func Redact(text string) string {
+	for _, pattern := range patterns {
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if strings.HasPrefix(match, "://") {
+				return "://" + Placeholder + "@"
+			}
+			return Placeholder
+		})
+	}
+	return keyValue.ReplaceAllString(text, "${1}"+Placeholder)
+}
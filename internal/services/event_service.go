@@ -2,23 +2,36 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"path/filepath"
+	"time"
 
 	"devlog/internal/config"
 	"devlog/internal/events"
 	"devlog/internal/logger"
 	"devlog/internal/metrics"
+	"devlog/internal/pause"
 	"devlog/internal/storage"
 )
 
 type EventService struct {
-	storage      *storage.Storage
+	storage      storage.Store
 	configGetter func() *config.Config
 	logger       *logger.Logger
+	pause        *pause.Controller
+	buffer       *WriteBuffer
+	enrich       func(*events.Event)
+	observe      func(*events.Event)
+	quotas       *quotaTracker
+	now          func() time.Time
+	random       func() float64
 }
 
-func NewEventService(storage *storage.Storage, configGetter func() *config.Config, log *logger.Logger) *EventService {
+func NewEventService(storage storage.Store, configGetter func() *config.Config, log *logger.Logger) *EventService {
 	if log == nil {
 		log = logger.Default()
 	}
@@ -26,15 +39,92 @@ func NewEventService(storage *storage.Storage, configGetter func() *config.Confi
 		storage:      storage,
 		configGetter: configGetter,
 		logger:       log,
+		quotas:       newQuotaTracker(),
+		now:          time.Now,
+		random:       rand.Float64,
 	}
 }
 
+func (s *EventService) SetPause(p *pause.Controller) {
+	s.pause = p
+}
+
+// SetWriteBuffer makes IngestEvent hand accepted events to b instead of
+// storing them before returning.
+func (s *EventService) SetWriteBuffer(b *WriteBuffer) {
+	b.onStored = s.stored
+	s.buffer = b
+}
+
+// SetEnricher sets a function run on every accepted event before it is
+// stored, letting plugins add fields.
+func (s *EventService) SetEnricher(fn func(*events.Event)) {
+	s.enrich = fn
+}
+
+// SetObserver sets a function run on every event once it is stored,
+// letting plugins watch the events that were kept.
+func (s *EventService) SetObserver(fn func(*events.Event)) {
+	s.observe = fn
+}
+
+func (s *EventService) Paused() bool {
+	return s.pause != nil && s.pause.Active()
+}
+
+// IngestEvent filters an event and stores it, or buffers it when a write
+// buffer is set.
 func (s *EventService) IngestEvent(ctx context.Context, event *events.Event) error {
+	if err := s.accept(event); err != nil {
+		return err
+	}
+	if s.buffer != nil {
+		s.buffer.Add(event)
+		return nil
+	}
+	return s.store(event)
+}
+
+// IngestEventSync is IngestEvent without the write buffer, for callers that
+// must know the event is stored before returning, such as the queue.
+func (s *EventService) IngestEventSync(ctx context.Context, event *events.Event) error {
+	if err := s.accept(event); err != nil {
+		return err
+	}
+	return s.store(event)
+}
+
+// accept validates an event and applies the configured filters and privacy
+// rules to it.
+func (s *EventService) accept(event *events.Event) error {
 	if err := event.Validate(); err != nil {
 		metrics.EventIngestionErrors.Add(1)
 		return &ValidationError{Err: err}
 	}
 
+	// Payload schemas describe the current version, so older payloads are
+	// upgraded before they are checked.
+	if _, err := event.Upgrade(); err != nil {
+		metrics.EventIngestionErrors.Add(1)
+		return &ValidationError{Err: err}
+	}
+	if err := event.ValidatePayload(); err != nil {
+		metrics.EventIngestionErrors.Add(1)
+		s.logger.Warn("event rejected by payload schema",
+			slog.String("source", event.Source),
+			slog.String("type", event.Type),
+			slog.String("event_id", event.ID),
+			slog.String("error", err.Error()))
+		return &ValidationError{Err: err}
+	}
+
+	if s.Paused() {
+		s.logger.Debug("event dropped (capture paused)",
+			slog.String("source", event.Source),
+			slog.String("event_id", event.ID))
+		return ErrEventFiltered
+	}
+
 	cfg := s.configGetter()
 
 	if event.Source == string(events.SourceShell) && event.Type == string(events.TypeCommand) {
@@ -48,6 +138,26 @@ func (s *EventService) IngestEvent(ctx context.Context, event *events.Event) err
 		}
 	}
 
+	if decision := cfg.FilterEvent(event); !decision.Capture {
+		s.logger.Debug("event filtered",
+			slog.String("source", event.Source),
+			slog.String("reason", decision.Reason),
+			slog.String("event_id", event.ID))
+		return ErrEventFiltered
+	}
+
+	switch cfg.PrivacyAction(event) {
+	case config.PrivacyDrop:
+		s.logger.Debug("event dropped (excluded repo)",
+			slog.String("source", event.Source),
+			slog.String("event_id", event.ID))
+		return ErrEventFiltered
+	case config.PrivacyMask:
+		maskEvent(event)
+	default:
+		resolveRepo(cfg, event)
+	}
+
 	if event.Source == string(events.SourceGit) {
 		if !cfg.IsModuleEnabled("git") {
 			s.logger.Debug("git event filtered (module disabled)",
@@ -57,6 +167,62 @@ func (s *EventService) IngestEvent(ctx context.Context, event *events.Event) err
 		}
 	}
 
+	eventTime, err := time.Parse(time.RFC3339, event.Timestamp)
+	if err != nil {
+		eventTime = time.Now()
+	}
+	if !cfg.IsModuleActiveAt(event.Owner(), eventTime) {
+		s.logger.Debug("event filtered (outside active hours)",
+			slog.String("module", event.Owner()),
+			slog.String("event_id", event.ID))
+		return ErrEventFiltered
+	}
+
+	if quota := cfg.ModuleQuota(event.Owner()); !quota.IsZero() {
+		if !s.withinQuota(event, quota) {
+			return ErrEventFiltered
+		}
+	}
+
+	if s.enrich != nil {
+		s.enrich(event)
+	}
+
+	return nil
+}
+
+// withinQuota samples an event and checks its module's hourly quota. Errors
+// are never sampled away, but do count toward the quota once stored.
+func (s *EventService) withinQuota(event *events.Event, quota config.IngestQuota) bool {
+	if quota.SampleRate < 1 && event.Severity != string(events.SeverityError) && s.random() >= quota.SampleRate {
+		metrics.EventsSampledOut.Add(event.Source, 1)
+		s.logger.Debug("event dropped (sampled out)",
+			slog.String("source", event.Source),
+			slog.String("event_id", event.ID))
+		return false
+	}
+
+	if quota.MaxEventsPerHour == 0 {
+		return true
+	}
+	ok, first := s.quotas.allow(event.Owner(), quota.MaxEventsPerHour, s.now())
+	if ok {
+		return true
+	}
+	metrics.EventsOverQuota.Add(event.Source, 1)
+	if first {
+		s.logger.Warn("module reached its hourly quota; dropping its events until the rate falls",
+			slog.String("module", event.Owner()),
+			slog.Int("max_events_per_hour", quota.MaxEventsPerHour))
+	} else {
+		s.logger.Debug("event dropped (over quota)",
+			slog.String("source", event.Source),
+			slog.String("event_id", event.ID))
+	}
+	return false
+}
+
+func (s *EventService) store(event *events.Event) error {
 	insertTimer := metrics.StartTimer("insert_event")
 	defer insertTimer.Stop()
 
@@ -75,39 +241,114 @@ func (s *EventService) IngestEvent(ctx context.Context, event *events.Event) err
 		return fmt.Errorf("failed to store event: %w", err)
 	}
 
+	recordIngested(s.logger, event)
+	s.stored(event)
+	return nil
+}
+
+// stored runs after an event is written, directly or by the write buffer.
+// It charges the event to its module's quota and passes it to the
+// observer.
+func (s *EventService) stored(event *events.Event) {
+	s.quotas.record(event.Owner(), s.now())
+	if s.observe != nil {
+		s.observe(event)
+	}
+}
+
+func recordIngested(log *logger.Logger, event *events.Event) {
 	metrics.EventIngestionRate.Add(1)
 	metrics.GlobalSnapshot.RecordEventIngested(event.Source, event.Type)
-	s.logger.Info("event ingested",
+	log.Info("event ingested",
 		slog.String("source", event.Source),
 		slog.String("type", event.Type),
 		slog.String("event_id", event.ID))
-
-	return nil
 }
 
 func (s *EventService) SearchEvents(ctx context.Context, opts storage.SearchOptions) ([]*storage.SearchResult, error) {
 	if opts.Limit <= 0 {
 		opts.Limit = 20
 	}
+	opts.RepoPattern = s.configGetter().ResolveRepoName(opts.RepoPattern)
 
 	results, err := s.storage.Search(ctx, opts)
 	if err != nil {
 		return nil, fmt.Errorf("search failed: %w", err)
 	}
 
-	return results, nil
+	return s.AttachAnnotations(ctx, results)
+}
+
+// AttachAnnotations returns copies of the results with the annotations of
+// their events filled in. The results themselves may be shared with the
+// query cache and are left untouched.
+func (s *EventService) AttachAnnotations(ctx context.Context, results []*storage.SearchResult) ([]*storage.SearchResult, error) {
+	var ids []string
+	for _, result := range results {
+		if result.Event != nil {
+			ids = append(ids, result.Event.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return results, nil
+	}
+
+	annotations, err := s.storage.AnnotationsContext(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("load annotations: %w", err)
+	}
+	if len(annotations) == 0 {
+		return results, nil
+	}
+
+	annotated := make([]*storage.SearchResult, len(results))
+	for i, result := range results {
+		annotated[i] = result
+		if result.Event == nil || len(annotations[result.Event.ID]) == 0 {
+			continue
+		}
+		copied := *result
+		copied.Annotations = annotations[result.Event.ID]
+		annotated[i] = &copied
+	}
+	return annotated, nil
+}
+
+// GetEvent returns an event with its annotations.
+func (s *EventService) GetEvent(ctx context.Context, id string) (*events.Event, []storage.Annotation, error) {
+	event, err := s.storage.GetEventContext(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	annotations, err := s.storage.AnnotationsContext(ctx, []string{id})
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, annotations[id], nil
+}
+
+func (s *EventService) AddAnnotation(ctx context.Context, eventID, text string) (*storage.Annotation, error) {
+	return s.storage.AddAnnotationContext(ctx, eventID, text)
 }
 
 func (s *EventService) GetEvents(ctx context.Context, opts storage.QueryOptions) ([]*events.Event, error) {
 	return s.storage.QueryEventsContext(ctx, opts)
 }
 
+func (s *EventService) GetRelatedEvents(ctx context.Context, id string) ([]*events.Event, error) {
+	return s.storage.RelatedEventsContext(ctx, id)
+}
+
 func (s *EventService) GetEventsBySource(ctx context.Context) ([]storage.SourceCount, error) {
 	return s.storage.CountBySource(ctx)
 }
 
-func (s *EventService) GetTimeline(ctx context.Context) ([]storage.TimelinePoint, error) {
-	return s.storage.TimelineLast7Days(ctx)
+func (s *EventService) GetTimeline(ctx context.Context, start, end time.Time, bucket string) ([]storage.TimelinePoint, error) {
+	return s.storage.TimelineBuckets(ctx, start, end, bucket)
+}
+
+func (s *EventService) GetActivityDistribution(ctx context.Context, start, end time.Time) ([7][24]int, error) {
+	return s.storage.ActivityDistribution(ctx, start, end)
 }
 
 func (s *EventService) GetTopRepos(ctx context.Context, limit int) ([]storage.RepoStats, error) {
@@ -118,6 +359,18 @@ func (s *EventService) GetTopCommands(ctx context.Context, limit int) ([]storage
 	return s.storage.TopCommands(ctx, limit)
 }
 
+func (s *EventService) GetTopFiles(ctx context.Context, start, end time.Time, limit int) ([]storage.FileStats, error) {
+	return s.storage.TopFiles(ctx, start, end, limit)
+}
+
+func (s *EventService) GetTopLanguages(ctx context.Context, start, end time.Time, limit int) ([]storage.LanguageStats, error) {
+	return s.storage.TopLanguages(ctx, start, end, limit)
+}
+
+func (s *EventService) GetSummaries(ctx context.Context, start, end time.Time) ([]*storage.Summary, error) {
+	return s.storage.QuerySummariesContext(ctx, start, end)
+}
+
 func (s *EventService) CountEvents(ctx context.Context) (int, error) {
 	return s.storage.CountContext(ctx)
 }
@@ -138,3 +391,42 @@ var (
 	ErrEventFiltered  = fmt.Errorf("event filtered by configuration")
 	ErrDuplicateEvent = fmt.Errorf("duplicate event")
 )
+
+// resolveRepo stores the event under its canonical repo name (see
+// config.ResolveRepo). An absolute path that gets replaced is kept in the
+// payload so the event can still be traced back to its checkout.
+func resolveRepo(cfg *config.Config, event *events.Event) {
+	repo := cfg.ResolveRepo(event)
+	if repo == event.Repo {
+		return
+	}
+	if filepath.IsAbs(event.Repo) {
+		if _, ok := event.Payload["repo_path"]; !ok {
+			event.Payload["repo_path"] = event.Repo
+		}
+	}
+	event.Repo = repo
+}
+
+// maskEvent strips everything identifying from an event that came from a
+// masked repo, keeping only its source, type and time plus a stable hash of
+// the repo so activity can still be counted per repository.
+func maskEvent(event *events.Event) {
+	repo := event.Repo
+	if repo == "" {
+		for _, key := range config.RepoPathKeys {
+			if path, ok := event.Payload[key].(string); ok && path != "" {
+				repo = path
+				break
+			}
+		}
+	}
+
+	event.Payload = map[string]interface{}{"masked": true}
+	event.Branch = ""
+	event.Repo = ""
+	if repo != "" {
+		sum := sha256.Sum256([]byte(repo))
+		event.Repo = "masked-" + hex.EncodeToString(sum[:6])
+	}
+}
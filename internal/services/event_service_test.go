@@ -3,10 +3,13 @@ package services
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"devlog/internal/config"
 	"devlog/internal/events"
+	"devlog/internal/pause"
 	"devlog/internal/storage"
 	"devlog/internal/testutil"
 )
@@ -95,6 +98,158 @@ func TestEventService_IngestEvent_FilteredCommand(t *testing.T) {
 	}
 }
 
+func TestEventService_IngestEvent_Filters(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["git"] = config.ComponentConfig{Enabled: true}
+	cfg.Filters = []config.FilterRule{
+		{Module: "git", Action: config.FilterInclude, Repo: "/work/*"},
+		{Module: "git", Branch: "wip/*"},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	commit := func(repo, branch string) *events.Event {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Repo = repo
+		event.Branch = branch
+		event.Payload["message"] = "change"
+		return event
+	}
+
+	if err := service.IngestEvent(ctx, commit("/work/api", "main")); err != nil {
+		t.Fatalf("IngestEvent() error: %v", err)
+	}
+	if err := service.IngestEvent(ctx, commit("/home/me/dotfiles", "main")); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("repo outside include rule: expected ErrEventFiltered, got %v", err)
+	}
+	if err := service.IngestEvent(ctx, commit("/work/api", "wip/spike")); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("excluded branch: expected ErrEventFiltered, got %v", err)
+	}
+
+	count, err := store.CountContext(ctx)
+	testutil.AssertNoError(t, err, "CountContext failed")
+	testutil.AssertEqual(t, count, 1, "event count")
+}
+
+func TestEventService_IngestEvent_ExcludedRepos(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["git"] = config.ComponentConfig{Enabled: true}
+	cfg.Privacy.ExcludedRepos = []config.ExcludedRepo{
+		{Pattern: "/work/clients/*"},
+		{Pattern: "/work/secret", Action: config.PrivacyMask},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	commit := func(repo, workdir string) *events.Event {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Repo = repo
+		event.Branch = "feature/launch"
+		event.Payload["message"] = "add pricing page"
+		event.Payload["workdir"] = workdir
+		return event
+	}
+
+	if err := service.IngestEvent(ctx, commit("acme", "/work/clients/acme")); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("excluded repo: expected ErrEventFiltered, got %v", err)
+	}
+
+	masked := commit("secret", "/work/secret/web")
+	if err := service.IngestEvent(ctx, masked); err != nil {
+		t.Fatalf("IngestEvent() error: %v", err)
+	}
+
+	stored, err := store.GetEvent(masked.ID)
+	testutil.AssertNoError(t, err, "GetEvent failed")
+	if stored.Repo == "secret" || !strings.HasPrefix(stored.Repo, "masked-") {
+		t.Errorf("repo = %q, want hashed", stored.Repo)
+	}
+	if stored.Branch != "" || len(stored.Payload) != 1 || stored.Payload["masked"] != true {
+		t.Errorf("masked event kept details: branch=%q payload=%v", stored.Branch, stored.Payload)
+	}
+
+	again := commit("secret", "/work/secret")
+	service.IngestEvent(ctx, again)
+	if other, _ := store.GetEvent(again.ID); other == nil || other.Repo != stored.Repo {
+		t.Error("masked repo hash should be stable")
+	}
+}
+
+func TestEventService_IngestEvent_RepoAliases(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["git"] = config.ComponentConfig{Enabled: true}
+	cfg.Repos.Aliases = []config.RepoAlias{
+		{Name: "devlog", Match: []string{"github.com/*/devlog"}},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	fromFork := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	fromFork.Repo = "devlog-fork"
+	fromFork.Branch = "main"
+	fromFork.Payload[config.RemotePayloadKey] = "github.com/someone/devlog"
+
+	fromPath := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	fromPath.Repo = "/home/me/src/api"
+	fromPath.Branch = "main"
+
+	for _, event := range []*events.Event{fromFork, fromPath} {
+		if err := service.IngestEvent(ctx, event); err != nil {
+			t.Fatalf("IngestEvent() error: %v", err)
+		}
+	}
+
+	stored, err := store.GetEvent(fromFork.ID)
+	testutil.AssertNoError(t, err, "GetEvent failed")
+	testutil.AssertEqual(t, stored.Repo, "devlog", "aliased repo")
+
+	stored, err = store.GetEvent(fromPath.ID)
+	testutil.AssertNoError(t, err, "GetEvent failed")
+	testutil.AssertEqual(t, stored.Repo, "api", "absolute repo path")
+	testutil.AssertEqual(t, stored.Payload["repo_path"], "/home/me/src/api", "original repo path")
+
+	results, err := service.SearchEvents(ctx, storage.SearchOptions{Query: "*", RepoPattern: "/other/clone/api"})
+	testutil.AssertNoError(t, err, "SearchEvents failed")
+	testutil.AssertEqual(t, len(results), 1, "search by path finds canonical repo")
+}
+
+func TestEventService_IngestEvent_Paused(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	service := NewEventService(store, configGetter(cfg), nil)
+	controller := pause.NewController(t.TempDir())
+	service.SetPause(controller)
+	ctx := context.Background()
+
+	if _, err := controller.Pause(time.Hour); err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+
+	event := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	event.Payload["text"] = "private"
+	if err := service.IngestEvent(ctx, event); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("expected ErrEventFiltered while paused, got %v", err)
+	}
+
+	if err := controller.Resume(); err != nil {
+		t.Fatalf("Resume() error: %v", err)
+	}
+
+	event = events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	event.Payload["text"] = "public"
+	if err := service.IngestEvent(ctx, event); err != nil {
+		t.Errorf("expected event to be stored after resume, got %v", err)
+	}
+
+	count, _ := store.Count()
+	if count != 1 {
+		t.Errorf("expected 1 stored event, got %d", count)
+	}
+}
+
 func TestEventService_IngestEvent_FilteredGitEvent(t *testing.T) {
 	store := testutil.NewTestStorage(t)
 	cfg := testutil.NewTestConfig()
@@ -112,6 +267,96 @@ func TestEventService_IngestEvent_FilteredGitEvent(t *testing.T) {
 	}
 }
 
+func TestEventService_IngestEvent_OutsideActiveHours(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["clipboard"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"active_hours": "09:00-18:00 Mon-Fri"},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	saturday := time.Date(2025, 6, 7, 12, 0, 0, 0, time.Local)
+	event := events.NewEvent(string(events.SourceClipboard), string(events.TypeCopy))
+	event.Timestamp = saturday.Format(time.RFC3339)
+	event.Payload["content"] = "weekend plans"
+
+	err := service.IngestEvent(ctx, event)
+	if !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("expected ErrEventFiltered outside active hours, got %v", err)
+	}
+
+	event = events.NewEvent(string(events.SourceClipboard), string(events.TypeCopy))
+	event.Timestamp = saturday.AddDate(0, 0, 2).Format(time.RFC3339)
+	event.Payload["content"] = "standup notes"
+
+	err = service.IngestEvent(ctx, event)
+	testutil.AssertNoError(t, err, "IngestEvent during active hours")
+
+	count, err := store.CountContext(ctx)
+	testutil.AssertNoError(t, err, "CountContext failed")
+	testutil.AssertEqual(t, count, 1, "event count")
+}
+
+// TestEventService_IngestEvent_ActiveHoursByModule checks that forge's
+// active hours apply to its gitlab events, which are not stored under the
+// module's name.
+func TestEventService_IngestEvent_ActiveHoursByModule(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["forge"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"active_hours": "09:00-18:00 Mon-Fri"},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	saturday := time.Date(2025, 6, 7, 12, 0, 0, 0, time.Local)
+	mergeRequest := func(at time.Time) *events.Event {
+		event := events.NewEvent(string(events.SourceGitLab), string(events.TypePROpened))
+		event.Module = "forge"
+		event.Timestamp = at.Format(time.RFC3339)
+		event.Payload["title"] = "Add forge"
+		return event
+	}
+
+	if err := service.IngestEvent(ctx, mergeRequest(saturday)); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("expected ErrEventFiltered outside forge's active hours, got %v", err)
+	}
+	testutil.AssertNoError(t, service.IngestEvent(ctx, mergeRequest(saturday.AddDate(0, 0, 2))), "IngestEvent during active hours")
+}
+
+func TestEventService_IngestEvent_Enricher(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	calls := 0
+	service.SetEnricher(func(e *events.Event) {
+		calls++
+		e.Payload["issue"] = "PROJ-1"
+	})
+
+	event := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	event.Payload["text"] = "fix PROJ-1"
+	err := service.IngestEvent(ctx, event)
+	testutil.AssertNoError(t, err, "IngestEvent failed")
+
+	stored, err := store.GetEvent(event.ID)
+	testutil.AssertNoError(t, err, "GetEvent failed")
+	testutil.AssertEqual(t, stored.Payload["issue"], "PROJ-1", "enriched payload")
+
+	// Filtered events are not enriched.
+	event = events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	event.Payload["message"] = "PROJ-2"
+	if err := service.IngestEvent(ctx, event); !errors.Is(err, ErrEventFiltered) {
+		t.Fatalf("expected ErrEventFiltered, got %v", err)
+	}
+	testutil.AssertEqual(t, calls, 1, "enricher calls")
+}
+
 func TestEventService_SearchEvents(t *testing.T) {
 	store := testutil.NewTestStorage(t)
 	cfg := testutil.NewTestConfig()
@@ -217,11 +462,16 @@ func TestEventService_GetTimeline(t *testing.T) {
 	event := testutil.NewEventBuilder().Build()
 	testutil.MustInsertEvents(t, store, event)
 
-	timeline, err := service.GetTimeline(ctx)
+	end := time.Now()
+	timeline, err := service.GetTimeline(ctx, end.Add(-24*time.Hour), end, storage.BucketHour)
 	testutil.AssertNoError(t, err, "GetTimeline failed")
 
-	if len(timeline) < 1 {
-		t.Logf("GetTimeline returned %d points (timeline may aggregate by date)", len(timeline))
+	total := 0
+	for _, p := range timeline {
+		total += p.Count
+	}
+	if len(timeline) != 25 || total != 1 {
+		t.Errorf("GetTimeline returned %d points with %d events, want 25 and 1", len(timeline), total)
 	}
 }
 
@@ -369,3 +619,152 @@ func TestEventService_DynamicConfigChange(t *testing.T) {
 		t.Errorf("expected cd to be filtered after config change, got %v", err)
 	}
 }
+
+func TestEventService_IngestEventPayloadSchema(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	service := NewEventService(store, configGetter(testutil.NewTestConfig()), nil)
+	ctx := context.Background()
+
+	note := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	note.Payload["text"] = 42
+	err := service.IngestEvent(ctx, note)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !strings.Contains(err.Error(), `"text"`) {
+		t.Fatalf("expected a ValidationError naming the field, got %v", err)
+	}
+
+	// Payloads from before a migration are upgraded, then checked.
+	legacy := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	legacy.Version = 1
+	legacy.Payload["note"] = "old style"
+	testutil.AssertNoError(t, service.IngestEvent(ctx, legacy), "IngestEvent of legacy note")
+
+	count, _ := store.CountContext(ctx)
+	testutil.AssertEqual(t, count, 1, "event count")
+}
+
+func TestEventService_IngestEvent_Quota(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["git"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"max_events_per_hour": 3},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	now := time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	commit := func() error {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Payload["message"] = "wip"
+		return service.IngestEvent(ctx, event)
+	}
+	for i := 0; i < 3; i++ {
+		now = now.Add(10 * time.Minute)
+		testutil.AssertNoError(t, commit(), "IngestEvent under quota")
+	}
+	if err := commit(); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("fourth event in the hour: expected ErrEventFiltered, got %v", err)
+	}
+
+	// The first event leaves the rolling hour, making room for one more.
+	now = now.Add(41 * time.Minute)
+	testutil.AssertNoError(t, commit(), "IngestEvent after the window moved")
+	if err := commit(); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("expected ErrEventFiltered once the quota is full again, got %v", err)
+	}
+
+	count, err := store.CountContext(ctx)
+	testutil.AssertNoError(t, err, "CountContext failed")
+	testutil.AssertEqual(t, count, 4, "event count")
+}
+
+// TestEventService_IngestEvent_QuotaByModule checks that a module's quota
+// covers events stored under another source, such as ci's github workflow
+// runs, and not the same source's events from other modules.
+func TestEventService_IngestEvent_QuotaByModule(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["ci"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"max_events_per_hour": 1},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	run := func(module string) error {
+		event := events.NewEvent(string(events.SourceGitHub), string(events.TypeWorkflowRun))
+		event.Module = module
+		event.Payload["workflow"] = "ci"
+		return service.IngestEvent(ctx, event)
+	}
+	testutil.AssertNoError(t, run("ci"), "IngestEvent under the ci quota")
+	if err := run("ci"); !errors.Is(err, ErrEventFiltered) {
+		t.Errorf("second ci event in the hour: expected ErrEventFiltered, got %v", err)
+	}
+	testutil.AssertNoError(t, run(""), "IngestEvent of a github webhook event")
+	testutil.AssertNoError(t, run("github"), "IngestEvent of a github module event")
+}
+
+func TestEventService_IngestEvent_QuotaIgnoresDuplicates(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["git"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"max_events_per_hour": 2},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	ctx := context.Background()
+
+	event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	event.Payload["message"] = "wip"
+	testutil.AssertNoError(t, service.IngestEvent(ctx, event), "IngestEvent")
+	for range 3 {
+		if err := service.IngestEvent(ctx, event); !errors.Is(err, ErrDuplicateEvent) {
+			t.Fatalf("re-sent event: expected ErrDuplicateEvent, got %v", err)
+		}
+	}
+
+	// Duplicates were not stored, so they left room for a new event.
+	other := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	other.Payload["message"] = "fix"
+	testutil.AssertNoError(t, service.IngestEvent(ctx, other), "IngestEvent after duplicates")
+}
+
+func TestEventService_IngestEvent_Sampling(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	cfg := testutil.NewTestConfig()
+	cfg.Modules["git"] = config.ComponentConfig{
+		Enabled: true,
+		Config:  map[string]interface{}{"sample_rate": 0.25},
+	}
+	service := NewEventService(store, configGetter(cfg), nil)
+	draws := []float64{0.1, 0.5, 0.9, 0.2}
+	service.random = func() float64 {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+	ctx := context.Background()
+
+	var kept int
+	for range 4 {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Payload["message"] = "wip"
+		err := service.IngestEvent(ctx, event)
+		switch {
+		case err == nil:
+			kept++
+		case !errors.Is(err, ErrEventFiltered):
+			t.Fatalf("IngestEvent: %v", err)
+		}
+	}
+	testutil.AssertEqual(t, kept, 2, "events kept at 25% sampling")
+
+	failed := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	failed.Payload["message"] = "wip"
+	failed.Severity = string(events.SeverityError)
+	service.random = func() float64 { return 0.99 }
+	testutil.AssertNoError(t, service.IngestEvent(ctx, failed), "errors are never sampled away")
+}
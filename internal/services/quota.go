@@ -0,0 +1,76 @@
+package services
+
+import (
+	"sync"
+	"time"
+)
+
+// quotaTracker counts the events each module has stored over the last
+// hour to enforce max_events_per_hour. Counts are kept in one-minute
+// buckets, so memory stays fixed however high the limit is, and the hour
+// rolls forward a minute at a time.
+type quotaTracker struct {
+	mu      sync.Mutex
+	modules map[string]*hourWindow
+}
+
+type hourWindow struct {
+	counts  [60]int
+	minutes [60]int64
+	// over is set while the module is being held to its quota, so only
+	// the first dropped event is logged as a warning.
+	over bool
+}
+
+func newQuotaTracker() *quotaTracker {
+	return &quotaTracker{modules: make(map[string]*hourWindow)}
+}
+
+func (t *quotaTracker) window(module string) *hourWindow {
+	w := t.modules[module]
+	if w == nil {
+		w = &hourWindow{}
+		t.modules[module] = w
+	}
+	return w
+}
+
+// allow reports whether module has stored fewer than limit events in the
+// hour before now. It does not count the event; record does that once it
+// is stored, so events rejected later, such as duplicates, cost nothing.
+// first is set on the first refusal after the module was under its quota.
+func (t *quotaTracker) allow(module string, limit int, now time.Time) (ok, first bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := t.window(module)
+	minute := now.Unix() / 60
+	total := 0
+	for i, count := range w.counts {
+		if w.minutes[i] > minute-60 {
+			total += count
+		}
+	}
+	if total >= limit {
+		first = !w.over
+		w.over = true
+		return false, first
+	}
+	w.over = false
+	return true, false
+}
+
+// record counts an event module stored at now.
+func (t *quotaTracker) record(module string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := t.window(module)
+	minute := now.Unix() / 60
+	i := minute % 60
+	if w.minutes[i] != minute {
+		w.minutes[i] = minute
+		w.counts[i] = 0
+	}
+	w.counts[i]++
+}
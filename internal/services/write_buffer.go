@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/storage"
+)
+
+const (
+	DefaultWriteBufferSize    = 10000
+	DefaultWriteBatchSize     = 500
+	DefaultWriteFlushInterval = 250 * time.Millisecond
+	writeBufferInsertTimeout  = 30 * time.Second
+)
+
+// ErrWriteBufferFull is passed to the fallback for events that arrived while
+// the buffer was full.
+var ErrWriteBufferFull = fmt.Errorf("write buffer full")
+
+// ErrWriteBufferClosed is passed to the fallback for events that arrived
+// after Close or could not be flushed before Close gave up.
+var ErrWriteBufferClosed = fmt.Errorf("write buffer closed")
+
+// WriteBuffer holds accepted events in memory and stores them in batches,
+// one transaction per batch, from a single background writer. Events that
+// cannot be stored, or that do not fit in the buffer, are handed to the
+// fallback, which the daemon points at the on-disk queue.
+type WriteBuffer struct {
+	store    storage.Store
+	fallback func(*events.Event, error)
+	// onStored is called for each event the writer stores; the event
+	// service sets it before adding events.
+	onStored      func(*events.Event)
+	logger        *logger.Logger
+	batchSize     int
+	flushInterval time.Duration
+
+	events chan *events.Event
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewWriteBuffer creates a buffer holding up to size events that are
+// written batchSize at a time, at least every flushInterval. Zero values use
+// the defaults. Call Start before adding events.
+func NewWriteBuffer(store storage.Store, size, batchSize int, flushInterval time.Duration, fallback func(*events.Event, error), log *logger.Logger) *WriteBuffer {
+	if size <= 0 {
+		size = DefaultWriteBufferSize
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultWriteBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = DefaultWriteFlushInterval
+	}
+	if fallback == nil {
+		fallback = func(*events.Event, error) {}
+	}
+	if log == nil {
+		log = logger.Default()
+	}
+	return &WriteBuffer{
+		store:         store,
+		fallback:      fallback,
+		logger:        log,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		events:        make(chan *events.Event, size),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start runs the background writer until Close.
+func (b *WriteBuffer) Start() {
+	go b.run()
+}
+
+// Add queues an event for the next batch without blocking. An event that
+// does not fit is handed to the fallback instead.
+func (b *WriteBuffer) Add(event *events.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		b.fallback(event, ErrWriteBufferClosed)
+		return
+	}
+
+	select {
+	case b.events <- event:
+		metrics.WriteBuffer.Add("buffered", 1)
+	default:
+		metrics.WriteBuffer.Add("overflowed", 1)
+		b.logger.Warn("write buffer full, queueing event",
+			slog.String("event_id", event.ID))
+		b.fallback(event, ErrWriteBufferFull)
+	}
+}
+
+// Len reports how many events are waiting to be written.
+func (b *WriteBuffer) Len() int {
+	return len(b.events)
+}
+
+// Close stops accepting events and waits for the writer to flush what is
+// buffered. If ctx ends first, the events still buffered are handed to the
+// fallback.
+func (b *WriteBuffer) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.events)
+	b.mu.Unlock()
+
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	// The writer is still busy with a batch; whatever it has not taken yet
+	// goes to the fallback. The batch in flight is either committed or
+	// handed to the fallback by the writer itself.
+	spilled := 0
+	for event := range b.events {
+		b.fallback(event, ErrWriteBufferClosed)
+		spilled++
+	}
+	b.logger.Warn("write buffer did not drain before shutdown",
+		slog.Int("queued", spilled))
+	<-b.done
+	return ctx.Err()
+}
+
+func (b *WriteBuffer) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*events.Event, 0, b.batchSize)
+	for {
+		select {
+		case event, ok := <-b.events:
+			if !ok {
+				b.flush(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= b.batchSize {
+				b.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+func (b *WriteBuffer) flush(batch []*events.Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	timer := metrics.StartTimer("write_buffer_flush")
+	defer timer.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), writeBufferInsertTimeout)
+	defer cancel()
+
+	results, err := b.store.InsertEventsContext(ctx, batch)
+	if err != nil {
+		metrics.EventIngestionErrors.Add(int64(len(batch)))
+		b.logger.Error("failed to store event batch",
+			slog.Int("events", len(batch)),
+			slog.String("error", err.Error()))
+		for _, event := range batch {
+			b.fallback(event, fmt.Errorf("failed to store event: %w", err))
+		}
+		return
+	}
+
+	stored := 0
+	for i, event := range batch {
+		switch err := results[i]; {
+		case err == nil:
+			stored++
+			recordIngested(b.logger, event)
+			if b.onStored != nil {
+				b.onStored(event)
+			}
+		case err == storage.ErrDuplicateEvent:
+			b.logger.Debug("duplicate event skipped",
+				slog.String("event_id", event.ID),
+				slog.String("source", event.Source))
+		default:
+			metrics.EventIngestionErrors.Add(1)
+			b.logger.Error("failed to store event",
+				slog.String("event_id", event.ID),
+				slog.String("source", event.Source),
+				slog.String("error", err.Error()))
+			b.fallback(event, fmt.Errorf("failed to store event: %w", err))
+		}
+	}
+	metrics.WriteBuffer.Add("flushes", 1)
+	metrics.WriteBuffer.Add("written", int64(stored))
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/testutil"
+)
+
+type fallbackRecorder struct {
+	mu     sync.Mutex
+	events []*events.Event
+	errs   []error
+}
+
+func (r *fallbackRecorder) record(event *events.Event, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	r.errs = append(r.errs, err)
+}
+
+func TestWriteBufferFlushesBatches(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	service := NewEventService(store, configGetter(testutil.NewTestConfig()), nil)
+	rec := &fallbackRecorder{}
+	buffer := NewWriteBuffer(store, 100, 3, time.Hour, rec.record, nil)
+	service.SetWriteBuffer(buffer)
+	buffer.Start()
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		event := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+		testutil.AssertNoError(t, service.IngestEvent(ctx, event), "IngestEvent failed")
+	}
+
+	// A full batch is written without waiting for the flush interval.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		count, _ := store.CountContext(ctx)
+		if count == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("count = %d, want the first batch of 3 written", count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	testutil.AssertNoError(t, buffer.Close(ctx), "Close failed")
+	count, _ := store.CountContext(ctx)
+	testutil.AssertEqual(t, count, 4, "event count after close")
+
+	// Quotas are charged as the buffer stores events.
+	charged := 0
+	for _, n := range service.quotas.modules[string(events.SourceShell)].counts {
+		charged += n
+	}
+	testutil.AssertEqual(t, charged, 4, "events charged to the shell quota")
+
+	late := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+	testutil.AssertNoError(t, service.IngestEvent(ctx, late), "IngestEvent after close")
+	if len(rec.events) != 1 || !errors.Is(rec.errs[0], ErrWriteBufferClosed) {
+		t.Errorf("fallback got %v, want the late event as closed", rec.errs)
+	}
+}
+
+func TestWriteBufferOverflowAndFailure(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	rec := &fallbackRecorder{}
+	// Not started, so nothing drains the buffer.
+	buffer := NewWriteBuffer(store, 1, 10, time.Hour, rec.record, nil)
+
+	first := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+	second := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+	buffer.Add(first)
+	buffer.Add(second)
+	if len(rec.events) != 1 || rec.events[0] != second || !errors.Is(rec.errs[0], ErrWriteBufferFull) {
+		t.Fatalf("fallback got %v, want the second event as overflow", rec.errs)
+	}
+
+	// A batch that cannot be written goes to the fallback as a whole.
+	store.Close()
+	buffer.flush([]*events.Event{first})
+	if len(rec.events) != 2 || rec.events[1] != first {
+		t.Errorf("fallback got %d events, want the failed batch", len(rec.events))
+	}
+}
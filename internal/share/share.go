@@ -0,0 +1,118 @@
+package share
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	KindSummary = "summary"
+
+	keyFile  = "share.key"
+	keyBytes = 32
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid share token")
+	ErrExpired      = errors.New("share link has expired")
+)
+
+type Grant struct {
+	Kind      string `json:"k"`
+	Date      string `json:"d"`
+	ExpiresAt int64  `json:"e"`
+}
+
+func (g Grant) Expiry() time.Time {
+	return time.Unix(g.ExpiresAt, 0)
+}
+
+func LoadOrCreateKey(dataDir string) ([]byte, error) {
+	path := filepath.Join(dataDir, keyFile)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) < keyBytes {
+			return nil, fmt.Errorf("share key %s is corrupt; run 'devlog share rotate'", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read share key: %w", err)
+	}
+
+	return RotateKey(dataDir)
+}
+
+func RotateKey(dataDir string) ([]byte, error) {
+	key := make([]byte, keyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate share key: %w", err)
+	}
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	path := filepath.Join(dataDir, keyFile)
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)), 0600); err != nil {
+		return nil, fmt.Errorf("write share key: %w", err)
+	}
+
+	return key, nil
+}
+
+func Sign(key []byte, grant Grant) (string, error) {
+	payload, err := json.Marshal(grant)
+	if err != nil {
+		return "", fmt.Errorf("marshal grant: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac(key, encoded)), nil
+}
+
+func Verify(key []byte, token string, now time.Time) (*Grant, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, mac(key, encoded)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var grant Grant
+	if err := json.Unmarshal(payload, &grant); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if !now.Before(grant.Expiry()) {
+		return nil, ErrExpired
+	}
+
+	return &grant, nil
+}
+
+func mac(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,83 @@
+package share
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	key := []byte(strings.Repeat("k", keyBytes))
+	now := time.Date(2025, 6, 12, 9, 0, 0, 0, time.UTC)
+
+	token, err := Sign(key, Grant{Kind: KindSummary, Date: "2025-06-12", ExpiresAt: now.Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("Sign() error: %v", err)
+	}
+
+	grant, err := Verify(key, token, now)
+	if err != nil {
+		t.Fatalf("Verify() error: %v", err)
+	}
+	if grant.Kind != KindSummary || grant.Date != "2025-06-12" {
+		t.Errorf("got grant %+v", grant)
+	}
+
+	if _, err := Verify(key, token, now.Add(2*time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("expired token: got %v, want ErrExpired", err)
+	}
+
+	otherKey := []byte(strings.Repeat("x", keyBytes))
+	if _, err := Verify(otherKey, token, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("wrong key: got %v, want ErrInvalidToken", err)
+	}
+
+	forged, _ := Sign(otherKey, Grant{Kind: KindSummary, Date: "2025-06-13", ExpiresAt: now.Add(time.Hour).Unix()})
+	payload, _, _ := strings.Cut(forged, ".")
+	_, sig, _ := strings.Cut(token, ".")
+	if _, err := Verify(key, payload+"."+sig, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("swapped payload: got %v, want ErrInvalidToken", err)
+	}
+
+	for _, bad := range []string{"", "abc", "abc.def", token + "x"} {
+		if _, err := Verify(key, bad, now); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Verify(%q) = %v, want ErrInvalidToken", bad, err)
+		}
+	}
+}
+
+func TestLoadOrCreateKey(t *testing.T) {
+	dir := t.TempDir()
+
+	key, err := LoadOrCreateKey(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey() error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, keyFile))
+	if err != nil {
+		t.Fatalf("key file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	again, err := LoadOrCreateKey(dir)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKey() error: %v", err)
+	}
+	if string(again) != string(key) {
+		t.Error("LoadOrCreateKey() should return the stored key")
+	}
+
+	rotated, err := RotateKey(dir)
+	if err != nil {
+		t.Fatalf("RotateKey() error: %v", err)
+	}
+	if string(rotated) == string(key) {
+		t.Error("RotateKey() should generate a new key")
+	}
+}
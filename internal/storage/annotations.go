@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/errors"
+)
+
+// Annotation is a note attached to an event after it was captured, such as
+// "this was the root cause". Annotations are deleted with their event.
+type Annotation struct {
+	ID        int64     `json:"id"`
+	EventID   string    `json:"event_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var ErrAmbiguousEventID = fmt.Errorf("event id prefix matches more than one event")
+
+// ResolveEventIDContext expands an event ID or a unique prefix of one, such
+// as the eight characters search prints, to the full ID.
+func (s *Storage) ResolveEventIDContext(ctx context.Context, prefix string) (string, error) {
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+	if prefix == "" {
+		return "", fmt.Errorf("%w: empty id", ErrEventNotFound)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	// Event IDs are UUIDs, so the prefix has no LIKE wildcards to escape
+	// once it is known to be hex and dashes.
+	if strings.Trim(prefix, "0123456789abcdef-") != "" {
+		return "", fmt.Errorf("%w: %s", ErrEventNotFound, prefix)
+	}
+
+	rows, err := s.db.QueryContext(ctx, "SELECT id FROM events WHERE id LIKE ? LIMIT 2", prefix+"%")
+	if err != nil {
+		return "", errors.WrapStorage("resolve event id", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return "", errors.WrapStorage("resolve event id", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return "", errors.WrapStorage("resolve event id", err)
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", ErrEventNotFound, prefix)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrAmbiguousEventID, prefix)
+	}
+}
+
+func (s *Storage) AddAnnotationContext(ctx context.Context, eventID, text string) (*Annotation, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.NewValidation("text", "must not be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var exists int
+	err := s.db.QueryRowContext(ctx, "SELECT 1 FROM events WHERE id = ?", eventID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrEventNotFound, eventID)
+	}
+	if err != nil {
+		return nil, errors.WrapStorage("query event", err)
+	}
+
+	now := time.Now()
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO event_annotations (event_id, text, created_at)
+		VALUES (?, ?, ?)
+	`, eventID, text, now.Unix())
+	if err != nil {
+		return nil, errors.WrapStorage("insert annotation", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, errors.WrapStorage("insert annotation", err)
+	}
+
+	return &Annotation{ID: id, EventID: eventID, Text: text, CreatedAt: time.Unix(now.Unix(), 0)}, nil
+}
+
+// AnnotationsContext returns the annotations of the given events, oldest
+// first, keyed by event ID. Events without annotations are left out.
+func (s *Storage) AnnotationsContext(ctx context.Context, eventIDs []string) (map[string][]Annotation, error) {
+	annotations := make(map[string][]Annotation)
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	// Stay well below SQLite's limit on bound parameters.
+	const chunkSize = 500
+	for start := 0; start < len(eventIDs); start += chunkSize {
+		chunk := eventIDs[start:min(start+chunkSize, len(eventIDs))]
+
+		placeholders := make([]string, len(chunk))
+		args := make([]interface{}, len(chunk))
+		for i, id := range chunk {
+			placeholders[i] = "?"
+			args[i] = id
+		}
+
+		rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+			SELECT id, event_id, text, created_at
+			FROM event_annotations
+			WHERE event_id IN (%s)
+			ORDER BY created_at ASC, id ASC
+		`, strings.Join(placeholders, ",")), args...)
+		if err != nil {
+			return nil, errors.WrapStorage("query annotations", err)
+		}
+
+		for rows.Next() {
+			var a Annotation
+			var createdAt int64
+			if err := rows.Scan(&a.ID, &a.EventID, &a.Text, &createdAt); err != nil {
+				rows.Close()
+				return nil, errors.WrapStorage("scan annotation", err)
+			}
+			a.CreatedAt = time.Unix(createdAt, 0)
+			annotations[a.EventID] = append(annotations[a.EventID], a)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, errors.WrapStorage("query annotations", err)
+		}
+	}
+
+	return annotations, nil
+}
+
+func (s *Storage) DeleteAnnotationContext(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "DELETE FROM event_annotations WHERE id = ?", id)
+	if err != nil {
+		return errors.WrapStorage("delete annotation", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("annotation %d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"devlog/internal/events"
+)
+
+func TestAnnotations(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	other := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	for _, e := range []*events.Event{event, other} {
+		if err := storage.InsertEvent(e); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	id, err := storage.ResolveEventIDContext(ctx, event.ID[:8])
+	if err != nil || id != event.ID {
+		t.Fatalf("ResolveEventIDContext() = %q, %v, want %q", id, err, event.ID)
+	}
+	if _, err := storage.ResolveEventIDContext(ctx, "zzz"); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("non-hex prefix: got %v, want ErrEventNotFound", err)
+	}
+	if _, err := storage.ResolveEventIDContext(ctx, ""); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("empty prefix: got %v, want ErrEventNotFound", err)
+	}
+
+	if _, err := storage.AddAnnotationContext(ctx, event.ID, "this was the root cause"); err != nil {
+		t.Fatalf("AddAnnotationContext() error: %v", err)
+	}
+	if _, err := storage.AddAnnotationContext(ctx, event.ID, "fixed in a follow-up"); err != nil {
+		t.Fatalf("AddAnnotationContext() error: %v", err)
+	}
+	if _, err := storage.AddAnnotationContext(ctx, "missing", "text"); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("missing event: got %v, want ErrEventNotFound", err)
+	}
+	if _, err := storage.AddAnnotationContext(ctx, event.ID, "  "); err == nil {
+		t.Error("expected an error for empty text")
+	}
+
+	annotations, err := storage.AnnotationsContext(ctx, []string{event.ID, other.ID})
+	if err != nil {
+		t.Fatalf("AnnotationsContext() error: %v", err)
+	}
+	got := annotations[event.ID]
+	if len(got) != 2 || got[0].Text != "this was the root cause" || got[1].Text != "fixed in a follow-up" {
+		t.Errorf("annotations = %+v, want both in order", got)
+	}
+	if _, ok := annotations[other.ID]; ok {
+		t.Error("event without annotations should be left out")
+	}
+
+	if err := storage.DeleteAnnotationContext(ctx, got[1].ID); err != nil {
+		t.Fatalf("DeleteAnnotationContext() error: %v", err)
+	}
+	if _, err := storage.DeleteEventsContext(ctx, []string{event.ID}); err != nil {
+		t.Fatal(err)
+	}
+	var remaining int
+	if err := storage.db.QueryRow("SELECT COUNT(*) FROM event_annotations").Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Errorf("%d annotations left after deleting their event", remaining)
+	}
+}
@@ -0,0 +1,318 @@
+package storage
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+
+	"devlog/internal/errors"
+)
+
+const (
+	backupPrefix     = "events-"
+	backupTimeLayout = "20060102-150405"
+	gzipSuffix       = ".gz"
+)
+
+// sqliteBackuper is the online backup API of modernc.org/sqlite connections.
+type sqliteBackuper interface {
+	NewBackup(dstURI string) (*sqlite.Backup, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+type BackupOptions struct {
+	// Gzip compresses the copy. Restore detects compressed backups itself.
+	Gzip bool
+}
+
+type BackupInfo struct {
+	Path    string
+	Size    int64
+	Created time.Time
+}
+
+// BackupContext copies the database to dest with SQLite's online backup API.
+// The copy is a consistent snapshot even while the daemon keeps writing.
+func (s *Storage) BackupContext(ctx context.Context, dest string, opts BackupOptions) (*BackupInfo, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), DefaultDirPermissions); err != nil {
+		return nil, errors.WrapStorage("create backup directory", err)
+	}
+
+	raw := dest
+	if opts.Gzip {
+		raw = dest + ".tmp"
+		defer os.Remove(raw)
+	}
+	if err := os.Remove(raw); err != nil && !os.IsNotExist(err) {
+		return nil, errors.WrapStorage("remove old backup", err)
+	}
+
+	if err := copyDatabase(ctx, s.db, raw, false); err != nil {
+		os.Remove(raw)
+		return nil, errors.WrapStorage("back up database", err)
+	}
+
+	if opts.Gzip {
+		if err := gzipFile(raw, dest); err != nil {
+			os.Remove(dest)
+			return nil, errors.WrapStorage("compress backup", err)
+		}
+	}
+
+	info, err := os.Stat(dest)
+	if err != nil {
+		return nil, errors.WrapStorage("back up database", err)
+	}
+	return &BackupInfo{Path: dest, Size: info.Size(), Created: info.ModTime()}, nil
+}
+
+// copyDatabase runs the online backup API on one connection of db, copying
+// it to path, or from path into db when restore is set.
+func copyDatabase(ctx context.Context, db *sql.DB, path string, restore bool) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		backuper, ok := driverConn.(sqliteBackuper)
+		if !ok {
+			return fmt.Errorf("sqlite driver does not support online backup")
+		}
+		var backup *sqlite.Backup
+		if restore {
+			backup, err = backuper.NewRestore(path)
+		} else {
+			backup, err = backuper.NewBackup(path)
+		}
+		if err != nil {
+			return err
+		}
+		// Copying every page in one step holds a single read transaction,
+		// so writes made meanwhile cannot restart the copy.
+		if _, err := backup.Step(-1); err != nil {
+			backup.Finish()
+			return err
+		}
+		return backup.Finish()
+	})
+}
+
+// RestoreOptions controls Restore.
+type RestoreOptions struct {
+	// NoBackup skips saving the current database as <path>.pre-restore.bak.
+	NoBackup bool
+}
+
+type RestoreResult struct {
+	SchemaVersion int
+	Events        int
+	Backup        string // copy of the replaced database, empty if none
+}
+
+// Restore replaces the database at dbPath with a backup made by
+// BackupContext, gzip-compressed or not. The backup is checked before
+// anything is overwritten, and the current database is saved first unless
+// opts.NoBackup is set. Stop the daemon before restoring.
+func Restore(ctx context.Context, backupPath, dbPath string, opts RestoreOptions) (*RestoreResult, error) {
+	src, cleanup, err := uncompressedBackup(backupPath)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	result, err := checkBackup(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbPath), DefaultDirPermissions); err != nil {
+		return nil, errors.WrapStorage("create data directory", err)
+	}
+	db, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	if !opts.NoBackup {
+		if info, err := os.Stat(dbPath); err == nil && info.Size() > 0 {
+			result.Backup = dbPath + ".pre-restore.bak"
+			if err := backupDatabase(db, result.Backup); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := copyDatabase(ctx, db, src, true); err != nil {
+		return nil, errors.WrapStorage("restore database", err)
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return nil, errors.WrapStorage("checkpoint restored database", err)
+	}
+	return result, nil
+}
+
+// checkBackup opens a backup read-only and makes sure it is an intact
+// devlog database this version can use.
+func checkBackup(ctx context.Context, path string) (*RestoreResult, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, errors.WrapStorage("open backup", err)
+	}
+	defer db.Close()
+
+	var check string
+	if err := db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&check); err != nil {
+		return nil, fmt.Errorf("%s is not a devlog backup: %w", path, err)
+	}
+	if check != "ok" {
+		return nil, fmt.Errorf("backup %s is corrupt: %s", path, check)
+	}
+
+	result := &RestoreResult{}
+	if result.SchemaVersion, err = getCurrentVersion(db); err != nil {
+		return nil, errors.WrapStorage("read backup schema", err)
+	}
+	if result.SchemaVersion == 0 {
+		return nil, fmt.Errorf("%s is not a devlog backup: it has no schema", path)
+	}
+	if latest := LatestSchemaVersion(); result.SchemaVersion > latest {
+		return nil, fmt.Errorf("backup has schema version %d but this devlog only knows %d; upgrade devlog first", result.SchemaVersion, latest)
+	}
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM events").Scan(&result.Events); err != nil {
+		return nil, fmt.Errorf("%s is not a devlog backup: %w", path, err)
+	}
+	return result, nil
+}
+
+// uncompressedBackup returns a path to the backup as a plain database file,
+// unpacking gzip-compressed backups to a temporary file.
+func uncompressedBackup(path string) (string, func(), error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("open backup: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic, err := r.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return path, func() {}, nil
+	}
+
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return "", nil, fmt.Errorf("read compressed backup: %w", err)
+	}
+	defer zr.Close()
+
+	tmp, err := os.CreateTemp("", "devlog-restore-*.db")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.Remove(tmp.Name()) }
+	if _, err := io.Copy(tmp, zr); err != nil {
+		tmp.Close()
+		cleanup()
+		return "", nil, fmt.Errorf("decompress backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return tmp.Name(), cleanup, nil
+}
+
+func gzipFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// BackupFileName names a scheduled backup taken at t, so that backups sort
+// by age.
+func BackupFileName(t time.Time, gzipped bool) string {
+	name := backupPrefix + t.UTC().Format(backupTimeLayout) + ".db"
+	if gzipped {
+		name += gzipSuffix
+	}
+	return name
+}
+
+// ListBackups returns the scheduled backups in dir, newest first. Files not
+// named by BackupFileName are ignored.
+func ListBackups(dir string) ([]BackupInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []BackupInfo
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), backupPrefix)
+		if !ok || e.IsDir() {
+			continue
+		}
+		stamp = strings.TrimSuffix(strings.TrimSuffix(stamp, gzipSuffix), ".db")
+		created, err := time.Parse(backupTimeLayout, stamp)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupInfo{Path: filepath.Join(dir, e.Name()), Size: info.Size(), Created: created})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Created.After(backups[j].Created) })
+	return backups, nil
+}
+
+// PruneBackups deletes all but the newest keep scheduled backups in dir and
+// returns the paths removed.
+func PruneBackups(dir string, keep int) ([]string, error) {
+	backups, err := ListBackups(dir)
+	if err != nil || len(backups) <= keep {
+		return nil, err
+	}
+	var removed []string
+	for _, b := range backups[keep:] {
+		if err := os.Remove(b.Path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, b.Path)
+	}
+	return removed, nil
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func insertNote(t *testing.T, s *Storage, text string) *events.Event {
+	t.Helper()
+	event := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	event.Payload["text"] = text
+	if err := s.InsertEvent(event); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+	return event
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	for _, gzipped := range []bool{false, true} {
+		name := "plain"
+		if gzipped {
+			name = "gzip"
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store, dbPath := setupTestDB(t)
+			kept := insertNote(t, store, "before the backup")
+
+			dest := filepath.Join(t.TempDir(), "nested", "events.db")
+			info, err := store.BackupContext(ctx, dest, BackupOptions{Gzip: gzipped})
+			if err != nil {
+				t.Fatalf("BackupContext() error: %v", err)
+			}
+			if info.Size == 0 {
+				t.Error("backup is empty")
+			}
+			if _, err := os.Stat(dest + ".tmp"); !os.IsNotExist(err) {
+				t.Error("temporary uncompressed copy was left behind")
+			}
+
+			lost := insertNote(t, store, "after the backup")
+			store.Close()
+
+			result, err := Restore(ctx, dest, dbPath, RestoreOptions{})
+			if err != nil {
+				t.Fatalf("Restore() error: %v", err)
+			}
+			if result.Events != 1 || result.SchemaVersion != LatestSchemaVersion() {
+				t.Errorf("result = %+v, want 1 event at the latest schema", result)
+			}
+			if result.Backup == "" {
+				t.Error("current database was not saved before restoring")
+			}
+
+			restored, err := New(dbPath)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			defer restored.Close()
+			if _, err := restored.GetEvent(kept.ID); err != nil {
+				t.Errorf("event from before the backup missing: %v", err)
+			}
+			if _, err := restored.GetEvent(lost.ID); err == nil {
+				t.Error("event from after the backup survived the restore")
+			}
+		})
+	}
+}
+
+func TestRestoreRejectsInvalidBackup(t *testing.T) {
+	ctx := context.Background()
+	store, dbPath := setupTestDB(t)
+	insertNote(t, store, "keep me")
+	store.Close()
+
+	bogus := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(bogus, []byte("not a database at all, just some text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Restore(ctx, bogus, dbPath, RestoreOptions{}); err == nil {
+		t.Fatal("Restore() accepted a file that is not a database")
+	}
+
+	reopened, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer reopened.Close()
+	count, err := reopened.CountContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("count = %d after a rejected restore, want 1", count)
+	}
+}
+
+func TestListAndPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		name := BackupFileName(base.Add(time.Duration(i)*time.Hour), i%2 == 0)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "events.db.pre-restore.bak"), []byte("x"), 0644)
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 4 || !backups[0].Created.Equal(base.Add(3*time.Hour)) {
+		t.Fatalf("backups = %+v, want 4 newest first", backups)
+	}
+
+	removed, err := PruneBackups(dir, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 {
+		t.Errorf("removed %v, want the 2 oldest", removed)
+	}
+	if left, _ := ListBackups(dir); len(left) != 2 || !left[1].Created.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("left = %+v", left)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "events.db.pre-restore.bak")); err != nil {
+		t.Error("prune removed a file it does not own")
+	}
+}
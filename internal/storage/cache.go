@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"devlog/internal/metrics"
+)
+
+const (
+	DefaultQueryCacheSize = 256
+	DefaultQueryCacheTTL  = time.Minute
+)
+
+// queryCache is an LRU cache with a TTL for read-only query results. Every
+// entry is dropped as soon as the database's write watermark moves, so
+// callers never see results older than the last ingest.
+type queryCache struct {
+	mu        sync.Mutex
+	size      int
+	ttl       time.Duration
+	entries   map[string]*list.Element
+	order     *list.List
+	watermark cacheWatermark
+	now       func() time.Time
+
+	// generation counts writes this process made that do not move the
+	// watermark on their own: deletes and in-place updates.
+	generation atomic.Uint64
+}
+
+// cacheWatermark changes whenever events or summaries are added, or this
+// process deletes or rewrites rows. Deletes made by another process are
+// only noticed when entries expire.
+type cacheWatermark struct {
+	eventRow   int64
+	summaryID  int64
+	generation uint64
+}
+
+type cacheEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+func newQueryCache(size int, ttl time.Duration) *queryCache {
+	return &queryCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// EnableQueryCache caches search results and dashboard aggregations in
+// memory. A size or ttl of zero uses the defaults.
+func (s *Storage) EnableQueryCache(size int, ttl time.Duration) {
+	if size <= 0 {
+		size = DefaultQueryCacheSize
+	}
+	if ttl <= 0 {
+		ttl = DefaultQueryCacheTTL
+	}
+	s.cache = newQueryCache(size, ttl)
+}
+
+// invalidateCache drops cached results after a write the watermark cannot
+// see.
+func (s *Storage) invalidateCache() {
+	if s.cache != nil {
+		s.cache.generation.Add(1)
+	}
+}
+
+func (s *Storage) cacheWatermark(ctx context.Context) (cacheWatermark, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	w := cacheWatermark{generation: s.cache.generation.Load()}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT (SELECT COALESCE(MAX(rowid), 0) FROM events),
+			(SELECT COALESCE(MAX(id), 0) FROM summaries)
+	`).Scan(&w.eventRow, &w.summaryID)
+	if err != nil {
+		return w, fmt.Errorf("query cache watermark: %w", err)
+	}
+	return w, nil
+}
+
+func (c *queryCache) get(key string, w cacheWatermark) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if w != c.watermark {
+		if len(c.entries) > 0 {
+			metrics.QueryCache.Add("invalidations", 1)
+		}
+		c.entries = make(map[string]*list.Element)
+		c.order.Init()
+		c.watermark = w
+		return nil, false
+	}
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cacheEntry)
+	if c.now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+func (c *queryCache) put(key string, value any, w cacheWatermark) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// A write landed while the query ran; the result may predate it.
+	if w != c.watermark {
+		return
+	}
+
+	if el, ok := c.entries[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value = value
+		entry.expires = c.now().Add(c.ttl)
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expires: c.now().Add(c.ttl)})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// cached returns the cached result for the query described by plan, or runs
+// load and caches what it returns. plan must capture every input of the
+// query. Results are shared between callers and must not be modified.
+func cached[T any](ctx context.Context, s *Storage, name string, plan any, load func() (T, error)) (T, error) {
+	if s.cache == nil {
+		return load()
+	}
+
+	planJSON, err := json.Marshal(plan)
+	if err != nil {
+		return load()
+	}
+	key := name + ":" + string(planJSON)
+
+	w, err := s.cacheWatermark(ctx)
+	if err != nil {
+		return load()
+	}
+	if value, ok := s.cache.get(key, w); ok {
+		metrics.QueryCache.Add("hits", 1)
+		return value.(T), nil
+	}
+	metrics.QueryCache.Add("misses", 1)
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	s.cache.put(key, value, w)
+	return value, nil
+}
+
+// cacheRange rounds an open-ended range out to whole minutes so that
+// repeated "last N days" queries share an entry. Only used when the cache is
+// enabled.
+func cacheRange(start, end time.Time) (time.Time, time.Time) {
+	start = start.Truncate(time.Minute)
+	if rounded := end.Truncate(time.Minute); !rounded.Equal(end) {
+		end = rounded.Add(time.Minute)
+	}
+	return start, end
+}
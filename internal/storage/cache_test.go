@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func insertRepoEvent(t *testing.T, store *Storage, repo string) *events.Event {
+	t.Helper()
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Repo = repo
+	event.Payload["command"] = "make"
+	if err := store.InsertEvent(event); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+	return event
+}
+
+func TestQueryCacheInvalidation(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	store.EnableQueryCache(0, 0)
+	ctx := context.Background()
+
+	first := insertRepoEvent(t, store, "api")
+	repos, err := store.TopRepos(ctx, 10)
+	if err != nil || len(repos) != 1 {
+		t.Fatalf("TopRepos() = %v, %v", repos, err)
+	}
+
+	// Rows written behind the cache's back are not seen until the watermark
+	// moves.
+	if _, err := store.db.Exec("UPDATE events SET repo = 'hidden'"); err != nil {
+		t.Fatal(err)
+	}
+	repos, _ = store.TopRepos(ctx, 10)
+	if repos[0].Repo != "api" {
+		t.Errorf("expected a cached result, got %+v", repos)
+	}
+
+	insertRepoEvent(t, store, "web")
+	repos, _ = store.TopRepos(ctx, 10)
+	if len(repos) != 2 || repos[0].Repo == "api" {
+		t.Errorf("insert should invalidate the cache, got %+v", repos)
+	}
+
+	if _, err := store.DeleteEventsContext(ctx, []string{first.ID}); err != nil {
+		t.Fatal(err)
+	}
+	repos, _ = store.TopRepos(ctx, 10)
+	if len(repos) != 1 || repos[0].Repo != "web" {
+		t.Errorf("delete should invalidate the cache, got %+v", repos)
+	}
+}
+
+func TestQueryCacheTTLAndEviction(t *testing.T) {
+	c := newQueryCache(2, time.Minute)
+	now := time.Date(2025, 5, 21, 9, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	w := cacheWatermark{eventRow: 1}
+
+	c.get("", w)
+	c.put("a", 1, w)
+	c.put("b", 2, w)
+	c.get("a", w)
+	c.put("c", 3, w)
+
+	if _, ok := c.get("b", w); ok {
+		t.Error("least recently used entry should have been evicted")
+	}
+	if v, ok := c.get("a", w); !ok || v.(int) != 1 {
+		t.Errorf("a = %v, %v", v, ok)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := c.get("a", w); ok {
+		t.Error("expired entry should not be returned")
+	}
+
+	c.put("d", 4, cacheWatermark{eventRow: 2})
+	if _, ok := c.get("d", w); ok {
+		t.Error("result computed under another watermark should not be cached")
+	}
+}
+
+func TestSearchCacheSharesEquivalentPlans(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	store.EnableQueryCache(0, 0)
+	ctx := context.Background()
+
+	insertRepoEvent(t, store, "api")
+	after := time.Now().Add(-time.Hour)
+	results, err := store.Search(ctx, SearchOptions{Query: "make", After: &after, Modules: []string{"shell", "git"}})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Search() = %d results, %v", len(results), err)
+	}
+
+	if _, err := store.db.Exec("UPDATE events SET repo = 'hidden'"); err != nil {
+		t.Fatal(err)
+	}
+	sameMinute := after.Truncate(time.Minute)
+	results, err = store.Search(ctx, SearchOptions{Query: "  make ", After: &sameMinute, Modules: []string{"git", "shell"}})
+	if err != nil || len(results) != 1 || results[0].Event.Repo != "api" {
+		t.Errorf("equivalent search should hit the cache, got %+v, %v", results, err)
+	}
+}
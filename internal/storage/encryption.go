@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"devlog/internal/encryption"
+	"devlog/internal/errors"
+)
+
+const (
+	settingPayloadEncryption = "payload_encryption"
+	settingPayloadKeyID      = "payload_key_id"
+
+	payloadEncryptionAlgorithm = "aes-256-gcm"
+	payloadRewriteBatchSize    = 500
+)
+
+var ErrPayloadEncrypted = fmt.Errorf("payload is encrypted and no encryption key is loaded")
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (s *Storage) getSetting(ctx context.Context, key string) (string, bool, error) {
+	var value string
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+func setSetting(ctx context.Context, db execer, key, value string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO settings (key, value, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value, getCurrentTimestamp())
+	return err
+}
+
+func (s *Storage) loadPayloadCipher() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeout)
+	defer cancel()
+
+	algorithm, ok, err := s.getSetting(ctx, settingPayloadEncryption)
+	if err != nil {
+		return errors.WrapStorage("read encryption setting", err)
+	}
+	if !ok {
+		return nil
+	}
+	if algorithm != payloadEncryptionAlgorithm {
+		return errors.WrapStorage("load encryption key", fmt.Errorf("unsupported payload encryption %q", algorithm))
+	}
+
+	key, err := encryption.LoadKey()
+	if err != nil {
+		return errors.WrapStorage("load encryption key", fmt.Errorf("payload encryption is enabled for this database: %w", err))
+	}
+
+	c, err := encryption.NewCipher(key)
+	if err != nil {
+		return errors.WrapStorage("load encryption key", err)
+	}
+
+	keyID, _, err := s.getSetting(ctx, settingPayloadKeyID)
+	if err != nil {
+		return errors.WrapStorage("read encryption setting", err)
+	}
+	if keyID != c.KeyID() {
+		return errors.WrapStorage("load encryption key",
+			fmt.Errorf("key %s from %s does not match database key %s", c.KeyID(), encryption.KeySource(), keyID))
+	}
+
+	s.cipher = c
+	return nil
+}
+
+func (s *Storage) PayloadEncryptionEnabled() bool {
+	return s.cipher != nil
+}
+
+func (s *Storage) PayloadKeyID() string {
+	if s.cipher == nil {
+		return ""
+	}
+	return s.cipher.KeyID()
+}
+
+func (s *Storage) EnablePayloadEncryption(ctx context.Context, key []byte) (int, error) {
+	c, err := encryption.NewCipher(key)
+	if err != nil {
+		return 0, errors.WrapStorage("enable encryption", err)
+	}
+
+	if s.cipher != nil && s.cipher.KeyID() != c.KeyID() {
+		return 0, errors.WrapStorage("enable encryption",
+			fmt.Errorf("database is already encrypted with key %s", s.cipher.KeyID()))
+	}
+
+	settingsCtx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(settingsCtx, nil)
+	if err != nil {
+		return 0, errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if err := setSetting(settingsCtx, tx, settingPayloadEncryption, payloadEncryptionAlgorithm); err != nil {
+		return 0, errors.WrapStorage("save encryption setting", err)
+	}
+	if err := setSetting(settingsCtx, tx, settingPayloadKeyID, c.KeyID()); err != nil {
+		return 0, errors.WrapStorage("save encryption setting", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.WrapStorage("commit transaction", err)
+	}
+
+	s.cipher = c
+
+	return s.rewritePayloads(ctx, false, c.Encrypt)
+}
+
+func (s *Storage) DisablePayloadEncryption(ctx context.Context) (int, error) {
+	if s.cipher == nil {
+		return 0, errors.WrapStorage("disable encryption", fmt.Errorf("payload encryption is not enabled"))
+	}
+
+	n, err := s.rewritePayloads(ctx, true, s.cipher.Decrypt)
+	if err != nil {
+		return n, err
+	}
+
+	settingsCtx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(settingsCtx, "DELETE FROM settings WHERE key IN (?, ?)",
+		settingPayloadEncryption, settingPayloadKeyID); err != nil {
+		return n, errors.WrapStorage("clear encryption setting", err)
+	}
+
+	s.cipher = nil
+	return n, nil
+}
+
+func (s *Storage) rewritePayloads(ctx context.Context, encrypted bool, transform func(string) (string, error)) (int, error) {
+	op := "="
+	if !encrypted {
+		op = "!="
+	}
+	query := fmt.Sprintf(`
+		SELECT rowid, payload FROM events
+		WHERE rowid > ? AND substr(payload, 1, ?) %s ?
+		ORDER BY rowid
+		LIMIT ?
+	`, op)
+
+	total := 0
+	var lastRowID int64
+
+	for {
+		batchCtx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+		n, last, err := s.rewritePayloadBatch(batchCtx, query, lastRowID, transform)
+		cancel()
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if n < payloadRewriteBatchSize {
+			return total, nil
+		}
+		lastRowID = last
+	}
+}
+
+func (s *Storage) rewritePayloadBatch(ctx context.Context, query string, after int64, transform func(string) (string, error)) (int, int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, after, errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, after, len(encryption.Prefix), encryption.Prefix, payloadRewriteBatchSize)
+	if err != nil {
+		return 0, after, errors.WrapStorage("select payloads", err)
+	}
+
+	type row struct {
+		id      int64
+		payload string
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.id, &r.payload); err != nil {
+			rows.Close()
+			return 0, after, errors.WrapStorage("scan payload", err)
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, after, errors.WrapStorage("iterate payloads", err)
+	}
+
+	last := after
+	for _, r := range batch {
+		payload, err := transform(r.payload)
+		if err != nil {
+			return 0, after, errors.WrapStorage(fmt.Sprintf("rewrite payload at row %d", r.id), err)
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE events SET payload = ? WHERE rowid = ?", payload, r.id); err != nil {
+			return 0, after, errors.WrapStorage("update payload", err)
+		}
+		last = r.id
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, after, errors.WrapStorage("commit transaction", err)
+	}
+	s.invalidateCache()
+
+	return len(batch), last, nil
+}
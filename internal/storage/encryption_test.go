@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"devlog/internal/encryption"
+	"devlog/internal/events"
+)
+
+func rawPayload(t *testing.T, s *Storage, id string) string {
+	t.Helper()
+	var payload string
+	if err := s.db.QueryRow("SELECT payload FROM events WHERE id = ?", id).Scan(&payload); err != nil {
+		t.Fatalf("read raw payload: %v", err)
+	}
+	return payload
+}
+
+func newShellEvent(command string) *events.Event {
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Repo = "/path/to/repo"
+	event.Payload["command"] = command
+	return event
+}
+
+func TestPayloadEncryption(t *testing.T) {
+	key, _ := encryption.GenerateKey()
+	t.Setenv(encryption.EnvKey, hex.EncodeToString(key))
+
+	store, dbPath := setupTestDB(t)
+	ctx := context.Background()
+
+	plain := newShellEvent("echo before")
+	if err := store.InsertEvent(plain); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+
+	n, err := store.EnablePayloadEncryption(ctx, key)
+	if err != nil {
+		t.Fatalf("EnablePayloadEncryption failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 payload rewritten, got %d", n)
+	}
+	if !encryption.IsEncrypted(rawPayload(t, store, plain.ID)) {
+		t.Error("expected existing payload to be encrypted")
+	}
+
+	after := newShellEvent("echo after")
+	if err := store.InsertEvent(after); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	raw := rawPayload(t, store, after.ID)
+	if !encryption.IsEncrypted(raw) || strings.Contains(raw, "echo after") {
+		t.Errorf("expected new payload to be stored encrypted, got %q", raw)
+	}
+
+	got, err := store.GetEvent(after.ID)
+	if err != nil {
+		t.Fatalf("GetEvent failed: %v", err)
+	}
+	if got.Payload["command"] != "echo after" {
+		t.Errorf("expected decrypted command, got %v", got.Payload["command"])
+	}
+
+	if _, err := store.TopCommands(ctx, 10); err != nil {
+		t.Errorf("TopCommands should tolerate encrypted rows: %v", err)
+	}
+	if _, err := store.Search(ctx, SearchOptions{
+		PayloadFilter: &PayloadFilter{JSONPath: "$.command", Value: "echo after"},
+	}); err != nil {
+		t.Errorf("payload filter should tolerate encrypted rows: %v", err)
+	}
+	store.Close()
+
+	reopened, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if !reopened.PayloadEncryptionEnabled() {
+		t.Fatal("expected encryption to be enabled after reopen")
+	}
+	got, err = reopened.GetEvent(plain.ID)
+	if err != nil {
+		t.Fatalf("GetEvent failed: %v", err)
+	}
+	if got.Payload["command"] != "echo before" {
+		t.Errorf("expected decrypted command, got %v", got.Payload["command"])
+	}
+
+	n, err = reopened.DisablePayloadEncryption(ctx)
+	if err != nil {
+		t.Fatalf("DisablePayloadEncryption failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 payloads decrypted, got %d", n)
+	}
+	if raw := rawPayload(t, reopened, after.ID); encryption.IsEncrypted(raw) {
+		t.Errorf("expected plaintext payload, got %q", raw)
+	}
+	results, err := reopened.Search(ctx, SearchOptions{Query: "after"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Event.ID != after.ID {
+		t.Errorf("expected decrypted payload to be searchable again, got %d results", len(results))
+	}
+	reopened.Close()
+
+	t.Setenv(encryption.EnvKey, "")
+	plainAgain, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("expected decrypted database to open without a key: %v", err)
+	}
+	plainAgain.Close()
+}
+
+func TestPayloadEncryptionKeyMismatch(t *testing.T) {
+	key, _ := encryption.GenerateKey()
+	store, dbPath := setupTestDB(t)
+
+	event := newShellEvent("ls")
+	if err := store.InsertEvent(event); err != nil {
+		t.Fatalf("InsertEvent failed: %v", err)
+	}
+	if _, err := store.EnablePayloadEncryption(context.Background(), key); err != nil {
+		t.Fatalf("EnablePayloadEncryption failed: %v", err)
+	}
+
+	other, _ := encryption.GenerateKey()
+	if _, err := store.EnablePayloadEncryption(context.Background(), other); err == nil {
+		t.Error("expected error when re-enabling with a different key")
+	}
+
+	unkeyed := &Storage{db: store.db}
+	if _, err := restoreEventPayload(unkeyed.cipher, event, rawPayload(t, store, event.ID)); !errors.Is(err, ErrPayloadEncrypted) {
+		t.Errorf("expected ErrPayloadEncrypted, got %v", err)
+	}
+	store.Close()
+
+	t.Setenv(encryption.EnvKey, hex.EncodeToString(other))
+	if _, err := New(dbPath); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("expected key mismatch error, got %v", err)
+	}
+}
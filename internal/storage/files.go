@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"devlog/internal/encryption"
+	"devlog/internal/events"
+)
+
+type FileStats struct {
+	Repo     string
+	Path     string
+	Language string
+	Count    int
+}
+
+type LanguageStats struct {
+	Language string
+	Count    int
+	Files    int
+}
+
+type fileTouch struct {
+	repo string
+	path string
+}
+
+// fileTouches lists every file mentioned by git commits, shell commands and
+// editor edits in [start, end). A file named twice by one event counts once.
+func (s *Storage) fileTouches(ctx context.Context, start, end time.Time) ([]fileTouch, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	query := `
+		SELECT id, timestamp, source, type, repo, branch, payload, version,
+			duration_ms, session_id, parent_id, severity
+		FROM events
+		WHERE timestamp >= ? AND timestamp < ?
+		AND ((source = 'git' AND type = 'commit')
+			OR (source = 'shell' AND type = 'command')
+			OR (source = 'claude' AND type = 'file_edit'))
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query file events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanFileTouches(rows, s.cipher)
+}
+
+func scanFileTouches(rows *sql.Rows, cipher *encryption.Cipher) ([]fileTouch, error) {
+	var touches []fileTouch
+	for rows.Next() {
+		event, err := scanEvent(cipher, rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+
+		workdir, _ := event.Payload["workdir"].(string)
+		seen := make(map[string]bool)
+		for _, path := range events.Files(event) {
+			path = normalizeFilePath(event.Repo, workdir, path)
+			if path == "" || seen[path] {
+				continue
+			}
+			seen[path] = true
+			touches = append(touches, fileTouch{repo: event.Repo, path: path})
+		}
+	}
+
+	return touches, rows.Err()
+}
+
+// normalizeFilePath makes paths from different sources comparable: shell
+// paths are resolved against the command's working directory, and absolute
+// paths inside a checkout named after repo become relative to it, like the
+// paths git reports.
+func normalizeFilePath(repo, workdir, path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" || path == "." {
+		return ""
+	}
+	if !filepath.IsAbs(path) && workdir != "" {
+		path = filepath.Join(workdir, path)
+	}
+	path = filepath.Clean(path)
+
+	if filepath.IsAbs(path) && repo != "" {
+		marker := string(filepath.Separator) + repo + string(filepath.Separator)
+		if i := strings.LastIndex(path, marker); i >= 0 {
+			return path[i+len(marker):]
+		}
+	}
+	return path
+}
+
+// TopFiles returns the files touched most often in [start, end).
+func (s *Storage) TopFiles(ctx context.Context, start, end time.Time, limit int) ([]FileStats, error) {
+	touches, err := s.fileTouches(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return countFiles(touches, limit), nil
+}
+
+func countFiles(touches []fileTouch, limit int) []FileStats {
+	counts := make(map[fileTouch]int)
+	for _, t := range touches {
+		counts[t]++
+	}
+
+	results := make([]FileStats, 0, len(counts))
+	for t, count := range counts {
+		results = append(results, FileStats{
+			Repo:     t.repo,
+			Path:     t.path,
+			Language: events.Language(t.path),
+			Count:    count,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		if results[i].Repo != results[j].Repo {
+			return results[i].Repo < results[j].Repo
+		}
+		return results[i].Path < results[j].Path
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// TopLanguages groups the files touched in [start, end) by language. Files
+// in unrecognised languages are left out.
+func (s *Storage) TopLanguages(ctx context.Context, start, end time.Time, limit int) ([]LanguageStats, error) {
+	touches, err := s.fileTouches(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return countLanguages(touches, limit), nil
+}
+
+func countLanguages(touches []fileTouch, limit int) []LanguageStats {
+	byLanguage := make(map[string]*LanguageStats)
+	files := make(map[fileTouch]bool)
+	for _, t := range touches {
+		lang := events.Language(t.path)
+		if lang == "" {
+			continue
+		}
+		stats, ok := byLanguage[lang]
+		if !ok {
+			stats = &LanguageStats{Language: lang}
+			byLanguage[lang] = stats
+		}
+		stats.Count++
+		if !files[t] {
+			files[t] = true
+			stats.Files++
+		}
+	}
+
+	results := make([]LanguageStats, 0, len(byLanguage))
+	for _, stats := range byLanguage {
+		results = append(results, *stats)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Count != results[j].Count {
+			return results[i].Count > results[j].Count
+		}
+		return results[i].Language < results[j].Language
+	})
+
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func TestTopFilesAndLanguages(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	commit := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	commit.Repo = "devlog"
+	commit.Payload["hash"] = "abc123"
+	commit.Payload["files"] = []interface{}{"internal/api/handlers.go", "README.md"}
+
+	vim := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	vim.Repo = "devlog"
+	vim.Payload["command"] = "vim handlers.go"
+	vim.Payload["workdir"] = "/home/me/src/devlog/internal/api"
+
+	edit := events.NewEvent("claude", "file_edit")
+	edit.Repo = "devlog"
+	edit.Payload["file_path"] = "/home/me/src/devlog/internal/api/handlers.go"
+
+	other := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	other.Repo = "scripts"
+	other.Payload["command"] = "cat deploy.sh && cat deploy.sh"
+	other.Payload["workdir"] = "/home/me/src/scripts"
+
+	for _, e := range []*events.Event{commit, vim, edit, other} {
+		if err := storage.InsertEvent(e); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	start, end := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	files, err := storage.TopFiles(ctx, start, end, 10)
+	if err != nil {
+		t.Fatalf("TopFiles() error: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("TopFiles() = %+v, want 3 files", files)
+	}
+	top := files[0]
+	if top.Repo != "devlog" || top.Path != "internal/api/handlers.go" || top.Count != 3 || top.Language != "Go" {
+		t.Errorf("top file = %+v, want devlog internal/api/handlers.go x3 (Go)", top)
+	}
+	for _, f := range files[1:] {
+		if f.Count != 1 {
+			t.Errorf("%s counted %d times, want 1", f.Path, f.Count)
+		}
+	}
+
+	langs, err := storage.TopLanguages(ctx, start, end, 10)
+	if err != nil {
+		t.Fatalf("TopLanguages() error: %v", err)
+	}
+	want := []LanguageStats{
+		{Language: "Go", Count: 3, Files: 1},
+		{Language: "Markdown", Count: 1, Files: 1},
+		{Language: "Shell", Count: 1, Files: 1},
+	}
+	if len(langs) != len(want) {
+		t.Fatalf("TopLanguages() = %+v, want %+v", langs, want)
+	}
+	for i := range want {
+		if langs[i] != want[i] {
+			t.Errorf("TopLanguages()[%d] = %+v, want %+v", i, langs[i], want[i])
+		}
+	}
+
+	if files, err := storage.TopFiles(ctx, start.Add(-48*time.Hour), start, 10); err != nil || len(files) != 0 {
+		t.Errorf("TopFiles() outside range = %+v, %v; want none", files, err)
+	}
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devlog/internal/errors"
+)
+
+type LLMUsage struct {
+	Timestamp    time.Time
+	Caller       string
+	Provider     string
+	Model        string
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+}
+
+// LLMDailyUsage totals LLM calls for one caller on one calendar day.
+type LLMDailyUsage struct {
+	Date         time.Time
+	Caller       string
+	Completions  int
+	InputTokens  int
+	OutputTokens int
+	CostUSD      float64
+}
+
+func (s *Storage) InsertLLMUsageContext(ctx context.Context, usage *LLMUsage) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	timestamp := usage.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO llm_usage (timestamp, caller, provider, model, input_tokens, output_tokens, cost_usd)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, timestamp.Unix(), usage.Caller, usage.Provider, usage.Model, usage.InputTokens, usage.OutputTokens, usage.CostUSD)
+	if err != nil {
+		return errors.WrapStorage("insert llm usage", err)
+	}
+	return nil
+}
+
+// LLMUsageByDayContext returns usage per calendar day in start's location and
+// caller, oldest day first. Days without calls are omitted.
+func (s *Storage) LLMUsageByDayContext(ctx context.Context, start, end time.Time) ([]LLMDailyUsage, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT timestamp, caller, input_tokens, output_tokens, cost_usd
+		FROM llm_usage
+		WHERE timestamp >= ? AND timestamp < ?
+		ORDER BY timestamp ASC
+	`, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query llm usage: %w", err)
+	}
+	defer rows.Close()
+
+	loc := start.Location()
+	var days []LLMDailyUsage
+	index := make(map[string]int)
+	for rows.Next() {
+		var timestamp int64
+		var caller string
+		var input, output int
+		var cost float64
+		if err := rows.Scan(&timestamp, &caller, &input, &output, &cost); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		t := time.Unix(timestamp, 0).In(loc)
+		key := t.Format("2006-01-02") + "\x00" + caller
+		i, ok := index[key]
+		if !ok {
+			i = len(days)
+			index[key] = i
+			days = append(days, LLMDailyUsage{
+				Date:   time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc),
+				Caller: caller,
+			})
+		}
+		day := &days[i]
+		day.Completions++
+		day.InputTokens += input
+		day.OutputTokens += output
+		day.CostUSD += cost
+	}
+
+	return days, rows.Err()
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"devlog/internal/errors"
+)
+
+// MaintenanceTimeout bounds each maintenance step. ANALYZE and the FTS merge
+// read most of the database, so they get far longer than regular queries.
+const MaintenanceTimeout = 10 * time.Minute
+
+type MaintenanceOptions struct {
+	// Vacuum also rebuilds the database file. It holds an exclusive lock for
+	// the whole run, so the daemon never does it on its own.
+	Vacuum bool
+}
+
+type MaintenanceStep struct {
+	Name     string
+	Duration time.Duration
+}
+
+type maintenanceTask struct {
+	name string
+	run  func(context.Context) error
+}
+
+type MaintenanceResult struct {
+	Steps       []MaintenanceStep
+	SizeBefore  int64
+	SizeAfter   int64
+	WALPages    int
+	Checkpoints int
+}
+
+// MaintainContext checkpoints the WAL, refreshes query planner statistics
+// and merges the full-text index segments, which keeps searches fast as the
+// database grows.
+func (s *Storage) MaintainContext(ctx context.Context, opts MaintenanceOptions) (*MaintenanceResult, error) {
+	result := &MaintenanceResult{}
+
+	if size, err := s.DatabaseSizeContext(ctx); err == nil {
+		result.SizeBefore = size.TotalBytes
+	}
+
+	exec := func(query string) func(context.Context) error {
+		return func(ctx context.Context) error {
+			_, err := s.db.ExecContext(ctx, query)
+			return err
+		}
+	}
+
+	steps := []maintenanceTask{
+		{"optimize search index", exec("INSERT INTO events_fts(events_fts) VALUES ('optimize')")},
+		{"analyze", exec("ANALYZE")},
+	}
+	if opts.Vacuum {
+		steps = append(steps, maintenanceTask{"vacuum", exec("VACUUM")})
+	}
+	steps = append(steps, maintenanceTask{"checkpoint wal", func(ctx context.Context) error {
+		var busy int
+		return s.db.QueryRowContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)").Scan(&busy, &result.WALPages, &result.Checkpoints)
+	}})
+
+	for _, step := range steps {
+		stepCtx, cancel := context.WithTimeout(ctx, MaintenanceTimeout)
+		start := time.Now()
+		err := step.run(stepCtx)
+		cancel()
+		if err != nil {
+			return result, errors.WrapStorage(step.name, err)
+		}
+		result.Steps = append(result.Steps, MaintenanceStep{Name: step.name, Duration: time.Since(start)})
+	}
+
+	if size, err := s.DatabaseSizeContext(ctx); err == nil {
+		result.SizeAfter = size.TotalBytes
+	}
+
+	return result, nil
+}
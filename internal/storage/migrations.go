@@ -2,102 +2,101 @@ package storage
 
 import (
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// Schema migrations live in migrations/ as NNN_name.sql. The number is the
+// schema version and must follow on from the previous file; the first line
+// may be a "-- description" comment. Applied migrations are never edited:
+// a schema change is always a new file.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
 type Migration struct {
 	Version     int
+	Name        string
 	Description string
 	Up          string
 }
 
-var migrations = []Migration{
-	{
-		Version:     1,
-		Description: "Initial schema with events table",
-		Up: `
-		CREATE TABLE IF NOT EXISTS events (
-			id TEXT PRIMARY KEY,
-			timestamp TEXT NOT NULL,
-			source TEXT NOT NULL,
-			type TEXT NOT NULL,
-			repo TEXT,
-			branch TEXT,
-			payload TEXT NOT NULL,
-			created_at INTEGER NOT NULL
-		);
-
-		CREATE INDEX IF NOT EXISTS idx_timestamp ON events(timestamp);
-		CREATE INDEX IF NOT EXISTS idx_repo ON events(repo);
-		CREATE INDEX IF NOT EXISTS idx_source ON events(source);
-		CREATE INDEX IF NOT EXISTS idx_created_at ON events(created_at);
-		`,
-	},
-	{
-		Version:     2,
-		Description: "Add FTS5 full-text search and JSON1 support",
-		Up: `
-		CREATE TABLE events_new (
-			id TEXT PRIMARY KEY,
-			timestamp INTEGER NOT NULL,
-			source TEXT NOT NULL,
-			type TEXT NOT NULL,
-			repo TEXT,
-			branch TEXT,
-			payload JSON NOT NULL,
-			created_at INTEGER NOT NULL
-		);
-
-		INSERT INTO events_new
-		SELECT
-			id,
-			CAST(strftime('%s', timestamp) AS INTEGER) as timestamp,
-			source,
-			type,
-			repo,
-			branch,
-			payload,
-			created_at
-		FROM events;
-
-		DROP TABLE events;
-		ALTER TABLE events_new RENAME TO events;
-
-		CREATE INDEX idx_events_timestamp ON events(timestamp);
-		CREATE INDEX idx_events_source ON events(source);
-		CREATE INDEX idx_events_type ON events(type);
-		CREATE INDEX idx_events_repo_branch ON events(repo, branch);
-
-		CREATE VIRTUAL TABLE events_fts USING fts5(
-			id UNINDEXED,
-			source,
-			type,
-			payload,
-			content=events,
-			content_rowid=rowid,
-			tokenize='porter unicode61 remove_diacritics 2'
-		);
-
-		INSERT INTO events_fts(rowid, id, source, type, payload)
-		SELECT rowid, id, source, type, payload FROM events;
-
-		CREATE TRIGGER events_ai AFTER INSERT ON events BEGIN
-			INSERT INTO events_fts(rowid, id, source, type, payload)
-			VALUES (new.rowid, new.id, new.source, new.type, new.payload);
-		END;
-
-		CREATE TRIGGER events_ad AFTER DELETE ON events BEGIN
-			DELETE FROM events_fts WHERE rowid = old.rowid;
-		END;
-
-		CREATE TRIGGER events_au AFTER UPDATE ON events BEGIN
-			DELETE FROM events_fts WHERE rowid = old.rowid;
-			INSERT INTO events_fts(rowid, id, source, type, payload)
-			VALUES (new.rowid, new.id, new.source, new.type, new.payload);
-		END;
-		`,
-	},
+var migrations = mustLoadMigrations(migrationFiles)
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+func mustLoadMigrations(fsys fs.FS) []Migration {
+	list, err := loadMigrations(fsys)
+	if err != nil {
+		panic(err)
+	}
+	return list
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Migration
+	for _, p := range paths {
+		name := path.Base(p)
+		m := migrationFileName.FindStringSubmatch(name)
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 001_description.sql", name)
+		}
+		version, _ := strconv.Atoi(m[1])
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", name, err)
+		}
+		up := string(data)
+		if strings.TrimSpace(up) == "" {
+			return nil, fmt.Errorf("migration %s is empty", name)
+		}
+
+		description := strings.ReplaceAll(m[2], "_", " ")
+		if first, _, _ := strings.Cut(up, "\n"); strings.HasPrefix(first, "-- ") {
+			description = strings.TrimSpace(strings.TrimPrefix(first, "-- "))
+		}
+
+		list = append(list, Migration{
+			Version:     version,
+			Name:        strings.TrimSuffix(name, ".sql"),
+			Description: description,
+			Up:          up,
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	for i, m := range list {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %s: expected version %d", m.Name, i+1)
+		}
+	}
+	return list, nil
+}
+
+// Migrations returns the schema migrations compiled into this binary.
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// LatestSchemaVersion is the schema version this binary expects.
+func LatestSchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
 }
 
 func getCurrentVersion(db *sql.DB) (int, error) {
@@ -111,65 +110,162 @@ func getCurrentVersion(db *sql.DB) (int, error) {
 		return 0, fmt.Errorf("create schema_version table: %w", err)
 	}
 
-	var version int
-	err = db.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&version)
-	if err == sql.ErrNoRows || version == 0 {
-		return 0, nil
-	}
-	if err != nil {
+	var version sql.NullInt64
+	if err := db.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&version); err != nil {
 		return 0, fmt.Errorf("query version: %w", err)
 	}
 
-	return version, nil
-}
-
-func setVersion(db *sql.DB, version int) error {
-	_, err := db.Exec("INSERT INTO schema_version (version, applied_at) VALUES (?, ?)",
-		version, getCurrentTimestamp())
-	return err
+	return int(version.Int64), nil
 }
 
 type MigrationLogger interface {
 	Printf(format string, v ...interface{})
 }
 
+// RunMigrations brings the schema up to the latest version. Each migration
+// runs in its own transaction together with its schema_version row, so an
+// interrupted upgrade leaves the database at the last migration that
+// completed and the next run picks up from there.
 func RunMigrations(db *sql.DB, logger MigrationLogger) error {
+	_, err := runMigrations(db, migrations, logger)
+	return err
+}
+
+func runMigrations(db *sql.DB, list []Migration, logger MigrationLogger) ([]Migration, error) {
 	currentVersion, err := getCurrentVersion(db)
 	if err != nil {
-		return fmt.Errorf("get current version: %w", err)
+		return nil, fmt.Errorf("get current version: %w", err)
 	}
 
-	migrationRan := false
-	for _, migration := range migrations {
-		if migration.Version <= currentVersion {
-			continue
-		}
+	pending, err := pendingMigrations(list, currentVersion)
+	if err != nil {
+		return nil, err
+	}
 
-		migrationRan = true
+	var applied []Migration
+	for _, migration := range pending {
 		if logger != nil {
 			logger.Printf("Applying migration %d: %s", migration.Version, migration.Description)
 		}
 
-		if _, err := db.Exec(migration.Up); err != nil {
-			return fmt.Errorf("apply migration %d: %w", migration.Version, err)
-		}
-
-		if err := setVersion(db, migration.Version); err != nil {
-			return fmt.Errorf("record version %d: %w", migration.Version, err)
+		if err := applyMigration(db, migration); err != nil {
+			return applied, err
 		}
+		applied = append(applied, migration)
 
 		if logger != nil {
 			logger.Printf("Migration %d applied successfully", migration.Version)
 		}
 	}
 
-	if !migrationRan && logger != nil {
+	if len(applied) == 0 && logger != nil {
 		logger.Printf("Database is up to date")
 	}
 
+	return applied, nil
+}
+
+func pendingMigrations(list []Migration, currentVersion int) ([]Migration, error) {
+	if len(list) > 0 && currentVersion > list[len(list)-1].Version {
+		return nil, fmt.Errorf("database schema is at version %d but this devlog only knows up to %d; upgrade devlog",
+			currentVersion, list[len(list)-1].Version)
+	}
+
+	var pending []Migration
+	for _, m := range list {
+		if m.Version > currentVersion {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+func applyMigration(db *sql.DB, migration Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(migration.Up); err != nil {
+		return fmt.Errorf("apply migration %d: %w", migration.Version, err)
+	}
+
+	if _, err := tx.Exec("INSERT INTO schema_version (version, applied_at) VALUES (?, ?)",
+		migration.Version, getCurrentTimestamp()); err != nil {
+		return fmt.Errorf("record version %d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %d: %w", migration.Version, err)
+	}
 	return nil
 }
 
+type MigrationState struct {
+	Migration
+	AppliedAt time.Time // zero while the migration is pending
+}
+
+type SchemaStatus struct {
+	Current    int
+	Latest     int
+	Migrations []MigrationState
+}
+
+func (s *SchemaStatus) Pending() []MigrationState {
+	var pending []MigrationState
+	for _, m := range s.Migrations {
+		if m.AppliedAt.IsZero() {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+func readSchemaStatus(db *sql.DB, list []Migration) (*SchemaStatus, error) {
+	current, err := getCurrentVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, fmt.Errorf("query schema versions: %w", err)
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int]int64)
+	for rows.Next() {
+		var version int
+		var at int64
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("scan schema version: %w", err)
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate schema versions: %w", err)
+	}
+
+	status := &SchemaStatus{Current: current}
+	if len(list) > 0 {
+		status.Latest = list[len(list)-1].Version
+	}
+	for _, m := range list {
+		state := MigrationState{Migration: m}
+		if at, ok := appliedAt[m.Version]; ok {
+			state.AppliedAt = time.Unix(at, 0)
+		} else if m.Version <= current {
+			// A missing row below the current version still counts as
+			// applied; only the newest one matters for the upgrade path.
+			state.AppliedAt = time.Unix(appliedAt[current], 0)
+		}
+		status.Migrations = append(status.Migrations, state)
+	}
+	return status, nil
+}
+
 func getCurrentTimestamp() int64 {
 	return time.Now().Unix()
 }
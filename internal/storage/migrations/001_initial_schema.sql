@@ -0,0 +1,17 @@
+-- Initial schema with events table
+
+CREATE TABLE IF NOT EXISTS events (
+	id TEXT PRIMARY KEY,
+	timestamp TEXT NOT NULL,
+	source TEXT NOT NULL,
+	type TEXT NOT NULL,
+	repo TEXT,
+	branch TEXT,
+	payload TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_timestamp ON events(timestamp);
+CREATE INDEX IF NOT EXISTS idx_repo ON events(repo);
+CREATE INDEX IF NOT EXISTS idx_source ON events(source);
+CREATE INDEX IF NOT EXISTS idx_created_at ON events(created_at);
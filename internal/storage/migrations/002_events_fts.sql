@@ -0,0 +1,60 @@
+-- Add FTS5 full-text search and JSON1 support
+
+CREATE TABLE events_new (
+	id TEXT PRIMARY KEY,
+	timestamp INTEGER NOT NULL,
+	source TEXT NOT NULL,
+	type TEXT NOT NULL,
+	repo TEXT,
+	branch TEXT,
+	payload JSON NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+INSERT INTO events_new
+SELECT
+	id,
+	CAST(strftime('%s', timestamp) AS INTEGER) as timestamp,
+	source,
+	type,
+	repo,
+	branch,
+	payload,
+	created_at
+FROM events;
+
+DROP TABLE events;
+ALTER TABLE events_new RENAME TO events;
+
+CREATE INDEX idx_events_timestamp ON events(timestamp);
+CREATE INDEX idx_events_source ON events(source);
+CREATE INDEX idx_events_type ON events(type);
+CREATE INDEX idx_events_repo_branch ON events(repo, branch);
+
+CREATE VIRTUAL TABLE events_fts USING fts5(
+	id UNINDEXED,
+	source,
+	type,
+	payload,
+	content=events,
+	content_rowid=rowid,
+	tokenize='porter unicode61 remove_diacritics 2'
+);
+
+INSERT INTO events_fts(rowid, id, source, type, payload)
+SELECT rowid, id, source, type, payload FROM events;
+
+CREATE TRIGGER events_ai AFTER INSERT ON events BEGIN
+	INSERT INTO events_fts(rowid, id, source, type, payload)
+	VALUES (new.rowid, new.id, new.source, new.type, new.payload);
+END;
+
+CREATE TRIGGER events_ad AFTER DELETE ON events BEGIN
+	DELETE FROM events_fts WHERE rowid = old.rowid;
+END;
+
+CREATE TRIGGER events_au AFTER UPDATE ON events BEGIN
+	DELETE FROM events_fts WHERE rowid = old.rowid;
+	INSERT INTO events_fts(rowid, id, source, type, payload)
+	VALUES (new.rowid, new.id, new.source, new.type, new.payload);
+END;
@@ -0,0 +1,18 @@
+-- Add summaries table
+
+CREATE TABLE IF NOT EXISTS summaries (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	period_start INTEGER NOT NULL,
+	period_end INTEGER NOT NULL,
+	context_start INTEGER NOT NULL,
+	repos JSON NOT NULL DEFAULT '[]',
+	summary TEXT NOT NULL,
+	event_count INTEGER NOT NULL DEFAULT 0,
+	context_event_count INTEGER NOT NULL DEFAULT 0,
+	provider TEXT,
+	input_tokens INTEGER NOT NULL DEFAULT 0,
+	output_tokens INTEGER NOT NULL DEFAULT 0,
+	created_at INTEGER NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_summaries_period ON summaries(period_start, period_end);
@@ -0,0 +1,29 @@
+-- Add FTS5 full-text search over summaries
+
+CREATE VIRTUAL TABLE summaries_fts USING fts5(
+	summary,
+	repos,
+	content=summaries,
+	content_rowid=id,
+	tokenize='porter unicode61 remove_diacritics 2'
+);
+
+INSERT INTO summaries_fts(rowid, summary, repos)
+SELECT id, summary, repos FROM summaries;
+
+CREATE TRIGGER summaries_ai AFTER INSERT ON summaries BEGIN
+	INSERT INTO summaries_fts(rowid, summary, repos)
+	VALUES (new.id, new.summary, new.repos);
+END;
+
+CREATE TRIGGER summaries_ad AFTER DELETE ON summaries BEGIN
+	INSERT INTO summaries_fts(summaries_fts, rowid, summary, repos)
+	VALUES ('delete', old.id, old.summary, old.repos);
+END;
+
+CREATE TRIGGER summaries_au AFTER UPDATE ON summaries BEGIN
+	INSERT INTO summaries_fts(summaries_fts, rowid, summary, repos)
+	VALUES ('delete', old.id, old.summary, old.repos);
+	INSERT INTO summaries_fts(rowid, summary, repos)
+	VALUES (new.id, new.summary, new.repos);
+END;
@@ -0,0 +1,14 @@
+-- Track summarized windows
+
+CREATE TABLE IF NOT EXISTS summary_windows (
+	period_start INTEGER NOT NULL,
+	period_end INTEGER NOT NULL,
+	event_count INTEGER NOT NULL DEFAULT 0,
+	created_at INTEGER NOT NULL,
+	PRIMARY KEY (period_start, period_end)
+);
+
+CREATE INDEX IF NOT EXISTS idx_summary_windows_end ON summary_windows(period_end);
+
+INSERT OR IGNORE INTO summary_windows (period_start, period_end, event_count, created_at)
+SELECT period_start, period_end, event_count, created_at FROM summaries;
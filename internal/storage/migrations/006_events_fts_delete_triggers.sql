@@ -0,0 +1,18 @@
+-- Use FTS5 delete command in events triggers
+
+DROP TRIGGER IF EXISTS events_ad;
+DROP TRIGGER IF EXISTS events_au;
+
+CREATE TRIGGER events_ad AFTER DELETE ON events BEGIN
+	INSERT INTO events_fts(events_fts, rowid, id, source, type, payload)
+	VALUES ('delete', old.rowid, old.id, old.source, old.type, old.payload);
+END;
+
+CREATE TRIGGER events_au AFTER UPDATE ON events BEGIN
+	INSERT INTO events_fts(events_fts, rowid, id, source, type, payload)
+	VALUES ('delete', old.rowid, old.id, old.source, old.type, old.payload);
+	INSERT INTO events_fts(rowid, id, source, type, payload)
+	VALUES (new.rowid, new.id, new.source, new.type, new.payload);
+END;
+
+INSERT INTO events_fts(events_fts) VALUES ('rebuild');
@@ -0,0 +1,4 @@
+-- Add payload version to events
+
+ALTER TABLE events ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_source_version ON events(source, version);
@@ -0,0 +1,14 @@
+-- Add LLM usage table
+
+CREATE TABLE IF NOT EXISTS llm_usage (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	timestamp INTEGER NOT NULL,
+	caller TEXT NOT NULL,
+	provider TEXT NOT NULL,
+	model TEXT,
+	input_tokens INTEGER NOT NULL DEFAULT 0,
+	output_tokens INTEGER NOT NULL DEFAULT 0,
+	cost_usd REAL NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_llm_usage_timestamp ON llm_usage(timestamp);
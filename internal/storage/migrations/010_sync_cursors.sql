@@ -0,0 +1,12 @@
+-- Add sync cursors and imported event tracking
+
+CREATE TABLE IF NOT EXISTS sync_cursors (
+	name TEXT PRIMARY KEY,
+	value INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sync_imports (
+	event_id TEXT PRIMARY KEY,
+	machine TEXT NOT NULL,
+	imported_at INTEGER NOT NULL
+);
@@ -0,0 +1,17 @@
+-- Add duration, session, parent and severity columns to events
+
+ALTER TABLE events ADD COLUMN duration_ms INTEGER;
+ALTER TABLE events ADD COLUMN session_id TEXT;
+ALTER TABLE events ADD COLUMN parent_id TEXT;
+ALTER TABLE events ADD COLUMN severity TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_events_session_id ON events(session_id) WHERE session_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_events_parent_id ON events(parent_id) WHERE parent_id IS NOT NULL;
+
+UPDATE events
+SET duration_ms = CAST(json_extract(payload, '$.duration_ms') AS INTEGER)
+WHERE json_valid(payload) AND json_extract(payload, '$.duration_ms') > 0;
+
+UPDATE events
+SET session_id = json_extract(payload, '$.session_id')
+WHERE source = 'claude' AND json_valid(payload) AND json_extract(payload, '$.session_id') != '';
@@ -0,0 +1,14 @@
+-- Add annotations attached to events after the fact
+
+CREATE TABLE IF NOT EXISTS event_annotations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id TEXT NOT NULL,
+	text TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_annotations_event_id ON event_annotations(event_id);
+
+CREATE TRIGGER IF NOT EXISTS event_annotations_ad AFTER DELETE ON events BEGIN
+	DELETE FROM event_annotations WHERE event_id = old.id;
+END;
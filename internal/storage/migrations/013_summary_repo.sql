@@ -0,0 +1,6 @@
+-- Add per-repo summaries
+
+ALTER TABLE summaries ADD COLUMN repo TEXT NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_summaries_period;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_summaries_period ON summaries(period_start, period_end, repo);
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	if len(migrations) == 0 {
+		t.Fatal("no embedded migrations")
+	}
+	if migrations[0].Description != "Initial schema with events table" {
+		t.Errorf("description = %q, want the header comment", migrations[0].Description)
+	}
+
+	bad := map[string]fstest.MapFS{
+		"gap": {
+			"migrations/001_a.sql": {Data: []byte("SELECT 1;")},
+			"migrations/003_c.sql": {Data: []byte("SELECT 1;")},
+		},
+		"duplicate": {
+			"migrations/001_a.sql":  {Data: []byte("SELECT 1;")},
+			"migrations/0001_b.sql": {Data: []byte("SELECT 1;")},
+		},
+		"bad name": {
+			"migrations/first.sql": {Data: []byte("SELECT 1;")},
+		},
+		"empty": {
+			"migrations/001_a.sql": {Data: []byte("\n")},
+		},
+	}
+	for name, fsys := range bad {
+		if _, err := loadMigrations(fsys); err == nil {
+			t.Errorf("%s: loadMigrations() should fail", name)
+		}
+	}
+
+	list, err := loadMigrations(fstest.MapFS{
+		"migrations/002_add_notes.sql": {Data: []byte("CREATE TABLE notes (id INTEGER);")},
+		"migrations/001_init.sql":      {Data: []byte("-- Create things\nCREATE TABLE things (id INTEGER);")},
+	})
+	if err != nil {
+		t.Fatalf("loadMigrations() error: %v", err)
+	}
+	if list[0].Description != "Create things" || list[1].Description != "add notes" {
+		t.Errorf("descriptions = %q, %q", list[0].Description, list[1].Description)
+	}
+}
+
+func openTestDB(t *testing.T) (*sql.DB, string) {
+	t.Helper()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+	db, err := openDB(dbPath)
+	if err != nil {
+		t.Fatalf("openDB() error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, dbPath
+}
+
+func TestRunMigrationsRollsBackFailedStep(t *testing.T) {
+	db, _ := openTestDB(t)
+	list := []Migration{
+		{Version: 1, Up: "CREATE TABLE things (id INTEGER);"},
+		{Version: 2, Up: "CREATE TABLE notes (id INTEGER); INSERT INTO missing VALUES (1);"},
+	}
+
+	applied, err := runMigrations(db, list, nil)
+	if err == nil {
+		t.Fatal("runMigrations() should fail on the broken migration")
+	}
+	if len(applied) != 1 {
+		t.Fatalf("applied %d migrations, want 1", len(applied))
+	}
+
+	if v, _ := getCurrentVersion(db); v != 1 {
+		t.Errorf("version = %d, want 1", v)
+	}
+	var n int
+	db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'notes'").Scan(&n)
+	if n != 0 {
+		t.Error("failed migration left the notes table behind")
+	}
+
+	list[1].Up = "CREATE TABLE notes (id INTEGER);"
+	applied, err = runMigrations(db, list, nil)
+	if err != nil {
+		t.Fatalf("runMigrations() retry error: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 {
+		t.Errorf("retry applied %+v, want only version 2", applied)
+	}
+}
+
+func TestRunMigrationsRefusesNewerSchema(t *testing.T) {
+	db, _ := openTestDB(t)
+	if err := RunMigrations(db, nil); err != nil {
+		t.Fatalf("RunMigrations() error: %v", err)
+	}
+	if _, err := db.Exec("INSERT INTO schema_version (version, applied_at) VALUES (?, 0)", LatestSchemaVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+
+	err := RunMigrations(db, nil)
+	if err == nil || !strings.Contains(err.Error(), "upgrade devlog") {
+		t.Errorf("RunMigrations() error = %v, want a newer-schema error", err)
+	}
+}
+
+func TestMigrateExistingDatabase(t *testing.T) {
+	db, dbPath := openTestDB(t)
+	if _, err := runMigrations(db, migrations[:5], nil); err != nil {
+		t.Fatalf("runMigrations() error: %v", err)
+	}
+	db.Close()
+
+	status, err := MigrationStatus(dbPath)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error: %v", err)
+	}
+	if status.Current != 5 || status.Latest != LatestSchemaVersion() {
+		t.Errorf("status = %d/%d, want 5/%d", status.Current, status.Latest, LatestSchemaVersion())
+	}
+	if got, want := len(status.Pending()), LatestSchemaVersion()-5; got != want {
+		t.Errorf("pending = %d, want %d", got, want)
+	}
+
+	result, err := Migrate(dbPath, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("Migrate() error: %v", err)
+	}
+	if result.From != 5 || result.To != LatestSchemaVersion() {
+		t.Errorf("migrated %d -> %d, want 5 -> %d", result.From, result.To, LatestSchemaVersion())
+	}
+	if result.Backup != dbPath+".v5.bak" {
+		t.Errorf("backup = %q", result.Backup)
+	}
+	if _, err := os.Stat(result.Backup); err != nil {
+		t.Errorf("backup not written: %v", err)
+	}
+
+	result, err = Migrate(dbPath, MigrateOptions{})
+	if err != nil {
+		t.Fatalf("second Migrate() error: %v", err)
+	}
+	if len(result.Applied) != 0 || result.Backup != "" {
+		t.Errorf("second Migrate() = %+v, want a no-op", result)
+	}
+
+	status, err = MigrationStatus(dbPath)
+	if err != nil {
+		t.Fatalf("MigrationStatus() error: %v", err)
+	}
+	if len(status.Pending()) != 0 {
+		t.Errorf("pending after migrate = %+v", status.Pending())
+	}
+}
@@ -0,0 +1,1003 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"devlog/internal/errors"
+	"devlog/internal/events"
+)
+
+// postgresDriver is the database/sql driver the Postgres backend opens. It is
+// registered by postgres_driver.go, which only builds with -tags postgres.
+const postgresDriver = "pgx"
+
+// postgresMigrationLock is the advisory lock key that serializes schema
+// migrations when several machines start against the same database.
+const postgresMigrationLock = 0x6465766c6f67
+
+var ErrPostgresUnavailable = fmt.Errorf("this devlog was built without Postgres support (rebuild with -tags postgres)")
+
+// The Postgres schema has its own migrations under postgres/migrations/,
+// numbered and described the same way as the SQLite ones.
+//
+//go:embed postgres/migrations/*.sql
+var postgresMigrationFiles embed.FS
+
+var postgresMigrations = func() []Migration {
+	sub, err := fs.Sub(postgresMigrationFiles, "postgres")
+	if err != nil {
+		panic(err)
+	}
+	return mustLoadMigrations(sub)
+}()
+
+// PostgresStore keeps events and summaries in a Postgres database that
+// several machines can share. Full-text search uses tsvector columns in
+// place of SQLite's FTS5 tables. Payload encryption and the query cache are
+// SQLite-only.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgres(dsn string) (*PostgresStore, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("postgres storage needs a dsn")
+	}
+	if !slices.Contains(sql.Drivers(), postgresDriver) {
+		return nil, ErrPostgresUnavailable
+	}
+
+	db, err := sql.Open(postgresDriver, dsn)
+	if err != nil {
+		return nil, errors.WrapStorage("open postgres", err)
+	}
+	db.SetMaxOpenConns(DefaultMaxOpenConns)
+	db.SetMaxIdleConns(DefaultMaxIdleConns)
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultQueryTimeoutLong)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, errors.WrapStorage("connect to postgres", err)
+	}
+	if err := migratePostgres(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+func (p *PostgresStore) Close() error {
+	return p.db.Close()
+}
+
+func migratePostgres(ctx context.Context, db *sql.DB) error {
+	for _, migration := range postgresMigrations {
+		if err := applyPostgresMigration(ctx, db, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPostgresMigration applies one migration unless another process got
+// there first. The advisory lock is held until the transaction ends.
+func applyPostgresMigration(ctx context.Context, db *sql.DB, migration Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", postgresMigrationLock); err != nil {
+		return fmt.Errorf("lock schema: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			applied_at BIGINT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create schema_version table: %w", err)
+	}
+
+	var current sql.NullInt64
+	if err := tx.QueryRowContext(ctx, "SELECT MAX(version) FROM schema_version").Scan(&current); err != nil {
+		return fmt.Errorf("query version: %w", err)
+	}
+	if _, err := pendingMigrations(postgresMigrations, int(current.Int64)); err != nil {
+		return err
+	}
+	if int(current.Int64) >= migration.Version {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("apply migration %d: %w", migration.Version, err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_version (version, applied_at) VALUES ($1, $2)",
+		migration.Version, getCurrentTimestamp()); err != nil {
+		return fmt.Errorf("record version %d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// pgArgs collects the arguments of a query built up clause by clause and
+// hands out their $n placeholders.
+type pgArgs []interface{}
+
+func (a *pgArgs) add(v interface{}) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+func (a *pgArgs) list(values []string) string {
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholders[i] = a.add(v)
+	}
+	return strings.Join(placeholders, ",")
+}
+
+// pgJSONPath converts a SQLite JSON path such as $.files[0].name into the
+// text[] literal the #>> operator takes: {"files","0","name"}.
+func pgJSONPath(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return "", fmt.Errorf("invalid json path %q: must start with $", path)
+	}
+	rest = strings.NewReplacer("[", ".", "]", "").Replace(rest)
+
+	var elems []string
+	for _, key := range strings.Split(rest, ".") {
+		if key == "" {
+			continue
+		}
+		key = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key)
+		elems = append(elems, `"`+key+`"`)
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// pgTSQuery adapts a sanitized FTS5 query for websearch_to_tsquery, which
+// already reads quoted phrases and ANDs bare words but has no prefix match.
+func pgTSQuery(ftsQuery string) string {
+	return strings.TrimSpace(strings.ReplaceAll(ftsQuery, "*", ""))
+}
+
+// rankScanner scans a trailing rank column after an event's columns.
+type rankScanner struct {
+	scanner interface {
+		Scan(dest ...interface{}) error
+	}
+	rank *float64
+}
+
+func (r rankScanner) Scan(dest ...interface{}) error {
+	return r.scanner.Scan(append(dest, r.rank)...)
+}
+
+const pgEventColumns = `id, timestamp, source, type, repo, branch, payload, version,
+	duration_ms, session_id, parent_id, severity`
+
+const pgInsertEventQuery = `
+	INSERT INTO events (id, timestamp, source, type, repo, branch, payload, version,
+		duration_ms, session_id, parent_id, severity, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	ON CONFLICT (id) DO NOTHING
+`
+
+func (p *PostgresStore) InsertEvent(event *events.Event) error {
+	return p.InsertEventContext(context.Background(), event)
+}
+
+func (p *PostgresStore) InsertEventContext(ctx context.Context, event *events.Event) error {
+	args, err := eventInsertArgs(event, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	result, err := p.db.ExecContext(ctx, pgInsertEventQuery, args...)
+	if err != nil {
+		return errors.WrapStorage("insert event", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrDuplicateEvent
+	}
+	return nil
+}
+
+// InsertEventsContext stores events in a single transaction, with the same
+// per-event results as (*Storage).InsertEventsContext.
+func (p *PostgresStore) InsertEventsContext(ctx context.Context, evts []*events.Event) ([]error, error) {
+	results := make([]error, len(evts))
+	if len(evts) == 0 {
+		return results, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pgInsertEventQuery)
+	if err != nil {
+		return nil, errors.WrapStorage("prepare insert", err)
+	}
+	defer stmt.Close()
+
+	for i, event := range evts {
+		args, err := eventInsertArgs(event, nil)
+		if err != nil {
+			results[i] = err
+			continue
+		}
+		result, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return nil, errors.WrapStorage("insert event", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			results[i] = ErrDuplicateEvent
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.WrapStorage("commit transaction", err)
+	}
+	return results, nil
+}
+
+func (p *PostgresStore) GetEventContext(ctx context.Context, id string) (*events.Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	query := "SELECT " + pgEventColumns + " FROM events WHERE id = $1"
+	event, err := scanEvent(nil, p.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrEventNotFound, id)
+	}
+	if err != nil {
+		return nil, errors.WrapStorage("query event", err)
+	}
+	return event, nil
+}
+
+func (p *PostgresStore) QueryEventsContext(ctx context.Context, opts QueryOptions) ([]*events.Event, error) {
+	var args pgArgs
+	query := "SELECT " + pgEventColumns + " FROM events WHERE 1=1"
+
+	if opts.StartTime != nil {
+		query += " AND timestamp >= " + args.add(opts.StartTime.Unix())
+	}
+	if opts.EndTime != nil {
+		query += " AND timestamp < " + args.add(opts.EndTime.Unix())
+	}
+	if opts.Source != "" {
+		query += " AND source = " + args.add(opts.Source)
+	}
+	if opts.RepoPattern != "" {
+		query += " AND repo ILIKE " + args.add(containsPattern(opts.RepoPattern)) + ` ESCAPE '\'`
+	}
+	if opts.SessionID != "" {
+		query += " AND session_id = " + args.add(opts.SessionID)
+	}
+	if opts.ParentID != "" {
+		query += " AND parent_id = " + args.add(opts.ParentID)
+	}
+	if opts.Severity != "" {
+		query += " AND severity = " + args.add(opts.Severity)
+	}
+
+	if opts.Cursor != "" {
+		ts, id, err := decodeEventCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		op := "<"
+		if opts.Ascending {
+			op = ">"
+		}
+		query += fmt.Sprintf(" AND (timestamp, id) %s (%s, %s)", op, args.add(ts), args.add(id))
+	}
+
+	if opts.Ascending {
+		query += " ORDER BY timestamp ASC, id ASC"
+	} else {
+		query += " ORDER BY timestamp DESC, id DESC"
+	}
+	if opts.Limit > 0 {
+		query += " LIMIT " + args.add(opts.Limit)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	return p.queryEvents(ctx, "query events", query, args...)
+}
+
+func (p *PostgresStore) queryEvents(ctx context.Context, op, query string, args ...interface{}) ([]*events.Event, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.WrapStorage(op, err)
+	}
+	defer rows.Close()
+
+	var result []*events.Event
+	for rows.Next() {
+		event, err := scanEvent(nil, rows)
+		if err != nil {
+			return nil, errors.WrapStorage("scan event", err)
+		}
+		result = append(result, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapStorage("iterate events", err)
+	}
+	return result, nil
+}
+
+func (p *PostgresStore) RelatedEventsContext(ctx context.Context, id string) ([]*events.Event, error) {
+	event, err := p.GetEventContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + pgEventColumns + ` FROM events
+		WHERE id != $1 AND (parent_id = $1 OR id = $2 OR ($3 != '' AND session_id = $3))
+		ORDER BY timestamp ASC, id ASC
+		LIMIT $4`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	return p.queryEvents(ctx, "query related events", query, id, event.ParentID, event.SessionID, MaxRelatedEvents)
+}
+
+// EventsAfterRowContext pages through events in insertion order. Row IDs
+// come from a sequence, so an event committed late by a concurrent writer can
+// land behind a position a reader has already passed.
+func (p *PostgresStore) EventsAfterRowContext(ctx context.Context, afterRow int64, limit int) ([]*events.Event, int64, error) {
+	query := "SELECT row_id, " + pgEventColumns + " FROM events WHERE row_id > $1 ORDER BY row_id ASC LIMIT $2"
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, query, afterRow, limit)
+	if err != nil {
+		return nil, afterRow, errors.WrapStorage("query events after row", err)
+	}
+	defer rows.Close()
+
+	lastRow := afterRow
+	var result []*events.Event
+	for rows.Next() {
+		var rowid int64
+		event, err := scanEvent(nil, rowidScanner{scanner: rows, rowid: &rowid})
+		if err != nil {
+			return nil, afterRow, errors.WrapStorage("scan event", err)
+		}
+		lastRow = rowid
+		result = append(result, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, afterRow, errors.WrapStorage("iterate events", err)
+	}
+	return result, lastRow, nil
+}
+
+func (p *PostgresStore) MaxEventRowContext(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var rowid sql.NullInt64
+	if err := p.db.QueryRowContext(ctx, "SELECT MAX(row_id) FROM events").Scan(&rowid); err != nil {
+		return 0, errors.WrapStorage("query max event row", err)
+	}
+	return rowid.Int64, nil
+}
+
+func (p *PostgresStore) Count() (int, error) {
+	return p.CountContext(context.Background())
+}
+
+func (p *PostgresStore) CountContext(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var count int
+	if err := p.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM events").Scan(&count); err != nil {
+		return 0, fmt.Errorf("count events: %w", err)
+	}
+	return count, nil
+}
+
+func (p *PostgresStore) Search(ctx context.Context, opts SearchOptions) ([]*SearchResult, error) {
+	return runSearch(ctx, p, opts)
+}
+
+func (p *PostgresStore) searchEvents(ctx context.Context, opts SearchOptions, ftsQuery string, hasFTSQuery bool, limit, offset int) ([]*SearchResult, error) {
+	var args pgArgs
+	selectFields := "e.id, e.timestamp, e.source, e.type, e.repo, e.branch, e.payload, e.version, " +
+		"e.duration_ms, e.session_id, e.parent_id, e.severity"
+	fromClause := "FROM events e"
+	var whereClauses []string
+
+	if hasFTSQuery {
+		// ts_rank grows with relevance; negating it sorts like FTS5's rank.
+		selectFields += ", -ts_rank(e.search_vector, q) AS rank"
+		fromClause += ", websearch_to_tsquery('english', " + args.add(pgTSQuery(ftsQuery)) + ") q"
+		whereClauses = append(whereClauses, "e.search_vector @@ q")
+	}
+	if opts.After != nil {
+		whereClauses = append(whereClauses, "e.timestamp >= "+args.add(opts.After.Unix()))
+	}
+	if opts.Before != nil {
+		whereClauses = append(whereClauses, "e.timestamp < "+args.add(opts.Before.Unix()))
+	}
+	if len(opts.Modules) > 0 {
+		whereClauses = append(whereClauses, "e.source IN ("+args.list(opts.Modules)+")")
+	}
+	if len(opts.Types) > 0 {
+		whereClauses = append(whereClauses, "e.type IN ("+args.list(opts.Types)+")")
+	}
+	if opts.RepoPattern != "" {
+		whereClauses = append(whereClauses, "e.repo ILIKE "+args.add(containsPattern(opts.RepoPattern))+` ESCAPE '\'`)
+	}
+	if opts.BranchPattern != "" {
+		whereClauses = append(whereClauses, "e.branch ILIKE "+args.add(containsPattern(opts.BranchPattern))+` ESCAPE '\'`)
+	}
+	if opts.PayloadFilter != nil {
+		path, err := pgJSONPath(opts.PayloadFilter.JSONPath)
+		if err != nil {
+			return nil, err
+		}
+		whereClauses = append(whereClauses, fmt.Sprintf("e.payload::jsonb #>> %s::text[] = %s",
+			args.add(path), args.add(opts.PayloadFilter.Value)))
+	}
+
+	whereClause := ""
+	if len(whereClauses) > 0 {
+		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	orderClause := ""
+	switch opts.SortOrder {
+	case SortByRelevance:
+		if hasFTSQuery {
+			orderClause = "ORDER BY rank"
+		} else {
+			orderClause = "ORDER BY e.timestamp DESC"
+		}
+	case SortByTimeDesc:
+		orderClause = "ORDER BY e.timestamp DESC"
+	case SortByTimeAsc:
+		orderClause = "ORDER BY e.timestamp ASC"
+	}
+
+	sqlQuery := fmt.Sprintf("SELECT %s %s %s %s LIMIT %d OFFSET %d",
+		selectFields, fromClause, whereClause, orderClause, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search events: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		var rank float64
+		var scanner interface {
+			Scan(dest ...interface{}) error
+		} = rows
+		if hasFTSQuery {
+			scanner = rankScanner{scanner: rows, rank: &rank}
+		}
+		event, err := scanEvent(nil, scanner)
+		if err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		results = append(results, &SearchResult{Event: event, Rank: rank})
+	}
+	return results, rows.Err()
+}
+
+func (p *PostgresStore) searchSummaries(ctx context.Context, opts SearchOptions, ftsQuery string, hasFTSQuery bool, limit, offset int) ([]*SearchResult, error) {
+	var args pgArgs
+	selectFields := `s.id, s.period_start, s.period_end, s.context_start, s.repos, s.summary,
+		s.event_count, s.context_event_count, COALESCE(s.provider, ''), s.input_tokens, s.output_tokens, s.created_at, s.repo`
+	fromClause := "FROM summaries s"
+	var whereClauses []string
+
+	if hasFTSQuery {
+		selectFields += ", -ts_rank(s.search_vector, q) AS rank"
+		fromClause += ", websearch_to_tsquery('english', " + args.add(pgTSQuery(ftsQuery)) + ") q"
+		whereClauses = append(whereClauses, "s.search_vector @@ q")
+	}
+	if opts.After != nil {
+		whereClauses = append(whereClauses, "s.period_end >= "+args.add(opts.After.Unix()))
+	}
+	if opts.Before != nil {
+		whereClauses = append(whereClauses, "s.period_start < "+args.add(opts.Before.Unix()))
+	}
+	if opts.RepoPattern != "" {
+		whereClauses = append(whereClauses, "s.repos ILIKE "+args.add(containsPattern(opts.RepoPattern))+` ESCAPE '\'`)
+	}
+
+	whereClause := ""
+	if len(whereClauses) > 0 {
+		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	orderClause := "ORDER BY s.period_start DESC"
+	switch {
+	case opts.SortOrder == SortByRelevance && hasFTSQuery:
+		orderClause = "ORDER BY rank"
+	case opts.SortOrder == SortByTimeAsc:
+		orderClause = "ORDER BY s.period_start ASC"
+	}
+
+	sqlQuery := fmt.Sprintf("SELECT %s %s %s %s LIMIT %d OFFSET %d",
+		selectFields, fromClause, whereClause, orderClause, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		var rank float64
+		var extra []interface{}
+		if hasFTSQuery {
+			extra = append(extra, &rank)
+		}
+		summary, err := scanSummary(rows, extra...)
+		if err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		results = append(results, &SearchResult{Summary: summary, Rank: rank})
+	}
+	return results, rows.Err()
+}
+
+func (p *PostgresStore) CountBySource(ctx context.Context) ([]SourceCount, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT source, COUNT(*) AS count
+		FROM events
+		GROUP BY source
+		ORDER BY count DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query sources: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SourceCount
+	for rows.Next() {
+		var sc SourceCount
+		if err := rows.Scan(&sc.Source, &sc.Count); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		results = append(results, sc)
+	}
+	return results, rows.Err()
+}
+
+func (p *PostgresStore) TimelineBuckets(ctx context.Context, start, end time.Time, bucket string) ([]TimelinePoint, error) {
+	if !ValidBucket(bucket) {
+		return nil, fmt.Errorf("invalid bucket %q", bucket)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("timeline end must be after start")
+	}
+
+	points, index, err := timelineGrid(start, end, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT (timestamp / $1) * $1 AS slot, source, COUNT(*)
+		FROM events
+		WHERE timestamp >= $2 AND timestamp < $3
+		GROUP BY slot, source
+		ORDER BY slot ASC
+	`, timelineSlotWidth(bucket), start.Unix(), end.Add(time.Second-1).Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query timeline: %w", err)
+	}
+	defer rows.Close()
+
+	if err := foldTimelineRows(rows, points, index, start.Location(), bucket); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+func (p *PostgresStore) ActivityDistribution(ctx context.Context, start, end time.Time) ([7][24]int, error) {
+	var grid [7][24]int
+	if !end.After(start) {
+		return grid, fmt.Errorf("distribution end must be after start")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT (timestamp / 3600) * 3600 AS slot, COUNT(*)
+		FROM events
+		WHERE timestamp >= $1 AND timestamp < $2
+		GROUP BY slot
+	`, start.Unix(), end.Add(time.Second-1).Unix())
+	if err != nil {
+		return grid, fmt.Errorf("query distribution: %w", err)
+	}
+	defer rows.Close()
+
+	loc := start.Location()
+	for rows.Next() {
+		var slot int64
+		var count int
+		if err := rows.Scan(&slot, &count); err != nil {
+			return grid, fmt.Errorf("scan row: %w", err)
+		}
+		t := time.Unix(slot, 0).In(loc)
+		grid[t.Weekday()][t.Hour()] += count
+	}
+	return grid, rows.Err()
+}
+
+func (p *PostgresStore) ActivityBucketsContext(ctx context.Context, start, end time.Time) ([]ActivityBucket, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT (timestamp / 3600) * 3600 AS hour, source, type, COALESCE(repo, '') AS repo, COUNT(*)
+		FROM events
+		WHERE timestamp >= $1 AND timestamp < $2
+		GROUP BY 1, 2, 3, 4
+		ORDER BY hour ASC
+	`, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query activity: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ActivityBucket
+	for rows.Next() {
+		var b ActivityBucket
+		var hour int64
+		if err := rows.Scan(&hour, &b.Source, &b.Type, &b.Repo, &b.Count); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		b.Hour = time.Unix(hour, 0)
+		results = append(results, b)
+	}
+	return results, rows.Err()
+}
+
+func (p *PostgresStore) TopRepos(ctx context.Context, limit int) ([]RepoStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT repo, COUNT(*) AS count
+		FROM events
+		WHERE repo IS NOT NULL AND repo != ''
+		GROUP BY repo
+		ORDER BY count DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query repos: %w", err)
+	}
+	defer rows.Close()
+
+	var results []RepoStats
+	for rows.Next() {
+		var rs RepoStats
+		if err := rows.Scan(&rs.Repo, &rs.Count); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		results = append(results, rs)
+	}
+	return results, rows.Err()
+}
+
+func (p *PostgresStore) TopCommands(ctx context.Context, limit int) ([]CommandStats, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT payload::jsonb ->> 'command' AS command, COUNT(*) AS count
+		FROM events
+		WHERE source = 'shell' AND type = 'command' AND payload::jsonb ->> 'command' IS NOT NULL
+		GROUP BY command
+		ORDER BY count DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query commands: %w", err)
+	}
+	defer rows.Close()
+
+	var results []CommandStats
+	for rows.Next() {
+		var cs CommandStats
+		if err := rows.Scan(&cs.Command, &cs.Count); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		results = append(results, cs)
+	}
+	return results, rows.Err()
+}
+
+func (p *PostgresStore) fileTouches(ctx context.Context, start, end time.Time) ([]fileTouch, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, "SELECT "+pgEventColumns+` FROM events
+		WHERE timestamp >= $1 AND timestamp < $2
+		AND ((source = 'git' AND type = 'commit')
+			OR (source = 'shell' AND type = 'command')
+			OR (source = 'claude' AND type = 'file_edit'))
+	`, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query file events: %w", err)
+	}
+	defer rows.Close()
+
+	return scanFileTouches(rows, nil)
+}
+
+func (p *PostgresStore) TopFiles(ctx context.Context, start, end time.Time, limit int) ([]FileStats, error) {
+	touches, err := p.fileTouches(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return countFiles(touches, limit), nil
+}
+
+func (p *PostgresStore) TopLanguages(ctx context.Context, start, end time.Time, limit int) ([]LanguageStats, error) {
+	touches, err := p.fileTouches(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return countLanguages(touches, limit), nil
+}
+
+func (p *PostgresStore) AddAnnotationContext(ctx context.Context, eventID, text string) (*Annotation, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.NewValidation("text", "must not be empty")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var exists int
+	err := p.db.QueryRowContext(ctx, "SELECT 1 FROM events WHERE id = $1", eventID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrEventNotFound, eventID)
+	}
+	if err != nil {
+		return nil, errors.WrapStorage("query event", err)
+	}
+
+	now := time.Now()
+	var id int64
+	if err := p.db.QueryRowContext(ctx, `
+		INSERT INTO event_annotations (event_id, text, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, eventID, text, now.Unix()).Scan(&id); err != nil {
+		return nil, errors.WrapStorage("insert annotation", err)
+	}
+
+	return &Annotation{ID: id, EventID: eventID, Text: text, CreatedAt: time.Unix(now.Unix(), 0)}, nil
+}
+
+func (p *PostgresStore) AnnotationsContext(ctx context.Context, eventIDs []string) (map[string][]Annotation, error) {
+	annotations := make(map[string][]Annotation)
+	if len(eventIDs) == 0 {
+		return annotations, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var args pgArgs
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, event_id, text, created_at
+		FROM event_annotations
+		WHERE event_id IN (`+args.list(eventIDs)+`)
+		ORDER BY created_at ASC, id ASC
+	`, args...)
+	if err != nil {
+		return nil, errors.WrapStorage("query annotations", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a Annotation
+		var createdAt int64
+		if err := rows.Scan(&a.ID, &a.EventID, &a.Text, &createdAt); err != nil {
+			return nil, errors.WrapStorage("scan annotation", err)
+		}
+		a.CreatedAt = time.Unix(createdAt, 0)
+		annotations[a.EventID] = append(annotations[a.EventID], a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapStorage("query annotations", err)
+	}
+	return annotations, nil
+}
+
+func (p *PostgresStore) QuerySummariesContext(ctx context.Context, start, end time.Time) ([]*Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, `
+		SELECT id, period_start, period_end, context_start, repos, summary,
+			event_count, context_event_count, COALESCE(provider, ''), input_tokens, output_tokens, created_at, repo
+		FROM summaries
+		WHERE period_start >= $1 AND period_start < $2
+		ORDER BY period_start ASC, repo ASC
+	`, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Summary
+	for rows.Next() {
+		summary, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate summaries: %w", err)
+	}
+	return result, nil
+}
+
+func (p *PostgresStore) ReplaceSummariesContext(ctx context.Context, start, end time.Time, summaries []*Summary) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM summaries WHERE period_start = $1 AND period_end = $2", start.Unix(), end.Unix()); err != nil {
+		return errors.WrapStorage("delete summaries", err)
+	}
+	for _, summary := range summaries {
+		args, err := summaryInsertArgs(summary)
+		if err != nil {
+			return err
+		}
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO summaries (
+				period_start, period_end, context_start, repos, summary,
+				event_count, context_event_count, provider, input_tokens, output_tokens, created_at, repo
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (period_start, period_end, repo) DO UPDATE SET
+				context_start = excluded.context_start,
+				repos = excluded.repos,
+				summary = excluded.summary,
+				event_count = excluded.event_count,
+				context_event_count = excluded.context_event_count,
+				provider = excluded.provider,
+				input_tokens = excluded.input_tokens,
+				output_tokens = excluded.output_tokens,
+				created_at = excluded.created_at
+			RETURNING id
+		`, args...).Scan(&summary.ID); err != nil {
+			return errors.WrapStorage("insert summary", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapStorage("commit summaries", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) RecordSummaryWindowContext(ctx context.Context, start, end time.Time, eventCount int) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	if _, err := p.db.ExecContext(ctx, `
+		INSERT INTO summary_windows (period_start, period_end, event_count, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (period_start, period_end) DO UPDATE SET
+			event_count = excluded.event_count,
+			created_at = excluded.created_at
+	`, start.Unix(), end.Unix(), eventCount, time.Now().Unix()); err != nil {
+		return errors.WrapStorage("record summary window", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) LastSummaryWindowEndContext(ctx context.Context) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var end sql.NullInt64
+	if err := p.db.QueryRowContext(ctx, "SELECT MAX(period_end) FROM summary_windows").Scan(&end); err != nil {
+		return time.Time{}, errors.WrapStorage("query last summary window", err)
+	}
+	if !end.Valid {
+		return time.Time{}, nil
+	}
+	return time.Unix(end.Int64, 0), nil
+}
+
+func (p *PostgresStore) SummaryCoverageContext(ctx context.Context, start, end time.Time) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var covered sql.NullInt64
+	if err := p.db.QueryRowContext(ctx, `
+		SELECT MAX(period_end) FROM summary_windows
+		WHERE period_start < $1 AND period_end > $2
+	`, end.Unix(), start.Unix()).Scan(&covered); err != nil {
+		return time.Time{}, errors.WrapStorage("query summary coverage", err)
+	}
+	if !covered.Valid {
+		return time.Time{}, nil
+	}
+	return time.Unix(covered.Int64, 0), nil
+}
+
+func (p *PostgresStore) DeleteSummaryWindowsContext(ctx context.Context, start, end time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	if _, err := p.db.ExecContext(ctx,
+		"DELETE FROM summary_windows WHERE period_start >= $1 AND period_start < $2",
+		start.Unix(), end.Unix()); err != nil {
+		return errors.WrapStorage("delete summary windows", err)
+	}
+	return nil
+}
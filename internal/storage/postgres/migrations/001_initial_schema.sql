@@ -0,0 +1,67 @@
+-- Initial Postgres schema with events, summaries, summary windows and annotations
+
+CREATE TABLE IF NOT EXISTS events (
+	row_id BIGSERIAL UNIQUE,
+	id TEXT PRIMARY KEY,
+	timestamp BIGINT NOT NULL,
+	source TEXT NOT NULL,
+	type TEXT NOT NULL,
+	repo TEXT,
+	branch TEXT,
+	payload TEXT NOT NULL,
+	version INTEGER NOT NULL DEFAULT 1,
+	duration_ms BIGINT,
+	session_id TEXT,
+	parent_id TEXT,
+	severity TEXT,
+	created_at BIGINT NOT NULL,
+	search_vector tsvector GENERATED ALWAYS AS (
+		to_tsvector('english', source || ' ' || type || ' ' || payload)
+	) STORED
+);
+
+CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp);
+CREATE INDEX IF NOT EXISTS idx_events_repo ON events(repo);
+CREATE INDEX IF NOT EXISTS idx_events_source ON events(source);
+CREATE INDEX IF NOT EXISTS idx_events_session_id ON events(session_id) WHERE session_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_events_parent_id ON events(parent_id) WHERE parent_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_events_search ON events USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS summaries (
+	id BIGSERIAL PRIMARY KEY,
+	period_start BIGINT NOT NULL,
+	period_end BIGINT NOT NULL,
+	context_start BIGINT NOT NULL,
+	repo TEXT NOT NULL DEFAULT '',
+	repos TEXT NOT NULL DEFAULT '[]',
+	summary TEXT NOT NULL,
+	event_count INTEGER NOT NULL DEFAULT 0,
+	context_event_count INTEGER NOT NULL DEFAULT 0,
+	provider TEXT,
+	input_tokens INTEGER NOT NULL DEFAULT 0,
+	output_tokens INTEGER NOT NULL DEFAULT 0,
+	created_at BIGINT NOT NULL,
+	search_vector tsvector GENERATED ALWAYS AS (
+		to_tsvector('english', summary || ' ' || repos)
+	) STORED,
+	UNIQUE (period_start, period_end, repo)
+);
+
+CREATE INDEX IF NOT EXISTS idx_summaries_search ON summaries USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS summary_windows (
+	period_start BIGINT NOT NULL,
+	period_end BIGINT NOT NULL,
+	event_count INTEGER NOT NULL DEFAULT 0,
+	created_at BIGINT NOT NULL,
+	PRIMARY KEY (period_start, period_end)
+);
+
+CREATE TABLE IF NOT EXISTS event_annotations (
+	id BIGSERIAL PRIMARY KEY,
+	event_id TEXT NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+	text TEXT NOT NULL,
+	created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_annotations_event_id ON event_annotations(event_id);
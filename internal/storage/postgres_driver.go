@@ -0,0 +1,7 @@
+//go:build postgres
+
+package storage
+
+// The Postgres driver is only compiled in with -tags postgres, so the
+// default build stays SQLite-only and free of the extra dependency.
+import _ "github.com/jackc/pgx/v5/stdlib"
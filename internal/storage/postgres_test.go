@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func TestPgJSONPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"$.command", `{"command"}`, false},
+		{"$.files[0].name", `{"files","0","name"}`, false},
+		{`$.a"b`, `{"a\"b"}`, false},
+		{"command", "", true},
+	}
+	for _, tt := range tests {
+		got, err := pgJSONPath(tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("pgJSONPath(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+}
+
+func TestPgArgs(t *testing.T) {
+	var args pgArgs
+	if got := args.add(1); got != "$1" {
+		t.Errorf("add() = %q, want $1", got)
+	}
+	if got := args.list([]string{"git", "shell"}); got != "$2,$3" {
+		t.Errorf("list() = %q, want $2,$3", got)
+	}
+	if len(args) != 3 || args[2] != "shell" {
+		t.Errorf("args = %v", args)
+	}
+}
+
+func TestPostgresMigrationsLoad(t *testing.T) {
+	if len(postgresMigrations) == 0 || postgresMigrations[0].Version != 1 {
+		t.Fatalf("postgres migrations = %+v", postgresMigrations)
+	}
+}
+
+func TestNewPostgresWithoutDriver(t *testing.T) {
+	if slices.Contains(sql.Drivers(), postgresDriver) {
+		t.Skip("built with the postgres driver")
+	}
+	if _, err := NewPostgres("postgres://localhost/devlog"); !errors.Is(err, ErrPostgresUnavailable) {
+		t.Errorf("NewPostgres() error = %v, want ErrPostgresUnavailable", err)
+	}
+}
+
+// TestPostgresStore runs against a real database when built with
+// -tags postgres and DEVLOG_TEST_POSTGRES_DSN names a scratch database.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("DEVLOG_TEST_POSTGRES_DSN")
+	if dsn == "" || !slices.Contains(sql.Drivers(), postgresDriver) {
+		t.Skip("set DEVLOG_TEST_POSTGRES_DSN and build with -tags postgres")
+	}
+
+	store, err := NewPostgres(dsn)
+	if err != nil {
+		t.Fatalf("NewPostgres() error: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	repo := "pgtest-" + time.Now().Format("150405.000000000")
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Repo = repo
+	event.Payload = map[string]interface{}{"command": "make flamingo"}
+
+	if err := store.InsertEventContext(ctx, event); err != nil {
+		t.Fatalf("InsertEventContext() error: %v", err)
+	}
+	if err := store.InsertEventContext(ctx, event); !errors.Is(err, ErrDuplicateEvent) {
+		t.Errorf("second insert error = %v, want ErrDuplicateEvent", err)
+	}
+
+	got, err := store.GetEventContext(ctx, event.ID)
+	if err != nil || got.Payload["command"] != "make flamingo" {
+		t.Fatalf("GetEventContext() = %+v, %v", got, err)
+	}
+
+	results, err := store.Search(ctx, SearchOptions{Query: "flamingo", RepoPattern: repo, SortOrder: SortByRelevance})
+	if err != nil || len(results) != 1 || results[0].Event.ID != event.ID {
+		t.Fatalf("Search() = %v, %v", results, err)
+	}
+
+	// LIKE wildcards in the pattern match only themselves.
+	results, err = store.Search(ctx, SearchOptions{Query: "flamingo", RepoPattern: strings.Replace(repo, "-", "_", 1)})
+	if err != nil || len(results) != 0 {
+		t.Fatalf("Search() with a wildcard pattern = %v, %v; want no results", results, err)
+	}
+
+	results, err = store.Search(ctx, SearchOptions{
+		RepoPattern:   repo,
+		PayloadFilter: &PayloadFilter{JSONPath: "$.command", Value: "make flamingo"},
+	})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Search() by payload = %v, %v", results, err)
+	}
+
+	if _, err := store.AddAnnotationContext(ctx, event.ID, "root cause"); err != nil {
+		t.Fatalf("AddAnnotationContext() error: %v", err)
+	}
+	annotations, err := store.AnnotationsContext(ctx, []string{event.ID})
+	if err != nil || len(annotations[event.ID]) != 1 {
+		t.Errorf("AnnotationsContext() = %v, %v", annotations, err)
+	}
+
+	start := time.Unix(time.Now().Unix(), 0).Add(-time.Hour)
+	end := start.Add(30 * time.Minute)
+	summary := &Summary{PeriodStart: start, PeriodEnd: end, ContextStart: start, Repo: repo, Repos: []string{repo}, Text: "flamingo work"}
+	if err := store.ReplaceSummariesContext(ctx, start, end, []*Summary{summary}); err != nil {
+		t.Fatalf("ReplaceSummariesContext() error: %v", err)
+	}
+	summaries, err := store.QuerySummariesContext(ctx, start, end)
+	if err != nil || len(summaries) == 0 {
+		t.Errorf("QuerySummariesContext() = %v, %v", summaries, err)
+	}
+}
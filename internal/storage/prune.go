@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/errors"
+)
+
+const DefaultPruneBatchSize = 1000
+
+type PruneFilter struct {
+	Sources []string
+	Before  time.Time
+}
+
+type PruneSourceStats struct {
+	Source string
+	Count  int
+	Bytes  int64
+	Oldest time.Time
+	Newest time.Time
+}
+
+type PrunePreview struct {
+	Sources []PruneSourceStats
+	Count   int
+	Bytes   int64
+}
+
+type DatabaseSize struct {
+	TotalBytes int64
+	FreeBytes  int64
+}
+
+func (f PruneFilter) where() (string, []interface{}, error) {
+	if f.Before.IsZero() {
+		return "", nil, fmt.Errorf("prune requires a cutoff time")
+	}
+
+	clause := "timestamp < ?"
+	args := []interface{}{f.Before.Unix()}
+
+	if len(f.Sources) > 0 {
+		placeholders := make([]string, len(f.Sources))
+		for i, source := range f.Sources {
+			placeholders[i] = "?"
+			args = append(args, source)
+		}
+		clause += fmt.Sprintf(" AND source IN (%s)", strings.Join(placeholders, ","))
+	}
+
+	return clause, args, nil
+}
+
+func (s *Storage) PreviewPruneContext(ctx context.Context, f PruneFilter) (*PrunePreview, error) {
+	where, args, err := f.where()
+	if err != nil {
+		return nil, errors.NewValidation("filter", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	query := fmt.Sprintf(`
+		SELECT
+			source,
+			COUNT(*),
+			SUM(length(CAST(payload AS BLOB)) + length(id) + length(source) + length(type)
+				+ COALESCE(length(repo), 0) + COALESCE(length(branch), 0) + 16),
+			MIN(timestamp),
+			MAX(timestamp)
+		FROM events
+		WHERE %s
+		GROUP BY source
+		ORDER BY COUNT(*) DESC
+	`, where)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, errors.WrapStorage("preview prune", err)
+	}
+	defer rows.Close()
+
+	preview := &PrunePreview{}
+	for rows.Next() {
+		var stats PruneSourceStats
+		var oldest, newest int64
+		if err := rows.Scan(&stats.Source, &stats.Count, &stats.Bytes, &oldest, &newest); err != nil {
+			return nil, errors.WrapStorage("scan prune preview", err)
+		}
+		stats.Oldest = time.Unix(oldest, 0)
+		stats.Newest = time.Unix(newest, 0)
+
+		preview.Sources = append(preview.Sources, stats)
+		preview.Count += stats.Count
+		preview.Bytes += stats.Bytes
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapStorage("iterate prune preview", err)
+	}
+
+	return preview, nil
+}
+
+func (s *Storage) PruneContext(ctx context.Context, f PruneFilter, batchSize int) (int64, error) {
+	where, args, err := f.where()
+	if err != nil {
+		return 0, errors.NewValidation("filter", err.Error())
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultPruneBatchSize
+	}
+
+	query := fmt.Sprintf(`
+		DELETE FROM events WHERE rowid IN (
+			SELECT rowid FROM events WHERE %s LIMIT ?
+		)
+	`, where)
+	args = append(args, batchSize)
+
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		batchCtx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+		result, err := s.db.ExecContext(batchCtx, query, args...)
+		cancel()
+		if err != nil {
+			return total, errors.WrapStorage("prune events", err)
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, errors.WrapStorage("prune events", err)
+		}
+		total += deleted
+		if deleted > 0 {
+			s.invalidateCache()
+		}
+
+		if deleted < int64(batchSize) {
+			break
+		}
+	}
+
+	if total > 0 {
+		optimizeCtx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+		defer cancel()
+		if _, err := s.db.ExecContext(optimizeCtx, "INSERT INTO events_fts(events_fts) VALUES ('optimize')"); err != nil {
+			return total, errors.WrapStorage("optimize search index", err)
+		}
+	}
+
+	return total, nil
+}
+
+func (s *Storage) DatabaseSizeContext(ctx context.Context) (*DatabaseSize, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var pageSize, pageCount, freePages int64
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&pageSize); err != nil {
+		return nil, errors.WrapStorage("read page size", err)
+	}
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&pageCount); err != nil {
+		return nil, errors.WrapStorage("read page count", err)
+	}
+	if err := s.db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&freePages); err != nil {
+		return nil, errors.WrapStorage("read freelist count", err)
+	}
+
+	return &DatabaseSize{
+		TotalBytes: pageSize * pageCount,
+		FreeBytes:  pageSize * freePages,
+	}, nil
+}
+
+func (s *Storage) VacuumContext(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return errors.WrapStorage("vacuum", err)
+	}
+	if _, err := s.db.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return errors.WrapStorage("checkpoint wal", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func insertPruneEvent(t *testing.T, s *Storage, source, text string, ts time.Time) {
+	t.Helper()
+	event := events.NewEvent(source, string(events.TypeCopy))
+	event.Timestamp = ts.UTC().Format(time.RFC3339)
+	event.Payload["content"] = text
+	if err := s.InsertEvent(event); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+}
+
+func TestPreviewPrune(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	now := time.Now()
+	insertPruneEvent(t, storage, string(events.SourceClipboard), "old copy one", now.Add(-60*24*time.Hour))
+	insertPruneEvent(t, storage, string(events.SourceClipboard), "old copy two", now.Add(-40*24*time.Hour))
+	insertPruneEvent(t, storage, string(events.SourceClipboard), "fresh copy", now.Add(-time.Hour))
+	insertPruneEvent(t, storage, string(events.SourceShell), "old shell", now.Add(-50*24*time.Hour))
+
+	ctx := context.Background()
+	cutoff := now.Add(-30 * 24 * time.Hour)
+
+	preview, err := storage.PreviewPruneContext(ctx, PruneFilter{Before: cutoff})
+	if err != nil {
+		t.Fatalf("PreviewPruneContext() error: %v", err)
+	}
+	if preview.Count != 3 {
+		t.Errorf("Count = %d, want 3", preview.Count)
+	}
+	if len(preview.Sources) != 2 {
+		t.Fatalf("got %d sources, want 2", len(preview.Sources))
+	}
+	if preview.Bytes <= 0 {
+		t.Errorf("Bytes = %d, want > 0", preview.Bytes)
+	}
+
+	preview, err = storage.PreviewPruneContext(ctx, PruneFilter{
+		Sources: []string{string(events.SourceClipboard)},
+		Before:  cutoff,
+	})
+	if err != nil {
+		t.Fatalf("PreviewPruneContext() error: %v", err)
+	}
+	if preview.Count != 2 || len(preview.Sources) != 1 {
+		t.Fatalf("clipboard preview = %d events in %d sources, want 2 in 1", preview.Count, len(preview.Sources))
+	}
+	if !preview.Sources[0].Oldest.Before(preview.Sources[0].Newest) {
+		t.Errorf("Oldest %v should be before Newest %v", preview.Sources[0].Oldest, preview.Sources[0].Newest)
+	}
+
+	if _, err := storage.PreviewPruneContext(ctx, PruneFilter{}); err == nil {
+		t.Error("PreviewPruneContext() without cutoff should fail")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		insertPruneEvent(t, storage, string(events.SourceClipboard), "stalesnippet", now.Add(-time.Duration(40+i)*24*time.Hour))
+	}
+	insertPruneEvent(t, storage, string(events.SourceClipboard), "freshsnippet", now.Add(-time.Hour))
+	insertPruneEvent(t, storage, string(events.SourceShell), "stalesnippet", now.Add(-45*24*time.Hour))
+
+	ctx := context.Background()
+	deleted, err := storage.PruneContext(ctx, PruneFilter{
+		Sources: []string{string(events.SourceClipboard)},
+		Before:  now.Add(-30 * 24 * time.Hour),
+	}, 2)
+	if err != nil {
+		t.Fatalf("PruneContext() error: %v", err)
+	}
+	if deleted != 5 {
+		t.Errorf("deleted = %d, want 5", deleted)
+	}
+
+	count, err := storage.Count()
+	if err != nil {
+		t.Fatalf("Count() error: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Count() = %d, want 2", count)
+	}
+
+	results, err := storage.Search(ctx, SearchOptions{Query: "stalesnippet", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(results) != 1 || results[0].Event.Source != string(events.SourceShell) {
+		t.Errorf("Search() after prune got %d results, want only the shell event", len(results))
+	}
+
+	if err := storage.VacuumContext(ctx); err != nil {
+		t.Fatalf("VacuumContext() error: %v", err)
+	}
+	size, err := storage.DatabaseSizeContext(ctx)
+	if err != nil {
+		t.Fatalf("DatabaseSizeContext() error: %v", err)
+	}
+	if size.TotalBytes <= 0 {
+		t.Errorf("TotalBytes = %d, want > 0", size.TotalBytes)
+	}
+}
+
+func TestMaintain(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	now := time.Now()
+	for i := 0; i < 20; i++ {
+		insertPruneEvent(t, storage, string(events.SourceClipboard), "maintenance snippet", now.Add(-time.Duration(i)*time.Hour))
+	}
+
+	result, err := storage.MaintainContext(ctx, MaintenanceOptions{Vacuum: true})
+	if err != nil {
+		t.Fatalf("MaintainContext() error: %v", err)
+	}
+
+	var names []string
+	for _, step := range result.Steps {
+		names = append(names, step.Name)
+	}
+	want := []string{"optimize search index", "analyze", "vacuum", "checkpoint wal"}
+	if len(names) != len(want) {
+		t.Fatalf("steps = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("steps[%d] = %q, want %q", i, names[i], want[i])
+		}
+	}
+	if result.SizeAfter <= 0 {
+		t.Errorf("SizeAfter = %d, want > 0", result.SizeAfter)
+	}
+
+	results, err := storage.Search(ctx, SearchOptions{Query: "maintenance", Limit: 50})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(results) != 20 {
+		t.Errorf("Search() after maintenance got %d results, want 20", len(results))
+	}
+}
@@ -3,16 +3,21 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"devlog/internal/encryption"
 	"devlog/internal/errors"
 	"devlog/internal/events"
 )
 
 var ErrDuplicateEvent = fmt.Errorf("event already exists")
 
+var ErrEventNotFound = fmt.Errorf("event not found")
+
 func isDuplicateKeyError(err error) bool {
 	if err == nil {
 		return false
@@ -27,31 +32,59 @@ func (s *Storage) InsertEvent(event *events.Event) error {
 }
 
 func (s *Storage) InsertEventContext(ctx context.Context, event *events.Event) error {
+	args, err := eventInsertArgs(event, s.cipher)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, insertEventQuery, args...); err != nil {
+		if isDuplicateKeyError(err) {
+			return ErrDuplicateEvent
+		}
+		return errors.WrapStorage("insert event", err)
+	}
+
+	return nil
+}
+
+const insertEventQuery = `
+	INSERT INTO events (id, timestamp, source, type, repo, branch, payload, version,
+		duration_ms, session_id, parent_id, severity, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+// eventInsertArgs validates, upgrades and, when cipher is set, encrypts an
+// event into the arguments of insertEventQuery.
+func eventInsertArgs(event *events.Event, cipher *encryption.Cipher) ([]interface{}, error) {
 	if err := event.Validate(); err != nil {
-		return errors.WrapStorage("validate event", err)
+		return nil, errors.WrapStorage("validate event", err)
+	}
+
+	if _, err := event.Upgrade(); err != nil {
+		return nil, errors.WrapStorage("upgrade payload", err)
 	}
 
 	payloadJSON, err := event.PayloadJSON()
 	if err != nil {
-		return errors.WrapStorage("serialize payload", err)
+		return nil, errors.WrapStorage("serialize payload", err)
 	}
 
-	query := `
-		INSERT INTO events (id, timestamp, source, type, repo, branch, payload, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`
-
-	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
-	defer cancel()
+	if cipher != nil {
+		payloadJSON, err = cipher.Encrypt(payloadJSON)
+		if err != nil {
+			return nil, errors.WrapStorage("encrypt payload", err)
+		}
+	}
 
 	timestamp, err := time.Parse(time.RFC3339, event.Timestamp)
 	if err != nil {
-		return errors.WrapStorage("parse timestamp", err)
+		return nil, errors.WrapStorage("parse timestamp", err)
 	}
 
-	_, err = s.db.ExecContext(
-		ctx,
-		query,
+	return []interface{}{
 		event.ID,
 		timestamp.Unix(),
 		event.Source,
@@ -59,17 +92,87 @@ func (s *Storage) InsertEventContext(ctx context.Context, event *events.Event) e
 		event.Repo,
 		event.Branch,
 		payloadJSON,
+		event.Version,
+		nullInt64(event.DurationMs),
+		nullString(event.SessionID),
+		nullString(event.ParentID),
+		nullString(event.Severity),
 		time.Now().Unix(),
-	)
+	}, nil
+}
+
+// InsertEventsContext stores events in a single transaction that is synced
+// to disk before it returns. The returned slice has one entry per event: nil
+// when it was stored, ErrDuplicateEvent when its ID already existed, or the
+// reason it could not be stored. The error is set when the transaction
+// itself failed, in which case nothing was stored.
+func (s *Storage) InsertEventsContext(ctx context.Context, evts []*events.Event) ([]error, error) {
+	results := make([]error, len(evts))
+	if len(evts) == 0 {
+		return results, nil
+	}
 
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	conn, err := s.db.Conn(ctx)
 	if err != nil {
-		if isDuplicateKeyError(err) {
-			return ErrDuplicateEvent
+		return nil, errors.WrapStorage("get connection", err)
+	}
+	defer conn.Close()
+
+	// WAL mode with synchronous=NORMAL skips the fsync on commit; a batch
+	// pays for one so a flushed batch survives power loss.
+	if _, err := conn.ExecContext(ctx, "PRAGMA synchronous=FULL"); err != nil {
+		return nil, errors.WrapStorage("set synchronous mode", err)
+	}
+	defer conn.ExecContext(context.Background(), "PRAGMA synchronous=NORMAL")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, strings.Replace(insertEventQuery, "INSERT INTO", "INSERT OR IGNORE INTO", 1))
+	if err != nil {
+		return nil, errors.WrapStorage("prepare insert", err)
+	}
+	defer stmt.Close()
+
+	stored := false
+	for i, event := range evts {
+		args, err := eventInsertArgs(event, s.cipher)
+		if err != nil {
+			results[i] = err
+			continue
 		}
-		return errors.WrapStorage("insert event", err)
+		result, err := stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return nil, errors.WrapStorage("insert event", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			results[i] = ErrDuplicateEvent
+			continue
+		}
+		stored = true
 	}
 
-	return nil
+	if !stored {
+		return results, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errors.WrapStorage("commit transaction", err)
+	}
+	return results, nil
+}
+
+func nullInt64(v int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: v, Valid: v != 0}
+}
+
+func nullString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
 }
 
 func (s *Storage) GetEvent(id string) (*events.Event, error) {
@@ -78,7 +181,8 @@ func (s *Storage) GetEvent(id string) (*events.Event, error) {
 
 func (s *Storage) GetEventContext(ctx context.Context, id string) (*events.Event, error) {
 	query := `
-		SELECT id, timestamp, source, type, repo, branch, payload
+		SELECT id, timestamp, source, type, repo, branch, payload, version,
+			duration_ms, session_id, parent_id, severity
 		FROM events
 		WHERE id = ?
 	`
@@ -86,9 +190,9 @@ func (s *Storage) GetEventContext(ctx context.Context, id string) (*events.Event
 	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
 	defer cancel()
 
-	event, err := s.scanEvent(s.db.QueryRowContext(ctx, query, id))
+	event, err := scanEvent(s.cipher, s.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("event not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrEventNotFound, id)
 	}
 	if err != nil {
 		return nil, errors.WrapStorage("query event", err)
@@ -98,10 +202,45 @@ func (s *Storage) GetEventContext(ctx context.Context, id string) (*events.Event
 }
 
 type QueryOptions struct {
-	StartTime *time.Time
-	EndTime   *time.Time
-	Source    string
-	Limit     int
+	StartTime   *time.Time
+	EndTime     *time.Time
+	Source      string
+	RepoPattern string
+	SessionID   string
+	ParentID    string
+	Severity    string
+	Cursor      string
+	Limit       int
+	Ascending   bool
+}
+
+var ErrInvalidCursor = fmt.Errorf("invalid cursor")
+
+func EventCursor(event *events.Event) string {
+	ts, err := time.Parse(time.RFC3339, event.Timestamp)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%s", ts.Unix(), event.ID)))
+}
+
+func decodeEventCursor(cursor string) (int64, string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+
+	tsStr, id, ok := strings.Cut(string(decoded), ":")
+	if !ok || id == "" {
+		return 0, "", ErrInvalidCursor
+	}
+
+	ts, err := strconv.ParseInt(tsStr, 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidCursor
+	}
+
+	return ts, id, nil
 }
 
 func (s *Storage) QueryEvents(opts QueryOptions) ([]*events.Event, error) {
@@ -110,7 +249,8 @@ func (s *Storage) QueryEvents(opts QueryOptions) ([]*events.Event, error) {
 
 func (s *Storage) QueryEventsContext(ctx context.Context, opts QueryOptions) ([]*events.Event, error) {
 	query := `
-		SELECT id, timestamp, source, type, repo, branch, payload
+		SELECT id, timestamp, source, type, repo, branch, payload, version,
+			duration_ms, session_id, parent_id, severity
 		FROM events
 		WHERE 1=1
 	`
@@ -132,7 +272,44 @@ func (s *Storage) QueryEventsContext(ctx context.Context, opts QueryOptions) ([]
 		args = append(args, opts.Source)
 	}
 
-	query += " ORDER BY timestamp DESC"
+	if opts.RepoPattern != "" {
+		query += ` AND repo LIKE ? ESCAPE '\'`
+		args = append(args, containsPattern(opts.RepoPattern))
+	}
+
+	if opts.SessionID != "" {
+		query += " AND session_id = ?"
+		args = append(args, opts.SessionID)
+	}
+
+	if opts.ParentID != "" {
+		query += " AND parent_id = ?"
+		args = append(args, opts.ParentID)
+	}
+
+	if opts.Severity != "" {
+		query += " AND severity = ?"
+		args = append(args, opts.Severity)
+	}
+
+	if opts.Cursor != "" {
+		ts, id, err := decodeEventCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		op := "<"
+		if opts.Ascending {
+			op = ">"
+		}
+		query += fmt.Sprintf(" AND (timestamp %s ? OR (timestamp = ? AND id %s ?))", op, op)
+		args = append(args, ts, ts, id)
+	}
+
+	if opts.Ascending {
+		query += " ORDER BY timestamp ASC, id ASC"
+	} else {
+		query += " ORDER BY timestamp DESC, id DESC"
+	}
 
 	if opts.Limit > 0 {
 		query += " LIMIT ?"
@@ -151,7 +328,7 @@ func (s *Storage) QueryEventsContext(ctx context.Context, opts QueryOptions) ([]
 	var result []*events.Event
 
 	for rows.Next() {
-		event, err := s.scanEvent(rows)
+		event, err := scanEvent(s.cipher, rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan event: %w", err)
 		}
@@ -165,6 +342,82 @@ func (s *Storage) QueryEventsContext(ctx context.Context, opts QueryOptions) ([]
 	return result, nil
 }
 
+// MaxRelatedEvents caps RelatedEventsContext, since a long Claude session can
+// hold thousands of commands.
+const MaxRelatedEvents = 500
+
+// RelatedEventsContext returns the events linked to id: its parent, its
+// children and the other events in its session, oldest first.
+func (s *Storage) RelatedEventsContext(ctx context.Context, id string) ([]*events.Event, error) {
+	event, err := s.GetEventContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, timestamp, source, type, repo, branch, payload, version,
+			duration_ms, session_id, parent_id, severity
+		FROM events
+		WHERE id != ? AND (parent_id = ? OR id = ? OR (? != '' AND session_id = ?))
+		ORDER BY timestamp ASC, id ASC
+		LIMIT ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, id, id, event.ParentID, event.SessionID, event.SessionID, MaxRelatedEvents)
+	if err != nil {
+		return nil, errors.WrapStorage("query related events", err)
+	}
+	defer rows.Close()
+
+	var result []*events.Event
+	for rows.Next() {
+		related, err := scanEvent(s.cipher, rows)
+		if err != nil {
+			return nil, errors.WrapStorage("scan event", err)
+		}
+		result = append(result, related)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapStorage("iterate events", err)
+	}
+
+	return result, nil
+}
+
+func (s *Storage) DeleteEventsContext(ctx context.Context, ids []string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := fmt.Sprintf("DELETE FROM events WHERE id IN (%s)", strings.Join(placeholders, ","))
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, errors.WrapStorage("delete events", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapStorage("delete events", err)
+	}
+	s.invalidateCache()
+
+	return deleted, nil
+}
+
 func (s *Storage) Count() (int, error) {
 	return s.CountContext(context.Background())
 }
@@ -181,15 +434,49 @@ func (s *Storage) CountContext(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (s *Storage) scanEvent(scanner interface {
+// eventLinks scans the optional duration_ms, session_id, parent_id and
+// severity columns, which are NULL for events stored before they existed.
+type eventLinks struct {
+	durationMs sql.NullInt64
+	sessionID  sql.NullString
+	parentID   sql.NullString
+	severity   sql.NullString
+}
+
+func (l *eventLinks) dest() []interface{} {
+	return []interface{}{&l.durationMs, &l.sessionID, &l.parentID, &l.severity}
+}
+
+func (l *eventLinks) apply(event *events.Event) {
+	event.DurationMs = l.durationMs.Int64
+	event.SessionID = l.sessionID.String
+	event.ParentID = l.parentID.String
+	event.Severity = l.severity.String
+}
+
+func scanEvent(cipher *encryption.Cipher, scanner interface {
+	Scan(dest ...interface{}) error
+}) (*events.Event, error) {
+	event, err := scanStoredEvent(cipher, scanner)
+	if err != nil {
+		return nil, err
+	}
+
+	// Old payloads are upgraded in memory; a failed step leaves the event as stored.
+	_, _ = event.Upgrade()
+	return event, nil
+}
+
+func scanStoredEvent(cipher *encryption.Cipher, scanner interface {
 	Scan(dest ...interface{}) error
 }) (*events.Event, error) {
 	var event events.Event
 	var payloadJSON string
 	var repo, branch sql.NullString
 	var timestampUnix int64
+	var links eventLinks
 
-	err := scanner.Scan(
+	err := scanner.Scan(append([]interface{}{
 		&event.ID,
 		&timestampUnix,
 		&event.Source,
@@ -197,13 +484,14 @@ func (s *Storage) scanEvent(scanner interface {
 		&repo,
 		&branch,
 		&payloadJSON,
-	)
+		&event.Version,
+	}, links.dest()...)...)
 
 	if err != nil {
 		return nil, err
 	}
+	links.apply(&event)
 
-	event.Version = 1
 	event.Timestamp = time.Unix(timestampUnix, 0).UTC().Format(time.RFC3339)
 
 	if repo.Valid {
@@ -214,7 +502,7 @@ func (s *Storage) scanEvent(scanner interface {
 		event.Branch = branch.String
 	}
 
-	restoredEvent, err := s.restoreEventPayload(&event, payloadJSON)
+	restoredEvent, err := restoreEventPayload(cipher, &event, payloadJSON)
 	if err != nil {
 		return nil, fmt.Errorf("restore payload: %w", err)
 	}
@@ -222,15 +510,31 @@ func (s *Storage) scanEvent(scanner interface {
 	return restoredEvent, nil
 }
 
-func (s *Storage) restoreEventPayload(event *events.Event, payloadJSON string) (*events.Event, error) {
+func restoreEventPayload(cipher *encryption.Cipher, event *events.Event, payloadJSON string) (*events.Event, error) {
+	if encryption.IsEncrypted(payloadJSON) {
+		if cipher == nil {
+			return nil, ErrPayloadEncrypted
+		}
+		decrypted, err := cipher.Decrypt(payloadJSON)
+		if err != nil {
+			return nil, err
+		}
+		payloadJSON = decrypted
+	}
+
 	restoredEvent, err := events.FromJSON([]byte(fmt.Sprintf(`{"v":1,"id":"%s","timestamp":"%s","source":"%s","type":"%s","payload":%s}`,
 		event.ID, event.Timestamp, event.Source, event.Type, payloadJSON)))
 	if err != nil {
 		return nil, err
 	}
 
+	restoredEvent.Version = event.Version
 	restoredEvent.Repo = event.Repo
 	restoredEvent.Branch = event.Branch
+	restoredEvent.DurationMs = event.DurationMs
+	restoredEvent.SessionID = event.SessionID
+	restoredEvent.ParentID = event.ParentID
+	restoredEvent.Severity = event.Severity
 
 	return restoredEvent, nil
 }
@@ -241,6 +545,12 @@ type SourceCount struct {
 }
 
 func (s *Storage) CountBySource(ctx context.Context) ([]SourceCount, error) {
+	return cached(ctx, s, "count_by_source", nil, func() ([]SourceCount, error) {
+		return s.countBySource(ctx)
+	})
+}
+
+func (s *Storage) countBySource(ctx context.Context) ([]SourceCount, error) {
 	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
 	defer cancel()
 
@@ -269,42 +579,198 @@ func (s *Storage) CountBySource(ctx context.Context) ([]SourceCount, error) {
 	return results, rows.Err()
 }
 
+const (
+	BucketMinute = "minute"
+	BucketHour   = "hour"
+	BucketDay    = "day"
+	BucketWeek   = "week"
+)
+
+// MaxTimelineBuckets caps how many buckets a single timeline query may span.
+const MaxTimelineBuckets = 5000
+
 type TimelinePoint struct {
-	Hour  string
-	Count int
+	Start    time.Time
+	Count    int
+	BySource map[string]int
+}
+
+// ValidBucket reports whether bucket is a supported timeline granularity.
+func ValidBucket(bucket string) bool {
+	switch bucket {
+	case BucketMinute, BucketHour, BucketDay, BucketWeek:
+		return true
+	}
+	return false
 }
 
-func (s *Storage) TimelineLast7Days(ctx context.Context) ([]TimelinePoint, error) {
+// BucketStart truncates t to the start of its bucket in t's location. Weeks
+// start on Monday.
+func BucketStart(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketMinute:
+		return t.Truncate(time.Minute)
+	case BucketHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case BucketWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+}
+
+func nextBucket(t time.Time, bucket string) time.Time {
+	switch bucket {
+	case BucketMinute:
+		return t.Add(time.Minute)
+	case BucketHour:
+		return t.Add(time.Hour)
+	case BucketWeek:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// TimelineBuckets returns event counts between start and end grouped into
+// minute, hour, day or week buckets aligned to start's location. Every bucket
+// in the range is present, including empty ones.
+func (s *Storage) TimelineBuckets(ctx context.Context, start, end time.Time, bucket string) ([]TimelinePoint, error) {
+	if s.cache == nil {
+		return s.timelineBuckets(ctx, start, end, bucket)
+	}
+	start, end = cacheRange(start, end)
+	plan := struct {
+		Start, End time.Time
+		Bucket     string
+	}{start, end, bucket}
+	return cached(ctx, s, "timeline", plan, func() ([]TimelinePoint, error) {
+		return s.timelineBuckets(ctx, start, end, bucket)
+	})
+}
+
+func (s *Storage) timelineBuckets(ctx context.Context, start, end time.Time, bucket string) ([]TimelinePoint, error) {
+	if !ValidBucket(bucket) {
+		return nil, fmt.Errorf("invalid bucket %q", bucket)
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("timeline end must be after start")
+	}
+
+	points, index, err := timelineGrid(start, end, bucket)
+	if err != nil {
+		return nil, err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
 	defer cancel()
 
+	width := timelineSlotWidth(bucket)
 	query := `
-		SELECT
-			strftime('%Y-%m-%d %H:00:00', datetime(timestamp, 'unixepoch')) as hour,
-			COUNT(*) as count
+		SELECT (timestamp / ?) * ? AS slot, source, COUNT(*)
 		FROM events
-		WHERE timestamp >= unixepoch('now', '-7 days')
-		GROUP BY hour
-		ORDER BY hour DESC
-		LIMIT 168
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY slot, source
+		ORDER BY slot ASC
 	`
 
-	rows, err := s.db.QueryContext(ctx, query)
+	// Timestamps are stored in whole seconds, so round a fractional end up to
+	// keep events from its final second.
+	rows, err := s.db.QueryContext(ctx, query, width, width, start.Unix(), end.Add(time.Second-1).Unix())
 	if err != nil {
 		return nil, fmt.Errorf("query timeline: %w", err)
 	}
 	defer rows.Close()
 
-	var results []TimelinePoint
+	if err := foldTimelineRows(rows, points, index, start.Location(), bucket); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// timelineGrid lays out the empty buckets between start and end, with an
+// index from each bucket's start to its position.
+func timelineGrid(start, end time.Time, bucket string) ([]TimelinePoint, map[int64]int, error) {
+	var points []TimelinePoint
+	index := make(map[int64]int)
+	for b := BucketStart(start, bucket); b.Before(end); b = nextBucket(b, bucket) {
+		if len(points) >= MaxTimelineBuckets {
+			return nil, nil, fmt.Errorf("timeline range spans more than %d %s buckets", MaxTimelineBuckets, bucket)
+		}
+		index[b.Unix()] = len(points)
+		points = append(points, TimelinePoint{Start: b, BySource: make(map[string]int)})
+	}
+	return points, index, nil
+}
+
+// timelineSlotWidth is the width in seconds of the rows a timeline query
+// groups by. Day and week buckets are folded from hourly rows so they follow
+// the local calendar rather than UTC.
+func timelineSlotWidth(bucket string) int64 {
+	if bucket == BucketMinute {
+		return 60
+	}
+	return 3600
+}
+
+// foldTimelineRows adds (slot, source, count) rows to the buckets they fall in.
+func foldTimelineRows(rows *sql.Rows, points []TimelinePoint, index map[int64]int, loc *time.Location, bucket string) error {
 	for rows.Next() {
-		var tp TimelinePoint
-		if err := rows.Scan(&tp.Hour, &tp.Count); err != nil {
-			return nil, fmt.Errorf("scan row: %w", err)
+		var slot int64
+		var source string
+		var count int
+		if err := rows.Scan(&slot, &source, &count); err != nil {
+			return fmt.Errorf("scan row: %w", err)
+		}
+
+		i, ok := index[BucketStart(time.Unix(slot, 0).In(loc), bucket).Unix()]
+		if !ok {
+			continue
 		}
-		results = append(results, tp)
+		points[i].Count += count
+		points[i].BySource[source] += count
 	}
+	return rows.Err()
+}
 
-	return results, rows.Err()
+// ActivityDistribution counts events between start and end by weekday and
+// hour of day in start's location. The grid is indexed by time.Weekday, then
+// hour.
+func (s *Storage) ActivityDistribution(ctx context.Context, start, end time.Time) ([7][24]int, error) {
+	var grid [7][24]int
+	if !end.After(start) {
+		return grid, fmt.Errorf("distribution end must be after start")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT (timestamp / 3600) * 3600 AS slot, COUNT(*)
+		FROM events
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY slot
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, start.Unix(), end.Add(time.Second-1).Unix())
+	if err != nil {
+		return grid, fmt.Errorf("query distribution: %w", err)
+	}
+	defer rows.Close()
+
+	loc := start.Location()
+	for rows.Next() {
+		var slot int64
+		var count int
+		if err := rows.Scan(&slot, &count); err != nil {
+			return grid, fmt.Errorf("scan row: %w", err)
+		}
+		t := time.Unix(slot, 0).In(loc)
+		grid[t.Weekday()][t.Hour()] += count
+	}
+
+	return grid, rows.Err()
 }
 
 type RepoStats struct {
@@ -313,6 +779,12 @@ type RepoStats struct {
 }
 
 func (s *Storage) TopRepos(ctx context.Context, limit int) ([]RepoStats, error) {
+	return cached(ctx, s, "top_repos", limit, func() ([]RepoStats, error) {
+		return s.topRepos(ctx, limit)
+	})
+}
+
+func (s *Storage) topRepos(ctx context.Context, limit int) ([]RepoStats, error) {
 	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
 	defer cancel()
 
@@ -349,6 +821,12 @@ type CommandStats struct {
 }
 
 func (s *Storage) TopCommands(ctx context.Context, limit int) ([]CommandStats, error) {
+	return cached(ctx, s, "top_commands", limit, func() ([]CommandStats, error) {
+		return s.topCommands(ctx, limit)
+	})
+}
+
+func (s *Storage) topCommands(ctx context.Context, limit int) ([]CommandStats, error) {
 	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
 	defer cancel()
 
@@ -358,7 +836,7 @@ func (s *Storage) TopCommands(ctx context.Context, limit int) ([]CommandStats, e
 			COUNT(*) as count
 		FROM events
 		WHERE source = 'shell' AND type = 'command'
-		AND json_extract(payload, '$.command') IS NOT NULL
+		AND CASE WHEN json_valid(payload) THEN json_extract(payload, '$.command') END IS NOT NULL
 		GROUP BY command
 		ORDER BY count DESC
 		LIMIT ?
@@ -381,3 +859,222 @@ func (s *Storage) TopCommands(ctx context.Context, limit int) ([]CommandStats, e
 
 	return results, rows.Err()
 }
+
+type ActivityBucket struct {
+	Hour   time.Time
+	Source string
+	Type   string
+	Repo   string
+	Count  int
+}
+
+func (s *Storage) ActivityBucketsContext(ctx context.Context, start, end time.Time) ([]ActivityBucket, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT (timestamp / 3600) * 3600 AS hour, source, type, COALESCE(repo, ''), COUNT(*)
+		FROM events
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY hour, source, type, repo
+		ORDER BY hour ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query activity: %w", err)
+	}
+	defer rows.Close()
+
+	var results []ActivityBucket
+	for rows.Next() {
+		var b ActivityBucket
+		var hour int64
+		if err := rows.Scan(&hour, &b.Source, &b.Type, &b.Repo, &b.Count); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+		b.Hour = time.Unix(hour, 0)
+		results = append(results, b)
+	}
+
+	return results, rows.Err()
+}
+
+type DailyMetric struct {
+	Date          time.Time
+	Events        int
+	BySource      map[string]int
+	ActiveHours   int
+	ActiveMinutes int
+	Failures      int
+}
+
+// maxSessionLength bounds how far before a range a session_end may have
+// started and still overlap it.
+const maxSessionLength = 24 * time.Hour
+
+// DailyMetricsContext returns one entry per calendar day in start's location,
+// including days without events. Failures are events whose payload records a
+// non-zero exit_code. ActiveMinutes comes from the activity module's
+// session_end events and stays zero when that module is not enabled.
+func (s *Storage) DailyMetricsContext(ctx context.Context, start, end time.Time) ([]DailyMetric, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT (timestamp / 3600) * 3600 AS hour, source, COUNT(*),
+			SUM(CASE WHEN json_valid(payload) AND COALESCE(json_extract(payload, '$.exit_code'), 0) != 0 THEN 1 ELSE 0 END)
+		FROM events
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY hour, source
+		ORDER BY hour ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query daily metrics: %w", err)
+	}
+	defer rows.Close()
+
+	loc := start.Location()
+	var days []DailyMetric
+	index := make(map[string]int)
+	for d := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc); d.Before(end); d = d.AddDate(0, 0, 1) {
+		index[d.Format("2006-01-02")] = len(days)
+		days = append(days, DailyMetric{Date: d, BySource: make(map[string]int)})
+	}
+
+	lastHour := make(map[int]int64)
+	for rows.Next() {
+		var hour int64
+		var source string
+		var count, failures int
+		if err := rows.Scan(&hour, &source, &count, &failures); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		i, ok := index[time.Unix(hour, 0).In(loc).Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		day := &days[i]
+		day.Events += count
+		day.Failures += failures
+		day.BySource[source] += count
+		if last, seen := lastHour[i]; !seen || last != hour {
+			lastHour[i] = hour
+			day.ActiveHours++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.addActiveMinutes(ctx, days, end); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+// addActiveMinutes spreads each recorded session over the days it covers.
+func (s *Storage) addActiveMinutes(ctx context.Context, days []DailyMetric, end time.Time) error {
+	if len(days) == 0 {
+		return nil
+	}
+	start := days[0].Date
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT timestamp, COALESCE(json_extract(payload, '$.duration_seconds'), 0)
+		FROM events
+		WHERE source = ? AND type = ? AND timestamp >= ? AND timestamp < ? AND json_valid(payload)
+	`, string(events.SourceActivity), string(events.TypeSessionEnd), start.Unix(), end.Add(maxSessionLength).Unix())
+	if err != nil {
+		return fmt.Errorf("query active sessions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var endedAt, seconds int64
+		if err := rows.Scan(&endedAt, &seconds); err != nil {
+			return fmt.Errorf("scan row: %w", err)
+		}
+		sessionEnd := time.Unix(endedAt, 0)
+		sessionStart := sessionEnd.Add(-time.Duration(seconds) * time.Second)
+
+		for i := range days {
+			dayStart := days[i].Date
+			dayEnd := dayStart.AddDate(0, 0, 1)
+			from, to := sessionStart, sessionEnd
+			if from.Before(dayStart) {
+				from = dayStart
+			}
+			if to.After(dayEnd) {
+				to = dayEnd
+			}
+			if to.After(from) {
+				days[i].ActiveMinutes += int(to.Sub(from).Minutes())
+			}
+		}
+	}
+	return rows.Err()
+}
+
+type rowidScanner struct {
+	scanner interface {
+		Scan(dest ...interface{}) error
+	}
+	rowid *int64
+}
+
+func (r rowidScanner) Scan(dest ...interface{}) error {
+	return r.scanner.Scan(append([]interface{}{r.rowid}, dest...)...)
+}
+
+func (s *Storage) EventsAfterRowContext(ctx context.Context, afterRow int64, limit int) ([]*events.Event, int64, error) {
+	query := `
+		SELECT rowid, id, timestamp, source, type, repo, branch, payload, version,
+			duration_ms, session_id, parent_id, severity
+		FROM events
+		WHERE rowid > ?
+		ORDER BY rowid ASC
+		LIMIT ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, afterRow, limit)
+	if err != nil {
+		return nil, afterRow, errors.WrapStorage("query events after row", err)
+	}
+	defer rows.Close()
+
+	lastRow := afterRow
+	var result []*events.Event
+	for rows.Next() {
+		var rowid int64
+		event, err := scanEvent(s.cipher, rowidScanner{scanner: rows, rowid: &rowid})
+		if err != nil {
+			return nil, afterRow, errors.WrapStorage("scan event", err)
+		}
+		lastRow = rowid
+		result = append(result, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, afterRow, errors.WrapStorage("iterate events", err)
+	}
+
+	return result, lastRow, nil
+}
+
+func (s *Storage) MaxEventRowContext(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var rowid sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(rowid) FROM events").Scan(&rowid); err != nil {
+		return 0, errors.WrapStorage("query max event row", err)
+	}
+	return rowid.Int64, nil
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+
+	"devlog/internal/errors"
+	"devlog/internal/events"
+)
+
+// RedactedPlaceholder replaces scrubbed text in event payloads.
+const RedactedPlaceholder = "[REDACTED]"
+
+const redactScanBatchSize = 500
+
+// RedactFilter selects events to redact: the events with the given IDs, or
+// the events whose payload, repo or branch contains Query (ignoring case),
+// optionally limited to events after Since and to some sources.
+type RedactFilter struct {
+	IDs     []string
+	Query   string
+	Since   *time.Time
+	Sources []string
+}
+
+// FindRedactableContext returns the events a redaction with f would touch,
+// oldest first. Payloads are matched after decryption, so this works on
+// encrypted databases too.
+func (s *Storage) FindRedactableContext(ctx context.Context, f RedactFilter) ([]*events.Event, error) {
+	if len(f.IDs) == 0 && f.Query == "" {
+		return nil, errors.NewValidation("filter", "an event id or a query is required")
+	}
+
+	if len(f.IDs) > 0 {
+		var matched []*events.Event
+		for _, id := range f.IDs {
+			event, err := s.GetEventContext(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if f.Query == "" || redactMatches(event, f.Query) {
+				matched = append(matched, event)
+			}
+		}
+		return matched, nil
+	}
+
+	sources := f.Sources
+	if len(sources) == 0 {
+		sources = []string{""}
+	}
+
+	var matched []*events.Event
+	for _, source := range sources {
+		opts := QueryOptions{
+			StartTime: f.Since,
+			Source:    source,
+			Limit:     redactScanBatchSize,
+			Ascending: true,
+		}
+		for {
+			page, err := s.QueryEventsContext(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for _, event := range page {
+				if redactMatches(event, f.Query) {
+					matched = append(matched, event)
+				}
+			}
+			if len(page) < redactScanBatchSize {
+				break
+			}
+			opts.Cursor = EventCursor(page[len(page)-1])
+		}
+	}
+	return matched, nil
+}
+
+func redactMatches(event *events.Event, query string) bool {
+	query = strings.ToLower(query)
+	return strings.Contains(strings.ToLower(event.Repo), query) ||
+		strings.Contains(strings.ToLower(event.Branch), query) ||
+		payloadContains(event.Payload, query)
+}
+
+func payloadContains(value interface{}, query string) bool {
+	switch v := value.(type) {
+	case string:
+		return strings.Contains(strings.ToLower(v), query)
+	case map[string]interface{}:
+		for key, item := range v {
+			if strings.Contains(strings.ToLower(key), query) || payloadContains(item, query) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if payloadContains(item, query) {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range v {
+			if strings.Contains(strings.ToLower(item), query) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RedactEventsContext deletes the events, or with scrub rewrites them, in a
+// single transaction. Scrubbing replaces every occurrence of query in the
+// payload, repo and branch with RedactedPlaceholder; without a query the
+// whole payload is replaced. Deleted and overwritten content is zeroed on
+// disk, purged from the search index and checkpointed out of the WAL, so it
+// cannot be recovered from the database files afterwards.
+func (s *Storage) RedactEventsContext(ctx context.Context, evts []*events.Event, scrub bool, query string) (int, error) {
+	if len(evts) == 0 {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return 0, errors.WrapStorage("get connection", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA secure_delete=ON"); err != nil {
+		return 0, errors.WrapStorage("enable secure delete", err)
+	}
+	defer conn.ExecContext(context.Background(), "PRAGMA secure_delete=OFF")
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	var pattern *regexp.Regexp
+	if query != "" {
+		pattern = regexp.MustCompile("(?i)" + regexp.QuoteMeta(query))
+	}
+
+	redacted := 0
+	for _, event := range evts {
+		var result sql.Result
+		if scrub {
+			payloadJSON, err := s.scrubbedPayload(event, pattern)
+			if err != nil {
+				return 0, err
+			}
+			repo, branch := event.Repo, event.Branch
+			if pattern != nil {
+				repo = pattern.ReplaceAllLiteralString(repo, RedactedPlaceholder)
+				branch = pattern.ReplaceAllLiteralString(branch, RedactedPlaceholder)
+			}
+			result, err = tx.ExecContext(ctx, `
+				UPDATE events SET payload = ?, version = ?, repo = ?, branch = ? WHERE id = ?
+			`, payloadJSON, event.Version, repo, branch, event.ID)
+			if err != nil {
+				return 0, errors.WrapStorage("scrub event", err)
+			}
+		} else {
+			result, err = tx.ExecContext(ctx, "DELETE FROM events WHERE id = ?", event.ID)
+			if err != nil {
+				return 0, errors.WrapStorage("delete event", err)
+			}
+		}
+		if n, err := result.RowsAffected(); err == nil && n > 0 {
+			redacted++
+		}
+	}
+
+	// The triggers remove the old rows from the index, but their terms stay
+	// in older index segments until they are merged.
+	if _, err := tx.ExecContext(ctx, "INSERT INTO events_fts(events_fts) VALUES ('optimize')"); err != nil {
+		return 0, errors.WrapStorage("optimize search index", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.WrapStorage("commit transaction", err)
+	}
+	s.invalidateCache()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return redacted, errors.WrapStorage("checkpoint wal", err)
+	}
+
+	return redacted, nil
+}
+
+func (s *Storage) scrubbedPayload(event *events.Event, pattern *regexp.Regexp) (string, error) {
+	var payload interface{} = map[string]interface{}{"redacted": true}
+	if pattern != nil {
+		payload = scrubValue(event.Payload, pattern)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", errors.WrapStorage("serialize payload", err)
+	}
+	payloadJSON := string(data)
+
+	if s.cipher != nil {
+		payloadJSON, err = s.cipher.Encrypt(payloadJSON)
+		if err != nil {
+			return "", errors.WrapStorage("encrypt payload", err)
+		}
+	}
+	return payloadJSON, nil
+}
+
+func scrubValue(value interface{}, pattern *regexp.Regexp) interface{} {
+	switch v := value.(type) {
+	case string:
+		return pattern.ReplaceAllLiteralString(v, RedactedPlaceholder)
+	case map[string]interface{}:
+		scrubbed := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			scrubbed[pattern.ReplaceAllLiteralString(key, RedactedPlaceholder)] = scrubValue(item, pattern)
+		}
+		return scrubbed
+	case []interface{}:
+		scrubbed := make([]interface{}, len(v))
+		for i, item := range v {
+			scrubbed[i] = scrubValue(item, pattern)
+		}
+		return scrubbed
+	case []string:
+		scrubbed := make([]string, len(v))
+		for i, item := range v {
+			scrubbed[i] = pattern.ReplaceAllLiteralString(item, RedactedPlaceholder)
+		}
+		return scrubbed
+	}
+	return value
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func TestRedactEvents(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	newCommand := func(command string) *events.Event {
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Payload = map[string]interface{}{"command": command}
+		if err := storage.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+		return event
+	}
+
+	leaked := newCommand("export AWS_SECRET_ACCESS_KEY=abc123")
+	scrubbed := newCommand("curl -H 'x-aws_secret: abc123' example.com")
+	old := newCommand("echo aws_secret")
+	if _, err := storage.db.Exec("UPDATE events SET timestamp = ? WHERE id = ?",
+		time.Now().Add(-60*24*time.Hour).Unix(), old.ID); err != nil {
+		t.Fatalf("backdate event: %v", err)
+	}
+	kept := newCommand("ls -la")
+
+	if _, err := storage.AddAnnotationContext(ctx, leaked.ID, "oops"); err != nil {
+		t.Fatalf("AddAnnotationContext() error: %v", err)
+	}
+
+	if _, err := storage.FindRedactableContext(ctx, RedactFilter{}); err == nil {
+		t.Error("expected an error without ids or a query")
+	}
+
+	since := time.Now().Add(-30 * 24 * time.Hour)
+	matched, err := storage.FindRedactableContext(ctx, RedactFilter{Query: "aws_secret", Since: &since})
+	if err != nil {
+		t.Fatalf("FindRedactableContext() error: %v", err)
+	}
+	if len(matched) != 2 {
+		t.Fatalf("matched %d events, want 2 (the backdated one is outside --since)", len(matched))
+	}
+	byEventID := map[string]*events.Event{}
+	for _, e := range matched {
+		byEventID[e.ID] = e
+	}
+
+	n, err := storage.RedactEventsContext(ctx, []*events.Event{byEventID[scrubbed.ID]}, true, "aws_secret")
+	if err != nil || n != 1 {
+		t.Fatalf("scrub = %d, %v, want 1", n, err)
+	}
+	event, err := storage.GetEventContext(ctx, scrubbed.ID)
+	if err != nil {
+		t.Fatalf("GetEventContext() error: %v", err)
+	}
+	if command := event.Payload["command"].(string); strings.Contains(strings.ToLower(command), "aws_secret") ||
+		!strings.Contains(command, RedactedPlaceholder) {
+		t.Errorf("scrubbed command = %q", command)
+	}
+
+	n, err = storage.RedactEventsContext(ctx, []*events.Event{byEventID[leaked.ID]}, false, "")
+	if err != nil || n != 1 {
+		t.Fatalf("delete = %d, %v, want 1", n, err)
+	}
+	if _, err := storage.GetEventContext(ctx, leaked.ID); err == nil {
+		t.Error("deleted event is still stored")
+	}
+	annotations, err := storage.AnnotationsContext(ctx, []string{leaked.ID})
+	if err != nil || len(annotations[leaked.ID]) != 0 {
+		t.Errorf("annotations of deleted event = %v, %v", annotations, err)
+	}
+
+	results, err := storage.Search(ctx, SearchOptions{Query: "abc123", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	for _, r := range results {
+		if r.Event != nil && r.Event.ID == leaked.ID {
+			t.Error("search index still returns the deleted event")
+		}
+	}
+
+	byID, err := storage.FindRedactableContext(ctx, RedactFilter{IDs: []string{kept.ID, old.ID}, Query: "aws_secret"})
+	if err != nil {
+		t.Fatalf("FindRedactableContext() by id error: %v", err)
+	}
+	if len(byID) != 1 || byID[0].ID != old.ID {
+		t.Errorf("by id with query matched %v, want only the old event", byID)
+	}
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"devlog/internal/errors"
+)
+
+// RenameRepoContext moves every event stored under repo from to repo to and
+// returns how many were changed.
+func (s *Storage) RenameRepoContext(ctx context.Context, from, to string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, "UPDATE events SET repo = ? WHERE repo = ?", to, from)
+	if err != nil {
+		return 0, errors.WrapStorage("rename repo", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapStorage("rename repo", err)
+	}
+	s.invalidateCache()
+	return n, nil
+}
+
+// ActiveReposContext returns the distinct repos with at least one event at
+// or after since, most recently active first.
+func (s *Storage) ActiveReposContext(ctx context.Context, since time.Time) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT repo FROM events
+		WHERE repo IS NOT NULL AND repo != '' AND timestamp >= ?
+		GROUP BY repo
+		ORDER BY MAX(timestamp) DESC
+	`, since.Unix())
+	if err != nil {
+		return nil, errors.WrapStorage("query active repos", err)
+	}
+	defer rows.Close()
+
+	var repos []string
+	for rows.Next() {
+		var repo string
+		if err := rows.Scan(&repo); err != nil {
+			return nil, errors.WrapStorage("scan active repo", err)
+		}
+		repos = append(repos, repo)
+	}
+	return repos, rows.Err()
+}
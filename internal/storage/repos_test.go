@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func TestRenameRepo(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	for _, repo := range []string{"/home/me/src/devlog", "/home/me/src/devlog", "api"} {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Repo = repo
+		if err := storage.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	n, err := storage.RenameRepoContext(ctx, "/home/me/src/devlog", "devlog")
+	if err != nil {
+		t.Fatalf("RenameRepoContext() error: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("renamed %d events, want 2", n)
+	}
+
+	repos, err := storage.TopRepos(ctx, -1)
+	if err != nil {
+		t.Fatalf("TopRepos() error: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Repo != "devlog" || repos[0].Count != 2 {
+		t.Errorf("TopRepos() = %+v, want devlog x2 and api", repos)
+	}
+}
+
+func TestActiveRepos(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	now := time.Now()
+	for _, e := range []struct {
+		repo string
+		age  time.Duration
+	}{
+		{"github.com/me/old", 30 * 24 * time.Hour},
+		{"github.com/me/devlog", 2 * time.Hour},
+		{"github.com/me/api", time.Hour},
+		{"github.com/me/devlog", 3 * time.Hour},
+	} {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Repo = e.repo
+		event.Timestamp = now.Add(-e.age).UTC().Format(time.RFC3339)
+		if err := storage.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	repos, err := storage.ActiveReposContext(context.Background(), now.AddDate(0, 0, -7))
+	if err != nil {
+		t.Fatalf("ActiveReposContext() error: %v", err)
+	}
+	want := []string{"github.com/me/api", "github.com/me/devlog"}
+	if len(repos) != len(want) || repos[0] != want[0] || repos[1] != want[1] {
+		t.Errorf("ActiveReposContext() = %v, want %v", repos, want)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"encoding/base64"
 	"fmt"
 	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -19,11 +21,13 @@ type SearchOptions struct {
 	PayloadFilter *PayloadFilter
 	Cursor        string
 	After         *time.Time
+	Before        *time.Time
 	Modules       []string
 	Types         []string
 	RepoPattern   string
 	BranchPattern string
 	SortOrder     SortOrder
+	Scope         SearchScope
 }
 
 type SortOrder string
@@ -34,15 +38,36 @@ const (
 	SortByTimeAsc   SortOrder = "time_asc"
 )
 
+type SearchScope string
+
+const (
+	ScopeEvents    SearchScope = "events"
+	ScopeSummaries SearchScope = "summaries"
+	ScopeAll       SearchScope = "all"
+)
+
+func ParseSearchScope(s string) (SearchScope, error) {
+	switch scope := SearchScope(s); scope {
+	case "":
+		return ScopeEvents, nil
+	case ScopeEvents, ScopeSummaries, ScopeAll:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("invalid scope: %s (must be events, summaries, or all)", s)
+	}
+}
+
 type PayloadFilter struct {
 	JSONPath string
 	Value    string
 }
 
 type SearchResult struct {
-	Event      *events.Event
-	Rank       float64
-	NextCursor string
+	Event       *events.Event
+	Summary     *Summary
+	Rank        float64
+	NextCursor  string
+	Annotations []Annotation
 }
 
 var (
@@ -94,7 +119,54 @@ func sanitizeFTSQuery(query string) string {
 	return cleaned
 }
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns a LIKE pattern for values containing s. Wildcards
+// in s are escaped, so the query must say ESCAPE '\'; a repo filter of
+// "my_app" must not also match "my-app".
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
 func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResult, error) {
+	if s.cache == nil {
+		return s.search(ctx, opts)
+	}
+	opts = normalizeSearchPlan(opts)
+	return cached(ctx, s, "search", opts, func() ([]*SearchResult, error) {
+		return s.search(ctx, opts)
+	})
+}
+
+// normalizeSearchPlan rewrites options that mean the same query into the
+// same form, so they share a cache entry.
+func normalizeSearchPlan(opts SearchOptions) SearchOptions {
+	opts.Query = strings.Join(strings.Fields(opts.Query), " ")
+	opts.Modules = slices.Sorted(slices.Values(opts.Modules))
+	opts.Types = slices.Sorted(slices.Values(opts.Types))
+	if opts.After != nil {
+		after := opts.After.Truncate(time.Minute)
+		opts.After = &after
+	}
+	if opts.Before != nil {
+		_, before := cacheRange(*opts.Before, *opts.Before)
+		opts.Before = &before
+	}
+	return opts
+}
+
+func (s *Storage) search(ctx context.Context, opts SearchOptions) ([]*SearchResult, error) {
+	return runSearch(ctx, s, opts)
+}
+
+// searchBackend runs the event and summary halves of a search against one
+// storage backend; runSearch validates the options and pages the results.
+type searchBackend interface {
+	searchEvents(ctx context.Context, opts SearchOptions, ftsQuery string, hasFTSQuery bool, limit, offset int) ([]*SearchResult, error)
+	searchSummaries(ctx context.Context, opts SearchOptions, ftsQuery string, hasFTSQuery bool, limit, offset int) ([]*SearchResult, error)
+}
+
+func runSearch(ctx context.Context, b searchBackend, opts SearchOptions) ([]*SearchResult, error) {
 	if opts.Limit <= 0 {
 		opts.Limit = 20
 	}
@@ -108,22 +180,108 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 		opts.Query = "*"
 	}
 
+	if opts.SortOrder == "" {
+		opts.SortOrder = SortByTimeAsc
+	}
+
 	sanitizedQuery := sanitizeFTSQuery(opts.Query)
 	hasFTSQuery := sanitizedQuery != "" && sanitizedQuery != "*"
 
-	hasFilters := opts.After != nil ||
-		len(opts.Modules) > 0 ||
+	eventOnlyFilters := len(opts.Modules) > 0 ||
 		len(opts.Types) > 0 ||
-		opts.RepoPattern != "" ||
 		opts.BranchPattern != "" ||
 		opts.PayloadFilter != nil
 
+	hasFilters := eventOnlyFilters || opts.After != nil || opts.Before != nil || opts.RepoPattern != ""
+
 	if !hasFTSQuery && !hasFilters {
-		return nil, fmt.Errorf("search requires at least one filter (module, type, repo, branch, since) or a non-empty query")
+		return nil, fmt.Errorf("search requires at least one filter (module, type, repo, branch, since, until) or a non-empty query")
+	}
+
+	var results []*SearchResult
+	switch opts.Scope {
+	case "", ScopeEvents:
+		results, err = b.searchEvents(ctx, opts, sanitizedQuery, hasFTSQuery, opts.Limit+1, offset)
+	case ScopeSummaries:
+		if eventOnlyFilters {
+			return nil, fmt.Errorf("module, type, branch, and payload filters do not apply to summaries")
+		}
+		results, err = b.searchSummaries(ctx, opts, sanitizedQuery, hasFTSQuery, opts.Limit+1, offset)
+	case ScopeAll:
+		results, err = searchAll(ctx, b, opts, sanitizedQuery, hasFTSQuery, eventOnlyFilters, offset)
+	default:
+		return nil, fmt.Errorf("invalid search scope: %s", opts.Scope)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(results) > opts.Limit
+	if hasMore {
+		results = results[:opts.Limit]
+		nextOffset := offset + opts.Limit
+		nextCursor := encodeCursor(nextOffset)
+		for i := range results {
+			results[i].NextCursor = nextCursor
+		}
+	}
+
+	return results, nil
+}
+
+func searchAll(ctx context.Context, b searchBackend, opts SearchOptions, ftsQuery string, hasFTSQuery, eventOnlyFilters bool, offset int) ([]*SearchResult, error) {
+	window := offset + opts.Limit + 1
+
+	results, err := b.searchEvents(ctx, opts, ftsQuery, hasFTSQuery, window, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if !eventOnlyFilters {
+		summaries, err := b.searchSummaries(ctx, opts, ftsQuery, hasFTSQuery, window, 0)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, summaries...)
+	}
+
+	sortSearchResults(results, opts.SortOrder, hasFTSQuery)
+
+	if offset >= len(results) {
+		return nil, nil
+	}
+	results = results[offset:]
+	if len(results) > window-offset {
+		results = results[:window-offset]
+	}
+	return results, nil
+}
+
+func sortSearchResults(results []*SearchResult, order SortOrder, hasFTSQuery bool) {
+	sort.SliceStable(results, func(i, j int) bool {
+		switch {
+		case order == SortByRelevance && hasFTSQuery:
+			return results[i].Rank < results[j].Rank
+		case order == SortByTimeAsc:
+			return results[i].Time().Before(results[j].Time())
+		default:
+			return results[i].Time().After(results[j].Time())
+		}
+	})
+}
+
+func (r *SearchResult) Time() time.Time {
+	if r.Summary != nil {
+		return r.Summary.PeriodStart
 	}
+	t, _ := time.Parse(time.RFC3339, r.Event.Timestamp)
+	return t
+}
 
+func (s *Storage) searchEvents(ctx context.Context, opts SearchOptions, ftsQuery string, hasFTSQuery bool, limit, offset int) ([]*SearchResult, error) {
 	var args []interface{}
-	selectFields := "e.id, e.timestamp, e.source, e.type, e.repo, e.branch, e.payload"
+	selectFields := "e.id, e.timestamp, e.source, e.type, e.repo, e.branch, e.payload, e.version, " +
+		"e.duration_ms, e.session_id, e.parent_id, e.severity"
 	if hasFTSQuery {
 		selectFields += ", rank"
 	}
@@ -134,7 +292,7 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 	if hasFTSQuery {
 		fromClause += " JOIN events_fts ON events_fts.rowid = e.rowid"
 		whereClauses = append(whereClauses, "events_fts MATCH ?")
-		args = append(args, sanitizedQuery)
+		args = append(args, ftsQuery)
 	}
 
 	if opts.After != nil {
@@ -142,6 +300,11 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 		args = append(args, opts.After.Unix())
 	}
 
+	if opts.Before != nil {
+		whereClauses = append(whereClauses, "e.timestamp < ?")
+		args = append(args, opts.Before.Unix())
+	}
+
 	if len(opts.Modules) > 0 {
 		placeholders := make([]string, len(opts.Modules))
 		for i, source := range opts.Modules {
@@ -161,17 +324,17 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 	}
 
 	if opts.RepoPattern != "" {
-		whereClauses = append(whereClauses, "e.repo LIKE ?")
-		args = append(args, "%"+opts.RepoPattern+"%")
+		whereClauses = append(whereClauses, `e.repo LIKE ? ESCAPE '\'`)
+		args = append(args, containsPattern(opts.RepoPattern))
 	}
 
 	if opts.BranchPattern != "" {
-		whereClauses = append(whereClauses, "e.branch LIKE ?")
-		args = append(args, "%"+opts.BranchPattern+"%")
+		whereClauses = append(whereClauses, `e.branch LIKE ? ESCAPE '\'`)
+		args = append(args, containsPattern(opts.BranchPattern))
 	}
 
 	if opts.PayloadFilter != nil {
-		whereClauses = append(whereClauses, "json_extract(e.payload, ?) = ?")
+		whereClauses = append(whereClauses, "CASE WHEN json_valid(e.payload) THEN json_extract(e.payload, ?) END = ?")
 		args = append(args, opts.PayloadFilter.JSONPath, opts.PayloadFilter.Value)
 	}
 
@@ -181,9 +344,6 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 	}
 
 	orderClause := ""
-	if opts.SortOrder == "" {
-		opts.SortOrder = SortByTimeAsc
-	}
 	switch opts.SortOrder {
 	case SortByRelevance:
 		if hasFTSQuery {
@@ -197,7 +357,7 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 		orderClause = "ORDER BY e.timestamp ASC"
 	}
 
-	limitClause := fmt.Sprintf("LIMIT %d OFFSET %d", opts.Limit+1, offset)
+	limitClause := fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
 
 	sqlQuery := fmt.Sprintf("SELECT %s %s %s %s %s",
 		selectFields, fromClause, whereClause, orderClause, limitClause)
@@ -224,14 +384,83 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 		return nil, err
 	}
 
-	hasMore := len(results) > opts.Limit
-	if hasMore {
-		results = results[:opts.Limit]
-		nextOffset := offset + opts.Limit
-		nextCursor := encodeCursor(nextOffset)
-		for i := range results {
-			results[i].NextCursor = nextCursor
+	return results, nil
+}
+
+func (s *Storage) searchSummaries(ctx context.Context, opts SearchOptions, ftsQuery string, hasFTSQuery bool, limit, offset int) ([]*SearchResult, error) {
+	var args []interface{}
+	selectFields := `s.id, s.period_start, s.period_end, s.context_start, s.repos, s.summary,
+		s.event_count, s.context_event_count, COALESCE(s.provider, ''), s.input_tokens, s.output_tokens, s.created_at, s.repo`
+	if hasFTSQuery {
+		selectFields += ", rank"
+	}
+
+	fromClause := "FROM summaries s"
+	var whereClauses []string
+
+	if hasFTSQuery {
+		fromClause += " JOIN summaries_fts ON summaries_fts.rowid = s.id"
+		whereClauses = append(whereClauses, "summaries_fts MATCH ?")
+		args = append(args, ftsQuery)
+	}
+
+	if opts.After != nil {
+		whereClauses = append(whereClauses, "s.period_end >= ?")
+		args = append(args, opts.After.Unix())
+	}
+
+	if opts.Before != nil {
+		whereClauses = append(whereClauses, "s.period_start < ?")
+		args = append(args, opts.Before.Unix())
+	}
+
+	if opts.RepoPattern != "" {
+		whereClauses = append(whereClauses, `s.repos LIKE ? ESCAPE '\'`)
+		args = append(args, containsPattern(opts.RepoPattern))
+	}
+
+	whereClause := ""
+	if len(whereClauses) > 0 {
+		whereClause = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+
+	orderClause := "ORDER BY s.period_start DESC"
+	switch {
+	case opts.SortOrder == SortByRelevance && hasFTSQuery:
+		orderClause = "ORDER BY rank"
+	case opts.SortOrder == SortByTimeAsc:
+		orderClause = "ORDER BY s.period_start ASC"
+	}
+
+	sqlQuery := fmt.Sprintf("SELECT %s %s %s %s LIMIT %d OFFSET %d",
+		selectFields, fromClause, whereClause, orderClause, limit, offset)
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var results []*SearchResult
+	for rows.Next() {
+		var rank float64
+		var extra []interface{}
+		if hasFTSQuery {
+			extra = append(extra, &rank)
+		}
+
+		summary, err := scanSummary(rows, extra...)
+		if err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
 		}
+		results = append(results, &SearchResult{Summary: summary, Rank: rank})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return results, nil
@@ -239,9 +468,10 @@ func (s *Storage) Search(ctx context.Context, opts SearchOptions) ([]*SearchResu
 
 func (s *Storage) QueryByPayloadField(ctx context.Context, jsonPath string, value string, limit int) ([]*events.Event, error) {
 	sqlQuery := `
-		SELECT id, timestamp, source, type, repo, branch, payload
+		SELECT id, timestamp, source, type, repo, branch, payload, version,
+			duration_ms, session_id, parent_id, severity
 		FROM events
-		WHERE json_extract(payload, ?) = ?
+		WHERE CASE WHEN json_valid(payload) THEN json_extract(payload, ?) END = ?
 		ORDER BY timestamp DESC
 		LIMIT ?
 	`
@@ -257,7 +487,7 @@ func (s *Storage) QueryByPayloadField(ctx context.Context, jsonPath string, valu
 
 	var result []*events.Event
 	for rows.Next() {
-		event, err := s.scanEvent(rows)
+		event, err := scanEvent(s.cipher, rows)
 		if err != nil {
 			return nil, fmt.Errorf("scan event: %w", err)
 		}
@@ -274,36 +504,27 @@ func (s *Storage) scanSearchResultWithFTS(scanner interface {
 	var payloadJSON string
 	var repo, branch sql.NullString
 	var timestampUnix int64
+	var links eventLinks
 	var rank float64
 
-	var err error
+	dest := append([]interface{}{
+		&event.ID,
+		&timestampUnix,
+		&event.Source,
+		&event.Type,
+		&repo,
+		&branch,
+		&payloadJSON,
+		&event.Version,
+	}, links.dest()...)
 	if hasFTSQuery {
-		err = scanner.Scan(
-			&event.ID,
-			&timestampUnix,
-			&event.Source,
-			&event.Type,
-			&repo,
-			&branch,
-			&payloadJSON,
-			&rank,
-		)
-	} else {
-		err = scanner.Scan(
-			&event.ID,
-			&timestampUnix,
-			&event.Source,
-			&event.Type,
-			&repo,
-			&branch,
-			&payloadJSON,
-		)
+		dest = append(dest, &rank)
 	}
-	if err != nil {
+	if err := scanner.Scan(dest...); err != nil {
 		return nil, err
 	}
+	links.apply(&event)
 
-	event.Version = 1
 	event.Timestamp = time.Unix(timestampUnix, 0).UTC().Format(time.RFC3339)
 
 	if repo.Valid {
@@ -314,10 +535,11 @@ func (s *Storage) scanSearchResultWithFTS(scanner interface {
 		event.Branch = branch.String
 	}
 
-	restoredEvent, err := s.restoreEventPayload(&event, payloadJSON)
+	restoredEvent, err := restoreEventPayload(s.cipher, &event, payloadJSON)
 	if err != nil {
 		return nil, fmt.Errorf("restore payload: %w", err)
 	}
+	_, _ = restoredEvent.Upgrade()
 
 	return &SearchResult{
 		Event: restoredEvent,
@@ -765,6 +765,56 @@ func TestSearchWithBranchPattern(t *testing.T) {
 	}
 }
 
+func TestSearchPatternsMatchWildcardsLiterally(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+	ctx := context.Background()
+
+	for _, repo := range []string{"my_app", "my-app", "100%done", "100xdone", `back\slash`} {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Repo = repo
+		event.Branch = "feature/" + repo
+		event.Payload["message"] = "test"
+		if err := storage.InsertEvent(event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"my_app", "my_app"},
+		{"100%", "100%done"},
+		{`k\s`, `back\slash`},
+	}
+	for _, tt := range tests {
+		results, err := storage.Search(ctx, SearchOptions{Query: "*", Limit: 10, RepoPattern: tt.pattern})
+		if err != nil {
+			t.Fatalf("Search(repo %q) error: %v", tt.pattern, err)
+		}
+		if len(results) != 1 || results[0].Event.Repo != tt.want {
+			t.Errorf("Search(repo %q) = %d results, want only %s", tt.pattern, len(results), tt.want)
+		}
+
+		results, err = storage.Search(ctx, SearchOptions{Query: "*", Limit: 10, BranchPattern: tt.pattern})
+		if err != nil {
+			t.Fatalf("Search(branch %q) error: %v", tt.pattern, err)
+		}
+		if len(results) != 1 || results[0].Event.Branch != "feature/"+tt.want {
+			t.Errorf("Search(branch %q) = %d results, want only feature/%s", tt.pattern, len(results), tt.want)
+		}
+
+		found, err := storage.QueryEventsContext(ctx, QueryOptions{RepoPattern: tt.pattern, Limit: 10})
+		if err != nil {
+			t.Fatalf("QueryEventsContext(repo %q) error: %v", tt.pattern, err)
+		}
+		if len(found) != 1 || found[0].Repo != tt.want {
+			t.Errorf("QueryEventsContext(repo %q) = %d events, want only %s", tt.pattern, len(found), tt.want)
+		}
+	}
+}
+
 func TestSearchWithAfterTime(t *testing.T) {
 	storage, _ := setupTestDB(t)
 	defer storage.Close()
@@ -883,3 +933,134 @@ func TestSearchWithCombinedFilters(t *testing.T) {
 		t.Errorf("Search() returned wrong event: branch=%s", results[0].Event.Branch)
 	}
 }
+
+func TestSearchScopes(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	note := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	note.Payload["text"] = "decided to retry the migration tomorrow"
+	if err := storage.InsertEvent(note); err != nil {
+		t.Fatalf("InsertEvent(note) error: %v", err)
+	}
+
+	summary := &Summary{
+		PeriodStart:  start,
+		PeriodEnd:    start.Add(30 * time.Minute),
+		ContextStart: start.Add(-time.Hour),
+		Repos:        []string{"/src/api"},
+		Text:         "Debugged a failing schema migration in the API",
+	}
+	if err := storage.InsertSummaryContext(ctx, summary); err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+
+	tests := []struct {
+		scope         SearchScope
+		wantEvents    int
+		wantSummaries int
+	}{
+		{"", 1, 0},
+		{ScopeEvents, 1, 0},
+		{ScopeSummaries, 0, 1},
+		{ScopeAll, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.scope), func(t *testing.T) {
+			results, err := storage.Search(ctx, SearchOptions{
+				Query:     "migration",
+				Scope:     tt.scope,
+				SortOrder: SortByRelevance,
+			})
+			if err != nil {
+				t.Fatalf("Search() error: %v", err)
+			}
+
+			var gotEvents, gotSummaries int
+			for _, r := range results {
+				switch {
+				case r.Summary != nil:
+					gotSummaries++
+				case r.Event != nil:
+					gotEvents++
+				}
+			}
+			if gotEvents != tt.wantEvents || gotSummaries != tt.wantSummaries {
+				t.Errorf("got %d events and %d summaries, want %d and %d",
+					gotEvents, gotSummaries, tt.wantEvents, tt.wantSummaries)
+			}
+		})
+	}
+}
+
+func TestSearchSummariesReindexOnUpdate(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	summary := &Summary{
+		PeriodStart:  start,
+		PeriodEnd:    start.Add(30 * time.Minute),
+		ContextStart: start,
+		Text:         "Refactored the scheduler",
+	}
+	if err := storage.InsertSummaryContext(ctx, summary); err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+
+	summary.Text = "Rewrote the webhook receiver"
+	if err := storage.InsertSummaryContext(ctx, summary); err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+
+	stale, err := storage.Search(ctx, SearchOptions{Query: "scheduler", Scope: ScopeSummaries})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(stale) != 0 {
+		t.Errorf("got %d results for replaced text, want 0", len(stale))
+	}
+
+	fresh, err := storage.Search(ctx, SearchOptions{Query: "webhook", Scope: ScopeSummaries})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(fresh) != 1 {
+		t.Fatalf("got %d results for new text, want 1", len(fresh))
+	}
+	if fresh[0].Summary.Text != summary.Text {
+		t.Errorf("Summary.Text = %q, want %q", fresh[0].Summary.Text, summary.Text)
+	}
+}
+
+func TestSearchSummariesRejectsEventFilters(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	_, err := storage.Search(context.Background(), SearchOptions{
+		Query:   "deploy",
+		Scope:   ScopeSummaries,
+		Modules: []string{"git"},
+	})
+	if err == nil {
+		t.Error("Search() expected error for module filter with summaries scope")
+	}
+}
+
+func TestParseSearchScope(t *testing.T) {
+	if scope, err := ParseSearchScope(""); err != nil || scope != ScopeEvents {
+		t.Errorf("ParseSearchScope(\"\") = %q, %v; want events", scope, err)
+	}
+	if scope, err := ParseSearchScope("all"); err != nil || scope != ScopeAll {
+		t.Errorf("ParseSearchScope(\"all\") = %q, %v; want all", scope, err)
+	}
+	if _, err := ParseSearchScope("notes"); err == nil {
+		t.Error("ParseSearchScope(\"notes\") expected error")
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 	_ "modernc.org/sqlite"
 
+	"devlog/internal/encryption"
 	"devlog/internal/errors"
 )
 
@@ -22,7 +23,9 @@ const (
 )
 
 type Storage struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *encryption.Cipher
+	cache  *queryCache
 }
 
 type stdoutMigrationLogger struct{}
@@ -37,6 +40,34 @@ func New(dbPath string) (*Storage, error) {
 		return nil, fmt.Errorf("database does not exist at %s (run with --init to create)", dbPath)
 	}
 
+	db, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := migrate(db, dbPath, MigrateOptions{}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if _, err := db.Exec("PRAGMA optimize"); err != nil {
+		db.Close()
+		return nil, errors.WrapStorage("optimize database", err)
+	}
+
+	s := &Storage{
+		db: db,
+	}
+
+	if err := s.loadPayloadCipher(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func openDB(dbPath string) (*sql.DB, error) {
 	db, err := sql.Open("sqlite", dbPath)
 	if err != nil {
 		return nil, errors.WrapStorage("open database", err)
@@ -56,19 +87,7 @@ func New(dbPath string) (*Storage, error) {
 		return nil, errors.WrapStorage("set synchronous mode", err)
 	}
 
-	if err := RunMigrations(db, nil); err != nil {
-		db.Close()
-		return nil, errors.WrapStorage("run migrations", err)
-	}
-
-	if _, err := db.Exec("PRAGMA optimize"); err != nil {
-		db.Close()
-		return nil, errors.WrapStorage("optimize database", err)
-	}
-
-	return &Storage{
-		db: db,
-	}, nil
+	return db, nil
 }
 
 func InitDB(dbPath string) error {
@@ -81,25 +100,108 @@ func InitDB(dbPath string) error {
 		return errors.WrapStorage("create database directory", err)
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := openDB(dbPath)
 	if err != nil {
-		return errors.WrapStorage("create database", err)
+		return err
 	}
 	defer db.Close()
 
-	if _, err := db.Exec("PRAGMA journal_mode=WAL"); err != nil {
-		return errors.WrapStorage("enable WAL mode", err)
+	if _, err := migrate(db, dbPath, MigrateOptions{Logger: &stdoutMigrationLogger{}}); err != nil {
+		return err
 	}
 
-	if _, err := db.Exec("PRAGMA synchronous=NORMAL"); err != nil {
-		return errors.WrapStorage("set synchronous mode", err)
+	fmt.Printf("Created database at %s\n", dbPath)
+	return nil
+}
+
+type MigrateOptions struct {
+	NoBackup bool
+	Logger   MigrationLogger
+}
+
+type MigrateResult struct {
+	From    int
+	To      int
+	Applied []Migration
+	Backup  string // copy taken before migrating, empty if none was needed
+}
+
+// Migrate applies pending schema migrations to an existing database. New
+// does the same on open; this is for upgrading ahead of restarting the
+// daemon and reporting what changed.
+func Migrate(dbPath string, opts MigrateOptions) (*MigrateResult, error) {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("database does not exist at %s (run with --init to create)", dbPath)
 	}
 
-	if err := RunMigrations(db, &stdoutMigrationLogger{}); err != nil {
-		return errors.WrapStorage("run migrations", err)
+	db, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
 	}
+	defer db.Close()
 
-	fmt.Printf("Created database at %s\n", dbPath)
+	return migrate(db, dbPath, opts)
+}
+
+// MigrationStatus reports which schema migrations have been applied to the
+// database without changing it.
+func MigrationStatus(dbPath string) (*SchemaStatus, error) {
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("database does not exist at %s (run with --init to create)", dbPath)
+	}
+
+	db, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	status, err := readSchemaStatus(db, migrations)
+	if err != nil {
+		return nil, errors.WrapStorage("read schema status", err)
+	}
+	return status, nil
+}
+
+// migrate copies a database that already has a schema to <path>.v<N>.bak
+// before upgrading it, so a migration that goes wrong on real data can be
+// undone by restoring the copy.
+func migrate(db *sql.DB, dbPath string, opts MigrateOptions) (*MigrateResult, error) {
+	current, err := getCurrentVersion(db)
+	if err != nil {
+		return nil, errors.WrapStorage("run migrations", err)
+	}
+	pending, err := pendingMigrations(migrations, current)
+	if err != nil {
+		return nil, errors.WrapStorage("run migrations", err)
+	}
+
+	result := &MigrateResult{From: current, To: current}
+	if len(pending) > 0 && current > 0 && !opts.NoBackup {
+		result.Backup = fmt.Sprintf("%s.v%d.bak", dbPath, current)
+		if err := backupDatabase(db, result.Backup); err != nil {
+			return nil, err
+		}
+	}
+
+	applied, err := runMigrations(db, migrations, opts.Logger)
+	result.Applied = applied
+	if len(applied) > 0 {
+		result.To = applied[len(applied)-1].Version
+	}
+	if err != nil {
+		return result, errors.WrapStorage("run migrations", err)
+	}
+	return result, nil
+}
+
+func backupDatabase(db *sql.DB, backupPath string) error {
+	if err := os.Remove(backupPath); err != nil && !os.IsNotExist(err) {
+		return errors.WrapStorage("remove old backup", err)
+	}
+	if _, err := db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return errors.WrapStorage("back up database", err)
+	}
 	return nil
 }
 
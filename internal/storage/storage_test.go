@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -119,6 +120,82 @@ func TestInsertAndGetEvent(t *testing.T) {
 	}
 }
 
+func TestEventLinks(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	insert := func(eventType events.EventType, at time.Duration, session, parent string) *events.Event {
+		t.Helper()
+		event := events.NewEvent(string(events.SourceClaude), string(eventType))
+		event.Timestamp = base.Add(at).Format(time.RFC3339)
+		event.SessionID = session
+		event.ParentID = parent
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+		return event
+	}
+
+	conv := insert(events.TypeConversation, 0, "s1", "")
+	cmd := insert(events.TypeCommand, time.Minute, "s1", conv.ID)
+	edit := insert(events.TypeFileEdit, 2*time.Minute, "s1", conv.ID)
+	insert(events.TypeConversation, 3*time.Minute, "s2", "")
+
+	failed := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	failed.DurationMs = 2500
+	failed.Severity = string(events.SeverityError)
+	failed.Payload["command"] = "make test"
+	if err := store.InsertEvent(failed); err != nil {
+		t.Fatalf("InsertEvent() error: %v", err)
+	}
+
+	got, err := store.GetEventContext(ctx, failed.ID)
+	if err != nil {
+		t.Fatalf("GetEventContext() error: %v", err)
+	}
+	if got.DurationMs != 2500 || got.Severity != "error" || got.SessionID != "" || got.ParentID != "" {
+		t.Errorf("round trip = %+v", got)
+	}
+
+	related, err := store.RelatedEventsContext(ctx, cmd.ID)
+	if err != nil {
+		t.Fatalf("RelatedEventsContext() error: %v", err)
+	}
+	if len(related) != 2 || related[0].ID != conv.ID || related[1].ID != edit.ID {
+		t.Errorf("related to command = %v, want the conversation and the edit", eventIDs(related))
+	}
+
+	children, err := store.QueryEventsContext(ctx, QueryOptions{ParentID: conv.ID, Ascending: true})
+	if err != nil {
+		t.Fatalf("QueryEventsContext() error: %v", err)
+	}
+	if len(children) != 2 || children[0].ID != cmd.ID {
+		t.Errorf("children = %v", eventIDs(children))
+	}
+
+	errs, err := store.QueryEventsContext(ctx, QueryOptions{Severity: "error"})
+	if err != nil {
+		t.Fatalf("QueryEventsContext() error: %v", err)
+	}
+	if len(errs) != 1 || errs[0].ID != failed.ID {
+		t.Errorf("severity filter = %v", eventIDs(errs))
+	}
+
+	if _, err := store.RelatedEventsContext(ctx, "missing"); !errors.Is(err, ErrEventNotFound) {
+		t.Errorf("missing event error = %v, want ErrEventNotFound", err)
+	}
+}
+
+func eventIDs(evts []*events.Event) []string {
+	ids := make([]string, len(evts))
+	for i, e := range evts {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
 func TestInsertInvalidEvent(t *testing.T) {
 	storage, _ := setupTestDB(t)
 	defer storage.Close()
@@ -138,6 +215,37 @@ func TestInsertInvalidEvent(t *testing.T) {
 	}
 }
 
+func TestInsertEventsBatch(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	ctx := context.Background()
+
+	existing := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	if err := store.InsertEvent(existing); err != nil {
+		t.Fatal(err)
+	}
+	fresh := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	invalid := events.NewEvent("", string(events.TypeCommit))
+
+	results, err := store.InsertEventsContext(ctx, []*events.Event{fresh, existing, invalid})
+	if err != nil {
+		t.Fatalf("InsertEventsContext() error: %v", err)
+	}
+	if results[0] != nil || results[1] != ErrDuplicateEvent || results[2] == nil {
+		t.Errorf("results = %v, want [nil, duplicate, error]", results)
+	}
+
+	count, _ := store.CountContext(ctx)
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+
+	var mode int
+	if err := store.db.QueryRowContext(ctx, "PRAGMA synchronous").Scan(&mode); err != nil || mode != 1 {
+		t.Errorf("synchronous = %d (%v), want NORMAL restored", mode, err)
+	}
+}
+
 func TestGetNonExistentEvent(t *testing.T) {
 	storage, _ := setupTestDB(t)
 	defer storage.Close()
@@ -277,20 +385,133 @@ func TestCountBySource(t *testing.T) {
 	}
 }
 
-func TestTimelineLast7Days(t *testing.T) {
-	storage, _ := setupTestDB(t)
-	defer storage.Close()
+func TestTimelineBuckets(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	// 2025-05-21 is a Wednesday.
+	base := time.Date(2025, 5, 21, 0, 0, 0, 0, time.UTC)
+	for _, e := range []struct {
+		source string
+		at     time.Duration
+	}{
+		{string(events.SourceGit), 9*time.Hour + 5*time.Minute},
+		{string(events.SourceShell), 9*time.Hour + 5*time.Minute + 30*time.Second},
+		{string(events.SourceShell), 9*time.Hour + 40*time.Minute},
+		{string(events.SourceShell), 11 * time.Hour},
+		{string(events.SourceGit), 6 * 24 * time.Hour},
+	} {
+		event := events.NewEvent(e.source, string(events.TypeCommit))
+		event.Timestamp = base.Add(e.at).Format(time.RFC3339)
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
 
-	for i := 0; i < 10; i++ {
-		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
-		if err := storage.InsertEvent(event); err != nil {
-			t.Fatal(err)
+	ctx := context.Background()
+
+	t.Run("minute", func(t *testing.T) {
+		start := base.Add(9 * time.Hour)
+		points, err := store.TimelineBuckets(ctx, start, start.Add(time.Hour), BucketMinute)
+		if err != nil {
+			t.Fatalf("TimelineBuckets() error: %v", err)
+		}
+		if len(points) != 60 {
+			t.Fatalf("got %d buckets, want 60", len(points))
+		}
+		if p := points[5]; p.Count != 2 || p.BySource["git"] != 1 || p.BySource["shell"] != 1 {
+			t.Errorf("09:05 bucket = %+v, want 1 git and 1 shell", p)
+		}
+		if points[40].Count != 1 || points[6].Count != 0 {
+			t.Errorf("09:40 = %d, 09:06 = %d, want 1 and 0", points[40].Count, points[6].Count)
+		}
+	})
+
+	t.Run("hour", func(t *testing.T) {
+		points, err := store.TimelineBuckets(ctx, base, base.AddDate(0, 0, 1), BucketHour)
+		if err != nil {
+			t.Fatalf("TimelineBuckets() error: %v", err)
+		}
+		if len(points) != 24 {
+			t.Fatalf("got %d buckets, want 24", len(points))
+		}
+		if points[9].Count != 3 || points[11].Count != 1 || points[10].Count != 0 {
+			t.Errorf("hours 9/10/11 = %d/%d/%d, want 3/0/1", points[9].Count, points[10].Count, points[11].Count)
+		}
+		if !points[9].Start.Equal(base.Add(9 * time.Hour)) {
+			t.Errorf("hour 9 start = %v", points[9].Start)
+		}
+	})
+
+	t.Run("day", func(t *testing.T) {
+		points, err := store.TimelineBuckets(ctx, base, base.AddDate(0, 0, 7), BucketDay)
+		if err != nil {
+			t.Fatalf("TimelineBuckets() error: %v", err)
+		}
+		if len(points) != 7 {
+			t.Fatalf("got %d buckets, want 7", len(points))
+		}
+		if points[0].Count != 4 || points[0].BySource["shell"] != 3 || points[6].Count != 1 {
+			t.Errorf("days = %+v", points)
+		}
+	})
+
+	t.Run("week starts on monday", func(t *testing.T) {
+		points, err := store.TimelineBuckets(ctx, base, base.AddDate(0, 0, 14), BucketWeek)
+		if err != nil {
+			t.Fatalf("TimelineBuckets() error: %v", err)
+		}
+		if len(points) != 3 {
+			t.Fatalf("got %d buckets, want 3", len(points))
+		}
+		if want := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC); !points[0].Start.Equal(want) {
+			t.Errorf("first week starts %v, want %v", points[0].Start, want)
+		}
+		if points[0].Count != 4 || points[1].Count != 1 {
+			t.Errorf("weeks = %d, %d, want 4, 1", points[0].Count, points[1].Count)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := store.TimelineBuckets(ctx, base, base.Add(time.Hour), "month"); err == nil {
+			t.Error("expected error for unknown bucket")
+		}
+		if _, err := store.TimelineBuckets(ctx, base, base, BucketHour); err == nil {
+			t.Error("expected error for empty range")
+		}
+		if _, err := store.TimelineBuckets(ctx, base, base.AddDate(1, 0, 0), BucketMinute); err == nil {
+			t.Error("expected error for too many buckets")
+		}
+	})
+}
+
+func TestActivityDistribution(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	// 2025-05-21 is a Wednesday.
+	base := time.Date(2025, 5, 21, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Duration{9 * time.Hour, 9*time.Hour + 30*time.Minute, 7*24*time.Hour + 9*time.Hour, 3*24*time.Hour + 22*time.Hour} {
+		event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+		event.Timestamp = base.Add(at).Format(time.RFC3339)
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
 		}
 	}
 
-	_, err := storage.TimelineLast7Days(context.Background())
+	grid, err := store.ActivityDistribution(context.Background(), base, base.AddDate(0, 0, 14))
 	if err != nil {
-		t.Fatalf("TimelineLast7Days() error: %v", err)
+		t.Fatalf("ActivityDistribution() error: %v", err)
+	}
+	if grid[time.Wednesday][9] != 3 {
+		t.Errorf("Wednesday 09:00 = %d, want 3", grid[time.Wednesday][9])
+	}
+	if grid[time.Saturday][22] != 1 {
+		t.Errorf("Saturday 22:00 = %d, want 1", grid[time.Saturday][22])
+	}
+
+	if _, err := store.ActivityDistribution(context.Background(), base, base); err == nil {
+		t.Error("expected an error for an empty range")
 	}
 }
 
@@ -609,3 +830,163 @@ func TestDatabaseRecoveryAfterError(t *testing.T) {
 		t.Errorf("Expected 2 valid events, got %d", count)
 	}
 }
+
+func TestActivityBuckets(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	base := time.Date(2025, 5, 19, 9, 0, 0, 0, time.UTC)
+	for i, offset := range []time.Duration{5 * time.Minute, 20 * time.Minute, 70 * time.Minute, 48 * time.Hour} {
+		event := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+		event.Timestamp = base.Add(offset).Format(time.RFC3339)
+		event.Repo = "/src/api"
+		event.Payload["message"] = fmt.Sprintf("commit %d", i)
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	buckets, err := store.ActivityBucketsContext(context.Background(), base, base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("ActivityBucketsContext() error: %v", err)
+	}
+	if len(buckets) != 2 {
+		t.Fatalf("got %d buckets, want 2", len(buckets))
+	}
+	if !buckets[0].Hour.Equal(base) || buckets[0].Count != 2 {
+		t.Errorf("first bucket = %+v, want 2 events at %v", buckets[0], base)
+	}
+	if buckets[1].Repo != "/src/api" || buckets[1].Count != 1 {
+		t.Errorf("second bucket = %+v, want 1 event in /src/api", buckets[1])
+	}
+}
+
+func TestDailyMetrics(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	base := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	insert := func(source, eventType string, at time.Duration, exitCode int) {
+		t.Helper()
+		event := events.NewEvent(source, eventType)
+		event.Timestamp = base.Add(at).Format(time.RFC3339)
+		if source == string(events.SourceShell) {
+			event.Payload["command"] = "make test"
+			event.Payload["exit_code"] = exitCode
+		} else {
+			event.Payload["message"] = "commit"
+		}
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	insert(string(events.SourceShell), string(events.TypeCommand), 9*time.Hour, 0)
+	insert(string(events.SourceShell), string(events.TypeCommand), 9*time.Hour+10*time.Minute, 2)
+	insert(string(events.SourceGit), string(events.TypeCommit), 9*time.Hour+30*time.Minute, 0)
+	insert(string(events.SourceShell), string(events.TypeCommand), 14*time.Hour, 1)
+	insert(string(events.SourceGit), string(events.TypeCommit), 48*time.Hour+time.Hour, 0)
+
+	days, err := store.DailyMetricsContext(context.Background(), base, base.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("DailyMetricsContext() error: %v", err)
+	}
+	if len(days) != 3 {
+		t.Fatalf("got %d days, want 3", len(days))
+	}
+
+	first := days[0]
+	if first.Events != 4 || first.ActiveHours != 2 || first.Failures != 2 {
+		t.Errorf("first day = %+v, want 4 events, 2 active hours, 2 failures", first)
+	}
+	if first.BySource["shell"] != 3 || first.BySource["git"] != 1 {
+		t.Errorf("first day by source = %v", first.BySource)
+	}
+	if days[1].Events != 0 || !days[1].Date.Equal(base.AddDate(0, 0, 1)) {
+		t.Errorf("second day = %+v, want empty day", days[1])
+	}
+	if days[2].Events != 1 || days[2].Failures != 0 {
+		t.Errorf("third day = %+v, want 1 event", days[2])
+	}
+}
+
+func TestDailyMetricsActiveMinutes(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	base := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	endSession := func(at time.Duration, length time.Duration) {
+		t.Helper()
+		event := events.NewEvent(string(events.SourceActivity), string(events.TypeSessionEnd))
+		event.Timestamp = base.Add(at).Format(time.RFC3339)
+		event.Payload["duration_seconds"] = int(length.Seconds())
+		if err := store.InsertEvent(event); err != nil {
+			t.Fatalf("InsertEvent() error: %v", err)
+		}
+	}
+
+	endSession(12*time.Hour, 3*time.Hour)
+	endSession(14*time.Hour+30*time.Minute, 90*time.Minute)
+	// Runs past midnight into the second day.
+	endSession(24*time.Hour+30*time.Minute, time.Hour)
+
+	days, err := store.DailyMetricsContext(context.Background(), base, base.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("DailyMetricsContext() error: %v", err)
+	}
+	if days[0].ActiveMinutes != 180+90+30 {
+		t.Errorf("first day active minutes = %d, want 300", days[0].ActiveMinutes)
+	}
+	if days[1].ActiveMinutes != 30 {
+		t.Errorf("second day active minutes = %d, want 30", days[1].ActiveMinutes)
+	}
+}
+
+func TestLLMUsageByDay(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	ctx := context.Background()
+	base := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	record := func(caller string, at time.Duration, input, output int, cost float64) {
+		t.Helper()
+		err := store.InsertLLMUsageContext(ctx, &LLMUsage{
+			Timestamp:    base.Add(at),
+			Caller:       caller,
+			Provider:     "anthropic",
+			Model:        "claude-haiku-4-5",
+			InputTokens:  input,
+			OutputTokens: output,
+			CostUSD:      cost,
+		})
+		if err != nil {
+			t.Fatalf("InsertLLMUsageContext() error: %v", err)
+		}
+	}
+
+	record("summarizer", 9*time.Hour, 1000, 100, 0.0015)
+	record("query", 10*time.Hour, 500, 50, 0.00075)
+	record("summarizer", 11*time.Hour, 2000, 200, 0.003)
+	record("summarizer", 30*time.Hour, 100, 10, 0.00015)
+	record("summarizer", 80*time.Hour, 100, 10, 0.00015)
+
+	days, err := store.LLMUsageByDayContext(ctx, base, base.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("LLMUsageByDayContext() error: %v", err)
+	}
+	if len(days) != 3 {
+		t.Fatalf("got %d rows, want 3: %+v", len(days), days)
+	}
+
+	first := days[0]
+	if !first.Date.Equal(base) || first.Caller != "summarizer" || first.Completions != 2 ||
+		first.InputTokens != 3000 || first.OutputTokens != 300 {
+		t.Errorf("first row = %+v", first)
+	}
+	if days[1].Caller != "query" || days[1].Completions != 1 {
+		t.Errorf("second row = %+v", days[1])
+	}
+	if !days[2].Date.Equal(base.AddDate(0, 0, 1)) || days[2].Completions != 1 {
+		t.Errorf("third row = %+v", days[2])
+	}
+}
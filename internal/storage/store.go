@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+)
+
+// Store is the event and summary storage the daemon, its API and the
+// summarizer work against. *Storage implements it on the local SQLite
+// database and *PostgresStore on a shared Postgres database.
+type Store interface {
+	InsertEvent(event *events.Event) error
+	InsertEventContext(ctx context.Context, event *events.Event) error
+	InsertEventsContext(ctx context.Context, evts []*events.Event) ([]error, error)
+	GetEventContext(ctx context.Context, id string) (*events.Event, error)
+	QueryEventsContext(ctx context.Context, opts QueryOptions) ([]*events.Event, error)
+	RelatedEventsContext(ctx context.Context, id string) ([]*events.Event, error)
+	EventsAfterRowContext(ctx context.Context, afterRow int64, limit int) ([]*events.Event, int64, error)
+	MaxEventRowContext(ctx context.Context) (int64, error)
+	Count() (int, error)
+	CountContext(ctx context.Context) (int, error)
+	Search(ctx context.Context, opts SearchOptions) ([]*SearchResult, error)
+
+	CountBySource(ctx context.Context) ([]SourceCount, error)
+	TimelineBuckets(ctx context.Context, start, end time.Time, bucket string) ([]TimelinePoint, error)
+	ActivityDistribution(ctx context.Context, start, end time.Time) ([7][24]int, error)
+	ActivityBucketsContext(ctx context.Context, start, end time.Time) ([]ActivityBucket, error)
+	TopRepos(ctx context.Context, limit int) ([]RepoStats, error)
+	TopCommands(ctx context.Context, limit int) ([]CommandStats, error)
+	TopFiles(ctx context.Context, start, end time.Time, limit int) ([]FileStats, error)
+	TopLanguages(ctx context.Context, start, end time.Time, limit int) ([]LanguageStats, error)
+
+	AddAnnotationContext(ctx context.Context, eventID, text string) (*Annotation, error)
+	AnnotationsContext(ctx context.Context, eventIDs []string) (map[string][]Annotation, error)
+
+	QuerySummariesContext(ctx context.Context, start, end time.Time) ([]*Summary, error)
+	ReplaceSummariesContext(ctx context.Context, start, end time.Time, summaries []*Summary) error
+	RecordSummaryWindowContext(ctx context.Context, start, end time.Time, eventCount int) error
+	LastSummaryWindowEndContext(ctx context.Context) (time.Time, error)
+	SummaryCoverageContext(ctx context.Context, start, end time.Time) (time.Time, error)
+	DeleteSummaryWindowsContext(ctx context.Context, start, end time.Time) error
+
+	Close() error
+}
+
+// Maintainer is implemented by stores that need periodic maintenance from
+// the daemon. Postgres leaves it to autovacuum.
+type Maintainer interface {
+	MaintainContext(ctx context.Context, opts MaintenanceOptions) (*MaintenanceResult, error)
+}
+
+// Backuper is implemented by stores the daemon can copy to a file on a
+// schedule. Postgres backups are left to pg_dump.
+type Backuper interface {
+	BackupContext(ctx context.Context, dest string, opts BackupOptions) (*BackupInfo, error)
+}
+
+var (
+	_ Store      = (*Storage)(nil)
+	_ Store      = (*PostgresStore)(nil)
+	_ Maintainer = (*Storage)(nil)
+	_ Backuper   = (*Storage)(nil)
+)
+
+// Open opens the store selected by cfg: the SQLite database in dataDir, or
+// the configured Postgres database.
+func Open(dataDir string, cfg config.StorageConfig) (Store, error) {
+	switch cfg.BackendName() {
+	case config.StorageSQLite:
+		dbPath := filepath.Join(dataDir, "events.db")
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("database does not exist (run 'devlog init' first)")
+		}
+		return New(dbPath)
+	case config.StoragePostgres:
+		return NewPostgres(cfg.PostgresDSN())
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// OpenConfigured opens the store selected in the config file, for callers
+// outside the daemon that have no config of their own at hand.
+func OpenConfigured(dataDir string) (Store, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return Open(dataDir, cfg.Storage)
+}
+
+// ErrSQLiteOnly is returned by OpenSQLite when the config selects Postgres.
+var ErrSQLiteOnly = fmt.Errorf("this needs the local SQLite database and is not available with the postgres storage backend")
+
+// OpenSQLite opens the SQLite database in dataDir for features that work on
+// the database file itself, such as backups and archives. It fails with
+// ErrSQLiteOnly when the config selects another backend, rather than
+// quietly opening a local database nothing else writes to.
+func OpenSQLite(dataDir string) (*Storage, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	if cfg.Storage.BackendName() != config.StorageSQLite {
+		return nil, ErrSQLiteOnly
+	}
+	return New(filepath.Join(dataDir, "events.db"))
+}
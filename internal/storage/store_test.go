@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+)
+
+// useStorageConfig points the config file at a temporary home that selects
+// the given storage, and returns the data directory.
+func useStorageConfig(t *testing.T, storageCfg config.StorageConfig) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", home)
+
+	configDir, err := config.ConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Storage = storageCfg
+	if err := cfg.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dataDir
+}
+
+func TestOpenConfiguredSQLite(t *testing.T) {
+	dataDir := useStorageConfig(t, config.StorageConfig{})
+
+	if _, err := OpenConfigured(dataDir); err == nil {
+		t.Fatal("OpenConfigured() before init succeeded, want an error")
+	}
+
+	if err := InitDB(filepath.Join(dataDir, "events.db")); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := OpenConfigured(dataDir)
+	if err != nil {
+		t.Fatalf("OpenConfigured() error: %v", err)
+	}
+	defer store.Close()
+	if _, ok := store.(*Storage); !ok {
+		t.Errorf("OpenConfigured() = %T, want *Storage", store)
+	}
+}
+
+func TestOpenSQLiteRefusesPostgres(t *testing.T) {
+	dataDir := useStorageConfig(t, config.StorageConfig{
+		Backend:  config.StoragePostgres,
+		Postgres: config.PostgresConfig{DSN: "postgres://localhost/devlog"},
+	})
+
+	if _, err := OpenSQLite(dataDir); !errors.Is(err, ErrSQLiteOnly) {
+		t.Errorf("OpenSQLite() error = %v, want ErrSQLiteOnly", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "events.db")); !os.IsNotExist(err) {
+		t.Errorf("OpenSQLite() left a local database behind: %v", err)
+	}
+}
+
+// TestOpenConfiguredPostgres checks that callers going through the config
+// reach the Postgres database when it is selected. It runs when built with
+// -tags postgres and DEVLOG_TEST_POSTGRES_DSN names a scratch database.
+func TestOpenConfiguredPostgres(t *testing.T) {
+	dsn := os.Getenv("DEVLOG_TEST_POSTGRES_DSN")
+	if dsn == "" || !slices.Contains(sql.Drivers(), postgresDriver) {
+		t.Skip("set DEVLOG_TEST_POSTGRES_DSN and build with -tags postgres")
+	}
+	dataDir := useStorageConfig(t, config.StorageConfig{
+		Backend:  config.StoragePostgres,
+		Postgres: config.PostgresConfig{DSN: dsn},
+	})
+
+	store, err := OpenConfigured(dataDir)
+	if err != nil {
+		t.Fatalf("OpenConfigured() error: %v", err)
+	}
+	defer store.Close()
+	if _, ok := store.(*PostgresStore); !ok {
+		t.Fatalf("OpenConfigured() = %T, want *PostgresStore", store)
+	}
+
+	ctx := context.Background()
+	event := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	event.Repo = "pgopen-" + event.ID
+	event.Payload = map[string]interface{}{"text": "routed through the config"}
+	if err := store.InsertEventContext(ctx, event); err != nil {
+		t.Fatalf("InsertEventContext() error: %v", err)
+	}
+	if got, err := store.GetEventContext(ctx, event.ID); err != nil || got.Payload["text"] != "routed through the config" {
+		t.Errorf("GetEventContext() = %+v, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "events.db")); !os.IsNotExist(err) {
+		t.Errorf("OpenConfigured() created a local database: %v", err)
+	}
+}
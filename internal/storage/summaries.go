@@ -0,0 +1,396 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"devlog/internal/errors"
+)
+
+type Summary struct {
+	ID           int64
+	PeriodStart  time.Time
+	PeriodEnd    time.Time
+	ContextStart time.Time
+	// Repo is set on summaries the summarizer wrote for one repo of a
+	// period (per_repo mode); it is empty for a summary of the whole period.
+	Repo              string
+	Repos             []string
+	Text              string
+	EventCount        int
+	ContextEventCount int
+	Provider          string
+	InputTokens       int
+	OutputTokens      int
+	CreatedAt         time.Time
+}
+
+func (s *Storage) InsertSummaryContext(ctx context.Context, summary *Summary) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	return insertSummary(ctx, s.db, summary)
+}
+
+// ReplaceSummariesContext stores summaries as the only summaries of the
+// period [start, end), removing any others stored for it, e.g. a whole-period
+// summary once the period has been summarized per repo.
+func (s *Storage) ReplaceSummariesContext(ctx context.Context, start, end time.Time, summaries []*Summary) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM summaries WHERE period_start = ? AND period_end = ?", start.Unix(), end.Unix()); err != nil {
+		return errors.WrapStorage("delete summaries", err)
+	}
+	for _, summary := range summaries {
+		if err := insertSummary(ctx, tx, summary); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapStorage("commit summaries", err)
+	}
+	return nil
+}
+
+func insertSummary(ctx context.Context, db interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, summary *Summary) error {
+	args, err := summaryInsertArgs(summary)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO summaries (
+			period_start, period_end, context_start, repos, summary,
+			event_count, context_event_count, provider, input_tokens, output_tokens, created_at, repo
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(period_start, period_end, repo) DO UPDATE SET
+			context_start = excluded.context_start,
+			repos = excluded.repos,
+			summary = excluded.summary,
+			event_count = excluded.event_count,
+			context_event_count = excluded.context_event_count,
+			provider = excluded.provider,
+			input_tokens = excluded.input_tokens,
+			output_tokens = excluded.output_tokens,
+			created_at = excluded.created_at
+		RETURNING id
+	`
+
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&summary.ID); err != nil {
+		return errors.WrapStorage("insert summary", err)
+	}
+
+	return nil
+}
+
+// summaryInsertArgs returns the column values of a summary in the order
+// the summary INSERT statements list them.
+func summaryInsertArgs(summary *Summary) ([]interface{}, error) {
+	repos := summary.Repos
+	if repos == nil {
+		repos = []string{}
+	}
+	reposJSON, err := json.Marshal(repos)
+	if err != nil {
+		return nil, errors.WrapStorage("serialize summary repos", err)
+	}
+
+	createdAt := summary.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+
+	return []interface{}{
+		summary.PeriodStart.Unix(),
+		summary.PeriodEnd.Unix(),
+		summary.ContextStart.Unix(),
+		string(reposJSON),
+		summary.Text,
+		summary.EventCount,
+		summary.ContextEventCount,
+		summary.Provider,
+		summary.InputTokens,
+		summary.OutputTokens,
+		createdAt.Unix(),
+		summary.Repo,
+	}, nil
+}
+
+func (s *Storage) QuerySummariesContext(ctx context.Context, start, end time.Time) ([]*Summary, error) {
+	query := `
+		SELECT id, period_start, period_end, context_start, repos, summary,
+			event_count, context_event_count, COALESCE(provider, ''), input_tokens, output_tokens, created_at, repo
+		FROM summaries
+		WHERE period_start >= ? AND period_start < ?
+		ORDER BY period_start ASC, repo ASC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("query summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Summary
+	for rows.Next() {
+		summary, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate summaries: %w", err)
+	}
+
+	return result, nil
+}
+
+func scanSummary(scanner interface {
+	Scan(dest ...interface{}) error
+}, extra ...interface{}) (*Summary, error) {
+	var summary Summary
+	var periodStart, periodEnd, contextStart, createdAt int64
+	var reposJSON string
+
+	dest := []interface{}{
+		&summary.ID,
+		&periodStart,
+		&periodEnd,
+		&contextStart,
+		&reposJSON,
+		&summary.Text,
+		&summary.EventCount,
+		&summary.ContextEventCount,
+		&summary.Provider,
+		&summary.InputTokens,
+		&summary.OutputTokens,
+		&createdAt,
+		&summary.Repo,
+	}
+	if err := scanner.Scan(append(dest, extra...)...); err != nil {
+		return nil, fmt.Errorf("scan summary: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(reposJSON), &summary.Repos); err != nil {
+		return nil, fmt.Errorf("parse summary repos: %w", err)
+	}
+
+	summary.PeriodStart = time.Unix(periodStart, 0)
+	summary.PeriodEnd = time.Unix(periodEnd, 0)
+	summary.ContextStart = time.Unix(contextStart, 0)
+	summary.CreatedAt = time.Unix(createdAt, 0)
+	return &summary, nil
+}
+
+func (s *Storage) RecordSummaryWindowContext(ctx context.Context, start, end time.Time, eventCount int) error {
+	query := `
+		INSERT INTO summary_windows (period_start, period_end, event_count, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(period_start, period_end) DO UPDATE SET
+			event_count = excluded.event_count,
+			created_at = excluded.created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, query, start.Unix(), end.Unix(), eventCount, time.Now().Unix()); err != nil {
+		return errors.WrapStorage("record summary window", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) LastSummaryWindowEndContext(ctx context.Context) (time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var end sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(period_end) FROM summary_windows").Scan(&end); err != nil {
+		return time.Time{}, errors.WrapStorage("query last summary window", err)
+	}
+	if !end.Valid {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(end.Int64, 0), nil
+}
+
+func (s *Storage) SummaryCoverageContext(ctx context.Context, start, end time.Time) (time.Time, error) {
+	query := `
+		SELECT MAX(period_end) FROM summary_windows
+		WHERE period_start < ? AND period_end > ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var covered sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, query, end.Unix(), start.Unix()).Scan(&covered); err != nil {
+		return time.Time{}, errors.WrapStorage("query summary coverage", err)
+	}
+	if !covered.Valid {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(covered.Int64, 0), nil
+}
+
+func (s *Storage) DeleteSummaryWindowsContext(ctx context.Context, start, end time.Time) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM summary_windows WHERE period_start >= ? AND period_start < ?",
+		start.Unix(), end.Unix())
+	if err != nil {
+		return errors.WrapStorage("delete summary windows", err)
+	}
+
+	return nil
+}
+
+func (s *Storage) SummariesAfterIDContext(ctx context.Context, afterID int64, limit int) ([]*Summary, error) {
+	query := `
+		SELECT id, period_start, period_end, context_start, repos, summary,
+			event_count, context_event_count, COALESCE(provider, ''), input_tokens, output_tokens, created_at, repo
+		FROM summaries
+		WHERE id > ?
+		ORDER BY id ASC
+		LIMIT ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Summary
+	for rows.Next() {
+		summary, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate summaries: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *Storage) GetSummaryContext(ctx context.Context, id int64) (*Summary, error) {
+	query := `
+		SELECT id, period_start, period_end, context_start, repos, summary,
+			event_count, context_event_count, COALESCE(provider, ''), input_tokens, output_tokens, created_at, repo
+		FROM summaries
+		WHERE id = ?
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	summary, err := scanSummary(s.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		return nil, fmt.Errorf("summary not found: %d: %w", id, err)
+	}
+	return summary, nil
+}
+
+func (s *Storage) MaxSummaryIDContext(ctx context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var id sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(id) FROM summaries").Scan(&id); err != nil {
+		return 0, errors.WrapStorage("query max summary id", err)
+	}
+	return id.Int64, nil
+}
+
+// SummaryDay totals the summaries that start on one calendar day.
+type SummaryDay struct {
+	Day        time.Time // midnight in the requested location
+	Repos      []string
+	EventCount int
+	Summaries  int
+}
+
+// SummaryDaysContext groups every stored summary by the day it starts on in
+// loc, oldest first, without loading the summary text.
+func (s *Storage) SummaryDaysContext(ctx context.Context, loc *time.Location) ([]SummaryDay, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT period_start, repos, event_count
+		FROM summaries
+		ORDER BY period_start ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query summary days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []SummaryDay
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var periodStart int64
+		var reposJSON string
+		var eventCount int
+		if err := rows.Scan(&periodStart, &reposJSON, &eventCount); err != nil {
+			return nil, fmt.Errorf("scan summary day: %w", err)
+		}
+
+		t := time.Unix(periodStart, 0).In(loc)
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		if len(days) == 0 || !days[len(days)-1].Day.Equal(day) {
+			days = append(days, SummaryDay{Day: day})
+			seen = make(map[string]bool)
+		}
+		current := &days[len(days)-1]
+		current.EventCount += eventCount
+		current.Summaries++
+
+		var repos []string
+		if err := json.Unmarshal([]byte(reposJSON), &repos); err != nil {
+			return nil, fmt.Errorf("parse summary repos: %w", err)
+		}
+		for _, repo := range repos {
+			if !seen[repo] {
+				seen[repo] = true
+				current.Repos = append(current.Repos, repo)
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate summary days: %w", err)
+	}
+	return days, nil
+}
@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestInsertAndQuerySummaries(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	summary := &Summary{
+		PeriodStart:       start,
+		PeriodEnd:         start.Add(30 * time.Minute),
+		ContextStart:      start.Add(-time.Hour),
+		Repos:             []string{"/src/api", "/src/web"},
+		Text:              "Worked on the API",
+		EventCount:        12,
+		ContextEventCount: 30,
+		Provider:          "anthropic",
+		InputTokens:       1500,
+		OutputTokens:      120,
+	}
+	if err := storage.InsertSummaryContext(ctx, summary); err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+
+	results, err := storage.QuerySummariesContext(ctx, start.Add(-time.Hour), start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("QuerySummariesContext() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(results))
+	}
+
+	got := results[0]
+	if summary.ID == 0 || got.ID != summary.ID {
+		t.Errorf("inserted ID = %d, stored ID = %d", summary.ID, got.ID)
+	}
+	if got.Text != summary.Text || got.EventCount != 12 || got.ContextEventCount != 30 {
+		t.Errorf("got %+v", got)
+	}
+	if !got.PeriodStart.Equal(start) || !got.PeriodEnd.Equal(start.Add(30*time.Minute)) {
+		t.Errorf("got period %v - %v", got.PeriodStart, got.PeriodEnd)
+	}
+	if len(got.Repos) != 2 || got.Repos[0] != "/src/api" {
+		t.Errorf("got repos %v", got.Repos)
+	}
+	if got.Provider != "anthropic" || got.InputTokens != 1500 || got.OutputTokens != 120 {
+		t.Errorf("got provider %q tokens %d/%d", got.Provider, got.InputTokens, got.OutputTokens)
+	}
+}
+
+func TestInsertSummaryReplacesSamePeriod(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	var ids []int64
+	for _, text := range []string{"first draft", "regenerated"} {
+		summary := &Summary{
+			PeriodStart: start,
+			PeriodEnd:   start.Add(30 * time.Minute),
+			Text:        text,
+		}
+		if err := storage.InsertSummaryContext(ctx, summary); err != nil {
+			t.Fatalf("InsertSummaryContext() error: %v", err)
+		}
+		ids = append(ids, summary.ID)
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("regenerated summary got ID %d, want %d", ids[1], ids[0])
+	}
+
+	results, err := storage.QuerySummariesContext(ctx, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("QuerySummariesContext() error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d summaries, want 1", len(results))
+	}
+	if results[0].Text != "regenerated" {
+		t.Errorf("got text %q, want %q", results[0].Text, "regenerated")
+	}
+	if results[0].Repos == nil || len(results[0].Repos) != 0 {
+		t.Errorf("got repos %v, want empty", results[0].Repos)
+	}
+}
+
+func TestReplaceSummariesPerRepo(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	end := start.Add(30 * time.Minute)
+
+	blended := &Summary{PeriodStart: start, PeriodEnd: end, Text: "Both repos"}
+	if err := storage.InsertSummaryContext(ctx, blended); err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+
+	perRepo := []*Summary{
+		{PeriodStart: start, PeriodEnd: end, Repo: "web", Repos: []string{"web"}, Text: "Web work"},
+		{PeriodStart: start, PeriodEnd: end, Repo: "api", Repos: []string{"api"}, Text: "API work"},
+	}
+	if err := storage.ReplaceSummariesContext(ctx, start, end, perRepo); err != nil {
+		t.Fatalf("ReplaceSummariesContext() error: %v", err)
+	}
+
+	results, err := storage.QuerySummariesContext(ctx, start, end)
+	if err != nil {
+		t.Fatalf("QuerySummariesContext() error: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("got %d summaries, want the blended one replaced by 2 per-repo ones", len(results))
+	}
+	if results[0].Repo != "api" || results[1].Repo != "web" || results[1].Text != "Web work" {
+		t.Errorf("got %q/%q, %q/%q", results[0].Repo, results[0].Text, results[1].Repo, results[1].Text)
+	}
+	if perRepo[0].ID == 0 || perRepo[1].ID == 0 {
+		t.Error("replaced summaries should get IDs")
+	}
+}
+
+func TestSummaryWindows(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	start := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	last, err := storage.LastSummaryWindowEndContext(ctx)
+	if err != nil {
+		t.Fatalf("LastSummaryWindowEndContext() error: %v", err)
+	}
+	if !last.IsZero() {
+		t.Errorf("got last window end %v on empty table, want zero", last)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := storage.RecordSummaryWindowContext(ctx, start, start.Add(30*time.Minute), 5); err != nil {
+			t.Fatalf("RecordSummaryWindowContext() error: %v", err)
+		}
+	}
+	if err := storage.RecordSummaryWindowContext(ctx, start.Add(30*time.Minute), start.Add(time.Hour), 0); err != nil {
+		t.Fatalf("RecordSummaryWindowContext() error: %v", err)
+	}
+
+	last, err = storage.LastSummaryWindowEndContext(ctx)
+	if err != nil {
+		t.Fatalf("LastSummaryWindowEndContext() error: %v", err)
+	}
+	if !last.Equal(start.Add(time.Hour)) {
+		t.Errorf("got last window end %v, want %v", last, start.Add(time.Hour))
+	}
+
+	covered, err := storage.SummaryCoverageContext(ctx, start.Add(15*time.Minute), start.Add(45*time.Minute))
+	if err != nil {
+		t.Fatalf("SummaryCoverageContext() error: %v", err)
+	}
+	if !covered.Equal(start.Add(time.Hour)) {
+		t.Errorf("got coverage %v, want %v", covered, start.Add(time.Hour))
+	}
+
+	covered, err = storage.SummaryCoverageContext(ctx, start.Add(time.Hour), start.Add(90*time.Minute))
+	if err != nil {
+		t.Fatalf("SummaryCoverageContext() error: %v", err)
+	}
+	if !covered.IsZero() {
+		t.Errorf("adjacent window should not count as covered, got %v", covered)
+	}
+
+	if err := storage.DeleteSummaryWindowsContext(ctx, start, start.Add(24*time.Hour)); err != nil {
+		t.Fatalf("DeleteSummaryWindowsContext() error: %v", err)
+	}
+	last, err = storage.LastSummaryWindowEndContext(ctx)
+	if err != nil {
+		t.Fatalf("LastSummaryWindowEndContext() error: %v", err)
+	}
+	if !last.IsZero() {
+		t.Errorf("got last window end %v after delete, want zero", last)
+	}
+}
+
+func TestSummaryDays(t *testing.T) {
+	storage, _ := setupTestDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	day1 := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+	day2 := time.Date(2025, 1, 17, 23, 30, 0, 0, time.UTC)
+	for _, s := range []*Summary{
+		{PeriodStart: day1, PeriodEnd: day1.Add(time.Hour), Repos: []string{"api"}, Text: "a", EventCount: 5},
+		{PeriodStart: day1.Add(2 * time.Hour), PeriodEnd: day1.Add(3 * time.Hour), Repos: []string{"web", "api"}, Text: "b", EventCount: 7},
+		{PeriodStart: day2, PeriodEnd: day2.Add(time.Hour), Repos: []string{}, Text: "c", EventCount: 1},
+	} {
+		if err := storage.InsertSummaryContext(ctx, s); err != nil {
+			t.Fatalf("InsertSummaryContext() error: %v", err)
+		}
+	}
+
+	days, err := storage.SummaryDaysContext(ctx, time.UTC)
+	if err != nil {
+		t.Fatalf("SummaryDaysContext() error: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("got %d days, want 2", len(days))
+	}
+	if days[0].EventCount != 12 || days[0].Summaries != 2 || len(days[0].Repos) != 2 {
+		t.Errorf("day 1 = %+v", days[0])
+	}
+
+	// The same summary falls on the next day further east.
+	east := time.FixedZone("UTC+2", 2*3600)
+	days, err = storage.SummaryDaysContext(ctx, east)
+	if err != nil {
+		t.Fatalf("SummaryDaysContext() error: %v", err)
+	}
+	if got := days[len(days)-1].Day; got.Day() != 18 {
+		t.Errorf("last day = %s, want the 18th in UTC+2", got)
+	}
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"devlog/internal/errors"
+)
+
+// SyncCursorContext returns a named sync position, or 0 if it was never set.
+func (s *Storage) SyncCursorContext(ctx context.Context, name string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	var value int64
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM sync_cursors WHERE name = ?", name).Scan(&value)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.WrapStorage("read sync cursor", err)
+	}
+	return value, nil
+}
+
+func (s *Storage) SetSyncCursorContext(ctx context.Context, name string, value int64) error {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO sync_cursors (name, value) VALUES (?, ?)
+		ON CONFLICT(name) DO UPDATE SET value = excluded.value
+	`, name, value)
+	if err != nil {
+		return errors.WrapStorage("save sync cursor", err)
+	}
+	return nil
+}
+
+// MarkSyncImportedContext records events that arrived from another machine
+// so they are not pushed back to the relay.
+func (s *Storage) MarkSyncImportedContext(ctx context.Context, machine string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WrapStorage("begin sync import", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT OR IGNORE INTO sync_imports (event_id, machine, imported_at) VALUES (?, ?, ?)")
+	if err != nil {
+		return errors.WrapStorage("prepare sync import", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().Unix()
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, id, machine, now); err != nil {
+			return errors.WrapStorage("record sync import", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapStorage("commit sync import", err)
+	}
+	return nil
+}
+
+// SyncImportedContext reports which of ids were imported from another
+// machine.
+func (s *Storage) SyncImportedContext(ctx context.Context, ids []string) (map[string]bool, error) {
+	imported := make(map[string]bool)
+	if len(ids) == 0 {
+		return imported, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeout)
+	defer cancel()
+
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT event_id FROM sync_imports WHERE event_id IN ("+strings.Join(placeholders, ",")+")", args...)
+	if err != nil {
+		return nil, errors.WrapStorage("query sync imports", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapStorage("scan sync import", err)
+		}
+		imported[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapStorage("iterate sync imports", err)
+	}
+	return imported, nil
+}
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"devlog/internal/errors"
+	"devlog/internal/events"
+)
+
+type OutdatedEvents struct {
+	Source         string
+	Version        int
+	CurrentVersion int
+	Count          int
+}
+
+type UpgradeResult struct {
+	Upgraded int
+	Failed   int
+}
+
+func (s *Storage) OutdatedEventsContext(ctx context.Context) ([]OutdatedEvents, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+	defer cancel()
+
+	var result []OutdatedEvents
+	for _, source := range upgradableSources() {
+		current := events.CurrentVersion(source)
+		rows, err := s.db.QueryContext(ctx, `
+			SELECT version, COUNT(*)
+			FROM events
+			WHERE source = ? AND version < ?
+			GROUP BY version
+			ORDER BY version
+		`, source, current)
+		if err != nil {
+			return nil, errors.WrapStorage("count outdated events", err)
+		}
+		for rows.Next() {
+			o := OutdatedEvents{Source: source, CurrentVersion: current}
+			if err := rows.Scan(&o.Version, &o.Count); err != nil {
+				rows.Close()
+				return nil, errors.WrapStorage("scan outdated events", err)
+			}
+			result = append(result, o)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, errors.WrapStorage("iterate outdated events", err)
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Storage) UpgradeEventsContext(ctx context.Context) (*UpgradeResult, error) {
+	result := &UpgradeResult{}
+	for _, source := range upgradableSources() {
+		var lastRowID int64
+		for {
+			batchCtx, cancel := context.WithTimeout(ctx, DefaultQueryTimeoutLong)
+			n, last, err := s.upgradeEventBatch(batchCtx, source, lastRowID, result)
+			cancel()
+			if err != nil {
+				return result, err
+			}
+			if n < payloadRewriteBatchSize {
+				break
+			}
+			lastRowID = last
+		}
+	}
+	return result, nil
+}
+
+func (s *Storage) upgradeEventBatch(ctx context.Context, source string, after int64, result *UpgradeResult) (int, int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, after, errors.WrapStorage("begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT rowid, id, timestamp, source, type, repo, branch, payload, version,
+			duration_ms, session_id, parent_id, severity
+		FROM events
+		WHERE source = ? AND version < ? AND rowid > ?
+		ORDER BY rowid
+		LIMIT ?
+	`, source, events.CurrentVersion(source), after, payloadRewriteBatchSize)
+	if err != nil {
+		return 0, after, errors.WrapStorage("select outdated events", err)
+	}
+
+	type row struct {
+		id    int64
+		event *events.Event
+	}
+	var batch []row
+	for rows.Next() {
+		var r row
+		r.event, err = scanStoredEvent(s.cipher, rowidScanner{scanner: rows, rowid: &r.id})
+		if err != nil {
+			rows.Close()
+			return 0, after, errors.WrapStorage("scan event", err)
+		}
+		batch = append(batch, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, after, errors.WrapStorage("iterate events", err)
+	}
+
+	last := after
+	for _, r := range batch {
+		last = r.id
+		if _, err := r.event.Upgrade(); err != nil {
+			result.Failed++
+			continue
+		}
+
+		payloadJSON, err := r.event.PayloadJSON()
+		if err != nil {
+			return 0, after, errors.WrapStorage(fmt.Sprintf("serialize payload at row %d", r.id), err)
+		}
+		if s.cipher != nil {
+			payloadJSON, err = s.cipher.Encrypt(payloadJSON)
+			if err != nil {
+				return 0, after, errors.WrapStorage("encrypt payload", err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE events SET payload = ?, version = ? WHERE rowid = ?",
+			payloadJSON, r.event.Version, r.id); err != nil {
+			return 0, after, errors.WrapStorage("update event", err)
+		}
+		result.Upgraded++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, after, errors.WrapStorage("commit transaction", err)
+	}
+	s.invalidateCache()
+
+	return len(batch), last, nil
+}
+
+func upgradableSources() []string {
+	seen := make(map[string]bool)
+	var sources []string
+	for _, m := range events.PayloadMigrations() {
+		if !seen[m.Source] {
+			seen[m.Source] = true
+			sources = append(sources, m.Source)
+		}
+	}
+	return sources
+}
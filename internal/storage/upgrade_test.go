@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"devlog/internal/events"
+)
+
+func insertLegacyNote(t *testing.T, s *Storage, text string) string {
+	t.Helper()
+	evt := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	evt.Payload["text"] = text
+	if err := s.InsertEventContext(context.Background(), evt); err != nil {
+		t.Fatalf("InsertEventContext() error: %v", err)
+	}
+	if _, err := s.db.Exec(`UPDATE events SET version = 1, payload = json_object('note', ?) WHERE id = ?`, text, evt.ID); err != nil {
+		t.Fatalf("downgrade event: %v", err)
+	}
+	return evt.ID
+}
+
+func TestInsertUpgradesOldPayloads(t *testing.T) {
+	s, _ := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	evt := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	evt.Version = 1
+	evt.Payload["note"] = "from an old client"
+	if err := s.InsertEventContext(ctx, evt); err != nil {
+		t.Fatalf("InsertEventContext() error: %v", err)
+	}
+
+	var version int
+	var payload string
+	if err := s.db.QueryRow("SELECT version, payload FROM events WHERE id = ?", evt.ID).Scan(&version, &payload); err != nil {
+		t.Fatalf("select event: %v", err)
+	}
+	if version != events.CurrentVersion(string(events.SourceManual)) {
+		t.Errorf("stored version = %d, want %d", version, events.CurrentVersion(string(events.SourceManual)))
+	}
+	if payload != `{"text":"from an old client"}` {
+		t.Errorf("stored payload = %s", payload)
+	}
+}
+
+func TestReadUpgradesLazily(t *testing.T) {
+	s, _ := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	id := insertLegacyNote(t, s, "rotated staging certs")
+
+	evt, err := s.GetEventContext(ctx, id)
+	if err != nil {
+		t.Fatalf("GetEventContext() error: %v", err)
+	}
+	if evt.Payload["text"] != "rotated staging certs" {
+		t.Errorf("payload not upgraded on read: %v", evt.Payload)
+	}
+	if _, ok := evt.Payload["note"]; ok {
+		t.Errorf("old key still present: %v", evt.Payload)
+	}
+
+	results, err := s.Search(ctx, SearchOptions{Query: "staging", Limit: 10})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if len(results) != 1 || results[0].Event.Payload["text"] != "rotated staging certs" {
+		t.Errorf("search result not upgraded: %+v", results)
+	}
+
+	var version int
+	if err := s.db.QueryRow("SELECT version FROM events WHERE id = ?", id).Scan(&version); err != nil {
+		t.Fatalf("select version: %v", err)
+	}
+	if version != 1 {
+		t.Errorf("lazy upgrade should not rewrite the row, got version %d", version)
+	}
+}
+
+func TestUpgradeEvents(t *testing.T) {
+	s, _ := setupTestDB(t)
+	defer s.Close()
+	ctx := context.Background()
+
+	for _, text := range []string{"first", "second", "third"} {
+		insertLegacyNote(t, s, text)
+	}
+	current := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	current.Payload["text"] = "already current"
+	if err := s.InsertEventContext(ctx, current); err != nil {
+		t.Fatalf("InsertEventContext() error: %v", err)
+	}
+
+	outdated, err := s.OutdatedEventsContext(ctx)
+	if err != nil {
+		t.Fatalf("OutdatedEventsContext() error: %v", err)
+	}
+	if len(outdated) != 1 || outdated[0].Source != "manual" || outdated[0].Version != 1 || outdated[0].Count != 3 {
+		t.Fatalf("unexpected outdated counts: %+v", outdated)
+	}
+
+	result, err := s.UpgradeEventsContext(ctx)
+	if err != nil {
+		t.Fatalf("UpgradeEventsContext() error: %v", err)
+	}
+	if result.Upgraded != 3 || result.Failed != 0 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	outdated, err = s.OutdatedEventsContext(ctx)
+	if err != nil {
+		t.Fatalf("OutdatedEventsContext() error: %v", err)
+	}
+	if len(outdated) != 0 {
+		t.Errorf("expected no outdated events after upgrade, got %+v", outdated)
+	}
+
+	var payload string
+	if err := s.db.QueryRow("SELECT payload FROM events WHERE json_extract(payload, '$.text') = 'second'").Scan(&payload); err != nil {
+		t.Fatalf("upgraded payload not stored: %v", err)
+	}
+	if payload != `{"text":"second"}` {
+		t.Errorf("stored payload = %s", payload)
+	}
+}
@@ -0,0 +1,178 @@
+package tlscert
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	certFile = "cert.pem"
+	keyFile  = "key.pem"
+
+	validFor = 2 * 365 * 24 * time.Hour
+	// renewBefore regenerates certificates this close to expiry on startup.
+	renewBefore = 30 * 24 * time.Hour
+)
+
+// Paths returns where the self-signed certificate and key live in dir.
+func Paths(dir string) (string, string) {
+	return filepath.Join(dir, certFile), filepath.Join(dir, keyFile)
+}
+
+// LoadOrCreate returns the self-signed certificate in dir, generating a new
+// one when none exists or the existing one is about to expire.
+func LoadOrCreate(dir string, hosts []string) (string, string, error) {
+	certPath, keyPath := Paths(dir)
+
+	cert, err := Load(certPath)
+	if err == nil {
+		if time.Until(cert.NotAfter) > renewBefore {
+			if _, err := os.Stat(keyPath); err == nil {
+				return certPath, keyPath, nil
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return "", "", err
+	}
+
+	if err := Generate(dir, hosts); err != nil {
+		return "", "", err
+	}
+	return certPath, keyPath, nil
+}
+
+// Generate writes a new self-signed certificate for hosts (DNS names or IP
+// addresses) to dir, replacing any existing one.
+func Generate(dir string, hosts []string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generate key: %w", err)
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return fmt.Errorf("generate serial: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"devlog"}, CommonName: "devlog daemon"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(validFor),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return fmt.Errorf("create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("marshal key: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("create tls dir: %w", err)
+	}
+	certPath, keyPath := Paths(dir)
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return fmt.Errorf("write key: %w", err)
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return fmt.Errorf("write certificate: %w", err)
+	}
+	return nil
+}
+
+// Load parses the first certificate in a PEM file.
+func Load(certPath string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, fmt.Errorf("no certificate found in %s", certPath)
+		}
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("parse %s: %w", certPath, err)
+			}
+			return cert, nil
+		}
+	}
+}
+
+// Fingerprint is the SHA-256 of the certificate, formatted the way browsers
+// show it, so it can be compared before trusting a self-signed certificate.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// DefaultHosts lists the names a self-signed certificate should cover:
+// loopback, this machine's hostname and every non-loopback interface
+// address, so the dashboard can be reached from the LAN by IP or name.
+func DefaultHosts(bindAddress string) []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	if name, err := os.Hostname(); err == nil && name != "" {
+		hosts = append(hosts, name)
+		if short, _, found := strings.Cut(name, "."); found {
+			hosts = append(hosts, short)
+		} else {
+			hosts = append(hosts, name+".local")
+		}
+	}
+
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && !ipNet.IP.IsLinkLocalUnicast() {
+				hosts = append(hosts, ipNet.IP.String())
+			}
+		}
+	}
+
+	if ip := net.ParseIP(bindAddress); ip != nil && !ip.IsUnspecified() {
+		hosts = append(hosts, ip.String())
+	}
+
+	seen := make(map[string]bool, len(hosts))
+	unique := hosts[:0]
+	for _, host := range hosts {
+		if !seen[host] {
+			seen[host] = true
+			unique = append(unique, host)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,61 @@
+package tlscert
+
+import (
+	"crypto/tls"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath, err := LoadOrCreate(dir, []string{"localhost", "127.0.0.1", "devbox.lan"})
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error: %v", err)
+	}
+
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		t.Fatalf("generated pair does not load: %v", err)
+	}
+	if info, err := os.Stat(keyPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("key permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	cert, err := Load(certPath)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if err := cert.VerifyHostname("devbox.lan"); err != nil {
+		t.Errorf("certificate does not cover devbox.lan: %v", err)
+	}
+	if err := cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Errorf("certificate does not cover 127.0.0.1: %v", err)
+	}
+
+	fp := Fingerprint(cert)
+	if len(strings.Split(fp, ":")) != 32 {
+		t.Errorf("Fingerprint() = %q, want 32 colon-separated bytes", fp)
+	}
+
+	// An existing, valid certificate is reused.
+	if _, _, err := LoadOrCreate(dir, []string{"other"}); err != nil {
+		t.Fatal(err)
+	}
+	again, _ := Load(certPath)
+	if Fingerprint(again) != fp {
+		t.Error("LoadOrCreate() replaced a valid certificate")
+	}
+}
+
+func TestDefaultHosts(t *testing.T) {
+	hosts := DefaultHosts("192.0.2.10")
+	for _, want := range []string{"localhost", "127.0.0.1", "192.0.2.10"} {
+		found := false
+		for _, host := range hosts {
+			found = found || host == want
+		}
+		if !found {
+			t.Errorf("DefaultHosts() = %v, missing %s", hosts, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package vcs
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NormalizeRemote reduces a clone URL to host/path form so that SSH, HTTPS
+// and scp-style URLs of the same repository compare equal:
+//
+//	git@github.com:owner/repo.git       -> github.com/owner/repo
+//	https://github.com/Owner/repo       -> github.com/owner/repo
+//	ssh://git@gitlab.com:22/group/repo  -> gitlab.com/group/repo
+//
+// Local paths and file:// URLs are returned as "".
+func NormalizeRemote(remote string) string {
+	remote = strings.TrimSpace(remote)
+	if remote == "" {
+		return ""
+	}
+
+	var host, path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil || u.Scheme == "file" || u.Host == "" {
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:path
+		colon := strings.Index(remote, ":")
+		if colon <= 0 || strings.Contains(remote[:colon], "/") {
+			return ""
+		}
+		host, path = remote[:colon], remote[colon+1:]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	if host == "" || path == "" {
+		return ""
+	}
+	return strings.ToLower(host + "/" + path)
+}
+
+func gitRemote(root string) string {
+	configPath := filepath.Join(root, ".git", "config")
+
+	// Worktrees and submodules have a .git file pointing at the real git dir.
+	if content, err := os.ReadFile(filepath.Join(root, ".git")); err == nil {
+		gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: ")
+		if !ok {
+			return ""
+		}
+		if !filepath.IsAbs(gitDir) {
+			gitDir = filepath.Join(root, gitDir)
+		}
+		if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+			dir := strings.TrimSpace(string(common))
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(gitDir, dir)
+			}
+			gitDir = dir
+		}
+		configPath = filepath.Join(gitDir, "config")
+	}
+
+	return readINIValue(configPath, `remote "origin"`, "url")
+}
+
+func hgRemote(root string) string {
+	return readINIValue(filepath.Join(root, ".hg", "hgrc"), "paths", "default")
+}
+
+// readINIValue returns key from [section] of a git config or hgrc file.
+func readINIValue(path, section, key string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inSection = strings.TrimSpace(line[1:len(line)-1]) == section
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(name) == key {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+package vcs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	Git       Kind = "git"
+	Mercurial Kind = "hg"
+	Jujutsu   Kind = "jj"
+)
+
+const jjTimeout = 500 * time.Millisecond
+
+type Repo struct {
+	Kind   Kind
+	Root   string
+	Name   string
+	Branch string
+	// Remote is the normalized origin URL (see NormalizeRemote), or "" for
+	// repositories without one.
+	Remote string
+}
+
+var markers = []struct {
+	dir  string
+	kind Kind
+}{
+	{".jj", Jujutsu},
+	{".hg", Mercurial},
+	{".git", Git},
+}
+
+func FindRoot(path string) (string, Kind, error) {
+	current := path
+	for {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(current, marker.dir)); err == nil {
+				return current, marker.kind, nil
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", "", fmt.Errorf("not a repository")
+		}
+		current = parent
+	}
+}
+
+func Detect(path string) (*Repo, error) {
+	root, kind, err := FindRoot(path)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &Repo{
+		Kind: kind,
+		Root: root,
+		Name: filepath.Base(root),
+	}
+
+	switch kind {
+	case Git:
+		repo.Branch, _ = gitBranch(root)
+		repo.Remote = NormalizeRemote(gitRemote(root))
+	case Mercurial:
+		repo.Branch, _ = hgBranch(root)
+		repo.Remote = NormalizeRemote(hgRemote(root))
+	case Jujutsu:
+		repo.Branch, _ = jjBranch(root)
+		repo.Remote = NormalizeRemote(gitRemote(root))
+	}
+
+	return repo, nil
+}
+
+func gitBranch(root string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(root, ".git", "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("read HEAD file: %w", err)
+	}
+
+	head := strings.TrimSpace(string(content))
+	if branch, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+		return branch, nil
+	}
+
+	if len(head) >= 7 {
+		return head[:7], nil
+	}
+
+	return "", fmt.Errorf("invalid HEAD format")
+}
+
+func hgBranch(root string) (string, error) {
+	if content, err := os.ReadFile(filepath.Join(root, ".hg", "bookmarks.current")); err == nil {
+		if bookmark := strings.TrimSpace(string(content)); bookmark != "" {
+			return bookmark, nil
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, ".hg", "branch"))
+	if os.IsNotExist(err) {
+		return "default", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read branch file: %w", err)
+	}
+
+	branch := strings.TrimSpace(string(content))
+	if branch == "" {
+		return "default", nil
+	}
+	return branch, nil
+}
+
+func jjBranch(root string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jjTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "jj", "--ignore-working-copy", "--no-pager", "log",
+		"--no-graph", "-r", "@", "-T", `bookmarks.join(",") ++ "\n" ++ change_id.short(8)`)
+	cmd.Dir = root
+
+	output, err := cmd.Output()
+	if err == nil {
+		return parseJJLog(string(output))
+	}
+
+	if _, statErr := os.Stat(filepath.Join(root, ".git")); statErr == nil {
+		return gitBranch(root)
+	}
+
+	return "", fmt.Errorf("run jj: %w", err)
+}
+
+func parseJJLog(output string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimSpace(scanner.Text()))
+	}
+
+	if len(lines) > 0 && lines[0] != "" {
+		bookmark := strings.Split(lines[0], ",")[0]
+		return strings.TrimSuffix(bookmark, "*"), nil
+	}
+	if len(lines) > 1 && lines[1] != "" {
+		return lines[1], nil
+	}
+
+	return "", fmt.Errorf("no bookmark or change id in jj output")
+}
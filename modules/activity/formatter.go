@@ -0,0 +1,35 @@
+package activity
+
+import (
+	"fmt"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/formatting"
+)
+
+type ActivityFormatter struct{}
+
+func init() {
+	formatting.Register("activity", &ActivityFormatter{})
+}
+
+func (f *ActivityFormatter) Format(event *events.Event) string {
+	switch event.Type {
+	case string(events.TypeSessionStart):
+		if away, ok := numberValue(event.Payload["away_seconds"]); ok {
+			return fmt.Sprintf("session started (away %s)", formatSeconds(away))
+		}
+		return "session started"
+	case string(events.TypeSessionEnd):
+		if active, ok := numberValue(event.Payload["duration_seconds"]); ok {
+			return fmt.Sprintf("session ended (active %s)", formatSeconds(active))
+		}
+		return "session ended"
+	}
+	return "activity event"
+}
+
+func formatSeconds(seconds int) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
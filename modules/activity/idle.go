@@ -0,0 +1,128 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	IdleSourceOS   = "os"
+	IdleSourceTmux = "tmux"
+)
+
+var knownIdleSources = []string{IdleSourceOS, IdleSourceTmux}
+
+type commandRunner func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+func runCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).Output()
+}
+
+// idleDetector reports how long ago the last keyboard or terminal input was
+// seen, according to each configured source.
+type idleDetector struct {
+	sources []string
+	goos    string
+	run     commandRunner
+	now     func() time.Time
+}
+
+func newIdleDetector(sources []string) *idleDetector {
+	return &idleDetector{
+		sources: sources,
+		goos:    runtime.GOOS,
+		run:     runCommand,
+		now:     time.Now,
+	}
+}
+
+// Idle returns the shortest idle time across the sources that could be read
+// and the name of that source. ok is false when no source answered, which
+// means presence is unknown rather than idle.
+func (d *idleDetector) Idle(ctx context.Context) (idle time.Duration, source string, ok bool) {
+	for _, name := range d.sources {
+		var value time.Duration
+		var err error
+		switch name {
+		case IdleSourceOS:
+			value, err = d.osIdle(ctx)
+		case IdleSourceTmux:
+			value, err = d.tmuxIdle(ctx)
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if !ok || value < idle {
+			idle, source, ok = value, name, true
+		}
+	}
+	return idle, source, ok
+}
+
+var hidIdleTime = regexp.MustCompile(`"HIDIdleTime"\s*=\s*(\d+)`)
+
+// osIdle reads the desktop session's input idle time: HIDIdleTime on macOS
+// and xprintidle on X11 Linux.
+func (d *idleDetector) osIdle(ctx context.Context) (time.Duration, error) {
+	switch d.goos {
+	case "darwin":
+		out, err := d.run(ctx, "ioreg", "-c", "IOHIDSystem", "-d", "4")
+		if err != nil {
+			return 0, err
+		}
+		m := hidIdleTime.FindSubmatch(out)
+		if m == nil {
+			return 0, fmt.Errorf("HIDIdleTime not found in ioreg output")
+		}
+		ns, err := strconv.ParseInt(string(m[1]), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse HIDIdleTime: %w", err)
+		}
+		return time.Duration(ns), nil
+	case "linux":
+		out, err := d.run(ctx, "xprintidle")
+		if err != nil {
+			return 0, err
+		}
+		ms, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse xprintidle output: %w", err)
+		}
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return 0, fmt.Errorf("os idle time is not supported on %s", d.goos)
+}
+
+// tmuxIdle uses the most recent input time of any attached tmux client,
+// which also covers ssh sessions where the OS idle time means nothing.
+func (d *idleDetector) tmuxIdle(ctx context.Context) (time.Duration, error) {
+	out, err := d.run(ctx, "tmux", "list-clients", "-F", "#{client_activity}")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, line := range strings.Fields(string(out)) {
+		ts, err := strconv.ParseInt(line, 10, 64)
+		if err == nil && ts > latest {
+			latest = ts
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("no attached tmux clients")
+	}
+
+	idle := d.now().Sub(time.Unix(latest, 0))
+	if idle < 0 {
+		idle = 0
+	}
+	return idle, nil
+}
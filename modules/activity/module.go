@@ -0,0 +1,188 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devlog/internal/install"
+	"devlog/internal/modules"
+	"devlog/internal/poller"
+	"devlog/internal/state"
+)
+
+const (
+	defaultPollInterval  = 30
+	defaultIdleThreshold = 10
+)
+
+type Module struct{}
+
+func (m *Module) Name() string {
+	return "activity"
+}
+
+func (m *Module) Description() string {
+	return "Detect idle time and record active sessions at the keyboard"
+}
+
+func (m *Module) Install(ctx *install.Context) error {
+	ctx.Log("Installing activity tracker...")
+	ctx.Log("")
+
+	detector := newIdleDetector(knownIdleSources)
+	found := false
+	for _, name := range knownIdleSources {
+		detector.sources = []string{name}
+		if idle, _, ok := detector.Idle(context.Background()); ok {
+			ctx.Log("✓ Idle source %s available (idle %s)", name, idle.Truncate(time.Second))
+			found = true
+		} else {
+			ctx.Log("  Idle source %s not available", name)
+		}
+	}
+	ctx.Log("")
+
+	if !found {
+		ctx.Log("Warning: no idle source is available right now.")
+		ctx.Log("  macOS works out of the box; on Linux install xprintidle, or run inside tmux.")
+		ctx.Log("")
+	}
+
+	ctx.Log("Configuration:")
+	ctx.Log("  • Idle threshold: %d minutes (configurable)", defaultIdleThreshold)
+	ctx.Log("  • Poll interval: %d seconds (configurable)", defaultPollInterval)
+	ctx.Log("")
+	ctx.Log("✓ Activity tracking will run in the background when daemon starts")
+
+	return nil
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling activity tracker...")
+
+	stateMgr, err := state.NewManager(ctx.DataDir)
+	if err != nil {
+		ctx.Log("Warning: failed to clean up state: %v", err)
+	} else {
+		if err := stateMgr.DeleteModule(stateModule); err != nil {
+			ctx.Log("Warning: failed to clean up state: %v", err)
+		} else {
+			ctx.Log("✓ Cleaned up activity state")
+		}
+	}
+
+	ctx.Log("✓ Activity tracking will be disabled")
+	ctx.Log("")
+	ctx.Log("Note: Recorded sessions will be preserved in your devlog.")
+	return nil
+}
+
+func (m *Module) DefaultConfig() interface{} {
+	return map[string]interface{}{
+		"poll_interval_seconds":  defaultPollInterval,
+		"idle_threshold_minutes": defaultIdleThreshold,
+		"sources":                []interface{}{IdleSourceOS, IdleSourceTmux},
+	}
+}
+
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources:    []string{"activity"},
+		QuietAfter: 3 * 24 * time.Hour,
+	}
+}
+
+func (m *Module) ValidateConfig(config interface{}) error {
+	cfg, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config must be a map")
+	}
+
+	if val, ok := cfg["poll_interval_seconds"]; ok {
+		interval, ok := numberValue(val)
+		if !ok {
+			return fmt.Errorf("poll_interval_seconds must be a number")
+		}
+		if interval < 5 || interval > 600 {
+			return fmt.Errorf("poll_interval_seconds must be between 5 and 600")
+		}
+	}
+
+	if val, ok := cfg["idle_threshold_minutes"]; ok {
+		threshold, ok := numberValue(val)
+		if !ok {
+			return fmt.Errorf("idle_threshold_minutes must be a number")
+		}
+		if threshold < 1 || threshold > 240 {
+			return fmt.Errorf("idle_threshold_minutes must be between 1 and 240")
+		}
+	}
+
+	if val, ok := cfg["sources"]; ok {
+		list, ok := val.([]interface{})
+		if !ok || len(list) == 0 {
+			return fmt.Errorf("sources must be a non-empty list")
+		}
+		for _, item := range list {
+			name, _ := item.(string)
+			if !isKnownIdleSource(name) {
+				return fmt.Errorf("unknown idle source %v (use %s or %s)", item, IdleSourceOS, IdleSourceTmux)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *Module) CreatePoller(config map[string]interface{}, dataDir string) (poller.Poller, error) {
+	pollInterval := defaultPollInterval
+	if n, ok := numberValue(config["poll_interval_seconds"]); ok {
+		pollInterval = n
+	}
+
+	threshold := defaultIdleThreshold
+	if n, ok := numberValue(config["idle_threshold_minutes"]); ok {
+		threshold = n
+	}
+
+	sources := knownIdleSources
+	if list, ok := config["sources"].([]interface{}); ok {
+		sources = nil
+		for _, item := range list {
+			if name, ok := item.(string); ok && isKnownIdleSource(name) {
+				sources = append(sources, name)
+			}
+		}
+	}
+
+	p, err := NewPoller(dataDir, time.Duration(pollInterval)*time.Second, time.Duration(threshold)*time.Minute, sources)
+	if err != nil {
+		return nil, err
+	}
+	p.Init()
+	return p, nil
+}
+
+func isKnownIdleSource(name string) bool {
+	for _, known := range knownIdleSources {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+func numberValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func init() {
+	modules.Register(&Module{})
+}
@@ -0,0 +1,177 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/state"
+)
+
+const stateModule = "activity"
+
+// Poller turns idle-time samples into session_start and session_end events.
+// A session ends once input has been idle for the threshold; the end event
+// is stamped with the last input, so the idle threshold itself is never
+// counted as active time.
+type Poller struct {
+	pollInterval  time.Duration
+	idleThreshold time.Duration
+	detector      *idleDetector
+	stateMgr      *state.Manager
+	now           func() time.Time
+
+	active       bool
+	sessionStart time.Time
+	lastActive   time.Time
+	lastSaved    time.Time
+}
+
+func NewPoller(dataDir string, pollInterval, idleThreshold time.Duration, sources []string) (*Poller, error) {
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("create state manager: %w", err)
+	}
+
+	return &Poller{
+		pollInterval:  pollInterval,
+		idleThreshold: idleThreshold,
+		detector:      newIdleDetector(sources),
+		stateMgr:      stateMgr,
+		now:           time.Now,
+	}, nil
+}
+
+func (p *Poller) Name() string {
+	return "activity"
+}
+
+func (p *Poller) PollInterval() time.Duration {
+	return p.pollInterval
+}
+
+// Init restores an open session from a previous daemon run so a restart
+// does not split it in two.
+func (p *Poller) Init() {
+	start, ok := p.loadTime("session_start")
+	if !ok {
+		return
+	}
+	last, ok := p.loadTime("last_active")
+	if !ok {
+		return
+	}
+	p.active = true
+	p.sessionStart = start
+	p.lastActive = last
+	p.lastSaved = last
+}
+
+func (p *Poller) Poll(ctx context.Context) ([]*events.Event, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	now := p.now()
+	idle, source, ok := p.detector.Idle(ctx)
+	if !ok {
+		return p.expire(now)
+	}
+	lastInput := now.Add(-idle).Truncate(time.Second)
+
+	var result []*events.Event
+	if p.active && lastInput.Sub(p.lastActive) >= p.idleThreshold {
+		// Input resumed after a gap the poller never saw, e.g. the machine
+		// slept: close the old session where it really stopped.
+		result = append(result, p.endSession())
+	}
+
+	if !p.active {
+		if idle >= p.idleThreshold {
+			return result, p.saveState()
+		}
+		result = append(result, p.startSession(lastInput, source))
+		return result, p.saveState()
+	}
+
+	if lastInput.After(p.lastActive) {
+		p.lastActive = lastInput
+	}
+	if now.Sub(p.lastActive) >= p.idleThreshold {
+		result = append(result, p.endSession())
+		return result, p.saveState()
+	}
+
+	if len(result) > 0 || p.lastActive.Sub(p.lastSaved) >= time.Minute {
+		return result, p.saveState()
+	}
+	return result, nil
+}
+
+// expire ends an open session when no idle source answers any more (tmux
+// server gone, screen locked over ssh) and the last known input is stale.
+func (p *Poller) expire(now time.Time) ([]*events.Event, error) {
+	if !p.active || now.Sub(p.lastActive) < p.idleThreshold {
+		return nil, nil
+	}
+	evt := p.endSession()
+	return []*events.Event{evt}, p.saveState()
+}
+
+func (p *Poller) startSession(at time.Time, source string) *events.Event {
+	evt := events.NewEvent(string(events.SourceActivity), string(events.TypeSessionStart))
+	evt.Timestamp = at.UTC().Format(time.RFC3339)
+	evt.Payload["idle_source"] = source
+	if !p.lastActive.IsZero() {
+		evt.Payload["away_seconds"] = int(at.Sub(p.lastActive).Seconds())
+	}
+
+	p.active = true
+	p.sessionStart = at
+	p.lastActive = at
+	return evt
+}
+
+func (p *Poller) endSession() *events.Event {
+	evt := events.NewEvent(string(events.SourceActivity), string(events.TypeSessionEnd))
+	evt.Timestamp = p.lastActive.UTC().Format(time.RFC3339)
+	evt.DurationMs = p.lastActive.Sub(p.sessionStart).Milliseconds()
+	evt.Payload["started_at"] = p.sessionStart.UTC().Format(time.RFC3339)
+	evt.Payload["duration_seconds"] = int(p.lastActive.Sub(p.sessionStart).Seconds())
+	evt.Payload["idle_threshold_seconds"] = int(p.idleThreshold.Seconds())
+
+	p.active = false
+	p.sessionStart = time.Time{}
+	return evt
+}
+
+func (p *Poller) saveState() error {
+	var start, last string
+	if p.active {
+		start = p.sessionStart.UTC().Format(time.RFC3339)
+		last = p.lastActive.UTC().Format(time.RFC3339)
+	}
+	if err := p.stateMgr.Set(stateModule, "session_start", start); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	if err := p.stateMgr.Set(stateModule, "last_active", last); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	p.lastSaved = p.lastActive
+	return nil
+}
+
+func (p *Poller) loadTime(key string) (time.Time, bool) {
+	value, ok := p.stateMgr.GetString(stateModule, key)
+	if !ok || value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,198 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+type fakeClock struct {
+	now  time.Time
+	idle time.Duration
+	ok   bool
+}
+
+func newTestPoller(t *testing.T, dir string, clock *fakeClock) *Poller {
+	t.Helper()
+	p, err := NewPoller(dir, 30*time.Second, 10*time.Minute, []string{IdleSourceTmux})
+	if err != nil {
+		t.Fatalf("NewPoller() error: %v", err)
+	}
+	p.now = func() time.Time { return clock.now }
+	p.detector.now = p.now
+	p.detector.run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		if !clock.ok {
+			return nil, fmt.Errorf("no server running")
+		}
+		return []byte(fmt.Sprintf("%d\n", clock.now.Add(-clock.idle).Unix())), nil
+	}
+	return p
+}
+
+func poll(t *testing.T, p *Poller) []*events.Event {
+	t.Helper()
+	evts, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	for _, evt := range evts {
+		if err := evt.Validate(); err != nil {
+			t.Fatalf("invalid event: %v", err)
+		}
+	}
+	return evts
+}
+
+func TestPollerSessionLifecycle(t *testing.T) {
+	base := time.Date(2025, 5, 19, 9, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: base, idle: 20 * time.Minute, ok: true}
+	p := newTestPoller(t, t.TempDir(), clock)
+
+	if evts := poll(t, p); len(evts) != 0 {
+		t.Fatalf("idle at startup should emit nothing, got %d events", len(evts))
+	}
+
+	clock.now = base.Add(time.Minute)
+	clock.idle = 5 * time.Second
+	evts := poll(t, p)
+	if len(evts) != 1 || evts[0].Type != string(events.TypeSessionStart) {
+		t.Fatalf("want session_start, got %+v", evts)
+	}
+	if evts[0].Timestamp != "2025-05-19T09:00:55Z" {
+		t.Errorf("session_start timestamp = %s, want the last input", evts[0].Timestamp)
+	}
+
+	// Typing until noon, then lunch.
+	clock.idle = 0
+	for at := 5 * time.Minute; at <= 3*time.Hour; at += 5 * time.Minute {
+		clock.now = base.Add(at)
+		if evts := poll(t, p); len(evts) != 0 {
+			t.Fatalf("active poll should emit nothing, got %+v", evts)
+		}
+	}
+	clock.now = base.Add(3*time.Hour + 9*time.Minute)
+	clock.idle = 9 * time.Minute
+	if evts := poll(t, p); len(evts) != 0 {
+		t.Fatalf("below threshold should emit nothing, got %+v", evts)
+	}
+
+	clock.now = base.Add(3*time.Hour + 10*time.Minute)
+	clock.idle = 10 * time.Minute
+	evts = poll(t, p)
+	if len(evts) != 1 || evts[0].Type != string(events.TypeSessionEnd) {
+		t.Fatalf("want session_end, got %+v", evts)
+	}
+	end := evts[0]
+	if end.Timestamp != "2025-05-19T12:00:00Z" {
+		t.Errorf("session_end timestamp = %s, want the last input at 12:00", end.Timestamp)
+	}
+	if end.Payload["started_at"] != "2025-05-19T09:00:55Z" {
+		t.Errorf("started_at = %v", end.Payload["started_at"])
+	}
+	if end.Payload["duration_seconds"] != int((3*time.Hour - 55*time.Second).Seconds()) {
+		t.Errorf("duration_seconds = %v", end.Payload["duration_seconds"])
+	}
+
+	clock.now = base.Add(4 * time.Hour)
+	clock.idle = time.Second
+	evts = poll(t, p)
+	if len(evts) != 1 || evts[0].Type != string(events.TypeSessionStart) {
+		t.Fatalf("want session_start after lunch, got %+v", evts)
+	}
+	if away := evts[0].Payload["away_seconds"]; away != int((time.Hour - time.Second).Seconds()) {
+		t.Errorf("away_seconds = %v", away)
+	}
+}
+
+func TestPollerGapWhileAsleep(t *testing.T) {
+	base := time.Date(2025, 5, 19, 9, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: base, ok: true}
+	p := newTestPoller(t, t.TempDir(), clock)
+	poll(t, p)
+
+	// The machine slept for an hour; the first poll after waking already
+	// sees fresh input.
+	clock.now = base.Add(time.Hour)
+	clock.idle = 2 * time.Second
+	evts := poll(t, p)
+	if len(evts) != 2 {
+		t.Fatalf("want session_end and session_start, got %+v", evts)
+	}
+	if evts[0].Type != string(events.TypeSessionEnd) || evts[0].Timestamp != "2025-05-19T09:00:00Z" {
+		t.Errorf("first event = %s at %s, want session_end at 09:00", evts[0].Type, evts[0].Timestamp)
+	}
+	if evts[1].Type != string(events.TypeSessionStart) {
+		t.Errorf("second event = %s, want session_start", evts[1].Type)
+	}
+}
+
+func TestPollerRestoresSession(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 5, 19, 9, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: base, ok: true}
+	poll(t, newTestPoller(t, dir, clock))
+
+	clock.now = base.Add(2 * time.Minute)
+	restarted := newTestPoller(t, dir, clock)
+	restarted.Init()
+	if evts := poll(t, restarted); len(evts) != 0 {
+		t.Fatalf("restart should continue the open session, got %+v", evts)
+	}
+
+	// The idle source disappears; the session ends once input is stale.
+	clock.ok = false
+	clock.now = base.Add(15 * time.Minute)
+	evts := poll(t, restarted)
+	if len(evts) != 1 || evts[0].Type != string(events.TypeSessionEnd) {
+		t.Fatalf("want session_end, got %+v", evts)
+	}
+	if evts[0].Payload["started_at"] != "2025-05-19T09:00:00Z" {
+		t.Errorf("started_at = %v, want the session from before the restart", evts[0].Payload["started_at"])
+	}
+}
+
+func TestIdleDetectorOS(t *testing.T) {
+	d := newIdleDetector([]string{IdleSourceOS})
+
+	d.goos = "darwin"
+	d.run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte(`    | |   "HIDIdleTime" = 4500000000` + "\n"), nil
+	}
+	if idle, source, ok := d.Idle(context.Background()); !ok || idle != 4500*time.Millisecond || source != IdleSourceOS {
+		t.Errorf("darwin idle = %v %s %v", idle, source, ok)
+	}
+
+	d.goos = "linux"
+	d.run = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return []byte("1234\n"), nil
+	}
+	if idle, _, ok := d.Idle(context.Background()); !ok || idle != 1234*time.Millisecond {
+		t.Errorf("linux idle = %v %v", idle, ok)
+	}
+
+	d.goos = "plan9"
+	if _, _, ok := d.Idle(context.Background()); ok {
+		t.Error("unsupported platform should report no idle source")
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	m := &Module{}
+	if err := m.ValidateConfig(m.DefaultConfig()); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+	bad := []map[string]interface{}{
+		{"idle_threshold_minutes": 0},
+		{"poll_interval_seconds": "fast"},
+		{"sources": []interface{}{"x11"}},
+		{"sources": []interface{}{}},
+	}
+	for _, cfg := range bad {
+		if err := m.ValidateConfig(cfg); err == nil {
+			t.Errorf("ValidateConfig(%v) should fail", cfg)
+		}
+	}
+}
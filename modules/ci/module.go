@@ -0,0 +1,212 @@
+package ci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"devlog/internal/encryption"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+	"devlog/internal/poller"
+	"devlog/internal/state"
+	"devlog/internal/storage"
+)
+
+const (
+	stateModule = "ci"
+
+	// TokenAccount is the OS keychain account the GitHub token is stored
+	// under by 'devlog module ci token'.
+	TokenAccount = "github-token"
+	TokenLabel   = "devlog GitHub token"
+
+	defaultPollInterval = 300
+	defaultActiveDays   = 7
+)
+
+type Module struct{}
+
+func (m *Module) Name() string {
+	return "ci"
+}
+
+func (m *Module) Description() string {
+	return "Poll GitHub Actions for workflow runs in your active repos, no webhook needed"
+}
+
+func (m *Module) Install(ctx *install.Context) error {
+	ctx.Log("Installing CI status poller...")
+	ctx.Log("")
+
+	if _, err := encryption.LoadSecret(TokenAccount); err != nil {
+		ctx.Log("No GitHub token found in %s.", encryption.KeySource())
+		ctx.Log("Store one (needs the actions:read / repo scope) with:")
+		ctx.Log("")
+		ctx.Log("  devlog module ci token")
+		ctx.Log("")
+	} else {
+		ctx.Log("✓ GitHub token found in %s", encryption.KeySource())
+	}
+
+	ctx.Log("Configuration:")
+	ctx.Log("  • Poll interval: %d seconds (configurable)", defaultPollInterval)
+	ctx.Log("  • Repos: any github.com repo with events in the last %d days,", defaultActiveDays)
+	ctx.Log("    or the modules.ci.repos list if set")
+	ctx.Log("")
+	ctx.Log("Active repos are only recognized when repos are named by remote")
+	ctx.Log("(repos.use_remote: true); otherwise list them under modules.ci.repos.")
+	ctx.Log("")
+	ctx.Log("✓ CI polling will run in the background when daemon starts")
+
+	return nil
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling CI status poller...")
+
+	stateMgr, err := state.NewManager(ctx.DataDir)
+	if err != nil {
+		ctx.Log("Warning: failed to clean up state: %v", err)
+	} else if err := stateMgr.DeleteModule(stateModule); err != nil {
+		ctx.Log("Warning: failed to clean up state: %v", err)
+	} else {
+		ctx.Log("✓ Cleaned up CI poll cursors")
+	}
+
+	ctx.Log("")
+	ctx.Log("Note: the GitHub token stays in %s; remove it with", encryption.KeySource())
+	ctx.Log("  devlog module ci token --delete")
+	return nil
+}
+
+func (m *Module) DefaultConfig() interface{} {
+	return map[string]interface{}{
+		"poll_interval_seconds": defaultPollInterval,
+		"active_days":           defaultActiveDays,
+		"repos":                 []interface{}{},
+	}
+}
+
+// HealthCheck reports workflow runs, which are stored as github events, and
+// whether the token the poller needs is present.
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	check := modules.Check{Name: "token", OK: true, Message: fmt.Sprintf("GitHub token found in %s", encryption.KeySource())}
+	if _, err := encryption.LoadSecret(TokenAccount); err != nil {
+		check = modules.Check{Name: "token", Message: "no GitHub token stored; run 'devlog module ci token'"}
+	}
+	return modules.Health{
+		Sources: []string{"github"},
+		Checks:  []modules.Check{check},
+	}
+}
+
+func (m *Module) ValidateConfig(config interface{}) error {
+	cfg, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config must be a map")
+	}
+
+	if val, ok := cfg["poll_interval_seconds"]; ok {
+		interval, ok := numberValue(val)
+		if !ok {
+			return fmt.Errorf("poll_interval_seconds must be a number")
+		}
+		if interval < 60 || interval > 86400 {
+			return fmt.Errorf("poll_interval_seconds must be between 60 and 86400")
+		}
+	}
+
+	if val, ok := cfg["active_days"]; ok {
+		days, ok := numberValue(val)
+		if !ok {
+			return fmt.Errorf("active_days must be a number")
+		}
+		if days < 1 || days > 90 {
+			return fmt.Errorf("active_days must be between 1 and 90")
+		}
+	}
+
+	if val, ok := cfg["repos"]; ok && val != nil {
+		list, ok := val.([]interface{})
+		if !ok {
+			return fmt.Errorf("repos must be a list")
+		}
+		for _, item := range list {
+			name, _ := item.(string)
+			if _, ok := poller.GitHubRepo(name, true); !ok {
+				return fmt.Errorf("repos: %v is not an owner/name GitHub repo", item)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m *Module) CreatePoller(config map[string]interface{}, dataDir string) (poller.Poller, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if secret, err := encryption.LoadSecret(TokenAccount); err == nil {
+		token = secret
+	}
+	if token == "" {
+		return nil, fmt.Errorf("ci module needs a GitHub token: run 'devlog module ci token' or set $GITHUB_TOKEN")
+	}
+
+	cfg := poller.ActionsConfig{
+		Token:      token,
+		ActiveDays: defaultActiveDays,
+		Interval:   defaultPollInterval * time.Second,
+	}
+	if n, ok := numberValue(config["poll_interval_seconds"]); ok {
+		cfg.Interval = time.Duration(n) * time.Second
+	}
+	if n, ok := numberValue(config["active_days"]); ok {
+		cfg.ActiveDays = n
+	}
+	if apiURL, ok := config["api_url"].(string); ok {
+		cfg.APIURL = apiURL
+	}
+	if list, ok := config["repos"].([]interface{}); ok {
+		for _, item := range list {
+			if name, ok := item.(string); ok {
+				cfg.Repos = append(cfg.Repos, name)
+			}
+		}
+	}
+
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return nil, errors.WrapModule("ci", "create state manager", err)
+	}
+
+	return poller.NewActionsPoller(cfg, activeRepos(dataDir), stateMgr), nil
+}
+
+// activeRepos lists repos from the event database, opening it only for the
+// duration of each lookup.
+func activeRepos(dataDir string) poller.RepoLister {
+	return func(ctx context.Context, since time.Time) ([]string, error) {
+		store, err := storage.OpenSQLite(dataDir)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+		return store.ActiveReposContext(ctx, since)
+	}
+}
+
+func numberValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func init() {
+	modules.Register(&Module{})
+}
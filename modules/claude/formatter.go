@@ -22,6 +22,10 @@ func (f *ClaudeFormatter) Format(event *events.Event) string {
 		return f.formatCommand(event)
 	case "file_edit":
 		return f.formatFileEdit(event)
+	case "tool_call":
+		return f.formatToolCall(event)
+	case "test_run":
+		return f.formatTestRun(event)
 	default:
 		return fmt.Sprintf("claude/%s", event.Type)
 	}
@@ -53,6 +57,10 @@ func (f *ClaudeFormatter) formatConversation(event *events.Event) string {
 	if readCount, ok := event.Payload["read_count"].(float64); ok && readCount > 0 {
 		metadata = append(metadata, fmt.Sprintf("%d reads", int(readCount)))
 	}
+	if testsRun, ok := event.Payload["tests_run"].(float64); ok && testsRun > 0 {
+		failed, _ := event.Payload["tests_failed"].(float64)
+		metadata = append(metadata, fmt.Sprintf("%d test runs, %d failed", int(testsRun), int(failed)))
+	}
 
 	if len(metadata) > 0 {
 		result += " ["
@@ -105,3 +113,33 @@ func (f *ClaudeFormatter) formatFileEdit(event *events.Event) string {
 	}
 	return "edited file"
 }
+
+func (f *ClaudeFormatter) formatToolCall(event *events.Event) string {
+	tool, _ := event.Payload["tool"].(string)
+	if tool == "" {
+		tool = "tool"
+	}
+
+	result := tool
+	if target, ok := event.Payload["target"].(string); ok && target != "" {
+		if len(target) > 70 {
+			target = target[:70] + "..."
+		}
+		result += " " + target
+	}
+	if failed, ok := event.Payload["failed"].(bool); ok && failed {
+		result += " [failed]"
+	}
+	return result
+}
+
+func (f *ClaudeFormatter) formatTestRun(event *events.Event) string {
+	cmd, _ := event.Payload["command"].(string)
+	if len(cmd) > 70 {
+		cmd = cmd[:70] + "..."
+	}
+	if passed, ok := event.Payload["passed"].(bool); ok && !passed {
+		return cmd + " [failed]"
+	}
+	return cmd + " [passed]"
+}
@@ -53,7 +53,8 @@ func (m *Module) Install(ctx *install.Context) error {
 	ctx.Log("The module will poll for new conversation entries and extract:")
 	ctx.Log("  • Conversation summaries")
 	ctx.Log("  • File operations (read, edit, write)")
-	ctx.Log("  • Shell commands executed")
+	ctx.Log("  • Shell commands executed and test runs")
+	ctx.Log("  • Other tool calls and token usage")
 	ctx.Log("  • Work session boundaries")
 
 	return nil
@@ -83,10 +84,18 @@ func (m *Module) DefaultConfig() interface{} {
 		"projects_dir":          "~/.claude/projects",
 		"extract_commands":      true,
 		"extract_file_edits":    true,
+		"extract_tool_calls":    true,
 		"min_message_length":    10,
 	}
 }
 
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources:    []string{"claude"},
+		QuietAfter: 7 * 24 * time.Hour,
+	}
+}
+
 func (m *Module) ValidateConfig(config interface{}) error {
 	cfg, ok := config.(map[string]interface{})
 	if !ok {
@@ -159,6 +168,11 @@ func (m *Module) CreatePoller(config map[string]interface{}, dataDir string) (po
 		extractFileEdits = efe
 	}
 
+	extractToolCalls := true
+	if etc, ok := config["extract_tool_calls"].(bool); ok {
+		extractToolCalls = etc
+	}
+
 	minMessageLength := 10
 	if val, exists := config["min_message_length"]; exists {
 		switch v := val.(type) {
@@ -179,6 +193,7 @@ func (m *Module) CreatePoller(config map[string]interface{}, dataDir string) (po
 		time.Duration(pollInterval)*time.Second,
 		extractCommands,
 		extractFileEdits,
+		extractToolCalls,
 		minMessageLength,
 	)
 }
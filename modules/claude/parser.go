@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,19 +22,34 @@ type ConversationEntry struct {
 	ParentUUID    *string         `json:"parentUuid"`
 	CWD           string          `json:"cwd"`
 	GitBranch     string          `json:"gitBranch"`
-	ToolUseResult *ToolUseResult  `json:"toolUseResult"`
+	ToolUseResult json.RawMessage `json:"toolUseResult"`
 }
 
 type Message struct {
+	ID      string          `json:"id"`
 	Role    string          `json:"role"`
+	Model   string          `json:"model"`
 	Content json.RawMessage `json:"content"`
+	Usage   *MessageUsage   `json:"usage"`
+}
+
+type MessageUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 }
 
 type ContentItem struct {
-	Type    string          `json:"type"`
-	Text    string          `json:"text"`
-	ToolUse *ToolUseContent `json:"tool_use,omitempty"`
-	ID      string          `json:"tool_use_id,omitempty"`
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	ToolUse   *ToolUseContent `json:"tool_use,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+	Content   json.RawMessage `json:"content,omitempty"`
 }
 
 type ToolUseContent struct {
@@ -64,6 +81,11 @@ type EditInput struct {
 	NewString string `json:"new_string"`
 }
 
+type MultiEditInput struct {
+	FilePath string      `json:"file_path"`
+	Edits    []EditInput `json:"edits"`
+}
+
 type WriteInput struct {
 	FilePath string `json:"file_path"`
 	Content  string `json:"content"`
@@ -74,23 +96,31 @@ type ParsedConversation struct {
 	Timestamp     time.Time
 	UserMessage   string
 	ClaudeMessage string
+	Model         string
 	Commands      []CommandExecution
 	FileEdits     []FileEdit
 	FileReads     []FileRead
+	ToolCalls     []ToolCall
+	Usage         TokenUsage
 	CWD           string
 	GitBranch     string
 }
 
 type CommandExecution struct {
+	ToolUseID   string
 	Command     string
 	Description string
 	Stdout      string
 	Stderr      string
+	Failed      bool
+	ExitCode    int
+	IsTest      bool
 	Timestamp   time.Time
 }
 
 type FileEdit struct {
 	FilePath  string
+	Operation string
 	OldString string
 	NewString string
 	Timestamp time.Time
@@ -101,6 +131,27 @@ type FileRead struct {
 	Timestamp time.Time
 }
 
+// ToolCall is a single tool invocation. Target is the most descriptive input
+// field for the tool (a path, pattern, URL or description).
+type ToolCall struct {
+	ID        string
+	Name      string
+	Target    string
+	Failed    bool
+	Timestamp time.Time
+}
+
+type TokenUsage struct {
+	InputTokens         int
+	OutputTokens        int
+	CacheCreationTokens int
+	CacheReadTokens     int
+}
+
+func (u TokenUsage) Total() int {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
 func ParseJSONLFile(filepath string, since time.Time) ([]ParsedConversation, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
@@ -143,6 +194,15 @@ func ParseJSONLFile(filepath string, since time.Time) ([]ParsedConversation, err
 
 func aggregateConversations(entries []ConversationEntry) ([]ParsedConversation, error) {
 	conversationMap := make(map[string]*ParsedConversation)
+	var order []string
+	seenMessages := make(map[string]bool)
+
+	type commandRef struct {
+		conv  *ParsedConversation
+		index int
+	}
+	commandsByID := make(map[string]commandRef)
+	toolCallsByID := make(map[string]commandRef)
 
 	for _, entry := range entries {
 		if entry.Type != "user" && entry.Type != "assistant" {
@@ -163,34 +223,81 @@ func aggregateConversations(entries []ConversationEntry) ([]ParsedConversation,
 				GitBranch: entry.GitBranch,
 			}
 			conversationMap[entry.SessionID] = conv
+			order = append(order, entry.SessionID)
 		}
 
 		if entry.Type == "user" {
 			text := extractTextFromMessage(entry.Message)
 
-			if entry.ToolUseResult != nil && entry.ToolUseResult.File != nil {
+			result := parseToolUseResult(entry.ToolUseResult)
+			if result != nil && result.File != nil {
 				conv.FileReads = append(conv.FileReads, FileRead{
-					FilePath:  entry.ToolUseResult.File.FilePath,
+					FilePath:  result.File.FilePath,
 					Timestamp: ts,
 				})
 			}
 
+			for _, item := range extractToolResults(entry.Message) {
+				if ref, ok := toolCallsByID[item.ToolUseID]; ok {
+					ref.conv.ToolCalls[ref.index].Failed = item.IsError
+				}
+				if ref, ok := commandsByID[item.ToolUseID]; ok {
+					cmd := &ref.conv.Commands[ref.index]
+					cmd.Failed = item.IsError
+					if item.IsError {
+						cmd.ExitCode = exitCodeFromResult(item.Content)
+					}
+					if result != nil {
+						cmd.Stdout = result.Stdout
+						cmd.Stderr = result.Stderr
+					}
+				}
+			}
+
 			if text != "" {
 				conv.UserMessage += text + "\n"
 			}
 		}
 
 		if entry.Type == "assistant" {
-			text, tools := extractContentFromMessage(entry.Message)
+			msg, text, tools := extractContentFromMessage(entry.Message)
+
+			if msg.Model != "" {
+				conv.Model = msg.Model
+			}
+			// Streamed replies repeat the same message (and usage) once per
+			// content block, so count each message ID once.
+			if msg.Usage != nil && (msg.ID == "" || !seenMessages[msg.ID]) {
+				seenMessages[msg.ID] = true
+				conv.Usage.InputTokens += msg.Usage.InputTokens
+				conv.Usage.OutputTokens += msg.Usage.OutputTokens
+				conv.Usage.CacheCreationTokens += msg.Usage.CacheCreationInputTokens
+				conv.Usage.CacheReadTokens += msg.Usage.CacheReadInputTokens
+			}
 
 			for _, tool := range tools {
+				if tool.ID != "" {
+					toolCallsByID[tool.ID] = commandRef{conv, len(conv.ToolCalls)}
+				}
+				conv.ToolCalls = append(conv.ToolCalls, ToolCall{
+					ID:        tool.ID,
+					Name:      tool.Name,
+					Target:    toolTarget(tool.Input),
+					Timestamp: ts,
+				})
+
 				switch tool.Name {
 				case "Bash":
 					var input BashInput
 					if err := json.Unmarshal(tool.Input, &input); err == nil {
+						if tool.ID != "" {
+							commandsByID[tool.ID] = commandRef{conv, len(conv.Commands)}
+						}
 						conv.Commands = append(conv.Commands, CommandExecution{
+							ToolUseID:   tool.ID,
 							Command:     input.Command,
 							Description: input.Description,
+							IsTest:      IsTestCommand(input.Command),
 							Timestamp:   ts,
 						})
 					}
@@ -199,16 +306,32 @@ func aggregateConversations(entries []ConversationEntry) ([]ParsedConversation,
 					if err := json.Unmarshal(tool.Input, &input); err == nil {
 						conv.FileEdits = append(conv.FileEdits, FileEdit{
 							FilePath:  input.FilePath,
+							Operation: "edit",
 							OldString: input.OldString,
 							NewString: input.NewString,
 							Timestamp: ts,
 						})
 					}
+				case "MultiEdit":
+					var input MultiEditInput
+					if err := json.Unmarshal(tool.Input, &input); err == nil {
+						edit := FileEdit{
+							FilePath:  input.FilePath,
+							Operation: "multi_edit",
+							Timestamp: ts,
+						}
+						if len(input.Edits) > 0 {
+							edit.OldString = input.Edits[0].OldString
+							edit.NewString = fmt.Sprintf("[%d edits] %s", len(input.Edits), input.Edits[0].NewString)
+						}
+						conv.FileEdits = append(conv.FileEdits, edit)
+					}
 				case "Write":
 					var input WriteInput
 					if err := json.Unmarshal(tool.Input, &input); err == nil {
 						conv.FileEdits = append(conv.FileEdits, FileEdit{
 							FilePath:  input.FilePath,
+							Operation: "write",
 							NewString: fmt.Sprintf("[New file: %d bytes]", len(input.Content)),
 							Timestamp: ts,
 						})
@@ -233,13 +356,99 @@ func aggregateConversations(entries []ConversationEntry) ([]ParsedConversation,
 	}
 
 	var result []ParsedConversation
-	for _, conv := range conversationMap {
-		result = append(result, *conv)
+	for _, id := range order {
+		result = append(result, *conversationMap[id])
 	}
 
 	return result, nil
 }
 
+func parseToolUseResult(raw json.RawMessage) *ToolUseResult {
+	if len(raw) == 0 {
+		return nil
+	}
+	// Failed tool calls record a plain error string instead of an object.
+	var result ToolUseResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+func extractToolResults(msgRaw json.RawMessage) []ContentItem {
+	var msg Message
+	if err := json.Unmarshal(msgRaw, &msg); err != nil {
+		return nil
+	}
+
+	var content []ContentItem
+	if err := json.Unmarshal(msg.Content, &content); err != nil {
+		return nil
+	}
+
+	var results []ContentItem
+	for _, item := range content {
+		if item.Type == "tool_result" && item.ToolUseID != "" {
+			results = append(results, item)
+		}
+	}
+	return results
+}
+
+var exitCodePattern = regexp.MustCompile(`Exit code (\d+)`)
+
+// exitCodeFromResult pulls the exit status out of a failed Bash tool result,
+// falling back to 1 when the output doesn't state it.
+func exitCodeFromResult(content json.RawMessage) int {
+	text := string(content)
+	var s string
+	if err := json.Unmarshal(content, &s); err == nil {
+		text = s
+	}
+	if m := exitCodePattern.FindStringSubmatch(text); m != nil {
+		if code, err := strconv.Atoi(m[1]); err == nil {
+			return code
+		}
+	}
+	return 1
+}
+
+var toolTargetKeys = []string{"file_path", "notebook_path", "pattern", "url", "query", "path", "description", "command", "prompt"}
+
+func toolTarget(input json.RawMessage) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(input, &fields); err != nil {
+		return ""
+	}
+	for _, key := range toolTargetKeys {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return truncateString(v, 200)
+		}
+	}
+	return ""
+}
+
+var testCommandPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bgo test\b`),
+	regexp.MustCompile(`\b(npm|yarn|pnpm|bun)( run)? test\b`),
+	regexp.MustCompile(`\b(pytest|jest|vitest|mocha|rspec|phpunit|tox|nox)\b`),
+	regexp.MustCompile(`\bpython3? -m (pytest|unittest)\b`),
+	regexp.MustCompile(`\bcargo (test|nextest)\b`),
+	regexp.MustCompile(`\b(make|just|task) (test|check)\b`),
+	regexp.MustCompile(`\b(mvn|gradle|gradlew|dotnet|mix|swift|deno) test\b`),
+	regexp.MustCompile(`\bbundle exec (rspec|rake test)\b`),
+}
+
+// IsTestCommand reports whether a shell command runs a test suite.
+func IsTestCommand(command string) bool {
+	for _, re := range testCommandPatterns {
+		if re.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
 func extractTextFromMessage(msgRaw json.RawMessage) string {
 	var msg Message
 	if err := json.Unmarshal(msgRaw, &msg); err != nil {
@@ -276,15 +485,15 @@ func extractTextFromMessage(msgRaw json.RawMessage) string {
 	return strings.Join(texts, "\n")
 }
 
-func extractContentFromMessage(msgRaw json.RawMessage) (string, []ToolUseContent) {
+func extractContentFromMessage(msgRaw json.RawMessage) (Message, string, []ToolUseContent) {
 	var msg Message
 	if err := json.Unmarshal(msgRaw, &msg); err != nil {
-		return "", nil
+		return msg, "", nil
 	}
 
 	var content []ContentItem
 	if err := json.Unmarshal(msg.Content, &content); err != nil {
-		return "", nil
+		return msg, "", nil
 	}
 
 	var texts []string
@@ -294,10 +503,14 @@ func extractContentFromMessage(msgRaw json.RawMessage) (string, []ToolUseContent
 		if item.Type == "text" && item.Text != "" {
 			texts = append(texts, item.Text)
 		}
-		if item.Type == "tool_use" && item.ToolUse != nil {
-			tools = append(tools, *item.ToolUse)
+		if item.Type == "tool_use" {
+			if item.ToolUse != nil {
+				tools = append(tools, *item.ToolUse)
+			} else if item.Name != "" {
+				tools = append(tools, ToolUseContent{ID: item.ID, Name: item.Name, Input: item.Input})
+			}
 		}
 	}
 
-	return strings.Join(texts, "\n"), tools
+	return msg, strings.Join(texts, "\n"), tools
 }
@@ -0,0 +1,177 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+)
+
+const sampleTranscript = `{"type":"user","timestamp":"2025-06-02T10:00:00Z","sessionId":"6f1c2a9e-3b7d-4c1a-9f0e-2d8b5a7c4e11","cwd":"/work/devlog","gitBranch":"main","message":{"role":"user","content":"Fix the flaky storage test please"}}
+{"type":"assistant","timestamp":"2025-06-02T10:00:05Z","sessionId":"6f1c2a9e-3b7d-4c1a-9f0e-2d8b5a7c4e11","message":{"id":"msg_1","role":"assistant","model":"model-x","content":[{"type":"text","text":"Looking at it."},{"type":"tool_use","id":"tu_1","name":"Read","input":{"file_path":"/work/devlog/internal/storage/storage_test.go"}}],"usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":1000,"cache_creation_input_tokens":50}}}
+{"type":"user","timestamp":"2025-06-02T10:00:06Z","sessionId":"6f1c2a9e-3b7d-4c1a-9f0e-2d8b5a7c4e11","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu_1","content":"package storage"}]},"toolUseResult":{"type":"text","file":{"filePath":"/work/devlog/internal/storage/storage_test.go","content":"package storage"}}}
+{"type":"assistant","timestamp":"2025-06-02T10:00:10Z","sessionId":"6f1c2a9e-3b7d-4c1a-9f0e-2d8b5a7c4e11","message":{"id":"msg_2","role":"assistant","model":"model-x","content":[{"type":"tool_use","id":"tu_2","name":"Edit","input":{"file_path":"/work/devlog/internal/storage/storage_test.go","old_string":"a","new_string":"b"}}],"usage":{"input_tokens":10,"output_tokens":5}}}
+{"type":"assistant","timestamp":"2025-06-02T10:00:10Z","sessionId":"6f1c2a9e-3b7d-4c1a-9f0e-2d8b5a7c4e11","message":{"id":"msg_2","role":"assistant","model":"model-x","content":[{"type":"tool_use","id":"tu_3","name":"Bash","input":{"command":"go test ./internal/storage/","description":"Run storage tests"}}],"usage":{"input_tokens":10,"output_tokens":5}}}
+{"type":"user","timestamp":"2025-06-02T10:00:20Z","sessionId":"6f1c2a9e-3b7d-4c1a-9f0e-2d8b5a7c4e11","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"tu_3","is_error":true,"content":"Error: Exit code 2\nFAIL devlog/internal/storage"}]},"toolUseResult":"Error: Exit code 2\nFAIL devlog/internal/storage"}
+{"type":"assistant","timestamp":"2025-06-02T10:00:30Z","sessionId":"6f1c2a9e-3b7d-4c1a-9f0e-2d8b5a7c4e11","message":{"id":"msg_3","role":"assistant","content":[{"type":"tool_use","id":"tu_4","name":"Grep","input":{"pattern":"setupTestDB","path":"/work/devlog"}},{"type":"tool_use","id":"tu_5","name":"Write","input":{"file_path":"/work/devlog/NOTES.md","content":"notes"}}],"usage":{"input_tokens":1,"output_tokens":1}}}
+{"type":"summary","summary":"Fix flaky test"}
+`
+
+func writeTranscript(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "s1.jsonl")
+	if err := os.WriteFile(path, []byte(sampleTranscript), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseJSONLFile(t *testing.T) {
+	convs, err := ParseJSONLFile(writeTranscript(t), time.Time{})
+	if err != nil {
+		t.Fatalf("ParseJSONLFile() error: %v", err)
+	}
+	if len(convs) != 1 {
+		t.Fatalf("got %d conversations, want 1", len(convs))
+	}
+	conv := convs[0]
+
+	if conv.Model != "model-x" {
+		t.Errorf("Model = %q", conv.Model)
+	}
+	want := TokenUsage{InputTokens: 111, OutputTokens: 26, CacheCreationTokens: 50, CacheReadTokens: 1000}
+	if conv.Usage != want {
+		t.Errorf("Usage = %+v, want %+v (repeated message IDs counted once)", conv.Usage, want)
+	}
+
+	if len(conv.ToolCalls) != 5 {
+		t.Fatalf("got %d tool calls, want 5", len(conv.ToolCalls))
+	}
+	if grep := conv.ToolCalls[3]; grep.Name != "Grep" || grep.Target != "setupTestDB" {
+		t.Errorf("Grep call = %+v", grep)
+	}
+
+	if len(conv.Commands) != 1 {
+		t.Fatalf("got %d commands, want 1", len(conv.Commands))
+	}
+	cmd := conv.Commands[0]
+	if !cmd.IsTest || !cmd.Failed || cmd.ExitCode != 2 {
+		t.Errorf("command = %+v, want failed test run with exit code 2", cmd)
+	}
+
+	if len(conv.FileEdits) != 2 || conv.FileEdits[0].Operation != "edit" || conv.FileEdits[1].Operation != "write" {
+		t.Errorf("FileEdits = %+v", conv.FileEdits)
+	}
+	if len(conv.FileReads) != 2 {
+		t.Errorf("got %d file reads, want 2", len(conv.FileReads))
+	}
+}
+
+func TestExtractEventsRecordsRemote(t *testing.T) {
+	convs, err := ParseJSONLFile(writeTranscript(t), time.Time{})
+	if err != nil {
+		t.Fatalf("ParseJSONLFile() error: %v", err)
+	}
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitConfig := "[remote \"origin\"]\n\turl = git@github.com:acme/api.git\n"
+	if err := os.WriteFile(filepath.Join(root, ".git", "config"), []byte(gitConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conv := convs[0]
+	conv.CWD = root
+
+	p, err := NewPoller(t.TempDir(), t.TempDir(), time.Minute, true, true, true, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extracted := p.extractEvents(conv)
+	if len(extracted) == 0 {
+		t.Fatal("extractEvents() returned no events")
+	}
+	for _, evt := range extracted {
+		if got := evt.Payload[config.RemotePayloadKey]; got != "github.com/acme/api" {
+			t.Errorf("%s event %s = %v, want github.com/acme/api", evt.Type, config.RemotePayloadKey, got)
+		}
+	}
+}
+
+func TestExtractEvents(t *testing.T) {
+	convs, err := ParseJSONLFile(writeTranscript(t), time.Time{})
+	if err != nil {
+		t.Fatalf("ParseJSONLFile() error: %v", err)
+	}
+
+	p, err := NewPoller(t.TempDir(), t.TempDir(), time.Minute, true, true, true, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[string]int)
+	var conversation, testRun map[string]interface{}
+	for _, evt := range p.extractEvents(convs[0]) {
+		if err := evt.Validate(); err != nil {
+			t.Errorf("invalid %s event: %v", evt.Type, err)
+		}
+		counts[evt.Type]++
+		switch evt.Type {
+		case "conversation":
+			conversation = evt.Payload
+		case "test_run":
+			testRun = evt.Payload
+		}
+	}
+
+	wantCounts := map[string]int{"conversation": 1, "command": 1, "test_run": 1, "file_edit": 2, "tool_call": 2}
+	for typ, n := range wantCounts {
+		if counts[typ] != n {
+			t.Errorf("got %d %s events, want %d", counts[typ], typ, n)
+		}
+	}
+
+	files, _ := conversation["files_touched"].([]string)
+	if strings.Join(files, ",") != "NOTES.md,internal/storage/storage_test.go" {
+		t.Errorf("files_touched = %v", files)
+	}
+	if cmds, _ := conversation["commands"].([]string); len(cmds) != 1 || cmds[0] != "go test ./internal/storage/" {
+		t.Errorf("commands = %v", cmds)
+	}
+	if conversation["tests_failed"] != 1 || conversation["output_tokens"] != 26 {
+		t.Errorf("conversation payload = %v", conversation)
+	}
+	if testRun["passed"] != false || testRun["exit_code"] != 2 {
+		t.Errorf("test_run payload = %v", testRun)
+	}
+
+	p.SetIgnoreList([]string{"go test*"})
+	for _, evt := range p.extractEvents(convs[0]) {
+		if evt.Type == "test_run" || evt.Type == "command" {
+			t.Errorf("ignored command still produced a %s event", evt.Type)
+		}
+	}
+}
+
+func TestIsTestCommand(t *testing.T) {
+	for cmd, want := range map[string]bool{
+		"go test ./...":               true,
+		"cd web && npm run test":      true,
+		"python -m pytest -x tests/":  true,
+		"cargo test --all":            true,
+		"make test":                   true,
+		"go build ./...":              false,
+		"git commit -m 'test fixes'":  false,
+		"cat internal/api/testdata/x": false,
+	} {
+		if got := IsTestCommand(cmd); got != want {
+			t.Errorf("IsTestCommand(%q) = %v, want %v", cmd, got, want)
+		}
+	}
+}
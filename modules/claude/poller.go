@@ -7,12 +7,16 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"devlog/internal/config"
 	"devlog/internal/errors"
 	"devlog/internal/events"
 	"devlog/internal/state"
+	"devlog/internal/vcs"
 )
 
 type Poller struct {
@@ -20,9 +24,12 @@ type Poller struct {
 	pollInterval     time.Duration
 	extractCommands  bool
 	extractFileEdits bool
+	extractToolCalls bool
 	minMessageLength int
 	stateMgr         *state.Manager
 	logger           *slog.Logger
+	ignoreMu         sync.RWMutex
+	ignoreList       []string
 }
 
 func NewPoller(
@@ -31,6 +38,7 @@ func NewPoller(
 	pollInterval time.Duration,
 	extractCommands bool,
 	extractFileEdits bool,
+	extractToolCalls bool,
 	minMessageLength int,
 ) (*Poller, error) {
 	stateMgr, err := state.NewManager(dataDir)
@@ -43,6 +51,7 @@ func NewPoller(
 		pollInterval:     pollInterval,
 		extractCommands:  extractCommands,
 		extractFileEdits: extractFileEdits,
+		extractToolCalls: extractToolCalls,
 		minMessageLength: minMessageLength,
 		stateMgr:         stateMgr,
 		logger:           slog.Default().With("module", "claude"),
@@ -53,6 +62,18 @@ func (p *Poller) Name() string {
 	return "claude"
 }
 
+func (p *Poller) SetIgnoreList(commands []string) {
+	p.ignoreMu.Lock()
+	defer p.ignoreMu.Unlock()
+	p.ignoreList = append([]string(nil), commands...)
+}
+
+func (p *Poller) isIgnored(command string) bool {
+	p.ignoreMu.RLock()
+	defer p.ignoreMu.RUnlock()
+	return config.IsCommandIgnored(p.ignoreList, command)
+}
+
 func (p *Poller) PollInterval() time.Duration {
 	return p.pollInterval
 }
@@ -168,13 +189,62 @@ func (p *Poller) extractEvents(conv ParsedConversation) []*events.Event {
 		}
 
 		event.Payload = map[string]interface{}{
-			"session_id":    conv.SessionID,
-			"user_message":  conv.UserMessage,
-			"claude_reply":  conv.ClaudeMessage,
-			"summary":       summary,
-			"command_count": len(conv.Commands),
-			"edit_count":    len(conv.FileEdits),
-			"read_count":    len(conv.FileReads),
+			"session_id":            conv.SessionID,
+			"user_message":          conv.UserMessage,
+			"claude_reply":          conv.ClaudeMessage,
+			"summary":               summary,
+			"command_count":         len(conv.Commands),
+			"edit_count":            len(conv.FileEdits),
+			"read_count":            len(conv.FileReads),
+			"tool_call_count":       len(conv.ToolCalls),
+			"files_touched":         filesTouched(conv),
+			"commands":              p.commandList(conv.Commands),
+			"tools":                 toolCounts(conv.ToolCalls),
+			"input_tokens":          conv.Usage.InputTokens,
+			"output_tokens":         conv.Usage.OutputTokens,
+			"cache_creation_tokens": conv.Usage.CacheCreationTokens,
+			"cache_read_tokens":     conv.Usage.CacheReadTokens,
+		}
+		if conv.Model != "" {
+			event.Payload["model"] = conv.Model
+		}
+
+		testsRun, testsFailed := 0, 0
+		for _, cmd := range conv.Commands {
+			if cmd.IsTest {
+				testsRun++
+				if cmd.Failed {
+					testsFailed++
+				}
+			}
+		}
+		if testsRun > 0 {
+			event.Payload["tests_run"] = testsRun
+			event.Payload["tests_failed"] = testsFailed
+		}
+
+		result = append(result, event)
+	}
+
+	for _, cmd := range conv.Commands {
+		if !p.extractCommands || !cmd.IsTest || p.isIgnored(cmd.Command) {
+			continue
+		}
+
+		event := events.NewEvent("claude", "test_run")
+		event.ID = generateID(conv.SessionID, "test_run", cmd.ToolUseID, cmd.Command, cmd.Timestamp.String())
+		event.Timestamp = cmd.Timestamp.Format(time.RFC3339)
+		if conv.CWD != "" {
+			event.Repo = filepath.Base(conv.CWD)
+		}
+		event.Branch = conv.GitBranch
+
+		event.Payload = map[string]interface{}{
+			"session_id": conv.SessionID,
+			"command":    cmd.Command,
+			"passed":     !cmd.Failed,
+			"exit_code":  cmd.ExitCode,
+			"output":     tailString(cmd.Stdout+cmd.Stderr, 500),
 		}
 
 		result = append(result, event)
@@ -182,6 +252,10 @@ func (p *Poller) extractEvents(conv ParsedConversation) []*events.Event {
 
 	if p.extractCommands {
 		for _, cmd := range conv.Commands {
+			if p.isIgnored(cmd.Command) {
+				continue
+			}
+
 			event := events.NewEvent("claude", "command")
 			event.ID = generateID(conv.SessionID, cmd.Command, cmd.Timestamp.String())
 			event.Timestamp = cmd.Timestamp.Format(time.RFC3339)
@@ -194,8 +268,11 @@ func (p *Poller) extractEvents(conv ParsedConversation) []*events.Event {
 				"session_id":  conv.SessionID,
 				"command":     cmd.Command,
 				"description": cmd.Description,
-				"stdout":      cmd.Stdout,
-				"stderr":      cmd.Stderr,
+				"stdout":      truncateString(cmd.Stdout, 2000),
+				"stderr":      truncateString(cmd.Stderr, 2000),
+			}
+			if cmd.Failed {
+				event.Payload["exit_code"] = cmd.ExitCode
 			}
 
 			result = append(result, event)
@@ -215,6 +292,7 @@ func (p *Poller) extractEvents(conv ParsedConversation) []*events.Event {
 			event.Payload = map[string]interface{}{
 				"session_id": conv.SessionID,
 				"file_path":  edit.FilePath,
+				"operation":  edit.Operation,
 				"old_string": truncateString(edit.OldString, 500),
 				"new_string": truncateString(edit.NewString, 500),
 			}
@@ -223,9 +301,128 @@ func (p *Poller) extractEvents(conv ParsedConversation) []*events.Event {
 		}
 	}
 
+	if p.extractToolCalls {
+		for _, call := range conv.ToolCalls {
+			if _, covered := subEventTools[call.Name]; covered {
+				continue
+			}
+
+			event := events.NewEvent("claude", "tool_call")
+			event.ID = generateID(conv.SessionID, "tool_call", call.ID, call.Name, call.Timestamp.String())
+			event.Timestamp = call.Timestamp.Format(time.RFC3339)
+			if conv.CWD != "" {
+				event.Repo = filepath.Base(conv.CWD)
+			}
+			event.Branch = conv.GitBranch
+
+			event.Payload = map[string]interface{}{
+				"session_id": conv.SessionID,
+				"tool":       call.Name,
+				"target":     call.Target,
+				"failed":     call.Failed,
+			}
+
+			result = append(result, event)
+		}
+	}
+
+	// Everything from one conversation shares its session; sub-events point
+	// at the conversation event when one was recorded.
+	hasConversation := len(conv.UserMessage) >= p.minMessageLength
+	for _, event := range result {
+		event.SessionID = conv.SessionID
+		if hasConversation && event.ID != conv.SessionID {
+			event.ParentID = conv.SessionID
+		}
+		if code, ok := event.Payload["exit_code"].(int); ok && code != 0 {
+			event.Severity = string(events.SeverityError)
+		} else if passed, ok := event.Payload["passed"].(bool); ok && !passed {
+			event.Severity = string(events.SeverityError)
+		} else if failed, ok := event.Payload["failed"].(bool); ok && failed {
+			event.Severity = string(events.SeverityError)
+		}
+	}
+
+	if conv.CWD != "" {
+		if repo, err := vcs.Detect(conv.CWD); err == nil {
+			for _, event := range result {
+				event.Payload["vcs"] = string(repo.Kind)
+				if event.Branch == "" {
+					event.Branch = repo.Branch
+				}
+				if repo.Remote != "" {
+					event.Payload[config.RemotePayloadKey] = repo.Remote
+				}
+			}
+		}
+	}
+
 	return result
 }
 
+// subEventTools already produce their own command/file_edit events, so they
+// are not repeated as tool_call events.
+var subEventTools = map[string]struct{}{
+	"Bash":      {},
+	"Edit":      {},
+	"MultiEdit": {},
+	"Write":     {},
+}
+
+// filesTouched lists the files edited or written in a conversation, relative
+// to its working directory when possible.
+func filesTouched(conv ParsedConversation) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, edit := range conv.FileEdits {
+		path := edit.FilePath
+		if conv.CWD != "" {
+			if rel, err := filepath.Rel(conv.CWD, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+const maxConversationCommands = 20
+
+func (p *Poller) commandList(cmds []CommandExecution) []string {
+	var list []string
+	for _, cmd := range cmds {
+		if p.isIgnored(cmd.Command) {
+			continue
+		}
+		if len(list) == maxConversationCommands {
+			break
+		}
+		list = append(list, truncateString(cmd.Command, 200))
+	}
+	return list
+}
+
+func toolCounts(calls []ToolCall) map[string]int {
+	counts := make(map[string]int)
+	for _, call := range calls {
+		counts[call.Name]++
+	}
+	return counts
+}
+
+func tailString(s string, maxLen int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxLen {
+		return s
+	}
+	return "..." + s[len(s)-maxLen:]
+}
+
 func generateID(parts ...string) string {
 	combined := strings.Join(parts, "|")
 	hash := md5.Sum([]byte(combined))
@@ -24,7 +24,6 @@ func main() {
 
 	p, err := claude.NewPoller(
 		projectsDir,
-		cwd,
 		"/tmp",
 		30*time.Second,
 		true,
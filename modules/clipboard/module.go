@@ -76,6 +76,13 @@ func (m *Module) DefaultConfig() interface{} {
 	}
 }
 
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources:    []string{"clipboard"},
+		QuietAfter: 3 * 24 * time.Hour,
+	}
+}
+
 func (m *Module) ValidateConfig(config interface{}) error {
 	cfg, ok := config.(map[string]interface{})
 	if !ok {
@@ -0,0 +1,244 @@
+package forge
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"devlog/internal/events"
+	"devlog/internal/modules"
+)
+
+type bitbucketUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+}
+
+func (u bitbucketUser) login() string {
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+	return u.DisplayName
+}
+
+type bitbucketLinks struct {
+	HTML struct {
+		Href string `json:"href"`
+	} `json:"html"`
+}
+
+type bitbucketRepository struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+}
+
+func (r bitbucketRepository) repo() Repo {
+	return Repo{Name: r.Name, FullName: r.FullName}
+}
+
+type bitbucketBranch struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+type bitbucketPullRequest struct {
+	ID          int             `json:"id"`
+	Title       string          `json:"title"`
+	Links       bitbucketLinks  `json:"links"`
+	Author      bitbucketUser   `json:"author"`
+	Source      bitbucketBranch `json:"source"`
+	Destination bitbucketBranch `json:"destination"`
+	CreatedOn   string          `json:"created_on"`
+	UpdatedOn   string          `json:"updated_on"`
+}
+
+type bitbucketComment struct {
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Links     bitbucketLinks `json:"links"`
+	CreatedOn string         `json:"created_on"`
+}
+
+type bitbucketCommitStatus struct {
+	Name      string `json:"name"`
+	Key       string `json:"key"`
+	State     string `json:"state"`
+	URL       string `json:"url"`
+	Refname   string `json:"refname"`
+	UpdatedOn string `json:"updated_on"`
+	Commit    struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+}
+
+type bitbucketApproval struct {
+	Date string `json:"date"`
+}
+
+type bitbucketPayload struct {
+	Actor        bitbucketUser          `json:"actor"`
+	Repository   bitbucketRepository    `json:"repository"`
+	PullRequest  *bitbucketPullRequest  `json:"pullrequest"`
+	Approval     *bitbucketApproval     `json:"approval"`
+	Comment      *bitbucketComment      `json:"comment"`
+	CommitStatus *bitbucketCommitStatus `json:"commit_status"`
+}
+
+// bitbucketConclusions maps finished build states to GitHub's workflow run
+// conclusions. INPROGRESS builds are skipped.
+var bitbucketConclusions = map[string]string{
+	"SUCCESSFUL": "success",
+	"FAILED":     "failure",
+	"STOPPED":    "cancelled",
+}
+
+// handleBitbucket converts a Bitbucket Cloud webhook. Bitbucket signs the
+// body like GitHub, but in X-Hub-Signature.
+func handleBitbucket(headers http.Header, body []byte, config map[string]interface{}) ([]*events.Event, error) {
+	secret, _ := config["webhook_secret"].(string)
+	if !VerifyHMAC(secret, headers.Get("X-Hub-Signature"), body) {
+		return nil, modules.ErrWebhookUnauthorized
+	}
+
+	eventKey := headers.Get("X-Event-Key")
+	if eventKey == "" {
+		return nil, fmt.Errorf("missing X-Event-Key header")
+	}
+	if eventKey == "diagnostics:ping" {
+		return nil, nil
+	}
+
+	var payload bitbucketPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	if !RepoAllowed(config, payload.Repository.repo()) {
+		return nil, nil
+	}
+
+	event := convertBitbucket(eventKey, &payload)
+	if event == nil {
+		return nil, nil
+	}
+
+	if delivery := headers.Get("X-Request-UUID"); delivery != "" {
+		event.ID = DeliveryID(events.SourceBitbucket, delivery)
+	}
+
+	return []*events.Event{event}, nil
+}
+
+func convertBitbucket(eventKey string, payload *bitbucketPayload) *events.Event {
+	switch eventKey {
+	case "pullrequest:created", "pullrequest:fulfilled", "pullrequest:rejected":
+		return convertBitbucketPullRequest(eventKey, payload)
+	case "pullrequest:approved", "pullrequest:comment_created":
+		return convertBitbucketReview(eventKey, payload)
+	case "repo:commit_status_updated":
+		return convertBitbucketCommitStatus(payload)
+	default:
+		return nil
+	}
+}
+
+func convertBitbucketPullRequest(eventKey string, payload *bitbucketPayload) *events.Event {
+	pr := payload.PullRequest
+	if pr == nil {
+		return nil
+	}
+
+	var eventType events.EventType
+	var action string
+	timestamp := pr.UpdatedOn
+	switch eventKey {
+	case "pullrequest:created":
+		eventType, action = events.TypePROpened, "opened"
+		timestamp = pr.CreatedOn
+	case "pullrequest:fulfilled":
+		eventType, action = events.TypePRMerged, "merged"
+	default:
+		eventType, action = events.TypePRClosed, "declined"
+	}
+
+	event := NewEvent(events.SourceBitbucket, eventType, payload.Repository.repo(), action, payload.Actor.login(), timestamp)
+	setBitbucketPullRequest(event, payload)
+	if eventType == events.TypePRMerged {
+		event.Payload["merged_by"] = payload.Actor.login()
+	}
+	return event
+}
+
+func convertBitbucketReview(eventKey string, payload *bitbucketPayload) *events.Event {
+	if payload.PullRequest == nil {
+		return nil
+	}
+
+	state, timestamp := "approved", ""
+	if eventKey == "pullrequest:comment_created" {
+		if payload.Comment == nil {
+			return nil
+		}
+		state, timestamp = "commented", payload.Comment.CreatedOn
+	} else if payload.Approval != nil {
+		timestamp = payload.Approval.Date
+	}
+
+	event := NewEvent(events.SourceBitbucket, events.TypePRReview, payload.Repository.repo(), state, payload.Actor.login(), timestamp)
+	setBitbucketPullRequest(event, payload)
+	event.Payload["reviewer"] = payload.Actor.login()
+	event.Payload["state"] = state
+	if payload.Comment != nil {
+		if url := payload.Comment.Links.HTML.Href; url != "" {
+			event.Payload["url"] = url
+		}
+		if body := payload.Comment.Content.Raw; body != "" {
+			event.Payload["body"] = body
+		}
+	}
+	return event
+}
+
+func setBitbucketPullRequest(event *events.Event, payload *bitbucketPayload) {
+	pr := payload.PullRequest
+	event.Branch = pr.Source.Branch.Name
+	event.SessionID = SessionID(events.SourceBitbucket, payload.Repository.repo(), pr.ID)
+	event.Payload["pr_number"] = pr.ID
+	event.Payload["title"] = pr.Title
+	event.Payload["url"] = pr.Links.HTML.Href
+	event.Payload["author"] = pr.Author.login()
+	event.Payload["base_branch"] = pr.Destination.Branch.Name
+}
+
+// convertBitbucketCommitStatus records finished builds, which is how
+// Bitbucket Pipelines and external CI report to Bitbucket.
+func convertBitbucketCommitStatus(payload *bitbucketPayload) *events.Event {
+	status := payload.CommitStatus
+	if status == nil {
+		return nil
+	}
+	conclusion, finished := bitbucketConclusions[strings.ToUpper(status.State)]
+	if !finished {
+		return nil
+	}
+
+	name := status.Name
+	if name == "" {
+		name = status.Key
+	}
+
+	event := NewEvent(events.SourceBitbucket, events.TypeWorkflowRun, payload.Repository.repo(), "completed", payload.Actor.login(), status.UpdatedOn)
+	event.Branch = status.Refname
+	event.Payload["title"] = name
+	event.Payload["workflow"] = name
+	event.Payload["conclusion"] = conclusion
+	event.Payload["head_sha"] = status.Commit.Hash
+	event.Payload["url"] = status.URL
+	if FailedConclusion(conclusion) {
+		event.Severity = string(events.SeverityError)
+	}
+	return event
+}
@@ -0,0 +1,155 @@
+// Package forge holds what the code hosting webhook receivers share: the
+// mapping of pull request, review, issue and CI events onto devlog events,
+// signature checks and the repo allowlist. The github module uses it for
+// GitHub; the forge module itself receives GitLab and Bitbucket webhooks.
+package forge
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+
+	"github.com/google/uuid"
+)
+
+const (
+	signaturePrefix = "sha256="
+	minSecretLength = 16
+)
+
+// Repo is the repository a webhook is about. FullName includes the owner
+// or group ("me/devlog", "group/subgroup/project").
+type Repo struct {
+	Name     string
+	FullName string
+}
+
+// NewEvent starts an event for a webhook delivery. timestamp is used when
+// it parses, otherwise the event keeps the receive time.
+func NewEvent(source events.EventSource, eventType events.EventType, repo Repo, action, actor, timestamp string) *events.Event {
+	event := events.NewEvent(string(source), string(eventType))
+	if ts, ok := ParseTime(timestamp); ok {
+		event.Timestamp = ts.UTC().Format(time.RFC3339)
+	}
+	event.Repo = repo.Name
+	event.Payload["action"] = action
+	event.Payload["full_name"] = repo.FullName
+	event.Payload["actor"] = actor
+	return event
+}
+
+// timeLayouts covers RFC 3339 and the "2025-06-12 14:03:11 UTC" and
+// "2025-06-12 14:03:11 +0200" forms GitLab uses in some hooks.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+}
+
+func ParseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if ts, err := time.Parse(layout, s); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// SessionID groups every event about one pull request: opening, reviews and
+// the merge or close.
+func SessionID(source events.EventSource, repo Repo, number int) string {
+	name := repo.FullName
+	if name == "" {
+		name = repo.Name
+	}
+	return fmt.Sprintf("%s:%s#%d", source, name, number)
+}
+
+// DeliveryID derives a stable event ID from the provider's delivery ID, so
+// redelivered webhooks are stored as duplicates.
+func DeliveryID(source events.EventSource, delivery string) string {
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(string(source)+":"+delivery)).String()
+}
+
+// VerifyHMAC checks a "sha256=<hex>" HMAC signature of body keyed by secret.
+func VerifyHMAC(secret, signature string, body []byte) bool {
+	if secret == "" || !strings.HasPrefix(signature, signaturePrefix) {
+		return false
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, signaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// RepoAllowed reports whether repo is in the config's repos allowlist. An
+// empty or missing list allows every repo.
+func RepoAllowed(config map[string]interface{}, repo Repo) bool {
+	allowed, ok := config["repos"].([]interface{})
+	if !ok || len(allowed) == 0 {
+		return true
+	}
+
+	for _, r := range allowed {
+		name, _ := r.(string)
+		if strings.EqualFold(name, repo.FullName) || strings.EqualFold(name, repo.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+func GenerateSecret() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// ValidateReceiver checks a receiver's webhook_secret and repos allowlist.
+func ValidateReceiver(config map[string]interface{}) error {
+	secret, ok := config["webhook_secret"].(string)
+	if !ok || secret == "" {
+		return fmt.Errorf("webhook_secret is required")
+	}
+	if len(secret) < minSecretLength {
+		return fmt.Errorf("webhook_secret must be at least %d characters", minSecretLength)
+	}
+
+	val, ok := config["repos"]
+	if !ok || val == nil {
+		return nil
+	}
+	repos, ok := val.([]interface{})
+	if !ok {
+		return fmt.Errorf("repos must be a list")
+	}
+	for _, r := range repos {
+		if _, ok := r.(string); !ok {
+			return fmt.Errorf("repos must contain only strings")
+		}
+	}
+	return nil
+}
+
+// FailedConclusion reports whether a CI conclusion, in GitHub's terms,
+// should mark the run's event as an error.
+func FailedConclusion(conclusion string) bool {
+	switch conclusion {
+	case "failure", "timed_out", "startup_failure":
+		return true
+	}
+	return false
+}
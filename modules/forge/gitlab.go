@@ -0,0 +1,238 @@
+package forge
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"devlog/internal/events"
+	"devlog/internal/modules"
+)
+
+type gitlabUser struct {
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+type gitlabProject struct {
+	Name              string `json:"name"`
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+func (p gitlabProject) repo() Repo {
+	return Repo{Name: p.Name, FullName: p.PathWithNamespace}
+}
+
+type gitlabMergeRequest struct {
+	IID          int    `json:"iid"`
+	Title        string `json:"title"`
+	URL          string `json:"url"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+}
+
+type gitlabAttributes struct {
+	gitlabMergeRequest
+
+	ID           int    `json:"id"`
+	Action       string `json:"action"`
+	State        string `json:"state"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+	ClosedAt     string `json:"closed_at"`
+	FinishedAt   string `json:"finished_at"`
+	Note         string `json:"note"`
+	NoteableType string `json:"noteable_type"`
+	Ref          string `json:"ref"`
+	SHA          string `json:"sha"`
+	Status       string `json:"status"`
+	Source       string `json:"source"`
+	Name         string `json:"name"`
+}
+
+type gitlabPayload struct {
+	ObjectKind       string              `json:"object_kind"`
+	User             gitlabUser          `json:"user"`
+	Project          gitlabProject       `json:"project"`
+	ObjectAttributes gitlabAttributes    `json:"object_attributes"`
+	MergeRequest     *gitlabMergeRequest `json:"merge_request"`
+}
+
+// gitlabConclusions maps finished pipeline statuses to GitHub's workflow
+// run conclusions. Pipelines in any other status are still running.
+var gitlabConclusions = map[string]string{
+	"success":  "success",
+	"failed":   "failure",
+	"canceled": "cancelled",
+	"skipped":  "skipped",
+}
+
+// handleGitLab converts a GitLab webhook. GitLab sends the secret token
+// itself in X-Gitlab-Token rather than signing the body.
+func handleGitLab(headers http.Header, body []byte, config map[string]interface{}) ([]*events.Event, error) {
+	secret, _ := config["webhook_secret"].(string)
+	token := headers.Get("X-Gitlab-Token")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(token)) != 1 {
+		return nil, modules.ErrWebhookUnauthorized
+	}
+
+	var payload gitlabPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	if !RepoAllowed(config, payload.Project.repo()) {
+		return nil, nil
+	}
+
+	event := convertGitLab(&payload)
+	if event == nil {
+		return nil, nil
+	}
+
+	delivery := headers.Get("X-Gitlab-Event-UUID")
+	if delivery == "" {
+		delivery = headers.Get("X-Gitlab-Webhook-UUID")
+	}
+	if delivery != "" {
+		event.ID = DeliveryID(events.SourceGitLab, delivery)
+	}
+
+	return []*events.Event{event}, nil
+}
+
+func convertGitLab(payload *gitlabPayload) *events.Event {
+	switch payload.ObjectKind {
+	case "merge_request":
+		return convertGitLabMergeRequest(payload)
+	case "note":
+		return convertGitLabNote(payload)
+	case "issue":
+		return convertGitLabIssue(payload)
+	case "pipeline":
+		return convertGitLabPipeline(payload)
+	default:
+		return nil
+	}
+}
+
+func convertGitLabMergeRequest(payload *gitlabPayload) *events.Event {
+	mr := payload.ObjectAttributes
+
+	var eventType events.EventType
+	timestamp := mr.UpdatedAt
+	switch mr.Action {
+	case "open":
+		eventType = events.TypePROpened
+		timestamp = mr.CreatedAt
+	case "merge":
+		eventType = events.TypePRMerged
+	case "close":
+		eventType = events.TypePRClosed
+	case "approved":
+		eventType = events.TypePRReview
+	default:
+		return nil
+	}
+
+	event := NewEvent(events.SourceGitLab, eventType, payload.Project.repo(), mr.Action, payload.User.Username, timestamp)
+	setGitLabMergeRequest(event, payload, mr.gitlabMergeRequest)
+	switch eventType {
+	case events.TypePROpened:
+		event.Payload["author"] = payload.User.Username
+	case events.TypePRMerged:
+		event.Payload["merged_by"] = payload.User.Username
+	case events.TypePRReview:
+		event.Payload["reviewer"] = payload.User.Username
+		event.Payload["state"] = "approved"
+	}
+	return event
+}
+
+// convertGitLabNote records comments on merge requests as reviews in the
+// "commented" state, like GitHub's review comments. Comments on issues,
+// commits and snippets are ignored.
+func convertGitLabNote(payload *gitlabPayload) *events.Event {
+	note := payload.ObjectAttributes
+	if note.NoteableType != "MergeRequest" || payload.MergeRequest == nil {
+		return nil
+	}
+
+	event := NewEvent(events.SourceGitLab, events.TypePRReview, payload.Project.repo(), "commented", payload.User.Username, note.CreatedAt)
+	setGitLabMergeRequest(event, payload, *payload.MergeRequest)
+	event.Payload["url"] = note.URL
+	event.Payload["reviewer"] = payload.User.Username
+	event.Payload["state"] = "commented"
+	if note.Note != "" {
+		event.Payload["body"] = note.Note
+	}
+	return event
+}
+
+func setGitLabMergeRequest(event *events.Event, payload *gitlabPayload, mr gitlabMergeRequest) {
+	event.Branch = mr.SourceBranch
+	event.SessionID = SessionID(events.SourceGitLab, payload.Project.repo(), mr.IID)
+	event.Payload["pr_number"] = mr.IID
+	event.Payload["title"] = mr.Title
+	event.Payload["url"] = mr.URL
+	event.Payload["base_branch"] = mr.TargetBranch
+}
+
+func convertGitLabIssue(payload *gitlabPayload) *events.Event {
+	is := payload.ObjectAttributes
+
+	var eventType events.EventType
+	var timestamp string
+	switch is.Action {
+	case "open":
+		eventType = events.TypeIssueOpened
+		timestamp = is.CreatedAt
+	case "close":
+		eventType = events.TypeIssueClosed
+		timestamp = is.ClosedAt
+		if timestamp == "" {
+			timestamp = is.UpdatedAt
+		}
+	default:
+		return nil
+	}
+
+	event := NewEvent(events.SourceGitLab, eventType, payload.Project.repo(), is.Action, payload.User.Username, timestamp)
+	event.Payload["issue_number"] = is.IID
+	event.Payload["title"] = is.Title
+	event.Payload["url"] = is.URL
+	event.Payload["author"] = payload.User.Username
+	return event
+}
+
+func convertGitLabPipeline(payload *gitlabPayload) *events.Event {
+	run := payload.ObjectAttributes
+	conclusion, finished := gitlabConclusions[run.Status]
+	if !finished {
+		return nil
+	}
+
+	name := run.Name
+	if name == "" {
+		name = "pipeline"
+	}
+
+	event := NewEvent(events.SourceGitLab, events.TypeWorkflowRun, payload.Project.repo(), "completed", payload.User.Username, run.FinishedAt)
+	event.Branch = run.Ref
+	event.Payload["title"] = name
+	event.Payload["workflow"] = name
+	event.Payload["run_number"] = run.ID
+	event.Payload["conclusion"] = conclusion
+	event.Payload["head_sha"] = run.SHA
+	event.Payload["trigger"] = run.Source
+	if payload.Project.WebURL != "" {
+		event.Payload["url"] = fmt.Sprintf("%s/-/pipelines/%d", strings.TrimSuffix(payload.Project.WebURL, "/"), run.ID)
+	}
+	if FailedConclusion(conclusion) {
+		event.Severity = string(events.SeverityError)
+	}
+	return event
+}
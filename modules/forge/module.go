@@ -0,0 +1,149 @@
+package forge
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+)
+
+type webhookHandler func(headers http.Header, body []byte, config map[string]interface{}) ([]*events.Event, error)
+
+// provider is a hosting service the forge module receives webhooks from.
+// detect recognizes its deliveries by the headers it always sends.
+type provider struct {
+	name   string
+	detect func(headers http.Header) bool
+	handle webhookHandler
+}
+
+var providers = []provider{
+	{
+		name:   "gitlab",
+		detect: func(h http.Header) bool { return h.Get("X-Gitlab-Event") != "" },
+		handle: handleGitLab,
+	},
+	{
+		name:   "bitbucket",
+		detect: func(h http.Header) bool { return h.Get("X-Event-Key") != "" },
+		handle: handleBitbucket,
+	},
+}
+
+type Module struct{}
+
+func (m *Module) Name() string {
+	return "forge"
+}
+
+func (m *Module) Description() string {
+	return "Receive GitLab and Bitbucket webhooks for merge requests, reviews, comments, issues, and pipelines"
+}
+
+func (m *Module) Install(ctx *install.Context) error {
+	ctx.Log("Installing GitLab and Bitbucket webhook receiver...")
+	ctx.Log("")
+	ctx.Log("The daemon will accept webhooks from both at:")
+	ctx.Log("  POST /api/v1/webhooks/forge")
+	ctx.Log("")
+	ctx.Log("GitLab (project or group Settings > Webhooks):")
+	ctx.Log("  URL:          https://<your-tunnel>/api/v1/webhooks/forge")
+	ctx.Log("  Secret token: modules.forge.gitlab.webhook_secret from config.yaml")
+	ctx.Log("  Triggers:     Merge request, Comments, Issues, Pipeline events")
+	ctx.Log("")
+	ctx.Log("Bitbucket (Repository settings > Webhooks):")
+	ctx.Log("  URL:          https://<your-tunnel>/api/v1/webhooks/forge")
+	ctx.Log("  Secret:       modules.forge.bitbucket.webhook_secret from config.yaml")
+	ctx.Log("  Triggers:     Pull request created, approved, comment created, merged, declined;")
+	ctx.Log("                Repository build status updated")
+	ctx.Log("")
+	ctx.Log("Note: the daemon listens on 127.0.0.1, so the provider needs a tunnel")
+	ctx.Log("(e.g. cloudflared, ngrok, tailscale funnel) to reach it.")
+	ctx.Log("")
+	ctx.Log("✓ Forge webhook receiver enabled")
+
+	return nil
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling GitLab and Bitbucket webhook receiver...")
+	ctx.Log("✓ Webhooks will be rejected once the config is reloaded")
+	ctx.Log("")
+	ctx.Log("Note: Remember to remove the webhooks from your GitLab and Bitbucket settings.")
+	return nil
+}
+
+func (m *Module) DefaultConfig() interface{} {
+	cfg := map[string]interface{}{}
+	for _, p := range providers {
+		cfg[p.name] = map[string]interface{}{
+			"webhook_secret": GenerateSecret(),
+			"repos":          []interface{}{},
+		}
+	}
+	return cfg
+}
+
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources: []string{string(events.SourceGitLab), string(events.SourceBitbucket)},
+	}
+}
+
+func (m *Module) ValidateConfig(config interface{}) error {
+	cfg, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config must be a map")
+	}
+
+	known := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		known[p.name] = true
+		val, ok := cfg[p.name]
+		if !ok || val == nil {
+			continue
+		}
+		providerCfg, ok := val.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be a map", p.name)
+		}
+		if err := ValidateReceiver(providerCfg); err != nil {
+			return fmt.Errorf("%s: %w", p.name, err)
+		}
+	}
+
+	var unknown []string
+	for name := range cfg {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown provider %q (supported: gitlab, bitbucket)", unknown[0])
+	}
+	return nil
+}
+
+// HandleWebhook routes a delivery to the provider that sent it. Providers
+// without a config section are rejected like a bad signature.
+func (m *Module) HandleWebhook(headers http.Header, body []byte, config map[string]interface{}) ([]*events.Event, error) {
+	for _, p := range providers {
+		if !p.detect(headers) {
+			continue
+		}
+		providerCfg, ok := config[p.name].(map[string]interface{})
+		if !ok {
+			return nil, modules.ErrWebhookUnauthorized
+		}
+		return p.handle(headers, body, providerCfg)
+	}
+	return nil, fmt.Errorf("unrecognized webhook: expected GitLab or Bitbucket headers")
+}
+
+func init() {
+	modules.Register(&Module{})
+}
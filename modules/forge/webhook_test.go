@@ -0,0 +1,332 @@
+package forge
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/modules"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func testConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"gitlab":    map[string]interface{}{"webhook_secret": testSecret},
+		"bitbucket": map[string]interface{}{"webhook_secret": testSecret},
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{"valid", testSecret, sign(testSecret, body), true},
+		{"wrong secret", testSecret, sign("another-secret-value", body), false},
+		{"missing prefix", testSecret, sign(testSecret, body)[len("sha256="):], false},
+		{"not hex", testSecret, "sha256=zzzz", false},
+		{"empty secret", "", sign("", body), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyHMAC(tt.secret, tt.signature, body); got != tt.want {
+				t.Errorf("VerifyHMAC() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for _, s := range []string{"2026-01-02T10:00:00Z", "2026-01-02 10:00:00 UTC", "2026-01-02 12:00:00 +0200"} {
+		ts, ok := ParseTime(s)
+		if !ok || ts.UTC().Format("2006-01-02T15:04:05") != "2026-01-02T10:00:00" {
+			t.Errorf("ParseTime(%q) = %v, %v", s, ts, ok)
+		}
+	}
+	if _, ok := ParseTime("yesterday"); ok {
+		t.Error("ParseTime() accepted an invalid time")
+	}
+}
+
+func TestGitLabWebhook(t *testing.T) {
+	m := &Module{}
+	project := `"project":{"name":"devlog","path_with_namespace":"team/devlog","web_url":"https://gitlab.com/team/devlog"}`
+
+	tests := []struct {
+		name       string
+		kind       string
+		body       string
+		wantType   events.EventType
+		wantBranch string
+		check      func(t *testing.T, e *events.Event)
+	}{
+		{
+			name:       "merge request opened",
+			kind:       "Merge Request Hook",
+			body:       `{"object_kind":"merge_request","user":{"username":"me"},` + project + `,"object_attributes":{"iid":12,"title":"Add forge","url":"https://gitlab.com/team/devlog/-/merge_requests/12","source_branch":"feature/forge","target_branch":"main","action":"open","created_at":"2026-01-02 10:00:00 UTC"}}`,
+			wantType:   events.TypePROpened,
+			wantBranch: "feature/forge",
+			check: func(t *testing.T, e *events.Event) {
+				if e.Timestamp != "2026-01-02T10:00:00Z" || e.Payload["pr_number"] != 12 || e.Payload["author"] != "me" {
+					t.Errorf("unexpected event: %s %v", e.Timestamp, e.Payload)
+				}
+				if e.SessionID != "gitlab:team/devlog#12" {
+					t.Errorf("session = %q", e.SessionID)
+				}
+			},
+		},
+		{
+			name:       "merge request merged",
+			kind:       "Merge Request Hook",
+			body:       `{"object_kind":"merge_request","user":{"username":"lead"},` + project + `,"object_attributes":{"iid":12,"source_branch":"feature/forge","action":"merge"}}`,
+			wantType:   events.TypePRMerged,
+			wantBranch: "feature/forge",
+		},
+		{
+			name:       "merge request approved",
+			kind:       "Merge Request Hook",
+			body:       `{"object_kind":"merge_request","user":{"username":"lead"},` + project + `,"object_attributes":{"iid":12,"source_branch":"feature/forge","action":"approved"}}`,
+			wantType:   events.TypePRReview,
+			wantBranch: "feature/forge",
+			check: func(t *testing.T, e *events.Event) {
+				if e.Payload["state"] != "approved" || e.Payload["reviewer"] != "lead" {
+					t.Errorf("unexpected payload: %v", e.Payload)
+				}
+			},
+		},
+		{
+			name:       "comment on merge request",
+			kind:       "Note Hook",
+			body:       `{"object_kind":"note","user":{"username":"lead"},` + project + `,"object_attributes":{"note":"needs a test","noteable_type":"MergeRequest","url":"https://gitlab.com/x#note_1"},"merge_request":{"iid":12,"title":"Add forge","source_branch":"feature/forge"}}`,
+			wantType:   events.TypePRReview,
+			wantBranch: "feature/forge",
+			check: func(t *testing.T, e *events.Event) {
+				if e.Payload["state"] != "commented" || e.Payload["body"] != "needs a test" {
+					t.Errorf("unexpected payload: %v", e.Payload)
+				}
+			},
+		},
+		{
+			name:     "issue closed",
+			kind:     "Issue Hook",
+			body:     `{"object_kind":"issue","user":{"username":"me"},` + project + `,"object_attributes":{"iid":4,"title":"Crash","action":"close"}}`,
+			wantType: events.TypeIssueClosed,
+		},
+		{
+			name:       "pipeline failed",
+			kind:       "Pipeline Hook",
+			body:       `{"object_kind":"pipeline","user":{"username":"me"},` + project + `,"object_attributes":{"id":881,"ref":"main","sha":"abc123","status":"failed","source":"push","finished_at":"2026-01-02 10:05:00 UTC"}}`,
+			wantType:   events.TypeWorkflowRun,
+			wantBranch: "main",
+			check: func(t *testing.T, e *events.Event) {
+				if e.Payload["conclusion"] != "failure" || e.Severity != string(events.SeverityError) {
+					t.Errorf("unexpected event: %v %s", e.Payload, e.Severity)
+				}
+				if e.Payload["url"] != "https://gitlab.com/team/devlog/-/pipelines/881" {
+					t.Errorf("url = %v", e.Payload["url"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			h.Set("X-Gitlab-Event", tt.kind)
+			h.Set("X-Gitlab-Token", testSecret)
+			evts, err := m.HandleWebhook(h, []byte(tt.body), testConfig())
+			if err != nil {
+				t.Fatalf("HandleWebhook() error: %v", err)
+			}
+			if len(evts) != 1 {
+				t.Fatalf("got %d events, want 1", len(evts))
+			}
+			e := evts[0]
+			if e.Source != "gitlab" || e.Type != string(tt.wantType) || e.Branch != tt.wantBranch || e.Repo != "devlog" {
+				t.Errorf("got %s/%s repo=%s branch=%s, want gitlab/%s branch=%s", e.Source, e.Type, e.Repo, e.Branch, tt.wantType, tt.wantBranch)
+			}
+			if err := e.Validate(); err != nil {
+				t.Errorf("Validate() error: %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+
+	running := `{"object_kind":"pipeline",` + project + `,"object_attributes":{"id":882,"status":"running"}}`
+	h := http.Header{}
+	h.Set("X-Gitlab-Event", "Pipeline Hook")
+	h.Set("X-Gitlab-Token", testSecret)
+	if evts, err := m.HandleWebhook(h, []byte(running), testConfig()); err != nil || len(evts) != 0 {
+		t.Errorf("running pipeline: got %d events, %v", len(evts), err)
+	}
+
+	h.Set("X-Gitlab-Token", "wrong-token-value-here")
+	if _, err := m.HandleWebhook(h, []byte(running), testConfig()); !errors.Is(err, modules.ErrWebhookUnauthorized) {
+		t.Errorf("wrong token: got %v, want ErrWebhookUnauthorized", err)
+	}
+}
+
+func TestBitbucketWebhook(t *testing.T) {
+	m := &Module{}
+	repo := `"repository":{"name":"devlog","full_name":"team/devlog"},"actor":{"nickname":"lead"}`
+	pr := `"pullrequest":{"id":9,"title":"Add forge","links":{"html":{"href":"https://bitbucket.org/team/devlog/pull-requests/9"}},"author":{"nickname":"me"},"source":{"branch":{"name":"feature/forge"}},"destination":{"branch":{"name":"main"}},"created_on":"2026-01-02T10:00:00.123456+00:00"}`
+
+	tests := []struct {
+		name     string
+		key      string
+		body     string
+		wantType events.EventType
+		check    func(t *testing.T, e *events.Event)
+	}{
+		{
+			name:     "pull request created",
+			key:      "pullrequest:created",
+			body:     `{` + repo + `,` + pr + `}`,
+			wantType: events.TypePROpened,
+			check: func(t *testing.T, e *events.Event) {
+				if e.Timestamp != "2026-01-02T10:00:00Z" || e.Branch != "feature/forge" || e.Payload["author"] != "me" {
+					t.Errorf("unexpected event: %s %s %v", e.Timestamp, e.Branch, e.Payload)
+				}
+			},
+		},
+		{
+			name:     "pull request merged",
+			key:      "pullrequest:fulfilled",
+			body:     `{` + repo + `,` + pr + `}`,
+			wantType: events.TypePRMerged,
+			check: func(t *testing.T, e *events.Event) {
+				if e.Payload["merged_by"] != "lead" {
+					t.Errorf("merged_by = %v", e.Payload["merged_by"])
+				}
+			},
+		},
+		{
+			name:     "pull request declined",
+			key:      "pullrequest:rejected",
+			body:     `{` + repo + `,` + pr + `}`,
+			wantType: events.TypePRClosed,
+		},
+		{
+			name:     "comment",
+			key:      "pullrequest:comment_created",
+			body:     `{` + repo + `,` + pr + `,"comment":{"content":{"raw":"looks good"},"created_on":"2026-01-02T11:00:00+00:00"}}`,
+			wantType: events.TypePRReview,
+			check: func(t *testing.T, e *events.Event) {
+				if e.Payload["state"] != "commented" || e.Payload["body"] != "looks good" || e.Payload["reviewer"] != "lead" {
+					t.Errorf("unexpected payload: %v", e.Payload)
+				}
+			},
+		},
+		{
+			name:     "build passed",
+			key:      "repo:commit_status_updated",
+			body:     `{` + repo + `,"commit_status":{"name":"Pipeline #41","state":"SUCCESSFUL","refname":"main","commit":{"hash":"abc123"}}}`,
+			wantType: events.TypeWorkflowRun,
+			check: func(t *testing.T, e *events.Event) {
+				if e.Payload["conclusion"] != "success" || e.Branch != "main" || e.Severity != "" {
+					t.Errorf("unexpected event: %v", e.Payload)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(tt.body)
+			h := http.Header{}
+			h.Set("X-Event-Key", tt.key)
+			h.Set("X-Request-UUID", "delivery-1")
+			h.Set("X-Hub-Signature", sign(testSecret, body))
+			evts, err := m.HandleWebhook(h, body, testConfig())
+			if err != nil {
+				t.Fatalf("HandleWebhook() error: %v", err)
+			}
+			if len(evts) != 1 {
+				t.Fatalf("got %d events, want 1", len(evts))
+			}
+			e := evts[0]
+			if e.Source != "bitbucket" || e.Type != string(tt.wantType) || e.Repo != "devlog" {
+				t.Errorf("got %s/%s repo=%s, want bitbucket/%s", e.Source, e.Type, e.Repo, tt.wantType)
+			}
+			if e.ID != DeliveryID(events.SourceBitbucket, "delivery-1") {
+				t.Errorf("ID not derived from the delivery")
+			}
+			if tt.check != nil {
+				tt.check(t, e)
+			}
+		})
+	}
+
+	body := []byte(`{` + repo + `,` + pr + `}`)
+	h := http.Header{}
+	h.Set("X-Event-Key", "pullrequest:created")
+	h.Set("X-Hub-Signature", sign("another-secret-value", body))
+	if _, err := m.HandleWebhook(h, body, testConfig()); !errors.Is(err, modules.ErrWebhookUnauthorized) {
+		t.Errorf("bad signature: got %v, want ErrWebhookUnauthorized", err)
+	}
+
+	h.Set("X-Hub-Signature", sign(testSecret, body))
+	gitlabOnly := map[string]interface{}{"gitlab": map[string]interface{}{"webhook_secret": testSecret}}
+	if _, err := m.HandleWebhook(h, body, gitlabOnly); !errors.Is(err, modules.ErrWebhookUnauthorized) {
+		t.Errorf("unconfigured provider: got %v, want ErrWebhookUnauthorized", err)
+	}
+
+	restricted := testConfig()
+	restricted["bitbucket"].(map[string]interface{})["repos"] = []interface{}{"team/other"}
+	if evts, err := m.HandleWebhook(h, body, restricted); err != nil || len(evts) != 0 {
+		t.Errorf("repo not in allowlist: got %d events, %v", len(evts), err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	m := &Module{}
+	if err := m.ValidateConfig(m.DefaultConfig()); err != nil {
+		t.Errorf("default config invalid: %v", err)
+	}
+	if err := m.ValidateConfig(map[string]interface{}{"gitlab": map[string]interface{}{"webhook_secret": "short"}}); err == nil {
+		t.Error("expected error for short secret")
+	}
+	if err := m.ValidateConfig(map[string]interface{}{"gitea": map[string]interface{}{}}); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+// TestConfigWithGenericSettings loads forge the way the daemon does, with
+// the settings every module accepts next to the providers.
+func TestConfigWithGenericSettings(t *testing.T) {
+	moduleCfg := testConfig()
+	moduleCfg["active_hours"] = "09:00-18:00 Mon-Fri"
+	moduleCfg["max_events_per_hour"] = 100
+	moduleCfg["sample_rate"] = 0.5
+	moduleCfg["resources"] = map[string]interface{}{"max_goroutines": 8}
+
+	cfg := config.DefaultConfig()
+	cfg.Modules["forge"] = config.ComponentConfig{Enabled: true, Config: moduleCfg}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+
+	moduleCfg["gitea"] = map[string]interface{}{}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() accepted an unknown provider")
+	}
+}
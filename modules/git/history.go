@@ -0,0 +1,454 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/state"
+	"devlog/internal/storage"
+	"devlog/internal/vcs"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	DefaultHistoryInterval = 15 * time.Second
+
+	// historySettle is how long a reflog entry is left alone, so an event
+	// the wrapper or a hook is still sending for it lands first.
+	historySettle = 10 * time.Second
+	// historyRescan is how often history_roots are searched for new repos
+	// and every reflog is read, whether or not a change was noticed.
+	historyRescan = 5 * time.Minute
+	// duplicateWindow is how far apart a reflog entry and an event recorded
+	// by the wrapper or a hook can be and still be the same operation.
+	duplicateWindow = 2 * time.Minute
+
+	offsetsKey = "reflog_offsets"
+)
+
+// RecordedFunc returns the git events already stored between start and end.
+type RecordedFunc func(ctx context.Context, start, end time.Time) ([]*events.Event, error)
+
+// HistoryWatcher reads the HEAD reflogs of repositories under history_roots
+// and turns new entries into git events, for repositories where neither the
+// wrapper nor hooks can be used. File notifications say which reflogs
+// changed; all of them are read on each rescan in case one was missed.
+//
+// A reflog is read from the end the first time its repository is seen, so
+// past history is not imported.
+type HistoryWatcher struct {
+	roots    []string
+	dataDir  string
+	interval time.Duration
+	stateMgr *state.Manager
+	recorded RecordedFunc
+
+	mu         sync.Mutex
+	reflogs    map[string]string // repo -> logs/HEAD path
+	dirty      map[string]bool
+	watcher    *fsnotify.Watcher
+	nextRescan time.Time
+}
+
+func NewHistoryWatcher(dataDir string, roots []string, interval time.Duration) (*HistoryWatcher, error) {
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("create state manager: %w", err)
+	}
+	return &HistoryWatcher{
+		roots:    roots,
+		dataDir:  dataDir,
+		interval: interval,
+		stateMgr: stateMgr,
+		recorded: storedGitEvents(dataDir),
+		dirty:    make(map[string]bool),
+	}, nil
+}
+
+// storedGitEvents looks events up in the event database, opening it only
+// for the duration of each lookup.
+func storedGitEvents(dataDir string) RecordedFunc {
+	return func(ctx context.Context, start, end time.Time) ([]*events.Event, error) {
+		store, err := storage.OpenConfigured(dataDir)
+		if err != nil {
+			return nil, err
+		}
+		defer store.Close()
+		return store.QueryEventsContext(ctx, storage.QueryOptions{
+			Source:    string(events.SourceGit),
+			StartTime: &start,
+			EndTime:   &end,
+		})
+	}
+}
+
+// Repos returns the repositories being watched, as of the last rescan.
+func (h *HistoryWatcher) Repos() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	repos := make([]string, 0, len(h.reflogs))
+	for repo := range h.reflogs {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// Poll returns events for the reflog entries written since the last poll
+// that are at least historySettle old, leaving out operations the wrapper
+// or a hook already recorded.
+func (h *HistoryWatcher) Poll(ctx context.Context, now time.Time) ([]*events.Event, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.reflogs == nil || !now.Before(h.nextRescan) {
+		if err := h.rescan(); err != nil {
+			return nil, err
+		}
+		h.nextRescan = now.Add(historyRescan)
+	}
+	h.drainNotifications()
+
+	offsets := h.loadOffsets()
+	cutoff := now.Add(-historySettle)
+
+	repos := make([]string, 0, len(h.dirty))
+	for repo := range h.dirty {
+		repos = append(repos, repo)
+	}
+	sort.Strings(repos)
+
+	var evts []*events.Event
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			break
+		}
+		reflog, ok := h.reflogs[repo]
+		if !ok {
+			delete(h.dirty, repo)
+			continue
+		}
+
+		offset, pending, repoEvents, err := readHistory(repo, reflog, offsets[repo], cutoff)
+		if err != nil {
+			delete(h.dirty, repo)
+			continue
+		}
+		offsets[repo] = offset
+		if !pending {
+			delete(h.dirty, repo)
+		}
+		evts = append(evts, repoEvents...)
+	}
+
+	evts, err := h.dropRecorded(ctx, evts)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range evts {
+		if event.Type == string(events.TypeCommit) {
+			describeCommit(event)
+		}
+		if detected, err := vcs.Detect(event.Repo); err == nil && detected.Remote != "" {
+			event.Payload[config.RemotePayloadKey] = detected.Remote
+		}
+	}
+
+	if err := h.stateMgr.Set(stateModule, offsetsKey, offsets); err != nil {
+		return nil, fmt.Errorf("save reflog offsets: %w", err)
+	}
+	return evts, nil
+}
+
+// rescan finds the repositories under the roots, skipping those with
+// devlog hooks, and starts watching their reflogs. Every reflog is marked
+// for reading.
+func (h *HistoryWatcher) rescan() error {
+	hooked, err := HookedRepos(h.dataDir)
+	if err != nil {
+		return err
+	}
+	skip := make(map[string]bool, len(hooked))
+	for _, repo := range hooked {
+		skip[repo] = true
+	}
+
+	offsets := h.loadOffsets()
+	reflogs := make(map[string]string)
+	for _, root := range h.roots {
+		found, err := FindRepos(root)
+		if err != nil {
+			return fmt.Errorf("scan %s: %w", root, err)
+		}
+		for _, repo := range found {
+			gitDir, ok := GitDir(repo)
+			if !ok || skip[repo] {
+				continue
+			}
+			reflogs[repo] = filepath.Join(gitDir, "logs", "HEAD")
+		}
+	}
+
+	if h.watcher == nil {
+		// Without notifications every reflog is read on each poll, which
+		// only costs a stat when nothing changed.
+		h.watcher, _ = fsnotify.NewBufferedWatcher(256)
+	}
+
+	for repo, reflog := range reflogs {
+		if _, known := offsets[repo]; !known {
+			offsets[repo] = fileSize(reflog)
+		}
+		if h.watcher != nil {
+			// Adding a watched directory again is harmless, and retries
+			// repos whose logs directory did not exist before their first
+			// commit.
+			h.watcher.Add(filepath.Dir(reflog))
+		}
+		h.dirty[repo] = true
+	}
+	for repo, reflog := range h.reflogs {
+		if _, ok := reflogs[repo]; !ok && h.watcher != nil {
+			h.watcher.Remove(filepath.Dir(reflog))
+		}
+	}
+	for repo := range offsets {
+		if _, ok := reflogs[repo]; !ok {
+			delete(offsets, repo)
+		}
+	}
+
+	h.reflogs = reflogs
+	return h.stateMgr.Set(stateModule, offsetsKey, offsets)
+}
+
+func (h *HistoryWatcher) drainNotifications() {
+	if h.watcher == nil {
+		for repo := range h.reflogs {
+			h.dirty[repo] = true
+		}
+		return
+	}
+
+	byReflog := make(map[string]string, len(h.reflogs))
+	for repo, reflog := range h.reflogs {
+		byReflog[reflog] = repo
+	}
+	for {
+		select {
+		case ev, ok := <-h.watcher.Events:
+			if !ok {
+				return
+			}
+			if repo, ok := byReflog[ev.Name]; ok {
+				h.dirty[repo] = true
+			}
+		case <-h.watcher.Errors:
+			// An overflow loses notifications; read everything.
+			for repo := range h.reflogs {
+				h.dirty[repo] = true
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Close stops watching reflogs. The next poll starts again.
+func (h *HistoryWatcher) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reflogs = nil
+	if h.watcher == nil {
+		return nil
+	}
+	err := h.watcher.Close()
+	h.watcher = nil
+	return err
+}
+
+func (h *HistoryWatcher) loadOffsets() map[string]int64 {
+	offsets := make(map[string]int64)
+	value, ok := h.stateMgr.Get(stateModule, offsetsKey)
+	if !ok {
+		return offsets
+	}
+	stored, _ := value.(map[string]interface{})
+	for repo, v := range stored {
+		if n, ok := v.(float64); ok {
+			offsets[repo] = int64(n)
+		}
+	}
+	return offsets
+}
+
+// dropRecorded removes events for operations the wrapper or a hook already
+// stored.
+func (h *HistoryWatcher) dropRecorded(ctx context.Context, evts []*events.Event) ([]*events.Event, error) {
+	if len(evts) == 0 || h.recorded == nil {
+		return evts, nil
+	}
+
+	var start, end time.Time
+	for i, event := range evts {
+		at, _ := time.Parse(time.RFC3339, event.Timestamp)
+		if i == 0 || at.Before(start) {
+			start = at
+		}
+		if i == 0 || at.After(end) {
+			end = at
+		}
+	}
+	stored, err := h.recorded(ctx, start.Add(-duplicateWindow), end.Add(duplicateWindow))
+	if err != nil {
+		return nil, fmt.Errorf("look up recorded git events: %w", err)
+	}
+
+	kept := evts[:0]
+	for _, event := range evts {
+		if !isRecorded(event, stored) {
+			kept = append(kept, event)
+		}
+	}
+	return kept, nil
+}
+
+// isRecorded reports whether one of stored is the same operation as event:
+// a commit with the same hash, or an event of the same type on the same
+// branch close in time.
+func isRecorded(event *events.Event, stored []*events.Event) bool {
+	at, _ := time.Parse(time.RFC3339, event.Timestamp)
+	for _, s := range stored {
+		if s.ID == event.ID || s.Type != event.Type {
+			continue
+		}
+		if event.Type == string(events.TypeCommit) {
+			if s.Payload["hash"] == event.Payload["hash"] {
+				return true
+			}
+			continue
+		}
+		sAt, err := time.Parse(time.RFC3339, s.Timestamp)
+		if err != nil || s.Branch != event.Branch {
+			continue
+		}
+		if d := sAt.Sub(at); d > -duplicateWindow && d < duplicateWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// readHistory reads the complete lines of reflog after offset and returns
+// the offset to resume from, whether entries newer than cutoff were left
+// for later, and the events for the rest.
+func readHistory(repo, reflog string, offset int64, cutoff time.Time) (int64, bool, []*events.Event, error) {
+	f, err := os.Open(reflog)
+	if err != nil {
+		return offset, false, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return offset, false, nil, err
+	}
+	if info.Size() < offset {
+		// The reflog was expired or rewritten; carry on from its end.
+		return info.Size(), false, nil, nil
+	}
+	if info.Size() == offset {
+		return offset, false, nil, nil
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, false, nil, err
+	}
+
+	var (
+		entries []ReflogEntry
+		ends    []int64
+		pos     = offset
+	)
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break // a partial last line is read once it is complete
+		}
+		pos += int64(len(line))
+		if entry, ok := ParseReflogLine(strings.TrimSuffix(line, "\n")); ok {
+			entries = append(entries, entry)
+			ends = append(ends, pos)
+		}
+	}
+
+	settled := 0
+	for settled < len(entries) && !entries[settled].Time.After(cutoff) {
+		settled++
+	}
+	if settled == 0 {
+		return offset, len(entries) > 0, nil, nil
+	}
+
+	gitDir, _ := GitDir(repo)
+	evts := HistoryEvents(repo, entries, headBranch(gitDir))
+	last := entries[settled-1].Time
+	kept := evts[:0]
+	for _, event := range evts {
+		if at, err := time.Parse(time.RFC3339, event.Timestamp); err == nil && !at.After(last) {
+			kept = append(kept, event)
+		}
+	}
+	return ends[settled-1], settled < len(entries), kept, nil
+}
+
+// describeCommit replaces the reflog's one-line summary of a commit with
+// its full message, author and changed files.
+func describeCommit(event *events.Event) {
+	hash, _ := event.Payload["hash"].(string)
+	out, err := exec.Command("git", "-C", event.Repo, "show", "-s", "--format=%an%x00%B", hash).Output()
+	if err != nil {
+		return
+	}
+	author, message, ok := bytes.Cut(out, []byte{0})
+	if !ok {
+		return
+	}
+	event.Payload["author"] = string(author)
+	if msg := strings.TrimSpace(string(message)); msg != "" {
+		event.Payload["message"] = msg
+	}
+
+	out, err = exec.Command("git", "-C", event.Repo, "diff-tree", "--root", "--no-commit-id", "--name-only", "-r", hash).Output()
+	if err != nil {
+		return
+	}
+	files := splitLines(string(out))
+	if len(files) > 200 {
+		files = files[:200]
+	}
+	if len(files) > 0 {
+		event.Payload["files"] = files
+	}
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
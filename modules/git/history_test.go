@@ -0,0 +1,139 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/install"
+)
+
+func runGit(t *testing.T, repo string, args ...string) {
+	t.Helper()
+	args = append([]string{"-C", repo, "-c", "user.name=Ada", "-c", "user.email=ada@example.com"}, args...)
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+}
+
+func commitFile(t *testing.T, repo, name, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(repo, name), []byte(message), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, repo, "add", name)
+	runGit(t, repo, "commit", "-q", "-m", message)
+}
+
+// pollSettled polls as if the reflog entries had settled until the watcher
+// has read the whole reflog, since file notifications arrive asynchronously.
+func pollSettled(t *testing.T, watcher *HistoryWatcher, repo string) []*events.Event {
+	t.Helper()
+	gitDir, _ := GitDir(repo)
+	size := fileSize(filepath.Join(gitDir, "logs", "HEAD"))
+
+	var all []*events.Event
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		evts, err := watcher.Poll(t.Context(), time.Now().Add(historySettle+time.Second))
+		if err != nil {
+			t.Fatalf("Poll: %v", err)
+		}
+		all = append(all, evts...)
+		if watcher.loadOffsets()[repo] == size {
+			return all
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not read the reflog")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHistoryWatcher(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	dataDir := t.TempDir()
+	repo := filepath.Join(root, "api")
+	initRepo(t, repo)
+	commitFile(t, repo, "README.md", "Initial commit")
+	gitDir, _ := GitDir(repo)
+	base := headBranch(gitDir)
+
+	hooked := filepath.Join(root, "web")
+	initRepo(t, hooked)
+	ctx := &install.Context{DataDir: dataDir, Log: func(string, ...interface{}) {}}
+	if err := InstallRepoHooks(ctx, hooked); err != nil {
+		t.Fatalf("InstallRepoHooks: %v", err)
+	}
+
+	watcher, err := NewHistoryWatcher(dataDir, []string{root}, DefaultHistoryInterval)
+	if err != nil {
+		t.Fatalf("NewHistoryWatcher: %v", err)
+	}
+	defer watcher.Close()
+	var recorded []*events.Event
+	watcher.recorded = func(ctx context.Context, start, end time.Time) ([]*events.Event, error) {
+		return recorded, nil
+	}
+
+	// Existing history is not imported.
+	evts, err := watcher.Poll(t.Context(), time.Now())
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if len(evts) != 0 {
+		t.Errorf("first Poll() returned %d events, want none", len(evts))
+	}
+	if repos := watcher.Repos(); !reflect.DeepEqual(repos, []string{repo}) {
+		t.Errorf("Repos() = %v, want only the repo without hooks", repos)
+	}
+
+	commitFile(t, repo, "main.go", "Add main\n\nWith a body.")
+	runGit(t, repo, "checkout", "-q", "-b", "feature")
+
+	// Entries are left alone until they are historySettle old.
+	if evts, _ := watcher.Poll(t.Context(), time.Now()); len(evts) != 0 {
+		t.Errorf("Poll() returned %d unsettled events", len(evts))
+	}
+
+	evts = pollSettled(t, watcher, repo)
+	if len(evts) != 2 {
+		t.Fatalf("Poll() returned %d events, want a commit and a checkout", len(evts))
+	}
+	commit, checkout := evts[0], evts[1]
+	if commit.Type != string(events.TypeCommit) || commit.Branch != base || commit.Repo != repo {
+		t.Errorf("commit = %s on %s in %s", commit.Type, commit.Branch, commit.Repo)
+	}
+	if commit.Payload["message"] != "Add main\n\nWith a body." || commit.Payload["author"] != "Ada" {
+		t.Errorf("commit payload = %v", commit.Payload)
+	}
+	if files, _ := commit.Payload["files"].([]string); !reflect.DeepEqual(files, []string{"main.go"}) {
+		t.Errorf("commit files = %v", commit.Payload["files"])
+	}
+	if checkout.Type != string(events.TypeCheckout) || checkout.Branch != "feature" || checkout.Payload["from_branch"] != base {
+		t.Errorf("checkout = %s on %s, payload %v", checkout.Type, checkout.Branch, checkout.Payload)
+	}
+
+	if evts, _ := watcher.Poll(t.Context(), time.Now().Add(historySettle+time.Second)); len(evts) != 0 {
+		t.Errorf("Poll() returned %d events twice", len(evts))
+	}
+
+	// A commit the wrapper already recorded is not recorded again.
+	commitFile(t, repo, "lib.go", "Add lib")
+	head, _ := exec.Command("git", "-C", repo, "rev-parse", "HEAD").Output()
+	wrapped := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	wrapped.Payload["hash"] = string(head[:len(head)-1])
+	recorded = []*events.Event{wrapped}
+
+	if evts := pollSettled(t, watcher, repo); len(evts) != 0 {
+		t.Errorf("Poll() returned %d events for a recorded commit", len(evts))
+	}
+}
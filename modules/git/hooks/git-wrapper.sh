@@ -36,6 +36,9 @@ fi
 
 SUBCOMMAND="$1"
 
+# Tells devlog's per-repo hooks that this command is recorded here.
+export DEVLOG_GIT_WRAPPER=1
+
 case "$SUBCOMMAND" in
     commit)
         __devlog_get_repo_info
@@ -46,10 +49,12 @@ case "$SUBCOMMAND" in
             read -r COMMIT_HASH COMMIT_AUTHOR < <("$GIT_BIN" log -1 --format='%H %an' 2>/dev/null)
             if [ -n "$COMMIT_HASH" ]; then
                 COMMIT_MESSAGE="$("$GIT_BIN" log -1 --pretty=%B 2>/dev/null)"
+                COMMIT_FILES="$("$GIT_BIN" diff-tree --root --no-commit-id --name-only -r HEAD 2>/dev/null | head -n 200)"
                 __devlog_capture_git_event "commit" "$REPO_PATH" "$BRANCH" \
                     --hash="$COMMIT_HASH" \
                     --message="$COMMIT_MESSAGE" \
-                    --author="$COMMIT_AUTHOR"
+                    --author="$COMMIT_AUTHOR" \
+                    --files="$COMMIT_FILES"
             fi
         fi
 
@@ -199,6 +204,7 @@ case "$SUBCOMMAND" in
         ;;
 
     *)
+        unset DEVLOG_GIT_WRAPPER
         exec "$GIT_BIN" "$@"
         ;;
 esac
@@ -0,0 +1,70 @@
+#!/bin/bash
+# devlog git hook: {{HOOK}} for {{REPO}}
+# Installed by 'devlog module git hooks install'. A hook that was already here
+# is kept as {{HOOK}}.devlog-orig and still runs first.
+
+HOOK_DIR="$(cd "$(dirname "$0")" && pwd)"
+STATUS=0
+if [ -x "$HOOK_DIR/{{HOOK}}.devlog-orig" ]; then
+    "$HOOK_DIR/{{HOOK}}.devlog-orig" "$@"
+    STATUS=$?
+fi
+
+# Commands run through the devlog git wrapper are recorded by the wrapper.
+[ -n "$DEVLOG_GIT_WRAPPER" ] && exit $STATUS
+[ "${DEVLOG_GIT_ENABLED:-true}" != "true" ] && exit $STATUS
+case "$GIT_REFLOG_ACTION" in
+    rebase*) exit $STATUS ;;
+esac
+
+COMMON_LIB="${HOME}/.local/bin/devlog-git-common.sh"
+[ -f "$COMMON_LIB" ] || exit $STATUS
+source "$COMMON_LIB"
+
+__devlog_get_repo_info
+[ -z "$REPO_PATH" ] && exit $STATUS
+
+case "{{HOOK}}" in
+    post-commit)
+        read -r COMMIT_HASH COMMIT_AUTHOR < <(git log -1 --format='%H %an' 2>/dev/null)
+        if [ -n "$COMMIT_HASH" ]; then
+            COMMIT_MESSAGE="$(git log -1 --pretty=%B 2>/dev/null)"
+            COMMIT_FILES="$(git diff-tree --root --no-commit-id --name-only -r HEAD 2>/dev/null | head -n 200)"
+            __devlog_capture_git_event "commit" "$REPO_PATH" "$BRANCH" \
+                --hash="$COMMIT_HASH" \
+                --message="$COMMIT_MESSAGE" \
+                --author="$COMMIT_AUTHOR" \
+                --files="$COMMIT_FILES"
+        fi
+        ;;
+
+    post-checkout)
+        # The third argument is 1 for a branch checkout and 0 for files.
+        if [ "$3" = "1" ]; then
+            FROM_BRANCH="$(git rev-parse --abbrev-ref @{-1} 2>/dev/null)"
+            if [ -n "$FROM_BRANCH" ] && [ "$FROM_BRANCH" != "$BRANCH" ]; then
+                __devlog_capture_git_event "checkout" "$REPO_PATH" "$BRANCH" \
+                    --from-branch="$FROM_BRANCH"
+            fi
+        fi
+        ;;
+
+    post-merge)
+        case "$GIT_REFLOG_ACTION" in
+            pull*)
+                CHANGES="fast-forward"
+                git rev-parse -q --verify HEAD^2 >/dev/null 2>&1 && CHANGES="merge"
+                __devlog_capture_git_event "pull" "$REPO_PATH" "$BRANCH" \
+                    --changes="$CHANGES"
+                ;;
+            *)
+                MERGE_BRANCH="${GIT_REFLOG_ACTION#merge }"
+                [ "$MERGE_BRANCH" = "$GIT_REFLOG_ACTION" ] && MERGE_BRANCH=""
+                __devlog_capture_git_event "merge" "$REPO_PATH" "$BRANCH" \
+                    --merged-branch="$MERGE_BRANCH"
+                ;;
+        esac
+        ;;
+esac
+
+exit $STATUS
@@ -6,8 +6,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"devlog/internal/config"
 	"devlog/internal/events"
 	"devlog/internal/ingest"
+	"devlog/internal/vcs"
 
 	"github.com/urfave/cli/v2"
 )
@@ -25,6 +27,7 @@ func (h *IngestHandler) CLICommand() *cli.Command {
 			&cli.StringFlag{Name: "hash", Usage: "Commit hash (for commit)"},
 			&cli.StringFlag{Name: "message", Usage: "Commit message (for commit)"},
 			&cli.StringFlag{Name: "author", Usage: "Commit author (for commit)"},
+			&cli.StringFlag{Name: "files", Usage: "Newline-separated paths changed by the commit (for commit)"},
 			&cli.StringFlag{Name: "remote", Usage: "Remote name (for push/pull/fetch)"},
 			&cli.StringFlag{Name: "remote-url", Usage: "Remote URL (for push/pull/fetch)"},
 			&cli.StringFlag{Name: "ref", Usage: "Reference (for push)"},
@@ -56,6 +59,9 @@ func (h *IngestHandler) handle(c *cli.Context) error {
 	if v := c.String("author"); v != "" {
 		args = append(args, "--author", v)
 	}
+	if v := c.String("files"); v != "" {
+		args = append(args, "--files", v)
+	}
 	if v := c.String("remote"); v != "" {
 		args = append(args, "--remote", v)
 	}
@@ -92,6 +98,28 @@ func (h *IngestHandler) handle(c *cli.Context) error {
 	return h.ingestEvent(args)
 }
 
+// payloadSchemas are checked by the daemon when git events are ingested.
+var payloadSchemas = []events.PayloadSchema{
+	{
+		Source: string(events.SourceGit),
+		Types:  []events.EventType{events.TypeCommit},
+		Fields: map[string]events.PayloadField{
+			"hash":    {Kind: events.KindString, Required: true},
+			"message": {Kind: events.KindString},
+			"author":  {Kind: events.KindString},
+			"files":   {Kind: events.KindStringList},
+		},
+	},
+	{
+		Source: string(events.SourceGit),
+		Fields: map[string]events.PayloadField{
+			"remote":     {Kind: events.KindString},
+			"remote_url": {Kind: events.KindString},
+			"ref":        {Kind: events.KindString},
+		},
+	},
+}
+
 func (h *IngestHandler) ingestEvent(args []string) error {
 	fs := flag.NewFlagSet("git-event", flag.ExitOnError)
 	repo := fs.String("repo", "", "Repository path")
@@ -101,6 +129,7 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	hash := fs.String("hash", "", "Commit hash (for commit)")
 	message := fs.String("message", "", "Commit message (for commit)")
 	author := fs.String("author", "", "Commit author (for commit)")
+	files := fs.String("files", "", "Newline-separated paths changed by the commit (for commit)")
 
 	remote := fs.String("remote", "", "Remote name (for push/pull/fetch)")
 	remoteURL := fs.String("remote-url", "", "Remote URL (for push/pull/fetch)")
@@ -148,6 +177,9 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	event := events.NewEvent(string(events.SourceGit), typeConstant)
 	event.Repo = filepath.Base(*repo)
 	event.Branch = *branch
+	if detected, err := vcs.Detect(*repo); err == nil && detected.Remote != "" {
+		event.Payload[config.RemotePayloadKey] = detected.Remote
+	}
 
 	if *hash != "" {
 		event.Payload["hash"] = *hash
@@ -158,6 +190,9 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	if *author != "" {
 		event.Payload["author"] = *author
 	}
+	if paths := splitLines(*files); len(paths) > 0 {
+		event.Payload["files"] = paths
+	}
 	if *remote != "" {
 		event.Payload["remote"] = *remote
 	}
@@ -196,6 +231,16 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	return ingest.SendEvent(event)
 }
 
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
 func detectHostingProvider(remoteURL string) string {
 	url := strings.ToLower(remoteURL)
 
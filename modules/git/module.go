@@ -1,21 +1,24 @@
 package git
 
 import (
-	_ "embed"
+	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"devlog/internal/assets"
 	"devlog/internal/config"
+	"devlog/internal/events"
 	"devlog/internal/install"
 	"devlog/internal/modules"
+	"devlog/internal/poller"
+	"devlog/internal/state"
 )
 
-//go:embed hooks/git-wrapper.sh
-var gitWrapperScript string
-
-//go:embed hooks/devlog-git-common.sh
-var gitCommonLib string
+//go:embed hooks
+var hooksFS embed.FS
 
 type Module struct{}
 
@@ -45,7 +48,7 @@ func (m *Module) Install(ctx *install.Context) error {
 	}
 
 	commonLibPath := filepath.Join(binDir, "devlog-git-common.sh")
-	if err := os.WriteFile(commonLibPath, []byte(gitCommonLib), 0644); err != nil {
+	if err := ctx.WriteAsset("git", "devlog-git-common.sh", commonLibPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "git wrapper",
 			File:      commonLibPath,
@@ -59,7 +62,7 @@ func (m *Module) Install(ctx *install.Context) error {
 	}
 
 	wrapperPath := filepath.Join(binDir, "git")
-	if err := os.WriteFile(wrapperPath, []byte(gitWrapperScript), 0755); err != nil {
+	if err := ctx.WriteAsset("git", "git-wrapper.sh", wrapperPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "git wrapper",
 			File:      wrapperPath,
@@ -75,6 +78,8 @@ func (m *Module) Install(ctx *install.Context) error {
 	ctx.Log("✓ Installed shared library to %s", commonLibPath)
 	ctx.Log("✓ Installed git wrapper to %s", wrapperPath)
 
+	m.reinstallRepoHooks(ctx)
+
 	cfg, err := config.Load()
 	if err == nil && cfg.IsModuleEnabled("shell") {
 		cfg.AddToShellIgnoreList("git")
@@ -103,7 +108,7 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 
 	commonLibPath := filepath.Join(binDir, "devlog-git-common.sh")
 	if _, err := os.Stat(commonLibPath); err == nil {
-		if err := os.Remove(commonLibPath); err != nil {
+		if err := ctx.RemoveAsset(commonLibPath); err != nil {
 			return fmt.Errorf("remove common library: %w", err)
 		}
 		ctx.Log("✓ Removed shared library from %s", commonLibPath)
@@ -111,9 +116,8 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 
 	wrapperPath := filepath.Join(binDir, "git")
 	if _, err := os.Stat(wrapperPath); err == nil {
-		content, err := os.ReadFile(wrapperPath)
-		if err == nil && string(content) == gitWrapperScript {
-			if err := os.Remove(wrapperPath); err != nil {
+		if ctx.OwnsAsset("git", "git-wrapper.sh", wrapperPath) {
+			if err := ctx.RemoveAsset(wrapperPath); err != nil {
 				return fmt.Errorf("remove git wrapper: %w", err)
 			}
 			ctx.Log("✓ Removed git wrapper from %s", wrapperPath)
@@ -122,6 +126,22 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 		}
 	}
 
+	repos, err := HookedRepos(ctx.DataDir)
+	if err != nil {
+		ctx.Log("Warning: failed to list repos with hooks: %v", err)
+	}
+	for _, repo := range repos {
+		if err := UninstallRepoHooks(ctx, repo); err != nil {
+			ctx.Log("Warning: %v", err)
+			continue
+		}
+		ctx.Log("✓ Removed hooks from %s", repo)
+	}
+
+	if stateMgr, err := state.NewManager(ctx.DataDir); err == nil {
+		stateMgr.DeleteModule(stateModule)
+	}
+
 	cfg, err := config.Load()
 	if err == nil && cfg.IsModuleEnabled("shell") {
 		cfg.RemoveFromShellIgnoreList("git")
@@ -133,14 +153,197 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 	return nil
 }
 
+// reinstallRepoHooks rewrites the hooks of repositories that already had
+// them, so reinstalling the module repairs them. Repositories that have
+// since been deleted are forgotten.
+func (m *Module) reinstallRepoHooks(ctx *install.Context) {
+	if _, err := PruneRepoHooks(ctx.DataDir); err != nil {
+		ctx.Log("Warning: failed to prune repo hooks: %v", err)
+	}
+	repos, err := HookedRepos(ctx.DataDir)
+	if err != nil {
+		ctx.Log("Warning: failed to list repos with hooks: %v", err)
+		return
+	}
+	for _, repo := range repos {
+		if err := InstallRepoHooks(ctx, repo); err != nil {
+			ctx.Log("Warning: %v", err)
+		}
+	}
+	if len(repos) > 0 {
+		ctx.Log("✓ Reinstalled hooks in %d repos", len(repos))
+	}
+}
+
 func (m *Module) DefaultConfig() interface{} {
-	return map[string]interface{}{}
+	return map[string]interface{}{
+		"hook_roots":                 []interface{}{},
+		"auto_install_hooks":         false,
+		"hook_scan_interval_seconds": int(DefaultScanInterval.Seconds()),
+		"history_roots":              []interface{}{},
+		"history_interval_seconds":   int(DefaultHistoryInterval.Seconds()),
+	}
+}
+
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	health := modules.Health{
+		Sources:    []string{string(events.SourceGit)},
+		QuietAfter: 7 * 24 * time.Hour,
+		Checks:     []modules.Check{modules.WrapperCheck(ctx, "git")},
+	}
+
+	stateMgr, err := state.NewManager(ctx.DataDir)
+	if err != nil {
+		return health
+	}
+	if pending := PendingRepos(stateMgr); len(pending) > 0 {
+		health.Checks = append(health.Checks, modules.Check{
+			Name:    "repo hooks",
+			Message: fmt.Sprintf("%d new repos have no hooks; run 'devlog module git hooks install'", len(pending)),
+		})
+	}
+	return health
 }
 
 func (m *Module) ValidateConfig(config interface{}) error {
+	cfg, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config must be a map")
+	}
+
+	for _, key := range []string{"hook_roots", "history_roots"} {
+		val, ok := cfg[key]
+		if !ok || val == nil {
+			continue
+		}
+		list, ok := val.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s must be a list", key)
+		}
+		for _, item := range list {
+			if root, ok := item.(string); !ok || root == "" {
+				return fmt.Errorf("%s: %v is not a directory", key, item)
+			}
+		}
+	}
+
+	if val, ok := cfg["auto_install_hooks"]; ok {
+		if _, ok := val.(bool); !ok {
+			return fmt.Errorf("auto_install_hooks must be true or false")
+		}
+	}
+
+	if val, ok := cfg["hook_scan_interval_seconds"]; ok {
+		interval, ok := numberValue(val)
+		if !ok {
+			return fmt.Errorf("hook_scan_interval_seconds must be a number")
+		}
+		if interval < 60 || interval > 86400 {
+			return fmt.Errorf("hook_scan_interval_seconds must be between 60 and 86400")
+		}
+	}
+
+	if val, ok := cfg["history_interval_seconds"]; ok {
+		interval, ok := numberValue(val)
+		if !ok {
+			return fmt.Errorf("history_interval_seconds must be a number")
+		}
+		if interval < 5 || interval > 3600 {
+			return fmt.Errorf("history_interval_seconds must be between 5 and 3600")
+		}
+	}
+
 	return nil
 }
 
+// CreatePoller scans hook_roots for new clones and reads the reflogs of
+// repos under history_roots; it is off until a root of either kind is
+// configured.
+func (m *Module) CreatePoller(config map[string]interface{}, dataDir string) (poller.Poller, error) {
+	homeDir, _ := os.UserHomeDir()
+	hookRoots := HookRoots(config, homeDir)
+	historyRoots := HistoryRoots(config, homeDir)
+	if len(hookRoots) == 0 && len(historyRoots) == 0 {
+		return nil, modules.ErrPollerDisabled
+	}
+
+	var (
+		scanner *RepoScanner
+		history *HistoryWatcher
+		err     error
+	)
+	if len(hookRoots) > 0 {
+		interval := DefaultScanInterval
+		if n, ok := numberValue(config["hook_scan_interval_seconds"]); ok && n > 0 {
+			interval = time.Duration(n) * time.Second
+		}
+		auto, _ := config["auto_install_hooks"].(bool)
+		if scanner, err = NewRepoScanner(dataDir, hookRoots, auto, interval); err != nil {
+			return nil, err
+		}
+	}
+	if len(historyRoots) > 0 {
+		interval := DefaultHistoryInterval
+		if n, ok := numberValue(config["history_interval_seconds"]); ok && n > 0 {
+			interval = time.Duration(n) * time.Second
+		}
+		if history, err = NewHistoryWatcher(dataDir, historyRoots, interval); err != nil {
+			return nil, err
+		}
+	}
+	return NewPoller(scanner, history), nil
+}
+
+// HookRoots returns the configured hook_roots as absolute paths.
+func HookRoots(config map[string]interface{}, homeDir string) []string {
+	return expandRoots(config["hook_roots"], homeDir)
+}
+
+// HistoryRoots returns the configured history_roots as absolute paths.
+func HistoryRoots(config map[string]interface{}, homeDir string) []string {
+	return expandRoots(config["history_roots"], homeDir)
+}
+
+func expandRoots(value interface{}, homeDir string) []string {
+	list, _ := value.([]interface{})
+	var roots []string
+	for _, item := range list {
+		root, ok := item.(string)
+		if !ok || root == "" {
+			continue
+		}
+		roots = append(roots, ExpandRoot(root, homeDir))
+	}
+	return roots
+}
+
+// ExpandRoot resolves a leading ~ against homeDir and makes root absolute.
+func ExpandRoot(root, homeDir string) string {
+	if root == "~" {
+		root = homeDir
+	} else if strings.HasPrefix(root, "~/") {
+		root = filepath.Join(homeDir, root[2:])
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return root
+}
+
+func numberValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
 func init() {
+	assets.RegisterFS("git", hooksFS, "hooks", "git-wrapper.sh", repoHookAsset)
+	for _, schema := range payloadSchemas {
+		events.RegisterPayloadSchema(schema)
+	}
 	modules.Register(&Module{})
 }
@@ -0,0 +1,178 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"devlog/internal/assets"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/state"
+)
+
+const (
+	DefaultScanInterval = 10 * time.Minute
+
+	stateModule = "git"
+	// pendingKey holds the repositories the last scan found without hooks.
+	pendingKey = "pending_repos"
+)
+
+// Poller is the git module's poller. It scans the hook roots for new clones
+// and reads the reflogs of repositories under the history roots; either can
+// be nil.
+type Poller struct {
+	scanner  *RepoScanner
+	history  *HistoryWatcher
+	nextScan time.Time
+	now      func() time.Time
+}
+
+func NewPoller(scanner *RepoScanner, history *HistoryWatcher) *Poller {
+	return &Poller{scanner: scanner, history: history, now: time.Now}
+}
+
+func (p *Poller) Name() string {
+	return "git"
+}
+
+func (p *Poller) PollInterval() time.Duration {
+	if p.history != nil {
+		return p.history.interval
+	}
+	return p.scanner.interval
+}
+
+func (p *Poller) Poll(ctx context.Context) ([]*events.Event, error) {
+	now := p.now()
+
+	// Hooks go in before reflogs are read, so a repository that just got
+	// them is left to its hooks.
+	var scanErr error
+	if p.scanner != nil && !now.Before(p.nextScan) {
+		scanErr = p.scanner.Scan(ctx)
+		p.nextScan = now.Add(p.scanner.interval)
+	}
+	if p.history == nil {
+		return nil, scanErr
+	}
+
+	evts, err := p.history.Poll(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(evts) > 0 {
+		// The manager drops the events of a poll that fails; the scan
+		// error comes back on its next run.
+		return evts, nil
+	}
+	return nil, scanErr
+}
+
+// Close stops the history watcher's file notifications.
+func (p *Poller) Close() error {
+	if p.history == nil {
+		return nil
+	}
+	return p.history.Close()
+}
+
+// RepoScanner watches the hook roots for new clones. With
+// auto_install_hooks it installs the repo hooks into them; otherwise it
+// records them so 'devlog module status' and 'devlog module git hooks' can
+// offer to.
+type RepoScanner struct {
+	roots    []string
+	auto     bool
+	interval time.Duration
+	ctx      *install.Context
+	stateMgr *state.Manager
+}
+
+func NewRepoScanner(dataDir string, roots []string, auto bool, interval time.Duration) (*RepoScanner, error) {
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("create state manager: %w", err)
+	}
+
+	homeDir, _ := os.UserHomeDir()
+	ctx := &install.Context{
+		DataDir: dataDir,
+		HomeDir: homeDir,
+		Log:     func(format string, args ...interface{}) {},
+	}
+	if m, err := assets.LoadManifest(dataDir); err == nil {
+		ctx.Version = m.Version
+	}
+
+	return &RepoScanner{
+		roots:    roots,
+		auto:     auto,
+		interval: interval,
+		ctx:      ctx,
+		stateMgr: stateMgr,
+	}, nil
+}
+
+// Scan looks for repositories without hooks. It records no events; hooks
+// it installs record them from then on.
+func (s *RepoScanner) Scan(ctx context.Context) error {
+	if _, err := PruneRepoHooks(s.ctx.DataDir); err != nil {
+		return fmt.Errorf("prune repo hooks: %w", err)
+	}
+
+	repos, err := UnhookedRepos(s.roots, s.ctx.DataDir)
+	if err != nil {
+		return fmt.Errorf("scan for repos: %w", err)
+	}
+
+	var (
+		unhooked   []string
+		installErr error
+	)
+	for _, repo := range repos {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if s.auto {
+			err := InstallRepoHooks(s.ctx, repo)
+			if err == nil {
+				continue
+			}
+			if installErr == nil {
+				installErr = err
+			}
+		}
+		unhooked = append(unhooked, repo)
+	}
+
+	if err := SetPendingRepos(s.stateMgr, unhooked); err != nil {
+		return err
+	}
+	return installErr
+}
+
+// PendingRepos returns the repositories the last scan found without hooks.
+func PendingRepos(stateMgr *state.Manager) []string {
+	value, ok := stateMgr.Get(stateModule, pendingKey)
+	if !ok {
+		return nil
+	}
+	list, _ := value.([]interface{})
+	repos := make([]string, 0, len(list))
+	for _, item := range list {
+		if repo, ok := item.(string); ok {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+func SetPendingRepos(stateMgr *state.Manager, repos []string) error {
+	if len(repos) == 0 {
+		return stateMgr.Delete(stateModule, pendingKey)
+	}
+	return stateMgr.Set(stateModule, pendingKey, repos)
+}
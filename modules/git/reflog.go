@@ -0,0 +1,243 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+
+	"github.com/google/uuid"
+)
+
+// ReflogEntry is one line of a reflog: HEAD moving from Old to New.
+type ReflogEntry struct {
+	Old     string
+	New     string
+	Name    string
+	Email   string
+	Time    time.Time
+	Message string
+}
+
+// ParseReflogLine parses "<old> <new> <name> <<email>> <unix> <tz>\t<message>".
+func ParseReflogLine(line string) (ReflogEntry, bool) {
+	header, message, ok := strings.Cut(line, "\t")
+	if !ok {
+		return ReflogEntry{}, false
+	}
+
+	fields := strings.SplitN(header, " ", 3)
+	if len(fields) != 3 {
+		return ReflogEntry{}, false
+	}
+	entry := ReflogEntry{Old: fields[0], New: fields[1], Message: message}
+
+	rest := fields[2]
+	open, closing := strings.Index(rest, "<"), strings.LastIndex(rest, ">")
+	if open < 0 || closing < open {
+		return ReflogEntry{}, false
+	}
+	entry.Name = strings.TrimSpace(rest[:open])
+	entry.Email = rest[open+1 : closing]
+
+	when := strings.Fields(rest[closing+1:])
+	if len(when) < 1 {
+		return ReflogEntry{}, false
+	}
+	secs, err := strconv.ParseInt(when[0], 10, 64)
+	if err != nil {
+		return ReflogEntry{}, false
+	}
+	entry.Time = time.Unix(secs, 0).UTC()
+	return entry, true
+}
+
+// GitDir returns the git directory of repo, following the .git file that
+// worktrees and submodules use.
+func GitDir(repo string) (string, bool) {
+	dotGit := filepath.Join(repo, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", false
+	}
+	if info.IsDir() {
+		return dotGit, true
+	}
+
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", false
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return "", false
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repo, dir)
+	}
+	return dir, true
+}
+
+// headBranch returns the branch checked out in gitDir, in the form the
+// wrapper reports it.
+func headBranch(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(content))
+	if branch, ok := strings.CutPrefix(head, "ref: refs/heads/"); ok {
+		return branch
+	}
+	if len(head) >= 7 {
+		return "detached-" + head[:7]
+	}
+	return ""
+}
+
+// HistoryEvents turns reflog entries of repo into the git events the
+// wrapper would have sent, with repo as their (absolute) repo. branch is the branch checked out after the last
+// entry; the branch of earlier entries is worked out from the checkouts
+// between them. Entries that are not a commit, checkout, merge, pull or
+// finished rebase are skipped, as are the steps of a rebase.
+func HistoryEvents(repo string, entries []ReflogEntry, branch string) []*events.Event {
+	// Walk back over the checkouts to find the branch before the first entry.
+	for i := len(entries) - 1; i >= 0; i-- {
+		if from, _, ok := parseCheckout(entries[i].Message); ok {
+			branch = from
+		}
+	}
+
+	var (
+		evts         []*events.Event
+		rebaseTarget string
+	)
+	for _, entry := range entries {
+		action, detail, _ := strings.Cut(entry.Message, ": ")
+		verb, _, _ := strings.Cut(action, " ")
+
+		var event *events.Event
+		switch {
+		case verb == "commit" || verb == "cherry-pick":
+			event = newHistoryEvent(repo, branch, events.TypeCommit, entry)
+			event.Payload["hash"] = entry.New
+			event.Payload["message"] = detail
+			if entry.Name != "" {
+				event.Payload["author"] = entry.Name
+			}
+
+		case verb == "checkout":
+			from, to, ok := parseCheckout(entry.Message)
+			if !ok {
+				continue
+			}
+			branch = to
+			if from == to {
+				continue
+			}
+			event = newHistoryEvent(repo, branch, events.TypeCheckout, entry)
+			event.Payload["from_branch"] = from
+
+		case verb == "merge":
+			event = newHistoryEvent(repo, branch, events.TypeMerge, entry)
+			if merged := strings.TrimSpace(strings.TrimPrefix(action, "merge")); merged != "" {
+				event.Payload["merged_branch"] = merged
+			}
+
+		case verb == "pull" || verb == "rebase":
+			step := rebaseStep(action)
+			if step == "start" {
+				rebaseTarget, _ = strings.CutPrefix(detail, "checkout ")
+			}
+			if step != "" && step != "finish" {
+				continue
+			}
+			if step == "finish" {
+				if ref, ok := strings.CutPrefix(detail, "returning to refs/heads/"); ok {
+					branch = ref
+				}
+			}
+
+			if verb == "rebase" {
+				event = newHistoryEvent(repo, branch, events.TypeRebase, entry)
+				if rebaseTarget != "" {
+					event.Payload["target_branch"] = rebaseTarget
+				}
+				break
+			}
+			event = newHistoryEvent(repo, branch, events.TypePull, entry)
+			switch {
+			case step == "finish":
+				event.Payload["changes"] = "rebase"
+			case strings.Contains(detail, "Fast-forward"):
+				event.Payload["changes"] = "fast-forward"
+			default:
+				event.Payload["changes"] = "merge"
+			}
+			if fields := strings.Fields(action); len(fields) > 1 && !strings.HasPrefix(fields[1], "-") {
+				event.Payload["remote"] = fields[1]
+			}
+
+		default:
+			continue
+		}
+		evts = append(evts, event)
+	}
+	return evts
+}
+
+func newHistoryEvent(repo, branch string, eventType events.EventType, entry ReflogEntry) *events.Event {
+	event := events.NewEvent(string(events.SourceGit), string(eventType))
+	// The same reflog line always gives the same ID, so rereading a reflog
+	// cannot store an event twice.
+	event.ID = uuid.NewSHA1(uuid.NameSpaceURL,
+		[]byte(fmt.Sprintf("git:reflog:%s:%s:%d:%s", repo, entry.New, entry.Time.Unix(), entry.Message))).String()
+	event.Timestamp = entry.Time.Format(time.RFC3339)
+	event.Repo = repo
+	event.Branch = branch
+	if event.Branch == "" {
+		event.Branch = "detached-" + shortHash(entry.New)
+	}
+	event.Payload["reflog"] = true
+	return event
+}
+
+// parseCheckout reads "checkout: moving from A to B".
+func parseCheckout(message string) (from, to string, ok bool) {
+	rest, ok := strings.CutPrefix(message, "checkout: moving from ")
+	if !ok {
+		return "", "", false
+	}
+	from, to, ok = strings.Cut(rest, " to ")
+	return refName(from), refName(to), ok
+}
+
+// refName reports a detached HEAD the way the wrapper does.
+func refName(ref string) string {
+	if (len(ref) == 40 || len(ref) == 64) && strings.Trim(ref, "0123456789abcdef") == "" {
+		return "detached-" + shortHash(ref)
+	}
+	return ref
+}
+
+// rebaseStep returns the step in "rebase -i (pick)" or
+// "pull --rebase (finish)", or "" for an action without one.
+func rebaseStep(action string) string {
+	open := strings.LastIndex(action, "(")
+	if open < 0 || !strings.HasSuffix(action, ")") {
+		return ""
+	}
+	return action[open+1 : len(action)-1]
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
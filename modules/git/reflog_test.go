@@ -0,0 +1,114 @@
+package git
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const (
+	hashA = "1111111111111111111111111111111111111111"
+	hashB = "2222222222222222222222222222222222222222"
+	hashC = "3333333333333333333333333333333333333333"
+)
+
+func reflogLine(old, new string, at int64, message string) string {
+	return fmt.Sprintf("%s %s Ada Lovelace <ada@example.com> %d +0100\t%s", old, new, at, message)
+}
+
+func TestParseReflogLine(t *testing.T) {
+	entry, ok := ParseReflogLine(reflogLine(hashA, hashB, 1700000000, "commit: Fix login"))
+	if !ok {
+		t.Fatal("ParseReflogLine() failed")
+	}
+	if entry.Old != hashA || entry.New != hashB {
+		t.Errorf("hashes = %s %s", entry.Old, entry.New)
+	}
+	if entry.Name != "Ada Lovelace" || entry.Email != "ada@example.com" {
+		t.Errorf("author = %q <%q>", entry.Name, entry.Email)
+	}
+	if !entry.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("time = %v", entry.Time)
+	}
+	if entry.Message != "commit: Fix login" {
+		t.Errorf("message = %q", entry.Message)
+	}
+
+	for _, line := range []string{
+		"",
+		hashA + " " + hashB + " no tab",
+		hashA + " " + hashB + " Ada <ada@example.com> soon +0100\tcommit: x",
+	} {
+		if _, ok := ParseReflogLine(line); ok {
+			t.Errorf("ParseReflogLine(%q) succeeded", line)
+		}
+	}
+}
+
+func TestHistoryEvents(t *testing.T) {
+	lines := []string{
+		reflogLine(hashA, hashB, 100, "checkout: moving from main to feature"),
+		reflogLine(hashB, hashC, 110, "commit: Add feature"),
+		reflogLine(hashC, hashC, 120, "checkout: moving from feature to feature"),
+		reflogLine(hashC, hashA, 130, "rebase (start): checkout main"),
+		reflogLine(hashA, hashB, 131, "rebase (pick): Add feature"),
+		reflogLine(hashB, hashB, 132, "rebase (finish): returning to refs/heads/feature"),
+		reflogLine(hashB, hashA, 140, "checkout: moving from feature to main"),
+		reflogLine(hashA, hashC, 150, "pull origin main: Fast-forward"),
+		reflogLine(hashC, hashB, 160, "merge feature: Merge made by the 'ort' strategy."),
+		reflogLine(hashB, hashA, 170, "checkout: moving from main to "+hashA),
+		reflogLine(hashA, hashA, 180, "reset: moving to HEAD"),
+	}
+	var entries []ReflogEntry
+	for _, line := range lines {
+		entry, ok := ParseReflogLine(line)
+		if !ok {
+			t.Fatalf("ParseReflogLine(%q) failed", line)
+		}
+		entries = append(entries, entry)
+	}
+
+	evts := HistoryEvents("/src/api", entries, "detached-1111111")
+
+	want := []struct {
+		typ, branch, key, value string
+	}{
+		{"checkout", "feature", "from_branch", "main"},
+		{"commit", "feature", "hash", hashC},
+		{"rebase", "feature", "target_branch", "main"},
+		{"checkout", "main", "from_branch", "feature"},
+		{"pull", "main", "changes", "fast-forward"},
+		{"merge", "main", "merged_branch", "feature"},
+		{"checkout", "detached-1111111", "from_branch", "main"},
+	}
+	if len(evts) != len(want) {
+		for _, e := range evts {
+			t.Logf("%s on %s: %v", e.Type, e.Branch, e.Payload)
+		}
+		t.Fatalf("HistoryEvents() returned %d events, want %d", len(evts), len(want))
+	}
+	for i, w := range want {
+		e := evts[i]
+		if e.Type != w.typ || e.Branch != w.branch || e.Payload[w.key] != w.value {
+			t.Errorf("event %d = %s on %s with %s=%v, want %s on %s with %s=%s",
+				i, e.Type, e.Branch, w.key, e.Payload[w.key], w.typ, w.branch, w.key, w.value)
+		}
+		if e.Repo != "/src/api" || e.Payload["reflog"] != true {
+			t.Errorf("event %d: repo %q, payload %v", i, e.Repo, e.Payload)
+		}
+	}
+	if evts[1].Payload["message"] != "Add feature" || evts[1].Payload["author"] != "Ada Lovelace" {
+		t.Errorf("commit payload = %v", evts[1].Payload)
+	}
+	if evts[4].Payload["remote"] != "origin" {
+		t.Errorf("pull payload = %v", evts[4].Payload)
+	}
+
+	again := HistoryEvents("/src/api", entries, "detached-1111111")
+	if again[0].ID != evts[0].ID {
+		t.Error("event IDs are not stable across reads")
+	}
+	if evts[0].ID == evts[3].ID {
+		t.Error("different entries share an ID")
+	}
+}
@@ -0,0 +1,217 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"devlog/internal/assets"
+	"devlog/internal/install"
+)
+
+const (
+	repoHookAsset = "repo-hook.sh"
+	repoHookMark  = "# devlog git hook:"
+	origSuffix    = ".devlog-orig"
+)
+
+// RepoHooks are the hooks installed into each repository. They record
+// commits, branch switches and merges made by tools that bypass the git
+// wrapper, such as editors and GUI clients.
+var RepoHooks = []string{"post-commit", "post-checkout", "post-merge"}
+
+// skipDirs are never searched for repositories.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// FindRepos returns the git repositories under root, which may itself be
+// one. Repositories are not searched for nested ones, and hidden and
+// dependency directories are skipped.
+func FindRepos(root string) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return repos, err
+}
+
+// HooksDir is where git looks for repo's hooks, honoring core.hooksPath and
+// worktrees.
+func HooksDir(repo string) (string, error) {
+	out, err := exec.Command("git", "-C", repo, "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("find hooks directory of %s: %w", repo, err)
+	}
+	dir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repo, dir)
+	}
+	return dir, nil
+}
+
+// InstallRepoHooks writes the devlog hooks into repo. An existing hook that
+// is not devlog's is renamed to <hook>.devlog-orig and chained.
+func InstallRepoHooks(ctx *install.Context, repo string) error {
+	dir, err := HooksDir(repo)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create hooks directory: %w", err)
+	}
+
+	for _, hook := range RepoHooks {
+		path := filepath.Join(dir, hook)
+		if _, err := os.Stat(path); err == nil && !isRepoHook(path) {
+			orig := path + origSuffix
+			if _, err := os.Stat(orig); err == nil {
+				return fmt.Errorf("%s and %s both exist; merge them by hand", path, orig)
+			}
+			if err := os.Rename(path, orig); err != nil {
+				return fmt.Errorf("keep existing %s hook: %w", hook, err)
+			}
+		}
+
+		vars := map[string]string{"HOOK": hook, "REPO": repo}
+		if err := ctx.WriteAsset("git", repoHookAsset, path, vars); err != nil {
+			return fmt.Errorf("write %s hook: %w", hook, err)
+		}
+	}
+	return nil
+}
+
+// UninstallRepoHooks removes the devlog hooks from repo and restores any
+// hooks they chained.
+func UninstallRepoHooks(ctx *install.Context, repo string) error {
+	dir, err := HooksDir(repo)
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range RepoHooks {
+		path := filepath.Join(dir, hook)
+		if !isRepoHook(path) {
+			continue
+		}
+		if err := ctx.RemoveAsset(path); err != nil {
+			return fmt.Errorf("remove %s hook: %w", hook, err)
+		}
+		orig := path + origSuffix
+		if _, err := os.Stat(orig); err == nil {
+			if err := os.Rename(orig, path); err != nil {
+				return fmt.Errorf("restore %s hook: %w", hook, err)
+			}
+		}
+	}
+	return nil
+}
+
+func isRepoHook(path string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && bytes.Contains(content, []byte(repoHookMark))
+}
+
+// HookedRepos lists the repositories devlog has installed hooks into, from
+// the asset manifest.
+func HookedRepos(dataDir string) ([]string, error) {
+	m, err := assets.LoadManifest(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var repos []string
+	for _, entry := range m.Files {
+		if entry.Module != "git" || entry.Asset != repoHookAsset {
+			continue
+		}
+		repo := entry.Vars["REPO"]
+		if repo != "" && !seen[repo] {
+			seen[repo] = true
+			repos = append(repos, repo)
+		}
+	}
+	sort.Strings(repos)
+	return repos, nil
+}
+
+// PruneRepoHooks forgets the hooks of repositories that have been deleted,
+// so they are not reported as missing, and returns those repositories.
+func PruneRepoHooks(dataDir string) ([]string, error) {
+	m, err := assets.LoadManifest(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var gone []string
+	for path, entry := range m.Files {
+		if entry.Module != "git" || entry.Asset != repoHookAsset {
+			continue
+		}
+		repo := entry.Vars["REPO"]
+		if _, err := os.Stat(filepath.Join(repo, ".git")); err == nil {
+			continue
+		}
+		if err := assets.Forget(dataDir, path); err != nil {
+			return gone, err
+		}
+		if !seen[repo] {
+			seen[repo] = true
+			gone = append(gone, repo)
+		}
+	}
+	sort.Strings(gone)
+	return gone, nil
+}
+
+// UnhookedRepos returns the repositories under roots that have no devlog
+// hooks yet, such as fresh clones.
+func UnhookedRepos(roots []string, dataDir string) ([]string, error) {
+	hooked, err := HookedRepos(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(hooked))
+	for _, repo := range hooked {
+		known[repo] = true
+	}
+
+	var repos []string
+	for _, root := range roots {
+		found, err := FindRepos(root)
+		if err != nil {
+			return repos, err
+		}
+		for _, repo := range found {
+			if !known[repo] {
+				known[repo] = true
+				repos = append(repos, repo)
+			}
+		}
+	}
+	return repos, nil
+}
@@ -0,0 +1,142 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"devlog/internal/install"
+	"devlog/internal/state"
+)
+
+func initRepo(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+}
+
+func TestFindRepos(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	initRepo(t, filepath.Join(root, "api"))
+	initRepo(t, filepath.Join(root, "api", "nested"))
+	initRepo(t, filepath.Join(root, "work", "web"))
+	initRepo(t, filepath.Join(root, "web", "node_modules", "dep"))
+	initRepo(t, filepath.Join(root, ".cache", "repo"))
+
+	got, err := FindRepos(root)
+	if err != nil {
+		t.Fatalf("FindRepos: %v", err)
+	}
+	want := []string{filepath.Join(root, "api"), filepath.Join(root, "work", "web")}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindRepos() = %v, want %v", got, want)
+	}
+}
+
+func TestRepoHooks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	repo := filepath.Join(root, "api")
+	initRepo(t, repo)
+	hooksDir := filepath.Join(repo, ".git", "hooks")
+	existing := []byte("#!/bin/sh\necho lint\n")
+	if err := os.WriteFile(filepath.Join(hooksDir, "post-commit"), existing, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := &install.Context{DataDir: t.TempDir(), Version: "1.0.0", Log: func(string, ...interface{}) {}}
+	if err := InstallRepoHooks(ctx, repo); err != nil {
+		t.Fatalf("InstallRepoHooks: %v", err)
+	}
+	for _, hook := range RepoHooks {
+		if !isRepoHook(filepath.Join(hooksDir, hook)) {
+			t.Errorf("%s is not the devlog hook", hook)
+		}
+	}
+	if orig, _ := os.ReadFile(filepath.Join(hooksDir, "post-commit"+origSuffix)); string(orig) != string(existing) {
+		t.Errorf("existing hook not kept, got %q", orig)
+	}
+
+	// Installing again must not chain the devlog hook to itself.
+	if err := InstallRepoHooks(ctx, repo); err != nil {
+		t.Fatalf("second InstallRepoHooks: %v", err)
+	}
+	if orig, _ := os.ReadFile(filepath.Join(hooksDir, "post-commit"+origSuffix)); string(orig) != string(existing) {
+		t.Errorf("reinstall replaced the chained hook, got %q", orig)
+	}
+
+	if hooked, _ := HookedRepos(ctx.DataDir); !reflect.DeepEqual(hooked, []string{repo}) {
+		t.Errorf("HookedRepos() = %v, want %v", hooked, []string{repo})
+	}
+
+	other := filepath.Join(root, "web")
+	initRepo(t, other)
+	if pending, _ := UnhookedRepos([]string{root}, ctx.DataDir); !reflect.DeepEqual(pending, []string{other}) {
+		t.Errorf("UnhookedRepos() = %v, want %v", pending, []string{other})
+	}
+
+	if err := UninstallRepoHooks(ctx, repo); err != nil {
+		t.Fatalf("UninstallRepoHooks: %v", err)
+	}
+	if content, _ := os.ReadFile(filepath.Join(hooksDir, "post-commit")); string(content) != string(existing) {
+		t.Errorf("existing hook not restored, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(hooksDir, "post-merge")); !os.IsNotExist(err) {
+		t.Error("post-merge hook not removed")
+	}
+	if hooked, _ := HookedRepos(ctx.DataDir); len(hooked) != 0 {
+		t.Errorf("HookedRepos() after uninstall = %v", hooked)
+	}
+}
+
+func TestRepoScanner(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	root := t.TempDir()
+	dataDir := t.TempDir()
+	hooked := filepath.Join(root, "api")
+	initRepo(t, hooked)
+	ctx := &install.Context{DataDir: dataDir, Log: func(string, ...interface{}) {}}
+	if err := InstallRepoHooks(ctx, hooked); err != nil {
+		t.Fatalf("InstallRepoHooks: %v", err)
+	}
+	clone := filepath.Join(root, "web")
+	initRepo(t, clone)
+
+	scanner, err := NewRepoScanner(dataDir, []string{root}, false, DefaultScanInterval)
+	if err != nil {
+		t.Fatalf("NewRepoScanner: %v", err)
+	}
+	if err := scanner.Scan(t.Context()); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	stateMgr, _ := state.NewManager(dataDir)
+	if pending := PendingRepos(stateMgr); !reflect.DeepEqual(pending, []string{clone}) {
+		t.Errorf("PendingRepos() = %v, want the new clone", pending)
+	}
+
+	// A deleted repo is forgotten rather than reported as missing hooks.
+	os.RemoveAll(hooked)
+	scanner.auto = true
+	if err := scanner.Scan(t.Context()); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if pending := PendingRepos(stateMgr); len(pending) != 0 {
+		t.Errorf("PendingRepos() after auto install = %v", pending)
+	}
+	if repos, _ := HookedRepos(dataDir); !reflect.DeepEqual(repos, []string{clone}) {
+		t.Errorf("HookedRepos() = %v, want only the clone", repos)
+	}
+}
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"fmt"
+
+	"devlog/internal/install"
+	"devlog/internal/modules"
+	"devlog/modules/forge"
+)
+
+type Module struct{}
+
+func (m *Module) Name() string {
+	return "github"
+}
+
+func (m *Module) Description() string {
+	return "Receive GitHub webhooks for pull requests, reviews, issues, and workflow runs, and optionally import stars and issue comments as research"
+}
+
+func (m *Module) Install(ctx *install.Context) error {
+	ctx.Log("Installing GitHub webhook receiver...")
+	ctx.Log("")
+	ctx.Log("The daemon will accept GitHub webhooks at:")
+	ctx.Log("  POST /api/v1/webhooks/github")
+	ctx.Log("")
+	ctx.Log("Setup:")
+	ctx.Log("  1. Find the generated secret under modules.github.webhook_secret in config.yaml")
+	ctx.Log("  2. In your repository (or organization) settings, add a webhook:")
+	ctx.Log("       Payload URL:  https://<your-tunnel>/api/v1/webhooks/github")
+	ctx.Log("       Content type: application/json")
+	ctx.Log("       Secret:       the webhook_secret value")
+	ctx.Log("       Events:       Pull requests, Pull request reviews, Issues, Workflow runs")
+	ctx.Log("")
+	ctx.Log("Note: the daemon listens on 127.0.0.1, so GitHub needs a tunnel")
+	ctx.Log("(e.g. cloudflared, ngrok, tailscale funnel) to reach it.")
+	ctx.Log("")
+	ctx.Log("Optional: set modules.github.research.enabled to import repos you star")
+	ctx.Log("and issues you comment on as research events (needs $GITHUB_TOKEN).")
+	ctx.Log("")
+	ctx.Log("✓ GitHub webhook receiver enabled")
+
+	return nil
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling GitHub webhook receiver...")
+	ctx.Log("✓ Webhooks will be rejected once the config is reloaded")
+	ctx.Log("")
+	ctx.Log("Note: Remember to remove the webhook from your GitHub repository settings.")
+	return nil
+}
+
+func (m *Module) DefaultConfig() interface{} {
+	return map[string]interface{}{
+		"webhook_secret": forge.GenerateSecret(),
+		"repos":          []interface{}{},
+		"research": map[string]interface{}{
+			"enabled":               false,
+			"token_env":             "GITHUB_TOKEN",
+			"poll_interval_seconds": defaultResearchInterval,
+			"lookback_days":         defaultLookbackDays,
+			"sources":               []interface{}{ResearchStars, ResearchComments},
+		},
+	}
+}
+
+func (m *Module) ValidateConfig(config interface{}) error {
+	cfg, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config must be a map")
+	}
+
+	if err := forge.ValidateReceiver(cfg); err != nil {
+		return err
+	}
+
+	return validateResearchConfig(cfg)
+}
+
+func init() {
+	modules.Register(&Module{})
+}
@@ -0,0 +1,488 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"devlog/internal/errors"
+	"devlog/internal/events"
+	"devlog/internal/modules"
+	"devlog/internal/poller"
+	"devlog/internal/state"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultAPIURL           = "https://api.github.com"
+	defaultResearchInterval = 900
+	defaultLookbackDays     = 30
+	maxResearchPages        = 10
+
+	ResearchStars         = "stars"
+	ResearchComments      = "comments"
+	ResearchNotifications = "notifications"
+)
+
+var defaultResearchSources = []string{ResearchStars, ResearchComments}
+
+type ResearchConfig struct {
+	Enabled             bool
+	Username            string
+	Token               string
+	TokenEnv            string
+	PollIntervalSeconds int
+	LookbackDays        int
+	Sources             []string
+}
+
+// parseResearchConfig reads the optional research block of the github module
+// config, applying defaults for anything unset.
+func parseResearchConfig(config map[string]interface{}) ResearchConfig {
+	cfg := ResearchConfig{
+		TokenEnv:            "GITHUB_TOKEN",
+		PollIntervalSeconds: defaultResearchInterval,
+		LookbackDays:        defaultLookbackDays,
+		Sources:             defaultResearchSources,
+	}
+
+	raw, ok := config["research"].(map[string]interface{})
+	if !ok {
+		return cfg
+	}
+
+	cfg.Enabled, _ = raw["enabled"].(bool)
+	cfg.Username, _ = raw["username"].(string)
+	cfg.Token, _ = raw["token"].(string)
+	if env, ok := raw["token_env"].(string); ok && env != "" {
+		cfg.TokenEnv = env
+	}
+	if n, ok := numberValue(raw["poll_interval_seconds"]); ok {
+		cfg.PollIntervalSeconds = n
+	}
+	if n, ok := numberValue(raw["lookback_days"]); ok {
+		cfg.LookbackDays = n
+	}
+	if list, ok := raw["sources"].([]interface{}); ok {
+		cfg.Sources = nil
+		for _, s := range list {
+			if name, ok := s.(string); ok {
+				cfg.Sources = append(cfg.Sources, name)
+			}
+		}
+	}
+
+	return cfg
+}
+
+func numberValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func validateResearchConfig(config map[string]interface{}) error {
+	val, ok := config["research"]
+	if !ok || val == nil {
+		return nil
+	}
+	raw, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("research must be a map")
+	}
+
+	for _, key := range []string{"poll_interval_seconds", "lookback_days"} {
+		if v, ok := raw[key]; ok {
+			if _, ok := numberValue(v); !ok {
+				return fmt.Errorf("research.%s must be a number", key)
+			}
+		}
+	}
+
+	cfg := parseResearchConfig(config)
+	if cfg.PollIntervalSeconds < 300 || cfg.PollIntervalSeconds > 86400 {
+		return fmt.Errorf("research.poll_interval_seconds must be between 300 and 86400")
+	}
+	if cfg.LookbackDays < 1 || cfg.LookbackDays > 90 {
+		return fmt.Errorf("research.lookback_days must be between 1 and 90")
+	}
+	for _, source := range cfg.Sources {
+		switch source {
+		case ResearchStars, ResearchComments, ResearchNotifications:
+		default:
+			return fmt.Errorf("research.sources: unknown source %q (use stars, comments or notifications)", source)
+		}
+	}
+	return nil
+}
+
+func (m *Module) CreatePoller(config map[string]interface{}, dataDir string) (poller.Poller, error) {
+	cfg := parseResearchConfig(config)
+	if !cfg.Enabled {
+		return nil, modules.ErrPollerDisabled
+	}
+
+	token := cfg.Token
+	if token == "" {
+		token = os.Getenv(cfg.TokenEnv)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("github research import needs a token: set research.token or $%s", cfg.TokenEnv)
+	}
+
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return nil, errors.WrapModule("github", "create state manager", err)
+	}
+
+	return &ResearchPoller{
+		cfg:      cfg,
+		token:    token,
+		apiURL:   defaultAPIURL,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		stateMgr: stateMgr,
+		logger:   slog.Default().With("module", "github"),
+	}, nil
+}
+
+// ResearchPoller imports the user's GitHub interactions that happen outside
+// their own repos (stars, issue comments, notifications) as research events.
+type ResearchPoller struct {
+	cfg      ResearchConfig
+	token    string
+	apiURL   string
+	client   *http.Client
+	stateMgr *state.Manager
+	logger   *slog.Logger
+}
+
+func (p *ResearchPoller) Name() string {
+	return "github"
+}
+
+func (p *ResearchPoller) PollInterval() time.Duration {
+	return time.Duration(p.cfg.PollIntervalSeconds) * time.Second
+}
+
+func (p *ResearchPoller) Poll(ctx context.Context) ([]*events.Event, error) {
+	var all []*events.Event
+	for _, source := range p.cfg.Sources {
+		var evts []*events.Event
+		var err error
+		switch source {
+		case ResearchStars:
+			evts, err = p.pollStars(ctx)
+		case ResearchComments:
+			evts, err = p.pollActivity(ctx)
+		case ResearchNotifications:
+			evts, err = p.pollNotifications(ctx)
+		}
+		if err != nil {
+			return all, errors.WrapModule("github", "poll "+source, err)
+		}
+		all = append(all, evts...)
+	}
+	return all, nil
+}
+
+// cursor returns the timestamp after which a source's items are new. The
+// first poll looks back lookback_days.
+func (p *ResearchPoller) cursor(key string) time.Time {
+	if s, ok := p.stateMgr.GetString("github", key); ok {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t
+		}
+	}
+	return time.Now().AddDate(0, 0, -p.cfg.LookbackDays)
+}
+
+func (p *ResearchPoller) saveCursor(key string, since, latest time.Time) error {
+	if !latest.After(since) {
+		return nil
+	}
+	if err := p.stateMgr.Set("github", key, latest.UTC().Format(time.RFC3339)); err != nil {
+		return errors.WrapModule("github", "save "+key, err)
+	}
+	return nil
+}
+
+func (p *ResearchPoller) get(ctx context.Context, path, accept string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if accept == "" {
+		accept = "application/vnd.github+json"
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (p *ResearchPoller) username(ctx context.Context) (string, error) {
+	if p.cfg.Username != "" {
+		return p.cfg.Username, nil
+	}
+	var me user
+	if err := p.get(ctx, "/user", "", &me); err != nil {
+		return "", err
+	}
+	p.cfg.Username = me.Login
+	return me.Login, nil
+}
+
+type starredRepo struct {
+	StarredAt string `json:"starred_at"`
+	Repo      struct {
+		FullName        string   `json:"full_name"`
+		Description     string   `json:"description"`
+		HTMLURL         string   `json:"html_url"`
+		Language        string   `json:"language"`
+		StargazersCount int      `json:"stargazers_count"`
+		Topics          []string `json:"topics"`
+	} `json:"repo"`
+}
+
+func (p *ResearchPoller) pollStars(ctx context.Context) ([]*events.Event, error) {
+	since := p.cursor("research_stars_since")
+	latest := since
+	var result []*events.Event
+
+	for page := 1; page <= maxResearchPages; page++ {
+		var stars []starredRepo
+		path := fmt.Sprintf("/user/starred?sort=created&direction=desc&per_page=100&page=%d", page)
+		if err := p.get(ctx, path, "application/vnd.github.star+json", &stars); err != nil {
+			return nil, err
+		}
+
+		done := len(stars) < 100
+		for _, s := range stars {
+			ts, err := time.Parse(time.RFC3339, s.StarredAt)
+			if err != nil {
+				continue
+			}
+			if !ts.After(since) {
+				done = true
+				break
+			}
+			if ts.After(latest) {
+				latest = ts
+			}
+
+			text := "Starred " + s.Repo.FullName
+			if s.Repo.Description != "" {
+				text += ": " + s.Repo.Description
+			}
+			event := newResearchEvent("star", "star:"+s.Repo.FullName+":"+s.StarredAt, ts, text, s.Repo.FullName, s.Repo.HTMLURL)
+			event.Payload["description"] = s.Repo.Description
+			event.Payload["stargazers"] = s.Repo.StargazersCount
+			if s.Repo.Language != "" {
+				event.Payload["language"] = s.Repo.Language
+				addTag(event, strings.ToLower(s.Repo.Language))
+			}
+			if len(s.Repo.Topics) > 0 {
+				event.Payload["topics"] = s.Repo.Topics
+			}
+			result = append(result, event)
+		}
+		if done {
+			break
+		}
+	}
+
+	return result, p.saveCursor("research_stars_since", since, latest)
+}
+
+type userEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	CreatedAt string `json:"created_at"`
+	Repo      struct {
+		Name string `json:"name"`
+	} `json:"repo"`
+	Payload struct {
+		Action  string `json:"action"`
+		Issue   *issue `json:"issue"`
+		Comment *struct {
+			Body    string `json:"body"`
+			HTMLURL string `json:"html_url"`
+		} `json:"comment"`
+	} `json:"payload"`
+}
+
+// pollActivity walks the user's public event feed for issues they opened or
+// commented on. GitHub keeps the last 90 days (up to 300 events) there.
+func (p *ResearchPoller) pollActivity(ctx context.Context) ([]*events.Event, error) {
+	login, err := p.username(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	since := p.cursor("research_activity_since")
+	latest := since
+	var result []*events.Event
+
+	for page := 1; page <= 3; page++ {
+		var feed []userEvent
+		path := fmt.Sprintf("/users/%s/events?per_page=100&page=%d", login, page)
+		if err := p.get(ctx, path, "", &feed); err != nil {
+			return nil, err
+		}
+
+		done := len(feed) < 100
+		for _, ue := range feed {
+			ts, err := time.Parse(time.RFC3339, ue.CreatedAt)
+			if err != nil {
+				continue
+			}
+			if !ts.After(since) {
+				done = true
+				break
+			}
+
+			event := convertActivity(ue, ts)
+			if event == nil {
+				continue
+			}
+			if ts.After(latest) {
+				latest = ts
+			}
+			result = append(result, event)
+		}
+		if done {
+			break
+		}
+	}
+
+	return result, p.saveCursor("research_activity_since", since, latest)
+}
+
+func convertActivity(ue userEvent, ts time.Time) *events.Event {
+	is := ue.Payload.Issue
+	if is == nil {
+		return nil
+	}
+
+	kind := "issue"
+	if strings.Contains(is.HTMLURL, "/pull/") {
+		kind = "pull request"
+	}
+
+	switch {
+	case ue.Type == "IssueCommentEvent" && ue.Payload.Action == "created" && ue.Payload.Comment != nil:
+		text := fmt.Sprintf("Commented on %s %s#%d: %s", kind, ue.Repo.Name, is.Number, is.Title)
+		event := newResearchEvent("issue_comment", "event:"+ue.ID, ts, text, ue.Repo.Name, ue.Payload.Comment.HTMLURL)
+		event.Payload["issue_number"] = is.Number
+		event.Payload["title"] = is.Title
+		event.Payload["comment"] = truncate(ue.Payload.Comment.Body, 1000)
+		return event
+	case ue.Type == "IssuesEvent" && ue.Payload.Action == "opened":
+		text := fmt.Sprintf("Opened issue %s#%d: %s", ue.Repo.Name, is.Number, is.Title)
+		event := newResearchEvent("issue_opened", "event:"+ue.ID, ts, text, ue.Repo.Name, is.HTMLURL)
+		event.Payload["issue_number"] = is.Number
+		event.Payload["title"] = is.Title
+		return event
+	}
+	return nil
+}
+
+type notificationThread struct {
+	ID        string `json:"id"`
+	Reason    string `json:"reason"`
+	UpdatedAt string `json:"updated_at"`
+	Subject   struct {
+		Title string `json:"title"`
+		URL   string `json:"url"`
+		Type  string `json:"type"`
+	} `json:"subject"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		HTMLURL  string `json:"html_url"`
+	} `json:"repository"`
+}
+
+func (p *ResearchPoller) pollNotifications(ctx context.Context) ([]*events.Event, error) {
+	since := p.cursor("research_notifications_since")
+	latest := since
+
+	var threads []notificationThread
+	path := "/notifications?all=true&participating=true&per_page=100&since=" + since.UTC().Format(time.RFC3339)
+	if err := p.get(ctx, path, "", &threads); err != nil {
+		return nil, err
+	}
+
+	var result []*events.Event
+	for _, th := range threads {
+		ts, err := time.Parse(time.RFC3339, th.UpdatedAt)
+		if err != nil || !ts.After(since) {
+			continue
+		}
+		if ts.After(latest) {
+			latest = ts
+		}
+
+		text := fmt.Sprintf("%s activity in %s: %s", th.Subject.Type, th.Repository.FullName, th.Subject.Title)
+		event := newResearchEvent("notification", "notification:"+th.ID+":"+th.UpdatedAt, ts, text, th.Repository.FullName, subjectHTMLURL(th.Subject.URL, th.Repository.HTMLURL))
+		event.Payload["title"] = th.Subject.Title
+		event.Payload["subject_type"] = th.Subject.Type
+		event.Payload["reason"] = th.Reason
+		result = append(result, event)
+	}
+
+	return result, p.saveCursor("research_notifications_since", since, latest)
+}
+
+// subjectHTMLURL turns a notification's API URL
+// (api.github.com/repos/o/r/issues/1) into the page a person would open.
+func subjectHTMLURL(apiURL, repoURL string) string {
+	if i := strings.Index(apiURL, "/repos/"); i >= 0 {
+		rest := strings.TrimPrefix(apiURL[i:], "/repos/")
+		rest = strings.Replace(rest, "/pulls/", "/pull/", 1)
+		return "https://github.com/" + rest
+	}
+	return repoURL
+}
+
+func newResearchEvent(kind, key string, ts time.Time, text, fullName, url string) *events.Event {
+	event := events.NewEvent(string(events.SourceGitHub), string(events.TypeResearch))
+	event.ID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("github:research:"+key)).String()
+	event.Timestamp = ts.UTC().Format(time.RFC3339)
+	event.Payload["kind"] = kind
+	event.Payload["text"] = text
+	event.Payload["full_name"] = fullName
+	event.Payload["url"] = url
+	event.Payload["tags"] = []string{"research", kind}
+	return event
+}
+
+func addTag(event *events.Event, tag string) {
+	event.Payload["tags"] = append(event.Tags(), tag)
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen] + "..."
+}
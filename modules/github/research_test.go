@@ -0,0 +1,208 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/modules"
+	"devlog/internal/state"
+)
+
+func researchConfig(research map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"webhook_secret": testSecret,
+		"research":       research,
+	}
+}
+
+func TestCreatePollerDisabled(t *testing.T) {
+	m := &Module{}
+
+	_, err := m.CreatePoller(map[string]interface{}{"webhook_secret": testSecret}, t.TempDir())
+	if !errors.Is(err, modules.ErrPollerDisabled) {
+		t.Errorf("CreatePoller() without research = %v, want ErrPollerDisabled", err)
+	}
+
+	t.Setenv("DEVLOG_TEST_GH_TOKEN", "")
+	_, err = m.CreatePoller(researchConfig(map[string]interface{}{
+		"enabled":   true,
+		"token_env": "DEVLOG_TEST_GH_TOKEN",
+	}), t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "DEVLOG_TEST_GH_TOKEN") {
+		t.Errorf("CreatePoller() without token = %v, want token error", err)
+	}
+}
+
+func TestValidateResearchConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		research map[string]interface{}
+		wantErr  bool
+	}{
+		{"defaults", map[string]interface{}{"enabled": true}, false},
+		{"all sources", map[string]interface{}{"sources": []interface{}{"stars", "comments", "notifications"}}, false},
+		{"unknown source", map[string]interface{}{"sources": []interface{}{"forks"}}, true},
+		{"interval too short", map[string]interface{}{"poll_interval_seconds": 60}, true},
+		{"lookback too long", map[string]interface{}{"lookback_days": float64(365)}, true},
+		{"interval not a number", map[string]interface{}{"poll_interval_seconds": "often"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Module{}).ValidateConfig(researchConfig(tt.research))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newTestResearchPoller(t *testing.T, handler http.Handler, sources ...string) *ResearchPoller {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	stateMgr, err := state.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &ResearchPoller{
+		cfg: ResearchConfig{
+			Username:            "octocat",
+			PollIntervalSeconds: defaultResearchInterval,
+			LookbackDays:        defaultLookbackDays,
+			Sources:             sources,
+		},
+		token:    "test-token",
+		apiURL:   server.URL,
+		client:   server.Client(),
+		stateMgr: stateMgr,
+	}
+}
+
+func TestResearchPollerStars(t *testing.T) {
+	recent := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().AddDate(0, 0, -60).UTC().Format(time.RFC3339)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/starred", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("missing auth header")
+		}
+		if !strings.Contains(r.Header.Get("Accept"), "star+json") {
+			t.Errorf("Accept = %q, want star+json media type", r.Header.Get("Accept"))
+		}
+		w.Write([]byte(`[
+			{"starred_at":"` + recent + `","repo":{"full_name":"charmbracelet/bubbletea","description":"A TUI framework","html_url":"https://github.com/charmbracelet/bubbletea","language":"Go","stargazers_count":30000,"topics":["tui"]}},
+			{"starred_at":"` + old + `","repo":{"full_name":"old/repo"}}
+		]`))
+	})
+
+	p := newTestResearchPoller(t, mux, ResearchStars)
+
+	evts, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if len(evts) != 1 {
+		t.Fatalf("got %d events, want 1 (stars older than the lookback are skipped)", len(evts))
+	}
+
+	evt := evts[0]
+	if err := evt.Validate(); err != nil {
+		t.Fatalf("invalid event: %v", err)
+	}
+	if evt.Source != string(events.SourceGitHub) || evt.Type != string(events.TypeResearch) {
+		t.Errorf("event = %s/%s, want github/research", evt.Source, evt.Type)
+	}
+	if evt.Payload["kind"] != "star" || evt.Payload["full_name"] != "charmbracelet/bubbletea" {
+		t.Errorf("payload = %v", evt.Payload)
+	}
+	if got := strings.Join(evt.Tags(), ","); got != "research,star,go" {
+		t.Errorf("tags = %s", got)
+	}
+	if text := evt.Payload["text"].(string); text != "Starred charmbracelet/bubbletea: A TUI framework" {
+		t.Errorf("text = %q", text)
+	}
+
+	evts, err = p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("second Poll() error: %v", err)
+	}
+	if len(evts) != 0 {
+		t.Errorf("second poll returned %d events, want 0", len(evts))
+	}
+}
+
+func TestResearchPollerActivity(t *testing.T) {
+	at := func(d time.Duration) string { return time.Now().Add(-d).UTC().Format(time.RFC3339) }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/octocat/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"id":"3","type":"IssueCommentEvent","created_at":"` + at(time.Hour) + `","repo":{"name":"spf13/cobra"},"payload":{"action":"created","issue":{"number":12,"title":"Shell completion for flags","html_url":"https://github.com/spf13/cobra/issues/12"},"comment":{"body":"Does this work with zsh?","html_url":"https://github.com/spf13/cobra/issues/12#issuecomment-1"}}},
+			{"id":"2","type":"PushEvent","created_at":"` + at(2*time.Hour) + `","repo":{"name":"octocat/dotfiles"},"payload":{}},
+			{"id":"1","type":"IssuesEvent","created_at":"` + at(3*time.Hour) + `","repo":{"name":"urfave/cli"},"payload":{"action":"opened","issue":{"number":7,"title":"Flag aliases","html_url":"https://github.com/urfave/cli/issues/7"}}}
+		]`))
+	})
+
+	p := newTestResearchPoller(t, mux, ResearchComments)
+
+	evts, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if len(evts) != 2 {
+		t.Fatalf("got %d events, want 2", len(evts))
+	}
+	if evts[0].Payload["kind"] != "issue_comment" || evts[0].Payload["comment"] != "Does this work with zsh?" {
+		t.Errorf("comment event = %v", evts[0].Payload)
+	}
+	if evts[1].Payload["kind"] != "issue_opened" || evts[1].Payload["text"] != "Opened issue urfave/cli#7: Flag aliases" {
+		t.Errorf("issue event = %v", evts[1].Payload)
+	}
+}
+
+func TestResearchPollerNotifications(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /notifications", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("participating") != "true" || r.URL.Query().Get("since") == "" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[{"id":"99","reason":"comment","updated_at":"` + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339) + `",
+			"subject":{"title":"Add context support","url":"https://api.github.com/repos/go-chi/chi/pulls/5","type":"PullRequest"},
+			"repository":{"full_name":"go-chi/chi","html_url":"https://github.com/go-chi/chi"}}]`))
+	})
+
+	p := newTestResearchPoller(t, mux, ResearchNotifications)
+
+	evts, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("Poll() error: %v", err)
+	}
+	if len(evts) != 1 {
+		t.Fatalf("got %d events, want 1", len(evts))
+	}
+	if evts[0].Payload["url"] != "https://github.com/go-chi/chi/pull/5" || evts[0].Payload["reason"] != "comment" {
+		t.Errorf("payload = %v", evts[0].Payload)
+	}
+}
+
+func TestResearchPollerAPIError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /user/starred", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad credentials", http.StatusUnauthorized)
+	})
+
+	p := newTestResearchPoller(t, mux, ResearchStars)
+	if _, err := p.Poll(context.Background()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Poll() error = %v, want 401", err)
+	}
+}
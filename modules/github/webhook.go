@@ -0,0 +1,246 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"devlog/internal/events"
+	"devlog/internal/modules"
+	"devlog/modules/forge"
+)
+
+type user struct {
+	Login string `json:"login"`
+}
+
+type repository struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+}
+
+func (r repository) repo() forge.Repo {
+	return forge.Repo{Name: r.Name, FullName: r.FullName}
+}
+
+type pullRequest struct {
+	Number   int    `json:"number"`
+	Title    string `json:"title"`
+	HTMLURL  string `json:"html_url"`
+	Merged   bool   `json:"merged"`
+	User     user   `json:"user"`
+	MergedBy *user  `json:"merged_by"`
+	Head     struct {
+		Ref string `json:"ref"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	CreatedAt string `json:"created_at"`
+	ClosedAt  string `json:"closed_at"`
+	MergedAt  string `json:"merged_at"`
+}
+
+type review struct {
+	State       string `json:"state"`
+	Body        string `json:"body"`
+	HTMLURL     string `json:"html_url"`
+	User        user   `json:"user"`
+	SubmittedAt string `json:"submitted_at"`
+}
+
+type issue struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	HTMLURL   string `json:"html_url"`
+	User      user   `json:"user"`
+	CreatedAt string `json:"created_at"`
+	ClosedAt  string `json:"closed_at"`
+}
+
+type workflowRun struct {
+	Name       string `json:"name"`
+	HeadBranch string `json:"head_branch"`
+	HeadSHA    string `json:"head_sha"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+	RunNumber  int    `json:"run_number"`
+	Event      string `json:"event"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type webhookPayload struct {
+	Action      string       `json:"action"`
+	Repository  repository   `json:"repository"`
+	Sender      user         `json:"sender"`
+	PullRequest *pullRequest `json:"pull_request"`
+	Review      *review      `json:"review"`
+	Issue       *issue       `json:"issue"`
+	WorkflowRun *workflowRun `json:"workflow_run"`
+}
+
+func (m *Module) HandleWebhook(headers http.Header, body []byte, config map[string]interface{}) ([]*events.Event, error) {
+	secret, _ := config["webhook_secret"].(string)
+	if !forge.VerifyHMAC(secret, headers.Get("X-Hub-Signature-256"), body) {
+		return nil, modules.ErrWebhookUnauthorized
+	}
+
+	eventName := headers.Get("X-GitHub-Event")
+	if eventName == "" {
+		return nil, fmt.Errorf("missing X-GitHub-Event header")
+	}
+	if eventName == "ping" {
+		return nil, nil
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	if !forge.RepoAllowed(config, payload.Repository.repo()) {
+		return nil, nil
+	}
+
+	event := convertEvent(eventName, &payload)
+	if event == nil {
+		return nil, nil
+	}
+
+	if delivery := headers.Get("X-GitHub-Delivery"); delivery != "" {
+		event.ID = forge.DeliveryID(events.SourceGitHub, delivery)
+	}
+
+	return []*events.Event{event}, nil
+}
+
+func convertEvent(eventName string, payload *webhookPayload) *events.Event {
+	switch eventName {
+	case "pull_request":
+		return convertPullRequest(payload)
+	case "pull_request_review":
+		return convertReview(payload)
+	case "issues":
+		return convertIssue(payload)
+	case "workflow_run":
+		return convertWorkflowRun(payload)
+	default:
+		return nil
+	}
+}
+
+func convertPullRequest(payload *webhookPayload) *events.Event {
+	pr := payload.PullRequest
+	if pr == nil {
+		return nil
+	}
+
+	var eventType events.EventType
+	var timestamp string
+	switch {
+	case payload.Action == "opened":
+		eventType = events.TypePROpened
+		timestamp = pr.CreatedAt
+	case payload.Action == "closed" && pr.Merged:
+		eventType = events.TypePRMerged
+		timestamp = pr.MergedAt
+	case payload.Action == "closed":
+		eventType = events.TypePRClosed
+		timestamp = pr.ClosedAt
+	default:
+		return nil
+	}
+
+	event := newEvent(eventType, payload, timestamp)
+	event.Branch = pr.Head.Ref
+	event.SessionID = prSessionID(payload.Repository, pr.Number)
+	event.Payload["pr_number"] = pr.Number
+	event.Payload["title"] = pr.Title
+	event.Payload["url"] = pr.HTMLURL
+	event.Payload["author"] = pr.User.Login
+	event.Payload["base_branch"] = pr.Base.Ref
+	if pr.MergedBy != nil {
+		event.Payload["merged_by"] = pr.MergedBy.Login
+	}
+	return event
+}
+
+func convertReview(payload *webhookPayload) *events.Event {
+	pr := payload.PullRequest
+	rv := payload.Review
+	if pr == nil || rv == nil || payload.Action != "submitted" {
+		return nil
+	}
+
+	event := newEvent(events.TypePRReview, payload, rv.SubmittedAt)
+	event.Branch = pr.Head.Ref
+	event.SessionID = prSessionID(payload.Repository, pr.Number)
+	event.Payload["pr_number"] = pr.Number
+	event.Payload["title"] = pr.Title
+	event.Payload["url"] = rv.HTMLURL
+	event.Payload["author"] = pr.User.Login
+	event.Payload["reviewer"] = rv.User.Login
+	event.Payload["state"] = strings.ToLower(rv.State)
+	if rv.Body != "" {
+		event.Payload["body"] = rv.Body
+	}
+	return event
+}
+
+func convertIssue(payload *webhookPayload) *events.Event {
+	is := payload.Issue
+	if is == nil {
+		return nil
+	}
+
+	var eventType events.EventType
+	var timestamp string
+	switch payload.Action {
+	case "opened":
+		eventType = events.TypeIssueOpened
+		timestamp = is.CreatedAt
+	case "closed":
+		eventType = events.TypeIssueClosed
+		timestamp = is.ClosedAt
+	default:
+		return nil
+	}
+
+	event := newEvent(eventType, payload, timestamp)
+	event.Payload["issue_number"] = is.Number
+	event.Payload["title"] = is.Title
+	event.Payload["url"] = is.HTMLURL
+	event.Payload["author"] = is.User.Login
+	return event
+}
+
+func convertWorkflowRun(payload *webhookPayload) *events.Event {
+	run := payload.WorkflowRun
+	if run == nil || payload.Action != "completed" {
+		return nil
+	}
+
+	event := newEvent(events.TypeWorkflowRun, payload, run.UpdatedAt)
+	event.Branch = run.HeadBranch
+	event.Payload["title"] = run.Name
+	event.Payload["workflow"] = run.Name
+	event.Payload["run_number"] = run.RunNumber
+	event.Payload["conclusion"] = run.Conclusion
+	event.Payload["head_sha"] = run.HeadSHA
+	event.Payload["trigger"] = run.Event
+	event.Payload["url"] = run.HTMLURL
+	if forge.FailedConclusion(run.Conclusion) {
+		event.Severity = string(events.SeverityError)
+	}
+	return event
+}
+
+func prSessionID(repo repository, number int) string {
+	return forge.SessionID(events.SourceGitHub, repo.repo(), number)
+}
+
+func newEvent(eventType events.EventType, payload *webhookPayload, timestamp string) *events.Event {
+	return forge.NewEvent(events.SourceGitHub, eventType, payload.Repository.repo(), payload.Action, payload.Sender.Login, timestamp)
+}
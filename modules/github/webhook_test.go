@@ -0,0 +1,219 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"testing"
+
+	"devlog/internal/events"
+	"devlog/internal/modules"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookHeaders(eventName, delivery string, body []byte) http.Header {
+	h := http.Header{}
+	h.Set("X-GitHub-Event", eventName)
+	h.Set("X-GitHub-Delivery", delivery)
+	h.Set("X-Hub-Signature-256", sign(testSecret, body))
+	return h
+}
+
+func TestHandleWebhook(t *testing.T) {
+	m := &Module{}
+	config := map[string]interface{}{"webhook_secret": testSecret}
+
+	tests := []struct {
+		name       string
+		eventName  string
+		body       string
+		wantType   events.EventType
+		wantBranch string
+	}{
+		{
+			name:       "pr opened",
+			eventName:  "pull_request",
+			body:       `{"action":"opened","repository":{"name":"devlog","full_name":"me/devlog"},"pull_request":{"number":7,"title":"Add webhooks","head":{"ref":"feature/webhooks"},"created_at":"2026-01-02T10:00:00Z"}}`,
+			wantType:   events.TypePROpened,
+			wantBranch: "feature/webhooks",
+		},
+		{
+			name:       "pr merged",
+			eventName:  "pull_request",
+			body:       `{"action":"closed","repository":{"name":"devlog"},"pull_request":{"number":7,"merged":true,"head":{"ref":"feature/webhooks"},"merged_by":{"login":"me"}}}`,
+			wantType:   events.TypePRMerged,
+			wantBranch: "feature/webhooks",
+		},
+		{
+			name:       "pr closed without merge",
+			eventName:  "pull_request",
+			body:       `{"action":"closed","repository":{"name":"devlog"},"pull_request":{"number":7,"merged":false,"head":{"ref":"wip"}}}`,
+			wantType:   events.TypePRClosed,
+			wantBranch: "wip",
+		},
+		{
+			name:       "review submitted",
+			eventName:  "pull_request_review",
+			body:       `{"action":"submitted","repository":{"name":"devlog"},"pull_request":{"number":7,"head":{"ref":"feature/webhooks"}},"review":{"state":"APPROVED","user":{"login":"reviewer"}}}`,
+			wantType:   events.TypePRReview,
+			wantBranch: "feature/webhooks",
+		},
+		{
+			name:      "issue opened",
+			eventName: "issues",
+			body:      `{"action":"opened","repository":{"name":"devlog"},"issue":{"number":3,"title":"Bug"}}`,
+			wantType:  events.TypeIssueOpened,
+		},
+		{
+			name:       "workflow run completed",
+			eventName:  "workflow_run",
+			body:       `{"action":"completed","repository":{"name":"devlog"},"workflow_run":{"name":"CI","head_branch":"main","conclusion":"failure","run_number":42}}`,
+			wantType:   events.TypeWorkflowRun,
+			wantBranch: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(tt.body)
+			evts, err := m.HandleWebhook(webhookHeaders(tt.eventName, "delivery-1", body), body, config)
+			if err != nil {
+				t.Fatalf("HandleWebhook() error = %v", err)
+			}
+			if len(evts) != 1 {
+				t.Fatalf("got %d events, want 1", len(evts))
+			}
+
+			evt := evts[0]
+			if err := evt.Validate(); err != nil {
+				t.Errorf("event failed validation: %v", err)
+			}
+			if evt.Type != string(tt.wantType) {
+				t.Errorf("type = %s, want %s", evt.Type, tt.wantType)
+			}
+			if evt.Source != string(events.SourceGitHub) {
+				t.Errorf("source = %s, want github", evt.Source)
+			}
+			if evt.Repo != "devlog" {
+				t.Errorf("repo = %s, want devlog", evt.Repo)
+			}
+			if evt.Branch != tt.wantBranch {
+				t.Errorf("branch = %s, want %s", evt.Branch, tt.wantBranch)
+			}
+		})
+	}
+}
+
+func TestHandleWebhook_Links(t *testing.T) {
+	m := &Module{}
+	config := map[string]interface{}{"webhook_secret": testSecret}
+	handle := func(eventName, body string) *events.Event {
+		t.Helper()
+		evts, err := m.HandleWebhook(webhookHeaders(eventName, eventName+"-1", []byte(body)), []byte(body), config)
+		if err != nil || len(evts) != 1 {
+			t.Fatalf("HandleWebhook() = %v, %v", evts, err)
+		}
+		return evts[0]
+	}
+
+	opened := handle("pull_request", `{"action":"opened","repository":{"name":"devlog","full_name":"me/devlog"},"pull_request":{"number":7,"head":{"ref":"f"}}}`)
+	review := handle("pull_request_review", `{"action":"submitted","repository":{"name":"devlog","full_name":"me/devlog"},"pull_request":{"number":7,"head":{"ref":"f"}},"review":{"state":"APPROVED","user":{"login":"r"}}}`)
+	if opened.SessionID != "github:me/devlog#7" || review.SessionID != opened.SessionID {
+		t.Errorf("session ids = %q, %q, want both github:me/devlog#7", opened.SessionID, review.SessionID)
+	}
+
+	failed := handle("workflow_run", `{"action":"completed","repository":{"name":"devlog"},"workflow_run":{"name":"CI","conclusion":"failure"}}`)
+	if failed.Severity != string(events.SeverityError) {
+		t.Errorf("failed run severity = %q, want error", failed.Severity)
+	}
+	passed := handle("workflow_run", `{"action":"completed","repository":{"name":"devlog"},"workflow_run":{"name":"CI","conclusion":"success"}}`)
+	if passed.Severity != "" {
+		t.Errorf("successful run severity = %q, want none", passed.Severity)
+	}
+}
+
+func TestHandleWebhook_DeliveryIDIsStable(t *testing.T) {
+	m := &Module{}
+	config := map[string]interface{}{"webhook_secret": testSecret}
+	body := []byte(`{"action":"opened","repository":{"name":"devlog"},"issue":{"number":3}}`)
+
+	first, err := m.HandleWebhook(webhookHeaders("issues", "abc", body), body, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.HandleWebhook(webhookHeaders("issues", "abc", body), body, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first[0].ID != second[0].ID {
+		t.Errorf("redelivered webhook got a new id: %s vs %s", first[0].ID, second[0].ID)
+	}
+}
+
+func TestHandleWebhook_Ignored(t *testing.T) {
+	m := &Module{}
+	config := map[string]interface{}{
+		"webhook_secret": testSecret,
+		"repos":          []interface{}{"me/devlog"},
+	}
+
+	tests := []struct {
+		name      string
+		eventName string
+		body      string
+	}{
+		{"ping", "ping", `{"zen":"Keep it logically awesome."}`},
+		{"unsupported event", "star", `{"action":"created","repository":{"full_name":"me/devlog"}}`},
+		{"unsupported action", "pull_request", `{"action":"labeled","repository":{"full_name":"me/devlog"},"pull_request":{"number":1}}`},
+		{"repo not allowed", "issues", `{"action":"opened","repository":{"name":"other","full_name":"me/other"},"issue":{"number":1}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(tt.body)
+			evts, err := m.HandleWebhook(webhookHeaders(tt.eventName, "d", body), body, config)
+			if err != nil {
+				t.Fatalf("HandleWebhook() error = %v", err)
+			}
+			if len(evts) != 0 {
+				t.Errorf("got %d events, want 0", len(evts))
+			}
+		})
+	}
+}
+
+func TestHandleWebhook_BadSignature(t *testing.T) {
+	m := &Module{}
+	body := []byte(`{"action":"opened"}`)
+	headers := webhookHeaders("issues", "d", body)
+	headers.Set("X-Hub-Signature-256", sign("not-the-configured-secret", body))
+
+	_, err := m.HandleWebhook(headers, body, map[string]interface{}{"webhook_secret": testSecret})
+	if !errors.Is(err, modules.ErrWebhookUnauthorized) {
+		t.Errorf("got error %v, want ErrWebhookUnauthorized", err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	m := &Module{}
+
+	if err := m.ValidateConfig(m.DefaultConfig()); err != nil {
+		t.Errorf("default config should be valid: %v", err)
+	}
+	if err := m.ValidateConfig(map[string]interface{}{"webhook_secret": "short"}); err == nil {
+		t.Error("expected error for short secret")
+	}
+	if err := m.ValidateConfig(map[string]interface{}{"webhook_secret": testSecret, "repos": "me/devlog"}); err == nil {
+		t.Error("expected error for non-list repos")
+	}
+}
@@ -0,0 +1,152 @@
+package kubectl
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"devlog/internal/events"
+)
+
+const (
+	EnvProd    = "prod"
+	EnvStaging = "staging"
+	EnvDev     = "dev"
+)
+
+const (
+	DangerLow      = "low"
+	DangerMedium   = "medium"
+	DangerHigh     = "high"
+	DangerCritical = "critical"
+)
+
+// envOrder is the order environments are matched in, so a context that
+// matches both a prod and a dev pattern is treated as prod.
+var envOrder = []string{EnvProd, EnvStaging, EnvDev}
+
+func defaultEnvironments() map[string]interface{} {
+	return map[string]interface{}{
+		EnvProd:    []interface{}{"*prod*"},
+		EnvStaging: []interface{}{"*staging*", "*stage*"},
+		EnvDev:     []interface{}{"*dev*", "kind-*", "minikube", "docker-desktop", "rancher-desktop"},
+	}
+}
+
+func parseEnvironments(cfg map[string]interface{}) (map[string][]string, error) {
+	raw, ok := cfg["environments"]
+	if !ok {
+		return nil, nil
+	}
+	envMap, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("environments must be a map of environment name to context patterns")
+	}
+
+	envs := make(map[string][]string, len(envMap))
+	for env, value := range envMap {
+		var patterns []string
+		switch v := value.(type) {
+		case string:
+			patterns = []string{v}
+		case []string:
+			patterns = v
+		case []interface{}:
+			for _, p := range v {
+				s, ok := p.(string)
+				if !ok {
+					return nil, fmt.Errorf("environments.%s must be a list of strings", env)
+				}
+				patterns = append(patterns, s)
+			}
+		default:
+			return nil, fmt.Errorf("environments.%s must be a list of strings", env)
+		}
+
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("environments.%s: invalid pattern %q", env, p)
+			}
+		}
+		envs[env] = patterns
+	}
+	return envs, nil
+}
+
+// environmentFor returns the first environment with a pattern matching the
+// kubectl context or cluster name, or "" if none does.
+func environmentFor(envs map[string][]string, kubeContext, cluster string) string {
+	names := make([]string, 0, len(envs))
+	for env := range envs {
+		names = append(names, env)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		ri, rj := envRank(names[i]), envRank(names[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+
+	for _, env := range names {
+		for _, pattern := range envs[env] {
+			for _, name := range []string{kubeContext, cluster} {
+				if name == "" {
+					continue
+				}
+				if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); ok {
+					return env
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func envRank(env string) int {
+	for i, e := range envOrder {
+		if e == env {
+			return i
+		}
+	}
+	return len(envOrder)
+}
+
+// dangerLevel rates an operation by how much damage it can do, raised one
+// step when it targets production.
+func dangerLevel(operation, env string) string {
+	levels := []string{DangerLow, DangerMedium, DangerHigh, DangerCritical}
+
+	level := 1
+	switch operation {
+	case "get", "describe", "logs":
+		level = 0
+	case "delete":
+		level = 2
+	}
+
+	if env == EnvProd && level > 0 {
+		level++
+	}
+	return levels[level]
+}
+
+// classify records the environment and danger level of a kubectl event
+// using the module's environments config.
+func classify(event *events.Event, cfg map[string]interface{}) {
+	envs, err := parseEnvironments(cfg)
+	if err != nil || envs == nil {
+		envs, _ = parseEnvironments(map[string]interface{}{"environments": defaultEnvironments()})
+	}
+
+	kubeContext, _ := event.Payload["context"].(string)
+	cluster, _ := event.Payload["cluster"].(string)
+	env := environmentFor(envs, kubeContext, cluster)
+	if env != "" {
+		event.Payload["environment"] = env
+	}
+
+	operation := strings.TrimPrefix(event.Type, "kubectl_")
+	event.Payload["danger_level"] = dangerLevel(operation, env)
+}
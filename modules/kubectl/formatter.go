@@ -53,6 +53,14 @@ func (f *KubectlFormatter) Format(event *events.Event) string {
 		}
 	}
 
+	if manifests := manifestList(event); len(manifests) > 0 {
+		part := "-f " + manifests[0]
+		if len(manifests) > 1 {
+			part += fmt.Sprintf(" +%d", len(manifests)-1)
+		}
+		parts = append(parts, part)
+	}
+
 	if namespace != "" {
 		parts = append(parts, fmt.Sprintf("-n %s", namespace))
 	}
@@ -67,5 +75,32 @@ func (f *KubectlFormatter) Format(event *events.Event) string {
 		result += fmt.Sprintf(" [exit:%d]", exitCode)
 	}
 
+	var risk []string
+	if env, ok := event.Payload["environment"].(string); ok && env != "" && env != context {
+		risk = append(risk, env)
+	}
+	if danger, ok := event.Payload["danger_level"].(string); ok && (danger == DangerHigh || danger == DangerCritical) {
+		risk = append(risk, "danger:"+danger)
+	}
+	if len(risk) > 0 {
+		result += " [" + strings.Join(risk, ", ") + "]"
+	}
+
 	return result
 }
+
+func manifestList(event *events.Event) []string {
+	switch m := event.Payload["manifests"].(type) {
+	case []string:
+		return m
+	case []interface{}:
+		result := make([]string, 0, len(m))
+		for _, v := range m {
+			if s, ok := v.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
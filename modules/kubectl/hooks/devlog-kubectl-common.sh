@@ -103,6 +103,35 @@ __devlog_extract_resource_names() {
     fi
 }
 
+__devlog_extract_manifests() {
+    local args=("$@")
+
+    for i in "${!args[@]}"; do
+        case "${args[$i]}" in
+            -f|--filename|-k|--kustomize)
+                [ -n "${args[$((i+1))]}" ] && echo "${args[$((i+1))]}"
+                ;;
+            --filename=*|--kustomize=*)
+                echo "${args[$i]#*=}"
+                ;;
+            -f=*|-k=*)
+                echo "${args[$i]#-?=}"
+                ;;
+            -f?*|-k?*)
+                echo "${args[$i]#-?}"
+                ;;
+        esac
+    done
+}
+
+__devlog_manifest_args() {
+    MANIFEST_ARGS=()
+    local manifest
+    while IFS= read -r manifest; do
+        [ -n "$manifest" ] && MANIFEST_ARGS+=(--manifest="$manifest")
+    done < <(__devlog_extract_manifests "$@")
+}
+
 __devlog_capture_kubectl_event() {
     [ -z "$DEVLOG_BIN_PATH" ] && return
 
@@ -53,11 +53,13 @@ case "$SUBCOMMAND" in
             NAMESPACE=$(__devlog_extract_namespace "$@")
             RESOURCE_TYPE=$(__devlog_extract_resource_type "$@")
             RESOURCE_NAMES=$(grep -oE '(created|configured|unchanged)$' "$OUTPUT_FILE" | wc -l | tr -d ' ')
+            __devlog_manifest_args "$@"
 
             __devlog_capture_kubectl_event "$SUBCOMMAND" "$KUBECTL_CONTEXT" "$NAMESPACE" \
                 --resource-type="$RESOURCE_TYPE" \
                 --resource-count="$RESOURCE_NAMES" \
-                --exit-code="$EXIT_CODE" &
+                --exit-code="$EXIT_CODE" \
+                "${MANIFEST_ARGS[@]}" &
         fi
 
         rm -f "$OUTPUT_FILE"
@@ -74,11 +76,13 @@ case "$SUBCOMMAND" in
             NAMESPACE=$(__devlog_extract_namespace "$@")
             RESOURCE_TYPE=$(__devlog_extract_resource_type "$@")
             RESOURCE_NAMES=$(__devlog_extract_resource_names "$@")
+            __devlog_manifest_args "$@"
 
             __devlog_capture_kubectl_event "delete" "$KUBECTL_CONTEXT" "$NAMESPACE" \
                 --resource-type="$RESOURCE_TYPE" \
                 --resource-names="$RESOURCE_NAMES" \
-                --exit-code="$EXIT_CODE" &
+                --exit-code="$EXIT_CODE" \
+                "${MANIFEST_ARGS[@]}" &
         fi
 
         rm -f "$OUTPUT_FILE"
@@ -3,9 +3,14 @@ package kubectl
 import (
 	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"devlog/internal/config"
 	"devlog/internal/events"
 	"devlog/internal/ingest"
+	"devlog/internal/vcs"
 
 	"github.com/urfave/cli/v2"
 )
@@ -25,6 +30,7 @@ func (h *IngestHandler) CLICommand() *cli.Command {
 			&cli.StringFlag{Name: "resource-names", Usage: "Resource names"},
 			&cli.StringFlag{Name: "resource-count", Usage: "Number of resources affected"},
 			&cli.StringFlag{Name: "workdir", Usage: "Working directory"},
+			&cli.StringSliceFlag{Name: "manifest", Usage: "Manifest file or directory passed with -f/-k (repeatable)"},
 			&cli.IntFlag{Name: "exit-code", Usage: "Command exit code", Value: 0},
 		},
 		Action: h.handle,
@@ -52,6 +58,9 @@ func (h *IngestHandler) handle(c *cli.Context) error {
 	if v := c.String("workdir"); v != "" {
 		args = append(args, "--workdir", v)
 	}
+	for _, m := range c.StringSlice("manifest") {
+		args = append(args, "--manifest", m)
+	}
 	if c.IsSet("exit-code") {
 		args = append(args, "--exit-code", c.String("exit-code"))
 	}
@@ -59,7 +68,44 @@ func (h *IngestHandler) handle(c *cli.Context) error {
 }
 
 func (h *IngestHandler) ingestEvent(args []string) error {
-	fs := flag.NewFlagSet("kubectl-event", flag.ExitOnError)
+	event, err := buildEvent(args)
+	if err != nil {
+		return err
+	}
+
+	var moduleCfg map[string]interface{}
+	if cfg, err := config.Load(); err == nil {
+		moduleCfg, _ = cfg.GetModuleConfig("kubectl")
+	}
+	classify(event, moduleCfg)
+
+	return ingest.SendEvent(event)
+}
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// payloadSchema is checked by the daemon when kubectl events are ingested.
+var payloadSchema = events.PayloadSchema{
+	Source: string(events.SourceKubectl),
+	Fields: map[string]events.PayloadField{
+		"context":   {Kind: events.KindString, Required: true},
+		"namespace": {Kind: events.KindString, Required: true},
+		"exit_code": {Kind: events.KindNumber, Required: true},
+		"manifests": {Kind: events.KindStringList},
+	},
+}
+
+func buildEvent(args []string) (*events.Event, error) {
+	fs := flag.NewFlagSet("kubectl-event", flag.ContinueOnError)
 	operation := fs.String("operation", "", "Operation type")
 	context := fs.String("context", "", "Kubectl context")
 	cluster := fs.String("cluster", "", "Cluster name")
@@ -69,11 +115,15 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	resourceCount := fs.String("resource-count", "", "Number of resources affected")
 	workdir := fs.String("workdir", "", "Working directory")
 	exitCode := fs.Int("exit-code", 0, "Command exit code")
+	var manifests stringList
+	fs.Var(&manifests, "manifest", "Manifest file or directory")
 
-	fs.Parse(args)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
 
 	if *operation == "" || *context == "" || *namespace == "" {
-		return fmt.Errorf("--operation, --context, and --namespace are required")
+		return nil, fmt.Errorf("--operation, --context, and --namespace are required")
 	}
 
 	var typeConstant string
@@ -99,7 +149,7 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	case "debug":
 		typeConstant = string(events.TypeKubectlDebug)
 	default:
-		return fmt.Errorf("unknown operation type: %s", *operation)
+		return nil, fmt.Errorf("unknown operation type: %s", *operation)
 	}
 
 	event := events.NewEvent(string(events.SourceKubectl), typeConstant)
@@ -122,15 +172,78 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 
 	if *workdir != "" {
 		event.Payload["workdir"] = *workdir
-		if repoPath, err := ingest.FindGitRepo(*workdir); err == nil {
-			event.Repo = repoPath
-			if branch, err := ingest.FindGitBranch(*workdir); err == nil {
-				event.Branch = branch
+		if repo, err := vcs.Detect(*workdir); err == nil {
+			event.Repo = repo.Name
+			event.Branch = repo.Branch
+			event.Payload["vcs"] = string(repo.Kind)
+			if repo.Remote != "" {
+				event.Payload[config.RemotePayloadKey] = repo.Remote
 			}
 		}
 	}
 
-	return ingest.SendEvent(event)
+	if len(manifests) > 0 {
+		attributeManifests(event, *workdir, manifests)
+	}
+
+	return event, nil
+}
+
+func attributeManifests(event *events.Event, workdir string, manifests []string) {
+	var paths []string
+	var manifestRepo *vcs.Repo
+
+	for _, m := range manifests {
+		m = strings.TrimSpace(m)
+		if m == "" || m == "-" {
+			continue
+		}
+		if strings.Contains(m, "://") {
+			paths = append(paths, m)
+			continue
+		}
+
+		path := m
+		if !filepath.IsAbs(path) && workdir != "" {
+			path = filepath.Join(workdir, path)
+		}
+		path = filepath.Clean(path)
+
+		if manifestRepo == nil {
+			dir := path
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				dir = filepath.Dir(path)
+			}
+			if repo, err := vcs.Detect(dir); err == nil {
+				manifestRepo = repo
+			}
+		}
+
+		paths = append(paths, path)
+	}
+
+	if len(paths) == 0 {
+		return
+	}
+
+	if manifestRepo != nil {
+		for i, path := range paths {
+			if rel, err := filepath.Rel(manifestRepo.Root, path); err == nil && !strings.HasPrefix(rel, "..") {
+				paths[i] = rel
+			}
+		}
+		event.Repo = manifestRepo.Name
+		event.Branch = manifestRepo.Branch
+		event.Payload["vcs"] = string(manifestRepo.Kind)
+		event.Payload["manifest_root"] = manifestRepo.Root
+		if manifestRepo.Remote != "" {
+			event.Payload[config.RemotePayloadKey] = manifestRepo.Remote
+		} else {
+			delete(event.Payload, config.RemotePayloadKey)
+		}
+	}
+
+	event.Payload["manifests"] = paths
 }
 
 func init() {
@@ -0,0 +1,247 @@
+package kubectl
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"devlog/internal/config"
+)
+
+func makeRepo(t *testing.T, branch string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/"+branch+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func setOrigin(t *testing.T, root, url string) {
+	t.Helper()
+	gitConfig := "[remote \"origin\"]\n\turl = " + url + "\n"
+	if err := os.WriteFile(filepath.Join(root, ".git", "config"), []byte(gitConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildEventRecordsRemote(t *testing.T) {
+	app := makeRepo(t, "main")
+	setOrigin(t, app, "git@github.com:acme/api.git")
+
+	event, err := buildEvent([]string{"--operation", "get", "--context", "prod", "--namespace", "api", "--workdir", app})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if got := event.Payload[config.RemotePayloadKey]; got != "github.com/acme/api" {
+		t.Errorf("%s = %v, want github.com/acme/api", config.RemotePayloadKey, got)
+	}
+
+	// Manifests from another repo attribute the event, and its remote, to
+	// that repo.
+	infra := makeRepo(t, "main")
+	setOrigin(t, infra, "https://github.com/acme/infra.git")
+	manifest := filepath.Join(infra, "deploy.yaml")
+	if err := os.WriteFile(manifest, []byte("kind: Deployment\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	event, err = buildEvent([]string{"--operation", "apply", "--context", "prod", "--namespace", "api", "--workdir", app, "--manifest", manifest})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if got := event.Payload[config.RemotePayloadKey]; got != "github.com/acme/infra" {
+		t.Errorf("%s = %v, want github.com/acme/infra", config.RemotePayloadKey, got)
+	}
+
+	// A manifest repo without an origin must not keep the workdir's remote.
+	local := makeRepo(t, "main")
+	manifest = filepath.Join(local, "deploy.yaml")
+	if err := os.WriteFile(manifest, []byte("kind: Deployment\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	event, err = buildEvent([]string{"--operation", "apply", "--context", "prod", "--namespace", "api", "--workdir", app, "--manifest", manifest})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if got, ok := event.Payload[config.RemotePayloadKey]; ok {
+		t.Errorf("%s = %v, want none for a repo without an origin", config.RemotePayloadKey, got)
+	}
+}
+
+func TestBuildEventAttributesManifestRepo(t *testing.T) {
+	infra := makeRepo(t, "deploy-api")
+	if err := os.MkdirAll(filepath.Join(infra, "k8s", "overlays", "prod"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(infra, "k8s", "api.yaml"), []byte("kind: Deployment\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := makeRepo(t, "main")
+
+	event, err := buildEvent([]string{
+		"--operation", "apply",
+		"--context", "prod",
+		"--namespace", "api",
+		"--workdir", app,
+		"--manifest", filepath.Join(infra, "k8s", "api.yaml"),
+		"--manifest", filepath.Join(infra, "k8s", "overlays", "prod"),
+		"--manifest", "-",
+		"--manifest", "https://example.com/crd.yaml",
+	})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if err := event.ValidatePayload(); err != nil {
+		t.Errorf("event does not match its payload schema: %v", err)
+	}
+
+	if event.Repo != filepath.Base(infra) || event.Branch != "deploy-api" {
+		t.Errorf("repo = %q branch = %q, want %q deploy-api", event.Repo, event.Branch, filepath.Base(infra))
+	}
+	if event.Payload["workdir"] != app {
+		t.Errorf("workdir = %v, want %s", event.Payload["workdir"], app)
+	}
+	if event.Payload["manifest_root"] != infra {
+		t.Errorf("manifest_root = %v, want %s", event.Payload["manifest_root"], infra)
+	}
+	want := []string{"k8s/api.yaml", "k8s/overlays/prod", "https://example.com/crd.yaml"}
+	if got := event.Payload["manifests"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("manifests = %v, want %v", got, want)
+	}
+
+	if got := (&KubectlFormatter{}).Format(event); got != "apply -f k8s/api.yaml +2 -n api @prod" {
+		t.Errorf("Format() = %q", got)
+	}
+}
+
+func TestBuildEventRelativeManifest(t *testing.T) {
+	repo := makeRepo(t, "main")
+	if err := os.WriteFile(filepath.Join(repo, "svc.yaml"), []byte("kind: Service\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := buildEvent([]string{
+		"--operation", "delete",
+		"--context", "dev",
+		"--namespace", "default",
+		"--workdir", repo,
+		"--manifest", "svc.yaml",
+	})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if event.Type != "kubectl_delete" || event.Repo != filepath.Base(repo) {
+		t.Errorf("got %s in repo %q", event.Type, event.Repo)
+	}
+	if got := event.Payload["manifests"]; !reflect.DeepEqual(got, []string{"svc.yaml"}) {
+		t.Errorf("manifests = %v, want [svc.yaml]", got)
+	}
+
+	plain, err := buildEvent([]string{"--operation", "get", "--context", "dev", "--namespace", "default"})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if _, ok := plain.Payload["manifests"]; ok {
+		t.Error("event without -f should not record manifests")
+	}
+
+	if _, err := buildEvent([]string{"--operation", "apply"}); err == nil {
+		t.Error("buildEvent() expected error when context and namespace are missing")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cfg := map[string]interface{}{
+		"environments": map[string]interface{}{
+			"prod":    []interface{}{"arn:aws:eks:*:cluster/payments-*", "prod-*"},
+			"staging": []interface{}{"stg-*"},
+			"dev":     "kind-*",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		args        []string
+		wantEnv     string
+		wantDanger  string
+		wantSummary string
+	}{
+		{
+			name:        "prod delete is critical",
+			args:        []string{"--operation", "delete", "--context", "prod-eu", "--namespace", "api", "--resource-type", "pod", "--resource-names", "api-1"},
+			wantEnv:     "prod",
+			wantDanger:  DangerCritical,
+			wantSummary: "delete pod/api-1 -n api @prod-eu [prod, danger:critical]",
+		},
+		{
+			name:       "prod matched by cluster",
+			args:       []string{"--operation", "apply", "--context", "payments", "--cluster", "arn:aws:eks:us-east-1:1:cluster/payments-main", "--namespace", "api"},
+			wantEnv:    "prod",
+			wantDanger: DangerHigh,
+		},
+		{
+			name:       "prod reads stay low",
+			args:       []string{"--operation", "logs", "--context", "prod-eu", "--namespace", "api"},
+			wantEnv:    "prod",
+			wantDanger: DangerLow,
+		},
+		{
+			name:        "staging apply",
+			args:        []string{"--operation", "apply", "--context", "stg-1", "--namespace", "api"},
+			wantEnv:     "staging",
+			wantDanger:  DangerMedium,
+			wantSummary: "apply -n api @stg-1 [staging]",
+		},
+		{
+			name:       "unknown context",
+			args:       []string{"--operation", "delete", "--context", "scratch", "--namespace", "default"},
+			wantDanger: DangerHigh,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := buildEvent(tt.args)
+			if err != nil {
+				t.Fatalf("buildEvent() error: %v", err)
+			}
+			classify(event, cfg)
+
+			if env, _ := event.Payload["environment"].(string); env != tt.wantEnv {
+				t.Errorf("environment = %q, want %q", env, tt.wantEnv)
+			}
+			if event.Payload["danger_level"] != tt.wantDanger {
+				t.Errorf("danger_level = %v, want %s", event.Payload["danger_level"], tt.wantDanger)
+			}
+			if tt.wantSummary != "" {
+				if got := (&KubectlFormatter{}).Format(event); got != tt.wantSummary {
+					t.Errorf("Format() = %q, want %q", got, tt.wantSummary)
+				}
+			}
+		})
+	}
+}
+
+func TestClassifyDefaults(t *testing.T) {
+	event, err := buildEvent([]string{"--operation", "exec", "--context", "gke_acme_us-central1_production", "--namespace", "api"})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	classify(event, nil)
+	if event.Payload["environment"] != "prod" || event.Payload["danger_level"] != DangerHigh {
+		t.Errorf("payload = %v, want prod/high from default patterns", event.Payload)
+	}
+
+	if err := (&Module{}).ValidateConfig((&Module{}).DefaultConfig()); err != nil {
+		t.Errorf("default config invalid: %v", err)
+	}
+	bad := map[string]interface{}{"environments": map[string]interface{}{"prod": []interface{}{"[prod"}}}
+	if err := (&Module{}).ValidateConfig(bad); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
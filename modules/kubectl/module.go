@@ -1,21 +1,20 @@
 package kubectl
 
 import (
-	_ "embed"
+	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
 
+	"devlog/internal/assets"
 	"devlog/internal/config"
+	"devlog/internal/events"
 	"devlog/internal/install"
 	"devlog/internal/modules"
 )
 
-//go:embed hooks/kubectl-wrapper.sh
-var kubectlWrapperScript string
-
-//go:embed hooks/devlog-kubectl-common.sh
-var kubectlCommonLib string
+//go:embed hooks
+var hooksFS embed.FS
 
 type Module struct{}
 
@@ -45,7 +44,7 @@ func (m *Module) Install(ctx *install.Context) error {
 	}
 
 	commonLibPath := filepath.Join(binDir, "devlog-kubectl-common.sh")
-	if err := os.WriteFile(commonLibPath, []byte(kubectlCommonLib), 0644); err != nil {
+	if err := ctx.WriteAsset("kubectl", "devlog-kubectl-common.sh", commonLibPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "kubectl wrapper",
 			File:      commonLibPath,
@@ -59,7 +58,7 @@ func (m *Module) Install(ctx *install.Context) error {
 	}
 
 	wrapperPath := filepath.Join(binDir, "kubectl")
-	if err := os.WriteFile(wrapperPath, []byte(kubectlWrapperScript), 0755); err != nil {
+	if err := ctx.WriteAsset("kubectl", "kubectl-wrapper.sh", wrapperPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "kubectl wrapper",
 			File:      wrapperPath,
@@ -73,7 +72,7 @@ func (m *Module) Install(ctx *install.Context) error {
 	}
 
 	kWrapperPath := filepath.Join(binDir, "k")
-	if err := os.WriteFile(kWrapperPath, []byte(kubectlWrapperScript), 0755); err != nil {
+	if err := ctx.WriteAsset("kubectl", "kubectl-wrapper.sh", kWrapperPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "k alias wrapper",
 			File:      kWrapperPath,
@@ -118,7 +117,7 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 
 	commonLibPath := filepath.Join(binDir, "devlog-kubectl-common.sh")
 	if _, err := os.Stat(commonLibPath); err == nil {
-		if err := os.Remove(commonLibPath); err != nil {
+		if err := ctx.RemoveAsset(commonLibPath); err != nil {
 			return fmt.Errorf("remove common library: %w", err)
 		}
 		ctx.Log("✓ Removed shared library from %s", commonLibPath)
@@ -126,9 +125,8 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 
 	wrapperPath := filepath.Join(binDir, "kubectl")
 	if _, err := os.Stat(wrapperPath); err == nil {
-		content, err := os.ReadFile(wrapperPath)
-		if err == nil && string(content) == kubectlWrapperScript {
-			if err := os.Remove(wrapperPath); err != nil {
+		if ctx.OwnsAsset("kubectl", "kubectl-wrapper.sh", wrapperPath) {
+			if err := ctx.RemoveAsset(wrapperPath); err != nil {
 				return fmt.Errorf("remove kubectl wrapper: %w", err)
 			}
 			ctx.Log("✓ Removed kubectl wrapper from %s", wrapperPath)
@@ -139,9 +137,8 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 
 	kWrapperPath := filepath.Join(binDir, "k")
 	if _, err := os.Stat(kWrapperPath); err == nil {
-		content, err := os.ReadFile(kWrapperPath)
-		if err == nil && string(content) == kubectlWrapperScript {
-			if err := os.Remove(kWrapperPath); err != nil {
+		if ctx.OwnsAsset("kubectl", "kubectl-wrapper.sh", kWrapperPath) {
+			if err := ctx.RemoveAsset(kWrapperPath); err != nil {
 				return fmt.Errorf("remove k wrapper: %w", err)
 			}
 			ctx.Log("✓ Removed k alias wrapper from %s", kWrapperPath)
@@ -162,13 +159,29 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 }
 
 func (m *Module) DefaultConfig() interface{} {
-	return map[string]interface{}{}
+	return map[string]interface{}{
+		"environments": defaultEnvironments(),
+	}
+}
+
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources: []string{string(events.SourceKubectl)},
+		Checks:  []modules.Check{modules.WrapperCheck(ctx, "kubectl")},
+	}
 }
 
 func (m *Module) ValidateConfig(config interface{}) error {
-	return nil
+	cfg, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config must be a map")
+	}
+	_, err := parseEnvironments(cfg)
+	return err
 }
 
 func init() {
+	assets.RegisterFS("kubectl", hooksFS, "hooks", "kubectl-wrapper.sh")
+	events.RegisterPayloadSchema(payloadSchema)
 	modules.Register(&Module{})
 }
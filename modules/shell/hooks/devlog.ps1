@@ -0,0 +1,65 @@
+# devlog shell integration for PowerShell (Windows PowerShell 5.1 and pwsh)
+
+if ($env:DEVLOG_SHELL_ENABLED -and $env:DEVLOG_SHELL_ENABLED -ne 'true') { return }
+
+# Dot-sourcing the profile twice must not wrap the prompt twice.
+if ($global:__devlogOriginalPrompt) { return }
+
+$__devlogBin = if ($env:DEVLOG_BIN) { $env:DEVLOG_BIN } else { 'devlog' }
+$__devlogApp = Get-Command $__devlogBin -CommandType Application -ErrorAction SilentlyContinue | Select-Object -First 1
+if (-not $__devlogApp) { return }
+
+$global:__devlogBinPath = $__devlogApp.Path
+$global:__devlogLastHistoryId = (Get-History -Count 1).Id
+$global:__devlogOriginalPrompt = $function:prompt
+
+# Quotes an argument so the Windows command-line parser, which Go and .NET
+# both follow, reads it back unchanged.
+function global:__devlog_quote([string]$arg) {
+    '"' + (($arg -replace '(\\*)"', '$1$1\"') -replace '(\\+)$', '$1$1') + '"'
+}
+
+function global:__devlog_send($entry, [bool]$succeeded, $lastExitCode) {
+    $command = $entry.CommandLine
+    if (-not $command -or -not $command.Trim()) { return }
+
+    # Skip devlog daemon control commands, like the bash and zsh hooks.
+    if ($command -match '(^|\s)devlog(\.exe)?\s+daemon\s+(start|stop|restart|status)') { return }
+
+    $exitCode = 0
+    if (-not $succeeded) {
+        $exitCode = if ($lastExitCode) { $lastExitCode } else { 1 }
+    }
+    $duration = [int64]($entry.EndExecutionTime - $entry.StartExecutionTime).TotalMilliseconds
+
+    $arguments = @(
+        'ingest', 'shell-command',
+        "--command=$command",
+        "--exit-code=$exitCode",
+        "--workdir=$($PWD.ProviderPath)",
+        "--duration=$duration"
+    )
+    $info = New-Object System.Diagnostics.ProcessStartInfo $global:__devlogBinPath
+    $info.Arguments = ($arguments | ForEach-Object { __devlog_quote $_ }) -join ' '
+    $info.UseShellExecute = $false
+    $info.CreateNoWindow = $true
+    try {
+        [System.Diagnostics.Process]::Start($info) | Out-Null
+    } catch {
+    }
+}
+
+function global:prompt {
+    # Read the status of the last command before anything here resets it.
+    $succeeded = $global:?
+    $lastExitCode = $global:LASTEXITCODE
+
+    $entry = Get-History -Count 1
+    if ($entry -and $entry.Id -ne $global:__devlogLastHistoryId) {
+        $global:__devlogLastHistoryId = $entry.Id
+        __devlog_send $entry $succeeded $lastExitCode
+    }
+
+    $global:LASTEXITCODE = $lastExitCode
+    & $global:__devlogOriginalPrompt
+}
@@ -7,6 +7,7 @@ import (
 	"devlog/internal/config"
 	"devlog/internal/events"
 	"devlog/internal/ingest"
+	"devlog/internal/vcs"
 
 	"github.com/urfave/cli/v2"
 )
@@ -45,6 +46,19 @@ func (h *IngestHandler) handle(c *cli.Context) error {
 	return h.ingestEvent(args)
 }
 
+// payloadSchema is checked by the daemon when command events are ingested.
+var payloadSchema = events.PayloadSchema{
+	Source: string(events.SourceShell),
+	Types:  []events.EventType{events.TypeCommand},
+	Fields: map[string]events.PayloadField{
+		"command":     {Kind: events.KindString, Required: true},
+		"exit_code":   {Kind: events.KindNumber, Required: true},
+		"workdir":     {Kind: events.KindString},
+		"duration_ms": {Kind: events.KindNumber},
+		"output":      {Kind: events.KindString},
+	},
+}
+
 func (h *IngestHandler) ingestEvent(args []string) error {
 	fs := flag.NewFlagSet("shell-command", flag.ExitOnError)
 	command := fs.String("command", "", "The shell command")
@@ -59,11 +73,15 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 		return fmt.Errorf("--command is required")
 	}
 
+	var outputCfg OutputConfig
 	cfg, err := config.Load()
 	if err == nil {
 		if !cfg.ShouldCaptureCommand(*command) {
 			return nil
 		}
+		if shellCfg, ok := cfg.GetModuleConfig("shell"); ok {
+			outputCfg = parseOutputConfig(shellCfg)
+		}
 	}
 
 	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
@@ -72,8 +90,13 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 
 	if *workdir != "" {
 		event.Payload["workdir"] = *workdir
-		if repoPath, err := ingest.FindGitRepo(*workdir); err == nil {
-			event.Repo = repoPath
+		if repo, err := vcs.Detect(*workdir); err == nil {
+			event.Repo = repo.Name
+			event.Branch = repo.Branch
+			event.Payload["vcs"] = string(repo.Kind)
+			if repo.Remote != "" {
+				event.Payload[config.RemotePayloadKey] = repo.Remote
+			}
 		}
 	}
 
@@ -83,6 +106,15 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 
 	if *duration > 0 {
 		event.Payload["duration_ms"] = *duration
+		event.DurationMs = *duration
+	}
+	if *exitCode != 0 {
+		event.Severity = string(events.SeverityError)
+		if outputCfg.Enabled {
+			if out := captureOutput(outputCfg, *command, detectScreenReader()); out != "" {
+				event.Payload["output"] = out
+			}
+		}
 	}
 
 	return ingest.SendEvent(event)
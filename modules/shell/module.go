@@ -1,18 +1,24 @@
 package shell
 
 import (
-	_ "embed"
+	"embed"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
+	"devlog/internal/assets"
+	"devlog/internal/config"
 	"devlog/internal/configfile"
+	"devlog/internal/events"
 	"devlog/internal/install"
 	"devlog/internal/modules"
 )
 
-//go:embed hooks/devlog.sh
-var devlogShellScript string
+//go:embed hooks/devlog.sh hooks/devlog.ps1
+var hooksFS embed.FS
 
 type Module struct{}
 
@@ -27,11 +33,7 @@ func (m *Module) Description() string {
 func (m *Module) Install(ctx *install.Context) error {
 	ctx.Log("Installing shell hooks...")
 
-	shellEnv := os.Getenv("SHELL")
-	currentShell := "unknown"
-	if shellEnv != "" {
-		currentShell = filepath.Base(shellEnv)
-	}
+	currentShell := detectShell()
 
 	ctx.Log("Current shell: %s", currentShell)
 	ctx.Log("")
@@ -50,8 +52,12 @@ func (m *Module) Install(ctx *install.Context) error {
 		}
 	}
 
-	scriptPath := filepath.Join(hooksDir, "devlog.sh")
-	if err := os.WriteFile(scriptPath, []byte(devlogShellScript), 0644); err != nil {
+	scriptName := "devlog.sh"
+	if currentShell == "powershell" {
+		scriptName = "devlog.ps1"
+	}
+	scriptPath := filepath.Join(hooksDir, scriptName)
+	if err := ctx.WriteAsset("shell", scriptName, scriptPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "shell integration",
 			File:      scriptPath,
@@ -69,6 +75,18 @@ func (m *Module) Install(ctx *install.Context) error {
 	sourceLine := fmt.Sprintf(`source "%s"`, scriptPath)
 
 	switch currentShell {
+	case "powershell":
+		// Single quotes keep PowerShell from expanding $ in the path.
+		sourceLine = fmt.Sprintf(". '%s'", strings.ReplaceAll(scriptPath, "'", "''"))
+		if err := m.installPowerShell(ctx, sourceLine); err != nil {
+			return err
+		}
+		ctx.Log("")
+		ctx.Log("Installation complete!")
+		ctx.Log("")
+		ctx.Log("To activate the hooks, open a new PowerShell window or run: . $PROFILE")
+		ctx.Log("")
+		return nil
 	case "bash":
 		if err := m.installBash(ctx, sourceLine); err != nil {
 			return err
@@ -123,6 +141,65 @@ func (m *Module) installZsh(ctx *install.Context, sourceLine string) error {
 	return m.addToRcFile(ctx, rcFile, sourceLine)
 }
 
+// installPowerShell adds the hook to the profile of every PowerShell
+// edition the user may start: pwsh and, on Windows, Windows PowerShell.
+func (m *Module) installPowerShell(ctx *install.Context, sourceLine string) error {
+	ctx.Log("Installing for PowerShell...")
+	for _, profile := range powershellProfiles(runtime.GOOS, ctx.HomeDir) {
+		if err := os.MkdirAll(filepath.Dir(profile), 0755); err != nil {
+			return &modules.InstallError{
+				Component: "shell integration",
+				File:      profile,
+				Err:       err,
+				RecoverySteps: []string{
+					fmt.Sprintf("Create the profile directory: New-Item -ItemType Directory -Force '%s'", filepath.Dir(profile)),
+					fmt.Sprintf("Or add this line to $PROFILE manually: %s", sourceLine),
+				},
+			}
+		}
+		if err := m.addToRcFile(ctx, profile, sourceLine); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// detectShell names the user's shell from $SHELL. Windows does not set it,
+// so there the shell is PowerShell.
+func detectShell() string {
+	return shellName(os.Getenv("SHELL"), runtime.GOOS)
+}
+
+func shellName(shellEnv, goos string) string {
+	if shellEnv == "" {
+		if goos == "windows" {
+			return "powershell"
+		}
+		return "unknown"
+	}
+	name := strings.TrimSuffix(filepath.Base(shellEnv), ".exe")
+	if name == "pwsh" {
+		return "powershell"
+	}
+	return name
+}
+
+// powershellProfiles lists the current-user, current-host profiles that
+// $PROFILE points to: pwsh keeps its own under Documents\PowerShell on
+// Windows and ~/.config/powershell elsewhere, and Windows PowerShell 5.1
+// uses Documents\WindowsPowerShell.
+func powershellProfiles(goos, homeDir string) []string {
+	const profileName = "Microsoft.PowerShell_profile.ps1"
+	if goos != "windows" {
+		return []string{filepath.Join(homeDir, ".config", "powershell", profileName)}
+	}
+	documents := filepath.Join(homeDir, "Documents")
+	return []string{
+		filepath.Join(documents, "PowerShell", profileName),
+		filepath.Join(documents, "WindowsPowerShell", profileName),
+	}
+}
+
 func (m *Module) addToRcFile(ctx *install.Context, rcFile string, sourceLine string) error {
 	cfgMgr := configfile.NewFileSystemManager(".backup.devlog")
 
@@ -163,26 +240,57 @@ func (m *Module) addToRcFile(ctx *install.Context, rcFile string, sourceLine str
 	return nil
 }
 
-func (m *Module) Uninstall(ctx *install.Context) error {
-	ctx.Log("Uninstalling shell hooks...")
+func (m *Module) Check(ctx *install.Context) []modules.Check {
+	var rcFiles []string
+	switch detectShell() {
+	case "powershell":
+		rcFiles = powershellProfiles(runtime.GOOS, ctx.HomeDir)
+	case "bash":
+		rcFiles = []string{filepath.Join(ctx.HomeDir, ".bash_profile"), filepath.Join(ctx.HomeDir, ".bashrc")}
+	case "zsh":
+		rcFiles = []string{filepath.Join(ctx.HomeDir, ".zshrc")}
+	default:
+		return nil
+	}
 
-	scriptPath := filepath.Join(ctx.DataDir, "hooks", "devlog.sh")
-	if _, err := os.Stat(scriptPath); err == nil {
-		if err := os.Remove(scriptPath); err != nil {
-			return fmt.Errorf("remove devlog.sh: %w", err)
+	cfgMgr := configfile.NewFileSystemManager(".backup.devlog")
+	for _, rcFile := range rcFiles {
+		hasSection, err := cfgMgr.HasSection(rcFile, "devlog shell integration")
+		if err != nil {
+			return []modules.Check{{Name: "rc file", Message: err.Error()}}
+		}
+		if hasSection {
+			return []modules.Check{{Name: "rc file", OK: true, Message: fmt.Sprintf("hook sourced from %s", rcFile)}}
 		}
-		ctx.Log("✓ Removed %s", scriptPath)
 	}
 
-	shellEnv := os.Getenv("SHELL")
-	currentShell := "unknown"
-	if shellEnv != "" {
-		currentShell = filepath.Base(shellEnv)
+	return []modules.Check{{
+		Name:    "rc file",
+		Message: fmt.Sprintf("devlog shell integration section not found in %s", rcFiles[len(rcFiles)-1]),
+	}}
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling shell hooks...")
+
+	for _, scriptName := range []string{"devlog.sh", "devlog.ps1"} {
+		scriptPath := filepath.Join(ctx.DataDir, "hooks", scriptName)
+		if _, err := os.Stat(scriptPath); err == nil {
+			if err := ctx.RemoveAsset(scriptPath); err != nil {
+				return fmt.Errorf("remove %s: %w", scriptName, err)
+			}
+			ctx.Log("✓ Removed %s", scriptPath)
+		}
 	}
 
 	ctx.Log("")
 
-	switch currentShell {
+	switch detectShell() {
+	case "powershell":
+		ctx.Log("Checking PowerShell profiles...")
+		for _, profile := range powershellProfiles(runtime.GOOS, ctx.HomeDir) {
+			m.removeFromRcFile(ctx, profile)
+		}
 	case "bash":
 		m.uninstallBash(ctx)
 	case "zsh":
@@ -191,6 +299,7 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 		ctx.Log("Please manually remove the 'devlog shell integration' section from your shell RC files:")
 		ctx.Log("  ~/.bashrc or ~/.bash_profile (for Bash)")
 		ctx.Log("  ~/.zshrc (for Zsh)")
+		ctx.Log("  $PROFILE (for PowerShell)")
 	}
 
 	return nil
@@ -242,8 +351,15 @@ func (m *Module) DefaultConfig() interface{} {
 	}
 }
 
-func (m *Module) ValidateConfig(config interface{}) error {
-	cfg, ok := config.(map[string]interface{})
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources:    []string{string(events.SourceShell)},
+		QuietAfter: 3 * 24 * time.Hour,
+	}
+}
+
+func (m *Module) ValidateConfig(cfgValue interface{}) error {
+	cfg, ok := cfgValue.(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("config must be a map")
 	}
@@ -254,15 +370,21 @@ func (m *Module) ValidateConfig(config interface{}) error {
 			return fmt.Errorf("ignore_list must be an array of strings")
 		}
 		for i, item := range ignoreSlice {
-			if _, ok := item.(string); !ok {
+			pattern, ok := item.(string)
+			if !ok {
 				return fmt.Errorf("ignore_list[%d] must be a string", i)
 			}
+			if err := config.ValidateIgnorePattern(pattern); err != nil {
+				return fmt.Errorf("ignore_list[%d]: %w", i, err)
+			}
 		}
 	}
 
-	return nil
+	return validateOutputConfig(cfg)
 }
 
 func init() {
+	assets.RegisterFS("shell", hooksFS, "hooks")
+	events.RegisterPayloadSchema(payloadSchema)
 	modules.Register(&Module{})
 }
@@ -0,0 +1,155 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"devlog/internal/install"
+)
+
+func TestShellName(t *testing.T) {
+	tests := []struct {
+		shell string
+		goos  string
+		want  string
+	}{
+		{"/bin/bash", "linux", "bash"},
+		{"/bin/zsh", "darwin", "zsh"},
+		{"/usr/bin/pwsh", "linux", "powershell"},
+		{"C:/Program Files/PowerShell/7/pwsh.exe", "windows", "powershell"},
+		{"", "windows", "powershell"},
+		{"", "linux", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := shellName(tt.shell, tt.goos); got != tt.want {
+			t.Errorf("shellName(%q, %q) = %q, want %q", tt.shell, tt.goos, got, tt.want)
+		}
+	}
+}
+
+func TestPowershellProfiles(t *testing.T) {
+	home := filepath.Join("home", "ada")
+	const profile = "Microsoft.PowerShell_profile.ps1"
+	tests := []struct {
+		goos string
+		want []string
+	}{
+		{"linux", []string{filepath.Join(home, ".config", "powershell", profile)}},
+		{"darwin", []string{filepath.Join(home, ".config", "powershell", profile)}},
+		{"windows", []string{
+			filepath.Join(home, "Documents", "PowerShell", profile),
+			filepath.Join(home, "Documents", "WindowsPowerShell", profile),
+		}},
+	}
+	for _, tt := range tests {
+		if got := powershellProfiles(tt.goos, home); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("powershellProfiles(%q) = %q, want %q", tt.goos, got, tt.want)
+		}
+	}
+}
+
+func TestPowershellInstallUninstall(t *testing.T) {
+	t.Setenv("SHELL", "/usr/bin/pwsh")
+	// The apostrophe checks that the profile quotes the hook path.
+	dataDir := filepath.Join(t.TempDir(), "o'brien")
+	ctx := &install.Context{DataDir: dataDir, HomeDir: t.TempDir(), Version: "1.0.0", Log: func(string, ...interface{}) {}}
+	m := &Module{}
+
+	profiles := powershellProfiles(runtime.GOOS, ctx.HomeDir)
+	existing := "Set-PSReadLineOption -EditMode Emacs\n"
+	if err := os.MkdirAll(filepath.Dir(profiles[0]), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(profiles[0], []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Install(ctx); err != nil {
+		t.Fatalf("Install() error: %v", err)
+	}
+	scriptPath := filepath.Join(dataDir, "hooks", "devlog.ps1")
+	if _, err := os.Stat(scriptPath); err != nil {
+		t.Fatalf("hook script not written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "hooks", "devlog.sh")); !os.IsNotExist(err) {
+		t.Errorf("PowerShell install wrote the POSIX hook: %v", err)
+	}
+	sourceLine := ". '" + strings.ReplaceAll(scriptPath, "'", "''") + "'"
+	for _, profile := range profiles {
+		data, err := os.ReadFile(profile)
+		if err != nil {
+			t.Fatalf("read %s: %v", profile, err)
+		}
+		if !strings.Contains(string(data), "# devlog shell integration\n"+sourceLine+"\n") {
+			t.Errorf("%s = %q, want it to dot-source %s", profile, data, sourceLine)
+		}
+	}
+
+	// Installing again must not source the hook twice.
+	if err := m.Install(ctx); err != nil {
+		t.Fatalf("second Install() error: %v", err)
+	}
+	if data, _ := os.ReadFile(profiles[0]); strings.Count(string(data), sourceLine) != 1 {
+		t.Errorf("reinstall added the hook again: %q", data)
+	}
+	if checks := m.Check(ctx); len(checks) != 1 || !checks[0].OK {
+		t.Errorf("Check() = %+v, want the profile reported as hooked", checks)
+	}
+
+	if err := m.Uninstall(ctx); err != nil {
+		t.Fatalf("Uninstall() error: %v", err)
+	}
+	if _, err := os.Stat(scriptPath); !os.IsNotExist(err) {
+		t.Errorf("Uninstall() left the hook script: %v", err)
+	}
+	// Like the bash and zsh uninstall, only the marker goes; the source
+	// line is left for the user to delete.
+	if data, _ := os.ReadFile(profiles[0]); !strings.HasPrefix(string(data), existing) || strings.Contains(string(data), "# devlog shell integration") {
+		t.Errorf("profile after Uninstall() = %q, want the marker removed and %q kept", data, existing)
+	}
+	if checks := m.Check(ctx); len(checks) != 1 || checks[0].OK {
+		t.Errorf("Check() after Uninstall() = %+v, want the profile reported as not hooked", checks)
+	}
+}
+
+// TestPowershellHookSkipsDaemonCommands checks the hook's filter, which is
+// written in a regex syntax Go shares with .NET.
+func TestPowershellHookSkipsDaemonCommands(t *testing.T) {
+	hook, err := hooksFS.ReadFile("hooks/devlog.ps1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`\$command -match '([^']+)'`).FindSubmatch(hook)
+	if match == nil {
+		t.Fatal("devlog.ps1 has no daemon command filter")
+	}
+	skip := regexp.MustCompile(string(match[1]))
+
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"devlog daemon start", true},
+		{"devlog.exe daemon stop", true},
+		{"  devlog daemon status", true},
+		{"devlog status", false},
+		{"devlog daemon install-service", false},
+		{"go test ./...", false},
+	}
+	for _, tt := range tests {
+		if got := skip.MatchString(tt.command); got != tt.want {
+			t.Errorf("hook skips %q = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+
+	for _, want := range []string{"'ingest', 'shell-command'", "$global:LASTEXITCODE = $lastExitCode", "if ($global:__devlogOriginalPrompt) { return }"} {
+		if !strings.Contains(string(hook), want) {
+			t.Errorf("devlog.ps1 does not contain %q", want)
+		}
+	}
+}
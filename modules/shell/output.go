@@ -0,0 +1,192 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"devlog/internal/secrets"
+)
+
+const (
+	defaultOutputMaxLines = 40
+	defaultOutputMaxBytes = 4096
+	// screenHistoryLines is how far back the screen is read to find the
+	// command line that started the output.
+	screenHistoryLines = 1000
+	screenReadTimeout  = 2 * time.Second
+)
+
+// OutputConfig is the optional capture_output block of the shell module
+// config.
+type OutputConfig struct {
+	Enabled  bool
+	MaxLines int
+	MaxBytes int
+}
+
+func parseOutputConfig(config map[string]interface{}) OutputConfig {
+	cfg := OutputConfig{
+		MaxLines: defaultOutputMaxLines,
+		MaxBytes: defaultOutputMaxBytes,
+	}
+
+	raw, ok := config["capture_output"].(map[string]interface{})
+	if !ok {
+		return cfg
+	}
+
+	cfg.Enabled, _ = raw["enabled"].(bool)
+	if n, ok := raw["max_lines"].(int); ok {
+		cfg.MaxLines = n
+	}
+	if n, ok := raw["max_bytes"].(int); ok {
+		cfg.MaxBytes = n
+	}
+	return cfg
+}
+
+func validateOutputConfig(config map[string]interface{}) error {
+	val, ok := config["capture_output"]
+	if !ok || val == nil {
+		return nil
+	}
+	raw, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("capture_output must be a map")
+	}
+	if v, ok := raw["enabled"]; ok {
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("capture_output.enabled must be a boolean")
+		}
+	}
+	for _, key := range []string{"max_lines", "max_bytes"} {
+		v, ok := raw[key]
+		if !ok {
+			continue
+		}
+		if n, ok := v.(int); !ok || n <= 0 {
+			return fmt.Errorf("capture_output.%s must be a positive number", key)
+		}
+	}
+	return nil
+}
+
+// screenReader returns recent lines of the terminal the shell runs in.
+// exact is set when the terminal itself knows where the last command's
+// output starts and ends, so the text is that output alone.
+type screenReader func(ctx context.Context, lines int) (text string, exact bool, err error)
+
+// detectScreenReader picks the terminal multiplexer or emulator that can
+// read back the shell's screen, from the variables they set in the
+// environment the hook runs the ingest command in. Plain terminals keep no
+// scrollback a program can read, so there output is not captured.
+func detectScreenReader() screenReader {
+	if pane := os.Getenv("TMUX_PANE"); pane != "" && os.Getenv("TMUX") != "" {
+		return func(ctx context.Context, lines int) (string, bool, error) {
+			out, err := exec.CommandContext(ctx, "tmux", "capture-pane", "-p", "-J", "-t", pane, "-S", fmt.Sprintf("-%d", lines)).Output()
+			return string(out), false, err
+		}
+	}
+	if window := os.Getenv("KITTY_WINDOW_ID"); window != "" {
+		// kitty's shell integration marks command output, so remote
+		// control can return exactly the last command's.
+		return func(ctx context.Context, lines int) (string, bool, error) {
+			out, err := exec.CommandContext(ctx, "kitty", "@", "get-text", "--match", "id:"+window, "--extent", "last_cmd_output").Output()
+			return string(out), true, err
+		}
+	}
+	if pane := os.Getenv("WEZTERM_PANE"); pane != "" {
+		return func(ctx context.Context, lines int) (string, bool, error) {
+			out, err := exec.CommandContext(ctx, "wezterm", "cli", "get-text", "--pane-id", pane, "--start-line", fmt.Sprintf("-%d", lines)).Output()
+			return string(out), false, err
+		}
+	}
+	return nil
+}
+
+// captureOutput reads the output of the command that just failed back
+// from the screen, keeping its last lines with secrets redacted. It
+// returns "" when the terminal cannot be read or the output is not found.
+func captureOutput(cfg OutputConfig, command string, read screenReader) string {
+	if read == nil {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), screenReadTimeout)
+	defer cancel()
+
+	screen, exact, err := read(ctx, screenHistoryLines)
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.ReplaceAll(screen, "\r\n", "\n"), "\n")
+	if !exact {
+		lines = commandOutput(lines, command)
+	}
+	return trimOutput(lines, cfg)
+}
+
+// commandOutput finds the output of command in the screen lines: the lines
+// after the last one ending with the command as typed, up to the next
+// prompt. A prompt is recognised by starting like the command's own prompt
+// line did, which holds for single-line prompts. nil means the command is
+// not on screen.
+func commandOutput(lines []string, command string) []string {
+	first, _, _ := strings.Cut(strings.TrimSpace(command), "\n")
+	if first == "" {
+		return nil
+	}
+
+	start := -1
+	var prompt string
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimRight(lines[i], " ")
+		if strings.HasSuffix(line, first) {
+			start = i
+			prompt = strings.TrimSpace(strings.TrimSuffix(line, first))
+			break
+		}
+	}
+	if start < 0 {
+		return nil
+	}
+
+	out := lines[start+1:]
+	if prompt != "" {
+		for i, line := range out {
+			if strings.HasPrefix(strings.TrimSpace(line), prompt) {
+				out = out[:i]
+				break
+			}
+		}
+	}
+	return out
+}
+
+// trimOutput drops blank lines around the output, keeps its last MaxLines
+// lines, redacts them, and caps them at MaxBytes. Errors are printed last,
+// so it is always the start that goes.
+func trimOutput(lines []string, cfg OutputConfig) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > cfg.MaxLines {
+		lines = lines[len(lines)-cfg.MaxLines:]
+	}
+	text := secrets.Redact(strings.Join(lines, "\n"))
+	if len(text) > cfg.MaxBytes {
+		cut := min(len(text)-cfg.MaxBytes+len("..."), len(text))
+		for cut < len(text) && !utf8.RuneStart(text[cut]) {
+			cut++
+		}
+		text = "..." + text[cut:]
+	}
+	return text
+}
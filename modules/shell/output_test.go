@@ -0,0 +1,87 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCommandOutput(t *testing.T) {
+	screen := []string{
+		"me@box:~/api$ ls",
+		"main.go  go.mod",
+		"me@box:~/api$ go test ./...",
+		"--- FAIL: TestHandler (0.00s)",
+		"    handler_test.go:42: status = 500, want 200",
+		"FAIL",
+		"",
+		"me@box:~/api$ ",
+	}
+
+	got := strings.Join(commandOutput(screen, "go test ./..."), "|")
+	want := "--- FAIL: TestHandler (0.00s)|    handler_test.go:42: status = 500, want 200|FAIL|"
+	if got != want {
+		t.Errorf("commandOutput() = %q, want %q", got, want)
+	}
+
+	if out := commandOutput(screen, "make build"); out != nil {
+		t.Errorf("commandOutput() for a command not on screen = %q, want nil", out)
+	}
+}
+
+func TestTrimOutput(t *testing.T) {
+	lines := []string{"", "step 1", "step 2", "export TOKEN=hunter2hunter2", "error: boom", "", ""}
+
+	got := trimOutput(lines, OutputConfig{MaxLines: 2, MaxBytes: 1024})
+	if got != "export TOKEN=[REDACTED]\nerror: boom" {
+		t.Errorf("trimOutput() = %q", got)
+	}
+
+	got = trimOutput(lines, OutputConfig{MaxLines: 10, MaxBytes: 14})
+	if got != "...error: boom" {
+		t.Errorf("trimOutput() with a byte cap = %q", got)
+	}
+}
+
+func TestCaptureOutput(t *testing.T) {
+	cfg := OutputConfig{Enabled: true, MaxLines: 10, MaxBytes: 1024}
+
+	exact := func(ctx context.Context, lines int) (string, bool, error) {
+		return "npm ERR! missing script: lint\n", true, nil
+	}
+	if got := captureOutput(cfg, "npm run lint", exact); got != "npm ERR! missing script: lint" {
+		t.Errorf("captureOutput() from an exact reader = %q", got)
+	}
+
+	failing := func(ctx context.Context, lines int) (string, bool, error) {
+		return "", false, errors.New("no server running")
+	}
+	if got := captureOutput(cfg, "npm run lint", failing); got != "" {
+		t.Errorf("captureOutput() when the screen can't be read = %q, want empty", got)
+	}
+	if got := captureOutput(cfg, "npm run lint", nil); got != "" {
+		t.Errorf("captureOutput() without a reader = %q, want empty", got)
+	}
+}
+
+func TestValidateOutputConfig(t *testing.T) {
+	m := &Module{}
+	valid := map[string]interface{}{
+		"capture_output": map[string]interface{}{"enabled": true, "max_lines": 20, "max_bytes": 2048},
+	}
+	if err := m.ValidateConfig(valid); err != nil {
+		t.Errorf("ValidateConfig() error = %v", err)
+	}
+
+	for _, bad := range []interface{}{
+		true,
+		map[string]interface{}{"enabled": "yes"},
+		map[string]interface{}{"max_lines": 0},
+		map[string]interface{}{"max_bytes": "4k"},
+	} {
+		if err := m.ValidateConfig(map[string]interface{}{"capture_output": bad}); err == nil {
+			t.Errorf("ValidateConfig(capture_output: %v) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+
+	"devlog/internal/events"
+	"devlog/internal/formatting"
+)
+
+type SSHFormatter struct{}
+
+func init() {
+	formatting.Register("ssh", &SSHFormatter{})
+}
+
+func (f *SSHFormatter) Format(event *events.Event) string {
+	host, _ := event.Payload["host"].(string)
+	target := host
+	if user, ok := event.Payload["user"].(string); ok && user != "" {
+		target = user + "@" + host
+	}
+
+	var result string
+	switch event.Type {
+	case string(events.TypeSessionStart):
+		result = "ssh " + target
+		if cmd, ok := event.Payload["command"].(string); ok && cmd != "" {
+			result += " -- " + cmd
+		}
+	case string(events.TypeSessionEnd):
+		result = "ssh " + target + " ended"
+		if event.DurationMs > 0 {
+			result += " after " + formatting.FormatDurationMs(event.DurationMs)
+		}
+	case string(events.TypeCommand):
+		cmd, _ := event.Payload["command"].(string)
+		result = fmt.Sprintf("%s$ %s", target, cmd)
+		if dir, ok := event.Payload["remote_workdir"].(string); ok && dir != "" {
+			result += fmt.Sprintf(" (in %s)", dir)
+		}
+	default:
+		result = strings.TrimSpace("ssh " + event.Type + " " + target)
+	}
+
+	if ec, ok := numberValue(event.Payload["exit_code"]); ok && ec != 0 {
+		result += fmt.Sprintf(" [exit:%d]", ec)
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+# devlog ssh remote hook
+#
+# Reports each command run in this shell to the devlog relay that the ssh
+# wrapper forwards to 127.0.0.1:{{PORT}}. When the session was not opened
+# through the wrapper nothing listens there and the report is dropped.
+# Requires curl; works in bash and zsh.
+
+if command -v curl &> /dev/null && [ -z "$__DEVLOG_SSH_HOOKED" ]; then
+    __DEVLOG_SSH_HOOKED=1
+
+    __devlog_ssh_preexec() {
+        __DEVLOG_SSH_CMD="$1"
+    }
+
+    __devlog_ssh_precmd() {
+        local exit_code=$?
+        [ -z "$__DEVLOG_SSH_CMD" ] && return
+        local cmd="$__DEVLOG_SSH_CMD"
+        unset __DEVLOG_SSH_CMD
+
+        (
+            curl -s -o /dev/null --max-time 2 \
+                --data-urlencode "command=$cmd" \
+                --data-urlencode "workdir=$PWD" \
+                --data-urlencode "exit_code=$exit_code" \
+                --data-urlencode "user=$USER" \
+                "http://127.0.0.1:{{PORT}}/command" &> /dev/null &
+        )
+    }
+
+    if [ -n "$BASH_VERSION" ]; then
+        __devlog_ssh_bash_preexec() {
+            [ -n "$COMP_LINE" ] && return
+            [ "$BASH_COMMAND" = "$PROMPT_COMMAND" ] && return
+            [[ "$BASH_COMMAND" == __devlog_* ]] && return
+            __devlog_ssh_preexec "$BASH_COMMAND"
+        }
+
+        trap '__devlog_ssh_bash_preexec' DEBUG
+
+        if [[ "$PROMPT_COMMAND" != *"__devlog_ssh_precmd"* ]]; then
+            PROMPT_COMMAND="__devlog_ssh_precmd${PROMPT_COMMAND:+; $PROMPT_COMMAND}"
+        fi
+    fi
+
+    if [ -n "$ZSH_VERSION" ]; then
+        autoload -Uz add-zsh-hook
+
+        add-zsh-hook preexec __devlog_ssh_preexec
+        add-zsh-hook precmd __devlog_ssh_precmd
+    fi
+fi
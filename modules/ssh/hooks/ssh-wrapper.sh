@@ -0,0 +1,113 @@
+#!/bin/bash
+
+DEVLOG_SSH_ENABLED="${DEVLOG_SSH_ENABLED:-true}"
+
+find_real_ssh() {
+    local this_script="$(realpath "${BASH_SOURCE[0]}" 2>/dev/null || readlink -f "${BASH_SOURCE[0]}" 2>/dev/null)"
+    [ -z "$this_script" ] && this_script="${BASH_SOURCE[0]}"
+
+    IFS=: read -ra paths <<< "$PATH"
+    for dir in "${paths[@]}"; do
+        [ -z "$dir" ] && continue
+        local candidate="$dir/ssh"
+        [ ! -x "$candidate" ] && continue
+        local candidate_real="$(realpath "$candidate" 2>/dev/null || readlink -f "$candidate" 2>/dev/null)"
+        [ -z "$candidate_real" ] && candidate_real="$candidate"
+        [ "$candidate_real" = "$this_script" ] && continue
+        echo "$candidate"
+        return 0
+    done
+
+    echo "/usr/bin/ssh"
+}
+
+SSH_BIN="$(find_real_ssh)"
+[ "$DEVLOG_SSH_ENABLED" != "true" ] && exec "$SSH_BIN" "$@"
+
+# Only interactive sessions are recorded. scp, rsync and git run ssh without
+# a terminal on stdin and stdout.
+{ [ -t 0 ] && [ -t 1 ]; } || exec "$SSH_BIN" "$@"
+
+DEVLOG_BIN="${DEVLOG_BIN:-devlog}"
+command -v "$DEVLOG_BIN" &> /dev/null || exec "$SSH_BIN" "$@"
+
+# Find the destination, skipping options and the values of options that
+# take one. Everything after the destination is the remote command.
+DESTINATION=""
+LOGIN_USER=""
+LOGIN_PORT=""
+REMOTE_COMMAND=""
+ARGS=("$@")
+i=0
+while [ $i -lt ${#ARGS[@]} ]; do
+    arg="${ARGS[$i]}"
+    case "$arg" in
+        --)
+            DESTINATION="${ARGS[$((i + 1))]}"
+            i=$((i + 1))
+            break
+            ;;
+        -l) i=$((i + 1)); LOGIN_USER="${ARGS[$i]}" ;;
+        -l*) LOGIN_USER="${arg#-l}" ;;
+        -p) i=$((i + 1)); LOGIN_PORT="${ARGS[$i]}" ;;
+        -p*) LOGIN_PORT="${arg#-p}" ;;
+        -[BbcDEeFIiJLmOoQRSWw]) i=$((i + 1)) ;;
+        -*) ;;
+        *)
+            DESTINATION="$arg"
+            break
+            ;;
+    esac
+    i=$((i + 1))
+done
+[ -n "$DESTINATION" ] && REMOTE_COMMAND="${ARGS[*]:$((i + 1))}"
+
+# -V, -G and -Q only print information.
+[ -z "$DESTINATION" ] && exec "$SSH_BIN" "$@"
+
+SESSION_ARGS=(--destination "$DESTINATION" --user "$LOGIN_USER" --port "$LOGIN_PORT")
+
+# The start handler prints key=value lines: session_id when the session is
+# recorded, and forward_port when remote commands should be captured.
+START_OUTPUT="$("$DEVLOG_BIN" ingest ssh start "${SESSION_ARGS[@]}" --command "$REMOTE_COMMAND" 2>/dev/null)" || exec "$SSH_BIN" "$@"
+
+SESSION_ID=""
+FORWARD_PORT=""
+while IFS='=' read -r key value; do
+    case "$key" in
+        session_id) SESSION_ID="$value" ;;
+        forward_port) FORWARD_PORT="$value" ;;
+    esac
+done <<< "$START_OUTPUT"
+[ -z "$SESSION_ID" ] && exec "$SSH_BIN" "$@"
+
+EXTRA_ARGS=()
+RELAY_PID=""
+if [ -n "$FORWARD_PORT" ]; then
+    PORT_FILE="$(mktemp)"
+    "$DEVLOG_BIN" ingest ssh relay "${SESSION_ARGS[@]}" --session-id "$SESSION_ID" --port-file "$PORT_FILE" &> /dev/null &
+    RELAY_PID=$!
+
+    for _ in 1 2 3 4 5 6 7 8 9 10; do
+        [ -s "$PORT_FILE" ] && break
+        sleep 0.1
+    done
+    RELAY_PORT="$(cat "$PORT_FILE" 2>/dev/null)"
+    rm -f "$PORT_FILE"
+
+    if [ -n "$RELAY_PORT" ]; then
+        EXTRA_ARGS=(-R "127.0.0.1:${FORWARD_PORT}:127.0.0.1:${RELAY_PORT}")
+    fi
+fi
+
+START_TIME=$(date +%s)
+"$SSH_BIN" "${EXTRA_ARGS[@]}" "$@"
+EXIT_CODE=$?
+DURATION=$((($(date +%s) - START_TIME) * 1000))
+
+[ -n "$RELAY_PID" ] && kill "$RELAY_PID" 2>/dev/null
+
+"$DEVLOG_BIN" ingest ssh end "${SESSION_ARGS[@]}" --session-id "$SESSION_ID" \
+    --duration-ms "$DURATION" --exit-code "$EXIT_CODE" &> /dev/null &
+
+exit $EXIT_CODE
@@ -0,0 +1,195 @@
+package ssh
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"devlog/internal/events"
+	"devlog/internal/ingest"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+)
+
+// exitConnectionFailed is the status ssh exits with when it could not
+// connect, as opposed to the remote command's own status.
+const exitConnectionFailed = 255
+
+type IngestHandler struct{}
+
+func (h *IngestHandler) CLICommand() *cli.Command {
+	return &cli.Command{
+		Name:  "ssh",
+		Usage: "Ingest ssh session events (used by ssh wrapper)",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "start",
+				Usage: "Record the start of a session and print its session_id",
+				Flags: append(sessionFlags(),
+					&cli.StringFlag{Name: "command", Usage: "Remote command given on the ssh command line"},
+				),
+				Action: h.start,
+			},
+			{
+				Name:  "end",
+				Usage: "Record the end of a session",
+				Flags: append(sessionFlags(),
+					&cli.StringFlag{Name: "session-id", Usage: "Session ID printed by start", Required: true},
+					&cli.Int64Flag{Name: "duration-ms", Usage: "Session duration in milliseconds"},
+					&cli.IntFlag{Name: "exit-code", Usage: "ssh exit code"},
+				),
+				Action: h.end,
+			},
+			{
+				Name:  "relay",
+				Usage: "Receive commands reported from the remote machine until terminated",
+				Flags: append(sessionFlags(),
+					&cli.StringFlag{Name: "session-id", Usage: "Session ID printed by start", Required: true},
+					&cli.StringFlag{Name: "port-file", Usage: "File to write the relay's local port to", Required: true},
+				),
+				Action: h.relay,
+			},
+		},
+	}
+}
+
+func sessionFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "destination", Usage: "ssh destination ([user@]host or ssh:// URI)", Required: true},
+		&cli.StringFlag{Name: "user", Usage: "Login user given with -l"},
+		&cli.StringFlag{Name: "port", Usage: "Port given with -p"},
+	}
+}
+
+func sessionFromFlags(c *cli.Context) (Session, error) {
+	s, err := newSession(c.String("destination"), c.String("user"), c.String("port"))
+	if err != nil {
+		return Session{}, err
+	}
+	s.ID = c.String("session-id")
+	return s, nil
+}
+
+// start records the session unless its host is ignored. The wrapper reads
+// the printed session_id and, when remote commands are captured, the
+// forward_port to tunnel back to the relay.
+func (h *IngestHandler) start(c *cli.Context) error {
+	s, err := sessionFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	cfg := loadSettings()
+	if cfg.ignored(s.Host) {
+		return nil
+	}
+
+	s.ID = uuid.New().String()
+	if err := ingest.SendEvent(startEvent(s, c.String("command"))); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.App.Writer, "session_id=%s\n", s.ID)
+	if cfg.CaptureRemoteCommands {
+		fmt.Fprintf(c.App.Writer, "forward_port=%d\n", cfg.ForwardPort)
+	}
+	return nil
+}
+
+func (h *IngestHandler) end(c *cli.Context) error {
+	s, err := sessionFromFlags(c)
+	if err != nil {
+		return err
+	}
+	return ingest.SendEvent(endEvent(s, c.Int64("duration-ms"), c.Int("exit-code")))
+}
+
+func (h *IngestHandler) relay(c *cli.Context) error {
+	s, err := sessionFromFlags(c)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer stop()
+
+	relay := &Relay{Session: s, Send: ingest.SendEvent}
+	return relay.Serve(ctx, c.String("port-file"))
+}
+
+// payloadSchema is checked by the daemon when ssh events are ingested.
+var payloadSchema = events.PayloadSchema{
+	Source: string(events.SourceSSH),
+	Fields: map[string]events.PayloadField{
+		"host":        {Kind: events.KindString, Required: true},
+		"user":        {Kind: events.KindString},
+		"port":        {Kind: events.KindNumber},
+		"command":     {Kind: events.KindString},
+		"exit_code":   {Kind: events.KindNumber},
+		"duration_ms": {Kind: events.KindNumber},
+		"remote":      {Kind: events.KindBool},
+	},
+}
+
+func newSessionEvent(s Session, eventType events.EventType) *events.Event {
+	event := events.NewEvent(string(events.SourceSSH), string(eventType))
+	event.SessionID = s.ID
+	event.Payload["host"] = s.Host
+	if s.User != "" {
+		event.Payload["user"] = s.User
+	}
+	if s.Port != 0 {
+		event.Payload["port"] = s.Port
+	}
+	return event
+}
+
+func startEvent(s Session, command string) *events.Event {
+	event := newSessionEvent(s, events.TypeSessionStart)
+	if command != "" {
+		event.Payload["command"] = command
+	}
+	return event
+}
+
+func endEvent(s Session, durationMs int64, exitCode int) *events.Event {
+	event := newSessionEvent(s, events.TypeSessionEnd)
+	event.DurationMs = durationMs
+	event.Payload["duration_ms"] = durationMs
+	event.Payload["exit_code"] = exitCode
+	if exitCode == exitConnectionFailed {
+		event.Severity = string(events.SeverityError)
+	}
+	return event
+}
+
+// RemoteCommand is a command the remote hook reported from inside a session.
+type RemoteCommand struct {
+	Command  string
+	Workdir  string
+	User     string
+	ExitCode int
+}
+
+// remoteCommandEvent records a remote command. The working directory is
+// stored as remote_workdir so it is never mistaken for a local repo path.
+func remoteCommandEvent(s Session, cmd RemoteCommand) *events.Event {
+	event := newSessionEvent(s, events.TypeCommand)
+	event.Payload["command"] = cmd.Command
+	event.Payload["exit_code"] = cmd.ExitCode
+	event.Payload["remote"] = true
+	if cmd.Workdir != "" {
+		event.Payload["remote_workdir"] = cmd.Workdir
+	}
+	if cmd.User != "" {
+		event.Payload["user"] = cmd.User
+	}
+	return event
+}
+
+func init() {
+	ingest.Register("ssh", &IngestHandler{})
+}
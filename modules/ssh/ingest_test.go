@@ -0,0 +1,142 @@
+package ssh
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"devlog/internal/events"
+)
+
+func TestNewSession(t *testing.T) {
+	tests := []struct {
+		destination, user, port string
+		want                    Session
+	}{
+		{"prod-db-1", "", "", Session{Host: "prod-db-1"}},
+		{"deploy@prod-db-1", "", "", Session{Host: "prod-db-1", User: "deploy"}},
+		{"prod-db-1", "root", "2222", Session{Host: "prod-db-1", User: "root", Port: 2222}},
+		{"deploy@prod-db-1", "root", "", Session{Host: "prod-db-1", User: "deploy"}},
+		{"ssh://ops@dev.example.com:2200", "", "22", Session{Host: "dev.example.com", User: "ops", Port: 2200}},
+	}
+	for _, tt := range tests {
+		got, err := newSession(tt.destination, tt.user, tt.port)
+		if err != nil {
+			t.Errorf("newSession(%q) error: %v", tt.destination, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("newSession(%q, %q, %q) = %+v, want %+v", tt.destination, tt.user, tt.port, got, tt.want)
+		}
+	}
+
+	if _, err := newSession("deploy@", "", ""); err == nil {
+		t.Error("newSession() should reject a destination without a host")
+	}
+}
+
+func TestSettingsIgnoredHosts(t *testing.T) {
+	defaults := settingsFrom(nil)
+	if !defaults.ignored("GitHub.com") || defaults.ignored("prod-db-1") {
+		t.Errorf("default ignore_hosts = %v", defaults.IgnoreHosts)
+	}
+	if defaults.CaptureRemoteCommands || defaults.ForwardPort != DefaultForwardPort {
+		t.Errorf("defaults = %+v", defaults)
+	}
+
+	cfg := settingsFrom(map[string]interface{}{
+		"capture_remote_commands": true,
+		"forward_port":            float64(9000),
+		"ignore_hosts":            []interface{}{"*.internal"},
+	})
+	if !cfg.CaptureRemoteCommands || cfg.ForwardPort != 9000 {
+		t.Errorf("settings = %+v", cfg)
+	}
+	if !cfg.ignored("build.internal") || cfg.ignored("github.com") {
+		t.Errorf("ignore_hosts = %v", cfg.IgnoreHosts)
+	}
+}
+
+func TestSessionEvents(t *testing.T) {
+	s := Session{ID: "abc", Host: "prod-db-1", User: "deploy"}
+
+	start := startEvent(s, "uptime")
+	end := endEvent(s, 90_000, 0)
+	failed := endEvent(s, 2_000, exitConnectionFailed)
+	for _, event := range []*events.Event{start, end, failed} {
+		if err := event.Validate(); err != nil {
+			t.Errorf("%s event invalid: %v", event.Type, err)
+		}
+		if err := event.ValidatePayload(); err != nil {
+			t.Errorf("%s event does not match its payload schema: %v", event.Type, err)
+		}
+		if event.SessionID != "abc" {
+			t.Errorf("%s SessionID = %q", event.Type, event.SessionID)
+		}
+	}
+
+	f := &SSHFormatter{}
+	if got := f.Format(start); got != "ssh deploy@prod-db-1 -- uptime" {
+		t.Errorf("Format(start) = %q", got)
+	}
+	if got := f.Format(end); got != "ssh deploy@prod-db-1 ended after 1m30s" {
+		t.Errorf("Format(end) = %q", got)
+	}
+	if failed.Severity != string(events.SeverityError) {
+		t.Errorf("connection failure severity = %q", failed.Severity)
+	}
+}
+
+func TestRelayRecordsRemoteCommands(t *testing.T) {
+	var sent []*events.Event
+	relay := &Relay{
+		Session: Session{ID: "abc", Host: "prod-db-1", User: "deploy"},
+		Send: func(e *events.Event) error {
+			sent = append(sent, e)
+			return nil
+		},
+	}
+
+	post := func(path string, form url.Values) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		relay.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	code := post("/command", url.Values{
+		"command":   {"systemctl restart worker"},
+		"workdir":   {"/srv/app"},
+		"exit_code": {"1"},
+		"user":      {"root"},
+	})
+	if code != http.StatusNoContent {
+		t.Fatalf("POST /command = %d", code)
+	}
+	if code := post("/command", url.Values{"command": {"  "}}); code != http.StatusBadRequest {
+		t.Errorf("empty command = %d, want 400", code)
+	}
+	if code := post("/api/v1/ingest", url.Values{"command": {"ls"}}); code != http.StatusNotFound {
+		t.Errorf("other path = %d, want 404", code)
+	}
+
+	if len(sent) != 1 {
+		t.Fatalf("sent %d events, want 1", len(sent))
+	}
+	event := sent[0]
+	if err := event.ValidatePayload(); err != nil {
+		t.Errorf("event does not match its payload schema: %v", err)
+	}
+	if event.Type != string(events.TypeCommand) || event.SessionID != "abc" || event.Payload["remote"] != true {
+		t.Errorf("event = %+v", event)
+	}
+	if _, ok := event.Payload["workdir"]; ok {
+		t.Error("remote working directory stored as workdir")
+	}
+	if got := (&SSHFormatter{}).Format(event); got != "root@prod-db-1$ systemctl restart worker (in /srv/app) [exit:1]" {
+		t.Errorf("Format() = %q", got)
+	}
+}
@@ -0,0 +1,171 @@
+package ssh
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"devlog/internal/assets"
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+)
+
+//go:embed hooks
+var hooksFS embed.FS
+
+type Module struct{}
+
+func (m *Module) Name() string {
+	return "ssh"
+}
+
+func (m *Module) Description() string {
+	return "Record interactive ssh sessions with host and duration, and optionally the commands run remotely"
+}
+
+func (m *Module) Install(ctx *install.Context) error {
+	ctx.Log("Installing ssh command wrapper...")
+
+	binDir := filepath.Join(ctx.HomeDir, ".local", "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return &modules.InstallError{
+			Component: "ssh wrapper",
+			File:      binDir,
+			Err:       err,
+			RecoverySteps: []string{
+				fmt.Sprintf("Check directory permissions: ls -la %s", filepath.Dir(binDir)),
+				fmt.Sprintf("Try creating manually: mkdir -p %s", binDir),
+				"Check disk space: df -h",
+			},
+		}
+	}
+
+	wrapperPath := filepath.Join(binDir, "ssh")
+	if err := ctx.WriteAsset("ssh", "ssh-wrapper.sh", wrapperPath, nil); err != nil {
+		return &modules.InstallError{
+			Component: "ssh wrapper",
+			File:      wrapperPath,
+			Err:       err,
+			RecoverySteps: []string{
+				fmt.Sprintf("Check file permissions: ls -la %s", filepath.Dir(wrapperPath)),
+				"Ensure directory exists and is writable",
+				fmt.Sprintf("Try manual install: Save the wrapper script to %s and chmod +x %s", wrapperPath, wrapperPath),
+			},
+		}
+	}
+
+	ctx.Log("✓ Installed ssh wrapper to %s", wrapperPath)
+
+	cfg, err := config.Load()
+	if err == nil && cfg.IsModuleEnabled("shell") {
+		cfg.AddToShellIgnoreList("ssh")
+		if err := cfg.Save(); err == nil {
+			ctx.Log("✓ Added 'ssh' to shell module ignore list")
+		}
+	}
+
+	ctx.Log("")
+	ctx.Log("Interactive ssh sessions will now be tracked. scp, rsync and git over ssh are not recorded.")
+	ctx.Log("")
+	ctx.Log("IMPORTANT: Ensure %s is in your PATH and appears BEFORE /usr/bin", binDir)
+	ctx.Log("Add this to your shell RC file:")
+	ctx.Log("")
+	ctx.Log("  export PATH=\"%s:$PATH\"", binDir)
+	ctx.Log("")
+	ctx.Log("To also record commands run on remote machines, set capture_remote_commands: true")
+	ctx.Log("and add the output of 'devlog module ssh remote-hook' to the remote shell RC file.")
+
+	return nil
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling ssh wrapper...")
+
+	wrapperPath := filepath.Join(ctx.HomeDir, ".local", "bin", "ssh")
+	if _, err := os.Stat(wrapperPath); err == nil {
+		if ctx.OwnsAsset("ssh", "ssh-wrapper.sh", wrapperPath) {
+			if err := ctx.RemoveAsset(wrapperPath); err != nil {
+				return fmt.Errorf("remove ssh wrapper: %w", err)
+			}
+			ctx.Log("✓ Removed ssh wrapper from %s", wrapperPath)
+		} else {
+			ctx.Log("Warning: ssh wrapper at %s doesn't match devlog's wrapper, skipping removal", wrapperPath)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err == nil && cfg.IsModuleEnabled("shell") {
+		cfg.RemoveFromShellIgnoreList("ssh")
+		if err := cfg.Save(); err == nil {
+			ctx.Log("✓ Removed 'ssh' from shell module ignore list")
+		}
+	}
+
+	return nil
+}
+
+func (m *Module) DefaultConfig() interface{} {
+	ignoreHosts := make([]interface{}, len(defaultIgnoreHosts))
+	for i, host := range defaultIgnoreHosts {
+		ignoreHosts[i] = host
+	}
+	return map[string]interface{}{
+		"capture_remote_commands": false,
+		"forward_port":            DefaultForwardPort,
+		"ignore_hosts":            ignoreHosts,
+	}
+}
+
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources: []string{string(events.SourceSSH)},
+		Checks:  []modules.Check{modules.WrapperCheck(ctx, "ssh")},
+	}
+}
+
+func (m *Module) ValidateConfig(config interface{}) error {
+	cfg, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("config must be a map")
+	}
+
+	if val, ok := cfg["capture_remote_commands"]; ok && val != nil {
+		if _, ok := val.(bool); !ok {
+			return fmt.Errorf("capture_remote_commands must be a boolean")
+		}
+	}
+
+	if val, ok := cfg["forward_port"]; ok && val != nil {
+		port, ok := numberValue(val)
+		if !ok || port < 1 || port > 65535 {
+			return fmt.Errorf("forward_port must be a port number between 1 and 65535")
+		}
+	}
+
+	if val, ok := cfg["ignore_hosts"]; ok && val != nil {
+		list, ok := val.([]interface{})
+		if !ok {
+			return fmt.Errorf("ignore_hosts must be a list")
+		}
+		for _, item := range list {
+			pattern, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("ignore_hosts: %v is not a string", item)
+			}
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return fmt.Errorf("ignore_hosts: invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	assets.RegisterFS("ssh", hooksFS, "hooks", "ssh-wrapper.sh")
+	events.RegisterPayloadSchema(payloadSchema)
+	modules.Register(&Module{})
+}
@@ -0,0 +1,106 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+)
+
+const maxReportBytes = 64 * 1024
+
+// Relay receives the commands the remote hook reports through the wrapper's
+// reverse tunnel and records them as commands of its session. It only
+// accepts command reports, so the remote machine never reaches the daemon's
+// API.
+type Relay struct {
+	Session Session
+	Send    func(*events.Event) error
+}
+
+func (r *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/command" {
+		http.NotFound(w, req)
+		return
+	}
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req.Body = http.MaxBytesReader(w, req.Body, maxReportBytes)
+	if err := req.ParseForm(); err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return
+	}
+
+	cmd := RemoteCommand{
+		Command: strings.TrimSpace(req.PostForm.Get("command")),
+		Workdir: req.PostForm.Get("workdir"),
+		User:    req.PostForm.Get("user"),
+	}
+	if cmd.Command == "" {
+		http.Error(w, "command is required", http.StatusBadRequest)
+		return
+	}
+	cmd.ExitCode, _ = strconv.Atoi(req.PostForm.Get("exit_code"))
+
+	if err := r.Send(remoteCommandEvent(r.Session, cmd)); err != nil {
+		http.Error(w, "could not record command", http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Serve listens on a free loopback port, writes the port to portFile and
+// serves until ctx is done or the wrapper that started the relay exits.
+func (r *Relay) Serve(ctx context.Context, portFile string) error {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	// The wrapper polls for a non-empty port file, so it must never see a
+	// partial write.
+	port := strconv.Itoa(ln.Addr().(*net.TCPAddr).Port)
+	tmp := portFile + ".tmp"
+	if err := os.WriteFile(tmp, []byte(port), 0600); err != nil {
+		ln.Close()
+		return fmt.Errorf("write port file: %w", err)
+	}
+	if err := os.Rename(tmp, portFile); err != nil {
+		ln.Close()
+		return fmt.Errorf("write port file: %w", err)
+	}
+
+	srv := &http.Server{Handler: r, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		parent := os.Getppid()
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				srv.Close()
+				return
+			case <-ticker.C:
+				if os.Getppid() != parent {
+					srv.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
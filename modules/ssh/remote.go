@@ -0,0 +1,22 @@
+package ssh
+
+import (
+	"strconv"
+
+	"devlog/internal/assets"
+)
+
+// RemoteHook returns the bash/zsh snippet that reports commands run on a
+// remote machine to the relay forwarded to port.
+func RemoteHook(port int) (string, error) {
+	asset, err := assets.Get("ssh", "remote-hook.sh")
+	if err != nil {
+		return "", err
+	}
+	return asset.Render(map[string]string{"PORT": strconv.Itoa(port)}), nil
+}
+
+// ForwardPort returns the configured forward_port.
+func ForwardPort() int {
+	return loadSettings().ForwardPort
+}
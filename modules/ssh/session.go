@@ -0,0 +1,130 @@
+package ssh
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"devlog/internal/config"
+)
+
+// DefaultForwardPort is the port on the remote machine that the wrapper
+// forwards back to the session's relay when remote commands are captured.
+const DefaultForwardPort = 8574
+
+var defaultIgnoreHosts = []string{"github.com", "gitlab.com", "bitbucket.org"}
+
+// Session identifies one ssh connection made through the wrapper.
+type Session struct {
+	ID   string
+	Host string
+	User string
+	Port int
+}
+
+// Target returns the session's destination as user@host.
+func (s Session) Target() string {
+	if s.User == "" {
+		return s.Host
+	}
+	return s.User + "@" + s.Host
+}
+
+// newSession builds a session from the destination and the -l and -p values
+// the wrapper found on the ssh command line. A user or port given in the
+// destination itself wins, as it does for ssh.
+func newSession(destination, loginUser, loginPort string) (Session, error) {
+	s := Session{User: loginUser}
+	if loginPort != "" {
+		port, err := strconv.Atoi(loginPort)
+		if err != nil {
+			return Session{}, fmt.Errorf("invalid port %q", loginPort)
+		}
+		s.Port = port
+	}
+
+	if strings.HasPrefix(destination, "ssh://") {
+		u, err := url.Parse(destination)
+		if err != nil {
+			return Session{}, fmt.Errorf("invalid destination %q: %w", destination, err)
+		}
+		s.Host = u.Hostname()
+		if u.User != nil {
+			s.User = u.User.Username()
+		}
+		if p := u.Port(); p != "" {
+			s.Port, _ = strconv.Atoi(p)
+		}
+	} else if at := strings.LastIndex(destination, "@"); at >= 0 {
+		s.User = destination[:at]
+		s.Host = destination[at+1:]
+	} else {
+		s.Host = destination
+	}
+
+	if s.Host == "" {
+		return Session{}, fmt.Errorf("destination %q has no host", destination)
+	}
+	return s, nil
+}
+
+type settings struct {
+	CaptureRemoteCommands bool
+	ForwardPort           int
+	IgnoreHosts           []string
+}
+
+func loadSettings() settings {
+	var moduleCfg map[string]interface{}
+	if cfg, err := config.Load(); err == nil {
+		moduleCfg, _ = cfg.GetModuleConfig("ssh")
+	}
+	return settingsFrom(moduleCfg)
+}
+
+func settingsFrom(cfg map[string]interface{}) settings {
+	s := settings{
+		ForwardPort: DefaultForwardPort,
+		IgnoreHosts: defaultIgnoreHosts,
+	}
+	if v, ok := cfg["capture_remote_commands"].(bool); ok {
+		s.CaptureRemoteCommands = v
+	}
+	if n, ok := numberValue(cfg["forward_port"]); ok && n > 0 {
+		s.ForwardPort = n
+	}
+	if list, ok := cfg["ignore_hosts"].([]interface{}); ok {
+		s.IgnoreHosts = nil
+		for _, item := range list {
+			if pattern, ok := item.(string); ok {
+				s.IgnoreHosts = append(s.IgnoreHosts, pattern)
+			}
+		}
+	}
+	return s
+}
+
+// ignored reports whether host matches one of the ignore_hosts patterns.
+func (s settings) ignored(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range s.IgnoreHosts {
+		if ok, _ := filepath.Match(strings.ToLower(pattern), host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func numberValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
@@ -0,0 +1,42 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+
+	"devlog/internal/events"
+	"devlog/internal/formatting"
+)
+
+type TerraformFormatter struct{}
+
+func init() {
+	formatting.Register("terraform", &TerraformFormatter{})
+}
+
+func (f *TerraformFormatter) Format(event *events.Event) string {
+	parts := []string{strings.TrimPrefix(event.Type, "terraform_")}
+
+	add, hasAdd := event.Payload["add"].(float64)
+	change, _ := event.Payload["change"].(float64)
+	destroy, _ := event.Payload["destroy"].(float64)
+	if hasAdd {
+		parts = append(parts, fmt.Sprintf("+%d ~%d -%d", int(add), int(change), int(destroy)))
+	}
+
+	if ws, ok := event.Payload["workspace"].(string); ok && ws != "" {
+		parts = append(parts, fmt.Sprintf("@%s", ws))
+	}
+
+	if backend, ok := event.Payload["backend"].(string); ok && backend != "" {
+		parts = append(parts, fmt.Sprintf("(%s)", backend))
+	}
+
+	result := strings.Join(parts, " ")
+
+	if ec, ok := event.Payload["exit_code"].(float64); ok && ec != 0 {
+		result += fmt.Sprintf(" [exit:%d]", int(ec))
+	}
+
+	return result
+}
@@ -0,0 +1,106 @@
+#!/bin/bash
+
+DEVLOG_TERRAFORM_ENABLED="${DEVLOG_TERRAFORM_ENABLED:-true}"
+
+find_real_terraform() {
+    local this_script="$(realpath "${BASH_SOURCE[0]}" 2>/dev/null || readlink -f "${BASH_SOURCE[0]}" 2>/dev/null)"
+    [ -z "$this_script" ] && this_script="${BASH_SOURCE[0]}"
+
+    IFS=: read -ra paths <<< "$PATH"
+    for dir in "${paths[@]}"; do
+        [ -z "$dir" ] && continue
+        local candidate="$dir/terraform"
+        [ ! -x "$candidate" ] && continue
+        local candidate_real="$(realpath "$candidate" 2>/dev/null || readlink -f "$candidate" 2>/dev/null)"
+        [ -z "$candidate_real" ] && candidate_real="$candidate"
+        [ "$candidate_real" = "$this_script" ] && continue
+        echo "$candidate"
+        return 0
+    done
+
+    echo "/usr/local/bin/terraform"
+}
+
+find_devlog() {
+    local devlog_bin="${DEVLOG_BIN:-devlog}"
+
+    if command -v "$devlog_bin" &> /dev/null; then
+        echo "$devlog_bin"
+        return 0
+    fi
+
+    for path in /usr/local/bin/devlog ~/.local/bin/devlog ~/bin/devlog; do
+        if [ -x "$path" ]; then
+            echo "$path"
+            return 0
+        fi
+    done
+
+    return 1
+}
+
+TERRAFORM_BIN="$(find_real_terraform)"
+[ "$DEVLOG_TERRAFORM_ENABLED" != "true" ] && exec "$TERRAFORM_BIN" "$@"
+
+DEVLOG_BIN_PATH="$(find_devlog)"
+[ -z "$DEVLOG_BIN_PATH" ] && exec "$TERRAFORM_BIN" "$@"
+
+WORKDIR="$PWD"
+SUBCOMMAND=""
+DESTROY_FLAG=false
+for arg in "$@"; do
+    if [ -z "$SUBCOMMAND" ]; then
+        case "$arg" in
+            -chdir=*)
+                CHDIR="${arg#-chdir=}"
+                case "$CHDIR" in
+                    /*) WORKDIR="$CHDIR" ;;
+                    *) WORKDIR="$PWD/$CHDIR" ;;
+                esac
+                ;;
+            -*) ;;
+            *) SUBCOMMAND="$arg" ;;
+        esac
+    elif [ "$arg" = "-destroy" ]; then
+        DESTROY_FLAG=true
+    fi
+done
+
+case "$SUBCOMMAND" in
+    plan|apply|destroy)
+        OPERATION="$SUBCOMMAND"
+        if [ "$DESTROY_FLAG" = true ] && [ "$SUBCOMMAND" = "apply" ]; then
+            OPERATION="destroy"
+        fi
+
+        WORKSPACE="${TF_WORKSPACE:-$("$TERRAFORM_BIN" -chdir="$WORKDIR" workspace show 2>/dev/null)}"
+        START_MS=$(date +%s%3N 2>/dev/null)
+
+        OUTPUT_FILE=$(mktemp)
+        "$TERRAFORM_BIN" "$@" 2>&1 | tee "$OUTPUT_FILE"
+        EXIT_CODE=${PIPESTATUS[0]}
+
+        END_MS=$(date +%s%3N 2>/dev/null)
+        DURATION_MS=0
+        if [[ "$START_MS" =~ ^[0-9]+$ ]] && [[ "$END_MS" =~ ^[0-9]+$ ]]; then
+            DURATION_MS=$((END_MS - START_MS))
+        fi
+
+        SUMMARY=$(sed 's/\x1b\[[0-9;]*m//g' "$OUTPUT_FILE" | grep -E '^(Plan: |No changes\.|Apply complete!|Destroy complete!)' | tail -n 1)
+        rm -f "$OUTPUT_FILE"
+
+        "$DEVLOG_BIN_PATH" ingest terraform \
+            --operation="$OPERATION" \
+            --workdir="$WORKDIR" \
+            --workspace="$WORKSPACE" \
+            --summary="$SUMMARY" \
+            --duration-ms="$DURATION_MS" \
+            --exit-code="$EXIT_CODE" &> /dev/null &
+
+        exit $EXIT_CODE
+        ;;
+
+    *)
+        exec "$TERRAFORM_BIN" "$@"
+        ;;
+esac
@@ -0,0 +1,211 @@
+package terraform
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/ingest"
+	"devlog/internal/vcs"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	planPattern    = regexp.MustCompile(`Plan: (\d+) to add, (\d+) to change, (\d+) to destroy`)
+	applyPattern   = regexp.MustCompile(`Resources: (\d+) added, (\d+) changed, (\d+) destroyed`)
+	destroyPattern = regexp.MustCompile(`Resources: (\d+) destroyed`)
+	noChangesText  = regexp.MustCompile(`^No changes\.`)
+)
+
+type resourceCounts struct {
+	Add     int
+	Change  int
+	Destroy int
+}
+
+type IngestHandler struct{}
+
+func (h *IngestHandler) CLICommand() *cli.Command {
+	return &cli.Command{
+		Name:  "terraform",
+		Usage: "Ingest a terraform event (used by terraform wrapper)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "operation", Usage: "Operation type (plan, apply, destroy)", Required: true},
+			&cli.StringFlag{Name: "workdir", Usage: "Terraform working directory"},
+			&cli.StringFlag{Name: "workspace", Usage: "Terraform workspace"},
+			&cli.StringFlag{Name: "summary", Usage: "Summary line from terraform output"},
+			&cli.Int64Flag{Name: "duration-ms", Usage: "Command duration in milliseconds"},
+			&cli.IntFlag{Name: "exit-code", Usage: "Command exit code", Value: 0},
+		},
+		Action: h.handle,
+	}
+}
+
+func (h *IngestHandler) handle(c *cli.Context) error {
+	args := []string{"--operation", c.String("operation")}
+	if v := c.String("workdir"); v != "" {
+		args = append(args, "--workdir", v)
+	}
+	if v := c.String("workspace"); v != "" {
+		args = append(args, "--workspace", v)
+	}
+	if v := c.String("summary"); v != "" {
+		args = append(args, "--summary", v)
+	}
+	if c.IsSet("duration-ms") {
+		args = append(args, "--duration-ms", c.String("duration-ms"))
+	}
+	if c.IsSet("exit-code") {
+		args = append(args, "--exit-code", c.String("exit-code"))
+	}
+	return h.ingestEvent(args)
+}
+
+func (h *IngestHandler) ingestEvent(args []string) error {
+	event, err := buildEvent(args)
+	if err != nil {
+		return err
+	}
+	return ingest.SendEvent(event)
+}
+
+// payloadSchema is checked by the daemon when terraform events are ingested.
+var payloadSchema = events.PayloadSchema{
+	Source: string(events.SourceTerraform),
+	Fields: map[string]events.PayloadField{
+		"exit_code":   {Kind: events.KindNumber, Required: true},
+		"workspace":   {Kind: events.KindString, Required: true},
+		"duration_ms": {Kind: events.KindNumber},
+		"add":         {Kind: events.KindNumber},
+		"change":      {Kind: events.KindNumber},
+		"destroy":     {Kind: events.KindNumber},
+	},
+}
+
+func buildEvent(args []string) (*events.Event, error) {
+	fs := flag.NewFlagSet("terraform-event", flag.ContinueOnError)
+	operation := fs.String("operation", "", "Operation type")
+	workdir := fs.String("workdir", "", "Terraform working directory")
+	workspace := fs.String("workspace", "", "Terraform workspace")
+	summary := fs.String("summary", "", "Summary line from terraform output")
+	durationMs := fs.Int64("duration-ms", 0, "Command duration in milliseconds")
+	exitCode := fs.Int("exit-code", 0, "Command exit code")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var typeConstant string
+	switch *operation {
+	case "plan":
+		typeConstant = string(events.TypeTerraformPlan)
+	case "apply":
+		typeConstant = string(events.TypeTerraformApply)
+	case "destroy":
+		typeConstant = string(events.TypeTerraformDestroy)
+	case "":
+		return nil, fmt.Errorf("--operation is required")
+	default:
+		return nil, fmt.Errorf("unknown operation type: %s", *operation)
+	}
+
+	event := events.NewEvent(string(events.SourceTerraform), typeConstant)
+	event.Payload["exit_code"] = *exitCode
+
+	if *workspace == "" {
+		*workspace = "default"
+	}
+	event.Payload["workspace"] = *workspace
+
+	if *durationMs > 0 {
+		event.Payload["duration_ms"] = *durationMs
+		event.DurationMs = *durationMs
+	}
+	if *exitCode != 0 {
+		event.Severity = string(events.SeverityError)
+	}
+
+	if counts, ok := parseSummary(*summary); ok {
+		event.Payload["add"] = counts.Add
+		event.Payload["change"] = counts.Change
+		event.Payload["destroy"] = counts.Destroy
+	}
+
+	if *workdir != "" {
+		event.Payload["workdir"] = *workdir
+		if backend := detectBackend(*workdir); backend != "" {
+			event.Payload["backend"] = backend
+		}
+		if repo, err := vcs.Detect(*workdir); err == nil {
+			event.Repo = repo.Name
+			event.Branch = repo.Branch
+			event.Payload["vcs"] = string(repo.Kind)
+			if repo.Remote != "" {
+				event.Payload[config.RemotePayloadKey] = repo.Remote
+			}
+		}
+	}
+
+	return event, nil
+}
+
+func parseSummary(line string) (resourceCounts, bool) {
+	atoi := func(s string) int {
+		n, _ := strconv.Atoi(s)
+		return n
+	}
+
+	if m := planPattern.FindStringSubmatch(line); m != nil {
+		return resourceCounts{Add: atoi(m[1]), Change: atoi(m[2]), Destroy: atoi(m[3])}, true
+	}
+	if m := applyPattern.FindStringSubmatch(line); m != nil {
+		return resourceCounts{Add: atoi(m[1]), Change: atoi(m[2]), Destroy: atoi(m[3])}, true
+	}
+	if m := destroyPattern.FindStringSubmatch(line); m != nil {
+		return resourceCounts{Destroy: atoi(m[1])}, true
+	}
+	if noChangesText.MatchString(line) {
+		return resourceCounts{}, true
+	}
+	return resourceCounts{}, false
+}
+
+func detectBackend(workdir string) string {
+	dataDir := os.Getenv("TF_DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(workdir, dataDir)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dataDir, "terraform.tfstate"))
+	if err != nil {
+		if _, statErr := os.Stat(dataDir); statErr == nil {
+			return "local"
+		}
+		return ""
+	}
+
+	var state struct {
+		Backend *struct {
+			Type string `json:"type"`
+		} `json:"backend"`
+	}
+	if err := json.Unmarshal(data, &state); err != nil || state.Backend == nil || state.Backend.Type == "" {
+		return "local"
+	}
+
+	return state.Backend.Type
+}
+
+func init() {
+	ingest.Register("terraform", &IngestHandler{})
+}
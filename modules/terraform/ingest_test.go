@@ -0,0 +1,104 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSummary(t *testing.T) {
+	tests := []struct {
+		line string
+		want resourceCounts
+		ok   bool
+	}{
+		{"Plan: 3 to add, 1 to change, 2 to destroy.", resourceCounts{3, 1, 2}, true},
+		{"Apply complete! Resources: 4 added, 0 changed, 1 destroyed.", resourceCounts{4, 0, 1}, true},
+		{"Destroy complete! Resources: 7 destroyed.", resourceCounts{0, 0, 7}, true},
+		{"No changes. Your infrastructure matches the configuration.", resourceCounts{}, true},
+		{"", resourceCounts{}, false},
+		{"Error: Invalid provider configuration", resourceCounts{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseSummary(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseSummary(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetectBackend(t *testing.T) {
+	t.Setenv("TF_DATA_DIR", "")
+
+	uninitialized := t.TempDir()
+	if got := detectBackend(uninitialized); got != "" {
+		t.Errorf("uninitialized dir: got backend %q, want empty", got)
+	}
+
+	local := t.TempDir()
+	if err := os.Mkdir(filepath.Join(local, ".terraform"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectBackend(local); got != "local" {
+		t.Errorf("initialized without backend: got %q, want local", got)
+	}
+
+	remote := t.TempDir()
+	if err := os.Mkdir(filepath.Join(remote, ".terraform"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	state := `{"version":3,"backend":{"type":"s3","config":{"bucket":"infra-state"}}}`
+	if err := os.WriteFile(filepath.Join(remote, ".terraform", "terraform.tfstate"), []byte(state), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := detectBackend(remote); got != "s3" {
+		t.Errorf("s3 backend: got %q, want s3", got)
+	}
+
+	t.Setenv("TF_DATA_DIR", filepath.Join(remote, ".terraform"))
+	if got := detectBackend(uninitialized); got != "s3" {
+		t.Errorf("TF_DATA_DIR override: got %q, want s3", got)
+	}
+}
+
+func TestBuildEvent(t *testing.T) {
+	event, err := buildEvent([]string{
+		"--operation", "apply",
+		"--workspace", "prod",
+		"--summary", "Apply complete! Resources: 2 added, 1 changed, 0 destroyed.",
+		"--duration-ms", "5400",
+		"--exit-code", "0",
+	})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+
+	if event.Source != "terraform" || event.Type != "terraform_apply" {
+		t.Errorf("got %s/%s, want terraform/terraform_apply", event.Source, event.Type)
+	}
+	if err := event.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+	if event.Payload["workspace"] != "prod" || event.Payload["add"] != 2 || event.Payload["change"] != 1 || event.Payload["destroy"] != 0 {
+		t.Errorf("unexpected payload: %v", event.Payload)
+	}
+	if event.Payload["duration_ms"] != int64(5400) {
+		t.Errorf("got duration_ms %v, want 5400", event.Payload["duration_ms"])
+	}
+
+	failed, err := buildEvent([]string{"--operation", "plan", "--exit-code", "1"})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if _, ok := failed.Payload["add"]; ok {
+		t.Error("failed plan without summary should not report resource counts")
+	}
+	if failed.Payload["workspace"] != "default" {
+		t.Errorf("got workspace %v, want default", failed.Payload["workspace"])
+	}
+
+	if _, err := buildEvent([]string{"--operation", "import"}); err == nil {
+		t.Error("buildEvent() expected error for unsupported operation")
+	}
+}
@@ -0,0 +1,128 @@
+package terraform
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"devlog/internal/assets"
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+)
+
+//go:embed hooks
+var hooksFS embed.FS
+
+type Module struct{}
+
+func (m *Module) Name() string {
+	return "terraform"
+}
+
+func (m *Module) Description() string {
+	return "Capture terraform plan, apply, and destroy runs with workspace, backend, and resource counts"
+}
+
+func (m *Module) Install(ctx *install.Context) error {
+	ctx.Log("Installing terraform command wrapper...")
+
+	binDir := filepath.Join(ctx.HomeDir, ".local", "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return &modules.InstallError{
+			Component: "terraform wrapper",
+			File:      binDir,
+			Err:       err,
+			RecoverySteps: []string{
+				fmt.Sprintf("Check directory permissions: ls -la %s", filepath.Dir(binDir)),
+				fmt.Sprintf("Try creating manually: mkdir -p %s", binDir),
+				"Check disk space: df -h",
+			},
+		}
+	}
+
+	wrapperPath := filepath.Join(binDir, "terraform")
+	if err := ctx.WriteAsset("terraform", "terraform-wrapper.sh", wrapperPath, nil); err != nil {
+		return &modules.InstallError{
+			Component: "terraform wrapper",
+			File:      wrapperPath,
+			Err:       err,
+			RecoverySteps: []string{
+				fmt.Sprintf("Check file permissions: ls -la %s", filepath.Dir(wrapperPath)),
+				"Ensure directory exists and is writable",
+				fmt.Sprintf("Try manual install: Save the wrapper script to %s and chmod +x %s", wrapperPath, wrapperPath),
+			},
+		}
+	}
+
+	ctx.Log("✓ Installed terraform wrapper to %s", wrapperPath)
+
+	cfg, err := config.Load()
+	if err == nil && cfg.IsModuleEnabled("shell") {
+		cfg.AddToShellIgnoreList("terraform")
+		if err := cfg.Save(); err == nil {
+			ctx.Log("✓ Added 'terraform' to shell module ignore list")
+		}
+	}
+
+	ctx.Log("")
+	ctx.Log("terraform plan, apply, and destroy runs will now be tracked.")
+	ctx.Log("")
+	ctx.Log("IMPORTANT: Ensure %s is in your PATH and appears BEFORE /usr/local/bin", binDir)
+	ctx.Log("Add this to your shell RC file:")
+	ctx.Log("")
+	ctx.Log("  export PATH=\"%s:$PATH\"", binDir)
+	ctx.Log("")
+	ctx.Log("Then restart your shell or run: source ~/.zshrc (or ~/.bashrc)")
+
+	return nil
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling terraform wrapper...")
+
+	wrapperPath := filepath.Join(ctx.HomeDir, ".local", "bin", "terraform")
+	if _, err := os.Stat(wrapperPath); err == nil {
+		if ctx.OwnsAsset("terraform", "terraform-wrapper.sh", wrapperPath) {
+			if err := ctx.RemoveAsset(wrapperPath); err != nil {
+				return fmt.Errorf("remove terraform wrapper: %w", err)
+			}
+			ctx.Log("✓ Removed terraform wrapper from %s", wrapperPath)
+		} else {
+			ctx.Log("Warning: terraform wrapper at %s doesn't match devlog's wrapper, skipping removal", wrapperPath)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err == nil && cfg.IsModuleEnabled("shell") {
+		cfg.RemoveFromShellIgnoreList("terraform")
+		if err := cfg.Save(); err == nil {
+			ctx.Log("✓ Removed 'terraform' from shell module ignore list")
+		}
+	}
+
+	return nil
+}
+
+func (m *Module) DefaultConfig() interface{} {
+	return map[string]interface{}{}
+}
+
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources: []string{string(events.SourceTerraform)},
+		Checks:  []modules.Check{modules.WrapperCheck(ctx, "terraform")},
+	}
+}
+
+func (m *Module) ValidateConfig(config interface{}) error {
+	return nil
+}
+
+func init() {
+	assets.RegisterFS("terraform", hooksFS, "hooks", "terraform-wrapper.sh")
+	events.RegisterPayloadSchema(payloadSchema)
+	modules.Register(&Module{})
+}
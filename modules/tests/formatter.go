@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"fmt"
+	"strings"
+
+	"devlog/internal/events"
+	"devlog/internal/formatting"
+)
+
+// maxFormattedFailures is how many failing tests are named in the one-line
+// format before the rest are counted.
+const maxFormattedFailures = 3
+
+type TestsFormatter struct{}
+
+func init() {
+	formatting.Register("tests", &TestsFormatter{})
+}
+
+func (f *TestsFormatter) Format(event *events.Event) string {
+	command, _ := event.Payload["command"].(string)
+	if command == "" {
+		command, _ = event.Payload["runner"].(string)
+	}
+
+	var counts []string
+	for _, kind := range []string{"failed", "passed", "skipped"} {
+		if n, ok := number(event.Payload[kind]); ok && (n > 0 || kind == "failed") {
+			counts = append(counts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	if failed, ok := number(event.Payload["packages_failed"]); ok && failed > 0 {
+		counts = append(counts, fmt.Sprintf("%d packages failed", failed))
+	}
+
+	result := command
+	if len(counts) > 0 {
+		result += ": " + strings.Join(counts, ", ")
+	}
+
+	if names := stringList(event.Payload["failed_tests"]); len(names) > 0 {
+		shown := names
+		if len(shown) > maxFormattedFailures {
+			shown = shown[:maxFormattedFailures]
+		}
+		result += " (" + strings.Join(shown, ", ")
+		if more := len(names) - len(shown); more > 0 {
+			result += fmt.Sprintf(" +%d more", more)
+		}
+		result += ")"
+	}
+
+	if ec, ok := number(event.Payload["exit_code"]); ok && ec != 0 {
+		result += fmt.Sprintf(" [exit:%d]", ec)
+	}
+
+	return result
+}
+
+func number(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func stringList(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/ingest"
+	"devlog/internal/vcs"
+
+	"github.com/urfave/cli/v2"
+)
+
+// maxOutputBytes is how much of the end of a run's output is parsed. Test
+// frameworks print their summary and failures last.
+const maxOutputBytes = 4 << 20
+
+type IngestHandler struct{}
+
+func (h *IngestHandler) CLICommand() *cli.Command {
+	return &cli.Command{
+		Name:  "tests",
+		Usage: "Ingest a test run (used by the test runner wrappers)",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "runner", Usage: "Wrapped command (go, pytest, npm, make)", Required: true},
+			&cli.StringFlag{Name: "command", Usage: "Full command line that was run"},
+			&cli.StringFlag{Name: "workdir", Usage: "Working directory"},
+			&cli.StringFlag{Name: "output-file", Usage: "File holding the run's combined output"},
+			&cli.Int64Flag{Name: "duration-ms", Usage: "Command duration in milliseconds"},
+			&cli.IntFlag{Name: "exit-code", Usage: "Command exit code", Value: 0},
+		},
+		Action: h.handle,
+	}
+}
+
+func (h *IngestHandler) handle(c *cli.Context) error {
+	args := []string{"--runner", c.String("runner")}
+	for _, name := range []string{"command", "workdir", "output-file"} {
+		if v := c.String(name); v != "" {
+			args = append(args, "--"+name, v)
+		}
+	}
+	if c.IsSet("duration-ms") {
+		args = append(args, "--duration-ms", c.String("duration-ms"))
+	}
+	if c.IsSet("exit-code") {
+		args = append(args, "--exit-code", c.String("exit-code"))
+	}
+	return h.ingestEvent(args)
+}
+
+func (h *IngestHandler) ingestEvent(args []string) error {
+	event, err := buildEvent(args)
+	if err != nil {
+		return err
+	}
+	return ingest.SendEvent(event)
+}
+
+// payloadSchema is checked by the daemon when test runs are ingested.
+var payloadSchema = events.PayloadSchema{
+	Source: string(events.SourceTests),
+	Types:  []events.EventType{events.TypeTestRun},
+	Fields: map[string]events.PayloadField{
+		"runner":          {Kind: events.KindString, Required: true},
+		"exit_code":       {Kind: events.KindNumber, Required: true},
+		"command":         {Kind: events.KindString},
+		"framework":       {Kind: events.KindString},
+		"passed":          {Kind: events.KindNumber},
+		"failed":          {Kind: events.KindNumber},
+		"skipped":         {Kind: events.KindNumber},
+		"failed_tests":    {Kind: events.KindStringList},
+		"packages_passed": {Kind: events.KindNumber},
+		"packages_failed": {Kind: events.KindNumber},
+		"failed_packages": {Kind: events.KindStringList},
+		"duration_ms":     {Kind: events.KindNumber},
+	},
+}
+
+func buildEvent(args []string) (*events.Event, error) {
+	fs := flag.NewFlagSet("tests-event", flag.ContinueOnError)
+	runner := fs.String("runner", "", "Wrapped command")
+	command := fs.String("command", "", "Full command line")
+	workdir := fs.String("workdir", "", "Working directory")
+	outputFile := fs.String("output-file", "", "File holding the run's output")
+	durationMs := fs.Int64("duration-ms", 0, "Command duration in milliseconds")
+	exitCode := fs.Int("exit-code", 0, "Command exit code")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if *runner == "" {
+		return nil, fmt.Errorf("--runner is required")
+	}
+
+	event := events.NewEvent(string(events.SourceTests), string(events.TypeTestRun))
+	event.Payload["runner"] = *runner
+	event.Payload["exit_code"] = *exitCode
+	if *command != "" {
+		event.Payload["command"] = *command
+	}
+	if *durationMs > 0 {
+		event.Payload["duration_ms"] = *durationMs
+		event.DurationMs = *durationMs
+	}
+	if *exitCode != 0 {
+		event.Severity = string(events.SeverityError)
+	}
+
+	if *outputFile != "" {
+		output, err := readTail(*outputFile, maxOutputBytes)
+		if err != nil {
+			return nil, fmt.Errorf("read test output: %w", err)
+		}
+		applyResult(event, parseOutput(output))
+	}
+
+	if *workdir != "" {
+		event.Payload["workdir"] = *workdir
+		if repo, err := vcs.Detect(*workdir); err == nil {
+			event.Repo = repo.Name
+			event.Branch = repo.Branch
+			event.Payload["vcs"] = string(repo.Kind)
+			if repo.Remote != "" {
+				event.Payload[config.RemotePayloadKey] = repo.Remote
+			}
+		}
+	}
+
+	return event, nil
+}
+
+func applyResult(event *events.Event, result testResult) {
+	if result.Framework == "" {
+		return
+	}
+	event.Payload["framework"] = result.Framework
+	for kind, n := range result.Counts {
+		event.Payload[kind] = n
+	}
+	if len(result.FailedTests) > 0 {
+		event.Payload["failed_tests"] = result.FailedTests
+	}
+	if result.PackagesPassed+result.PackagesFailed > 0 {
+		event.Payload["packages_passed"] = result.PackagesPassed
+		event.Payload["packages_failed"] = result.PackagesFailed
+	}
+	if len(result.FailedPackages) > 0 {
+		event.Payload["failed_packages"] = result.FailedPackages
+	}
+}
+
+func readTail(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > limit {
+		if _, err := f.Seek(-limit, io.SeekEnd); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func init() {
+	ingest.Register("tests", &IngestHandler{})
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		framework   string
+		counts      map[string]int
+		failedTests []string
+	}{
+		{
+			name: "go verbose",
+			output: "=== RUN   TestInsert\n--- PASS: TestInsert (0.01s)\n=== RUN   TestPrune\n" +
+				"=== RUN   TestPrune/old\n    --- FAIL: TestPrune/old (0.00s)\n--- FAIL: TestPrune (0.00s)\n" +
+				"--- SKIP: TestSlow (0.00s)\nFAIL\nFAIL\tdevlog/internal/storage\t0.210s\nok  \tdevlog/internal/api\t0.100s\n",
+			framework:   "go",
+			counts:      map[string]int{"passed": 1, "failed": 1, "skipped": 1},
+			failedTests: []string{"TestPrune/old", "TestPrune"},
+		},
+		{
+			name:      "go quiet and passing",
+			output:    "ok  \tdevlog/internal/api\t0.100s\n?   \tdevlog/cmd\t[no test files]\n",
+			framework: "go",
+			counts:    map[string]int{"failed": 0},
+		},
+		{
+			name: "pytest",
+			output: "tests/test_api.py ..F.\n=========================== short test summary info ============================\n" +
+				"FAILED tests/test_api.py::test_login - AssertionError: 401\n" +
+				"ERROR tests/test_db.py::test_connect\n" +
+				"=================== 1 failed, 3 passed, 1 skipped, 1 error in 0.52s ===================\n",
+			framework:   "pytest",
+			counts:      map[string]int{"passed": 3, "failed": 2, "skipped": 1},
+			failedTests: []string{"tests/test_api.py::test_login", "tests/test_db.py::test_connect"},
+		},
+		{
+			name:      "pytest quiet",
+			output:    "....\n4 passed in 0.12s\n",
+			framework: "pytest",
+			counts:    map[string]int{"passed": 4},
+		},
+		{
+			name: "jest",
+			output: "FAIL src/auth.test.js\n  ● Auth › rejects expired tokens\n\n    expect(received).toBe(expected)\n" +
+				"  ● Console\n\nTest Suites: 1 failed, 2 passed, 3 total\nTests:       1 failed, 1 skipped, 12 passed, 14 total\n",
+			framework:   "jest",
+			counts:      map[string]int{"passed": 12, "failed": 1, "skipped": 1},
+			failedTests: []string{"Auth › rejects expired tokens"},
+		},
+		{
+			name: "vitest",
+			output: "\x1b[31m FAIL \x1b[39m src/cart.test.ts > cart > applies discount\n" +
+				" Test Files  1 failed | 3 passed (4)\n      Tests  1 failed | 20 passed (21)\n",
+			framework:   "vitest",
+			counts:      map[string]int{"passed": 20, "failed": 1},
+			failedTests: []string{"src/cart.test.ts > cart > applies discount"},
+		},
+		{
+			name:      "mocha",
+			output:    "  api\n    ✓ lists users\n\n  5 passing (40ms)\n  2 pending\n  1 failing\n",
+			framework: "mocha",
+			counts:    map[string]int{"passed": 5, "failed": 1, "skipped": 2},
+		},
+		{
+			name:   "unrecognized",
+			output: "make: *** No rule to make target 'test'.  Stop.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseOutput(tt.output)
+			if got.Framework != tt.framework {
+				t.Errorf("framework = %q, want %q", got.Framework, tt.framework)
+			}
+			if tt.counts != nil && !reflect.DeepEqual(got.Counts, tt.counts) {
+				t.Errorf("counts = %v, want %v", got.Counts, tt.counts)
+			}
+			if !reflect.DeepEqual(got.FailedTests, tt.failedTests) {
+				t.Errorf("failed tests = %q, want %q", got.FailedTests, tt.failedTests)
+			}
+		})
+	}
+}
+
+func TestBuildEvent(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output")
+	output := "--- FAIL: TestPrune (0.00s)\nFAIL\tdevlog/internal/storage\t0.210s\nok  \tdevlog/internal/api\t0.100s\n"
+	if err := os.WriteFile(outputFile, []byte(output), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	event, err := buildEvent([]string{
+		"--runner", "make",
+		"--command", "make test",
+		"--output-file", outputFile,
+		"--duration-ms", "2100",
+		"--exit-code", "2",
+	})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+
+	if event.Source != "tests" || event.Type != "test_run" {
+		t.Errorf("got %s/%s, want tests/test_run", event.Source, event.Type)
+	}
+	if err := event.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+	if err := event.ValidatePayload(); err != nil {
+		t.Errorf("ValidatePayload() error: %v", err)
+	}
+	if event.Payload["framework"] != "go" || event.Payload["failed"] != 1 || event.Payload["packages_failed"] != 1 {
+		t.Errorf("unexpected payload: %v", event.Payload)
+	}
+	if _, ok := event.Payload["passed"]; ok {
+		t.Error("go test without -v should not report a passed count")
+	}
+	if event.Severity != "error" {
+		t.Errorf("severity = %q, want error", event.Severity)
+	}
+
+	want := "make test: 1 failed, 1 packages failed (TestPrune) [exit:2]"
+	if got := (&TestsFormatter{}).Format(event); got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+
+	if _, err := buildEvent([]string{"--command", "go test"}); err == nil {
+		t.Error("buildEvent() expected error without --runner")
+	}
+}
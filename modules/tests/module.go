@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"devlog/internal/assets"
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/modules"
+)
+
+//go:embed hooks
+var hooksFS embed.FS
+
+// runner is a command the module wraps. ignore lists the shell ignore_list
+// entries for its test invocations, so they are not also recorded as plain
+// shell commands.
+type runner struct {
+	name   string
+	ignore []string
+}
+
+var runners = []runner{
+	{name: "go", ignore: []string{"go test"}},
+	{name: "pytest", ignore: []string{"pytest"}},
+	{name: "npm", ignore: []string{"npm test", "npm run test"}},
+	{name: "make", ignore: []string{"make test", "make check"}},
+}
+
+type Module struct{}
+
+func (m *Module) Name() string {
+	return "tests"
+}
+
+func (m *Module) Description() string {
+	return "Capture go test, pytest, npm test, and make test runs with pass/fail counts and failing tests"
+}
+
+func (m *Module) Install(ctx *install.Context) error {
+	ctx.Log("Installing test runner wrappers...")
+
+	binDir := filepath.Join(ctx.HomeDir, ".local", "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return &modules.InstallError{
+			Component: "test runner wrappers",
+			File:      binDir,
+			Err:       err,
+			RecoverySteps: []string{
+				fmt.Sprintf("Check directory permissions: ls -la %s", filepath.Dir(binDir)),
+				fmt.Sprintf("Try creating manually: mkdir -p %s", binDir),
+				"Check disk space: df -h",
+			},
+		}
+	}
+
+	var ignore []string
+	for _, r := range runners {
+		wrapperPath := filepath.Join(binDir, r.name)
+		if _, err := exec.LookPath(r.name); err != nil {
+			if _, statErr := os.Stat(wrapperPath); statErr != nil {
+				ctx.Log("  %s not found in PATH, skipping", r.name)
+				continue
+			}
+		}
+
+		if err := ctx.WriteAsset("tests", "test-wrapper.sh", wrapperPath, map[string]string{"TOOL": r.name}); err != nil {
+			return &modules.InstallError{
+				Component: r.name + " wrapper",
+				File:      wrapperPath,
+				Err:       err,
+				RecoverySteps: []string{
+					fmt.Sprintf("Check file permissions: ls -la %s", filepath.Dir(wrapperPath)),
+					"Ensure directory exists and is writable",
+				},
+			}
+		}
+		ctx.Log("✓ Installed %s wrapper to %s", r.name, wrapperPath)
+		ignore = append(ignore, r.ignore...)
+	}
+
+	cfg, err := config.Load()
+	if err == nil && cfg.IsModuleEnabled("shell") && len(ignore) > 0 {
+		cfg.AddToShellIgnoreList(ignore...)
+		if err := cfg.Save(); err == nil {
+			ctx.Log("✓ Added test commands to shell module ignore list")
+		}
+	}
+
+	ctx.Log("")
+	ctx.Log("Test runs will now be recorded with their results. Other subcommands pass straight through.")
+	ctx.Log("")
+	ctx.Log("IMPORTANT: Ensure %s is in your PATH and appears BEFORE /usr/local/bin", binDir)
+	ctx.Log("Add this to your shell RC file:")
+	ctx.Log("")
+	ctx.Log("  export PATH=\"%s:$PATH\"", binDir)
+	ctx.Log("")
+	ctx.Log("Then restart your shell or run: source ~/.zshrc (or ~/.bashrc)")
+
+	return nil
+}
+
+func (m *Module) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling test runner wrappers...")
+
+	var ignore []string
+	for _, r := range runners {
+		ignore = append(ignore, r.ignore...)
+
+		wrapperPath := filepath.Join(ctx.HomeDir, ".local", "bin", r.name)
+		if _, err := os.Stat(wrapperPath); err != nil {
+			continue
+		}
+		if !ctx.OwnsAsset("tests", "test-wrapper.sh", wrapperPath) {
+			ctx.Log("Warning: %s at %s doesn't match devlog's wrapper, skipping removal", r.name, wrapperPath)
+			continue
+		}
+		if err := ctx.RemoveAsset(wrapperPath); err != nil {
+			return fmt.Errorf("remove %s wrapper: %w", r.name, err)
+		}
+		ctx.Log("✓ Removed %s wrapper from %s", r.name, wrapperPath)
+	}
+
+	cfg, err := config.Load()
+	if err == nil && cfg.IsModuleEnabled("shell") {
+		cfg.RemoveFromShellIgnoreList(ignore...)
+		if err := cfg.Save(); err == nil {
+			ctx.Log("✓ Removed test commands from shell module ignore list")
+		}
+	}
+
+	return nil
+}
+
+func (m *Module) DefaultConfig() interface{} {
+	return map[string]interface{}{}
+}
+
+// HealthCheck checks the wrappers Install actually wrote; runners that were
+// not installed at the time are skipped.
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	health := modules.Health{Sources: []string{string(events.SourceTests)}}
+	binDir := filepath.Join(ctx.HomeDir, ".local", "bin")
+	for _, r := range runners {
+		if _, err := os.Stat(filepath.Join(binDir, r.name)); err != nil {
+			continue
+		}
+		health.Checks = append(health.Checks, modules.WrapperCheck(ctx, r.name))
+	}
+	return health
+}
+
+func (m *Module) ValidateConfig(config interface{}) error {
+	return nil
+}
+
+func init() {
+	assets.RegisterFS("tests", hooksFS, "hooks", "test-wrapper.sh")
+	events.RegisterPayloadSchema(payloadSchema)
+	modules.Register(&Module{})
+}
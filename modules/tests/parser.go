@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxFailedTests caps how many failing test names are kept per run; the
+// failed count still reports the total.
+const maxFailedTests = 20
+
+var (
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+	goResultPattern = regexp.MustCompile(`^\s*--- (PASS|FAIL|SKIP): (\S+)`)
+	goRunPattern    = regexp.MustCompile(`^=== RUN\s`)
+	goPackageOK     = regexp.MustCompile(`^ok\s+(\S+)\t`)
+	goPackageFailed = regexp.MustCompile(`^FAIL\t(\S+)`)
+	pytestSummary   = regexp.MustCompile(`^=*\s*((?:\d+ \w+(?:, )?)+) in [\d.]+s`)
+	pytestFailed    = regexp.MustCompile(`^(?:FAILED|ERROR) (\S+)`)
+	jestSummary     = regexp.MustCompile(`^Tests:\s+(.+)`)
+	jestFailed      = regexp.MustCompile(`^\s*● (.+)$`)
+	vitestSummary   = regexp.MustCompile(`^\s*Tests\s+(\d+ \w+(?: \| \d+ \w+)*)`)
+	vitestFailed    = regexp.MustCompile(`^\s*FAIL\s+(\S+ > .+)$`)
+	mochaCount      = regexp.MustCompile(`^\s+(\d+) (passing|failing|pending)\b`)
+	countPattern    = regexp.MustCompile(`(\d+) (\w+)`)
+)
+
+// testResult is what could be read from a test run's output. Counts only
+// holds the counts the output reported: go test without -v prints no
+// passing tests, so "passed" is missing rather than zero.
+type testResult struct {
+	Framework      string
+	Counts         map[string]int
+	FailedTests    []string
+	PackagesPassed int
+	PackagesFailed int
+	FailedPackages []string
+}
+
+// parseOutput recognizes go test, pytest, jest, vitest and mocha output.
+// Runs through make or npm are recognized by the runner they invoke.
+func parseOutput(output string) testResult {
+	lines := strings.Split(ansiPattern.ReplaceAllString(output, ""), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+
+	for _, parse := range []func([]string) (testResult, bool){parseGo, parsePytest, parseJest, parseVitest, parseMocha} {
+		if result, ok := parse(lines); ok {
+			return result
+		}
+	}
+	return testResult{}
+}
+
+func parseGo(lines []string) (testResult, bool) {
+	result := testResult{Framework: "go", Counts: map[string]int{}}
+	verbose, seen := false, false
+
+	for _, line := range lines {
+		if goRunPattern.MatchString(line) {
+			verbose = true
+			continue
+		}
+		if m := goResultPattern.FindStringSubmatch(line); m != nil {
+			seen = true
+			// Subtests are reported under their parent; count top-level
+			// tests only but list failing subtests by name.
+			topLevel := !strings.Contains(m[2], "/")
+			switch m[1] {
+			case "PASS":
+				if topLevel {
+					result.Counts["passed"]++
+				}
+			case "FAIL":
+				if topLevel {
+					result.Counts["failed"]++
+				}
+				result.addFailedTest(m[2])
+			case "SKIP":
+				if topLevel {
+					result.Counts["skipped"]++
+				}
+			}
+			continue
+		}
+		if m := goPackageOK.FindStringSubmatch(line); m != nil {
+			seen = true
+			result.PackagesPassed++
+			continue
+		}
+		if m := goPackageFailed.FindStringSubmatch(line); m != nil {
+			seen = true
+			result.PackagesFailed++
+			result.FailedPackages = append(result.FailedPackages, m[1])
+		}
+	}
+
+	if !seen {
+		return testResult{}, false
+	}
+	if !verbose {
+		// Without -v only failures are printed.
+		result.Counts = map[string]int{"failed": result.Counts["failed"]}
+	}
+	return result, true
+}
+
+func parsePytest(lines []string) (testResult, bool) {
+	result := testResult{Framework: "pytest"}
+	for _, line := range lines {
+		if m := pytestFailed.FindStringSubmatch(line); m != nil {
+			result.addFailedTest(m[1])
+			continue
+		}
+		if m := pytestSummary.FindStringSubmatch(line); m != nil {
+			result.Counts = parseCounts(m[1], map[string]string{
+				"passed": "passed", "failed": "failed", "error": "failed", "errors": "failed",
+				"skipped": "skipped", "xfailed": "skipped", "deselected": "skipped", "xpassed": "passed",
+			})
+		}
+	}
+	return result, result.Counts != nil
+}
+
+func parseJest(lines []string) (testResult, bool) {
+	result := testResult{Framework: "jest"}
+	for _, line := range lines {
+		if m := jestFailed.FindStringSubmatch(line); m != nil {
+			if name := strings.TrimSpace(m[1]); !strings.HasPrefix(name, "Console") {
+				result.addFailedTest(name)
+			}
+			continue
+		}
+		if m := jestSummary.FindStringSubmatch(line); m != nil {
+			result.Counts = parseCounts(m[1], map[string]string{
+				"passed": "passed", "failed": "failed", "skipped": "skipped", "todo": "skipped",
+			})
+		}
+	}
+	return result, result.Counts != nil
+}
+
+func parseVitest(lines []string) (testResult, bool) {
+	result := testResult{Framework: "vitest"}
+	for _, line := range lines {
+		if m := vitestFailed.FindStringSubmatch(line); m != nil {
+			result.addFailedTest(strings.TrimSpace(m[1]))
+			continue
+		}
+		if m := vitestSummary.FindStringSubmatch(line); m != nil {
+			result.Counts = parseCounts(m[1], map[string]string{
+				"passed": "passed", "failed": "failed", "skipped": "skipped", "todo": "skipped",
+			})
+		}
+	}
+	return result, result.Counts != nil
+}
+
+func parseMocha(lines []string) (testResult, bool) {
+	result := testResult{Framework: "mocha"}
+	kinds := map[string]string{"passing": "passed", "failing": "failed", "pending": "skipped"}
+	for _, line := range lines {
+		if m := mochaCount.FindStringSubmatch(line); m != nil {
+			if result.Counts == nil {
+				result.Counts = map[string]int{}
+			}
+			n, _ := strconv.Atoi(m[1])
+			result.Counts[kinds[m[2]]] = n
+		}
+	}
+	return result, result.Counts != nil
+}
+
+// parseCounts reads "3 failed, 10 passed" style lists, mapping each
+// framework's words onto passed, failed and skipped.
+func parseCounts(text string, kinds map[string]string) map[string]int {
+	counts := map[string]int{}
+	for _, m := range countPattern.FindAllStringSubmatch(text, -1) {
+		kind, ok := kinds[m[2]]
+		if !ok {
+			continue
+		}
+		n, _ := strconv.Atoi(m[1])
+		counts[kind] += n
+	}
+	return counts
+}
+
+func (r *testResult) addFailedTest(name string) {
+	for _, existing := range r.FailedTests {
+		if existing == name {
+			return
+		}
+	}
+	if len(r.FailedTests) < maxFailedTests {
+		r.FailedTests = append(r.FailedTests, name)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"flag"
 	"fmt"
 
+	"devlog/internal/config"
 	"devlog/internal/events"
 	"devlog/internal/ingest"
+	"devlog/internal/vcs"
 
 	"github.com/urfave/cli/v2"
 )
@@ -61,6 +63,14 @@ func (h *IngestHandler) handle(c *cli.Context) error {
 }
 
 func (h *IngestHandler) ingestEvent(args []string) error {
+	event, err := buildEvent(args)
+	if err != nil {
+		return err
+	}
+	return ingest.SendEvent(event)
+}
+
+func buildEvent(args []string) (*events.Event, error) {
 	fs := flag.NewFlagSet("tmux-event", flag.ExitOnError)
 	eventType := fs.String("type", "", "Event type (session, attach, detach, session-switch, window, pane)")
 	action := fs.String("action", "", "Action (create, close, rename, split)")
@@ -75,7 +85,7 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	fs.Parse(args)
 
 	if *eventType == "" {
-		return fmt.Errorf("--type is required")
+		return nil, fmt.Errorf("--type is required")
 	}
 
 	var typeConstant string
@@ -93,7 +103,7 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 	case "pane":
 		typeConstant = string(events.TypeTmuxPane)
 	default:
-		return fmt.Errorf("unknown event type: %s", *eventType)
+		return nil, fmt.Errorf("unknown event type: %s", *eventType)
 	}
 
 	event := events.NewEvent(string(events.SourceTmux), typeConstant)
@@ -122,15 +132,17 @@ func (h *IngestHandler) ingestEvent(args []string) error {
 
 	if *panePath != "" {
 		event.Payload["pane_path"] = *panePath
-		if repoPath, err := ingest.FindGitRepo(*panePath); err == nil {
-			event.Repo = repoPath
-			if branch, err := ingest.FindGitBranch(*panePath); err == nil {
-				event.Branch = branch
+		if repo, err := vcs.Detect(*panePath); err == nil {
+			event.Repo = repo.Name
+			event.Branch = repo.Branch
+			event.Payload["vcs"] = string(repo.Kind)
+			if repo.Remote != "" {
+				event.Payload[config.RemotePayloadKey] = repo.Remote
 			}
 		}
 	}
 
-	return ingest.SendEvent(event)
+	return event, nil
 }
 
 func init() {
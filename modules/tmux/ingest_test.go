@@ -0,0 +1,45 @@
+package tmux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"devlog/internal/config"
+)
+
+// makeRepo creates a git checkout on main whose origin is url.
+func makeRepo(t *testing.T, url string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	gitConfig := "[remote \"origin\"]\n\turl = " + url + "\n"
+	if err := os.WriteFile(filepath.Join(root, ".git", "config"), []byte(gitConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestBuildEventRecordsRemote(t *testing.T) {
+	root := makeRepo(t, "git@github.com:acme/api.git")
+
+	event, err := buildEvent([]string{"--type", "pane", "--action", "split", "--session", "dev", "--pane-path", root})
+	if err != nil {
+		t.Fatalf("buildEvent() error: %v", err)
+	}
+	if event.Repo != filepath.Base(root) || event.Branch != "main" {
+		t.Errorf("repo = %q branch = %q, want %q main", event.Repo, event.Branch, filepath.Base(root))
+	}
+	if got := event.Payload[config.RemotePayloadKey]; got != "github.com/acme/api" {
+		t.Errorf("%s = %v, want github.com/acme/api", config.RemotePayloadKey, got)
+	}
+
+	if _, err := buildEvent([]string{"--type", "bogus"}); err == nil {
+		t.Error("buildEvent() expected error for an unknown type")
+	}
+}
@@ -1,26 +1,23 @@
 package tmux
 
 import (
-	_ "embed"
+	"embed"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"devlog/internal/assets"
 	"devlog/internal/config"
 	"devlog/internal/install"
 	"devlog/internal/modules"
+	"devlog/internal/poller"
 )
 
-//go:embed hooks/tmux-hooks.conf
-var tmuxHooksConfig string
-
-//go:embed hooks/devlog-tmux-wrapper.sh
-var tmuxWrapperScript string
-
-//go:embed hooks/devlog-tmux-common.sh
-var tmuxCommonLib string
+//go:embed hooks
+var hooksFS embed.FS
 
 type Module struct{}
 
@@ -54,7 +51,7 @@ func (m *Module) Install(ctx *install.Context) error {
 	}
 
 	commonLibPath := filepath.Join(configDir, "devlog-tmux-common.sh")
-	if err := os.WriteFile(commonLibPath, []byte(tmuxCommonLib), 0644); err != nil {
+	if err := ctx.WriteAsset("tmux", "devlog-tmux-common.sh", commonLibPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "tmux common library",
 			File:      commonLibPath,
@@ -68,7 +65,7 @@ func (m *Module) Install(ctx *install.Context) error {
 	}
 
 	wrapperPath := filepath.Join(configDir, "devlog-tmux-wrapper.sh")
-	if err := os.WriteFile(wrapperPath, []byte(tmuxWrapperScript), 0755); err != nil {
+	if err := ctx.WriteAsset("tmux", "devlog-tmux-wrapper.sh", wrapperPath, nil); err != nil {
 		return &modules.InstallError{
 			Component: "tmux wrapper script",
 			File:      wrapperPath,
@@ -85,9 +82,8 @@ func (m *Module) Install(ctx *install.Context) error {
 	ctx.Log("✓ Installed wrapper script to %s", wrapperPath)
 
 	hooksPath := filepath.Join(configDir, "tmux-hooks.conf")
-	// Replace placeholder with actual wrapper path
-	hooksConfig := strings.ReplaceAll(tmuxHooksConfig, "{{WRAPPER_PATH}}", wrapperPath)
-	if err := os.WriteFile(hooksPath, []byte(hooksConfig), 0644); err != nil {
+	vars := map[string]string{"WRAPPER_PATH": wrapperPath}
+	if err := ctx.WriteAsset("tmux", "tmux-hooks.conf", hooksPath, vars); err != nil {
 		return &modules.InstallError{
 			Component: "tmux integration",
 			File:      hooksPath,
@@ -142,21 +138,21 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 	commonLibPath := filepath.Join(configDir, "devlog-tmux-common.sh")
 
 	if _, err := os.Stat(hooksPath); err == nil {
-		if err := os.Remove(hooksPath); err != nil {
+		if err := ctx.RemoveAsset(hooksPath); err != nil {
 			return fmt.Errorf("remove tmux hooks config: %w", err)
 		}
 		ctx.Log("✓ Removed tmux hooks config from %s", hooksPath)
 	}
 
 	if _, err := os.Stat(wrapperPath); err == nil {
-		if err := os.Remove(wrapperPath); err != nil {
+		if err := ctx.RemoveAsset(wrapperPath); err != nil {
 			return fmt.Errorf("remove tmux wrapper script: %w", err)
 		}
 		ctx.Log("✓ Removed wrapper script from %s", wrapperPath)
 	}
 
 	if _, err := os.Stat(commonLibPath); err == nil {
-		if err := os.Remove(commonLibPath); err != nil {
+		if err := ctx.RemoveAsset(commonLibPath); err != nil {
 			return fmt.Errorf("remove tmux common library: %w", err)
 		}
 		ctx.Log("✓ Removed common library from %s", commonLibPath)
@@ -190,16 +186,38 @@ func (m *Module) Uninstall(ctx *install.Context) error {
 }
 
 func (m *Module) DefaultConfig() interface{} {
-	return map[string]interface{}{}
+	return map[string]interface{}{
+		"sampling": map[string]interface{}{
+			"enabled":          false,
+			"interval_seconds": defaultSampleInterval,
+			"max_lines":        defaultSampleMaxLines,
+			"min_new_lines":    defaultSampleMinLines,
+		},
+	}
+}
+
+func (m *Module) HealthCheck(ctx *install.Context) modules.Health {
+	return modules.Health{
+		Sources:    []string{"tmux"},
+		QuietAfter: 7 * 24 * time.Hour,
+	}
 }
 
 func (m *Module) ValidateConfig(config interface{}) error {
-	_, ok := config.(map[string]interface{})
+	cfg, ok := config.(map[string]interface{})
 	if !ok {
 		return fmt.Errorf("config must be a map")
 	}
 
-	return nil
+	return validateSamplingConfig(cfg)
+}
+
+func (m *Module) CreatePoller(config map[string]interface{}, dataDir string) (poller.Poller, error) {
+	cfg := parseSamplingConfig(config)
+	if !cfg.Enabled {
+		return nil, modules.ErrPollerDisabled
+	}
+	return NewSampler(cfg), nil
 }
 
 func addToTmuxConf(ctx *install.Context, tmuxConfPath, sourceLine string) error {
@@ -351,5 +369,6 @@ func checkTmuxInstalled() error {
 }
 
 func init() {
+	assets.RegisterFS("tmux", hooksFS, "hooks", "devlog-tmux-wrapper.sh")
 	modules.Register(&Module{})
 }
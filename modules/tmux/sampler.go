@@ -0,0 +1,335 @@
+package tmux
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/secrets"
+	"devlog/internal/vcs"
+)
+
+const (
+	defaultSampleInterval  = 60
+	defaultSampleMaxLines  = 200
+	defaultSampleMinLines  = 3
+	paneListFormat         = "#{pane_id}\t#{session_name}\t#{window_name}\t#{pane_current_command}\t#{pane_current_path}\t#{alternate_on}"
+	sampleCommandsWildcard = "*"
+)
+
+// defaultSampleCommands are interactive programs whose output says what was
+// being worked on but never reaches the shell module's command history.
+var defaultSampleCommands = []string{
+	"python", "python3", "ipython", "node", "irb", "pry", "ghci", "iex", "erl", "lua", "R", "julia",
+	"psql", "mysql", "sqlite3", "redis-cli", "mongosh",
+	"gdb", "lldb", "dlv", "pdb", "rdbg",
+}
+
+// SamplingConfig is the optional sampling block of the tmux module config.
+type SamplingConfig struct {
+	Enabled         bool
+	IntervalSeconds int
+	MaxLines        int
+	MinNewLines     int
+	Commands        []string
+}
+
+func parseSamplingConfig(config map[string]interface{}) SamplingConfig {
+	cfg := SamplingConfig{
+		IntervalSeconds: defaultSampleInterval,
+		MaxLines:        defaultSampleMaxLines,
+		MinNewLines:     defaultSampleMinLines,
+		Commands:        defaultSampleCommands,
+	}
+
+	raw, ok := config["sampling"].(map[string]interface{})
+	if !ok {
+		return cfg
+	}
+
+	cfg.Enabled, _ = raw["enabled"].(bool)
+	if n, ok := numberValue(raw["interval_seconds"]); ok {
+		cfg.IntervalSeconds = n
+	}
+	if n, ok := numberValue(raw["max_lines"]); ok {
+		cfg.MaxLines = n
+	}
+	if n, ok := numberValue(raw["min_new_lines"]); ok {
+		cfg.MinNewLines = n
+	}
+	if list, ok := raw["commands"].([]interface{}); ok {
+		cfg.Commands = nil
+		for _, c := range list {
+			if name, ok := c.(string); ok {
+				cfg.Commands = append(cfg.Commands, name)
+			}
+		}
+	}
+	return cfg
+}
+
+func validateSamplingConfig(config map[string]interface{}) error {
+	val, ok := config["sampling"]
+	if !ok || val == nil {
+		return nil
+	}
+	raw, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("sampling must be a map")
+	}
+	for _, key := range []string{"interval_seconds", "max_lines", "min_new_lines"} {
+		if v, ok := raw[key]; ok {
+			if _, ok := numberValue(v); !ok {
+				return fmt.Errorf("sampling.%s must be a number", key)
+			}
+		}
+	}
+
+	cfg := parseSamplingConfig(config)
+	if cfg.IntervalSeconds < 10 || cfg.IntervalSeconds > 3600 {
+		return fmt.Errorf("sampling.interval_seconds must be between 10 and 3600")
+	}
+	if cfg.MaxLines < 10 || cfg.MaxLines > 2000 {
+		return fmt.Errorf("sampling.max_lines must be between 10 and 2000")
+	}
+	if cfg.MinNewLines < 1 || cfg.MinNewLines > cfg.MaxLines {
+		return fmt.Errorf("sampling.min_new_lines must be between 1 and max_lines")
+	}
+	if len(cfg.Commands) == 0 {
+		return fmt.Errorf("sampling.commands must list at least one command (or %q for all)", sampleCommandsWildcard)
+	}
+	return nil
+}
+
+func numberValue(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+type paneInfo struct {
+	ID        string
+	Session   string
+	Window    string
+	Command   string
+	Path      string
+	Alternate bool
+}
+
+type paneSnapshot struct {
+	hash  string
+	lines []string
+}
+
+// Sampler periodically captures the visible history of tmux panes running
+// interactive programs and emits what was added since the previous sample.
+// Snapshots are only kept in memory: after a daemon restart each pane's
+// first capture is a baseline and emits nothing.
+type Sampler struct {
+	cfg       SamplingConfig
+	run       func(ctx context.Context, args ...string) ([]byte, error)
+	snapshots map[string]paneSnapshot
+}
+
+func NewSampler(cfg SamplingConfig) *Sampler {
+	return &Sampler{
+		cfg:       cfg,
+		run:       runTmux,
+		snapshots: make(map[string]paneSnapshot),
+	}
+}
+
+func runTmux(ctx context.Context, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, "tmux", args...).Output()
+}
+
+func (s *Sampler) Name() string {
+	return "tmux"
+}
+
+func (s *Sampler) PollInterval() time.Duration {
+	return time.Duration(s.cfg.IntervalSeconds) * time.Second
+}
+
+func (s *Sampler) Poll(ctx context.Context) ([]*events.Event, error) {
+	out, err := s.run(ctx, "list-panes", "-a", "-F", paneListFormat)
+	if err != nil {
+		// No server running means nothing to sample, not a failure.
+		s.snapshots = make(map[string]paneSnapshot)
+		return nil, nil
+	}
+
+	panes := parsePanes(string(out))
+	seen := make(map[string]bool, len(panes))
+
+	var result []*events.Event
+	for _, pane := range panes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if pane.Alternate || !s.shouldSample(pane.Command) {
+			continue
+		}
+		seen[pane.ID] = true
+
+		capture, err := s.run(ctx, "capture-pane", "-p", "-J", "-t", pane.ID, "-S", fmt.Sprintf("-%d", s.cfg.MaxLines))
+		if err != nil {
+			continue
+		}
+		if event := s.sample(pane, normalizeCapture(string(capture))); event != nil {
+			result = append(result, event)
+		}
+	}
+
+	for id := range s.snapshots {
+		if !seen[id] {
+			delete(s.snapshots, id)
+		}
+	}
+	return result, nil
+}
+
+func (s *Sampler) shouldSample(command string) bool {
+	for _, c := range s.cfg.Commands {
+		if c == sampleCommandsWildcard {
+			return !isShell(command)
+		}
+		if strings.EqualFold(c, command) {
+			return true
+		}
+	}
+	return false
+}
+
+func isShell(command string) bool {
+	switch filepath.Base(command) {
+	case "bash", "zsh", "fish", "sh", "dash", "ksh", "tcsh", "nu", "-bash", "-zsh":
+		return true
+	}
+	return false
+}
+
+// sample compares a capture with the pane's previous one and returns an
+// event holding the new lines, or nil when nothing worth recording changed.
+func (s *Sampler) sample(pane paneInfo, lines []string) *events.Event {
+	hash := hashLines(lines)
+	prev, known := s.snapshots[pane.ID]
+	if known && prev.hash == hash {
+		return nil
+	}
+	if !known {
+		s.snapshots[pane.ID] = paneSnapshot{hash: hash, lines: lines}
+		return nil
+	}
+
+	added := newLines(prev.lines, lines)
+	if len(added) < s.cfg.MinNewLines {
+		// Keep the old baseline so small changes add up to a sample.
+		return nil
+	}
+	s.snapshots[pane.ID] = paneSnapshot{hash: hash, lines: lines}
+
+	text := secrets.Redact(strings.Join(added, "\n"))
+
+	event := events.NewEvent(string(events.SourceTmux), string(events.TypeTmuxSample))
+	event.Payload["session"] = pane.Session
+	event.Payload["window"] = pane.Window
+	event.Payload["pane_id"] = pane.ID
+	event.Payload["command"] = pane.Command
+	event.Payload["output"] = text
+	event.Payload["line_count"] = len(added)
+	event.Payload["hash"] = hash
+	if pane.Path != "" {
+		event.Payload["pane_path"] = pane.Path
+		if repo, err := vcs.Detect(pane.Path); err == nil {
+			event.Repo = repo.Name
+			event.Branch = repo.Branch
+			event.Payload["vcs"] = string(repo.Kind)
+			if repo.Remote != "" {
+				event.Payload[config.RemotePayloadKey] = repo.Remote
+			}
+		}
+	}
+	return event
+}
+
+func parsePanes(out string) []paneInfo {
+	var panes []paneInfo
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 || fields[0] == "" {
+			continue
+		}
+		panes = append(panes, paneInfo{
+			ID:        fields[0],
+			Session:   fields[1],
+			Window:    fields[2],
+			Command:   fields[3],
+			Path:      fields[4],
+			Alternate: fields[5] == "1",
+		})
+	}
+	return panes
+}
+
+// normalizeCapture drops trailing whitespace and the blank rows tmux pads
+// the visible screen with, so an idle prompt hashes the same every time.
+func normalizeCapture(capture string) []string {
+	lines := strings.Split(capture, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// newLines returns the lines of cur that follow the part it shares with
+// prev. Scrolling drops lines off the top of prev, so the overlap is the
+// longest suffix of prev that cur starts with. The last line of prev is
+// usually the prompt being typed on, so a second attempt ignores it. With no
+// overlap (the screen was cleared) everything in cur is new.
+func newLines(prev, cur []string) []string {
+	bases := [][]string{prev}
+	if len(prev) > 1 {
+		bases = append(bases, prev[:len(prev)-1])
+	}
+	for _, base := range bases {
+		for start := 0; start < len(base); start++ {
+			overlap := base[start:]
+			if len(overlap) > len(cur) {
+				continue
+			}
+			if equalLines(overlap, cur[:len(overlap)]) {
+				return cur[len(overlap):]
+			}
+		}
+	}
+	return cur
+}
+
+func equalLines(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func hashLines(lines []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
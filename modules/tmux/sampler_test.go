@@ -0,0 +1,181 @@
+package tmux
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/modules"
+)
+
+type fakeTmux struct {
+	panes    string
+	captures map[string]string
+}
+
+func (f *fakeTmux) run(ctx context.Context, args ...string) ([]byte, error) {
+	switch args[0] {
+	case "list-panes":
+		if f.panes == "" {
+			return nil, fmt.Errorf("no server running")
+		}
+		return []byte(f.panes), nil
+	case "capture-pane":
+		return []byte(f.captures[args[4]]), nil
+	}
+	return nil, fmt.Errorf("unexpected tmux command %v", args)
+}
+
+func newTestSampler(fake *fakeTmux, minNew int) *Sampler {
+	cfg := parseSamplingConfig(map[string]interface{}{})
+	cfg.MinNewLines = minNew
+	s := NewSampler(cfg)
+	s.run = fake.run
+	return s
+}
+
+func TestSamplerEmitsNewLines(t *testing.T) {
+	fake := &fakeTmux{
+		panes: "%1\tdev\trepl\tpython3\t/tmp\t0\n" +
+			"%2\tdev\tshell\tzsh\t/tmp\t0\n" +
+			"%3\tdev\tedit\tnode\t/tmp\t1\n",
+		captures: map[string]string{
+			"%1": ">>> import json\n>>> \n\n\n",
+			"%2": "$ ls\n",
+			"%3": "full screen app",
+		},
+	}
+	s := newTestSampler(fake, 2)
+	ctx := context.Background()
+
+	evts, err := s.Poll(ctx)
+	if err != nil || len(evts) != 0 {
+		t.Fatalf("first Poll() = %d events, %v; want a silent baseline", len(evts), err)
+	}
+
+	// Unchanged and trailing-blank-only changes are ignored.
+	fake.captures["%1"] = ">>> import json\n>>>   \n"
+	if evts, _ := s.Poll(ctx); len(evts) != 0 {
+		t.Fatalf("unchanged pane produced %d events", len(evts))
+	}
+
+	// One new line is below min_new_lines and is held back.
+	fake.captures["%1"] = ">>> import json\n>>> data = load()\n"
+	if evts, _ := s.Poll(ctx); len(evts) != 0 {
+		t.Fatalf("got %d events below min_new_lines", len(evts))
+	}
+
+	fake.captures["%1"] = ">>> import json\n>>> data = load()\n>>> data['password'] = 'hunter2'\n>>> len(data)\n3\n>>> "
+	evts, err = s.Poll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evts) != 1 {
+		t.Fatalf("got %d events, want 1", len(evts))
+	}
+
+	evt := evts[0]
+	if evt.Type != string(events.TypeTmuxSample) || evt.Payload["pane_id"] != "%1" || evt.Payload["command"] != "python3" {
+		t.Errorf("unexpected event %+v", evt)
+	}
+	output := evt.Payload["output"].(string)
+	if strings.Contains(output, "import json") {
+		t.Errorf("output repeats the baseline:\n%s", output)
+	}
+	if !strings.Contains(output, "data = load()") || !strings.Contains(output, "len(data)") {
+		t.Errorf("output missing new lines:\n%s", output)
+	}
+	if strings.Contains(output, "hunter2") {
+		t.Errorf("output not redacted:\n%s", output)
+	}
+	if err := evt.Validate(); err != nil {
+		t.Errorf("event does not validate: %v", err)
+	}
+}
+
+func TestSampleRecordsRemote(t *testing.T) {
+	root := makeRepo(t, "https://github.com/acme/api.git")
+	s := newTestSampler(&fakeTmux{}, 1)
+
+	pane := paneInfo{ID: "%1", Session: "dev", Window: "repl", Command: "python3", Path: root}
+	s.sample(pane, []string{">>> "})
+	event := s.sample(pane, []string{">>> 1 + 1", "2"})
+	if event == nil {
+		t.Fatal("sample() = nil after new output")
+	}
+	if event.Repo != filepath.Base(root) {
+		t.Errorf("repo = %q, want %q", event.Repo, filepath.Base(root))
+	}
+	if got := event.Payload[config.RemotePayloadKey]; got != "github.com/acme/api" {
+		t.Errorf("%s = %v, want github.com/acme/api", config.RemotePayloadKey, got)
+	}
+}
+
+func TestSamplerTmuxNotRunning(t *testing.T) {
+	s := newTestSampler(&fakeTmux{}, 1)
+	evts, err := s.Poll(context.Background())
+	if err != nil || len(evts) != 0 {
+		t.Errorf("Poll() without tmux = %d events, %v; want none and no error", len(evts), err)
+	}
+}
+
+func TestNewLines(t *testing.T) {
+	tests := []struct {
+		prev, cur, want string
+	}{
+		{"a|b|c", "a|b|c|d", "d"},
+		{"a|b|c", "b|c|d|e", "d|e"},
+		{"a|b|c", "x|y", "x|y"},
+		{"", "a", "a"},
+	}
+	split := func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, "|")
+	}
+	for _, tt := range tests {
+		got := strings.Join(newLines(split(tt.prev), split(tt.cur)), "|")
+		if got != tt.want {
+			t.Errorf("newLines(%s, %s) = %s, want %s", tt.prev, tt.cur, got, tt.want)
+		}
+	}
+}
+
+func TestSamplingConfig(t *testing.T) {
+	m := &Module{}
+	if err := m.ValidateConfig(m.DefaultConfig()); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+	if _, err := m.CreatePoller(m.DefaultConfig().(map[string]interface{}), t.TempDir()); err != modules.ErrPollerDisabled {
+		t.Errorf("CreatePoller() with sampling disabled error = %v, want ErrPollerDisabled", err)
+	}
+
+	invalid := []map[string]interface{}{
+		{"interval_seconds": 1},
+		{"max_lines": 5},
+		{"min_new_lines": 0},
+		{"commands": []interface{}{}},
+	}
+	for _, sampling := range invalid {
+		if err := m.ValidateConfig(map[string]interface{}{"sampling": sampling}); err == nil {
+			t.Errorf("ValidateConfig(%v) expected error", sampling)
+		}
+	}
+
+	p, err := m.CreatePoller(map[string]interface{}{"sampling": map[string]interface{}{
+		"enabled":  true,
+		"commands": []interface{}{"*"},
+	}}, t.TempDir())
+	if err != nil {
+		t.Fatalf("CreatePoller() error: %v", err)
+	}
+	s := p.(*Sampler)
+	if !s.shouldSample("cargo") || s.shouldSample("zsh") {
+		t.Error("wildcard should sample everything except shells")
+	}
+}
@@ -0,0 +1,118 @@
+// Package apiclient is a typed client for the devlog daemon's HTTP API,
+// generated from the OpenAPI document the daemon serves at
+// /api/v1/openapi.json. Every endpoint except the event stream has a method.
+//
+// Unlike pkg/devlog it does not queue events while the daemon is down; it is
+// a thin wrapper over the HTTP API for scripts and tools. devlog.ts is the
+// same client for TypeScript.
+package apiclient
+
+//go:generate go run devlog/internal/api/apigen -go client_gen.go -ts devlog.ts -spec openapi.json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "http://localhost:8573"
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Token is sent as a bearer token, for daemons with http.auth_enabled.
+	Token string
+}
+
+// New returns a client for the daemon at baseURL, or DefaultBaseURL when it
+// is empty.
+func New(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		BaseURL:    strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		Token:      token,
+	}
+}
+
+// APIError is a non-2xx answer from the daemon.
+type APIError struct {
+	StatusCode int
+	Message    string
+	// Queue is set when the daemon asks callers to queue the event and
+	// retry, e.g. while it shuts down.
+	Queue bool
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("devlog API: %s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, contentType string, body []byte, out interface{}) error {
+	target := c.BaseURL + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var errResp ErrorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+			apiErr.Message, apiErr.Queue = errResp.Error, errResp.Queue
+		}
+		return apiErr
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// encodeNDJSON encodes items as newline-delimited JSON for batch ingestion.
+func encodeNDJSON[T any](items []T) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
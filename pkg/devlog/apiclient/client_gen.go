@@ -0,0 +1,865 @@
+// Code generated by apigen from the devlog OpenAPI spec. DO NOT EDIT.
+
+package apiclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+)
+
+type AddAnnotationRequest struct {
+	Text string `json:"text"`
+}
+
+type AnnotationResponse struct {
+	CreatedAt string `json:"created_at"`
+	EventID   string `json:"event_id"`
+	ID        int64  `json:"id"`
+	Text      string `json:"text"`
+}
+
+type BatchIngestError struct {
+	Error string `json:"error"`
+	Line  int    `json:"line"`
+}
+
+type BatchIngestResponse struct {
+	Duplicates int                `json:"duplicates,omitempty"`
+	Errors     []BatchIngestError `json:"errors,omitempty"`
+	Filtered   int                `json:"filtered,omitempty"`
+	Ingested   int                `json:"ingested"`
+	OK         bool               `json:"ok"`
+}
+
+type CommandStat struct {
+	Command string `json:"command"`
+	Count   int    `json:"count"`
+}
+
+type CommandStatsResponse struct {
+	Data []CommandStat `json:"data"`
+}
+
+type ComponentInfo struct {
+	DefaultConfig interface{} `json:"default_config,omitempty"`
+	Description   string      `json:"description"`
+	Name          string      `json:"name"`
+}
+
+type ConfigResponse struct {
+	Config          map[string]interface{} `json:"config"`
+	Modules         []ComponentInfo        `json:"modules"`
+	Path            string                 `json:"path"`
+	Plugins         []ComponentInfo        `json:"plugins"`
+	RestartRequired []string               `json:"restart_required,omitempty"`
+	Version         string                 `json:"version"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+	OK    bool   `json:"ok"`
+	Queue bool   `json:"queue,omitempty"`
+}
+
+type Event struct {
+	Branch     string                 `json:"branch,omitempty"`
+	DurationMs int64                  `json:"duration_ms,omitempty"`
+	ID         string                 `json:"id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Payload    map[string]interface{} `json:"payload"`
+	Repo       string                 `json:"repo,omitempty"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	Severity   string                 `json:"severity,omitempty"`
+	Source     string                 `json:"source"`
+	Timestamp  string                 `json:"timestamp"`
+	Type       string                 `json:"type"`
+	V          int                    `json:"v"`
+}
+
+type EventDetailResponse struct {
+	Annotations []AnnotationResponse `json:"annotations"`
+	Event       EventResponse        `json:"event"`
+}
+
+type EventResponse struct {
+	Branch     string                 `json:"branch,omitempty"`
+	DurationMs int64                  `json:"duration_ms,omitempty"`
+	ID         string                 `json:"id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Payload    map[string]interface{} `json:"payload"`
+	Repo       string                 `json:"repo,omitempty"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	Severity   string                 `json:"severity,omitempty"`
+	Source     string                 `json:"source"`
+	Timestamp  string                 `json:"timestamp"`
+	Type       string                 `json:"type"`
+}
+
+type EventsBySourceResponse struct {
+	Data []SourceCount `json:"data"`
+}
+
+type EventsTimelineResponse struct {
+	Bucket string          `json:"bucket"`
+	Data   []TimelinePoint `json:"data"`
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+}
+
+type FileStat struct {
+	Count    int    `json:"count"`
+	Language string `json:"language,omitempty"`
+	Path     string `json:"path"`
+	Repo     string `json:"repo,omitempty"`
+}
+
+type FileStatsResponse struct {
+	Data []FileStat `json:"data"`
+	From string     `json:"from"`
+	To   string     `json:"to"`
+}
+
+type FocusResponse struct {
+	ActiveMinutes   int            `json:"active_minutes"`
+	DeepWork        []FocusStreak  `json:"deep_work"`
+	DeepWorkMinutes int            `json:"deep_work_minutes"`
+	Events          int            `json:"events"`
+	From            string         `json:"from"`
+	LongestStreak   *FocusStreak   `json:"longest_streak,omitempty"`
+	Switches        int            `json:"switches"`
+	SwitchesByHour  [24]int        `json:"switches_by_hour"`
+	SwitchesByKind  map[string]int `json:"switches_by_kind"`
+	SwitchesPerHour float64        `json:"switches_per_hour"`
+	To              string         `json:"to"`
+	Truncated       bool           `json:"truncated,omitempty"`
+}
+
+type FocusStreak struct {
+	Branches        []string `json:"branches,omitempty"`
+	DurationMinutes int      `json:"duration_minutes"`
+	End             string   `json:"end"`
+	Events          int      `json:"events"`
+	Repo            string   `json:"repo"`
+	Start           string   `json:"start"`
+	Switches        int      `json:"switches"`
+}
+
+type GetEventsResponse struct {
+	Count      int             `json:"count"`
+	Events     []EventResponse `json:"events"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+}
+
+type HealthResponse struct {
+	Checks map[string]string `json:"checks"`
+	Status string            `json:"status"`
+}
+
+type HeatmapDay struct {
+	Count int    `json:"count"`
+	Date  string `json:"date"`
+}
+
+type HeatmapResponse struct {
+	Data  []HeatmapDay `json:"data"`
+	From  string       `json:"from"`
+	Max   int          `json:"max"`
+	To    string       `json:"to"`
+	Total int          `json:"total"`
+}
+
+type HourlyDistributionResponse struct {
+	ByHour    [24]int    `json:"by_hour"`
+	ByWeekday [7]int     `json:"by_weekday"`
+	From      string     `json:"from"`
+	Grid      [7][24]int `json:"grid"`
+	To        string     `json:"to"`
+	Weekdays  []string   `json:"weekdays"`
+}
+
+type IngestEventResponse struct {
+	Error    string `json:"error,omitempty"`
+	EventID  string `json:"event_id,omitempty"`
+	Filtered bool   `json:"filtered,omitempty"`
+	OK       bool   `json:"ok"`
+}
+
+type LanguageStat struct {
+	Count    int    `json:"count"`
+	Files    int    `json:"files"`
+	Language string `json:"language"`
+}
+
+type LanguageStatsResponse struct {
+	Data []LanguageStat `json:"data"`
+	From string         `json:"from"`
+	To   string         `json:"to"`
+}
+
+type PauseRequest struct {
+	Duration string `json:"duration,omitempty"`
+}
+
+type PauseResponse struct {
+	Paused bool   `json:"paused"`
+	Since  string `json:"since,omitempty"`
+	Until  string `json:"until,omitempty"`
+}
+
+type PreflightIssue struct {
+	Check    string `json:"check"`
+	Message  string `json:"message"`
+	Module   string `json:"module"`
+	Repaired bool   `json:"repaired,omitempty"`
+}
+
+type RelatedEventsResponse struct {
+	Count   int             `json:"count"`
+	EventID string          `json:"event_id"`
+	Events  []EventResponse `json:"events"`
+}
+
+type RepoStat struct {
+	Count int    `json:"count"`
+	Repo  string `json:"repo"`
+}
+
+type RepoStatsResponse struct {
+	Data []RepoStat `json:"data"`
+}
+
+type SearchResponse struct {
+	Count      int                    `json:"count"`
+	HasMore    bool                   `json:"has_more,omitempty"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	Query      string                 `json:"query"`
+	Results    []SearchResultResponse `json:"results"`
+}
+
+type SearchResultResponse struct {
+	Annotations []AnnotationResponse   `json:"annotations,omitempty"`
+	Branch      string                 `json:"branch,omitempty"`
+	ID          string                 `json:"id"`
+	Kind        string                 `json:"kind"`
+	Payload     map[string]interface{} `json:"payload,omitempty"`
+	Rank        float64                `json:"rank"`
+	Repo        string                 `json:"repo,omitempty"`
+	Source      string                 `json:"source,omitempty"`
+	Summary     *SummaryResponse       `json:"summary,omitempty"`
+	Timestamp   string                 `json:"timestamp"`
+	Type        string                 `json:"type,omitempty"`
+}
+
+type SourceCount struct {
+	Count  int    `json:"count"`
+	Source string `json:"source"`
+}
+
+type StatusResponse struct {
+	EventCount    int              `json:"event_count"`
+	Paused        bool             `json:"paused"`
+	PausedUntil   string           `json:"paused_until,omitempty"`
+	Preflight     []PreflightIssue `json:"preflight,omitempty"`
+	Running       bool             `json:"running"`
+	UptimeSeconds int              `json:"uptime_seconds"`
+}
+
+type SummariesResponse struct {
+	Count     int               `json:"count"`
+	Date      string            `json:"date"`
+	Repo      string            `json:"repo,omitempty"`
+	Repos     []string          `json:"repos"`
+	Summaries []SummaryResponse `json:"summaries"`
+}
+
+type SummaryResponse struct {
+	ContextEventCount int      `json:"context_event_count"`
+	ContextStart      string   `json:"context_start"`
+	CreatedAt         string   `json:"created_at"`
+	EventCount        int      `json:"event_count"`
+	ID                int64    `json:"id"`
+	InputTokens       int      `json:"input_tokens"`
+	OutputTokens      int      `json:"output_tokens"`
+	PeriodEnd         string   `json:"period_end"`
+	PeriodStart       string   `json:"period_start"`
+	Provider          string   `json:"provider,omitempty"`
+	Repo              string   `json:"repo,omitempty"`
+	Repos             []string `json:"repos"`
+	Summary           string   `json:"summary"`
+}
+
+type TimelinePoint struct {
+	BySource map[string]int `json:"by_source"`
+	Count    int            `json:"count"`
+	Start    string         `json:"start"`
+}
+
+type UpdateConfigRequest struct {
+	Config  map[string]interface{} `json:"config"`
+	Version string                 `json:"version,omitempty"`
+}
+
+// CommandStats calls GET /api/v1/analytics/command-stats: most run shell commands.
+func (c *Client) CommandStats(ctx context.Context) (*CommandStatsResponse, error) {
+	var out CommandStatsResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/command-stats", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EventsBySource calls GET /api/v1/analytics/events-by-source: event counts per source.
+func (c *Client) EventsBySource(ctx context.Context) (*EventsBySourceResponse, error) {
+	var out EventsBySourceResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/events-by-source", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EventsTimelineParams holds the query parameters of EventsTimeline. Zero values are left out.
+type EventsTimelineParams struct {
+	// Start, YYYY-MM-DD or RFC 3339
+	From string
+	// End, YYYY-MM-DD or RFC 3339
+	To string
+	// minute, hour, day, week or auto
+	Bucket string
+}
+
+func (p *EventsTimelineParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	if p.To != "" {
+		q.Set("to", p.To)
+	}
+	if p.Bucket != "" {
+		q.Set("bucket", p.Bucket)
+	}
+	return q
+}
+
+// EventsTimeline calls GET /api/v1/analytics/events-timeline: event counts over time.
+func (c *Client) EventsTimeline(ctx context.Context, params *EventsTimelineParams) (*EventsTimelineResponse, error) {
+	var out EventsTimelineResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/events-timeline", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FocusParams holds the query parameters of Focus. Zero values are left out.
+type FocusParams struct {
+	// Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time
+	Since string
+	// Shortest streak that counts as deep work, a duration (default 45m)
+	DeepWork string
+	// Longest pause that still counts as working, a duration (default 15m)
+	IdleGap string
+}
+
+func (p *FocusParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Since != "" {
+		q.Set("since", p.Since)
+	}
+	if p.DeepWork != "" {
+		q.Set("deep_work", p.DeepWork)
+	}
+	if p.IdleGap != "" {
+		q.Set("idle_gap", p.IdleGap)
+	}
+	return q
+}
+
+// Focus calls GET /api/v1/analytics/focus: context switches, focus streaks and deep-work windows.
+func (c *Client) Focus(ctx context.Context, params *FocusParams) (*FocusResponse, error) {
+	var out FocusResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/focus", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HeatmapParams holds the query parameters of Heatmap. Zero values are left out.
+type HeatmapParams struct {
+	// Year, the last 12 months by default
+	Year int
+}
+
+func (p *HeatmapParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Year != 0 {
+		q.Set("year", strconv.Itoa(p.Year))
+	}
+	return q
+}
+
+// Heatmap calls GET /api/v1/analytics/heatmap: events per day of a year.
+func (c *Client) Heatmap(ctx context.Context, params *HeatmapParams) (*HeatmapResponse, error) {
+	var out HeatmapResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/heatmap", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// HourlyDistributionParams holds the query parameters of HourlyDistribution. Zero values are left out.
+type HourlyDistributionParams struct {
+	// Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time
+	Since string
+}
+
+func (p *HourlyDistributionParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Since != "" {
+		q.Set("since", p.Since)
+	}
+	return q
+}
+
+// HourlyDistribution calls GET /api/v1/analytics/hourly-distribution: events by weekday and hour.
+func (c *Client) HourlyDistribution(ctx context.Context, params *HourlyDistributionParams) (*HourlyDistributionResponse, error) {
+	var out HourlyDistributionResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/hourly-distribution", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RepoStats calls GET /api/v1/analytics/repo-stats: most active repos.
+func (c *Client) RepoStats(ctx context.Context) (*RepoStatsResponse, error) {
+	var out RepoStatsResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/repo-stats", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TopFilesParams holds the query parameters of TopFiles. Zero values are left out.
+type TopFilesParams struct {
+	// Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time
+	Since string
+	// Files to return (default 15, max 100)
+	Limit int
+}
+
+func (p *TopFilesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Since != "" {
+		q.Set("since", p.Since)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// TopFiles calls GET /api/v1/analytics/top-files: most touched files.
+func (c *Client) TopFiles(ctx context.Context, params *TopFilesParams) (*FileStatsResponse, error) {
+	var out FileStatsResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/top-files", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TopLanguagesParams holds the query parameters of TopLanguages. Zero values are left out.
+type TopLanguagesParams struct {
+	// Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time
+	Since string
+	// Languages to return (default 15, max 100)
+	Limit int
+}
+
+func (p *TopLanguagesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Since != "" {
+		q.Set("since", p.Since)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return q
+}
+
+// TopLanguages calls GET /api/v1/analytics/top-languages: most touched languages.
+func (c *Client) TopLanguages(ctx context.Context, params *TopLanguagesParams) (*LanguageStatsResponse, error) {
+	var out LanguageStatsResponse
+	if err := c.do(ctx, "GET", "/api/v1/analytics/top-languages", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetConfig calls GET /api/v1/config: config.yaml as JSON, with secrets redacted.
+func (c *Client) GetConfig(ctx context.Context) (*ConfigResponse, error) {
+	var out ConfigResponse
+	if err := c.do(ctx, "GET", "/api/v1/config", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateConfig calls PUT /api/v1/config: validate and save config.yaml; the daemon reloads it.
+func (c *Client) UpdateConfig(ctx context.Context, body *UpdateConfigRequest) (*ConfigResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var out ConfigResponse
+	if err := c.do(ctx, "PUT", "/api/v1/config", nil, "application/json", data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListEventsParams holds the query parameters of ListEvents. Zero values are left out.
+type ListEventsParams struct {
+	// Events per page (default 50, max 500)
+	Limit int
+	// Only events from this source
+	Source string
+	// Only events whose repo contains this
+	Repo string
+	// Only events in this session
+	SessionID string
+	// Only children of this event
+	ParentID string
+	// Only events with this severity
+	Severity string
+	// next_cursor of the previous page
+	Cursor string
+	// Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time
+	Since string
+}
+
+func (p *ListEventsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Source != "" {
+		q.Set("source", p.Source)
+	}
+	if p.Repo != "" {
+		q.Set("repo", p.Repo)
+	}
+	if p.SessionID != "" {
+		q.Set("session_id", p.SessionID)
+	}
+	if p.ParentID != "" {
+		q.Set("parent_id", p.ParentID)
+	}
+	if p.Severity != "" {
+		q.Set("severity", p.Severity)
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	if p.Since != "" {
+		q.Set("since", p.Since)
+	}
+	return q
+}
+
+// ListEvents calls GET /api/v1/events: events newest first, with cursor paging.
+func (c *Client) ListEvents(ctx context.Context, params *ListEventsParams) (*GetEventsResponse, error) {
+	var out GetEventsResponse
+	if err := c.do(ctx, "GET", "/api/v1/events", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEvent calls GET /api/v1/events/{id}: one event with its annotations.
+func (c *Client) GetEvent(ctx context.Context, id string) (*EventDetailResponse, error) {
+	var out EventDetailResponse
+	if err := c.do(ctx, "GET", "/api/v1/events/"+url.PathEscape(id), nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AddAnnotation calls POST /api/v1/events/{id}/annotations: attach a note to an event.
+func (c *Client) AddAnnotation(ctx context.Context, id string, body *AddAnnotationRequest) (*AnnotationResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var out AnnotationResponse
+	if err := c.do(ctx, "POST", "/api/v1/events/"+url.PathEscape(id)+"/annotations", nil, "application/json", data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRelatedEvents calls GET /api/v1/events/{id}/related: an event's parent, children and session, oldest first.
+func (c *Client) GetRelatedEvents(ctx context.Context, id string) (*RelatedEventsResponse, error) {
+	var out RelatedEventsResponse
+	if err := c.do(ctx, "GET", "/api/v1/events/"+url.PathEscape(id)+"/related", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetHealth calls GET /api/v1/health: health checks; answers 503 when one fails.
+func (c *Client) GetHealth(ctx context.Context) (*HealthResponse, error) {
+	var out HealthResponse
+	if err := c.do(ctx, "GET", "/api/v1/health", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// IngestEvent calls POST /api/v1/ingest: store one event.
+func (c *Client) IngestEvent(ctx context.Context, body *Event) (*IngestEventResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var out IngestEventResponse
+	if err := c.do(ctx, "POST", "/api/v1/ingest", nil, "application/json", data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// IngestBatch calls POST /api/v1/ingest/batch: store up to 500 events sent as newline-delimited JSON.
+func (c *Client) IngestBatch(ctx context.Context, body []Event) (*BatchIngestResponse, error) {
+	data, err := encodeNDJSON(body)
+	if err != nil {
+		return nil, err
+	}
+	var out BatchIngestResponse
+	if err := c.do(ctx, "POST", "/api/v1/ingest/batch", nil, "application/x-ndjson", data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMetricsParams holds the query parameters of GetMetrics. Zero values are left out.
+type GetMetricsParams struct {
+	// true for the condensed summary
+	Summary string
+}
+
+func (p *GetMetricsParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Summary != "" {
+		q.Set("summary", p.Summary)
+	}
+	return q
+}
+
+// GetMetrics calls GET /api/v1/metrics: daemon metrics.
+func (c *Client) GetMetrics(ctx context.Context, params *GetMetricsParams) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	if err := c.do(ctx, "GET", "/api/v1/metrics", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetOpenAPI calls GET /api/v1/openapi.json: this API description.
+func (c *Client) GetOpenAPI(ctx context.Context) (map[string]interface{}, error) {
+	var out map[string]interface{}
+	if err := c.do(ctx, "GET", "/api/v1/openapi.json", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetPause calls GET /api/v1/pause: whether capture is paused.
+func (c *Client) GetPause(ctx context.Context) (*PauseResponse, error) {
+	var out PauseResponse
+	if err := c.do(ctx, "GET", "/api/v1/pause", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Pause calls POST /api/v1/pause: pause capture, indefinitely or for a duration.
+func (c *Client) Pause(ctx context.Context, body *PauseRequest) (*PauseResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	var out PauseResponse
+	if err := c.do(ctx, "POST", "/api/v1/pause", nil, "application/json", data, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Resume calls POST /api/v1/resume: resume capture.
+func (c *Client) Resume(ctx context.Context) (*PauseResponse, error) {
+	var out PauseResponse
+	if err := c.do(ctx, "POST", "/api/v1/resume", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchParams holds the query parameters of Search. Zero values are left out.
+type SearchParams struct {
+	// Search query
+	Q string
+	// Results per page (default 20, max 100)
+	Limit int
+	// next_cursor of the previous page
+	Cursor string
+	// Only events from these sources
+	Module []string
+	// Only events of these types
+	Type []string
+	// Only results whose repo contains this
+	Repo string
+	// Only events whose branch contains this
+	Branch string
+	// Only events linked to this issue key by the issues plugin
+	Issue string
+	// events, summaries or all
+	Scope string
+	// Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time
+	Since string
+	// Start date, YYYY-MM-DD or RFC 3339
+	From string
+	// End date, YYYY-MM-DD or RFC 3339
+	To string
+	// relevance or time_desc
+	Sort string
+}
+
+func (p *SearchParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Q != "" {
+		q.Set("q", p.Q)
+	}
+	if p.Limit != 0 {
+		q.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		q.Set("cursor", p.Cursor)
+	}
+	for _, v := range p.Module {
+		q.Add("module", v)
+	}
+	for _, v := range p.Type {
+		q.Add("type", v)
+	}
+	if p.Repo != "" {
+		q.Set("repo", p.Repo)
+	}
+	if p.Branch != "" {
+		q.Set("branch", p.Branch)
+	}
+	if p.Issue != "" {
+		q.Set("issue", p.Issue)
+	}
+	if p.Scope != "" {
+		q.Set("scope", p.Scope)
+	}
+	if p.Since != "" {
+		q.Set("since", p.Since)
+	}
+	if p.From != "" {
+		q.Set("from", p.From)
+	}
+	if p.To != "" {
+		q.Set("to", p.To)
+	}
+	if p.Sort != "" {
+		q.Set("sort", p.Sort)
+	}
+	return q
+}
+
+// Search calls GET /api/v1/search: full-text search over events and summaries.
+func (c *Client) Search(ctx context.Context, params *SearchParams) (*SearchResponse, error) {
+	var out SearchResponse
+	if err := c.do(ctx, "GET", "/api/v1/search", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetStatus calls GET /api/v1/status: daemon status and event count.
+func (c *Client) GetStatus(ctx context.Context) (*StatusResponse, error) {
+	var out StatusResponse
+	if err := c.do(ctx, "GET", "/api/v1/status", nil, "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSummariesParams holds the query parameters of ListSummaries. Zero values are left out.
+type ListSummariesParams struct {
+	// Day as YYYY-MM-DD, today by default
+	Date string
+	// Only summaries covering this repo
+	Repo string
+}
+
+func (p *ListSummariesParams) values() url.Values {
+	q := url.Values{}
+	if p == nil {
+		return q
+	}
+	if p.Date != "" {
+		q.Set("date", p.Date)
+	}
+	if p.Repo != "" {
+		q.Set("repo", p.Repo)
+	}
+	return q
+}
+
+// ListSummaries calls GET /api/v1/summaries: summaries of one day.
+func (c *Client) ListSummaries(ctx context.Context, params *ListSummariesParams) (*SummariesResponse, error) {
+	var out SummariesResponse
+	if err := c.do(ctx, "GET", "/api/v1/summaries", params.values(), "", nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReceiveWebhook calls POST /api/v1/webhooks/{module}: turn a webhook delivery into events; the module checks the signature.
+func (c *Client) ReceiveWebhook(ctx context.Context, module string, body []byte) (*BatchIngestResponse, error) {
+	var out BatchIngestResponse
+	if err := c.do(ctx, "POST", "/api/v1/webhooks/"+url.PathEscape(module), nil, "application/json", body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,104 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"devlog/internal/api"
+	"devlog/internal/config"
+	"devlog/internal/testutil"
+)
+
+func newTestClient(t *testing.T, cfg *config.Config) *Client {
+	t.Helper()
+	store := testutil.NewTestStorage(t)
+	server := api.NewServer(store, nil, func() *config.Config { return cfg }, nil)
+	ts := httptest.NewServer(server.SetupRoutes())
+	t.Cleanup(ts.Close)
+	return New(ts.URL, "")
+}
+
+func TestClientAgainstDaemon(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Modules["shell"] = config.ComponentConfig{Enabled: true}
+	client := newTestClient(t, cfg)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	first, second := "5b0c3f6e-2d1a-4c8e-9f3b-7a6d5e4c3b2a", "8e7d6c5b-4a39-4281-b7f6-e5d4c3b2a190"
+	ingested, err := client.IngestEvent(ctx, &Event{
+		V: 1, ID: first, Timestamp: now, Source: "manual", Type: "note",
+		Payload: map[string]interface{}{"text": "tagged release 2.0"},
+	})
+	if err != nil {
+		t.Fatalf("IngestEvent() error: %v", err)
+	}
+	if ingested.EventID != first {
+		t.Errorf("event_id = %q, want %q", ingested.EventID, first)
+	}
+
+	batch, err := client.IngestBatch(ctx, []Event{{
+		V: 1, ID: second, Timestamp: now, Source: "shell", Type: "command",
+		Payload: map[string]interface{}{"command": "make release"},
+	}})
+	if err != nil {
+		t.Fatalf("IngestBatch() error: %v", err)
+	}
+	if batch.Ingested != 1 {
+		t.Errorf("batch = %+v, want 1 ingested", batch)
+	}
+
+	page, err := client.ListEvents(ctx, &ListEventsParams{Source: "shell", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListEvents() error: %v", err)
+	}
+	if page.Count != 1 || page.Events[0].ID != second {
+		t.Errorf("events = %+v, want only %s", page.Events, second)
+	}
+
+	if _, err := client.AddAnnotation(ctx, first, &AddAnnotationRequest{Text: "the big one"}); err != nil {
+		t.Fatalf("AddAnnotation() error: %v", err)
+	}
+	detail, err := client.GetEvent(ctx, first)
+	if err != nil {
+		t.Fatalf("GetEvent() error: %v", err)
+	}
+	if len(detail.Annotations) != 1 || detail.Annotations[0].Text != "the big one" {
+		t.Errorf("annotations = %+v", detail.Annotations)
+	}
+
+	results, err := client.Search(ctx, &SearchParams{Q: "release", Module: []string{"manual"}})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if results.Count != 1 {
+		t.Errorf("search count = %d, want 1", results.Count)
+	}
+
+	spec, err := client.GetOpenAPI(ctx)
+	if err != nil {
+		t.Fatalf("GetOpenAPI() error: %v", err)
+	}
+	if spec["openapi"] != "3.0.3" {
+		t.Errorf("openapi = %v", spec["openapi"])
+	}
+}
+
+func TestClientAPIError(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HTTP.AuthEnabled = true
+	client := newTestClient(t, cfg)
+
+	_, err := client.GetStatus(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetStatus() error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message == "" {
+		t.Errorf("APIError = %+v, want 401 with a message", apiErr)
+	}
+}
@@ -0,0 +1,589 @@
+// Code generated by apigen from the devlog OpenAPI spec. DO NOT EDIT.
+
+export interface AddAnnotationRequest {
+  text: string;
+}
+
+export interface AnnotationResponse {
+  created_at: string;
+  event_id: string;
+  id: number;
+  text: string;
+}
+
+export interface BatchIngestError {
+  error: string;
+  line: number;
+}
+
+export interface BatchIngestResponse {
+  duplicates?: number;
+  errors?: BatchIngestError[];
+  filtered?: number;
+  ingested: number;
+  ok: boolean;
+}
+
+export interface CommandStat {
+  command: string;
+  count: number;
+}
+
+export interface CommandStatsResponse {
+  data: CommandStat[];
+}
+
+export interface ComponentInfo {
+  default_config?: unknown;
+  description: string;
+  name: string;
+}
+
+export interface ConfigResponse {
+  config: Record<string, unknown>;
+  modules: ComponentInfo[];
+  path: string;
+  plugins: ComponentInfo[];
+  restart_required?: string[];
+  version: string;
+}
+
+export interface ErrorResponse {
+  error: string;
+  ok: boolean;
+  queue?: boolean;
+}
+
+export interface Event {
+  branch?: string;
+  duration_ms?: number;
+  id: string;
+  parent_id?: string;
+  payload: Record<string, unknown>;
+  repo?: string;
+  session_id?: string;
+  severity?: string;
+  source: string;
+  timestamp: string;
+  type: string;
+  v: number;
+}
+
+export interface EventDetailResponse {
+  annotations: AnnotationResponse[];
+  event: EventResponse;
+}
+
+export interface EventResponse {
+  branch?: string;
+  duration_ms?: number;
+  id: string;
+  parent_id?: string;
+  payload: Record<string, unknown>;
+  repo?: string;
+  session_id?: string;
+  severity?: string;
+  source: string;
+  timestamp: string;
+  type: string;
+}
+
+export interface EventsBySourceResponse {
+  data: SourceCount[];
+}
+
+export interface EventsTimelineResponse {
+  bucket: string;
+  data: TimelinePoint[];
+  from: string;
+  to: string;
+}
+
+export interface FileStat {
+  count: number;
+  language?: string;
+  path: string;
+  repo?: string;
+}
+
+export interface FileStatsResponse {
+  data: FileStat[];
+  from: string;
+  to: string;
+}
+
+export interface FocusResponse {
+  active_minutes: number;
+  deep_work: FocusStreak[];
+  deep_work_minutes: number;
+  events: number;
+  from: string;
+  longest_streak?: FocusStreak;
+  switches: number;
+  switches_by_hour: [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number];
+  switches_by_kind: Record<string, number>;
+  switches_per_hour: number;
+  to: string;
+  truncated?: boolean;
+}
+
+export interface FocusStreak {
+  branches?: string[];
+  duration_minutes: number;
+  end: string;
+  events: number;
+  repo: string;
+  start: string;
+  switches: number;
+}
+
+export interface GetEventsResponse {
+  count: number;
+  events: EventResponse[];
+  next_cursor?: string;
+}
+
+export interface HealthResponse {
+  checks: Record<string, string>;
+  status: string;
+}
+
+export interface HeatmapDay {
+  count: number;
+  date: string;
+}
+
+export interface HeatmapResponse {
+  data: HeatmapDay[];
+  from: string;
+  max: number;
+  to: string;
+  total: number;
+}
+
+export interface HourlyDistributionResponse {
+  by_hour: [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number];
+  by_weekday: [number, number, number, number, number, number, number];
+  from: string;
+  grid: [[number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number], [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number], [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number], [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number], [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number], [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number], [number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number, number]];
+  to: string;
+  weekdays: string[];
+}
+
+export interface IngestEventResponse {
+  error?: string;
+  event_id?: string;
+  filtered?: boolean;
+  ok: boolean;
+}
+
+export interface LanguageStat {
+  count: number;
+  files: number;
+  language: string;
+}
+
+export interface LanguageStatsResponse {
+  data: LanguageStat[];
+  from: string;
+  to: string;
+}
+
+export interface PauseRequest {
+  duration?: string;
+}
+
+export interface PauseResponse {
+  paused: boolean;
+  since?: string;
+  until?: string;
+}
+
+export interface PreflightIssue {
+  check: string;
+  message: string;
+  module: string;
+  repaired?: boolean;
+}
+
+export interface RelatedEventsResponse {
+  count: number;
+  event_id: string;
+  events: EventResponse[];
+}
+
+export interface RepoStat {
+  count: number;
+  repo: string;
+}
+
+export interface RepoStatsResponse {
+  data: RepoStat[];
+}
+
+export interface SearchResponse {
+  count: number;
+  has_more?: boolean;
+  next_cursor?: string;
+  query: string;
+  results: SearchResultResponse[];
+}
+
+export interface SearchResultResponse {
+  annotations?: AnnotationResponse[];
+  branch?: string;
+  id: string;
+  kind: string;
+  payload?: Record<string, unknown>;
+  rank: number;
+  repo?: string;
+  source?: string;
+  summary?: SummaryResponse;
+  timestamp: string;
+  type?: string;
+}
+
+export interface SourceCount {
+  count: number;
+  source: string;
+}
+
+export interface StatusResponse {
+  event_count: number;
+  paused: boolean;
+  paused_until?: string;
+  preflight?: PreflightIssue[];
+  running: boolean;
+  uptime_seconds: number;
+}
+
+export interface SummariesResponse {
+  count: number;
+  date: string;
+  repo?: string;
+  repos: string[];
+  summaries: SummaryResponse[];
+}
+
+export interface SummaryResponse {
+  context_event_count: number;
+  context_start: string;
+  created_at: string;
+  event_count: number;
+  id: number;
+  input_tokens: number;
+  output_tokens: number;
+  period_end: string;
+  period_start: string;
+  provider?: string;
+  repo?: string;
+  repos: string[];
+  summary: string;
+}
+
+export interface TimelinePoint {
+  by_source: Record<string, number>;
+  count: number;
+  start: string;
+}
+
+export interface UpdateConfigRequest {
+  config: Record<string, unknown>;
+  version?: string;
+}
+
+export interface EventsTimelineParams {
+  /** Start, YYYY-MM-DD or RFC 3339 */
+  from?: string;
+  /** End, YYYY-MM-DD or RFC 3339 */
+  to?: string;
+  /** minute, hour, day, week or auto */
+  bucket?: string;
+}
+
+export interface FocusParams {
+  /** Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time */
+  since?: string;
+  /** Shortest streak that counts as deep work, a duration (default 45m) */
+  deep_work?: string;
+  /** Longest pause that still counts as working, a duration (default 15m) */
+  idle_gap?: string;
+}
+
+export interface HeatmapParams {
+  /** Year, the last 12 months by default */
+  year?: number;
+}
+
+export interface HourlyDistributionParams {
+  /** Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time */
+  since?: string;
+}
+
+export interface TopFilesParams {
+  /** Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time */
+  since?: string;
+  /** Files to return (default 15, max 100) */
+  limit?: number;
+}
+
+export interface TopLanguagesParams {
+  /** Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time */
+  since?: string;
+  /** Languages to return (default 15, max 100) */
+  limit?: number;
+}
+
+export interface ListEventsParams {
+  /** Events per page (default 50, max 500) */
+  limit?: number;
+  /** Only events from this source */
+  source?: string;
+  /** Only events whose repo contains this */
+  repo?: string;
+  /** Only events in this session */
+  session_id?: string;
+  /** Only children of this event */
+  parent_id?: string;
+  /** Only events with this severity */
+  severity?: string;
+  /** next_cursor of the previous page */
+  cursor?: string;
+  /** Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time */
+  since?: string;
+}
+
+export interface GetMetricsParams {
+  /** true for the condensed summary */
+  summary?: string;
+}
+
+export interface SearchParams {
+  /** Search query */
+  q?: string;
+  /** Results per page (default 20, max 100) */
+  limit?: number;
+  /** next_cursor of the previous page */
+  cursor?: string;
+  /** Only events from these sources */
+  module?: string[];
+  /** Only events of these types */
+  type?: string[];
+  /** Only results whose repo contains this */
+  repo?: string;
+  /** Only events whose branch contains this */
+  branch?: string;
+  /** Only events linked to this issue key by the issues plugin */
+  issue?: string;
+  /** events, summaries or all */
+  scope?: string;
+  /** Only events after this point: a duration such as 24h or 7d, or an RFC 3339 time */
+  since?: string;
+  /** Start date, YYYY-MM-DD or RFC 3339 */
+  from?: string;
+  /** End date, YYYY-MM-DD or RFC 3339 */
+  to?: string;
+  /** relevance or time_desc */
+  sort?: string;
+}
+
+export interface ListSummariesParams {
+  /** Day as YYYY-MM-DD, today by default */
+  date?: string;
+  /** Only summaries covering this repo */
+  repo?: string;
+}
+
+export class DevlogError extends Error {
+  constructor(
+    readonly status: number,
+    message: string,
+    /** The daemon asks the caller to queue the event and retry later. */
+    readonly queue: boolean = false,
+  ) {
+    super(message);
+    this.name = "DevlogError";
+  }
+}
+
+export interface ClientOptions {
+  /** Daemon address, e.g. http://localhost:8573. Defaults to the page's origin. */
+  baseURL?: string;
+  /** Bearer token from 'devlog token create', for daemons with http.auth_enabled. */
+  token?: string;
+  fetch?: typeof fetch;
+}
+
+export class DevlogClient {
+  constructor(private readonly options: ClientOptions = {}) {}
+
+  private async request<T>(
+    method: string,
+    path: string,
+    query?: object,
+    body?: { data: string; contentType: string },
+  ): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {}) as [string, unknown][]) {
+      if (value === undefined || value === "" || value === 0) continue;
+      for (const v of Array.isArray(value) ? value : [value]) params.append(key, String(v));
+    }
+    const qs = params.toString();
+    const headers: Record<string, string> = {};
+    if (body) headers["Content-Type"] = body.contentType;
+    if (this.options.token) headers["Authorization"] = `Bearer ${this.options.token}`;
+
+    const doFetch = this.options.fetch ?? fetch;
+    const res = await doFetch((this.options.baseURL ?? "") + path + (qs ? "?" + qs : ""), {
+      method,
+      headers,
+      body: body?.data,
+    });
+    const text = await res.text();
+    let data: any;
+    try {
+      data = text ? JSON.parse(text) : undefined;
+    } catch {
+      data = undefined;
+    }
+    if (!res.ok) {
+      throw new DevlogError(res.status, data?.error ?? res.statusText, data?.queue ?? false);
+    }
+    return data as T;
+  }
+
+  /** GET /api/v1/analytics/command-stats: most run shell commands. */
+  commandStats(): Promise<CommandStatsResponse> {
+    return this.request("GET", `/api/v1/analytics/command-stats`);
+  }
+
+  /** GET /api/v1/analytics/events-by-source: event counts per source. */
+  eventsBySource(): Promise<EventsBySourceResponse> {
+    return this.request("GET", `/api/v1/analytics/events-by-source`);
+  }
+
+  /** GET /api/v1/analytics/events-timeline: event counts over time. */
+  eventsTimeline(params: EventsTimelineParams = {}): Promise<EventsTimelineResponse> {
+    return this.request("GET", `/api/v1/analytics/events-timeline`, params);
+  }
+
+  /** GET /api/v1/analytics/focus: context switches, focus streaks and deep-work windows. */
+  focus(params: FocusParams = {}): Promise<FocusResponse> {
+    return this.request("GET", `/api/v1/analytics/focus`, params);
+  }
+
+  /** GET /api/v1/analytics/heatmap: events per day of a year. */
+  heatmap(params: HeatmapParams = {}): Promise<HeatmapResponse> {
+    return this.request("GET", `/api/v1/analytics/heatmap`, params);
+  }
+
+  /** GET /api/v1/analytics/hourly-distribution: events by weekday and hour. */
+  hourlyDistribution(params: HourlyDistributionParams = {}): Promise<HourlyDistributionResponse> {
+    return this.request("GET", `/api/v1/analytics/hourly-distribution`, params);
+  }
+
+  /** GET /api/v1/analytics/repo-stats: most active repos. */
+  repoStats(): Promise<RepoStatsResponse> {
+    return this.request("GET", `/api/v1/analytics/repo-stats`);
+  }
+
+  /** GET /api/v1/analytics/top-files: most touched files. */
+  topFiles(params: TopFilesParams = {}): Promise<FileStatsResponse> {
+    return this.request("GET", `/api/v1/analytics/top-files`, params);
+  }
+
+  /** GET /api/v1/analytics/top-languages: most touched languages. */
+  topLanguages(params: TopLanguagesParams = {}): Promise<LanguageStatsResponse> {
+    return this.request("GET", `/api/v1/analytics/top-languages`, params);
+  }
+
+  /** GET /api/v1/config: config.yaml as JSON, with secrets redacted. */
+  getConfig(): Promise<ConfigResponse> {
+    return this.request("GET", `/api/v1/config`);
+  }
+
+  /** PUT /api/v1/config: validate and save config.yaml; the daemon reloads it. */
+  updateConfig(body: UpdateConfigRequest): Promise<ConfigResponse> {
+    return this.request("PUT", `/api/v1/config`, undefined, { data: JSON.stringify(body), contentType: "application/json" });
+  }
+
+  /** GET /api/v1/events: events newest first, with cursor paging. */
+  listEvents(params: ListEventsParams = {}): Promise<GetEventsResponse> {
+    return this.request("GET", `/api/v1/events`, params);
+  }
+
+  /** GET /api/v1/events/{id}: one event with its annotations. */
+  getEvent(id: string): Promise<EventDetailResponse> {
+    return this.request("GET", `/api/v1/events/${encodeURIComponent(id)}`);
+  }
+
+  /** POST /api/v1/events/{id}/annotations: attach a note to an event. */
+  addAnnotation(id: string, body: AddAnnotationRequest): Promise<AnnotationResponse> {
+    return this.request("POST", `/api/v1/events/${encodeURIComponent(id)}/annotations`, undefined, { data: JSON.stringify(body), contentType: "application/json" });
+  }
+
+  /** GET /api/v1/events/{id}/related: an event's parent, children and session, oldest first. */
+  getRelatedEvents(id: string): Promise<RelatedEventsResponse> {
+    return this.request("GET", `/api/v1/events/${encodeURIComponent(id)}/related`);
+  }
+
+  /** GET /api/v1/health: health checks; answers 503 when one fails. */
+  getHealth(): Promise<HealthResponse> {
+    return this.request("GET", `/api/v1/health`);
+  }
+
+  /** POST /api/v1/ingest: store one event. */
+  ingestEvent(body: Event): Promise<IngestEventResponse> {
+    return this.request("POST", `/api/v1/ingest`, undefined, { data: JSON.stringify(body), contentType: "application/json" });
+  }
+
+  /** POST /api/v1/ingest/batch: store up to 500 events sent as newline-delimited JSON. */
+  ingestBatch(body: Event[]): Promise<BatchIngestResponse> {
+    return this.request("POST", `/api/v1/ingest/batch`, undefined, { data: body.map((item) => JSON.stringify(item)).join("\n"), contentType: "application/x-ndjson" });
+  }
+
+  /** GET /api/v1/metrics: daemon metrics. */
+  getMetrics(params: GetMetricsParams = {}): Promise<Record<string, unknown>> {
+    return this.request("GET", `/api/v1/metrics`, params);
+  }
+
+  /** GET /api/v1/openapi.json: this API description. */
+  getOpenAPI(): Promise<Record<string, unknown>> {
+    return this.request("GET", `/api/v1/openapi.json`);
+  }
+
+  /** GET /api/v1/pause: whether capture is paused. */
+  getPause(): Promise<PauseResponse> {
+    return this.request("GET", `/api/v1/pause`);
+  }
+
+  /** POST /api/v1/pause: pause capture, indefinitely or for a duration. */
+  pause(body: PauseRequest): Promise<PauseResponse> {
+    return this.request("POST", `/api/v1/pause`, undefined, { data: JSON.stringify(body), contentType: "application/json" });
+  }
+
+  /** POST /api/v1/resume: resume capture. */
+  resume(): Promise<PauseResponse> {
+    return this.request("POST", `/api/v1/resume`);
+  }
+
+  /** GET /api/v1/search: full-text search over events and summaries. */
+  search(params: SearchParams = {}): Promise<SearchResponse> {
+    return this.request("GET", `/api/v1/search`, params);
+  }
+
+  /** GET /api/v1/status: daemon status and event count. */
+  getStatus(): Promise<StatusResponse> {
+    return this.request("GET", `/api/v1/status`);
+  }
+
+  /** GET /api/v1/summaries: summaries of one day. */
+  listSummaries(params: ListSummariesParams = {}): Promise<SummariesResponse> {
+    return this.request("GET", `/api/v1/summaries`, params);
+  }
+
+  /** POST /api/v1/webhooks/{module}: turn a webhook delivery into events; the module checks the signature. */
+  receiveWebhook(module: string, body: string): Promise<BatchIngestResponse> {
+    return this.request("POST", `/api/v1/webhooks/${encodeURIComponent(module)}`, undefined, { data: body, contentType: "application/json" });
+  }
+}
@@ -0,0 +1,394 @@
+// Package devlog is a client for a local devlog daemon. It lets other Go
+// programs record events and read history without shelling out to the
+// devlog binary.
+//
+// Ingestion behaves like the CLI hooks: events go to the daemon's HTTP API
+// when it is reachable and are written to the on-disk queue otherwise, to be
+// picked up the next time the daemon starts. Nothing is sent while capture
+// is paused.
+package devlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/events"
+	"devlog/internal/pause"
+	"devlog/internal/queue"
+)
+
+type Event = events.Event
+
+const DefaultTimeout = 5 * time.Second
+
+var ErrDaemonUnavailable = errors.New("devlog daemon is not reachable")
+
+// NewEvent returns an event with a fresh ID and the current time. Source and
+// type must be values the daemon accepts, e.g. "manual" and "note".
+func NewEvent(source, eventType string) *Event {
+	return events.NewEvent(source, eventType)
+}
+
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	QueueDir   string
+	DataDir    string
+	// Token is sent as a bearer token, for daemons with http.auth_enabled.
+	Token string
+}
+
+type Option func(*Client)
+
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.BaseURL = strings.TrimSuffix(baseURL, "/") }
+}
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) { c.HTTPClient = client }
+}
+
+func WithQueueDir(dir string) Option {
+	return func(c *Client) { c.QueueDir = dir }
+}
+
+func WithDataDir(dir string) Option {
+	return func(c *Client) { c.DataDir = dir }
+}
+
+func WithToken(token string) Option {
+	return func(c *Client) { c.Token = token }
+}
+
+// New builds a client from the user's devlog config: the daemon port from
+// config.yaml, the queue in the data directory and the API token from
+// $DEVLOG_API_TOKEN or 'devlog token create'. Options override all three.
+func New(opts ...Option) (*Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		BaseURL:    cfg.HTTP.LocalURL(),
+		HTTPClient: cfg.HTTP.Client(DefaultTimeout),
+		DataDir:    dataDir,
+		Token:      config.ClientToken(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.QueueDir == "" && c.DataDir != "" {
+		c.QueueDir = filepath.Join(c.DataDir, "queue")
+	}
+	return c, nil
+}
+
+type IngestResult struct {
+	Ingested   int
+	Filtered   int
+	Duplicates int
+	Queued     int
+	Errors     []string
+}
+
+// Ingest records a single event. If the daemon cannot be reached the event
+// is queued and Ingest returns nil.
+func (c *Client) Ingest(ctx context.Context, event *Event) error {
+	if err := event.Validate(); err != nil {
+		return fmt.Errorf("invalid event: %w", err)
+	}
+	if c.paused() {
+		return nil
+	}
+
+	body, err := event.ToJSON()
+	if err != nil {
+		return fmt.Errorf("serialize event: %w", err)
+	}
+
+	var resp struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	status, err := c.do(ctx, http.MethodPost, "/api/v1/ingest", nil, bytes.NewReader(body), &resp)
+	if err == nil {
+		return nil
+	}
+	if status == http.StatusBadRequest {
+		return err
+	}
+
+	return c.enqueue(event)
+}
+
+// IngestBatch records several events in one request, falling back to the
+// queue for all of them if the daemon cannot be reached.
+func (c *Client) IngestBatch(ctx context.Context, evts []*Event) (*IngestResult, error) {
+	if c.paused() {
+		return &IngestResult{Filtered: len(evts)}, nil
+	}
+
+	var body bytes.Buffer
+	for _, event := range evts {
+		data, err := event.ToJSON()
+		if err != nil {
+			return nil, fmt.Errorf("serialize event: %w", err)
+		}
+		body.Write(data)
+		body.WriteByte('\n')
+	}
+
+	var resp struct {
+		Ingested   int `json:"ingested"`
+		Filtered   int `json:"filtered"`
+		Duplicates int `json:"duplicates"`
+		Errors     []struct {
+			Line  int    `json:"line"`
+			Error string `json:"error"`
+		} `json:"errors"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, "/api/v1/ingest/batch", nil, &body, &resp); err == nil {
+		result := &IngestResult{
+			Ingested:   resp.Ingested,
+			Filtered:   resp.Filtered,
+			Duplicates: resp.Duplicates,
+		}
+		for _, e := range resp.Errors {
+			result.Errors = append(result.Errors, fmt.Sprintf("line %d: %s", e.Line, e.Error))
+		}
+		return result, nil
+	}
+
+	result := &IngestResult{}
+	for _, event := range evts {
+		if err := c.enqueue(event); err != nil {
+			return result, err
+		}
+		result.Queued++
+	}
+	return result, nil
+}
+
+type SearchOptions struct {
+	Query   string
+	Limit   int
+	Modules []string
+	Types   []string
+	Repo    string
+	Branch  string
+	Since   string
+	Scope   string
+	Sort    string
+	Cursor  string
+}
+
+type Summary struct {
+	ID           int64    `json:"id"`
+	PeriodStart  string   `json:"period_start"`
+	PeriodEnd    string   `json:"period_end"`
+	Repos        []string `json:"repos"`
+	Text         string   `json:"summary"`
+	EventCount   int      `json:"event_count"`
+	Provider     string   `json:"provider,omitempty"`
+	InputTokens  int      `json:"input_tokens"`
+	OutputTokens int      `json:"output_tokens"`
+	CreatedAt    string   `json:"created_at"`
+}
+
+type SearchResult struct {
+	Kind      string                 `json:"kind"`
+	ID        string                 `json:"id"`
+	Timestamp string                 `json:"timestamp"`
+	Source    string                 `json:"source,omitempty"`
+	Type      string                 `json:"type,omitempty"`
+	Repo      string                 `json:"repo,omitempty"`
+	Branch    string                 `json:"branch,omitempty"`
+	Payload   map[string]interface{} `json:"payload,omitempty"`
+	Summary   *Summary               `json:"summary,omitempty"`
+	Rank      float64                `json:"rank"`
+}
+
+type SearchPage struct {
+	Results    []SearchResult `json:"results"`
+	Count      int            `json:"count"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more,omitempty"`
+}
+
+// Search runs a full-text search against the daemon. It requires the
+// daemon to be running.
+func (c *Client) Search(ctx context.Context, opts SearchOptions) (*SearchPage, error) {
+	params := url.Values{}
+	setParam(params, "q", opts.Query)
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	for _, m := range opts.Modules {
+		params.Add("module", m)
+	}
+	for _, t := range opts.Types {
+		params.Add("type", t)
+	}
+	setParam(params, "repo", opts.Repo)
+	setParam(params, "branch", opts.Branch)
+	setParam(params, "since", opts.Since)
+	setParam(params, "scope", opts.Scope)
+	setParam(params, "sort", opts.Sort)
+	setParam(params, "cursor", opts.Cursor)
+
+	var page SearchPage
+	if _, err := c.do(ctx, http.MethodGet, "/api/v1/search", params, nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+type QueryOptions struct {
+	Source    string
+	Repo      string
+	Since     string
+	SessionID string
+	ParentID  string
+	Severity  string
+	Limit     int
+	Cursor    string
+}
+
+type EventPage struct {
+	Events     []*Event `json:"events"`
+	Count      int      `json:"count"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+// Query lists events newest first, filtered by source, repo and time. It
+// requires the daemon to be running.
+func (c *Client) Query(ctx context.Context, opts QueryOptions) (*EventPage, error) {
+	params := url.Values{}
+	setParam(params, "source", opts.Source)
+	setParam(params, "repo", opts.Repo)
+	setParam(params, "since", opts.Since)
+	setParam(params, "session_id", opts.SessionID)
+	setParam(params, "parent_id", opts.ParentID)
+	setParam(params, "severity", opts.Severity)
+	setParam(params, "cursor", opts.Cursor)
+	if opts.Limit > 0 {
+		params.Set("limit", strconv.Itoa(opts.Limit))
+	}
+
+	var page EventPage
+	if _, err := c.do(ctx, http.MethodGet, "/api/v1/events", params, nil, &page); err != nil {
+		return nil, err
+	}
+	for _, event := range page.Events {
+		event.Version = events.CurrentVersion(event.Source)
+	}
+	return &page, nil
+}
+
+// Related returns the events linked to an event: its parent, its children
+// and the rest of its session, oldest first. It requires the daemon to be
+// running.
+func (c *Client) Related(ctx context.Context, id string) ([]*Event, error) {
+	var page struct {
+		Events []*Event `json:"events"`
+	}
+	if _, err := c.do(ctx, http.MethodGet, "/api/v1/events/"+url.PathEscape(id)+"/related", nil, nil, &page); err != nil {
+		return nil, err
+	}
+	for _, event := range page.Events {
+		event.Version = events.CurrentVersion(event.Source)
+	}
+	return page.Events, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body io.Reader, out interface{}) (int, error) {
+	target := c.BaseURL + path
+	if len(params) > 0 {
+		target += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return 0, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDaemonUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return resp.StatusCode, fmt.Errorf("devlog: %s (status %d)", apiErr.Error, resp.StatusCode)
+		}
+		return resp.StatusCode, fmt.Errorf("devlog: unexpected status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return resp.StatusCode, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func (c *Client) enqueue(event *Event) error {
+	if c.QueueDir == "" {
+		return ErrDaemonUnavailable
+	}
+	q, err := queue.New(c.QueueDir)
+	if err != nil {
+		return fmt.Errorf("create queue: %w", err)
+	}
+	if err := q.Enqueue(event); err != nil {
+		return fmt.Errorf("queue event: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) paused() bool {
+	return c.DataDir != "" && pause.NewController(c.DataDir).Active()
+}
+
+func setParam(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
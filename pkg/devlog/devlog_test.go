@@ -0,0 +1,141 @@
+package devlog
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devlog/internal/api"
+	"devlog/internal/config"
+	"devlog/internal/pause"
+	"devlog/internal/testutil"
+)
+
+func newTestClient(t *testing.T) (*Client, string) {
+	t.Helper()
+	store := testutil.NewTestStorage(t)
+	cfg := config.DefaultConfig()
+	cfg.Modules["shell"] = config.ComponentConfig{Enabled: true}
+	server := api.NewServer(store, nil, func() *config.Config { return cfg }, nil)
+	ts := httptest.NewServer(server.SetupRoutes())
+	t.Cleanup(ts.Close)
+
+	dataDir := t.TempDir()
+	client, err := New(WithBaseURL(ts.URL), WithDataDir(dataDir), WithQueueDir(filepath.Join(dataDir, "queue")))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	return client, dataDir
+}
+
+func queuedFiles(t *testing.T, dir string) int {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatalf("read queue: %v", err)
+	}
+	return len(entries)
+}
+
+func TestIngestSearchAndQuery(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	event := NewEvent("manual", "note")
+	event.Repo = "devlog"
+	event.Payload["text"] = "shipped the websocket fix"
+	if err := client.Ingest(ctx, event); err != nil {
+		t.Fatalf("Ingest() error: %v", err)
+	}
+
+	other := NewEvent("shell", "command")
+	other.Payload["command"] = "make test"
+	result, err := client.IngestBatch(ctx, []*Event{other})
+	if err != nil {
+		t.Fatalf("IngestBatch() error: %v", err)
+	}
+	if result.Ingested != 1 || result.Queued != 0 {
+		t.Errorf("unexpected batch result: %+v", result)
+	}
+
+	page, err := client.Search(ctx, SearchOptions{Query: "websocket"})
+	if err != nil {
+		t.Fatalf("Search() error: %v", err)
+	}
+	if page.Count != 1 || page.Results[0].ID != event.ID {
+		t.Errorf("unexpected search results: %+v", page)
+	}
+
+	events, err := client.Query(ctx, QueryOptions{Source: "shell", Since: "1h"})
+	if err != nil {
+		t.Fatalf("Query() error: %v", err)
+	}
+	if events.Count != 1 || events.Events[0].Payload["command"] != "make test" {
+		t.Errorf("unexpected query results: %+v", events)
+	}
+}
+
+func TestIngestRejectsInvalidEvent(t *testing.T) {
+	client, dataDir := newTestClient(t)
+
+	event := NewEvent("nonsense", "note")
+	if err := client.Ingest(context.Background(), event); err == nil {
+		t.Error("expected validation error")
+	}
+	if n := queuedFiles(t, filepath.Join(dataDir, "queue")); n != 0 {
+		t.Errorf("invalid event should not be queued, found %d files", n)
+	}
+}
+
+func TestIngestQueuesWhenDaemonUnavailable(t *testing.T) {
+	dataDir := t.TempDir()
+	queueDir := filepath.Join(dataDir, "queue")
+	client, err := New(WithBaseURL("http://127.0.0.1:1"), WithDataDir(dataDir), WithQueueDir(queueDir))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := client.Ingest(ctx, NewEvent("manual", "note")); err != nil {
+		t.Fatalf("Ingest() error: %v", err)
+	}
+	result, err := client.IngestBatch(ctx, []*Event{NewEvent("manual", "note"), NewEvent("manual", "note")})
+	if err != nil {
+		t.Fatalf("IngestBatch() error: %v", err)
+	}
+	if result.Queued != 2 {
+		t.Errorf("expected 2 queued, got %+v", result)
+	}
+	if n := queuedFiles(t, queueDir); n != 3 {
+		t.Errorf("expected 3 queued files, got %d", n)
+	}
+
+	if _, err := client.Search(ctx, SearchOptions{Query: "x"}); err == nil {
+		t.Error("expected Search to fail without a daemon")
+	}
+}
+
+func TestIngestSkippedWhilePaused(t *testing.T) {
+	dataDir := t.TempDir()
+	queueDir := filepath.Join(dataDir, "queue")
+	client, err := New(WithBaseURL("http://127.0.0.1:1"), WithDataDir(dataDir), WithQueueDir(queueDir))
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := pause.NewController(dataDir).Pause(time.Hour); err != nil {
+		t.Fatalf("Pause() error: %v", err)
+	}
+
+	if err := client.Ingest(context.Background(), NewEvent("manual", "note")); err != nil {
+		t.Fatalf("Ingest() error: %v", err)
+	}
+	if n := queuedFiles(t, queueDir); n != 0 {
+		t.Errorf("expected nothing queued while paused, got %d", n)
+	}
+}
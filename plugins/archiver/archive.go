@@ -0,0 +1,310 @@
+package archiver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+)
+
+const manifestVersion = 1
+
+type Manifest struct {
+	Version  int            `json:"version"`
+	Archives []ArchiveEntry `json:"archives"`
+}
+
+type ArchiveEntry struct {
+	Key        string `json:"key"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	EventCount int    `json:"event_count"`
+	SizeBytes  int    `json:"size_bytes"`
+	SHA256     string `json:"sha256"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type RunResult struct {
+	Archives       int
+	EventsArchived int
+	EventsDeleted  int64
+}
+
+type RestoreResult struct {
+	Archives       int
+	EventsRestored int
+	Duplicates     int
+}
+
+type Archiver struct {
+	store     *storage.Storage
+	objects   ObjectStore
+	prefix    string
+	retention time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func NewArchiver(store *storage.Storage, objects ObjectStore, prefix string, retention time.Duration, batchSize int) *Archiver {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	return &Archiver{
+		store:     store,
+		objects:   objects,
+		prefix:    prefix,
+		retention: retention,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+func (a *Archiver) manifestKey() string {
+	return path.Join(a.prefix, "manifest.json")
+}
+
+func (a *Archiver) LoadManifest(ctx context.Context) (*Manifest, error) {
+	data, err := a.objects.Get(ctx, a.manifestKey())
+	if err == ErrObjectNotFound {
+		return &Manifest{Version: manifestVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	if manifest.Version > manifestVersion {
+		return nil, fmt.Errorf("manifest version %d is newer than supported version %d", manifest.Version, manifestVersion)
+	}
+
+	return &manifest, nil
+}
+
+func (a *Archiver) saveManifest(ctx context.Context, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := a.objects.Put(ctx, a.manifestKey(), data, "application/json"); err != nil {
+		return fmt.Errorf("save manifest: %w", err)
+	}
+	return nil
+}
+
+func (a *Archiver) Run(ctx context.Context) (*RunResult, error) {
+	result := &RunResult{}
+	cutoff := a.now().Add(-a.retention)
+
+	manifest, err := a.LoadManifest(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		batch, err := a.store.QueryEventsContext(ctx, storage.QueryOptions{
+			EndTime:   &cutoff,
+			Limit:     a.batchSize,
+			Ascending: true,
+		})
+		if err != nil {
+			return result, fmt.Errorf("query aged events: %w", err)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		entry, err := a.archiveBatch(ctx, batch)
+		if err != nil {
+			return result, err
+		}
+
+		manifest.Archives = append(manifest.Archives, *entry)
+		if err := a.saveManifest(ctx, manifest); err != nil {
+			return result, err
+		}
+
+		ids := make([]string, len(batch))
+		for i, event := range batch {
+			ids[i] = event.ID
+		}
+
+		deleted, err := a.store.DeleteEventsContext(ctx, ids)
+		if err != nil {
+			return result, fmt.Errorf("prune archived events: %w", err)
+		}
+
+		result.Archives++
+		result.EventsArchived += len(batch)
+		result.EventsDeleted += deleted
+
+		if len(batch) < a.batchSize {
+			return result, nil
+		}
+	}
+}
+
+func (a *Archiver) archiveBatch(ctx context.Context, batch []*events.Event) (*ArchiveEntry, error) {
+	data, err := EncodeEvents(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := time.Parse(time.RFC3339, batch[0].Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("parse timestamp: %w", err)
+	}
+	last, err := time.Parse(time.RFC3339, batch[len(batch)-1].Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("parse timestamp: %w", err)
+	}
+
+	now := a.now().UTC()
+	key := path.Join(a.prefix, "events", first.UTC().Format("2006/01/02"),
+		fmt.Sprintf("%d-%d-%d.jsonl.gz", first.Unix(), last.Unix(), now.UnixNano()))
+
+	if err := a.objects.Put(ctx, key, data, "application/gzip"); err != nil {
+		return nil, fmt.Errorf("upload archive: %w", err)
+	}
+
+	return &ArchiveEntry{
+		Key:        key,
+		From:       first.UTC().Format(time.RFC3339),
+		To:         last.UTC().Format(time.RFC3339),
+		EventCount: len(batch),
+		SizeBytes:  len(data),
+		SHA256:     sha256Hex(data),
+		CreatedAt:  now.Format(time.RFC3339),
+	}, nil
+}
+
+func (a *Archiver) Restore(ctx context.Context, from, to time.Time) (*RestoreResult, error) {
+	result := &RestoreResult{}
+
+	manifest, err := a.LoadManifest(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	entries := ArchivesInRange(manifest, from, to)
+	for _, entry := range entries {
+		data, err := a.objects.Get(ctx, entry.Key)
+		if err != nil {
+			return result, fmt.Errorf("download %s: %w", entry.Key, err)
+		}
+		if sha256Hex(data) != entry.SHA256 {
+			return result, fmt.Errorf("checksum mismatch for %s", entry.Key)
+		}
+
+		evts, err := DecodeEvents(data)
+		if err != nil {
+			return result, fmt.Errorf("decode %s: %w", entry.Key, err)
+		}
+
+		for _, event := range evts {
+			ts, err := time.Parse(time.RFC3339, event.Timestamp)
+			if err != nil || ts.Before(from) || !ts.Before(to) {
+				continue
+			}
+
+			err = a.store.InsertEventContext(ctx, event)
+			if err == storage.ErrDuplicateEvent {
+				result.Duplicates++
+				continue
+			}
+			if err != nil {
+				return result, fmt.Errorf("restore event %s: %w", event.ID, err)
+			}
+			result.EventsRestored++
+		}
+		result.Archives++
+	}
+
+	return result, nil
+}
+
+func ArchivesInRange(manifest *Manifest, from, to time.Time) []ArchiveEntry {
+	var entries []ArchiveEntry
+	for _, entry := range manifest.Archives {
+		entryFrom, err := time.Parse(time.RFC3339, entry.From)
+		if err != nil {
+			continue
+		}
+		entryTo, err := time.Parse(time.RFC3339, entry.To)
+		if err != nil {
+			continue
+		}
+		if entryTo.Before(from) || !entryFrom.Before(to) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].From < entries[j].From
+	})
+	return entries
+}
+
+// EncodeEvents writes events as gzipped JSONL, one event per line.
+func EncodeEvents(evts []*events.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+
+	for _, event := range evts {
+		if err := encoder.Encode(event); err != nil {
+			return nil, fmt.Errorf("encode event %s: %w", event.ID, err)
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func DecodeEvents(data []byte) ([]*events.Event, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("open gzip: %w", err)
+	}
+	defer gz.Close()
+
+	var evts []*events.Event
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		event, err := events.FromJSON(line)
+		if err != nil {
+			return nil, err
+		}
+		evts = append(evts, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+
+	return evts, nil
+}
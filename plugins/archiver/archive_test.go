@@ -0,0 +1,210 @@
+package archiver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+	"devlog/internal/testutil"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string][]byte)}
+}
+
+func (m *memoryStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func insertEventAt(t *testing.T, store *storage.Storage, ts time.Time) *events.Event {
+	t.Helper()
+	event := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Timestamp = ts.UTC().Format(time.RFC3339)
+	testutil.MustInsertEvents(t, store, event)
+	return event
+}
+
+func TestArchiverRunAndRestore(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	objects := newMemoryStore()
+	ctx := context.Background()
+
+	now := time.Now()
+	old := make([]*events.Event, 0, 5)
+	for i := 0; i < 5; i++ {
+		old = append(old, insertEventAt(t, store, now.AddDate(0, 0, -40).Add(time.Duration(i)*time.Hour)))
+	}
+	recent := insertEventAt(t, store, now.Add(-time.Hour))
+
+	arch := NewArchiver(store, objects, "devlog", 30*24*time.Hour, 2)
+
+	result, err := arch.Run(ctx)
+	testutil.AssertNoError(t, err, "run archiver")
+	testutil.AssertEqual(t, result.EventsArchived, 5, "events archived")
+	testutil.AssertEqual(t, result.Archives, 3, "archives written")
+	testutil.AssertEqual(t, result.EventsDeleted, int64(5), "events pruned")
+
+	count, err := store.Count()
+	testutil.AssertNoError(t, err, "count events")
+	testutil.AssertEqual(t, count, 1, "remaining events")
+
+	if _, err := store.GetEvent(recent.ID); err != nil {
+		t.Fatalf("recent event should not be archived: %v", err)
+	}
+
+	manifest, err := arch.LoadManifest(ctx)
+	testutil.AssertNoError(t, err, "load manifest")
+	testutil.AssertEqual(t, len(manifest.Archives), 3, "manifest entries")
+	for _, entry := range manifest.Archives {
+		if !strings.HasPrefix(entry.Key, "devlog/events/") || !strings.HasSuffix(entry.Key, ".jsonl.gz") {
+			t.Errorf("unexpected archive key %q", entry.Key)
+		}
+	}
+
+	second, err := arch.Run(ctx)
+	testutil.AssertNoError(t, err, "second run")
+	testutil.AssertEqual(t, second.EventsArchived, 0, "second run archived")
+
+	from := now.AddDate(0, 0, -41)
+	restored, err := arch.Restore(ctx, from, now.AddDate(0, 0, -39))
+	testutil.AssertNoError(t, err, "restore")
+	testutil.AssertEqual(t, restored.EventsRestored, 5, "events restored")
+
+	for _, event := range old {
+		got, err := store.GetEvent(event.ID)
+		testutil.AssertNoError(t, err, "get restored event")
+		testutil.AssertEqual(t, got.Timestamp, event.Timestamp, "restored timestamp")
+	}
+
+	again, err := arch.Restore(ctx, from, now.AddDate(0, 0, -39))
+	testutil.AssertNoError(t, err, "restore again")
+	testutil.AssertEqual(t, again.EventsRestored, 0, "events restored twice")
+	testutil.AssertEqual(t, again.Duplicates, 5, "duplicates skipped")
+}
+
+func TestArchiverRestoreChecksumMismatch(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	objects := newMemoryStore()
+	ctx := context.Background()
+
+	insertEventAt(t, store, time.Now().AddDate(0, 0, -10))
+
+	arch := NewArchiver(store, objects, "", 24*time.Hour, 100)
+	_, err := arch.Run(ctx)
+	testutil.AssertNoError(t, err, "run archiver")
+
+	manifest, err := arch.LoadManifest(ctx)
+	testutil.AssertNoError(t, err, "load manifest")
+	objects.objects[manifest.Archives[0].Key] = []byte("corrupted")
+
+	_, err = arch.Restore(ctx, time.Now().AddDate(0, 0, -11), time.Now())
+	testutil.AssertError(t, err, "restore corrupted archive")
+}
+
+func TestArchivesInRange(t *testing.T) {
+	manifest := &Manifest{Archives: []ArchiveEntry{
+		{Key: "b", From: "2025-01-03T00:00:00Z", To: "2025-01-04T00:00:00Z"},
+		{Key: "a", From: "2025-01-01T00:00:00Z", To: "2025-01-02T00:00:00Z"},
+		{Key: "c", From: "2025-02-01T00:00:00Z", To: "2025-02-02T00:00:00Z"},
+	}}
+
+	from := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	entries := ArchivesInRange(manifest, from, to)
+	testutil.AssertEqual(t, len(entries), 2, "entries in range")
+	testutil.AssertEqual(t, entries[0].Key, "a", "first entry")
+	testutil.AssertEqual(t, entries[1].Key, "b", "second entry")
+}
+
+func TestS3ClientPutGet(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(auth, "/us-east-1/s3/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("x-amz-content-sha256") == "" || r.Header.Get("x-amz-date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = string(body)
+		case http.MethodGet:
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(data))
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewS3Client(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          "archive",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		UsePathStyle:    true,
+	})
+	testutil.AssertNoError(t, err, "create client")
+
+	ctx := context.Background()
+	testutil.AssertNoError(t, client.Put(ctx, "devlog/manifest.json", []byte(`{"version":1}`), "application/json"), "put object")
+
+	if _, ok := objects["/archive/devlog/manifest.json"]; !ok {
+		t.Fatalf("object not stored at path-style key, got %v", objects)
+	}
+
+	data, err := client.Get(ctx, "devlog/manifest.json")
+	testutil.AssertNoError(t, err, "get object")
+	testutil.AssertEqual(t, string(data), `{"version":1}`, "object content")
+
+	_, err = client.Get(ctx, "devlog/missing.json")
+	if err != ErrObjectNotFound {
+		t.Fatalf("got %v, want ErrObjectNotFound", err)
+	}
+
+	testutil.AssertNoError(t, client.(ObjectDeleter).Delete(ctx, "devlog/manifest.json"), "delete object")
+	if _, err := client.Get(ctx, "devlog/manifest.json"); err != ErrObjectNotFound {
+		t.Fatalf("after delete got %v, want ErrObjectNotFound", err)
+	}
+}
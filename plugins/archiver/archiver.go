@@ -0,0 +1,255 @@
+package archiver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/storage"
+)
+
+type Plugin struct {
+	archiver *Archiver
+	storage  *storage.Storage
+	interval time.Duration
+	logger   *logger.Logger
+}
+
+type Config struct {
+	IntervalSeconds int    `json:"interval_seconds"`
+	RetainDays      int    `json:"retain_days"`
+	BatchSize       int    `json:"batch_size,omitempty"`
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix,omitempty"`
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	UsePathStyle    bool   `json:"use_path_style,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "archiver"
+}
+
+func (p *Plugin) Description() string {
+	return "Exports aged events to S3-compatible object storage and prunes them locally"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "archiver",
+		Description:  "Exports aged events to S3-compatible object storage and prunes them locally",
+		Dependencies: []string{},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Archiver plugin")
+	ctx.Log("Configure bucket, credentials and retain_days in the plugin configuration")
+	ctx.Log("Events older than retain_days are uploaded as gzipped JSONL and removed from the local database")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Archiver plugin")
+	ctx.Log("Archived objects are left in the bucket")
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		IntervalSeconds: 86400,
+		RetainDays:      90,
+		BatchSize:       1000,
+		Prefix:          "devlog",
+		Region:          "us-east-1",
+	}
+}
+
+func (p *Plugin) ValidateConfig(config interface{}) error {
+	cfgMap, ok := config.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	bucket, ok := cfgMap["bucket"].(string)
+	if !ok || bucket == "" {
+		return errors.NewValidation("bucket", "is required")
+	}
+
+	for _, field := range []string{"access_key_id", "secret_access_key"} {
+		val, ok := cfgMap[field].(string)
+		if !ok || val == "" {
+			return errors.NewValidation(field, "is required")
+		}
+	}
+
+	interval, err := numberField(cfgMap, "interval_seconds")
+	if err != nil {
+		return err
+	}
+	if interval < 3600 || interval > 7*86400 {
+		return errors.NewValidation("interval_seconds", "must be between 3600 and 604800")
+	}
+
+	retainDays, err := numberField(cfgMap, "retain_days")
+	if err != nil {
+		return err
+	}
+	if retainDays < 1 {
+		return errors.NewValidation("retain_days", "must be at least 1")
+	}
+
+	if _, ok := cfgMap["batch_size"]; ok {
+		batchSize, err := numberField(cfgMap, "batch_size")
+		if err != nil {
+			return err
+		}
+		if batchSize < 1 || batchSize > 10000 {
+			return errors.NewValidation("batch_size", "must be between 1 and 10000")
+		}
+	}
+
+	return nil
+}
+
+func numberField(cfgMap map[string]interface{}, field string) (int, error) {
+	val, ok := cfgMap[field]
+	if !ok {
+		return 0, errors.NewValidation(field, "is required")
+	}
+	switch v := val.(type) {
+	case float64:
+		return int(v), nil
+	case int:
+		return v, nil
+	default:
+		return 0, errors.NewValidation(field, "must be a number")
+	}
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+func NewFromConfig(cfg *Config, store *storage.Storage) (*Archiver, error) {
+	objects, err := NewS3Client(S3Config{
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		Bucket:          cfg.Bucket,
+		AccessKeyID:     cfg.AccessKeyID,
+		SecretAccessKey: cfg.SecretAccessKey,
+		UsePathStyle:    cfg.UsePathStyle,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create s3 client: %w", err)
+	}
+
+	retention := time.Duration(cfg.RetainDays) * 24 * time.Hour
+	return NewArchiver(store, objects, cfg.Prefix, retention, cfg.BatchSize), nil
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("archiver", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("archiver", "parse config", err)
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("archiver", "get data dir", err)
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("archiver", "open storage", err)
+	}
+	p.storage = store
+
+	archiver, err := NewFromConfig(cfg, store)
+	if err != nil {
+		store.Close()
+		return errors.WrapPlugin("archiver", "create archiver", err)
+	}
+	p.archiver = archiver
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+
+	p.run(ctx)
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context) {
+	p.logger.Info("archiver started", slog.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.archive(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("archiver stopped")
+			if p.storage != nil {
+				p.storage.Close()
+			}
+			return
+		case <-ticker.C:
+			p.archive(ctx)
+		}
+	}
+}
+
+func (p *Plugin) archive(ctx context.Context) {
+	timer := metrics.StartPluginTimer("archiver")
+	defer timer.Stop()
+
+	result, err := p.archiver.Run(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error("archive run failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	if result.EventsArchived > 0 {
+		p.logger.Info("archived events",
+			slog.Int("archives", result.Archives),
+			slog.Int("events", result.EventsArchived),
+			slog.Int64("pruned", result.EventsDeleted))
+	}
+}
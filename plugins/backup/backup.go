@@ -0,0 +1,374 @@
+package backup
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/encryption"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/storage"
+	"devlog/plugins/archiver"
+	syncplugin "devlog/plugins/sync"
+)
+
+const (
+	DestinationS3     = "s3"
+	DestinationWebDAV = "webdav"
+
+	DefaultKeep = 14
+
+	// checkInterval is how often the plugin looks whether a backup is due.
+	checkInterval = 15 * time.Minute
+	stateFile     = "backup-upload.json"
+)
+
+type Plugin struct {
+	uploader *Uploader
+	storage  *storage.Storage
+	interval time.Duration
+	dataDir  string
+	logger   *logger.Logger
+}
+
+type Config struct {
+	Destination     string `json:"destination"`
+	Key             string `json:"key"`
+	IntervalSeconds int    `json:"interval_seconds"`
+	Keep            int    `json:"keep,omitempty"`
+	Prefix          string `json:"prefix,omitempty"`
+	SkipSummaries   bool   `json:"skip_summaries,omitempty"`
+
+	// S3 destination
+	Bucket          string `json:"bucket,omitempty"`
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	UsePathStyle    bool   `json:"use_path_style,omitempty"`
+
+	// WebDAV destination
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "backup"
+}
+
+func (p *Plugin) Description() string {
+	return "Uploads encrypted database backups and summary files to S3 or WebDAV"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "backup",
+		Description:  "Uploads encrypted database backups and summary files to S3 or WebDAV",
+		Dependencies: []string{},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Backup plugin")
+	if key, err := GenerateKey(); err == nil {
+		ctx.Log("New backup key (store a copy somewhere safe; backups cannot be read without it): %s", key)
+	}
+	ctx.Log("Configure the destination (s3 or webdav) in the plugin configuration")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Backup plugin")
+	ctx.Log("Uploaded backups are left at the destination")
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		Destination:     DestinationS3,
+		IntervalSeconds: 86400,
+		Keep:            DefaultKeep,
+		Prefix:          "devlog-backup",
+		Region:          "us-east-1",
+	}
+}
+
+func (p *Plugin) ValidateConfig(cfg interface{}) error {
+	cfgMap, ok := cfg.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	c, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+	return c.Validate()
+}
+
+func (c *Config) Validate() error {
+	if _, err := DecodeKey(c.Key); err != nil {
+		return errors.NewValidation("key", err.Error())
+	}
+	if c.IntervalSeconds < 3600 || c.IntervalSeconds > 30*86400 {
+		return errors.NewValidation("interval_seconds", "must be between 3600 and 2592000")
+	}
+	if c.Keep < 0 {
+		return errors.NewValidation("keep", "must not be negative")
+	}
+
+	switch c.Destination {
+	case DestinationS3:
+		if c.Bucket == "" {
+			return errors.NewValidation("bucket", "is required for the s3 destination")
+		}
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return errors.NewValidation("access_key_id", "and secret_access_key are required for the s3 destination")
+		}
+	case DestinationWebDAV:
+		if c.URL == "" {
+			return errors.NewValidation("url", "is required for the webdav destination")
+		}
+	default:
+		return errors.NewValidation("destination", fmt.Sprintf("must be %q or %q", DestinationS3, DestinationWebDAV))
+	}
+	return nil
+}
+
+// GenerateKey returns a new random backup key in the form DecodeKey accepts.
+func GenerateKey() (string, error) {
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// DecodeKey parses the base64 key printed by 'devlog backup keygen'.
+func DecodeKey(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("is required (generate one with 'devlog backup keygen')")
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != encryption.KeySize {
+		return nil, fmt.Errorf("must be %d bytes, base64 encoded", encryption.KeySize)
+	}
+	return key, nil
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewFromConfig builds an uploader for the configured destination.
+func NewFromConfig(cfg *Config, store *storage.Storage, dataDir string) (*Uploader, error) {
+	key, err := DecodeKey(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("key %w", err)
+	}
+	cipher, err := encryption.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects archiver.ObjectStore
+	switch cfg.Destination {
+	case DestinationWebDAV:
+		objects, err = syncplugin.NewWebDAVClient(cfg.URL, cfg.Username, cfg.Password)
+	default:
+		objects, err = archiver.NewS3Client(archiver.S3Config{
+			Endpoint:        cfg.Endpoint,
+			Region:          cfg.Region,
+			Bucket:          cfg.Bucket,
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			UsePathStyle:    cfg.UsePathStyle,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s destination: %w", cfg.Destination, err)
+	}
+
+	summariesDir := filepath.Join(dataDir, "summaries")
+	if cfg.SkipSummaries {
+		summariesDir = ""
+	}
+	return NewUploader(store, objects, cipher, cfg.Prefix, cfg.Keep, summariesDir), nil
+}
+
+// State is the outcome of the last scheduled run, kept in the data
+// directory so 'devlog backup status' can show it without the daemon.
+type State struct {
+	LastRun     time.Time `json:"last_run"`
+	LastSuccess time.Time `json:"last_success,omitempty"`
+	LastKey     string    `json:"last_key,omitempty"`
+	LastSize    int64     `json:"last_size,omitempty"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+func LoadState(dataDir string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(dataDir, stateFile))
+	if os.IsNotExist(err) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", stateFile, err)
+	}
+	return &s, nil
+}
+
+func (s *State) save(dataDir string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dataDir, stateFile), data, 0644)
+}
+
+// Due reports whether a backup should run at now.
+func (s *State) Due(now time.Time, interval time.Duration) bool {
+	return s.LastSuccess.IsZero() || now.Sub(s.LastSuccess) >= interval
+}
+
+// Record stores the outcome of a run in the state file.
+func Record(dataDir string, now time.Time, result *RunResult, runErr error) error {
+	state, err := LoadState(dataDir)
+	if err != nil {
+		state = &State{}
+	}
+	state.LastRun = now
+	state.LastError = ""
+	if result != nil && result.Backup.Key != "" {
+		state.LastSuccess = now
+		state.LastKey = result.Backup.Key
+		state.LastSize = result.Backup.Size
+	}
+	if runErr != nil {
+		state.LastError = runErr.Error()
+	}
+	return state.save(dataDir)
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("backup", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("backup", "parse config", err)
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("backup", "get data dir", err)
+	}
+	p.dataDir = dataDir
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("backup", "open storage", err)
+	}
+	p.storage = store
+
+	uploader, err := NewFromConfig(cfg, store, dataDir)
+	if err != nil {
+		store.Close()
+		return errors.WrapPlugin("backup", "create uploader", err)
+	}
+	p.uploader = uploader
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+
+	p.run(ctx)
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context) {
+	p.logger.Info("backup uploads started", slog.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	p.backupIfDue(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("backup uploads stopped")
+			if p.storage != nil {
+				p.storage.Close()
+			}
+			return
+		case <-ticker.C:
+			p.backupIfDue(ctx)
+		}
+	}
+}
+
+func (p *Plugin) backupIfDue(ctx context.Context) {
+	state, err := LoadState(p.dataDir)
+	if err != nil {
+		p.logger.Warn("read backup state failed", slog.String("error", err.Error()))
+		state = &State{}
+	}
+	now := time.Now()
+	if !state.Due(now, p.interval) {
+		return
+	}
+
+	timer := metrics.StartPluginTimer("backup")
+	defer timer.Stop()
+
+	result, err := p.uploader.Run(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+	if err := Record(p.dataDir, now, result, err); err != nil {
+		p.logger.Warn("write backup state failed", slog.String("error", err.Error()))
+	}
+	if err != nil {
+		p.logger.Error("backup upload failed", slog.String("error", err.Error()))
+		return
+	}
+	p.logger.Info("uploaded backup",
+		slog.String("key", result.Backup.Key),
+		slog.Int64("bytes", result.Backup.Size),
+		slog.Int("summaries", result.Summaries),
+		slog.Int("pruned", result.Pruned))
+}
@@ -0,0 +1,203 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/encryption"
+	"devlog/internal/events"
+	"devlog/internal/storage"
+	"devlog/internal/testutil"
+	"devlog/plugins/archiver"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string][]byte)}
+}
+
+func (m *memoryStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, archiver.ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func (m *memoryStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *memoryStore) keys(prefix string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var keys []string
+	for k := range m.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func newTestUploader(t *testing.T, objects archiver.ObjectStore, keep int) (*Uploader, *storage.Storage, string) {
+	t.Helper()
+	key, err := encryption.GenerateKey()
+	testutil.AssertNoError(t, err, "generate key")
+	cipher, err := encryption.NewCipher(key)
+	testutil.AssertNoError(t, err, "create cipher")
+
+	store := testutil.NewTestStorage(t)
+	summaries := t.TempDir()
+	return NewUploader(store, objects, cipher, "devlog-backup", keep, summaries), store, summaries
+}
+
+func TestUploaderRunAndDownload(t *testing.T) {
+	objects := newMemoryStore()
+	uploader, store, summaries := newTestUploader(t, objects, 2)
+	ctx := context.Background()
+
+	evt := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+	testutil.MustInsertEvents(t, store, evt)
+	testutil.AssertNoError(t, os.WriteFile(filepath.Join(summaries, "summary_2026-01-02.md"), []byte("# Friday"), 0644), "write summary")
+
+	result, err := uploader.Run(ctx)
+	testutil.AssertNoError(t, err, "run")
+	testutil.AssertEqual(t, result.Summaries, 1, "summaries uploaded")
+	if !strings.HasPrefix(result.Backup.Key, "devlog-backup/db/events-") || !strings.HasSuffix(result.Backup.Key, ".db.gz.enc") {
+		t.Errorf("unexpected backup key %q", result.Backup.Key)
+	}
+
+	sealed, _ := objects.Get(ctx, "devlog-backup/summaries/summary_2026-01-02.md.enc")
+	if len(sealed) == 0 || strings.Contains(string(sealed), "Friday") {
+		t.Errorf("summary should be stored encrypted, got %q", sealed)
+	}
+
+	dest := filepath.Join(t.TempDir(), "restore.db.gz")
+	testutil.AssertNoError(t, uploader.Download(ctx, result.Backup.Key, dest), "download")
+
+	dbPath := filepath.Join(t.TempDir(), "events.db")
+	restored, err := storage.Restore(ctx, dest, dbPath, storage.RestoreOptions{})
+	testutil.AssertNoError(t, err, "restore downloaded backup")
+	testutil.AssertEqual(t, restored.Events, 1, "restored events")
+
+	// Unchanged summaries are not uploaded again.
+	uploader.now = func() time.Time { return time.Now().Add(time.Hour) }
+	result, err = uploader.Run(ctx)
+	testutil.AssertNoError(t, err, "second run")
+	testutil.AssertEqual(t, result.Summaries, 0, "summaries uploaded on second run")
+}
+
+func TestUploaderPrunesOldBackups(t *testing.T) {
+	objects := newMemoryStore()
+	uploader, _, _ := newTestUploader(t, objects, 2)
+	ctx := context.Background()
+
+	start := time.Date(2026, 1, 1, 3, 0, 0, 0, time.UTC)
+	var keys []string
+	for i := 0; i < 4; i++ {
+		at := start.Add(time.Duration(i) * 24 * time.Hour)
+		uploader.now = func() time.Time { return at }
+		result, err := uploader.Run(ctx)
+		testutil.AssertNoError(t, err, "run")
+		keys = append(keys, result.Backup.Key)
+	}
+
+	testutil.AssertEqual(t, len(objects.keys("devlog-backup/db/")), 2, "backups kept")
+	manifest, err := uploader.Manifest(ctx)
+	testutil.AssertNoError(t, err, "read manifest")
+	testutil.AssertEqual(t, len(manifest.Backups), 2, "manifest entries")
+	testutil.AssertEqual(t, manifest.Backups[0].Key, keys[3], "newest backup first")
+	if _, err := objects.Get(ctx, keys[0]); err != archiver.ErrObjectNotFound {
+		t.Errorf("oldest backup should be deleted, got %v", err)
+	}
+}
+
+func TestDownloadWrongKey(t *testing.T) {
+	objects := newMemoryStore()
+	uploader, _, _ := newTestUploader(t, objects, 1)
+	ctx := context.Background()
+
+	result, err := uploader.Run(ctx)
+	testutil.AssertNoError(t, err, "run")
+
+	other, _, _ := newTestUploader(t, objects, 1)
+	err = other.Download(ctx, result.Backup.Key, filepath.Join(t.TempDir(), "out.db.gz"))
+	if err == nil || !strings.Contains(err.Error(), "decrypt") {
+		t.Errorf("Download() with another key error = %v, want decrypt error", err)
+	}
+}
+
+func TestStateDue(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	state, err := LoadState(dir)
+	testutil.AssertNoError(t, err, "load empty state")
+	if !state.Due(now, time.Hour) {
+		t.Error("first backup should be due")
+	}
+
+	result := &RunResult{Backup: RemoteBackup{Key: "devlog-backup/db/x", Size: 10}}
+	testutil.AssertNoError(t, Record(dir, now, result, nil), "record")
+	state, err = LoadState(dir)
+	testutil.AssertNoError(t, err, "load state")
+	testutil.AssertEqual(t, state.LastKey, "devlog-backup/db/x", "last key")
+	if state.Due(now.Add(30*time.Minute), time.Hour) {
+		t.Error("backup should not be due before the interval")
+	}
+	if !state.Due(now.Add(time.Hour), time.Hour) {
+		t.Error("backup should be due after the interval")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	key, _ := GenerateKey()
+	valid := Config{
+		Destination:     DestinationWebDAV,
+		Key:             key,
+		IntervalSeconds: 86400,
+		URL:             "https://dav.example.com/devlog",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	tests := map[string]func(c *Config){
+		"missing key":         func(c *Config) { c.Key = "" },
+		"short key":           func(c *Config) { c.Key = "c2hvcnQ=" },
+		"interval too short":  func(c *Config) { c.IntervalSeconds = 60 },
+		"negative keep":       func(c *Config) { c.Keep = -1 },
+		"unknown destination": func(c *Config) { c.Destination = "ftp" },
+		"webdav needs url":    func(c *Config) { c.URL = "" },
+		"s3 needs bucket":     func(c *Config) { c.Destination = DestinationS3 },
+	}
+	for name, mutate := range tests {
+		c := valid
+		mutate(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"devlog/internal/encryption"
+	"devlog/internal/storage"
+	"devlog/plugins/archiver"
+)
+
+const encryptedSuffix = ".enc"
+
+// Manifest is stored unencrypted at <prefix>/manifest.json. It lists the
+// uploaded database backups and the hash of each summary file last uploaded,
+// so unchanged summaries are not sent again.
+type Manifest struct {
+	Backups   []RemoteBackup    `json:"backups"`
+	Summaries map[string]string `json:"summaries,omitempty"`
+}
+
+type RemoteBackup struct {
+	Key     string    `json:"key"`
+	Created time.Time `json:"created"`
+	Size    int64     `json:"size"`
+	SHA256  string    `json:"sha256"`
+}
+
+type RunResult struct {
+	Backup    RemoteBackup
+	Summaries int
+	Pruned    int
+}
+
+// Uploader copies the database with the online backup API, encrypts the
+// copy and pushes it to object storage, along with the daily summary files.
+type Uploader struct {
+	store        *storage.Storage
+	objects      archiver.ObjectStore
+	cipher       *encryption.Cipher
+	prefix       string
+	keep         int
+	summariesDir string
+	now          func() time.Time
+}
+
+// NewUploader returns an uploader keeping the newest keep backups under
+// prefix. summariesDir may be empty to skip summary files.
+func NewUploader(store *storage.Storage, objects archiver.ObjectStore, cipher *encryption.Cipher, prefix string, keep int, summariesDir string) *Uploader {
+	if keep <= 0 {
+		keep = DefaultKeep
+	}
+	return &Uploader{
+		store:        store,
+		objects:      objects,
+		cipher:       cipher,
+		prefix:       strings.Trim(prefix, "/"),
+		keep:         keep,
+		summariesDir: summariesDir,
+		now:          time.Now,
+	}
+}
+
+func (u *Uploader) key(parts ...string) string {
+	return path.Join(append([]string{u.prefix}, parts...)...)
+}
+
+// Run uploads one backup and any changed summary files, then deletes
+// backups beyond the retention count.
+func (u *Uploader) Run(ctx context.Context) (*RunResult, error) {
+	manifest, err := u.Manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RunResult{}
+	now := u.now()
+	backup, err := u.uploadDatabase(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	result.Backup = backup
+	manifest.Backups = append(manifest.Backups, backup)
+
+	if u.summariesDir != "" {
+		n, err := u.uploadSummaries(ctx, manifest)
+		result.Summaries = n
+		if err != nil {
+			// Keep the database backup in the manifest even when a summary
+			// upload fails.
+			if saveErr := u.saveManifest(ctx, manifest); saveErr != nil {
+				return result, saveErr
+			}
+			return result, err
+		}
+	}
+
+	result.Pruned, err = u.prune(ctx, manifest)
+	if saveErr := u.saveManifest(ctx, manifest); saveErr != nil {
+		return result, saveErr
+	}
+	return result, err
+}
+
+func (u *Uploader) uploadDatabase(ctx context.Context, now time.Time) (RemoteBackup, error) {
+	tmpDir, err := os.MkdirTemp("", "devlog-backup-*")
+	if err != nil {
+		return RemoteBackup{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	name := storage.BackupFileName(now, true)
+	local := filepath.Join(tmpDir, name)
+	if _, err := u.store.BackupContext(ctx, local, storage.BackupOptions{Gzip: true}); err != nil {
+		return RemoteBackup{}, err
+	}
+	data, err := os.ReadFile(local)
+	if err != nil {
+		return RemoteBackup{}, err
+	}
+	sealed, err := u.cipher.Seal(data)
+	if err != nil {
+		return RemoteBackup{}, fmt.Errorf("encrypt backup: %w", err)
+	}
+
+	key := u.key("db", name+encryptedSuffix)
+	if err := u.objects.Put(ctx, key, sealed, "application/octet-stream"); err != nil {
+		return RemoteBackup{}, err
+	}
+	sum := sha256.Sum256(sealed)
+	return RemoteBackup{Key: key, Created: now.UTC(), Size: int64(len(sealed)), SHA256: hex.EncodeToString(sum[:])}, nil
+}
+
+func (u *Uploader) uploadSummaries(ctx context.Context, manifest *Manifest) (int, error) {
+	files, err := filepath.Glob(filepath.Join(u.summariesDir, "*.md"))
+	if err != nil {
+		return 0, err
+	}
+	if manifest.Summaries == nil {
+		manifest.Summaries = make(map[string]string)
+	}
+
+	uploaded := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return uploaded, err
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		name := filepath.Base(file)
+		if manifest.Summaries[name] == hash {
+			continue
+		}
+
+		sealed, err := u.cipher.Seal(data)
+		if err != nil {
+			return uploaded, fmt.Errorf("encrypt %s: %w", name, err)
+		}
+		if err := u.objects.Put(ctx, u.key("summaries", name+encryptedSuffix), sealed, "application/octet-stream"); err != nil {
+			return uploaded, err
+		}
+		manifest.Summaries[name] = hash
+		uploaded++
+	}
+	return uploaded, nil
+}
+
+// prune deletes the oldest backups beyond the retention count. Stores that
+// cannot delete objects keep everything.
+func (u *Uploader) prune(ctx context.Context, manifest *Manifest) (int, error) {
+	sort.Slice(manifest.Backups, func(i, j int) bool {
+		return manifest.Backups[i].Created.After(manifest.Backups[j].Created)
+	})
+	if len(manifest.Backups) <= u.keep {
+		return 0, nil
+	}
+	deleter, ok := u.objects.(archiver.ObjectDeleter)
+	if !ok {
+		return 0, nil
+	}
+
+	var firstErr error
+	pruned := 0
+	kept := manifest.Backups[:u.keep:u.keep]
+	for _, old := range manifest.Backups[u.keep:] {
+		if err := deleter.Delete(ctx, old.Key); err != nil {
+			// Left in the manifest so the next run tries again.
+			kept = append(kept, old)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		pruned++
+	}
+	manifest.Backups = kept
+	return pruned, firstErr
+}
+
+// Manifest fetches the manifest, or an empty one before the first upload.
+func (u *Uploader) Manifest(ctx context.Context) (*Manifest, error) {
+	data, err := u.objects.Get(ctx, u.key("manifest.json"))
+	if errors.Is(err, archiver.ErrObjectNotFound) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	sort.Slice(m.Backups, func(i, j int) bool { return m.Backups[i].Created.After(m.Backups[j].Created) })
+	return &m, nil
+}
+
+func (u *Uploader) saveManifest(ctx context.Context, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return u.objects.Put(ctx, u.key("manifest.json"), data, "application/json")
+}
+
+// Download fetches and decrypts the backup stored at key and writes it to
+// dest as a gzipped database that 'devlog db restore' accepts.
+func (u *Uploader) Download(ctx context.Context, key, dest string) error {
+	sealed, err := u.objects.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	data, err := u.cipher.Open(sealed)
+	if err != nil {
+		return fmt.Errorf("decrypt %s: %w", key, err)
+	}
+	return os.WriteFile(dest, data, 0600)
+}
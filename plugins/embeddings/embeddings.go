@@ -0,0 +1,294 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/llm"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/storage"
+	llmplugin "devlog/plugins/llm"
+)
+
+type Plugin struct {
+	indexer  *Indexer
+	index    *Index
+	storage  *storage.Storage
+	interval time.Duration
+	logger   *logger.Logger
+}
+
+type Config struct {
+	Provider        string   `json:"provider,omitempty"`
+	BaseURL         string   `json:"base_url,omitempty"`
+	APIKey          string   `json:"api_key,omitempty"`
+	Model           string   `json:"model,omitempty"`
+	TimeoutSeconds  int      `json:"timeout_seconds,omitempty"`
+	IntervalSeconds int      `json:"interval_seconds"`
+	BatchSize       int      `json:"batch_size,omitempty"`
+	ExcludeSources  []string `json:"exclude_sources,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "embeddings"
+}
+
+func (p *Plugin) Description() string {
+	return "Indexes events and summaries as vector embeddings for semantic search"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "embeddings",
+		Description:  "Indexes events and summaries as vector embeddings for semantic search",
+		Dependencies: []string{},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Embeddings plugin")
+	ctx.Log("Events and summaries are embedded in the background and stored in %s", filepath.Join("~/.local/share/devlog", indexFileName))
+	ctx.Log("Leave provider empty to reuse the llm plugin's ollama or openai settings")
+	ctx.Log("With ollama, pull the model first: ollama pull %s", llm.DefaultOllamaEmbeddingModel)
+	ctx.Log("Search with: devlog search --semantic \"when did I debug the flaky websocket test\"")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Embeddings plugin")
+	ctx.Log("The index file %s is left in the data directory", indexFileName)
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		IntervalSeconds: 300,
+		BatchSize:       DefaultBatchSize,
+		ExcludeSources:  []string{"clipboard"},
+	}
+}
+
+func (p *Plugin) ValidateConfig(config interface{}) error {
+	cfgMap, ok := config.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	if provider, ok := cfgMap["provider"].(string); ok && provider != "" {
+		switch llm.ProviderType(provider) {
+		case llm.ProviderOllama, llm.ProviderOpenAI:
+		default:
+			return errors.NewValidation("provider", "must be 'ollama' or 'openai'")
+		}
+		if provider == string(llm.ProviderOpenAI) {
+			if key, _ := cfgMap["api_key"].(string); key == "" {
+				return errors.NewValidation("api_key", "is required for openai provider")
+			}
+		}
+	}
+
+	if val, ok := cfgMap["interval_seconds"]; ok {
+		interval, ok := val.(float64)
+		if !ok {
+			if i, isInt := val.(int); isInt {
+				interval, ok = float64(i), true
+			}
+		}
+		if !ok || interval < 30 {
+			return errors.NewValidation("interval_seconds", "must be a number of at least 30")
+		}
+	}
+
+	if val, ok := cfgMap["batch_size"]; ok {
+		batch, ok := val.(float64)
+		if !ok {
+			if i, isInt := val.(int); isInt {
+				batch, ok = float64(i), true
+			}
+		}
+		if !ok || batch < 1 || batch > 512 {
+			return errors.NewValidation("batch_size", "must be between 1 and 512")
+		}
+	}
+
+	return nil
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+func EmbedderConfig(cfg *Config, llmCfgMap map[string]interface{}) (llm.Config, error) {
+	embedCfg := llm.Config{
+		Provider: llm.ProviderType(cfg.Provider),
+		BaseURL:  cfg.BaseURL,
+		APIKey:   cfg.APIKey,
+		Model:    cfg.Model,
+		Timeout:  time.Duration(cfg.TimeoutSeconds) * time.Second,
+	}
+	if embedCfg.Provider != "" {
+		return embedCfg, nil
+	}
+
+	if llmCfgMap == nil {
+		return llm.Config{}, fmt.Errorf("no embeddings provider configured and the llm plugin is not set up")
+	}
+
+	llmCfg, err := llmplugin.ClientConfig(llmCfgMap)
+	if err != nil {
+		return llm.Config{}, err
+	}
+
+	candidates := llmCfg.Providers
+	if len(candidates) == 0 {
+		candidates = []llm.Config{llmCfg}
+	}
+	for _, c := range candidates {
+		if c.Provider == llm.ProviderOllama || c.Provider == llm.ProviderOpenAI {
+			embedCfg.Provider = c.Provider
+			embedCfg.BaseURL = c.BaseURL
+			embedCfg.APIKey = c.APIKey
+			return embedCfg, nil
+		}
+	}
+
+	return llm.Config{}, fmt.Errorf("the llm plugin has no ollama or openai provider; set provider in the embeddings config")
+}
+
+func NewEmbedderFromConfig(appCfg *config.Config, cfg *Config) (llm.Embedder, error) {
+	var llmCfgMap map[string]interface{}
+	if appCfg.IsPluginEnabled("llm") {
+		llmCfgMap, _ = appCfg.GetPluginConfig("llm")
+	}
+
+	embedCfg, err := EmbedderConfig(cfg, llmCfgMap)
+	if err != nil {
+		return nil, err
+	}
+	return llm.NewEmbedder(embedCfg)
+}
+
+func IndexPath(dataDir string) string {
+	return filepath.Join(dataDir, indexFileName)
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("embeddings", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("embeddings", "parse config", err)
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	appCfg, err := config.Load()
+	if err != nil {
+		return errors.WrapPlugin("embeddings", "load config", err)
+	}
+
+	embedder, err := NewEmbedderFromConfig(appCfg, cfg)
+	if err != nil {
+		return errors.WrapPlugin("embeddings", "create embedder", err)
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("embeddings", "get data dir", err)
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("embeddings", "open storage", err)
+	}
+	p.storage = store
+
+	index, err := OpenIndex(IndexPath(dataDir))
+	if err != nil {
+		store.Close()
+		return errors.WrapPlugin("embeddings", "open index", err)
+	}
+	p.index = index
+
+	p.indexer = NewIndexer(store, index, embedder, cfg.BatchSize, cfg.ExcludeSources)
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	if p.interval <= 0 {
+		p.interval = 5 * time.Minute
+	}
+
+	p.run(ctx, embedder.Model())
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context, model string) {
+	p.logger.Info("embeddings indexer started", slog.Duration("interval", p.interval), slog.String("model", model))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.indexNew(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("embeddings indexer stopped")
+			p.index.Close()
+			p.storage.Close()
+			return
+		case <-ticker.C:
+			p.indexNew(ctx)
+		}
+	}
+}
+
+func (p *Plugin) indexNew(ctx context.Context) {
+	timer := metrics.StartPluginTimer("embeddings")
+	defer timer.Stop()
+
+	result, err := p.indexer.Run(ctx)
+	if result != nil && result.Reset {
+		p.logger.Info("embedding model changed, rebuilding index")
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error("embeddings indexing failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+
+	if result.Events > 0 || result.Summaries > 0 {
+		p.logger.Info("indexed embeddings",
+			slog.Int("events", result.Events),
+			slog.Int("summaries", result.Summaries))
+	}
+}
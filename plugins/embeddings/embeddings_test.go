@@ -0,0 +1,245 @@
+package embeddings
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+	"devlog/internal/testutil"
+)
+
+var vocabulary = []string{"websocket", "flaky", "test", "migration", "database", "deploy"}
+
+type keywordEmbedder struct {
+	model string
+	calls int
+}
+
+func (k *keywordEmbedder) Model() string {
+	return k.model
+}
+
+func (k *keywordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	k.calls++
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		lower := strings.ToLower(text)
+		vec := make([]float32, len(vocabulary)+1)
+		vec[len(vocabulary)] = 0.01
+		for j, word := range vocabulary {
+			if strings.Contains(lower, word) {
+				vec[j] = 1
+			}
+		}
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+func openTestIndex(t *testing.T) *Index {
+	t.Helper()
+	index, err := OpenIndex(filepath.Join(t.TempDir(), indexFileName))
+	if err != nil {
+		t.Fatalf("OpenIndex() error = %v", err)
+	}
+	t.Cleanup(func() { index.Close() })
+	return index
+}
+
+func commandEvent(repo, command string) *events.Event {
+	return testutil.NewEventBuilder().
+		WithSource(string(events.SourceShell)).
+		WithType(string(events.TypeCommand)).
+		WithRepo(repo).
+		WithPayloadField("command", command).
+		Build()
+}
+
+func TestIndexSearchRanksByCosineSimilarity(t *testing.T) {
+	index := openTestIndex(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	err := index.Put(ctx, []Entry{
+		{Kind: KindEvent, Ref: "a", Timestamp: now, Vector: []float32{1, 0, 0}},
+		{Kind: KindEvent, Ref: "b", Timestamp: now, Vector: []float32{0.7, 0.7, 0}},
+		{Kind: KindSummary, Ref: "1", Timestamp: now.Add(-48 * time.Hour), Vector: []float32{0, 0, 5}},
+	})
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	matches, err := index.Search(ctx, []float32{2, 0, 0}, 2, SearchFilter{})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(matches) != 2 || matches[0].Ref != "a" || matches[1].Ref != "b" {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+	if matches[0].Score < 0.99 {
+		t.Errorf("expected normalized score near 1, got %f", matches[0].Score)
+	}
+
+	after := now.Add(-time.Hour)
+	matches, err = index.Search(ctx, []float32{0, 0, 1}, 5, SearchFilter{Kinds: []string{KindSummary}, After: &after})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("expected old summary to be filtered out, got %+v", matches)
+	}
+}
+
+func TestIndexEnsureModelResetsOnChange(t *testing.T) {
+	index := openTestIndex(t)
+	ctx := context.Background()
+
+	reset, err := index.EnsureModel(ctx, "ollama/a")
+	if err != nil || reset {
+		t.Fatalf("first EnsureModel() = %v, %v", reset, err)
+	}
+	if err := index.Put(ctx, []Entry{{Kind: KindEvent, Ref: "a", Timestamp: time.Now(), Vector: []float32{1}}}); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	reset, err = index.EnsureModel(ctx, "ollama/a")
+	if err != nil || reset {
+		t.Fatalf("same-model EnsureModel() = %v, %v", reset, err)
+	}
+
+	reset, err = index.EnsureModel(ctx, "openai/b")
+	if err != nil || !reset {
+		t.Fatalf("changed-model EnsureModel() = %v, %v", reset, err)
+	}
+	counts, err := index.Count(ctx)
+	if err != nil {
+		t.Fatalf("Count() error = %v", err)
+	}
+	if counts[KindEvent] != 0 {
+		t.Errorf("expected index to be cleared, got %v", counts)
+	}
+}
+
+func TestIndexerAndSemanticSearch(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	index := openTestIndex(t)
+	embedder := &keywordEmbedder{model: "test/keywords"}
+	ctx := context.Background()
+
+	websocket := commandEvent("api", "go test -run TestWebsocketReconnect -count=20 ./flaky/...")
+	migration := commandEvent("api", "make database migration")
+	deploy := commandEvent("web", "kubectl rollout deploy frontend")
+	clipboard := testutil.NewEventBuilder().
+		WithSource(string(events.SourceClipboard)).
+		WithType(string(events.TypeCopy)).
+		WithPayloadField("content", "websocket flaky test").
+		Build()
+	testutil.MustInsertEvents(t, store, websocket, migration, deploy, clipboard)
+
+	now := time.Now()
+	err := store.InsertSummaryContext(ctx, &storage.Summary{
+		PeriodStart:  now.Add(-time.Hour),
+		PeriodEnd:    now,
+		ContextStart: now.Add(-2 * time.Hour),
+		Repos:        []string{"api"},
+		Text:         "Spent the afternoon chasing a flaky websocket test",
+		CreatedAt:    now,
+	})
+	if err != nil {
+		t.Fatalf("InsertSummaryContext() error = %v", err)
+	}
+
+	indexer := NewIndexer(store, index, embedder, 2, []string{string(events.SourceClipboard)})
+	result, err := indexer.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Events != 3 || result.Summaries != 1 {
+		t.Fatalf("expected 3 events and 1 summary indexed, got %+v", result)
+	}
+
+	result, err = indexer.Run(ctx)
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if result.Events != 0 || result.Summaries != 0 {
+		t.Errorf("expected nothing new to index, got %+v", result)
+	}
+
+	results, err := Search(ctx, store, index, embedder, storage.SearchOptions{
+		Query: "when did I debug the flaky websocket test",
+		Limit: 1,
+		Scope: storage.ScopeEvents,
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Event == nil || results[0].Event.ID != websocket.ID {
+		t.Fatalf("expected websocket event first, got %+v", results)
+	}
+
+	results, err = Search(ctx, store, index, embedder, storage.SearchOptions{
+		Query: "flaky websocket",
+		Limit: 5,
+		Scope: storage.ScopeSummaries,
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) == 0 || results[0].Summary == nil {
+		t.Fatalf("expected a summary result, got %+v", results)
+	}
+
+	results, err = Search(ctx, store, index, embedder, storage.SearchOptions{
+		Query:       "deploy",
+		Limit:       5,
+		Scope:       storage.ScopeEvents,
+		RepoPattern: "api",
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	for _, r := range results {
+		if r.Event.Repo != "api" {
+			t.Errorf("repo filter not applied: %+v", r.Event)
+		}
+	}
+
+	_, err = Search(ctx, store, index, &keywordEmbedder{model: "other/model"}, storage.SearchOptions{Query: "deploy"})
+	if err == nil {
+		t.Error("expected error when the configured model differs from the index")
+	}
+}
+
+func TestEmbedderConfigFallsBackToLLMPlugin(t *testing.T) {
+	cfg, err := EmbedderConfig(&Config{}, map[string]interface{}{
+		"provider": "ollama",
+		"base_url": "http://localhost:11434",
+		"model":    "qwen2.5:14b",
+	})
+	if err != nil {
+		t.Fatalf("EmbedderConfig() error = %v", err)
+	}
+	if cfg.Provider != "ollama" || cfg.BaseURL != "http://localhost:11434" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.Model != "" {
+		t.Errorf("chat model should not be reused for embeddings, got %q", cfg.Model)
+	}
+
+	if _, err := EmbedderConfig(&Config{}, map[string]interface{}{"provider": "anthropic", "api_key": "k"}); err == nil {
+		t.Error("expected error when only anthropic is configured")
+	}
+
+	cfg, err = EmbedderConfig(&Config{Provider: "openai", APIKey: "k", Model: "text-embedding-3-large"}, nil)
+	if err != nil {
+		t.Fatalf("EmbedderConfig() error = %v", err)
+	}
+	if cfg.Model != "text-embedding-3-large" {
+		t.Errorf("unexpected model %q", cfg.Model)
+	}
+}
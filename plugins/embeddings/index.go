@@ -0,0 +1,286 @@
+package embeddings
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"math"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"devlog/internal/errors"
+)
+
+const (
+	KindEvent   = "event"
+	KindSummary = "summary"
+
+	metaModel      = "model"
+	metaEventRow   = "event_row"
+	metaSummaryID  = "summary_id"
+	indexFileName  = "embeddings.db"
+	indexOpTimeout = 30 * time.Second
+	searchTimeout  = 60 * time.Second
+)
+
+type Entry struct {
+	Kind      string
+	Ref       string
+	Timestamp time.Time
+	Vector    []float32
+}
+
+type Match struct {
+	Kind      string
+	Ref       string
+	Timestamp time.Time
+	Score     float64
+}
+
+type SearchFilter struct {
+	Kinds []string
+	After *time.Time
+}
+
+type Index struct {
+	db *sql.DB
+}
+
+func OpenIndex(path string) (*Index, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, errors.WrapStorage("open embeddings index", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	schema := `
+		PRAGMA journal_mode=WAL;
+		CREATE TABLE IF NOT EXISTS vectors (
+			kind TEXT NOT NULL,
+			ref TEXT NOT NULL,
+			timestamp INTEGER NOT NULL,
+			vector BLOB NOT NULL,
+			PRIMARY KEY (kind, ref)
+		);
+		CREATE INDEX IF NOT EXISTS idx_vectors_timestamp ON vectors(timestamp);
+		CREATE TABLE IF NOT EXISTS meta (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.WrapStorage("create embeddings index", err)
+	}
+
+	return &Index{db: db}, nil
+}
+
+func (idx *Index) Close() error {
+	return idx.db.Close()
+}
+
+func (idx *Index) Meta(ctx context.Context, key string) (string, error) {
+	var value string
+	err := idx.db.QueryRowContext(ctx, "SELECT value FROM meta WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WrapStorage("read index meta", err)
+	}
+	return value, nil
+}
+
+func (idx *Index) SetMeta(ctx context.Context, key, value string) error {
+	_, err := idx.db.ExecContext(ctx,
+		"INSERT INTO meta (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+		key, value)
+	if err != nil {
+		return errors.WrapStorage("write index meta", err)
+	}
+	return nil
+}
+
+func (idx *Index) EnsureModel(ctx context.Context, model string) (bool, error) {
+	current, err := idx.Meta(ctx, metaModel)
+	if err != nil {
+		return false, err
+	}
+	if current == model {
+		return false, nil
+	}
+
+	if err := idx.Reset(ctx); err != nil {
+		return false, err
+	}
+	return current != "", idx.SetMeta(ctx, metaModel, model)
+}
+
+func (idx *Index) Reset(ctx context.Context) error {
+	if _, err := idx.db.ExecContext(ctx, "DELETE FROM vectors; DELETE FROM meta;"); err != nil {
+		return errors.WrapStorage("reset embeddings index", err)
+	}
+	return nil
+}
+
+func (idx *Index) Put(ctx context.Context, entries []Entry) error {
+	ctx, cancel := context.WithTimeout(ctx, indexOpTimeout)
+	defer cancel()
+
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.WrapStorage("begin index transaction", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx,
+		"INSERT OR REPLACE INTO vectors (kind, ref, timestamp, vector) VALUES (?, ?, ?, ?)")
+	if err != nil {
+		return errors.WrapStorage("prepare index insert", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if _, err := stmt.ExecContext(ctx, e.Kind, e.Ref, e.Timestamp.Unix(), encodeVector(normalize(e.Vector))); err != nil {
+			return errors.WrapStorage("insert vector", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapStorage("commit index transaction", err)
+	}
+	return nil
+}
+
+func (idx *Index) Count(ctx context.Context) (map[string]int, error) {
+	rows, err := idx.db.QueryContext(ctx, "SELECT kind, COUNT(*) FROM vectors GROUP BY kind")
+	if err != nil {
+		return nil, errors.WrapStorage("count vectors", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var kind string
+		var n int
+		if err := rows.Scan(&kind, &n); err != nil {
+			return nil, errors.WrapStorage("scan count", err)
+		}
+		counts[kind] = n
+	}
+	return counts, rows.Err()
+}
+
+func (idx *Index) Search(ctx context.Context, query []float32, limit int, filter SearchFilter) ([]Match, error) {
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	q := normalize(query)
+
+	sqlQuery := "SELECT kind, ref, timestamp, vector FROM vectors WHERE 1=1"
+	var args []interface{}
+	if filter.After != nil {
+		sqlQuery += " AND timestamp >= ?"
+		args = append(args, filter.After.Unix())
+	}
+	if len(filter.Kinds) == 1 {
+		sqlQuery += " AND kind = ?"
+		args = append(args, filter.Kinds[0])
+	}
+
+	rows, err := idx.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, errors.WrapStorage("scan vectors", err)
+	}
+	defer rows.Close()
+
+	top := &matchHeap{}
+	for rows.Next() {
+		var m Match
+		var ts int64
+		var blob []byte
+		if err := rows.Scan(&m.Kind, &m.Ref, &ts, &blob); err != nil {
+			return nil, errors.WrapStorage("scan vector", err)
+		}
+
+		vec := decodeVector(blob)
+		if len(vec) != len(q) {
+			continue
+		}
+		m.Score = dot(q, vec)
+		m.Timestamp = time.Unix(ts, 0)
+
+		if top.Len() < limit {
+			heap.Push(top, m)
+		} else if m.Score > (*top)[0].Score {
+			(*top)[0] = m
+			heap.Fix(top, 0)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapStorage("iterate vectors", err)
+	}
+
+	matches := make([]Match, top.Len())
+	for i := len(matches) - 1; i >= 0; i-- {
+		matches[i] = heap.Pop(top).(Match)
+	}
+	return matches, nil
+}
+
+type matchHeap []Match
+
+func (h matchHeap) Len() int            { return len(h) }
+func (h matchHeap) Less(i, j int) bool  { return h[i].Score < h[j].Score }
+func (h matchHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x interface{}) { *h = append(*h, x.(Match)) }
+func (h *matchHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	m := old[n-1]
+	*h = old[:n-1]
+	return m
+}
+
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := float32(math.Sqrt(sum))
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = x / norm
+	}
+	return out
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(x))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
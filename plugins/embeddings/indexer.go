@@ -0,0 +1,178 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/llm"
+	"devlog/internal/storage"
+)
+
+const DefaultBatchSize = 32
+
+type Indexer struct {
+	store     *storage.Storage
+	index     *Index
+	embedder  llm.Embedder
+	batchSize int
+	exclude   map[string]bool
+}
+
+type IndexResult struct {
+	Events    int
+	Summaries int
+	Reset     bool
+}
+
+func NewIndexer(store *storage.Storage, index *Index, embedder llm.Embedder, batchSize int, excludeSources []string) *Indexer {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	exclude := make(map[string]bool, len(excludeSources))
+	for _, s := range excludeSources {
+		exclude[s] = true
+	}
+	return &Indexer{
+		store:     store,
+		index:     index,
+		embedder:  embedder,
+		batchSize: batchSize,
+		exclude:   exclude,
+	}
+}
+
+func (ix *Indexer) Run(ctx context.Context) (*IndexResult, error) {
+	result := &IndexResult{}
+
+	reset, err := ix.index.EnsureModel(ctx, ix.embedder.Model())
+	if err != nil {
+		return nil, err
+	}
+	result.Reset = reset
+
+	for {
+		n, done, err := ix.indexEvents(ctx)
+		result.Events += n
+		if err != nil {
+			return result, err
+		}
+		if done {
+			break
+		}
+	}
+
+	for {
+		n, done, err := ix.indexSummaries(ctx)
+		result.Summaries += n
+		if err != nil {
+			return result, err
+		}
+		if done {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (ix *Indexer) indexEvents(ctx context.Context) (int, bool, error) {
+	afterRow, err := ix.metaInt(ctx, metaEventRow)
+	if err != nil {
+		return 0, true, err
+	}
+
+	evts, lastRow, err := ix.store.EventsAfterRowContext(ctx, afterRow, ix.batchSize)
+	if err != nil {
+		return 0, true, err
+	}
+	if len(evts) == 0 {
+		return 0, true, nil
+	}
+
+	var docs []*events.Event
+	var texts []string
+	for _, evt := range evts {
+		if ix.exclude[evt.Source] {
+			continue
+		}
+		docs = append(docs, evt)
+		texts = append(texts, EventText(evt))
+	}
+
+	if len(texts) > 0 {
+		vectors, err := ix.embedder.Embed(ctx, texts)
+		if err != nil {
+			return 0, true, fmt.Errorf("embed events: %w", err)
+		}
+
+		entries := make([]Entry, len(docs))
+		for i, evt := range docs {
+			ts, _ := time.Parse(time.RFC3339, evt.Timestamp)
+			entries[i] = Entry{Kind: KindEvent, Ref: evt.ID, Timestamp: ts, Vector: vectors[i]}
+		}
+		if err := ix.index.Put(ctx, entries); err != nil {
+			return 0, true, err
+		}
+	}
+
+	if err := ix.index.SetMeta(ctx, metaEventRow, strconv.FormatInt(lastRow, 10)); err != nil {
+		return 0, true, err
+	}
+
+	return len(docs), len(evts) < ix.batchSize, nil
+}
+
+func (ix *Indexer) indexSummaries(ctx context.Context) (int, bool, error) {
+	afterID, err := ix.metaInt(ctx, metaSummaryID)
+	if err != nil {
+		return 0, true, err
+	}
+
+	summaries, err := ix.store.SummariesAfterIDContext(ctx, afterID, ix.batchSize)
+	if err != nil {
+		return 0, true, err
+	}
+	if len(summaries) == 0 {
+		return 0, true, nil
+	}
+
+	texts := make([]string, len(summaries))
+	for i, s := range summaries {
+		texts[i] = SummaryText(s)
+	}
+
+	vectors, err := ix.embedder.Embed(ctx, texts)
+	if err != nil {
+		return 0, true, fmt.Errorf("embed summaries: %w", err)
+	}
+
+	entries := make([]Entry, len(summaries))
+	for i, s := range summaries {
+		entries[i] = Entry{Kind: KindSummary, Ref: strconv.FormatInt(s.ID, 10), Timestamp: s.PeriodStart, Vector: vectors[i]}
+	}
+	if err := ix.index.Put(ctx, entries); err != nil {
+		return 0, true, err
+	}
+
+	lastID := summaries[len(summaries)-1].ID
+	if err := ix.index.SetMeta(ctx, metaSummaryID, strconv.FormatInt(lastID, 10)); err != nil {
+		return 0, true, err
+	}
+
+	return len(summaries), len(summaries) < ix.batchSize, nil
+}
+
+func (ix *Indexer) metaInt(ctx context.Context, key string) (int64, error) {
+	value, err := ix.index.Meta(ctx, key)
+	if err != nil || value == "" {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index position %q for %s", value, key)
+	}
+	return n, nil
+}
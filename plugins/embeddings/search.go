@@ -0,0 +1,119 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"devlog/internal/llm"
+	"devlog/internal/storage"
+)
+
+func Search(ctx context.Context, store *storage.Storage, index *Index, embedder llm.Embedder, opts storage.SearchOptions) ([]*storage.SearchResult, error) {
+	model, err := index.Meta(ctx, metaModel)
+	if err != nil {
+		return nil, err
+	}
+	if model == "" {
+		return nil, fmt.Errorf("embeddings index is empty (is the embeddings plugin running in the daemon?)")
+	}
+	if model != embedder.Model() {
+		return nil, fmt.Errorf("embeddings index was built with %s but %s is configured; restart the daemon to rebuild it", model, embedder.Model())
+	}
+
+	vectors, err := embedder.Embed(ctx, []string{opts.Query})
+	if err != nil {
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+
+	filter := SearchFilter{After: opts.After}
+	switch opts.Scope {
+	case storage.ScopeSummaries:
+		filter.Kinds = []string{KindSummary}
+	case storage.ScopeAll:
+	default:
+		filter.Kinds = []string{KindEvent}
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	modules := make(map[string]bool, len(opts.Modules))
+	for _, m := range opts.Modules {
+		modules[m] = true
+	}
+
+	candidates := limit
+	if len(modules) > 0 || len(opts.Types) > 0 || opts.RepoPattern != "" || opts.BranchPattern != "" {
+		candidates = limit * 10
+	}
+
+	matches, err := index.Search(ctx, vectors[0], candidates, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]bool, len(opts.Types))
+	for _, t := range opts.Types {
+		types[t] = true
+	}
+
+	var results []*storage.SearchResult
+	for _, m := range matches {
+		if len(results) >= limit {
+			break
+		}
+
+		switch m.Kind {
+		case KindEvent:
+			evt, err := store.GetEventContext(ctx, m.Ref)
+			if err != nil {
+				continue
+			}
+			if len(modules) > 0 && !modules[evt.Source] {
+				continue
+			}
+			if len(types) > 0 && !types[evt.Type] {
+				continue
+			}
+			if !containsFold(evt.Repo, opts.RepoPattern) || !containsFold(evt.Branch, opts.BranchPattern) {
+				continue
+			}
+			results = append(results, &storage.SearchResult{Event: evt, Rank: m.Score})
+		case KindSummary:
+			id, err := strconv.ParseInt(m.Ref, 10, 64)
+			if err != nil {
+				continue
+			}
+			summary, err := store.GetSummaryContext(ctx, id)
+			if err != nil {
+				continue
+			}
+			if opts.BranchPattern != "" || !summaryMatchesRepo(summary, opts.RepoPattern) {
+				continue
+			}
+			results = append(results, &storage.SearchResult{Summary: summary, Rank: m.Score})
+		}
+	}
+
+	return results, nil
+}
+
+func containsFold(s, pattern string) bool {
+	return pattern == "" || strings.Contains(strings.ToLower(s), strings.ToLower(pattern))
+}
+
+func summaryMatchesRepo(summary *storage.Summary, pattern string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, repo := range summary.Repos {
+		if containsFold(repo, pattern) {
+			return true
+		}
+	}
+	return false
+}
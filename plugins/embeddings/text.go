@@ -0,0 +1,55 @@
+package embeddings
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+)
+
+const maxDocumentChars = 2000
+
+var payloadTextKeys = []string{
+	"summary", "message", "command", "text", "content", "user_message",
+	"description", "file_path", "file", "title", "resource_type", "resource_names",
+}
+
+func EventText(evt *events.Event) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s", evt.Source, evt.Type)
+	if evt.Repo != "" {
+		fmt.Fprintf(&sb, " in %s", evt.Repo)
+	}
+	if evt.Branch != "" {
+		fmt.Fprintf(&sb, " on %s", evt.Branch)
+	}
+
+	for _, key := range payloadTextKeys {
+		if v, ok := evt.Payload[key].(string); ok && strings.TrimSpace(v) != "" {
+			sb.WriteString("\n")
+			sb.WriteString(strings.TrimSpace(v))
+		}
+	}
+	if tags := evt.Tags(); len(tags) > 0 {
+		fmt.Fprintf(&sb, "\ntags: %s", strings.Join(tags, ", "))
+	}
+
+	return truncate(sb.String())
+}
+
+func SummaryText(s *storage.Summary) string {
+	text := s.Text
+	if len(s.Repos) > 0 {
+		text = fmt.Sprintf("repos: %s\n%s", strings.Join(s.Repos, ", "), text)
+	}
+	return truncate(text)
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxDocumentChars {
+		return s
+	}
+	return string([]rune(s)[:maxDocumentChars])
+}
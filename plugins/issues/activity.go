@@ -0,0 +1,68 @@
+package issues
+
+import (
+	"sort"
+	"time"
+
+	"devlog/internal/events"
+)
+
+// Activity is the work recorded against one issue.
+type Activity struct {
+	Key    string
+	Title  string
+	Events int
+	Repos  []string
+	First  time.Time
+	Last   time.Time
+}
+
+// Group collects events by their issue field, most recently active first.
+func Group(evts []*events.Event) []*Activity {
+	byKey := make(map[string]*Activity)
+	repos := make(map[string]map[string]bool)
+	for _, e := range evts {
+		key, _ := e.Payload["issue"].(string)
+		if key == "" {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, e.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		a := byKey[key]
+		if a == nil {
+			a = &Activity{Key: key, First: ts, Last: ts}
+			byKey[key] = a
+			repos[key] = make(map[string]bool)
+		}
+		a.Events++
+		if ts.Before(a.First) {
+			a.First = ts
+		}
+		if ts.After(a.Last) {
+			a.Last = ts
+		}
+		if title, _ := e.Payload["issue_title"].(string); title != "" {
+			a.Title = title
+		}
+		if e.Repo != "" && !repos[key][e.Repo] {
+			repos[key][e.Repo] = true
+			a.Repos = append(a.Repos, e.Repo)
+		}
+	}
+
+	out := make([]*Activity, 0, len(byKey))
+	for _, a := range byKey {
+		sort.Strings(a.Repos)
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Last.Equal(out[j].Last) {
+			return out[i].Last.After(out[j].Last)
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
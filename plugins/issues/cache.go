@@ -0,0 +1,96 @@
+package issues
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Cache keeps what the tracker said about each issue key, in issues.json in
+// the data directory, and which keys still need looking up.
+type Cache struct {
+	mu      sync.RWMutex
+	path    string
+	issues  map[string]*Issue
+	pending map[string]bool
+}
+
+func LoadCache(path string) (*Cache, error) {
+	c := &Cache{path: path, issues: make(map[string]*Issue), pending: make(map[string]bool)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.issues); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return c, nil
+}
+
+func (c *Cache) Get(key string) (*Issue, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	issue, ok := c.issues[key]
+	return issue, ok
+}
+
+// Note records that key was seen, so it is looked up if it is not cached.
+func (c *Cache) Note(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.issues[key]; !ok {
+		c.pending[key] = true
+	}
+}
+
+func (c *Cache) Put(issue *Issue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.issues[issue.Key] = issue
+	delete(c.pending, issue.Key)
+}
+
+// Due returns up to limit keys never looked up or last fetched more than
+// maxAge before now, never-fetched first.
+func (c *Cache) Due(now time.Time, maxAge time.Duration, limit int) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var keys []string
+	for key := range c.pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var stale []*Issue
+	for _, issue := range c.issues {
+		if now.Sub(issue.Fetched) > maxAge {
+			stale = append(stale, issue)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Fetched.Before(stale[j].Fetched) })
+	for _, issue := range stale {
+		keys = append(keys, issue.Key)
+	}
+
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys
+}
+
+func (c *Cache) Save() error {
+	c.mu.RLock()
+	data, err := json.MarshalIndent(c.issues, "", "  ")
+	c.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
@@ -0,0 +1,102 @@
+package issues
+
+import (
+	"regexp"
+	"strings"
+
+	"devlog/internal/events"
+)
+
+// issuePattern matches Jira and Linear style keys: a project key of letters
+// and digits starting with a letter, a dash and a number.
+var issuePattern = regexp.MustCompile(`\b([A-Za-z][A-Za-z0-9]{1,9})-([1-9][0-9]{0,6})\b`)
+
+var projectPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]{1,9}$`)
+
+// notProjects are prefixes that look like issue keys in ordinary text but
+// name standards and encodings instead.
+var notProjects = map[string]bool{
+	"AES": true, "CVE": true, "GPT": true, "HTTP": true, "ISO": true,
+	"RFC": true, "SHA": true, "TLS": true, "UTF": true, "WIN": true,
+	"X86": true, "ARM": true, "MD": true, "PEP": true, "CWE": true,
+}
+
+// textFields are the payload fields searched for issue keys, besides the
+// branch: commit messages, Claude conversation summaries and prompts, and
+// PR titles and branches.
+var textFields = []string{"message", "summary", "user_message", "title", "merged_branch", "source_branch"}
+
+// Detector finds issue keys in events.
+type Detector struct {
+	projects map[string]bool
+}
+
+// NewDetector returns a detector accepting only the given project keys, or
+// any key not in the built-in list of false positives when projects is
+// empty.
+func NewDetector(projects []string) *Detector {
+	d := &Detector{}
+	if len(projects) > 0 {
+		d.projects = make(map[string]bool, len(projects))
+		for _, p := range projects {
+			d.projects[strings.ToUpper(strings.TrimSpace(p))] = true
+		}
+	}
+	return d
+}
+
+// Detect returns the issue keys mentioned by an event, upper-cased, with
+// the branch's keys first.
+func (d *Detector) Detect(event *events.Event) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(found []string) {
+		for _, k := range found {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	add(d.find(event.Branch))
+	for _, field := range textFields {
+		text, _ := event.Payload[field].(string)
+		add(d.find(text))
+	}
+	return keys
+}
+
+func (d *Detector) find(text string) []string {
+	if text == "" {
+		return nil
+	}
+	var keys []string
+	for _, m := range issuePattern.FindAllStringSubmatch(text, -1) {
+		project := strings.ToUpper(m[1])
+		if !d.accepts(project, m[1] == project) {
+			continue
+		}
+		keys = append(keys, project+"-"+m[2])
+	}
+	return keys
+}
+
+// accepts reports whether project is a project key. Lower-case keys, as in
+// Linear branch names (eng-123-fix-login), are only taken for configured
+// projects: unconfigured, too many words look like one (release-2).
+func (d *Detector) accepts(project string, upper bool) bool {
+	if d.projects != nil {
+		return d.projects[project]
+	}
+	if !upper || notProjects[project] {
+		return false
+	}
+	// Keys must start with two letters so that things like "X64-2" are not
+	// taken for tickets.
+	return isLetter(project[0]) && isLetter(project[1])
+}
+
+func isLetter(b byte) bool {
+	return b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,62 @@
+package issues
+
+import (
+	"reflect"
+	"testing"
+
+	"devlog/internal/events"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		projects []string
+		branch   string
+		payload  map[string]interface{}
+		want     []string
+	}{
+		{
+			name:    "branch and commit message",
+			branch:  "feature/PROJ-123-login",
+			payload: map[string]interface{}{"message": "PROJ-123: fix login, see ENG-9"},
+			want:    []string{"PROJ-123", "ENG-9"},
+		},
+		{
+			name:    "claude conversation",
+			payload: map[string]interface{}{"summary": "Worked on OPS-42 rollout"},
+			want:    []string{"OPS-42"},
+		},
+		{
+			name:    "standards and lower case are not issues",
+			branch:  "release-2",
+			payload: map[string]interface{}{"message": "bump to UTF-8, SHA-256 and ISO-8601; fix proj-12"},
+			want:    nil,
+		},
+		{
+			name:    "version-like keys",
+			payload: map[string]interface{}{"message": "support X64-2 and A-1"},
+			want:    nil,
+		},
+		{
+			name:     "configured projects match lower case",
+			projects: []string{"eng"},
+			branch:   "eng-123-fix-login",
+			payload:  map[string]interface{}{"message": "also PROJ-5"},
+			want:     []string{"ENG-123"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+			e.Branch = tt.branch
+			for k, v := range tt.payload {
+				e.Payload[k] = v
+			}
+			got := NewDetector(tt.projects).Detect(e)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
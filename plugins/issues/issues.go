@@ -0,0 +1,345 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+)
+
+const (
+	DefaultRefreshInterval = 5 * time.Minute
+
+	// maxAge is how long a looked-up title and status are trusted.
+	maxAge = 24 * time.Hour
+	// fetchBatch bounds the tracker requests made per refresh.
+	fetchBatch = 50
+	cacheFile  = "issues.json"
+)
+
+type Plugin struct {
+	mu       sync.RWMutex
+	detector *Detector
+	cache    *Cache
+	tracker  Tracker
+	interval time.Duration
+	logger   *logger.Logger
+}
+
+type Config struct {
+	// Projects restricts detection to these project keys (e.g. PROJ, ENG).
+	Projects []string `json:"projects,omitempty"`
+	// Tracker enables titles and statuses from "jira" or "linear".
+	Tracker                string `json:"tracker,omitempty"`
+	JiraURL                string `json:"jira_url,omitempty"`
+	JiraEmail              string `json:"jira_email,omitempty"`
+	JiraToken              string `json:"jira_token,omitempty"`
+	LinearAPIKey           string `json:"linear_api_key,omitempty"`
+	RefreshIntervalSeconds int    `json:"refresh_interval_seconds,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "issues"
+}
+
+func (p *Plugin) Description() string {
+	return "Links events to Jira and Linear issues mentioned in branches, commits and conversations"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "issues",
+		Description:  "Links events to Jira and Linear issues mentioned in branches, commits and conversations",
+		Dependencies: []string{},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Issues plugin")
+	ctx.Log("Set projects to your project keys (e.g. [PROJ, ENG]) to also match lower-case branch names")
+	ctx.Log("Set tracker to jira or linear to add issue titles to events")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Issues plugin")
+	ctx.Log("Events keep the issue fields already added")
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		RefreshIntervalSeconds: int(DefaultRefreshInterval.Seconds()),
+	}
+}
+
+func (p *Plugin) ValidateConfig(cfg interface{}) error {
+	cfgMap, ok := cfg.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	c, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+	return c.Validate()
+}
+
+func (c *Config) Validate() error {
+	for _, p := range c.Projects {
+		if !projectPattern.MatchString(p) {
+			return errors.NewValidation("projects", fmt.Sprintf("%q is not a project key", p))
+		}
+	}
+	if c.RefreshIntervalSeconds != 0 && c.RefreshIntervalSeconds < 60 {
+		return errors.NewValidation("refresh_interval_seconds", "must be at least 60")
+	}
+
+	switch c.Tracker {
+	case "":
+	case TrackerJira:
+		if c.JiraURL == "" {
+			return errors.NewValidation("jira_url", "is required for the jira tracker")
+		}
+	case TrackerLinear:
+	default:
+		return errors.NewValidation("tracker", fmt.Sprintf("must be %q, %q or empty", TrackerJira, TrackerLinear))
+	}
+	return nil
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewTracker returns the configured tracker client, or nil when lookups are
+// off. Tokens fall back to $JIRA_API_TOKEN and $LINEAR_API_KEY.
+func NewTracker(cfg *Config) (Tracker, error) {
+	switch cfg.Tracker {
+	case TrackerJira:
+		token := cfg.JiraToken
+		if token == "" {
+			token = os.Getenv("JIRA_API_TOKEN")
+		}
+		if token == "" {
+			return nil, fmt.Errorf("jira_token or $JIRA_API_TOKEN is required for the jira tracker")
+		}
+		return NewJiraClient(cfg.JiraURL, cfg.JiraEmail, token), nil
+	case TrackerLinear:
+		key := cfg.LinearAPIKey
+		if key == "" {
+			key = os.Getenv("LINEAR_API_KEY")
+		}
+		if key == "" {
+			return nil, fmt.Errorf("linear_api_key or $LINEAR_API_KEY is required for the linear tracker")
+		}
+		return NewLinearClient(key), nil
+	}
+	return nil, nil
+}
+
+// CachePath is where looked-up issues are kept.
+func CachePath(dataDir string) string {
+	return filepath.Join(dataDir, cacheFile)
+}
+
+func (p *Plugin) Initialize(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("issues", "initialize", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("issues", "parse config", err)
+	}
+
+	log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger)
+	if !ok || log == nil {
+		log = logger.Default()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("issues", "get data dir", err)
+	}
+	cache, err := LoadCache(CachePath(dataDir))
+	if err != nil {
+		return errors.WrapPlugin("issues", "load cache", err)
+	}
+
+	tracker, err := NewTracker(cfg)
+	if err != nil {
+		return errors.WrapPlugin("issues", "create tracker", err)
+	}
+
+	interval := time.Duration(cfg.RefreshIntervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.detector = NewDetector(cfg.Projects)
+	p.cache = cache
+	p.tracker = tracker
+	p.interval = interval
+	p.logger = log
+	return nil
+}
+
+// EnrichEvent sets payload.issue to the first issue key the event mentions,
+// payload.issues to all of them when there are several, and adds the
+// title and URL of issues already looked up. Keys the tracker does not
+// know are dropped.
+func (p *Plugin) EnrichEvent(event *events.Event) {
+	p.mu.RLock()
+	detector, cache, tracker := p.detector, p.cache, p.tracker
+	p.mu.RUnlock()
+	if detector == nil {
+		return
+	}
+	if _, ok := event.Payload["issue"]; ok {
+		return
+	}
+	Enrich(event, detector, cache, tracker != nil)
+}
+
+// Enrich adds the issue fields to event. cache may be nil; lookup says
+// whether unknown keys should be queued for the tracker.
+func Enrich(event *events.Event, detector *Detector, cache *Cache, lookup bool) {
+	var keys []string
+	for _, key := range detector.Detect(event) {
+		if cache != nil {
+			if issue, ok := cache.Get(key); ok && issue.Missing {
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	if event.Payload == nil {
+		event.Payload = make(map[string]interface{})
+	}
+	event.Payload["issue"] = keys[0]
+	if len(keys) > 1 {
+		list := make([]interface{}, len(keys))
+		for i, k := range keys {
+			list[i] = k
+		}
+		event.Payload["issues"] = list
+	}
+
+	if cache == nil {
+		return
+	}
+	issue, ok := cache.Get(keys[0])
+	if !ok {
+		if lookup {
+			for _, k := range keys {
+				cache.Note(k)
+			}
+		}
+		return
+	}
+	if issue.Title != "" {
+		event.Payload["issue_title"] = issue.Title
+	}
+	if issue.URL != "" {
+		event.Payload["issue_url"] = issue.URL
+	}
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	p.mu.RLock()
+	cache, tracker, interval, log := p.cache, p.tracker, p.interval, p.logger
+	p.mu.RUnlock()
+	if cache == nil {
+		return errors.WrapPlugin("issues", "start", fmt.Errorf("plugin not initialized"))
+	}
+
+	if tracker == nil {
+		log.Info("issue detection started (no tracker configured)")
+		<-ctx.Done()
+		return nil
+	}
+
+	log.Info("issue detection started", slog.Duration("refresh_interval", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("issue detection stopped")
+			return nil
+		case <-ticker.C:
+			timer := metrics.StartPluginTimer("issues")
+			n, err := Refresh(ctx, tracker, cache, time.Now())
+			timer.Stop()
+			if err != nil && ctx.Err() == nil {
+				log.Warn("issue lookup failed", slog.String("error", err.Error()))
+			}
+			if n > 0 {
+				log.Debug("looked up issues", slog.Int("count", n))
+			}
+		}
+	}
+}
+
+// Refresh looks up keys that were never fetched or are stale and saves the
+// cache. It stops at the first error other than an unknown key and returns
+// how many issues it updated.
+func Refresh(ctx context.Context, tracker Tracker, cache *Cache, now time.Time) (int, error) {
+	updated := 0
+	var fetchErr error
+	for _, key := range cache.Due(now, maxAge, fetchBatch) {
+		issue, err := tracker.Fetch(ctx, key)
+		if stderrors.Is(err, ErrIssueNotFound) {
+			issue, err = &Issue{Key: key, Missing: true}, nil
+		}
+		if err != nil {
+			fetchErr = err
+			break
+		}
+		issue.Fetched = now
+		cache.Put(issue)
+		updated++
+	}
+	if updated > 0 {
+		if err := cache.Save(); err != nil {
+			return updated, fmt.Errorf("save issue cache: %w", err)
+		}
+	}
+	return updated, fetchErr
+}
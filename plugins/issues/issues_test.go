@@ -0,0 +1,207 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+)
+
+func TestValidateConfig(t *testing.T) {
+	p := &Plugin{}
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr bool
+	}{
+		{"empty", map[string]interface{}{}, false},
+		{"projects", map[string]interface{}{"projects": []interface{}{"PROJ", "eng"}}, false},
+		{"bad project", map[string]interface{}{"projects": []interface{}{"PROJ-1"}}, true},
+		{"jira", map[string]interface{}{"tracker": "jira", "jira_url": "https://x.atlassian.net"}, false},
+		{"jira without url", map[string]interface{}{"tracker": "jira"}, true},
+		{"linear", map[string]interface{}{"tracker": "linear"}, false},
+		{"unknown tracker", map[string]interface{}{"tracker": "trello"}, true},
+		{"short interval", map[string]interface{}{"refresh_interval_seconds": 5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.ValidateConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJiraClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/rest/api/2/issue/PROJ-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"key":"PROJ-1","fields":{"summary":"Fix login","status":{"name":"In Progress"}}}`))
+	}))
+	defer srv.Close()
+
+	c := NewJiraClient(srv.URL+"/", "me@example.com", "tok")
+	issue, err := c.Fetch(context.Background(), "PROJ-1")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if issue.Title != "Fix login" || issue.Status != "In Progress" || issue.URL != srv.URL+"/browse/PROJ-1" {
+		t.Errorf("issue = %+v", issue)
+	}
+
+	if _, err := c.Fetch(context.Background(), "PROJ-2"); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("Fetch unknown key error = %v, want ErrIssueNotFound", err)
+	}
+}
+
+func TestLinearClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["id"] != "ENG-7" {
+			w.Write([]byte(`{"data":{"issue":null},"errors":[{"message":"Entity not found: Issue"}]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"issue":{"identifier":"ENG-7","title":"Cache tokens","url":"https://linear.app/x/issue/ENG-7","state":{"name":"Done"}}}}`))
+	}))
+	defer srv.Close()
+
+	c := NewLinearClient("lin_key")
+	c.URL = srv.URL
+	issue, err := c.Fetch(context.Background(), "ENG-7")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if issue.Title != "Cache tokens" || issue.Status != "Done" {
+		t.Errorf("issue = %+v", issue)
+	}
+
+	if _, err := c.Fetch(context.Background(), "ENG-8"); !errors.Is(err, ErrIssueNotFound) {
+		t.Errorf("Fetch unknown key error = %v, want ErrIssueNotFound", err)
+	}
+}
+
+type fakeTracker map[string]*Issue
+
+func (f fakeTracker) Fetch(ctx context.Context, key string) (*Issue, error) {
+	issue, ok := f[key]
+	if !ok {
+		return nil, ErrIssueNotFound
+	}
+	copy := *issue
+	return &copy, nil
+}
+
+func commit(branch, message string) *events.Event {
+	e := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	e.Branch = branch
+	e.Payload["message"] = message
+	return e
+}
+
+func TestEnrichAndRefresh(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "issues.json")
+	cache, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache: %v", err)
+	}
+	detector := NewDetector(nil)
+	tracker := fakeTracker{"PROJ-1": {Key: "PROJ-1", Title: "Fix login", URL: "https://jira/browse/PROJ-1"}}
+
+	// Before the lookup, events get the key and queue it.
+	e := commit("PROJ-1-login", "PROJ-1: fix, bumps VER-2")
+	Enrich(e, detector, cache, true)
+	if e.Payload["issue"] != "PROJ-1" || e.Payload["issue_title"] != nil {
+		t.Errorf("payload = %v, want issue without title", e.Payload)
+	}
+	if issues, _ := e.Payload["issues"].([]interface{}); len(issues) != 2 {
+		t.Errorf("issues = %v, want both keys", e.Payload["issues"])
+	}
+
+	now := time.Now()
+	n, err := Refresh(context.Background(), tracker, cache, now)
+	if err != nil || n != 2 {
+		t.Fatalf("Refresh() = %d, %v, want 2 lookups", n, err)
+	}
+
+	// After it, titles are added and unknown keys dropped.
+	e = commit("main", "VER-2 and PROJ-1")
+	Enrich(e, detector, cache, true)
+	if e.Payload["issue"] != "PROJ-1" || e.Payload["issue_title"] != "Fix login" || e.Payload["issues"] != nil {
+		t.Errorf("payload = %v, want PROJ-1 with title only", e.Payload)
+	}
+
+	// Nothing is due until the entries go stale, and the cache persists.
+	if due := cache.Due(now, maxAge, 10); len(due) != 0 {
+		t.Errorf("Due() = %v, want none", due)
+	}
+	reloaded, err := LoadCache(path)
+	if err != nil {
+		t.Fatalf("LoadCache: %v", err)
+	}
+	if due := reloaded.Due(now.Add(2*maxAge), maxAge, 10); len(due) != 2 {
+		t.Errorf("Due() after maxAge = %v, want both keys", due)
+	}
+}
+
+func TestEnrichEventKeepsExistingIssue(t *testing.T) {
+	p := &Plugin{detector: NewDetector(nil)}
+	e := commit("PROJ-1", "")
+	e.Payload["issue"] = "OTHER-9"
+	p.EnrichEvent(e)
+	if e.Payload["issue"] != "OTHER-9" {
+		t.Errorf("issue = %v, want the sender's value kept", e.Payload["issue"])
+	}
+
+	// Without Initialize the plugin leaves events alone.
+	e = commit("PROJ-1", "")
+	(&Plugin{}).EnrichEvent(e)
+	if _, ok := e.Payload["issue"]; ok {
+		t.Error("uninitialized plugin set issue")
+	}
+}
+
+func TestGroup(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var evts []*events.Event
+	for i, spec := range []struct{ key, repo string }{
+		{"PROJ-1", "api"}, {"ENG-7", "web"}, {"PROJ-1", "web"}, {"", "api"}, {"PROJ-1", "api"},
+	} {
+		e := commit("main", "")
+		e.Timestamp = base.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)
+		e.Repo = spec.repo
+		if spec.key != "" {
+			e.Payload["issue"] = spec.key
+		}
+		evts = append(evts, e)
+	}
+	evts[2].Payload["issue_title"] = "Fix login"
+
+	got := Group(evts)
+	if len(got) != 2 || got[0].Key != "PROJ-1" || got[1].Key != "ENG-7" {
+		t.Fatalf("Group() = %+v, want PROJ-1 then ENG-7", got)
+	}
+	p := got[0]
+	if p.Events != 3 || p.Title != "Fix login" || len(p.Repos) != 2 || !p.First.Equal(base) || !p.Last.Equal(base.Add(4*time.Hour)) {
+		t.Errorf("PROJ-1 = %+v", p)
+	}
+}
@@ -0,0 +1,177 @@
+package issues
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	TrackerJira   = "jira"
+	TrackerLinear = "linear"
+
+	DefaultLinearURL = "https://api.linear.app/graphql"
+)
+
+// ErrIssueNotFound is returned when the tracker does not know a key, which
+// usually means a false positive such as a version string.
+var ErrIssueNotFound = errors.New("issue not found")
+
+// Issue is what a tracker says about an issue key.
+type Issue struct {
+	Key     string    `json:"key"`
+	Title   string    `json:"title,omitempty"`
+	Status  string    `json:"status,omitempty"`
+	URL     string    `json:"url,omitempty"`
+	Fetched time.Time `json:"fetched"`
+	// Missing is set when the tracker has no such issue.
+	Missing bool `json:"missing,omitempty"`
+}
+
+// Tracker looks issues up in Jira or Linear.
+type Tracker interface {
+	Fetch(ctx context.Context, key string) (*Issue, error)
+}
+
+type JiraClient struct {
+	BaseURL string
+	Email   string
+	Token   string
+	HTTP    *http.Client
+}
+
+// NewJiraClient returns a client for a Jira site such as
+// https://example.atlassian.net. With an email the token is an API token
+// sent with basic auth; without one it is a personal access token.
+func NewJiraClient(baseURL, email, token string) *JiraClient {
+	return &JiraClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Email:   email,
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (c *JiraClient) Fetch(ctx context.Context, key string) (*Issue, error) {
+	endpoint := c.BaseURL + "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,status"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Email != "" {
+		req.SetBasicAuth(c.Email, c.Token)
+	} else if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	var body struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary string `json:"summary"`
+			Status  struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := doJSON(c.HTTP, req, &body); err != nil {
+		return nil, fmt.Errorf("fetch %s from jira: %w", key, err)
+	}
+	return &Issue{
+		Key:    key,
+		Title:  body.Fields.Summary,
+		Status: body.Fields.Status.Name,
+		URL:    c.BaseURL + "/browse/" + key,
+	}, nil
+}
+
+type LinearClient struct {
+	URL    string
+	APIKey string
+	HTTP   *http.Client
+}
+
+func NewLinearClient(apiKey string) *LinearClient {
+	return &LinearClient{
+		URL:    DefaultLinearURL,
+		APIKey: apiKey,
+		HTTP:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+const linearIssueQuery = `query Issue($id: String!) { issue(id: $id) { identifier title url state { name } } }`
+
+func (c *LinearClient) Fetch(ctx context.Context, key string) (*Issue, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"query":     linearIssueQuery,
+		"variables": map[string]string{"id": key},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.APIKey)
+
+	var body struct {
+		Data struct {
+			Issue *struct {
+				Identifier string `json:"identifier"`
+				Title      string `json:"title"`
+				URL        string `json:"url"`
+				State      struct {
+					Name string `json:"name"`
+				} `json:"state"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message    string `json:"message"`
+			Extensions struct {
+				Code string `json:"code"`
+			} `json:"extensions"`
+		} `json:"errors"`
+	}
+	if err := doJSON(c.HTTP, req, &body); err != nil {
+		return nil, fmt.Errorf("fetch %s from linear: %w", key, err)
+	}
+	if body.Data.Issue == nil {
+		for _, e := range body.Errors {
+			if strings.Contains(strings.ToLower(e.Message), "not found") || e.Extensions.Code == "ENTITY_NOT_FOUND" {
+				return nil, fmt.Errorf("fetch %s from linear: %w", key, ErrIssueNotFound)
+			}
+		}
+		if len(body.Errors) > 0 {
+			return nil, fmt.Errorf("fetch %s from linear: %s", key, body.Errors[0].Message)
+		}
+		return nil, fmt.Errorf("fetch %s from linear: %w", key, ErrIssueNotFound)
+	}
+	issue := body.Data.Issue
+	return &Issue{Key: key, Title: issue.Title, Status: issue.State.Name, URL: issue.URL}, nil
+}
+
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrIssueNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
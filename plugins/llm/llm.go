@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"devlog/internal/contextkeys"
 	"devlog/internal/errors"
@@ -17,10 +18,22 @@ type Plugin struct {
 }
 
 type Config struct {
-	Provider string `json:"provider"`
-	APIKey   string `json:"api_key"`
-	BaseURL  string `json:"base_url,omitempty"`
-	Model    string `json:"model,omitempty"`
+	Provider       string           `json:"provider"`
+	APIKey         string           `json:"api_key"`
+	BaseURL        string           `json:"base_url,omitempty"`
+	Model          string           `json:"model,omitempty"`
+	TimeoutSeconds int              `json:"timeout_seconds,omitempty"`
+	ContextTokens  int              `json:"context_tokens,omitempty"`
+	Providers      []ProviderConfig `json:"providers,omitempty"`
+}
+
+type ProviderConfig struct {
+	Provider       string `json:"provider"`
+	APIKey         string `json:"api_key,omitempty"`
+	BaseURL        string `json:"base_url,omitempty"`
+	Model          string `json:"model,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	ContextTokens  int    `json:"context_tokens,omitempty"`
 }
 
 func init() {
@@ -46,7 +59,8 @@ func (p *Plugin) Metadata() plugins.Metadata {
 func (p *Plugin) Install(ctx *install.Context) error {
 	ctx.Log("Installing LLM plugin")
 	ctx.Log("This plugin provides LLM services to other plugins")
-	ctx.Log("Configure your provider (ollama or anthropic) in the plugin configuration")
+	ctx.Log("Configure your provider (ollama, anthropic or openai) in the plugin configuration")
+	ctx.Log("List several under 'providers' to fall back to the next one when a call fails")
 	return nil
 }
 
@@ -69,56 +83,129 @@ func (p *Plugin) ValidateConfig(config interface{}) error {
 		return errors.NewValidation("config", "must be a map")
 	}
 
+	if rawProviders, ok := cfgMap["providers"]; ok {
+		providers, ok := rawProviders.([]interface{})
+		if !ok || len(providers) == 0 {
+			return errors.NewValidation("providers", "must be a non-empty list")
+		}
+		for i, raw := range providers {
+			providerMap, ok := raw.(map[string]interface{})
+			if !ok {
+				return errors.NewValidation(fmt.Sprintf("providers[%d]", i), "must be a map")
+			}
+			if err := validateProvider(fmt.Sprintf("providers[%d].", i), providerMap); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return validateProvider("", cfgMap)
+}
+
+func validateProvider(prefix string, cfgMap map[string]interface{}) error {
 	provider, ok := cfgMap["provider"].(string)
 	if !ok || provider == "" {
-		return errors.NewValidation("provider", "is required")
+		return errors.NewValidation(prefix+"provider", "is required")
 	}
 
-	if provider != "ollama" && provider != "anthropic" {
-		return errors.NewValidation("provider", "must be 'ollama' or 'anthropic'")
+	if !llm.IsValidProvider(provider) {
+		return errors.NewValidation(prefix+"provider", "must be 'ollama', 'anthropic' or 'openai'")
 	}
 
-	if provider == "anthropic" {
+	if provider == "anthropic" || provider == "openai" {
 		apiKey, ok := cfgMap["api_key"].(string)
 		if !ok || apiKey == "" {
-			return errors.NewValidation("api_key", "is required for anthropic provider")
+			return errors.NewValidation(prefix+"api_key", fmt.Sprintf("is required for %s provider", provider))
 		}
 	}
 
 	if provider == "ollama" {
 		baseURL, ok := cfgMap["base_url"].(string)
 		if !ok || baseURL == "" {
-			return errors.NewValidation("base_url", "is required for ollama provider")
+			return errors.NewValidation(prefix+"base_url", "is required for ollama provider")
 		}
 	}
 
-	return nil
-}
+	if val, ok := cfgMap["timeout_seconds"]; ok {
+		var timeout int
+		switch v := val.(type) {
+		case float64:
+			timeout = int(v)
+		case int:
+			timeout = v
+		default:
+			return errors.NewValidation(prefix+"timeout_seconds", "must be a number")
+		}
+		if timeout < 0 {
+			return errors.NewValidation(prefix+"timeout_seconds", "must not be negative")
+		}
+	}
 
-func (p *Plugin) Initialize(ctx context.Context) error {
-	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
-	if !ok || cfgMap == nil {
-		return errors.WrapPlugin("llm", "initialize", fmt.Errorf("plugin config not found in context"))
+	if val, ok := cfgMap["context_tokens"]; ok {
+		var tokens int
+		switch v := val.(type) {
+		case float64:
+			tokens = int(v)
+		case int:
+			tokens = v
+		default:
+			return errors.NewValidation(prefix+"context_tokens", "must be a number")
+		}
+		if tokens != 0 && tokens < 2048 {
+			return errors.NewValidation(prefix+"context_tokens", "must be at least 2048")
+		}
 	}
 
+	return nil
+}
+
+func ClientConfig(cfgMap map[string]interface{}) (llm.Config, error) {
 	cfg := &Config{}
 	cfgBytes, err := json.Marshal(cfgMap)
 	if err != nil {
-		return errors.WrapPlugin("llm", "marshal config", err)
+		return llm.Config{}, fmt.Errorf("marshal config: %w", err)
 	}
 	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
-		return errors.WrapPlugin("llm", "unmarshal config", err)
+		return llm.Config{}, fmt.Errorf("unmarshal config: %w", err)
 	}
 
-	if cfg.Provider == "" {
+	if cfg.Provider == "" && len(cfg.Providers) == 0 {
 		cfg.Provider = "ollama"
 	}
 
 	llmCfg := llm.Config{
-		Provider: llm.ProviderType(cfg.Provider),
-		APIKey:   cfg.APIKey,
-		BaseURL:  cfg.BaseURL,
-		Model:    cfg.Model,
+		Provider:      llm.ProviderType(cfg.Provider),
+		APIKey:        cfg.APIKey,
+		BaseURL:       cfg.BaseURL,
+		Model:         cfg.Model,
+		Timeout:       time.Duration(cfg.TimeoutSeconds) * time.Second,
+		ContextTokens: cfg.ContextTokens,
+	}
+
+	for _, provider := range cfg.Providers {
+		llmCfg.Providers = append(llmCfg.Providers, llm.Config{
+			Provider:      llm.ProviderType(provider.Provider),
+			APIKey:        provider.APIKey,
+			BaseURL:       provider.BaseURL,
+			Model:         provider.Model,
+			Timeout:       time.Duration(provider.TimeoutSeconds) * time.Second,
+			ContextTokens: provider.ContextTokens,
+		})
+	}
+
+	return llmCfg, nil
+}
+
+func (p *Plugin) Initialize(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("llm", "initialize", fmt.Errorf("plugin config not found in context"))
+	}
+
+	llmCfg, err := ClientConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("llm", "parse config", err)
 	}
 
 	client, err := llm.NewClient(llmCfg)
@@ -126,6 +213,7 @@ func (p *Plugin) Initialize(ctx context.Context) error {
 		return errors.WrapPlugin("llm", "create client", err)
 	}
 	p.client = client
+	TrackUsage()
 	return nil
 }
 
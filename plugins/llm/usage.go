@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"context"
+	"sync"
+
+	"devlog/internal/config"
+	"devlog/internal/llm"
+	"devlog/internal/storage"
+)
+
+// usageStore opens the database on the first completion and keeps it open
+// for the life of the process, instead of opening and migrating it again
+// for every completion. An error is kept too, so a process that cannot
+// record usage does not retry on each call.
+var usageStore = sync.OnceValues(func() (*storage.Storage, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return nil, err
+	}
+	return storage.OpenSQLite(dataDir)
+})
+
+// TrackUsage stores the tokens and estimated cost of every completion made by
+// this process so `devlog llm usage` can report spend across the daemon and
+// CLI commands. Failing to record usage never fails the completion.
+func TrackUsage() {
+	llm.SetUsageRecorder(recordUsage)
+}
+
+func recordUsage(u llm.Usage) {
+	store, err := usageStore()
+	if err != nil {
+		return
+	}
+	_ = store.InsertLLMUsageContext(context.Background(), &storage.LLMUsage{
+		Timestamp:    u.Time,
+		Caller:       u.Caller,
+		Provider:     u.Provider,
+		Model:        u.Model,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		CostUSD:      u.CostUSD,
+	})
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/llm"
+	"devlog/internal/storage"
+)
+
+func TestRecordUsageKeepsStoreOpen(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	configDir, err := config.ConfigDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataDir, err := config.DataDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{configDir, dataDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.DefaultConfig().Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.InitDB(filepath.Join(dataDir, "events.db")); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	recordUsage(llm.Usage{Time: now, Caller: "summarizer", Provider: "anthropic", InputTokens: 100, OutputTokens: 20})
+	first, err := usageStore()
+	if err != nil {
+		t.Fatalf("usageStore() error: %v", err)
+	}
+	recordUsage(llm.Usage{Time: now, Caller: "summarizer", Provider: "anthropic", InputTokens: 50, OutputTokens: 10})
+	if second, _ := usageStore(); second != first {
+		t.Error("usageStore() opened the database again for the second completion")
+	}
+
+	days, err := first.LLMUsageByDayContext(context.Background(), now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls, input := 0, 0
+	for _, day := range days {
+		calls += day.Completions
+		input += day.InputTokens
+	}
+	if calls != 2 || input != 150 {
+		t.Errorf("recorded %d calls with %d input tokens, want 2 and 150", calls, input)
+	}
+}
@@ -0,0 +1,91 @@
+package obsidian
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+var heatLevels = []string{"·", "░", "▒", "▓", "█"}
+
+type heatmapFrontMatter struct {
+	Tags    []string `yaml:"tags"`
+	Updated string   `yaml:"updated"`
+	Weeks   int      `yaml:"weeks"`
+}
+
+// renderHeatmap draws the last heatmapWeeks weeks as a table, newest week
+// first, with each active day linking to its note. Shades are relative to
+// the busiest day in range.
+func (e *Exporter) renderHeatmap(days []storage.SummaryDay) string {
+	today := e.now().In(e.loc)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, e.loc)
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	first := weekStart.AddDate(0, 0, -7*(e.heatmapWeeks-1))
+
+	counts := make(map[string]int)
+	maxCount, activeDays, total := 0, 0, 0
+	var busiest time.Time
+	for _, day := range days {
+		if day.Day.Before(first) || day.Day.After(today) {
+			continue
+		}
+		counts[dayKey(day.Day)] = day.EventCount
+		activeDays++
+		total += day.EventCount
+		if day.EventCount > maxCount {
+			maxCount = day.EventCount
+			busiest = day.Day
+		}
+	}
+
+	var b strings.Builder
+	writeFrontMatter(&b, heatmapFrontMatter{
+		Tags:    e.noteTags("heatmap"),
+		Updated: dayKey(today),
+		Weeks:   e.heatmapWeeks,
+	})
+
+	b.WriteString("# Activity Heatmap\n\n")
+	fmt.Fprintf(&b, "%d active days and %d events in the last %d weeks.", activeDays, total, e.heatmapWeeks)
+	if maxCount > 0 {
+		fmt.Fprintf(&b, " Busiest day: %s (%d events).", e.dayLink(busiest), maxCount)
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString("| Week | Mon | Tue | Wed | Thu | Fri | Sat | Sun |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|\n")
+	for week := weekStart; !week.Before(first); week = week.AddDate(0, 0, -7) {
+		fmt.Fprintf(&b, "| %s |", week.Format("Jan 2"))
+		for i := 0; i < 7; i++ {
+			day := week.AddDate(0, 0, i)
+			cell := ""
+			if !day.After(today) {
+				cell = heatLevels[0]
+				if count, ok := counts[dayKey(day)]; ok {
+					cell = tableLink(e.noteLink(dailyFolder+"/"+dayKey(day), heatLevel(count, maxCount)))
+				}
+			}
+			fmt.Fprintf(&b, " %s |", cell)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "\nLess %s More\n", strings.Join(heatLevels, " "))
+	return b.String()
+}
+
+// heatLevel picks a shade for count; any day with summaries gets at least
+// the lightest one so it stays distinguishable from an empty day.
+func heatLevel(count, maxCount int) string {
+	if maxCount <= 0 {
+		return heatLevels[1]
+	}
+	level := 1 + count*(len(heatLevels)-2)/maxCount
+	if level >= len(heatLevels) {
+		level = len(heatLevels) - 1
+	}
+	return heatLevels[level]
+}
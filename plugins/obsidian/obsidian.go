@@ -0,0 +1,249 @@
+package obsidian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/state"
+	"devlog/internal/storage"
+)
+
+const stateModule = "obsidian"
+
+type Plugin struct {
+	exporter *Exporter
+	storage  *storage.Storage
+	stateMgr *state.Manager
+	interval time.Duration
+	logger   *logger.Logger
+}
+
+type Config struct {
+	VaultPath       string   `json:"vault_path"`
+	Folder          string   `json:"folder,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	HeatmapWeeks    int      `json:"heatmap_weeks,omitempty"`
+	IntervalSeconds int      `json:"interval_seconds,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "obsidian"
+}
+
+func (p *Plugin) Description() string {
+	return "Writes summaries into an Obsidian vault as linked daily notes"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "obsidian",
+		Description:  "Writes summaries into an Obsidian vault as linked daily notes",
+		Dependencies: []string{"summarizer"},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Obsidian plugin")
+	ctx.Log("Set vault_path to your vault; notes are written under the folder setting (default DevLog)")
+	ctx.Log("Existing summaries are exported on the first run, new ones as the summarizer produces them")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Obsidian plugin")
+	ctx.Log("Notes already written to the vault are left in place")
+
+	stateMgr, err := state.NewManager(ctx.DataDir)
+	if err == nil {
+		stateMgr.DeleteModule(stateModule)
+	}
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		Folder:          "DevLog",
+		Tags:            []string{"devlog"},
+		HeatmapWeeks:    26,
+		IntervalSeconds: 300,
+	}
+}
+
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9_/-]+$`)
+
+func (p *Plugin) ValidateConfig(cfgValue interface{}) error {
+	cfgMap, ok := cfgValue.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	vault, ok := cfgMap["vault_path"].(string)
+	if !ok || vault == "" {
+		return errors.NewValidation("vault_path", "is required")
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+
+	if filepath.IsAbs(cfg.Folder) || strings.HasPrefix(filepath.Clean(cfg.Folder), "..") {
+		return errors.NewValidation("folder", "must be a relative path inside the vault")
+	}
+	for _, tag := range cfg.Tags {
+		if !tagPattern.MatchString(tag) {
+			return errors.NewValidation("tags", fmt.Sprintf("%q may only contain letters, digits, _, - and /", tag))
+		}
+	}
+	if cfg.HeatmapWeeks != 0 && (cfg.HeatmapWeeks < 1 || cfg.HeatmapWeeks > 104) {
+		return errors.NewValidation("heatmap_weeks", "must be between 1 and 104")
+	}
+	if cfg.IntervalSeconds != 0 && (cfg.IntervalSeconds < 30 || cfg.IntervalSeconds > 86400) {
+		return errors.NewValidation("interval_seconds", "must be between 30 and 86400")
+	}
+	return nil
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("obsidian", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("obsidian", "parse config", err)
+	}
+	if cfg.VaultPath == "" {
+		return errors.WrapPlugin("obsidian", "start", fmt.Errorf("vault_path is not set"))
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("obsidian", "get data dir", err)
+	}
+
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("obsidian", "create state manager", err)
+	}
+	p.stateMgr = stateMgr
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("obsidian", "open storage", err)
+	}
+	p.storage = store
+
+	p.exporter = NewExporter(store, cfg)
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	if p.interval <= 0 {
+		p.interval = 5 * time.Minute
+	}
+
+	p.run(ctx)
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context) {
+	p.logger.Info("obsidian export started",
+		slog.String("vault", p.exporter.vault),
+		slog.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	summaries, unsubscribe := plugins.Subscribe(plugins.TopicSummaryGenerated)
+	defer unsubscribe()
+
+	p.export(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("obsidian export stopped")
+			p.storage.Close()
+			return
+		case <-summaries:
+			p.export(ctx)
+		case <-ticker.C:
+			p.export(ctx)
+		}
+	}
+}
+
+// export writes notes for summaries added since the last run. The cursor is
+// kept per vault so pointing the plugin at a new vault exports everything
+// again.
+func (p *Plugin) export(ctx context.Context) {
+	timer := metrics.StartPluginTimer("obsidian")
+	defer timer.Stop()
+
+	var cursor int64
+	if vault, _ := p.stateMgr.GetString(stateModule, "vault"); vault == p.exporter.root() {
+		if v, ok := p.stateMgr.Get(stateModule, "last_summary_id"); ok {
+			if f, ok := v.(float64); ok {
+				cursor = int64(f)
+			}
+		}
+	}
+
+	result, err := p.exporter.Export(ctx, cursor)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error("obsidian export failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if result.LastSummaryID == cursor {
+		return
+	}
+
+	if err := p.stateMgr.Set(stateModule, "vault", p.exporter.root()); err != nil {
+		p.logger.Warn("failed to save obsidian state", slog.String("error", err.Error()))
+	}
+	if err := p.stateMgr.Set(stateModule, "last_summary_id", result.LastSummaryID); err != nil {
+		p.logger.Warn("failed to save obsidian state", slog.String("error", err.Error()))
+	}
+	if result.Notes > 0 {
+		p.logger.Info("obsidian notes updated",
+			slog.Int("days", result.Days),
+			slog.Int("notes", result.Notes))
+	}
+}
@@ -0,0 +1,148 @@
+package obsidian
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/storage"
+	"devlog/internal/testutil"
+)
+
+func newTestExporter(t *testing.T, store *storage.Storage) *Exporter {
+	t.Helper()
+	e := NewExporter(store, &Config{VaultPath: t.TempDir(), HeatmapWeeks: 2})
+	e.loc = time.UTC
+	e.now = func() time.Time { return time.Date(2025, 5, 21, 18, 0, 0, 0, time.UTC) }
+	return e
+}
+
+func insertSummary(t *testing.T, store *storage.Storage, start time.Time, text string, events int, repos ...string) {
+	t.Helper()
+	err := store.InsertSummaryContext(context.Background(), &storage.Summary{
+		PeriodStart: start,
+		PeriodEnd:   start.Add(30 * time.Minute),
+		Repos:       repos,
+		Text:        text,
+		EventCount:  events,
+	})
+	if err != nil {
+		t.Fatalf("InsertSummaryContext() error: %v", err)
+	}
+}
+
+func readNote(t *testing.T, e *Exporter, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(e.notePath(name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	return string(data)
+}
+
+func TestExport(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	defer store.Close()
+	e := newTestExporter(t, store)
+	ctx := context.Background()
+
+	mon := time.Date(2025, 5, 19, 9, 0, 0, 0, time.UTC)
+	insertSummary(t, store, mon, "Fixed the login bug", 12, "/src/api")
+	insertSummary(t, store, mon.Add(time.Hour), "Reviewed PRs", 3, "web")
+
+	result, err := e.Export(ctx, 0)
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if result.Days != 1 || result.Notes != 4 {
+		t.Errorf("result = %+v, want 1 day and 4 notes (daily, 2 repos, heatmap)", result)
+	}
+
+	daily := readNote(t, e, "Daily/2025-05-19")
+	for _, want := range []string{
+		"date: \"2025-05-19\"",
+		"- devlog/daily",
+		"- repo/api",
+		"events: 15",
+		"'[[DevLog/Repos/api|api]]'",
+		"## 09:00–09:30\n\nFixed the login bug",
+		"## 10:00–10:30\n\nReviewed PRs",
+	} {
+		if !strings.Contains(daily, want) {
+			t.Errorf("daily note missing %q:\n%s", want, daily)
+		}
+	}
+	if strings.Contains(daily, "previous:") {
+		t.Error("first day should not link to a previous day")
+	}
+
+	// A new day links back, and the earlier note gains a next link.
+	wed := time.Date(2025, 5, 21, 14, 0, 0, 0, time.UTC)
+	insertSummary(t, store, wed, "Shipped the release", 40, "api")
+	result, err = e.Export(ctx, result.LastSummaryID)
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if result.Days != 1 {
+		t.Errorf("second export touched %d days, want 1", result.Days)
+	}
+
+	if daily := readNote(t, e, "Daily/2025-05-19"); !strings.Contains(daily, "next: '[[DevLog/Daily/2025-05-21|2025-05-21]]'") {
+		t.Errorf("earlier note missing next link:\n%s", daily)
+	}
+	if daily := readNote(t, e, "Daily/2025-05-21"); !strings.Contains(daily, "← [[DevLog/Daily/2025-05-19|2025-05-19]]") {
+		t.Errorf("new note missing previous link:\n%s", daily)
+	}
+
+	repo := readNote(t, e, "Repos/api")
+	if !strings.Contains(repo, "days: 2") || strings.Index(repo, "2025-05-21") > strings.Index(repo, "| [[DevLog/Daily/2025-05-19") {
+		t.Errorf("repo page should list both days, newest first:\n%s", repo)
+	}
+
+	heatmap := readNote(t, e, heatmapNote)
+	if !strings.Contains(heatmap, `[[DevLog/Daily/2025-05-21\|█]]`) {
+		t.Errorf("heatmap should shade the busiest day darkest:\n%s", heatmap)
+	}
+	if !strings.Contains(heatmap, "| May 19 | [[DevLog/Daily/2025-05-19\\|▒]] | · | [[") {
+		t.Errorf("heatmap week row unexpected:\n%s", heatmap)
+	}
+
+	result, err = e.Export(ctx, result.LastSummaryID)
+	if err != nil {
+		t.Fatalf("Export() error: %v", err)
+	}
+	if result.Notes != 0 {
+		t.Errorf("export with no new summaries wrote %d notes", result.Notes)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	p := &Plugin{}
+	valid := map[string]interface{}{"vault_path": "~/notes", "tags": []interface{}{"work/devlog"}}
+	if err := p.ValidateConfig(valid); err != nil {
+		t.Errorf("ValidateConfig() error: %v", err)
+	}
+
+	for _, cfg := range []map[string]interface{}{
+		{},
+		{"vault_path": "~/notes", "folder": "../outside"},
+		{"vault_path": "~/notes", "tags": []interface{}{"has space"}},
+		{"vault_path": "~/notes", "heatmap_weeks": 500},
+	} {
+		if err := p.ValidateConfig(cfg); err == nil {
+			t.Errorf("ValidateConfig(%v) should fail", cfg)
+		}
+	}
+}
+
+func TestFileSafe(t *testing.T) {
+	if got := fileSafe("owner/repo#1"); got != "owner-repo-1" {
+		t.Errorf("fileSafe() = %q", got)
+	}
+	if got := filepath.Base(NewExporter(nil, &Config{VaultPath: "/v"}).repoPath("a/b")); got != "a-b.md" {
+		t.Errorf("repoPath() = %q", got)
+	}
+}
@@ -0,0 +1,379 @@
+package obsidian
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"devlog/internal/config"
+	"devlog/internal/storage"
+)
+
+const (
+	dailyFolder  = "Daily"
+	reposFolder  = "Repos"
+	heatmapNote  = "Activity Heatmap"
+	summaryBatch = 500
+)
+
+// Exporter renders summaries into a vault. Everything under the configured
+// folder is generated and rewritten on each export, so hand edits belong in
+// other notes that link here.
+type Exporter struct {
+	store        *storage.Storage
+	vault        string
+	folder       string
+	tags         []string
+	heatmapWeeks int
+	loc          *time.Location
+	now          func() time.Time
+}
+
+type ExportResult struct {
+	LastSummaryID int64
+	Days          int
+	Notes         int
+}
+
+func NewExporter(store *storage.Storage, cfg *Config) *Exporter {
+	folder := cfg.Folder
+	if folder == "" {
+		folder = "DevLog"
+	}
+	tags := cfg.Tags
+	if len(tags) == 0 {
+		tags = []string{"devlog"}
+	}
+	weeks := cfg.HeatmapWeeks
+	if weeks <= 0 {
+		weeks = 26
+	}
+	return &Exporter{
+		store:        store,
+		vault:        config.ExpandHome(cfg.VaultPath),
+		folder:       filepath.ToSlash(filepath.Clean(folder)),
+		tags:         tags,
+		heatmapWeeks: weeks,
+		loc:          time.Local,
+		now:          time.Now,
+	}
+}
+
+func (e *Exporter) root() string {
+	return filepath.Join(e.vault, filepath.FromSlash(e.folder))
+}
+
+// Export writes the daily notes for every day with a summary newer than
+// afterID, plus their neighbours (for the previous/next links), the pages
+// of the repos those days touched and the heatmap.
+func (e *Exporter) Export(ctx context.Context, afterID int64) (*ExportResult, error) {
+	result := &ExportResult{LastSummaryID: afterID}
+
+	touched := make(map[string]bool)
+	for {
+		batch, err := e.store.SummariesAfterIDContext(ctx, result.LastSummaryID, summaryBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range batch {
+			touched[dayKey(s.PeriodStart.In(e.loc))] = true
+			result.LastSummaryID = s.ID
+		}
+		if len(batch) < summaryBatch {
+			break
+		}
+	}
+	if len(touched) == 0 {
+		return result, nil
+	}
+
+	days, err := e.store.SummaryDaysContext(ctx, e.loc)
+	if err != nil {
+		return nil, err
+	}
+
+	render := make(map[int]bool)
+	touchedRepos := make(map[string]bool)
+	for i, day := range days {
+		if !touched[dayKey(day.Day)] {
+			continue
+		}
+		result.Days++
+		for j := i - 1; j <= i+1; j++ {
+			if j >= 0 && j < len(days) {
+				render[j] = true
+			}
+		}
+		for _, repo := range repoNames(day.Repos) {
+			touchedRepos[repo] = true
+		}
+	}
+
+	for i := range days {
+		if !render[i] {
+			continue
+		}
+		var prev, next *storage.SummaryDay
+		if i > 0 {
+			prev = &days[i-1]
+		}
+		if i+1 < len(days) {
+			next = &days[i+1]
+		}
+		changed, err := e.writeDailyNote(ctx, days[i], prev, next)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			result.Notes++
+		}
+	}
+
+	for repo := range touchedRepos {
+		changed, err := e.writeFile(e.repoPath(repo), e.renderRepoPage(repo, days))
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			result.Notes++
+		}
+	}
+
+	changed, err := e.writeFile(e.notePath(heatmapNote), e.renderHeatmap(days))
+	if err != nil {
+		return nil, err
+	}
+	if changed {
+		result.Notes++
+	}
+
+	return result, nil
+}
+
+type dailyFrontMatter struct {
+	Date     string   `yaml:"date"`
+	Tags     []string `yaml:"tags"`
+	Repos    []string `yaml:"repos,omitempty"`
+	Events   int      `yaml:"events"`
+	Previous string   `yaml:"previous,omitempty"`
+	Next     string   `yaml:"next,omitempty"`
+}
+
+func (e *Exporter) writeDailyNote(ctx context.Context, day storage.SummaryDay, prev, next *storage.SummaryDay) (bool, error) {
+	summaries, err := e.store.QuerySummariesContext(ctx, day.Day, day.Day.AddDate(0, 0, 1))
+	if err != nil {
+		return false, err
+	}
+
+	repos := repoNames(day.Repos)
+	fm := dailyFrontMatter{
+		Date:   dayKey(day.Day),
+		Tags:   e.noteTags("daily"),
+		Events: day.EventCount,
+	}
+	var repoLinks []string
+	for _, repo := range repos {
+		link := e.repoLink(repo)
+		fm.Repos = append(fm.Repos, link)
+		repoLinks = append(repoLinks, link)
+		fm.Tags = append(fm.Tags, "repo/"+tagSafe(repo))
+	}
+	if prev != nil {
+		fm.Previous = e.dayLink(prev.Day)
+	}
+	if next != nil {
+		fm.Next = e.dayLink(next.Day)
+	}
+
+	var b strings.Builder
+	if err := writeFrontMatter(&b, fm); err != nil {
+		return false, err
+	}
+	fmt.Fprintf(&b, "# %s\n\n", day.Day.Format("Monday, January 2, 2006"))
+
+	var nav []string
+	if prev != nil {
+		nav = append(nav, "← "+fm.Previous)
+	}
+	if next != nil {
+		nav = append(nav, fm.Next+" →")
+	}
+	if len(nav) > 0 {
+		fmt.Fprintf(&b, "%s\n\n", strings.Join(nav, " · "))
+	}
+	if len(repoLinks) > 0 {
+		fmt.Fprintf(&b, "**Repos:** %s\n\n", strings.Join(repoLinks, ", "))
+	}
+
+	for _, s := range summaries {
+		start := s.PeriodStart.In(e.loc)
+		end := s.PeriodEnd.In(e.loc)
+		fmt.Fprintf(&b, "## %s–%s\n\n%s\n\n", start.Format("15:04"), end.Format("15:04"), strings.TrimSpace(s.Text))
+	}
+
+	return e.writeFile(e.dayPath(day.Day), strings.TrimRight(b.String(), "\n")+"\n")
+}
+
+type repoFrontMatter struct {
+	Repo      string   `yaml:"repo"`
+	Tags      []string `yaml:"tags"`
+	FirstSeen string   `yaml:"first_seen"`
+	LastSeen  string   `yaml:"last_seen"`
+	Days      int      `yaml:"days"`
+}
+
+func (e *Exporter) renderRepoPage(repo string, days []storage.SummaryDay) string {
+	var active []storage.SummaryDay
+	for _, day := range days {
+		for _, name := range repoNames(day.Repos) {
+			if name == repo {
+				active = append(active, day)
+				break
+			}
+		}
+	}
+
+	var b strings.Builder
+	fm := repoFrontMatter{
+		Repo: repo,
+		Tags: append(e.noteTags("repo"), "repo/"+tagSafe(repo)),
+		Days: len(active),
+	}
+	if len(active) > 0 {
+		fm.FirstSeen = dayKey(active[0].Day)
+		fm.LastSeen = dayKey(active[len(active)-1].Day)
+	}
+	writeFrontMatter(&b, fm)
+
+	fmt.Fprintf(&b, "# %s\n\n", repo)
+	fmt.Fprintf(&b, "Summarized activity on %d days. See also %s.\n\n", len(active), e.noteLink(heatmapNote, "activity heatmap"))
+	b.WriteString("| Day | Events (all repos) |\n|---|---|\n")
+	for i := len(active) - 1; i >= 0; i-- {
+		fmt.Fprintf(&b, "| %s | %d |\n", tableLink(e.dayLink(active[i].Day)), active[i].EventCount)
+	}
+	return b.String()
+}
+
+func writeFrontMatter(b *strings.Builder, v interface{}) error {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode front matter: %w", err)
+	}
+	b.WriteString("---\n")
+	b.Write(buf.Bytes())
+	b.WriteString("---\n\n")
+	return nil
+}
+
+func (e *Exporter) noteTags(kind string) []string {
+	tags := append([]string(nil), e.tags...)
+	return append(tags, e.tags[0]+"/"+kind)
+}
+
+func (e *Exporter) dayPath(day time.Time) string {
+	return e.notePath(path.Join(dailyFolder, dayKey(day)))
+}
+
+func (e *Exporter) repoPath(repo string) string {
+	return e.notePath(path.Join(reposFolder, fileSafe(repo)))
+}
+
+func (e *Exporter) notePath(name string) string {
+	return filepath.Join(e.root(), filepath.FromSlash(name)+".md")
+}
+
+// Links use the full vault path so notes with the same name elsewhere in
+// the vault never capture them.
+func (e *Exporter) dayLink(day time.Time) string {
+	return e.noteLink(path.Join(dailyFolder, dayKey(day)), dayKey(day))
+}
+
+func (e *Exporter) repoLink(repo string) string {
+	return e.noteLink(path.Join(reposFolder, fileSafe(repo)), repo)
+}
+
+func (e *Exporter) noteLink(name, alias string) string {
+	return fmt.Sprintf("[[%s|%s]]", path.Join(e.folder, name), alias)
+}
+
+// tableLink escapes the alias separator, which Obsidian otherwise reads as
+// a column break inside tables.
+func tableLink(link string) string {
+	return strings.Replace(link, "|", `\|`, 1)
+}
+
+// writeFile replaces path with content unless it already matches, so
+// Obsidian and sync tools only see notes that really changed.
+func (e *Exporter) writeFile(target, content string) (bool, error) {
+	if existing, err := os.ReadFile(target); err == nil && string(existing) == content {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return false, fmt.Errorf("create note directory: %w", err)
+	}
+	tmp := target + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("write note: %w", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("write note: %w", err)
+	}
+	return true, nil
+}
+
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// repoNames turns stored repos into page names, using the directory name
+// for repos recorded as absolute paths.
+func repoNames(repos []string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, repo := range repos {
+		if filepath.IsAbs(repo) {
+			repo = filepath.Base(repo)
+		}
+		if repo == "" || seen[repo] {
+			continue
+		}
+		seen[repo] = true
+		names = append(names, repo)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var unsafeFileChars = strings.NewReplacer(
+	"/", "-", `\`, "-", ":", "-", "*", "-", "?", "-", `"`, "-",
+	"<", "-", ">", "-", "|", "-", "#", "-", "^", "-", "[", "-", "]", "-",
+)
+
+func fileSafe(name string) string {
+	return unsafeFileChars.Replace(name)
+}
+
+func tagSafe(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
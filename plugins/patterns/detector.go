@@ -0,0 +1,259 @@
+package patterns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"devlog/internal/events"
+)
+
+// PatternRepeatedFailure is the pattern of insights about a command that
+// kept failing.
+const PatternRepeatedFailure = "repeated_failure"
+
+// payloadSchema is checked by the daemon when insights are ingested.
+var payloadSchema = events.PayloadSchema{
+	Source: string(events.SourcePatterns),
+	Types:  []events.EventType{events.TypeInsight},
+	Fields: map[string]events.PayloadField{
+		"message":       {Kind: events.KindString, Required: true},
+		"pattern":       {Kind: events.KindString, Required: true},
+		"command":       {Kind: events.KindString},
+		"failures":      {Kind: events.KindNumber},
+		"first_failure": {Kind: events.KindString},
+		"last_failure":  {Kind: events.KindString},
+		"resolved":      {Kind: events.KindBool},
+		"origin":        {Kind: events.KindString},
+		"exit_code":     {Kind: events.KindNumber},
+		"workdir":       {Kind: events.KindString},
+		"output":        {Kind: events.KindString},
+	},
+}
+
+// failureKey tells apart the same command run from different sources or
+// repositories.
+type failureKey struct {
+	source  string
+	repo    string
+	command string
+}
+
+// streak is a run of failures of one command, each within the window of
+// the one before it.
+type streak struct {
+	count    int
+	first    time.Time
+	last     time.Time
+	exitCode int
+	branch   string
+	workdir  string
+	output   string
+	resolved bool
+}
+
+// Insight is a pattern found in the events, ready to be stored as an event.
+type Insight struct {
+	Source   string
+	Repo     string
+	Branch   string
+	Command  string
+	Workdir  string
+	Output   string
+	ExitCode int
+	Failures int
+	First    time.Time
+	Last     time.Time
+	Resolved bool
+}
+
+// Message states the insight in a sentence, such as "go test ./... failed
+// 7 times in 40 min".
+func (i Insight) Message() string {
+	msg := fmt.Sprintf("%s failed %d times in %s", i.Command, i.Failures, formatSpan(i.Last.Sub(i.First)))
+	if i.Resolved {
+		msg += " before passing"
+	}
+	return msg
+}
+
+func formatSpan(d time.Duration) string {
+	if d < time.Minute {
+		return "under a minute"
+	}
+	if d < 2*time.Hour {
+		return fmt.Sprintf("%d min", int(d.Round(time.Minute).Minutes()))
+	}
+	return fmt.Sprintf("%.1f hours", d.Hours())
+}
+
+// Event returns the insight as a patterns/insight event, timed at the last
+// failure so summaries place it with the work it describes.
+func (i Insight) Event() *events.Event {
+	event := events.NewEvent(string(events.SourcePatterns), string(events.TypeInsight))
+	event.Timestamp = i.Last.UTC().Format(time.RFC3339)
+	event.Repo = i.Repo
+	event.Branch = i.Branch
+	event.Severity = string(events.SeverityWarning)
+
+	event.Payload["message"] = i.Message()
+	event.Payload["pattern"] = PatternRepeatedFailure
+	event.Payload["command"] = i.Command
+	event.Payload["failures"] = i.Failures
+	event.Payload["first_failure"] = i.First.UTC().Format(time.RFC3339)
+	event.Payload["last_failure"] = i.Last.UTC().Format(time.RFC3339)
+	event.Payload["resolved"] = i.Resolved
+	event.Payload["origin"] = i.Source
+	event.Payload["exit_code"] = i.ExitCode
+	if i.Workdir != "" {
+		event.Payload["workdir"] = i.Workdir
+	}
+	if i.Output != "" {
+		event.Payload["output"] = i.Output
+	}
+	return event
+}
+
+// Detector finds commands and test runs that fail again and again. A
+// streak ends when the command passes or has not failed for the settle
+// time, and is reported if it reached the minimum number of failures.
+type Detector struct {
+	window      time.Duration
+	settle      time.Duration
+	minFailures int
+
+	mu      sync.Mutex
+	streaks map[failureKey]*streak
+	// ended holds streaks ended by a pass, until Due reports them.
+	ended []Insight
+}
+
+func NewDetector(window, settle time.Duration, minFailures int) *Detector {
+	return &Detector{
+		window:      window,
+		settle:      settle,
+		minFailures: minFailures,
+		streaks:     make(map[failureKey]*streak),
+	}
+}
+
+// Observe records a command or test run event. Events without a command
+// and exit code, insights included, are ignored.
+func (d *Detector) Observe(event *events.Event) {
+	command, exitCode, ok := commandResult(event)
+	if !ok {
+		return
+	}
+	at, err := time.Parse(time.RFC3339, event.Timestamp)
+	if err != nil {
+		at = time.Now()
+	}
+	key := failureKey{source: event.Source, repo: event.Repo, command: command}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	s := d.streaks[key]
+	if exitCode == 0 {
+		if s != nil {
+			delete(d.streaks, key)
+			if s.count >= d.minFailures {
+				s.resolved = true
+				d.ended = append(d.ended, s.insight(key))
+			}
+		}
+		return
+	}
+
+	if s == nil || at.Sub(s.last) > d.window {
+		s = &streak{first: at, last: at}
+		d.streaks[key] = s
+	}
+	s.count++
+	if at.After(s.last) {
+		s.last = at
+	}
+	s.exitCode = exitCode
+	s.branch = event.Branch
+	if workdir, ok := event.Payload["workdir"].(string); ok {
+		s.workdir = workdir
+	}
+	if output, ok := event.Payload["output"].(string); ok && output != "" {
+		s.output = output
+	}
+}
+
+// Due removes the streaks that have ended by now and returns the ones
+// long enough to report, oldest first.
+func (d *Detector) Due(now time.Time) []Insight {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	insights := d.ended
+	d.ended = nil
+	for key, s := range d.streaks {
+		if now.Sub(s.last) < d.settle {
+			continue
+		}
+		delete(d.streaks, key)
+		if s.count >= d.minFailures {
+			insights = append(insights, s.insight(key))
+		}
+	}
+	sort.Slice(insights, func(i, j int) bool {
+		return insights[i].Last.Before(insights[j].Last)
+	})
+	return insights
+}
+
+func (s *streak) insight(key failureKey) Insight {
+	return Insight{
+		Source:   key.source,
+		Repo:     key.repo,
+		Branch:   s.branch,
+		Command:  key.command,
+		Workdir:  s.workdir,
+		Output:   s.output,
+		ExitCode: s.exitCode,
+		Failures: s.count,
+		First:    s.first,
+		Last:     s.last,
+		Resolved: s.resolved,
+	}
+}
+
+// commandResult returns the command an event ran, with whitespace
+// collapsed, and its exit code. Test runs without a command line fall back
+// to the runner.
+func commandResult(event *events.Event) (string, int, bool) {
+	if event.Source == string(events.SourcePatterns) {
+		return "", 0, false
+	}
+	exitCode, ok := number(event.Payload["exit_code"])
+	if !ok {
+		return "", 0, false
+	}
+	command, _ := event.Payload["command"].(string)
+	if command == "" {
+		command, _ = event.Payload["runner"].(string)
+	}
+	command = strings.Join(strings.Fields(command), " ")
+	if command == "" {
+		return "", 0, false
+	}
+	return command, exitCode, true
+}
+
+func number(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
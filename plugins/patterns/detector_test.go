@@ -0,0 +1,188 @@
+package patterns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/logger"
+)
+
+var base = time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC)
+
+func commandEvent(command string, exitCode int, at time.Time) *events.Event {
+	event := events.NewEvent(string(events.SourceShell), string(events.TypeCommand))
+	event.Timestamp = at.Format(time.RFC3339)
+	event.Repo = "devlog"
+	event.Payload["command"] = command
+	// Events reach the detector decoded from JSON, so numbers are floats.
+	event.Payload["exit_code"] = float64(exitCode)
+	return event
+}
+
+func TestDetector_ReportsStreakAfterSettling(t *testing.T) {
+	d := NewDetector(time.Hour, 10*time.Minute, 3)
+
+	for _, minutes := range []int{0, 5, 12, 30, 40} {
+		d.Observe(commandEvent("go test  ./internal/storage", 1, base.Add(time.Duration(minutes)*time.Minute)))
+	}
+	d.Observe(commandEvent("make lint", 2, base))
+
+	if got := d.Due(base.Add(45 * time.Minute)); len(got) != 0 {
+		t.Fatalf("Due() before the streak settled = %+v", got)
+	}
+
+	got := d.Due(base.Add(50 * time.Minute))
+	if len(got) != 1 {
+		t.Fatalf("Due() returned %d insights, want 1", len(got))
+	}
+	if msg := got[0].Message(); msg != "go test ./internal/storage failed 5 times in 40 min" {
+		t.Errorf("Message() = %q", msg)
+	}
+	if len(d.streaks) != 0 {
+		t.Errorf("streaks left after Due() = %d, want 0 (short ones are dropped)", len(d.streaks))
+	}
+}
+
+func TestDetector_PassEndsStreak(t *testing.T) {
+	d := NewDetector(time.Hour, 10*time.Minute, 3)
+
+	for i := 0; i < 3; i++ {
+		d.Observe(commandEvent("npm test", 1, base.Add(time.Duration(i)*time.Minute)))
+	}
+	d.Observe(commandEvent("npm test", 0, base.Add(4*time.Minute)))
+	d.Observe(commandEvent("npm test", 1, base.Add(6*time.Minute)))
+
+	got := d.Due(base.Add(7 * time.Minute))
+	if len(got) != 1 || !got[0].Resolved || got[0].Failures != 3 {
+		t.Fatalf("Due() = %+v, want one resolved streak of 3", got)
+	}
+	if msg := got[0].Message(); msg != "npm test failed 3 times in 2 min before passing" {
+		t.Errorf("Message() = %q", msg)
+	}
+
+	// The failure after the pass starts a new streak.
+	if s := d.streaks[failureKey{source: "shell", repo: "devlog", command: "npm test"}]; s == nil || s.count != 1 {
+		t.Errorf("streak after pass = %+v, want a new one with 1 failure", s)
+	}
+}
+
+func TestDetector_WindowSplitsStreaks(t *testing.T) {
+	d := NewDetector(30*time.Minute, 10*time.Minute, 2)
+
+	d.Observe(commandEvent("cargo build", 101, base))
+	d.Observe(commandEvent("cargo build", 101, base.Add(45*time.Minute)))
+
+	if got := d.Due(base.Add(2 * time.Hour)); len(got) != 0 {
+		t.Errorf("failures further apart than the window were reported: %+v", got)
+	}
+}
+
+func TestDetector_IgnoresEventsWithoutResult(t *testing.T) {
+	d := NewDetector(time.Hour, time.Minute, 2)
+
+	note := events.NewEvent(string(events.SourceManual), string(events.TypeNote))
+	note.Payload["text"] = "exit_code is missing"
+	d.Observe(note)
+
+	insight := Insight{Source: "shell", Command: "go vet", Failures: 2, First: base, Last: base}.Event()
+	d.Observe(insight)
+	d.Observe(insight)
+
+	if len(d.streaks) != 0 {
+		t.Errorf("streaks = %d, want 0", len(d.streaks))
+	}
+}
+
+func TestDetector_TestRunFallsBackToRunner(t *testing.T) {
+	d := NewDetector(time.Hour, time.Minute, 2)
+
+	for i := 0; i < 2; i++ {
+		event := events.NewEvent(string(events.SourceTests), string(events.TypeTestRun))
+		event.Timestamp = base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)
+		event.Payload["runner"] = "pytest"
+		event.Payload["exit_code"] = 1
+		event.Payload["output"] = "FAILED test_api.py::test_login"
+		d.Observe(event)
+	}
+
+	got := d.Due(base.Add(time.Hour))
+	if len(got) != 1 || got[0].Command != "pytest" || got[0].Output != "FAILED test_api.py::test_login" {
+		t.Fatalf("Due() = %+v", got)
+	}
+}
+
+func TestInsightEvent(t *testing.T) {
+	insight := Insight{
+		Source:   "tests",
+		Repo:     "devlog",
+		Branch:   "main",
+		Command:  "go test ./...",
+		ExitCode: 1,
+		Failures: 7,
+		First:    base,
+		Last:     base.Add(40 * time.Minute),
+	}
+
+	event := insight.Event()
+	if err := event.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := event.ValidatePayload(); err != nil {
+		t.Fatalf("ValidatePayload() error = %v", err)
+	}
+	if event.Timestamp != "2026-03-02T14:40:00Z" || event.Repo != "devlog" || event.Severity != "warning" {
+		t.Errorf("event = %+v", event)
+	}
+	if event.Payload["message"] != "go test ./... failed 7 times in 40 min" {
+		t.Errorf("message = %v", event.Payload["message"])
+	}
+}
+
+type fakeIngester struct {
+	events []*events.Event
+}
+
+func (f *fakeIngester) IngestEvent(ctx context.Context, event *events.Event) error {
+	f.events = append(f.events, event)
+	return nil
+}
+
+func TestEmit(t *testing.T) {
+	ingester := &fakeIngester{}
+	insights := []Insight{
+		{Source: "shell", Command: "make", Failures: 3, First: base, Last: base.Add(time.Minute)},
+		{Source: "shell", Command: "make test", Failures: 4, First: base, Last: base.Add(2 * time.Minute)},
+	}
+
+	Emit(context.Background(), ingester, insights, logger.Default())
+
+	if len(ingester.events) != 2 {
+		t.Fatalf("ingested %d events, want 2", len(ingester.events))
+	}
+	if ingester.events[1].Payload["command"] != "make test" {
+		t.Errorf("second insight = %+v", ingester.events[1].Payload)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	p := &Plugin{}
+	if err := p.ValidateConfig(map[string]interface{}{}); err != nil {
+		t.Errorf("ValidateConfig() with defaults error = %v", err)
+	}
+	if err := p.ValidateConfig(map[string]interface{}{"window_minutes": 5}); err != nil {
+		t.Errorf("ValidateConfig() with a short window error = %v", err)
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{"window_minutes": 2000},
+		{"window_minutes": 30, "settle_minutes": 45},
+		{"min_failures": 1},
+		{"min_failures": "three"},
+	} {
+		if err := p.ValidateConfig(bad); err == nil {
+			t.Errorf("ValidateConfig(%v) should fail", bad)
+		}
+	}
+}
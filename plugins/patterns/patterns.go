@@ -0,0 +1,233 @@
+package patterns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+)
+
+const (
+	DefaultWindowMinutes = 60
+	DefaultSettleMinutes = 10
+	DefaultMinFailures   = 3
+
+	// checkInterval is how often ended streaks are looked for.
+	checkInterval = 30 * time.Second
+)
+
+type Plugin struct {
+	mu       sync.RWMutex
+	detector *Detector
+	ingester plugins.EventIngester
+	logger   *logger.Logger
+}
+
+type Config struct {
+	// WindowMinutes is the longest gap between two failures of a command
+	// that still counts as the same streak.
+	WindowMinutes int `json:"window_minutes,omitempty"`
+	// SettleMinutes is how long a command must go without failing before
+	// a streak that never passed is reported.
+	SettleMinutes int `json:"settle_minutes,omitempty"`
+	// MinFailures is the shortest streak worth an insight.
+	MinFailures int `json:"min_failures,omitempty"`
+}
+
+func init() {
+	events.RegisterPayloadSchema(payloadSchema)
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "patterns"
+}
+
+func (p *Plugin) Description() string {
+	return "Spots commands and tests that keep failing and records them as insights"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "patterns",
+		Description:  "Spots commands and tests that keep failing and records them as insights",
+		Dependencies: []string{},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Patterns plugin")
+	ctx.Log("Commands and test runs that fail min_failures times in a row become patterns/insight events")
+	ctx.Log("Insights show up in search, the dashboard and summaries")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Patterns plugin")
+	ctx.Log("Insights already recorded are kept")
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		WindowMinutes: DefaultWindowMinutes,
+		SettleMinutes: DefaultSettleMinutes,
+		MinFailures:   DefaultMinFailures,
+	}
+}
+
+func (p *Plugin) ValidateConfig(cfg interface{}) error {
+	cfgMap, ok := cfg.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	c, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+	return c.Validate()
+}
+
+func (c *Config) Validate() error {
+	if c.WindowMinutes < 1 || c.WindowMinutes > 24*60 {
+		return errors.NewValidation("window_minutes", "must be between 1 and 1440")
+	}
+	if c.SettleMinutes < 1 || c.SettleMinutes > c.WindowMinutes {
+		return errors.NewValidation("settle_minutes", "must be at least 1 and at most window_minutes")
+	}
+	if c.MinFailures < 2 {
+		return errors.NewValidation("min_failures", "must be at least 2")
+	}
+	return nil
+}
+
+// ParseConfig reads the plugin config, filling in defaults for options
+// that are not set.
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	if cfg.WindowMinutes == 0 {
+		cfg.WindowMinutes = DefaultWindowMinutes
+	}
+	if cfg.SettleMinutes == 0 {
+		cfg.SettleMinutes = min(DefaultSettleMinutes, cfg.WindowMinutes)
+	}
+	if cfg.MinFailures == 0 {
+		cfg.MinFailures = DefaultMinFailures
+	}
+	return cfg, nil
+}
+
+func (p *Plugin) Initialize(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("patterns", "initialize", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("patterns", "parse config", err)
+	}
+
+	log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger)
+	if !ok || log == nil {
+		log = logger.Default()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.detector = NewDetector(
+		time.Duration(cfg.WindowMinutes)*time.Minute,
+		time.Duration(cfg.SettleMinutes)*time.Minute,
+		cfg.MinFailures,
+	)
+	p.logger = log
+	return nil
+}
+
+func (p *Plugin) InjectServices(services map[string]interface{}) error {
+	service, ok := services[plugins.ServiceEventIngest]
+	if !ok {
+		return errors.WrapPlugin("patterns", "inject services", fmt.Errorf("%s service not found", plugins.ServiceEventIngest))
+	}
+
+	ingester, ok := service.(plugins.EventIngester)
+	if !ok {
+		return errors.WrapPlugin("patterns", "inject services", fmt.Errorf("%s service has wrong type", plugins.ServiceEventIngest))
+	}
+
+	p.mu.Lock()
+	p.ingester = ingester
+	p.mu.Unlock()
+	return nil
+}
+
+// ObserveEvent counts a stored event toward the failure streaks; insights
+// are stored from Start.
+func (p *Plugin) ObserveEvent(event *events.Event) {
+	p.mu.RLock()
+	detector := p.detector
+	p.mu.RUnlock()
+	if detector != nil {
+		detector.Observe(event)
+	}
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	p.mu.RLock()
+	detector, ingester, log := p.detector, p.ingester, p.logger
+	p.mu.RUnlock()
+	if detector == nil || ingester == nil {
+		return errors.WrapPlugin("patterns", "start", fmt.Errorf("plugin not initialized"))
+	}
+
+	log.Info("failure pattern detection started",
+		slog.Duration("window", detector.window),
+		slog.Int("min_failures", detector.minFailures))
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("failure pattern detection stopped")
+			return nil
+		case now := <-ticker.C:
+			timer := metrics.StartPluginTimer("patterns")
+			Emit(ctx, ingester, detector.Due(now), log)
+			timer.Stop()
+		}
+	}
+}
+
+// Emit stores insights through the ingest pipeline. An insight that is
+// filtered out or fails to store is logged and dropped.
+func Emit(ctx context.Context, ingester plugins.EventIngester, insights []Insight, log *logger.Logger) {
+	for _, insight := range insights {
+		event := insight.Event()
+		if err := ingester.IngestEvent(ctx, event); err != nil {
+			log.Debug("insight not stored",
+				slog.String("command", insight.Command),
+				slog.String("error", err.Error()))
+			continue
+		}
+		log.Info("recorded failure pattern", slog.String("insight", event.Payload["message"].(string)))
+	}
+}
@@ -0,0 +1,245 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+const defaultDSLLimit = 50
+
+// ParseDSL turns a query like
+//
+//	repo:devlog type:commit,merge since:3d keyword:"fts5 index"
+//
+// into search options without involving the LLM. Words without a key are
+// added to the full-text keywords. Dates are parsed in now's location.
+func ParseDSL(input string, now time.Time) (storage.SearchOptions, error) {
+	opts := storage.SearchOptions{
+		Limit:     defaultDSLLimit,
+		SortOrder: storage.SortByTimeDesc,
+		Scope:     storage.ScopeEvents,
+	}
+
+	tokens, err := tokenizeDSL(input)
+	if err != nil {
+		return opts, err
+	}
+
+	var keywords []string
+	for _, tok := range tokens {
+		if tok.key == "" {
+			keywords = append(keywords, tok.value)
+			continue
+		}
+		if tok.value == "" {
+			return opts, fmt.Errorf("%s: missing value", tok.key)
+		}
+
+		switch tok.key {
+		case "keyword", "q":
+			keywords = append(keywords, tok.value)
+		case "repo":
+			opts.RepoPattern = tok.value
+		case "branch":
+			opts.BranchPattern = tok.value
+		case "module", "source":
+			opts.Modules = append(opts.Modules, splitDSLList(tok.value)...)
+		case "type":
+			opts.Types = append(opts.Types, splitDSLList(tok.value)...)
+		case "since":
+			d, err := parseDSLDuration(tok.value)
+			if err != nil {
+				return opts, fmt.Errorf("since: %w", err)
+			}
+			after := now.Add(-d)
+			opts.After = &after
+		case "until":
+			d, err := parseDSLDuration(tok.value)
+			if err != nil {
+				return opts, fmt.Errorf("until: %w", err)
+			}
+			before := now.Add(-d)
+			opts.Before = &before
+		case "from", "after":
+			t, err := parseDSLTime(tok.value, now, false)
+			if err != nil {
+				return opts, fmt.Errorf("%s: %w", tok.key, err)
+			}
+			opts.After = &t
+		case "to", "before":
+			t, err := parseDSLTime(tok.value, now, true)
+			if err != nil {
+				return opts, fmt.Errorf("%s: %w", tok.key, err)
+			}
+			opts.Before = &t
+		case "limit":
+			n, err := strconv.Atoi(tok.value)
+			if err != nil || n <= 0 {
+				return opts, fmt.Errorf("limit: must be a positive number")
+			}
+			opts.Limit = n
+		case "sort":
+			switch order := storage.SortOrder(tok.value); order {
+			case storage.SortByTimeAsc, storage.SortByTimeDesc, storage.SortByRelevance:
+				opts.SortOrder = order
+			default:
+				return opts, fmt.Errorf("sort: must be time_asc, time_desc, or relevance")
+			}
+		case "scope":
+			scope, err := storage.ParseSearchScope(tok.value)
+			if err != nil {
+				return opts, err
+			}
+			opts.Scope = scope
+		default:
+			return opts, fmt.Errorf("unknown key %q (use keyword, repo, branch, module, type, since, until, from, to, limit, sort or scope)", tok.key)
+		}
+	}
+
+	if opts.After != nil && opts.Before != nil && !opts.Before.After(*opts.After) {
+		return opts, fmt.Errorf("time range is empty: end is not after start")
+	}
+
+	opts.Query = strings.Join(keywords, " ")
+	if opts.Query == "" {
+		opts.Query = "*"
+	}
+	return opts, nil
+}
+
+type dslToken struct {
+	key   string
+	value string
+}
+
+func tokenizeDSL(input string) ([]dslToken, error) {
+	var tokens []dslToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		if isDSLSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		var tok dslToken
+		start := i
+		for i < len(runes) && runes[i] != ':' && runes[i] != '"' && !isDSLSpace(runes[i]) {
+			i++
+		}
+		if i < len(runes) && runes[i] == ':' {
+			tok.key = strings.ToLower(string(runes[start:i]))
+			i++
+			start = i
+		} else {
+			i = start
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			value, next, err := readDSLQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tok.value = value
+			i = next
+		} else {
+			for i < len(runes) && !isDSLSpace(runes[i]) {
+				i++
+			}
+			tok.value = string(runes[start:i])
+		}
+
+		if tok.key == "" && tok.value == "" {
+			continue
+		}
+		tokens = append(tokens, tok)
+	}
+	return tokens, nil
+}
+
+func readDSLQuoted(runes []rune, i int) (string, int, error) {
+	var sb strings.Builder
+	for i++; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				sb.WriteRune(runes[i])
+			}
+		case '"':
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", i, fmt.Errorf("unterminated quote")
+}
+
+func isDSLSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n'
+}
+
+func splitDSLList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseDSLDuration accepts Go durations plus d (days) and w (weeks).
+func parseDSLDuration(s string) (time.Duration, error) {
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	default:
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid duration %q (e.g. 30m, 2h, 3d, 1w)", s)
+		}
+		return d, nil
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q (e.g. 30m, 2h, 3d, 1w)", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// parseDSLTime accepts RFC3339, YYYY-MM-DD, "today" and "yesterday". A
+// bare date used as an end bound covers the whole day.
+func parseDSLTime(s string, now time.Time, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	var day time.Time
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch s {
+	case "today":
+		day = today
+	case "yesterday":
+		day = today.AddDate(0, 0, -1)
+	default:
+		t, err := time.ParseInLocation("2006-01-02", s, now.Location())
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q (use YYYY-MM-DD, RFC3339, today or yesterday)", s)
+		}
+		day = t
+	}
+
+	if end {
+		return day.AddDate(0, 0, 1), nil
+	}
+	return day, nil
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+func TestParseDSL(t *testing.T) {
+	now := time.Date(2025, 6, 5, 15, 30, 0, 0, time.UTC)
+
+	opts, err := ParseDSL(`repo:devlog type:commit since:3d keyword:"fts5"`, now)
+	if err != nil {
+		t.Fatalf("ParseDSL() error: %v", err)
+	}
+	if opts.RepoPattern != "devlog" || opts.Query != "fts5" {
+		t.Errorf("repo = %q, query = %q", opts.RepoPattern, opts.Query)
+	}
+	if !reflect.DeepEqual(opts.Types, []string{"commit"}) {
+		t.Errorf("types = %v", opts.Types)
+	}
+	if opts.After == nil || !opts.After.Equal(now.Add(-72*time.Hour)) {
+		t.Errorf("after = %v, want 3 days before now", opts.After)
+	}
+	if opts.Before != nil {
+		t.Errorf("before = %v, want nil", opts.Before)
+	}
+	if opts.Limit != defaultDSLLimit || opts.SortOrder != storage.SortByTimeDesc || opts.Scope != storage.ScopeEvents {
+		t.Errorf("defaults = limit %d sort %q scope %q", opts.Limit, opts.SortOrder, opts.Scope)
+	}
+}
+
+func TestParseDSLTerms(t *testing.T) {
+	now := time.Date(2025, 6, 5, 15, 30, 0, 0, time.UTC)
+
+	opts, err := ParseDSL(`module:git,shell module:kubectl branch:"feature/*" from:2025-06-02 to:yesterday `+
+		`limit:10 sort:time_asc scope:all "login \"redirect\"" timeout`, now)
+	if err != nil {
+		t.Fatalf("ParseDSL() error: %v", err)
+	}
+	if !reflect.DeepEqual(opts.Modules, []string{"git", "shell", "kubectl"}) {
+		t.Errorf("modules = %v", opts.Modules)
+	}
+	if opts.BranchPattern != "feature/*" {
+		t.Errorf("branch = %q", opts.BranchPattern)
+	}
+	if want := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC); !opts.After.Equal(want) {
+		t.Errorf("after = %v, want %v", opts.After, want)
+	}
+	if want := time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC); !opts.Before.Equal(want) {
+		t.Errorf("before = %v, want %v (end of yesterday)", opts.Before, want)
+	}
+	if opts.Limit != 10 || opts.SortOrder != storage.SortByTimeAsc || opts.Scope != storage.ScopeAll {
+		t.Errorf("limit %d sort %q scope %q", opts.Limit, opts.SortOrder, opts.Scope)
+	}
+	if opts.Query != `login "redirect" timeout` {
+		t.Errorf("query = %q", opts.Query)
+	}
+}
+
+func TestParseDSLEmpty(t *testing.T) {
+	opts, err := ParseDSL("  ", time.Now())
+	if err != nil {
+		t.Fatalf("ParseDSL() error: %v", err)
+	}
+	if opts.Query != "*" {
+		t.Errorf("query = %q, want *", opts.Query)
+	}
+}
+
+func TestParseDSLErrors(t *testing.T) {
+	now := time.Date(2025, 6, 5, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"author:me", "unknown key"},
+		{"since:3x", "invalid duration"},
+		{"since:-2d", "invalid duration"},
+		{"from:06/02/2025", "invalid time"},
+		{`keyword:"fts5`, "unterminated quote"},
+		{"from:2025-06-05 to:2025-06-01", "time range is empty"},
+		{"limit:0", "positive number"},
+		{"sort:newest", "sort"},
+		{"repo:", "missing value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := ParseDSL(tt.input, now)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseDSL(%q) error = %v, want it to contain %q", tt.input, err, tt.want)
+			}
+		})
+	}
+}
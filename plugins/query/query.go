@@ -2,10 +2,11 @@ package query
 
 import (
 	"context"
+	"embed"
 	"encoding/json"
 	"fmt"
-	"path/filepath"
 	"strings"
+	"text/template"
 	"time"
 
 	"devlog/internal/config"
@@ -13,15 +14,38 @@ import (
 	"devlog/internal/install"
 	"devlog/internal/llm"
 	"devlog/internal/plugins"
+	"devlog/internal/prompts"
 	"devlog/internal/services"
 	"devlog/internal/storage"
+	llmplugin "devlog/plugins/llm"
 )
 
+//go:embed prompts/*.tmpl
+var promptsFS embed.FS
+
+var planPromptTemplate = template.Must(template.ParseFS(promptsFS, "prompts/plan.tmpl"))
+
+type planPromptData struct {
+	Now          string
+	TZName       string
+	OffsetHours  string
+	Date         string
+	Question     string
+	History      string
+	TwoHoursAgo  string
+	OffsetSuffix string
+}
+
 type Plugin struct {
-	llmClient llm.Client
+	llmClient    llm.Client
+	answerPrompt *template.Template
 }
 
 type Config struct {
+	SaveSessions bool `json:"save_sessions"`
+	// Prompt picks the answer prompt: "concise" (the default), "detailed"
+	// or "custom:<path>".
+	Prompt string `json:"prompt,omitempty"`
 }
 
 type QueryPlan struct {
@@ -77,11 +101,21 @@ func (p *Plugin) DefaultConfig() interface{} {
 }
 
 func (p *Plugin) ValidateConfig(config interface{}) error {
-	_, ok := config.(map[string]interface{})
+	cfgMap, ok := config.(map[string]interface{})
 	if !ok {
 		return errors.NewValidation("config", "must be a map")
 	}
 
+	if val, ok := cfgMap["prompt"]; ok && val != nil {
+		selection, ok := val.(string)
+		if !ok {
+			return errors.NewValidation("prompt", "must be a string")
+		}
+		if _, err := prompts.Load("query", selection); err != nil {
+			return errors.NewValidation("prompt", err.Error())
+		}
+	}
+
 	return nil
 }
 
@@ -105,6 +139,12 @@ func (p *Plugin) Start(ctx context.Context) error {
 	return nil
 }
 
+// AnswerPrompt returns the configured answer prompt, or nil for the
+// built-in one.
+func (p *Plugin) AnswerPrompt() *template.Template {
+	return p.answerPrompt
+}
+
 func (p *Plugin) LLMClient() llm.Client {
 	return p.llmClient
 }
@@ -156,6 +196,10 @@ func (qr *QueryResult) FormatPlan() string {
 }
 
 func (p *Plugin) Query(ctx context.Context, question string) (*QueryResult, error) {
+	return p.query(ctx, question, "")
+}
+
+func (p *Plugin) query(ctx context.Context, question, history string) (*QueryResult, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, errors.WrapPlugin("query", "load config", err)
@@ -165,9 +209,7 @@ func (p *Plugin) Query(ctx context.Context, question string) (*QueryResult, erro
 	if err != nil {
 		return nil, errors.WrapPlugin("query", "get data dir", err)
 	}
-	dbPath := filepath.Join(dataDir, "events.db")
-
-	store, err := storage.New(dbPath)
+	store, err := storage.Open(dataDir, cfg.Storage)
 	if err != nil {
 		return nil, errors.WrapPlugin("query", "open storage", err)
 	}
@@ -176,7 +218,7 @@ func (p *Plugin) Query(ctx context.Context, question string) (*QueryResult, erro
 	eventService := services.NewEventService(store, func() *config.Config { return cfg }, nil)
 
 	fmt.Println("Converting question to SQL query...")
-	plan, err := p.generateQueryPlan(ctx, question)
+	plan, err := p.generateQueryPlan(ctx, question, history)
 	if err != nil {
 		return nil, errors.WrapPlugin("query", "generate query plan", err)
 	}
@@ -193,61 +235,29 @@ func (p *Plugin) Query(ctx context.Context, question string) (*QueryResult, erro
 	}, nil
 }
 
-func (p *Plugin) generateQueryPlan(ctx context.Context, question string) (*QueryPlan, error) {
+func (p *Plugin) generateQueryPlan(ctx context.Context, question, history string) (*QueryPlan, error) {
 	now := time.Now()
 	_, offset := now.Zone()
 	tzName := now.Format("MST")
 	twoHoursAgo := now.Add(-2 * time.Hour)
 
-	prompt := fmt.Sprintf(`You are a query planner for a development activity database. Convert the user's natural language question into a structured query plan.
-
-Current time: %s (timezone: %s, offset: %+d hours)
-Current date: %s
-
-User question: %s
-
-Analyze the question and generate a JSON query plan with these fields:
-
-{
-  "time_range": {
-    "start": "RFC3339 timestamp with timezone or null",
-    "end": "RFC3339 timestamp with timezone or null"
-  },
-  "filters": {
-    "modules": ["git", "shell", "claude", etc] or null for all,
-    "types": ["commit", "command", etc] or null for all,
-    "repo": "repository name pattern" or null,
-    "branch": "branch name pattern" or null,
-    "keywords": "search keywords" or null
-  },
-  "limit": number (choose an appropriate limit based on the question, typically 50-100),
-  "response_goal": "concise description of what the user wants to know"
-}
+	var buf strings.Builder
+	err := planPromptTemplate.Execute(&buf, planPromptData{
+		Now:          now.Format(time.RFC3339),
+		TZName:       tzName,
+		OffsetHours:  fmt.Sprintf("%+d", offset/3600),
+		Date:         now.Format("2006-01-02"),
+		Question:     question,
+		History:      history,
+		TwoHoursAgo:  twoHoursAgo.Format(time.RFC3339),
+		OffsetSuffix: now.Format("-07:00"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("render query plan prompt: %w", err)
+	}
+	prompt := strings.TrimSuffix(buf.String(), "\n")
 
-Time parsing rules:
-- ALL times should use the user's local timezone: %s (offset: %+d hours from UTC)
-- DEFAULT: If the question is vague about time (e.g., "what was I working on?", "what did I do?"), default to the LAST 2 HOURS (%s to now)
-- "today" = start of today (00:00:00 local time) to now
-- "yesterday" = start of yesterday to end of yesterday (local time)
-- "last week" = 7 days ago to now
-- "last Tuesday" = most recent Tuesday at 00:00:00 local time
-- "11am to 3pm" = today 11:00:00 to 15:00:00 in LOCAL TIME
-- "from 11am to 3pm" = today 11:00:00 to 15:00:00 in LOCAL TIME
-- "past 2 hours" = 2 hours ago to now
-- When no date is specified with a time, assume TODAY in local timezone
-- IMPORTANT: Use the timezone offset shown above. Times like "11:00:00" should become "11:00:00%s"
-
-Module names (sources): git, shell, kubectl, claude, tmux, clipboard, wisprflow, manual
-
-Output ONLY valid JSON, no explanation.`,
-		now.Format(time.RFC3339), tzName, offset/3600,
-		now.Format("2006-01-02"),
-		question,
-		tzName, offset/3600,
-		twoHoursAgo.Format(time.RFC3339),
-		now.Format("-07:00"))
-
-	responseStr, err := p.llmClient.Complete(ctx, prompt)
+	responseStr, err := p.llmClient.Complete(llm.WithCaller(ctx, "query"), prompt)
 	if err != nil {
 		return nil, fmt.Errorf("llm completion failed: %w", err)
 	}
@@ -293,6 +303,7 @@ func (p *Plugin) executeSearch(ctx context.Context, eventService *services.Event
 		RepoPattern:   plan.Filters.Repo,
 		BranchPattern: plan.Filters.Branch,
 		After:         plan.TimeRange.Start,
+		Before:        plan.TimeRange.End,
 		SortOrder:     storage.SortByTimeDesc,
 	}
 
@@ -326,6 +337,13 @@ func LoadPlugin() (*Plugin, *Config, error) {
 	}
 
 	plugin := &Plugin{}
+	if pluginCfg.Prompt != "" {
+		tmpl, err := prompts.Load("query", pluginCfg.Prompt)
+		if err != nil {
+			return nil, nil, errors.WrapPlugin("query", "load prompt", err)
+		}
+		plugin.answerPrompt = tmpl
+	}
 
 	llmClient, err := loadLLMClient()
 	if err != nil {
@@ -358,30 +376,16 @@ func loadLLMClient() (llm.Client, error) {
 		return nil, fmt.Errorf("llm plugin config not found")
 	}
 
-	llmCfg := &struct {
-		Provider string `json:"provider"`
-		APIKey   string `json:"api_key"`
-		BaseURL  string `json:"base_url,omitempty"`
-		Model    string `json:"model,omitempty"`
-	}{}
-
-	cfgBytes, err := json.Marshal(llmCfgMap)
+	llmCfg, err := llmplugin.ClientConfig(llmCfgMap)
 	if err != nil {
-		return nil, errors.WrapPlugin("query", "marshal llm config", err)
-	}
-	if err := json.Unmarshal(cfgBytes, llmCfg); err != nil {
-		return nil, errors.WrapPlugin("query", "unmarshal llm config", err)
+		return nil, errors.WrapPlugin("query", "parse llm config", err)
 	}
 
-	client, err := llm.NewClient(llm.Config{
-		Provider: llm.ProviderType(llmCfg.Provider),
-		APIKey:   llmCfg.APIKey,
-		BaseURL:  llmCfg.BaseURL,
-		Model:    llmCfg.Model,
-	})
+	client, err := llm.NewClient(llmCfg)
 	if err != nil {
 		return nil, errors.WrapPlugin("query", "create llm client", err)
 	}
+	llmplugin.TrackUsage()
 
 	return client, nil
 }
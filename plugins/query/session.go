@@ -0,0 +1,104 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/llm"
+
+	"github.com/google/uuid"
+)
+
+const maxHistoryTurns = 5
+
+// Turn is one question asked in an interactive session.
+type Turn struct {
+	Question string
+	Plan     *QueryPlan
+	Found    int
+	Answer   string
+}
+
+// Session keeps earlier questions, plans and answers so follow-up questions
+// like "what about Tuesday?" can be planned against them.
+type Session struct {
+	ID      string
+	Started time.Time
+	Turns   []Turn
+	plugin  *Plugin
+}
+
+func (p *Plugin) NewSession() *Session {
+	return &Session{
+		ID:      uuid.New().String(),
+		Started: time.Now(),
+		plugin:  p,
+	}
+}
+
+// Ask plans and runs a question with the session history as context. The
+// caller records the turn once it has produced an answer.
+func (s *Session) Ask(ctx context.Context, question string) (*QueryResult, error) {
+	return s.plugin.query(ctx, question, s.history())
+}
+
+func (s *Session) Record(question string, result *QueryResult, answer string) {
+	turn := Turn{Question: question, Answer: answer}
+	if result != nil {
+		turn.Plan = result.Plan
+		turn.Found = len(result.Results)
+	}
+	s.Turns = append(s.Turns, turn)
+}
+
+func (s *Session) history() string {
+	turns := s.Turns
+	if len(turns) > maxHistoryTurns {
+		turns = turns[len(turns)-maxHistoryTurns:]
+	}
+
+	var sb strings.Builder
+	for i, turn := range turns {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("Q: %s\n", llm.SanitizeUntrusted(turn.Question, llm.DefaultMaxEventChars)))
+		if turn.Plan != nil {
+			if plan, err := json.Marshal(turn.Plan); err == nil {
+				sb.WriteString(fmt.Sprintf("Plan: %s\n", plan))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("Found: %d events\n", turn.Found))
+		if turn.Answer != "" {
+			sb.WriteString(fmt.Sprintf("Answer: %s\n", llm.SanitizeUntrusted(turn.Answer, llm.DefaultMaxEventChars)))
+		}
+	}
+	return sb.String()
+}
+
+// Event returns the session transcript as a manual/query_session event.
+func (s *Session) Event() *events.Event {
+	event := events.NewEvent(string(events.SourceManual), string(events.TypeQuerySession))
+	event.Timestamp = s.Started.Format(time.RFC3339)
+
+	var transcript strings.Builder
+	questions := make([]string, 0, len(s.Turns))
+	for i, turn := range s.Turns {
+		if i > 0 {
+			transcript.WriteString("\n\n")
+		}
+		transcript.WriteString(fmt.Sprintf("Q: %s\nA: %s", turn.Question, turn.Answer))
+		questions = append(questions, turn.Question)
+	}
+
+	event.Payload["session_id"] = s.ID
+	event.Payload["text"] = transcript.String()
+	event.Payload["questions"] = questions
+	event.Payload["turn_count"] = len(s.Turns)
+	event.Payload["duration_seconds"] = int(time.Since(s.Started).Seconds())
+	return event
+}
@@ -0,0 +1,85 @@
+package query
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type recordingClient struct {
+	prompts  []string
+	response string
+}
+
+func (c *recordingClient) Complete(ctx context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	return c.response, nil
+}
+
+func TestGenerateQueryPlanWithHistory(t *testing.T) {
+	client := &recordingClient{response: "```json\n" + `{"time_range":{"start":"2025-06-03T00:00:00Z","end":"2025-06-03T23:59:59Z"},"filters":{"modules":["git"]},"limit":500,"response_goal":"commits on Tuesday"}` + "\n```"}
+	p := &Plugin{llmClient: client}
+	session := p.NewSession()
+
+	first, err := p.generateQueryPlan(context.Background(), "What did I commit on Monday?", session.history())
+	if err != nil {
+		t.Fatalf("generateQueryPlan() error: %v", err)
+	}
+	if first.Limit != 100 || first.TimeRange.End == nil {
+		t.Errorf("plan = %+v, want limit capped at 100 and an end time", first)
+	}
+	if strings.Contains(client.prompts[0], "follow-up") {
+		t.Error("first question should not include session history")
+	}
+
+	session.Record("What did I commit on Monday?", &QueryResult{Plan: first}, "You fixed the login redirect.")
+
+	if _, err := p.generateQueryPlan(context.Background(), "what about Tuesday?", session.history()); err != nil {
+		t.Fatalf("generateQueryPlan() error: %v", err)
+	}
+	prompt := client.prompts[1]
+	for _, want := range []string{
+		"follow-up in an ongoing session",
+		"Q: What did I commit on Monday?",
+		`"modules":["git"]`,
+		"Answer: You fixed the login redirect.",
+		"User question: what about Tuesday?",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+}
+
+func TestSessionHistoryKeepsRecentTurns(t *testing.T) {
+	session := (&Plugin{}).NewSession()
+	for _, q := range []string{"q1", "q2", "q3", "q4", "q5", "q6", "q7"} {
+		session.Record(q, nil, "answer to "+q)
+	}
+
+	history := session.history()
+	if strings.Contains(history, "Q: q2\n") || !strings.Contains(history, "Q: q3\n") || !strings.Contains(history, "Q: q7\n") {
+		t.Errorf("history should keep the last %d turns:\n%s", maxHistoryTurns, history)
+	}
+}
+
+func TestSessionEvent(t *testing.T) {
+	session := (&Plugin{}).NewSession()
+	session.Record("What did I do on Monday?", nil, "Reviewed PRs.")
+	session.Record("what about Tuesday?", nil, "Deployed the API.")
+
+	event := session.Event()
+	if err := event.Validate(); err != nil {
+		t.Fatalf("invalid event: %v", err)
+	}
+	if event.Source != "manual" || event.Type != "query_session" {
+		t.Errorf("event = %s/%s", event.Source, event.Type)
+	}
+	if event.Payload["turn_count"] != 2 || event.Payload["session_id"] != session.ID {
+		t.Errorf("payload = %v", event.Payload)
+	}
+	want := "Q: What did I do on Monday?\nA: Reviewed PRs.\n\nQ: what about Tuesday?\nA: Deployed the API."
+	if event.Payload["text"] != want {
+		t.Errorf("text = %q", event.Payload["text"])
+	}
+}
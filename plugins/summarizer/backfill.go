@@ -0,0 +1,143 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"devlog/internal/config"
+)
+
+// Window is one focus period the summarizer covers.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+var sectionHeaderRegex = regexp.MustCompile(`(?m)^## (\d{2}):(\d{2}) - (\d{2}):(\d{2})`)
+
+type fileSection struct {
+	offset     int
+	start, end int // minutes since midnight; end is 1440 for sections ending at midnight
+}
+
+func parseSections(content []byte) []fileSection {
+	var sections []fileSection
+	for _, m := range sectionHeaderRegex.FindAllSubmatchIndex(content, -1) {
+		num := func(i int) int {
+			n, _ := strconv.Atoi(string(content[m[i]:m[i+1]]))
+			return n
+		}
+		start := num(2)*60 + num(4)
+		end := num(6)*60 + num(8)
+		if end <= start {
+			end = 24 * 60
+		}
+		sections = append(sections, fileSection{offset: m[0], start: start, end: end})
+	}
+	return sections
+}
+
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+func summaryPath(day time.Time) (string, error) {
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "summaries", fmt.Sprintf("summary_%s.md", day.Format("2006-01-02"))), nil
+}
+
+// MissingWindows splits [from, to) into interval-long windows and returns the
+// ones without a summary yet: windows recorded in summary_windows or already
+// covered by a section of that day's Markdown file are left out.
+func (p *Plugin) MissingWindows(ctx context.Context, from, to time.Time) ([]Window, error) {
+	if now := time.Now(); to.After(now) {
+		to = now
+	}
+
+	fileSections := make(map[string][]fileSection)
+	var missing []Window
+	for start := from; start.Before(to); start = start.Add(p.interval) {
+		end := start.Add(p.interval)
+		if end.After(to) {
+			end = to
+		}
+
+		covered, err := p.storage.SummaryCoverageContext(ctx, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("check summarized windows: %w", err)
+		}
+		if !covered.IsZero() && !covered.Before(end) {
+			continue
+		}
+
+		day := start.Format("2006-01-02")
+		sections, ok := fileSections[day]
+		if !ok {
+			path, err := summaryPath(start)
+			if err != nil {
+				return nil, err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("read summary file: %w", err)
+			}
+			sections = parseSections(content)
+			fileSections[day] = sections
+		}
+		if inSection(sections, minuteOfDay(start)) {
+			continue
+		}
+
+		missing = append(missing, Window{Start: start, End: end})
+	}
+	return missing, nil
+}
+
+func inSection(sections []fileSection, minute int) bool {
+	for _, s := range sections {
+		if s.start <= minute && minute < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// SummarizeWindow generates and stores the summary for one window using the
+// configured context window.
+func (p *Plugin) SummarizeWindow(ctx context.Context, w Window) error {
+	return p.GenerateSummaryForPeriod(ctx, w.Start, w.End, w.Start.Add(-p.contextWindow))
+}
+
+// insertBeforeLaterSection writes section ahead of the first section in the
+// file that starts after focusStart, so backfilled periods keep the daily
+// file in chronological order. It reports false when no such section exists
+// and the caller should append as usual.
+func insertBeforeLaterSection(path, section string, focusStart time.Time) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("read summary file: %w", err)
+	}
+
+	minute := minuteOfDay(focusStart)
+	for _, s := range parseSections(content) {
+		if s.start > minute {
+			updated := string(content[:s.offset]) + section + string(content[s.offset:])
+			if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+				return false, fmt.Errorf("write summary file: %w", err)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
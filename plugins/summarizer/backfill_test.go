@@ -0,0 +1,91 @@
+package summarizer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/logger"
+	"devlog/internal/testutil"
+)
+
+func TestMissingWindows(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	store := testutil.NewTestStorage(t)
+	p := &Plugin{storage: store, interval: time.Hour, logger: logger.Default()}
+
+	ctx := context.Background()
+	day := time.Date(2025, 5, 1, 0, 0, 0, 0, time.Local)
+	if err := store.RecordSummaryWindowContext(ctx, day, day.Add(time.Hour), 3); err != nil {
+		t.Fatalf("RecordSummaryWindowContext() error: %v", err)
+	}
+
+	path, err := summaryPath(day)
+	if err != nil {
+		t.Fatalf("summaryPath() error: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "# Development Summary - May 1, 2025\n\n## 02:00 - 03:00\n\n- Fixed the flaky login test\n\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	windows, err := p.MissingWindows(ctx, day, day.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("MissingWindows() error: %v", err)
+	}
+
+	var got []string
+	for _, w := range windows {
+		got = append(got, w.Start.Format("15:04")+"-"+w.End.Format("15:04"))
+	}
+	if strings.Join(got, ",") != "01:00-02:00,03:00-04:00" {
+		t.Errorf("missing windows = %v, want [01:00-02:00 03:00-04:00]", got)
+	}
+}
+
+func TestInsertBeforeLaterSection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary_2025-05-01.md")
+	content := "# Development Summary - May 1, 2025\n\n" +
+		"## 09:00 - 09:30\n\n- Morning work\n\n" +
+		"## 11:00 - 11:30\n\n- Late morning work\n\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 5, 1, 10, 0, 0, 0, time.Local)
+	inserted, err := insertBeforeLaterSection(path, "## 10:00 - 10:30\n\n- Backfilled\n\n", start)
+	if err != nil || !inserted {
+		t.Fatalf("insertBeforeLaterSection() = %v, %v; want inserted", inserted, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	got := string(data)
+	first, middle, last := strings.Index(got, "## 09:00"), strings.Index(got, "## 10:00"), strings.Index(got, "## 11:00")
+	if !(first < middle && middle < last) {
+		t.Errorf("sections out of order:\n%s", got)
+	}
+
+	late := time.Date(2025, 5, 1, 12, 0, 0, 0, time.Local)
+	inserted, err = insertBeforeLaterSection(path, "## 12:00 - 12:30\n\n", late)
+	if err != nil || inserted {
+		t.Errorf("insertBeforeLaterSection() for the latest period = %v, %v; want append by caller", inserted, err)
+	}
+}
+
+func TestParseSectionsMidnight(t *testing.T) {
+	sections := parseSections([]byte("## 23:30 - 00:00 (30 minutes)\n\nNo development activity recorded during this period.\n\n"))
+	if len(sections) != 1 || sections[0].start != 23*60+30 || sections[0].end != 24*60 {
+		t.Errorf("sections = %+v, want one section ending at midnight", sections)
+	}
+	if !inSection(sections, 23*60+45) || inSection(sections, 23*60) {
+		t.Error("inSection() did not respect the section bounds")
+	}
+}
@@ -0,0 +1,298 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/template"
+
+	"devlog/internal/events"
+	"devlog/internal/llm"
+)
+
+const (
+	// answerTokens is kept free for the model's answer; the API clients cap
+	// answers at 1000 tokens.
+	answerTokens = 1024
+	// minPromptTokens is the smallest prompt budget. The detailed prompt's
+	// instructions alone take about 2000 tokens.
+	minPromptTokens = 4096
+	// minChunkTokens is the least room a chunk gets for events, even when a
+	// long custom prompt leaves less than that in the budget.
+	minChunkTokens = 1024
+)
+
+// promptBudget returns how many tokens one summarizer prompt may use: the
+// configured max_prompt_tokens, or three quarters of the model's context
+// less room for the answer, since token counts are only estimated.
+func (p *Plugin) promptBudget() int {
+	budget := p.maxPromptTokens
+	if budget <= 0 {
+		budget = llm.ContextTokens(p.llmClient)*3/4 - answerTokens
+	}
+	return max(budget, minPromptTokens)
+}
+
+// chunk is a share of an oversized focus window that is summarized on its
+// own before the shares are merged.
+type chunk struct {
+	keys    []string
+	context []*events.Event
+	focus   []*events.Event
+}
+
+func (c chunk) label() string {
+	return strings.Join(c.keys, ", ")
+}
+
+// chunkKey groups events by repo, and events outside any repo by source.
+func chunkKey(evt *events.Event) string {
+	if evt.Repo != "" {
+		return evt.Repo
+	}
+	return evt.Source
+}
+
+// splitIntoChunks divides focusEvents into chunks whose events cost at most
+// room tokens. Events of the same repo (or, outside repos, source) stay
+// together unless they alone are over room, in which case they are split by
+// time. Presence events go to every chunk, as in splitByRepo. Each chunk's
+// context is the context events of its repos and sources, newest first
+// until room is used up.
+func splitIntoChunks(contextEvents, focusEvents []*events.Event, room int, cost func(*events.Event) int) []chunk {
+	var (
+		presence     []*events.Event
+		presenceCost int
+		order        []string
+		byKey        = make(map[string][]*events.Event)
+	)
+	for _, evt := range focusEvents {
+		if evt.Source == string(events.SourceActivity) {
+			presence = append(presence, evt)
+			presenceCost += cost(evt)
+			continue
+		}
+		key := chunkKey(evt)
+		if _, ok := byKey[key]; !ok {
+			order = append(order, key)
+		}
+		byKey[key] = append(byKey[key], evt)
+	}
+	sort.Strings(order)
+
+	room -= presenceCost
+	if room < 1 {
+		// Presence alone fills the budget; it is dropped rather than
+		// crowding out the work it would describe.
+		room += presenceCost
+		presence = nil
+	}
+
+	var (
+		chunks  []chunk
+		current chunk
+		used    int
+	)
+	flush := func() {
+		if len(current.focus) > 0 {
+			chunks = append(chunks, current)
+		}
+		current, used = chunk{}, 0
+	}
+	for _, key := range order {
+		group := byKey[key]
+		groupCost := 0
+		for _, evt := range group {
+			groupCost += cost(evt)
+		}
+
+		if groupCost <= room {
+			if used+groupCost > room {
+				flush()
+			}
+			current.keys = append(current.keys, key)
+			current.focus = append(current.focus, group...)
+			used += groupCost
+			continue
+		}
+
+		flush()
+		for _, evt := range group {
+			c := cost(evt)
+			if used > 0 && used+c > room {
+				flush()
+			}
+			if len(current.keys) == 0 || current.keys[len(current.keys)-1] != key {
+				current.keys = append(current.keys, key)
+			}
+			current.focus = append(current.focus, evt)
+			used += c
+		}
+	}
+	flush()
+
+	for i := range chunks {
+		c := &chunks[i]
+		focusCost := 0
+		for _, evt := range c.focus {
+			focusCost += cost(evt)
+		}
+		c.focus = append(c.focus, presence...)
+		c.context = chunkContext(contextEvents, c.keys, room-focusCost, cost)
+	}
+	return chunks
+}
+
+// chunkContext returns the context events of the given keys that fit in
+// room, dropping the oldest first.
+func chunkContext(contextEvents []*events.Event, keys []string, room int, cost func(*events.Event) int) []*events.Event {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	var kept []*events.Event
+	for i := len(contextEvents) - 1; i >= 0; i-- {
+		evt := contextEvents[i]
+		if !wanted[chunkKey(evt)] {
+			continue
+		}
+		c := cost(evt)
+		if c > room {
+			break
+		}
+		room -= c
+		kept = append(kept, evt)
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
+
+// partialSummary is the summary of one chunk, or of several merged.
+type partialSummary struct {
+	label      string
+	eventCount int
+	text       string
+}
+
+// summarizeChunked summarizes a focus window too large for one prompt: each
+// chunk is summarized on its own, then the chunk summaries are merged in a
+// second pass. Only the merge is streamed.
+func (p *Plugin) summarizeChunked(ctx context.Context, tmpl *template.Template, contextEvents, focusEvents []*events.Event, formatter func(*events.Event) string, budget int) (string, *llm.Completion, error) {
+	empty, err := buildPrompt(tmpl, nil, nil, formatter)
+	if err != nil {
+		return "", nil, fmt.Errorf("build prompt: %w", err)
+	}
+	cost := func(evt *events.Event) int {
+		// The line itself, plus its share of the repo and source headings.
+		return llm.EstimateTokens(llm.SanitizeUntrusted(formatter(evt), llm.DefaultMaxEventChars)) + 8
+	}
+	room := max(budget-llm.EstimateTokens(empty), minChunkTokens)
+	chunks := splitIntoChunks(contextEvents, focusEvents, room, cost)
+
+	p.logger.Info("focus window too large for one prompt, summarizing in chunks",
+		slog.Int("focus_events", len(focusEvents)),
+		slog.Int("chunks", len(chunks)),
+		slog.Int("prompt_budget", budget))
+
+	total := &llm.Completion{}
+	if len(chunks) == 1 {
+		// Only the context had to be trimmed; no merge is needed.
+		prompt, err := buildPrompt(tmpl, chunks[0].context, chunks[0].focus, formatter)
+		if err != nil {
+			return "", nil, fmt.Errorf("build prompt: %w", err)
+		}
+		return p.finishSummary(ctx, prompt, total)
+	}
+
+	ctx = llm.WithCaller(ctx, "summarizer")
+	parts := make([]partialSummary, 0, len(chunks))
+	for _, c := range chunks {
+		prompt, err := buildPrompt(tmpl, c.context, c.focus, formatter)
+		if err != nil {
+			return "", nil, fmt.Errorf("build prompt: %w", err)
+		}
+		completion, err := llm.CompleteWithUsage(ctx, p.llmClient, prompt)
+		if err != nil {
+			return "", nil, fmt.Errorf("summarize chunk %s: %w", c.label(), err)
+		}
+		addUsage(total, completion)
+
+		text := strings.TrimSpace(completion.Text)
+		if text == "" {
+			return "", nil, fmt.Errorf("empty summary from LLM for chunk %s", c.label())
+		}
+		parts = append(parts, partialSummary{label: c.label(), eventCount: len(c.focus), text: text})
+	}
+
+	// Merge in batches until the rest fit in one prompt.
+	for {
+		batches := batchSummaries(parts, max(budget-llm.EstimateTokens(buildMergePrompt(nil)), minChunkTokens))
+		if len(batches) <= 1 || len(batches) == len(parts) {
+			break
+		}
+		merged := make([]partialSummary, 0, len(batches))
+		for _, batch := range batches {
+			if len(batch) == 1 {
+				merged = append(merged, batch[0])
+				continue
+			}
+			completion, err := llm.CompleteWithUsage(ctx, p.llmClient, buildMergePrompt(batch))
+			if err != nil {
+				return "", nil, fmt.Errorf("merge chunk summaries: %w", err)
+			}
+			addUsage(total, completion)
+			merged = append(merged, mergedSummary(batch, completion.Text))
+		}
+		parts = merged
+	}
+
+	return p.finishSummary(ctx, buildMergePrompt(parts), total)
+}
+
+func addUsage(total, completion *llm.Completion) {
+	total.InputTokens += completion.InputTokens
+	total.OutputTokens += completion.OutputTokens
+	total.CostUSD += completion.CostUSD
+}
+
+// batchSummaries groups parts, in order, into batches whose merge prompts
+// fit in room.
+func batchSummaries(parts []partialSummary, room int) [][]partialSummary {
+	var (
+		batches [][]partialSummary
+		current []partialSummary
+		used    int
+	)
+	for _, part := range parts {
+		c := llm.EstimateTokens(formatPartial(part))
+		if len(current) > 0 && used+c > room {
+			batches = append(batches, current)
+			current, used = nil, 0
+		}
+		current = append(current, part)
+		used += c
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+func mergedSummary(batch []partialSummary, text string) partialSummary {
+	labels := make([]string, 0, len(batch))
+	count := 0
+	for _, part := range batch {
+		labels = append(labels, part.label)
+		count += part.eventCount
+	}
+	return partialSummary{
+		label:      strings.Join(labels, ", "),
+		eventCount: count,
+		text:       strings.TrimSpace(text),
+	}
+}
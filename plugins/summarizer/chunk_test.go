@@ -0,0 +1,127 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/llm"
+	"devlog/internal/testutil"
+)
+
+func TestSplitIntoChunks(t *testing.T) {
+	ts := at("2025-11-17 10:05")
+	var focus []*events.Event
+	for i := 0; i < 5; i++ {
+		focus = append(focus, repoEvent("shell", "big", ts))
+	}
+	focus = append(focus,
+		repoEvent("shell", "a", ts),
+		repoEvent("git", "b", ts),
+		repoEvent("shell", "", ts),
+		repoEvent(string(events.SourceActivity), "", ts),
+	)
+	background := []*events.Event{
+		repoEvent("shell", "a", ts.Add(-time.Hour)),
+		repoEvent("shell", "a", ts.Add(-30*time.Minute)),
+		repoEvent("shell", "c", ts.Add(-30*time.Minute)),
+	}
+	cost := func(*events.Event) int { return 10 }
+
+	// 40 tokens, less 10 for the presence event every chunk carries.
+	chunks := splitIntoChunks(background, focus, 40, cost)
+
+	var got []string
+	for _, c := range chunks {
+		got = append(got, fmt.Sprintf("%s:%d", c.label(), len(c.focus)))
+	}
+	want := "a, b:3 big:4 big, shell:4"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("chunks = %v, want %s", got, want)
+	}
+
+	for _, c := range chunks {
+		if last := c.focus[len(c.focus)-1]; last.Source != string(events.SourceActivity) {
+			t.Errorf("chunk %s has no presence event", c.label())
+		}
+	}
+	// The first chunk has 10 tokens left: only the newest context event of a.
+	if ctx := chunks[0].context; len(ctx) != 1 || ctx[0] != background[1] {
+		t.Errorf("first chunk context = %d events, want the newest event of a", len(ctx))
+	}
+	if len(chunks[1].context) != 0 {
+		t.Errorf("big has no context events, got %d", len(chunks[1].context))
+	}
+}
+
+type chunkRecorder struct{ prompts []string }
+
+func (r *chunkRecorder) Complete(ctx context.Context, prompt string) (string, error) {
+	r.prompts = append(r.prompts, prompt)
+	return fmt.Sprintf("Working on: part %d\n\n- Did a thing", len(r.prompts)), nil
+}
+
+func TestGenerateSummaryChunked(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := testutil.NewTestStorage(t)
+	ctx := context.Background()
+
+	start, end := at("2025-11-17 10:00"), at("2025-11-17 10:30")
+	for i := 0; i < 300; i++ {
+		repo := "client-a"
+		if i%3 == 0 {
+			repo = "client-b"
+		}
+		evt := repoEvent("shell", repo, start.Add(time.Duration(i)*5*time.Second))
+		evt.Payload["command"] = fmt.Sprintf("go test ./internal/storage/... -run TestQueryEvents%d", i)
+		if err := store.InsertEvent(evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := &chunkRecorder{}
+	p := NewForPoll(recorder, store, 30*time.Minute, time.Hour, nil)
+	if err := p.SetPrompt("concise"); err != nil {
+		t.Fatal(err)
+	}
+	p.SetMaxPromptTokens(minPromptTokens)
+	if err := p.GenerateSummaryForPeriod(ctx, start, end, start.Add(-time.Hour)); err != nil {
+		t.Fatalf("GenerateSummaryForPeriod() error: %v", err)
+	}
+
+	calls := len(recorder.prompts)
+	if calls < 3 {
+		t.Fatalf("made %d LLM calls, want at least two chunks and a merge", calls)
+	}
+	for i, prompt := range recorder.prompts[:calls-1] {
+		if tokens := llm.EstimateTokens(prompt); tokens > minPromptTokens {
+			t.Errorf("chunk prompt %d is %d tokens, over the %d budget", i, tokens, minPromptTokens)
+		}
+	}
+	merge := recorder.prompts[calls-1]
+	if !strings.Contains(merge, "PARTIAL SUMMARIES") || !strings.Contains(merge, "part 1") {
+		t.Errorf("last call is not a merge of the chunk summaries:\n%s", merge)
+	}
+
+	summaries, err := store.QuerySummariesContext(ctx, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 1 || summaries[0].EventCount != 300 || !strings.Contains(summaries[0].Text, fmt.Sprintf("part %d", calls)) {
+		t.Fatalf("stored summaries = %+v", summaries)
+	}
+}
+
+func TestPromptBudget(t *testing.T) {
+	p := NewForPoll(&chunkRecorder{}, nil, time.Hour, time.Hour, nil)
+	if got, want := p.promptBudget(), llm.DefaultContextTokens*3/4-answerTokens; got != want {
+		t.Errorf("promptBudget() = %d, want %d for a client of unknown size", got, want)
+	}
+	p.SetMaxPromptTokens(20000)
+	if got := p.promptBudget(); got != 20000 {
+		t.Errorf("promptBudget() = %d, want the configured 20000", got)
+	}
+}
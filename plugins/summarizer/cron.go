@@ -0,0 +1,149 @@
+package summarizer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const cronSearchLimit = 4 * 366 * 24 * time.Hour
+
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+type cronField struct {
+	values [61]bool
+	any    bool
+}
+
+type cronExpr struct {
+	minute, hour, dom, month, dow cronField
+}
+
+func parseCron(spec string) (*cronExpr, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := cronDescriptors[spec]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields (minute hour day month weekday), got %d", len(fields))
+	}
+
+	expr := &cronExpr{}
+	specs := []struct {
+		name     string
+		field    *cronField
+		min, max int
+	}{
+		{"minute", &expr.minute, 0, 59},
+		{"hour", &expr.hour, 0, 23},
+		{"day of month", &expr.dom, 1, 31},
+		{"month", &expr.month, 1, 12},
+		{"day of week", &expr.dow, 0, 7},
+	}
+
+	for i, s := range specs {
+		if err := s.field.parse(fields[i], s.min, s.max); err != nil {
+			return nil, fmt.Errorf("%s: %w", s.name, err)
+		}
+	}
+
+	if expr.dow.values[7] {
+		expr.dow.values[0] = true
+	}
+
+	return expr, nil
+}
+
+func (f *cronField) parse(spec string, min, max int) error {
+	f.any = spec == "*" || spec == "?"
+
+	for _, part := range strings.Split(spec, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx != -1 {
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid step in %q", part)
+			}
+			step = n
+			part = part[:idx]
+		}
+
+		lo, hi := min, max
+		switch {
+		case part == "*" || part == "?":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			a, errA := strconv.Atoi(bounds[0])
+			b, errB := strconv.Atoi(bounds[1])
+			if errA != nil || errB != nil {
+				return fmt.Errorf("invalid range %q", part)
+			}
+			lo, hi = a, b
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return fmt.Errorf("invalid value %q", part)
+			}
+			lo, hi = n, n
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return fmt.Errorf("value %q out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			f.values[v] = true
+		}
+	}
+
+	return nil
+}
+
+func (e *cronExpr) matchesDay(t time.Time) bool {
+	if !e.month.values[int(t.Month())] {
+		return false
+	}
+
+	domMatch := e.dom.values[t.Day()]
+	dowMatch := e.dow.values[int(t.Weekday())]
+
+	if e.dom.any || e.dow.any {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func (e *cronExpr) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(cronSearchLimit)
+
+	for t.Before(limit) {
+		if !e.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !e.hour.values[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !e.minute.values[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,169 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+)
+
+// JournalConfig points the summarizer at a git repository that receives a
+// copy of every daily summary file, committed after each period.
+type JournalConfig struct {
+	Path   string `json:"path"`
+	Push   bool   `json:"push,omitempty"`
+	Remote string `json:"remote,omitempty"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// ParseJournalConfig reads the journal block of the summarizer config.
+func ParseJournalConfig(val interface{}) (*JournalConfig, error) {
+	var cfg JournalConfig
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("must be an object with path, push, remote, or branch")
+	}
+	if strings.TrimSpace(cfg.Path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	return &cfg, nil
+}
+
+type journal struct {
+	dir    string
+	push   bool
+	remote string
+	branch string
+}
+
+func newJournal(cfg *JournalConfig) *journal {
+	if cfg == nil {
+		return nil
+	}
+	remote := cfg.Remote
+	if remote == "" {
+		remote = "origin"
+	}
+	return &journal{
+		dir:    config.ExpandHome(cfg.Path),
+		push:   cfg.Push,
+		remote: remote,
+		branch: cfg.Branch,
+	}
+}
+
+// SetJournal enables committing summaries to a git journal repository. A nil
+// config turns it off.
+func (p *Plugin) SetJournal(cfg *JournalConfig) {
+	p.journal = newJournal(cfg)
+}
+
+func journalCommitMessage(focusStart, focusEnd time.Time) string {
+	return fmt.Sprintf("summary %s %s-%s",
+		focusStart.Format("2006-01-02"),
+		focusStart.Format("15:04"),
+		focusEnd.Format("15:04"))
+}
+
+// record copies the daily summary file at src into the journal and commits
+// it. It reports whether a commit was made; an unchanged file is not
+// committed. Push failures are returned after the commit has succeeded so
+// the caller can log them without losing the local history.
+func (j *journal) record(ctx context.Context, src string, focusStart, focusEnd time.Time) (bool, error) {
+	if err := j.ensureRepo(ctx); err != nil {
+		return false, err
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return false, fmt.Errorf("read summary file: %w", err)
+	}
+	name := filepath.Base(src)
+	if err := os.WriteFile(filepath.Join(j.dir, name), data, 0644); err != nil {
+		return false, fmt.Errorf("write journal file: %w", err)
+	}
+
+	if _, err := j.git(ctx, "add", "--", name); err != nil {
+		return false, err
+	}
+	status, err := j.git(ctx, "status", "--porcelain", "--", name)
+	if err != nil {
+		return false, err
+	}
+	if strings.TrimSpace(status) == "" {
+		return false, nil
+	}
+
+	args := append(j.identity(ctx), "commit", "--quiet", "-m", journalCommitMessage(focusStart, focusEnd), "--", name)
+	if _, err := j.git(ctx, args...); err != nil {
+		return false, err
+	}
+
+	if j.push {
+		ref := "HEAD"
+		if j.branch != "" {
+			ref = "HEAD:refs/heads/" + j.branch
+		}
+		if _, err := j.git(ctx, "push", "--quiet", j.remote, ref); err != nil {
+			return true, fmt.Errorf("push journal: %w", err)
+		}
+	}
+	return true, nil
+}
+
+func (j *journal) ensureRepo(ctx context.Context) error {
+	if err := os.MkdirAll(j.dir, 0755); err != nil {
+		return fmt.Errorf("create journal dir: %w", err)
+	}
+	if _, err := os.Stat(filepath.Join(j.dir, ".git")); err == nil {
+		return nil
+	}
+	if _, err := j.git(ctx, "init", "--quiet"); err != nil {
+		return err
+	}
+	if j.branch != "" {
+		if _, err := j.git(ctx, "symbolic-ref", "HEAD", "refs/heads/"+j.branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// identity supplies a committer for machines where git has no user
+// configured, which is common for the account running the daemon.
+func (j *journal) identity(ctx context.Context) []string {
+	if email, err := j.git(ctx, "config", "user.email"); err == nil && strings.TrimSpace(email) != "" {
+		return nil
+	}
+	return []string{"-c", "user.name=devlog", "-c", "user.email=devlog@localhost"}
+}
+
+func (j *journal) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = j.dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		sub := args[0]
+		for i := 0; i+2 < len(args) && args[i] == "-c"; i += 2 {
+			sub = args[i+2]
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			return "", fmt.Errorf("git %s: %w", sub, err)
+		}
+		return "", fmt.Errorf("git %s: %w: %s", sub, err, msg)
+	}
+	return stdout.String(), nil
+}
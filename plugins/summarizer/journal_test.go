@@ -0,0 +1,109 @@
+package summarizer
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestJournalRecord(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	srcDir := t.TempDir()
+	src := filepath.Join(srcDir, "summary_2025-06-01.md")
+	journalDir := filepath.Join(t.TempDir(), "journal")
+	j := newJournal(&JournalConfig{Path: journalDir, Branch: "main"})
+
+	start := time.Date(2025, 6, 1, 14, 0, 0, 0, time.Local)
+	end := start.Add(30 * time.Minute)
+
+	if err := os.WriteFile(src, []byte("# Development Summary\n\n## 14:00 - 14:30\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	committed, err := j.record(ctx, src, start, end)
+	if err != nil || !committed {
+		t.Fatalf("first record: committed=%v err=%v", committed, err)
+	}
+	if got := gitOutput(t, journalDir, "log", "-1", "--format=%s"); got != "summary 2025-06-01 14:00-14:30" {
+		t.Errorf("commit message = %q", got)
+	}
+	if got := gitOutput(t, journalDir, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+		t.Errorf("branch = %q, want main", got)
+	}
+
+	committed, err = j.record(ctx, src, start, end)
+	if err != nil || committed {
+		t.Fatalf("unchanged record: committed=%v err=%v", committed, err)
+	}
+
+	if err := os.WriteFile(src, []byte("# Development Summary\n\n## 14:00 - 14:30\n\n## 14:30 - 15:00\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	committed, err = j.record(ctx, src, end, end.Add(30*time.Minute))
+	if err != nil || !committed {
+		t.Fatalf("second record: committed=%v err=%v", committed, err)
+	}
+	if got := gitOutput(t, journalDir, "rev-list", "--count", "HEAD"); got != "2" {
+		t.Errorf("commit count = %s, want 2", got)
+	}
+}
+
+func TestJournalRecordPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	remote := t.TempDir()
+	gitOutput(t, remote, "init", "--quiet", "--bare")
+
+	journalDir := t.TempDir()
+	gitOutput(t, journalDir, "init", "--quiet")
+	gitOutput(t, journalDir, "remote", "add", "origin", remote)
+
+	src := filepath.Join(t.TempDir(), "summary_2025-06-01.md")
+	if err := os.WriteFile(src, []byte("# Development Summary\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j := newJournal(&JournalConfig{Path: journalDir, Push: true, Branch: "journal"})
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.Local)
+	if _, err := j.record(ctx, src, start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if got := gitOutput(t, remote, "log", "-1", "--format=%s", "journal"); got != "summary 2025-06-01 09:00-10:00" {
+		t.Errorf("pushed commit = %q", got)
+	}
+}
+
+func TestParseJournalConfig(t *testing.T) {
+	if _, err := ParseJournalConfig(map[string]interface{}{"push": true}); err == nil {
+		t.Error("expected error for missing path")
+	}
+	if _, err := ParseJournalConfig("~/journal"); err == nil {
+		t.Error("expected error for non-object")
+	}
+	cfg, err := ParseJournalConfig(map[string]interface{}{"path": "~/journal", "push": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Path != "~/journal" || !cfg.Push {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
@@ -0,0 +1,93 @@
+package summarizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/testutil"
+)
+
+func repoEvent(source, repo string, ts time.Time) *events.Event {
+	evt := events.NewEvent(source, string(events.TypeCommand))
+	evt.Repo = repo
+	evt.Timestamp = ts.UTC().Format(time.RFC3339)
+	evt.Payload["command"] = "make " + repo
+	return evt
+}
+
+func TestSplitByRepo(t *testing.T) {
+	ts := at("2025-11-17 10:05")
+	focus := []*events.Event{
+		repoEvent("shell", "client-a", ts),
+		repoEvent("shell", "", ts),
+		repoEvent("git", "client-b", ts),
+		repoEvent("shell", "client-b", ts),
+		repoEvent(string(events.SourceActivity), "", ts),
+	}
+	background := []*events.Event{repoEvent("shell", "client-a", ts), repoEvent("shell", "", ts), repoEvent("shell", "client-c", ts)}
+
+	groups := splitByRepo(background, focus)
+	var order []string
+	for _, g := range groups {
+		order = append(order, g.label())
+	}
+	if strings.Join(order, ",") != "client-b,client-a,Other activity" {
+		t.Fatalf("groups = %v, want busiest repo first and other activity last", order)
+	}
+
+	a := groups[1]
+	if len(a.focus) != 2 || a.focus[1].Source != string(events.SourceActivity) {
+		t.Errorf("client-a focus should hold its event plus presence, got %d events", len(a.focus))
+	}
+	if len(a.context) != 2 {
+		t.Errorf("client-a context should hold its own and repo-less events, got %d", len(a.context))
+	}
+}
+
+type promptRecorder struct{ prompts []string }
+
+func (r *promptRecorder) Complete(ctx context.Context, prompt string) (string, error) {
+	r.prompts = append(r.prompts, prompt)
+	return "Working on: something\n\n- Did a thing\n- Did another thing", nil
+}
+
+func TestGenerateSummaryPerRepo(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store := testutil.NewTestStorage(t)
+	ctx := context.Background()
+
+	start, end := at("2025-11-17 10:00"), at("2025-11-17 10:30")
+	for _, evt := range []*events.Event{
+		repoEvent("shell", "client-a", at("2025-11-17 10:05")),
+		repoEvent("shell", "client-b", at("2025-11-17 10:10")),
+	} {
+		if err := store.InsertEvent(evt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder := &promptRecorder{}
+	p := NewForPoll(recorder, store, 30*time.Minute, time.Hour, nil)
+	p.SetPerRepo(true)
+	if err := p.GenerateSummaryForPeriod(ctx, start, end, start.Add(-time.Hour)); err != nil {
+		t.Fatalf("GenerateSummaryForPeriod() error: %v", err)
+	}
+
+	if len(recorder.prompts) != 2 {
+		t.Fatalf("made %d LLM calls, want one per repo", len(recorder.prompts))
+	}
+	if strings.Contains(recorder.prompts[0], "make client-b") {
+		t.Error("client-a prompt should not include client-b events")
+	}
+
+	summaries, err := store.QuerySummariesContext(ctx, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || summaries[0].Repo != "client-a" || summaries[1].Repo != "client-b" {
+		t.Fatalf("stored %d summaries: %+v", len(summaries), summaries)
+	}
+}
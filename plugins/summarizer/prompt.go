@@ -4,10 +4,28 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"text/template"
 
 	"devlog/internal/events"
+	"devlog/internal/llm"
+	"devlog/internal/prompts"
+	"devlog/internal/storage"
 )
 
+const maxRepoLabelChars = 100
+
+// DefaultPrompt is the prompt used when the config doesn't pick one.
+const DefaultPrompt = prompts.Detailed
+
+var defaultPromptTemplate = template.Must(prompts.Load("summarizer", DefaultPrompt))
+
+type summaryPromptData struct {
+	FenceNotice   string
+	RepoSection   string
+	ContextEvents string
+	FocusEvents   string
+}
+
 type repoActivity struct {
 	Repo         string
 	Branch       string
@@ -27,8 +45,15 @@ func extractRepoActivity(evts []*events.Event) []repoActivity {
 	sourcePriority := map[string]int{
 		"claude":    3,
 		"github":    2,
+		"gitlab":    2,
+		"bitbucket": 2,
+		"manual":    2,
+		"patterns":  2,
 		"git":       1,
 		"kubectl":   1,
+		"terraform": 1,
+		"tests":     1,
+		"ssh":       1,
 		"shell":     0,
 		"clipboard": 0,
 	}
@@ -74,7 +99,48 @@ func extractRepoActivity(evts []*events.Event) []repoActivity {
 	return activities
 }
 
-func buildPrompt(contextEvents, focusEvents []*events.Event, formatter func(*events.Event) string) string {
+type issueActivity struct {
+	Key        string
+	Title      string
+	EventCount int
+}
+
+// extractIssueActivity groups events by the issue key the issues plugin put
+// in their payload, most active first.
+func extractIssueActivity(evts []*events.Event) []issueActivity {
+	activityMap := make(map[string]*issueActivity)
+	var order []string
+	for _, evt := range evts {
+		key, _ := evt.Payload["issue"].(string)
+		if key == "" {
+			continue
+		}
+		activity := activityMap[key]
+		if activity == nil {
+			activity = &issueActivity{Key: key}
+			activityMap[key] = activity
+			order = append(order, key)
+		}
+		activity.EventCount++
+		if title, _ := evt.Payload["issue_title"].(string); title != "" {
+			activity.Title = title
+		}
+	}
+
+	activities := make([]issueActivity, 0, len(order))
+	for _, key := range order {
+		activities = append(activities, *activityMap[key])
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		return activities[i].EventCount > activities[j].EventCount
+	})
+	return activities
+}
+
+func buildPrompt(tmpl *template.Template, contextEvents, focusEvents []*events.Event, formatter func(*events.Event) string) (string, error) {
+	contextFence := llm.NewFence("CONTEXT EVENTS")
+	focusFence := llm.NewFence("FOCUS EVENTS")
+
 	contextBySource := groupEventsBySource(contextEvents)
 	focusBySource := groupEventsBySource(focusEvents)
 
@@ -85,160 +151,59 @@ func buildPrompt(contextEvents, focusEvents []*events.Event, formatter func(*eve
 		for _, activity := range repoActivities {
 			branchInfo := ""
 			if activity.Branch != "" {
-				branchInfo = fmt.Sprintf(" (%s)", activity.Branch)
+				branchInfo = fmt.Sprintf(" (%s)", llm.SanitizeUntrusted(activity.Branch, maxRepoLabelChars))
 			}
 			repoSection += fmt.Sprintf("- %s%s: %d events (%d CRITICAL/HIGH, %d MEDIUM/LOW)\n",
-				activity.Repo, branchInfo, activity.EventCount, activity.CriticalHigh, activity.MediumLow)
+				llm.SanitizeUntrusted(activity.Repo, maxRepoLabelChars), branchInfo, activity.EventCount, activity.CriticalHigh, activity.MediumLow)
 		}
 		repoSection += "\n"
 	}
 
-	prompt := `You are generating a factual development summary. This is a deterministic
-transformation of the provided events, not a creative task. You must ONLY use
-information explicitly present in the events. Never guess, infer intent, or
-invent missing details.
-
-You will be given two sets of events:
-
-1. CONTEXT EVENTS — older events for background reference only
-2. FOCUS EVENTS — the period that MUST be summarized
-
-Events are grouped by source category:
-- CRITICAL: Claude Code conversations, major architectural work
-- HIGH: GitHub commits, PR activity
-- MEDIUM: git commands, kubectl operations
-- LOW: shell commands, clipboard activity, misc background
-` + repoSection + `
-CONTEXT EVENTS (read for background only; DO NOT summarize these):
-` + formattedBySource(contextBySource, formatter) + `
-
-FOCUS EVENTS (summarize ONLY these):
-` + formattedBySource(focusBySource, formatter) + `
-
-==================== SUMMARY REQUIREMENTS ====================
-
-Your output has exactly two parts:
-
-----------------------------------------------------------------
-PART 1 — CONTEXT LINE (one line, max 80 chars)
-
-Format:
-Single repo: "Working on: <repo> (<branch>)"
-Multiple repos (2-3): "Working on: <repo1> (<branch1>), <repo2> (<branch2>)"
-Many repos (4+): "Working on: <repo1> (<branch1>) + N other repos"
-
-Rules:
-- Use the ACTIVE REPOSITORIES section above for repo/branch information
-- List repos in priority order (already sorted by CRITICAL/HIGH activity)
-- If no repo/branch: use "Working on: <primary-topic>"
-- Never use asterisks or markdown formatting in the context line
-- Keep concise: if listing multiple repos would exceed 80 chars, use "+ N other repos" format
-----------------------------------------------------------------
-
-PART 2 — ACTIVITY SUMMARY (2–4 bullet points)
-
-Each bullet MUST:
-- Be one complete sentence in past tense
-- Start with a strong action verb (not "Implemented clipboard operations" but specific action)
-- Include technical specifics: file paths, function names, tool names, error messages
-- Consolidate repetitive actions into patterns
-- Focus on what was accomplished, not what was attempted
-
-==================== SPECIFICITY GUIDELINES ====================
-
-LEVEL OF DETAIL (aim for the middle):
-
-TOO VAGUE ❌:
-- "Implemented clipboard copy operations throughout the session"
-- "Discussed and planned model testing for summarizer plugin"
-- "Ran multiple terraform plans to manage AWS infrastructure"
-
-TOO DETAILED ❌:
-- "Executed terraform plan at 11:41:03, 11:41:19, 11:41:45, and 11:41:58"
-- "Ran ./scripts/benchmark_summarizer.sh at 2025-11-20 04:28:39 and 13:47:21"
-- "Copied various output logs and configurations related to script testing"
-
-JUST RIGHT ✅:
-- "Created benchmark script for testing LLM models on summarizer prompt variants"
-- "Debugged terraform lock issue using force-unlock, then validated infrastructure plan"
-- "Evaluated qwen2.5:14b and llama3.1:8b for summarization quality and speed"
-
-==================== CONSOLIDATION RULES ====================
-
-You MUST consolidate repetitive or similar events:
-- If >3 related operations → describe the goal, not each operation
-- Repetitive debugging → "Debugged <specific-issue>" with outcome if known
-- Multiple commands for same goal → one bullet describing the objective
-- Clipboard/shell spam → OMIT unless it reveals important pattern
-
-EXAMPLES:
-- NOT: "Ran benchmark script twice"
-- YES: "Benchmarked multiple LLM models for summarizer performance"
-
-- NOT: "Addressed Terraform lock issues by unlocking specific resource"
-- YES: "Resolved terraform state lock conflict in aws-accounts-infra"
-
-==================== PRIORITIZATION (STRICT ORDER) ====================
-
-1. CRITICAL: architectural decisions, major code discussions
-2. HIGH: commits, PRs, major git operations
-3. MEDIUM: include ONLY if needed for understanding CRITICAL/HIGH
-4. LOW: include only if pattern reveals clear intent
-
-If a lower-priority event does not add value to understanding what was accomplished, OMIT IT.
-
-==================== HARD RULES (DO NOT BREAK THESE) ====================
-
-NEVER use:
-- "the user", "I", "we", "they"
-- Uncertainty: "appears", "seems", "probably", "likely"
-- Meta phrases: "worked on", "focused on", "spent time", "continued to"
-- Vague actions: "made changes", "updated files", "ran commands"
-- Timestamps in bullets (dates are already in event format)
-- Generic accomplishments without specifics
-
-ALWAYS use:
-- Past tense action verbs
-- Specific file paths when relevant
-- Tool/command names when they identify the work
-- Technical terminology appropriate to the domain
-- Concrete outcomes when visible in events
-
-==================== GOOD OUTPUT EXAMPLES ====================
-
-GOOD:
-Working on: devlog (main)
-
-- Created benchmark_summarizer.sh to test qwen and llama models with different prompt variants
-- Implemented automatic model unloading after tests to prevent memory exhaustion
-- Fixed timestamp query bug in SQLite event fetching using unixepoch conversion
-
-GOOD:
-Working on: aws-accounts-infra (main)
-
-- Resolved terraform state lock in wistia-dev workspace using force-unlock
-- Validated infrastructure plan for ECS service updates and RDS parameter changes
-- Applied terraform changes to staging environment
-
-GOOD (mixed repos):
-Working on: devlog (main)
-
-- Discussed implementing priority-based event categorization in internal/events/event.go
-- Benchmarked qwen2.5:14b for production summarizer with 50-event test cases
-- Deployed configuration updates to kubernetes staging cluster
-
-==================== OUTPUT FORMAT (STRICT) ====================
-
-<one-line context>
+	if issues := extractIssueActivity(focusEvents); len(issues) > 0 {
+		repoSection += "ISSUES IN FOCUS PERIOD:\n"
+		for _, issue := range issues {
+			title := ""
+			if issue.Title != "" {
+				title = fmt.Sprintf(" %q", llm.SanitizeUntrusted(issue.Title, maxRepoLabelChars))
+			}
+			repoSection += fmt.Sprintf("- %s%s: %d events\n", llm.SanitizeUntrusted(issue.Key, maxRepoLabelChars), title, issue.EventCount)
+		}
+		repoSection += "\n"
+	}
 
-- <bullet 1: most significant technical work>
-- <bullet 2: second most significant work>
-- <bullet 3: additional work if meaningfully different>
-- <bullet 4: only if truly distinct from above>
+	var buf strings.Builder
+	err := tmpl.Execute(&buf, summaryPromptData{
+		FenceNotice:   llm.FenceNotice(contextFence, focusFence),
+		RepoSection:   repoSection,
+		ContextEvents: contextFence.Wrap(formattedBySource(contextBySource, formatter)),
+		FocusEvents:   focusFence.Wrap(formattedBySource(focusBySource, formatter)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("render prompt: %w", err)
+	}
 
-Generate the summary now. Follow ALL rules above with zero deviations.`
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
 
-	return prompt
+// withAnnotations appends each event's annotations to its formatted line,
+// marked so the model can tell them apart from what was captured at the time.
+// The event text is shortened first so the annotations fit in the per-event
+// limit.
+func withAnnotations(formatter func(*events.Event) string, annotations map[string][]storage.Annotation) func(*events.Event) string {
+	if len(annotations) == 0 {
+		return formatter
+	}
+	return func(evt *events.Event) string {
+		notes := annotations[evt.ID]
+		if len(notes) == 0 {
+			return formatter(evt)
+		}
+		line := llm.SanitizeUntrusted(formatter(evt), llm.DefaultMaxEventChars/2)
+		for _, a := range notes {
+			line += fmt.Sprintf(" [annotation added %s] %s", a.CreatedAt.Format("2006-01-02"), a.Text)
+		}
+		return line
+	}
 }
 
 func groupEventsBySource(evts []*events.Event) map[string][]*events.Event {
@@ -258,13 +223,20 @@ func formattedBySource(eventsBySource map[string][]*events.Event, formatter func
 	}{
 		{"claude", "CRITICAL"},
 		{"github", "HIGH"},
+		{"gitlab", "HIGH"},
+		{"bitbucket", "HIGH"},
+		{"manual", "HIGH"},
+		{"patterns", "HIGH"},
 		{"git", "MEDIUM"},
 		{"kubectl", "MEDIUM"},
+		{"terraform", "MEDIUM"},
+		{"tests", "MEDIUM"},
+		{"ssh", "MEDIUM"},
 		{"shell", "LOW"},
 		{"clipboard", "LOW"},
 		{"tmux", "LOW"},
 		{"wisprflow", "LOW"},
-		{"manual", "MEDIUM"},
+		{"activity", "PRESENCE"},
 	}
 
 	for _, s := range sources {
@@ -275,7 +247,7 @@ func formattedBySource(eventsBySource map[string][]*events.Event, formatter func
 
 		sb.WriteString(fmt.Sprintf("\n=== %s: %s (%d events) ===\n", s.label, s.name, len(evts)))
 		for _, evt := range evts {
-			sb.WriteString(formatter(evt) + "\n")
+			sb.WriteString(llm.SanitizeUntrusted(formatter(evt), llm.DefaultMaxEventChars) + "\n")
 		}
 	}
 
@@ -283,5 +255,51 @@ func formattedBySource(eventsBySource map[string][]*events.Event, formatter func
 }
 
 func BuildPromptExported(contextEvents, focusEvents []*events.Event) string {
-	return buildPrompt(contextEvents, focusEvents, FormatEvent)
+	prompt, err := buildPrompt(defaultPromptTemplate, contextEvents, focusEvents, FormatEvent)
+	if err != nil {
+		panic(err)
+	}
+	return prompt
+}
+
+// mergePrompt asks the model to combine the summaries of the chunks of an
+// oversized focus window. The partial summaries already follow the output
+// format of the configured prompt, so the merge keeps it.
+const mergePrompt = `You are combining partial summaries of one period of a developer's activity.
+The period had too many events for one pass, so its events were split by
+repository or tool and each share was summarized separately. Use ONLY what the
+partial summaries state. Never guess intent or invent details.
+
+%s
+
+PARTIAL SUMMARIES:
+%s
+
+Write one summary of the whole period in exactly the format the partial
+summaries use. Lead with the most significant work, combine items that
+describe the same work, and when the format limits the number of bullets or
+sections, keep the most significant ones. Keep every mention of high or
+critical danger operations and their environment.
+
+Generate the summary now.`
+
+// buildMergePrompt renders mergePrompt for parts. Summaries are model output
+// built from untrusted events, so each line is sanitized and the whole is
+// fenced like event text.
+func buildMergePrompt(parts []partialSummary) string {
+	fence := llm.NewFence("PARTIAL SUMMARIES")
+	var sb strings.Builder
+	for _, part := range parts {
+		sb.WriteString(formatPartial(part))
+	}
+	return fmt.Sprintf(mergePrompt, llm.FenceNotice(fence), fence.Wrap(sb.String()))
+}
+
+func formatPartial(part partialSummary) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n=== %s (%d events) ===\n", llm.SanitizeUntrusted(part.label, maxRepoLabelChars), part.eventCount))
+	for _, line := range strings.Split(part.text, "\n") {
+		sb.WriteString(llm.SanitizeUntrusted(line, llm.DefaultMaxEventChars) + "\n")
+	}
+	return sb.String()
 }
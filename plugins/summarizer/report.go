@@ -0,0 +1,276 @@
+package summarizer
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+)
+
+const (
+	ReportFormatMarkdown = "markdown"
+	ReportFormatHTML     = "html"
+
+	maxReportRepos = 10
+	reportBarWidth = 30
+)
+
+//go:embed reports/*.tmpl
+var reportsFS embed.FS
+
+var reportFuncs = map[string]interface{}{
+	"bar":  reportBar,
+	"base": filepath.Base,
+	"join": strings.Join,
+	"pct":  func(f float64) string { return strconv.FormatFloat(f, 'f', 0, 64) + "%" },
+}
+
+var (
+	markdownReportTemplate = template.Must(template.New("report.md.tmpl").Funcs(reportFuncs).ParseFS(reportsFS, "reports/report.md.tmpl"))
+	htmlReportTemplate     = htmltemplate.Must(htmltemplate.New("report.html.tmpl").Funcs(reportFuncs).ParseFS(reportsFS, "reports/report.html.tmpl"))
+)
+
+type Report struct {
+	Title        string
+	Start        time.Time
+	End          time.Time
+	GeneratedAt  time.Time
+	TotalEvents  int
+	Commits      int
+	ActiveHours  int
+	ActiveDays   int
+	SummaryCount int
+	Repos        []ReportRepo
+	Sources      []ReportCount
+	Hours        []ReportCount
+	Days         []ReportDay
+}
+
+type ReportRepo struct {
+	Name    string
+	Events  int
+	Commits int
+	Percent float64
+}
+
+type ReportCount struct {
+	Name    string
+	Count   int
+	Percent float64
+	Max     int
+}
+
+type ReportDay struct {
+	Date        time.Time
+	Events      int
+	Commits     int
+	ActiveHours int
+	Summaries   []*storage.Summary
+}
+
+func ParseWeek(spec string, loc *time.Location) (time.Time, time.Time, error) {
+	var year, week int
+	if _, err := fmt.Sscanf(strings.ToUpper(spec), "%d-W%d", &year, &week); err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid week %q (use ISO format like 2025-W21)", spec)
+	}
+
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, loc)
+	weekOne := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	start := weekOne.AddDate(0, 0, (week-1)*7)
+
+	if y, w := start.ISOWeek(); week < 1 || y != year || w != week {
+		return time.Time{}, time.Time{}, fmt.Errorf("week %d does not exist in %d", week, year)
+	}
+
+	return start, start.AddDate(0, 0, 7), nil
+}
+
+func ParseMonth(spec string, loc *time.Location) (time.Time, time.Time, error) {
+	t, err := time.ParseInLocation("2006-01", spec, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid month %q (use format like 2025-05)", spec)
+	}
+	return t, t.AddDate(0, 1, 0), nil
+}
+
+func BuildReport(ctx context.Context, store storage.Store, title string, start, end time.Time) (*Report, error) {
+	summaries, err := store.QuerySummariesContext(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("query summaries: %w", err)
+	}
+
+	buckets, err := store.ActivityBucketsContext(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("query activity: %w", err)
+	}
+
+	return newReport(title, start, end, summaries, buckets), nil
+}
+
+func newReport(title string, start, end time.Time, summaries []*storage.Summary, buckets []storage.ActivityBucket) *Report {
+	r := &Report{
+		Title:        title,
+		Start:        start,
+		End:          end,
+		GeneratedAt:  time.Now(),
+		SummaryCount: len(summaries),
+	}
+
+	days := make(map[string]*ReportDay)
+	dayKeys := []string{}
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		days[key] = &ReportDay{Date: d}
+		dayKeys = append(dayKeys, key)
+	}
+
+	repos := make(map[string]*ReportRepo)
+	sources := make(map[string]int)
+	var hours [24]int
+	activeHours := make(map[int64]bool)
+
+	for _, b := range buckets {
+		local := b.Hour.In(start.Location())
+		day := days[local.Format("2006-01-02")]
+		if day == nil {
+			continue
+		}
+
+		isCommit := b.Source == string(events.SourceGit) && b.Type == string(events.TypeCommit)
+
+		r.TotalEvents += b.Count
+		day.Events += b.Count
+		if isCommit {
+			r.Commits += b.Count
+			day.Commits += b.Count
+		}
+		if !activeHours[b.Hour.Unix()] {
+			activeHours[b.Hour.Unix()] = true
+			day.ActiveHours++
+		}
+
+		sources[b.Source] += b.Count
+		hours[local.Hour()] += b.Count
+
+		if b.Repo != "" {
+			repo := repos[b.Repo]
+			if repo == nil {
+				repo = &ReportRepo{Name: b.Repo}
+				repos[b.Repo] = repo
+			}
+			repo.Events += b.Count
+			if isCommit {
+				repo.Commits += b.Count
+			}
+		}
+	}
+
+	for _, s := range summaries {
+		if day := days[s.PeriodStart.In(start.Location()).Format("2006-01-02")]; day != nil {
+			day.Summaries = append(day.Summaries, s)
+		}
+	}
+
+	for _, key := range dayKeys {
+		day := days[key]
+		r.ActiveHours += day.ActiveHours
+		if day.Events > 0 || len(day.Summaries) > 0 {
+			r.ActiveDays++
+		}
+		r.Days = append(r.Days, *day)
+	}
+
+	for _, repo := range repos {
+		repo.Percent = percent(repo.Events, r.TotalEvents)
+		r.Repos = append(r.Repos, *repo)
+	}
+	sort.Slice(r.Repos, func(i, j int) bool {
+		if r.Repos[i].Events != r.Repos[j].Events {
+			return r.Repos[i].Events > r.Repos[j].Events
+		}
+		return r.Repos[i].Name < r.Repos[j].Name
+	})
+	if len(r.Repos) > maxReportRepos {
+		r.Repos = r.Repos[:maxReportRepos]
+	}
+
+	maxSource := 0
+	for name, count := range sources {
+		r.Sources = append(r.Sources, ReportCount{Name: name, Count: count, Percent: percent(count, r.TotalEvents)})
+		if count > maxSource {
+			maxSource = count
+		}
+	}
+	sort.Slice(r.Sources, func(i, j int) bool {
+		if r.Sources[i].Count != r.Sources[j].Count {
+			return r.Sources[i].Count > r.Sources[j].Count
+		}
+		return r.Sources[i].Name < r.Sources[j].Name
+	})
+	for i := range r.Sources {
+		r.Sources[i].Max = maxSource
+	}
+
+	maxHour := 0
+	for _, count := range hours {
+		if count > maxHour {
+			maxHour = count
+		}
+	}
+	for hour, count := range hours {
+		if count == 0 {
+			continue
+		}
+		r.Hours = append(r.Hours, ReportCount{
+			Name:    fmt.Sprintf("%02d:00", hour),
+			Count:   count,
+			Percent: percent(count, r.TotalEvents),
+			Max:     maxHour,
+		})
+	}
+
+	return r
+}
+
+func (r *Report) LastDay() time.Time {
+	return r.End.AddDate(0, 0, -1)
+}
+
+func (r *Report) Render(w io.Writer, format string) error {
+	switch format {
+	case "", ReportFormatMarkdown:
+		return markdownReportTemplate.Execute(w, r)
+	case ReportFormatHTML:
+		return htmlReportTemplate.Execute(w, r)
+	default:
+		return fmt.Errorf("unknown report format %q (use %s or %s)", format, ReportFormatMarkdown, ReportFormatHTML)
+	}
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) * 100 / float64(total)
+}
+
+func reportBar(c ReportCount) string {
+	if c.Max == 0 {
+		return ""
+	}
+	width := c.Count * reportBarWidth / c.Max
+	if width == 0 && c.Count > 0 {
+		width = 1
+	}
+	return strings.Repeat("█", width)
+}
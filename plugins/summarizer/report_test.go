@@ -0,0 +1,142 @@
+package summarizer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/storage"
+)
+
+func TestParseWeek(t *testing.T) {
+	tests := []struct {
+		spec      string
+		wantStart string
+		wantErr   bool
+	}{
+		{"2025-W21", "2025-05-19", false},
+		{"2025-w01", "2024-12-30", false},
+		{"2020-W53", "2020-12-28", false},
+		{"2025-W53", "", true},
+		{"2025-W00", "", true},
+		{"2025-05", "", true},
+	}
+
+	for _, tt := range tests {
+		start, end, err := ParseWeek(tt.spec, time.UTC)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseWeek(%q) expected error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("ParseWeek(%q) error: %v", tt.spec, err)
+		}
+		if got := start.Format("2006-01-02"); got != tt.wantStart {
+			t.Errorf("ParseWeek(%q) start = %s, want %s", tt.spec, got, tt.wantStart)
+		}
+		if end.Sub(start) != 7*24*time.Hour {
+			t.Errorf("ParseWeek(%q) spans %v, want 7 days", tt.spec, end.Sub(start))
+		}
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	start, end, err := ParseMonth("2024-02", time.UTC)
+	if err != nil {
+		t.Fatalf("ParseMonth() error: %v", err)
+	}
+	if start.Format("2006-01-02") != "2024-02-01" || end.Format("2006-01-02") != "2024-03-01" {
+		t.Errorf("ParseMonth() = %s..%s", start, end)
+	}
+
+	if _, _, err := ParseMonth("2024-13", time.UTC); err == nil {
+		t.Error("ParseMonth() expected error for invalid month")
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	start, end, _ := ParseWeek("2025-W21", time.UTC)
+	monday := start.Add(9 * time.Hour)
+	tuesday := start.Add(24*time.Hour + 14*time.Hour)
+
+	buckets := []storage.ActivityBucket{
+		{Hour: monday, Source: "git", Type: "commit", Repo: "/src/api", Count: 3},
+		{Hour: monday, Source: "shell", Type: "command", Repo: "/src/api", Count: 10},
+		{Hour: monday.Add(time.Hour), Source: "shell", Type: "command", Repo: "/src/web", Count: 5},
+		{Hour: tuesday, Source: "git", Type: "commit", Repo: "/src/web", Count: 2},
+		{Hour: end.Add(time.Hour), Source: "shell", Type: "command", Count: 100},
+	}
+	summaries := []*storage.Summary{
+		{PeriodStart: monday, PeriodEnd: monday.Add(30 * time.Minute), Text: "Fixed the login flow", Repos: []string{"/src/api"}},
+	}
+
+	r := newReport("Weekly Report", start, end, summaries, buckets)
+
+	if r.TotalEvents != 20 {
+		t.Errorf("TotalEvents = %d, want 20", r.TotalEvents)
+	}
+	if r.Commits != 5 {
+		t.Errorf("Commits = %d, want 5", r.Commits)
+	}
+	if r.ActiveHours != 3 {
+		t.Errorf("ActiveHours = %d, want 3", r.ActiveHours)
+	}
+	if r.ActiveDays != 2 {
+		t.Errorf("ActiveDays = %d, want 2", r.ActiveDays)
+	}
+	if len(r.Days) != 7 {
+		t.Fatalf("got %d days, want 7", len(r.Days))
+	}
+	if len(r.Days[0].Summaries) != 1 {
+		t.Errorf("Monday has %d summaries, want 1", len(r.Days[0].Summaries))
+	}
+	if len(r.Repos) != 2 || r.Repos[0].Name != "/src/api" || r.Repos[0].Commits != 3 {
+		t.Errorf("Repos = %+v, want /src/api first with 3 commits", r.Repos)
+	}
+	if len(r.Hours) != 3 {
+		t.Errorf("got %d hour buckets, want 3", len(r.Hours))
+	}
+
+	var md bytes.Buffer
+	if err := r.Render(&md, ReportFormatMarkdown); err != nil {
+		t.Fatalf("Render(markdown) error: %v", err)
+	}
+	for _, want := range []string{"# Weekly Report", "| api | 13 | 3 |", "Fixed the login flow", "### Monday, May 19"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := r.Render(&html, ReportFormatHTML); err != nil {
+		t.Fatalf("Render(html) error: %v", err)
+	}
+	if !strings.Contains(html.String(), "<title>Weekly Report</title>") {
+		t.Errorf("html report missing title:\n%s", html.String())
+	}
+
+	if err := r.Render(&bytes.Buffer{}, "pdf"); err == nil {
+		t.Error("Render() expected error for unknown format")
+	}
+}
+
+func TestNewReportEscapesHTML(t *testing.T) {
+	start, end, _ := ParseMonth("2025-05", time.UTC)
+	summaries := []*storage.Summary{
+		{PeriodStart: start, PeriodEnd: start.Add(time.Hour), Text: "<script>alert(1)</script>"},
+	}
+
+	var html bytes.Buffer
+	if err := newReport("Monthly", start, end, summaries, nil).Render(&html, ReportFormatHTML); err != nil {
+		t.Fatalf("Render(html) error: %v", err)
+	}
+	if strings.Contains(html.String(), "<script>") {
+		t.Error("html report did not escape summary text")
+	}
+	if !strings.Contains(html.String(), "&lt;script&gt;") {
+		t.Error("html report missing escaped summary text")
+	}
+}
@@ -0,0 +1,204 @@
+package summarizer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	AlignHour = "hour"
+	AlignNone = "none"
+)
+
+type ScheduleConfig struct {
+	Align        string      `json:"align,omitempty"`
+	Cron         string      `json:"cron,omitempty"`
+	WorkingHours *TimeWindow `json:"working_hours,omitempty"`
+	QuietHours   *TimeWindow `json:"quiet_hours,omitempty"`
+}
+
+type TimeWindow struct {
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+	Days  []string `json:"days,omitempty"`
+}
+
+type window struct {
+	start int
+	end   int
+	days  map[time.Weekday]bool
+}
+
+type schedule struct {
+	interval time.Duration
+	align    string
+	origin   time.Time
+	cron     *cronExpr
+	working  *window
+	quiet    *window
+}
+
+type period struct {
+	start time.Time
+	end   time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+func newSchedule(interval time.Duration, cfg ScheduleConfig, origin time.Time) (*schedule, error) {
+	s := &schedule{
+		interval: interval,
+		align:    cfg.Align,
+		origin:   origin,
+	}
+
+	switch s.align {
+	case "":
+		s.align = AlignHour
+	case AlignHour, AlignNone:
+	default:
+		return nil, fmt.Errorf("align must be %q or %q", AlignHour, AlignNone)
+	}
+
+	if cfg.Cron != "" {
+		expr, err := parseCron(cfg.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("cron: %w", err)
+		}
+		if expr.next(origin).IsZero() {
+			return nil, fmt.Errorf("cron: %q never matches", cfg.Cron)
+		}
+		s.cron = expr
+	}
+
+	if cfg.WorkingHours != nil {
+		w, err := parseWindow(*cfg.WorkingHours)
+		if err != nil {
+			return nil, fmt.Errorf("working_hours: %w", err)
+		}
+		s.working = w
+	}
+
+	if cfg.QuietHours != nil {
+		w, err := parseWindow(*cfg.QuietHours)
+		if err != nil {
+			return nil, fmt.Errorf("quiet_hours: %w", err)
+		}
+		s.quiet = w
+	}
+
+	return s, nil
+}
+
+func parseWindow(tw TimeWindow) (*window, error) {
+	start, err := parseClock(tw.Start)
+	if err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+	end, err := parseClock(tw.End)
+	if err != nil {
+		return nil, fmt.Errorf("end: %w", err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("start and end must differ")
+	}
+
+	w := &window{start: start, end: end}
+	if len(tw.Days) > 0 {
+		w.days = make(map[time.Weekday]bool)
+		for _, d := range tw.Days {
+			name := strings.ToLower(strings.TrimSpace(d))
+			if len(name) > 3 {
+				name = name[:3]
+			}
+			day, ok := weekdays[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown day %q", d)
+			}
+			w.days[day] = true
+		}
+	}
+
+	return w, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("must be HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (w *window) contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	day := t.Weekday()
+
+	var inside bool
+	if w.start < w.end {
+		inside = minute >= w.start && minute < w.end
+	} else {
+		inside = minute >= w.start || minute < w.end
+		if minute < w.end {
+			day = t.AddDate(0, 0, -1).Weekday()
+		}
+	}
+
+	if !inside {
+		return false
+	}
+	return w.days == nil || w.days[day]
+}
+
+func (w *window) endAfter(t time.Time) time.Time {
+	end := time.Date(t.Year(), t.Month(), t.Day(), w.end/60, w.end%60, 0, 0, t.Location())
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
+
+func (s *schedule) next(after time.Time) time.Time {
+	if s.cron != nil {
+		return s.cron.next(after)
+	}
+
+	base := s.origin
+	if s.align == AlignHour {
+		base = time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, after.Location())
+	}
+
+	elapsed := after.Sub(base)
+	steps := elapsed/s.interval + 1
+	next := base.Add(steps * s.interval)
+
+	if s.align == AlignHour {
+		midnight := time.Date(after.Year(), after.Month(), after.Day()+1, 0, 0, 0, 0, after.Location())
+		if next.After(midnight) {
+			next = midnight
+		}
+	}
+
+	return next
+}
+
+func (s *schedule) inWorkingHours(t time.Time) bool {
+	return s.working == nil || s.working.contains(t)
+}
+
+func (s *schedule) inQuietHours(t time.Time) bool {
+	return s.quiet != nil && s.quiet.contains(t)
+}
+
+func (s *schedule) quietEnd(t time.Time) time.Time {
+	return s.quiet.endAfter(t)
+}
@@ -0,0 +1,235 @@
+package summarizer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"devlog/internal/logger"
+	"devlog/internal/testutil"
+)
+
+func at(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScheduleNext_HourAligned(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		after    string
+		want     string
+	}{
+		{"30 minute boundary", 30 * time.Minute, "2025-11-17 14:12", "2025-11-17 14:30"},
+		{"exactly on boundary", 30 * time.Minute, "2025-11-17 14:30", "2025-11-17 15:00"},
+		{"90 minutes aligned to midnight", 90 * time.Minute, "2025-11-17 02:00", "2025-11-17 03:00"},
+		{"caps at midnight", 5 * time.Hour, "2025-11-17 21:00", "2025-11-18 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newSchedule(tt.interval, ScheduleConfig{}, at("2025-11-17 09:07"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.next(at(tt.after)); !got.Equal(at(tt.want)) {
+				t.Errorf("next(%s) = %s, want %s", tt.after, got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNext_Unaligned(t *testing.T) {
+	s, err := newSchedule(30*time.Minute, ScheduleConfig{Align: AlignNone}, at("2025-11-17 09:07"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.next(at("2025-11-17 09:20")); !got.Equal(at("2025-11-17 09:37")) {
+		t.Errorf("got %s, want 09:37", got.Format("15:04"))
+	}
+}
+
+func TestScheduleNext_Cron(t *testing.T) {
+	tests := []struct {
+		spec  string
+		after string
+		want  string
+	}{
+		{"*/15 * * * *", "2025-11-17 14:07", "2025-11-17 14:15"},
+		{"0 9-17 * * 1-5", "2025-11-17 17:30", "2025-11-18 09:00"},
+		{"0 9-17 * * 1-5", "2025-11-21 18:00", "2025-11-24 09:00"},
+		{"30 12 1 * *", "2025-11-17 00:00", "2025-12-01 12:30"},
+		{"0 18 * * 7", "2025-11-17 00:00", "2025-11-23 18:00"},
+		{"@daily", "2025-11-17 14:00", "2025-11-18 00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := newSchedule(30*time.Minute, ScheduleConfig{Cron: tt.spec}, at(tt.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.next(at(tt.after)); !got.Equal(at(tt.want)) {
+				t.Errorf("next(%s) = %s, want %s", tt.after, got.Format("2006-01-02 15:04"), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("parseCron(%q) expected error", spec)
+		}
+	}
+
+	if _, err := newSchedule(time.Hour, ScheduleConfig{Cron: "0 0 30 2 *"}, time.Now()); err == nil {
+		t.Error("expected error for cron that never matches")
+	}
+}
+
+func TestScheduleWindows(t *testing.T) {
+	s, err := newSchedule(30*time.Minute, ScheduleConfig{
+		WorkingHours: &TimeWindow{Start: "09:00", End: "18:00", Days: []string{"mon", "tue", "wed", "thu", "friday"}},
+		QuietHours:   &TimeWindow{Start: "22:00", End: "07:00"},
+	}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2025-11-17 is a Monday.
+	working := map[string]bool{
+		"2025-11-17 09:00": true,
+		"2025-11-17 17:59": true,
+		"2025-11-17 18:00": false,
+		"2025-11-17 08:30": false,
+		"2025-11-21 12:00": true,
+		"2025-11-22 12:00": false,
+	}
+	for ts, want := range working {
+		if got := s.inWorkingHours(at(ts)); got != want {
+			t.Errorf("inWorkingHours(%s) = %v, want %v", ts, got, want)
+		}
+	}
+
+	quiet := map[string]bool{
+		"2025-11-17 21:59": false,
+		"2025-11-17 22:00": true,
+		"2025-11-18 03:00": true,
+		"2025-11-18 07:00": false,
+	}
+	for ts, want := range quiet {
+		if got := s.inQuietHours(at(ts)); got != want {
+			t.Errorf("inQuietHours(%s) = %v, want %v", ts, got, want)
+		}
+	}
+
+	if got := s.quietEnd(at("2025-11-17 23:30")); !got.Equal(at("2025-11-18 07:00")) {
+		t.Errorf("quietEnd before midnight = %s, want next morning", got)
+	}
+	if got := s.quietEnd(at("2025-11-18 02:00")); !got.Equal(at("2025-11-18 07:00")) {
+		t.Errorf("quietEnd after midnight = %s, want same morning", got)
+	}
+}
+
+func TestCatchUpCombinesMissedPeriods(t *testing.T) {
+	sched, err := newSchedule(30*time.Minute, ScheduleConfig{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Plugin{schedule: sched}
+
+	if got := p.catchUp(at("2025-11-17 12:00"), at("2025-11-17 12:00")); !got.Equal(at("2025-11-17 12:00")) {
+		t.Errorf("on-time run moved to %s", got.Format("15:04"))
+	}
+	if got := p.catchUp(at("2025-11-17 12:00"), at("2025-11-17 14:10")); !got.Equal(at("2025-11-17 14:00")) {
+		t.Errorf("late run should extend to last passed boundary, got %s", got.Format("15:04"))
+	}
+}
+
+func TestValidateConfigSchedule(t *testing.T) {
+	p := &Plugin{}
+	base := func(schedule interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"interval_seconds":       1800.0,
+			"context_window_seconds": 3600.0,
+			"schedule":               schedule,
+		}
+	}
+
+	valid := map[string]interface{}{
+		"cron":        "*/30 9-18 * * 1-5",
+		"quiet_hours": map[string]interface{}{"start": "22:00", "end": "07:00"},
+	}
+	if err := p.ValidateConfig(base(valid)); err != nil {
+		t.Errorf("expected valid schedule, got %v", err)
+	}
+
+	invalid := []interface{}{
+		map[string]interface{}{"align": "minute"},
+		map[string]interface{}{"cron": "every hour"},
+		map[string]interface{}{"working_hours": map[string]interface{}{"start": "9am", "end": "17:00"}},
+		map[string]interface{}{"quiet_hours": map[string]interface{}{"start": "22:00", "end": "22:00"}},
+		map[string]interface{}{"working_hours": map[string]interface{}{"start": "09:00", "end": "17:00", "days": []interface{}{"funday"}}},
+		"hourly",
+	}
+	for _, schedule := range invalid {
+		if err := p.ValidateConfig(base(schedule)); err == nil {
+			t.Errorf("expected error for schedule %v", schedule)
+		}
+	}
+}
+
+func TestResumePointAfterRestart(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	ctx := context.Background()
+	p := &Plugin{storage: store, interval: 30 * time.Minute, logger: logger.Default()}
+
+	nextRun := at("2025-11-17 10:30")
+	if got := p.resumePoint(ctx, nextRun); !got.Equal(at("2025-11-17 10:00")) {
+		t.Errorf("fresh database resumed from %s, want 10:00", got.Format("15:04"))
+	}
+
+	if err := store.RecordSummaryWindowContext(ctx, at("2025-11-17 09:00"), at("2025-11-17 09:30"), 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.resumePoint(ctx, nextRun); !got.Equal(at("2025-11-17 09:30")) {
+		t.Errorf("missed window should be picked up from 09:30, got %s", got.Format("15:04"))
+	}
+
+	if err := store.RecordSummaryWindowContext(ctx, at("2025-11-17 09:45"), at("2025-11-17 10:15"), 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.resumePoint(ctx, nextRun); !got.Equal(at("2025-11-17 10:15")) {
+		t.Errorf("already summarized time should not be repeated, got %s", got.Format("15:04"))
+	}
+
+	if got := p.resumePoint(ctx, at("2025-11-18 10:30")); !got.Equal(at("2025-11-18 10:00")) {
+		t.Errorf("windows from a previous day should be ignored, got %s", got)
+	}
+}
+
+func TestSummarizePeriodSkipsCoveredWindow(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	ctx := context.Background()
+	p := &Plugin{storage: store, interval: 30 * time.Minute, logger: logger.Default()}
+
+	if err := store.RecordSummaryWindowContext(ctx, at("2025-11-17 10:00"), at("2025-11-17 10:30"), 4); err != nil {
+		t.Fatal(err)
+	}
+
+	p.summarizePeriod(ctx, period{start: at("2025-11-17 10:00"), end: at("2025-11-17 10:30")})
+
+	last, err := store.LastSummaryWindowEndContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Equal(at("2025-11-17 10:30")) {
+		t.Errorf("last window end = %s, want 10:30", last.Format("15:04"))
+	}
+}
@@ -0,0 +1,344 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+)
+
+const (
+	StandupFormatText     = "text"
+	StandupFormatMarkdown = "markdown"
+	StandupFormatSlack    = "slack"
+
+	maxStandupItems = 8
+)
+
+var (
+	blockerTags = map[string]bool{"blocker": true, "blocked": true}
+	todoTags    = map[string]bool{"todo": true, "today": true, "next": true}
+)
+
+// Standup is a "Yesterday / Today / Blockers" digest built without an LLM
+// from stored summaries and the higher-signal events in the window.
+type Standup struct {
+	Since     time.Time
+	Now       time.Time
+	Yesterday []string
+	Today     []string
+	Blockers  []string
+}
+
+// BuildStandup covers activity from since until now. Anything from midnight
+// today onwards is treated as already under way and listed under Today.
+func BuildStandup(ctx context.Context, store storage.Store, since, now time.Time) (*Standup, error) {
+	evts, err := store.QueryEventsContext(ctx, storage.QueryOptions{
+		StartTime: &since,
+		EndTime:   &now,
+		Ascending: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("query events: %w", err)
+	}
+
+	summaries, err := store.QuerySummariesContext(ctx, since, now)
+	if err != nil {
+		return nil, fmt.Errorf("query summaries: %w", err)
+	}
+
+	return newStandup(since, now, evts, summaries), nil
+}
+
+func newStandup(since, now time.Time, evts []*events.Event, summaries []*storage.Summary) *Standup {
+	s := &Standup{Since: since, Now: now}
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if !since.Before(midnight) {
+		midnight = since
+	}
+
+	var before, after []*events.Event
+	for _, evt := range evts {
+		ts, err := time.Parse(time.RFC3339, evt.Timestamp)
+		if err != nil {
+			continue
+		}
+		if ts.Before(midnight) {
+			before = append(before, evt)
+		} else {
+			after = append(after, evt)
+		}
+	}
+
+	s.Yesterday = standupDone(before)
+	if len(s.Yesterday) == 0 {
+		for _, summary := range summaries {
+			if summary.PeriodStart.Before(midnight) {
+				s.Yesterday = appendUnique(s.Yesterday, firstLine(summary.Text))
+			}
+		}
+	}
+
+	s.Today = standupPlanned(evts, after)
+	s.Blockers = standupBlockers(evts)
+
+	s.Yesterday = capItems(s.Yesterday)
+	s.Today = capItems(s.Today)
+	s.Blockers = capItems(s.Blockers)
+	return s
+}
+
+// standupDone lists merged and opened PRs, reviews, notes and commit
+// subjects grouped by repo, roughly in order of how much a team cares.
+func standupDone(evts []*events.Event) []string {
+	var prs, reviews, notes []string
+	commits := map[string][]string{}
+	var repos []string
+
+	for _, evt := range evts {
+		switch events.EventType(evt.Type) {
+		case events.TypePRMerged:
+			prs = appendUnique(prs, "Merged "+prLabel(evt))
+		case events.TypePROpened:
+			prs = appendUnique(prs, "Opened "+prLabel(evt))
+		case events.TypePRReview:
+			reviews = appendUnique(reviews, "Reviewed "+prLabel(evt))
+		case events.TypeNote:
+			if !hasTag(evt, blockerTags) && !hasTag(evt, todoTags) {
+				notes = appendUnique(notes, payloadString(evt, "text"))
+			}
+		case events.TypeCommit:
+			msg := firstLine(payloadString(evt, "message"))
+			if msg == "" {
+				continue
+			}
+			repo := evt.Repo
+			if _, ok := commits[repo]; !ok {
+				repos = append(repos, repo)
+			}
+			commits[repo] = appendUnique(commits[repo], msg)
+		}
+	}
+
+	sort.SliceStable(repos, func(i, j int) bool {
+		return len(commits[repos[i]]) > len(commits[repos[j]])
+	})
+
+	items := append(prs, reviews...)
+	for _, repo := range repos {
+		items = append(items, commitLine(repo, commits[repo]))
+	}
+	return append(items, notes...)
+}
+
+// standupPlanned lists explicit todo notes, PRs still open at the end of the
+// window and whatever was last touched today.
+func standupPlanned(all, today []*events.Event) []string {
+	var items []string
+	for _, evt := range all {
+		if events.EventType(evt.Type) == events.TypeNote && hasTag(evt, todoTags) {
+			items = appendUnique(items, payloadString(evt, "text"))
+		}
+	}
+
+	open := map[string]*events.Event{}
+	var order []string
+	for _, evt := range all {
+		key := fmt.Sprintf("%s#%v", evt.Repo, evt.Payload["pr_number"])
+		switch events.EventType(evt.Type) {
+		case events.TypePROpened:
+			if _, ok := open[key]; !ok {
+				order = append(order, key)
+			}
+			open[key] = evt
+		case events.TypePRMerged, events.TypePRClosed:
+			delete(open, key)
+		}
+	}
+	for _, key := range order {
+		if evt, ok := open[key]; ok {
+			items = appendUnique(items, "Get "+prLabel(evt)+" reviewed and merged")
+		}
+	}
+
+	for i := len(today) - 1; i >= 0; i-- {
+		evt := today[i]
+		if evt.Repo == "" {
+			continue
+		}
+		where := evt.Repo
+		if evt.Branch != "" {
+			where += " (" + evt.Branch + ")"
+		}
+		items = appendUnique(items, "Continue work on "+where)
+		break
+	}
+	return items
+}
+
+func standupBlockers(evts []*events.Event) []string {
+	var items []string
+	failedRuns := map[string]*events.Event{}
+	var order []string
+
+	for _, evt := range evts {
+		switch events.EventType(evt.Type) {
+		case events.TypeNote:
+			if hasTag(evt, blockerTags) {
+				items = appendUnique(items, payloadString(evt, "text"))
+			}
+		case events.TypeWorkflowRun:
+			// Only the latest run per workflow and branch counts: a later
+			// green run means the failure is no longer blocking anything.
+			// Runs that have only started say nothing either way yet.
+			if payloadString(evt, "conclusion") == "" {
+				continue
+			}
+			key := evt.Repo + "/" + payloadString(evt, "workflow") + "@" + evt.Branch
+			if _, ok := failedRuns[key]; !ok {
+				order = append(order, key)
+			}
+			if payloadString(evt, "conclusion") == "failure" {
+				failedRuns[key] = evt
+			} else {
+				failedRuns[key] = nil
+			}
+		}
+	}
+
+	for _, key := range order {
+		evt := failedRuns[key]
+		if evt == nil {
+			continue
+		}
+		label := fmt.Sprintf("%s failing in %s", payloadString(evt, "workflow"), evt.Repo)
+		if evt.Branch != "" {
+			label += " on " + evt.Branch
+		}
+		items = appendUnique(items, label)
+	}
+	return items
+}
+
+func (s *Standup) Render(w io.Writer, format string) error {
+	var heading func(string) string
+	bullet := "- "
+	switch format {
+	case "", StandupFormatText:
+		heading = func(h string) string { return h + ":" }
+	case StandupFormatMarkdown:
+		heading = func(h string) string { return "## " + h }
+	case StandupFormatSlack:
+		heading = func(h string) string { return "*" + h + "*" }
+		bullet = "• "
+	default:
+		return fmt.Errorf("unknown standup format %q (use text, markdown or slack)", format)
+	}
+
+	sections := []struct {
+		title string
+		items []string
+		empty string
+	}{
+		{"Yesterday", s.Yesterday, "Nothing recorded"},
+		{"Today", s.Today, "Nothing planned yet"},
+		{"Blockers", s.Blockers, "None"},
+	}
+
+	var b strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(heading(section.title) + "\n")
+		if len(section.items) == 0 {
+			b.WriteString(bullet + section.empty + "\n")
+			continue
+		}
+		for _, item := range section.items {
+			if format == StandupFormatSlack {
+				item = slackEscape(item)
+			}
+			b.WriteString(bullet + item + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func prLabel(evt *events.Event) string {
+	label := fmt.Sprintf("%s#%v", evt.Repo, evt.Payload["pr_number"])
+	if title := payloadString(evt, "title"); title != "" {
+		label += ": " + title
+	}
+	return label
+}
+
+func commitLine(repo string, messages []string) string {
+	if repo == "" {
+		repo = "(no repo)"
+	}
+	if len(messages) == 1 {
+		return fmt.Sprintf("%s: %s", repo, messages[0])
+	}
+	shown := messages
+	if len(shown) > 3 {
+		shown = shown[:3]
+	}
+	line := fmt.Sprintf("%s: %d commits (%s", repo, len(messages), strings.Join(shown, "; "))
+	if len(messages) > len(shown) {
+		line += "; ..."
+	}
+	return line + ")"
+}
+
+func hasTag(evt *events.Event, tags map[string]bool) bool {
+	for _, tag := range evt.Tags() {
+		if tags[strings.ToLower(tag)] {
+			return true
+		}
+	}
+	return false
+}
+
+func payloadString(evt *events.Event, key string) string {
+	s, _ := evt.Payload[key].(string)
+	return strings.TrimSpace(s)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(strings.TrimLeft(line, "#*- "))
+}
+
+func appendUnique(items []string, item string) []string {
+	if item == "" {
+		return items
+	}
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+func capItems(items []string) []string {
+	if len(items) <= maxStandupItems {
+		return items
+	}
+	extra := len(items) - maxStandupItems + 1
+	return append(items[:maxStandupItems-1], fmt.Sprintf("...and %d more", extra))
+}
+
+// slackEscape escapes the three characters Slack's mrkdwn treats as control
+// characters.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,98 @@
+package summarizer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/storage"
+)
+
+func standupEvent(ts time.Time, eventType events.EventType, repo string, payload map[string]interface{}) *events.Event {
+	evt := events.NewEvent("test", string(eventType))
+	evt.Timestamp = ts.UTC().Format(time.RFC3339)
+	evt.Repo = repo
+	evt.Branch = "main"
+	evt.Payload = payload
+	return evt
+}
+
+func TestNewStandup(t *testing.T) {
+	now := time.Date(2025, 5, 20, 9, 30, 0, 0, time.UTC)
+	since := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	yesterday := since.Add(10 * time.Hour)
+
+	evts := []*events.Event{
+		standupEvent(yesterday, events.TypeCommit, "api", map[string]interface{}{"message": "Fix login redirect\n\nDetails"}),
+		standupEvent(yesterday.Add(time.Minute), events.TypeCommit, "api", map[string]interface{}{"message": "Add session tests"}),
+		standupEvent(yesterday.Add(time.Hour), events.TypePROpened, "api", map[string]interface{}{"pr_number": 42, "title": "Login fixes"}),
+		standupEvent(yesterday.Add(2*time.Hour), events.TypePRMerged, "web", map[string]interface{}{"pr_number": 7, "title": "Dark mode"}),
+		standupEvent(yesterday.Add(3*time.Hour), events.TypeNote, "", map[string]interface{}{"text": "Waiting on staging creds", "tags": []interface{}{"blocker"}}),
+		standupEvent(yesterday.Add(4*time.Hour), events.TypeWorkflowRun, "api", map[string]interface{}{"workflow": "CI", "conclusion": "failure"}),
+		standupEvent(yesterday.Add(4*time.Hour), events.TypeWorkflowRun, "web", map[string]interface{}{"workflow": "CI", "conclusion": "failure"}),
+		standupEvent(yesterday.Add(5*time.Hour), events.TypeWorkflowRun, "web", map[string]interface{}{"workflow": "CI", "conclusion": "success"}),
+		standupEvent(now.Add(-time.Hour), events.TypeNote, "", map[string]interface{}{"text": "Write the migration", "tags": []string{"todo"}}),
+		standupEvent(now.Add(-time.Minute), events.TypeCommand, "cli", map[string]interface{}{"command": "go test ./..."}),
+	}
+
+	s := newStandup(since, now, evts, nil)
+
+	wantYesterday := []string{"Opened api#42: Login fixes", "Merged web#7: Dark mode", "api: 2 commits (Fix login redirect; Add session tests)"}
+	if strings.Join(s.Yesterday, "|") != strings.Join(wantYesterday, "|") {
+		t.Errorf("Yesterday = %q, want %q", s.Yesterday, wantYesterday)
+	}
+
+	wantToday := []string{"Write the migration", "Get api#42: Login fixes reviewed and merged", "Continue work on cli (main)"}
+	if strings.Join(s.Today, "|") != strings.Join(wantToday, "|") {
+		t.Errorf("Today = %q, want %q", s.Today, wantToday)
+	}
+
+	wantBlockers := []string{"Waiting on staging creds", "CI failing in api on main"}
+	if strings.Join(s.Blockers, "|") != strings.Join(wantBlockers, "|") {
+		t.Errorf("Blockers = %q, want %q", s.Blockers, wantBlockers)
+	}
+}
+
+func TestNewStandupFallsBackToSummaries(t *testing.T) {
+	now := time.Date(2025, 5, 20, 9, 0, 0, 0, time.UTC)
+	since := time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC)
+	summaries := []*storage.Summary{
+		{PeriodStart: since.Add(10 * time.Hour), Text: "## Refactored the poller\nMore detail"},
+	}
+
+	s := newStandup(since, now, nil, summaries)
+	if len(s.Yesterday) != 1 || s.Yesterday[0] != "Refactored the poller" {
+		t.Errorf("Yesterday = %q, want the summary's first line", s.Yesterday)
+	}
+}
+
+func TestStandupRender(t *testing.T) {
+	s := &Standup{
+		Yesterday: []string{"Merged web#7: <Dark> mode"},
+		Blockers:  []string{"Waiting on creds"},
+	}
+
+	var slack bytes.Buffer
+	if err := s.Render(&slack, StandupFormatSlack); err != nil {
+		t.Fatalf("Render(slack) error: %v", err)
+	}
+	for _, want := range []string{"*Yesterday*\n• Merged web#7: &lt;Dark&gt; mode", "*Today*\n• Nothing planned yet", "*Blockers*\n• Waiting on creds"} {
+		if !strings.Contains(slack.String(), want) {
+			t.Errorf("slack output missing %q:\n%s", want, slack.String())
+		}
+	}
+
+	var md bytes.Buffer
+	if err := s.Render(&md, StandupFormatMarkdown); err != nil {
+		t.Fatalf("Render(markdown) error: %v", err)
+	}
+	if !strings.Contains(md.String(), "## Yesterday\n- Merged web#7: <Dark> mode") {
+		t.Errorf("markdown output:\n%s", md.String())
+	}
+
+	if err := s.Render(&md, "pdf"); err == nil {
+		t.Error("Render() expected error for unknown format")
+	}
+}
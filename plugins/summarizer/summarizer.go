@@ -4,11 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	"devlog/internal/config"
@@ -20,22 +23,38 @@ import (
 	"devlog/internal/logger"
 	"devlog/internal/metrics"
 	"devlog/internal/plugins"
+	"devlog/internal/prompts"
 	"devlog/internal/storage"
 )
 
 type Plugin struct {
-	llmClient      llm.Client
-	storage        *storage.Storage
-	interval       time.Duration
-	contextWindow  time.Duration
-	excludeSources map[string]bool
-	logger         *logger.Logger
+	llmClient       llm.Client
+	storage         storage.Store
+	interval        time.Duration
+	contextWindow   time.Duration
+	excludeSources  map[string]bool
+	schedule        *schedule
+	journal         *journal
+	prompt          *template.Template
+	perRepo         bool
+	maxPromptTokens int
+	promptName      string
+	validation      ValidationConfig
+	rules           *FormatRules
+	stream          io.Writer
+	logger          *logger.Logger
 }
 
 type Config struct {
-	IntervalSeconds      int      `json:"interval_seconds"`
-	ContextWindowSeconds int      `json:"context_window_seconds"`
-	ExcludeSources       []string `json:"exclude_sources"`
+	IntervalSeconds      int               `json:"interval_seconds"`
+	ContextWindowSeconds int               `json:"context_window_seconds"`
+	ExcludeSources       []string          `json:"exclude_sources"`
+	Schedule             *ScheduleConfig   `json:"schedule,omitempty"`
+	Journal              *JournalConfig    `json:"journal,omitempty"`
+	Prompt               string            `json:"prompt,omitempty"`
+	PerRepo              bool              `json:"per_repo,omitempty"`
+	MaxPromptTokens      int               `json:"max_prompt_tokens,omitempty"`
+	Validation           *ValidationConfig `json:"validation,omitempty"`
 }
 
 func init() {
@@ -122,9 +141,74 @@ func (p *Plugin) ValidateConfig(config interface{}) error {
 		return errors.NewValidation("context_window_seconds", "must be greater than or equal to interval_seconds")
 	}
 
+	if val, ok := cfgMap["schedule"]; ok && val != nil {
+		scheduleCfg, err := parseScheduleConfig(val)
+		if err != nil {
+			return errors.NewValidation("schedule", err.Error())
+		}
+		if _, err := newSchedule(time.Duration(interval)*time.Second, scheduleCfg, time.Now()); err != nil {
+			return errors.NewValidation("schedule", err.Error())
+		}
+	}
+
+	if val, ok := cfgMap["journal"]; ok && val != nil {
+		if _, err := ParseJournalConfig(val); err != nil {
+			return errors.NewValidation("journal", err.Error())
+		}
+	}
+
+	if val, ok := cfgMap["per_repo"]; ok && val != nil {
+		if _, ok := val.(bool); !ok {
+			return errors.NewValidation("per_repo", "must be true or false")
+		}
+	}
+
+	if val, ok := cfgMap["max_prompt_tokens"]; ok && val != nil {
+		var tokens float64
+		switch v := val.(type) {
+		case float64:
+			tokens = v
+		case int:
+			tokens = float64(v)
+		default:
+			return errors.NewValidation("max_prompt_tokens", "must be a number")
+		}
+		if tokens != 0 && tokens < minPromptTokens {
+			return errors.NewValidation("max_prompt_tokens", fmt.Sprintf("must be 0 (use the model's context) or at least %d", minPromptTokens))
+		}
+	}
+
+	if val, ok := cfgMap["validation"]; ok && val != nil {
+		if _, err := ParseValidationConfig(val); err != nil {
+			return errors.NewValidation("validation", err.Error())
+		}
+	}
+
+	if val, ok := cfgMap["prompt"]; ok && val != nil {
+		selection, ok := val.(string)
+		if !ok {
+			return errors.NewValidation("prompt", "must be a string")
+		}
+		if _, err := prompts.Load("summarizer", selection); err != nil {
+			return errors.NewValidation("prompt", err.Error())
+		}
+	}
+
 	return nil
 }
 
+func parseScheduleConfig(val interface{}) (ScheduleConfig, error) {
+	var cfg ScheduleConfig
+	data, err := json.Marshal(val)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("must be an object with align, cron, working_hours, or quiet_hours")
+	}
+	return cfg, nil
+}
+
 func (p *Plugin) InjectServices(services map[string]interface{}) error {
 	llmClient, ok := services["llm.client"]
 	if !ok {
@@ -162,6 +246,25 @@ func (p *Plugin) Start(ctx context.Context) error {
 		p.excludeSources[source] = true
 	}
 
+	scheduleCfg := ScheduleConfig{}
+	if cfg.Schedule != nil {
+		scheduleCfg = *cfg.Schedule
+	}
+	sched, err := newSchedule(p.interval, scheduleCfg, time.Now())
+	if err != nil {
+		return errors.WrapPlugin("summarizer", "parse schedule", err)
+	}
+	p.schedule = sched
+	p.journal = newJournal(cfg.Journal)
+	p.perRepo = cfg.PerRepo
+	p.maxPromptTokens = cfg.MaxPromptTokens
+	if cfg.Validation != nil {
+		p.validation = *cfg.Validation
+	}
+	if err := p.SetPrompt(cfg.Prompt); err != nil {
+		return errors.WrapPlugin("summarizer", "load prompt", err)
+	}
+
 	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
 		p.logger = log
 	} else {
@@ -172,9 +275,12 @@ func (p *Plugin) Start(ctx context.Context) error {
 	if err != nil {
 		return errors.WrapPlugin("summarizer", "get data dir", err)
 	}
-	dbPath := filepath.Join(dataDir, "events.db")
+	appCfg, err := config.Load()
+	if err != nil {
+		return errors.WrapPlugin("summarizer", "load config", err)
+	}
 
-	store, err := storage.New(dbPath)
+	store, err := storage.Open(dataDir, appCfg.Storage)
 	if err != nil {
 		return errors.WrapPlugin("summarizer", "open storage", err)
 	}
@@ -185,47 +291,40 @@ func (p *Plugin) Start(ctx context.Context) error {
 	return nil
 }
 
-func (p *Plugin) calculateNextRunTime() time.Time {
-	now := time.Now()
-	intervalMins := int(p.interval.Minutes())
-
-	currentMinute := now.Minute()
-	minutesToBoundary := intervalMins - (currentMinute % intervalMins)
-
-	if minutesToBoundary == intervalMins {
-		minutesToBoundary = 0
-	}
-
-	nextBoundary := now.Truncate(time.Minute).Add(time.Duration(minutesToBoundary) * time.Minute)
-
-	if time.Until(nextBoundary) < 5*time.Second {
-		nextBoundary = nextBoundary.Add(p.interval)
+func (p *Plugin) calculateNextRunTime(after time.Time) time.Time {
+	next := p.schedule.next(after)
+	if next.Sub(after) < 5*time.Second {
+		next = p.schedule.next(next)
 	}
-
-	return nextBoundary
-}
-
-func (p *Plugin) isAtBoundary(t time.Time) bool {
-	intervalMins := int(p.interval.Minutes())
-	return t.Minute()%intervalMins == 0
+	return next
 }
 
 func (p *Plugin) run(ctx context.Context) {
-	nextRun := p.calculateNextRunTime()
+	nextRun := p.calculateNextRunTime(time.Now())
+	lastRun := p.resumePoint(ctx, nextRun)
+	var pending []period
 
 	p.logger.Info("summarizer started",
 		slog.Duration("interval", p.interval),
 		slog.Duration("context_window", p.contextWindow),
+		slog.Time("resume_from", lastRun),
 		slog.Time("next_run", nextRun))
 
 	for {
-		delay := time.Until(nextRun)
+		wake := nextRun
+		var flushAt time.Time
+		if len(pending) > 0 {
+			flushAt = p.schedule.quietEnd(pending[len(pending)-1].end)
+			if flushAt.Before(wake) {
+				wake = flushAt
+			}
+		}
 
-		p.logger.Debug("waiting until next boundary",
-			slog.Time("next_run", nextRun),
-			slog.Duration("delay", delay))
+		p.logger.Debug("waiting until next run",
+			slog.Time("next_run", wake),
+			slog.Duration("delay", time.Until(wake)))
 
-		timer := time.NewTimer(delay)
+		timer := time.NewTimer(time.Until(wake))
 
 		select {
 		case <-ctx.Done():
@@ -235,16 +334,111 @@ func (p *Plugin) run(ctx context.Context) {
 				p.storage.Close()
 			}
 			return
-		case now := <-timer.C:
-			if p.isAtBoundary(now) {
-				p.logger.Debug("boundary reached, generating summary")
-				if err := p.generateSummary(ctx); err != nil {
-					p.logger.Error("failed to generate summary",
-						slog.String("error", err.Error()))
-				}
-			}
-			nextRun = p.calculateNextRunTime()
+		case <-timer.C:
+		}
+
+		if wake.Before(nextRun) {
+			pending = p.flushPending(ctx, pending)
+			continue
 		}
+
+		end := p.catchUp(nextRun, time.Now())
+		current := period{start: lastRun, end: end}
+		lastRun = end
+		nextRun = p.calculateNextRunTime(end)
+
+		switch {
+		case !p.schedule.inWorkingHours(current.end):
+			p.logger.Debug("outside working hours, skipping summary",
+				slog.Time("period_end", current.end))
+		case p.schedule.inQuietHours(current.end):
+			p.logger.Debug("quiet hours, deferring summary",
+				slog.Time("period_end", current.end))
+			pending = append(pending, current)
+		default:
+			pending = p.flushPending(ctx, pending)
+			p.summarizePeriod(ctx, current)
+		}
+	}
+}
+
+func (p *Plugin) resumePoint(ctx context.Context, nextRun time.Time) time.Time {
+	fallback := nextRun.Add(-p.interval)
+
+	last, err := p.storage.LastSummaryWindowEndContext(ctx)
+	if err != nil {
+		p.logger.Warn("failed to load last summarized window",
+			slog.String("error", err.Error()))
+		return fallback
+	}
+	if last.IsZero() || !last.Before(nextRun) {
+		return fallback
+	}
+
+	dayStart := time.Date(nextRun.Year(), nextRun.Month(), nextRun.Day(), 0, 0, 0, 0, nextRun.Location())
+	if dayStart.Equal(nextRun) {
+		dayStart = dayStart.AddDate(0, 0, -1)
+	}
+	if last.Before(dayStart) {
+		return fallback
+	}
+
+	return last
+}
+
+func (p *Plugin) catchUp(scheduled, now time.Time) time.Time {
+	end := scheduled
+	for {
+		following := p.schedule.next(end)
+		if following.IsZero() || following.After(now) {
+			return end
+		}
+		end = following
+	}
+}
+
+func (p *Plugin) flushPending(ctx context.Context, pending []period) []period {
+	if len(pending) == 0 {
+		return nil
+	}
+
+	p.logger.Info("quiet hours ended, generating deferred summaries",
+		slog.Int("count", len(pending)))
+
+	for _, deferred := range pending {
+		if ctx.Err() != nil {
+			return nil
+		}
+		p.summarizePeriod(ctx, deferred)
+	}
+	return nil
+}
+
+func (p *Plugin) summarizePeriod(ctx context.Context, current period) {
+	timer := metrics.StartPluginTimer("summarizer")
+	defer timer.Stop()
+
+	covered, err := p.storage.SummaryCoverageContext(ctx, current.start, current.end)
+	if err != nil {
+		p.logger.Warn("failed to check summarized windows",
+			slog.String("error", err.Error()))
+	}
+	if !covered.IsZero() {
+		if !covered.Before(current.end) {
+			p.logger.Debug("period already summarized, skipping",
+				slog.Time("period_start", current.start),
+				slog.Time("period_end", current.end))
+			return
+		}
+		if covered.After(current.start) {
+			current.start = covered
+		}
+	}
+
+	contextStart := current.start.Add(-p.contextWindow)
+	if err := p.GenerateSummaryForPeriod(ctx, current.start, current.end, contextStart); err != nil {
+		p.logger.Error("failed to generate summary",
+			slog.String("error", err.Error()))
 	}
 }
 
@@ -280,41 +474,200 @@ func (p *Plugin) GenerateSummaryForPeriod(ctx context.Context, focusStart, focus
 	filteredContextEvents := p.filterEvents(contextEvents)
 	filteredFocusEvents := p.filterEvents(focusEvents)
 
-	if len(filteredFocusEvents) == 0 {
+	if !hasWorkEvents(filteredFocusEvents) {
+		// Presence events alone (e.g. a session ending over lunch) do not
+		// describe any work, so the window is recorded as inactive.
+		filteredFocusEvents = nil
 		p.logger.Debug("no events in focus window, generating placeholder")
 		if err := p.saveSummary("", focusStart, focusEnd, filteredContextEvents, filteredFocusEvents); err != nil {
 			return fmt.Errorf("save summary: %w", err)
 		}
+		if err := p.storage.RecordSummaryWindowContext(ctx, focusStart, focusEnd, 0); err != nil {
+			return fmt.Errorf("record summary window: %w", err)
+		}
+		p.commitJournal(ctx, focusStart, focusEnd)
 		return nil
 	}
 
-	prompt := buildPrompt(filteredContextEvents, filteredFocusEvents, FormatEvent)
+	annotate := withAnnotations(FormatEvent, p.annotations(ctx, filteredContextEvents, filteredFocusEvents))
+	groups := []repoGroup{{context: filteredContextEvents, focus: filteredFocusEvents}}
+	if p.perRepo {
+		groups = splitByRepo(filteredContextEvents, filteredFocusEvents)
+	}
 
-	p.logger.Debug("requesting LLM summary",
-		slog.Int("context_events", len(filteredContextEvents)),
-		slog.Int("focus_events", len(filteredFocusEvents)))
+	var stored []*storage.Summary
+	var sections []string
+	for i, group := range groups {
+		if p.stream != nil && len(groups) > 1 {
+			if i > 0 {
+				fmt.Fprint(p.stream, "\n\n")
+			}
+			fmt.Fprintf(p.stream, "%s:\n", group.label())
+		}
 
-	summary, err := p.llmClient.Complete(ctx, prompt)
-	if err != nil {
-		return fmt.Errorf("generate summary: %w", err)
-	}
+		summary, completion, err := p.summarizeEvents(ctx, group.context, group.focus, annotate)
+		if err != nil {
+			if group.repo != "" {
+				return fmt.Errorf("summarize %s: %w", group.repo, err)
+			}
+			return err
+		}
 
-	summary = strings.TrimSpace(summary)
-	if summary == "" {
-		return fmt.Errorf("empty summary from LLM")
+		if p.perRepo {
+			sections = append(sections, fmt.Sprintf("### %s\n\n%s", group.label(), summary))
+		} else {
+			sections = append(sections, summary)
+		}
+		stored = append(stored, &storage.Summary{
+			PeriodStart:       focusStart,
+			PeriodEnd:         focusEnd,
+			ContextStart:      contextStart,
+			Repo:              group.repo,
+			Repos:             collectRepos(group.focus),
+			Text:              summary,
+			EventCount:        len(group.focus),
+			ContextEventCount: len(group.context),
+			Provider:          completion.Provider,
+			InputTokens:       completion.InputTokens,
+			OutputTokens:      completion.OutputTokens,
+		})
 	}
 
-	if err := p.saveSummary(summary, focusStart, focusEnd, filteredContextEvents, filteredFocusEvents); err != nil {
+	if err := p.saveSummary(strings.Join(sections, "\n\n"), focusStart, focusEnd, filteredContextEvents, filteredFocusEvents); err != nil {
 		return fmt.Errorf("save summary: %w", err)
 	}
 
-	p.logger.Info("summary generated",
-		slog.Int("context_events", len(filteredContextEvents)),
-		slog.Int("focus_events", len(filteredFocusEvents)))
+	if err := p.storage.RecordSummaryWindowContext(ctx, focusStart, focusEnd, len(filteredFocusEvents)); err != nil {
+		return fmt.Errorf("record summary window: %w", err)
+	}
+	p.commitJournal(ctx, focusStart, focusEnd)
+
+	if err := p.storage.ReplaceSummariesContext(ctx, focusStart, focusEnd, stored); err != nil {
+		return fmt.Errorf("store summary: %w", err)
+	}
+
+	for _, summary := range stored {
+		plugins.Publish(plugins.TopicSummaryGenerated, plugins.SummaryGenerated{
+			ID:          summary.ID,
+			PeriodStart: summary.PeriodStart,
+			PeriodEnd:   summary.PeriodEnd,
+			Repos:       summary.Repos,
+			EventCount:  summary.EventCount,
+		})
+
+		p.logger.Info("summary generated",
+			slog.Int64("id", summary.ID),
+			slog.String("repo", summary.Repo),
+			slog.Int("context_events", summary.ContextEventCount),
+			slog.Int("focus_events", summary.EventCount))
+	}
 
 	return nil
 }
 
+// summarizeEvents asks the LLM to summarize focusEvents against the
+// background of contextEvents.
+func (p *Plugin) summarizeEvents(ctx context.Context, contextEvents, focusEvents []*events.Event, formatter func(*events.Event) string) (string, *llm.Completion, error) {
+	tmpl := p.prompt
+	if tmpl == nil {
+		tmpl = defaultPromptTemplate
+	}
+	prompt, err := buildPrompt(tmpl, contextEvents, focusEvents, formatter)
+	if err != nil {
+		return "", nil, fmt.Errorf("build prompt: %w", err)
+	}
+	if budget := p.promptBudget(); llm.EstimateTokens(prompt) > budget {
+		return p.summarizeChunked(ctx, tmpl, contextEvents, focusEvents, formatter, budget)
+	}
+
+	p.logger.Debug("requesting LLM summary",
+		slog.Int("context_events", len(contextEvents)),
+		slog.Int("focus_events", len(focusEvents)))
+
+	return p.finishSummary(ctx, prompt, &llm.Completion{})
+}
+
+// repoGroup is the share of a period's events that one per-repo summary
+// covers. An empty repo holds the events that happened outside any repo.
+type repoGroup struct {
+	repo    string
+	context []*events.Event
+	focus   []*events.Event
+}
+
+func (g repoGroup) label() string {
+	if g.repo == "" {
+		return "Other activity"
+	}
+	return g.repo
+}
+
+// splitByRepo divides a period's events into one group per repo, busiest
+// first, with work outside any repo last. Each group's context also keeps
+// the events outside any repo, and presence events go to every group since
+// they say when the developer was at the keyboard at all.
+func splitByRepo(contextEvents, focusEvents []*events.Event) []repoGroup {
+	byRepo := make(map[string]*repoGroup)
+	var presence []*events.Event
+	for _, evt := range focusEvents {
+		if evt.Source == string(events.SourceActivity) {
+			presence = append(presence, evt)
+			continue
+		}
+		g := byRepo[evt.Repo]
+		if g == nil {
+			g = &repoGroup{repo: evt.Repo}
+			byRepo[evt.Repo] = g
+		}
+		g.focus = append(g.focus, evt)
+	}
+
+	groups := make([]repoGroup, 0, len(byRepo))
+	for _, g := range byRepo {
+		for _, evt := range contextEvents {
+			if evt.Repo == "" || evt.Repo == g.repo {
+				g.context = append(g.context, evt)
+			}
+		}
+		g.focus = append(g.focus, presence...)
+		groups = append(groups, *g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if (groups[i].repo == "") != (groups[j].repo == "") {
+			return groups[j].repo == ""
+		}
+		if len(groups[i].focus) != len(groups[j].focus) {
+			return len(groups[i].focus) > len(groups[j].focus)
+		}
+		return groups[i].repo < groups[j].repo
+	})
+	return groups
+}
+
+func collectRepos(evts []*events.Event) []string {
+	seen := make(map[string]bool)
+	var repos []string
+	for _, evt := range evts {
+		if evt.Repo == "" || seen[evt.Repo] {
+			continue
+		}
+		seen[evt.Repo] = true
+		repos = append(repos, evt.Repo)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+func hasWorkEvents(evts []*events.Event) bool {
+	for _, evt := range evts {
+		if evt.Source != string(events.SourceActivity) {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *Plugin) filterEvents(evts []*events.Event) []*events.Event {
 	if len(p.excludeSources) == 0 {
 		return evts
@@ -329,37 +682,21 @@ func (p *Plugin) filterEvents(evts []*events.Event) []*events.Event {
 	return filtered
 }
 
-func extractFilesFromCommand(cmd string) []string {
-	var files []string
-
-	patterns := []struct {
-		regex *regexp.Regexp
-		group int
-	}{
-		{regexp.MustCompile(`(?:vim|vi|nvim|nano|emacs|code|subl)\s+([^\s]+)`), 1},
-		{regexp.MustCompile(`(?:cat|less|more|head|tail)\s+([^\s]+)`), 1},
-		{regexp.MustCompile(`sed\s+.*?\s+([^\s][^\s]*\.[^\s]+)(?:\s|$)`), 1},
-		{regexp.MustCompile(`awk\s+.*?\s+([^\s][^\s]*\.[^\s]+)(?:\s|$)`), 1},
-		{regexp.MustCompile(`echo\s+.*?>\s*([^\s]+)`), 1},
-		{regexp.MustCompile(`(?:cp|mv)\s+[^\s]+\s+([^\s]+)`), 1},
-		{regexp.MustCompile(`(?:touch|rm|chmod|chown)\s+([^\s]+)`), 1},
-		{regexp.MustCompile(`git\s+(?:add|rm|mv|checkout)\s+([^\s-][^\s]*)`), 1},
-	}
-
-	for _, pattern := range patterns {
-		if matches := pattern.regex.FindAllStringSubmatch(cmd, -1); matches != nil {
-			for _, match := range matches {
-				if len(match) > pattern.group {
-					file := match[pattern.group]
-					if file != "" && !strings.HasPrefix(file, "-") && !strings.HasPrefix(file, "'") && !strings.HasPrefix(file, "\"") {
-						files = append(files, file)
-					}
-				}
-			}
+// annotations loads the annotations of the given events. A failure only
+// costs the summary some context, so it is logged rather than returned.
+func (p *Plugin) annotations(ctx context.Context, eventLists ...[]*events.Event) map[string][]storage.Annotation {
+	var ids []string
+	for _, evts := range eventLists {
+		for _, evt := range evts {
+			ids = append(ids, evt.ID)
 		}
 	}
-
-	return files
+	annotations, err := p.storage.AnnotationsContext(ctx, ids)
+	if err != nil {
+		p.logger.Warn("failed to load annotations", slog.String("error", err.Error()))
+		return nil
+	}
+	return annotations
 }
 
 func FormatEvent(evt *events.Event) string {
@@ -376,17 +713,34 @@ func FormatEvent(evt *events.Event) string {
 		line += fmt.Sprintf(" (workdir: %s)", workdir)
 	}
 
+	if evt.Source == string(events.SourceActivity) {
+		return line + formatPresence(evt)
+	}
+
+	if evt.Source == string(events.SourceManual) && evt.Type == string(events.TypeNote) {
+		if text, ok := evt.Payload["text"].(string); ok && text != "" {
+			line += fmt.Sprintf(": %s", text)
+		}
+		if tags := evt.Tags(); len(tags) > 0 {
+			line += fmt.Sprintf(" [tags: %s]", strings.Join(tags, ", "))
+		}
+		return line
+	}
+
 	if summary, ok := evt.Payload["summary"].(string); ok && summary != "" {
 		if len(summary) > 200 {
 			summary = summary[:200] + "..."
 		}
 		line += fmt.Sprintf(": %s", summary)
+		if evt.Source == string(events.SourceClaude) {
+			line += formatClaudeActivity(evt)
+		}
 	} else if msg, ok := evt.Payload["message"].(string); ok && msg != "" {
 		line += fmt.Sprintf(": %s", msg)
 	} else if cmd, ok := evt.Payload["command"].(string); ok && cmd != "" {
 		line += fmt.Sprintf(": %s", cmd)
 
-		files := extractFilesFromCommand(cmd)
+		files := events.ExtractFilesFromCommand(cmd)
 		if len(files) > 0 {
 			line += fmt.Sprintf(" [files: %s]", strings.Join(files, ", "))
 		}
@@ -397,6 +751,53 @@ func FormatEvent(evt *events.Event) string {
 		line += fmt.Sprintf(": %s", text)
 	}
 
+	if env, ok := evt.Payload["environment"].(string); ok && env != "" {
+		line += fmt.Sprintf(" [env: %s]", env)
+	}
+	if danger, ok := evt.Payload["danger_level"].(string); ok && danger != "" {
+		line += fmt.Sprintf(" [danger: %s]", danger)
+	}
+
+	return line
+}
+
+// formatPresence states how long the developer was active or away, so the
+// summary can tell real idle gaps from quiet working time.
+func formatPresence(evt *events.Event) string {
+	switch evt.Type {
+	case string(events.TypeSessionStart):
+		if away, ok := evt.Payload["away_seconds"].(float64); ok {
+			return fmt.Sprintf(": back at the keyboard after %d minutes away", int(away)/60)
+		}
+		return ": back at the keyboard"
+	case string(events.TypeSessionEnd):
+		if active, ok := evt.Payload["duration_seconds"].(float64); ok {
+			return fmt.Sprintf(": went idle after %d minutes active", int(active)/60)
+		}
+		return ": went idle"
+	}
+	return ""
+}
+
+// formatClaudeActivity lists the files and commands behind a Claude
+// conversation so the summary can cite them.
+func formatClaudeActivity(evt *events.Event) string {
+	var line string
+	if files := evt.StringList("files_touched"); len(files) > 0 {
+		if len(files) > 10 {
+			files = append(files[:10:10], fmt.Sprintf("+%d more", len(files)-10))
+		}
+		line += fmt.Sprintf(" [files: %s]", strings.Join(files, ", "))
+	}
+	if cmds := evt.StringList("commands"); len(cmds) > 0 {
+		if len(cmds) > 5 {
+			cmds = append(cmds[:5:5], fmt.Sprintf("+%d more", len(cmds)-5))
+		}
+		line += fmt.Sprintf(" [ran: %s]", strings.Join(cmds, "; "))
+	}
+	if testsRun, ok := evt.Payload["tests_run"]; ok {
+		line += fmt.Sprintf(" [tests: %v runs, %v failed]", testsRun, evt.Payload["tests_failed"])
+	}
 	return line
 }
 
@@ -674,6 +1075,54 @@ func (p *Plugin) buildInactivePeriodSection(focusStart, focusEnd time.Time) stri
 		durationStr)
 }
 
+// SetPrompt selects the summary prompt: "concise", "detailed" or
+// "custom:<path>". An empty selection uses DefaultPrompt.
+func (p *Plugin) SetPrompt(selection string) error {
+	if selection == "" {
+		selection = DefaultPrompt
+	}
+	tmpl, err := prompts.Load("summarizer", selection)
+	if err != nil {
+		return err
+	}
+	p.prompt = tmpl
+	p.promptName = selection
+	p.rules = formatRules(selection, p.validation)
+	return nil
+}
+
+// SetValidation changes the format checks made on each summary.
+func (p *Plugin) SetValidation(cfg ValidationConfig) {
+	p.validation = cfg
+	p.rules = formatRules(p.promptName, cfg)
+}
+
+// SetPerRepo makes each period get one summary per active repo instead of
+// one for all of its events.
+func (p *Plugin) SetPerRepo(perRepo bool) {
+	p.perRepo = perRepo
+}
+
+// SetMaxPromptTokens caps the size of each summary prompt; larger focus
+// windows are summarized in chunks. Zero derives the cap from the model.
+func (p *Plugin) SetMaxPromptTokens(tokens int) {
+	p.maxPromptTokens = tokens
+}
+
+// SetStream makes summary generation copy the LLM's answer to w as it
+// arrives, for commands that summarize in the foreground. A nil writer turns
+// it off.
+func (p *Plugin) SetStream(w io.Writer) {
+	p.stream = w
+}
+
+func (p *Plugin) complete(ctx context.Context, prompt string) (*llm.Completion, error) {
+	if p.stream == nil {
+		return llm.CompleteWithUsage(ctx, p.llmClient, prompt)
+	}
+	return llm.StreamTo(ctx, p.llmClient, prompt, p.stream)
+}
+
 func (p *Plugin) saveSummary(summary string, focusStart, focusEnd time.Time, contextEvents, focusEvents []*events.Event) error {
 	dataDir, err := config.DataDir()
 	if err != nil {
@@ -688,13 +1137,23 @@ func (p *Plugin) saveSummary(summary string, focusStart, focusEnd time.Time, con
 	filename := fmt.Sprintf("summary_%s.md", focusStart.Format("2006-01-02"))
 	path := filepath.Join(summariesDir, filename)
 
+	var section string
 	if len(focusEvents) == 0 {
+		section = p.buildInactivePeriodSection(focusStart, focusEnd)
+	} else {
+		section = p.buildMarkdownSection(summary, focusStart, focusEnd, contextEvents, focusEvents)
+	}
+
+	inserted, err := insertBeforeLaterSection(path, section, focusStart)
+	switch {
+	case err != nil:
+		return err
+	case inserted:
+	case len(focusEvents) == 0:
 		if err := p.updateOrCreateInactivePeriod(path, focusStart, focusEnd); err != nil {
 			return err
 		}
-	} else {
-		section := p.buildMarkdownSection(summary, focusStart, focusEnd, contextEvents, focusEvents)
-
+	default:
 		if _, err := os.Stat(path); os.IsNotExist(err) {
 			header := fmt.Sprintf("# Development Summary - %s\n\n", focusStart.Format("January 2, 2006"))
 			section = header + section
@@ -719,7 +1178,34 @@ func (p *Plugin) saveSummary(summary string, focusStart, focusEnd time.Time, con
 	return nil
 }
 
-func NewForPoll(llmClient llm.Client, store *storage.Storage, interval, contextWindow time.Duration, excludeSources []string) *Plugin {
+// commitJournal mirrors the day's summary file into the journal repository.
+// Failures are logged rather than returned: the summary itself is already
+// saved and the next period retries the commit.
+func (p *Plugin) commitJournal(ctx context.Context, focusStart, focusEnd time.Time) {
+	if p.journal == nil {
+		return
+	}
+	path, err := summaryPath(focusStart)
+	if err != nil {
+		p.logger.Warn("journal commit failed", slog.String("error", err.Error()))
+		return
+	}
+	committed, err := p.journal.record(ctx, path, focusStart, focusEnd)
+	if err != nil {
+		p.logger.Warn("journal commit failed",
+			slog.String("journal", p.journal.dir),
+			slog.Bool("committed", committed),
+			slog.String("error", err.Error()))
+		return
+	}
+	if committed {
+		p.logger.Debug("journal committed",
+			slog.String("journal", p.journal.dir),
+			slog.String("message", journalCommitMessage(focusStart, focusEnd)))
+	}
+}
+
+func NewForPoll(llmClient llm.Client, store storage.Store, interval, contextWindow time.Duration, excludeSources []string) *Plugin {
 	excludeMap := make(map[string]bool)
 	for _, source := range excludeSources {
 		excludeMap[source] = true
@@ -730,6 +1216,7 @@ func NewForPoll(llmClient llm.Client, store *storage.Storage, interval, contextW
 		interval:       interval,
 		contextWindow:  contextWindow,
 		excludeSources: excludeMap,
+		rules:          formatRules(DefaultPrompt, ValidationConfig{}),
 		logger:         logger.Default(),
 	}
 }
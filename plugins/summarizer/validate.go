@@ -0,0 +1,316 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"devlog/internal/llm"
+	"devlog/internal/metrics"
+	"devlog/internal/prompts"
+)
+
+const (
+	// DefaultMaxRetries is how many times a summary that breaks the format
+	// is sent back to the model.
+	DefaultMaxRetries = 2
+
+	contextPrefix = "Working on:"
+)
+
+// defaultBannedPhrases are the hedging and filler phrases the built-in
+// prompts forbid in bullets.
+var defaultBannedPhrases = []string{
+	"the user",
+	"appears to",
+	"seems",
+	"probably",
+	"likely",
+	"worked on",
+	"focused on",
+	"spent time",
+	"continued to",
+	"made changes",
+	"updated files",
+	"ran commands",
+}
+
+// ValidationConfig overrides the format checks made on each summary. Zero
+// values keep the defaults of the selected prompt.
+type ValidationConfig struct {
+	Enabled         *bool    `json:"enabled,omitempty"`
+	MaxRetries      *int     `json:"max_retries,omitempty"`
+	MaxContextChars int      `json:"max_context_chars,omitempty"`
+	MinBullets      int      `json:"min_bullets,omitempty"`
+	MaxBullets      int      `json:"max_bullets,omitempty"`
+	BannedPhrases   []string `json:"banned_phrases,omitempty"`
+}
+
+// ParseValidationConfig reads the summarizer's validation option.
+func ParseValidationConfig(val interface{}) (ValidationConfig, error) {
+	var cfg ValidationConfig
+	data, err := json.Marshal(val)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("must be an object with enabled, max_retries, max_context_chars, min_bullets, max_bullets or banned_phrases")
+	}
+	if cfg.MaxRetries != nil && (*cfg.MaxRetries < 0 || *cfg.MaxRetries > 5) {
+		return cfg, fmt.Errorf("max_retries must be between 0 and 5")
+	}
+	if cfg.MaxContextChars < 0 || cfg.MinBullets < 0 || cfg.MaxBullets < 0 {
+		return cfg, fmt.Errorf("limits must not be negative")
+	}
+	if cfg.MaxBullets > 0 && cfg.MinBullets > cfg.MaxBullets {
+		return cfg, fmt.Errorf("min_bullets must not exceed max_bullets")
+	}
+	return cfg, nil
+}
+
+// FormatRules is the output format a summary is checked against: a
+// "Working on:" context line followed by bullets.
+type FormatRules struct {
+	MaxRetries      int
+	MaxContextChars int
+	MinBullets      int
+	MaxBullets      int
+	BannedPhrases   []string
+
+	banned []*regexp.Regexp
+}
+
+// Violation is one way a summary breaks its FormatRules.
+type Violation struct {
+	Rule   string
+	Detail string
+}
+
+// formatRules returns the rules for a prompt selection with cfg applied, or
+// nil when summaries are not checked. The built-in prompts are checked by
+// default; custom prompts have their own format and are only checked when
+// enabled explicitly.
+func formatRules(selection string, cfg ValidationConfig) *FormatRules {
+	rules := &FormatRules{
+		MaxRetries:      DefaultMaxRetries,
+		MaxContextChars: 80,
+		MinBullets:      2,
+		MaxBullets:      4,
+		BannedPhrases:   defaultBannedPhrases,
+	}
+	if selection == "" {
+		selection = DefaultPrompt
+	}
+	enabled := selection == prompts.Concise || selection == prompts.Detailed
+	if selection == prompts.Concise {
+		rules.MinBullets, rules.MaxBullets = 1, 2
+	}
+
+	if cfg.Enabled != nil {
+		enabled = *cfg.Enabled
+	}
+	if !enabled {
+		return nil
+	}
+	if cfg.MaxRetries != nil {
+		rules.MaxRetries = *cfg.MaxRetries
+	}
+	if cfg.MaxContextChars > 0 {
+		rules.MaxContextChars = cfg.MaxContextChars
+	}
+	if cfg.MinBullets > 0 {
+		rules.MinBullets = cfg.MinBullets
+	}
+	if cfg.MaxBullets > 0 {
+		rules.MaxBullets = cfg.MaxBullets
+	}
+	if cfg.BannedPhrases != nil {
+		rules.BannedPhrases = cfg.BannedPhrases
+	}
+
+	for _, phrase := range rules.BannedPhrases {
+		rules.banned = append(rules.banned, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(phrase)+`\b`))
+	}
+	return rules
+}
+
+// Check returns the ways summary breaks the rules.
+func (r *FormatRules) Check(summary string) []Violation {
+	var (
+		violations  []Violation
+		contextLine string
+		bullets     []string
+		stray       int
+	)
+	for _, line := range strings.Split(strings.TrimSpace(summary), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case isBullet(line):
+			bullets = append(bullets, line)
+		case contextLine == "" && len(bullets) == 0:
+			contextLine = line
+		default:
+			stray++
+		}
+	}
+
+	switch {
+	case contextLine == "":
+		violations = append(violations, Violation{"context_line", fmt.Sprintf("the summary must start with a %q line", contextPrefix)})
+	case !strings.HasPrefix(contextLine, contextPrefix):
+		violations = append(violations, Violation{"context_line", fmt.Sprintf("the first line must start with %q, got %q", contextPrefix, truncate(contextLine, 40))})
+	case strings.Contains(contextLine, "*"):
+		violations = append(violations, Violation{"context_line", "the context line must not use asterisks or markdown"})
+	case utf8.RuneCountInString(contextLine) > r.MaxContextChars:
+		violations = append(violations, Violation{"context_line", fmt.Sprintf("the context line is %d characters, the limit is %d", utf8.RuneCountInString(contextLine), r.MaxContextChars)})
+	}
+
+	if n := len(bullets); n < r.MinBullets || n > r.MaxBullets {
+		violations = append(violations, Violation{"bullets", fmt.Sprintf("there must be %s, got %d", bulletRange(r.MinBullets, r.MaxBullets), n)})
+	}
+	if stray > 0 {
+		violations = append(violations, Violation{"stray_text", "there must be no text besides the context line and the bullets"})
+	}
+
+	for i, pattern := range r.banned {
+		for _, bullet := range bullets {
+			if pattern.MatchString(bullet) {
+				violations = append(violations, Violation{"banned_phrase", fmt.Sprintf("bullets must not use %q", r.BannedPhrases[i])})
+				break
+			}
+		}
+	}
+	return violations
+}
+
+func isBullet(line string) bool {
+	return strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ") || strings.HasPrefix(line, "• ")
+}
+
+func bulletRange(lo, hi int) string {
+	if lo == hi {
+		return fmt.Sprintf("exactly %d bullets", lo)
+	}
+	return fmt.Sprintf("%d to %d bullets", lo, hi)
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
+
+// repairPrompt sends a summary that broke the format back to the model
+// with the rules it broke. The summary is model output built from
+// untrusted events, so it is sanitized and fenced like event text.
+func repairPrompt(prompt, summary string, violations []Violation) string {
+	fence := llm.NewFence("PREVIOUS ANSWER")
+	var answer strings.Builder
+	for _, line := range strings.Split(summary, "\n") {
+		answer.WriteString(llm.SanitizeUntrusted(line, llm.DefaultMaxEventChars) + "\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	sb.WriteString("\n\n==================== CORRECTION ====================\n\n")
+	sb.WriteString(llm.FenceNotice(fence))
+	sb.WriteString("\n\nYour previous answer was:\n")
+	sb.WriteString(fence.Wrap(answer.String()))
+	sb.WriteString("\n\nIt broke these rules:\n")
+	for _, v := range violations {
+		sb.WriteString("- " + v.Detail + "\n")
+	}
+	sb.WriteString("\nWrite the summary again, fixing every problem above. Output only the summary.")
+	return sb.String()
+}
+
+// finishSummary makes the call that produces a period's summary, streamed
+// when a stream is set, and returns it with the usage of every call in
+// total. A summary that breaks the format rules is sent back to the model
+// up to MaxRetries times; if none passes, the one with the fewest
+// violations is kept.
+func (p *Plugin) finishSummary(ctx context.Context, prompt string, total *llm.Completion) (string, *llm.Completion, error) {
+	ctx = llm.WithCaller(ctx, "summarizer")
+	rules := p.rules
+
+	var (
+		best          string
+		bestViolation []Violation
+		attempt       = prompt
+		retries       int
+	)
+	for {
+		completion, err := p.complete(ctx, attempt)
+		if err != nil {
+			return "", nil, fmt.Errorf("generate summary: %w", err)
+		}
+		addUsage(total, completion)
+		total.Provider = completion.Provider
+		total.Model = completion.Model
+
+		summary := strings.TrimSpace(completion.Text)
+		if rules == nil {
+			best, bestViolation = summary, nil
+			break
+		}
+
+		violations := rules.Check(summary)
+		if summary == "" {
+			violations = []Violation{{"empty", "the answer was empty"}}
+		}
+		if best == "" || len(violations) < len(bestViolation) {
+			best, bestViolation = summary, violations
+		}
+		if len(violations) == 0 || retries >= rules.MaxRetries {
+			break
+		}
+
+		retries++
+		rulesBroken := violationRules(violations)
+		p.logger.Info("summary broke the required format, asking again",
+			slog.String("rules", strings.Join(rulesBroken, ",")),
+			slog.Int("retry", retries))
+		if p.stream != nil {
+			fmt.Fprintf(p.stream, "\n\n[summary broke the format (%s); regenerating]\n\n", strings.Join(rulesBroken, ", "))
+		}
+		attempt = repairPrompt(prompt, summary, violations)
+	}
+
+	if rules != nil {
+		outcome := metrics.SummaryValid
+		switch {
+		case len(bestViolation) > 0:
+			outcome = metrics.SummaryInvalid
+			p.logger.Warn("summary still breaks the required format, keeping the closest answer",
+				slog.String("rules", strings.Join(violationRules(bestViolation), ",")),
+				slog.Int("retries", retries))
+		case retries > 0:
+			outcome = metrics.SummaryRepaired
+		}
+		metrics.RecordSummaryQuality(outcome, retries, violationRules(bestViolation))
+	}
+
+	if best == "" {
+		return "", nil, fmt.Errorf("empty summary from LLM")
+	}
+	total.Text = best
+	return best, total, nil
+}
+
+func violationRules(violations []Violation) []string {
+	seen := make(map[string]bool)
+	var rules []string
+	for _, v := range violations {
+		if !seen[v.Rule] {
+			seen[v.Rule] = true
+			rules = append(rules, v.Rule)
+		}
+	}
+	return rules
+}
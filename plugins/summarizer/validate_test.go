@@ -0,0 +1,119 @@
+package summarizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"devlog/internal/llm"
+	"devlog/internal/metrics"
+	"devlog/internal/prompts"
+)
+
+func TestFormatRulesCheck(t *testing.T) {
+	rules := formatRules(prompts.Detailed, ValidationConfig{})
+	tests := []struct {
+		name    string
+		summary string
+		want    []string
+	}{
+		{"valid", "Working on: devlog (main)\n\n- Fixed the storage test\n- Added chunking", nil},
+		{"preamble", "Here is the summary:\n\n- Fixed the storage test\n- Added chunking", []string{"context_line"}},
+		{"long context", "Working on: " + strings.Repeat("x", 80) + "\n\n- Fixed a\n- Fixed b", []string{"context_line"}},
+		{"markdown", "Working on: **devlog** (main)\n\n- Fixed a\n- Fixed b", []string{"context_line"}},
+		{"one bullet", "Working on: devlog (main)\n\n- Fixed a", []string{"bullets"}},
+		{"five bullets", "Working on: devlog (main)\n- a\n- b\n- c\n- d\n- e", []string{"bullets"}},
+		{"trailing note", "Working on: devlog (main)\n\n- Fixed a\n- Fixed b\n\nLet me know if you need more.", []string{"stray_text"}},
+		{"hedging", "Working on: devlog (main)\n\n- Probably fixed a\n- Worked on b", []string{"banned_phrase", "banned_phrase"}},
+		{"banned phrase in a word", "Working on: devlog (main)\n\n- Fixed unlikely race\n- Fixed b", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range rules.Check(tt.summary) {
+				got = append(got, v.Rule)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRulesFor(t *testing.T) {
+	if rules := formatRules(prompts.Concise, ValidationConfig{}); rules.MinBullets != 1 || rules.MaxBullets != 2 {
+		t.Errorf("concise rules allow %d-%d bullets, want 1-2", rules.MinBullets, rules.MaxBullets)
+	}
+	if rules := formatRules("custom:mine.tmpl", ValidationConfig{}); rules != nil {
+		t.Error("custom prompts should not be checked unless enabled")
+	}
+	enabled, retries := true, 0
+	rules := formatRules("custom:mine.tmpl", ValidationConfig{Enabled: &enabled, MaxRetries: &retries, MaxBullets: 6})
+	if rules == nil || rules.MaxRetries != 0 || rules.MaxBullets != 6 {
+		t.Errorf("overrides not applied: %+v", rules)
+	}
+}
+
+type scriptedClient struct {
+	answers []string
+	prompts []string
+}
+
+func (c *scriptedClient) Complete(ctx context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	answer := c.answers[0]
+	if len(c.answers) > 1 {
+		c.answers = c.answers[1:]
+	}
+	return answer, nil
+}
+
+func TestFinishSummaryRetries(t *testing.T) {
+	valid := "Working on: devlog (main)\n\n- Fixed the storage test\n- Added chunking"
+	invalid := "Sure! Here's the summary.\n\n- Seems like some work happened"
+
+	t.Run("repaired", func(t *testing.T) {
+		before := metrics.GlobalSnapshot.Copy().SummaryQuality
+		client := &scriptedClient{answers: []string{invalid, valid}}
+		p := NewForPoll(client, nil, 0, 0, nil)
+
+		summary, _, err := p.finishSummary(context.Background(), "PROMPT", &llm.Completion{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if summary != valid {
+			t.Errorf("summary = %q, want the corrected answer", summary)
+		}
+		if len(client.prompts) != 2 {
+			t.Fatalf("made %d calls, want a retry", len(client.prompts))
+		}
+		retry := client.prompts[1]
+		if !strings.HasPrefix(retry, "PROMPT") || !strings.Contains(retry, "Seems like some work happened") || !strings.Contains(retry, `must not use "seems"`) {
+			t.Errorf("retry prompt lacks the original prompt, answer or broken rules:\n%s", retry)
+		}
+		if after := metrics.GlobalSnapshot.Copy().SummaryQuality; after.Repaired != before.Repaired+1 {
+			t.Errorf("repaired count = %d, want %d", after.Repaired, before.Repaired+1)
+		}
+	})
+
+	t.Run("gives up", func(t *testing.T) {
+		before := metrics.GlobalSnapshot.Copy().SummaryQuality
+		client := &scriptedClient{answers: []string{invalid, "Working on: devlog\n\n- Fixed a"}}
+		p := NewForPoll(client, nil, 0, 0, nil)
+
+		summary, _, err := p.finishSummary(context.Background(), "PROMPT", &llm.Completion{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(client.prompts) != 1+DefaultMaxRetries {
+			t.Errorf("made %d calls, want %d", len(client.prompts), 1+DefaultMaxRetries)
+		}
+		if summary != "Working on: devlog\n\n- Fixed a" {
+			t.Errorf("summary = %q, want the answer with the fewest violations", summary)
+		}
+		after := metrics.GlobalSnapshot.Copy().SummaryQuality
+		if after.Invalid != before.Invalid+1 || after.Violations["bullets"] != before.Violations["bullets"]+1 {
+			t.Errorf("quality = %+v, want one more invalid summary with a bullets violation", after)
+		}
+	})
+}
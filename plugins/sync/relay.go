@@ -0,0 +1,143 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"devlog/plugins/archiver"
+)
+
+// webdavClient stores relay objects on any WebDAV server (Nextcloud,
+// rclone serve webdav, Apache mod_dav, ...). It satisfies the same
+// ObjectStore interface as the archiver's S3 client.
+type webdavClient struct {
+	base     *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+func NewWebDAVClient(rawURL, username, password string) (archiver.ObjectStore, error) {
+	base, err := url.Parse(strings.TrimSuffix(rawURL, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid webdav url %q", rawURL)
+	}
+	if base.Scheme != "https" && base.Scheme != "http" {
+		return nil, fmt.Errorf("webdav url must be http or https")
+	}
+
+	return &webdavClient{
+		base:     base,
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: 120 * time.Second},
+	}, nil
+}
+
+func (c *webdavClient) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	status, body, err := c.do(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		return fmt.Errorf("put %s: %w", key, err)
+	}
+
+	// Servers answer 404 or 409 when the parent collection is missing.
+	if status == http.StatusNotFound || status == http.StatusConflict {
+		if err := c.mkcolAll(ctx, path.Dir(key)); err != nil {
+			return fmt.Errorf("put %s: %w", key, err)
+		}
+		status, body, err = c.do(ctx, http.MethodPut, key, data, contentType)
+		if err != nil {
+			return fmt.Errorf("put %s: %w", key, err)
+		}
+	}
+
+	if status != http.StatusOK && status != http.StatusCreated && status != http.StatusNoContent {
+		return fmt.Errorf("put %s: status %d: %s", key, status, body)
+	}
+	return nil
+}
+
+func (c *webdavClient) Get(ctx context.Context, key string) ([]byte, error) {
+	status, body, err := c.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	if status == http.StatusNotFound {
+		return nil, archiver.ErrObjectNotFound
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("get %s: status %d: %s", key, status, body)
+	}
+	return body, nil
+}
+
+func (c *webdavClient) Delete(ctx context.Context, key string) error {
+	status, body, err := c.do(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		return fmt.Errorf("delete %s: %w", key, err)
+	}
+	if status != http.StatusNoContent && status != http.StatusOK && status != http.StatusNotFound {
+		return fmt.Errorf("delete %s: status %d: %s", key, status, body)
+	}
+	return nil
+}
+
+func (c *webdavClient) mkcolAll(ctx context.Context, dir string) error {
+	var current string
+	for _, part := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if part == "" || part == "." {
+			continue
+		}
+		current = path.Join(current, part)
+
+		status, body, err := c.do(ctx, "MKCOL", current+"/", nil, "")
+		if err != nil {
+			return fmt.Errorf("mkcol %s: %w", current, err)
+		}
+		// 405 means the collection already exists.
+		if status != http.StatusCreated && status != http.StatusMethodNotAllowed && status != http.StatusOK {
+			return fmt.Errorf("mkcol %s: status %d: %s", current, status, body)
+		}
+	}
+	return nil
+}
+
+func (c *webdavClient) do(ctx context.Context, method, key string, data []byte, contentType string) (int, []byte, error) {
+	u := *c.base
+	u.Path = c.base.Path + "/" + strings.TrimPrefix(key, "/")
+
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("create request: %w", err)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, fmt.Errorf("read response: %w", err)
+	}
+	return resp.StatusCode, respBody, nil
+}
@@ -0,0 +1,336 @@
+package sync
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/encryption"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/storage"
+	"devlog/plugins/archiver"
+)
+
+const (
+	RelayS3     = "s3"
+	RelayWebDAV = "webdav"
+)
+
+var machineIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+type Plugin struct {
+	syncer   *Syncer
+	storage  *storage.Storage
+	interval time.Duration
+	logger   *logger.Logger
+}
+
+type Config struct {
+	MachineID       string   `json:"machine_id,omitempty"`
+	Peers           []string `json:"peers"`
+	Key             string   `json:"key"`
+	IntervalSeconds int      `json:"interval_seconds"`
+	BatchSize       int      `json:"batch_size,omitempty"`
+	Prefix          string   `json:"prefix,omitempty"`
+	Relay           string   `json:"relay"`
+
+	// S3 relay
+	Bucket          string `json:"bucket,omitempty"`
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	UsePathStyle    bool   `json:"use_path_style,omitempty"`
+
+	// WebDAV relay
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "sync"
+}
+
+func (p *Plugin) Description() string {
+	return "Replicates events between machines through an end-to-end encrypted relay"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "sync",
+		Description:  "Replicates events between machines through an end-to-end encrypted relay",
+		Dependencies: []string{},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Sync plugin")
+	if key, err := GenerateKey(); err == nil {
+		ctx.Log("New sync key (on your other machines, reuse the first machine's key instead): %s", key)
+	}
+	ctx.Log("Configure the relay (s3 or webdav) and list the other machines under peers")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Sync plugin")
+	ctx.Log("Objects already pushed are left on the relay")
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		MachineID:       DefaultMachineID(),
+		IntervalSeconds: 300,
+		BatchSize:       1000,
+		Prefix:          "devlog-sync",
+		Relay:           RelayS3,
+		Region:          "us-east-1",
+	}
+}
+
+func (p *Plugin) ValidateConfig(cfg interface{}) error {
+	cfgMap, ok := cfg.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	c, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+	return c.Validate()
+}
+
+func (c *Config) Validate() error {
+	if _, err := DecodeKey(c.Key); err != nil {
+		return errors.NewValidation("key", err.Error())
+	}
+
+	machine := c.machineID()
+	if !machineIDPattern.MatchString(machine) {
+		return errors.NewValidation("machine_id", "may only contain letters, digits, '.', '_' and '-'")
+	}
+	if len(c.Peers) == 0 {
+		return errors.NewValidation("peers", "list at least one other machine")
+	}
+	for _, peer := range c.Peers {
+		if !machineIDPattern.MatchString(peer) {
+			return errors.NewValidation("peers", fmt.Sprintf("invalid machine id %q", peer))
+		}
+		if peer == machine {
+			return errors.NewValidation("peers", "must not include this machine's own machine_id")
+		}
+	}
+
+	if c.IntervalSeconds < 60 || c.IntervalSeconds > 86400 {
+		return errors.NewValidation("interval_seconds", "must be between 60 and 86400")
+	}
+	if c.BatchSize != 0 && (c.BatchSize < 1 || c.BatchSize > 10000) {
+		return errors.NewValidation("batch_size", "must be between 1 and 10000")
+	}
+
+	switch c.Relay {
+	case RelayS3:
+		if c.Bucket == "" {
+			return errors.NewValidation("bucket", "is required for the s3 relay")
+		}
+		if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+			return errors.NewValidation("access_key_id", "and secret_access_key are required for the s3 relay")
+		}
+	case RelayWebDAV:
+		if c.URL == "" {
+			return errors.NewValidation("url", "is required for the webdav relay")
+		}
+	default:
+		return errors.NewValidation("relay", fmt.Sprintf("must be %q or %q", RelayS3, RelayWebDAV))
+	}
+
+	return nil
+}
+
+func (c *Config) machineID() string {
+	if c.MachineID != "" {
+		return c.MachineID
+	}
+	return DefaultMachineID()
+}
+
+// DefaultMachineID is the short hostname, which is what peers list unless
+// machine_id is set explicitly.
+func DefaultMachineID() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "devlog"
+	}
+	host, _, _ = strings.Cut(host, ".")
+	return host
+}
+
+// GenerateKey returns a new random sync key in the form DecodeKey accepts.
+func GenerateKey() (string, error) {
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// DecodeKey parses the base64 sync key printed by 'devlog sync keygen'.
+func DecodeKey(s string) ([]byte, error) {
+	if s == "" {
+		return nil, fmt.Errorf("is required (generate one with 'devlog sync keygen')")
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(key) != encryption.KeySize {
+		return nil, fmt.Errorf("must be %d bytes, base64 encoded", encryption.KeySize)
+	}
+	return key, nil
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+func NewFromConfig(cfg *Config, store *storage.Storage) (*Syncer, error) {
+	key, err := DecodeKey(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("key %w", err)
+	}
+	cipher, err := encryption.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects archiver.ObjectStore
+	switch cfg.Relay {
+	case RelayWebDAV:
+		objects, err = NewWebDAVClient(cfg.URL, cfg.Username, cfg.Password)
+	default:
+		objects, err = archiver.NewS3Client(archiver.S3Config{
+			Endpoint:        cfg.Endpoint,
+			Region:          cfg.Region,
+			Bucket:          cfg.Bucket,
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			UsePathStyle:    cfg.UsePathStyle,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create %s relay: %w", cfg.Relay, err)
+	}
+
+	return NewSyncer(store, objects, cipher, cfg.machineID(), cfg.Peers, cfg.Prefix, cfg.BatchSize), nil
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("sync", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("sync", "parse config", err)
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("sync", "get data dir", err)
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("sync", "open storage", err)
+	}
+	p.storage = store
+
+	syncer, err := NewFromConfig(cfg, store)
+	if err != nil {
+		store.Close()
+		return errors.WrapPlugin("sync", "create syncer", err)
+	}
+	p.syncer = syncer
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+
+	p.run(ctx)
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context) {
+	p.logger.Info("sync started",
+		slog.String("machine", p.syncer.Machine()),
+		slog.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.sync(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("sync stopped")
+			if p.storage != nil {
+				p.storage.Close()
+			}
+			return
+		case <-ticker.C:
+			p.sync(ctx)
+		}
+	}
+}
+
+func (p *Plugin) sync(ctx context.Context) {
+	timer := metrics.StartPluginTimer("sync")
+	defer timer.Stop()
+
+	pushed, err := p.syncer.Push(ctx)
+	if err != nil && ctx.Err() == nil {
+		p.logger.Error("sync push failed", slog.String("error", err.Error()))
+	}
+
+	pulled, err := p.syncer.Pull(ctx)
+	if err != nil && ctx.Err() == nil {
+		p.logger.Error("sync pull failed", slog.String("error", err.Error()))
+	}
+
+	if pushed.Events > 0 || pulled.Events > 0 {
+		p.logger.Info("synced events",
+			slog.Int("pushed", pushed.Events),
+			slog.Int("pulled", pulled.Events),
+			slog.Int("duplicates", pulled.Duplicates))
+	}
+}
@@ -0,0 +1,257 @@
+package sync
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	gosync "sync"
+	"testing"
+
+	"devlog/internal/encryption"
+	"devlog/internal/events"
+	"devlog/internal/storage"
+	"devlog/internal/testutil"
+	"devlog/plugins/archiver"
+)
+
+type memoryStore struct {
+	mu      gosync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{objects: make(map[string][]byte)}
+}
+
+func (m *memoryStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, archiver.ErrObjectNotFound
+	}
+	return data, nil
+}
+
+func newTestCipher(t *testing.T) *encryption.Cipher {
+	t.Helper()
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := encryption.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cipher
+}
+
+func insertEvents(t *testing.T, store *storage.Storage, text string, n int) []*events.Event {
+	t.Helper()
+	evts := make([]*events.Event, n)
+	for i := range evts {
+		evts[i] = testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+		evts[i].Payload["command"] = text
+	}
+	testutil.MustInsertEvents(t, store, evts...)
+	return evts
+}
+
+func TestSyncBetweenMachines(t *testing.T) {
+	relay := newMemoryStore()
+	cipher := newTestCipher(t)
+	ctx := context.Background()
+
+	laptopStore := testutil.NewTestStorage(t)
+	desktopStore := testutil.NewTestStorage(t)
+	laptop := NewSyncer(laptopStore, relay, cipher, "laptop", []string{"desktop"}, "sync", 2)
+	desktop := NewSyncer(desktopStore, relay, cipher, "desktop", []string{"laptop"}, "sync", 2)
+
+	insertEvents(t, laptopStore, "make test", 3)
+	insertEvents(t, desktopStore, "go build", 1)
+
+	pushed, err := laptop.Push(ctx)
+	if err != nil {
+		t.Fatalf("laptop Push() error: %v", err)
+	}
+	if pushed.Events != 3 || pushed.Segments != 2 {
+		t.Errorf("laptop pushed %+v, want 3 events in 2 segments", pushed)
+	}
+
+	for key, data := range relay.objects {
+		if bytes.Contains(data, []byte("make test")) || !encryption.IsEncrypted(string(data)) {
+			t.Errorf("relay object %s is not encrypted", key)
+		}
+	}
+
+	if _, err := desktop.Push(ctx); err != nil {
+		t.Fatalf("desktop Push() error: %v", err)
+	}
+	pulled, err := desktop.Pull(ctx)
+	if err != nil {
+		t.Fatalf("desktop Pull() error: %v", err)
+	}
+	if pulled.Events != 3 {
+		t.Errorf("desktop pulled %d events, want 3", pulled.Events)
+	}
+
+	count, err := desktopStore.Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("desktop has %d events, want 4", count)
+	}
+
+	// Pulled events must not be pushed back to the relay.
+	pushed, err = desktop.Push(ctx)
+	if err != nil {
+		t.Fatalf("desktop Push() error: %v", err)
+	}
+	if pushed.Events != 0 {
+		t.Errorf("desktop re-pushed %d pulled events", pushed.Events)
+	}
+
+	pulled, err = laptop.Pull(ctx)
+	if err != nil {
+		t.Fatalf("laptop Pull() error: %v", err)
+	}
+	if pulled.Events != 1 {
+		t.Errorf("laptop pulled %d events, want 1", pulled.Events)
+	}
+
+	pulled, err = desktop.Pull(ctx)
+	if err != nil {
+		t.Fatalf("second Pull() error: %v", err)
+	}
+	if pulled.Segments != 0 {
+		t.Errorf("second pull read %d segments, want 0", pulled.Segments)
+	}
+}
+
+func TestSyncWrongKey(t *testing.T) {
+	relay := newMemoryStore()
+	ctx := context.Background()
+
+	laptopStore := testutil.NewTestStorage(t)
+	insertEvents(t, laptopStore, "ls", 1)
+	laptop := NewSyncer(laptopStore, relay, newTestCipher(t), "laptop", []string{"desktop"}, "sync", 10)
+	if _, err := laptop.Push(ctx); err != nil {
+		t.Fatalf("Push() error: %v", err)
+	}
+
+	desktop := NewSyncer(testutil.NewTestStorage(t), relay, newTestCipher(t), "desktop", []string{"laptop"}, "sync", 10)
+	if _, err := desktop.Pull(ctx); err == nil || !strings.Contains(err.Error(), "wrong key") {
+		t.Errorf("Pull() with another key error = %v, want wrong key", err)
+	}
+}
+
+func TestWebDAVClient(t *testing.T) {
+	files := map[string][]byte{}
+	dirs := map[string]bool{"/dav": true}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		p := strings.TrimSuffix(r.URL.Path, "/")
+		parent := p[:strings.LastIndex(p, "/")]
+		switch r.Method {
+		case "MKCOL":
+			if dirs[p] {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			dirs[p] = true
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodPut:
+			if !dirs[parent] {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			files[p], _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet:
+			data, ok := files[p]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			if _, ok := files[p]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(files, p)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewWebDAVClient(server.URL+"/dav/", "me", "secret")
+	if err != nil {
+		t.Fatalf("NewWebDAVClient() error: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := client.Put(ctx, "sync/laptop/index.json.enc", []byte("hello"), ""); err != nil {
+		t.Fatalf("Put() error: %v", err)
+	}
+	data, err := client.Get(ctx, "sync/laptop/index.json.enc")
+	if err != nil || string(data) != "hello" {
+		t.Errorf("Get() = %q, %v", data, err)
+	}
+	if _, err := client.Get(ctx, "sync/desktop/index.json.enc"); err != archiver.ErrObjectNotFound {
+		t.Errorf("Get() missing object error = %v, want ErrObjectNotFound", err)
+	}
+
+	if err := client.(archiver.ObjectDeleter).Delete(ctx, "sync/laptop/index.json.enc"); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if _, err := client.Get(ctx, "sync/laptop/index.json.enc"); err != archiver.ErrObjectNotFound {
+		t.Errorf("Get() after Delete() error = %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	key, _ := encryption.GenerateKey()
+	valid := Config{
+		MachineID:       "laptop",
+		Peers:           []string{"desktop"},
+		Key:             base64.StdEncoding.EncodeToString(key),
+		IntervalSeconds: 300,
+		Relay:           RelayWebDAV,
+		URL:             "https://dav.example.com/devlog",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	tests := map[string]func(c *Config){
+		"missing key":     func(c *Config) { c.Key = "" },
+		"short key":       func(c *Config) { c.Key = base64.StdEncoding.EncodeToString([]byte("short")) },
+		"no peers":        func(c *Config) { c.Peers = nil },
+		"self as peer":    func(c *Config) { c.Peers = []string{"laptop"} },
+		"unknown relay":   func(c *Config) { c.Relay = "ftp" },
+		"s3 needs bucket": func(c *Config) { c.Relay = RelayS3 },
+	}
+	for name, mutate := range tests {
+		c := valid
+		mutate(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
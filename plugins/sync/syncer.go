@@ -0,0 +1,384 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"devlog/internal/encryption"
+	"devlog/internal/events"
+	"devlog/internal/storage"
+	"devlog/plugins/archiver"
+)
+
+const (
+	indexVersion     = 1
+	pushCursor       = "push"
+	pullCursorPrefix = "pull:"
+)
+
+// Index lists the segments one machine has pushed. Only that machine
+// writes it, so machines never contend for the same object.
+type Index struct {
+	Version  int       `json:"version"`
+	Machine  string    `json:"machine"`
+	Segments []Segment `json:"segments"`
+}
+
+type Segment struct {
+	Seq        int64  `json:"seq"`
+	Key        string `json:"key"`
+	EventCount int    `json:"event_count"`
+	SHA256     string `json:"sha256"`
+	CreatedAt  string `json:"created_at"`
+}
+
+type PushResult struct {
+	Segments int
+	Events   int
+}
+
+type PullResult struct {
+	Segments   int
+	Events     int
+	Duplicates int
+}
+
+// Syncer replicates events between machines through a relay that only ever
+// sees ciphertext: every object is gzipped JSONL sealed with the shared key.
+// Event IDs are UUIDs, so events from different machines never collide and
+// re-delivered events are skipped as duplicates.
+type Syncer struct {
+	store     *storage.Storage
+	objects   archiver.ObjectStore
+	cipher    *encryption.Cipher
+	machine   string
+	peers     []string
+	prefix    string
+	batchSize int
+	now       func() time.Time
+}
+
+func NewSyncer(store *storage.Storage, objects archiver.ObjectStore, cipher *encryption.Cipher, machine string, peers []string, prefix string, batchSize int) *Syncer {
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+	return &Syncer{
+		store:     store,
+		objects:   objects,
+		cipher:    cipher,
+		machine:   machine,
+		peers:     peers,
+		prefix:    prefix,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+func (s *Syncer) Machine() string {
+	return s.machine
+}
+
+func (s *Syncer) indexKey(machine string) string {
+	return path.Join(s.prefix, machine, "index.json.enc")
+}
+
+func (s *Syncer) LoadIndex(ctx context.Context, machine string) (*Index, error) {
+	data, err := s.objects.Get(ctx, s.indexKey(machine))
+	if err == archiver.ErrObjectNotFound {
+		return &Index{Version: indexVersion, Machine: machine}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load %s index: %w", machine, err)
+	}
+
+	plain, err := s.open(data)
+	if err != nil {
+		return nil, fmt.Errorf("open %s index: %w", machine, err)
+	}
+
+	var index Index
+	if err := json.Unmarshal(plain, &index); err != nil {
+		return nil, fmt.Errorf("parse %s index: %w", machine, err)
+	}
+	if index.Version > indexVersion {
+		return nil, fmt.Errorf("%s index version %d is newer than supported version %d", machine, index.Version, indexVersion)
+	}
+
+	sort.Slice(index.Segments, func(i, j int) bool {
+		return index.Segments[i].Seq < index.Segments[j].Seq
+	})
+	return &index, nil
+}
+
+func (s *Syncer) saveIndex(ctx context.Context, index *Index) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("marshal index: %w", err)
+	}
+	sealed, err := s.seal(data)
+	if err != nil {
+		return err
+	}
+	if err := s.objects.Put(ctx, s.indexKey(index.Machine), sealed, "application/octet-stream"); err != nil {
+		return fmt.Errorf("save index: %w", err)
+	}
+	return nil
+}
+
+// Push uploads events captured on this machine since the last push. Events
+// that were pulled from a peer are left out so they do not bounce back.
+func (s *Syncer) Push(ctx context.Context) (*PushResult, error) {
+	result := &PushResult{}
+
+	cursor, err := s.store.SyncCursorContext(ctx, pushCursor)
+	if err != nil {
+		return result, err
+	}
+
+	var index *Index
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		batch, lastRow, err := s.store.EventsAfterRowContext(ctx, cursor, s.batchSize)
+		if err != nil {
+			return result, fmt.Errorf("read events: %w", err)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		local, err := s.localEvents(ctx, batch)
+		if err != nil {
+			return result, err
+		}
+
+		if len(local) > 0 {
+			if index == nil {
+				if index, err = s.LoadIndex(ctx, s.machine); err != nil {
+					return result, err
+				}
+			}
+			if err := s.pushSegment(ctx, index, local); err != nil {
+				return result, err
+			}
+			result.Segments++
+			result.Events += len(local)
+		}
+
+		cursor = lastRow
+		if err := s.store.SetSyncCursorContext(ctx, pushCursor, cursor); err != nil {
+			return result, err
+		}
+
+		if len(batch) < s.batchSize {
+			return result, nil
+		}
+	}
+}
+
+func (s *Syncer) localEvents(ctx context.Context, batch []*events.Event) ([]*events.Event, error) {
+	ids := make([]string, len(batch))
+	for i, event := range batch {
+		ids[i] = event.ID
+	}
+	imported, err := s.store.SyncImportedContext(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	local := make([]*events.Event, 0, len(batch))
+	for _, event := range batch {
+		if !imported[event.ID] {
+			local = append(local, event)
+		}
+	}
+	return local, nil
+}
+
+func (s *Syncer) pushSegment(ctx context.Context, index *Index, evts []*events.Event) error {
+	data, err := archiver.EncodeEvents(evts)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.seal(data)
+	if err != nil {
+		return err
+	}
+
+	var seq int64 = 1
+	if n := len(index.Segments); n > 0 {
+		seq = index.Segments[n-1].Seq + 1
+	}
+	key := path.Join(s.prefix, s.machine, "segments", fmt.Sprintf("%010d.jsonl.gz.enc", seq))
+
+	if err := s.objects.Put(ctx, key, sealed, "application/octet-stream"); err != nil {
+		return fmt.Errorf("upload segment: %w", err)
+	}
+
+	index.Segments = append(index.Segments, Segment{
+		Seq:        seq,
+		Key:        key,
+		EventCount: len(evts),
+		SHA256:     sha256Hex(sealed),
+		CreatedAt:  s.now().UTC().Format(time.RFC3339),
+	})
+	return s.saveIndex(ctx, index)
+}
+
+// Pull imports every segment each peer pushed since the last pull.
+func (s *Syncer) Pull(ctx context.Context) (*PullResult, error) {
+	result := &PullResult{}
+	for _, peer := range s.peers {
+		if err := s.pullPeer(ctx, peer, result); err != nil {
+			return result, fmt.Errorf("pull from %s: %w", peer, err)
+		}
+	}
+	return result, nil
+}
+
+func (s *Syncer) pullPeer(ctx context.Context, peer string, result *PullResult) error {
+	index, err := s.LoadIndex(ctx, peer)
+	if err != nil {
+		return err
+	}
+
+	cursorName := pullCursorPrefix + peer
+	cursor, err := s.store.SyncCursorContext(ctx, cursorName)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range index.Segments {
+		if segment.Seq <= cursor {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data, err := s.objects.Get(ctx, segment.Key)
+		if err != nil {
+			return fmt.Errorf("download %s: %w", segment.Key, err)
+		}
+		if sha256Hex(data) != segment.SHA256 {
+			return fmt.Errorf("checksum mismatch for %s", segment.Key)
+		}
+		plain, err := s.open(data)
+		if err != nil {
+			return fmt.Errorf("open %s: %w", segment.Key, err)
+		}
+		evts, err := archiver.DecodeEvents(plain)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", segment.Key, err)
+		}
+
+		ids := make([]string, len(evts))
+		for i, event := range evts {
+			ids[i] = event.ID
+		}
+		// Mark before inserting so a concurrent push never picks them up.
+		if err := s.store.MarkSyncImportedContext(ctx, peer, ids); err != nil {
+			return err
+		}
+
+		for _, event := range evts {
+			err := s.store.InsertEventContext(ctx, event)
+			if err == storage.ErrDuplicateEvent {
+				result.Duplicates++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("import event %s: %w", event.ID, err)
+			}
+			result.Events++
+		}
+
+		cursor = segment.Seq
+		if err := s.store.SetSyncCursorContext(ctx, cursorName, cursor); err != nil {
+			return err
+		}
+		result.Segments++
+	}
+	return nil
+}
+
+type PeerStatus struct {
+	Machine string
+	// Pulled and Available are segment sequence numbers.
+	Pulled    int64
+	Available int64
+	Err       error
+}
+
+type Status struct {
+	Machine string
+	// PendingRows is how many rows were stored since the last push. Rows
+	// pulled from peers are counted but will not be pushed.
+	PendingRows    int64
+	PushedSegments int
+	Peers          []PeerStatus
+}
+
+func (s *Syncer) Status(ctx context.Context) (*Status, error) {
+	cursor, err := s.store.SyncCursorContext(ctx, pushCursor)
+	if err != nil {
+		return nil, err
+	}
+	maxRow, err := s.store.MaxEventRowContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{Machine: s.machine, PendingRows: max(maxRow-cursor, 0)}
+
+	own, err := s.LoadIndex(ctx, s.machine)
+	if err != nil {
+		return nil, err
+	}
+	status.PushedSegments = len(own.Segments)
+
+	for _, peer := range s.peers {
+		ps := PeerStatus{Machine: peer}
+		ps.Pulled, ps.Err = s.store.SyncCursorContext(ctx, pullCursorPrefix+peer)
+		if ps.Err == nil {
+			var index *Index
+			if index, ps.Err = s.LoadIndex(ctx, peer); ps.Err == nil && len(index.Segments) > 0 {
+				ps.Available = index.Segments[len(index.Segments)-1].Seq
+			}
+		}
+		status.Peers = append(status.Peers, ps)
+	}
+	return status, nil
+}
+
+func (s *Syncer) seal(data []byte) ([]byte, error) {
+	sealed, err := s.cipher.Encrypt(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	return []byte(sealed), nil
+}
+
+func (s *Syncer) open(data []byte) ([]byte, error) {
+	if !encryption.IsEncrypted(string(data)) {
+		return nil, fmt.Errorf("object is not encrypted")
+	}
+	plain, err := s.cipher.Decrypt(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(plain), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
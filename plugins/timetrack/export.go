@@ -0,0 +1,153 @@
+package timetrack
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"devlog/internal/analytics"
+)
+
+const (
+	FormatTimewarrior = "timew"
+	FormatJSON        = "json"
+	FormatCSV         = "csv"
+)
+
+// timewTime is the timestamp format of Timewarrior's import and data files.
+const timewTime = "20060102T150405Z"
+
+// Project is the name a session's repo is tracked under: the directory
+// name for repos stored as paths, the repo otherwise.
+func Project(repo string) string {
+	if filepath.IsAbs(repo) {
+		return filepath.Base(repo)
+	}
+	return repo
+}
+
+// Description is the time entry text: the issue and its title when known,
+// otherwise the project and branch.
+func Description(s analytics.Session) string {
+	if s.Issue != "" {
+		if s.IssueTitle != "" {
+			return s.Issue + " " + s.IssueTitle
+		}
+		return s.Issue
+	}
+	if s.Branch != "" {
+		return fmt.Sprintf("%s (%s)", Project(s.Repo), s.Branch)
+	}
+	return Project(s.Repo)
+}
+
+// Tags are the project, the issue and any extra tags from the config.
+func Tags(s analytics.Session, extra []string) []string {
+	tags := []string{Project(s.Repo)}
+	if s.Issue != "" {
+		tags = append(tags, s.Issue)
+	}
+	return append(tags, extra...)
+}
+
+// ToToggl converts a session to a Toggl time entry. projects maps project
+// names (or full repos) to Toggl project IDs.
+func ToToggl(s analytics.Session, cfg *Config) TogglEntry {
+	entry := TogglEntry{
+		Description: Description(s),
+		Start:       s.Start.UTC().Format(time.RFC3339),
+		Stop:        s.End.UTC().Format(time.RFC3339),
+		Duration:    int64(s.Duration().Seconds()),
+		Tags:        Tags(s, cfg.Tags),
+		Billable:    cfg.Billable,
+		CreatedWith: "devlog",
+	}
+	if id, ok := cfg.Projects[s.Repo]; ok {
+		entry.ProjectID = id
+	} else if id, ok := cfg.Projects[Project(s.Repo)]; ok {
+		entry.ProjectID = id
+	}
+	return entry
+}
+
+type timewInterval struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Tags       []string `json:"tags"`
+	Annotation string   `json:"annotation,omitempty"`
+}
+
+type jsonEntry struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Minutes     float64   `json:"minutes"`
+	Repo        string    `json:"repo"`
+	Branch      string    `json:"branch,omitempty"`
+	Issue       string    `json:"issue,omitempty"`
+	Description string    `json:"description"`
+	Events      int       `json:"events"`
+}
+
+// Write writes sessions as a JSON array for 'timew import', as JSON
+// entries, or as CSV with one row per session.
+func Write(w io.Writer, format string, sessions []analytics.Session, tags []string) error {
+	switch format {
+	case FormatTimewarrior:
+		out := make([]timewInterval, len(sessions))
+		for i, s := range sessions {
+			out[i] = timewInterval{
+				Start:      s.Start.UTC().Format(timewTime),
+				End:        s.End.UTC().Format(timewTime),
+				Tags:       Tags(s, tags),
+				Annotation: Description(s),
+			}
+		}
+		return writeJSON(w, out)
+
+	case FormatJSON:
+		out := make([]jsonEntry, len(sessions))
+		for i, s := range sessions {
+			out[i] = jsonEntry{
+				Start:       s.Start,
+				End:         s.End,
+				Minutes:     s.Duration().Minutes(),
+				Repo:        s.Repo,
+				Branch:      s.Branch,
+				Issue:       s.Issue,
+				Description: Description(s),
+				Events:      s.Events,
+			}
+		}
+		return writeJSON(w, out)
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"date", "start", "end", "minutes", "project", "branch", "issue", "description"})
+		for _, s := range sessions {
+			start, end := s.Start.Local(), s.End.Local()
+			cw.Write([]string{
+				start.Format("2006-01-02"),
+				start.Format("15:04"),
+				end.Format("15:04"),
+				strconv.FormatFloat(s.Duration().Minutes(), 'f', 0, 64),
+				Project(s.Repo),
+				s.Branch,
+				s.Issue,
+				Description(s),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("invalid format: %s (must be %s, %s or %s)", format, FormatTimewarrior, FormatJSON, FormatCSV)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,332 @@
+package timetrack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"devlog/internal/analytics"
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/events"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/state"
+	"devlog/internal/storage"
+)
+
+const (
+	stateModule     = "timetrack"
+	pushedUntilKey  = "pushed_until"
+	queryBatch      = 1000
+	defaultInterval = 15 * time.Minute
+)
+
+type Plugin struct {
+	pusher   *Pusher
+	storage  storage.Store
+	interval time.Duration
+	logger   *logger.Logger
+}
+
+type Config struct {
+	// TogglAPIToken enables pushing finished sessions to Toggl Track.
+	// $TOGGL_API_TOKEN is used when empty.
+	TogglAPIToken    string `json:"toggl_api_token,omitempty"`
+	TogglWorkspaceID int64  `json:"toggl_workspace_id,omitempty"`
+	// Projects maps repos, or their directory names, to Toggl project IDs.
+	Projects        map[string]int64 `json:"projects,omitempty"`
+	Billable        bool             `json:"billable,omitempty"`
+	Tags            []string         `json:"tags,omitempty"`
+	IdleGapMinutes  int              `json:"idle_gap_minutes,omitempty"`
+	MinMinutes      int              `json:"min_minutes,omitempty"`
+	IntervalSeconds int              `json:"interval_seconds,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "timetrack"
+}
+
+func (p *Plugin) Description() string {
+	return "Turns work sessions into Toggl or Timewarrior time entries"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:        "timetrack",
+		Description: "Turns work sessions into Toggl or Timewarrior time entries",
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Time Tracking plugin")
+	ctx.Log("Set toggl_api_token and toggl_workspace_id to push sessions to Toggl Track")
+	ctx.Log("Without them, use 'devlog timetrack export --format timew | timew import'")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Time Tracking plugin")
+	ctx.Log("Time entries already pushed stay in Toggl")
+
+	stateMgr, err := state.NewManager(ctx.DataDir)
+	if err == nil {
+		stateMgr.DeleteModule(stateModule)
+	}
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		IdleGapMinutes:  int(analytics.DefaultIdleGap.Minutes()),
+		MinMinutes:      int(analytics.DefaultMinSession.Minutes()),
+		IntervalSeconds: int(defaultInterval.Seconds()),
+	}
+}
+
+func (p *Plugin) ValidateConfig(cfgValue interface{}) error {
+	cfgMap, ok := cfgValue.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+
+	if cfg.TogglAPIToken != "" && cfg.TogglWorkspaceID <= 0 {
+		return errors.NewValidation("toggl_workspace_id", "is required with toggl_api_token")
+	}
+	if cfg.IdleGapMinutes < 0 || cfg.IdleGapMinutes > 240 {
+		return errors.NewValidation("idle_gap_minutes", "must be between 0 and 240")
+	}
+	if cfg.MinMinutes < 0 {
+		return errors.NewValidation("min_minutes", "must not be negative")
+	}
+	if cfg.IntervalSeconds != 0 && (cfg.IntervalSeconds < 60 || cfg.IntervalSeconds > 86400) {
+		return errors.NewValidation("interval_seconds", "must be between 60 and 86400")
+	}
+	return nil
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+// SessionOptions returns the session detection settings of cfg.
+func (c *Config) SessionOptions() analytics.SessionOptions {
+	return analytics.SessionOptions{
+		IdleGap:     time.Duration(c.IdleGapMinutes) * time.Minute,
+		MinDuration: time.Duration(c.MinMinutes) * time.Minute,
+	}
+}
+
+// NewTogglClientFromConfig returns a Toggl client, or nil when no API token
+// is configured.
+func NewTogglClientFromConfig(cfg *Config) (*TogglClient, error) {
+	token := cfg.TogglAPIToken
+	if token == "" {
+		token = os.Getenv("TOGGL_API_TOKEN")
+	}
+	if token == "" {
+		return nil, nil
+	}
+	if cfg.TogglWorkspaceID <= 0 {
+		return nil, fmt.Errorf("toggl_workspace_id is not set")
+	}
+	return NewTogglClient(token, cfg.TogglWorkspaceID), nil
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("timetrack", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("timetrack", "parse config", err)
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	client, err := NewTogglClientFromConfig(cfg)
+	if err != nil {
+		return errors.WrapPlugin("timetrack", "start", err)
+	}
+	if client == nil {
+		p.logger.Info("time tracking has no toggl token, export only")
+		<-ctx.Done()
+		return nil
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("timetrack", "get data dir", err)
+	}
+
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("timetrack", "create state manager", err)
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("timetrack", "open storage", err)
+	}
+	p.storage = store
+
+	p.pusher = NewPusher(store, stateMgr, client, cfg)
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	if p.interval <= 0 {
+		p.interval = defaultInterval
+	}
+
+	p.run(ctx)
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context) {
+	p.logger.Info("toggl push started",
+		slog.Int64("workspace_id", p.pusher.client.WorkspaceID),
+		slog.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.push(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("toggl push stopped")
+			p.storage.Close()
+			return
+		case <-ticker.C:
+			p.push(ctx)
+		}
+	}
+}
+
+func (p *Plugin) push(ctx context.Context) {
+	timer := metrics.StartPluginTimer("timetrack")
+	defer timer.Stop()
+
+	sent, err := p.pusher.Run(ctx, time.Now())
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error("toggl push failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if sent > 0 {
+		p.logger.Debug("toggl time entries created", slog.Int("entries", sent))
+	}
+}
+
+// Pusher creates Toggl time entries for sessions that have ended since its
+// last run.
+type Pusher struct {
+	store  storage.Store
+	state  *state.Manager
+	client *TogglClient
+	cfg    *Config
+}
+
+func NewPusher(store storage.Store, stateMgr *state.Manager, client *TogglClient, cfg *Config) *Pusher {
+	return &Pusher{
+		store:  store,
+		state:  stateMgr,
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Run pushes every session that has ended by now and returns how many were
+// sent. A session has ended once work moved elsewhere or nothing happened
+// for the idle gap. The first run only records the current time, so
+// history is not replayed; the cursor moves past a session once Toggl has
+// accepted it.
+func (p *Pusher) Run(ctx context.Context, now time.Time) (int, error) {
+	value, ok := p.state.GetString(stateModule, pushedUntilKey)
+	if !ok {
+		return 0, p.state.Set(stateModule, pushedUntilKey, now.UTC().Format(time.RFC3339))
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s: %w", pushedUntilKey, err)
+	}
+
+	opts := p.cfg.SessionOptions()
+	sessions, err := SessionsBetween(ctx, p.store, since, now, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	idleGap := opts.IdleGap
+	if idleGap <= 0 {
+		idleGap = analytics.DefaultIdleGap
+	}
+
+	sent := 0
+	for i, s := range sessions {
+		last := i == len(sessions)-1
+		if last && now.Sub(s.End) <= idleGap {
+			break
+		}
+		if err := p.client.Create(ctx, ToToggl(s, p.cfg)); err != nil {
+			return sent, err
+		}
+		sent++
+		if err := p.state.Set(stateModule, pushedUntilKey, s.End.UTC().Format(time.RFC3339)); err != nil {
+			return sent, fmt.Errorf("save %s: %w", pushedUntilKey, err)
+		}
+	}
+	return sent, nil
+}
+
+// SessionsBetween detects sessions in the events of [start, end).
+func SessionsBetween(ctx context.Context, store storage.Store, start, end time.Time, opts analytics.SessionOptions) ([]analytics.Session, error) {
+	var all []*events.Event
+	q := storage.QueryOptions{
+		StartTime: &start,
+		EndTime:   &end,
+		Limit:     queryBatch,
+		Ascending: true,
+	}
+	for {
+		evts, err := store.QueryEventsContext(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, evts...)
+		if len(evts) < queryBatch {
+			return analytics.Sessions(all, opts), nil
+		}
+		q.Cursor = storage.EventCursor(evts[len(evts)-1])
+	}
+}
@@ -0,0 +1,174 @@
+package timetrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/analytics"
+	"devlog/internal/events"
+	"devlog/internal/state"
+	"devlog/internal/testutil"
+)
+
+var base = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func session(repo, issue string, startMin, endMin int) analytics.Session {
+	return analytics.Session{
+		Repo:   repo,
+		Branch: "main",
+		Issue:  issue,
+		Start:  base.Add(time.Duration(startMin) * time.Minute),
+		End:    base.Add(time.Duration(endMin) * time.Minute),
+		Events: 3,
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	p := &Plugin{}
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		wantErr bool
+	}{
+		{"empty", map[string]interface{}{}, false},
+		{"toggl", map[string]interface{}{"toggl_api_token": "t", "toggl_workspace_id": 42}, false},
+		{"toggl without workspace", map[string]interface{}{"toggl_api_token": "t"}, true},
+		{"negative min", map[string]interface{}{"min_minutes": -1}, true},
+		{"long idle gap", map[string]interface{}{"idle_gap_minutes": 600}, true},
+		{"short interval", map[string]interface{}{"interval_seconds": 10}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.ValidateConfig(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToToggl(t *testing.T) {
+	cfg := &Config{Projects: map[string]int64{"devlog": 7}, Billable: true, Tags: []string{"client-a"}}
+	s := session("/home/me/src/devlog", "PROJ-1", 0, 90)
+	s.IssueTitle = "Fix login"
+
+	entry := ToToggl(s, cfg)
+	if entry.Description != "PROJ-1 Fix login" || entry.ProjectID != 7 || entry.Duration != 5400 || !entry.Billable {
+		t.Errorf("entry = %+v", entry)
+	}
+	if entry.Start != "2026-03-02T09:00:00Z" || entry.Stop != "2026-03-02T10:30:00Z" {
+		t.Errorf("start/stop = %s %s", entry.Start, entry.Stop)
+	}
+	if strings.Join(entry.Tags, ",") != "devlog,PROJ-1,client-a" {
+		t.Errorf("tags = %v", entry.Tags)
+	}
+
+	if got := ToToggl(session("other", "", 0, 10), cfg); got.ProjectID != 0 || got.Description != "other (main)" {
+		t.Errorf("unmapped entry = %+v", got)
+	}
+}
+
+func TestWriteTimewarrior(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatTimewarrior, []analytics.Session{session("devlog", "PROJ-1", 0, 30)}, nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	var got []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 1 || got[0]["start"] != "20260302T090000Z" || got[0]["end"] != "20260302T093000Z" || got[0]["annotation"] != "PROJ-1" {
+		t.Errorf("timew output = %v", got)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, FormatCSV, []analytics.Session{session("devlog", "", 0, 30)}, nil); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], ",30,devlog,main,,devlog (main)") {
+		t.Errorf("csv output = %q", buf.String())
+	}
+
+	if err := Write(&buf, "xml", nil, nil); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+type togglRecorder struct {
+	mu      sync.Mutex
+	entries []TogglEntry
+	paths   []string
+}
+
+func (r *togglRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if user, pass, ok := req.BasicAuth(); !ok || user != "tok" || pass != "api_token" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	var entry TogglEntry
+	json.NewDecoder(req.Body).Decode(&entry)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	r.paths = append(r.paths, req.URL.Path)
+	w.Write([]byte(`{}`))
+}
+
+func TestPusherRun(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	stateMgr, err := state.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &togglRecorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	client := NewTogglClient("tok", 42)
+	client.APIURL = srv.URL
+	pusher := NewPusher(store, stateMgr, client, &Config{})
+	ctx := context.Background()
+
+	// The first run only sets the cursor.
+	if sent, err := pusher.Run(ctx, base); err != nil || sent != 0 {
+		t.Fatalf("first Run() = %d, %v", sent, err)
+	}
+
+	var evts []*events.Event
+	for _, spec := range []struct {
+		min  int
+		repo string
+	}{{1, "api"}, {10, "api"}, {20, "api"}, {25, "web"}, {35, "web"}} {
+		e := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+		e.Timestamp = base.Add(time.Duration(spec.min) * time.Minute).Format(time.RFC3339)
+		e.Repo = spec.repo
+		evts = append(evts, e)
+	}
+	testutil.MustInsertEvents(t, store, evts...)
+
+	// At 9:40 the web session may still be going.
+	sent, err := pusher.Run(ctx, base.Add(40*time.Minute))
+	if err != nil || sent != 1 {
+		t.Fatalf("Run() = %d, %v, want the api session", sent, err)
+	}
+	if rec.paths[0] != "/workspaces/42/time_entries" || rec.entries[0].Description != "api (main)" || rec.entries[0].Duration != 24*60 {
+		t.Errorf("first entry %s %+v", rec.paths[0], rec.entries[0])
+	}
+
+	// Once idle, it is pushed, and the api session is not sent again.
+	sent, err = pusher.Run(ctx, base.Add(2*time.Hour))
+	if err != nil || sent != 1 {
+		t.Fatalf("Run() = %d, %v, want the web session", sent, err)
+	}
+	if len(rec.entries) != 2 || rec.entries[1].Description != "web (main)" || rec.entries[1].WorkspaceID != 42 {
+		t.Errorf("entries = %+v", rec.entries)
+	}
+}
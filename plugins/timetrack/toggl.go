@@ -0,0 +1,106 @@
+package timetrack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const DefaultTogglURL = "https://api.track.toggl.com/api/v9"
+
+// TogglEntry is a time entry in the shape of Toggl Track's v9 API.
+type TogglEntry struct {
+	Description string   `json:"description"`
+	Start       string   `json:"start"`
+	Stop        string   `json:"stop"`
+	Duration    int64    `json:"duration"`
+	WorkspaceID int64    `json:"workspace_id"`
+	ProjectID   int64    `json:"project_id,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Billable    bool     `json:"billable"`
+	CreatedWith string   `json:"created_with"`
+}
+
+type TogglClient struct {
+	APIURL      string
+	Token       string
+	WorkspaceID int64
+	HTTP        *http.Client
+}
+
+func NewTogglClient(token string, workspaceID int64) *TogglClient {
+	return &TogglClient{
+		APIURL:      DefaultTogglURL,
+		Token:       token,
+		WorkspaceID: workspaceID,
+		HTTP:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Create adds one time entry to the workspace.
+func (c *TogglClient) Create(ctx context.Context, entry TogglEntry) error {
+	entry.WorkspaceID = c.WorkspaceID
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal time entry: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/workspaces/%d/time_entries", strings.TrimRight(c.APIURL, "/"), c.WorkspaceID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.do(req, nil)
+}
+
+// Starts returns the start times of the entries already in Toggl between
+// start and end, so a push can skip sessions it sent before.
+func (c *TogglClient) Starts(ctx context.Context, start, end time.Time) (map[time.Time]bool, error) {
+	q := url.Values{}
+	q.Set("start_date", start.UTC().Format(time.RFC3339))
+	q.Set("end_date", end.UTC().Format(time.RFC3339))
+	endpoint := strings.TrimRight(c.APIURL, "/") + "/me/time_entries?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	var existing []struct {
+		Start string `json:"start"`
+	}
+	if err := c.do(req, &existing); err != nil {
+		return nil, err
+	}
+	starts := make(map[time.Time]bool, len(existing))
+	for _, e := range existing {
+		if t, err := time.Parse(time.RFC3339, e.Start); err == nil {
+			starts[t.UTC()] = true
+		}
+	}
+	return starts, nil
+}
+
+func (c *TogglClient) do(req *http.Request, out interface{}) error {
+	req.SetBasicAuth(c.Token, "api_token")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("toggl: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("toggl: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
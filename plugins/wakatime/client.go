@@ -0,0 +1,117 @@
+package wakatime
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultAPIURL = "https://api.wakatime.com/api/v1"
+
+	// maxBulkHeartbeats is the most heartbeats WakaTime accepts per bulk
+	// request.
+	maxBulkHeartbeats = 25
+)
+
+type Client struct {
+	APIURL  string
+	APIKey  string
+	Machine string
+	HTTP    *http.Client
+}
+
+func NewClient(apiURL, apiKey, machine string) *Client {
+	return &Client{
+		APIURL:  strings.TrimRight(apiURL, "/"),
+		APIKey:  apiKey,
+		Machine: machine,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send posts heartbeats to the bulk endpoint, maxBulkHeartbeats at a time.
+// It stops at the first rejected batch.
+func (c *Client) Send(ctx context.Context, beats []Heartbeat) error {
+	for start := 0; start < len(beats); start += maxBulkHeartbeats {
+		end := min(start+maxBulkHeartbeats, len(beats))
+		if err := c.post(ctx, beats[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) post(ctx context.Context, beats []Heartbeat) error {
+	body, err := json.Marshal(beats)
+	if err != nil {
+		return fmt.Errorf("marshal heartbeats: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.APIURL+"/users/current/heartbeats.bulk", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.APIKey)))
+	if c.Machine != "" {
+		req.Header.Set("X-Machine-Name", c.Machine)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return fmt.Errorf("send heartbeats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("send heartbeats: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// ReadWakaTimeConfig reads api_url and api_key from the [settings] section
+// of a wakatime-cli config file such as ~/.wakatime.cfg.
+func ReadWakaTimeConfig(path string) (apiURL, apiKey string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if section != "settings" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "api_url":
+			apiURL = strings.TrimSpace(value)
+		case "api_key":
+			apiKey = strings.TrimSpace(value)
+		}
+	}
+	return apiURL, apiKey, scanner.Err()
+}
@@ -0,0 +1,150 @@
+package wakatime
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+)
+
+// Heartbeat is one WakaTime heartbeat, in the shape accepted by
+// /users/current/heartbeats.bulk and by wakatime-cli --extra-heartbeats.
+type Heartbeat struct {
+	Entity    string  `json:"entity"`
+	Type      string  `json:"type"`
+	Category  string  `json:"category"`
+	Time      float64 `json:"time"`
+	Project   string  `json:"project,omitempty"`
+	Branch    string  `json:"branch,omitempty"`
+	Language  string  `json:"language,omitempty"`
+	IsWrite   bool    `json:"is_write,omitempty"`
+	UserAgent string  `json:"user_agent,omitempty"`
+}
+
+const (
+	typeFile = "file"
+	typeApp  = "app"
+
+	categoryCoding    = "coding"
+	categoryBuilding  = "building"
+	categoryTests     = "running tests"
+	categoryReviewing = "code reviewing"
+	categoryDocs      = "writing docs"
+)
+
+var testCommands = []string{"go test", "pytest", "npm test", "yarn test", "pnpm test", "cargo test", "make test", "bundle exec rspec", "rspec", "jest", "mix test"}
+
+var buildCommands = []string{"go build", "cargo build", "npm run build", "yarn build", "pnpm build", "make", "docker build", "mvn", "gradle"}
+
+// Heartbeats converts events into heartbeats. Events that touched files
+// become one file heartbeat per file; everything else becomes an app
+// heartbeat named after its source. When sources is not empty, events from
+// other sources are skipped.
+func Heartbeats(evts []*events.Event, sources []string, userAgent string) []Heartbeat {
+	var beats []Heartbeat
+	for _, evt := range evts {
+		if len(sources) > 0 && !contains(sources, evt.Source) {
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339, evt.Timestamp)
+		if err != nil {
+			continue
+		}
+
+		base := Heartbeat{
+			Type:      typeApp,
+			Entity:    evt.Source,
+			Category:  category(evt),
+			Time:      float64(ts.UnixNano()) / 1e9,
+			Project:   project(evt.Repo),
+			Branch:    evt.Branch,
+			UserAgent: userAgent,
+		}
+
+		files := events.Files(evt)
+		if len(files) == 0 {
+			beats = append(beats, base)
+			continue
+		}
+
+		workdir, _ := evt.Payload["workdir"].(string)
+		write := isWrite(evt)
+		for _, path := range files {
+			hb := base
+			hb.Type = typeFile
+			hb.Entity = entityPath(evt.Repo, workdir, path)
+			hb.Language = events.Language(path)
+			hb.IsWrite = write
+			beats = append(beats, hb)
+		}
+	}
+	return beats
+}
+
+func category(evt *events.Event) string {
+	switch {
+	case evt.Source == string(events.SourceShell):
+		cmd, _ := evt.Payload["command"].(string)
+		cmd = strings.TrimSpace(cmd)
+		if hasCommandPrefix(cmd, testCommands) {
+			return categoryTests
+		}
+		if hasCommandPrefix(cmd, buildCommands) {
+			return categoryBuilding
+		}
+	case evt.Type == string(events.TypeTestRun):
+		return categoryTests
+	case evt.Type == string(events.TypePRReview):
+		return categoryReviewing
+	case evt.Type == string(events.TypeNote):
+		return categoryDocs
+	}
+	return categoryCoding
+}
+
+func hasCommandPrefix(cmd string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if cmd == prefix || strings.HasPrefix(cmd, prefix+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func isWrite(evt *events.Event) bool {
+	return evt.Type == string(events.TypeCommit) || evt.Type == string(events.TypeFileEdit)
+}
+
+// project names the WakaTime project after the repo, using the directory
+// name for repos recorded as absolute paths.
+func project(repo string) string {
+	if filepath.IsAbs(repo) {
+		return filepath.Base(repo)
+	}
+	return repo
+}
+
+// entityPath makes a file path absolute when the event says where it was
+// run, since WakaTime groups files by their full path.
+func entityPath(repo, workdir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	switch {
+	case workdir != "":
+		return filepath.Join(workdir, path)
+	case filepath.IsAbs(repo):
+		return filepath.Join(repo, path)
+	}
+	return path
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,336 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/state"
+	"devlog/internal/storage"
+)
+
+const (
+	stateModule    = "wakatime"
+	eventCursorKey = "event_row"
+	pushBatch      = 500
+)
+
+// UserAgent identifies devlog's heartbeats in WakaTime and Wakapi.
+var UserAgent = fmt.Sprintf("devlog (%s-%s) devlog-wakatime", runtime.GOOS, runtime.GOARCH)
+
+type Plugin struct {
+	pusher   *Pusher
+	storage  storage.Store
+	interval time.Duration
+	logger   *logger.Logger
+}
+
+type Config struct {
+	APIURL          string   `json:"api_url,omitempty"`
+	APIKey          string   `json:"api_key,omitempty"`
+	Machine         string   `json:"machine,omitempty"`
+	Sources         []string `json:"sources,omitempty"`
+	IntervalSeconds int      `json:"interval_seconds,omitempty"`
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "wakatime"
+}
+
+func (p *Plugin) Description() string {
+	return "Sends activity to WakaTime or Wakapi as heartbeats"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:        "wakatime",
+		Description: "Sends activity to WakaTime or Wakapi as heartbeats",
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing WakaTime plugin")
+	ctx.Log("Leave api_key empty to use the key and api_url from ~/.wakatime.cfg")
+	ctx.Log("For Wakapi, set api_url to your instance's API, e.g. https://wakapi.dev/api")
+	ctx.Log("Only new events are sent; use 'devlog wakatime push --since 30d' to backfill")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling WakaTime plugin")
+	ctx.Log("Heartbeats already sent stay in your WakaTime account")
+
+	stateMgr, err := state.NewManager(ctx.DataDir)
+	if err == nil {
+		stateMgr.DeleteModule(stateModule)
+	}
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		IntervalSeconds: 120,
+	}
+}
+
+func (p *Plugin) ValidateConfig(cfgValue interface{}) error {
+	cfgMap, ok := cfgValue.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+
+	if cfg.APIURL != "" {
+		u, err := url.Parse(cfg.APIURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.NewValidation("api_url", "must be an http or https URL")
+		}
+	}
+	if cfg.IntervalSeconds != 0 && (cfg.IntervalSeconds < 30 || cfg.IntervalSeconds > 86400) {
+		return errors.NewValidation("interval_seconds", "must be between 30 and 86400")
+	}
+	return nil
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewClientFromConfig builds a client, falling back to the api_key and
+// api_url in ~/.wakatime.cfg for whatever the plugin config leaves empty.
+func NewClientFromConfig(cfg *Config) (*Client, error) {
+	apiURL, apiKey := cfg.APIURL, cfg.APIKey
+	if apiURL == "" || apiKey == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			fileURL, fileKey, err := ReadWakaTimeConfig(filepath.Join(home, ".wakatime.cfg"))
+			if err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("read ~/.wakatime.cfg: %w", err)
+			}
+			if apiURL == "" {
+				apiURL = fileURL
+			}
+			if apiKey == "" {
+				apiKey = fileKey
+			}
+		}
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("api_key is not set and ~/.wakatime.cfg has none")
+	}
+	if apiURL == "" {
+		apiURL = DefaultAPIURL
+	}
+
+	machine := cfg.Machine
+	if machine == "" {
+		machine, _ = os.Hostname()
+	}
+	return NewClient(apiURL, apiKey, machine), nil
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("wakatime", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("wakatime", "parse config", err)
+	}
+
+	client, err := NewClientFromConfig(cfg)
+	if err != nil {
+		return errors.WrapPlugin("wakatime", "start", err)
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("wakatime", "get data dir", err)
+	}
+
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("wakatime", "create state manager", err)
+	}
+
+	store, err := storage.OpenConfigured(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("wakatime", "open storage", err)
+	}
+	p.storage = store
+
+	p.pusher = NewPusher(store, stateMgr, client, cfg.Sources)
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	if p.interval <= 0 {
+		p.interval = 2 * time.Minute
+	}
+
+	p.run(ctx)
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context) {
+	p.logger.Info("wakatime push started",
+		slog.String("api_url", p.pusher.client.APIURL),
+		slog.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.push(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("wakatime push stopped")
+			p.storage.Close()
+			return
+		case <-ticker.C:
+			p.push(ctx)
+		}
+	}
+}
+
+func (p *Plugin) push(ctx context.Context) {
+	timer := metrics.StartPluginTimer("wakatime")
+	defer timer.Stop()
+
+	sent, err := p.pusher.Run(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			p.logger.Error("wakatime push failed", slog.String("error", err.Error()))
+		}
+		return
+	}
+	if sent > 0 {
+		p.logger.Debug("wakatime heartbeats sent", slog.Int("heartbeats", sent))
+	}
+}
+
+// Pusher sends heartbeats for events ingested since its last run.
+type Pusher struct {
+	store   storage.Store
+	state   *state.Manager
+	client  *Client
+	sources []string
+}
+
+func NewPusher(store storage.Store, stateMgr *state.Manager, client *Client, sources []string) *Pusher {
+	return &Pusher{
+		store:   store,
+		state:   stateMgr,
+		client:  client,
+		sources: sources,
+	}
+}
+
+// Run sends heartbeats for new events and returns how many were sent. On
+// the first run the cursor starts at the current end of the database so
+// history is not replayed; the cursor only moves past a batch once WakaTime
+// has accepted it.
+func (p *Pusher) Run(ctx context.Context) (int, error) {
+	afterRow, err := p.cursor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for {
+		evts, lastRow, err := p.store.EventsAfterRowContext(ctx, afterRow, pushBatch)
+		if err != nil {
+			return sent, err
+		}
+		if len(evts) == 0 {
+			return sent, nil
+		}
+
+		beats := Heartbeats(evts, p.sources, UserAgent)
+		if err := p.client.Send(ctx, beats); err != nil {
+			return sent, err
+		}
+		sent += len(beats)
+
+		afterRow = lastRow
+		if err := p.state.Set(stateModule, eventCursorKey, strconv.FormatInt(afterRow, 10)); err != nil {
+			return sent, fmt.Errorf("save %s: %w", eventCursorKey, err)
+		}
+		if len(evts) < pushBatch {
+			return sent, nil
+		}
+	}
+}
+
+func (p *Pusher) cursor(ctx context.Context) (int64, error) {
+	if value, ok := p.state.GetString(stateModule, eventCursorKey); ok {
+		return strconv.ParseInt(value, 10, 64)
+	}
+	start, err := p.store.MaxEventRowContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := p.state.Set(stateModule, eventCursorKey, strconv.FormatInt(start, 10)); err != nil {
+		return 0, fmt.Errorf("save %s: %w", eventCursorKey, err)
+	}
+	return start, nil
+}
+
+// HeartbeatsBetween converts every event in [start, end) to heartbeats, for
+// exports and backfills.
+func HeartbeatsBetween(ctx context.Context, store storage.Store, start, end time.Time, sources []string) ([]Heartbeat, error) {
+	var beats []Heartbeat
+	opts := storage.QueryOptions{
+		StartTime: &start,
+		EndTime:   &end,
+		Limit:     pushBatch,
+		Ascending: true,
+	}
+	for {
+		evts, err := store.QueryEventsContext(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		beats = append(beats, Heartbeats(evts, sources, UserAgent)...)
+		if len(evts) < pushBatch {
+			return beats, nil
+		}
+		opts.Cursor = storage.EventCursor(evts[len(evts)-1])
+	}
+}
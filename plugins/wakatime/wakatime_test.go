@@ -0,0 +1,169 @@
+package wakatime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/state"
+	"devlog/internal/testutil"
+)
+
+type recorder struct {
+	mu      sync.Mutex
+	batches [][]Heartbeat
+	headers []http.Header
+	status  int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	var beats []Heartbeat
+	json.Unmarshal(body, &beats)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, beats)
+	r.headers = append(r.headers, req.Header.Clone())
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func TestHeartbeats(t *testing.T) {
+	commit := testutil.NewEventBuilder().
+		WithSource(string(events.SourceGit)).
+		WithType(string(events.TypeCommit)).
+		WithRepo("/home/me/src/devlog").
+		WithBranch("main").
+		WithPayloadField("files", []interface{}{"main.go", "README.md"}).
+		Build()
+	tests := testutil.NewEventBuilder().
+		WithSource(string(events.SourceShell)).
+		WithType(string(events.TypeCommand)).
+		WithRepo("devlog").
+		WithPayloadField("command", "go test ./...").
+		Build()
+	note := testutil.NewTestEvent(string(events.SourceManual), string(events.TypeNote))
+
+	beats := Heartbeats([]*events.Event{commit, tests, note}, nil, "ua")
+	if len(beats) != 4 {
+		t.Fatalf("got %d heartbeats, want 4: %+v", len(beats), beats)
+	}
+
+	goFile := beats[0]
+	if goFile.Type != "file" || goFile.Entity != "/home/me/src/devlog/main.go" || goFile.Language != "Go" ||
+		goFile.Project != "devlog" || goFile.Branch != "main" || !goFile.IsWrite || goFile.UserAgent != "ua" {
+		t.Errorf("commit heartbeat = %+v", goFile)
+	}
+	if beats[2].Type != "app" || beats[2].Entity != "shell" || beats[2].Category != "running tests" {
+		t.Errorf("shell heartbeat = %+v", beats[2])
+	}
+	if beats[3].Category != "writing docs" {
+		t.Errorf("note heartbeat = %+v", beats[3])
+	}
+
+	if only := Heartbeats([]*events.Event{commit, tests, note}, []string{"shell"}, "ua"); len(only) != 1 {
+		t.Errorf("sources filter kept %d heartbeats, want 1", len(only))
+	}
+}
+
+func TestClientSendBatches(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	beats := make([]Heartbeat, 60)
+	for i := range beats {
+		beats[i] = Heartbeat{Entity: "shell", Type: "app", Category: "coding", Time: float64(i)}
+	}
+
+	client := NewClient(server.URL+"/api/", "secret", "laptop")
+	if err := client.Send(context.Background(), beats); err != nil {
+		t.Fatalf("Send() error: %v", err)
+	}
+
+	if len(rec.batches) != 3 || len(rec.batches[0]) != 25 || len(rec.batches[2]) != 10 {
+		t.Fatalf("got batches of %d, want 25, 25, 10", len(rec.batches))
+	}
+	h := rec.headers[0]
+	if h.Get("Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("secret")) {
+		t.Errorf("Authorization = %q", h.Get("Authorization"))
+	}
+	if h.Get("X-Machine-Name") != "laptop" {
+		t.Errorf("X-Machine-Name = %q", h.Get("X-Machine-Name"))
+	}
+
+	rec.status = http.StatusUnauthorized
+	if err := client.Send(context.Background(), beats[:1]); err == nil {
+		t.Error("expected an error for a rejected batch")
+	}
+}
+
+func TestPusherRun(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	stateMgr, err := state.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	testutil.MustInsertEvents(t, store, testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand)))
+
+	pusher := NewPusher(store, stateMgr, NewClient(server.URL, "key", ""), nil)
+	ctx := context.Background()
+
+	sent, err := pusher.Run(ctx)
+	if err != nil || sent != 0 {
+		t.Fatalf("first run sent %d (%v), want existing history skipped", sent, err)
+	}
+
+	evt := testutil.NewTestEvent(string(events.SourceShell), string(events.TypeCommand))
+	evt.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	testutil.MustInsertEvents(t, store, evt)
+
+	rec.status = http.StatusInternalServerError
+	if _, err := pusher.Run(ctx); err == nil {
+		t.Fatal("expected an error when the server rejects heartbeats")
+	}
+
+	rec.status = 0
+	sent, err = pusher.Run(ctx)
+	if err != nil || sent != 1 {
+		t.Fatalf("retry sent %d (%v), want the failed event resent", sent, err)
+	}
+
+	sent, err = pusher.Run(ctx)
+	if err != nil || sent != 0 {
+		t.Errorf("third run sent %d (%v), want nothing new", sent, err)
+	}
+}
+
+func TestReadWakaTimeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".wakatime.cfg")
+	content := "[settings]\napi_url = https://wakapi.dev/api\napi_key = waka_123\n\n[projectmap]\napi_key = ignored\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	apiURL, apiKey, err := ReadWakaTimeConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apiURL != "https://wakapi.dev/api" || apiKey != "waka_123" {
+		t.Errorf("got %q, %q", apiURL, apiKey)
+	}
+}
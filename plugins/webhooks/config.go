@@ -0,0 +1,173 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+)
+
+const (
+	TriggerSummary     = "summary"
+	TriggerDailyReport = "daily_report"
+	TriggerEvent       = "event"
+
+	FormatJSON    = "json"
+	FormatSlack   = "slack"
+	FormatDiscord = "discord"
+)
+
+type Config struct {
+	IntervalSeconds int      `json:"interval_seconds"`
+	DailyReportAt   string   `json:"daily_report_at,omitempty"`
+	Targets         []Target `json:"targets"`
+}
+
+type Target struct {
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`
+	Format  string            `json:"format,omitempty"`
+	Secret  string            `json:"secret,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	On      []string          `json:"on"`
+	Match   []EventFilter     `json:"match,omitempty"`
+}
+
+// EventFilter selects events for the "event" trigger. Every non-empty field
+// must match; string fields accept * and ? globs.
+type EventFilter struct {
+	Source  string            `json:"source,omitempty"`
+	Types   []string          `json:"types,omitempty"`
+	Repo    string            `json:"repo,omitempty"`
+	Branch  string            `json:"branch,omitempty"`
+	Payload map[string]string `json:"payload,omitempty"`
+}
+
+func ParseConfig(cfgMap map[string]interface{}) (*Config, error) {
+	cfg := &Config{}
+	cfgBytes, err := json.Marshal(cfgMap)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(cfgBytes, cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return cfg, nil
+}
+
+func (c *Config) Validate() error {
+	if c.DailyReportAt != "" {
+		if _, err := time.Parse("15:04", c.DailyReportAt); err != nil {
+			return fmt.Errorf("daily_report_at must be HH:MM")
+		}
+	}
+
+	names := make(map[string]bool)
+	for i, t := range c.Targets {
+		label := t.Name
+		if label == "" {
+			label = fmt.Sprintf("targets[%d]", i)
+		} else if names[t.Name] {
+			return fmt.Errorf("%s: duplicate target name", label)
+		}
+		names[t.Name] = true
+
+		u, err := url.Parse(t.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s: url must be an http(s) URL", label)
+		}
+
+		switch t.Format {
+		case "", FormatJSON, FormatSlack, FormatDiscord:
+		default:
+			return fmt.Errorf("%s: format must be json, slack or discord", label)
+		}
+
+		if len(t.On) == 0 {
+			return fmt.Errorf("%s: on must list at least one of summary, daily_report, event", label)
+		}
+		for _, trigger := range t.On {
+			switch trigger {
+			case TriggerSummary, TriggerDailyReport:
+			case TriggerEvent:
+				if len(t.Match) == 0 {
+					return fmt.Errorf("%s: the event trigger needs at least one match filter", label)
+				}
+			default:
+				return fmt.Errorf("%s: unknown trigger %q", label, trigger)
+			}
+		}
+	}
+	return nil
+}
+
+func (t Target) Wants(trigger string) bool {
+	for _, on := range t.On {
+		if on == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Target) MatchesEvent(evt *events.Event) bool {
+	for _, f := range t.Match {
+		if f.Matches(evt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f EventFilter) Matches(evt *events.Event) bool {
+	if f.Source != "" && !globMatch(f.Source, evt.Source) {
+		return false
+	}
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			if globMatch(t, evt.Type) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if f.Repo != "" && !globMatch(f.Repo, evt.Repo) {
+		return false
+	}
+	if f.Branch != "" && !globMatch(f.Branch, evt.Branch) {
+		return false
+	}
+	for key, pattern := range f.Payload {
+		value, ok := evt.Payload[key]
+		if !ok || !globMatch(pattern, fmt.Sprint(value)) {
+			return false
+		}
+	}
+	return true
+}
+
+func globMatch(pattern, value string) bool {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	matched, _ := regexp.MatchString(b.String(), value)
+	return matched
+}
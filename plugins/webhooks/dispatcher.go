@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"devlog/internal/state"
+	"devlog/internal/storage"
+	"devlog/plugins/summarizer"
+)
+
+const (
+	stateModule     = "webhooks"
+	eventCursorKey  = "event_row"
+	summaryIDKey    = "summary_id"
+	lastReportKey   = "last_daily_report"
+	dispatchBatch   = 500
+	reportDayLayout = "2006-01-02"
+)
+
+type Dispatcher struct {
+	store   *storage.Storage
+	state   *state.Manager
+	sender  *Sender
+	targets []Target
+	dailyAt string
+	now     func() time.Time
+}
+
+type DispatchResult struct {
+	Events    int
+	Summaries int
+	Reports   int
+	Failures  []error
+}
+
+func NewDispatcher(store *storage.Storage, stateMgr *state.Manager, sender *Sender, cfg *Config) *Dispatcher {
+	return &Dispatcher{
+		store:   store,
+		state:   stateMgr,
+		sender:  sender,
+		targets: cfg.Targets,
+		dailyAt: cfg.DailyReportAt,
+		now:     time.Now,
+	}
+}
+
+func (d *Dispatcher) wants(trigger string) bool {
+	for _, t := range d.targets {
+		if t.Wants(trigger) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run delivers everything ingested since the previous run. On the first run
+// the cursors start at the current end of the database so existing history is
+// not replayed.
+func (d *Dispatcher) Run(ctx context.Context) (*DispatchResult, error) {
+	result := &DispatchResult{}
+
+	if err := d.dispatchEvents(ctx, result); err != nil {
+		return result, err
+	}
+	if err := d.dispatchSummaries(ctx, result); err != nil {
+		return result, err
+	}
+	if err := d.dispatchDailyReport(ctx, result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (d *Dispatcher) cursor(ctx context.Context, key string, initial func(context.Context) (int64, error)) (int64, error) {
+	if value, ok := d.state.GetString(stateModule, key); ok {
+		return strconv.ParseInt(value, 10, 64)
+	}
+	start, err := initial(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := d.state.Set(stateModule, key, strconv.FormatInt(start, 10)); err != nil {
+		return 0, fmt.Errorf("save %s: %w", key, err)
+	}
+	return start, nil
+}
+
+func (d *Dispatcher) dispatchEvents(ctx context.Context, result *DispatchResult) error {
+	if !d.wants(TriggerEvent) {
+		return nil
+	}
+
+	afterRow, err := d.cursor(ctx, eventCursorKey, d.store.MaxEventRowContext)
+	if err != nil {
+		return err
+	}
+
+	for {
+		evts, lastRow, err := d.store.EventsAfterRowContext(ctx, afterRow, dispatchBatch)
+		if err != nil {
+			return err
+		}
+		if len(evts) == 0 {
+			return nil
+		}
+
+		for _, evt := range evts {
+			for _, target := range d.targets {
+				if !target.Wants(TriggerEvent) || !target.MatchesEvent(evt) {
+					continue
+				}
+				result.Events++
+				if err := d.sender.Send(ctx, target, EventNotification(evt)); err != nil {
+					result.Failures = append(result.Failures, fmt.Errorf("%s: %w", target.Name, err))
+				}
+			}
+		}
+
+		afterRow = lastRow
+		if err := d.state.Set(stateModule, eventCursorKey, strconv.FormatInt(afterRow, 10)); err != nil {
+			return fmt.Errorf("save %s: %w", eventCursorKey, err)
+		}
+		if len(evts) < dispatchBatch {
+			return nil
+		}
+	}
+}
+
+func (d *Dispatcher) dispatchSummaries(ctx context.Context, result *DispatchResult) error {
+	if !d.wants(TriggerSummary) {
+		return nil
+	}
+
+	afterID, err := d.cursor(ctx, summaryIDKey, d.store.MaxSummaryIDContext)
+	if err != nil {
+		return err
+	}
+
+	summaries, err := d.store.SummariesAfterIDContext(ctx, afterID, dispatchBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range summaries {
+		for _, target := range d.targets {
+			if !target.Wants(TriggerSummary) {
+				continue
+			}
+			result.Summaries++
+			if err := d.sender.Send(ctx, target, SummaryNotification(s)); err != nil {
+				result.Failures = append(result.Failures, fmt.Errorf("%s: %w", target.Name, err))
+			}
+		}
+		afterID = s.ID
+	}
+
+	if len(summaries) > 0 {
+		if err := d.state.Set(stateModule, summaryIDKey, strconv.FormatInt(afterID, 10)); err != nil {
+			return fmt.Errorf("save %s: %w", summaryIDKey, err)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) dispatchDailyReport(ctx context.Context, result *DispatchResult) error {
+	if d.dailyAt == "" || !d.wants(TriggerDailyReport) {
+		return nil
+	}
+
+	now := d.now()
+	at, err := time.ParseInLocation("15:04", d.dailyAt, now.Location())
+	if err != nil {
+		return fmt.Errorf("parse daily_report_at: %w", err)
+	}
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	due := dayStart.Add(time.Duration(at.Hour())*time.Hour + time.Duration(at.Minute())*time.Minute)
+	if now.Before(due) {
+		return nil
+	}
+
+	day := dayStart.Format(reportDayLayout)
+	if last, ok := d.state.GetString(stateModule, lastReportKey); ok && last == day {
+		return nil
+	}
+
+	n, err := BuildDailyReport(ctx, d.store, dayStart)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range d.targets {
+		if !target.Wants(TriggerDailyReport) {
+			continue
+		}
+		result.Reports++
+		if err := d.sender.Send(ctx, target, n); err != nil {
+			result.Failures = append(result.Failures, fmt.Errorf("%s: %w", target.Name, err))
+		}
+	}
+
+	if err := d.state.Set(stateModule, lastReportKey, day); err != nil {
+		return fmt.Errorf("save %s: %w", lastReportKey, err)
+	}
+	return nil
+}
+
+func BuildDailyReport(ctx context.Context, store storage.Store, dayStart time.Time) (*Notification, error) {
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	title := "Daily report " + dayStart.Format(reportDayLayout)
+
+	report, err := summarizer.BuildReport(ctx, store, title, dayStart, dayEnd)
+	if err != nil {
+		return nil, fmt.Errorf("build daily report: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.Render(&buf, summarizer.ReportFormatMarkdown); err != nil {
+		return nil, fmt.Errorf("render daily report: %w", err)
+	}
+	return ReportNotification(title, dayStart, dayEnd, buf.String()), nil
+}
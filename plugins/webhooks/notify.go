@@ -0,0 +1,187 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/output"
+	"devlog/internal/storage"
+)
+
+const (
+	SignatureHeader = "X-Devlog-Signature"
+
+	discordMaxContent = 2000
+	slackMaxText      = 3000
+)
+
+type Notification struct {
+	Kind      string          `json:"kind"`
+	Timestamp time.Time       `json:"timestamp"`
+	Text      string          `json:"text"`
+	Event     *events.Event   `json:"event,omitempty"`
+	Summary   *SummaryPayload `json:"summary,omitempty"`
+	Report    *ReportPayload  `json:"report,omitempty"`
+}
+
+type SummaryPayload struct {
+	ID          int64     `json:"id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Repos       []string  `json:"repos"`
+	EventCount  int       `json:"event_count"`
+	Text        string    `json:"text"`
+}
+
+type ReportPayload struct {
+	Title    string    `json:"title"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Markdown string    `json:"markdown"`
+}
+
+func EventNotification(evt *events.Event) *Notification {
+	return &Notification{
+		Kind:      TriggerEvent,
+		Timestamp: time.Now().UTC(),
+		Text:      "devlog: " + strings.TrimPrefix(output.FormatEventLine(evt, 120, 120, 200, 200), "\n"),
+		Event:     evt,
+	}
+}
+
+func SummaryNotification(s *storage.Summary) *Notification {
+	header := fmt.Sprintf("devlog summary %s – %s", s.PeriodStart.Local().Format("Jan 2 15:04"), s.PeriodEnd.Local().Format("15:04"))
+	if len(s.Repos) > 0 {
+		header += " (" + strings.Join(s.Repos, ", ") + ")"
+	}
+	return &Notification{
+		Kind:      TriggerSummary,
+		Timestamp: time.Now().UTC(),
+		Text:      header + "\n" + s.Text,
+		Summary: &SummaryPayload{
+			ID:          s.ID,
+			PeriodStart: s.PeriodStart,
+			PeriodEnd:   s.PeriodEnd,
+			Repos:       s.Repos,
+			EventCount:  s.EventCount,
+			Text:        s.Text,
+		},
+	}
+}
+
+func ReportNotification(title string, start, end time.Time, markdown string) *Notification {
+	return &Notification{
+		Kind:      TriggerDailyReport,
+		Timestamp: time.Now().UTC(),
+		Text:      markdown,
+		Report: &ReportPayload{
+			Title:    title,
+			Start:    start,
+			End:      end,
+			Markdown: markdown,
+		},
+	}
+}
+
+func Render(n *Notification, format string) ([]byte, error) {
+	switch format {
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": output.Truncate(n.Text, slackMaxText)})
+	case FormatDiscord:
+		return json.Marshal(map[string]string{"content": output.Truncate(n.Text, discordMaxContent)})
+	default:
+		return json.Marshal(n)
+	}
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type Sender struct {
+	Client   *http.Client
+	Attempts int
+	Backoff  time.Duration
+}
+
+func NewSender() *Sender {
+	return &Sender{
+		Client:   &http.Client{Timeout: 10 * time.Second},
+		Attempts: 3,
+		Backoff:  2 * time.Second,
+	}
+}
+
+func (s *Sender) Send(ctx context.Context, target Target, n *Notification) error {
+	body, err := Render(n, target.Format)
+	if err != nil {
+		return fmt.Errorf("render payload: %w", err)
+	}
+
+	attempts := s.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.Backoff * time.Duration(1<<(attempt-1))):
+			}
+		}
+
+		retry, err := s.post(ctx, target, n.Kind, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+func (s *Sender) post(ctx context.Context, target Target, kind string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "devlog-webhooks")
+	req.Header.Set("X-Devlog-Event", kind)
+	for k, v := range target.Headers {
+		req.Header.Set(k, v)
+	}
+	if target.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(target.Secret, body))
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("post to %s: %w", target.URL, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("post to %s: unexpected status %d", target.URL, resp.StatusCode)
+}
@@ -0,0 +1,200 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"devlog/internal/config"
+	"devlog/internal/contextkeys"
+	"devlog/internal/errors"
+	"devlog/internal/install"
+	"devlog/internal/logger"
+	"devlog/internal/metrics"
+	"devlog/internal/plugins"
+	"devlog/internal/state"
+	"devlog/internal/storage"
+)
+
+type Plugin struct {
+	dispatcher *Dispatcher
+	storage    *storage.Storage
+	interval   time.Duration
+	logger     *logger.Logger
+}
+
+func init() {
+	plugins.Register(&Plugin{})
+}
+
+func (p *Plugin) Name() string {
+	return "webhooks"
+}
+
+func (p *Plugin) Description() string {
+	return "Posts summaries, daily reports and matching events to HTTP webhooks"
+}
+
+func (p *Plugin) Metadata() plugins.Metadata {
+	return plugins.Metadata{
+		Name:         "webhooks",
+		Description:  "Posts summaries, daily reports and matching events to HTTP webhooks",
+		Dependencies: []string{},
+	}
+}
+
+func (p *Plugin) Install(ctx *install.Context) error {
+	ctx.Log("Installing Webhooks plugin")
+	ctx.Log("Add targets with a url and the triggers they should receive (summary, daily_report, event)")
+	ctx.Log("Use format slack or discord to post to incoming webhooks for those services")
+	ctx.Log("Send a test notification with: devlog webhooks test")
+	return nil
+}
+
+func (p *Plugin) Uninstall(ctx *install.Context) error {
+	ctx.Log("Uninstalling Webhooks plugin")
+	return nil
+}
+
+func (p *Plugin) DefaultConfig() interface{} {
+	return &Config{
+		IntervalSeconds: 30,
+		DailyReportAt:   "18:00",
+		Targets:         []Target{},
+	}
+}
+
+func (p *Plugin) ValidateConfig(cfgValue interface{}) error {
+	cfgMap, ok := cfgValue.(map[string]interface{})
+	if !ok {
+		return errors.NewValidation("config", "must be a map")
+	}
+
+	if _, ok := cfgMap["interval_seconds"]; ok {
+		interval, err := numberField(cfgMap, "interval_seconds")
+		if err != nil {
+			return err
+		}
+		if interval < 5 || interval > 3600 {
+			return errors.NewValidation("interval_seconds", "must be between 5 and 3600")
+		}
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.NewValidation("config", err.Error())
+	}
+	if err := cfg.Validate(); err != nil {
+		return errors.NewValidation("targets", err.Error())
+	}
+	return nil
+}
+
+func numberField(cfgMap map[string]interface{}, field string) (int, error) {
+	val, ok := cfgMap[field]
+	if !ok {
+		return 0, errors.NewValidation(field, "is required")
+	}
+	switch v := val.(type) {
+	case float64:
+		return int(v), nil
+	case int:
+		return v, nil
+	default:
+		return 0, errors.NewValidation(field, "must be a number")
+	}
+}
+
+func (p *Plugin) Start(ctx context.Context) error {
+	cfgMap, ok := ctx.Value(contextkeys.PluginConfig).(map[string]interface{})
+	if !ok || cfgMap == nil {
+		return errors.WrapPlugin("webhooks", "start", fmt.Errorf("plugin config not found in context"))
+	}
+
+	cfg, err := ParseConfig(cfgMap)
+	if err != nil {
+		return errors.WrapPlugin("webhooks", "parse config", err)
+	}
+
+	if log, ok := ctx.Value(contextkeys.Logger).(*logger.Logger); ok && log != nil {
+		p.logger = log
+	} else {
+		p.logger = logger.Default()
+	}
+
+	dataDir, err := config.DataDir()
+	if err != nil {
+		return errors.WrapPlugin("webhooks", "get data dir", err)
+	}
+
+	stateMgr, err := state.NewManager(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("webhooks", "create state manager", err)
+	}
+
+	store, err := storage.OpenSQLite(dataDir)
+	if err != nil {
+		return errors.WrapPlugin("webhooks", "open storage", err)
+	}
+	p.storage = store
+
+	p.dispatcher = NewDispatcher(store, stateMgr, NewSender(), cfg)
+	p.interval = time.Duration(cfg.IntervalSeconds) * time.Second
+	if p.interval <= 0 {
+		p.interval = 30 * time.Second
+	}
+
+	p.run(ctx, len(cfg.Targets))
+
+	return nil
+}
+
+func (p *Plugin) run(ctx context.Context, targets int) {
+	p.logger.Info("webhooks started", slog.Duration("interval", p.interval), slog.Int("targets", targets))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	summaries, unsubscribe := plugins.Subscribe(plugins.TopicSummaryGenerated)
+	defer unsubscribe()
+
+	p.dispatch(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.logger.Info("webhooks stopped")
+			p.storage.Close()
+			return
+		case <-summaries:
+			p.dispatch(ctx)
+		case <-ticker.C:
+			p.dispatch(ctx)
+		}
+	}
+}
+
+func (p *Plugin) dispatch(ctx context.Context) {
+	timer := metrics.StartPluginTimer("webhooks")
+	defer timer.Stop()
+
+	result, err := p.dispatcher.Run(ctx)
+	if err != nil && ctx.Err() == nil {
+		p.logger.Error("webhook dispatch failed", slog.String("error", err.Error()))
+	}
+	if result == nil {
+		return
+	}
+
+	for _, failure := range result.Failures {
+		p.logger.Warn("webhook delivery failed", slog.String("error", failure.Error()))
+	}
+	if sent := result.Events + result.Summaries + result.Reports; sent > 0 {
+		p.logger.Info("webhooks delivered",
+			slog.Int("events", result.Events),
+			slog.Int("summaries", result.Summaries),
+			slog.Int("reports", result.Reports),
+			slog.Int("failed", len(result.Failures)))
+	}
+}
@@ -0,0 +1,323 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"devlog/internal/events"
+	"devlog/internal/state"
+	"devlog/internal/storage"
+	"devlog/internal/testutil"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	headers  []http.Header
+	statuses []int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		w.WriteHeader(status)
+	}
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func (r *recorder) notification(t *testing.T, i int) Notification {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n Notification
+	if err := json.Unmarshal(r.bodies[i], &n); err != nil {
+		t.Fatalf("decode body %d: %v", i, err)
+	}
+	return n
+}
+
+func testSender() *Sender {
+	return &Sender{Client: http.DefaultClient, Attempts: 3, Backoff: time.Millisecond}
+}
+
+func kubectlEvent(operation, context string) *events.Event {
+	evt := events.NewEvent(string(events.SourceKubectl), "kubectl_"+operation)
+	evt.Payload["context"] = context
+	evt.Payload["namespace"] = "default"
+	evt.Payload["resource"] = "deployment/api"
+	return evt
+}
+
+func TestEventFilterMatches(t *testing.T) {
+	filter := EventFilter{
+		Source:  "kubectl",
+		Types:   []string{"kubectl_delete"},
+		Payload: map[string]string{"context": "prod-*"},
+	}
+
+	tests := []struct {
+		name  string
+		event *events.Event
+		want  bool
+	}{
+		{"delete in prod", kubectlEvent("delete", "prod-eu"), true},
+		{"delete in staging", kubectlEvent("delete", "staging"), false},
+		{"apply in prod", kubectlEvent("apply", "prod-eu"), false},
+		{"other source", events.NewEvent(string(events.SourceShell), string(events.TypeCommand)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filter.Matches(tt.event); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	repoFilter := EventFilter{Types: []string{"pr_*", "commit"}, Repo: "*/devlog"}
+	evt := events.NewEvent(string(events.SourceGit), string(events.TypeCommit))
+	evt.Repo = "/home/me/devlog"
+	if !repoFilter.Matches(evt) {
+		t.Error("expected repo and type globs to match")
+	}
+	evt.Repo = "/home/me/other"
+	if repoFilter.Matches(evt) {
+		t.Error("expected repo glob not to match")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := Config{
+		DailyReportAt: "18:00",
+		Targets: []Target{{
+			Name:   "slack",
+			URL:    "https://hooks.slack.com/services/x",
+			Format: FormatSlack,
+			On:     []string{TriggerSummary, TriggerEvent},
+			Match:  []EventFilter{{Source: "kubectl"}},
+		}},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	invalid := map[string]Config{
+		"bad time":     {DailyReportAt: "6pm"},
+		"bad url":      {Targets: []Target{{Name: "a", URL: "ftp://x", On: []string{TriggerSummary}}}},
+		"bad format":   {Targets: []Target{{Name: "a", URL: "http://x", Format: "teams", On: []string{TriggerSummary}}}},
+		"no triggers":  {Targets: []Target{{Name: "a", URL: "http://x"}}},
+		"event filter": {Targets: []Target{{Name: "a", URL: "http://x", On: []string{TriggerEvent}}}},
+		"duplicate": {Targets: []Target{
+			{Name: "a", URL: "http://x", On: []string{TriggerSummary}},
+			{Name: "a", URL: "http://y", On: []string{TriggerSummary}},
+		}},
+	}
+	for name, cfg := range invalid {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	n := SummaryNotification(&storage.Summary{
+		ID:          7,
+		PeriodStart: time.Now().Add(-time.Hour),
+		PeriodEnd:   time.Now(),
+		Repos:       []string{"devlog"},
+		Text:        "Worked on webhooks.",
+	})
+
+	body, err := Render(n, FormatSlack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slack map[string]string
+	if err := json.Unmarshal(body, &slack); err != nil {
+		t.Fatal(err)
+	}
+	if slack["text"] == "" {
+		t.Error("slack payload missing text")
+	}
+
+	n.Text = string(make([]byte, 5000))
+	body, err = Render(n, FormatDiscord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var discord map[string]string
+	if err := json.Unmarshal(body, &discord); err != nil {
+		t.Fatal(err)
+	}
+	if len(discord["content"]) > discordMaxContent {
+		t.Errorf("discord content length = %d, want <= %d", len(discord["content"]), discordMaxContent)
+	}
+}
+
+func TestSenderSignsAndRetries(t *testing.T) {
+	rec := &recorder{statuses: []int{http.StatusBadGateway, http.StatusOK}}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	target := Target{Name: "t", URL: server.URL, Secret: "s3cret", Headers: map[string]string{"X-Team": "infra"}}
+	n := EventNotification(kubectlEvent("delete", "prod"))
+
+	if err := testSender().Send(context.Background(), target, n); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if rec.count() != 2 {
+		t.Fatalf("requests = %d, want 2 (one retry)", rec.count())
+	}
+
+	body, _ := Render(n, FormatJSON)
+	if got := rec.headers[1].Get(SignatureHeader); got != Sign("s3cret", body) {
+		t.Errorf("signature = %q, want %q", got, Sign("s3cret", body))
+	}
+	if rec.headers[1].Get("X-Team") != "infra" {
+		t.Error("custom header not sent")
+	}
+
+	rejecting := &recorder{statuses: []int{http.StatusBadRequest}}
+	rejectServer := httptest.NewServer(rejecting)
+	defer rejectServer.Close()
+	if err := testSender().Send(context.Background(), Target{URL: rejectServer.URL}, n); err == nil {
+		t.Error("expected error for 400 response")
+	}
+	if rejecting.count() != 1 {
+		t.Errorf("requests = %d, want 1 (no retry on 4xx)", rejecting.count())
+	}
+}
+
+func TestDispatcherDeliversNewActivity(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	ctx := context.Background()
+
+	testutil.MustInsertEvents(t, store, kubectlEvent("delete", "prod"))
+
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	stateMgr, err := state.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{Targets: []Target{
+		{
+			Name:  "alerts",
+			URL:   server.URL,
+			On:    []string{TriggerEvent},
+			Match: []EventFilter{{Types: []string{"kubectl_delete"}, Payload: map[string]string{"context": "prod*"}}},
+		},
+		{Name: "digest", URL: server.URL, On: []string{TriggerSummary}},
+	}}
+	d := NewDispatcher(store, stateMgr, testSender(), cfg)
+
+	result, err := d.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Events != 0 || rec.count() != 0 {
+		t.Fatalf("first run replayed history: events=%d requests=%d", result.Events, rec.count())
+	}
+
+	testutil.MustInsertEvents(t, store,
+		kubectlEvent("delete", "prod-us"),
+		kubectlEvent("delete", "staging"),
+		kubectlEvent("get", "prod-us"),
+	)
+	if err := store.InsertSummaryContext(ctx, &storage.Summary{
+		PeriodStart: time.Now().Add(-15 * time.Minute),
+		PeriodEnd:   time.Now(),
+		Text:        "Deleted the api deployment.",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = d.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if result.Events != 1 || result.Summaries != 1 || len(result.Failures) != 0 {
+		t.Fatalf("result = %+v, want 1 event and 1 summary", result)
+	}
+
+	if n := rec.notification(t, 0); n.Kind != TriggerEvent || n.Event.Payload["context"] != "prod-us" {
+		t.Errorf("first notification = %+v", n)
+	}
+	if n := rec.notification(t, 1); n.Kind != TriggerSummary || n.Summary == nil {
+		t.Errorf("second notification = %+v", n)
+	}
+
+	result, err = d.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Events != 0 || result.Summaries != 0 {
+		t.Errorf("re-delivered activity: %+v", result)
+	}
+}
+
+func TestDispatcherDailyReportOncePerDay(t *testing.T) {
+	store := testutil.NewTestStorage(t)
+	ctx := context.Background()
+
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	stateMgr, err := state.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &Config{
+		DailyReportAt: "18:00",
+		Targets:       []Target{{Name: "report", URL: server.URL, Format: FormatDiscord, On: []string{TriggerDailyReport}}},
+	}
+	d := NewDispatcher(store, stateMgr, testSender(), cfg)
+
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	d.now = func() time.Time { return day.Add(17 * time.Hour) }
+	if _, err := d.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.count() != 0 {
+		t.Fatal("report sent before daily_report_at")
+	}
+
+	d.now = func() time.Time { return day.Add(18*time.Hour + time.Minute) }
+	for i := 0; i < 2; i++ {
+		if _, err := d.Run(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rec.count() != 1 {
+		t.Fatalf("requests = %d, want 1", rec.count())
+	}
+
+	d.now = func() time.Time { return day.AddDate(0, 0, 1).Add(19 * time.Hour) }
+	if _, err := d.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rec.count() != 2 {
+		t.Errorf("requests = %d, want 2 after the next day", rec.count())
+	}
+}